@@ -0,0 +1,1965 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v6.33.2
+// source: catalog.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// 列出数据源请求
+type ListSourcesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSourcesRequest) Reset() {
+	*x = ListSourcesRequest{}
+	mi := &file_catalog_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSourcesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSourcesRequest) ProtoMessage() {}
+
+func (x *ListSourcesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSourcesRequest.ProtoReflect.Descriptor instead.
+func (*ListSourcesRequest) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{0}
+}
+
+// 列出数据源响应
+type ListSourcesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sources       []string               `protobuf:"bytes,1,rep,name=sources,proto3" json:"sources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSourcesResponse) Reset() {
+	*x = ListSourcesResponse{}
+	mi := &file_catalog_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSourcesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSourcesResponse) ProtoMessage() {}
+
+func (x *ListSourcesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSourcesResponse.ProtoReflect.Descriptor instead.
+func (*ListSourcesResponse) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListSourcesResponse) GetSources() []string {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+// Catalog 信息
+type CatalogInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Catalog       string                 `protobuf:"bytes,1,opt,name=catalog,proto3" json:"catalog,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Properties    map[string]string      `protobuf:"bytes,4,rep,name=properties,proto3" json:"properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CatalogInfo) Reset() {
+	*x = CatalogInfo{}
+	mi := &file_catalog_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CatalogInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CatalogInfo) ProtoMessage() {}
+
+func (x *CatalogInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CatalogInfo.ProtoReflect.Descriptor instead.
+func (*CatalogInfo) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CatalogInfo) GetCatalog() string {
+	if x != nil {
+		return x.Catalog
+	}
+	return ""
+}
+
+func (x *CatalogInfo) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CatalogInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CatalogInfo) GetProperties() map[string]string {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
+// 列出 Catalog 请求
+type ListCatalogsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCatalogsRequest) Reset() {
+	*x = ListCatalogsRequest{}
+	mi := &file_catalog_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCatalogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCatalogsRequest) ProtoMessage() {}
+
+func (x *ListCatalogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCatalogsRequest.ProtoReflect.Descriptor instead.
+func (*ListCatalogsRequest) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListCatalogsRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+// 列出 Catalog 响应
+type ListCatalogsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Catalogs      []*CatalogInfo         `protobuf:"bytes,1,rep,name=catalogs,proto3" json:"catalogs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCatalogsResponse) Reset() {
+	*x = ListCatalogsResponse{}
+	mi := &file_catalog_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCatalogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCatalogsResponse) ProtoMessage() {}
+
+func (x *ListCatalogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCatalogsResponse.ProtoReflect.Descriptor instead.
+func (*ListCatalogsResponse) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListCatalogsResponse) GetCatalogs() []*CatalogInfo {
+	if x != nil {
+		return x.Catalogs
+	}
+	return nil
+}
+
+// 列出 Schema 请求
+type ListSchemasRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Catalog       string                 `protobuf:"bytes,2,opt,name=catalog,proto3" json:"catalog,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSchemasRequest) Reset() {
+	*x = ListSchemasRequest{}
+	mi := &file_catalog_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSchemasRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSchemasRequest) ProtoMessage() {}
+
+func (x *ListSchemasRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSchemasRequest.ProtoReflect.Descriptor instead.
+func (*ListSchemasRequest) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListSchemasRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ListSchemasRequest) GetCatalog() string {
+	if x != nil {
+		return x.Catalog
+	}
+	return ""
+}
+
+// 列出 Schema 响应
+type ListSchemasResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schemas       []string               `protobuf:"bytes,1,rep,name=schemas,proto3" json:"schemas,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSchemasResponse) Reset() {
+	*x = ListSchemasResponse{}
+	mi := &file_catalog_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSchemasResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSchemasResponse) ProtoMessage() {}
+
+func (x *ListSchemasResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSchemasResponse.ProtoReflect.Descriptor instead.
+func (*ListSchemasResponse) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListSchemasResponse) GetSchemas() []string {
+	if x != nil {
+		return x.Schemas
+	}
+	return nil
+}
+
+// 分页列出表请求
+type ListTablesRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Source  string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Catalog string                 `protobuf:"bytes,2,opt,name=catalog,proto3" json:"catalog,omitempty"`
+	Schema  string                 `protobuf:"bytes,3,opt,name=schema,proto3" json:"schema,omitempty"`
+	// 每页数量，默认 20，最大 100
+	PageSize int32 `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// 上一页返回的 next_page_token
+	PageToken     string `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTablesRequest) Reset() {
+	*x = ListTablesRequest{}
+	mi := &file_catalog_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTablesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTablesRequest) ProtoMessage() {}
+
+func (x *ListTablesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTablesRequest.ProtoReflect.Descriptor instead.
+func (*ListTablesRequest) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListTablesRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ListTablesRequest) GetCatalog() string {
+	if x != nil {
+		return x.Catalog
+	}
+	return ""
+}
+
+func (x *ListTablesRequest) GetSchema() string {
+	if x != nil {
+		return x.Schema
+	}
+	return ""
+}
+
+func (x *ListTablesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListTablesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// 分页列出表响应
+type ListTablesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tables        []string               `protobuf:"bytes,1,rep,name=tables,proto3" json:"tables,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,3,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTablesResponse) Reset() {
+	*x = ListTablesResponse{}
+	mi := &file_catalog_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTablesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTablesResponse) ProtoMessage() {}
+
+func (x *ListTablesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTablesResponse.ProtoReflect.Descriptor instead.
+func (*ListTablesResponse) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListTablesResponse) GetTables() []string {
+	if x != nil {
+		return x.Tables
+	}
+	return nil
+}
+
+func (x *ListTablesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListTablesResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+// 流式列出表请求
+type StreamTablesRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Source  string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Catalog string                 `protobuf:"bytes,2,opt,name=catalog,proto3" json:"catalog,omitempty"`
+	Schema  string                 `protobuf:"bytes,3,opt,name=schema,proto3" json:"schema,omitempty"`
+	// 为每张表获取元数据，获取失败时在 error 中返回原因并继续
+	IncludeMetadata bool `protobuf:"varint,4,opt,name=include_metadata,json=includeMetadata,proto3" json:"include_metadata,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *StreamTablesRequest) Reset() {
+	*x = StreamTablesRequest{}
+	mi := &file_catalog_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTablesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTablesRequest) ProtoMessage() {}
+
+func (x *StreamTablesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTablesRequest.ProtoReflect.Descriptor instead.
+func (*StreamTablesRequest) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StreamTablesRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *StreamTablesRequest) GetCatalog() string {
+	if x != nil {
+		return x.Catalog
+	}
+	return ""
+}
+
+func (x *StreamTablesRequest) GetSchema() string {
+	if x != nil {
+		return x.Schema
+	}
+	return ""
+}
+
+func (x *StreamTablesRequest) GetIncludeMetadata() bool {
+	if x != nil {
+		return x.IncludeMetadata
+	}
+	return false
+}
+
+// 流式列出表的一项
+type TableEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Metadata      *TableMetadata         `protobuf:"bytes,2,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TableEntry) Reset() {
+	*x = TableEntry{}
+	mi := &file_catalog_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TableEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TableEntry) ProtoMessage() {}
+
+func (x *TableEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TableEntry.ProtoReflect.Descriptor instead.
+func (*TableEntry) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TableEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TableEntry) GetMetadata() *TableMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *TableEntry) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// 获取表元数据请求
+type GetTableMetadataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Catalog       string                 `protobuf:"bytes,2,opt,name=catalog,proto3" json:"catalog,omitempty"`
+	Schema        string                 `protobuf:"bytes,3,opt,name=schema,proto3" json:"schema,omitempty"`
+	Table         string                 `protobuf:"bytes,4,opt,name=table,proto3" json:"table,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTableMetadataRequest) Reset() {
+	*x = GetTableMetadataRequest{}
+	mi := &file_catalog_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTableMetadataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTableMetadataRequest) ProtoMessage() {}
+
+func (x *GetTableMetadataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTableMetadataRequest.ProtoReflect.Descriptor instead.
+func (*GetTableMetadataRequest) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetTableMetadataRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *GetTableMetadataRequest) GetCatalog() string {
+	if x != nil {
+		return x.Catalog
+	}
+	return ""
+}
+
+func (x *GetTableMetadataRequest) GetSchema() string {
+	if x != nil {
+		return x.Schema
+	}
+	return ""
+}
+
+func (x *GetTableMetadataRequest) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+// 获取表统计信息请求
+type GetTableStatisticsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Catalog       string                 `protobuf:"bytes,2,opt,name=catalog,proto3" json:"catalog,omitempty"`
+	Schema        string                 `protobuf:"bytes,3,opt,name=schema,proto3" json:"schema,omitempty"`
+	Table         string                 `protobuf:"bytes,4,opt,name=table,proto3" json:"table,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTableStatisticsRequest) Reset() {
+	*x = GetTableStatisticsRequest{}
+	mi := &file_catalog_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTableStatisticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTableStatisticsRequest) ProtoMessage() {}
+
+func (x *GetTableStatisticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTableStatisticsRequest.ProtoReflect.Descriptor instead.
+func (*GetTableStatisticsRequest) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetTableStatisticsRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *GetTableStatisticsRequest) GetCatalog() string {
+	if x != nil {
+		return x.Catalog
+	}
+	return ""
+}
+
+func (x *GetTableStatisticsRequest) GetSchema() string {
+	if x != nil {
+		return x.Schema
+	}
+	return ""
+}
+
+func (x *GetTableStatisticsRequest) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+// 获取表分区信息请求
+type ListPartitionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Catalog       string                 `protobuf:"bytes,2,opt,name=catalog,proto3" json:"catalog,omitempty"`
+	Schema        string                 `protobuf:"bytes,3,opt,name=schema,proto3" json:"schema,omitempty"`
+	Table         string                 `protobuf:"bytes,4,opt,name=table,proto3" json:"table,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPartitionsRequest) Reset() {
+	*x = ListPartitionsRequest{}
+	mi := &file_catalog_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPartitionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPartitionsRequest) ProtoMessage() {}
+
+func (x *ListPartitionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPartitionsRequest.ProtoReflect.Descriptor instead.
+func (*ListPartitionsRequest) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListPartitionsRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ListPartitionsRequest) GetCatalog() string {
+	if x != nil {
+		return x.Catalog
+	}
+	return ""
+}
+
+func (x *ListPartitionsRequest) GetSchema() string {
+	if x != nil {
+		return x.Schema
+	}
+	return ""
+}
+
+func (x *ListPartitionsRequest) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+// 获取表分区信息响应
+type ListPartitionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Partitions    []*PartitionInfo       `protobuf:"bytes,1,rep,name=partitions,proto3" json:"partitions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPartitionsResponse) Reset() {
+	*x = ListPartitionsResponse{}
+	mi := &file_catalog_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPartitionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPartitionsResponse) ProtoMessage() {}
+
+func (x *ListPartitionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPartitionsResponse.ProtoReflect.Descriptor instead.
+func (*ListPartitionsResponse) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListPartitionsResponse) GetPartitions() []*PartitionInfo {
+	if x != nil {
+		return x.Partitions
+	}
+	return nil
+}
+
+// 同步数据源请求
+type SyncSourceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncSourceRequest) Reset() {
+	*x = SyncSourceRequest{}
+	mi := &file_catalog_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncSourceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncSourceRequest) ProtoMessage() {}
+
+func (x *SyncSourceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncSourceRequest.ProtoReflect.Descriptor instead.
+func (*SyncSourceRequest) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SyncSourceRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+// 同步数据源响应
+type SyncSourceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncSourceResponse) Reset() {
+	*x = SyncSourceResponse{}
+	mi := &file_catalog_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncSourceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncSourceResponse) ProtoMessage() {}
+
+func (x *SyncSourceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncSourceResponse.ProtoReflect.Descriptor instead.
+func (*SyncSourceResponse) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SyncSourceResponse) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *SyncSourceResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// 表元数据
+type TableMetadata struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SourceCategory string                 `protobuf:"bytes,1,opt,name=source_category,json=sourceCategory,proto3" json:"source_category,omitempty"`
+	SourceType     string                 `protobuf:"bytes,2,opt,name=source_type,json=sourceType,proto3" json:"source_type,omitempty"`
+	Catalog        string                 `protobuf:"bytes,3,opt,name=catalog,proto3" json:"catalog,omitempty"`
+	Schema         string                 `protobuf:"bytes,4,opt,name=schema,proto3" json:"schema,omitempty"`
+	Name           string                 `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	// TABLE, VIEW, EXTERNAL_TABLE, MATERIALIZED_VIEW, COLLECTION, TOPIC 等
+	Type            string                 `protobuf:"bytes,6,opt,name=type,proto3" json:"type,omitempty"`
+	Comment         string                 `protobuf:"bytes,7,opt,name=comment,proto3" json:"comment,omitempty"`
+	Columns         []*Column              `protobuf:"bytes,8,rep,name=columns,proto3" json:"columns,omitempty"`
+	Partitions      []*PartitionInfo       `protobuf:"bytes,9,rep,name=partitions,proto3" json:"partitions,omitempty"`
+	Indexes         []*Index               `protobuf:"bytes,10,rep,name=indexes,proto3" json:"indexes,omitempty"`
+	PrimaryKey      []string               `protobuf:"bytes,11,rep,name=primary_key,json=primaryKey,proto3" json:"primary_key,omitempty"`
+	Storage         *StorageInfo           `protobuf:"bytes,12,opt,name=storage,proto3" json:"storage,omitempty"`
+	Properties      map[string]string      `protobuf:"bytes,13,rep,name=properties,proto3" json:"properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	LastRefreshedAt *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=last_refreshed_at,json=lastRefreshedAt,proto3" json:"last_refreshed_at,omitempty"`
+	// 是否为推断的 Schema
+	InferredSchema bool `protobuf:"varint,15,opt,name=inferred_schema,json=inferredSchema,proto3" json:"inferred_schema,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TableMetadata) Reset() {
+	*x = TableMetadata{}
+	mi := &file_catalog_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TableMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TableMetadata) ProtoMessage() {}
+
+func (x *TableMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TableMetadata.ProtoReflect.Descriptor instead.
+func (*TableMetadata) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *TableMetadata) GetSourceCategory() string {
+	if x != nil {
+		return x.SourceCategory
+	}
+	return ""
+}
+
+func (x *TableMetadata) GetSourceType() string {
+	if x != nil {
+		return x.SourceType
+	}
+	return ""
+}
+
+func (x *TableMetadata) GetCatalog() string {
+	if x != nil {
+		return x.Catalog
+	}
+	return ""
+}
+
+func (x *TableMetadata) GetSchema() string {
+	if x != nil {
+		return x.Schema
+	}
+	return ""
+}
+
+func (x *TableMetadata) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TableMetadata) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *TableMetadata) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *TableMetadata) GetColumns() []*Column {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *TableMetadata) GetPartitions() []*PartitionInfo {
+	if x != nil {
+		return x.Partitions
+	}
+	return nil
+}
+
+func (x *TableMetadata) GetIndexes() []*Index {
+	if x != nil {
+		return x.Indexes
+	}
+	return nil
+}
+
+func (x *TableMetadata) GetPrimaryKey() []string {
+	if x != nil {
+		return x.PrimaryKey
+	}
+	return nil
+}
+
+func (x *TableMetadata) GetStorage() *StorageInfo {
+	if x != nil {
+		return x.Storage
+	}
+	return nil
+}
+
+func (x *TableMetadata) GetProperties() map[string]string {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
+func (x *TableMetadata) GetLastRefreshedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastRefreshedAt
+	}
+	return nil
+}
+
+func (x *TableMetadata) GetInferredSchema() bool {
+	if x != nil {
+		return x.InferredSchema
+	}
+	return false
+}
+
+// 列定义
+type Column struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	OrdinalPosition   int32                  `protobuf:"varint,1,opt,name=ordinal_position,json=ordinalPosition,proto3" json:"ordinal_position,omitempty"`
+	Name              string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Type              string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	SourceType        string                 `protobuf:"bytes,4,opt,name=source_type,json=sourceType,proto3" json:"source_type,omitempty"`
+	Length            *int32                 `protobuf:"varint,5,opt,name=length,proto3,oneof" json:"length,omitempty"`
+	Precision         *int32                 `protobuf:"varint,6,opt,name=precision,proto3,oneof" json:"precision,omitempty"`
+	Scale             *int32                 `protobuf:"varint,7,opt,name=scale,proto3,oneof" json:"scale,omitempty"`
+	Nullable          bool                   `protobuf:"varint,8,opt,name=nullable,proto3" json:"nullable,omitempty"`
+	DefaultValue      *string                `protobuf:"bytes,9,opt,name=default_value,json=defaultValue,proto3,oneof" json:"default_value,omitempty"`
+	Comment           string                 `protobuf:"bytes,10,opt,name=comment,proto3" json:"comment,omitempty"`
+	IsPrimaryKey      bool                   `protobuf:"varint,11,opt,name=is_primary_key,json=isPrimaryKey,proto3" json:"is_primary_key,omitempty"`
+	IsPartitionColumn bool                   `protobuf:"varint,12,opt,name=is_partition_column,json=isPartitionColumn,proto3" json:"is_partition_column,omitempty"`
+	IsAutoIncrement   bool                   `protobuf:"varint,13,opt,name=is_auto_increment,json=isAutoIncrement,proto3" json:"is_auto_increment,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Column) Reset() {
+	*x = Column{}
+	mi := &file_catalog_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Column) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Column) ProtoMessage() {}
+
+func (x *Column) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Column.ProtoReflect.Descriptor instead.
+func (*Column) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Column) GetOrdinalPosition() int32 {
+	if x != nil {
+		return x.OrdinalPosition
+	}
+	return 0
+}
+
+func (x *Column) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Column) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Column) GetSourceType() string {
+	if x != nil {
+		return x.SourceType
+	}
+	return ""
+}
+
+func (x *Column) GetLength() int32 {
+	if x != nil && x.Length != nil {
+		return *x.Length
+	}
+	return 0
+}
+
+func (x *Column) GetPrecision() int32 {
+	if x != nil && x.Precision != nil {
+		return *x.Precision
+	}
+	return 0
+}
+
+func (x *Column) GetScale() int32 {
+	if x != nil && x.Scale != nil {
+		return *x.Scale
+	}
+	return 0
+}
+
+func (x *Column) GetNullable() bool {
+	if x != nil {
+		return x.Nullable
+	}
+	return false
+}
+
+func (x *Column) GetDefaultValue() string {
+	if x != nil && x.DefaultValue != nil {
+		return *x.DefaultValue
+	}
+	return ""
+}
+
+func (x *Column) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *Column) GetIsPrimaryKey() bool {
+	if x != nil {
+		return x.IsPrimaryKey
+	}
+	return false
+}
+
+func (x *Column) GetIsPartitionColumn() bool {
+	if x != nil {
+		return x.IsPartitionColumn
+	}
+	return false
+}
+
+func (x *Column) GetIsAutoIncrement() bool {
+	if x != nil {
+		return x.IsAutoIncrement
+	}
+	return false
+}
+
+// 索引定义
+type Index struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Columns       []string               `protobuf:"bytes,2,rep,name=columns,proto3" json:"columns,omitempty"`
+	Unique        bool                   `protobuf:"varint,3,opt,name=unique,proto3" json:"unique,omitempty"`
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	Comment       string                 `protobuf:"bytes,5,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Index) Reset() {
+	*x = Index{}
+	mi := &file_catalog_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Index) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Index) ProtoMessage() {}
+
+func (x *Index) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Index.ProtoReflect.Descriptor instead.
+func (*Index) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Index) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Index) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *Index) GetUnique() bool {
+	if x != nil {
+		return x.Unique
+	}
+	return false
+}
+
+func (x *Index) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Index) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+// 分区信息
+type PartitionInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Columns       []string               `protobuf:"bytes,3,rep,name=columns,proto3" json:"columns,omitempty"`
+	Expression    string                 `protobuf:"bytes,4,opt,name=expression,proto3" json:"expression,omitempty"`
+	ValuesCount   int32                  `protobuf:"varint,5,opt,name=values_count,json=valuesCount,proto3" json:"values_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PartitionInfo) Reset() {
+	*x = PartitionInfo{}
+	mi := &file_catalog_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PartitionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartitionInfo) ProtoMessage() {}
+
+func (x *PartitionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartitionInfo.ProtoReflect.Descriptor instead.
+func (*PartitionInfo) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *PartitionInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PartitionInfo) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *PartitionInfo) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *PartitionInfo) GetExpression() string {
+	if x != nil {
+		return x.Expression
+	}
+	return ""
+}
+
+func (x *PartitionInfo) GetValuesCount() int32 {
+	if x != nil {
+		return x.ValuesCount
+	}
+	return 0
+}
+
+// 存储信息
+type StorageInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        string                 `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	Location      string                 `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	InputFormat   string                 `protobuf:"bytes,3,opt,name=input_format,json=inputFormat,proto3" json:"input_format,omitempty"`
+	OutputFormat  string                 `protobuf:"bytes,4,opt,name=output_format,json=outputFormat,proto3" json:"output_format,omitempty"`
+	Serde         string                 `protobuf:"bytes,5,opt,name=serde,proto3" json:"serde,omitempty"`
+	Compressed    bool                   `protobuf:"varint,6,opt,name=compressed,proto3" json:"compressed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StorageInfo) Reset() {
+	*x = StorageInfo{}
+	mi := &file_catalog_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorageInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageInfo) ProtoMessage() {}
+
+func (x *StorageInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageInfo.ProtoReflect.Descriptor instead.
+func (*StorageInfo) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *StorageInfo) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *StorageInfo) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *StorageInfo) GetInputFormat() string {
+	if x != nil {
+		return x.InputFormat
+	}
+	return ""
+}
+
+func (x *StorageInfo) GetOutputFormat() string {
+	if x != nil {
+		return x.OutputFormat
+	}
+	return ""
+}
+
+func (x *StorageInfo) GetSerde() string {
+	if x != nil {
+		return x.Serde
+	}
+	return ""
+}
+
+func (x *StorageInfo) GetCompressed() bool {
+	if x != nil {
+		return x.Compressed
+	}
+	return false
+}
+
+// 表统计信息
+type TableStatistics struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	RowCount       int64                  `protobuf:"varint,1,opt,name=row_count,json=rowCount,proto3" json:"row_count,omitempty"`
+	DataSizeBytes  int64                  `protobuf:"varint,2,opt,name=data_size_bytes,json=dataSizeBytes,proto3" json:"data_size_bytes,omitempty"`
+	PartitionCount int32                  `protobuf:"varint,3,opt,name=partition_count,json=partitionCount,proto3" json:"partition_count,omitempty"`
+	ColumnStats    []*ColumnStatistics    `protobuf:"bytes,4,rep,name=column_stats,json=columnStats,proto3" json:"column_stats,omitempty"`
+	CollectedAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TableStatistics) Reset() {
+	*x = TableStatistics{}
+	mi := &file_catalog_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TableStatistics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TableStatistics) ProtoMessage() {}
+
+func (x *TableStatistics) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TableStatistics.ProtoReflect.Descriptor instead.
+func (*TableStatistics) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *TableStatistics) GetRowCount() int64 {
+	if x != nil {
+		return x.RowCount
+	}
+	return 0
+}
+
+func (x *TableStatistics) GetDataSizeBytes() int64 {
+	if x != nil {
+		return x.DataSizeBytes
+	}
+	return 0
+}
+
+func (x *TableStatistics) GetPartitionCount() int32 {
+	if x != nil {
+		return x.PartitionCount
+	}
+	return 0
+}
+
+func (x *TableStatistics) GetColumnStats() []*ColumnStatistics {
+	if x != nil {
+		return x.ColumnStats
+	}
+	return nil
+}
+
+func (x *TableStatistics) GetCollectedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return nil
+}
+
+// 列统计信息，未知的统计项不设置
+type ColumnStatistics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	DistinctCount *int64                 `protobuf:"varint,2,opt,name=distinct_count,json=distinctCount,proto3,oneof" json:"distinct_count,omitempty"`
+	NullCount     *int64                 `protobuf:"varint,3,opt,name=null_count,json=nullCount,proto3,oneof" json:"null_count,omitempty"`
+	Min           *string                `protobuf:"bytes,4,opt,name=min,proto3,oneof" json:"min,omitempty"`
+	Max           *string                `protobuf:"bytes,5,opt,name=max,proto3,oneof" json:"max,omitempty"`
+	Avg           *float64               `protobuf:"fixed64,6,opt,name=avg,proto3,oneof" json:"avg,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ColumnStatistics) Reset() {
+	*x = ColumnStatistics{}
+	mi := &file_catalog_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ColumnStatistics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ColumnStatistics) ProtoMessage() {}
+
+func (x *ColumnStatistics) ProtoReflect() protoreflect.Message {
+	mi := &file_catalog_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ColumnStatistics.ProtoReflect.Descriptor instead.
+func (*ColumnStatistics) Descriptor() ([]byte, []int) {
+	return file_catalog_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ColumnStatistics) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ColumnStatistics) GetDistinctCount() int64 {
+	if x != nil && x.DistinctCount != nil {
+		return *x.DistinctCount
+	}
+	return 0
+}
+
+func (x *ColumnStatistics) GetNullCount() int64 {
+	if x != nil && x.NullCount != nil {
+		return *x.NullCount
+	}
+	return 0
+}
+
+func (x *ColumnStatistics) GetMin() string {
+	if x != nil && x.Min != nil {
+		return *x.Min
+	}
+	return ""
+}
+
+func (x *ColumnStatistics) GetMax() string {
+	if x != nil && x.Max != nil {
+		return *x.Max
+	}
+	return ""
+}
+
+func (x *ColumnStatistics) GetAvg() float64 {
+	if x != nil && x.Avg != nil {
+		return *x.Avg
+	}
+	return 0
+}
+
+var File_catalog_proto protoreflect.FileDescriptor
+
+const file_catalog_proto_rawDesc = "" +
+	"\n" +
+	"\rcatalog.proto\x12\x0fapi.metadata.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x14\n" +
+	"\x12ListSourcesRequest\"/\n" +
+	"\x13ListSourcesResponse\x12\x18\n" +
+	"\asources\x18\x01 \x03(\tR\asources\"\xea\x01\n" +
+	"\vCatalogInfo\x12\x18\n" +
+	"\acatalog\x18\x01 \x01(\tR\acatalog\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12L\n" +
+	"\n" +
+	"properties\x18\x04 \x03(\v2,.api.metadata.v1.CatalogInfo.PropertiesEntryR\n" +
+	"properties\x1a=\n" +
+	"\x0fPropertiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"-\n" +
+	"\x13ListCatalogsRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\"P\n" +
+	"\x14ListCatalogsResponse\x128\n" +
+	"\bcatalogs\x18\x01 \x03(\v2\x1c.api.metadata.v1.CatalogInfoR\bcatalogs\"F\n" +
+	"\x12ListSchemasRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x18\n" +
+	"\acatalog\x18\x02 \x01(\tR\acatalog\"/\n" +
+	"\x13ListSchemasResponse\x12\x18\n" +
+	"\aschemas\x18\x01 \x03(\tR\aschemas\"\x99\x01\n" +
+	"\x11ListTablesRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x18\n" +
+	"\acatalog\x18\x02 \x01(\tR\acatalog\x12\x16\n" +
+	"\x06schema\x18\x03 \x01(\tR\x06schema\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x05 \x01(\tR\tpageToken\"u\n" +
+	"\x12ListTablesResponse\x12\x16\n" +
+	"\x06tables\x18\x01 \x03(\tR\x06tables\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1f\n" +
+	"\vtotal_count\x18\x03 \x01(\x05R\n" +
+	"totalCount\"\x8a\x01\n" +
+	"\x13StreamTablesRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x18\n" +
+	"\acatalog\x18\x02 \x01(\tR\acatalog\x12\x16\n" +
+	"\x06schema\x18\x03 \x01(\tR\x06schema\x12)\n" +
+	"\x10include_metadata\x18\x04 \x01(\bR\x0fincludeMetadata\"r\n" +
+	"\n" +
+	"TableEntry\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12:\n" +
+	"\bmetadata\x18\x02 \x01(\v2\x1e.api.metadata.v1.TableMetadataR\bmetadata\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"y\n" +
+	"\x17GetTableMetadataRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x18\n" +
+	"\acatalog\x18\x02 \x01(\tR\acatalog\x12\x16\n" +
+	"\x06schema\x18\x03 \x01(\tR\x06schema\x12\x14\n" +
+	"\x05table\x18\x04 \x01(\tR\x05table\"{\n" +
+	"\x19GetTableStatisticsRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x18\n" +
+	"\acatalog\x18\x02 \x01(\tR\acatalog\x12\x16\n" +
+	"\x06schema\x18\x03 \x01(\tR\x06schema\x12\x14\n" +
+	"\x05table\x18\x04 \x01(\tR\x05table\"w\n" +
+	"\x15ListPartitionsRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x18\n" +
+	"\acatalog\x18\x02 \x01(\tR\acatalog\x12\x16\n" +
+	"\x06schema\x18\x03 \x01(\tR\x06schema\x12\x14\n" +
+	"\x05table\x18\x04 \x01(\tR\x05table\"X\n" +
+	"\x16ListPartitionsResponse\x12>\n" +
+	"\n" +
+	"partitions\x18\x01 \x03(\v2\x1e.api.metadata.v1.PartitionInfoR\n" +
+	"partitions\"+\n" +
+	"\x11SyncSourceRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\"D\n" +
+	"\x12SyncSourceResponse\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"\xcb\x05\n" +
+	"\rTableMetadata\x12'\n" +
+	"\x0fsource_category\x18\x01 \x01(\tR\x0esourceCategory\x12\x1f\n" +
+	"\vsource_type\x18\x02 \x01(\tR\n" +
+	"sourceType\x12\x18\n" +
+	"\acatalog\x18\x03 \x01(\tR\acatalog\x12\x16\n" +
+	"\x06schema\x18\x04 \x01(\tR\x06schema\x12\x12\n" +
+	"\x04name\x18\x05 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x06 \x01(\tR\x04type\x12\x18\n" +
+	"\acomment\x18\a \x01(\tR\acomment\x121\n" +
+	"\acolumns\x18\b \x03(\v2\x17.api.metadata.v1.ColumnR\acolumns\x12>\n" +
+	"\n" +
+	"partitions\x18\t \x03(\v2\x1e.api.metadata.v1.PartitionInfoR\n" +
+	"partitions\x120\n" +
+	"\aindexes\x18\n" +
+	" \x03(\v2\x16.api.metadata.v1.IndexR\aindexes\x12\x1f\n" +
+	"\vprimary_key\x18\v \x03(\tR\n" +
+	"primaryKey\x126\n" +
+	"\astorage\x18\f \x01(\v2\x1c.api.metadata.v1.StorageInfoR\astorage\x12N\n" +
+	"\n" +
+	"properties\x18\r \x03(\v2..api.metadata.v1.TableMetadata.PropertiesEntryR\n" +
+	"properties\x12F\n" +
+	"\x11last_refreshed_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\x0flastRefreshedAt\x12'\n" +
+	"\x0finferred_schema\x18\x0f \x01(\bR\x0einferredSchema\x1a=\n" +
+	"\x0fPropertiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xee\x03\n" +
+	"\x06Column\x12)\n" +
+	"\x10ordinal_position\x18\x01 \x01(\x05R\x0fordinalPosition\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1f\n" +
+	"\vsource_type\x18\x04 \x01(\tR\n" +
+	"sourceType\x12\x1b\n" +
+	"\x06length\x18\x05 \x01(\x05H\x00R\x06length\x88\x01\x01\x12!\n" +
+	"\tprecision\x18\x06 \x01(\x05H\x01R\tprecision\x88\x01\x01\x12\x19\n" +
+	"\x05scale\x18\a \x01(\x05H\x02R\x05scale\x88\x01\x01\x12\x1a\n" +
+	"\bnullable\x18\b \x01(\bR\bnullable\x12(\n" +
+	"\rdefault_value\x18\t \x01(\tH\x03R\fdefaultValue\x88\x01\x01\x12\x18\n" +
+	"\acomment\x18\n" +
+	" \x01(\tR\acomment\x12$\n" +
+	"\x0eis_primary_key\x18\v \x01(\bR\fisPrimaryKey\x12.\n" +
+	"\x13is_partition_column\x18\f \x01(\bR\x11isPartitionColumn\x12*\n" +
+	"\x11is_auto_increment\x18\r \x01(\bR\x0fisAutoIncrementB\t\n" +
+	"\a_lengthB\f\n" +
+	"\n" +
+	"_precisionB\b\n" +
+	"\x06_scaleB\x10\n" +
+	"\x0e_default_value\"{\n" +
+	"\x05Index\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\acolumns\x18\x02 \x03(\tR\acolumns\x12\x16\n" +
+	"\x06unique\x18\x03 \x01(\bR\x06unique\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x18\n" +
+	"\acomment\x18\x05 \x01(\tR\acomment\"\x94\x01\n" +
+	"\rPartitionInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
+	"\acolumns\x18\x03 \x03(\tR\acolumns\x12\x1e\n" +
+	"\n" +
+	"expression\x18\x04 \x01(\tR\n" +
+	"expression\x12!\n" +
+	"\fvalues_count\x18\x05 \x01(\x05R\vvaluesCount\"\xbf\x01\n" +
+	"\vStorageInfo\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12\x1a\n" +
+	"\blocation\x18\x02 \x01(\tR\blocation\x12!\n" +
+	"\finput_format\x18\x03 \x01(\tR\vinputFormat\x12#\n" +
+	"\routput_format\x18\x04 \x01(\tR\foutputFormat\x12\x14\n" +
+	"\x05serde\x18\x05 \x01(\tR\x05serde\x12\x1e\n" +
+	"\n" +
+	"compressed\x18\x06 \x01(\bR\n" +
+	"compressed\"\x84\x02\n" +
+	"\x0fTableStatistics\x12\x1b\n" +
+	"\trow_count\x18\x01 \x01(\x03R\browCount\x12&\n" +
+	"\x0fdata_size_bytes\x18\x02 \x01(\x03R\rdataSizeBytes\x12'\n" +
+	"\x0fpartition_count\x18\x03 \x01(\x05R\x0epartitionCount\x12D\n" +
+	"\fcolumn_stats\x18\x04 \x03(\v2!.api.metadata.v1.ColumnStatisticsR\vcolumnStats\x12=\n" +
+	"\fcollected_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vcollectedAt\"\xf5\x01\n" +
+	"\x10ColumnStatistics\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12*\n" +
+	"\x0edistinct_count\x18\x02 \x01(\x03H\x00R\rdistinctCount\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"null_count\x18\x03 \x01(\x03H\x01R\tnullCount\x88\x01\x01\x12\x15\n" +
+	"\x03min\x18\x04 \x01(\tH\x02R\x03min\x88\x01\x01\x12\x15\n" +
+	"\x03max\x18\x05 \x01(\tH\x03R\x03max\x88\x01\x01\x12\x15\n" +
+	"\x03avg\x18\x06 \x01(\x01H\x04R\x03avg\x88\x01\x01B\x11\n" +
+	"\x0f_distinct_countB\r\n" +
+	"\v_null_countB\x06\n" +
+	"\x04_minB\x06\n" +
+	"\x04_maxB\x06\n" +
+	"\x04_avg2\xc9\x06\n" +
+	"\x0eCatalogService\x12X\n" +
+	"\vListSources\x12#.api.metadata.v1.ListSourcesRequest\x1a$.api.metadata.v1.ListSourcesResponse\x12[\n" +
+	"\fListCatalogs\x12$.api.metadata.v1.ListCatalogsRequest\x1a%.api.metadata.v1.ListCatalogsResponse\x12X\n" +
+	"\vListSchemas\x12#.api.metadata.v1.ListSchemasRequest\x1a$.api.metadata.v1.ListSchemasResponse\x12U\n" +
+	"\n" +
+	"ListTables\x12\".api.metadata.v1.ListTablesRequest\x1a#.api.metadata.v1.ListTablesResponse\x12S\n" +
+	"\fStreamTables\x12$.api.metadata.v1.StreamTablesRequest\x1a\x1b.api.metadata.v1.TableEntry0\x01\x12\\\n" +
+	"\x10GetTableMetadata\x12(.api.metadata.v1.GetTableMetadataRequest\x1a\x1e.api.metadata.v1.TableMetadata\x12b\n" +
+	"\x12GetTableStatistics\x12*.api.metadata.v1.GetTableStatisticsRequest\x1a .api.metadata.v1.TableStatistics\x12a\n" +
+	"\x0eListPartitions\x12&.api.metadata.v1.ListPartitionsRequest\x1a'.api.metadata.v1.ListPartitionsResponse\x12U\n" +
+	"\n" +
+	"SyncSource\x12\".api.metadata.v1.SyncSourceRequest\x1a#.api.metadata.v1.SyncSourceResponseB Z\x1ego-metadata/api/metadata/v1;v1b\x06proto3"
+
+var (
+	file_catalog_proto_rawDescOnce sync.Once
+	file_catalog_proto_rawDescData []byte
+)
+
+func file_catalog_proto_rawDescGZIP() []byte {
+	file_catalog_proto_rawDescOnce.Do(func() {
+		file_catalog_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_catalog_proto_rawDesc), len(file_catalog_proto_rawDesc)))
+	})
+	return file_catalog_proto_rawDescData
+}
+
+var file_catalog_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
+var file_catalog_proto_goTypes = []any{
+	(*ListSourcesRequest)(nil),        // 0: api.metadata.v1.ListSourcesRequest
+	(*ListSourcesResponse)(nil),       // 1: api.metadata.v1.ListSourcesResponse
+	(*CatalogInfo)(nil),               // 2: api.metadata.v1.CatalogInfo
+	(*ListCatalogsRequest)(nil),       // 3: api.metadata.v1.ListCatalogsRequest
+	(*ListCatalogsResponse)(nil),      // 4: api.metadata.v1.ListCatalogsResponse
+	(*ListSchemasRequest)(nil),        // 5: api.metadata.v1.ListSchemasRequest
+	(*ListSchemasResponse)(nil),       // 6: api.metadata.v1.ListSchemasResponse
+	(*ListTablesRequest)(nil),         // 7: api.metadata.v1.ListTablesRequest
+	(*ListTablesResponse)(nil),        // 8: api.metadata.v1.ListTablesResponse
+	(*StreamTablesRequest)(nil),       // 9: api.metadata.v1.StreamTablesRequest
+	(*TableEntry)(nil),                // 10: api.metadata.v1.TableEntry
+	(*GetTableMetadataRequest)(nil),   // 11: api.metadata.v1.GetTableMetadataRequest
+	(*GetTableStatisticsRequest)(nil), // 12: api.metadata.v1.GetTableStatisticsRequest
+	(*ListPartitionsRequest)(nil),     // 13: api.metadata.v1.ListPartitionsRequest
+	(*ListPartitionsResponse)(nil),    // 14: api.metadata.v1.ListPartitionsResponse
+	(*SyncSourceRequest)(nil),         // 15: api.metadata.v1.SyncSourceRequest
+	(*SyncSourceResponse)(nil),        // 16: api.metadata.v1.SyncSourceResponse
+	(*TableMetadata)(nil),             // 17: api.metadata.v1.TableMetadata
+	(*Column)(nil),                    // 18: api.metadata.v1.Column
+	(*Index)(nil),                     // 19: api.metadata.v1.Index
+	(*PartitionInfo)(nil),             // 20: api.metadata.v1.PartitionInfo
+	(*StorageInfo)(nil),               // 21: api.metadata.v1.StorageInfo
+	(*TableStatistics)(nil),           // 22: api.metadata.v1.TableStatistics
+	(*ColumnStatistics)(nil),          // 23: api.metadata.v1.ColumnStatistics
+	nil,                               // 24: api.metadata.v1.CatalogInfo.PropertiesEntry
+	nil,                               // 25: api.metadata.v1.TableMetadata.PropertiesEntry
+	(*timestamppb.Timestamp)(nil),     // 26: google.protobuf.Timestamp
+}
+var file_catalog_proto_depIdxs = []int32{
+	24, // 0: api.metadata.v1.CatalogInfo.properties:type_name -> api.metadata.v1.CatalogInfo.PropertiesEntry
+	2,  // 1: api.metadata.v1.ListCatalogsResponse.catalogs:type_name -> api.metadata.v1.CatalogInfo
+	17, // 2: api.metadata.v1.TableEntry.metadata:type_name -> api.metadata.v1.TableMetadata
+	20, // 3: api.metadata.v1.ListPartitionsResponse.partitions:type_name -> api.metadata.v1.PartitionInfo
+	18, // 4: api.metadata.v1.TableMetadata.columns:type_name -> api.metadata.v1.Column
+	20, // 5: api.metadata.v1.TableMetadata.partitions:type_name -> api.metadata.v1.PartitionInfo
+	19, // 6: api.metadata.v1.TableMetadata.indexes:type_name -> api.metadata.v1.Index
+	21, // 7: api.metadata.v1.TableMetadata.storage:type_name -> api.metadata.v1.StorageInfo
+	25, // 8: api.metadata.v1.TableMetadata.properties:type_name -> api.metadata.v1.TableMetadata.PropertiesEntry
+	26, // 9: api.metadata.v1.TableMetadata.last_refreshed_at:type_name -> google.protobuf.Timestamp
+	23, // 10: api.metadata.v1.TableStatistics.column_stats:type_name -> api.metadata.v1.ColumnStatistics
+	26, // 11: api.metadata.v1.TableStatistics.collected_at:type_name -> google.protobuf.Timestamp
+	0,  // 12: api.metadata.v1.CatalogService.ListSources:input_type -> api.metadata.v1.ListSourcesRequest
+	3,  // 13: api.metadata.v1.CatalogService.ListCatalogs:input_type -> api.metadata.v1.ListCatalogsRequest
+	5,  // 14: api.metadata.v1.CatalogService.ListSchemas:input_type -> api.metadata.v1.ListSchemasRequest
+	7,  // 15: api.metadata.v1.CatalogService.ListTables:input_type -> api.metadata.v1.ListTablesRequest
+	9,  // 16: api.metadata.v1.CatalogService.StreamTables:input_type -> api.metadata.v1.StreamTablesRequest
+	11, // 17: api.metadata.v1.CatalogService.GetTableMetadata:input_type -> api.metadata.v1.GetTableMetadataRequest
+	12, // 18: api.metadata.v1.CatalogService.GetTableStatistics:input_type -> api.metadata.v1.GetTableStatisticsRequest
+	13, // 19: api.metadata.v1.CatalogService.ListPartitions:input_type -> api.metadata.v1.ListPartitionsRequest
+	15, // 20: api.metadata.v1.CatalogService.SyncSource:input_type -> api.metadata.v1.SyncSourceRequest
+	1,  // 21: api.metadata.v1.CatalogService.ListSources:output_type -> api.metadata.v1.ListSourcesResponse
+	4,  // 22: api.metadata.v1.CatalogService.ListCatalogs:output_type -> api.metadata.v1.ListCatalogsResponse
+	6,  // 23: api.metadata.v1.CatalogService.ListSchemas:output_type -> api.metadata.v1.ListSchemasResponse
+	8,  // 24: api.metadata.v1.CatalogService.ListTables:output_type -> api.metadata.v1.ListTablesResponse
+	10, // 25: api.metadata.v1.CatalogService.StreamTables:output_type -> api.metadata.v1.TableEntry
+	17, // 26: api.metadata.v1.CatalogService.GetTableMetadata:output_type -> api.metadata.v1.TableMetadata
+	22, // 27: api.metadata.v1.CatalogService.GetTableStatistics:output_type -> api.metadata.v1.TableStatistics
+	14, // 28: api.metadata.v1.CatalogService.ListPartitions:output_type -> api.metadata.v1.ListPartitionsResponse
+	16, // 29: api.metadata.v1.CatalogService.SyncSource:output_type -> api.metadata.v1.SyncSourceResponse
+	21, // [21:30] is the sub-list for method output_type
+	12, // [12:21] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_catalog_proto_init() }
+func file_catalog_proto_init() {
+	if File_catalog_proto != nil {
+		return
+	}
+	file_catalog_proto_msgTypes[18].OneofWrappers = []any{}
+	file_catalog_proto_msgTypes[23].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_catalog_proto_rawDesc), len(file_catalog_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   26,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_catalog_proto_goTypes,
+		DependencyIndexes: file_catalog_proto_depIdxs,
+		MessageInfos:      file_catalog_proto_msgTypes,
+	}.Build()
+	File_catalog_proto = out.File
+	file_catalog_proto_goTypes = nil
+	file_catalog_proto_depIdxs = nil
+}
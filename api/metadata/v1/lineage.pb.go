@@ -0,0 +1,651 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v6.33.2
+// source: lineage.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// 列引用
+type ColumnRef struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Database string                 `protobuf:"bytes,1,opt,name=database,proto3" json:"database,omitempty"`
+	Table    string                 `protobuf:"bytes,2,opt,name=table,proto3" json:"table,omitempty"`
+	Column   string                 `protobuf:"bytes,3,opt,name=column,proto3" json:"column,omitempty"`
+	// 血缘来源列的解析置信度: catalog, syntactic 或 guessed
+	Confidence    string `protobuf:"bytes,4,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ColumnRef) Reset() {
+	*x = ColumnRef{}
+	mi := &file_lineage_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ColumnRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ColumnRef) ProtoMessage() {}
+
+func (x *ColumnRef) ProtoReflect() protoreflect.Message {
+	mi := &file_lineage_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ColumnRef.ProtoReflect.Descriptor instead.
+func (*ColumnRef) Descriptor() ([]byte, []int) {
+	return file_lineage_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ColumnRef) GetDatabase() string {
+	if x != nil {
+		return x.Database
+	}
+	return ""
+}
+
+func (x *ColumnRef) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+func (x *ColumnRef) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+func (x *ColumnRef) GetConfidence() string {
+	if x != nil {
+		return x.Confidence
+	}
+	return ""
+}
+
+// 目标列的血缘
+type ColumnLineage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Target        *ColumnRef             `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	Sources       []*ColumnRef           `protobuf:"bytes,2,rep,name=sources,proto3" json:"sources,omitempty"`
+	Operators     []string               `protobuf:"bytes,3,rep,name=operators,proto3" json:"operators,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ColumnLineage) Reset() {
+	*x = ColumnLineage{}
+	mi := &file_lineage_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ColumnLineage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ColumnLineage) ProtoMessage() {}
+
+func (x *ColumnLineage) ProtoReflect() protoreflect.Message {
+	mi := &file_lineage_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ColumnLineage.ProtoReflect.Descriptor instead.
+func (*ColumnLineage) Descriptor() ([]byte, []int) {
+	return file_lineage_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ColumnLineage) GetTarget() *ColumnRef {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+func (x *ColumnLineage) GetSources() []*ColumnRef {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+func (x *ColumnLineage) GetOperators() []string {
+	if x != nil {
+		return x.Operators
+	}
+	return nil
+}
+
+// 无法解析的表或列引用
+type UnresolvedRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Database      string                 `protobuf:"bytes,1,opt,name=database,proto3" json:"database,omitempty"`
+	Table         string                 `protobuf:"bytes,2,opt,name=table,proto3" json:"table,omitempty"`
+	Column        string                 `protobuf:"bytes,3,opt,name=column,proto3" json:"column,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnresolvedRef) Reset() {
+	*x = UnresolvedRef{}
+	mi := &file_lineage_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnresolvedRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnresolvedRef) ProtoMessage() {}
+
+func (x *UnresolvedRef) ProtoReflect() protoreflect.Message {
+	mi := &file_lineage_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnresolvedRef.ProtoReflect.Descriptor instead.
+func (*UnresolvedRef) Descriptor() ([]byte, []int) {
+	return file_lineage_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UnresolvedRef) GetDatabase() string {
+	if x != nil {
+		return x.Database
+	}
+	return ""
+}
+
+func (x *UnresolvedRef) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+func (x *UnresolvedRef) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+func (x *UnresolvedRef) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// 解析 SQL 请求
+type AnalyzeSQLRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sql           string                 `protobuf:"bytes,1,opt,name=sql,proto3" json:"sql,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeSQLRequest) Reset() {
+	*x = AnalyzeSQLRequest{}
+	mi := &file_lineage_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeSQLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeSQLRequest) ProtoMessage() {}
+
+func (x *AnalyzeSQLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lineage_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeSQLRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeSQLRequest) Descriptor() ([]byte, []int) {
+	return file_lineage_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AnalyzeSQLRequest) GetSql() string {
+	if x != nil {
+		return x.Sql
+	}
+	return ""
+}
+
+// 记录 SQL 血缘请求
+type RecordSQLRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sql           string                 `protobuf:"bytes,1,opt,name=sql,proto3" json:"sql,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordSQLRequest) Reset() {
+	*x = RecordSQLRequest{}
+	mi := &file_lineage_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordSQLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordSQLRequest) ProtoMessage() {}
+
+func (x *RecordSQLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lineage_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordSQLRequest.ProtoReflect.Descriptor instead.
+func (*RecordSQLRequest) Descriptor() ([]byte, []int) {
+	return file_lineage_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RecordSQLRequest) GetSql() string {
+	if x != nil {
+		return x.Sql
+	}
+	return ""
+}
+
+// SQL 血缘解析结果
+type AnalyzeSQLResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Columns       []*ColumnLineage       `protobuf:"bytes,1,rep,name=columns,proto3" json:"columns,omitempty"`
+	Unresolved    []*UnresolvedRef       `protobuf:"bytes,2,rep,name=unresolved,proto3" json:"unresolved,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeSQLResponse) Reset() {
+	*x = AnalyzeSQLResponse{}
+	mi := &file_lineage_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeSQLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeSQLResponse) ProtoMessage() {}
+
+func (x *AnalyzeSQLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lineage_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeSQLResponse.ProtoReflect.Descriptor instead.
+func (*AnalyzeSQLResponse) Descriptor() ([]byte, []int) {
+	return file_lineage_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AnalyzeSQLResponse) GetColumns() []*ColumnLineage {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *AnalyzeSQLResponse) GetUnresolved() []*UnresolvedRef {
+	if x != nil {
+		return x.Unresolved
+	}
+	return nil
+}
+
+// 获取表血缘请求
+type GetTableLineageRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Database string                 `protobuf:"bytes,1,opt,name=database,proto3" json:"database,omitempty"`
+	Table    string                 `protobuf:"bytes,2,opt,name=table,proto3" json:"table,omitempty"`
+	// 血缘边的有效时间，默认当前时间
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTableLineageRequest) Reset() {
+	*x = GetTableLineageRequest{}
+	mi := &file_lineage_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTableLineageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTableLineageRequest) ProtoMessage() {}
+
+func (x *GetTableLineageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lineage_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTableLineageRequest.ProtoReflect.Descriptor instead.
+func (*GetTableLineageRequest) Descriptor() ([]byte, []int) {
+	return file_lineage_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetTableLineageRequest) GetDatabase() string {
+	if x != nil {
+		return x.Database
+	}
+	return ""
+}
+
+func (x *GetTableLineageRequest) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+func (x *GetTableLineageRequest) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+// 列级血缘边
+type LineageEdge struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        *ColumnRef             `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Target        *ColumnRef             `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	Operators     []string               `protobuf:"bytes,3,rep,name=operators,proto3" json:"operators,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LineageEdge) Reset() {
+	*x = LineageEdge{}
+	mi := &file_lineage_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LineageEdge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LineageEdge) ProtoMessage() {}
+
+func (x *LineageEdge) ProtoReflect() protoreflect.Message {
+	mi := &file_lineage_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LineageEdge.ProtoReflect.Descriptor instead.
+func (*LineageEdge) Descriptor() ([]byte, []int) {
+	return file_lineage_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LineageEdge) GetSource() *ColumnRef {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+func (x *LineageEdge) GetTarget() *ColumnRef {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+func (x *LineageEdge) GetOperators() []string {
+	if x != nil {
+		return x.Operators
+	}
+	return nil
+}
+
+// 获取表血缘响应
+type GetTableLineageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Edges         []*LineageEdge         `protobuf:"bytes,1,rep,name=edges,proto3" json:"edges,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTableLineageResponse) Reset() {
+	*x = GetTableLineageResponse{}
+	mi := &file_lineage_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTableLineageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTableLineageResponse) ProtoMessage() {}
+
+func (x *GetTableLineageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lineage_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTableLineageResponse.ProtoReflect.Descriptor instead.
+func (*GetTableLineageResponse) Descriptor() ([]byte, []int) {
+	return file_lineage_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetTableLineageResponse) GetEdges() []*LineageEdge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+var File_lineage_proto protoreflect.FileDescriptor
+
+const file_lineage_proto_rawDesc = "" +
+	"\n" +
+	"\rlineage.proto\x12\x0fapi.metadata.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"u\n" +
+	"\tColumnRef\x12\x1a\n" +
+	"\bdatabase\x18\x01 \x01(\tR\bdatabase\x12\x14\n" +
+	"\x05table\x18\x02 \x01(\tR\x05table\x12\x16\n" +
+	"\x06column\x18\x03 \x01(\tR\x06column\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x04 \x01(\tR\n" +
+	"confidence\"\x97\x01\n" +
+	"\rColumnLineage\x122\n" +
+	"\x06target\x18\x01 \x01(\v2\x1a.api.metadata.v1.ColumnRefR\x06target\x124\n" +
+	"\asources\x18\x02 \x03(\v2\x1a.api.metadata.v1.ColumnRefR\asources\x12\x1c\n" +
+	"\toperators\x18\x03 \x03(\tR\toperators\"q\n" +
+	"\rUnresolvedRef\x12\x1a\n" +
+	"\bdatabase\x18\x01 \x01(\tR\bdatabase\x12\x14\n" +
+	"\x05table\x18\x02 \x01(\tR\x05table\x12\x16\n" +
+	"\x06column\x18\x03 \x01(\tR\x06column\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"%\n" +
+	"\x11AnalyzeSQLRequest\x12\x10\n" +
+	"\x03sql\x18\x01 \x01(\tR\x03sql\"$\n" +
+	"\x10RecordSQLRequest\x12\x10\n" +
+	"\x03sql\x18\x01 \x01(\tR\x03sql\"\x8e\x01\n" +
+	"\x12AnalyzeSQLResponse\x128\n" +
+	"\acolumns\x18\x01 \x03(\v2\x1e.api.metadata.v1.ColumnLineageR\acolumns\x12>\n" +
+	"\n" +
+	"unresolved\x18\x02 \x03(\v2\x1e.api.metadata.v1.UnresolvedRefR\n" +
+	"unresolved\"{\n" +
+	"\x16GetTableLineageRequest\x12\x1a\n" +
+	"\bdatabase\x18\x01 \x01(\tR\bdatabase\x12\x14\n" +
+	"\x05table\x18\x02 \x01(\tR\x05table\x12/\n" +
+	"\x05as_of\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\"\x93\x01\n" +
+	"\vLineageEdge\x122\n" +
+	"\x06source\x18\x01 \x01(\v2\x1a.api.metadata.v1.ColumnRefR\x06source\x122\n" +
+	"\x06target\x18\x02 \x01(\v2\x1a.api.metadata.v1.ColumnRefR\x06target\x12\x1c\n" +
+	"\toperators\x18\x03 \x03(\tR\toperators\"M\n" +
+	"\x17GetTableLineageResponse\x122\n" +
+	"\x05edges\x18\x01 \x03(\v2\x1c.api.metadata.v1.LineageEdgeR\x05edges2\xa2\x02\n" +
+	"\x0eLineageService\x12U\n" +
+	"\n" +
+	"AnalyzeSQL\x12\".api.metadata.v1.AnalyzeSQLRequest\x1a#.api.metadata.v1.AnalyzeSQLResponse\x12S\n" +
+	"\tRecordSQL\x12!.api.metadata.v1.RecordSQLRequest\x1a#.api.metadata.v1.AnalyzeSQLResponse\x12d\n" +
+	"\x0fGetTableLineage\x12'.api.metadata.v1.GetTableLineageRequest\x1a(.api.metadata.v1.GetTableLineageResponseB Z\x1ego-metadata/api/metadata/v1;v1b\x06proto3"
+
+var (
+	file_lineage_proto_rawDescOnce sync.Once
+	file_lineage_proto_rawDescData []byte
+)
+
+func file_lineage_proto_rawDescGZIP() []byte {
+	file_lineage_proto_rawDescOnce.Do(func() {
+		file_lineage_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_lineage_proto_rawDesc), len(file_lineage_proto_rawDesc)))
+	})
+	return file_lineage_proto_rawDescData
+}
+
+var file_lineage_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_lineage_proto_goTypes = []any{
+	(*ColumnRef)(nil),               // 0: api.metadata.v1.ColumnRef
+	(*ColumnLineage)(nil),           // 1: api.metadata.v1.ColumnLineage
+	(*UnresolvedRef)(nil),           // 2: api.metadata.v1.UnresolvedRef
+	(*AnalyzeSQLRequest)(nil),       // 3: api.metadata.v1.AnalyzeSQLRequest
+	(*RecordSQLRequest)(nil),        // 4: api.metadata.v1.RecordSQLRequest
+	(*AnalyzeSQLResponse)(nil),      // 5: api.metadata.v1.AnalyzeSQLResponse
+	(*GetTableLineageRequest)(nil),  // 6: api.metadata.v1.GetTableLineageRequest
+	(*LineageEdge)(nil),             // 7: api.metadata.v1.LineageEdge
+	(*GetTableLineageResponse)(nil), // 8: api.metadata.v1.GetTableLineageResponse
+	(*timestamppb.Timestamp)(nil),   // 9: google.protobuf.Timestamp
+}
+var file_lineage_proto_depIdxs = []int32{
+	0,  // 0: api.metadata.v1.ColumnLineage.target:type_name -> api.metadata.v1.ColumnRef
+	0,  // 1: api.metadata.v1.ColumnLineage.sources:type_name -> api.metadata.v1.ColumnRef
+	1,  // 2: api.metadata.v1.AnalyzeSQLResponse.columns:type_name -> api.metadata.v1.ColumnLineage
+	2,  // 3: api.metadata.v1.AnalyzeSQLResponse.unresolved:type_name -> api.metadata.v1.UnresolvedRef
+	9,  // 4: api.metadata.v1.GetTableLineageRequest.as_of:type_name -> google.protobuf.Timestamp
+	0,  // 5: api.metadata.v1.LineageEdge.source:type_name -> api.metadata.v1.ColumnRef
+	0,  // 6: api.metadata.v1.LineageEdge.target:type_name -> api.metadata.v1.ColumnRef
+	7,  // 7: api.metadata.v1.GetTableLineageResponse.edges:type_name -> api.metadata.v1.LineageEdge
+	3,  // 8: api.metadata.v1.LineageService.AnalyzeSQL:input_type -> api.metadata.v1.AnalyzeSQLRequest
+	4,  // 9: api.metadata.v1.LineageService.RecordSQL:input_type -> api.metadata.v1.RecordSQLRequest
+	6,  // 10: api.metadata.v1.LineageService.GetTableLineage:input_type -> api.metadata.v1.GetTableLineageRequest
+	5,  // 11: api.metadata.v1.LineageService.AnalyzeSQL:output_type -> api.metadata.v1.AnalyzeSQLResponse
+	5,  // 12: api.metadata.v1.LineageService.RecordSQL:output_type -> api.metadata.v1.AnalyzeSQLResponse
+	8,  // 13: api.metadata.v1.LineageService.GetTableLineage:output_type -> api.metadata.v1.GetTableLineageResponse
+	11, // [11:14] is the sub-list for method output_type
+	8,  // [8:11] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_lineage_proto_init() }
+func file_lineage_proto_init() {
+	if File_lineage_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_lineage_proto_rawDesc), len(file_lineage_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_lineage_proto_goTypes,
+		DependencyIndexes: file_lineage_proto_depIdxs,
+		MessageInfos:      file_lineage_proto_msgTypes,
+	}.Build()
+	File_lineage_proto = out.File
+	file_lineage_proto_goTypes = nil
+	file_lineage_proto_depIdxs = nil
+}
@@ -0,0 +1,207 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.0
+// - protoc             v6.33.2
+// source: lineage.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	LineageService_AnalyzeSQL_FullMethodName      = "/api.metadata.v1.LineageService/AnalyzeSQL"
+	LineageService_RecordSQL_FullMethodName       = "/api.metadata.v1.LineageService/RecordSQL"
+	LineageService_GetTableLineage_FullMethodName = "/api.metadata.v1.LineageService/GetTableLineage"
+)
+
+// LineageServiceClient is the client API for LineageService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// LineageService 血缘解析服务 (仅 gRPC)
+type LineageServiceClient interface {
+	// 解析 SQL 的列级血缘，不写入血缘图
+	AnalyzeSQL(ctx context.Context, in *AnalyzeSQLRequest, opts ...grpc.CallOption) (*AnalyzeSQLResponse, error)
+	// 解析 SQL 并将血缘写入血缘图
+	RecordSQL(ctx context.Context, in *RecordSQLRequest, opts ...grpc.CallOption) (*AnalyzeSQLResponse, error)
+	// 获取表在指定时间有效的列级血缘边
+	GetTableLineage(ctx context.Context, in *GetTableLineageRequest, opts ...grpc.CallOption) (*GetTableLineageResponse, error)
+}
+
+type lineageServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLineageServiceClient(cc grpc.ClientConnInterface) LineageServiceClient {
+	return &lineageServiceClient{cc}
+}
+
+func (c *lineageServiceClient) AnalyzeSQL(ctx context.Context, in *AnalyzeSQLRequest, opts ...grpc.CallOption) (*AnalyzeSQLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnalyzeSQLResponse)
+	err := c.cc.Invoke(ctx, LineageService_AnalyzeSQL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lineageServiceClient) RecordSQL(ctx context.Context, in *RecordSQLRequest, opts ...grpc.CallOption) (*AnalyzeSQLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnalyzeSQLResponse)
+	err := c.cc.Invoke(ctx, LineageService_RecordSQL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lineageServiceClient) GetTableLineage(ctx context.Context, in *GetTableLineageRequest, opts ...grpc.CallOption) (*GetTableLineageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTableLineageResponse)
+	err := c.cc.Invoke(ctx, LineageService_GetTableLineage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LineageServiceServer is the server API for LineageService service.
+// All implementations must embed UnimplementedLineageServiceServer
+// for forward compatibility.
+//
+// LineageService 血缘解析服务 (仅 gRPC)
+type LineageServiceServer interface {
+	// 解析 SQL 的列级血缘，不写入血缘图
+	AnalyzeSQL(context.Context, *AnalyzeSQLRequest) (*AnalyzeSQLResponse, error)
+	// 解析 SQL 并将血缘写入血缘图
+	RecordSQL(context.Context, *RecordSQLRequest) (*AnalyzeSQLResponse, error)
+	// 获取表在指定时间有效的列级血缘边
+	GetTableLineage(context.Context, *GetTableLineageRequest) (*GetTableLineageResponse, error)
+	mustEmbedUnimplementedLineageServiceServer()
+}
+
+// UnimplementedLineageServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLineageServiceServer struct{}
+
+func (UnimplementedLineageServiceServer) AnalyzeSQL(context.Context, *AnalyzeSQLRequest) (*AnalyzeSQLResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AnalyzeSQL not implemented")
+}
+func (UnimplementedLineageServiceServer) RecordSQL(context.Context, *RecordSQLRequest) (*AnalyzeSQLResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RecordSQL not implemented")
+}
+func (UnimplementedLineageServiceServer) GetTableLineage(context.Context, *GetTableLineageRequest) (*GetTableLineageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTableLineage not implemented")
+}
+func (UnimplementedLineageServiceServer) mustEmbedUnimplementedLineageServiceServer() {}
+func (UnimplementedLineageServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeLineageServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LineageServiceServer will
+// result in compilation errors.
+type UnsafeLineageServiceServer interface {
+	mustEmbedUnimplementedLineageServiceServer()
+}
+
+func RegisterLineageServiceServer(s grpc.ServiceRegistrar, srv LineageServiceServer) {
+	// If the following call panics, it indicates UnimplementedLineageServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&LineageService_ServiceDesc, srv)
+}
+
+func _LineageService_AnalyzeSQL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyzeSQLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LineageServiceServer).AnalyzeSQL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LineageService_AnalyzeSQL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LineageServiceServer).AnalyzeSQL(ctx, req.(*AnalyzeSQLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LineageService_RecordSQL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordSQLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LineageServiceServer).RecordSQL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LineageService_RecordSQL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LineageServiceServer).RecordSQL(ctx, req.(*RecordSQLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LineageService_GetTableLineage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTableLineageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LineageServiceServer).GetTableLineage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LineageService_GetTableLineage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LineageServiceServer).GetTableLineage(ctx, req.(*GetTableLineageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LineageService_ServiceDesc is the grpc.ServiceDesc for LineageService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LineageService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "api.metadata.v1.LineageService",
+	HandlerType: (*LineageServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AnalyzeSQL",
+			Handler:    _LineageService_AnalyzeSQL_Handler,
+		},
+		{
+			MethodName: "RecordSQL",
+			Handler:    _LineageService_RecordSQL_Handler,
+		},
+		{
+			MethodName: "GetTableLineage",
+			Handler:    _LineageService_GetTableLineage_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "lineage.proto",
+}
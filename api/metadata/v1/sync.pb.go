@@ -0,0 +1,307 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v6.33.2
+// source: sync.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// 同步进度事件类型
+type SyncProgressEventType int32
+
+const (
+	SyncProgressEventType_SYNC_PROGRESS_EVENT_TYPE_UNSPECIFIED SyncProgressEventType = 0
+	SyncProgressEventType_SYNC_PROGRESS_EVENT_TYPE_STARTED     SyncProgressEventType = 1
+	SyncProgressEventType_SYNC_PROGRESS_EVENT_TYPE_TABLE       SyncProgressEventType = 2
+	SyncProgressEventType_SYNC_PROGRESS_EVENT_TYPE_FINISHED    SyncProgressEventType = 3
+)
+
+// Enum value maps for SyncProgressEventType.
+var (
+	SyncProgressEventType_name = map[int32]string{
+		0: "SYNC_PROGRESS_EVENT_TYPE_UNSPECIFIED",
+		1: "SYNC_PROGRESS_EVENT_TYPE_STARTED",
+		2: "SYNC_PROGRESS_EVENT_TYPE_TABLE",
+		3: "SYNC_PROGRESS_EVENT_TYPE_FINISHED",
+	}
+	SyncProgressEventType_value = map[string]int32{
+		"SYNC_PROGRESS_EVENT_TYPE_UNSPECIFIED": 0,
+		"SYNC_PROGRESS_EVENT_TYPE_STARTED":     1,
+		"SYNC_PROGRESS_EVENT_TYPE_TABLE":       2,
+		"SYNC_PROGRESS_EVENT_TYPE_FINISHED":    3,
+	}
+)
+
+func (x SyncProgressEventType) Enum() *SyncProgressEventType {
+	p := new(SyncProgressEventType)
+	*p = x
+	return p
+}
+
+func (x SyncProgressEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SyncProgressEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_sync_proto_enumTypes[0].Descriptor()
+}
+
+func (SyncProgressEventType) Type() protoreflect.EnumType {
+	return &file_sync_proto_enumTypes[0]
+}
+
+func (x SyncProgressEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SyncProgressEventType.Descriptor instead.
+func (SyncProgressEventType) EnumDescriptor() ([]byte, []int) {
+	return file_sync_proto_rawDescGZIP(), []int{0}
+}
+
+// 订阅同步进度请求
+type WatchSyncProgressRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExecutionId   string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchSyncProgressRequest) Reset() {
+	*x = WatchSyncProgressRequest{}
+	mi := &file_sync_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchSyncProgressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchSyncProgressRequest) ProtoMessage() {}
+
+func (x *WatchSyncProgressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sync_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchSyncProgressRequest.ProtoReflect.Descriptor instead.
+func (*WatchSyncProgressRequest) Descriptor() ([]byte, []int) {
+	return file_sync_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *WatchSyncProgressRequest) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+// 同步进度事件
+type SyncProgressEvent struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ExecutionId string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	Type        SyncProgressEventType  `protobuf:"varint,2,opt,name=type,proto3,enum=api.metadata.v1.SyncProgressEventType" json:"type,omitempty"`
+	// TABLE 事件对应的完整表名
+	Table string `protobuf:"bytes,3,opt,name=table,proto3" json:"table,omitempty"`
+	// 表同步失败或整个同步失败的原因
+	Error string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	// 累计成功、失败的表数量与表总数
+	Completed     int32                  `protobuf:"varint,5,opt,name=completed,proto3" json:"completed,omitempty"`
+	Failed        int32                  `protobuf:"varint,6,opt,name=failed,proto3" json:"failed,omitempty"`
+	Total         int32                  `protobuf:"varint,7,opt,name=total,proto3" json:"total,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncProgressEvent) Reset() {
+	*x = SyncProgressEvent{}
+	mi := &file_sync_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncProgressEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncProgressEvent) ProtoMessage() {}
+
+func (x *SyncProgressEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_sync_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncProgressEvent.ProtoReflect.Descriptor instead.
+func (*SyncProgressEvent) Descriptor() ([]byte, []int) {
+	return file_sync_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SyncProgressEvent) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+func (x *SyncProgressEvent) GetType() SyncProgressEventType {
+	if x != nil {
+		return x.Type
+	}
+	return SyncProgressEventType_SYNC_PROGRESS_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *SyncProgressEvent) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+func (x *SyncProgressEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *SyncProgressEvent) GetCompleted() int32 {
+	if x != nil {
+		return x.Completed
+	}
+	return 0
+}
+
+func (x *SyncProgressEvent) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *SyncProgressEvent) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *SyncProgressEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+var File_sync_proto protoreflect.FileDescriptor
+
+const file_sync_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"sync.proto\x12\x0fapi.metadata.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"=\n" +
+	"\x18WatchSyncProgressRequest\x12!\n" +
+	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\"\x9a\x02\n" +
+	"\x11SyncProgressEvent\x12!\n" +
+	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\x12:\n" +
+	"\x04type\x18\x02 \x01(\x0e2&.api.metadata.v1.SyncProgressEventTypeR\x04type\x12\x14\n" +
+	"\x05table\x18\x03 \x01(\tR\x05table\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x1c\n" +
+	"\tcompleted\x18\x05 \x01(\x05R\tcompleted\x12\x16\n" +
+	"\x06failed\x18\x06 \x01(\x05R\x06failed\x12\x14\n" +
+	"\x05total\x18\a \x01(\x05R\x05total\x12.\n" +
+	"\x04time\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\x04time*\xb2\x01\n" +
+	"\x15SyncProgressEventType\x12(\n" +
+	"$SYNC_PROGRESS_EVENT_TYPE_UNSPECIFIED\x10\x00\x12$\n" +
+	" SYNC_PROGRESS_EVENT_TYPE_STARTED\x10\x01\x12\"\n" +
+	"\x1eSYNC_PROGRESS_EVENT_TYPE_TABLE\x10\x02\x12%\n" +
+	"!SYNC_PROGRESS_EVENT_TYPE_FINISHED\x10\x032{\n" +
+	"\x13SyncProgressService\x12d\n" +
+	"\x11WatchSyncProgress\x12).api.metadata.v1.WatchSyncProgressRequest\x1a\".api.metadata.v1.SyncProgressEvent0\x01B Z\x1ego-metadata/api/metadata/v1;v1b\x06proto3"
+
+var (
+	file_sync_proto_rawDescOnce sync.Once
+	file_sync_proto_rawDescData []byte
+)
+
+func file_sync_proto_rawDescGZIP() []byte {
+	file_sync_proto_rawDescOnce.Do(func() {
+		file_sync_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sync_proto_rawDesc), len(file_sync_proto_rawDesc)))
+	})
+	return file_sync_proto_rawDescData
+}
+
+var file_sync_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_sync_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_sync_proto_goTypes = []any{
+	(SyncProgressEventType)(0),       // 0: api.metadata.v1.SyncProgressEventType
+	(*WatchSyncProgressRequest)(nil), // 1: api.metadata.v1.WatchSyncProgressRequest
+	(*SyncProgressEvent)(nil),        // 2: api.metadata.v1.SyncProgressEvent
+	(*timestamppb.Timestamp)(nil),    // 3: google.protobuf.Timestamp
+}
+var file_sync_proto_depIdxs = []int32{
+	0, // 0: api.metadata.v1.SyncProgressEvent.type:type_name -> api.metadata.v1.SyncProgressEventType
+	3, // 1: api.metadata.v1.SyncProgressEvent.time:type_name -> google.protobuf.Timestamp
+	1, // 2: api.metadata.v1.SyncProgressService.WatchSyncProgress:input_type -> api.metadata.v1.WatchSyncProgressRequest
+	2, // 3: api.metadata.v1.SyncProgressService.WatchSyncProgress:output_type -> api.metadata.v1.SyncProgressEvent
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_sync_proto_init() }
+func file_sync_proto_init() {
+	if File_sync_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sync_proto_rawDesc), len(file_sync_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_sync_proto_goTypes,
+		DependencyIndexes: file_sync_proto_depIdxs,
+		EnumInfos:         file_sync_proto_enumTypes,
+		MessageInfos:      file_sync_proto_msgTypes,
+	}.Build()
+	File_sync_proto = out.File
+	file_sync_proto_goTypes = nil
+	file_sync_proto_depIdxs = nil
+}
@@ -0,0 +1,130 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.0
+// - protoc             v6.33.2
+// source: sync.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SyncProgressService_WatchSyncProgress_FullMethodName = "/api.metadata.v1.SyncProgressService/WatchSyncProgress"
+)
+
+// SyncProgressServiceClient is the client API for SyncProgressService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SyncProgressService 同步进度服务 (仅 gRPC)
+type SyncProgressServiceClient interface {
+	// 订阅执行中同步的逐表进度，首个事件为当前进度，同步结束后关闭
+	WatchSyncProgress(ctx context.Context, in *WatchSyncProgressRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SyncProgressEvent], error)
+}
+
+type syncProgressServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSyncProgressServiceClient(cc grpc.ClientConnInterface) SyncProgressServiceClient {
+	return &syncProgressServiceClient{cc}
+}
+
+func (c *syncProgressServiceClient) WatchSyncProgress(ctx context.Context, in *WatchSyncProgressRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SyncProgressEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SyncProgressService_ServiceDesc.Streams[0], SyncProgressService_WatchSyncProgress_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchSyncProgressRequest, SyncProgressEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SyncProgressService_WatchSyncProgressClient = grpc.ServerStreamingClient[SyncProgressEvent]
+
+// SyncProgressServiceServer is the server API for SyncProgressService service.
+// All implementations must embed UnimplementedSyncProgressServiceServer
+// for forward compatibility.
+//
+// SyncProgressService 同步进度服务 (仅 gRPC)
+type SyncProgressServiceServer interface {
+	// 订阅执行中同步的逐表进度，首个事件为当前进度，同步结束后关闭
+	WatchSyncProgress(*WatchSyncProgressRequest, grpc.ServerStreamingServer[SyncProgressEvent]) error
+	mustEmbedUnimplementedSyncProgressServiceServer()
+}
+
+// UnimplementedSyncProgressServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSyncProgressServiceServer struct{}
+
+func (UnimplementedSyncProgressServiceServer) WatchSyncProgress(*WatchSyncProgressRequest, grpc.ServerStreamingServer[SyncProgressEvent]) error {
+	return status.Error(codes.Unimplemented, "method WatchSyncProgress not implemented")
+}
+func (UnimplementedSyncProgressServiceServer) mustEmbedUnimplementedSyncProgressServiceServer() {}
+func (UnimplementedSyncProgressServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeSyncProgressServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SyncProgressServiceServer will
+// result in compilation errors.
+type UnsafeSyncProgressServiceServer interface {
+	mustEmbedUnimplementedSyncProgressServiceServer()
+}
+
+func RegisterSyncProgressServiceServer(s grpc.ServiceRegistrar, srv SyncProgressServiceServer) {
+	// If the following call panics, it indicates UnimplementedSyncProgressServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SyncProgressService_ServiceDesc, srv)
+}
+
+func _SyncProgressService_WatchSyncProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchSyncProgressRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SyncProgressServiceServer).WatchSyncProgress(m, &grpc.GenericServerStream[WatchSyncProgressRequest, SyncProgressEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SyncProgressService_WatchSyncProgressServer = grpc.ServerStreamingServer[SyncProgressEvent]
+
+// SyncProgressService_ServiceDesc is the grpc.ServiceDesc for SyncProgressService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SyncProgressService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "api.metadata.v1.SyncProgressService",
+	HandlerType: (*SyncProgressServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchSyncProgress",
+			Handler:       _SyncProgressService_WatchSyncProgress_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sync.proto",
+}
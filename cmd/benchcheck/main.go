@@ -0,0 +1,175 @@
+// Package main provides benchcheck, which compares go test -bench output
+// against a baseline and fails when a benchmark exceeds its performance
+// budget. It is meant for CI:
+//
+//	go test -run '^$' -bench . -benchmem -count 5 ./... | tee bench.txt
+//	go run ./cmd/benchcheck -baseline docs/benchmarks/baseline.txt bench.txt
+//
+// Results of repeated runs (-count) are reduced to their median. Timings
+// depend on the machine, so the time budget can be disabled with -max-time 0
+// on runners that differ from the one the baseline was recorded on; the
+// allocation budgets hold anywhere.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// metrics are the reported units that are compared.
+var metrics = []string{"ns/op", "B/op", "allocs/op"}
+
+// benchLine matches a benchmark result line, capturing the name without the
+// GOMAXPROCS suffix and the measurements after the iteration count.
+var benchLine = regexp.MustCompile(`^(Benchmark\S+?)(?:-\d+)?\s+\d+\s+(.*)$`)
+
+func main() {
+	baselinePath := flag.String("baseline", "docs/benchmarks/baseline.txt", "baseline go test -bench output")
+	maxTime := flag.Float64("max-time", 0.30, "allowed ns/op regression ratio, 0 disables the check")
+	maxAllocs := flag.Float64("max-allocs", 0.10, "allowed B/op and allocs/op regression ratio, 0 disables the check")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: benchcheck [flags] [current-results]\n\nReads the current results from stdin when no file is given.\n\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	baseline, err := parseFile(*baselinePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	var current map[string]map[string]float64
+	if flag.NArg() > 0 {
+		current, err = parseFile(flag.Arg(0))
+	} else {
+		current, err = parse(os.Stdin)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	budgets := map[string]float64{"ns/op": *maxTime, "B/op": *maxAllocs, "allocs/op": *maxAllocs}
+	if !compare(os.Stdout, baseline, current, budgets) {
+		os.Exit(1)
+	}
+}
+
+// compare prints the change of every metric and reports whether all
+// benchmarks are within budget.
+func compare(w io.Writer, baseline, current map[string]map[string]float64, budgets map[string]float64) bool {
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	ok := true
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "benchmark\tmetric\tbaseline\tcurrent\tdelta\t")
+	for _, name := range names {
+		base, found := baseline[name]
+		if !found {
+			fmt.Fprintf(tw, "%s\t\t\t\tnew\t\n", name)
+			continue
+		}
+		for _, metric := range metrics {
+			old, hasOld := base[metric]
+			cur, hasCur := current[name][metric]
+			if !hasOld || !hasCur || old == 0 {
+				continue
+			}
+			delta := cur/old - 1
+			status := ""
+			if budget := budgets[metric]; budget > 0 && delta > budget {
+				status = fmt.Sprintf("over budget (+%.0f%%)", budget*100)
+				ok = false
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%+.1f%%\t%s\n", name, metric, format(old), format(cur), delta*100, status)
+		}
+	}
+	for name := range baseline {
+		if _, found := current[name]; !found {
+			fmt.Fprintf(tw, "%s\t\t\t\tmissing\t\n", name)
+		}
+	}
+	tw.Flush()
+	return ok
+}
+
+func parseFile(path string) (map[string]map[string]float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	results, err := parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return results, nil
+}
+
+// parse reads go test -bench output and returns the median of each metric
+// by benchmark name. Benchmarks of different packages must have distinct
+// names.
+func parse(r io.Reader) (map[string]map[string]float64, error) {
+	samples := make(map[string]map[string][]float64)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		m := benchLine.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if m == nil {
+			continue
+		}
+		name, fields := m[1], strings.Fields(m[2])
+		for i := 0; i+1 < len(fields); i += 2 {
+			if !slices.Contains(metrics, fields[i+1]) {
+				continue
+			}
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid %s value %q", name, fields[i+1], fields[i])
+			}
+			if samples[name] == nil {
+				samples[name] = make(map[string][]float64)
+			}
+			samples[name][fields[i+1]] = append(samples[name][fields[i+1]], v)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no benchmark results")
+	}
+
+	results := make(map[string]map[string]float64, len(samples))
+	for name, byMetric := range samples {
+		results[name] = make(map[string]float64, len(byMetric))
+		for metric, values := range byMetric {
+			results[name][metric] = median(values)
+		}
+	}
+	return results, nil
+}
+
+func median(values []float64) float64 {
+	slices.Sort(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+func format(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
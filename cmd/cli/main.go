@@ -2,13 +2,57 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/go-kratos/kratos/v2/log"
+	"gopkg.in/yaml.v3"
+
+	"go-metadata/internal/auth"
+	"go-metadata/internal/biz"
+	"go-metadata/internal/collector"
+	collectorConfig "go-metadata/internal/collector/config"
+	_ "go-metadata/internal/collector/drivers"
+	"go-metadata/internal/collector/factory"
+	lineageCore "go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/airflow"
+	"go-metadata/internal/lineage/metadata"
+	"go-metadata/internal/lineage/snapshot"
+	"go-metadata/internal/model"
+	"go-metadata/internal/redact"
+	"go-metadata/internal/render"
+	"go-metadata/internal/repl"
+	"go-metadata/internal/report"
+	"go-metadata/internal/search"
+	"go-metadata/internal/service"
 	lineageService "go-metadata/internal/service/lineage"
 	metadataService "go-metadata/internal/service/metadata"
+	"go-metadata/internal/store"
+	"go-metadata/internal/textfile"
+	"go-metadata/internal/urn"
 )
 
 const (
@@ -20,14 +64,233 @@ func main() {
 	// Define subcommands
 	analyzeCmd := flag.NewFlagSet("analyze", flag.ExitOnError)
 	analyzeSQL := analyzeCmd.String("sql", "", "SQL statement to analyze")
-	analyzeFile := analyzeCmd.String("file", "", "SQL file to analyze")
+	analyzeFile := analyzeCmd.String("file", "", "SQL file, glob or directory of .sql files to analyze, - for stdin")
+	analyzeVars := analyzeCmd.String("vars", "", "Template variables for dbt/Airflow SQL (key=value,...)")
+	analyzeComments := analyzeCmd.Bool("comments", false, "Print the SQL comments and their annotations (e.g. -- owner: team-x) with each statement")
+	analyzeScript := analyzeCmd.Bool("script", false, "Analyze each file as one script, following temporary tables, USE and SET across its statements")
+	analyzeOutput := analyzeCmd.String("output", lineageCore.FormatText, "Output format: text, json, yaml, table (a row per column), dot (Graphviz) or mermaid (flowchart)")
+	analyzeExplain := analyzeCmd.Bool("explain", false, "Print the parse tree of the statement (rule names, token spans) as JSON instead of its lineage")
+	analyzeStore := analyzeCmd.String("store", "", "SQLite file of metadata harvested by sync to resolve SELECT * and unqualified columns against")
 
 	syncCmd := flag.NewFlagSet("sync", flag.ExitOnError)
 	syncSource := syncCmd.String("source", "", "Data source name to sync")
+	syncConfig := syncCmd.String("config", "configs/config.yaml", "Config file whose sources or collectors section defines the data sources")
+	syncStore := syncCmd.String("store", "metadata.db", "SQLite file the harvested metadata is stored in")
+	syncIncremental := syncCmd.Bool("incremental", false, "Only refetch the tables whose fingerprint (DDL hash, update time, row count) changed since the last sync")
+	syncSQL := syncCmd.String("sql", "", "SQL file, glob or directory of .sql files whose lineage protects upstream tables dropped from the source from being pruned")
+	syncForce := syncCmd.Bool("force", false, "Prune tables dropped from the source even if others depend on them")
+
+	freshCmd := flag.NewFlagSet("freshness", flag.ExitOnError)
+	freshSource := freshCmd.String("source", "", "Data source name of the table")
+	freshTable := freshCmd.String("table", "", "Table to check (schema.table or catalog.schema.table)")
+	freshConfig := freshCmd.String("config", "configs/config.yaml", "Config file whose sources or collectors section defines the data sources")
+	freshCadence := freshCmd.Duration("cadence", 24*time.Hour, "Expected interval between loads of the table")
+	freshGrace := freshCmd.Duration("grace", 0, "Delay tolerated on top of the cadence")
+	freshColumn := freshCmd.String("column", "", "Date or timestamp column whose max statistic is checked instead of the latest partition")
+	freshTZ := freshCmd.String("tz", "UTC", "Time zone of partition values and timestamps without one")
+
+	compareCmd := flag.NewFlagSet("compare", flag.ExitOnError)
+	compareSource := compareCmd.String("source", "", "Data source of the schema to promote, e.g. staging")
+	compareTarget := compareCmd.String("target", "", "Data source to compare it with, e.g. prod (default -source)")
+	compareSchema := compareCmd.String("schema", "", "Schema to compare (schema or catalog.schema)")
+	compareTargetSchema := compareCmd.String("target-schema", "", "Schema of the target if named differently (default -schema)")
+	compareConfig := compareCmd.String("config", "configs/config.yaml", "Config file whose sources or collectors section defines the data sources")
+	compareJSON := compareCmd.Bool("json", false, "Print the differences as JSON")
+	compareMigration := compareCmd.String("migration", "", "Write a SQL script altering the target to match the source to this file")
+	compareDialect := compareCmd.String("dialect", "", "SQL dialect of the migration script, mysql or postgres (default the type of the target)")
+	compareDrop := compareCmd.Bool("drop", false, "Drop the tables, columns and indexes only in the target in the migration script instead of commenting the drops out")
+
+	driftCmd := flag.NewFlagSet("drift", flag.ExitOnError)
+	driftSource := driftCmd.String("source", "", "Data source whose schemas are checked")
+	driftConfig := driftCmd.String("config", "configs/config.yaml", "Config file whose sources or collectors section defines the data sources")
+	driftBaseline := driftCmd.String("baseline", "", "Committed baseline file of the schemas, e.g. schemas/mysql_prod.json")
+	driftSchemas := driftCmd.String("schemas", "", "Only check these schemas (comma-separated, schema or catalog.schema)")
+	driftOut := driftCmd.String("out", "", "Also write the current snapshot of the schemas to this file")
+	driftUpdate := driftCmd.Bool("update", false, "Rewrite the baseline with the current schemas instead of checking them")
+	driftJSON := driftCmd.Bool("json", false, "Print the differences as JSON")
+
+	searchCmd := flag.NewFlagSet("search", flag.ExitOnError)
+	searchStore := searchCmd.String("store", "metadata.db", "SQLite file the harvested metadata is stored in")
+	searchSourceType := searchCmd.String("source-type", "", "Only find tables of these source types (comma-separated, e.g. mysql,hive)")
+	searchSource := searchCmd.String("source", "", "Only find tables of these data sources (comma-separated)")
+	searchLimit := searchCmd.Int("limit", search.DefaultLimit, "Maximum number of tables to show")
+	searchJSON := searchCmd.Bool("json", false, "Print the results as JSON")
+
+	describeCmd := flag.NewFlagSet("describe", flag.ExitOnError)
+	describeStore := describeCmd.String("store", "metadata.db", "SQLite file the harvested metadata is stored in")
+	describeSource := describeCmd.String("source", "", "Data source of the table, if several have one of that name")
+	describeJSON := describeCmd.Bool("json", false, "Print the table as JSON")
+	describeLang := describeCmd.String("lang", "", "Language to show the comments in, e.g. en; comments without a description in it are shown as written")
+
+	subscribeCmd := flag.NewFlagSet("subscribe", flag.ExitOnError)
+	subscribeStore := subscribeCmd.String("store", "metadata.db", "SQLite file the harvested metadata is stored in")
+	subscribeUser := subscribeCmd.String("user", os.Getenv("USER"), "User to subscribe")
+	subscribeSource := subscribeCmd.String("source", "", "Data source of the watched tables")
+	subscribeTable := subscribeCmd.String("table", "", "Watched tables, e.g. shop.orders or shop.*; empty watches the whole source")
+	subscribeEvents := subscribeCmd.String("events", "", "Watched event types (comma-separated, e.g. schema_drift,sla_breach); empty watches all")
+	subscribeChannel := subscribeCmd.String("channel", "", "Set the channel the user is notified in: slack, teams, dingtalk or webhook")
+	subscribeWebhook := subscribeCmd.String("webhook", "", "Webhook URL of the -channel")
+	subscribeRemove := subscribeCmd.Bool("remove", false, "Remove the subscription, or the -channel")
+	subscribeList := subscribeCmd.Bool("list", false, "List the subscriptions and channels of the user")
 
 	listCmd := flag.NewFlagSet("list", flag.ExitOnError)
 	listDatabase := listCmd.String("database", "", "Database name")
 
+	reportCmd := flag.NewFlagSet("report", flag.ExitOnError)
+	reportOut := reportCmd.String("out", "./site", "Output directory of the generated site")
+	reportTitle := reportCmd.String("title", "", "Site title")
+	reportDDL := reportCmd.String("ddl", "", "DDL file describing the tables")
+	reportSchema := reportCmd.String("schema", "", "JSON schema file describing the tables")
+	reportSQL := reportCmd.String("sql", "", "SQL file, glob or directory of .sql files to derive lineage from")
+	reportVars := reportCmd.String("vars", "", "Template variables for dbt/Airflow SQL (key=value,...)")
+	reportDocs := reportCmd.String("docs", "", "Directory of table READMEs, e.g. dw/orders.md for dw.orders")
+	reportURN := reportCmd.String("urn", "", "URN scheme shown on the table pages: datahub, openlineage, native or a template such as {{.Platform}}:{{.Name}}")
+	reportPlatform := reportCmd.String("platform", "", "Platform of the tables in URNs, e.g. mysql or hive")
+	reportInstance := reportCmd.String("instance", "", "Instance of the tables in URNs, e.g. db.internal:3306")
+	reportEnv := reportCmd.String("env", "", "DataHub environment of the tables in URNs (default PROD)")
+	reportStore := reportCmd.String("store", "", "SQLite file of synced metadata to report the schema change rates of the sources from")
+	reportSince := reportCmd.Duration("since", service.DefaultChangeWindow, "With -store, period to report the schema change rates over")
+
+	storageCmd := flag.NewFlagSet("report storage", flag.ExitOnError)
+	storageServer := storageCmd.String("server", "http://127.0.0.1:8000", "Metadata server URL")
+	storageGroupBy := storageCmd.String("group-by", biz.GroupBySchema, "Roll up per schema or source")
+	storageInterval := storageCmd.String("interval", "", "Report one roll-up per day, week or month instead of the current sizes")
+	storageFrom := storageCmd.String("from", "", "Start date of the report (2006-01-02), default the first snapshot")
+	storageTo := storageCmd.String("to", "", "End date of the report (2006-01-02), default now")
+	storageCSV := storageCmd.String("csv", "", "Write the roll-ups as CSV to this file instead of printing them")
+
+	viewCmd := flag.NewFlagSet("lineage view", flag.ExitOnError)
+	viewAddr := viewCmd.String("addr", "127.0.0.1:0", "Address of the temporary viewer server")
+	viewDepth := viewCmd.Int("depth", 0, "Initial traversal depth (0 means unlimited)")
+	viewDDL := viewCmd.String("ddl", "", "DDL file describing the tables")
+	viewSchema := viewCmd.String("schema", "", "JSON schema file describing the tables")
+	viewSQL := viewCmd.String("sql", "", "SQL file, glob or directory of .sql files to derive lineage from")
+	viewVars := viewCmd.String("vars", "", "Template variables for dbt/Airflow SQL (key=value,...)")
+
+	tagsCmd := flag.NewFlagSet("lineage tags", flag.ExitOnError)
+	tagsRules := tagsCmd.String("rules", "", "YAML file of tag propagation rules")
+	tagsDDL := tagsCmd.String("ddl", "", "DDL file describing the tables, tagged with @tag, @pii and @column annotations")
+	tagsSchema := tagsCmd.String("schema", "", "JSON schema file describing the tables")
+	tagsSQL := tagsCmd.String("sql", "", "SQL file, glob or directory of .sql files to derive lineage from")
+	tagsVars := tagsCmd.String("vars", "", "Template variables for dbt/Airflow SQL (key=value,...)")
+
+	qlCmd := flag.NewFlagSet("lineage querylog", flag.ExitOnError)
+	qlSource := qlCmd.String("source", "", "RDBMS data source whose query log (pg_stat_statements, MySQL general log table) is read")
+	qlConfig := qlCmd.String("config", "configs/config.yaml", "Config file whose sources or collectors section defines the data sources")
+	qlSince := qlCmd.Duration("since", 24*time.Hour, "Read the statements executed within this duration, for logs that record when")
+	qlFollow := qlCmd.Bool("follow", false, "Keep reading the statements executed since the previous read every -interval until interrupted")
+	qlInterval := qlCmd.Duration("interval", time.Minute, "Interval between reads with -follow")
+	qlTop := qlCmd.Int("top", 20, "Number of edges to show, most executed first (0 for all)")
+	qlJSON := qlCmd.Bool("json", false, "Print the summary and edges as JSON")
+	qlDDL := qlCmd.String("ddl", "", "DDL file describing the tables")
+	qlSchema := qlCmd.String("schema", "", "JSON schema file describing the tables")
+
+	afCmd := flag.NewFlagSet("lineage airflow", flag.ExitOnError)
+	afURL := afCmd.String("url", "", "Base URL of the Airflow webserver, e.g. http://airflow:8080")
+	afUser := afCmd.String("user", "", "Airflow user, for the basic auth backend")
+	afPassword := afCmd.String("password", os.Getenv("AIRFLOW_PASSWORD"), "Airflow password (default $AIRFLOW_PASSWORD)")
+	afToken := afCmd.String("token", os.Getenv("AIRFLOW_TOKEN"), "Bearer token sent instead of basic auth (default $AIRFLOW_TOKEN)")
+	afDAGs := afCmd.String("dags", "", "Comma-separated DAG IDs to import (default all)")
+	afJSON := afCmd.Bool("json", false, "Print the summary and jobs as JSON")
+	afDDL := afCmd.String("ddl", "", "DDL file describing the tables")
+	afSchema := afCmd.String("schema", "", "JSON schema file describing the tables")
+
+	rcCmd := flag.NewFlagSet("lineage rootcause", flag.ExitOnError)
+	rcStore := rcCmd.String("store", "metadata.db", "SQLite file the harvested metadata, schema changes and failed syncs are stored in")
+	rcSince := rcCmd.Duration("since", 24*time.Hour, "Window of the incident before -until")
+	rcUntil := rcCmd.String("until", "", "End of the window, when the table was found broken (RFC 3339, default now)")
+	rcDepth := rcCmd.Int("depth", 0, "Upstream traversal depth (0 means unlimited)")
+	rcCadence := rcCmd.Duration("cadence", 24*time.Hour, "Expected interval between loads of the upstream tables (0 skips freshness)")
+	rcGrace := rcCmd.Duration("grace", 0, "Delay tolerated on top of the cadence")
+	rcTop := rcCmd.Int("top", 10, "Number of causes to show (0 for all)")
+	rcJSON := rcCmd.Bool("json", false, "Print the causes as JSON")
+	rcDDL := rcCmd.String("ddl", "", "DDL file describing the tables")
+	rcSchema := rcCmd.String("schema", "", "JSON schema file describing the tables")
+	rcSQL := rcCmd.String("sql", "", "SQL file, glob or directory of .sql files to derive lineage from")
+	rcVars := rcCmd.String("vars", "", "Template variables for dbt/Airflow SQL (key=value,...)")
+
+	replCmd := flag.NewFlagSet("repl", flag.ExitOnError)
+	replStore := replCmd.String("store", "metadata.db", "SQLite file of the harvested metadata whose tables are resolved and completed, if it exists")
+	replDDL := replCmd.String("ddl", "", "DDL file describing the tables")
+	replSchema := replCmd.String("schema", "", "JSON schema file describing the tables")
+	replVars := replCmd.String("vars", "", "Template variables for dbt/Airflow SQL (key=value,...)")
+	replDialect := replCmd.String("dialect", "generic", "SQL dialect: "+strings.Join(repl.Dialects, ", "))
+	replOutput := replCmd.String("output", lineageCore.FormatText, "Output format: text, json, dot or mermaid")
+
+	exportCmd := flag.NewFlagSet("snapshot export", flag.ExitOnError)
+	exportOut := exportCmd.String("out", "snapshot.tar", "Output snapshot file")
+	exportOrigin := exportCmd.String("origin", "", "Name of the exporting deployment")
+	exportDatabases := exportCmd.String("databases", "", "Comma-separated databases to limit the export to")
+	exportDDL := exportCmd.String("ddl", "", "DDL file describing the tables")
+	exportSchema := exportCmd.String("schema", "", "JSON schema file describing the tables")
+	exportSQL := exportCmd.String("sql", "", "SQL file, glob or directory of .sql files to derive lineage from")
+	exportVars := exportCmd.String("vars", "", "Template variables for dbt/Airflow SQL (key=value,...)")
+	exportAnonymize := exportCmd.Bool("anonymize", false, "Replace database, table, column and job names with hashes and strip comments, keeping structure and lineage")
+	exportSalt := exportCmd.String("salt", "", "Secret key of the -anonymize hashes; exports with the same salt use the same names (default: random)")
+
+	importCmd := flag.NewFlagSet("snapshot import", flag.ExitOnError)
+	importSchemaOut := importCmd.String("schema-out", "", "Write the imported tables as a JSON schema file")
+
+	backupCmd := flag.NewFlagSet("backup", flag.ExitOnError)
+	backupDir := backupCmd.String("dir", "./backups", "Directory backups are written to")
+	backupKeep := backupCmd.Int("keep", 7, "Number of most recent backups to keep (0 keeps all)")
+	backupInterval := backupCmd.Duration("interval", 0, "Run as a daemon taking a backup at this interval (e.g. 24h)")
+	backupOrigin := backupCmd.String("origin", "", "Name of this deployment")
+	backupDDL := backupCmd.String("ddl", "", "DDL file describing the tables")
+	backupSchema := backupCmd.String("schema", "", "JSON schema file describing the tables")
+	backupSQL := backupCmd.String("sql", "", "SQL file, glob or directory of .sql files to derive lineage from")
+	backupVars := backupCmd.String("vars", "", "Template variables for dbt/Airflow SQL (key=value,...)")
+
+	restoreCmd := flag.NewFlagSet("restore", flag.ExitOnError)
+	restoreSchemaOut := restoreCmd.String("schema-out", "", "Write the restored tables as a JSON schema file")
+
+	applyCmd := flag.NewFlagSet("apply", flag.ExitOnError)
+	applyFile := applyCmd.String("f", "", "Change file (.yaml, .yml, .json or .csv), or SQL files with @owner/@tag comment annotations (.sql, glob or directory)")
+	applyDatabase := applyCmd.String("database", "", "Database of the tables of SQL files that are not qualified")
+	applyServer := applyCmd.String("server", "http://127.0.0.1:8000", "Metadata server URL")
+	applyDryRun := applyCmd.Bool("dry-run", false, "Show the plan without applying it")
+	applyYes := applyCmd.Bool("yes", false, "Apply without asking for confirmation")
+
+	policyPushCmd := flag.NewFlagSet("policy push", flag.ExitOnError)
+	policyPushFile := policyPushCmd.String("f", "", "Policy file (.yaml or .yml)")
+	policyPushServer := policyPushCmd.String("server", "http://127.0.0.1:8000", "Metadata server URL")
+
+	policyCheckCmd := flag.NewFlagSet("policy check", flag.ExitOnError)
+	policyCheckServer := policyCheckCmd.String("server", "http://127.0.0.1:8000", "Metadata server URL")
+	policyCheckFailOn := policyCheckCmd.String("fail-on", biz.SeverityError, "Lowest severity that fails the check (error or warning)")
+
+	usageCmd := flag.NewFlagSet("usage", flag.ExitOnError)
+	usageTable := usageCmd.String("table", "", "Only report the columns of this table (table or db.table)")
+	usageUnused := usageCmd.Bool("unused", false, "Report the catalog columns no query used instead")
+	usageSince := usageCmd.Duration("since", 0, "With -unused, also report columns not used within this period (e.g. 720h)")
+	usageDDL := usageCmd.String("ddl", "", "DDL file describing the tables")
+	usageSchema := usageCmd.String("schema", "", "JSON schema file describing the tables")
+	usageSQL := usageCmd.String("sql", "", "Query log file, glob or directory of .sql files; each file counts as executed at its modification time")
+
+	relCmd := flag.NewFlagSet("relationships", flag.ExitOnError)
+	relTable := relCmd.String("table", "", "Only report the relationships of this table (table or db.table)")
+	relMin := relCmd.Int64("min", 2, "Minimum number of SQL files joining two tables for a suggested relationship")
+	relERD := relCmd.String("erd", "", "Write a Mermaid ER diagram of the tables and relationships to this file")
+	relDDL := relCmd.String("ddl", "", "DDL file describing the tables and their foreign keys")
+	relSchema := relCmd.String("schema", "", "JSON schema file describing the tables and their foreign keys")
+	relSQL := relCmd.String("sql", "", "SQL file, glob or directory of .sql files to infer relationships from")
+	relVars := relCmd.String("vars", "", "Template variables for dbt/Airflow SQL (key=value,...)")
+
+	dupCmd := flag.NewFlagSet("duplicates", flag.ExitOnError)
+	dupServer := dupCmd.String("server", "http://127.0.0.1:8000", "Metadata server URL")
+	dupThreshold := dupCmd.Float64("threshold", 0.8, "Minimum column similarity of two tables, between 0 and 1")
+	dupMinColumns := dupCmd.Int("min-columns", 3, "Skip tables with fewer columns")
+	dupCrossSource := dupCmd.Bool("cross-source", false, "Only compare tables of different data sources")
+
+	secretsMigrateCmd := flag.NewFlagSet("secrets migrate", flag.ExitOnError)
+	secretsMigrateConfig := secretsMigrateCmd.String("config", "configs/config.yaml", "Config file whose credentials are encrypted in place; its encryption section configures the keys")
+	secretsMigrateServer := secretsMigrateCmd.String("server", "", "Also migrate the credentials stored by this metadata server")
+	secretsMigrateDryRun := secretsMigrateCmd.Bool("dry-run", false, "Only count the credentials of the config file to migrate")
+
+	// The output format flags are global and may be given anywhere
+	args, format := render.Flags(os.Args[1:])
+	os.Args = append(os.Args[:1], args...)
+
 	// Check for subcommand
 	if len(os.Args) < 2 {
 		printUsage()
@@ -36,24 +299,235 @@ func main() {
 
 	// Initialize services
 	metaSvc := metadataService.NewService(nil)
-	lineageSvc := lineageService.NewService(nil, nil)
+	analyzer := lineageCore.NewAnalyzer(nil)
+	analyzer.RegisterSnowflake(nil)
+	lineageSvc := lineageService.NewService(analyzer, nil)
 
 	ctx := context.Background()
 
 	switch os.Args[1] {
 	case "analyze":
 		analyzeCmd.Parse(os.Args[2:])
-		runAnalyze(ctx, lineageSvc, *analyzeSQL, *analyzeFile)
+		analyzer.SetTemplateResolver(parseTemplateVars(*analyzeVars))
+		analyzer.SetPreserveComments(*analyzeComments)
+		if *analyzeStore != "" {
+			st, err := store.Open(ctx, &store.Config{Driver: store.DriverSQLite, DSN: *analyzeStore})
+			if err != nil {
+				fmt.Printf("Error opening store %s: %v\n", *analyzeStore, redact.Error(err))
+				os.Exit(1)
+			}
+			defer st.Close()
+			analyzer.SetCatalog(metadata.NewStoreCatalog(st))
+		}
+		if *analyzeExplain {
+			runExplain(ctx, lineageSvc, *analyzeSQL, *analyzeFile, format)
+			return
+		}
+		if format.Structured() {
+			// Graphs have no structured form: refuse rather than drop one of the flags
+			if *analyzeOutput == lineageCore.FormatDOT || *analyzeOutput == lineageCore.FormatMermaid {
+				fmt.Printf("Error: --%s cannot be combined with -output %s\n", format, *analyzeOutput)
+				analyzeCmd.Usage()
+				os.Exit(2)
+			}
+			*analyzeOutput = string(format)
+		}
+		runAnalyze(ctx, lineageSvc, *analyzeSQL, *analyzeFile, *analyzeScript, *analyzeOutput)
 
 	case "sync":
 		syncCmd.Parse(os.Args[2:])
-		runSync(ctx, metaSvc, *syncSource)
+		runSync(ctx, metaSvc, *syncSource, *syncConfig, *syncStore, *syncSQL, metadataService.SyncOptions{Incremental: *syncIncremental, Force: *syncForce}, format)
+
+	case "freshness":
+		freshCmd.Parse(os.Args[2:])
+		runFreshness(ctx, metaSvc, *freshSource, *freshTable, *freshConfig, *freshColumn, *freshTZ, *freshCadence, *freshGrace, format)
+
+	case "compare":
+		compareCmd.Parse(os.Args[2:])
+		runCompare(ctx, metaSvc, *compareSource, *compareTarget, *compareSchema, *compareTargetSchema, *compareConfig, jsonFormat(*compareJSON, format), *compareMigration, *compareDialect, *compareDrop)
+
+	case "drift":
+		driftCmd.Parse(os.Args[2:])
+		runDrift(ctx, metaSvc, *driftSource, *driftConfig, *driftBaseline, *driftSchemas, *driftOut, *driftUpdate, jsonFormat(*driftJSON, format))
+
+	case "search":
+		searchCmd.Parse(os.Args[2:])
+		runSearch(ctx, *searchStore, strings.Join(searchCmd.Args(), " "), *searchSourceType, *searchSource, *searchLimit, jsonFormat(*searchJSON, format))
+
+	case "describe":
+		describeCmd.Parse(os.Args[2:])
+		runDescribe(ctx, *describeStore, *describeSource, describeCmd.Arg(0), *describeLang, jsonFormat(*describeJSON, format))
+
+	case "subscribe":
+		subscribeCmd.Parse(os.Args[2:])
+		sub := &store.Subscription{User: *subscribeUser, Source: *subscribeSource, Table: *subscribeTable}
+		if *subscribeEvents != "" {
+			sub.Events = strings.Split(*subscribeEvents, ",")
+		}
+		runSubscribe(ctx, *subscribeStore, sub, *subscribeChannel, *subscribeWebhook, *subscribeRemove, *subscribeList, format)
 
 	case "list":
 		listCmd.Parse(os.Args[2:])
-		runList(ctx, metaSvc, *listDatabase)
+		runList(ctx, metaSvc, *listDatabase, format)
+
+	case "report":
+		if len(os.Args) > 2 && os.Args[2] == "storage" {
+			storageCmd.Parse(os.Args[3:])
+			runStorageReport(ctx, *storageServer, *storageGroupBy, *storageInterval, *storageFrom, *storageTo, *storageCSV, format)
+			break
+		}
+		reportCmd.Parse(os.Args[2:])
+		urns := &urn.Config{Scheme: *reportURN, Platform: *reportPlatform, Instance: *reportInstance, Env: *reportEnv}
+		runReport(ctx, *reportOut, *reportTitle, *reportDDL, *reportSchema, *reportSQL, *reportVars, *reportDocs, urns, *reportStore, *reportSince)
+
+	case "lineage":
+		if len(os.Args) >= 3 && os.Args[2] == "tags" {
+			tagsCmd.Parse(os.Args[3:])
+			runLineageTags(*tagsRules, *tagsDDL, *tagsSchema, *tagsSQL, *tagsVars, format)
+			return
+		}
+		if len(os.Args) >= 3 && os.Args[2] == "querylog" {
+			qlCmd.Parse(os.Args[3:])
+			runQueryLog(ctx, metaSvc, *qlSource, *qlConfig, *qlSince, *qlFollow, *qlInterval, *qlTop, *qlDDL, *qlSchema, jsonFormat(*qlJSON, format))
+			return
+		}
+		if len(os.Args) >= 3 && os.Args[2] == "airflow" {
+			afCmd.Parse(os.Args[3:])
+			cfg := &airflow.Config{URL: *afURL, Username: *afUser, Password: *afPassword, Token: *afToken}
+			runAirflow(ctx, cfg, *afDAGs, *afDDL, *afSchema, jsonFormat(*afJSON, format))
+			return
+		}
+		if len(os.Args) < 3 || os.Args[2] != "view" && os.Args[2] != "rootcause" {
+			fmt.Println("Usage: lineage view <db.table> [options] | lineage rootcause <db.table> [options] | lineage tags -rules <file> [options] | lineage querylog -source <name> [options] | lineage airflow -url <url> [options]")
+			os.Exit(1)
+		}
+		// Accept the table before or after the options.
+		args, table := os.Args[3:], ""
+		if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+			table, args = args[0], args[1:]
+		}
+		if os.Args[2] == "rootcause" {
+			rcCmd.Parse(args)
+			if table == "" {
+				table = rcCmd.Arg(0)
+			}
+			opts := metadataService.RootCauseOptions{
+				Freshness: collector.FreshnessOptions{Cadence: *rcCadence, Grace: *rcGrace},
+			}
+			runRootCause(ctx, table, *rcStore, *rcUntil, *rcSince, *rcDepth, *rcTop, opts, *rcDDL, *rcSchema, *rcSQL, *rcVars, jsonFormat(*rcJSON, format))
+			return
+		}
+		viewCmd.Parse(args)
+		if table == "" {
+			table = viewCmd.Arg(0)
+		}
+		runLineageView(table, *viewDepth, *viewAddr, *viewDDL, *viewSchema, *viewSQL, *viewVars)
+
+	case "repl":
+		replCmd.Parse(os.Args[2:])
+		runRepl(ctx, *replStore, *replDDL, *replSchema, *replVars, *replDialect, *replOutput)
+
+	case "usage":
+		usageCmd.Parse(os.Args[2:])
+		runUsage(ctx, *usageTable, *usageUnused, *usageSince, *usageDDL, *usageSchema, *usageSQL, format)
+
+	case "relationships":
+		relCmd.Parse(os.Args[2:])
+		runRelationships(*relTable, *relMin, *relERD, *relDDL, *relSchema, *relSQL, *relVars, format)
+
+	case "duplicates":
+		dupCmd.Parse(os.Args[2:])
+		runDuplicates(ctx, *dupServer, *dupThreshold, *dupMinColumns, *dupCrossSource, format)
+
+	case "snapshot":
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: snapshot export|import [options]")
+			os.Exit(1)
+		}
+		switch os.Args[2] {
+		case "export":
+			exportCmd.Parse(os.Args[3:])
+			runSnapshotExport(ctx, *exportOut, *exportOrigin, *exportDatabases, *exportDDL, *exportSchema, *exportSQL, *exportVars, *exportAnonymize, *exportSalt, format)
+		case "import":
+			args, path := os.Args[3:], ""
+			if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+				path, args = args[0], args[1:]
+			}
+			importCmd.Parse(args)
+			if path == "" {
+				path = importCmd.Arg(0)
+			}
+			runSnapshotImport(ctx, path, *importSchemaOut, format)
+		default:
+			fmt.Printf("Unknown snapshot command: %s\n", os.Args[2])
+			os.Exit(1)
+		}
+
+	case "backup":
+		backupCmd.Parse(os.Args[2:])
+		runBackup(ctx, &lineageService.BackupConfig{
+			Dir:      *backupDir,
+			Interval: *backupInterval,
+			Keep:     *backupKeep,
+			Origin:   *backupOrigin,
+		}, *backupDDL, *backupSchema, *backupSQL, *backupVars, format)
+
+	case "restore":
+		args, path := os.Args[2:], ""
+		if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+			path, args = args[0], args[1:]
+		}
+		restoreCmd.Parse(args)
+		if path == "" {
+			path = restoreCmd.Arg(0)
+		}
+		runRestore(ctx, path, *restoreSchemaOut, format)
+
+	case "apply":
+		applyCmd.Parse(os.Args[2:])
+		runApply(ctx, *applyFile, *applyDatabase, *applyServer, *applyDryRun, *applyYes, format)
+
+	case "policy":
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: policy push|check [options]")
+			os.Exit(1)
+		}
+		switch os.Args[2] {
+		case "push":
+			policyPushCmd.Parse(os.Args[3:])
+			runPolicyPush(ctx, *policyPushFile, *policyPushServer)
+		case "check":
+			policyCheckCmd.Parse(os.Args[3:])
+			runPolicyCheck(ctx, *policyCheckServer, *policyCheckFailOn, format)
+		default:
+			fmt.Printf("Unknown policy command: %s\n", os.Args[2])
+			os.Exit(1)
+		}
+
+	case "secrets":
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: secrets migrate|keygen [options]")
+			os.Exit(1)
+		}
+		switch os.Args[2] {
+		case "migrate":
+			secretsMigrateCmd.Parse(os.Args[3:])
+			runSecretsMigrate(ctx, *secretsMigrateConfig, *secretsMigrateServer, *secretsMigrateDryRun)
+		case "keygen":
+			runSecretsKeygen()
+		default:
+			fmt.Printf("Unknown secrets command: %s\n", os.Args[2])
+			os.Exit(1)
+		}
 
 	case "version":
+		if format.Structured() {
+			writeFormatted(format, struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			}{appName, appVersion})
+			break
+		}
 		fmt.Printf("%s version %s\n", appName, appVersion)
 
 	case "help":
@@ -75,84 +549,2774 @@ Usage:
 Commands:
   analyze   Analyze SQL statement for lineage
   sync      Synchronize metadata from data source
+  freshness Check that a table's latest partition or max timestamp is within its load cadence
+  compare   Compare a schema across two data sources, e.g. staging and prod, to plan a migration
+  drift     Fail if the schemas of a data source differ from a committed baseline (metadata as code)
+  search    Search the names, comments, tags and example values of synced tables and columns
+  describe  Show the columns of a synced table with their comments and example values
+  subscribe Watch tables to be notified of their changes only, in your own Slack, Teams, DingTalk or webhook channel
   list      List tables in a database
+  report    Generate a static HTML catalog and lineage site, or roll up storage (report storage)
+  lineage   View the lineage of a table in the browser (lineage view <db.table>), rank the likely causes of an
+            incident on it (lineage rootcause <db.table>), propagate tags (lineage tags), harvest lineage
+            from the query log of a MySQL or PostgreSQL source (lineage querylog), or import Airflow DAGs as
+            pipelines linked to the tables their tasks read and write (lineage airflow)
+  repl      Interactive prompt printing the lineage of SQL as it is typed, with table name completion
+  usage     Report column usage counts or unused columns from query logs
+  relationships  Show foreign keys and table relationships inferred from joins
+  duplicates     Find tables with similar columns, likely duplicated datasets
+  snapshot  Export or import a catalog and lineage snapshot bundle
+  backup    Back up the catalog and lineage graph, once or on a schedule
+  restore   Restore a backup file or the latest backup in a directory
+  apply     Apply declarative metadata edits from a YAML or CSV file
+  policy    Upload metadata policies or check the violations found by syncs
+  secrets   Encrypt plaintext credentials with the configured keys, or generate a key
   version   Show version information
   help      Show this help message
 
+Output options (any command printing results, anywhere on the command line):
+  --json    Print the results as JSON
+  --yaml    Print the results as YAML
+  --table   Print the results as an aligned table, a row per table, column or change
+
 Examples:
   %s analyze -sql "SELECT a.id, b.name FROM table_a a JOIN table_b b ON a.id = b.id"
   %s analyze -file query.sql
-  %s sync -source mysql_prod
+  %s analyze -file "exports/*.sql"
+  %s analyze -file models/orders.sql -comments
+  %s analyze -file dags/daily_sales.sql -script
+  %s analyze -file models/orders.sql -store metadata.db
+  %s analyze -file "models/*.sql" -output dot | dot -Tsvg > lineage.svg
+  %s analyze -sql "SELECT id FROM orders DISTRIBUTE BY id" -explain
+  %s analyze -file models/orders.sql -vars "ds=2024-06-01,is_incremental=false"
+  %s analyze -file "models/*.sql" --yaml
+  %s sync -source mysql_prod -config configs/config.yaml -store metadata.db
+  %s sync -source mysql_prod -incremental
+  %s sync -source mysql_prod -sql ./models
+  %s freshness -source hive-prod -table dw.orders -cadence 24h -grace 2h
+  %s compare -source mysql_staging -target mysql_prod -schema shop
+  %s compare -source mysql_staging -target mysql_prod -schema shop -migration shop.sql
+  %s drift -source mysql_prod -baseline schemas/mysql_prod.json
+  %s drift -source mysql_prod -baseline schemas/mysql_prod.json -update
+  %s drift -source mysql_prod -baseline schemas/mysql_prod.json --table
+  %s search -store metadata.db -source-type mysql,hive user order
+  %s describe -store metadata.db -lang en shop.orders
+  %s subscribe -user alice -source mysql_prod -table "shop.*" -events schema_drift,sla_breach
+  %s subscribe -user alice -channel slack -webhook https://hooks.slack.com/services/T000/B000/XXXX
   %s list -database mydb
+  %s list -database mydb --table
+  %s sync -source mysql_prod --json | jq .failed
+  %s report -out ./site -ddl schema.sql -sql ./models -docs ./docs
+  %s report -out ./site -ddl schema.sql -urn datahub -platform mysql
+  %s report -out ./site -ddl schema.sql -store metadata.db -since 2016h
+  %s report storage -group-by source -interval month -csv storage.csv
+  %s lineage view analytics.daily_sales -sql ./models
+  %s lineage rootcause analytics.daily_sales -sql ./models -store metadata.db -since 12h
+  %s lineage tags -rules tag-rules.yaml -ddl schema.sql -sql ./models
+  %s lineage querylog -source pg-prod -since 168h -ddl schema.sql
+  %s lineage airflow -url http://airflow:8080 -user admin -dags daily_sales -ddl schema.sql
+  %s repl -store metadata.db -dialect postgres
+  %s usage -ddl schema.sql -sql ./query_log -unused -since 2160h
+  %s relationships -ddl schema.sql -sql ./models -min 3 -erd erd.mmd
+  %s duplicates -cross-source -threshold 0.7
+  %s snapshot export -out prod.tar -databases dw,ods -ddl schema.sql -sql ./models
+  %s snapshot import prod.tar -schema-out tables.json
+  %s snapshot export -anonymize -out shared.tar -ddl schema.sql -sql ./models
+  %s backup -dir ./backups -keep 7 -interval 24h -ddl schema.sql -sql ./models
+  %s restore ./backups -schema-out tables.json
+  %s apply -f changes.yaml -server http://127.0.0.1:8000
+  %s apply -f ./models -database dw -dry-run
+  %s policy push -f policies.yaml
+  %s policy check -fail-on warning
+  %s secrets keygen
+  %s secrets migrate -config configs/config.yaml -server http://127.0.0.1:8000
 
-`, appName, appName, appName, appName, appName, appName)
+`, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName)
 }
 
-func runAnalyze(ctx context.Context, svc *lineageService.Service, sql, file string) {
+func runAnalyze(ctx context.Context, svc *lineageService.Service, sql, file string, script bool, output string) {
 	if sql == "" && file == "" {
 		fmt.Println("Error: either -sql or -file must be provided")
 		os.Exit(1)
 	}
+	switch output {
+	case lineageCore.FormatText, lineageCore.FormatJSON, lineageCore.FormatDOT, lineageCore.FormatMermaid, string(render.YAML), string(render.Table):
+	default:
+		fmt.Printf("Error: unknown -output %q, use text, json, yaml, table, dot or mermaid\n", output)
+		os.Exit(1)
+	}
+
+	if file == "" {
+		result, err := svc.AnalyzeSQL(ctx, sql)
+		if err != nil {
+			fmt.Printf("Error analyzing SQL: %v\n", err)
+			os.Exit(1)
+		}
+		switch output {
+		case lineageCore.FormatJSON:
+			writeLineageJSON(result)
+		case string(render.YAML):
+			writeFormatted(render.YAML, result)
+		default:
+			writeLineage(output, []analyzedStatement{{Result: result}})
+		}
+		return
+	}
+
+	// Scripts are analyzed a statement at a time as they are read, or whole
+	// with -script. Structured output is written once all are analyzed, and
+	// errors go to stderr to keep it parseable.
+	errOut := os.Stdout
+	if output != lineageCore.FormatText {
+		errOut = os.Stderr
+	}
+	files, err := textfile.Expand(file, ".sql")
+	if err != nil {
+		fmt.Printf("Error reading file: %v\n", err)
+		os.Exit(1)
+	}
+	var analyzed []analyzedStatement
+	failed := 0
+	for _, name := range files {
+		label := name
+		if name == textfile.Stdin {
+			label = "stdin"
+		}
+		if script {
+			data, err := readSQLFile(name)
+			if err != nil {
+				fmt.Fprintf(errOut, "Error reading file: %v\n", err)
+				os.Exit(1)
+			}
+			result, err := svc.AnalyzeSQL(ctx, data)
+			if err != nil {
+				fmt.Fprintf(errOut, "Error analyzing SQL of %s: %v\n", label, err)
+				failed++
+				continue
+			}
+			analyzed = append(analyzed, analyzedStatement{File: label, Result: result})
+			if output == lineageCore.FormatText {
+				fmt.Printf("== %s ==\n", label)
+				printLineage(result)
+			}
+			continue
+		}
+		n := 0
+		err := scanSQLFile(name, func(stmt string) error {
+			n++
+			result, err := svc.AnalyzeSQL(ctx, stmt)
+			if err != nil {
+				fmt.Fprintf(errOut, "Error analyzing SQL of %s #%d: %v\n", label, n, err)
+				failed++
+				return nil
+			}
+			analyzed = append(analyzed, analyzedStatement{File: label, Statement: n, Result: result})
+			if output == lineageCore.FormatText {
+				fmt.Printf("== %s #%d ==\n", label, n)
+				printLineage(result)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(errOut, "Error reading file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if output != lineageCore.FormatText {
+		writeLineage(output, analyzed)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
 
-	sqlContent := sql
-	if file != "" {
-		content, err := os.ReadFile(file)
+// runExplain prints the parse tree of a statement, given with -sql or as the
+// content of a single -file, as JSON, or as YAML with --yaml.
+func runExplain(ctx context.Context, svc *lineageService.Service, sql, file string, format render.Format) {
+	if sql == "" && file == "" {
+		fmt.Println("Error: either -sql or -file must be provided")
+		os.Exit(1)
+	}
+	if sql == "" {
+		files, err := textfile.Expand(file, ".sql")
 		if err != nil {
 			fmt.Printf("Error reading file: %v\n", err)
 			os.Exit(1)
 		}
-		sqlContent = string(content)
+		if len(files) != 1 {
+			fmt.Printf("Error: -explain takes a single file, %s matches %d\n", file, len(files))
+			os.Exit(1)
+		}
+		if sql, err = readSQLFile(files[0]); err != nil {
+			fmt.Printf("Error reading file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	tree, err := svc.ExplainSQL(ctx, sql)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error explaining SQL: %v\n", err)
+		os.Exit(1)
 	}
+	if format != render.YAML {
+		format = render.JSON
+	}
+	writeFormatted(format, tree)
+}
+
+// analyzedStatement is the lineage of a statement of a SQL file, or of a
+// whole file analyzed as a script (Statement 0).
+type analyzedStatement struct {
+	File      string                     `json:"file,omitempty"`
+	Statement int                        `json:"statement,omitempty"`
+	Result    *lineageCore.LineageResult `json:"lineage"`
+}
 
-	result, err := svc.AnalyzeSQL(ctx, sqlContent)
+// lineageRow is a column of a statement and its sources, a row of the table
+// output of analyze.
+type lineageRow struct {
+	File      string   `json:"file,omitempty"`
+	Statement int      `json:"statement,omitempty"`
+	Target    string   `json:"target"`
+	Sources   []string `json:"sources"`
+}
+
+// writeLineage writes the lineage of the analyzed statements in a structured
+// output format: a JSON or YAML array of the statements, a table of their
+// columns, or one DOT or Mermaid graph of the lineage of all of them. Text is
+// printed as the statements are analyzed.
+func writeLineage(output string, analyzed []analyzedStatement) {
+	var err error
+	switch output {
+	case lineageCore.FormatJSON, string(render.YAML):
+		if analyzed == nil {
+			analyzed = []analyzedStatement{}
+		}
+		err = render.Write(os.Stdout, render.Format(output), analyzed)
+	case string(render.Table):
+		rows := []lineageRow{}
+		for _, a := range analyzed {
+			for _, col := range a.Result.Columns {
+				row := lineageRow{File: a.File, Statement: a.Statement, Target: col.Target.QualifiedName()}
+				for _, src := range col.Sources {
+					row.Sources = append(row.Sources, src.QualifiedName())
+				}
+				rows = append(rows, row)
+			}
+		}
+		err = render.Write(os.Stdout, render.Table, rows)
+	case lineageCore.FormatDOT, lineageCore.FormatMermaid:
+		results := make([]*lineageCore.LineageResult, len(analyzed))
+		for i, a := range analyzed {
+			results[i] = a.Result
+		}
+		if output == lineageCore.FormatDOT {
+			err = lineageCore.WriteDOT(os.Stdout, results...)
+		} else {
+			err = lineageCore.WriteMermaid(os.Stdout, results...)
+		}
+	default:
+		for _, a := range analyzed {
+			printLineage(a.Result)
+		}
+	}
 	if err != nil {
-		fmt.Printf("Error analyzing SQL: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error writing lineage: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// writeLineageJSON writes the lineage of a single statement as a JSON object.
+func writeLineageJSON(result *lineageCore.LineageResult) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing lineage: %v\n", err)
 		os.Exit(1)
 	}
+}
 
+// printLineage prints the column lineage and unresolved references of a
+// statement.
+func printLineage(result *lineageCore.LineageResult) {
 	if result == nil {
 		fmt.Println("No lineage information extracted (analyzer not configured)")
 		return
 	}
 
-	fmt.Println("Lineage analysis completed successfully")
-	// TODO: Format and print lineage result
+	if len(result.Columns) == 0 {
+		fmt.Println("No column lineage found")
+	} else {
+		fmt.Printf("Column lineage (%d):\n", len(result.Columns))
+	}
+	for _, col := range result.Columns {
+		sources := make([]string, len(col.Sources))
+		for i, src := range col.Sources {
+			sources[i] = src.QualifiedName()
+			if src.Confidence != "" && src.Confidence != lineageCore.ConfidenceCatalog {
+				sources[i] += " (" + string(src.Confidence) + ")"
+			}
+		}
+		fmt.Printf("  %s <- %s\n", col.Target.QualifiedName(), strings.Join(sources, ", "))
+	}
+
+	if result.HasUnresolved() {
+		fmt.Printf("Unresolved references (%d):\n", len(result.Unresolved))
+		for _, ref := range result.Unresolved {
+			name := ref.Table
+			if ref.Database != "" {
+				name = ref.Database + "." + name
+			}
+			if ref.Column != "" {
+				if name != "" {
+					name += "."
+				}
+				name += ref.Column
+			}
+			fmt.Printf("  - %s: %s\n", name, ref.Reason)
+		}
+	}
+
+	if d := result.Dataset; d != nil {
+		if len(d.Owners) > 0 {
+			fmt.Printf("Owners: %s\n", strings.Join(d.Owners, ", "))
+		}
+		if len(d.Tags) > 0 {
+			fmt.Printf("Tags: %s\n", strings.Join(d.Tags, ", "))
+		}
+	}
+	if len(result.Annotations) > 0 {
+		keys := make([]string, 0, len(result.Annotations))
+		for key := range result.Annotations {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Printf("Annotations (%d):\n", len(keys))
+		for _, key := range keys {
+			fmt.Printf("  %s: %s\n", key, result.Annotations[key])
+		}
+	}
+	if len(result.Comments) > 0 {
+		fmt.Printf("Comments (%d):\n", len(result.Comments))
+		for _, c := range result.Comments {
+			fmt.Printf("  %d: %s\n", c.Line, strings.ReplaceAll(c.Text, "\n", "\n     "))
+		}
+	}
+	if len(result.Skipped) > 0 {
+		fmt.Printf("Skipped statements (%d):\n", len(result.Skipped))
+		for _, skipped := range result.Skipped {
+			fmt.Printf("  #%d: %s\n", skipped.Statement, skipped.Reason)
+		}
+	}
+}
+
+// parseTemplateVars parses "key=value,key2=value2" into template variables.
+func parseTemplateVars(s string) *lineageCore.TemplateVars {
+	vars := &lineageCore.TemplateVars{Vars: make(map[string]string)}
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		vars.Vars[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return vars
+}
+
+// syncResult is the outcome of a sync, as printed with the output flags.
+type syncResult struct {
+	Source string `json:"source"`
+	Store  string `json:"store"`
+	*metadataService.SyncSummary
+	Error string `json:"error,omitempty"`
 }
 
-func runSync(ctx context.Context, svc *metadataService.Service, source string) {
+func runSync(ctx context.Context, svc *metadataService.Service, source, configPath, storePath, sqlPath string, opts metadataService.SyncOptions, format render.Format) {
+	sources, err := loadSources(configPath)
+	if err != nil {
+		fmt.Printf("Error loading sources from %s: %v\n", configPath, redact.Error(err))
+		os.Exit(1)
+	}
 	if source == "" {
-		fmt.Println("Error: -source must be provided")
+		fmt.Printf("Error: -source must be provided, one of: %s\n", strings.Join(sources.Names(), ", "))
+		os.Exit(1)
+	}
+	if err := registerCollector(svc, sources, source); err != nil {
+		fmt.Printf("Error creating collector %s: %v\n", source, redact.Error(err))
+		os.Exit(1)
+	}
+	defer svc.Close()
+
+	st, err := store.Open(ctx, &store.Config{Driver: store.DriverSQLite, DSN: storePath})
+	if err != nil {
+		fmt.Printf("Error opening store %s: %v\n", storePath, redact.Error(err))
 		os.Exit(1)
 	}
+	defer st.Close()
+	svc.SetStore(st)
+	svc.SetTiers(sources.Tiers())
+	if sqlPath != "" {
+		_, graph, _ := loadLineage("", "", sqlPath, "")
+		svc.SetDependencyChecker(graphDependents{graph})
+	}
 
-	err := svc.SyncMetadata(ctx, source)
+	summary, err := svc.Sync(ctx, source, opts)
+	if format.Structured() {
+		result := syncResult{Source: source, Store: storePath, SyncSummary: summary}
+		if err != nil {
+			result.Error = redact.Error(err).Error()
+		}
+		writeFormatted(format, result)
+		if err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Printf("Tables fetched: %d, unchanged: %d, deferred: %d, failed: %d\n", summary.Fetched, summary.Unchanged, summary.Deferred, summary.Failed)
 	if err != nil {
-		fmt.Printf("Error syncing metadata: %v\n", err)
+		fmt.Printf("Error syncing metadata: %v\n", redact.Error(err))
+		if errors.Is(err, metadataService.ErrHasDependents) {
+			fmt.Println("Tables dropped from the source were kept because other tables depend on them; use -force to remove them")
+		}
 		os.Exit(1)
 	}
 
-	fmt.Printf("Metadata synchronized from source: %s\n", source)
+	fmt.Printf("Metadata synchronized from source %s into %s\n", source, storePath)
 }
 
-func runList(ctx context.Context, svc *metadataService.Service, database string) {
-	if database == "" {
-		fmt.Println("Error: -database must be provided")
+// graphDependents finds the dependents of tables in a lineage graph.
+type graphDependents struct {
+	graph *lineageCore.Graph
+}
+
+func (d graphDependents) Dependents(_ context.Context, table string) []string {
+	return d.graph.Dependents(table)
+}
+
+// loadSources reads the sources of a config file, decrypting the credentials
+// encrypted by secrets migrate with the keys of its encryption section, as the
+// server does.
+func loadSources(configPath string) (*collectorConfig.Sources, error) {
+	data, err := textfile.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	decrypted, err := auth.DecryptConfig([]byte(data))
+	if err != nil {
+		return nil, err
+	}
+	return collectorConfig.ParseSources(decrypted)
+}
+
+// registerCollector registers the collector of the named source, resolving
+// its credential references as the server does. Only that source is
+// connected to, so a broken definition of another source does not prevent
+// the sync.
+func registerCollector(svc *metadataService.Service, sources *collectorConfig.Sources, name string) error {
+	cfg, err := sources.Get(name)
+	if err != nil {
+		return err
+	}
+	if err := cfg.ResolveCredentials(context.Background()); err != nil {
+		return err
+	}
+	col, err := factory.Create(cfg)
+	if err != nil {
+		return err
+	}
+	svc.RegisterCollector(cfg.ID, col)
+	svc.SetTimeouts(cfg.ID, cfg.Timeouts)
+	harvest, err := cfg.Harvest()
+	if err != nil {
+		return err
+	}
+	svc.SetHarvest(cfg.ID, harvest)
+	return nil
+}
+
+// staleExitCode is the exit code of a freshness check that found the table
+// stale, distinct from the exit code 1 of a check that failed to run.
+const staleExitCode = 2
+
+func runFreshness(ctx context.Context, svc *metadataService.Service, source, table, configPath, column, tz string, cadence, grace time.Duration, format render.Format) {
+	if source == "" || table == "" {
+		fmt.Println("Error: -source and -table must be provided")
+		os.Exit(1)
+	}
+	var catalog, schema, name string
+	switch parts := strings.Split(table, "."); len(parts) {
+	case 2:
+		schema, name = parts[0], parts[1]
+	case 3:
+		catalog, schema, name = parts[0], parts[1], parts[2]
+	default:
+		fmt.Println("Error: -table must be schema.table or catalog.schema.table")
+		os.Exit(1)
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		fmt.Printf("Error: invalid -tz: %v\n", err)
+		os.Exit(1)
+	}
+	sources, err := loadSources(configPath)
+	if err != nil {
+		fmt.Printf("Error loading sources from %s: %v\n", configPath, redact.Error(err))
+		os.Exit(1)
+	}
+	if err := registerCollector(svc, sources, source); err != nil {
+		fmt.Printf("Error creating collector %s: %v\n", source, redact.Error(err))
 		os.Exit(1)
 	}
+	defer svc.Close()
 
-	tables, err := svc.ListTables(ctx, database)
+	meta, err := svc.FetchTableMetadata(ctx, source, catalog, schema, name)
 	if err != nil {
-		fmt.Printf("Error listing tables: %v\n", err)
+		fmt.Printf("Error fetching table %s: %v\n", table, redact.Error(err))
+		os.Exit(1)
+	}
+	opts := collector.FreshnessOptions{Cadence: cadence, Grace: grace, Column: column, Location: loc}
+	if column == "" {
+		// Sources without partitions fall back to the statistics
+		meta.Partitions, _ = svc.FetchPartitions(ctx, source, catalog, schema, name)
+	}
+	f, err := collector.CheckFreshness(meta, time.Now(), opts)
+	if column != "" || errors.Is(err, collector.ErrNoFreshness) {
+		if meta.Stats, err = svc.FetchTableStatistics(ctx, source, catalog, schema, name); err != nil {
+			fmt.Printf("Error fetching statistics of %s: %v\n", table, redact.Error(err))
+			os.Exit(1)
+		}
+		f, err = collector.CheckFreshness(meta, time.Now(), opts)
+	}
+	if err != nil {
+		fmt.Printf("Error checking freshness of %s: %v\n", table, err)
 		os.Exit(1)
 	}
 
-	if len(tables) == 0 {
-		fmt.Printf("No tables found in database: %s\n", database)
+	if format.Structured() {
+		writeFormatted(format, struct {
+			Source string `json:"source"`
+			Table  string `json:"table"`
+			*collector.Freshness
+		}{source, table, f})
+		if f.Stale {
+			os.Exit(staleExitCode)
+		}
 		return
 	}
+	basis := "latest partition " + f.Partition
+	if f.Basis == collector.FreshnessBasisStatistic {
+		basis = "max " + f.Column
+	}
+	status := "fresh"
+	if f.Stale {
+		status = "STALE"
+	}
+	fmt.Printf("%s %s: data up to %s (%s), %s old; due by %s\n", status, table,
+		f.Latest.In(loc).Format(time.RFC3339), basis, f.Age.Round(time.Minute), f.Deadline.In(loc).Format(time.RFC3339))
+	if f.Stale {
+		os.Exit(staleExitCode)
+	}
+}
 
-	fmt.Printf("Tables in database %s:\n", database)
-	for _, t := range tables {
-		fmt.Printf("  - %s.%s\n", t.Database, t.Table)
+// schemaDiffExitCode is the exit code of a comparison that found
+// differences, distinct from the exit code 1 of a comparison that failed.
+const schemaDiffExitCode = 2
+
+func runCompare(ctx context.Context, svc *metadataService.Service, source, target, schema, targetSchema, configPath string, format render.Format, migrationPath, dialect string, drop bool) {
+	if source == "" || schema == "" {
+		fmt.Println("Error: -source and -schema must be provided")
+		os.Exit(1)
+	}
+	if target == "" {
+		target = source
+	}
+	if targetSchema == "" {
+		targetSchema = schema
+	}
+	if target == source && targetSchema == schema {
+		fmt.Println("Error: -target or -target-schema must differ from the source")
+		os.Exit(1)
+	}
+	sources, err := loadSources(configPath)
+	if err != nil {
+		fmt.Printf("Error loading sources from %s: %v\n", configPath, redact.Error(err))
+		os.Exit(1)
+	}
+	for _, name := range []string{source, target} {
+		if err := registerCollector(svc, sources, name); err != nil {
+			fmt.Printf("Error creating collector %s: %v\n", name, redact.Error(err))
+			os.Exit(1)
+		}
+	}
+	defer svc.Close()
+
+	sourceTables, err := fetchSchemaTables(ctx, svc, source, schema)
+	if err != nil {
+		fmt.Printf("Error reading %s of %s: %v\n", schema, source, redact.Error(err))
+		os.Exit(1)
+	}
+	targetTables, err := fetchSchemaTables(ctx, svc, target, targetSchema)
+	if err != nil {
+		fmt.Printf("Error reading %s of %s: %v\n", targetSchema, target, redact.Error(err))
+		os.Exit(1)
+	}
+	diff := collector.CompareSchemas(sourceTables, targetTables)
+
+	if migrationPath != "" {
+		if dialect == "" {
+			cfg, err := sources.Get(target)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			dialect = cfg.Type
+		}
+		// Table names are not qualified: the schema alone is made current
+		_, current, ok := strings.Cut(targetSchema, ".")
+		if !ok {
+			current = targetSchema
+		}
+		script, err := collector.GenerateMigration(sourceTables, targetTables, collector.MigrationOptions{
+			Dialect: dialect,
+			Schema:  current,
+			Drop:    drop,
+			Title:   fmt.Sprintf("Migration of %s (%s) to match %s (%s)", targetSchema, target, schema, source),
+		})
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(migrationPath, []byte(script), 0o644); err != nil {
+			fmt.Printf("Error writing migration: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	switch format {
+	case render.JSON, render.YAML:
+		writeFormatted(format, diff)
+	case render.Table:
+		writeFormatted(format, tableChanges(diff, nil, collector.DiffMissing, collector.DiffExtra))
+	default:
+		fmt.Printf("Comparing %s (%s) with %s (%s)\n", schema, source, targetSchema, target)
+		printSchemaDiff(diff, target)
+	}
+	if !diff.Empty() {
+		os.Exit(schemaDiffExitCode)
+	}
+}
+
+// runDrift fetches the schemas of a source and compares them with a
+// committed baseline file, exiting with schemaDiffExitCode if they differ,
+// so that CI fails on schema changes that were not reviewed. With update,
+// the baseline is rewritten instead.
+func runDrift(ctx context.Context, svc *metadataService.Service, source, configPath, baselinePath, schemas, out string, update bool, format render.Format) {
+	if source == "" || baselinePath == "" {
+		fmt.Println("Error: -source and -baseline must be provided")
+		os.Exit(1)
+	}
+	sources, err := loadSources(configPath)
+	if err != nil {
+		fmt.Printf("Error loading sources from %s: %v\n", configPath, redact.Error(err))
+		os.Exit(1)
+	}
+	if err := registerCollector(svc, sources, source); err != nil {
+		fmt.Printf("Error creating collector %s: %v\n", source, redact.Error(err))
+		os.Exit(1)
+	}
+	defer svc.Close()
+
+	tables, err := fetchSourceTables(ctx, svc, source, schemas)
+	if err != nil {
+		fmt.Printf("Error reading %s: %v\n", source, redact.Error(err))
+		os.Exit(1)
+	}
+	current := collector.NewBaseline(source, tables)
+	data, err := current.Marshal()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if out != "" {
+		if err := os.WriteFile(out, data, 0o644); err != nil {
+			fmt.Printf("Error writing snapshot: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if update {
+		if err := os.WriteFile(baselinePath, data, 0o644); err != nil {
+			fmt.Printf("Error writing baseline: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Baseline %s updated with %d tables of %s\n", baselinePath, len(current.Tables), source)
+		return
+	}
+
+	committed, err := os.ReadFile(baselinePath)
+	if err != nil {
+		fmt.Printf("Error reading baseline: %v (use -update to create it)\n", err)
+		os.Exit(1)
+	}
+	baseline, err := collector.ParseBaseline(committed)
+	if err != nil {
+		fmt.Printf("Error reading baseline %s: %v\n", baselinePath, err)
+		os.Exit(1)
+	}
+	diff := collector.CompareBaseline(current, baseline)
+
+	switch format {
+	case render.JSON, render.YAML:
+		writeFormatted(format, diff)
+	case render.Table:
+		writeFormatted(format, tableChanges(&diff.SchemaDiff, diff.Redefined, "added", "dropped"))
+	default:
+		fmt.Printf("Comparing %d tables of %s with baseline %s (%d tables)\n", len(current.Tables), source, baselinePath, len(baseline.Tables))
+		printBaselineDiff(diff)
+	}
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		annotateDrift(diff, source, baselinePath)
+	}
+	if !diff.Empty() {
+		os.Exit(schemaDiffExitCode)
+	}
+}
+
+// fetchSourceTables fetches the metadata of the tables of a source, or only
+// of the schemas in the comma-separated list, given as schema or
+// catalog.schema.
+func fetchSourceTables(ctx context.Context, svc *metadataService.Service, source, schemas string) ([]*collector.TableMetadata, error) {
+	include := make(map[string]bool)
+	for _, s := range strings.Split(schemas, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			include[strings.ToLower(s)] = true
+		}
+	}
+	var tables []*collector.TableMetadata
+	err := svc.WalkTables(ctx, source, func(catalog, schema, table string) error {
+		if len(include) > 0 && !include[strings.ToLower(schema)] && !include[strings.ToLower(catalog+"."+schema)] {
+			return nil
+		}
+		t, err := svc.FetchTableMetadata(ctx, source, catalog, schema, table)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", schema, table, err)
+		}
+		tables = append(tables, t)
+		return nil
+	})
+	return tables, err
+}
+
+// printBaselineDiff prints the changes of the schemas of a source since its
+// baseline: + for added, ~ for changed and - for dropped.
+func printBaselineDiff(diff *collector.BaselineDiff) {
+	if diff.Empty() {
+		fmt.Println("No differences")
+		return
+	}
+	if len(diff.MissingTables) > 0 {
+		fmt.Printf("\nTables added since the baseline (%d):\n", len(diff.MissingTables))
+		for _, t := range diff.MissingTables {
+			fmt.Printf("  + %s\n", t)
+		}
+	}
+	if len(diff.Tables) > 0 {
+		fmt.Printf("\nTables changed (%d):\n", len(diff.Tables))
+		for _, td := range diff.Tables {
+			printTableDiff(td)
+		}
+	}
+	if len(diff.Redefined) > 0 {
+		fmt.Printf("\nTables redefined (%d):\n", len(diff.Redefined))
+		for _, r := range diff.Redefined {
+			fmt.Printf("  ~ %s: %s\n", r.Table, strings.Join(r.Changes, ", "))
+		}
+	}
+	if len(diff.ExtraTables) > 0 {
+		fmt.Printf("\nTables dropped since the baseline (%d):\n", len(diff.ExtraTables))
+		for _, t := range diff.ExtraTables {
+			fmt.Printf("  - %s\n", t)
+		}
+	}
+}
+
+// annotateDrift reports the changes of a drift check to GitHub Actions: an
+// error annotation on the baseline file per changed table, and a table of
+// the changes in the job summary.
+func annotateDrift(diff *collector.BaselineDiff, source, baselinePath string) {
+	changes := tableChanges(&diff.SchemaDiff, diff.Redefined, "added", "dropped")
+
+	// Workflow command values escape newlines, and properties also : and ,
+	escape := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	property := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	for _, c := range changes {
+		msg := fmt.Sprintf("%s %s", c.Table, c.Change)
+		if c.Details != "" {
+			msg += ": " + c.Details
+		}
+		fmt.Printf("::error file=%s,title=%s::%s\n", property.Replace(baselinePath), property.Replace("Schema drift in "+source), escape.Replace(msg))
+	}
+
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Schema drift of %s\n\n", source)
+	if len(changes) == 0 {
+		fmt.Fprintf(&b, "No differences from `%s`.\n", baselinePath)
+	} else {
+		fmt.Fprintf(&b, "%d tables differ from `%s`. Review the changes and commit the new baseline with `%s drift -update`.\n\n", len(changes), baselinePath, appName)
+		b.WriteString("| Table | Change | Details |\n|---|---|---|\n")
+		for _, c := range changes {
+			fmt.Fprintf(&b, "| `%s` | %s | %s |\n", c.Table, c.Change, strings.ReplaceAll(c.Details, "|", "\\|"))
+		}
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		fmt.Printf("Warning: cannot write the job summary: %v\n", err)
+		return
+	}
+	defer f.Close()
+	f.WriteString(b.String())
+}
+
+// tableChange is a table that differs between two schemas, a row of the
+// table output of compare and drift and of the drift job summary.
+type tableChange struct {
+	Table   string `json:"table"`
+	Change  string `json:"change"`
+	Details string `json:"details,omitempty"`
+}
+
+// tableChanges lists the tables of diff that differ: the tables only in the
+// source as added, the changed and redefined tables, and the tables only in
+// the target as dropped.
+func tableChanges(diff *collector.SchemaDiff, redefined []*collector.TableRedefinition, added, dropped string) []tableChange {
+	changes := []tableChange{}
+	for _, t := range diff.MissingTables {
+		changes = append(changes, tableChange{t, added, ""})
+	}
+	for _, td := range diff.Tables {
+		var details []string
+		for _, c := range td.Columns {
+			details = append(details, fmt.Sprintf("column %s %s", c.Column, c.Kind))
+		}
+		for _, i := range td.Indexes {
+			details = append(details, fmt.Sprintf("index %s %s", i.Index, i.Kind))
+		}
+		if td.PrimaryKey != nil {
+			details = append(details, "primary key changed")
+		}
+		changes = append(changes, tableChange{td.Table, collector.DiffChanged, strings.Join(details, ", ")})
+	}
+	for _, r := range redefined {
+		changes = append(changes, tableChange{r.Table, "redefined", strings.Join(r.Changes, ", ")})
+	}
+	for _, t := range diff.ExtraTables {
+		changes = append(changes, tableChange{t, dropped, ""})
+	}
+	return changes
+}
+
+// runSearch searches the tables synced into the store by their names,
+// comments and tags.
+func runSearch(ctx context.Context, storePath, text, sourceTypes, sources string, limit int, format render.Format) {
+	if strings.TrimSpace(text) == "" {
+		fmt.Println("Error: words to search for must be provided, e.g. search user order")
+		os.Exit(1)
+	}
+	st, err := store.Open(ctx, &store.Config{Driver: store.DriverSQLite, DSN: storePath})
+	if err != nil {
+		fmt.Printf("Error opening store %s: %v\n", storePath, redact.Error(err))
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	docs, err := search.Load(ctx, st, "")
+	if err != nil {
+		fmt.Printf("Error reading store %s: %v\n", storePath, err)
+		os.Exit(1)
+	}
+	ix := search.NewIndex()
+	ix.Add(docs...)
+	q := search.Query{Text: text, Limit: limit}
+	if sourceTypes != "" {
+		q.SourceTypes = strings.Split(sourceTypes, ",")
+	}
+	if sources != "" {
+		q.Sources = strings.Split(sources, ",")
+	}
+	result := ix.Search(q)
+
+	switch format {
+	case render.JSON, render.YAML:
+		writeFormatted(format, result)
+		return
+	case render.Table:
+		type hit struct {
+			Score      string   `json:"score"`
+			Table      string   `json:"table"`
+			Source     string   `json:"source"`
+			SourceType string   `json:"source_type"`
+			Matches    []string `json:"matches"`
+		}
+		hits := make([]hit, len(result.Hits))
+		for i, h := range result.Hits {
+			hits[i] = hit{fmt.Sprintf("%.2f", h.Score), h.Catalog + "." + h.Schema + "." + h.Table, h.Source, h.SourceType, h.Matches}
+		}
+		writeFormatted(format, hits)
+		return
+	}
+	if result.Total == 0 {
+		fmt.Printf("No tables match %q among %d tables\n", text, ix.Len())
+		return
+	}
+	fmt.Printf("%d of %d tables match %q\n\n", result.Total, ix.Len(), text)
+	for _, h := range result.Hits {
+		fmt.Printf("%7.2f  %s.%s.%s (%s, %s)\n", h.Score, h.Catalog, h.Schema, h.Table, h.Source, h.SourceType)
+		if h.Comment != "" {
+			fmt.Printf("         %s\n", h.Comment)
+		}
+		fmt.Printf("         matches: %s\n", strings.Join(h.Matches, ", "))
+		for _, c := range h.Columns {
+			if len(c.Examples) > 0 && (slices.Contains(h.Matches, "column:"+c.Name) || slices.Contains(h.Matches, "example:"+c.Name)) {
+				fmt.Printf("         %s e.g. %s\n", c.Name, strings.Join(c.Examples, ", "))
+			}
+		}
+	}
+	types := make([]string, 0, len(result.Facets))
+	for t := range result.Facets {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	facets := make([]string, 0, len(types))
+	for _, t := range types {
+		facets = append(facets, fmt.Sprintf("%s (%d)", t, result.Facets[t]))
+	}
+	fmt.Printf("\nSource types: %s\n", strings.Join(facets, ", "))
+}
+
+// runDescribe prints a synced table of the store: its columns with their
+// types, comments and example values, if the sync captured them. Comments
+// are shown in lang if their source is described in it. The table is named
+// as table, schema.table or catalog.schema.table.
+func runDescribe(ctx context.Context, storePath, source, name, lang string, format render.Format) {
+	if name == "" {
+		fmt.Println("Error: the table to describe must be provided, e.g. describe shop.orders")
+		os.Exit(1)
+	}
+	st, err := store.Open(ctx, &store.Config{Driver: store.DriverSQLite, DSN: storePath})
+	if err != nil {
+		fmt.Printf("Error opening store %s: %v\n", storePath, redact.Error(err))
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	keys, err := st.Tables(ctx, source)
+	if err != nil {
+		fmt.Printf("Error reading store %s: %v\n", storePath, err)
+		os.Exit(1)
+	}
+	var matches []store.TableKey
+	for _, key := range keys {
+		qualified := strings.ToLower(key.Catalog + "." + key.Schema + "." + key.Table)
+		if lower := strings.ToLower(name); qualified == lower || strings.HasSuffix(qualified, "."+lower) {
+			matches = append(matches, key)
+		}
+	}
+	switch {
+	case len(matches) == 0:
+		fmt.Printf("No table %s in %s\n", name, storePath)
+		os.Exit(1)
+	case len(matches) > 1:
+		fmt.Printf("Error: %d tables are named %s, qualify the name or set -source:\n", len(matches), name)
+		for _, key := range matches {
+			fmt.Printf("  - %s\n", key)
+		}
+		os.Exit(1)
+	}
+
+	t, err := st.GetTable(ctx, matches[0])
+	if err != nil {
+		fmt.Printf("Error reading %s: %v\n", matches[0], err)
+		os.Exit(1)
+	}
+	switch format {
+	case render.JSON, render.YAML:
+		writeFormatted(format, t)
+		return
+	case render.Table:
+		type column struct {
+			Name     string   `json:"name"`
+			Type     string   `json:"type"`
+			Nullable bool     `json:"nullable"`
+			Comment  string   `json:"comment"`
+			Examples []string `json:"examples"`
+		}
+		columns := make([]column, len(t.Columns))
+		for i := range t.Columns {
+			c := &t.Columns[i]
+			columns[i] = column{c.Name, collector.ColumnType(c), c.Nullable, collector.Description(c.Comment, c.Descriptions, lang), c.Examples}
+		}
+		writeFormatted(format, columns)
+		return
+	}
+	fmt.Printf("%s (%s, %s)\n", matches[0], t.Type, t.SourceType)
+	if comment := collector.Description(t.Comment, t.Descriptions, lang); comment != "" {
+		fmt.Printf("  %s\n", comment)
+	}
+	fmt.Println()
+	for i := range t.Columns {
+		c := &t.Columns[i]
+		fmt.Printf("  %s %s%s\n", c.Name, collector.ColumnType(c), nullability(c))
+		if comment := collector.Description(c.Comment, c.Descriptions, lang); comment != "" {
+			fmt.Printf("      %s\n", comment)
+		}
+		if len(c.Examples) > 0 {
+			fmt.Printf("      e.g. %s\n", strings.Join(c.Examples, ", "))
+		}
+	}
+}
+
+// runSubscribe subscribes a user of the store to the events of tables of a
+// source, removes their subscription, or sets or removes the channel they
+// are notified in. The server notifies the users subscribed in its store.
+func runSubscribe(ctx context.Context, storePath string, sub *store.Subscription, channel, webhook string, remove, list bool, format render.Format) {
+	if sub.User == "" {
+		fmt.Println("Error: -user must be provided")
+		os.Exit(1)
+	}
+	st, err := store.Open(ctx, &store.Config{Driver: store.DriverSQLite, DSN: storePath})
+	if err != nil {
+		fmt.Printf("Error opening store %s: %v\n", storePath, redact.Error(err))
+		os.Exit(1)
+	}
+	defer st.Close()
+	svc := metadataService.NewService(nil)
+	svc.SetStore(st)
+
+	switch {
+	case list:
+	case channel != "" && remove:
+		err = svc.DeleteUserChannel(ctx, sub.User, channel)
+	case channel != "":
+		err = svc.SetUserChannel(ctx, &store.UserChannel{User: sub.User, Type: channel, WebhookURL: webhook})
+	case sub.Source == "":
+		fmt.Println("Error: -source, -channel or -list must be provided")
+		os.Exit(1)
+	case remove:
+		err = svc.Unsubscribe(ctx, sub.User, sub.Source, sub.Table)
+	default:
+		err = svc.Subscribe(ctx, sub)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	subs, err := svc.Subscriptions(ctx, sub.User)
+	if err != nil {
+		fmt.Printf("Error reading store %s: %v\n", storePath, err)
+		os.Exit(1)
+	}
+	channels, err := svc.UserChannels(ctx, sub.User)
+	if err != nil {
+		fmt.Printf("Error reading store %s: %v\n", storePath, err)
+		os.Exit(1)
+	}
+	switch format {
+	case render.JSON, render.YAML:
+		if subs == nil {
+			subs = []store.Subscription{}
+		}
+		if channels == nil {
+			channels = []store.UserChannel{}
+		}
+		writeFormatted(format, struct {
+			Subscriptions []store.Subscription `json:"subscriptions"`
+			Channels      []store.UserChannel  `json:"channels"`
+		}{subs, channels})
+		return
+	case render.Table:
+		writeFormatted(format, subs)
+		return
+	}
+	fmt.Printf("Subscriptions of %s:\n", sub.User)
+	if len(subs) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, s := range subs {
+		table, events := s.Table, "all events"
+		if table == "" {
+			table = "*"
+		}
+		if len(s.Events) > 0 {
+			events = strings.Join(s.Events, ", ")
+		}
+		fmt.Printf("  %s %s (%s)\n", s.Source, table, events)
+	}
+	fmt.Println("Channels:")
+	if len(channels) == 0 {
+		fmt.Println("  (none, notifications of the subscriptions are not sent)")
+	}
+	for _, c := range channels {
+		fmt.Printf("  %s %s\n", c.Type, c.WebhookURL)
+	}
+}
+
+// fetchSchemaTables fetches the metadata of the tables of a schema, or
+// catalog.schema, of a source. A schema without a catalog is read from the
+// first catalog of the source.
+func fetchSchemaTables(ctx context.Context, svc *metadataService.Service, source, spec string) ([]*collector.TableMetadata, error) {
+	catalog, schema, ok := strings.Cut(spec, ".")
+	if !ok {
+		catalog, schema = "", spec
+		catalogs, err := svc.DiscoverCatalogs(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		if len(catalogs) > 0 {
+			catalog = catalogs[0].Catalog
+		}
+	}
+
+	var tables []*collector.TableMetadata
+	opts := &collector.ListOptions{PageSize: 500}
+	for {
+		page, err := svc.ListSourceTables(ctx, source, catalog, schema, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range page.Tables {
+			t, err := svc.FetchTableMetadata(ctx, source, catalog, schema, name)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", schema, name, err)
+			}
+			tables = append(tables, t)
+		}
+		if page.NextPageToken == "" {
+			return tables, nil
+		}
+		opts.PageToken = page.NextPageToken
+	}
+}
+
+// printSchemaDiff prints the differences of a schema as the steps migrating
+// the target to the source: + to create, ~ to alter and - only in the target.
+func printSchemaDiff(diff *collector.SchemaDiff, target string) {
+	if diff.Empty() {
+		fmt.Println("No differences")
+		return
+	}
+	if len(diff.MissingTables) > 0 {
+		fmt.Printf("\nTables missing from %s (%d):\n", target, len(diff.MissingTables))
+		for _, t := range diff.MissingTables {
+			fmt.Printf("  + %s\n", t)
+		}
+	}
+	if len(diff.Tables) > 0 {
+		fmt.Printf("\nTables to alter (%d):\n", len(diff.Tables))
+		for _, td := range diff.Tables {
+			printTableDiff(td)
+		}
+	}
+	if len(diff.ExtraTables) > 0 {
+		fmt.Printf("\nTables only in %s (%d):\n", target, len(diff.ExtraTables))
+		for _, t := range diff.ExtraTables {
+			fmt.Printf("  - %s\n", t)
+		}
+	}
+}
+
+// printTableDiff prints the differences of a table as the steps altering the
+// target to match the source.
+func printTableDiff(td *collector.TableDiff) {
+	fmt.Printf("  ~ %s\n", td.Table)
+	for _, c := range td.Columns {
+		switch c.Kind {
+		case collector.DiffMissing:
+			fmt.Printf("      + column %s %s%s\n", c.Column, collector.ColumnType(c.Source), nullability(c.Source))
+		case collector.DiffChanged:
+			fmt.Printf("      ~ column %s: %s\n", c.Column, strings.Join(c.Changes, "; "))
+		case collector.DiffExtra:
+			fmt.Printf("      - column %s %s\n", c.Column, collector.ColumnType(c.Target))
+		}
+	}
+	for _, i := range td.Indexes {
+		switch i.Kind {
+		case collector.DiffMissing:
+			fmt.Printf("      + index %s\n", indexDefinition(i.Source))
+		case collector.DiffChanged:
+			fmt.Printf("      ~ index %s -> %s\n", indexDefinition(i.Target), indexDefinition(i.Source))
+		case collector.DiffExtra:
+			fmt.Printf("      - index %s\n", indexDefinition(i.Target))
+		}
+	}
+	if pk := td.PrimaryKey; pk != nil {
+		fmt.Printf("      ~ primary key (%s) -> (%s)\n", strings.Join(pk.Target, ", "), strings.Join(pk.Source, ", "))
+	}
+}
+
+func nullability(c *collector.Column) string {
+	if c.Nullable {
+		return " NULL"
+	}
+	return " NOT NULL"
+}
+
+func indexDefinition(i *collector.Index) string {
+	unique := ""
+	if i.Unique {
+		unique = "unique "
+	}
+	return fmt.Sprintf("%s%s (%s)", unique, i.Name, strings.Join(i.Columns, ", "))
+}
+
+// listedTable is a table of a database, a row of the table output of list.
+type listedTable struct {
+	Database string `json:"database"`
+	Table    string `json:"table"`
+	Type     string `json:"table_type"`
+	Columns  int    `json:"columns"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+func runList(ctx context.Context, svc *metadataService.Service, database string, format render.Format) {
+	if database == "" {
+		fmt.Println("Error: -database must be provided")
+		os.Exit(1)
+	}
+
+	tables, err := svc.ListTables(ctx, database)
+	if err != nil {
+		fmt.Printf("Error listing tables: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch format {
+	case render.JSON, render.YAML:
+		if tables == nil {
+			tables = []*model.TableMetadata{}
+		}
+		writeFormatted(format, tables)
+		return
+	case render.Table:
+		rows := make([]listedTable, len(tables))
+		for i, t := range tables {
+			rows[i] = listedTable{Database: t.Database, Table: t.Table, Type: t.TableType, Columns: len(t.Columns), Comment: t.Comment}
+		}
+		writeFormatted(format, rows)
+		return
+	}
+	if len(tables) == 0 {
+		fmt.Printf("No tables found in database: %s\n", database)
+		return
+	}
+
+	fmt.Printf("Tables in database %s:\n", database)
+	for _, t := range tables {
+		fmt.Printf("  - %s.%s\n", t.Database, t.Table)
+	}
+}
+
+func runReport(ctx context.Context, out, title, ddl, schema, sqlPath, vars, docsDir string, urns *urn.Config, storePath string, since time.Duration) {
+	var namer *urn.Namer
+	if urns.Scheme != "" {
+		// A template is given in place of the scheme name
+		if strings.Contains(urns.Scheme, "{{") {
+			urns.Scheme, urns.Template = urn.SchemeTemplate, urns.Scheme
+		}
+		var err error
+		if namer, err = urn.New(urns); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	provider, graph, joins := loadLineage(ddl, schema, sqlPath, vars)
+	var docs map[string]string
+	if docsDir != "" {
+		var err error
+		if docs, err = loadDocs(docsDir); err != nil {
+			fmt.Printf("Error reading docs: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var (
+		rates      []*store.ChangeRate
+		ratesSince time.Time
+	)
+	if storePath != "" {
+		st, err := store.Open(ctx, &store.Config{Driver: store.DriverSQLite, DSN: storePath})
+		if err != nil {
+			fmt.Printf("Error opening store %s: %v\n", storePath, redact.Error(err))
+			os.Exit(1)
+		}
+		now := time.Now()
+		ratesSince = now.Add(-since)
+		changes, err := st.SchemaChanges(ctx, "", ratesSince)
+		st.Close()
+		if err != nil {
+			fmt.Printf("Error reading store %s: %v\n", storePath, err)
+			os.Exit(1)
+		}
+		rates = store.ChangeRates(changes, ratesSince, now)
+	}
+
+	stats, err := report.Generate(out, report.Options{
+		Title:         title,
+		Tables:        provider.AllTables(),
+		Graph:         graph,
+		Relationships: joins.Relationships("", 1),
+		Docs:          docs,
+		URNs:          namer,
+		ChangeRates:   rates,
+		ChangesSince:  ratesSince,
+	})
+	if err != nil {
+		fmt.Printf("Error generating report: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Report generated in %s (%d tables, %d lineage edges)\n", out, stats.Tables, stats.Edges)
+}
+
+// loadDocs reads the table READMEs under dir. The table of a README is its
+// path without the .md extension, with separators replaced by dots: both
+// dw/orders.md and dw.orders.md document dw.orders.
+func loadDocs(dir string) (map[string]string, error) {
+	docs := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".md") {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		table := strings.ReplaceAll(filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel))), "/", ".")
+		docs[table] = string(content)
+		return nil
+	})
+	return docs, err
+}
+
+func runLineageView(table string, depth int, addr, ddl, schema, sqlPath, vars string) {
+	if table == "" {
+		fmt.Println("Error: a table (db.table) must be provided")
+		os.Exit(1)
+	}
+	_, graph, _ := loadLineage(ddl, schema, sqlPath, vars)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		fmt.Printf("Error starting viewer: %v\n", err)
+		os.Exit(1)
+	}
+	server := &http.Server{Handler: report.NewViewerHandler(graph)}
+	go server.Serve(listener)
+
+	viewURL := fmt.Sprintf("http://%s/?node=%s&depth=%d", listener.Addr(), url.QueryEscape(table), depth)
+	fmt.Printf("Lineage viewer for %s running at %s (press Ctrl+C to stop)\n", table, viewURL)
+	openBrowser(viewURL)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	server.Shutdown(shutdownCtx)
+}
+
+// runRootCause ranks the likely causes of an incident on a table: the schema
+// changes, failed syncs and stale data of the table and its ancestors in the
+// lineage, as recorded by syncs into the store.
+func runRootCause(ctx context.Context, table, storePath, until string, since time.Duration, depth, top int, opts metadataService.RootCauseOptions, ddl, schema, sqlPath, vars string, format render.Format) {
+	if table == "" {
+		fmt.Println("Error: a table (db.table) must be provided")
+		os.Exit(1)
+	}
+	opts.Until = time.Now()
+	if until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			fmt.Printf("Error: invalid -until %q, use RFC 3339 (e.g. 2024-06-01T08:00:00Z)\n", until)
+			os.Exit(1)
+		}
+		opts.Until = t
+	}
+	opts.Since = opts.Until.Add(-since)
+
+	_, graph, _ := loadLineage(ddl, schema, sqlPath, vars)
+	ancestors := graph.Ancestors(table, depth)
+
+	st, err := store.Open(ctx, &store.Config{Driver: store.DriverSQLite, DSN: storePath})
+	if err != nil {
+		fmt.Printf("Error opening store %s: %v\n", storePath, redact.Error(err))
+		os.Exit(1)
+	}
+	defer st.Close()
+	svc := metadataService.NewService(nil)
+	svc.SetStore(st)
+
+	causes, err := svc.RootCause(ctx, table, ancestors, opts)
+	if err != nil {
+		fmt.Printf("Error reading store %s: %v\n", storePath, err)
+		os.Exit(1)
+	}
+	if top > 0 && len(causes) > top {
+		causes = causes[:top]
+	}
+
+	if format.Structured() {
+		if causes == nil {
+			causes = []metadataService.Cause{}
+		}
+		writeFormatted(format, causes)
+		return
+	}
+	fmt.Printf("Likely causes of the incident on %s between %s and %s (%d upstream tables):\n",
+		table, opts.Since.Format(time.RFC3339), opts.Until.Format(time.RFC3339), len(ancestors))
+	if len(causes) == 0 {
+		fmt.Println("  No schema changes, failed syncs or stale data found upstream")
+		return
+	}
+	for i, c := range causes {
+		where := "the table itself"
+		switch {
+		case c.Hops == 1:
+			where = "read directly"
+		case c.Hops > 1:
+			where = fmt.Sprintf("%d hops upstream", c.Hops)
+		}
+		fmt.Printf("%2d. [%.2f] %s %s (%s, %s)\n", i+1, c.Score, c.Kind, c.Table, c.Source, where)
+		fmt.Printf("      %s at %s\n", c.Detail, c.At.Format(time.RFC3339))
+	}
+}
+
+func runQueryLog(ctx context.Context, svc *metadataService.Service, source, configPath string, since time.Duration, follow bool, interval time.Duration, top int, ddl, schema string, format render.Format) {
+	sources, err := loadSources(configPath)
+	if err != nil {
+		fmt.Printf("Error loading sources from %s: %v\n", configPath, redact.Error(err))
+		os.Exit(1)
+	}
+	if source == "" {
+		fmt.Printf("Error: -source must be provided, one of: %s\n", strings.Join(sources.Names(), ", "))
+		os.Exit(1)
+	}
+	if follow && interval <= 0 {
+		fmt.Println("Error: -interval must be positive")
+		os.Exit(1)
+	}
+	if err := registerCollector(svc, sources, source); err != nil {
+		fmt.Printf("Error creating collector %s: %v\n", source, redact.Error(err))
+		os.Exit(1)
+	}
+	defer svc.Close()
+
+	provider := loadCatalog(ddl, schema)
+	lineageSvc := lineageService.NewService(lineageCore.NewAnalyzer(metadata.NewCatalogAdapter(provider)), nil)
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var total lineageService.QueryLogSummary
+	start := time.Now().Add(-since)
+	for {
+		entries, err := svc.ReadQueryLog(ctx, source, start)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			fmt.Fprintf(os.Stderr, "Error reading the query log of %s: %v\n", source, redact.Error(err))
+			os.Exit(1)
+		}
+		summary := lineageSvc.RecordQueryLog(ctx, service.QueryLogStatements(entries, time.Now()))
+		total.Statements += summary.Statements
+		total.Executions += summary.Executions
+		total.WithLineage += summary.WithLineage
+		total.Skipped += summary.Skipped
+		if len(total.Errors) < 10 {
+			total.Errors = append(total.Errors, summary.Errors[:min(len(summary.Errors), 10-len(total.Errors))]...)
+		}
+		if !format.Structured() {
+			fmt.Printf("%s: read %d statements (%d with lineage, %d executions), skipped %d\n", time.Now().Format(time.RFC3339),
+				summary.Statements, summary.WithLineage, summary.Executions, summary.Skipped)
+		}
+		if !follow {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(interval):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	// The edges most executed first
+	edges := lineageSvc.MergedGraph().Edges()
+	sort.SliceStable(edges, func(i, j int) bool {
+		return edges[i].Provenance.Occurrences > edges[j].Provenance.Occurrences
+	})
+	if top > 0 && len(edges) > top {
+		edges = edges[:top]
+	}
+	switch format {
+	case render.JSON, render.YAML:
+		if edges == nil {
+			edges = []*lineageCore.Edge{}
+		}
+		writeFormatted(format, struct {
+			Summary lineageService.QueryLogSummary `json:"summary"`
+			Edges   []*lineageCore.Edge            `json:"edges"`
+		}{total, edges})
+		return
+	case render.Table:
+		type edge struct {
+			Target     string    `json:"target"`
+			Source     string    `json:"source"`
+			Executions int       `json:"executions"`
+			LastSeen   time.Time `json:"last_seen"`
+		}
+		rows := make([]edge, len(edges))
+		for i, e := range edges {
+			rows[i] = edge{e.Target.QualifiedName(), e.Source.QualifiedName(), e.Provenance.Occurrences, e.Provenance.LastSeen}
+		}
+		writeFormatted(format, rows)
+		return
+	}
+	for _, e := range total.Errors {
+		fmt.Printf("  skipped: %s\n", e)
+	}
+	fmt.Printf("Column lineage from the query log of %s (%d edges):\n", source, len(edges))
+	for _, e := range edges {
+		fmt.Printf("  %s <- %s  executions=%d last_seen=%s\n", e.Target.QualifiedName(), e.Source.QualifiedName(),
+			e.Provenance.Occurrences, e.Provenance.LastSeen.Format(time.RFC3339))
+	}
+}
+
+func runAirflow(ctx context.Context, cfg *airflow.Config, dags, ddl, schema string, format render.Format) {
+	client, err := airflow.NewClient(cfg)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	var dagIDs []string
+	if dags != "" {
+		dagIDs = strings.Split(dags, ",")
+	}
+	pipelines, err := airflow.Import(ctx, client, dagIDs)
+	if err != nil {
+		fmt.Printf("Error importing DAGs from %s: %v\n", cfg.URL, redact.Error(err))
+		os.Exit(1)
+	}
+
+	// Snowflake operators load files with COPY INTO
+	provider := loadCatalog(ddl, schema)
+	analyzer := lineageCore.NewAnalyzer(metadata.NewCatalogAdapter(provider))
+	analyzer.RegisterSnowflake(nil)
+	lineageSvc := lineageService.NewService(analyzer, nil)
+	summary, err := lineageSvc.RecordAirflowPipelines(ctx, pipelines)
+	if err != nil {
+		fmt.Printf("Error recording DAGs: %v\n", err)
+		os.Exit(1)
+	}
+	graph := lineageSvc.MergedGraph()
+
+	if format.Structured() {
+		jobs := make([]*lineageCore.Job, 0)
+		for _, job := range graph.Jobs() {
+			if job.Type == lineageCore.JobTypeAirflowDAG || job.Type == lineageCore.JobTypeAirflowTask {
+				jobs = append(jobs, job)
+			}
+		}
+		if format == render.Table {
+			writeFormatted(format, jobs)
+			return
+		}
+		writeFormatted(format, struct {
+			Summary *airflow.Summary   `json:"summary"`
+			Jobs    []*lineageCore.Job `json:"jobs"`
+		}{summary, jobs})
+		return
+	}
+
+	fmt.Printf("Imported %d DAGs, %d tasks (%d linked to tables)\n", summary.Pipelines, summary.Tasks, summary.Linked)
+	for _, e := range summary.Errors {
+		fmt.Printf("  skipped: %s\n", e)
+	}
+	for _, p := range pipelines {
+		dag, err := graph.Job(p.ID)
+		if err != nil {
+			continue
+		}
+		fmt.Printf("\n%s\n  reads:  %s\n  writes: %s\n", p.ID, joinOrDash(dag.Inputs), joinOrDash(dag.Outputs))
+		for _, t := range p.Tasks {
+			task, err := graph.Job(p.ID + "." + t.ID)
+			if err != nil {
+				continue
+			}
+			fmt.Printf("  %s [%s] reads %s, writes %s", t.ID, t.Operator, joinOrDash(task.Inputs), joinOrDash(task.Outputs))
+			if len(t.Downstream) > 0 {
+				fmt.Printf(" -> %s", strings.Join(t.Downstream, ", "))
+			}
+			fmt.Println()
+		}
+	}
+}
+
+// joinOrDash joins names with commas, or returns - if there are none.
+func joinOrDash(names []string) string {
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, ", ")
+}
+
+func runRepl(ctx context.Context, storePath, ddl, schema, vars, dialect, output string) {
+	var tables []string
+	provider := loadCatalog(ddl, schema)
+	for _, t := range provider.AllTables() {
+		db := t.Database
+		if t.Schema != "" {
+			db = t.Schema
+		}
+		tables = append(tables, db+"."+t.Table)
+	}
+	analyzer := lineageCore.NewAnalyzer(metadata.NewCatalogAdapter(provider))
+	analyzer.SetTemplateResolver(parseTemplateVars(vars))
+	analyzer.RegisterSnowflake(nil)
+
+	// The default store is optional: DDL or schema files may describe the
+	// tables instead.
+	if _, err := os.Stat(storePath); err == nil {
+		st, err := store.Open(ctx, &store.Config{Driver: store.DriverSQLite, DSN: storePath})
+		if err != nil {
+			fmt.Printf("Error opening store %s: %v\n", storePath, redact.Error(err))
+			os.Exit(1)
+		}
+		defer st.Close()
+		keys, err := st.Tables(ctx, "")
+		if err != nil {
+			fmt.Printf("Error listing tables: %v\n", err)
+			os.Exit(1)
+		}
+		for _, key := range keys {
+			db := key.Catalog
+			if key.Schema != "" {
+				db = key.Schema
+			}
+			tables = append(tables, db+"."+key.Table)
+		}
+		if ddl == "" && schema == "" {
+			analyzer.SetCatalog(metadata.NewStoreCatalog(st))
+		}
+	}
+
+	print := func(result *lineageCore.LineageResult, output string) error {
+		switch output {
+		case lineageCore.FormatJSON:
+			writeLineageJSON(result)
+		case lineageCore.FormatDOT:
+			return lineageCore.WriteDOT(os.Stdout, result)
+		case lineageCore.FormatMermaid:
+			return lineageCore.WriteMermaid(os.Stdout, result)
+		default:
+			printLineage(result)
+		}
+		return nil
+	}
+	session := repl.NewSession(analyzer, tables, os.Stdout, print)
+	if err := session.SetDialect(dialect); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := session.SetOutput(output); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	editor := repl.NewEditor(os.Stdin, os.Stdout)
+	if err := repl.Run(session, editor); err != nil {
+		fmt.Printf("Error reading input: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runLineageTags(rulesFile, ddl, schema, sqlPath, vars string, format render.Format) {
+	if rulesFile == "" {
+		fmt.Println("Error: -rules must be provided")
+		os.Exit(1)
+	}
+	f, err := os.Open(rulesFile)
+	if err != nil {
+		fmt.Printf("Error reading rules: %v\n", err)
+		os.Exit(1)
+	}
+	rules, err := lineageCore.ParseTagRules(f)
+	f.Close()
+	if err != nil {
+		fmt.Printf("Error reading rules: %v\n", err)
+		os.Exit(1)
+	}
+
+	provider, graph, _ := loadLineage(ddl, schema, sqlPath, vars)
+	// Lineage may reference tables without their database, so the tags are
+	// set on both names
+	tags := make(map[string][]string)
+	for _, t := range provider.AllTables() {
+		for _, table := range []string{t.Database + "." + t.Table, t.Table} {
+			tags[table] = append(tags[table], t.Tags...)
+			for _, col := range t.Columns {
+				tags[table+"."+col.Name] = append(tags[table+"."+col.Name], col.Tags...)
+			}
+		}
+	}
+	propagator := lineageCore.NewTagPropagator(graph, rules)
+	for node, nodeTags := range tags {
+		propagator.SetTags(node, nodeTags)
+	}
+
+	propagated := propagator.Propagated()
+	nodes := make([]string, 0, len(propagated))
+	for node := range propagated {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	if format.Structured() {
+		type tag struct {
+			Node string `json:"node"`
+			lineageCore.PropagatedTag
+		}
+		tags := []tag{}
+		for _, node := range nodes {
+			for _, t := range propagated[node] {
+				tags = append(tags, tag{node, t})
+			}
+		}
+		writeFormatted(format, tags)
+		return
+	}
+	fmt.Printf("Propagated tags (%d nodes):\n", len(nodes))
+	for _, node := range nodes {
+		for _, t := range propagated[node] {
+			fmt.Printf("  %-40s %-20s from %s (%d hops)\n", node, t.Tag, t.From, t.Hops)
+		}
+	}
+}
+
+func runUsage(ctx context.Context, table string, unused bool, since time.Duration, ddl, schema, sqlPath string, format render.Format) {
+	if sqlPath == "" {
+		fmt.Println("Error: -sql must be provided")
+		os.Exit(1)
+	}
+	provider := loadCatalog(ddl, schema)
+	svc := lineageService.NewService(lineageCore.NewAnalyzer(metadata.NewCatalogAdapter(provider)), nil)
+
+	files, err := textfile.Expand(sqlPath, ".sql")
+	if err != nil {
+		fmt.Printf("Error reading SQL files: %v\n", err)
+		os.Exit(1)
+	}
+	for _, file := range files {
+		executedAt := time.Now()
+		if file != textfile.Stdin {
+			info, err := os.Stat(file)
+			if err != nil {
+				fmt.Printf("Error reading file: %v\n", err)
+				os.Exit(1)
+			}
+			executedAt = info.ModTime()
+		}
+		err := scanSQLFile(file, func(stmt string) error {
+			if _, err := svc.RecordQueryLogSQL(ctx, stmt, executedAt); err != nil {
+				fmt.Printf("Warning: skipping a statement of %s: %v\n", file, err)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("Error reading file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if !unused {
+		columns := svc.GetColumnUsage(ctx, table)
+		if format.Structured() {
+			if columns == nil {
+				columns = []*lineageCore.ColumnUsageStats{}
+			}
+			writeFormatted(format, columns)
+			return
+		}
+		fmt.Printf("Column usage from %d query files (%d columns):\n", len(files), len(columns))
+		for _, c := range columns {
+			fmt.Printf("  %-40s queries=%d select=%d filter=%d join=%d group_by=%d order_by=%d last_used=%s\n",
+				c.Ref().QualifiedName(), c.Queries, c.Selected, c.Filtered, c.Joined, c.Grouped, c.Ordered,
+				c.LastUsed.Format(time.RFC3339))
+		}
+		return
+	}
+
+	var tables []*lineageCore.TableSchema
+	for _, t := range provider.AllTables() {
+		if table != "" && !strings.EqualFold(t.Table, table) && !strings.EqualFold(t.Database+"."+t.Table, table) {
+			continue
+		}
+		tables = append(tables, &lineageCore.TableSchema{Database: t.Database, Table: t.Table, Columns: t.GetColumnNames()})
+	}
+	var cutoff time.Time
+	if since > 0 {
+		cutoff = time.Now().Add(-since)
+	}
+	columns := svc.GetUnusedColumns(ctx, tables, cutoff)
+	if format.Structured() {
+		if columns == nil {
+			columns = []lineageCore.ColumnRef{}
+		}
+		writeFormatted(format, columns)
+		return
+	}
+	fmt.Printf("Unused columns (%d):\n", len(columns))
+	for _, ref := range columns {
+		fmt.Printf("  - %s\n", ref.QualifiedName())
+	}
+}
+
+func runRelationships(table string, minOccurrences int64, erd, ddl, schema, sqlPath, vars string, format render.Format) {
+	provider, _, joins := loadLineage(ddl, schema, sqlPath, vars)
+
+	relationships := joins.Relationships(table, minOccurrences)
+	msg := os.Stdout
+	if format.Structured() {
+		if relationships == nil {
+			relationships = []*lineageCore.Relationship{}
+		}
+		writeFormatted(format, relationships)
+		msg = os.Stderr
+	} else {
+		printRelationships(relationships)
+	}
+
+	if erd == "" {
+		return
+	}
+	f, err := os.Create(erd)
+	if err != nil {
+		fmt.Printf("Error creating ER diagram: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	if err := report.WriteERD(f, provider.AllTables(), relationships); err != nil {
+		fmt.Printf("Error writing ER diagram: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(msg, "ER diagram written to %s\n", erd)
+}
+
+// printRelationships prints relationships with their join keys.
+func printRelationships(relationships []*lineageCore.Relationship) {
+	fmt.Printf("Relationships (%d):\n", len(relationships))
+	for _, r := range relationships {
+		source := "foreign key"
+		if r.Suggested() {
+			source = "suggested"
+		}
+		fmt.Printf("  %s <-> %s (%s, %d joins)\n", r.Left, r.Right, source, r.Occurrences)
+		for _, k := range r.Keys {
+			fmt.Printf("    %s.%s = %s.%s (%d)\n", r.Left, k.LeftColumn, r.Right, k.RightColumn, k.Occurrences)
+		}
+	}
+}
+
+func runSnapshotExport(ctx context.Context, out, origin, databases, ddl, schema, sqlPath, vars string, anonymize bool, salt string, format render.Format) {
+	provider, graph, _ := loadLineage(ddl, schema, sqlPath, vars)
+
+	opts := snapshot.Options{Origin: origin}
+	if databases != "" {
+		opts.Databases = strings.Split(databases, ",")
+	}
+	if anonymize {
+		if salt == "" {
+			key := make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				fmt.Printf("Error generating salt: %v\n", err)
+				os.Exit(1)
+			}
+			salt = base64.StdEncoding.EncodeToString(key)
+		}
+		opts.Anonymize = snapshot.NewAnonymizer(salt)
+	}
+
+	file, err := os.Create(out)
+	if err != nil {
+		fmt.Printf("Error creating snapshot: %v\n", err)
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	svc := lineageService.NewService(nil, nil)
+	svc.MergedGraph().Merge(graph)
+	manifest, err := svc.ExportSnapshot(ctx, file, provider.AllTables(), opts)
+	if err != nil {
+		fmt.Printf("Error writing snapshot: %v\n", err)
+		os.Exit(1)
+	}
+	if format.Structured() {
+		fmt.Fprintf(os.Stderr, "Snapshot written to %s\n", out)
+		writeFormatted(format, manifest)
+		return
+	}
+	fmt.Printf("Snapshot written to %s\n", out)
+	printManifest(manifest)
+}
+
+func runSnapshotImport(ctx context.Context, path, schemaOut string, format render.Format) {
+	if path == "" {
+		fmt.Println("Error: a snapshot file must be provided")
+		os.Exit(1)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Printf("Error opening snapshot: %v\n", err)
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	provider := metadata.NewMemoryProvider()
+	svc := lineageService.NewService(nil, nil)
+	manifest, err := svc.ImportSnapshot(ctx, file, provider)
+	if err != nil {
+		fmt.Printf("Error importing snapshot: %v\n", err)
+		os.Exit(1)
+	}
+	msg := os.Stdout
+	if format.Structured() {
+		msg = os.Stderr
+	}
+	fmt.Fprintf(msg, "Snapshot %s verified\n", path)
+	writeManifest(manifest, format)
+	writeSchema(msg, provider, schemaOut)
+}
+
+func runBackup(ctx context.Context, config *lineageService.BackupConfig, ddl, schema, sqlPath, vars string, format render.Format) {
+	provider, graph, _ := loadLineage(ddl, schema, sqlPath, vars)
+
+	svc := lineageService.NewService(nil, nil)
+	svc.MergedGraph().Merge(graph)
+	backuper := svc.NewBackuper(config, provider, log.NewStdLogger(os.Stdout))
+
+	path, err := backuper.RunOnce(ctx, time.Now())
+	if err != nil {
+		fmt.Printf("Error writing backup: %v\n", err)
+		os.Exit(1)
+	}
+	if config.Interval <= 0 {
+		if format.Structured() {
+			writeFormatted(format, struct {
+				Path string `json:"path"`
+			}{path})
+			return
+		}
+		fmt.Printf("Backup written to %s\n", path)
+		return
+	}
+
+	// Daemon mode: keep taking backups until interrupted.
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	backuper.Start(ctx)
+	<-ctx.Done()
+	backuper.Stop()
+}
+
+func runRestore(ctx context.Context, path, schemaOut string, format render.Format) {
+	if path == "" {
+		fmt.Println("Error: a backup file or directory must be provided")
+		os.Exit(1)
+	}
+
+	provider := metadata.NewMemoryProvider()
+	svc := lineageService.NewService(nil, nil)
+	restored, manifest, err := svc.RestoreBackup(ctx, path, provider)
+	if err != nil {
+		fmt.Printf("Error restoring backup: %v\n", err)
+		os.Exit(1)
+	}
+	msg := os.Stdout
+	if format.Structured() {
+		msg = os.Stderr
+	}
+	fmt.Fprintf(msg, "Backup %s restored\n", restored)
+	writeManifest(manifest, format)
+	writeSchema(msg, provider, schemaOut)
+}
+
+func runApply(ctx context.Context, file, database, server string, dryRun, yes bool, format render.Format) {
+	if file == "" {
+		fmt.Println("Error: a change file must be provided with -f")
+		os.Exit(1)
+	}
+	// The confirmation would interleave with structured output
+	if format.Structured() && !dryRun && !yes {
+		fmt.Println("Error: --json, --yaml and --table need -dry-run or -yes")
+		os.Exit(1)
+	}
+	var changes []*biz.Change
+	var err error
+	if isSQLPath(file) {
+		changes, err = sqlChanges(file, database)
+	} else {
+		changes, err = readChanges(file)
+	}
+	if err != nil {
+		fmt.Printf("Error reading change file: %v\n", err)
+		os.Exit(1)
+	}
+	if len(changes) == 0 {
+		fmt.Println("No changes in", file)
+		return
+	}
+
+	// Preview the plan, then apply exactly what was previewed: the table
+	// versions of the plan make the server reject tables modified since.
+	plan, err := postApply(ctx, server, &service.ApplyRequest{Changes: changes, DryRun: true})
+	if err != nil {
+		fmt.Printf("Error planning changes: %v\n", err)
+		os.Exit(1)
+	}
+	failed := plan.Count(biz.PlanNotFound) + plan.Count(biz.PlanInvalid)
+	updates := plan.Count(biz.PlanUpdate)
+	if !format.Structured() {
+		printPlan(plan)
+	} else if failed > 0 || updates == 0 || dryRun {
+		writeFormatted(format, plan)
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %d tables cannot be changed; nothing was applied\n", failed)
+		os.Exit(1)
+	}
+	if updates == 0 || dryRun {
+		return
+	}
+	if !yes && !confirm(fmt.Sprintf("Apply changes to %d tables?", updates)) {
+		fmt.Println("Apply cancelled")
+		return
+	}
+
+	versions := make(map[string]int64, updates)
+	for _, e := range plan.Entities {
+		if e.Action == biz.PlanUpdate {
+			versions[e.Table] = e.Version
+		}
+	}
+	applied, err := postApply(ctx, server, &service.ApplyRequest{Changes: changes, Versions: versions})
+	if err != nil {
+		fmt.Printf("Error applying changes: %v\n", err)
+		os.Exit(1)
+	}
+	if format.Structured() {
+		writeFormatted(format, applied)
+	}
+	if !applied.Applied {
+		if !format.Structured() {
+			printPlan(applied)
+		}
+		fmt.Fprintln(os.Stderr, "Error: the tables changed since the plan; nothing was applied")
+		os.Exit(1)
+	}
+	if !format.Structured() {
+		fmt.Printf("Applied changes to %d tables\n", applied.Count(biz.PlanUpdate))
+	}
+}
+
+// readChanges reads a YAML, JSON or CSV change file.
+func readChanges(file string) ([]*biz.Change, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	format := biz.FormatYAML
+	if strings.EqualFold(filepath.Ext(file), ".csv") {
+		format = biz.FormatCSV
+	}
+	changes, err := biz.ParseChanges(f, format)
+	if err != nil {
+		return nil, err
+	}
+	return changes, readReadmes(changes, filepath.Dir(file))
+}
+
+// readReadmes sets the README of the changes that only give a readme_path to
+// the content of that file, relative to dir. URLs are kept as links.
+func readReadmes(changes []*biz.Change, dir string) error {
+	for _, c := range changes {
+		if c.Readme != nil || c.ReadmePath == nil || *c.ReadmePath == "" || strings.Contains(*c.ReadmePath, "://") {
+			continue
+		}
+		path := *c.ReadmePath
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: readme: %w", c.Table, err)
+		}
+		readme := string(content)
+		c.Readme = &readme
+	}
+	return nil
+}
+
+// isSQLPath reports whether path names SQL files: a .sql file or pattern, or
+// a directory.
+func isSQLPath(path string) bool {
+	if strings.EqualFold(filepath.Ext(path), ".sql") {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// sqlChanges returns the changes declared by the comment annotations of the
+// statements of SQL files, e.g. "-- @owner team-data", for the tables they
+// create or write. Unqualified tables are in database.
+func sqlChanges(path, database string) ([]*biz.Change, error) {
+	files, err := textfile.Expand(path, ".sql")
+	if err != nil {
+		return nil, err
+	}
+	ddl := metadata.NewDDLParser()
+	analyzer := lineageCore.NewAnalyzer(nil)
+
+	var changes []*biz.Change
+	byTable := make(map[string]*biz.Change)
+	for _, name := range files {
+		n := 0
+		err := scanSQLFile(name, func(stmt string) error {
+			n++
+			annotations := lineageCore.ParseDatasetAnnotations(lineageCore.ExtractComments(stmt))
+			if annotations == nil {
+				return nil
+			}
+			tables, err := statementTables(ddl, analyzer, stmt, database)
+			if err != nil {
+				return fmt.Errorf("%s statement %d: %w", name, n, err)
+			}
+			for _, table := range tables {
+				c, ok := byTable[table]
+				if !ok {
+					c = &biz.Change{Table: table}
+					byTable[table] = c
+					changes = append(changes, c)
+				}
+				mergeAnnotations(c, annotations)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return changes, biz.ValidateChanges(changes)
+}
+
+// statementTables returns the tables a statement creates or writes, as
+// database.table or database.schema.table.
+func statementTables(ddl *metadata.DDLParser, analyzer *lineageCore.Analyzer, stmt, database string) ([]string, error) {
+	qualify := func(db, schema, table string) (string, error) {
+		if db == "" {
+			db = database
+		}
+		if db == "" {
+			return "", fmt.Errorf("table %s has no database, set one with -database", table)
+		}
+		if schema != "" {
+			return db + "." + schema + "." + table, nil
+		}
+		return db + "." + table, nil
+	}
+
+	if schema, err := ddl.ParseDDL(stmt); err == nil && schema != nil {
+		table, err := qualify(schema.Database, schema.Schema, schema.Table)
+		if err != nil {
+			return nil, err
+		}
+		return []string{table}, nil
+	}
+
+	result, err := analyzer.Analyze(stmt)
+	if err != nil {
+		return nil, err
+	}
+	var tables []string
+	for _, col := range result.Columns {
+		table, err := qualify(col.Target.Database, "", col.Target.Table)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(tables, table) {
+			tables = append(tables, table)
+		}
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("annotations on a statement that writes no table")
+	}
+	return tables, nil
+}
+
+// mergeAnnotations adds comment annotations to a change. Owners and tags of
+// the statements of a table add up; other annotations than these are not
+// applied.
+func mergeAnnotations(c *biz.Change, a *lineageCore.DatasetAnnotations) {
+	appendList := func(list **[]string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		if *list == nil {
+			*list = &[]string{}
+		}
+		**list = append(**list, items...)
+	}
+	appendList(&c.Owners, a.Owners)
+	appendList(&c.Tags, a.Tags)
+	if a.Description != "" {
+		c.Description = &a.Description
+	}
+	if a.Deprecated {
+		deprecated := true
+		c.Deprecated = &deprecated
+		if a.DeprecationNote != "" {
+			c.DeprecationNote = &a.DeprecationNote
+		}
+	}
+	for column, tags := range a.ColumnTags {
+		if c.Columns == nil {
+			c.Columns = make(map[string]*biz.ColumnChange)
+		}
+		cc, ok := c.Columns[column]
+		if !ok {
+			cc = &biz.ColumnChange{}
+			c.Columns[column] = cc
+		}
+		appendList(&cc.Tags, tags)
+	}
+}
+
+// policyViolationsExitCode is the exit code of a policy check that found
+// violations, distinct from the exit code 1 of a check that failed to run.
+const policyViolationsExitCode = 2
+
+func runPolicyPush(ctx context.Context, file, server string) {
+	if file == "" {
+		fmt.Println("Error: a policy file must be provided with -f")
+		os.Exit(1)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		fmt.Printf("Error opening policy file: %v\n", err)
+		os.Exit(1)
+	}
+	set, err := biz.ParsePolicies(f)
+	f.Close()
+	if err != nil {
+		fmt.Printf("Error reading policy file: %v\n", err)
+		os.Exit(1)
+	}
+	if err := callServer(ctx, http.MethodPut, server, "/api/v1/policies", set, &biz.PolicySet{}); err != nil {
+		fmt.Printf("Error uploading policies: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Uploaded %d policies; tables are checked on their next sync\n", len(set.Policies))
+}
+
+func runSecretsMigrate(ctx context.Context, configPath, server string, dryRun bool) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		fmt.Printf("Error reading config file: %v\n", err)
+		os.Exit(1)
+	}
+	var root yaml.Node
+	var cfg struct {
+		Encryption *auth.EncryptionConfig `yaml:"encryption"`
+	}
+	if err := yaml.Unmarshal(data, &root); err == nil {
+		err = root.Decode(&cfg)
+	}
+	if err != nil {
+		fmt.Printf("Error parsing config file: %v\n", err)
+		os.Exit(1)
+	}
+	keyring, err := auth.NewKeyring(cfg.Encryption)
+	if err != nil {
+		fmt.Printf("Error loading encryption keys from %s: %v\n", configPath, err)
+		os.Exit(1)
+	}
+
+	n, err := auth.EncryptYAMLSecrets(&root, keyring)
+	if err != nil {
+		fmt.Printf("Error encrypting credentials: %v\n", err)
+		os.Exit(1)
+	}
+	switch {
+	case dryRun:
+		fmt.Printf("%d credentials in %s would be encrypted with key %s\n", n, configPath, keyring.Primary())
+		return
+	case n > 0:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&root); err != nil {
+			fmt.Printf("Error writing config file: %v\n", err)
+			os.Exit(1)
+		}
+		info, err := os.Stat(configPath)
+		if err == nil {
+			err = os.WriteFile(configPath, buf.Bytes(), info.Mode().Perm())
+		}
+		if err != nil {
+			fmt.Printf("Error writing config file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("Encrypted %d credentials in %s with key %s\n", n, configPath, keyring.Primary())
+
+	if server == "" {
+		return
+	}
+	var result biz.SecretMigrationResult
+	if err := callServer(ctx, http.MethodPost, server, "/api/v1/datasources/secrets/migrate", nil, &result); err != nil {
+		fmt.Printf("Error migrating stored credentials: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Encrypted the credentials of %d of %d stored data sources\n", result.Migrated, result.Scanned)
+}
+
+func runSecretsKeygen() {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		fmt.Printf("Error generating key: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(base64.StdEncoding.EncodeToString(key))
+}
+
+func runPolicyCheck(ctx context.Context, server, failOn string, format render.Format) {
+	if failOn != biz.SeverityError && failOn != biz.SeverityWarning {
+		fmt.Println("Error: -fail-on must be error or warning")
+		os.Exit(1)
+	}
+	var resp service.ListViolationsResponse
+	if err := callServer(ctx, http.MethodGet, server, "/api/v1/policies/violations", nil, &resp); err != nil {
+		fmt.Printf("Error listing policy violations: %v\n", err)
+		os.Exit(1)
+	}
+	failed := 0
+	for _, v := range resp.Violations {
+		if v.Severity == biz.SeverityError || failOn == biz.SeverityWarning {
+			failed++
+		}
+	}
+	switch {
+	case format.Structured():
+		if resp.Violations == nil {
+			resp.Violations = []*biz.PolicyViolation{}
+		}
+		writeFormatted(format, resp.Violations)
+	case len(resp.Violations) == 0:
+		fmt.Println("No policy violations")
+	default:
+		for _, v := range resp.Violations {
+			name := v.Table
+			if v.Column != "" {
+				name += "." + v.Column
+			}
+			fmt.Printf("%-7s %s %s [%s]\n", v.Severity, name, v.Message, v.Policy)
+		}
+		fmt.Printf("\n%d policy violations\n", len(resp.Violations))
+	}
+	if failed > 0 {
+		os.Exit(policyViolationsExitCode)
+	}
+}
+
+func runStorageReport(ctx context.Context, server, groupBy, interval, from, to, csvFile string, format render.Format) {
+	query := url.Values{}
+	query.Set("group_by", groupBy)
+	for name, v := range map[string]string{"interval": interval, "from": from, "to": to} {
+		if v != "" {
+			query.Set(name, v)
+		}
+	}
+
+	var resp service.StorageReportResponse
+	if err := callServer(ctx, http.MethodGet, server, "/api/v1/reports/storage?"+query.Encode(), nil, &resp); err != nil {
+		fmt.Printf("Error building storage report: %v\n", err)
+		os.Exit(1)
+	}
+
+	if csvFile != "" {
+		f, err := os.Create(csvFile)
+		if err != nil {
+			fmt.Printf("Error creating CSV file: %v\n", err)
+			os.Exit(1)
+		}
+		if err := biz.WriteStorageCSV(f, resp.Rollups); err != nil {
+			f.Close()
+			fmt.Printf("Error writing CSV file: %v\n", err)
+			os.Exit(1)
+		}
+		if err := f.Close(); err != nil {
+			fmt.Printf("Error writing CSV file: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %d roll-ups to %s\n", len(resp.Rollups), csvFile)
+		return
+	}
+
+	if format.Structured() {
+		if resp.Rollups == nil {
+			resp.Rollups = []*biz.StorageRollup{}
+		}
+		writeFormatted(format, resp.Rollups)
+		return
+	}
+	if len(resp.Rollups) == 0 {
+		fmt.Println("No table sizes recorded")
+		return
+	}
+	for _, r := range resp.Rollups {
+		name := r.Source
+		if r.Database != "" || r.Schema != "" {
+			name += " " + strings.Trim(r.Database+"."+r.Schema, ".")
+		}
+		period := ""
+		if !r.Period.IsZero() {
+			period = r.Period.Format("2006-01-02") + "  "
+		}
+		growth := formatBytes(r.DataSizeGrowth)
+		if r.DataSizeGrowth >= 0 {
+			growth = "+" + growth
+		}
+		fmt.Printf("%s%-40s %6d tables %14d rows %10s (%s)\n",
+			period, name, r.Tables, r.RowCount, formatBytes(r.DataSize), growth)
+	}
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. 1.5 GiB.
+func formatBytes(n int64) string {
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%s%d B", sign, n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%s%.1f %ciB", sign, float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func runDuplicates(ctx context.Context, server string, threshold float64, minColumns int, crossSource bool, format render.Format) {
+	query := url.Values{}
+	query.Set("threshold", strconv.FormatFloat(threshold, 'f', -1, 64))
+	query.Set("min_columns", strconv.Itoa(minColumns))
+	query.Set("cross_source", strconv.FormatBool(crossSource))
+
+	var resp service.FindDuplicatesResponse
+	if err := callServer(ctx, http.MethodGet, server, "/api/v1/tables/duplicates?"+query.Encode(), nil, &resp); err != nil {
+		fmt.Printf("Error finding duplicates: %v\n", err)
+		os.Exit(1)
+	}
+	if format.Structured() {
+		if resp.Duplicates == nil {
+			resp.Duplicates = []*biz.DuplicateCandidate{}
+		}
+		writeFormatted(format, resp.Duplicates)
+		return
+	}
+	if len(resp.Duplicates) == 0 {
+		fmt.Println("No duplicated datasets found")
+		return
+	}
+
+	for _, d := range resp.Duplicates {
+		left, right := d.Left, d.Right
+		if d.LeftSource != "" {
+			left += " (" + d.LeftSource + ")"
+		}
+		if d.RightSource != "" {
+			right += " (" + d.RightSource + ")"
+		}
+		fmt.Printf("%5.1f%%  %s  ~  %s\n", d.Similarity*100, left, right)
+		fmt.Printf("        shared columns: %s\n", strings.Join(d.SharedColumns, ", "))
+		if len(d.TypeMismatches) > 0 {
+			fmt.Printf("        type mismatches: %s\n", strings.Join(d.TypeMismatches, ", "))
+		}
+	}
+	fmt.Printf("\n%d likely duplicated table pairs\n", len(resp.Duplicates))
+}
+
+// postApply sends an apply request to the metadata server.
+func postApply(ctx context.Context, server string, req *service.ApplyRequest) (*biz.ApplyPlan, error) {
+	var plan biz.ApplyPlan
+	if err := callServer(ctx, http.MethodPost, server, "/api/v1/metadata/apply", req, &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// callServer sends a JSON request to the metadata server, if in is not nil,
+// and decodes the JSON response into out.
+func callServer(ctx context.Context, method, server, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(server, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("%s: %s", resp.Status, apiErr.Message)
+		}
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// printPlan prints a plan in the style of a diff: ~ tables to update with
+// their changed fields, = unchanged tables and ! tables that cannot be changed.
+func printPlan(plan *biz.ApplyPlan) {
+	for _, e := range plan.Entities {
+		switch e.Action {
+		case biz.PlanUpdate:
+			fmt.Printf("~ %s\n", e.Table)
+			for _, d := range e.Diffs {
+				fmt.Printf("    %s: %s -> %s\n", d.Field, previewValue(d.Old), previewValue(d.New))
+			}
+		case biz.PlanUnchanged:
+			fmt.Printf("= %s (unchanged)\n", e.Table)
+		case biz.PlanNotFound:
+			fmt.Printf("! %s (not found)\n", e.Table)
+		default:
+			fmt.Printf("! %s (%s: %s)\n", e.Table, e.Action, e.Error)
+		}
+	}
+	fmt.Printf("\nPlan: %d to update, %d unchanged, %d not found, %d invalid\n",
+		plan.Count(biz.PlanUpdate), plan.Count(biz.PlanUnchanged), plan.Count(biz.PlanNotFound), plan.Count(biz.PlanInvalid))
+}
+
+// previewValue quotes a field value for a plan, shortening long values.
+func previewValue(v string) string {
+	const maxLen = 60
+	if v == "" {
+		return "(none)"
+	}
+	if r := []rune(v); len(r) > maxLen {
+		v = string(r[:maxLen]) + "..."
+	}
+	return strconv.Quote(v)
+}
+
+// writeFormatted writes v to stdout in a structured output format.
+func writeFormatted(format render.Format, v any) {
+	if err := render.Write(os.Stdout, format, v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// jsonFormat returns the output format of a command with a -json option of
+// its own, which --yaml and --table override.
+func jsonFormat(asJSON bool, format render.Format) render.Format {
+	if asJSON && !format.Structured() {
+		return render.JSON
+	}
+	return format
+}
+
+// confirm asks a yes/no question on the terminal, defaulting to no.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// writeSchema writes the tables of provider as a JSON schema file usable with
+// -schema, if path is set, and reports it to msg.
+func writeSchema(msg io.Writer, provider *metadata.MemoryProvider, path string) {
+	if path == "" {
+		return
+	}
+	data, err := provider.ExportToJSON()
+	if err != nil {
+		fmt.Printf("Error exporting tables: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		fmt.Printf("Error writing schema file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(msg, "Tables written to %s\n", path)
+}
+
+// writeManifest prints a manifest, or writes it in a structured format.
+func writeManifest(m *snapshot.Manifest, format render.Format) {
+	if format.Structured() {
+		writeFormatted(format, m)
+		return
+	}
+	printManifest(m)
+}
+
+func printManifest(m *snapshot.Manifest) {
+	fmt.Printf("  Format version: %d\n", m.Version)
+	fmt.Printf("  Created at:     %s\n", m.CreatedAt.Format(time.RFC3339))
+	if m.Origin != "" {
+		fmt.Printf("  Origin:         %s\n", m.Origin)
+	}
+	if m.Partial() {
+		fmt.Printf("  Databases:      %s\n", strings.Join(m.Databases, ", "))
+	}
+	if m.Anonymized {
+		fmt.Println("  Anonymized:     yes")
+	}
+	for _, e := range m.Entries {
+		fmt.Printf("  %-15s %d entities (sha256 %s)\n", e.Name+":", e.Count, e.SHA256[:12])
+	}
+}
+
+// openBrowser opens url in the default browser, ignoring failures since the
+// URL is printed as well.
+func openBrowser(url string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	_ = cmd.Start()
+}
+
+// loadLineage builds the catalog from DDL and JSON schema files and the
+// lineage graph from the SQL files under sqlPath. The table relationships
+// are the foreign keys of the catalog and the joins of the SQL files.
+func loadLineage(ddl, schema, sqlPath, vars string) (*metadata.MemoryProvider, *lineageCore.Graph, *lineageCore.RelationshipStats) {
+	provider := loadCatalog(ddl, schema)
+	joins := lineageCore.NewRelationshipStats()
+	for _, t := range provider.AllTables() {
+		for _, key := range metadata.ForeignKeyJoins(t) {
+			joins.AddForeignKey(key)
+		}
+	}
+
+	analyzer := lineageCore.NewAnalyzer(metadata.NewCatalogAdapter(provider))
+	analyzer.SetTemplateResolver(parseTemplateVars(vars))
+	analyzer.RegisterSnowflake(nil)
+
+	graph := lineageCore.NewGraph()
+	if sqlPath != "" {
+		files, err := textfile.Expand(sqlPath, ".sql")
+		if err != nil {
+			fmt.Printf("Error reading SQL files: %v\n", err)
+			os.Exit(1)
+		}
+		for _, file := range files {
+			err := scanSQLFile(file, func(stmt string) error {
+				result, err := analyzer.Analyze(stmt)
+				if err != nil {
+					fmt.Printf("Warning: skipping a statement of %s: %v\n", file, err)
+					return nil
+				}
+				graph.AddFrom(result, lineageCore.Fingerprint(stmt), lineageCore.OriginAnalysis, time.Now())
+				joins.Add(result, time.Now())
+				return nil
+			})
+			if err != nil {
+				fmt.Printf("Error reading file: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+	return provider, graph, joins
+}
+
+// loadCatalog builds the catalog from DDL and JSON schema files.
+func loadCatalog(ddl, schema string) *metadata.MemoryProvider {
+	builder := metadata.NewMetadataBuilder()
+	if ddl != "" {
+		content, err := textfile.ReadFile(ddl)
+		if err != nil {
+			fmt.Printf("Error reading DDL file: %v\n", err)
+			os.Exit(1)
+		}
+		builder.LoadFromDDL(content)
+	}
+	provider := builder.Build()
+	if schema != "" {
+		if err := provider.LoadFromJSON(schema); err != nil {
+			fmt.Printf("Error loading schema file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	return provider
+}
+
+// scanSQLFile calls fn with each statement of a SQL file, or of stdin for
+// "-", as it is read.
+func scanSQLFile(name string, fn func(stmt string) error) error {
+	r, err := textfile.Open(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return lineageCore.ScanStatements(r, fn)
+}
+
+// readSQLFile returns the contents of a SQL file, or of stdin for "-".
+func readSQLFile(name string) (string, error) {
+	r, err := textfile.Open(name)
+	if err != nil {
+		return "", err
 	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	return string(data), err
 }
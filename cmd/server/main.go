@@ -1,14 +1,35 @@
 package main
 
 import (
+	"context"
+	"crypto/fips140"
 	"flag"
+	"fmt"
 	"os"
+	"time"
 
+	"go-metadata/internal/auth"
+	"go-metadata/internal/biz"
+	collectorConfig "go-metadata/internal/collector/config"
+	_ "go-metadata/internal/collector/drivers"
+	"go-metadata/internal/collector/factory"
 	"go-metadata/internal/conf"
+	"go-metadata/internal/data/graph"
+	"go-metadata/internal/data/graph/nebula"
+	"go-metadata/internal/data/graph/neo4j"
+	lineageCore "go-metadata/internal/lineage"
+	"go-metadata/internal/notify"
+	"go-metadata/internal/redact"
+	"go-metadata/internal/scheduler"
+	lineageService "go-metadata/internal/service/lineage"
+	metadataService "go-metadata/internal/service/metadata"
+	"go-metadata/internal/store"
+	"go-metadata/internal/tlsprofile"
 
 	"github.com/go-kratos/kratos/v2"
 	"github.com/go-kratos/kratos/v2/config"
 	"github.com/go-kratos/kratos/v2/config/file"
+	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware/tracing"
 	"github.com/go-kratos/kratos/v2/transport/grpc"
@@ -31,7 +52,14 @@ func init() {
 	flag.StringVar(&flagconf, "conf", "../../configs", "config path, eg: -conf config.yaml")
 }
 
-func newApp(logger log.Logger, gs *grpc.Server, hs *http.Server) *kratos.App {
+// newApp creates the application of the servers. Syncs of md merge the
+// tables they store into the catalog of tables. The lineage compactor applies
+// the retention policies of compaction to the lineage graph of ls, and sm
+// schedules collection tasks, while the servers run.
+func newApp(logger log.Logger, gs *grpc.Server, hs *http.Server, md *metadataService.Service, tables *biz.TableUsecase,
+	ls *lineageService.Service, compaction *lineageService.CompactionConfig, sm *scheduler.SchedulerManager) *kratos.App {
+	md.SetTables(tables)
+	compactor := ls.NewCompactor(compaction, logger)
 	return kratos.New(
 		kratos.ID(id),
 		kratos.Name(Name),
@@ -42,12 +70,19 @@ func newApp(logger log.Logger, gs *grpc.Server, hs *http.Server) *kratos.App {
 			gs,
 			hs,
 		),
+		kratos.BeforeStart(compactor.Start),
+		kratos.BeforeStart(sm.Initialize),
+		kratos.AfterStop(func(context.Context) error {
+			return compactor.Stop()
+		}),
+		kratos.AfterStop(sm.Shutdown),
 	)
 }
 
 func main() {
 	flag.Parse()
-	logger := log.With(log.NewStdLogger(os.Stdout),
+	// Credentials in errors and DSNs echoed by drivers are masked
+	logger := log.With(redact.Logger(log.NewStdLogger(os.Stdout)),
 		"ts", log.DefaultTimestamp,
 		"caller", log.DefaultCaller,
 		"service.id", id,
@@ -61,6 +96,8 @@ func main() {
 		config.WithSource(
 			file.NewSource(flagconf),
 		),
+		config.WithDecoder(decodeConfig),
+		config.WithResolver(keepSecretReferences),
 	)
 	defer c.Close()
 
@@ -73,7 +110,52 @@ func main() {
 		panic(err)
 	}
 
-	app, cleanup, err := wireApp(bc.Server, bc.Data, logger)
+	if err := applyTLSProfile(c, logger); err != nil {
+		panic(err)
+	}
+
+	cipher, err := newSecretCipher(c)
+	if err != nil {
+		panic(err)
+	}
+
+	md, err := newMetadataService(c)
+	if err != nil {
+		panic(err)
+	}
+	defer md.Close()
+
+	st, err := newMetadataStore(c)
+	if err != nil {
+		panic(err)
+	}
+	if st != nil {
+		defer st.Close()
+		md.SetStore(st)
+	}
+
+	notifier, err := newNotifier(c, st, logger)
+	if err != nil {
+		panic(err)
+	}
+	if notifier != nil {
+		md.SetNotifier(notifier)
+	}
+
+	graphDB, err := newGraphDB(context.Background(), c)
+	if err != nil {
+		panic(err)
+	}
+	if graphDB != nil {
+		defer graphDB.Close()
+	}
+
+	compaction, err := newCompactionConfig(c)
+	if err != nil {
+		panic(err)
+	}
+
+	app, cleanup, err := wireApp(bc.Server, bc.Data, bc.Scheduler, cipher, md, md.Store(), graphDB, compaction, logger)
 	if err != nil {
 		panic(err)
 	}
@@ -84,3 +166,221 @@ func main() {
 		panic(err)
 	}
 }
+
+// applyTLSProfile restricts all outbound TLS to the tls section of the config.
+// Drivers that negotiate TLS themselves (postgres, sqlserver, oracle, hive) and
+// TLS 1.3 cipher suites only follow the fips profile in FIPS 140-3 mode.
+func applyTLSProfile(c config.Config, logger log.Logger) error {
+	var tc tlsprofile.Config
+	if err := c.Value("tls").Scan(&tc); err != nil {
+		return nil
+	}
+	if err := tlsprofile.Apply(&tc); err != nil {
+		return err
+	}
+	if tc.Profile == tlsprofile.ProfileFIPS && !fips140.Enabled() {
+		log.NewHelper(logger).Warn("tls profile fips: Go is not in FIPS 140-3 mode, run with GODEBUG=fips140=on to restrict TLS 1.3 and database drivers without a TLS hook")
+	}
+	return nil
+}
+
+// newSecretCipher creates the keyring encrypting connector credentials from
+// the encryption section of the config, or returns nil if there is none.
+func newSecretCipher(c config.Config) (biz.SecretCipher, error) {
+	var ec auth.EncryptionConfig
+	if err := c.Value("encryption").Scan(&ec); err != nil || len(ec.Keys) == 0 {
+		return nil, nil
+	}
+	return auth.NewKeyring(&ec)
+}
+
+// decodeConfig decodes a config file, decrypting the credentials encrypted by
+// secrets migrate with the keys of its encryption section, so that every
+// section sees plaintext credentials.
+func decodeConfig(kv *config.KeyValue, target map[string]interface{}) error {
+	value := kv.Value
+	if kv.Format == "yaml" {
+		var err error
+		if value, err = auth.DecryptConfig(value); err != nil {
+			return fmt.Errorf("decrypt %s: %w", kv.Key, err)
+		}
+	}
+	codec := encoding.GetCodec(kv.Format)
+	if codec == nil {
+		return fmt.Errorf("unsupported config format: %s", kv.Format)
+	}
+	return codec.Unmarshal(value, &target)
+}
+
+// keepSecretReferences replaces the placeholder resolver of kratos, which
+// would expand the ${env:NAME} and ${vault:path#field} credential references
+// into their default values NAME and path#field. The config has no kratos
+// placeholders; credential references are resolved when the collectors are
+// created (see newMetadataService).
+func keepSecretReferences(map[string]interface{}) error {
+	return nil
+}
+
+// newMetadataStore opens the PostgreSQL or SQLite store that syncs persist
+// harvested metadata to from the store section of the config, or returns nil
+// if there is none.
+func newMetadataStore(c config.Config) (*store.Store, error) {
+	var sc store.Config
+	if err := c.Value("store").Scan(&sc); err != nil || sc.DSN == "" {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	return store.Open(ctx, &sc)
+}
+
+// newMetadataService creates the metadata service with a collector for each
+// source of the collectors section of the config, whose credentials were
+// decrypted by decodeConfig, resolving ${env:...} and ${vault:...}
+// credential references. Collectors connect on first use, so an unreachable
+// source does not prevent startup. The tier rules of the tiers section apply
+// to the tables of all sources.
+func newMetadataService(c config.Config) (*metadataService.Service, error) {
+	md := metadataService.NewService(nil)
+	var tiers collectorConfig.TierConfig
+	if err := c.Value("tiers").Scan(&tiers); err == nil {
+		if err := tiers.Validate(); err != nil {
+			return nil, err
+		}
+		md.SetTiers(&tiers)
+	}
+	var sources []*collectorConfig.ConnectorConfig
+	if err := c.Value("collectors").Scan(&sources); err != nil {
+		return md, nil
+	}
+	for _, cfg := range sources {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := cfg.ResolveCredentials(ctx)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("collector %s: %w", cfg.ID, err)
+		}
+		col, err := factory.Create(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("collector %s: %w", cfg.ID, err)
+		}
+		md.RegisterCollector(cfg.ID, col)
+		md.SetTimeouts(cfg.ID, cfg.Timeouts)
+		harvest, err := cfg.Harvest()
+		if err != nil {
+			return nil, fmt.Errorf("collector %s: %w", cfg.ID, err)
+		}
+		md.SetHarvest(cfg.ID, harvest)
+	}
+	return md, nil
+}
+
+// newGraphDB connects to the graph database of the graph section of the
+// config, which the lineage graph is persisted to after syncs, or returns
+// nil if no type is configured.
+func newGraphDB(ctx context.Context, c config.Config) (graph.GraphDB, error) {
+	var gc struct {
+		Type      string `json:"type"`
+		Host      string `json:"host"`
+		Port      int    `json:"port"`
+		User      string `json:"user"`
+		Password  string `json:"password"`
+		Space     string `json:"space"`
+		BatchSize int    `json:"batch_size"`
+	}
+	if err := c.Value("graph").Scan(&gc); err != nil || gc.Type == "" {
+		return nil, nil
+	}
+	cfg := &graph.Config{
+		Type: gc.Type, Host: gc.Host, Port: gc.Port, User: gc.User, Password: gc.Password,
+		Space: gc.Space, BatchSize: gc.BatchSize,
+	}
+	var db graph.GraphDB
+	switch gc.Type {
+	case "neo4j":
+		db = neo4j.NewClient(cfg)
+	case "nebula":
+		db = nebula.NewClient(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported graph database type: %s", gc.Type)
+	}
+	if err := db.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("connect graph database: %w", err)
+	}
+	return db, nil
+}
+
+// newCompactionConfig reads the retention policies of the lineage.compaction
+// section of the config, or returns nil for the default policies if there is
+// none.
+func newCompactionConfig(c config.Config) (*lineageService.CompactionConfig, error) {
+	var cc struct {
+		Interval string `json:"interval"`
+		Policies []struct {
+			Origin string `json:"origin"`
+			MaxAge string `json:"max_age"`
+			Retire bool   `json:"retire"`
+		} `json:"policies"`
+	}
+	if err := c.Value("lineage.compaction").Scan(&cc); err != nil {
+		return nil, nil
+	}
+	compaction := lineageService.DefaultCompactionConfig()
+	if cc.Interval != "" {
+		interval, err := time.ParseDuration(cc.Interval)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("lineage.compaction: invalid interval %q", cc.Interval)
+		}
+		compaction.Interval = interval
+	}
+	if cc.Policies != nil {
+		compaction.Policies = make([]lineageCore.RetentionPolicy, 0, len(cc.Policies))
+		for _, p := range cc.Policies {
+			maxAge, err := time.ParseDuration(p.MaxAge)
+			if err != nil || maxAge <= 0 {
+				return nil, fmt.Errorf("lineage.compaction: invalid max_age %q of origin %q", p.MaxAge, p.Origin)
+			}
+			compaction.Policies = append(compaction.Policies, lineageCore.RetentionPolicy{
+				Origin: lineageCore.Origin(p.Origin),
+				MaxAge: maxAge,
+				Retire: p.Retire,
+			})
+		}
+	}
+	return compaction, nil
+}
+
+// newNotifier creates the dispatcher of the channels of the notifications
+// section of the config and of the subscriptions of users in the store, or
+// returns nil if there are neither. Failed notifications are logged.
+func newNotifier(c config.Config, st *store.Store, logger log.Logger) (biz.Notifier, error) {
+	var nc notify.Config
+	if err := c.Value("notifications").Scan(&nc); err != nil {
+		nc = notify.Config{}
+	}
+	if len(nc.Channels) == 0 && st == nil {
+		return nil, nil
+	}
+	d, err := notify.NewDispatcher(&nc)
+	if err != nil {
+		return nil, err
+	}
+	if st != nil {
+		d.SetWatchers(st)
+	}
+	return &loggedNotifier{d: d, log: log.NewHelper(logger)}, nil
+}
+
+// loggedNotifier logs the notifications that could not be sent.
+type loggedNotifier struct {
+	d   *notify.Dispatcher
+	log *log.Helper
+}
+
+func (n *loggedNotifier) Notify(ctx context.Context, notification *biz.Notification) error {
+	err := n.d.Notify(ctx, notification)
+	if err != nil {
+		n.log.Errorf("notifying %s of %s failed: %v", notification.Event, notification.Source, err)
+	}
+	return err
+}
@@ -9,8 +9,13 @@ import (
 	"go-metadata/internal/biz"
 	"go-metadata/internal/conf"
 	"go-metadata/internal/data"
+	"go-metadata/internal/data/graph"
+	"go-metadata/internal/scheduler"
 	"go-metadata/internal/server"
 	"go-metadata/internal/service"
+	lineageService "go-metadata/internal/service/lineage"
+	metadataService "go-metadata/internal/service/metadata"
+	"go-metadata/internal/store"
 
 	"github.com/go-kratos/kratos/v2"
 	"github.com/go-kratos/kratos/v2/log"
@@ -18,6 +23,6 @@ import (
 )
 
 // wireApp init kratos application.
-func wireApp(*conf.Server, *conf.Data, log.Logger) (*kratos.App, func(), error) {
-	panic(wire.Build(server.ProviderSet, data.ProviderSet, biz.ProviderSet, service.ProviderSet, newApp))
+func wireApp(*conf.Server, *conf.Data, *conf.Scheduler, biz.SecretCipher, *metadataService.Service, store.Repository, graph.GraphDB, *lineageService.CompactionConfig, log.Logger) (*kratos.App, func(), error) {
+	panic(wire.Build(server.ProviderSet, data.ProviderSet, biz.ProviderSet, service.ProviderSet, scheduler.ProviderSet, newApp))
 }
\ No newline at end of file
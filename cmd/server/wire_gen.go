@@ -12,31 +12,52 @@ import (
 	"go-metadata/internal/biz"
 	"go-metadata/internal/conf"
 	"go-metadata/internal/data"
+	"go-metadata/internal/data/graph"
+	"go-metadata/internal/scheduler"
 	"go-metadata/internal/server"
 	"go-metadata/internal/service"
+	"go-metadata/internal/service/lineage"
+	"go-metadata/internal/service/metadata"
+	"go-metadata/internal/store"
 )
 
 // Injectors from wire.go:
 
 // wireApp init kratos application.
-func wireApp(confServer *conf.Server, confData *conf.Data, logger log.Logger) (*kratos.App, func(), error) {
-	dataData, cleanup, err := data.NewData(confData, logger)
+func wireApp(confServer *conf.Server, confData *conf.Data, confScheduler *conf.Scheduler, secretCipher biz.SecretCipher, metadataService *metadata.Service, repository store.Repository, graphDB graph.GraphDB, compactionConfig *lineage.CompactionConfig, logger log.Logger) (*kratos.App, func(), error) {
+	dataData, cleanup, err := data.NewData(confData, repository, logger)
 	if err != nil {
 		return nil, nil, err
 	}
 	dataSourceRepo := data.NewDataSourceRepo(dataData, logger)
-	dataSourceUsecase := biz.NewDataSourceUsecase(dataSourceRepo, logger)
+	dataSourceUsecase := biz.NewDataSourceUsecase(dataSourceRepo, secretCipher, logger)
 	dataSourceService := service.NewDataSourceService(dataSourceUsecase, logger)
 	taskRepo := data.NewTaskRepo(dataData, logger)
 	taskUsecase := biz.NewTaskUsecase(taskRepo, logger)
 	taskService := service.NewTaskService(taskUsecase, logger)
+	syncProgressService := service.NewSyncProgressService(taskUsecase, logger)
 	templateRepo := data.NewTemplateRepo(dataData, logger)
 	templateUsecase := biz.NewTemplateUsecase(templateRepo, dataSourceRepo, logger)
 	templateService := service.NewTemplateService(templateUsecase, logger)
-	grpcServer := server.NewGRPCServer(confServer, logger, dataSourceService, taskService, templateService)
+	lineageService := service.NewLineageService(graphDB, metadataService)
+	tableRepo := data.NewTableRepo(dataData, logger)
+	policyRepo := data.NewPolicyRepo(dataData, logger)
+	tableUsecase := biz.NewTableUsecase(tableRepo, policyRepo, logger)
+	catalogService := service.NewCatalogService(metadataService, lineageService, tableUsecase, logger)
+	catalogGRPCService := service.NewCatalogGRPCService(catalogService, metadataService, logger)
+	lineageGRPCService := service.NewLineageGRPCService(lineageService)
+	grpcServer := server.NewGRPCServer(confServer, logger, dataSourceService, taskService, syncProgressService, templateService, catalogGRPCService, lineageGRPCService)
 	userService := service.NewUserService(logger)
-	httpServer := server.NewHTTPServer(confServer, logger, dataSourceService, taskService, templateService, userService)
-	app := newApp(logger, grpcServer, httpServer)
+	tableService := service.NewTableService(tableUsecase, logger)
+	httpServer, err := server.NewHTTPServer(confServer, logger, dataSourceService, taskService, templateService, userService, tableService, lineageService, catalogService)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	builtinScheduler := scheduler.NewBuiltinScheduler(logger)
+	dolphinSchedulerAdapter := scheduler.NewDolphinSchedulerAdapterFromConfig(confScheduler, logger)
+	schedulerManager := scheduler.ProvideSchedulerManager(confScheduler, builtinScheduler, dolphinSchedulerAdapter, taskRepo, dataSourceRepo, logger)
+	app := newApp(logger, grpcServer, httpServer, metadataService, tableUsecase, lineageService, compactionConfig, schedulerManager)
 	return app, func() {
 		cleanup()
 	}, nil
@@ -143,14 +143,9 @@ func MaskPassword(password string) string {
 func MaskConnectionConfig(config map[string]interface{}) map[string]interface{} {
 	masked := make(map[string]interface{})
 	for k, v := range config {
-		switch k {
-		case "password", "secret", "api_key", "access_key", "secret_key":
-			if str, ok := v.(string); ok && str != "" {
-				masked[k] = "********"
-			} else {
-				masked[k] = v
-			}
-		default:
+		if str, ok := v.(string); ok && str != "" && IsSensitiveKey(k) {
+			masked[k] = "********"
+		} else {
 			masked[k] = v
 		}
 	}
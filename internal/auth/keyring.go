@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 密钥环相关错误
+var (
+	ErrUnknownKey      = errors.New("unknown encryption key")
+	ErrInvalidKeyring  = errors.New("invalid encryption config")
+	ErrUnknownProvider = errors.New("unknown key provider")
+)
+
+// secretPrefix 标记已加密的凭证，格式为 enc:<密钥 ID>:<密文>
+const secretPrefix = "enc:"
+
+// keyID 密钥 ID 只能包含字母、数字、. _ -
+var keyID = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// encryptedValue 密钥环加密的凭证：enc:<密钥 ID>:<密文>，密文为 base64 (aes-gcm) 或 Vault 密文 (vault:v1:...)
+var encryptedValue = regexp.MustCompile(`^enc:[A-Za-z0-9_.-]+:[A-Za-z0-9+/=:]+$`)
+
+// EncryptionConfig 凭证加密配置
+//
+//	encryption:
+//	  primary: "2024-06"
+//	  keys:
+//	    - id: "2024-06"
+//	      provider: "aes-gcm"
+//	      key: "env:METADATA_SECRET_KEY"
+//	    - id: "2023-01"
+//	      provider: "vault-transit"
+//	      address: "https://vault:8200"
+//	      token: "file:/run/secrets/vault-token"
+//	      key_name: "metadata"
+type EncryptionConfig struct {
+	// Primary 新凭证使用的密钥 ID，其余密钥仅用于解密旧凭证
+	Primary string       `json:"primary" yaml:"primary"`
+	Keys    []*KeyConfig `json:"keys" yaml:"keys"`
+}
+
+// KeyConfig 密钥配置。Key、Token 可以是字面值，也可以是 env:变量名 或 file:路径
+type KeyConfig struct {
+	ID       string `json:"id" yaml:"id"`
+	Provider string `json:"provider" yaml:"provider"` // aes-gcm, vault-transit
+	// aes-gcm: base64 编码的 32 字节密钥
+	Key string `json:"key" yaml:"key"`
+	// vault-transit: Vault 地址、令牌与 transit 密钥名
+	Address string `json:"address" yaml:"address"`
+	Token   string `json:"token" yaml:"token"`
+	KeyName string `json:"key_name" yaml:"key_name"`
+}
+
+// KeyProvider 根据配置创建密钥的加密器
+type KeyProvider func(cfg *KeyConfig) (Encryptor, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]KeyProvider{
+		"aes-gcm":       newAESGCMKey,
+		"vault-transit": newVaultTransitKey,
+	}
+)
+
+// RegisterKeyProvider 注册密钥提供方 (如云 KMS、age)，供 provider 字段引用
+func RegisterKeyProvider(name string, p KeyProvider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = p
+}
+
+// Keyring 密钥环：使用主密钥加密，按密文中的密钥 ID 解密，支持密钥轮换
+type Keyring struct {
+	primary string
+	keys    map[string]Encryptor
+}
+
+// NewKeyring 根据配置创建密钥环
+func NewKeyring(cfg *EncryptionConfig) (*Keyring, error) {
+	if cfg == nil || len(cfg.Keys) == 0 {
+		return nil, fmt.Errorf("%w: no keys", ErrInvalidKeyring)
+	}
+	k := &Keyring{primary: cfg.Primary, keys: make(map[string]Encryptor, len(cfg.Keys))}
+	for _, kc := range cfg.Keys {
+		if kc == nil || !keyID.MatchString(kc.ID) {
+			return nil, fmt.Errorf("%w: key id must be letters, digits, '.', '_' and '-'", ErrInvalidKeyring)
+		}
+		if _, ok := k.keys[kc.ID]; ok {
+			return nil, fmt.Errorf("%w: key %s is defined more than once", ErrInvalidKeyring, kc.ID)
+		}
+		providersMu.RLock()
+		provider, ok := providers[kc.Provider]
+		providersMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("%w: %q (key %s)", ErrUnknownProvider, kc.Provider, kc.ID)
+		}
+		enc, err := provider(kc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %s: %v", ErrInvalidKeyring, kc.ID, err)
+		}
+		k.keys[kc.ID] = enc
+	}
+	if k.primary == "" && len(cfg.Keys) == 1 {
+		k.primary = cfg.Keys[0].ID
+	}
+	if _, ok := k.keys[k.primary]; !ok {
+		return nil, fmt.Errorf("%w: primary key %q is not defined", ErrInvalidKeyring, k.primary)
+	}
+	return k, nil
+}
+
+// Primary 返回主密钥 ID
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+// KeyIDs 返回所有密钥 ID
+func (k *Keyring) KeyIDs() []string {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Encrypt 使用主密钥加密
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	ciphertext, err := k.keys[k.primary].Encrypt(plaintext)
+	if err != nil {
+		return "", err
+	}
+	return secretPrefix + k.primary + ":" + ciphertext, nil
+}
+
+// Decrypt 使用密文标记的密钥解密保存的凭证；未加密的值 (包括不符合密文格式的 enc:foo) 原样返回，
+// 以便迁移前后的配置都能使用。用户提交的凭证是明文，不应经过 Decrypt
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	id, ciphertext, _ := strings.Cut(strings.TrimPrefix(value, secretPrefix), ":")
+	enc, ok := k.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	return enc.Decrypt(ciphertext)
+}
+
+// Current 判断值是否为空或已用主密钥加密，即无需迁移
+func (k *Keyring) Current(value string) bool {
+	return value == "" || IsEncrypted(value) && strings.HasPrefix(value, secretPrefix+k.primary+":")
+}
+
+// Reencrypt 将明文或旧密钥加密的值改用主密钥加密，返回新值及是否发生变化
+func (k *Keyring) Reencrypt(value string) (string, bool, error) {
+	if k.Current(value) {
+		return value, false, nil
+	}
+	plaintext, err := k.Decrypt(value)
+	if err != nil {
+		return "", false, err
+	}
+	ciphertext, err := k.Encrypt(plaintext)
+	if err != nil {
+		return "", false, err
+	}
+	return ciphertext, true, nil
+}
+
+// IsEncrypted 判断值是否为密钥环加密的凭证，即符合 enc:<密钥 ID>:<密文> 格式
+func IsEncrypted(value string) bool {
+	return encryptedValue.MatchString(value)
+}
+
+// resolveSecret 解析 env:变量名、file:路径 形式的密钥引用
+func resolveSecret(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, "env:"):
+		name := strings.TrimPrefix(ref, "env:")
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return v, nil
+	case strings.HasPrefix(ref, "file:"):
+		data, err := os.ReadFile(strings.TrimPrefix(ref, "file:"))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return ref, nil
+}
+
+// newAESGCMKey 创建本地 AES-256-GCM 密钥
+func newAESGCMKey(cfg *KeyConfig) (Encryptor, error) {
+	ref, err := resolveSecret(cfg.Key)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(ref)
+	if err != nil || len(key) != 32 {
+		return nil, ErrInvalidKey
+	}
+	return &AESEncryptor{key: key}, nil
+}
@@ -0,0 +1,287 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newKeyValue(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func newTestKeyring(t *testing.T, primary string, keys ...*KeyConfig) *Keyring {
+	t.Helper()
+	k, err := NewKeyring(&EncryptionConfig{Primary: primary, Keys: keys})
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	return k
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	k := newTestKeyring(t, "", &KeyConfig{ID: "k1", Provider: "aes-gcm", Key: newKeyValue(t)})
+	if k.Primary() != "k1" {
+		t.Errorf("Expected the only key to be primary, got %q", k.Primary())
+	}
+
+	ciphertext, err := k.Encrypt("s3cret")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if !strings.HasPrefix(ciphertext, "enc:k1:") || !IsEncrypted(ciphertext) {
+		t.Errorf("Unexpected ciphertext %q", ciphertext)
+	}
+	if again, _ := k.Encrypt("s3cret"); again == ciphertext {
+		t.Error("Expected a fresh nonce for every encryption")
+	}
+	plaintext, err := k.Decrypt(ciphertext)
+	if err != nil || plaintext != "s3cret" {
+		t.Errorf("Decrypt = %q, %v, want s3cret", plaintext, err)
+	}
+
+	if empty, err := k.Encrypt(""); empty != "" || err != nil {
+		t.Errorf("Expected empty values to stay empty, got %q, %v", empty, err)
+	}
+}
+
+func TestKeyringDecryptPlaintext(t *testing.T) {
+	k := newTestKeyring(t, "", &KeyConfig{ID: "k1", Provider: "aes-gcm", Key: newKeyValue(t)})
+	for _, value := range []string{"", "plain", "enc:foo", "enc:", "enc:k1:", "enc:k1:not base64!", "env:MYSQL_PASSWORD"} {
+		got, err := k.Decrypt(value)
+		if err != nil || got != value {
+			t.Errorf("Decrypt(%q) = %q, %v; expected the value unchanged", value, got, err)
+		}
+	}
+}
+
+func TestKeyringDecryptErrors(t *testing.T) {
+	k := newTestKeyring(t, "", &KeyConfig{ID: "k1", Provider: "aes-gcm", Key: newKeyValue(t)})
+	if _, err := k.Decrypt("enc:other:AAAA"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey, got %v", err)
+	}
+
+	ciphertext, _ := k.Encrypt("s3cret")
+	raw, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, "enc:k1:"))
+	raw[len(raw)-1] ^= 0xff
+	tampered := "enc:k1:" + base64.StdEncoding.EncodeToString(raw)
+	if _, err := k.Decrypt(tampered); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Expected ErrDecryptionFailed for a tampered ciphertext, got %v", err)
+	}
+
+	other := newTestKeyring(t, "", &KeyConfig{ID: "k1", Provider: "aes-gcm", Key: newKeyValue(t)})
+	if _, err := other.Decrypt(ciphertext); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Expected ErrDecryptionFailed with the wrong key, got %v", err)
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	oldKey := &KeyConfig{ID: "2023-01", Provider: "aes-gcm", Key: newKeyValue(t)}
+	newKey := &KeyConfig{ID: "2024-06", Provider: "aes-gcm", Key: newKeyValue(t)}
+
+	old := newTestKeyring(t, "", oldKey)
+	stored, err := old.Encrypt("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := newTestKeyring(t, "2024-06", newKey, oldKey)
+	if ids := rotated.KeyIDs(); len(ids) != 2 || ids[0] != "2023-01" || ids[1] != "2024-06" {
+		t.Errorf("Unexpected key ids %v", ids)
+	}
+	if plaintext, err := rotated.Decrypt(stored); err != nil || plaintext != "s3cret" {
+		t.Fatalf("Expected rotated-out keys to still decrypt, got %q, %v", plaintext, err)
+	}
+
+	migrated, changed, err := rotated.Reencrypt(stored)
+	if err != nil || !changed {
+		t.Fatalf("Reencrypt = %v, %v; expected a change", changed, err)
+	}
+	if !strings.HasPrefix(migrated, "enc:2024-06:") {
+		t.Errorf("Expected the primary key to encrypt, got %q", migrated)
+	}
+	if plaintext, _ := rotated.Decrypt(migrated); plaintext != "s3cret" {
+		t.Errorf("Expected the migrated value to decrypt to s3cret, got %q", plaintext)
+	}
+	if again, changed, err := rotated.Reencrypt(migrated); changed || err != nil || again != migrated {
+		t.Errorf("Expected current values to be left alone, got %q, %v, %v", again, changed, err)
+	}
+
+	// Once the old key is dropped, only migrated values can be read.
+	current := newTestKeyring(t, "", newKey)
+	if _, err := current.Decrypt(stored); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey after dropping the old key, got %v", err)
+	}
+	if plaintext, err := current.Decrypt(migrated); err != nil || plaintext != "s3cret" {
+		t.Errorf("Decrypt = %q, %v, want s3cret", plaintext, err)
+	}
+
+	encrypted, changed, err := current.Reencrypt("plain")
+	if err != nil || !changed || !strings.HasPrefix(encrypted, "enc:2024-06:") {
+		t.Errorf("Expected plaintext to be encrypted, got %q, %v, %v", encrypted, changed, err)
+	}
+	if _, changed, _ := current.Reencrypt(""); changed {
+		t.Error("Expected empty values to be left alone")
+	}
+}
+
+func TestKeyringCurrent(t *testing.T) {
+	oldKey := &KeyConfig{ID: "k1", Provider: "aes-gcm", Key: newKeyValue(t)}
+	newKey := &KeyConfig{ID: "k2", Provider: "aes-gcm", Key: newKeyValue(t)}
+	old := newTestKeyring(t, "", oldKey)
+	k := newTestKeyring(t, "k2", newKey, oldKey)
+
+	byOld, _ := old.Encrypt("s3cret")
+	byPrimary, _ := k.Encrypt("s3cret")
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"", true},
+		{byPrimary, true},
+		{byOld, false},
+		{"s3cret", false},
+		{"enc:k2:", false},
+		{"enc:k2:not base64!", false},
+	}
+	for _, tt := range tests {
+		if got := k.Current(tt.value); got != tt.want {
+			t.Errorf("Current(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestNewKeyringInvalid(t *testing.T) {
+	key := newKeyValue(t)
+	tests := []struct {
+		name string
+		cfg  *EncryptionConfig
+		want error
+	}{
+		{"nil", nil, ErrInvalidKeyring},
+		{"no keys", &EncryptionConfig{}, ErrInvalidKeyring},
+		{"bad id", &EncryptionConfig{Keys: []*KeyConfig{{ID: "a:b", Provider: "aes-gcm", Key: key}}}, ErrInvalidKeyring},
+		{"duplicate id", &EncryptionConfig{Primary: "k1", Keys: []*KeyConfig{
+			{ID: "k1", Provider: "aes-gcm", Key: key}, {ID: "k1", Provider: "aes-gcm", Key: key}}}, ErrInvalidKeyring},
+		{"unknown provider", &EncryptionConfig{Keys: []*KeyConfig{{ID: "k1", Provider: "rot13"}}}, ErrUnknownProvider},
+		{"short key", &EncryptionConfig{Keys: []*KeyConfig{{ID: "k1", Provider: "aes-gcm", Key: "c2hvcnQ="}}}, ErrInvalidKeyring},
+		{"undefined primary", &EncryptionConfig{Primary: "k2", Keys: []*KeyConfig{{ID: "k1", Provider: "aes-gcm", Key: key}}}, ErrInvalidKeyring},
+		{"ambiguous primary", &EncryptionConfig{Keys: []*KeyConfig{
+			{ID: "k1", Provider: "aes-gcm", Key: key}, {ID: "k2", Provider: "aes-gcm", Key: key}}}, ErrInvalidKeyring},
+		{"vault without address", &EncryptionConfig{Keys: []*KeyConfig{{ID: "k1", Provider: "vault-transit", KeyName: "metadata"}}}, ErrInvalidKeyring},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyring(tt.cfg); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestKeyReferences(t *testing.T) {
+	key := newKeyValue(t)
+	t.Setenv("METADATA_TEST_SECRET_KEY", key)
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte(key+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	literal := newTestKeyring(t, "", &KeyConfig{ID: "k1", Provider: "aes-gcm", Key: key})
+	ciphertext, _ := literal.Encrypt("s3cret")
+	for _, ref := range []string{"env:METADATA_TEST_SECRET_KEY", "file:" + path} {
+		k := newTestKeyring(t, "", &KeyConfig{ID: "k1", Provider: "aes-gcm", Key: ref})
+		if plaintext, err := k.Decrypt(ciphertext); err != nil || plaintext != "s3cret" {
+			t.Errorf("Key %s: Decrypt = %q, %v, want s3cret", ref, plaintext, err)
+		}
+	}
+
+	for _, ref := range []string{"env:METADATA_TEST_UNSET_KEY", "file:" + filepath.Join(t.TempDir(), "missing")} {
+		_, err := NewKeyring(&EncryptionConfig{Keys: []*KeyConfig{{ID: "k1", Provider: "aes-gcm", Key: ref}}})
+		if !errors.Is(err, ErrInvalidKeyring) {
+			t.Errorf("Key %s: expected ErrInvalidKeyring, got %v", ref, err)
+		}
+	}
+}
+
+// fakeTransit serves the encrypt and decrypt endpoints of a Vault transit
+// key, wrapping the plaintext instead of encrypting it.
+func fakeTransit(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		var in map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var data map[string]string
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/metadata":
+			data = map[string]string{"ciphertext": "vault:v1:" + in["plaintext"]}
+		case "/v1/transit/decrypt/metadata":
+			data = map[string]string{"plaintext": strings.TrimPrefix(in["ciphertext"], "vault:v1:")}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestVaultTransitKey(t *testing.T) {
+	srv := fakeTransit(t, "vault-token")
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("vault-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	k := newTestKeyring(t, "", &KeyConfig{ID: "vault", Provider: "vault-transit", Address: srv.URL + "/", Token: "file:" + path, KeyName: "metadata"})
+	ciphertext, err := k.Encrypt("s3cret")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if !strings.HasPrefix(ciphertext, "enc:vault:vault:v1:") || !IsEncrypted(ciphertext) {
+		t.Errorf("Unexpected ciphertext %q", ciphertext)
+	}
+	if plaintext, err := k.Decrypt(ciphertext); err != nil || plaintext != "s3cret" {
+		t.Errorf("Decrypt = %q, %v, want s3cret", plaintext, err)
+	}
+
+	t.Setenv("METADATA_TEST_VAULT_TOKEN", "wrong")
+	denied := newTestKeyring(t, "", &KeyConfig{ID: "vault", Provider: "vault-transit", Address: srv.URL, Token: "env:METADATA_TEST_VAULT_TOKEN", KeyName: "metadata"})
+	if _, err := denied.Encrypt("s3cret"); !errors.Is(err, ErrEncryptionFailed) {
+		t.Errorf("Expected ErrEncryptionFailed, got %v", err)
+	}
+	if _, err := denied.Decrypt(ciphertext); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Expected ErrDecryptionFailed, got %v", err)
+	}
+}
+
+func TestRegisterKeyProvider(t *testing.T) {
+	RegisterKeyProvider("test-noop", func(*KeyConfig) (Encryptor, error) {
+		return NewNoOpEncryptor(), nil
+	})
+	k := newTestKeyring(t, "", &KeyConfig{ID: "noop", Provider: "test-noop"})
+	ciphertext, err := k.Encrypt("s3cret")
+	if err != nil || ciphertext != "enc:noop:s3cret" {
+		t.Errorf("Encrypt = %q, %v", ciphertext, err)
+	}
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const secretsConfig = `# metadata server
+encryption:
+  keys:
+    - id: k1
+      provider: aes-gcm
+      key: env:METADATA_TEST_SECRET_KEY
+      token: not-a-credential
+collectors:
+  - id: mysql_prod
+    credentials:
+      user: readonly
+      password: p@ss # rotated quarterly
+    properties:
+      extra:
+        api_key: es-key
+        Secret_Key: s3-secret
+  - id: mysql_dev
+    credentials:
+      password: ""
+  - id: mysql_ref
+    credentials:
+      password: "${env:MYSQL_PASSWORD}"
+graph:
+  password: env:NEO4J_PASSWORD
+  nodes: [{access_key: s3-access}]
+`
+
+func parseYAML(t *testing.T, data string) *yaml.Node {
+	t.Helper()
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(data), &root); err != nil {
+		t.Fatal(err)
+	}
+	return &root
+}
+
+func collectSecrets(t *testing.T, root *yaml.Node) map[string]string {
+	t.Helper()
+	values := make(map[string]string)
+	err := walkYAMLSecrets(root, "", func(path string, value *yaml.Node) error {
+		values[path] = value.Value
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return values
+}
+
+func TestWalkYAMLSecrets(t *testing.T) {
+	got := collectSecrets(t, parseYAML(t, secretsConfig))
+	want := map[string]string{
+		"collectors[0].credentials.password":        "p@ss",
+		"collectors[0].properties.extra.api_key":    "es-key",
+		"collectors[0].properties.extra.Secret_Key": "s3-secret",
+		"collectors[2].credentials.password":        "${env:MYSQL_PASSWORD}",
+		"graph.password":                            "env:NEO4J_PASSWORD",
+		"graph.nodes[0].access_key":                 "s3-access",
+	}
+	if len(got) != len(want) {
+		t.Errorf("Expected %d secrets, got %d: %v", len(want), len(got), got)
+	}
+	for path, value := range want {
+		if got[path] != value {
+			t.Errorf("%s = %q, want %q", path, got[path], value)
+		}
+	}
+}
+
+func TestEncryptYAMLSecrets(t *testing.T) {
+	t.Setenv("METADATA_TEST_SECRET_KEY", newKeyValue(t))
+	k := newTestKeyring(t, "", &KeyConfig{ID: "k1", Provider: "aes-gcm", Key: "env:METADATA_TEST_SECRET_KEY"})
+	root := parseYAML(t, secretsConfig)
+
+	n, err := EncryptYAMLSecrets(root, k)
+	if err != nil {
+		t.Fatalf("EncryptYAMLSecrets failed: %v", err)
+	}
+	// References are resolved when the config is loaded, never encrypted.
+	if n != 4 {
+		t.Errorf("Expected 4 credentials encrypted, got %d", n)
+	}
+	encrypted := collectSecrets(t, root)
+	for _, path := range []string{"collectors[0].credentials.password", "collectors[0].properties.extra.api_key",
+		"collectors[0].properties.extra.Secret_Key", "graph.nodes[0].access_key"} {
+		if !strings.HasPrefix(encrypted[path], "enc:k1:") {
+			t.Errorf("Expected %s to be encrypted, got %q", path, encrypted[path])
+		}
+	}
+	for _, path := range []string{"collectors[2].credentials.password", "graph.password"} {
+		if IsEncrypted(encrypted[path]) {
+			t.Errorf("Expected the reference %s to be left alone, got %q", path, encrypted[path])
+		}
+	}
+
+	out, err := yaml.Marshal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kept := range []string{"# metadata server", "# rotated quarterly", "token: not-a-credential", "user: readonly"} {
+		if !strings.Contains(string(out), kept) {
+			t.Errorf("Expected %q to be kept:\n%s", kept, out)
+		}
+	}
+
+	if n, err := EncryptYAMLSecrets(root, k); err != nil || n != 0 {
+		t.Errorf("Expected a second migration to change nothing, got %d, %v", n, err)
+	}
+
+	if err := DecryptYAMLSecrets(root, k); err != nil {
+		t.Fatalf("DecryptYAMLSecrets failed: %v", err)
+	}
+	if got, want := collectSecrets(t, root), collectSecrets(t, parseYAML(t, secretsConfig)); len(got) != len(want) {
+		t.Errorf("Expected %d secrets after decrypting, got %d", len(want), len(got))
+	} else {
+		for path, value := range want {
+			if got[path] != value {
+				t.Errorf("%s = %q after decrypting, want %q", path, got[path], value)
+			}
+		}
+	}
+}
+
+func TestEncryptYAMLSecretsUnknownKey(t *testing.T) {
+	k := newTestKeyring(t, "", &KeyConfig{ID: "k1", Provider: "aes-gcm", Key: newKeyValue(t)})
+	root := parseYAML(t, "collectors:\n  - credentials:\n      password: enc:gone:AAAA\n")
+
+	_, err := EncryptYAMLSecrets(root, k)
+	if !errors.Is(err, ErrUnknownKey) || !strings.Contains(err.Error(), "collectors[0].credentials.password") {
+		t.Errorf("Expected ErrUnknownKey naming the field, got %v", err)
+	}
+}
+
+func TestDecryptConfig(t *testing.T) {
+	t.Setenv("METADATA_TEST_SECRET_KEY", newKeyValue(t))
+	k := newTestKeyring(t, "", &KeyConfig{ID: "k1", Provider: "aes-gcm", Key: "env:METADATA_TEST_SECRET_KEY"})
+	root := parseYAML(t, secretsConfig)
+	if _, err := EncryptYAMLSecrets(root, k); err != nil {
+		t.Fatal(err)
+	}
+	migrated, err := yaml.Marshal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := DecryptConfig(migrated)
+	if err != nil {
+		t.Fatalf("DecryptConfig failed: %v", err)
+	}
+	var cfg struct {
+		Collectors []struct {
+			Credentials struct {
+				Password string `yaml:"password"`
+			} `yaml:"credentials"`
+			Properties struct {
+				Extra map[string]string `yaml:"extra"`
+			} `yaml:"properties"`
+		} `yaml:"collectors"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Collectors[0].Credentials.Password; got != "p@ss" {
+		t.Errorf("Expected the password to be decrypted, got %q", got)
+	}
+	if got := cfg.Collectors[0].Properties.Extra["api_key"]; got != "es-key" {
+		t.Errorf("Expected the extra api_key to be decrypted, got %q", got)
+	}
+	if got := cfg.Collectors[2].Credentials.Password; got != "${env:MYSQL_PASSWORD}" {
+		t.Errorf("Expected the reference to be kept, got %q", got)
+	}
+
+	// Without keys the config is returned as is, including values that look encrypted.
+	plain := []byte("collectors:\n  - credentials:\n      password: enc:k1:AAAA\n")
+	if data, err := DecryptConfig(plain); err != nil || string(data) != string(plain) {
+		t.Errorf("Expected a config without encryption to be unchanged, got %q, %v", data, err)
+	}
+
+	// A config whose keys cannot decrypt its credentials fails to load.
+	t.Setenv("METADATA_TEST_SECRET_KEY", newKeyValue(t))
+	if _, err := DecryptConfig(migrated); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Expected ErrDecryptionFailed with a different key, got %v", err)
+	}
+}
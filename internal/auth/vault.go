@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VaultTransitEncryptor 使用 HashiCorp Vault transit 引擎 (KMS) 加解密，
+// 密钥不离开 Vault，密钥版本由 Vault 管理
+type VaultTransitEncryptor struct {
+	address    string
+	token      string
+	keyName    string
+	httpClient *http.Client
+}
+
+// newVaultTransitKey 创建 Vault transit 密钥
+func newVaultTransitKey(cfg *KeyConfig) (Encryptor, error) {
+	if cfg.Address == "" || cfg.KeyName == "" {
+		return nil, errors.New("address and key_name are required")
+	}
+	if _, err := url.Parse(cfg.Address); err != nil {
+		return nil, fmt.Errorf("invalid vault address: %w", err)
+	}
+	token, err := resolveSecret(cfg.Token)
+	if err != nil {
+		return nil, err
+	}
+	return &VaultTransitEncryptor{
+		address:    strings.TrimSuffix(cfg.Address, "/"),
+		token:      token,
+		keyName:    cfg.KeyName,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Encrypt 加密字符串，返回 Vault 密文 (vault:v1:...)
+func (e *VaultTransitEncryptor) Encrypt(plaintext string) (string, error) {
+	var out struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	in := map[string]string{"plaintext": base64.StdEncoding.EncodeToString([]byte(plaintext))}
+	if err := e.call("encrypt", in, &out); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrEncryptionFailed, err)
+	}
+	return out.Data.Ciphertext, nil
+}
+
+// Decrypt 解密 Vault 密文
+func (e *VaultTransitEncryptor) Decrypt(ciphertext string) (string, error) {
+	var out struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := e.call("decrypt", map[string]string{"ciphertext": ciphertext}, &out); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrDecryptionFailed, err)
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(out.Data.Plaintext)
+	if err != nil {
+		return "", ErrDecryptionFailed
+	}
+	return string(plaintext), nil
+}
+
+func (e *VaultTransitEncryptor) call(op string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	endpoint := e.address + "/v1/transit/" + op + "/" + url.PathEscape(e.keyName)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", e.token)
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("vault %s: status %d: %s", op, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
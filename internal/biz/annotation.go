@@ -0,0 +1,115 @@
+package biz
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxAnnotationKeyLength is the maximum length in bytes of an annotation key.
+	MaxAnnotationKeyLength = 255
+	// MaxAnnotationValueLength is the maximum length in bytes of an annotation value.
+	MaxAnnotationValueLength = 1024
+	// MaxAnnotations is the maximum number of annotations of a table or column.
+	MaxAnnotations = 100
+
+	columnAnnotationsPath = ".annotations."
+)
+
+// ErrInvalidAnnotation is returned for annotations with a malformed key or
+// an oversized value.
+var ErrInvalidAnnotation = errors.New("invalid annotation")
+
+// annotationKey matches namespaced keys such as dq.check or finance.coa: a
+// lowercase namespace followed by one or more dot-separated segments.
+var annotationKey = regexp.MustCompile(`^[a-z][a-z0-9_-]*(\.[a-z0-9_-]+)+$`)
+
+// ValidateAnnotation checks an annotation key and value.
+func ValidateAnnotation(key, value string) error {
+	if len(key) > MaxAnnotationKeyLength || !annotationKey.MatchString(key) {
+		return fmt.Errorf("%w: key %q must be a lowercase namespaced key such as dq.check", ErrInvalidAnnotation, key)
+	}
+	if len(value) > MaxAnnotationValueLength {
+		return fmt.Errorf("%w: value of %s is %d bytes, at most %d allowed", ErrInvalidAnnotation, key, len(value), MaxAnnotationValueLength)
+	}
+	return nil
+}
+
+// ColumnAnnotationField returns the field name of an annotation of a column,
+// as reported in conflicts.
+func ColumnAnnotationField(column, key string) string {
+	return columnFieldPrefix + column + columnAnnotationsPath + key
+}
+
+// ApplyAnnotations sets and removes annotations of t, or of its column named
+// column if column is not empty. Removing a missing key is not an error.
+func ApplyAnnotations(t *TableMetadata, column string, set map[string]string, remove []string, at time.Time) error {
+	target := &t.Annotations
+	if column != "" {
+		col := t.column(column)
+		if col == nil {
+			return fmt.Errorf("%w: %s", ErrColumnNotFound, column)
+		}
+		target = &col.Annotations
+	}
+
+	annotations := make(map[string]string, len(*target)+len(set))
+	for k, v := range *target {
+		annotations[k] = v
+	}
+	for _, k := range remove {
+		delete(annotations, k)
+	}
+	for k, v := range set {
+		if err := ValidateAnnotation(k, v); err != nil {
+			return err
+		}
+		annotations[k] = v
+	}
+	if len(annotations) > MaxAnnotations {
+		return fmt.Errorf("%w: %d annotations, at most %d allowed", ErrInvalidAnnotation, len(annotations), MaxAnnotations)
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+
+	*target = annotations
+	t.UpdatedAt = at
+	return nil
+}
+
+// AnnotationQuery selects annotations by key and, optionally, value.
+type AnnotationQuery struct {
+	// Key is an exact key such as dq.check, or a namespace followed by .*
+	// such as dq.* to match every key in the namespace.
+	Key string `json:"key"`
+	// Value, if not empty, must equal the annotation value.
+	Value string `json:"value,omitempty"`
+}
+
+// Match reports whether an annotation matches the query.
+func (q AnnotationQuery) Match(key, value string) bool {
+	if ns, ok := strings.CutSuffix(q.Key, "*"); ok {
+		if !strings.HasPrefix(key, ns) {
+			return false
+		}
+	} else if key != q.Key {
+		return false
+	}
+	return q.Value == "" || value == q.Value
+}
+
+// AnnotationMatch is an annotation found by a search. Column is empty for
+// table annotations.
+type AnnotationMatch struct {
+	TableID  string `json:"table_id"`
+	Database string `json:"database"`
+	Schema   string `json:"schema,omitempty"`
+	Table    string `json:"table"`
+	Column   string `json:"column,omitempty"`
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+}
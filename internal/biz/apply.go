@@ -0,0 +1,448 @@
+package biz
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fields set by declarative changes, as reported in plans. Column fields are
+// prefixed with columns.<name>., e.g. columns.amount.tags.
+const (
+	FieldDescription = "description"
+	FieldReadme      = "readme"
+	FieldOwners      = "owners"
+	FieldTags        = "tags"
+	FieldDeprecation = "deprecation"
+)
+
+// Change file formats.
+const (
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+)
+
+// csvListSeparator separates the items of owners and tags in CSV cells.
+const csvListSeparator = ";"
+
+// ErrInvalidChange is returned for malformed change files and changes.
+var ErrInvalidChange = errors.New("invalid change")
+
+// Change is a declarative edit of a table and its columns. Only the fields
+// that are set are changed; an empty string or list clears a field, and
+// deprecated: false removes a deprecation.
+type Change struct {
+	// Table is database.table or database.schema.table.
+	Table           string                   `json:"table" yaml:"table"`
+	Description     *string                  `json:"description,omitempty" yaml:"description"`
+	Owners          *[]string                `json:"owners,omitempty" yaml:"owners"`
+	Tags            *[]string                `json:"tags,omitempty" yaml:"tags"`
+	Deprecated      *bool                    `json:"deprecated,omitempty" yaml:"deprecated"`
+	DeprecationNote *string                  `json:"deprecation_note,omitempty" yaml:"deprecation_note"`
+	Columns         map[string]*ColumnChange `json:"columns,omitempty" yaml:"columns"`
+
+	// Readme is the long-form markdown documentation and ReadmePath where it
+	// is maintained; see Readme. The apply command reads a README file from
+	// readme_path, relative to the change file, if readme is not set.
+	Readme     *string `json:"readme,omitempty" yaml:"readme"`
+	ReadmePath *string `json:"readme_path,omitempty" yaml:"readme_path"`
+}
+
+// ColumnChange is a declarative edit of a column.
+type ColumnChange struct {
+	Description     *string   `json:"description,omitempty" yaml:"description"`
+	Tags            *[]string `json:"tags,omitempty" yaml:"tags"`
+	Deprecated      *bool     `json:"deprecated,omitempty" yaml:"deprecated"`
+	DeprecationNote *string   `json:"deprecation_note,omitempty" yaml:"deprecation_note"`
+}
+
+// ParseChanges reads a change file. The YAML format, which also accepts
+// JSON, is a document with a list of changes:
+//
+//	changes:
+//	  - table: dw.fact_orders
+//	    description: Daily order facts, loaded T+1.
+//	    owners: [alice, data-platform]
+//	    tags: [finance]
+//	    readme_path: docs/dw/fact_orders.md
+//	    columns:
+//	      amount:
+//	        tags: [pii]
+//
+// The CSV format has a header row naming the columns table, column,
+// description, owners, tags, deprecated and deprecation_note; all but table
+// are optional. A row with a column edits that column. Empty cells leave a
+// field unchanged and owners and tags are separated by semicolons.
+func ParseChanges(r io.Reader, format string) ([]*Change, error) {
+	var changes []*Change
+	var err error
+	switch format {
+	case FormatYAML:
+		changes, err = parseYAMLChanges(r)
+	case FormatCSV:
+		changes, err = parseCSVChanges(r)
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidChange, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateChanges(changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// ValidateChanges checks that changes are well-formed and change each table
+// at most once.
+func ValidateChanges(changes []*Change) error {
+	seen := make(map[string]bool, len(changes))
+	for i, c := range changes {
+		if c == nil {
+			return fmt.Errorf("%w: change %d is empty", ErrInvalidChange, i+1)
+		}
+		if err := c.validate(); err != nil {
+			return fmt.Errorf("change %d: %w", i+1, err)
+		}
+		if seen[c.Table] {
+			return fmt.Errorf("%w: table %s is changed more than once", ErrInvalidChange, c.Table)
+		}
+		seen[c.Table] = true
+	}
+	return nil
+}
+
+func parseYAMLChanges(r io.Reader) ([]*Change, error) {
+	var file struct {
+		Changes []*Change `yaml:"changes"`
+	}
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidChange, err)
+	}
+	return file.Changes, nil
+}
+
+func parseCSVChanges(r io.Reader) ([]*Change, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidChange, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "table", "column", FieldDescription, FieldOwners, FieldTags, "deprecated", "deprecation_note":
+			header[name] = i
+		default:
+			return nil, fmt.Errorf("%w: unknown CSV column %q", ErrInvalidChange, name)
+		}
+	}
+	if _, ok := header["table"]; !ok {
+		return nil, fmt.Errorf("%w: CSV header has no table column", ErrInvalidChange)
+	}
+
+	var changes []*Change
+	byTable := make(map[string]*Change)
+	for n, record := range records[1:] {
+		line := n + 2
+		cell := func(name string) *string {
+			i, ok := header[name]
+			if !ok || i >= len(record) || strings.TrimSpace(record[i]) == "" {
+				return nil
+			}
+			v := strings.TrimSpace(record[i])
+			return &v
+		}
+		list := func(name string) *[]string {
+			v := cell(name)
+			if v == nil {
+				return nil
+			}
+			items := strings.Split(*v, csvListSeparator)
+			return &items
+		}
+		var deprecated *bool
+		if v := cell("deprecated"); v != nil {
+			b, err := strconv.ParseBool(*v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: deprecated must be true or false", ErrInvalidChange, line)
+			}
+			deprecated = &b
+		}
+
+		table := cell("table")
+		if table == nil {
+			return nil, fmt.Errorf("%w: line %d: table is required", ErrInvalidChange, line)
+		}
+		c, ok := byTable[*table]
+		if !ok {
+			c = &Change{Table: *table}
+			byTable[*table] = c
+			changes = append(changes, c)
+		}
+
+		if column := cell("column"); column != nil {
+			if cell(FieldOwners) != nil {
+				return nil, fmt.Errorf("%w: line %d: owners are set on tables, not columns", ErrInvalidChange, line)
+			}
+			if c.Columns == nil {
+				c.Columns = make(map[string]*ColumnChange)
+			}
+			if _, ok := c.Columns[*column]; ok {
+				return nil, fmt.Errorf("%w: line %d: column %s.%s is changed more than once", ErrInvalidChange, line, *table, *column)
+			}
+			c.Columns[*column] = &ColumnChange{
+				Description:     cell(FieldDescription),
+				Tags:            list(FieldTags),
+				Deprecated:      deprecated,
+				DeprecationNote: cell("deprecation_note"),
+			}
+			continue
+		}
+		if c.Description != nil || c.Owners != nil || c.Tags != nil || c.Deprecated != nil {
+			return nil, fmt.Errorf("%w: line %d: table %s is changed more than once", ErrInvalidChange, line, *table)
+		}
+		c.Description = cell(FieldDescription)
+		c.Owners = list(FieldOwners)
+		c.Tags = list(FieldTags)
+		c.Deprecated = deprecated
+		c.DeprecationNote = cell("deprecation_note")
+	}
+	return changes, nil
+}
+
+// ParseTableName splits database.table or database.schema.table.
+func ParseTableName(name string) (database, schema, table string, err error) {
+	parts := strings.Split(name, ".")
+	for _, p := range parts {
+		if p == "" {
+			parts = nil
+			break
+		}
+	}
+	switch len(parts) {
+	case 2:
+		return parts[0], "", parts[1], nil
+	case 3:
+		return parts[0], parts[1], parts[2], nil
+	}
+	return "", "", "", fmt.Errorf("%w: table %q must be database.table or database.schema.table", ErrInvalidChange, name)
+}
+
+func (c *Change) validate() error {
+	if _, _, _, err := ParseTableName(c.Table); err != nil {
+		return err
+	}
+	if c.DeprecationNote != nil && (c.Deprecated == nil || !*c.Deprecated) {
+		return fmt.Errorf("%w: %s: deprecation_note requires deprecated: true", ErrInvalidChange, c.Table)
+	}
+	for name, col := range c.Columns {
+		if col == nil {
+			return fmt.Errorf("%w: %s: column %s has no changes", ErrInvalidChange, c.Table, name)
+		}
+		if col.DeprecationNote != nil && (col.Deprecated == nil || !*col.Deprecated) {
+			return fmt.Errorf("%w: %s.%s: deprecation_note requires deprecated: true", ErrInvalidChange, c.Table, name)
+		}
+	}
+	return nil
+}
+
+// PlanAction is what applying a change does to a table.
+type PlanAction = string
+
+const (
+	// PlanUpdate means the change modifies the table.
+	PlanUpdate PlanAction = "update"
+	// PlanUnchanged means the table already matches the change.
+	PlanUnchanged PlanAction = "unchanged"
+	// PlanNotFound means the table has no stored metadata.
+	PlanNotFound PlanAction = "not_found"
+	// PlanInvalid means the change cannot be applied to the table, e.g.
+	// because it names a column the table does not have.
+	PlanInvalid PlanAction = "invalid"
+)
+
+// FieldDiff is the change of a field. Lists are shown comma-separated.
+type FieldDiff struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// EntityPlan is the planned change of a table.
+type EntityPlan struct {
+	Table  string     `json:"table"`
+	Action PlanAction `json:"action"`
+	// Version is the table version the plan was computed from.
+	Version int64        `json:"version"`
+	Diffs   []*FieldDiff `json:"diffs,omitempty"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// ApplyPlan is the outcome of applying changes, or of previewing them.
+type ApplyPlan struct {
+	Entities []*EntityPlan `json:"entities"`
+	// Applied reports whether the changes were written. Nothing is written
+	// on a dry run or if any table is not found or invalid.
+	Applied bool `json:"applied"`
+}
+
+// Count returns the number of tables with the given action.
+func (p *ApplyPlan) Count(action PlanAction) int {
+	n := 0
+	for _, e := range p.Entities {
+		if e.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// applyTo applies the change to t in place and returns the modified fields.
+func (c *Change) applyTo(t *TableMetadata, user string, at time.Time) ([]*FieldDiff, error) {
+	var diffs []*FieldDiff
+	diff := func(field, old, new string) {
+		if old != new {
+			diffs = append(diffs, &FieldDiff{Field: field, Old: old, New: new})
+		}
+	}
+
+	if c.Description != nil {
+		old := t.Description
+		if err := ApplyDescription(t, "", *c.Description, at); err != nil {
+			return nil, err
+		}
+		diff(FieldDescription, old, t.Description)
+	}
+	if c.Readme != nil || c.ReadmePath != nil {
+		old := t.Readme
+		markdown, path := "", ""
+		if old != nil {
+			markdown, path = old.Markdown, old.Path
+		}
+		if c.Readme != nil {
+			markdown = *c.Readme
+		}
+		if c.ReadmePath != nil {
+			path = *c.ReadmePath
+		}
+		if err := ApplyReadme(t, markdown, path, user, at); err != nil {
+			return nil, err
+		}
+		diff(FieldReadme, formatReadme(old), formatReadme(t.Readme))
+	}
+	if c.Owners != nil {
+		old := t.Owners
+		t.Owners = normalizeList(*c.Owners)
+		diff(FieldOwners, strings.Join(old, ", "), strings.Join(t.Owners, ", "))
+	}
+	if c.Tags != nil {
+		old := t.Tags
+		t.Tags = normalizeList(*c.Tags)
+		diff(FieldTags, strings.Join(old, ", "), strings.Join(t.Tags, ", "))
+	}
+	if c.Deprecated != nil {
+		old := t.Deprecation
+		t.Deprecation = deprecate(old, *c.Deprecated, c.DeprecationNote, user, at)
+		diff(FieldDeprecation, formatDeprecation(old), formatDeprecation(t.Deprecation))
+	}
+
+	for name, cc := range c.Columns {
+		col := t.column(name)
+		if col == nil {
+			return nil, fmt.Errorf("%w: %s", ErrColumnNotFound, name)
+		}
+		if cc.Description != nil {
+			old := col.Description
+			if err := ApplyDescription(t, name, *cc.Description, at); err != nil {
+				return nil, err
+			}
+			diff(ColumnDescriptionField(name), old, col.Description)
+		}
+		if cc.Tags != nil {
+			old := col.Tags
+			col.Tags = normalizeList(*cc.Tags)
+			diff(columnField(name, FieldTags), strings.Join(old, ", "), strings.Join(col.Tags, ", "))
+		}
+		if cc.Deprecated != nil {
+			old := col.Deprecation
+			col.Deprecation = deprecate(old, *cc.Deprecated, cc.DeprecationNote, user, at)
+			diff(columnField(name, FieldDeprecation), formatDeprecation(old), formatDeprecation(col.Deprecation))
+		}
+	}
+	sortDiffs(diffs)
+	return diffs, nil
+}
+
+// deprecate returns the deprecation after a change. Re-deprecating with the
+// same note keeps the original author and time.
+func deprecate(current *Deprecation, deprecated bool, note *string, user string, at time.Time) *Deprecation {
+	if !deprecated {
+		return nil
+	}
+	n := ""
+	if note != nil {
+		n = strings.TrimSpace(*note)
+	}
+	if current != nil && current.Note == n {
+		return current
+	}
+	return &Deprecation{Note: n, By: user, At: at}
+}
+
+func formatDeprecation(d *Deprecation) string {
+	if d == nil {
+		return ""
+	}
+	if d.Note == "" {
+		return "deprecated"
+	}
+	return "deprecated: " + d.Note
+}
+
+// normalizeList trims the items of a list and drops empty and duplicate ones,
+// keeping the order. An empty list is nil.
+func normalizeList(items []string) []string {
+	var result []string
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		result = append(result, item)
+	}
+	return result
+}
+
+// sortDiffs orders table fields before column fields, since columns are
+// applied in map order.
+func sortDiffs(diffs []*FieldDiff) {
+	sort.SliceStable(diffs, func(i, j int) bool {
+		ci := strings.HasPrefix(diffs[i].Field, columnFieldPrefix)
+		cj := strings.HasPrefix(diffs[j].Field, columnFieldPrefix)
+		if ci != cj {
+			return !ci
+		}
+		return ci && diffs[i].Field < diffs[j].Field
+	})
+}
+
+// columnField returns the name of a field of a column.
+func columnField(column, field string) string {
+	return columnFieldPrefix + column + "." + field
+}
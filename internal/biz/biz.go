@@ -9,4 +9,5 @@ var ProviderSet = wire.NewSet(
 	NewDataSourceUsecase,
 	NewTaskUsecase,
 	NewTemplateUsecase,
+	NewTableUsecase,
 )
@@ -2,6 +2,8 @@ package biz
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/go-kratos/kratos/v2/log"
 )
@@ -19,25 +21,71 @@ type DataSourceRepo interface {
 	BatchUpdateStatus(ctx context.Context, ids []string, status DataSourceStatus) (*BatchOperationResult, error)
 }
 
+// ErrEncryptionNotConfigured is returned when migrating credentials without
+// an encryption key.
+var ErrEncryptionNotConfigured = errors.New("credential encryption is not configured")
+
+// SecretCipher encrypts connector credentials at rest. Decrypt returns
+// values that are not encrypted unchanged, so that plaintext credentials
+// stored before encryption was configured keep working until migrated.
+type SecretCipher interface {
+	Encrypt(plaintext string) (string, error)
+	Decrypt(value string) (string, error)
+	// Current reports whether value is empty or encrypted with the primary
+	// key, i.e. needs no migration.
+	Current(value string) bool
+}
+
+// SecretMigrationResult is the outcome of migrating stored credentials to
+// the primary key.
+type SecretMigrationResult struct {
+	Scanned  int `json:"scanned"`
+	Migrated int `json:"migrated"`
+}
+
 // DataSourceUsecase is a DataSource usecase.
 type DataSourceUsecase struct {
-	repo DataSourceRepo
-	log  *log.Helper
+	repo   DataSourceRepo
+	cipher SecretCipher
+	log    *log.Helper
 }
 
-// NewDataSourceUsecase creates a new DataSourceUsecase.
-func NewDataSourceUsecase(repo DataSourceRepo, logger log.Logger) *DataSourceUsecase {
-	return &DataSourceUsecase{repo: repo, log: log.NewHelper(logger)}
+// NewDataSourceUsecase creates a new DataSourceUsecase. Credentials are
+// stored encrypted with cipher, or in plaintext if cipher is nil.
+func NewDataSourceUsecase(repo DataSourceRepo, cipher SecretCipher, logger log.Logger) *DataSourceUsecase {
+	return &DataSourceUsecase{repo: repo, cipher: cipher, log: log.NewHelper(logger)}
 }
 
 // Create creates a DataSource.
 func (uc *DataSourceUsecase) Create(ctx context.Context, ds *DataSource) (*DataSource, error) {
-	return uc.repo.Create(ctx, ds)
+	if _, err := ds.CollectionWindows(); err != nil {
+		return nil, err
+	}
+	stored, err := uc.seal(ds)
+	if err != nil {
+		return nil, err
+	}
+	created, err := uc.repo.Create(ctx, stored)
+	if err != nil {
+		return nil, err
+	}
+	return uc.open(created)
 }
 
 // Update updates a DataSource.
 func (uc *DataSourceUsecase) Update(ctx context.Context, ds *DataSource) (*DataSource, error) {
-	return uc.repo.Update(ctx, ds)
+	if _, err := ds.CollectionWindows(); err != nil {
+		return nil, err
+	}
+	stored, err := uc.seal(ds)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := uc.repo.Update(ctx, stored)
+	if err != nil {
+		return nil, err
+	}
+	return uc.open(updated)
 }
 
 // Delete deletes a DataSource.
@@ -47,12 +95,25 @@ func (uc *DataSourceUsecase) Delete(ctx context.Context, id string) error {
 
 // Get gets a DataSource by ID.
 func (uc *DataSourceUsecase) Get(ctx context.Context, id string) (*DataSource, error) {
-	return uc.repo.Get(ctx, id)
+	ds, err := uc.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return uc.open(ds)
 }
 
 // List lists DataSources.
 func (uc *DataSourceUsecase) List(ctx context.Context, page, pageSize int) ([]*DataSource, int64, error) {
-	return uc.repo.List(ctx, page, pageSize)
+	list, total, err := uc.repo.List(ctx, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i, ds := range list {
+		if list[i], err = uc.open(ds); err != nil {
+			return nil, 0, err
+		}
+	}
+	return list, total, nil
 }
 
 // TestConnection tests the connection of a DataSource.
@@ -92,3 +153,72 @@ func (uc *DataSourceUsecase) CanDelete(ctx context.Context, id string) (*CanDele
 func (uc *DataSourceUsecase) BatchUpdateStatus(ctx context.Context, ids []string, status DataSourceStatus) (*BatchOperationResult, error) {
 	return uc.repo.BatchUpdateStatus(ctx, ids, status)
 }
+
+// MigrateSecrets encrypts the stored credentials that are still in plaintext
+// or encrypted with a rotated-out key with the primary key.
+func (uc *DataSourceUsecase) MigrateSecrets(ctx context.Context) (*SecretMigrationResult, error) {
+	if uc.cipher == nil {
+		return nil, ErrEncryptionNotConfigured
+	}
+	const pageSize = 100
+	result := &SecretMigrationResult{}
+	for page := 1; ; page++ {
+		list, total, err := uc.repo.List(ctx, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, ds := range list {
+			result.Scanned++
+			if ds.Config == nil || uc.cipher.Current(ds.Config.Password) {
+				continue
+			}
+			opened, err := uc.open(ds)
+			if err != nil {
+				return nil, err
+			}
+			sealed, err := uc.seal(opened)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := uc.repo.Update(ctx, sealed); err != nil {
+				return nil, err
+			}
+			result.Migrated++
+		}
+		if len(list) < pageSize || int64(page*pageSize) >= total {
+			return result, nil
+		}
+	}
+}
+
+// seal returns a copy of ds with its credentials encrypted for storage. The
+// credentials of ds are plaintext, as submitted or returned by open; they are
+// never parsed as ciphertext, so a password that looks encrypted is kept as is.
+func (uc *DataSourceUsecase) seal(ds *DataSource) (*DataSource, error) {
+	if uc.cipher == nil || ds.Config == nil || ds.Config.Password == "" {
+		return ds, nil
+	}
+	password, err := uc.cipher.Encrypt(ds.Config.Password)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt credentials of %s: %w", ds.Name, err)
+	}
+	c, cfg := *ds, *ds.Config
+	cfg.Password = password
+	c.Config = &cfg
+	return &c, nil
+}
+
+// open returns a copy of a stored ds with its credentials decrypted.
+func (uc *DataSourceUsecase) open(ds *DataSource) (*DataSource, error) {
+	if uc.cipher == nil || ds == nil || ds.Config == nil || ds.Config.Password == "" {
+		return ds, nil
+	}
+	password, err := uc.cipher.Decrypt(ds.Config.Password)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt credentials of %s: %w", ds.Name, err)
+	}
+	c, cfg := *ds, *ds.Config
+	cfg.Password = password
+	c.Config = &cfg
+	return &c, nil
+}
@@ -0,0 +1,138 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// prefixCipher "encrypts" values by prefixing them with its key, so that
+// tests can tell stored values from submitted ones.
+type prefixCipher struct {
+	key string
+}
+
+func (c prefixCipher) Encrypt(plaintext string) (string, error) {
+	return "sealed:" + c.key + ":" + plaintext, nil
+}
+
+func (c prefixCipher) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, "sealed:") {
+		return value, nil
+	}
+	rest, ok := strings.CutPrefix(value, "sealed:"+c.key+":")
+	if !ok {
+		return "", errors.New("unknown key")
+	}
+	return rest, nil
+}
+
+func (c prefixCipher) Current(value string) bool {
+	return value == "" || strings.HasPrefix(value, "sealed:"+c.key+":")
+}
+
+// memDataSourceRepo keeps data sources in memory. The other methods of
+// DataSourceRepo are not implemented.
+type memDataSourceRepo struct {
+	DataSourceRepo
+	stored map[string]*DataSource
+}
+
+func (r *memDataSourceRepo) Create(_ context.Context, ds *DataSource) (*DataSource, error) {
+	r.stored[ds.ID] = ds
+	return ds, nil
+}
+
+func (r *memDataSourceRepo) Update(_ context.Context, ds *DataSource) (*DataSource, error) {
+	r.stored[ds.ID] = ds
+	return ds, nil
+}
+
+func (r *memDataSourceRepo) Get(_ context.Context, id string) (*DataSource, error) {
+	return r.stored[id], nil
+}
+
+func (r *memDataSourceRepo) List(_ context.Context, _, _ int) ([]*DataSource, int64, error) {
+	var list []*DataSource
+	for _, ds := range r.stored {
+		list = append(list, ds)
+	}
+	return list, int64(len(list)), nil
+}
+
+func TestDataSourceCredentialsAreSealed(t *testing.T) {
+	repo := &memDataSourceRepo{stored: make(map[string]*DataSource)}
+	uc := NewDataSourceUsecase(repo, prefixCipher{key: "k1"}, log.NewStdLogger(io.Discard))
+	ctx := context.Background()
+
+	// Submitted passwords are plaintext, even if they look encrypted.
+	for _, password := range []string{"s3cret", "sealed:k1:abc", "sealed:gone:abc"} {
+		created, err := uc.Create(ctx, &DataSource{ID: password, Name: "mysql", Config: &ConnectionConfig{Password: password}})
+		if err != nil {
+			t.Fatalf("Create with password %q failed: %v", password, err)
+		}
+		if created.Config.Password != password {
+			t.Errorf("Expected Create to return the submitted password %q, got %q", password, created.Config.Password)
+		}
+		if got := repo.stored[password].Config.Password; got != "sealed:k1:"+password {
+			t.Errorf("Expected password %q to be stored encrypted, got %q", password, got)
+		}
+		got, err := uc.Get(ctx, password)
+		if err != nil || got.Config.Password != password {
+			t.Errorf("Get = %v, %v; expected password %q", got, err, password)
+		}
+	}
+}
+
+func TestMigrateSecrets(t *testing.T) {
+	repo := &memDataSourceRepo{stored: map[string]*DataSource{
+		"plain":   {ID: "plain", Config: &ConnectionConfig{Password: "s3cret"}},
+		"old":     {ID: "old", Config: &ConnectionConfig{Password: "sealed:k1:s3cret"}},
+		"current": {ID: "current", Config: &ConnectionConfig{Password: "sealed:k2:s3cret"}},
+		"empty":   {ID: "empty", Config: &ConnectionConfig{}},
+	}}
+	// k2 is the primary key; k1 is still known for decryption.
+	cipher := rotatedCipher{primary: prefixCipher{key: "k2"}, old: prefixCipher{key: "k1"}}
+	uc := NewDataSourceUsecase(repo, cipher, log.NewStdLogger(io.Discard))
+
+	result, err := uc.MigrateSecrets(context.Background())
+	if err != nil {
+		t.Fatalf("MigrateSecrets failed: %v", err)
+	}
+	if result.Scanned != 4 || result.Migrated != 2 {
+		t.Errorf("Expected 4 scanned and 2 migrated, got %+v", result)
+	}
+	for _, id := range []string{"plain", "old", "current"} {
+		if got := repo.stored[id].Config.Password; got != "sealed:k2:s3cret" {
+			t.Errorf("Expected %s to be encrypted with the primary key, got %q", id, got)
+		}
+	}
+
+	if _, err := NewDataSourceUsecase(repo, nil, log.NewStdLogger(io.Discard)).MigrateSecrets(context.Background()); !errors.Is(err, ErrEncryptionNotConfigured) {
+		t.Errorf("Expected ErrEncryptionNotConfigured, got %v", err)
+	}
+}
+
+// rotatedCipher encrypts with primary and decrypts with primary or old.
+type rotatedCipher struct {
+	primary, old prefixCipher
+}
+
+func (c rotatedCipher) Encrypt(plaintext string) (string, error) {
+	return c.primary.Encrypt(plaintext)
+}
+
+func (c rotatedCipher) Decrypt(value string) (string, error) {
+	if plaintext, err := c.primary.Decrypt(value); err == nil {
+		return plaintext, nil
+	}
+	return c.old.Decrypt(value)
+}
+
+func (c rotatedCipher) Current(value string) bool {
+	return c.primary.Current(value)
+}
@@ -0,0 +1,58 @@
+package biz
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxDescriptionLength is the maximum length in bytes of a description.
+const MaxDescriptionLength = 64 << 10
+
+var (
+	// ErrColumnNotFound is returned when a table has no column of the given name.
+	ErrColumnNotFound = errors.New("column not found")
+
+	// ErrDescriptionTooLong is returned for descriptions longer than
+	// MaxDescriptionLength.
+	ErrDescriptionTooLong = errors.New("description is too long")
+)
+
+// ColumnDescriptionField returns the field name of the description of a
+// column, as reported in conflicts.
+func ColumnDescriptionField(column string) string {
+	return columnField(column, FieldDescription)
+}
+
+// ApplyDescription sets the description of t, or of its column named column
+// if column is not empty. Descriptions are markdown; surrounding whitespace is
+// trimmed and an empty description clears it.
+func ApplyDescription(t *TableMetadata, column, description string, at time.Time) error {
+	description = strings.TrimSpace(description)
+	if len(description) > MaxDescriptionLength {
+		return fmt.Errorf("%w: %d bytes, at most %d allowed", ErrDescriptionTooLong, len(description), MaxDescriptionLength)
+	}
+
+	if column == "" {
+		t.Description = description
+	} else {
+		col := t.column(column)
+		if col == nil {
+			return fmt.Errorf("%w: %s", ErrColumnNotFound, column)
+		}
+		col.Description = description
+	}
+	t.UpdatedAt = at
+	return nil
+}
+
+// column returns the column of t named name, or nil.
+func (t *TableMetadata) column(name string) *ColumnMetadata {
+	for _, col := range t.Columns {
+		if col.Name == name {
+			return col
+		}
+	}
+	return nil
+}
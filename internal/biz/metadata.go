@@ -9,15 +9,32 @@ type TableMetadata struct {
 	Database    string            `json:"database"`
 	Schema      string            `json:"schema"`
 	Name        string            `json:"name"`
-	Type        string            `json:"type"` // table, view, etc.
-	Comment     string            `json:"comment"`
+	Type        string            `json:"type"`        // table, view, etc.
+	Source      string            `json:"source"`      // connector the table is collected from
+	Comment     string            `json:"comment"`     // from the data source
+	Description string            `json:"description"` // user-authored markdown, never set by sync
 	Columns     []*ColumnMetadata `json:"columns"`
 	Indexes     []*IndexMetadata  `json:"indexes"`
 	RowCount    int64             `json:"row_count"`
 	DataSize    int64             `json:"data_size"`
+	ConsumerLag *int64            `json:"consumer_lag,omitempty"` // furthest-behind consumer group of a topic
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
 	CollectedAt time.Time         `json:"collected_at"`
+
+	// Version is incremented on every write and used for optimistic locking.
+	Version int64 `json:"version"`
+	// Overrides are user edits of fields that are also populated by sync.
+	Overrides []*FieldOverride `json:"overrides,omitempty"`
+	// Annotations are user-defined namespaced key/values such as
+	// dq.check=enabled, never set by sync.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Owners, Tags and Deprecation are curated by users, never set by sync.
+	Owners      []string     `json:"owners,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+	// Readme is the long-form documentation, never set by sync.
+	Readme *Readme `json:"readme,omitempty"`
 }
 
 // ColumnMetadata represents metadata for a table column.
@@ -30,10 +47,25 @@ type ColumnMetadata struct {
 	Scale        int    `json:"scale"`
 	Nullable     bool   `json:"nullable"`
 	DefaultValue string `json:"default_value"`
-	Comment      string `json:"comment"`
+	Comment      string `json:"comment"`     // from the data source
+	Description  string `json:"description"` // user-authored markdown, never set by sync
 	IsPrimaryKey bool   `json:"is_primary_key"`
 	IsForeignKey bool   `json:"is_foreign_key"`
 	Position     int    `json:"position"`
+
+	// Annotations are user-defined namespaced key/values, never set by sync.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Tags and Deprecation are curated by users, never set by sync.
+	Tags        []string     `json:"tags,omitempty"`
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+}
+
+// Deprecation marks a table or column as deprecated.
+type Deprecation struct {
+	// Note explains the deprecation, e.g. the replacement to use instead.
+	Note string    `json:"note,omitempty"`
+	By   string    `json:"by,omitempty"`
+	At   time.Time `json:"at"`
 }
 
 // IndexMetadata represents metadata for a table index.
@@ -0,0 +1,57 @@
+package biz
+
+import (
+	"context"
+	"time"
+)
+
+// Notification event types.
+const (
+	// EventSchemaDrift is notified when a sync finds added, dropped or
+	// altered columns.
+	EventSchemaDrift = "schema_drift"
+	// EventSLABreach is notified when a table violates the freshness of a
+	// policy.
+	EventSLABreach = "sla_breach"
+	// EventPolicyViolation is notified for other new policy violations.
+	EventPolicyViolation = "policy_violation"
+	// EventSyncFailed is notified when the sync of a source fails. Its
+	// Table is empty and its Changes are the errors of the sync.
+	EventSyncFailed = "sync_failed"
+)
+
+// Notification reports an event of a table to the people watching it.
+type Notification struct {
+	Event  string
+	Source string
+	// Table is the qualified name of the table, empty for events of a
+	// source.
+	Table string
+	// Summary is a one-line description of the event, Changes its details.
+	Summary string
+	Changes []string
+	Owners  []string
+	At      time.Time
+}
+
+// Notifier sends notifications, e.g. to chat channels.
+type Notifier interface {
+	Notify(ctx context.Context, n *Notification) error
+}
+
+// SetNotifier sets the notifier of the policy events found by syncs.
+// Without one no notifications are sent.
+func (uc *TableUsecase) SetNotifier(n Notifier) {
+	uc.notifier = n
+}
+
+// notify sends n if a notifier is set. Like policy checks, a failed
+// notification must not fail the sync.
+func (uc *TableUsecase) notify(ctx context.Context, n *Notification) {
+	if uc.notifier == nil {
+		return
+	}
+	if err := uc.notifier.Notify(ctx, n); err != nil {
+		uc.log.Errorf("notifying %s of %s failed: %v", n.Event, n.Table, err)
+	}
+}
@@ -0,0 +1,373 @@
+package biz
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PolicySeverity is the severity of a policy violation.
+type PolicySeverity = string
+
+// Policy severities.
+const (
+	SeverityError   PolicySeverity = "error"
+	SeverityWarning PolicySeverity = "warning"
+)
+
+// PolicyEventType is the type of a policy event.
+type PolicyEventType = string
+
+// Policy event types.
+const (
+	// PolicyViolated is reported when a sync finds a new violation.
+	PolicyViolated PolicyEventType = "violated"
+	// PolicyResolved is reported when a sync no longer finds a violation.
+	PolicyResolved PolicyEventType = "resolved"
+)
+
+// ErrInvalidPolicy is returned for malformed policy files and policies.
+var ErrInvalidPolicy = errors.New("invalid policy")
+
+// policyName matches policy names such as finance-owner.
+var policyName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// PolicySet is a set of metadata policies, usually kept in a YAML file:
+//
+//	policies:
+//	  - name: finance-owner
+//	    description: Every table in schema finance must have an owner.
+//	    match:
+//	      schema: finance
+//	    require:
+//	      owner: true
+//	  - name: pii-tagged
+//	    severity: warning
+//	    match:
+//	      columns: ["*email*", "*phone*"]
+//	    require:
+//	      tags: [pii]
+//	  - name: orders-fresh
+//	    match:
+//	      table: orders
+//	    require:
+//	      freshness: 24h
+type PolicySet struct {
+	Policies []*Policy `json:"policies" yaml:"policies"`
+}
+
+// Policy is a rule that the tables, or the columns, selected by Match must
+// satisfy.
+type Policy struct {
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Severity    PolicySeverity    `json:"severity,omitempty" yaml:"severity,omitempty"` // error if empty
+	Match       PolicyMatch       `json:"match" yaml:"match"`
+	Require     PolicyRequirement `json:"require" yaml:"require"`
+}
+
+// PolicyMatch selects tables by glob patterns (see path.Match) compared
+// case-insensitively; empty patterns match everything. If Columns is set the
+// policy applies to the columns of the selected tables whose name matches
+// any of the patterns instead of to the tables.
+type PolicyMatch struct {
+	Database string `json:"database,omitempty" yaml:"database,omitempty"`
+	Schema   string `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Table    string `json:"table,omitempty" yaml:"table,omitempty"`
+	// Tags selects the tables carrying all of the tags.
+	Tags    []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Columns []string `json:"columns,omitempty" yaml:"columns,omitempty"`
+}
+
+// PolicyRequirement is what a policy requires of the tables or columns it
+// applies to.
+type PolicyRequirement struct {
+	// Owner requires at least one owner; tables only.
+	Owner       bool     `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Description bool     `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Annotations are required annotation keys, or key=value pairs.
+	Annotations []string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	// Freshness is the longest time, e.g. 24h, that a table may go without
+	// changing; tables only. Violations are reported as SLA breaches.
+	Freshness string `json:"freshness,omitempty" yaml:"freshness,omitempty"`
+}
+
+// PolicyViolation is a table or column that does not satisfy a policy.
+// Column is empty for table policies.
+type PolicyViolation struct {
+	Policy     string         `json:"policy"`
+	Severity   PolicySeverity `json:"severity"`
+	TableID    string         `json:"table_id"`
+	Table      string         `json:"table"` // database.table or database.schema.table
+	Column     string         `json:"column,omitempty"`
+	Message    string         `json:"message"`
+	DetectedAt time.Time      `json:"detected_at"`
+}
+
+// PolicyEvent reports a change in the violations of a table found by a sync.
+type PolicyEvent struct {
+	Type      PolicyEventType  `json:"type"`
+	Violation *PolicyViolation `json:"violation"`
+	At        time.Time        `json:"at"`
+}
+
+// ParsePolicies reads a YAML policy file and validates it.
+func ParsePolicies(r io.Reader) (*PolicySet, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	var set PolicySet
+	if err := dec.Decode(&set); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+// Validate checks the policies of s.
+func (s *PolicySet) Validate() error {
+	names := make(map[string]bool, len(s.Policies))
+	for i, p := range s.Policies {
+		if p == nil || p.Name == "" {
+			return fmt.Errorf("%w: policy %d has no name", ErrInvalidPolicy, i+1)
+		}
+		if err := p.validate(); err != nil {
+			return err
+		}
+		if names[p.Name] {
+			return fmt.Errorf("%w: policy %s is defined more than once", ErrInvalidPolicy, p.Name)
+		}
+		names[p.Name] = true
+	}
+	return nil
+}
+
+// policy returns the policy named name, or nil.
+func (s *PolicySet) policy(name string) *Policy {
+	if s == nil {
+		return nil
+	}
+	for _, p := range s.Policies {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func (p *Policy) validate() error {
+	if !policyName.MatchString(p.Name) {
+		return fmt.Errorf("%w: name %q must be lowercase letters, digits, '.', '_' and '-'", ErrInvalidPolicy, p.Name)
+	}
+	switch p.Severity {
+	case "", SeverityError, SeverityWarning:
+	default:
+		return fmt.Errorf("%w: %s: severity must be error or warning", ErrInvalidPolicy, p.Name)
+	}
+	for _, pattern := range append([]string{p.Match.Database, p.Match.Schema, p.Match.Table}, p.Match.Columns...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%w: %s: bad pattern %q", ErrInvalidPolicy, p.Name, pattern)
+		}
+	}
+	req := p.Require
+	if !req.Owner && !req.Description && len(req.Tags) == 0 && len(req.Annotations) == 0 && req.Freshness == "" {
+		return fmt.Errorf("%w: %s requires nothing", ErrInvalidPolicy, p.Name)
+	}
+	if req.Owner && len(p.Match.Columns) > 0 {
+		return fmt.Errorf("%w: %s: owners are required of tables, not columns", ErrInvalidPolicy, p.Name)
+	}
+	if req.Freshness != "" {
+		if len(p.Match.Columns) > 0 {
+			return fmt.Errorf("%w: %s: freshness is required of tables, not columns", ErrInvalidPolicy, p.Name)
+		}
+		if d, err := time.ParseDuration(req.Freshness); err != nil || d <= 0 {
+			return fmt.Errorf("%w: %s: bad freshness %q", ErrInvalidPolicy, p.Name, req.Freshness)
+		}
+	}
+	for _, a := range req.Annotations {
+		key, value, _ := strings.Cut(a, "=")
+		if err := ValidateAnnotation(key, value); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidPolicy, p.Name, err)
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the violations of the policies of s by t, in the order of
+// the policies and columns. A nil set has no policies.
+func (s *PolicySet) Evaluate(t *TableMetadata, at time.Time) []*PolicyViolation {
+	if s == nil {
+		return nil
+	}
+	var violations []*PolicyViolation
+	report := func(p *Policy, column, message string) {
+		severity := p.Severity
+		if severity == "" {
+			severity = SeverityError
+		}
+		violations = append(violations, &PolicyViolation{
+			Policy:     p.Name,
+			Severity:   severity,
+			TableID:    t.ID,
+			Table:      qualifiedName(t),
+			Column:     column,
+			Message:    message,
+			DetectedAt: at,
+		})
+	}
+
+	for _, p := range s.Policies {
+		if !p.Match.matchTable(t) {
+			continue
+		}
+		if len(p.Match.Columns) == 0 {
+			for _, msg := range p.Require.check(len(t.Owners) > 0, t.Description, t.Tags, t.Annotations) {
+				report(p, "", msg)
+			}
+			if p.Require.stale(t, at) {
+				report(p, "", p.Require.freshnessMessage())
+			}
+			continue
+		}
+		for _, col := range t.Columns {
+			if !matchAny(p.Match.Columns, col.Name) {
+				continue
+			}
+			for _, msg := range p.Require.check(true, col.Description, col.Tags, col.Annotations) {
+				report(p, col.Name, msg)
+			}
+		}
+	}
+	return violations
+}
+
+func (m *PolicyMatch) matchTable(t *TableMetadata) bool {
+	if !matchGlob(m.Database, t.Database) || !matchGlob(m.Schema, t.Schema) || !matchGlob(m.Table, t.Name) {
+		return false
+	}
+	for _, tag := range m.Tags {
+		if !hasTag(t.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// check returns a message for every requirement that is not met.
+func (r *PolicyRequirement) check(hasOwner bool, description string, tags []string, annotations map[string]string) []string {
+	var missing []string
+	if r.Owner && !hasOwner {
+		missing = append(missing, "has no owner")
+	}
+	if r.Description && strings.TrimSpace(description) == "" {
+		missing = append(missing, "has no description")
+	}
+	for _, tag := range r.Tags {
+		if !hasTag(tags, tag) {
+			missing = append(missing, fmt.Sprintf("is not tagged %s", tag))
+		}
+	}
+	for _, a := range r.Annotations {
+		key, value, withValue := strings.Cut(a, "=")
+		actual, ok := annotations[key]
+		switch {
+		case !ok:
+			missing = append(missing, fmt.Sprintf("has no annotation %s", key))
+		case withValue && actual != value:
+			missing = append(missing, fmt.Sprintf("has annotation %s=%s, not %s", key, actual, value))
+		}
+	}
+	return missing
+}
+
+// stale reports whether t has not changed within the required freshness.
+func (r *PolicyRequirement) stale(t *TableMetadata, at time.Time) bool {
+	if r.Freshness == "" || t.UpdatedAt.IsZero() {
+		return false
+	}
+	d, err := time.ParseDuration(r.Freshness)
+	return err == nil && at.Sub(t.UpdatedAt) > d
+}
+
+// freshnessMessage is the message of freshness violations. It must not
+// depend on the age of the table, so that a table staying stale keeps a
+// single open violation.
+func (r *PolicyRequirement) freshnessMessage() string {
+	return fmt.Sprintf("has not changed within %s", r.Freshness)
+}
+
+// DiffViolations compares the violations of a table before and after a sync
+// and returns the events reporting the differences. Violations still open
+// keep their original detection time.
+func DiffViolations(previous, current []*PolicyViolation, at time.Time) []*PolicyEvent {
+	open := make(map[string]*PolicyViolation, len(previous))
+	for _, v := range previous {
+		open[v.key()] = v
+	}
+
+	var events []*PolicyEvent
+	for _, v := range current {
+		if prev, ok := open[v.key()]; ok {
+			v.DetectedAt = prev.DetectedAt
+			delete(open, v.key())
+			continue
+		}
+		events = append(events, &PolicyEvent{Type: PolicyViolated, Violation: v, At: at})
+	}
+	resolved := make([]*PolicyViolation, 0, len(open))
+	for _, v := range open {
+		resolved = append(resolved, v)
+	}
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i].key() < resolved[j].key() })
+	for _, v := range resolved {
+		events = append(events, &PolicyEvent{Type: PolicyResolved, Violation: v, At: at})
+	}
+	return events
+}
+
+func (v *PolicyViolation) key() string {
+	return v.Policy + "\x00" + v.Column + "\x00" + v.Message
+}
+
+// qualifiedName returns the name of t as written in change and policy files.
+func qualifiedName(t *TableMetadata) string {
+	if t.Schema == "" {
+		return t.Database + "." + t.Name
+	}
+	return t.Database + "." + t.Schema + "." + t.Name
+}
+
+func matchGlob(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+	return ok
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if matchGlob(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
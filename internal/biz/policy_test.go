@@ -0,0 +1,151 @@
+package biz
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPolicies = `
+policies:
+  - name: finance-owner
+    match:
+      schema: fin*
+    require:
+      owner: true
+      description: true
+  - name: pii-tagged
+    severity: warning
+    match:
+      columns: ["*email*", "*PHONE*"]
+    require:
+      tags: [pii]
+      annotations: [privacy.class=restricted]
+  - name: gold-fresh
+    match:
+      tags: [gold]
+    require:
+      freshness: 24h
+`
+
+func TestParsePolicies(t *testing.T) {
+	set, err := ParsePolicies(strings.NewReader(testPolicies))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Policies) != 3 || set.Policies[1].Severity != SeverityWarning || set.Policies[2].Require.Freshness != "24h" {
+		t.Errorf("Unexpected policies %+v", set.Policies)
+	}
+
+	invalid := map[string]string{
+		"unknown field":      "policies:\n  - name: p\n    require:\n      owners: true\n",
+		"no name":            "policies:\n  - require:\n      owner: true\n",
+		"bad name":           "policies:\n  - name: Finance Owner\n    require:\n      owner: true\n",
+		"duplicate":          "policies:\n  - name: p\n    require:\n      owner: true\n  - name: p\n    require:\n      owner: true\n",
+		"bad severity":       "policies:\n  - name: p\n    severity: fatal\n    require:\n      owner: true\n",
+		"bad pattern":        "policies:\n  - name: p\n    match:\n      table: \"[orders\"\n    require:\n      owner: true\n",
+		"requires nothing":   "policies:\n  - name: p\n    match:\n      table: orders\n",
+		"column owner":       "policies:\n  - name: p\n    match:\n      columns: [id]\n    require:\n      owner: true\n",
+		"column freshness":   "policies:\n  - name: p\n    match:\n      columns: [id]\n    require:\n      freshness: 1h\n",
+		"bad freshness":      "policies:\n  - name: p\n    require:\n      freshness: daily\n",
+		"negative freshness": "policies:\n  - name: p\n    require:\n      freshness: -1h\n",
+		"bad annotation":     "policies:\n  - name: p\n    require:\n      annotations: [Owner]\n",
+	}
+	for name, doc := range invalid {
+		if _, err := ParsePolicies(strings.NewReader(doc)); !errors.Is(err, ErrInvalidPolicy) {
+			t.Errorf("%s: expected ErrInvalidPolicy, got %v", name, err)
+		}
+	}
+}
+
+func TestPolicySetEvaluate(t *testing.T) {
+	set, err := ParsePolicies(strings.NewReader(testPolicies))
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	table := &TableMetadata{
+		ID:       "7",
+		Database: "dw",
+		Schema:   "Finance",
+		Name:     "ledger",
+		Tags:     []string{"GOLD"},
+		Columns: []*ColumnMetadata{
+			{Name: "id"},
+			{Name: "customer_email", Tags: []string{"pii"}},
+			{Name: "phone_number", Annotations: map[string]string{"privacy.class": "internal"}},
+		},
+		UpdatedAt: at.Add(-25 * time.Hour),
+	}
+
+	var got []string
+	for _, v := range set.Evaluate(table, at) {
+		if v.TableID != "7" || v.Table != "dw.Finance.ledger" || !v.DetectedAt.Equal(at) {
+			t.Errorf("Unexpected violation %+v", v)
+		}
+		got = append(got, v.Policy+"/"+v.Severity+"/"+v.Column+": "+v.Message)
+	}
+	want := []string{
+		"finance-owner/error/: has no owner",
+		"finance-owner/error/: has no description",
+		"pii-tagged/warning/customer_email: has no annotation privacy.class",
+		"pii-tagged/warning/phone_number: is not tagged pii",
+		"pii-tagged/warning/phone_number: has annotation privacy.class=internal, not restricted",
+		"gold-fresh/error/: has not changed within 24h",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Evaluate() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Satisfying every requirement leaves no violation
+	table.Owners = []string{"alice"}
+	table.Description = "General ledger"
+	table.UpdatedAt = at.Add(-time.Hour)
+	for _, c := range table.Columns[1:] {
+		c.Tags = []string{"PII"}
+		c.Annotations = map[string]string{"privacy.class": "restricted"}
+	}
+	if violations := set.Evaluate(table, at); len(violations) != 0 {
+		t.Errorf("Expected no violation, got %v", violations)
+	}
+
+	// Tables outside the matches are not checked
+	other := &TableMetadata{Database: "dw", Schema: "sales", Name: "orders", Columns: []*ColumnMetadata{{Name: "id"}}}
+	if violations := set.Evaluate(other, at); len(violations) != 0 {
+		t.Errorf("Expected no violation, got %v", violations)
+	}
+	if violations := (*PolicySet)(nil).Evaluate(other, at); violations != nil {
+		t.Errorf("Expected no violation of a nil set, got %v", violations)
+	}
+}
+
+func TestDiffViolations(t *testing.T) {
+	first := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := first.Add(time.Hour)
+	violation := func(policy, column string, detected time.Time) *PolicyViolation {
+		return &PolicyViolation{Policy: policy, Column: column, Message: "has no owner", DetectedAt: detected}
+	}
+	previous := []*PolicyViolation{violation("owner", "", first), violation("tagged", "b", first), violation("tagged", "a", first)}
+	current := []*PolicyViolation{violation("owner", "", at), violation("described", "", at)}
+
+	events := DiffViolations(previous, current, at)
+	var got []string
+	for _, e := range events {
+		if !e.At.Equal(at) {
+			t.Errorf("event at %v", e.At)
+		}
+		got = append(got, e.Type+" "+e.Violation.Policy+"."+e.Violation.Column)
+	}
+	want := "violated described. resolved tagged.a resolved tagged.b"
+	if strings.Join(got, " ") != want {
+		t.Errorf("events = %q, want %q", strings.Join(got, " "), want)
+	}
+	// A violation still open keeps its detection time
+	if !current[0].DetectedAt.Equal(first) {
+		t.Errorf("detected at %v, want %v", current[0].DetectedAt, first)
+	}
+	if events := DiffViolations(current, current, at); len(events) != 0 {
+		t.Errorf("Expected no events, got %v", events)
+	}
+}
@@ -0,0 +1,180 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Sync progress event types.
+const (
+	// ProgressStarted is published when an execution starts syncing, with
+	// the number of tables to sync.
+	ProgressStarted = "started"
+	// ProgressTable is published after each table, with Error set if it
+	// failed.
+	ProgressTable = "table"
+	// ProgressFinished is the last event of an execution, with Error set if
+	// the sync failed as a whole.
+	ProgressFinished = "finished"
+)
+
+const (
+	// progressBuffer is the number of events buffered per watcher. A watcher
+	// that falls further behind loses the oldest events, which is harmless
+	// since every event carries the cumulative counts.
+	progressBuffer = 64
+	// progressRetention is how long the outcome of a finished execution can
+	// still be watched.
+	progressRetention = 10 * time.Minute
+)
+
+// ErrExecutionNotRunning is returned when watching the progress of an
+// execution that is neither running nor recently finished.
+var ErrExecutionNotRunning = errors.New("execution is not running")
+
+// SyncProgress is a progress event of a running sync execution.
+type SyncProgress struct {
+	ExecutionID string
+	Type        string
+	// Table is the qualified name of the table of a ProgressTable event.
+	Table string
+	Error string
+	// Completed counts the synced tables, Failed those that failed, out of
+	// Total tables.
+	Completed int
+	Failed    int
+	Total     int
+	At        time.Time
+}
+
+// ProgressBroker fans the progress events of running sync executions out to
+// their watchers. ProgressBroker is safe for concurrent use.
+type ProgressBroker struct {
+	mu   sync.Mutex
+	runs map[string]*progressRun
+}
+
+type progressRun struct {
+	latest   *SyncProgress
+	watchers map[chan *SyncProgress]struct{}
+	finished time.Time
+}
+
+// NewProgressBroker creates a broker without running executions.
+func NewProgressBroker() *ProgressBroker {
+	return &ProgressBroker{runs: make(map[string]*progressRun)}
+}
+
+// Start publishes the start of an execution syncing total tables.
+func (b *ProgressBroker) Start(executionID string, total int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for id, run := range b.runs {
+		if !run.finished.IsZero() && now.Sub(run.finished) > progressRetention {
+			delete(b.runs, id)
+		}
+	}
+	run, ok := b.runs[executionID]
+	if !ok {
+		run = &progressRun{watchers: make(map[chan *SyncProgress]struct{})}
+		b.runs[executionID] = run
+	}
+	run.finished = time.Time{}
+	b.publish(run, &SyncProgress{ExecutionID: executionID, Type: ProgressStarted, Total: total, At: now})
+}
+
+// TableDone publishes that a table was synced, or failed with err.
+func (b *ProgressBroker) TableDone(executionID, table string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	run, ok := b.runs[executionID]
+	if !ok || !run.finished.IsZero() {
+		return
+	}
+	p := *run.latest
+	p.Type, p.Table, p.Error, p.At = ProgressTable, table, "", time.Now()
+	if err != nil {
+		p.Error = err.Error()
+		p.Failed++
+	} else {
+		p.Completed++
+	}
+	b.publish(run, &p)
+}
+
+// Finish publishes the end of an execution, which failed if err is not nil,
+// and closes the channels of its watchers.
+func (b *ProgressBroker) Finish(executionID string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	run, ok := b.runs[executionID]
+	if !ok || !run.finished.IsZero() {
+		return
+	}
+	p := *run.latest
+	p.Type, p.Table, p.Error, p.At = ProgressFinished, "", "", time.Now()
+	if err != nil {
+		p.Error = err.Error()
+	}
+	b.publish(run, &p)
+	run.finished = p.At
+	for ch := range run.watchers {
+		close(ch)
+	}
+	run.watchers = make(map[chan *SyncProgress]struct{})
+}
+
+// Watch returns the progress events of an execution, starting with its
+// latest one. The channel is closed after the ProgressFinished event or when
+// ctx is done.
+func (b *ProgressBroker) Watch(ctx context.Context, executionID string) (<-chan *SyncProgress, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	run, ok := b.runs[executionID]
+	if !ok {
+		return nil, ErrExecutionNotRunning
+	}
+	ch := make(chan *SyncProgress, progressBuffer)
+	ch <- run.latest
+	if !run.finished.IsZero() {
+		close(ch)
+		return ch, nil
+	}
+	run.watchers[ch] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := run.watchers[ch]; ok {
+			delete(run.watchers, ch)
+			close(ch)
+		}
+	}()
+	return ch, nil
+}
+
+// publish records p as the latest event of run and sends it to the
+// watchers, dropping their oldest buffered event if they fall behind.
+func (b *ProgressBroker) publish(run *progressRun, p *SyncProgress) {
+	run.latest = p
+	for ch := range run.watchers {
+		select {
+		case ch <- p:
+			continue
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- p
+	}
+}
@@ -0,0 +1,64 @@
+package biz
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxReadmeLength is the maximum length in bytes of a README.
+const MaxReadmeLength = 1 << 20
+
+// ErrReadmeTooLong is returned for READMEs longer than MaxReadmeLength.
+var ErrReadmeTooLong = errors.New("readme is too long")
+
+// Readme is the long-form markdown documentation of a table, such as its
+// grain, load schedule and known caveats, kept separate from the short
+// description shown in listings.
+type Readme struct {
+	Markdown string `json:"markdown,omitempty"`
+	// Path is where the README is maintained, e.g. docs/dw/orders.md in the
+	// repository of the models or a URL of the file in a git host. A README
+	// may be only a link.
+	Path      string    `json:"path,omitempty"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ApplyReadme sets the README of t. Surrounding whitespace is trimmed; an
+// empty markdown and path removes the README.
+func ApplyReadme(t *TableMetadata, markdown, path, user string, at time.Time) error {
+	markdown = strings.TrimSpace(markdown)
+	path = strings.TrimSpace(path)
+	if len(markdown) > MaxReadmeLength {
+		return fmt.Errorf("%w: %d bytes, at most %d allowed", ErrReadmeTooLong, len(markdown), MaxReadmeLength)
+	}
+
+	if markdown == "" && path == "" {
+		t.Readme = nil
+	} else if t.Readme == nil || t.Readme.Markdown != markdown || t.Readme.Path != path {
+		t.Readme = &Readme{Markdown: markdown, Path: path, UpdatedBy: user, UpdatedAt: at}
+	}
+	t.UpdatedAt = at
+	return nil
+}
+
+// formatReadme summarizes a README for plans: its path, size and a digest of
+// its content.
+func formatReadme(r *Readme) string {
+	if r == nil {
+		return ""
+	}
+	var parts []string
+	if r.Path != "" {
+		parts = append(parts, r.Path)
+	}
+	if r.Markdown != "" {
+		sum := sha256.Sum256([]byte(r.Markdown))
+		parts = append(parts, fmt.Sprintf("%d bytes, sha256:%s", len(r.Markdown), hex.EncodeToString(sum[:4])))
+	}
+	return strings.Join(parts, " ")
+}
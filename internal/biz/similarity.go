@@ -0,0 +1,280 @@
+package biz
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// minHashBands and minHashRows split the MinHash signature into bands
+	// for locality-sensitive hashing. Tables whose signatures agree on all
+	// rows of any band become candidate pairs; with 32 bands of 4 rows a
+	// pair with a Jaccard similarity of 0.5 is found with a probability of
+	// about 87%, one of 0.7 with more than 99.9%.
+	minHashBands = 32
+	minHashRows  = 4
+	minHashSize  = minHashBands * minHashRows
+
+	defaultSimilarityThreshold  = 0.8
+	defaultSimilarityMinColumns = 3
+)
+
+// SimilarityOptions configures duplicate dataset detection.
+type SimilarityOptions struct {
+	// Threshold is the minimum Jaccard similarity of the column sets of two
+	// tables, between 0 and 1. Defaults to 0.8.
+	Threshold float64 `json:"threshold"`
+	// MinColumns skips tables with fewer columns, whose similarity says
+	// little. Defaults to 3.
+	MinColumns int `json:"min_columns"`
+	// CrossSource only compares tables collected from different sources.
+	CrossSource bool `json:"cross_source"`
+}
+
+// DuplicateCandidate is a pair of tables with similar columns, likely
+// copies of the same dataset.
+type DuplicateCandidate struct {
+	Left        string `json:"left"`
+	LeftSource  string `json:"left_source,omitempty"`
+	Right       string `json:"right"`
+	RightSource string `json:"right_source,omitempty"`
+	// Similarity is the Jaccard similarity of the column name and type
+	// family sets of the tables.
+	Similarity float64 `json:"similarity"`
+	// NameSimilarity is the Jaccard similarity of the column name sets.
+	NameSimilarity float64 `json:"name_similarity"`
+	// SharedColumns are the column names both tables have.
+	SharedColumns []string `json:"shared_columns"`
+	// TypeMismatches are the shared columns whose type families differ.
+	TypeMismatches []string `json:"type_mismatches,omitempty"`
+}
+
+// FindDuplicates compares the columns of all the tables of the catalog, as
+// merged by syncs, and returns the likely duplicated datasets, most similar
+// first.
+func (uc *TableUsecase) FindDuplicates(ctx context.Context, opts SimilarityOptions) ([]*DuplicateCandidate, error) {
+	tables, err := uc.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return FindDuplicates(tables, opts), nil
+}
+
+// FindDuplicates returns the pairs of tables whose column sets are at least
+// opts.Threshold similar. Columns are compared by normalized name and type
+// family, so that e.g. user_id BIGINT in MySQL matches UserId LONG in Hive.
+// Candidate pairs are found with MinHash signatures and locality-sensitive
+// hashing instead of comparing every pair; their exact similarity is then
+// computed.
+func FindDuplicates(tables []*TableMetadata, opts SimilarityOptions) []*DuplicateCandidate {
+	if opts.Threshold <= 0 {
+		opts.Threshold = defaultSimilarityThreshold
+	}
+	if opts.MinColumns <= 0 {
+		opts.MinColumns = defaultSimilarityMinColumns
+	}
+
+	profiles := make([]*tableProfile, 0, len(tables))
+	for _, t := range tables {
+		if len(t.Columns) >= opts.MinColumns {
+			profiles = append(profiles, newTableProfile(t))
+		}
+	}
+
+	buckets := make(map[uint64][]int)
+	for i, p := range profiles {
+		for band := 0; band < minHashBands; band++ {
+			h := fnv.New64a()
+			var buf [8]byte
+			buf[0] = byte(band)
+			h.Write(buf[:1])
+			for _, v := range p.signature[band*minHashRows : (band+1)*minHashRows] {
+				for j := range buf {
+					buf[j] = byte(v >> (8 * j))
+				}
+				h.Write(buf[:])
+			}
+			key := h.Sum64()
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	seen := make(map[[2]int]bool)
+	var candidates []*DuplicateCandidate
+	for _, bucket := range buckets {
+		for x := 0; x < len(bucket); x++ {
+			for y := x + 1; y < len(bucket); y++ {
+				pair := [2]int{bucket[x], bucket[y]}
+				if seen[pair] {
+					continue
+				}
+				seen[pair] = true
+				left, right := profiles[pair[0]], profiles[pair[1]]
+				if opts.CrossSource && left.table.Source == right.table.Source {
+					continue
+				}
+				if c := compareProfiles(left, right); c.Similarity >= opts.Threshold {
+					candidates = append(candidates, c)
+				}
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Similarity != b.Similarity {
+			return a.Similarity > b.Similarity
+		}
+		if a.Left != b.Left {
+			return a.Left < b.Left
+		}
+		return a.Right < b.Right
+	})
+	return candidates
+}
+
+// tableProfile holds the column features and MinHash signature of a table.
+type tableProfile struct {
+	table     *TableMetadata
+	name      string
+	features  map[string]bool   // normalized name:type family
+	types     map[string]string // normalized name -> type family
+	signature [minHashSize]uint64
+}
+
+func newTableProfile(t *TableMetadata) *tableProfile {
+	p := &tableProfile{
+		table:    t,
+		name:     qualifiedName(t),
+		features: make(map[string]bool),
+		types:    make(map[string]string),
+	}
+	for i := range p.signature {
+		p.signature[i] = math.MaxUint64
+	}
+	for _, col := range t.Columns {
+		name := normalizeColumnName(col.Name)
+		family := typeFamily(col)
+		p.types[name] = family
+		feature := name + ":" + family
+		p.features[feature] = true
+
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		x := h.Sum64()
+		for i := range p.signature {
+			if v := minHash(x, uint64(i)); v < p.signature[i] {
+				p.signature[i] = v
+			}
+		}
+	}
+	return p
+}
+
+// minHash derives the i-th hash of a feature hash with the splitmix64
+// finalizer.
+func minHash(x, i uint64) uint64 {
+	z := x + (i+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func compareProfiles(left, right *tableProfile) *DuplicateCandidate {
+	if right.name < left.name {
+		left, right = right, left
+	}
+	c := &DuplicateCandidate{
+		Left:        left.name,
+		LeftSource:  left.table.Source,
+		Right:       right.name,
+		RightSource: right.table.Source,
+	}
+
+	shared := 0
+	for f := range left.features {
+		if right.features[f] {
+			shared++
+		}
+	}
+	c.Similarity = jaccard(shared, len(left.features), len(right.features))
+
+	for name, family := range left.types {
+		other, ok := right.types[name]
+		if !ok {
+			continue
+		}
+		c.SharedColumns = append(c.SharedColumns, name)
+		if other != family {
+			c.TypeMismatches = append(c.TypeMismatches, name)
+		}
+	}
+	sort.Strings(c.SharedColumns)
+	sort.Strings(c.TypeMismatches)
+	c.NameSimilarity = jaccard(len(c.SharedColumns), len(left.types), len(right.types))
+	return c
+}
+
+func jaccard(shared, left, right int) float64 {
+	union := left + right - shared
+	if union == 0 {
+		return 0
+	}
+	return math.Round(float64(shared)/float64(union)*1000) / 1000
+}
+
+// normalizeColumnName lowercases a column name and drops the characters
+// naming conventions differ in, so that user_id, UserId and "USER-ID" match.
+func normalizeColumnName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r > 127 {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// typeFamily maps the type of a column to a family comparable across
+// sources: integer, decimal, float, string, boolean, date, timestamp,
+// binary, json, complex or the lowercased base type.
+func typeFamily(col *ColumnMetadata) string {
+	t := col.DataType
+	if t == "" {
+		t = col.Type
+	}
+	t = strings.ToLower(strings.TrimSpace(t))
+	if i := strings.IndexAny(t, "(< "); i >= 0 {
+		t = t[:i]
+	}
+
+	switch t {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint", "long", "short", "byte",
+		"int2", "int4", "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64",
+		"serial", "bigserial", "smallserial":
+		return "integer"
+	case "decimal", "numeric", "number", "money", "decimal32", "decimal64", "decimal128":
+		return "decimal"
+	case "float", "double", "real", "float4", "float8", "float32", "float64":
+		return "float"
+	case "char", "varchar", "nchar", "nvarchar", "varchar2", "nvarchar2", "text", "tinytext",
+		"mediumtext", "longtext", "string", "clob", "nclob", "uuid", "enum", "fixedstring", "keyword":
+		return "string"
+	case "bool", "boolean", "bit":
+		return "boolean"
+	case "date", "date32":
+		return "date"
+	case "datetime", "datetime2", "datetime64", "timestamp", "timestamptz", "smalldatetime", "time", "timetz":
+		return "timestamp"
+	case "binary", "varbinary", "blob", "tinyblob", "mediumblob", "longblob", "bytea", "bytes", "raw", "image":
+		return "binary"
+	case "json", "jsonb", "object":
+		return "json"
+	case "array", "map", "struct", "nested", "tuple":
+		return "complex"
+	}
+	return t
+}
@@ -0,0 +1,184 @@
+package biz
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func similarTable(source, database, name string, columns ...string) *TableMetadata {
+	t := &TableMetadata{Source: source, Database: database, Name: name}
+	for _, c := range columns {
+		colName, colType, _ := strings.Cut(c, " ")
+		t.Columns = append(t.Columns, &ColumnMetadata{Name: colName, Type: colType})
+	}
+	return t
+}
+
+func TestFindDuplicates(t *testing.T) {
+	tables := []*TableMetadata{
+		similarTable("mysql", "shop", "users", "user_id bigint", "email varchar(255)", "created_at datetime", "name varchar(64)"),
+		similarTable("hive", "dw", "users", "UserId LONG", "EMAIL string", "created_at timestamp", "Name STRING"),
+		similarTable("mysql", "shop", "users_copy", "user_id int", "email text", "created_at datetime", "name varchar(64)"),
+		similarTable("pg", "crm", "contacts", "user_id bigint", "email text", "created_at timestamptz", "name boolean"),
+		similarTable("pg", "crm", "orders", "order_id bigint", "amount decimal(10,2)", "paid boolean", "placed_at date"),
+		// Too few columns to compare
+		similarTable("pg", "crm", "ids", "user_id bigint", "email text"),
+	}
+
+	got := FindDuplicates(tables, SimilarityOptions{})
+	var pairs []string
+	for _, c := range got {
+		pairs = append(pairs, fmt.Sprintf("%s/%s %s/%s %.3f", c.LeftSource, c.Left, c.RightSource, c.Right, c.Similarity))
+	}
+	want := []string{
+		"hive/dw.users mysql/shop.users 1.000",
+		"hive/dw.users mysql/shop.users_copy 1.000",
+		"mysql/shop.users mysql/shop.users_copy 1.000",
+	}
+	if strings.Join(pairs, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("FindDuplicates() =\n%s\nwant\n%s", strings.Join(pairs, "\n"), strings.Join(want, "\n"))
+	}
+	if shared := strings.Join(got[0].SharedColumns, ","); shared != "createdat,email,name,userid" || got[0].NameSimilarity != 1 {
+		t.Errorf("shared columns = %s, name similarity = %v", shared, got[0].NameSimilarity)
+	}
+
+	// A lower threshold finds the table whose name column has another type
+	got = FindDuplicates(tables, SimilarityOptions{Threshold: 0.6, CrossSource: true})
+	var contacts *DuplicateCandidate
+	for _, c := range got {
+		if c.LeftSource == c.RightSource {
+			t.Errorf("Same source pair %+v with CrossSource", c)
+		}
+		if c.Left == "crm.contacts" && c.Right == "dw.users" {
+			contacts = c
+		}
+	}
+	if contacts == nil {
+		t.Fatalf("crm.contacts and dw.users not found in %v", got)
+	}
+	// 3 of 5 name:type features are shared, all 4 names
+	if contacts.Similarity != 0.6 || contacts.NameSimilarity != 1 || strings.Join(contacts.TypeMismatches, ",") != "name" {
+		t.Errorf("Unexpected candidate %+v", contacts)
+	}
+
+	if got := FindDuplicates(tables, SimilarityOptions{MinColumns: 5}); len(got) != 0 {
+		t.Errorf("Expected no candidates with 5 columns or more, got %v", got)
+	}
+}
+
+func TestFindDuplicatesThreshold(t *testing.T) {
+	// 5 of 7 distinct columns are shared: a similarity of 0.714
+	left := similarTable("a", "db", "left", "a int", "b int", "c int", "d int", "e int", "f int")
+	right := similarTable("b", "db", "right", "a int", "b int", "c int", "d int", "e int", "g int")
+	tables := []*TableMetadata{left, right}
+
+	if got := FindDuplicates(tables, SimilarityOptions{}); len(got) != 0 {
+		t.Errorf("Expected no candidates at the default threshold, got %+v", got[0])
+	}
+	got := FindDuplicates(tables, SimilarityOptions{Threshold: 0.7})
+	if len(got) != 1 || got[0].Similarity != 0.714 || len(got[0].SharedColumns) != 5 {
+		t.Fatalf("Unexpected candidates %v", got)
+	}
+	if got := FindDuplicates(tables, SimilarityOptions{Threshold: 0.715}); len(got) != 0 {
+		t.Errorf("Expected no candidates just above the similarity, got %+v", got[0])
+	}
+}
+
+// TestFindDuplicatesBanding checks that the locality-sensitive hashing finds
+// the pairs that comparing every pair finds.
+func TestFindDuplicatesBanding(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var tables []*TableMetadata
+	for i := 0; i < 40; i++ {
+		// Families of tables sharing most of a base set of columns
+		base := i % 8
+		var columns []string
+		for c := 0; c < 10; c++ {
+			if rng.Intn(10) > 0 {
+				columns = append(columns, fmt.Sprintf("c%d_%d bigint", base, c))
+			}
+		}
+		columns = append(columns, fmt.Sprintf("x%d varchar", rng.Intn(3)))
+		tables = append(tables, similarTable("s", "db", fmt.Sprintf("t%02d", i), columns...))
+	}
+
+	opts := SimilarityOptions{Threshold: 0.7}
+	want := make(map[string]bool)
+	for i := range tables {
+		for j := i + 1; j < len(tables); j++ {
+			if c := compareProfiles(newTableProfile(tables[i]), newTableProfile(tables[j])); c.Similarity >= opts.Threshold {
+				want[c.Left+" "+c.Right] = true
+			}
+		}
+	}
+	if len(want) < 20 {
+		t.Fatalf("Expected many similar pairs, got %d", len(want))
+	}
+
+	got := FindDuplicates(tables, opts)
+	for _, c := range got {
+		if !want[c.Left+" "+c.Right] {
+			t.Errorf("Unexpected pair %s %s (%.3f)", c.Left, c.Right, c.Similarity)
+		}
+		delete(want, c.Left+" "+c.Right)
+	}
+	for pair := range want {
+		t.Errorf("Pair %s not found", pair)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].Similarity > got[i-1].Similarity {
+			t.Fatalf("Candidates not ordered by similarity: %v then %v", got[i-1].Similarity, got[i].Similarity)
+		}
+	}
+}
+
+func TestMinHashSignature(t *testing.T) {
+	a := newTableProfile(similarTable("s", "db", "a", "id bigint", "name varchar", "email text"))
+	b := newTableProfile(similarTable("s", "db", "b", "Email STRING", "ID int", "NAME char(10)"))
+	if a.signature != b.signature {
+		t.Error("Tables with the same features have different signatures")
+	}
+	c := newTableProfile(similarTable("s", "db", "c", "id bigint", "name varchar", "email bytea"))
+	same := 0
+	for i := range a.signature {
+		if a.signature[i] == c.signature[i] {
+			same++
+		}
+	}
+	// The expected share of equal hashes is the similarity, 0.5
+	if share := float64(same) / minHashSize; share < 0.35 || share > 0.65 {
+		t.Errorf("%d of %d hashes are equal", same, minHashSize)
+	}
+}
+
+func TestTypeFamily(t *testing.T) {
+	tests := map[string]string{
+		"BIGINT UNSIGNED":        "integer",
+		"int8":                   "integer",
+		"decimal(10,2)":          "decimal",
+		"double precision":       "float",
+		"varchar(255)":           "string",
+		"Nullable(String)":       "nullable",
+		"timestamp with tz":      "timestamp",
+		"array<string>":          "complex",
+		"jsonb":                  "json",
+		"geometry":               "geometry",
+		"LowCardinality(String)": "lowcardinality",
+	}
+	for typ, want := range tests {
+		if got := typeFamily(&ColumnMetadata{Type: typ}); got != want {
+			t.Errorf("typeFamily(%q) = %q, want %q", typ, got, want)
+		}
+	}
+	// The data type, without length, is preferred
+	if got := typeFamily(&ColumnMetadata{Type: "varchar(10)", DataType: "text"}); got != "string" {
+		t.Errorf("typeFamily() = %q", got)
+	}
+	for name, want := range map[string]string{"user_id": "userid", "UserId": "userid", `"USER-ID"`: "userid", "名称": "名称"} {
+		if got := normalizeColumnName(name); got != want {
+			t.Errorf("normalizeColumnName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
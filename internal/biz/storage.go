@@ -0,0 +1,258 @@
+package biz
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Storage roll-up groupings.
+const (
+	GroupBySchema = "schema"
+	GroupBySource = "source"
+)
+
+// Storage roll-up intervals. Periods start at midnight UTC, weeks on Monday.
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// ErrInvalidStorageOptions is returned for unknown storage roll-up groupings
+// and intervals.
+var ErrInvalidStorageOptions = errors.New("invalid storage report options")
+
+// SizeSnapshot is the size of a table reported by a sync. A snapshot is
+// recorded when a table is first synced and whenever its row count or data
+// size changes, so the size of a table at any time is the one of its latest
+// earlier snapshot.
+type SizeSnapshot struct {
+	TableID     string    `json:"table_id"`
+	Source      string    `json:"source"`
+	Database    string    `json:"database"`
+	Schema      string    `json:"schema"`
+	Table       string    `json:"table"`
+	RowCount    int64     `json:"row_count"`
+	DataSize    int64     `json:"data_size"`
+	CollectedAt time.Time `json:"collected_at"`
+}
+
+// StorageOptions configures a storage roll-up.
+type StorageOptions struct {
+	// GroupBy is GroupBySchema (the default) or GroupBySource.
+	GroupBy string `json:"group_by"`
+	// Interval is IntervalDay, IntervalWeek or IntervalMonth to report one
+	// roll-up per period between From and To; empty reports the sizes at To.
+	Interval string `json:"interval"`
+	// From defaults to the first snapshot, To to now.
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Validate checks the grouping and interval.
+func (o StorageOptions) Validate() error {
+	switch o.GroupBy {
+	case "", GroupBySchema, GroupBySource:
+	default:
+		return fmt.Errorf("%w: group_by must be %s or %s", ErrInvalidStorageOptions, GroupBySchema, GroupBySource)
+	}
+	switch o.Interval {
+	case "", IntervalDay, IntervalWeek, IntervalMonth:
+	default:
+		return fmt.Errorf("%w: interval must be %s, %s or %s", ErrInvalidStorageOptions, IntervalDay, IntervalWeek, IntervalMonth)
+	}
+	if !o.From.IsZero() && !o.To.IsZero() && o.From.After(o.To) {
+		return fmt.Errorf("%w: from is after to", ErrInvalidStorageOptions)
+	}
+	return nil
+}
+
+// StorageRollup is the total size of the tables of a schema or source at the
+// end of a period.
+type StorageRollup struct {
+	// Period is the start of the period; zero without an interval.
+	Period time.Time `json:"period"`
+	Source string    `json:"source"`
+	// Database and Schema are empty when grouped by source.
+	Database string `json:"database,omitempty"`
+	Schema   string `json:"schema,omitempty"`
+	Tables   int    `json:"tables"`
+	RowCount int64  `json:"row_count"`
+	DataSize int64  `json:"data_size"`
+	// DataSizeGrowth is the change of DataSize since the previous period, or
+	// since the sizes before From for the first one.
+	DataSizeGrowth int64 `json:"data_size_growth"`
+}
+
+// StorageReport rolls up the recorded table sizes per schema or source.
+func (uc *TableUsecase) StorageReport(ctx context.Context, opts StorageOptions) ([]*StorageRollup, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	until := opts.To
+	if until.IsZero() {
+		until = now
+	}
+	snapshots, err := uc.repo.ListSizeSnapshots(ctx, until)
+	if err != nil {
+		return nil, err
+	}
+	return RollupStorage(snapshots, opts, now), nil
+}
+
+// RollupStorage sums the sizes of the tables per schema or source at the end
+// of each period, carrying the latest size of tables not synced within a
+// period forward. snapshots must be ordered by CollectedAt.
+func RollupStorage(snapshots []*SizeSnapshot, opts StorageOptions, now time.Time) []*StorageRollup {
+	to := opts.To
+	if to.IsZero() {
+		to = now
+	}
+	var periods []time.Time
+	if opts.Interval != "" {
+		from := opts.From
+		if from.IsZero() {
+			if len(snapshots) == 0 {
+				return []*StorageRollup{}
+			}
+			from = snapshots[0].CollectedAt
+		}
+		for p := periodStart(from, opts.Interval); !p.After(to); p = nextPeriod(p, opts.Interval) {
+			periods = append(periods, p)
+		}
+	}
+
+	rollups := make([]*StorageRollup, 0)
+	latest := make(map[string]*SizeSnapshot)
+	previous := make(map[string]int64)
+	next := 0
+	rollup := func(period, end time.Time) {
+		for ; next < len(snapshots) && snapshots[next].CollectedAt.Before(end); next++ {
+			s := snapshots[next]
+			id := s.TableID
+			if id == "" {
+				id = strings.ToLower(s.Source + "|" + s.Database + "." + s.Schema + "." + s.Table)
+			}
+			latest[id] = s
+		}
+
+		groups := make(map[string]*StorageRollup)
+		for _, s := range latest {
+			r := &StorageRollup{Period: period, Source: s.Source}
+			if opts.GroupBy != GroupBySource {
+				r.Database, r.Schema = s.Database, s.Schema
+			}
+			key := r.Source + "|" + r.Database + "|" + r.Schema
+			if g, ok := groups[key]; ok {
+				r = g
+			} else {
+				groups[key] = r
+			}
+			r.Tables++
+			r.RowCount += s.RowCount
+			r.DataSize += s.DataSize
+		}
+
+		keys := make([]string, 0, len(groups))
+		for key := range groups {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			r := groups[key]
+			r.DataSizeGrowth = r.DataSize - previous[key]
+			previous[key] = r.DataSize
+			rollups = append(rollups, r)
+		}
+	}
+
+	if len(periods) == 0 {
+		rollup(time.Time{}, to.Add(time.Nanosecond))
+		return rollups
+	}
+	for i, p := range periods {
+		end := to.Add(time.Nanosecond)
+		if i+1 < len(periods) {
+			end = periods[i+1]
+		}
+		if i == 0 {
+			// Growth is reported relative to the sizes before the first period.
+			rollup(time.Time{}, p)
+			rollups = rollups[:0]
+		}
+		rollup(p, end)
+	}
+	return rollups
+}
+
+func periodStart(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case IntervalWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case IntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+func nextPeriod(p time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalWeek:
+		return p.AddDate(0, 0, 7)
+	case IntervalMonth:
+		return p.AddDate(0, 1, 0)
+	}
+	return p.AddDate(0, 0, 1)
+}
+
+// WriteStorageCSV writes roll-ups as CSV with a header row, for spreadsheets
+// used in capacity planning.
+func WriteStorageCSV(w io.Writer, rollups []*StorageRollup) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"period", "source", "database", "schema", "tables", "row_count", "data_size_bytes", "data_size_growth_bytes"})
+	for _, r := range rollups {
+		period := ""
+		if !r.Period.IsZero() {
+			period = r.Period.Format("2006-01-02")
+		}
+		cw.Write([]string{
+			period,
+			r.Source,
+			r.Database,
+			r.Schema,
+			strconv.Itoa(r.Tables),
+			strconv.FormatInt(r.RowCount, 10),
+			strconv.FormatInt(r.DataSize, 10),
+			strconv.FormatInt(r.DataSizeGrowth, 10),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func newSizeSnapshot(t *TableMetadata) *SizeSnapshot {
+	at := t.CollectedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return &SizeSnapshot{
+		TableID:     t.ID,
+		Source:      t.Source,
+		Database:    t.Database,
+		Schema:      t.Schema,
+		Table:       t.Name,
+		RowCount:    t.RowCount,
+		DataSize:    t.DataSize,
+		CollectedAt: at,
+	}
+}
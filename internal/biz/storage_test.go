@@ -0,0 +1,155 @@
+package biz
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func day(d int, hour int) time.Time {
+	return time.Date(2026, 3, d, hour, 0, 0, 0, time.UTC)
+}
+
+func snapshot(id, schema string, size int64, at time.Time) *SizeSnapshot {
+	return &SizeSnapshot{TableID: id, Source: "pg", Database: "dw", Schema: schema, Table: "t" + id, RowCount: size / 10, DataSize: size, CollectedAt: at}
+}
+
+func formatRollups(rollups []*StorageRollup) string {
+	var lines []string
+	for _, r := range rollups {
+		period := "-"
+		if !r.Period.IsZero() {
+			period = r.Period.Format("01-02")
+		}
+		lines = append(lines, fmt.Sprintf("%s %s/%s %d %d %+d", period, r.Source, r.Schema, r.Tables, r.DataSize, r.DataSizeGrowth))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestRollupStorage(t *testing.T) {
+	// Monday 2 March to Wednesday 11 March 2026
+	snapshots := []*SizeSnapshot{
+		snapshot("1", "sales", 100, day(2, 8)),
+		snapshot("2", "sales", 50, day(2, 9)),
+		snapshot("3", "finance", 10, day(3, 1)),
+		snapshot("1", "sales", 150, day(4, 23)),
+		snapshot("2", "sales", 20, day(9, 0)),
+		snapshot("3", "finance", 30, day(11, 6)),
+	}
+	now := day(12, 0)
+
+	tests := []struct {
+		name string
+		opts StorageOptions
+		want string
+	}{
+		{
+			name: "latest sizes",
+			opts: StorageOptions{},
+			want: "- pg/finance 1 30 +30\n- pg/sales 2 170 +170",
+		},
+		{
+			name: "sizes at a time",
+			opts: StorageOptions{To: day(4, 0)},
+			want: "- pg/finance 1 10 +10\n- pg/sales 2 150 +150",
+		},
+		{
+			name: "by source",
+			opts: StorageOptions{GroupBy: GroupBySource},
+			want: "- pg/ 3 200 +200",
+		},
+		{
+			// Tables not synced within a day keep their latest size
+			name: "daily",
+			opts: StorageOptions{Interval: IntervalDay, To: day(5, 12)},
+			want: "03-02 pg/sales 2 150 +150\n" +
+				"03-03 pg/finance 1 10 +10\n03-03 pg/sales 2 150 +0\n" +
+				"03-04 pg/finance 1 10 +0\n03-04 pg/sales 2 200 +50\n" +
+				"03-05 pg/finance 1 10 +0\n03-05 pg/sales 2 200 +0",
+		},
+		{
+			// Growth of the first period is relative to the sizes before it
+			name: "daily from",
+			opts: StorageOptions{Interval: IntervalDay, GroupBy: GroupBySource, From: day(4, 0), To: day(5, 12)},
+			want: "03-04 pg/ 3 210 +50\n03-05 pg/ 3 210 +0",
+		},
+		{
+			// Weeks start on Monday
+			name: "weekly",
+			opts: StorageOptions{Interval: IntervalWeek, GroupBy: GroupBySource, From: day(10, 0)},
+			want: "03-09 pg/ 3 200 -10",
+		},
+		{
+			name: "monthly",
+			opts: StorageOptions{Interval: IntervalMonth, GroupBy: GroupBySource},
+			want: "03-01 pg/ 3 200 +200",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatRollups(RollupStorage(snapshots, tt.opts, now)); got != tt.want {
+				t.Errorf("RollupStorage() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if got := RollupStorage(nil, StorageOptions{Interval: IntervalDay}, now); len(got) != 0 {
+		t.Errorf("Expected no roll-ups without snapshots, got %v", got)
+	}
+}
+
+func TestStorageOptionsValidate(t *testing.T) {
+	invalid := []StorageOptions{
+		{GroupBy: "table"},
+		{Interval: "hour"},
+		{From: day(5, 0), To: day(4, 0)},
+	}
+	for _, opts := range invalid {
+		if err := opts.Validate(); !errors.Is(err, ErrInvalidStorageOptions) {
+			t.Errorf("Validate(%+v) = %v, want ErrInvalidStorageOptions", opts, err)
+		}
+	}
+	if err := (StorageOptions{GroupBy: GroupBySource, Interval: IntervalWeek, From: day(4, 0), To: day(5, 0)}).Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpsertSyncedSizeSnapshots(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, _ := newTestUsecase()
+	sync := func(rows int64, at time.Time) {
+		synced := syncedOrders("orders", "")
+		synced.RowCount, synced.DataSize, synced.CollectedAt = rows, rows*100, at
+		if _, err := uc.UpsertSynced(ctx, synced); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sync(100, day(2, 0))
+	sync(100, day(3, 0))
+	sync(120, day(4, 0))
+
+	if len(repo.snapshots) != 2 || repo.snapshots[1].RowCount != 120 || !repo.snapshots[1].CollectedAt.Equal(day(4, 0)) {
+		t.Fatalf("Unexpected snapshots %+v", repo.snapshots)
+	}
+	rollups, err := uc.StorageReport(ctx, StorageOptions{Interval: IntervalDay, From: day(2, 0), To: day(4, 12)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "03-02 mysql_prod/ 1 10000 +10000\n03-03 mysql_prod/ 1 10000 +0\n03-04 mysql_prod/ 1 12000 +2000"
+	if got := formatRollups(rollups); got != want {
+		t.Errorf("StorageReport() =\n%s\nwant\n%s", got, want)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStorageCSV(&buf, rollups[2:]); err != nil {
+		t.Fatal(err)
+	}
+	wantCSV := "period,source,database,schema,tables,row_count,data_size_bytes,data_size_growth_bytes\n2026-03-04,mysql_prod,shop,,1,120,12000,2000\n"
+	if buf.String() != wantCSV {
+		t.Errorf("CSV = %q, want %q", buf.String(), wantCSV)
+	}
+}
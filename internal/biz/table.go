@@ -0,0 +1,453 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// maxUpsertAttempts bounds the retries of a write that lost an optimistic
+// locking race.
+const maxUpsertAttempts = 5
+
+// TableRepo is a table metadata repo interface.
+type TableRepo interface {
+	// Get returns the stored metadata of a table, or ErrTableNotFound. A
+	// table of several sources is read from one of them.
+	Get(ctx context.Context, database, schema, name string) (*TableMetadata, error)
+	// GetFromSource returns the stored metadata of a table of a source, or
+	// ErrTableNotFound.
+	GetFromSource(ctx context.Context, source, database, schema, name string) (*TableMetadata, error)
+	// Save creates a table (Version 0) or updates it if the stored version
+	// still equals t.Version, returning ErrVersionConflict otherwise. The
+	// saved table has its version incremented.
+	Save(ctx context.Context, t *TableMetadata) (*TableMetadata, error)
+	// List returns the stored metadata of every table.
+	List(ctx context.Context) ([]*TableMetadata, error)
+	// SaveConflicts records sync conflicts.
+	SaveConflicts(ctx context.Context, conflicts []*MetadataConflict) error
+	// ListConflicts lists the conflicts recorded for a table, newest first.
+	ListConflicts(ctx context.Context, tableID string) ([]*MetadataConflict, error)
+	// SaveSizeSnapshot records the size of a table reported by a sync.
+	SaveSizeSnapshot(ctx context.Context, s *SizeSnapshot) error
+	// ListSizeSnapshots lists the size snapshots collected up to until,
+	// oldest first.
+	ListSizeSnapshots(ctx context.Context, until time.Time) ([]*SizeSnapshot, error)
+	// SearchAnnotations lists the table and column annotations matching q,
+	// ordered by database, table, column and key.
+	SearchAnnotations(ctx context.Context, q AnnotationQuery) ([]*AnnotationMatch, error)
+}
+
+// PolicyRepo stores the metadata policies and the violations found by syncs.
+type PolicyRepo interface {
+	// GetPolicies returns the stored policies, or an empty set.
+	GetPolicies(ctx context.Context) (*PolicySet, error)
+	// SavePolicies replaces the stored policies.
+	SavePolicies(ctx context.Context, set *PolicySet) error
+	// ListViolations lists the open violations of a table, or of every table
+	// if tableID is empty, ordered by table, policy and column.
+	ListViolations(ctx context.Context, tableID string) ([]*PolicyViolation, error)
+	// SaveViolations replaces the open violations of a table and records
+	// the events reporting the change.
+	SaveViolations(ctx context.Context, tableID string, violations []*PolicyViolation, events []*PolicyEvent) error
+	// ListPolicyEvents lists the most recent policy events, newest first.
+	ListPolicyEvents(ctx context.Context, limit int) ([]*PolicyEvent, error)
+}
+
+// UpsertResult is the outcome of writing synced table metadata.
+type UpsertResult struct {
+	Table     *TableMetadata
+	Created   bool
+	Changed   bool
+	Conflicts []*MetadataConflict
+	// Violations are the policy violations of the table after the sync.
+	Violations []*PolicyViolation
+}
+
+// TableUsecase is a table metadata usecase.
+type TableUsecase struct {
+	repo     TableRepo
+	policies PolicyRepo
+	notifier Notifier
+	log      *log.Helper
+}
+
+// NewTableUsecase creates a new TableUsecase.
+func NewTableUsecase(repo TableRepo, policies PolicyRepo, logger log.Logger) *TableUsecase {
+	return &TableUsecase{repo: repo, policies: policies, log: log.NewHelper(logger)}
+}
+
+// Get returns the stored metadata of a table.
+func (uc *TableUsecase) Get(ctx context.Context, database, schema, name string) (*TableMetadata, error) {
+	return uc.repo.Get(ctx, database, schema, name)
+}
+
+// List returns the stored metadata of all tables.
+func (uc *TableUsecase) List(ctx context.Context) ([]*TableMetadata, error) {
+	return uc.repo.List(ctx)
+}
+
+// UpsertSynced writes table metadata collected by a sync. Source-derived
+// fields are overwritten while user edits are preserved (see MergeSynced);
+// repeating a sync with unchanged metadata writes nothing. If the table is
+// modified concurrently the write is retried on the fresh copy. The table is
+// then checked against the policies, recording new and resolved violations
+// as policy events. A size snapshot is recorded for new tables and tables
+// whose row count or data size changed. New violations are sent to the
+// notifier, if any; schema drift is notified by the sync itself, which
+// compares the collected metadata.
+func (uc *TableUsecase) UpsertSynced(ctx context.Context, synced *TableMetadata) (*UpsertResult, error) {
+	var result *UpsertResult
+	var resized bool
+	err := uc.retry(ctx, synced.Source, synced.Database, synced.Schema, synced.Name, func(existing *TableMetadata) (*TableMetadata, error) {
+		merged, conflicts, changed := MergeSynced(existing, synced, time.Now())
+		result = &UpsertResult{Table: merged, Created: existing == nil, Changed: changed, Conflicts: conflicts}
+		resized = existing == nil || existing.RowCount != synced.RowCount || existing.DataSize != synced.DataSize
+		if existing != nil && !changed && !merged.CollectedAt.After(existing.CollectedAt) {
+			result.Table = existing
+			return nil, nil
+		}
+		return merged, nil
+	}, func(saved *TableMetadata) {
+		result.Table = saved
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Conflicts) > 0 {
+		for _, c := range result.Conflicts {
+			c.TableID = result.Table.ID
+		}
+		if err := uc.repo.SaveConflicts(ctx, result.Conflicts); err != nil {
+			return nil, err
+		}
+		uc.log.Warnf("sync of %s.%s conflicts with %d user edits", synced.Database, synced.Name, len(result.Conflicts))
+	}
+
+	if resized {
+		if err := uc.repo.SaveSizeSnapshot(ctx, newSizeSnapshot(result.Table)); err != nil {
+			// Like policy checks, a missing snapshot must not fail the sync.
+			uc.log.Errorf("recording the size of %s.%s failed: %v", synced.Database, synced.Name, err)
+		}
+	}
+
+	violations, err := uc.checkPolicies(ctx, result.Table)
+	if err != nil {
+		// The sync itself succeeded; the violations are updated by the next one.
+		uc.log.Errorf("policy check of %s.%s failed: %v", synced.Database, synced.Name, err)
+	}
+	result.Violations = violations
+	return result, nil
+}
+
+// checkPolicies evaluates the policies on t and records the changes in its
+// violations.
+func (uc *TableUsecase) checkPolicies(ctx context.Context, t *TableMetadata) ([]*PolicyViolation, error) {
+	set, err := uc.policies.GetPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := uc.policies.ListViolations(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	violations := set.Evaluate(t, now)
+	events := DiffViolations(previous, violations, now)
+	if len(events) == 0 {
+		return violations, nil
+	}
+	if err := uc.policies.SaveViolations(ctx, t.ID, violations, events); err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		v := e.Violation
+		name := v.Table
+		if v.Column != "" {
+			name += "." + v.Column
+		}
+		if e.Type == PolicyResolved {
+			uc.log.Infof("policy %s resolved on %s: %s", v.Policy, name, v.Message)
+			continue
+		}
+		uc.log.Warnf("policy %s violated on %s: %s", v.Policy, name, v.Message)
+		event := EventPolicyViolation
+		if p := set.policy(v.Policy); p != nil && p.Require.Freshness != "" && v.Message == p.Require.freshnessMessage() {
+			event = EventSLABreach
+		}
+		uc.notify(ctx, &Notification{
+			Event:   event,
+			Source:  t.Source,
+			Table:   v.Table,
+			Summary: fmt.Sprintf("%s %s (policy %s)", name, v.Message, v.Policy),
+			Owners:  t.Owners,
+			At:      e.At,
+		})
+	}
+	return violations, nil
+}
+
+// EditField sets a user-authored value of an editable field (see
+// FieldComment and ColumnCommentField), retrying on concurrent modification.
+func (uc *TableUsecase) EditField(ctx context.Context, database, schema, name, field, value, user string) (*TableMetadata, error) {
+	var result *TableMetadata
+	err := uc.retry(ctx, "", database, schema, name, func(existing *TableMetadata) (*TableMetadata, error) {
+		if existing == nil {
+			return nil, ErrTableNotFound
+		}
+		if err := ApplyUserEdit(existing, field, value, user, time.Now()); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}, func(saved *TableMetadata) {
+		result = saved
+	})
+	return result, err
+}
+
+// SetDescription sets the markdown description of a table, or of one of its
+// columns if column is not empty. Descriptions are never touched by sync.
+func (uc *TableUsecase) SetDescription(ctx context.Context, database, schema, name, column, description, user string) (*TableMetadata, error) {
+	var result *TableMetadata
+	err := uc.retry(ctx, "", database, schema, name, func(existing *TableMetadata) (*TableMetadata, error) {
+		if existing == nil {
+			return nil, ErrTableNotFound
+		}
+		if err := ApplyDescription(existing, column, description, time.Now()); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}, func(saved *TableMetadata) {
+		result = saved
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.log.Infof("description of %s.%s %s updated by %s", database, name, column, user)
+	return result, nil
+}
+
+// SetReadme sets the long-form markdown documentation of a table and the path
+// where it is maintained. An empty markdown and path removes the README.
+func (uc *TableUsecase) SetReadme(ctx context.Context, database, schema, name, markdown, path, user string) (*TableMetadata, error) {
+	var result *TableMetadata
+	err := uc.retry(ctx, "", database, schema, name, func(existing *TableMetadata) (*TableMetadata, error) {
+		if existing == nil {
+			return nil, ErrTableNotFound
+		}
+		if err := ApplyReadme(existing, markdown, path, user, time.Now()); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}, func(saved *TableMetadata) {
+		result = saved
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.log.Infof("readme of %s.%s updated by %s", database, name, user)
+	return result, nil
+}
+
+// SetAnnotations sets and removes annotations of a table, or of one of its
+// columns if column is not empty. Annotations are never touched by sync.
+func (uc *TableUsecase) SetAnnotations(ctx context.Context, database, schema, name, column string, set map[string]string, remove []string, user string) (*TableMetadata, error) {
+	var result *TableMetadata
+	err := uc.retry(ctx, "", database, schema, name, func(existing *TableMetadata) (*TableMetadata, error) {
+		if existing == nil {
+			return nil, ErrTableNotFound
+		}
+		if err := ApplyAnnotations(existing, column, set, remove, time.Now()); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}, func(saved *TableMetadata) {
+		result = saved
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.log.Infof("annotations of %s.%s %s updated by %s: %d set, %d removed", database, name, column, user, len(set), len(remove))
+	return result, nil
+}
+
+// ApplyOptions configures TableUsecase.Apply.
+type ApplyOptions struct {
+	// DryRun computes the plan without writing.
+	DryRun bool
+	// Versions are the table versions of a previewed plan, keyed by table as
+	// in the changes. Applying fails with ErrVersionConflict if a table was
+	// modified since, so that what is written is what was previewed.
+	Versions map[string]int64
+	// User is recorded as the author of deprecations.
+	User string
+}
+
+// Apply applies declarative changes to many tables at once. The plan of
+// every change is computed first; nothing is written on a dry run or if any
+// table is not found or a change is invalid for it. Each table is then
+// written separately, so a failure midway leaves the earlier tables changed.
+func (uc *TableUsecase) Apply(ctx context.Context, changes []*Change, opts ApplyOptions) (*ApplyPlan, error) {
+	if err := ValidateChanges(changes); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	plan := &ApplyPlan{Entities: make([]*EntityPlan, 0, len(changes))}
+	for _, c := range changes {
+		entity, err := uc.plan(ctx, c, opts, now)
+		if err != nil {
+			return nil, err
+		}
+		plan.Entities = append(plan.Entities, entity)
+	}
+	if opts.DryRun || plan.Count(PlanNotFound) > 0 || plan.Count(PlanInvalid) > 0 {
+		return plan, nil
+	}
+
+	for i, c := range changes {
+		if plan.Entities[i].Action != PlanUpdate {
+			continue
+		}
+		database, schema, name, _ := ParseTableName(c.Table)
+		err := uc.retry(ctx, "", database, schema, name, func(existing *TableMetadata) (*TableMetadata, error) {
+			if existing == nil {
+				return nil, ErrTableNotFound
+			}
+			if err := checkVersion(c.Table, existing, opts); err != nil {
+				return nil, err
+			}
+			if _, err := c.applyTo(existing, opts.User, now); err != nil {
+				return nil, err
+			}
+			return existing, nil
+		}, func(saved *TableMetadata) {
+			plan.Entities[i].Version = saved.Version
+		})
+		if err != nil {
+			return nil, fmt.Errorf("apply %s: %w", c.Table, err)
+		}
+	}
+	plan.Applied = true
+	uc.log.Infof("%s applied changes to %d tables", opts.User, plan.Count(PlanUpdate))
+	return plan, nil
+}
+
+// plan computes the planned change of a table.
+func (uc *TableUsecase) plan(ctx context.Context, c *Change, opts ApplyOptions, at time.Time) (*EntityPlan, error) {
+	entity := &EntityPlan{Table: c.Table}
+	database, schema, name, _ := ParseTableName(c.Table)
+	t, err := uc.repo.Get(ctx, database, schema, name)
+	if errors.Is(err, ErrTableNotFound) {
+		entity.Action = PlanNotFound
+		return entity, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entity.Version = t.Version
+	if err := checkVersion(c.Table, t, opts); err != nil {
+		return nil, err
+	}
+
+	diffs, err := c.applyTo(cloneTable(t), opts.User, at)
+	switch {
+	case err != nil:
+		entity.Action, entity.Error = PlanInvalid, err.Error()
+	case len(diffs) == 0:
+		entity.Action = PlanUnchanged
+	default:
+		entity.Action, entity.Diffs = PlanUpdate, diffs
+	}
+	return entity, nil
+}
+
+func checkVersion(table string, t *TableMetadata, opts ApplyOptions) error {
+	if expected, ok := opts.Versions[table]; ok && expected != t.Version {
+		return fmt.Errorf("%w: %s changed since the plan (version %d, planned %d)", ErrVersionConflict, table, t.Version, expected)
+	}
+	return nil
+}
+
+// SearchAnnotations finds the tables and columns with annotations matching q.
+func (uc *TableUsecase) SearchAnnotations(ctx context.Context, q AnnotationQuery) ([]*AnnotationMatch, error) {
+	if q.Key == "" {
+		return nil, fmt.Errorf("%w: a key or namespace.* is required", ErrInvalidAnnotation)
+	}
+	return uc.repo.SearchAnnotations(ctx, q)
+}
+
+// GetPolicies returns the metadata policies.
+func (uc *TableUsecase) GetPolicies(ctx context.Context) (*PolicySet, error) {
+	return uc.policies.GetPolicies(ctx)
+}
+
+// SetPolicies validates and replaces the metadata policies. Tables are
+// checked against them on their next sync.
+func (uc *TableUsecase) SetPolicies(ctx context.Context, set *PolicySet, user string) error {
+	if err := set.Validate(); err != nil {
+		return err
+	}
+	if err := uc.policies.SavePolicies(ctx, set); err != nil {
+		return err
+	}
+	uc.log.Infof("%s set %d metadata policies", user, len(set.Policies))
+	return nil
+}
+
+// ListViolations lists the open policy violations of every table.
+func (uc *TableUsecase) ListViolations(ctx context.Context) ([]*PolicyViolation, error) {
+	return uc.policies.ListViolations(ctx, "")
+}
+
+// ListPolicyEvents lists the most recent policy events, newest first.
+func (uc *TableUsecase) ListPolicyEvents(ctx context.Context, limit int) ([]*PolicyEvent, error) {
+	return uc.policies.ListPolicyEvents(ctx, limit)
+}
+
+// ListConflicts lists the sync conflicts recorded for a table.
+func (uc *TableUsecase) ListConflicts(ctx context.Context, tableID string) ([]*MetadataConflict, error) {
+	return uc.repo.ListConflicts(ctx, tableID)
+}
+
+// retry reads a table, of source if not empty, lets update compute the new
+// state from it (nil when the table does not exist) and saves the result,
+// repeating on ErrVersionConflict. update returns nil to skip the write.
+func (uc *TableUsecase) retry(ctx context.Context, source, database, schema, name string,
+	update func(existing *TableMetadata) (*TableMetadata, error), saved func(*TableMetadata)) error {
+	for attempt := 1; ; attempt++ {
+		var existing *TableMetadata
+		var err error
+		if source != "" {
+			existing, err = uc.repo.GetFromSource(ctx, source, database, schema, name)
+		} else {
+			existing, err = uc.repo.Get(ctx, database, schema, name)
+		}
+		if errors.Is(err, ErrTableNotFound) {
+			existing, err = nil, nil
+		}
+		if err != nil {
+			return err
+		}
+
+		next, err := update(existing)
+		if err != nil || next == nil {
+			return err
+		}
+
+		result, err := uc.repo.Save(ctx, next)
+		if errors.Is(err, ErrVersionConflict) && attempt < maxUpsertAttempts {
+			uc.log.Debugf("retrying write of %s.%s after concurrent modification", database, name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		saved(result)
+		return nil
+	}
+}
@@ -0,0 +1,262 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// memTableRepo is an in-memory TableRepo with the optimistic locking of the
+// stores.
+type memTableRepo struct {
+	mu        sync.Mutex
+	tables    map[string]*TableMetadata
+	conflicts []*MetadataConflict
+	snapshots []*SizeSnapshot
+	// beforeSave runs before every Save, e.g. to write concurrently.
+	beforeSave func()
+}
+
+func newMemTableRepo() *memTableRepo {
+	return &memTableRepo{tables: make(map[string]*TableMetadata)}
+}
+
+func (r *memTableRepo) Get(ctx context.Context, database, schema, name string) (*TableMetadata, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tables[qualifiedName(&TableMetadata{Database: database, Schema: schema, Name: name})]
+	if !ok {
+		return nil, ErrTableNotFound
+	}
+	return cloneTable(t), nil
+}
+
+func (r *memTableRepo) GetFromSource(ctx context.Context, source, database, schema, name string) (*TableMetadata, error) {
+	t, err := r.Get(ctx, database, schema, name)
+	if err != nil || t.Source != source {
+		return nil, ErrTableNotFound
+	}
+	return t, nil
+}
+
+func (r *memTableRepo) Save(ctx context.Context, t *TableMetadata) (*TableMetadata, error) {
+	if r.beforeSave != nil {
+		r.beforeSave()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := qualifiedName(t)
+	stored, ok := r.tables[key]
+	switch {
+	case !ok && t.Version != 0, ok && stored.Version != t.Version:
+		return nil, ErrVersionConflict
+	case !ok:
+		t.ID = strconv.Itoa(len(r.tables) + 1)
+	}
+	t.Version++
+	r.tables[key] = cloneTable(t)
+	return t, nil
+}
+
+func (r *memTableRepo) List(ctx context.Context) ([]*TableMetadata, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tables := make([]*TableMetadata, 0, len(r.tables))
+	for _, t := range r.tables {
+		tables = append(tables, cloneTable(t))
+	}
+	sort.Slice(tables, func(i, j int) bool { return qualifiedName(tables[i]) < qualifiedName(tables[j]) })
+	return tables, nil
+}
+
+func (r *memTableRepo) SaveConflicts(ctx context.Context, conflicts []*MetadataConflict) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.conflicts = append(r.conflicts, conflicts...)
+	return nil
+}
+
+func (r *memTableRepo) ListConflicts(ctx context.Context, tableID string) ([]*MetadataConflict, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var conflicts []*MetadataConflict
+	for i := len(r.conflicts) - 1; i >= 0; i-- {
+		if r.conflicts[i].TableID == tableID {
+			conflicts = append(conflicts, r.conflicts[i])
+		}
+	}
+	return conflicts, nil
+}
+
+func (r *memTableRepo) SaveSizeSnapshot(ctx context.Context, s *SizeSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.snapshots = append(r.snapshots, s)
+	return nil
+}
+
+func (r *memTableRepo) ListSizeSnapshots(ctx context.Context, until time.Time) ([]*SizeSnapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var snapshots []*SizeSnapshot
+	for _, s := range r.snapshots {
+		if !s.CollectedAt.After(until) {
+			snapshots = append(snapshots, s)
+		}
+	}
+	return snapshots, nil
+}
+
+func (r *memTableRepo) SearchAnnotations(ctx context.Context, q AnnotationQuery) ([]*AnnotationMatch, error) {
+	return nil, nil
+}
+
+// memPolicyRepo is an in-memory PolicyRepo.
+type memPolicyRepo struct {
+	mu         sync.Mutex
+	set        *PolicySet
+	violations map[string][]*PolicyViolation
+	events     []*PolicyEvent
+}
+
+func newMemPolicyRepo() *memPolicyRepo {
+	return &memPolicyRepo{set: &PolicySet{}, violations: make(map[string][]*PolicyViolation)}
+}
+
+func (r *memPolicyRepo) GetPolicies(ctx context.Context) (*PolicySet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.set, nil
+}
+
+func (r *memPolicyRepo) SavePolicies(ctx context.Context, set *PolicySet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.set = set
+	return nil
+}
+
+func (r *memPolicyRepo) ListViolations(ctx context.Context, tableID string) ([]*PolicyViolation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tableID != "" {
+		return r.violations[tableID], nil
+	}
+	var all []*PolicyViolation
+	for _, v := range r.violations {
+		all = append(all, v...)
+	}
+	return all, nil
+}
+
+func (r *memPolicyRepo) SaveViolations(ctx context.Context, tableID string, violations []*PolicyViolation, events []*PolicyEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.violations[tableID] = violations
+	r.events = append(r.events, events...)
+	return nil
+}
+
+func (r *memPolicyRepo) ListPolicyEvents(ctx context.Context, limit int) ([]*PolicyEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []*PolicyEvent
+	for i := len(r.events) - 1; i >= 0 && len(events) < limit; i-- {
+		events = append(events, r.events[i])
+	}
+	return events, nil
+}
+
+func newTestUsecase() (*TableUsecase, *memTableRepo, *memPolicyRepo) {
+	tables, policies := newMemTableRepo(), newMemPolicyRepo()
+	return NewTableUsecase(tables, policies, log.NewStdLogger(io.Discard)), tables, policies
+}
+
+func TestUpsertSyncedRecordsConflicts(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, _ := newTestUsecase()
+
+	created, err := uc.UpsertSynced(ctx, syncedOrders("orders", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created.Created || created.Table.ID == "" || created.Table.Version != 1 {
+		t.Fatalf("Unexpected result %+v", created)
+	}
+	if _, err := uc.EditField(ctx, "shop", "", "orders", FieldComment, "Customer orders", "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := uc.UpsertSynced(ctx, syncedOrders("orders v2", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created || len(result.Conflicts) != 1 {
+		t.Fatalf("created = %v, conflicts = %v", result.Created, result.Conflicts)
+	}
+	conflicts, err := uc.ListConflicts(ctx, created.Table.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 || conflicts[0].TableID != created.Table.ID || conflicts[0].SourceValue != "orders v2" {
+		t.Fatalf("Unexpected recorded conflicts %v", conflicts)
+	}
+	stored, err := uc.Get(ctx, "shop", "", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Comment != "Customer orders" || stored.Version != 3 {
+		t.Errorf("comment = %q, version = %d", stored.Comment, stored.Version)
+	}
+
+	// The same sync again is not a new conflict
+	if _, err := uc.UpsertSynced(ctx, syncedOrders("orders v2", "")); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.conflicts) != 1 {
+		t.Errorf("Expected 1 recorded conflict, got %d", len(repo.conflicts))
+	}
+}
+
+func TestUpsertSyncedRetriesConcurrentEdit(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, _ := newTestUsecase()
+	if _, err := uc.UpsertSynced(ctx, syncedOrders("orders", "")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A user edits the table between the read and the write of the sync
+	edited := false
+	repo.beforeSave = func() {
+		if edited {
+			return
+		}
+		edited = true
+		if _, err := uc.SetDescription(ctx, "shop", "", "orders", "", "# Orders", "alice"); err != nil {
+			t.Error(err)
+		}
+	}
+	result, err := uc.UpsertSynced(ctx, syncedOrders("orders v2", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Table.Comment != "orders v2" || result.Table.Description != "# Orders" || result.Table.Version != 3 {
+		t.Errorf("Edit lost: comment %q, description %q, version %d", result.Table.Comment, result.Table.Description, result.Table.Version)
+	}
+
+	repo.beforeSave = func() {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		repo.tables["shop.orders"].Version++
+	}
+	if _, err := uc.UpsertSynced(ctx, syncedOrders("orders v3", "")); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict after %d attempts, got %v", maxUpsertAttempts, err)
+	}
+}
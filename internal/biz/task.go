@@ -18,15 +18,38 @@ type TaskRepo interface {
 	ListExecutions(ctx context.Context, taskID string, page, pageSize int) ([]*TaskExecution, int64, error)
 }
 
+// TaskScheduler schedules the executions of tasks.
+type TaskScheduler interface {
+	CreateTask(ctx context.Context, task *CollectionTask) error
+	UpdateTask(ctx context.Context, task *CollectionTask) error
+	DeleteTask(ctx context.Context, id string) error
+	StartTask(ctx context.Context, id string) error
+	StopTask(ctx context.Context, id string) error
+	PauseTask(ctx context.Context, id string) error
+	ResumeTask(ctx context.Context, id string) error
+	GetTaskStatus(ctx context.Context, id string) (*TaskStatus, error)
+}
+
 // TaskUsecase is a Task usecase.
 type TaskUsecase struct {
-	repo TaskRepo
-	log  *log.Helper
+	repo     TaskRepo
+	progress *ProgressBroker
+	log      *log.Helper
 }
 
 // NewTaskUsecase creates a new TaskUsecase.
 func NewTaskUsecase(repo TaskRepo, logger log.Logger) *TaskUsecase {
-	return &TaskUsecase{repo: repo, log: log.NewHelper(logger)}
+	return &TaskUsecase{repo: repo, progress: NewProgressBroker(), log: log.NewHelper(logger)}
+}
+
+// Progress returns the broker executions publish their sync progress to.
+func (uc *TaskUsecase) Progress() *ProgressBroker {
+	return uc.progress
+}
+
+// WatchProgress streams the sync progress of a running execution.
+func (uc *TaskUsecase) WatchProgress(ctx context.Context, executionID string) (<-chan *SyncProgress, error) {
+	return uc.progress.Watch(ctx, executionID)
 }
 
 // Create creates a Task.
@@ -56,22 +79,22 @@ func (uc *TaskUsecase) List(ctx context.Context, page, pageSize int) ([]*Collect
 
 // Start starts a Task.
 func (uc *TaskUsecase) Start(ctx context.Context, id string) error {
-	return uc.repo.UpdateStatus(ctx, id, "TASK_STATUS_ACTIVE")
+	return uc.repo.UpdateStatus(ctx, id, TaskStatusActive)
 }
 
 // Stop stops a Task.
 func (uc *TaskUsecase) Stop(ctx context.Context, id string) error {
-	return uc.repo.UpdateStatus(ctx, id, "TASK_STATUS_INACTIVE")
+	return uc.repo.UpdateStatus(ctx, id, TaskStatusInactive)
 }
 
 // Pause pauses a Task.
 func (uc *TaskUsecase) Pause(ctx context.Context, id string) error {
-	return uc.repo.UpdateStatus(ctx, id, "TASK_STATUS_PAUSED")
+	return uc.repo.UpdateStatus(ctx, id, TaskStatusPaused)
 }
 
 // Resume resumes a Task.
 func (uc *TaskUsecase) Resume(ctx context.Context, id string) error {
-	return uc.repo.UpdateStatus(ctx, id, "TASK_STATUS_ACTIVE")
+	return uc.repo.UpdateStatus(ctx, id, TaskStatusActive)
 }
 
 // ExecuteNow executes a Task immediately.
@@ -23,6 +23,24 @@ type SchedulerType = string
 // ExecutionStatus represents the status of execution.
 type ExecutionStatus = string
 
+// Task statuses, named as in the API.
+const (
+	TaskStatusActive    TaskStatus = "TASK_STATUS_ACTIVE"
+	TaskStatusInactive  TaskStatus = "TASK_STATUS_INACTIVE"
+	TaskStatusRunning   TaskStatus = "TASK_STATUS_RUNNING"
+	TaskStatusCompleted TaskStatus = "TASK_STATUS_COMPLETED"
+	TaskStatusFailed    TaskStatus = "TASK_STATUS_FAILED"
+	TaskStatusPaused    TaskStatus = "TASK_STATUS_PAUSED"
+)
+
+// Schedule types, named as in the API.
+const (
+	ScheduleTypeImmediate ScheduleType = "SCHEDULE_TYPE_IMMEDIATE"
+	ScheduleTypeOnce      ScheduleType = "SCHEDULE_TYPE_ONCE"
+	ScheduleTypeCron      ScheduleType = "SCHEDULE_TYPE_CRON"
+	ScheduleTypeInterval  ScheduleType = "SCHEDULE_TYPE_INTERVAL"
+)
+
 // ConnectionConfig represents connection configuration.
 type ConnectionConfig struct {
 	Host         string
@@ -144,6 +162,15 @@ type TaskExecution struct {
 	CreatedAt    time.Time
 }
 
+// ExecutionResult represents the outcome of a task execution.
+type ExecutionResult struct {
+	TablesProcessed  int32
+	RecordsProcessed int64
+	ErrorsCount      int32
+	WarningsCount    int32
+	ProcessingStats  map[string]int64
+}
+
 // TaskStatusInfo represents task status information.
 type TaskStatusInfo struct {
 	TaskID         string
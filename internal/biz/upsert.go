@@ -0,0 +1,357 @@
+package biz
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Editable fields. Apart from these, every field of a table is derived from
+// its data source and overwritten by each sync.
+const (
+	// FieldComment is the table comment.
+	FieldComment = "comment"
+
+	columnFieldPrefix = "columns."
+	columnCommentPath = ".comment"
+)
+
+// ColumnCommentField returns the field name of the comment of a column.
+func ColumnCommentField(column string) string {
+	return columnFieldPrefix + column + columnCommentPath
+}
+
+var (
+	// ErrTableNotFound is returned when a table has no stored metadata.
+	ErrTableNotFound = errors.New("table metadata not found")
+
+	// ErrVersionConflict is returned when a table was modified concurrently
+	// since it was read.
+	ErrVersionConflict = errors.New("table metadata was modified concurrently")
+
+	// ErrFieldNotEditable is returned for user edits of fields that are not
+	// editable or do not exist.
+	ErrFieldNotEditable = errors.New("field is not editable")
+)
+
+// FieldOverride is a user edit of a field that is also populated by sync. The
+// user value wins over the source value until the source reports the same
+// value or the edit is reverted.
+type FieldOverride struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+	// SourceValue is the last value reported by the data source; a sync
+	// reporting a different value conflicts with the edit.
+	SourceValue string    `json:"source_value"`
+	EditedBy    string    `json:"edited_by"`
+	EditedAt    time.Time `json:"edited_at"`
+}
+
+// ConflictResolution describes how a sync conflict was resolved.
+type ConflictResolution = string
+
+const (
+	// ConflictKeptUserValue means the user value was kept over a changed source value.
+	ConflictKeptUserValue ConflictResolution = "kept_user_value"
+	// ConflictFieldRemoved means the edited field no longer exists in the
+	// source (e.g. the column was dropped) and the edit was discarded.
+	ConflictFieldRemoved ConflictResolution = "field_removed"
+)
+
+// MetadataConflict records a sync that changed a field edited by a user.
+type MetadataConflict struct {
+	TableID             string             `json:"table_id"`
+	Field               string             `json:"field"`
+	UserValue           string             `json:"user_value"`
+	EditedBy            string             `json:"edited_by"`
+	PreviousSourceValue string             `json:"previous_source_value"`
+	SourceValue         string             `json:"source_value"`
+	Resolution          ConflictResolution `json:"resolution"`
+	DetectedAt          time.Time          `json:"detected_at"`
+}
+
+// MergeSynced merges table metadata reported by a sync into the stored
+// metadata, which is nil for a new table. Source-derived fields are taken from
+// synced; user overrides are kept and re-applied. A sync that reports a new
+// source value for an overridden field produces a conflict once: the override
+// remembers the new source value, so repeating the same sync is a no-op.
+// Overrides whose field the source now reports with the user value are dropped.
+// Descriptions, READMEs and annotations are user-owned and kept as they are
+// (see keepUserFields).
+//
+// It returns the merged metadata, the conflicts, and whether anything other
+// than the collection time changed.
+func MergeSynced(existing, synced *TableMetadata, at time.Time) (*TableMetadata, []*MetadataConflict, bool) {
+	merged := cloneTable(synced)
+	merged.Overrides = nil
+	if existing == nil {
+		keepUserFields(nil, merged, at)
+		merged.Version = 0
+		merged.CreatedAt = at
+		merged.UpdatedAt = at
+		return merged, nil, true
+	}
+
+	merged.ID = existing.ID
+	merged.Version = existing.Version
+	merged.CreatedAt = existing.CreatedAt
+	merged.UpdatedAt = existing.UpdatedAt
+
+	conflicts := keepUserFields(existing, merged, at)
+	for _, o := range existing.Overrides {
+		override := *o
+		source, ok := getField(merged, override.Field)
+		if !ok {
+			conflicts = append(conflicts, newConflict(existing.ID, &override, "", ConflictFieldRemoved, at))
+			continue
+		}
+		if source == override.Value {
+			continue
+		}
+		if source != override.SourceValue {
+			conflicts = append(conflicts, newConflict(existing.ID, &override, source, ConflictKeptUserValue, at))
+			override.SourceValue = source
+		}
+		setField(merged, override.Field, override.Value)
+		merged.Overrides = append(merged.Overrides, &override)
+	}
+
+	changed := !sameContent(existing, merged)
+	if changed {
+		merged.UpdatedAt = at
+	}
+	return merged, conflicts, changed
+}
+
+// ApplyUserEdit sets an editable field of t to a user-authored value, recording
+// an override that later syncs preserve. Setting a field back to its source
+// value removes the override.
+func ApplyUserEdit(t *TableMetadata, field, value, user string, at time.Time) error {
+	current, ok := getField(t, field)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrFieldNotEditable, field)
+	}
+
+	var override *FieldOverride
+	for _, o := range t.Overrides {
+		if o.Field == field {
+			override = o
+			break
+		}
+	}
+	if override == nil {
+		// Without an override the current value is the source value.
+		override = &FieldOverride{Field: field, SourceValue: current}
+		t.Overrides = append(t.Overrides, override)
+	}
+	override.Value = value
+	override.EditedBy = user
+	override.EditedAt = at
+
+	if value == override.SourceValue {
+		t.Overrides = removeOverride(t.Overrides, field)
+	}
+	setField(t, field, value)
+	t.UpdatedAt = at
+	return nil
+}
+
+// keepUserFields copies the descriptions and annotations of existing into
+// merged, which holds the columns reported by a sync; existing is nil for a
+// new table. These fields belong to users, so whatever the sync reported is
+// discarded. The user fields of a column that no longer exists are returned
+// as conflicts so that they can be restored, e.g. on a renamed column.
+func keepUserFields(existing, merged *TableMetadata, at time.Time) []*MetadataConflict {
+	copyTableUserFields(merged, &TableMetadata{})
+	for _, col := range merged.Columns {
+		copyColumnUserFields(col, &ColumnMetadata{})
+	}
+	if existing == nil {
+		return nil
+	}
+
+	copyTableUserFields(merged, existing)
+	var conflicts []*MetadataConflict
+	removed := func(field, value string) {
+		conflicts = append(conflicts, &MetadataConflict{
+			TableID:    existing.ID,
+			Field:      field,
+			UserValue:  value,
+			Resolution: ConflictFieldRemoved,
+			DetectedAt: at,
+		})
+	}
+	for _, old := range existing.Columns {
+		if col := merged.column(old.Name); col != nil {
+			copyColumnUserFields(col, old)
+			continue
+		}
+		if old.Description != "" {
+			removed(ColumnDescriptionField(old.Name), old.Description)
+		}
+		keys := make([]string, 0, len(old.Annotations))
+		for k := range old.Annotations {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			removed(ColumnAnnotationField(old.Name, k), old.Annotations[k])
+		}
+		if len(old.Tags) > 0 {
+			removed(columnField(old.Name, FieldTags), strings.Join(old.Tags, ","))
+		}
+	}
+	return conflicts
+}
+
+// copyTableUserFields copies the user-curated fields of src to dst.
+func copyTableUserFields(dst, src *TableMetadata) {
+	dst.Description = src.Description
+	dst.Annotations = cloneAnnotations(src.Annotations)
+	dst.Owners = cloneStrings(src.Owners)
+	dst.Tags = cloneStrings(src.Tags)
+	dst.Deprecation = cloneDeprecation(src.Deprecation)
+	dst.Readme = cloneReadme(src.Readme)
+}
+
+// copyColumnUserFields copies the user-curated fields of src to dst.
+func copyColumnUserFields(dst, src *ColumnMetadata) {
+	dst.Description = src.Description
+	dst.Annotations = cloneAnnotations(src.Annotations)
+	dst.Tags = cloneStrings(src.Tags)
+	dst.Deprecation = cloneDeprecation(src.Deprecation)
+}
+
+func newConflict(tableID string, o *FieldOverride, source string, resolution ConflictResolution, at time.Time) *MetadataConflict {
+	return &MetadataConflict{
+		TableID:             tableID,
+		Field:               o.Field,
+		UserValue:           o.Value,
+		EditedBy:            o.EditedBy,
+		PreviousSourceValue: o.SourceValue,
+		SourceValue:         source,
+		Resolution:          resolution,
+		DetectedAt:          at,
+	}
+}
+
+// getField returns the value of an editable field and whether it exists.
+func getField(t *TableMetadata, field string) (string, bool) {
+	if field == FieldComment {
+		return t.Comment, true
+	}
+	if col := findColumn(t, field); col != nil {
+		return col.Comment, true
+	}
+	return "", false
+}
+
+func setField(t *TableMetadata, field, value string) {
+	if field == FieldComment {
+		t.Comment = value
+		return
+	}
+	if col := findColumn(t, field); col != nil {
+		col.Comment = value
+	}
+}
+
+// findColumn returns the column addressed by a column comment field.
+func findColumn(t *TableMetadata, field string) *ColumnMetadata {
+	name, ok := strings.CutPrefix(field, columnFieldPrefix)
+	if !ok {
+		return nil
+	}
+	name, ok = strings.CutSuffix(name, columnCommentPath)
+	if !ok {
+		return nil
+	}
+	return t.column(name)
+}
+
+func removeOverride(overrides []*FieldOverride, field string) []*FieldOverride {
+	result := overrides[:0]
+	for _, o := range overrides {
+		if o.Field != field {
+			result = append(result, o)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// sameContent reports whether two versions of a table differ only in
+// bookkeeping fields.
+func sameContent(a, b *TableMetadata) bool {
+	x, y := cloneTable(a), cloneTable(b)
+	for _, t := range []*TableMetadata{x, y} {
+		t.UpdatedAt, t.CollectedAt, t.Version = time.Time{}, time.Time{}, 0
+		// Consumer lag moves constantly and is not a change of the table.
+		t.ConsumerLag = nil
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+func cloneTable(t *TableMetadata) *TableMetadata {
+	c := *t
+	c.Columns = make([]*ColumnMetadata, len(t.Columns))
+	for i, col := range t.Columns {
+		cc := *col
+		copyColumnUserFields(&cc, col)
+		c.Columns[i] = &cc
+	}
+	copyTableUserFields(&c, t)
+	c.Indexes = make([]*IndexMetadata, len(t.Indexes))
+	for i, idx := range t.Indexes {
+		ic := *idx
+		ic.Columns = append([]string(nil), idx.Columns...)
+		c.Indexes[i] = &ic
+	}
+	if t.Overrides != nil {
+		c.Overrides = make([]*FieldOverride, len(t.Overrides))
+		for i, o := range t.Overrides {
+			oc := *o
+			c.Overrides[i] = &oc
+		}
+	}
+	return &c
+}
+
+func cloneAnnotations(annotations map[string]string) map[string]string {
+	if annotations == nil {
+		return nil
+	}
+	c := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		c[k] = v
+	}
+	return c
+}
+
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string(nil), s...)
+}
+
+func cloneDeprecation(d *Deprecation) *Deprecation {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	return &c
+}
+
+func cloneReadme(r *Readme) *Readme {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	return &c
+}
@@ -0,0 +1,162 @@
+package biz
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func syncedOrders(comment, idComment string) *TableMetadata {
+	return &TableMetadata{
+		Database: "shop",
+		Name:     "orders",
+		Source:   "mysql_prod",
+		Comment:  comment,
+		Columns: []*ColumnMetadata{
+			{Name: "id", Type: "bigint", Comment: idComment, IsPrimaryKey: true, Position: 1},
+			{Name: "amount", Type: "decimal(10,2)", Position: 2},
+		},
+		RowCount: 100,
+	}
+}
+
+func TestMergeSyncedNewTable(t *testing.T) {
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	synced := syncedOrders("orders", "")
+	synced.Description = "set by the source"
+	synced.Columns[0].Annotations = map[string]string{"dq.check": "enabled"}
+
+	merged, conflicts, changed := MergeSynced(nil, synced, at)
+	if !changed || len(conflicts) != 0 {
+		t.Fatalf("changed = %v, conflicts = %v, want a change without conflicts", changed, conflicts)
+	}
+	if merged.Version != 0 || !merged.CreatedAt.Equal(at) || !merged.UpdatedAt.Equal(at) {
+		t.Errorf("Unexpected version or times: %d %v %v", merged.Version, merged.CreatedAt, merged.UpdatedAt)
+	}
+	// User-owned fields are never taken from a sync
+	if merged.Description != "" || merged.Columns[0].Annotations != nil {
+		t.Errorf("Sync set user fields: %q %v", merged.Description, merged.Columns[0].Annotations)
+	}
+	if synced.Description == "" {
+		t.Error("MergeSynced modified the synced table")
+	}
+}
+
+func TestMergeSyncedKeepsUserEdits(t *testing.T) {
+	created := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	edited := created.Add(time.Hour)
+	existing, _, _ := MergeSynced(nil, syncedOrders("orders", "pk"), created)
+	existing.ID, existing.Version = "7", 3
+	if err := ApplyUserEdit(existing, FieldComment, "Customer orders", "alice", edited); err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyUserEdit(existing, ColumnCommentField("id"), "Order id", "bob", edited); err != nil {
+		t.Fatal(err)
+	}
+	existing.Description = "# Orders"
+	existing.Owners = []string{"alice"}
+
+	// The source changes the table comment and keeps the column comment
+	at := edited.Add(time.Hour)
+	merged, conflicts, changed := MergeSynced(existing, syncedOrders("orders v2", "pk"), at)
+	if !changed {
+		t.Error("Expected a change")
+	}
+	if merged.ID != "7" || merged.Version != 3 || !merged.CreatedAt.Equal(created) || !merged.UpdatedAt.Equal(at) {
+		t.Errorf("Unexpected id, version or times: %s %d %v %v", merged.ID, merged.Version, merged.CreatedAt, merged.UpdatedAt)
+	}
+	if merged.Comment != "Customer orders" || merged.Columns[0].Comment != "Order id" {
+		t.Errorf("User values not kept: %q %q", merged.Comment, merged.Columns[0].Comment)
+	}
+	if merged.Description != "# Orders" || len(merged.Owners) != 1 {
+		t.Errorf("User fields not kept: %q %v", merged.Description, merged.Owners)
+	}
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %d", len(conflicts))
+	}
+	want := MetadataConflict{
+		TableID: "7", Field: FieldComment, UserValue: "Customer orders", EditedBy: "alice",
+		PreviousSourceValue: "orders", SourceValue: "orders v2", Resolution: ConflictKeptUserValue, DetectedAt: at,
+	}
+	if *conflicts[0] != want {
+		t.Errorf("conflict = %+v, want %+v", *conflicts[0], want)
+	}
+
+	// The override remembers the new source value: repeating the sync is a no-op
+	again, conflicts, changed := MergeSynced(merged, syncedOrders("orders v2", "pk"), at.Add(time.Hour))
+	if changed || len(conflicts) != 0 {
+		t.Errorf("Repeated sync: changed = %v, conflicts = %v", changed, conflicts)
+	}
+	if again.Comment != "Customer orders" || !again.UpdatedAt.Equal(at) {
+		t.Errorf("Repeated sync changed the table: %q %v", again.Comment, again.UpdatedAt)
+	}
+}
+
+func TestMergeSyncedDropsOverridesMatchingSource(t *testing.T) {
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	existing, _, _ := MergeSynced(nil, syncedOrders("orders", ""), at)
+	if err := ApplyUserEdit(existing, FieldComment, "Customer orders", "alice", at); err != nil {
+		t.Fatal(err)
+	}
+
+	merged, conflicts, _ := MergeSynced(existing, syncedOrders("Customer orders", ""), at.Add(time.Hour))
+	if len(conflicts) != 0 || len(merged.Overrides) != 0 {
+		t.Errorf("Expected the override to be dropped, got %v and conflicts %v", merged.Overrides, conflicts)
+	}
+	if merged.Comment != "Customer orders" {
+		t.Errorf("comment = %q", merged.Comment)
+	}
+}
+
+func TestMergeSyncedRemovedColumn(t *testing.T) {
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	existing, _, _ := MergeSynced(nil, syncedOrders("orders", ""), at)
+	existing.ID = "7"
+	if err := ApplyUserEdit(existing, ColumnCommentField("amount"), "Gross amount", "bob", at); err != nil {
+		t.Fatal(err)
+	}
+	existing.Columns[1].Description = "Including tax"
+	existing.Columns[1].Annotations = map[string]string{"finance.coa": "4010", "dq.check": "enabled"}
+
+	synced := syncedOrders("orders", "")
+	synced.Columns = synced.Columns[:1]
+	merged, conflicts, changed := MergeSynced(existing, synced, at.Add(time.Hour))
+	if !changed || len(merged.Overrides) != 0 {
+		t.Errorf("changed = %v, overrides = %v", changed, merged.Overrides)
+	}
+	want := []string{
+		ColumnDescriptionField("amount"),
+		ColumnAnnotationField("amount", "dq.check"),
+		ColumnAnnotationField("amount", "finance.coa"),
+		ColumnCommentField("amount"),
+	}
+	if len(conflicts) != len(want) {
+		t.Fatalf("Expected %d conflicts, got %d", len(want), len(conflicts))
+	}
+	for i, c := range conflicts {
+		if c.Field != want[i] || c.Resolution != ConflictFieldRemoved || c.TableID != "7" {
+			t.Errorf("conflict %d = %+v, want field %s removed", i, *c, want[i])
+		}
+	}
+	if conflicts[3].UserValue != "Gross amount" || conflicts[3].EditedBy != "bob" {
+		t.Errorf("Unexpected override conflict %+v", *conflicts[3])
+	}
+}
+
+func TestApplyUserEdit(t *testing.T) {
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	table, _, _ := MergeSynced(nil, syncedOrders("orders", ""), at)
+	if err := ApplyUserEdit(table, "columns.missing.comment", "x", "alice", at); !errors.Is(err, ErrFieldNotEditable) {
+		t.Errorf("Expected ErrFieldNotEditable, got %v", err)
+	}
+	if err := ApplyUserEdit(table, FieldComment, "Customer orders", "alice", at); err != nil {
+		t.Fatal(err)
+	}
+	// Reverting to the source value removes the override
+	if err := ApplyUserEdit(table, FieldComment, "orders", "alice", at); err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Overrides) != 0 || table.Comment != "orders" {
+		t.Errorf("overrides = %v, comment = %q", table.Overrides, table.Comment)
+	}
+}
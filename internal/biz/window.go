@@ -0,0 +1,148 @@
+package biz
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Extra keys of ConnectionConfig restricting when a DataSource may be
+// collected, e.g. "01:00-05:00" or "00:00-02:00,22:00-24:00". Windows ending
+// before they start span midnight. The timezone defaults to the server's.
+const (
+	ExtraCollectionWindows  = "collection_windows"
+	ExtraCollectionTimezone = "collection_timezone"
+)
+
+// ErrInvalidCollectionWindow is returned for a malformed collection window.
+var ErrInvalidCollectionWindow = errors.New("invalid collection window")
+
+// CollectionWindow is a daily time range, in minutes since midnight, in
+// which collection is allowed.
+type CollectionWindow struct {
+	Start int
+	End   int
+}
+
+// CollectionWindows are the allowed collection windows of a DataSource.
+// A nil *CollectionWindows allows collection at any time.
+type CollectionWindows struct {
+	Windows  []CollectionWindow
+	Location *time.Location
+}
+
+// ParseCollectionWindows parses a comma separated list of HH:MM-HH:MM ranges
+// in the given IANA timezone. An empty spec returns nil.
+func ParseCollectionWindows(spec, timezone string) (*CollectionWindows, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	loc := time.Local
+	if timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidCollectionWindow, timezone)
+		}
+	}
+	w := &CollectionWindows{Location: loc}
+	for _, part := range strings.Split(spec, ",") {
+		start, end, ok := strings.Cut(strings.TrimSpace(part), "-")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q is not HH:MM-HH:MM", ErrInvalidCollectionWindow, part)
+		}
+		s, err := parseClock(start)
+		if err != nil {
+			return nil, err
+		}
+		e, err := parseClock(end)
+		if err != nil {
+			return nil, err
+		}
+		if s == e || s == 24*60 {
+			return nil, fmt.Errorf("%w: %q is empty", ErrInvalidCollectionWindow, part)
+		}
+		w.Windows = append(w.Windows, CollectionWindow{Start: s, End: e})
+	}
+	return w, nil
+}
+
+// parseClock parses HH:MM, allowing 24:00 as the end of the day.
+func parseClock(s string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(strings.TrimSpace(s), "%d:%d", &h, &m); err != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m > 0) {
+		return 0, fmt.Errorf("%w: invalid time %q", ErrInvalidCollectionWindow, s)
+	}
+	return h*60 + m, nil
+}
+
+// CollectionWindows returns the collection windows configured in the Extra of
+// ds, or nil if collection is not restricted.
+func (ds *DataSource) CollectionWindows() (*CollectionWindows, error) {
+	if ds == nil || ds.Config == nil {
+		return nil, nil
+	}
+	return ParseCollectionWindows(ds.Config.Extra[ExtraCollectionWindows], ds.Config.Extra[ExtraCollectionTimezone])
+}
+
+// Allows reports whether t falls in one of the windows.
+func (w *CollectionWindows) Allows(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+	t = t.In(w.Location)
+	for _, win := range w.Windows {
+		// A window spanning midnight may have opened the day before.
+		for _, day := range []int{-1, 0} {
+			open, end := win.bounds(t, day)
+			if !t.Before(open) && t.Before(end) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Next returns t if collection is allowed at t, otherwise the time the next
+// window opens.
+func (w *CollectionWindows) Next(t time.Time) time.Time {
+	if w.Allows(t) {
+		return t
+	}
+	t = t.In(w.Location)
+	var next time.Time
+	for _, win := range w.Windows {
+		for _, day := range []int{0, 1} {
+			open, _ := win.bounds(t, day)
+			if open.After(t) && (next.IsZero() || open.Before(next)) {
+				next = open
+			}
+		}
+	}
+	return next
+}
+
+// String formats the windows as they are configured.
+func (w *CollectionWindows) String() string {
+	if w == nil {
+		return ""
+	}
+	parts := make([]string, len(w.Windows))
+	for i, win := range w.Windows {
+		parts[i] = fmt.Sprintf("%02d:%02d-%02d:%02d", win.Start/60, win.Start%60, win.End/60, win.End%60)
+	}
+	return strings.Join(parts, ",") + " " + w.Location.String()
+}
+
+// bounds returns the wall clock open and end time of the window opening on
+// the day of t plus the given number of days.
+func (win CollectionWindow) bounds(t time.Time, day int) (time.Time, time.Time) {
+	y, m, d := t.Date()
+	open := time.Date(y, m, d+day, win.Start/60, win.Start%60, 0, 0, t.Location())
+	endDay := d + day
+	if win.End <= win.Start {
+		endDay++
+	}
+	end := time.Date(y, m, endDay, win.End/60, win.End%60, 0, 0, t.Location())
+	return open, end
+}
@@ -0,0 +1,150 @@
+package biz
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func clock(d, hour, min int) time.Time {
+	return time.Date(2026, 3, d, hour, min, 0, 0, time.UTC)
+}
+
+func mustParseWindows(t *testing.T, spec, timezone string) *CollectionWindows {
+	t.Helper()
+	w, err := ParseCollectionWindows(spec, timezone)
+	if err != nil {
+		t.Fatalf("ParseCollectionWindows(%q, %q): %v", spec, timezone, err)
+	}
+	return w
+}
+
+func TestParseCollectionWindows(t *testing.T) {
+	w := mustParseWindows(t, " 00:00-02:00 , 22:00-24:00", "UTC")
+	want := []CollectionWindow{{Start: 0, End: 120}, {Start: 22 * 60, End: 24 * 60}}
+	if len(w.Windows) != len(want) || w.Windows[0] != want[0] || w.Windows[1] != want[1] {
+		t.Errorf("Expected windows %v, got %v", want, w.Windows)
+	}
+	if got := w.String(); got != "00:00-02:00,22:00-24:00 UTC" {
+		t.Errorf("Unexpected String() %q", got)
+	}
+
+	if w := mustParseWindows(t, "  ", "Nowhere/Invalid"); w != nil {
+		t.Errorf("Expected nil windows for an empty spec, got %v", w)
+	}
+
+	invalid := []struct {
+		spec     string
+		timezone string
+	}{
+		{"01:00", ""},
+		{"01:00-", ""},
+		{"ab:cd-05:00", ""},
+		{"25:00-26:00", ""},
+		{"01:60-02:00", ""},
+		{"24:30-01:00", ""},
+		{"-01:00-02:00", ""},
+		{"03:00-03:00", ""},
+		{"24:00-01:00", ""},
+		{"01:00-05:00,", ""},
+		{"01:00-05:00", "Nowhere/Invalid"},
+	}
+	for _, tt := range invalid {
+		if _, err := ParseCollectionWindows(tt.spec, tt.timezone); !errors.Is(err, ErrInvalidCollectionWindow) {
+			t.Errorf("ParseCollectionWindows(%q, %q): expected ErrInvalidCollectionWindow, got %v", tt.spec, tt.timezone, err)
+		}
+	}
+}
+
+func TestDataSourceCollectionWindows(t *testing.T) {
+	ds := &DataSource{Config: &ConnectionConfig{Extra: map[string]string{
+		ExtraCollectionWindows:  "01:00-05:00",
+		ExtraCollectionTimezone: "UTC",
+	}}}
+	w, err := ds.CollectionWindows()
+	if err != nil || w == nil || len(w.Windows) != 1 {
+		t.Fatalf("Expected one window, got %v, %v", w, err)
+	}
+
+	for _, ds := range []*DataSource{nil, {}, {Config: &ConnectionConfig{}}} {
+		if w, err := ds.CollectionWindows(); w != nil || err != nil {
+			t.Errorf("Expected no windows, got %v, %v", w, err)
+		}
+	}
+}
+
+func TestCollectionWindowsAllows(t *testing.T) {
+	tests := []struct {
+		spec string
+		at   time.Time
+		want bool
+	}{
+		{"01:00-05:00", clock(2, 1, 0), true},
+		{"01:00-05:00", clock(2, 4, 59), true},
+		{"01:00-05:00", clock(2, 5, 0), false},
+		{"01:00-05:00", clock(2, 0, 59), false},
+		// Windows ending before they start span midnight.
+		{"22:00-02:00", clock(2, 23, 0), true},
+		{"22:00-02:00", clock(3, 0, 0), true},
+		{"22:00-02:00", clock(3, 1, 59), true},
+		{"22:00-02:00", clock(3, 2, 0), false},
+		{"22:00-02:00", clock(2, 21, 59), false},
+		// 24:00 ends the window at midnight.
+		{"22:00-24:00", clock(2, 23, 59), true},
+		{"22:00-24:00", clock(3, 0, 0), false},
+		{"00:00-02:00,22:00-24:00", clock(3, 0, 0), true},
+		{"00:00-02:00,22:00-24:00", clock(3, 12, 0), false},
+	}
+	for _, tt := range tests {
+		w := mustParseWindows(t, tt.spec, "UTC")
+		if got := w.Allows(tt.at); got != tt.want {
+			t.Errorf("%s.Allows(%s) = %v, want %v", tt.spec, tt.at.Format(time.RFC3339), got, tt.want)
+		}
+	}
+
+	var unrestricted *CollectionWindows
+	if !unrestricted.Allows(clock(2, 12, 0)) {
+		t.Error("Expected nil windows to allow collection at any time")
+	}
+}
+
+func TestCollectionWindowsNext(t *testing.T) {
+	tests := []struct {
+		spec string
+		at   time.Time
+		want time.Time
+	}{
+		// Allowed now.
+		{"01:00-05:00", clock(2, 2, 30), clock(2, 2, 30)},
+		// Later the same day.
+		{"01:00-05:00", clock(2, 0, 30), clock(2, 1, 0)},
+		// After the window closed, the next day.
+		{"01:00-05:00", clock(2, 6, 0), clock(3, 1, 0)},
+		{"22:00-02:00", clock(3, 2, 0), clock(3, 22, 0)},
+		{"22:00-24:00", clock(3, 0, 0), clock(3, 22, 0)},
+		// The earliest of several windows, across the end of the month.
+		{"12:00-13:00,03:00-04:00", clock(31, 13, 30), time.Date(2026, 4, 1, 3, 0, 0, 0, time.UTC)},
+		{"12:00-13:00,03:00-04:00", clock(2, 5, 0), clock(2, 12, 0)},
+	}
+	for _, tt := range tests {
+		w := mustParseWindows(t, tt.spec, "UTC")
+		if got := w.Next(tt.at); !got.Equal(tt.want) {
+			t.Errorf("%s.Next(%s) = %s, want %s", tt.spec, tt.at.Format(time.RFC3339), got.Format(time.RFC3339), tt.want.Format(time.RFC3339))
+		}
+	}
+}
+
+func TestCollectionWindowsTimezone(t *testing.T) {
+	// 01:00-05:00 in Shanghai (UTC+8) is 17:00-21:00 UTC the day before.
+	w := mustParseWindows(t, "01:00-05:00", "Asia/Shanghai")
+
+	if !w.Allows(clock(2, 18, 0)) {
+		t.Error("Expected 18:00 UTC to be in the window")
+	}
+	if w.Allows(clock(2, 1, 0)) {
+		t.Error("Expected 01:00 UTC to be outside the window")
+	}
+	if got := w.Next(clock(2, 22, 0)); !got.Equal(clock(3, 17, 0)) {
+		t.Errorf("Expected next window at 17:00 UTC the next day, got %s", got.UTC().Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,234 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Baseline is the canonical snapshot of the schemas of a source, committed
+// to a repository so that schema changes are reviewed as code. It holds
+// only the definitions of the tables: statistics, properties and refresh
+// times are left out, and tables, columns, indexes and constraints are
+// sorted, so that the same schema always gives the same file.
+type Baseline struct {
+	Source string           `json:"source"`
+	Tables []*BaselineTable `json:"tables"`
+}
+
+// BaselineTable is the definition of a table in a baseline.
+type BaselineTable struct {
+	Catalog          string            `json:"catalog"`
+	Schema           string            `json:"schema"`
+	Name             string            `json:"name"`
+	Type             TableType         `json:"type"`
+	Comment          string            `json:"comment,omitempty"`
+	Columns          []Column          `json:"columns"`
+	Indexes          []Index           `json:"indexes,omitempty"`
+	PrimaryKey       []string          `json:"primary_key,omitempty"`
+	Partitions       []PartitionInfo   `json:"partitions,omitempty"`
+	CheckConstraints []CheckConstraint `json:"check_constraints,omitempty"`
+	Storage          *StorageInfo      `json:"storage,omitempty"`
+}
+
+// QualifiedName returns catalog.schema.name, or catalog.name for sources
+// without schemas.
+func (t *BaselineTable) QualifiedName() string {
+	if t.Schema == "" {
+		return t.Catalog + "." + t.Name
+	}
+	return t.Catalog + "." + t.Schema + "." + t.Name
+}
+
+// NewBaseline returns the baseline of the tables of a source.
+func NewBaseline(source string, tables []*TableMetadata) *Baseline {
+	b := &Baseline{Source: source, Tables: make([]*BaselineTable, 0, len(tables))}
+	for _, t := range tables {
+		bt := &BaselineTable{
+			Catalog:          t.Catalog,
+			Schema:           t.Schema,
+			Name:             t.Name,
+			Type:             t.Type,
+			Comment:          t.Comment,
+			Columns:          append([]Column(nil), t.Columns...),
+			Indexes:          append([]Index(nil), t.Indexes...),
+			PrimaryKey:       t.PrimaryKey,
+			CheckConstraints: append([]CheckConstraint(nil), t.CheckConstraints...),
+			Storage:          t.Storage,
+		}
+		for i := range bt.Columns {
+			bt.Columns[i].Examples = nil
+			bt.Columns[i].Descriptions = nil
+			bt.Columns[i].Raw = nil
+		}
+		for _, p := range t.Partitions {
+			// The partition count and latest partition change with every load
+			p.ValuesCount, p.Latest = 0, ""
+			bt.Partitions = append(bt.Partitions, p)
+		}
+		sort.SliceStable(bt.Columns, func(i, j int) bool {
+			return bt.Columns[i].OrdinalPosition < bt.Columns[j].OrdinalPosition
+		})
+		sort.Slice(bt.Indexes, func(i, j int) bool { return bt.Indexes[i].Name < bt.Indexes[j].Name })
+		sort.Slice(bt.CheckConstraints, func(i, j int) bool {
+			return bt.CheckConstraints[i].Name < bt.CheckConstraints[j].Name
+		})
+		b.Tables = append(b.Tables, bt)
+	}
+	sort.Slice(b.Tables, func(i, j int) bool { return b.Tables[i].QualifiedName() < b.Tables[j].QualifiedName() })
+	return b
+}
+
+// ParseBaseline parses a baseline file written by Baseline.Marshal.
+func ParseBaseline(data []byte) (*Baseline, error) {
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse baseline: %w", err)
+	}
+	return &b, nil
+}
+
+// Marshal returns the baseline file: indented JSON, one attribute per line,
+// so that its diffs are readable in code review.
+func (b *Baseline) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// BaselineDiff is the difference of the current schemas of a source from
+// its baseline: the tables added since the baseline are missing from it and
+// the tables dropped since are extra.
+type BaselineDiff struct {
+	SchemaDiff
+	// Redefined are the tables whose columns, indexes and primary key match
+	// the baseline but whose other attributes differ.
+	Redefined []*TableRedefinition `json:"redefined_tables,omitempty"`
+}
+
+// TableRedefinition lists the attributes of a table that differ from its
+// baseline apart from its columns, indexes and primary key, e.g. "comment"
+// or "column note comment".
+type TableRedefinition struct {
+	Table   string   `json:"table"`
+	Changes []string `json:"changes"`
+}
+
+// Empty reports whether the current schemas match the baseline.
+func (d *BaselineDiff) Empty() bool {
+	return d.SchemaDiff.Empty() && len(d.Redefined) == 0
+}
+
+// CompareBaseline compares the current baseline of a source with its
+// committed baseline. Tables are matched by their qualified names and their
+// columns, indexes and primary keys are compared as by CompareSchemas; any
+// other difference of a table, such as its comment, is a redefinition.
+func CompareBaseline(current, baseline *Baseline) *BaselineDiff {
+	diff := &BaselineDiff{SchemaDiff: *CompareSchemas(baselineMetadata(current), baselineMetadata(baseline))}
+	compared := make(map[string]bool, len(diff.Tables))
+	for _, td := range diff.Tables {
+		compared[strings.ToLower(td.Table)] = true
+	}
+	previous := make(map[string]*BaselineTable, len(baseline.Tables))
+	for _, t := range baseline.Tables {
+		previous[strings.ToLower(t.QualifiedName())] = t
+	}
+	for _, t := range current.Tables {
+		name := strings.ToLower(t.QualifiedName())
+		p, ok := previous[name]
+		if !ok || compared[name] {
+			continue
+		}
+		if changes := redefinitions(t, p); len(changes) > 0 {
+			diff.Redefined = append(diff.Redefined, &TableRedefinition{Table: t.QualifiedName(), Changes: changes})
+		}
+	}
+	return diff
+}
+
+// baselineMetadata returns the tables of a baseline named by their qualified
+// names.
+func baselineMetadata(b *Baseline) []*TableMetadata {
+	tables := make([]*TableMetadata, 0, len(b.Tables))
+	for _, t := range b.Tables {
+		tables = append(tables, &TableMetadata{
+			Name:       t.QualifiedName(),
+			Columns:    t.Columns,
+			Indexes:    t.Indexes,
+			PrimaryKey: t.PrimaryKey,
+		})
+	}
+	return tables
+}
+
+// redefinitions lists the attributes of a table that differ from its
+// baseline, given that its columns, indexes and primary key match.
+func redefinitions(current, baseline *BaselineTable) []string {
+	var changes []string
+	for _, attr := range []struct {
+		name            string
+		current, before any
+	}{
+		{"type", current.Type, baseline.Type},
+		{"comment", current.Comment, baseline.Comment},
+		{"partitions", current.Partitions, baseline.Partitions},
+		{"check constraints", current.CheckConstraints, baseline.CheckConstraints},
+		{"storage", current.Storage, baseline.Storage},
+	} {
+		if !sameJSON(attr.current, attr.before) {
+			changes = append(changes, attr.name)
+		}
+	}
+	columns := make(map[string]*Column, len(baseline.Columns))
+	for i := range baseline.Columns {
+		columns[strings.ToLower(baseline.Columns[i].Name)] = &baseline.Columns[i]
+	}
+	for i := range current.Columns {
+		c := &current.Columns[i]
+		b := columns[strings.ToLower(c.Name)]
+		if b == nil {
+			continue
+		}
+		var columnChanges []string
+		for _, attr := range []struct {
+			name            string
+			current, before any
+		}{
+			{"comment", c.Comment, b.Comment},
+			{"position", c.OrdinalPosition, b.OrdinalPosition},
+			{"charset", c.Charset, b.Charset},
+			{"collation", c.Collation, b.Collation},
+		} {
+			if !sameJSON(attr.current, attr.before) {
+				columnChanges = append(columnChanges, fmt.Sprintf("column %s %s", c.Name, attr.name))
+			}
+		}
+		if len(columnChanges) == 0 && !sameJSON(c, b) {
+			columnChanges = append(columnChanges, fmt.Sprintf("column %s definition", c.Name))
+		}
+		changes = append(changes, columnChanges...)
+	}
+	return changes
+}
+
+// sameJSON reports whether two values encode to the same JSON, as they
+// would in a baseline file. Empty lists are the same as none.
+func sameJSON(a, b any) bool {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	empty := func(data []byte) bool { return string(data) == "null" || string(data) == "[]" }
+	return bytes.Equal(ja, jb) || empty(ja) && empty(jb)
+}
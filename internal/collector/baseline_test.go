@@ -0,0 +1,92 @@
+package collector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewBaseline(t *testing.T) {
+	tables := []*TableMetadata{
+		{
+			Catalog: "def", Schema: "shop", Name: "users", Type: TableTypeTable,
+			Columns: []Column{{OrdinalPosition: 1, Name: "id", SourceType: "bigint"}},
+		},
+		{
+			Catalog: "def", Schema: "shop", Name: "orders", Type: TableTypeTable,
+			Columns: []Column{
+				{OrdinalPosition: 2, Name: "note", SourceType: "varchar(64)", Raw: map[string]any{"EXTRA": ""}},
+				{OrdinalPosition: 1, Name: "id", SourceType: "bigint"},
+			},
+			Indexes:         []Index{{Name: "idx_user", Columns: []string{"user_id"}}, {Name: "idx_created", Columns: []string{"created_at"}}},
+			Partitions:      []PartitionInfo{{Name: "dt", Type: "RANGE", Columns: []string{"dt"}, ValuesCount: 30, Latest: "dt=2024-01-15"}},
+			Stats:           &TableStatistics{RowCount: 10},
+			Properties:      map[string]string{"update_time": "2024-01-15 08:00:00"},
+			LastRefreshedAt: time.Now(),
+		},
+	}
+	b := NewBaseline("mysql_prod", tables)
+
+	if got := []string{b.Tables[0].QualifiedName(), b.Tables[1].QualifiedName()}; !reflect.DeepEqual(got, []string{"def.shop.orders", "def.shop.users"}) {
+		t.Fatalf("tables = %v", got)
+	}
+	orders := b.Tables[0]
+	if orders.Columns[0].Name != "id" || orders.Columns[1].Raw != nil || orders.Indexes[0].Name != "idx_created" {
+		t.Errorf("orders not canonical: %+v", orders)
+	}
+	if p := orders.Partitions[0]; p.ValuesCount != 0 || p.Latest != "" {
+		t.Errorf("partition not canonical: %+v", p)
+	}
+	if tables[0].Columns[0].Name != "id" || tables[1].Columns[0].Raw == nil {
+		t.Error("NewBaseline modified the tables")
+	}
+
+	data, err := b.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	for _, volatile := range []string{"update_time", "row_count", "last_refreshed_at", "dt=2024-01-15"} {
+		if strings.Contains(string(data), volatile) {
+			t.Errorf("baseline contains %s:\n%s", volatile, data)
+		}
+	}
+	parsed, err := ParseBaseline(data)
+	if err != nil {
+		t.Fatalf("ParseBaseline() error = %v", err)
+	}
+	if diff := CompareBaseline(b, parsed); !diff.Empty() {
+		t.Errorf("parsed baseline differs: %+v", diff)
+	}
+}
+
+func TestCompareBaseline(t *testing.T) {
+	table := func(name, comment string, columns ...Column) *TableMetadata {
+		return &TableMetadata{Catalog: "def", Schema: "shop", Name: name, Type: TableTypeTable, Comment: comment, Columns: columns}
+	}
+	baseline := NewBaseline("mysql_prod", []*TableMetadata{
+		table("orders", "", Column{OrdinalPosition: 1, Name: "id", SourceType: "bigint"}, Column{OrdinalPosition: 2, Name: "note", SourceType: "varchar(64)"}),
+		table("users", "", Column{OrdinalPosition: 1, Name: "id", SourceType: "bigint"}, Column{OrdinalPosition: 2, Name: "email", SourceType: "varchar(255)"}),
+		table("legacy", "", Column{OrdinalPosition: 1, Name: "id", SourceType: "bigint"}),
+	})
+	current := NewBaseline("mysql_prod", []*TableMetadata{
+		table("orders", "", Column{OrdinalPosition: 1, Name: "id", SourceType: "bigint"}, Column{OrdinalPosition: 2, Name: "note", SourceType: "varchar(255)"}),
+		table("users", "registered users", Column{OrdinalPosition: 1, Name: "id", SourceType: "bigint"}, Column{OrdinalPosition: 2, Name: "email", SourceType: "varchar(255)", Comment: "login"}),
+		table("coupons", "", Column{OrdinalPosition: 1, Name: "id", SourceType: "bigint"}),
+	})
+
+	diff := CompareBaseline(current, baseline)
+	if !reflect.DeepEqual(diff.MissingTables, []string{"def.shop.coupons"}) || !reflect.DeepEqual(diff.ExtraTables, []string{"def.shop.legacy"}) {
+		t.Errorf("added %v, dropped %v", diff.MissingTables, diff.ExtraTables)
+	}
+	if len(diff.Tables) != 1 || diff.Tables[0].Table != "def.shop.orders" || diff.Tables[0].Columns[0].Changes[0] != "type varchar(64) -> varchar(255)" {
+		t.Errorf("Tables = %+v", diff.Tables)
+	}
+	want := []*TableRedefinition{{Table: "def.shop.users", Changes: []string{"comment", "column email comment"}}}
+	if !reflect.DeepEqual(diff.Redefined, want) {
+		t.Errorf("Redefined = %+v, want %+v", diff.Redefined, want)
+	}
+	if diff.Empty() {
+		t.Error("Empty() = true")
+	}
+}
@@ -13,6 +13,7 @@ import (
 type BatchCollector struct {
 	collector Collector
 	source    string
+	progress  func(table string, err error)
 }
 
 // NewBatchCollector creates a new BatchCollector wrapping the given Collector.
@@ -23,6 +24,13 @@ func NewBatchCollector(c Collector, source string) *BatchCollector {
 	}
 }
 
+// OnTableDone registers a callback invoked after each table of
+// FetchAllTableMetadata with the qualified table name and the error if the
+// table failed, e.g. to publish the progress of a sync.
+func (b *BatchCollector) OnTableDone(fn func(table string, err error)) {
+	b.progress = fn
+}
+
 // FetchAllTableMetadata fetches metadata for multiple tables with partial failure handling.
 // It continues processing remaining tables even if some fail.
 func (b *BatchCollector) FetchAllTableMetadata(ctx context.Context, catalog, schema string, tables []string) *PartialResult[*TableMetadata] {
@@ -33,10 +41,12 @@ func (b *BatchCollector) FetchAllTableMetadata(ctx context.Context, catalog, sch
 		if err := CheckContext(ctx, b.source, "fetch_all_table_metadata"); err != nil {
 			// On context cancellation, add remaining tables as failures and return
 			result.AddFailure(fmt.Sprintf("%s.%s.%s", catalog, schema, table), err)
+			b.tableDone(catalog, schema, table, err)
 			continue
 		}
 
 		metadata, err := b.collector.FetchTableMetadata(ctx, catalog, schema, table)
+		b.tableDone(catalog, schema, table, err)
 		if err != nil {
 			// Check if it's a context error - if so, we should stop
 			if IsContextError(err) {
@@ -135,3 +145,9 @@ func (b *BatchCollector) ListAllSchemas(ctx context.Context, catalogs []string)
 
 	return result
 }
+
+func (b *BatchCollector) tableDone(catalog, schema, table string, err error) {
+	if b.progress != nil {
+		b.progress(fmt.Sprintf("%s.%s.%s", catalog, schema, table), err)
+	}
+}
@@ -296,3 +296,33 @@ func TestPartialFailureHandling(t *testing.T) {
 
 	properties.TestingRun(t)
 }
+
+// TestBatchCollectorOnTableDone tests that the progress callback sees every
+// table, with the error of failed tables.
+func TestBatchCollectorOnTableDone(t *testing.T) {
+	failError := NewQueryError("test", "fetch_table_metadata", errors.New("simulated failure"))
+	batch := NewBatchCollector(newMockCollector([]string{"bad"}, failError), "test")
+
+	var done []string
+	failed := 0
+	batch.OnTableDone(func(table string, err error) {
+		done = append(done, table)
+		if err != nil {
+			failed++
+		}
+	})
+	batch.FetchAllTableMetadata(context.Background(), "catalog", "schema", []string{"a", "bad", "b"})
+
+	want := []string{"catalog.schema.a", "catalog.schema.bad", "catalog.schema.b"}
+	if len(done) != len(want) {
+		t.Fatalf("got %v, want %v", done, want)
+	}
+	for i := range want {
+		if done[i] != want[i] {
+			t.Errorf("table %d = %s, want %s", i, done[i], want[i])
+		}
+	}
+	if failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+}
@@ -40,7 +40,7 @@ var allCategories = []CategoryInfo{
 		Category:    CategoryDataWarehouse,
 		DisplayName: "数据仓库/MPP",
 		Description: "分布式存储，分区表",
-		Types:       []string{"hive", "clickhouse", "doris", "starrocks"},
+		Types:       []string{"hive", "impala", "trino", "presto", "clickhouse", "doris", "starrocks"},
 	},
 	{
 		Category:    CategoryDocumentDB,
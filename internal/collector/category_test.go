@@ -120,6 +120,9 @@ func TestGetCategoryByType(t *testing.T) {
 		{"oracle", CategoryRDBMS},
 		{"sqlserver", CategoryRDBMS},
 		{"hive", CategoryDataWarehouse},
+		{"impala", CategoryDataWarehouse},
+		{"trino", CategoryDataWarehouse},
+		{"presto", CategoryDataWarehouse},
 		{"clickhouse", CategoryDataWarehouse},
 		{"doris", CategoryDataWarehouse},
 		{"mongodb", CategoryDocumentDB},
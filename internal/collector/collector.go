@@ -5,6 +5,10 @@ package collector
 import "context"
 
 // Collector 元数据采集器统一接口
+//
+// 实现必须并发安全：同一个采集器会被并行同步的多个 worker、健康检查与 API 同时调用，
+// 包括在其他操作进行中调用 Connect 与 Close。连接保存在 Conn 中，Close 后进行中的操作
+// 以连接已关闭的错误结束，重复的 Connect 只建立一个连接。
 type Collector interface {
 	// 基础信息
 	Category() DataSourceCategory
@@ -30,6 +34,17 @@ type Collector interface {
 	FetchPartitions(ctx context.Context, catalog, schema, table string) ([]PartitionInfo, error)
 }
 
+// ViewDependencyCollector 可选接口：从系统目录采集 schema 下视图对表/视图的依赖
+type ViewDependencyCollector interface {
+	FetchViewDependencies(ctx context.Context, catalog, schema string) ([]ViewDependency, error)
+}
+
+// ChangeDetector 可选接口：批量获取 schema 下各表的变更指纹，增量同步只重新采集指纹变化的表
+type ChangeDetector interface {
+	// TableFingerprints 返回 schema 下各表的变更指纹，键为表名
+	TableFingerprints(ctx context.Context, catalog, schema string) (map[string]TableFingerprint, error)
+}
+
 // ListOptions 列表查询选项
 type ListOptions struct {
 	PageToken string
@@ -0,0 +1,424 @@
+// Package collectortest provides an in-memory collector.Collector for testing
+// code that drives collectors, such as sync pipelines, without real data
+// sources.
+//
+// Catalogs, schemas, tables, statistics and view dependencies are programmed
+// up front; errors and latencies can be injected per operation:
+//
+//	c := collectortest.New(collector.CategoryRDBMS, "mysql")
+//	c.AddTable(&collector.TableMetadata{Catalog: "def", Schema: "shop", Name: "orders"})
+//	c.SetLatency(collectortest.OpListTables, 50*time.Millisecond)
+//	c.SetError(collectortest.OpFetchTableStatistics, collector.NewTimeoutError("mysql", "fetch_table_statistics", nil))
+//
+// Register c.Creator() with a factory to have pipelines that create their
+// collectors from configuration receive the fake.
+package collectortest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/config"
+	"go-metadata/internal/collector/factory"
+	"go-metadata/internal/collector/matcher"
+)
+
+// 操作名，与采集器错误中的 operation 一致
+const (
+	OpConnect               = "connect"
+	OpClose                 = "close"
+	OpHealthCheck           = "health_check"
+	OpDiscoverCatalogs      = "discover_catalogs"
+	OpListSchemas           = "list_schemas"
+	OpListTables            = "list_tables"
+	OpFetchTableMetadata    = "fetch_table_metadata"
+	OpFetchTableStatistics  = "fetch_table_statistics"
+	OpFetchPartitions       = "fetch_partitions"
+	OpFetchViewDependencies = "fetch_view_dependencies"
+)
+
+type tableKey struct {
+	catalog, schema, table string
+}
+
+// Collector 内存采集器，并发安全
+type Collector struct {
+	category collector.DataSourceCategory
+	typ      string
+
+	mu        sync.Mutex
+	connected bool
+	catalogs  []collector.CatalogInfo
+	schemas   map[string][]string
+	tables    map[tableKey]*collector.TableMetadata
+	stats     map[tableKey]*collector.TableStatistics
+	views     map[tableKey][]collector.ViewDependency
+	errs      map[string]error
+	latencies map[string]time.Duration
+	calls     map[string]int
+}
+
+var (
+	_ collector.Collector               = (*Collector)(nil)
+	_ collector.ViewDependencyCollector = (*Collector)(nil)
+)
+
+// New 创建空的内存采集器
+func New(category collector.DataSourceCategory, typ string) *Collector {
+	return &Collector{
+		category:  category,
+		typ:       typ,
+		schemas:   make(map[string][]string),
+		tables:    make(map[tableKey]*collector.TableMetadata),
+		stats:     make(map[tableKey]*collector.TableStatistics),
+		views:     make(map[tableKey][]collector.ViewDependency),
+		errs:      make(map[string]error),
+		latencies: make(map[string]time.Duration),
+		calls:     make(map[string]int),
+	}
+}
+
+// Creator 返回始终返回该采集器的 factory.CollectorCreator
+func (c *Collector) Creator() factory.CollectorCreator {
+	return func(*config.ConnectorConfig) (collector.Collector, error) {
+		return c, nil
+	}
+}
+
+// AddCatalog 添加 Catalog，已存在时替换其信息
+func (c *Collector) AddCatalog(info collector.CatalogInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addCatalog(info)
+}
+
+func (c *Collector) addCatalog(info collector.CatalogInfo) {
+	if i := slices.IndexFunc(c.catalogs, func(ci collector.CatalogInfo) bool { return ci.Catalog == info.Catalog }); i >= 0 {
+		c.catalogs[i] = info
+		return
+	}
+	if info.Type == "" {
+		info.Type = c.typ
+	}
+	c.catalogs = append(c.catalogs, info)
+}
+
+// AddSchema 添加 Schema，所属 Catalog 不存在时一并添加
+func (c *Collector) AddSchema(catalog, schema string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addSchema(catalog, schema)
+}
+
+func (c *Collector) addSchema(catalog, schema string) {
+	if !slices.ContainsFunc(c.catalogs, func(ci collector.CatalogInfo) bool { return ci.Catalog == catalog }) {
+		c.addCatalog(collector.CatalogInfo{Catalog: catalog})
+	}
+	if schema != "" && !slices.Contains(c.schemas[catalog], schema) {
+		c.schemas[catalog] = append(c.schemas[catalog], schema)
+	}
+}
+
+// AddTable 按 md 的 Catalog、Schema、Name 添加表，所属 Catalog 和 Schema
+// 不存在时一并添加。FetchPartitions 返回 md.Partitions。
+func (c *Collector) AddTable(md *collector.TableMetadata) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addSchema(md.Catalog, md.Schema)
+	stored := *md
+	if stored.SourceCategory == "" {
+		stored.SourceCategory = c.category
+	}
+	if stored.SourceType == "" {
+		stored.SourceType = c.typ
+	}
+	c.tables[tableKey{md.Catalog, md.Schema, md.Name}] = &stored
+}
+
+// RemoveTable 删除表及其统计信息
+func (c *Collector) RemoveTable(catalog, schema, table string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := tableKey{catalog, schema, table}
+	delete(c.tables, key)
+	delete(c.stats, key)
+}
+
+// SetStatistics 设置表的统计信息，nil 表示未采集到统计信息
+func (c *Collector) SetStatistics(catalog, schema, table string, stats *collector.TableStatistics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats[tableKey{catalog, schema, table}] = stats
+}
+
+// AddViewDependency 添加视图依赖
+func (c *Collector) AddViewDependency(catalog string, dep collector.ViewDependency) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addSchema(catalog, dep.Schema)
+	key := tableKey{catalog: catalog, schema: dep.Schema}
+	c.views[key] = append(c.views[key], dep)
+}
+
+// SetError 使操作 op 返回 err，err 为 nil 时恢复正常
+func (c *Collector) SetError(op string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		delete(c.errs, op)
+		return
+	}
+	c.errs[op] = err
+}
+
+// SetLatency 使操作 op 延迟 d 后返回，期间响应 context 取消
+func (c *Collector) SetLatency(op string, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.latencies[op] = d
+}
+
+// Calls 返回操作 op 的调用次数
+func (c *Collector) Calls(op string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[op]
+}
+
+// Connected 返回采集器是否处于连接状态
+func (c *Collector) Connected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.connected
+}
+
+// Category 返回数据源类别
+func (c *Collector) Category() collector.DataSourceCategory {
+	return c.category
+}
+
+// Type 返回数据源类型
+func (c *Collector) Type() string {
+	return c.typ
+}
+
+// Connect 建立连接
+func (c *Collector) Connect(ctx context.Context) error {
+	if err := c.begin(ctx, OpConnect); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.connected = true
+	return nil
+}
+
+// Close 关闭连接
+func (c *Collector) Close() error {
+	if err := c.begin(context.Background(), OpClose); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.connected = false
+	return nil
+}
+
+// HealthCheck 健康检查
+func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, error) {
+	start := time.Now()
+	if err := c.begin(ctx, OpHealthCheck); err != nil {
+		return nil, err
+	}
+	if !c.Connected() {
+		return &collector.HealthStatus{Connected: false, Message: "not connected"}, nil
+	}
+	return &collector.HealthStatus{Connected: true, Latency: time.Since(start), Version: "memory"}, nil
+}
+
+// DiscoverCatalogs 发现 Catalog
+func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogInfo, error) {
+	if err := c.begin(ctx, OpDiscoverCatalogs); err != nil {
+		return nil, err
+	}
+	if err := c.checkConnected(OpDiscoverCatalogs); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.catalogs), nil
+}
+
+// ListSchemas 列出 Schema
+func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string, error) {
+	if err := c.begin(ctx, OpListSchemas); err != nil {
+		return nil, err
+	}
+	if err := c.checkConnected(OpListSchemas); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !slices.ContainsFunc(c.catalogs, func(ci collector.CatalogInfo) bool { return ci.Catalog == catalog }) {
+		return nil, collector.NewNotFoundError(c.typ, OpListSchemas, catalog, nil)
+	}
+	return slices.Clone(c.schemas[catalog]), nil
+}
+
+// ListTables 列出表，按名称排序，支持过滤和分页
+func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts *collector.ListOptions) (*collector.TableListResult, error) {
+	if err := c.begin(ctx, OpListTables); err != nil {
+		return nil, err
+	}
+	if err := c.checkConnected(OpListTables); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	var tables []string
+	for key := range c.tables {
+		if key.catalog == catalog && key.schema == schema {
+			tables = append(tables, key.table)
+		}
+	}
+	c.mu.Unlock()
+	slices.Sort(tables)
+
+	if opts != nil && opts.Filter != nil {
+		m, err := matcher.NewRuleMatcher(&config.MatchingRule{
+			Include: opts.Filter.Include,
+			Exclude: opts.Filter.Exclude,
+		}, "glob", false)
+		if err != nil {
+			return nil, collector.NewInvalidConfigError(c.typ, "filter", err.Error())
+		}
+		tables = slices.DeleteFunc(tables, func(t string) bool { return !m.Match(t) })
+	}
+
+	result := &collector.TableListResult{TotalCount: len(tables)}
+	if opts == nil || opts.PageSize <= 0 {
+		result.Tables = tables
+		return result, nil
+	}
+	start := 0
+	if opts.PageToken != "" {
+		var err error
+		if start, err = strconv.Atoi(opts.PageToken); err != nil || start < 0 {
+			return nil, collector.NewInvalidConfigError(c.typ, "page_token", fmt.Sprintf("invalid page token %q", opts.PageToken))
+		}
+	}
+	end := min(start+opts.PageSize, len(tables))
+	if start < end {
+		result.Tables = tables[start:end]
+	}
+	if end < len(tables) {
+		result.NextPageToken = strconv.Itoa(end)
+	}
+	return result, nil
+}
+
+// FetchTableMetadata 获取表元数据
+func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, table string) (*collector.TableMetadata, error) {
+	if err := c.begin(ctx, OpFetchTableMetadata); err != nil {
+		return nil, err
+	}
+	md, err := c.table(OpFetchTableMetadata, catalog, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	clone := *md
+	clone.LastRefreshedAt = time.Now()
+	return &clone, nil
+}
+
+// FetchTableStatistics 获取表统计信息，未设置时返回 nil
+func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, table string) (*collector.TableStatistics, error) {
+	if err := c.begin(ctx, OpFetchTableStatistics); err != nil {
+		return nil, err
+	}
+	if _, err := c.table(OpFetchTableStatistics, catalog, schema, table); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats[tableKey{catalog, schema, table}]
+	if stats == nil {
+		return nil, nil
+	}
+	clone := *stats
+	return &clone, nil
+}
+
+// FetchPartitions 获取分区信息
+func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table string) ([]collector.PartitionInfo, error) {
+	if err := c.begin(ctx, OpFetchPartitions); err != nil {
+		return nil, err
+	}
+	md, err := c.table(OpFetchPartitions, catalog, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(md.Partitions), nil
+}
+
+// FetchViewDependencies 获取 schema 下的视图依赖
+func (c *Collector) FetchViewDependencies(ctx context.Context, catalog, schema string) ([]collector.ViewDependency, error) {
+	if err := c.begin(ctx, OpFetchViewDependencies); err != nil {
+		return nil, err
+	}
+	if err := c.checkConnected(OpFetchViewDependencies); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.views[tableKey{catalog: catalog, schema: schema}]), nil
+}
+
+// begin counts a call of op, waits out its latency and returns the injected
+// error, if any.
+func (c *Collector) begin(ctx context.Context, op string) error {
+	c.mu.Lock()
+	c.calls[op]++
+	latency, err := c.latencies[op], c.errs[op]
+	c.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return collector.WrapContextError(ctx, c.typ, op)
+		case <-timer.C:
+		}
+	}
+	if err := collector.CheckContext(ctx, c.typ, op); err != nil {
+		return err
+	}
+	return err
+}
+
+func (c *Collector) checkConnected(op string) error {
+	if !c.Connected() {
+		return collector.NewConnectionClosedError(c.typ, op)
+	}
+	return nil
+}
+
+// table returns the stored table, which must not be modified.
+func (c *Collector) table(op, catalog, schema, table string) (*collector.TableMetadata, error) {
+	if err := c.checkConnected(op); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	md, ok := c.tables[tableKey{catalog, schema, table}]
+	if !ok {
+		return nil, collector.NewNotFoundError(c.typ, op, fmt.Sprintf("%s.%s.%s", catalog, schema, table), nil)
+	}
+	return md, nil
+}
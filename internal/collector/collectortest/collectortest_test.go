@@ -0,0 +1,138 @@
+package collectortest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/config"
+	"go-metadata/internal/collector/factory"
+)
+
+func newShop() *Collector {
+	c := New(collector.CategoryRDBMS, "mysql")
+	for _, name := range []string{"orders", "customers", "order_items"} {
+		c.AddTable(&collector.TableMetadata{
+			Catalog: "def",
+			Schema:  "shop",
+			Name:    name,
+			Columns: []collector.Column{{Name: "id", Type: "bigint"}},
+		})
+	}
+	c.AddSchema("def", "empty")
+	return c
+}
+
+func TestCollector_Discovery(t *testing.T) {
+	ctx := context.Background()
+	c := newShop()
+
+	if _, err := c.ListSchemas(ctx, "def"); collector.GetErrorCode(err) != collector.ErrCodeConnectionClosed {
+		t.Fatalf("Expected CONNECTION_CLOSED before Connect, got %v", err)
+	}
+	if err := c.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	catalogs, err := c.DiscoverCatalogs(ctx)
+	if err != nil || len(catalogs) != 1 || catalogs[0].Catalog != "def" || catalogs[0].Type != "mysql" {
+		t.Fatalf("Unexpected catalogs %+v, %v", catalogs, err)
+	}
+	schemas, err := c.ListSchemas(ctx, "def")
+	if err != nil || !slices.Equal(schemas, []string{"shop", "empty"}) {
+		t.Fatalf("Unexpected schemas %v, %v", schemas, err)
+	}
+
+	md, err := c.FetchTableMetadata(ctx, "def", "shop", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.SourceType != "mysql" || md.SourceCategory != collector.CategoryRDBMS || len(md.Columns) != 1 {
+		t.Errorf("Unexpected metadata %+v", md)
+	}
+	if _, err := c.FetchTableMetadata(ctx, "def", "shop", "missing"); collector.GetErrorCode(err) != collector.ErrCodeNotFound {
+		t.Errorf("Expected NOT_FOUND, got %v", err)
+	}
+
+	if err := c.Close(); err != nil || c.Connected() {
+		t.Errorf("Expected a closed collector, got %v", err)
+	}
+}
+
+func TestCollector_ListTables(t *testing.T) {
+	ctx := context.Background()
+	c := newShop()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var tables []string
+	opts := &collector.ListOptions{PageSize: 2}
+	for {
+		page, err := c.ListTables(ctx, "def", "shop", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.TotalCount != 3 {
+			t.Errorf("Expected total count 3, got %d", page.TotalCount)
+		}
+		tables = append(tables, page.Tables...)
+		if page.NextPageToken == "" {
+			break
+		}
+		opts.PageToken = page.NextPageToken
+	}
+	if !slices.Equal(tables, []string{"customers", "order_items", "orders"}) {
+		t.Errorf("Unexpected tables %v", tables)
+	}
+
+	filtered, err := c.ListTables(ctx, "def", "shop", &collector.ListOptions{
+		Filter: &collector.MatchingRule{Include: []string{"order*"}, Exclude: []string{"*_items"}},
+	})
+	if err != nil || !slices.Equal(filtered.Tables, []string{"orders"}) {
+		t.Errorf("Unexpected filtered tables %v, %v", filtered, err)
+	}
+}
+
+func TestCollector_InjectedFailures(t *testing.T) {
+	ctx := context.Background()
+	c := newShop()
+	injected := collector.NewNetworkError("mysql", OpConnect, errors.New("connection refused"))
+
+	c.SetError(OpConnect, injected)
+	if err := c.Connect(ctx); !errors.Is(err, injected) {
+		t.Fatalf("Expected the injected error, got %v", err)
+	}
+	c.SetError(OpConnect, nil)
+	if err := c.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Calls(OpConnect); got != 2 {
+		t.Errorf("Expected 2 connect calls, got %d", got)
+	}
+
+	c.SetLatency(OpListTables, time.Second)
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.ListTables(timeout, "def", "shop", nil); collector.GetErrorCode(err) != collector.ErrCodeDeadlineExceeded {
+		t.Errorf("Expected DEADLINE_EXCEEDED, got %v", err)
+	}
+}
+
+func TestCollector_Creator(t *testing.T) {
+	c := newShop()
+	f := factory.NewFactory()
+	if err := f.Register(collector.CategoryRDBMS, "mysql", c.Creator()); err != nil {
+		t.Fatal(err)
+	}
+	created, err := f.Create(&config.ConnectorConfig{Type: "mysql", Category: collector.CategoryRDBMS, Endpoint: "localhost:3306"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != collector.Collector(c) {
+		t.Error("Expected the factory to return the fake collector")
+	}
+}
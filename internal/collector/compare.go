@@ -0,0 +1,246 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kinds of schema differences, from the point of view of migrating the
+// target to match the source.
+const (
+	// DiffMissing is an object of the source missing from the target, to be
+	// created.
+	DiffMissing = "missing"
+	// DiffExtra is an object of the target not in the source, to be dropped
+	// or kept deliberately.
+	DiffExtra = "extra"
+	// DiffChanged is an object of both whose definitions differ, to be
+	// altered.
+	DiffChanged = "changed"
+)
+
+// SchemaDiff is the difference between the tables of a source schema, e.g.
+// staging, and a target schema, e.g. prod.
+type SchemaDiff struct {
+	// MissingTables are the tables of the source missing from the target.
+	MissingTables []string `json:"missing_tables,omitempty"`
+	// ExtraTables are the tables of the target not in the source.
+	ExtraTables []string `json:"extra_tables,omitempty"`
+	// Tables are the tables of both whose columns, indexes or primary keys
+	// differ.
+	Tables []*TableDiff `json:"tables,omitempty"`
+}
+
+// Empty reports whether the schemas are the same.
+func (d *SchemaDiff) Empty() bool {
+	return len(d.MissingTables) == 0 && len(d.ExtraTables) == 0 && len(d.Tables) == 0
+}
+
+// TableDiff is the difference between a table of the source and the target.
+type TableDiff struct {
+	Table   string       `json:"table"`
+	Columns []ColumnDiff `json:"columns,omitempty"`
+	Indexes []IndexDiff  `json:"indexes,omitempty"`
+	// PrimaryKey is set when the primary keys differ.
+	PrimaryKey *PrimaryKeyDiff `json:"primary_key,omitempty"`
+}
+
+// PrimaryKeyDiff is the columns of the primary keys of the source and
+// target, if they differ.
+type PrimaryKeyDiff struct {
+	Source []string `json:"source"`
+	Target []string `json:"target"`
+}
+
+// ColumnDiff is a column missing from, extra in or changed in the target.
+type ColumnDiff struct {
+	Column string  `json:"column"`
+	Kind   string  `json:"kind"`
+	Source *Column `json:"source,omitempty"`
+	Target *Column `json:"target,omitempty"`
+	// Changes describe how the attributes of a changed column must change
+	// in the target to match the source, e.g. "type varchar(64) ->
+	// varchar(255)".
+	Changes []string `json:"changes,omitempty"`
+}
+
+// IndexDiff is an index missing from, extra in or changed in the target.
+type IndexDiff struct {
+	Index  string `json:"index"`
+	Kind   string `json:"kind"`
+	Source *Index `json:"source,omitempty"`
+	Target *Index `json:"target,omitempty"`
+}
+
+// CompareSchemas compares the tables of a source schema with those of a
+// target schema, e.g. staging and prod. Tables, columns and indexes are
+// matched by name regardless of case; columns are compared by type,
+// nullability, default, auto increment and generation expression, and
+// indexes by columns and uniqueness. Comments and statistics are ignored.
+func CompareSchemas(source, target []*TableMetadata) *SchemaDiff {
+	diff := &SchemaDiff{}
+	targets := make(map[string]*TableMetadata, len(target))
+	for _, t := range target {
+		targets[strings.ToLower(t.Name)] = t
+	}
+	sources := make(map[string]bool, len(source))
+	for _, s := range source {
+		key := strings.ToLower(s.Name)
+		sources[key] = true
+		t, ok := targets[key]
+		if !ok {
+			diff.MissingTables = append(diff.MissingTables, s.Name)
+			continue
+		}
+		if td := CompareTables(s, t); td != nil {
+			diff.Tables = append(diff.Tables, td)
+		}
+	}
+	for _, t := range target {
+		if !sources[strings.ToLower(t.Name)] {
+			diff.ExtraTables = append(diff.ExtraTables, t.Name)
+		}
+	}
+
+	sort.Strings(diff.MissingTables)
+	sort.Strings(diff.ExtraTables)
+	sort.Slice(diff.Tables, func(i, j int) bool { return diff.Tables[i].Table < diff.Tables[j].Table })
+	return diff
+}
+
+// CompareTables compares a table of the source with the same table of the
+// target, as CompareSchemas does. It returns nil if they do not differ.
+func CompareTables(source, target *TableMetadata) *TableDiff {
+	td := &TableDiff{Table: source.Name}
+
+	targetCols := make(map[string]*Column, len(target.Columns))
+	for i := range target.Columns {
+		targetCols[strings.ToLower(target.Columns[i].Name)] = &target.Columns[i]
+	}
+	sourceCols := make(map[string]bool, len(source.Columns))
+	for i := range source.Columns {
+		s := &source.Columns[i]
+		key := strings.ToLower(s.Name)
+		sourceCols[key] = true
+		t, ok := targetCols[key]
+		if !ok {
+			td.Columns = append(td.Columns, ColumnDiff{Column: s.Name, Kind: DiffMissing, Source: s})
+			continue
+		}
+		if changes := columnChanges(s, t); len(changes) > 0 {
+			td.Columns = append(td.Columns, ColumnDiff{Column: s.Name, Kind: DiffChanged, Source: s, Target: t, Changes: changes})
+		}
+	}
+	for i := range target.Columns {
+		t := &target.Columns[i]
+		if !sourceCols[strings.ToLower(t.Name)] {
+			td.Columns = append(td.Columns, ColumnDiff{Column: t.Name, Kind: DiffExtra, Target: t})
+		}
+	}
+
+	targetIdx := make(map[string]*Index, len(target.Indexes))
+	for i := range target.Indexes {
+		targetIdx[strings.ToLower(target.Indexes[i].Name)] = &target.Indexes[i]
+	}
+	sourceIdx := make(map[string]bool, len(source.Indexes))
+	for i := range source.Indexes {
+		s := &source.Indexes[i]
+		key := strings.ToLower(s.Name)
+		sourceIdx[key] = true
+		t, ok := targetIdx[key]
+		switch {
+		case !ok:
+			td.Indexes = append(td.Indexes, IndexDiff{Index: s.Name, Kind: DiffMissing, Source: s})
+		case s.Unique != t.Unique || !sameNames(s.Columns, t.Columns):
+			td.Indexes = append(td.Indexes, IndexDiff{Index: s.Name, Kind: DiffChanged, Source: s, Target: t})
+		}
+	}
+	for i := range target.Indexes {
+		t := &target.Indexes[i]
+		if !sourceIdx[strings.ToLower(t.Name)] {
+			td.Indexes = append(td.Indexes, IndexDiff{Index: t.Name, Kind: DiffExtra, Target: t})
+		}
+	}
+
+	if !sameNames(source.PrimaryKey, target.PrimaryKey) {
+		td.PrimaryKey = &PrimaryKeyDiff{Source: source.PrimaryKey, Target: target.PrimaryKey}
+	}
+
+	if len(td.Columns) == 0 && len(td.Indexes) == 0 && td.PrimaryKey == nil {
+		return nil
+	}
+	return td
+}
+
+// columnChanges describes how the attributes of a column of the target
+// differ from the source, from the target value to the source value.
+func columnChanges(s, t *Column) []string {
+	var changes []string
+	if st, tt := ColumnType(s), ColumnType(t); !strings.EqualFold(st, tt) {
+		changes = append(changes, fmt.Sprintf("type %s -> %s", tt, st))
+	}
+	if s.Nullable != t.Nullable {
+		changes = append(changes, fmt.Sprintf("nullable %t -> %t", t.Nullable, s.Nullable))
+	}
+	if sd, td := defaultValue(s), defaultValue(t); sd != td {
+		changes = append(changes, fmt.Sprintf("default %s -> %s", td, sd))
+	}
+	if s.IsAutoIncrement != t.IsAutoIncrement {
+		changes = append(changes, fmt.Sprintf("auto_increment %t -> %t", t.IsAutoIncrement, s.IsAutoIncrement))
+	}
+	if sg, tg := generation(s), generation(t); sg != tg {
+		changes = append(changes, fmt.Sprintf("generated %s -> %s", tg, sg))
+	}
+	return changes
+}
+
+// ColumnType returns the type of a column as declared in its source, e.g.
+// varchar(64), or its normalized type if the source type is unknown. The
+// length, precision and scale are appended to types without them.
+func ColumnType(c *Column) string {
+	t := c.SourceType
+	if t == "" {
+		t = c.Type
+	}
+	if strings.Contains(t, "(") {
+		return t
+	}
+	switch {
+	case c.Length != nil:
+		return fmt.Sprintf("%s(%d)", t, *c.Length)
+	case c.Precision != nil && c.Scale != nil:
+		return fmt.Sprintf("%s(%d,%d)", t, *c.Precision, *c.Scale)
+	case c.Precision != nil:
+		return fmt.Sprintf("%s(%d)", t, *c.Precision)
+	}
+	return t
+}
+
+func defaultValue(c *Column) string {
+	if c.Default == nil {
+		return "none"
+	}
+	return *c.Default
+}
+
+func generation(c *Column) string {
+	if c.Generated == nil {
+		return "none"
+	}
+	return c.Generated.Expression
+}
+
+// sameNames reports whether two lists of column names are the same,
+// regardless of case.
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareSchemas(t *testing.T) {
+	length := func(n int) *int { return &n }
+	def := "0"
+	staging := []*TableMetadata{
+		{
+			Name: "orders",
+			Columns: []Column{
+				{Name: "id", SourceType: "bigint", IsAutoIncrement: true},
+				{Name: "note", Type: "varchar", Length: length(255), Nullable: true},
+				{Name: "discount", SourceType: "decimal(10,2)", Default: &def},
+				{Name: "User_ID", SourceType: "bigint"},
+			},
+			Indexes: []Index{
+				{Name: "idx_user", Columns: []string{"user_id"}},
+				{Name: "idx_created", Columns: []string{"created_at", "id"}},
+			},
+			PrimaryKey: []string{"id"},
+		},
+		{Name: "coupons", Columns: []Column{{Name: "id", SourceType: "bigint"}}},
+		{Name: "users", Columns: []Column{{Name: "id", SourceType: "bigint"}}},
+	}
+	prod := []*TableMetadata{
+		{
+			Name: "orders",
+			Columns: []Column{
+				{Name: "id", SourceType: "BIGINT", IsAutoIncrement: true},
+				{Name: "note", Type: "varchar", Length: length(64)},
+				{Name: "user_id", SourceType: "bigint"},
+				{Name: "legacy_flag", SourceType: "tinyint(1)"},
+			},
+			Indexes: []Index{
+				{Name: "idx_created", Columns: []string{"created_at"}},
+				{Name: "idx_legacy", Columns: []string{"legacy_flag"}},
+			},
+			PrimaryKey: []string{"ID"},
+		},
+		{Name: "Users", Columns: []Column{{Name: "id", SourceType: "bigint"}}},
+		{Name: "legacy_orders", Columns: []Column{{Name: "id", SourceType: "bigint"}}},
+	}
+
+	diff := CompareSchemas(staging, prod)
+	if diff.Empty() {
+		t.Fatal("CompareSchemas() found no difference")
+	}
+	if !reflect.DeepEqual(diff.MissingTables, []string{"coupons"}) {
+		t.Errorf("MissingTables = %v", diff.MissingTables)
+	}
+	if !reflect.DeepEqual(diff.ExtraTables, []string{"legacy_orders"}) {
+		t.Errorf("ExtraTables = %v", diff.ExtraTables)
+	}
+	if len(diff.Tables) != 1 || diff.Tables[0].Table != "orders" {
+		t.Fatalf("Tables = %+v, want orders", diff.Tables)
+	}
+
+	td := diff.Tables[0]
+	var columns []string
+	for _, c := range td.Columns {
+		columns = append(columns, c.Kind+" "+c.Column)
+	}
+	if want := []string{"changed note", "missing discount", "extra legacy_flag"}; !reflect.DeepEqual(columns, want) {
+		t.Errorf("Columns = %v, want %v", columns, want)
+	}
+	if want := []string{"type varchar(64) -> varchar(255)", "nullable false -> true"}; !reflect.DeepEqual(td.Columns[0].Changes, want) {
+		t.Errorf("Changes of note = %v, want %v", td.Columns[0].Changes, want)
+	}
+	var indexes []string
+	for _, i := range td.Indexes {
+		indexes = append(indexes, i.Kind+" "+i.Index)
+	}
+	if want := []string{"missing idx_user", "changed idx_created", "extra idx_legacy"}; !reflect.DeepEqual(indexes, want) {
+		t.Errorf("Indexes = %v, want %v", indexes, want)
+	}
+	if td.PrimaryKey != nil {
+		t.Errorf("PrimaryKey = %+v, want no difference", td.PrimaryKey)
+	}
+
+	if diff := CompareSchemas(staging, staging); !diff.Empty() {
+		t.Errorf("CompareSchemas() of the same schema = %+v", diff)
+	}
+}
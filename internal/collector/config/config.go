@@ -2,6 +2,8 @@
 package config
 
 import (
+	"time"
+
 	"go-metadata/internal/collector"
 )
 
@@ -17,6 +19,8 @@ type ConnectorConfig struct {
 	Collect     *CollectOptions            `json:"collect,omitempty" yaml:"collect"`
 	Statistics  *StatisticsConfig          `json:"statistics,omitempty" yaml:"statistics"`
 	Infer       *InferConfig               `json:"infer,omitempty" yaml:"infer"` // Schema inference config for schema-less data sources
+	Session     *SessionConfig             `json:"session,omitempty" yaml:"session"` // Read replica and query guards for RDBMS collectors
+	Timeouts    *TimeoutConfig             `json:"timeouts,omitempty" yaml:"timeouts"` // Per-operation timeouts of syncs
 }
 
 // Credentials 凭证信息
@@ -34,6 +38,81 @@ type ConnectionProps struct {
 	Extra             map[string]string `json:"extra,omitempty" yaml:"extra"`
 }
 
+// DefaultApplicationName 采集器会话的默认标识
+const DefaultApplicationName = "go-metadata"
+
+// 事务隔离级别
+const (
+	IsolationReadUncommitted = "read_uncommitted"
+	IsolationReadCommitted   = "read_committed"
+)
+
+// SessionConfig 会话与查询保护配置 (mysql, postgres, sqlserver, oracle)，
+// 便于 DBA 识别并控制元数据扫描
+type SessionConfig struct {
+	// ReadReplica 只读副本地址 (host:port)，优先连接
+	ReadReplica string `json:"read_replica" yaml:"read_replica"`
+	// ReplicaOnly 只读副本不可用时不回退到 Endpoint
+	ReplicaOnly bool `json:"replica_only" yaml:"replica_only"`
+	// StatementTimeout 会话级语句超时 (秒)，0 表示不限制
+	StatementTimeout int `json:"statement_timeout" yaml:"statement_timeout"`
+	// IsolationLevel 会话事务隔离级别: read_uncommitted, read_committed
+	IsolationLevel string `json:"isolation_level" yaml:"isolation_level"`
+	// ApplicationName 会话标识，默认 go-metadata
+	ApplicationName string `json:"application_name" yaml:"application_name"`
+}
+
+// TimeoutConfig 同步时单个操作的超时 (秒)，0 表示不限制。
+// 超时只中止当前操作，单张表查询挂起不会拖住整个同步
+type TimeoutConfig struct {
+	// List 列出 catalog、schema 与一页表的超时
+	List int `json:"list" yaml:"list"`
+	// Fetch 获取单表元数据的超时
+	Fetch int `json:"fetch" yaml:"fetch"`
+	// Stats 获取单表统计信息的超时
+	Stats int `json:"stats" yaml:"stats"`
+}
+
+// Duration 返回 kind 类型操作的超时，未配置时返回 0
+func (t *TimeoutConfig) Duration(kind collector.TimeoutKind) time.Duration {
+	if t == nil {
+		return 0
+	}
+	var seconds int
+	switch kind {
+	case collector.TimeoutList:
+		seconds = t.List
+	case collector.TimeoutFetch:
+		seconds = t.Fetch
+	case collector.TimeoutStats:
+		seconds = t.Stats
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Endpoints 返回依次尝试的连接地址：配置只读副本时先连副本，副本不可用时回退到 Endpoint
+func (c *ConnectorConfig) Endpoints() []string {
+	if c.Session == nil || c.Session.ReadReplica == "" {
+		return []string{c.Endpoint}
+	}
+	if c.Session.ReplicaOnly || c.Endpoint == "" {
+		return []string{c.Session.ReadReplica}
+	}
+	return []string{c.Session.ReadReplica, c.Endpoint}
+}
+
+// SessionSettings 返回会话配置，未配置会话标识时使用 DefaultApplicationName
+func (c *ConnectorConfig) SessionSettings() SessionConfig {
+	var s SessionConfig
+	if c.Session != nil {
+		s = *c.Session
+	}
+	if s.ApplicationName == "" {
+		s.ApplicationName = DefaultApplicationName
+	}
+	return s
+}
+
 // MatchingConfig 匹配规则配置
 type MatchingConfig struct {
 	PatternType   string        `json:"pattern_type" yaml:"pattern_type"` // glob, regex
@@ -87,6 +166,19 @@ const (
 	TypeMergeMostCommon TypeMergeStrategy = "most_common"
 )
 
+// SampleValueMode defines whether values of the sampled documents are kept
+// in the inferred metadata.
+type SampleValueMode string
+
+const (
+	// SampleValuesNone keeps only the types and structure of the samples
+	SampleValuesNone SampleValueMode = "none"
+	// SampleValuesHash keeps SHA-256 digests of example values
+	SampleValuesHash SampleValueMode = "hash"
+	// SampleValuesRaw keeps example values as sampled
+	SampleValuesRaw SampleValueMode = "raw"
+)
+
 // InferConfig holds configuration for schema inference.
 // Used for schema-less data sources like DocumentDB, KeyValue, and ObjectStorage.
 type InferConfig struct {
@@ -98,6 +190,8 @@ type InferConfig struct {
 	MaxDepth int `json:"max_depth" yaml:"max_depth"`
 	// TypeMerge specifies the strategy for merging multiple types
 	TypeMerge TypeMergeStrategy `json:"type_merge" yaml:"type_merge"`
+	// SampleValues specifies whether example values are kept (empty = none)
+	SampleValues SampleValueMode `json:"sample_values,omitempty" yaml:"sample_values"`
 }
 
 // DefaultInferConfig returns the default inference configuration.
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Extra keys of ConnectionProps configuring how many tables a sync harvests
+// in parallel and how fast, e.g. "8" and "20" for eight workers fetching at
+// most twenty tables per second.
+const (
+	ExtraConcurrency = "concurrency"
+	ExtraRateLimit   = "rate_limit"
+)
+
+// Extra keys of ConnectionProps opting in to the capture of example values
+// of columns, e.g. "3" values of at most "64" characters, leaving out the
+// columns matching the comma-separated globs of example_exclude on top of
+// those classified as personal data by their names.
+const (
+	ExtraExamples       = "examples"
+	ExtraExampleLength  = "example_length"
+	ExtraExampleExclude = "example_exclude"
+)
+
+// ExtraLanguages is the extra key of ConnectionProps listing the languages
+// the comments of tables and columns are described in, e.g. "en,zh-CN". The
+// comments are translated to those they are not written in if the service
+// has a collector.Translator.
+const ExtraLanguages = "languages"
+
+// ExtraQueryLog is the extra key of ConnectionProps opting in to lineage
+// harvested from the query log of the source, "true" to read it on every
+// sync, e.g. pg_stat_statements or the MySQL general log table.
+const ExtraQueryLog = "query_log"
+
+// languageTag matches the BCP 47 language tags of ExtraLanguages, such as en,
+// zh-CN or zh-Hant.
+var languageTag = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// Bounds of the example values of a column.
+const (
+	MaxExamples          = 10
+	DefaultExampleLength = 64
+)
+
+// HarvestConfig 同步时采集表元数据的配置：并行度与速率、列示例值、
+// 表和列描述的语言，以及是否读取查询日志采集血缘
+type HarvestConfig struct {
+	// Concurrency 并行获取表元数据的 worker 数，默认 1 (串行)。
+	// 不宜超过 max_open_conns，否则 worker 会等待空闲连接
+	Concurrency int `json:"concurrency"`
+	// RateLimit 每秒最多获取的表数，0 表示不限制
+	RateLimit float64 `json:"rate_limit"`
+	// Examples 每列采集的示例值个数，0 (默认) 表示不采集
+	Examples int `json:"examples,omitempty"`
+	// ExampleLength 示例值的最大字符数，超出部分截断
+	ExampleLength int `json:"example_length,omitempty"`
+	// ExampleExclude 不采集示例值的列名模式，个人信息列总是排除
+	ExampleExclude []string `json:"example_exclude,omitempty"`
+	// Languages 表和列描述的语言，注释会被翻译为其中非原文的语言
+	Languages []string `json:"languages,omitempty"`
+	// QueryLog 同步时读取数据源的查询日志，从执行过的语句中采集血缘
+	QueryLog bool `json:"query_log,omitempty"`
+}
+
+// Harvest returns the harvest config set in the Extra properties: one worker,
+// no rate limit, no example values, no descriptions and no query log unless
+// configured.
+func (c *ConnectorConfig) Harvest() (HarvestConfig, error) {
+	h := HarvestConfig{Concurrency: 1, ExampleLength: DefaultExampleLength}
+	if v := strings.TrimSpace(c.Properties.Extra[ExtraConcurrency]); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return h, fmt.Errorf("%s must be a positive integer, got %q", ExtraConcurrency, v)
+		}
+		h.Concurrency = n
+	}
+	if v := strings.TrimSpace(c.Properties.Extra[ExtraRateLimit]); v != "" {
+		r, err := strconv.ParseFloat(v, 64)
+		if err != nil || r < 0 {
+			return h, fmt.Errorf("%s must be a non-negative number of tables per second, got %q", ExtraRateLimit, v)
+		}
+		h.RateLimit = r
+	}
+	if v := strings.TrimSpace(c.Properties.Extra[ExtraExamples]); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > MaxExamples {
+			return h, fmt.Errorf("%s must be a number of values per column between 0 and %d, got %q", ExtraExamples, MaxExamples, v)
+		}
+		h.Examples = n
+	}
+	if v := strings.TrimSpace(c.Properties.Extra[ExtraExampleLength]); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return h, fmt.Errorf("%s must be a positive number of characters, got %q", ExtraExampleLength, v)
+		}
+		h.ExampleLength = n
+	}
+	for _, pattern := range strings.Split(c.Properties.Extra[ExtraExampleExclude], ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return h, fmt.Errorf("%s: invalid pattern %q", ExtraExampleExclude, pattern)
+		}
+		h.ExampleExclude = append(h.ExampleExclude, pattern)
+	}
+	for _, lang := range strings.Split(c.Properties.Extra[ExtraLanguages], ",") {
+		if lang = strings.TrimSpace(lang); lang == "" {
+			continue
+		}
+		if !languageTag.MatchString(lang) {
+			return h, fmt.Errorf("%s: invalid language tag %q", ExtraLanguages, lang)
+		}
+		h.Languages = append(h.Languages, lang)
+	}
+	if v := strings.TrimSpace(c.Properties.Extra[ExtraQueryLog]); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return h, fmt.Errorf("%s must be true or false, got %q", ExtraQueryLog, v)
+		}
+		h.QueryLog = b
+	}
+	return h, nil
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConnectorConfig_Harvest(t *testing.T) {
+	cfg := &ConnectorConfig{Type: "hive", Endpoint: "localhost:10000"}
+	h, err := cfg.Harvest()
+	if err != nil || h.Concurrency != 1 || h.RateLimit != 0 || h.Examples != 0 {
+		t.Errorf("Harvest() = %+v, %v; want one worker without rate limit nor examples", h, err)
+	}
+
+	cfg.Properties.Extra = map[string]string{ExtraConcurrency: "8", ExtraRateLimit: "2.5"}
+	h, err = cfg.Harvest()
+	if err != nil || h.Concurrency != 8 || h.RateLimit != 2.5 {
+		t.Errorf("Harvest() = %+v, %v", h, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	cfg.Properties.Extra = map[string]string{ExtraExamples: "3", ExtraExampleExclude: "*_token, notes"}
+	h, err = cfg.Harvest()
+	if err != nil || h.Examples != 3 || h.ExampleLength != DefaultExampleLength || len(h.ExampleExclude) != 2 || h.ExampleExclude[1] != "notes" {
+		t.Errorf("Harvest() = %+v, %v", h, err)
+	}
+
+	cfg.Properties.Extra = map[string]string{ExtraLanguages: "en, zh-CN"}
+	h, err = cfg.Harvest()
+	if err != nil || len(h.Languages) != 2 || h.Languages[0] != "en" || h.Languages[1] != "zh-CN" {
+		t.Errorf("Harvest() = %+v, %v", h, err)
+	}
+
+	cfg.Properties.Extra = map[string]string{ExtraQueryLog: "true"}
+	if h, err = cfg.Harvest(); err != nil || !h.QueryLog {
+		t.Errorf("Harvest() = %+v, %v; want the query log read", h, err)
+	}
+
+	for _, extra := range []map[string]string{
+		{ExtraConcurrency: "0"},
+		{ExtraConcurrency: "many"},
+		{ExtraRateLimit: "-1"},
+		{ExtraExamples: "100"},
+		{ExtraExampleLength: "0"},
+		{ExtraExampleExclude: "[a"},
+		{ExtraLanguages: "en,chinese simplified"},
+		{ExtraQueryLog: "sometimes"},
+	} {
+		cfg.Properties.Extra = extra
+		if _, err := cfg.Harvest(); err == nil {
+			t.Errorf("Harvest() of %v succeeded", extra)
+		}
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "properties.extra") {
+			t.Errorf("Validate() of %v error = %v, should contain %q", extra, err, "properties.extra")
+		}
+	}
+}
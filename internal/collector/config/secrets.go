@@ -0,0 +1,110 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// 凭证引用相关错误
+var (
+	ErrUnknownSecretProvider = errors.New("unknown secret provider")
+	ErrSecretNotFound        = errors.New("secret not found")
+)
+
+// secretRef 凭证中的引用，格式为 ${提供方:引用}，如 ${env:MYSQL_PASSWORD}、
+// ${vault:secret/data/mysql/prod#password}
+var secretRef = regexp.MustCompile(`\$\{([A-Za-z][A-Za-z0-9_-]*):([^}]*)\}`)
+
+// SecretProvider 凭证提供方，按引用返回凭证，使配置文件中不保存明文密码
+type SecretProvider interface {
+	// Resolve 返回引用的凭证，引用不含提供方前缀，如 MYSQL_PASSWORD
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretProviderFunc 函数形式的凭证提供方
+type SecretProviderFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve implements SecretProvider.
+func (f SecretProviderFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var (
+	secretProvidersMu sync.RWMutex
+	secretProviders   = map[string]SecretProvider{
+		"env":   SecretProviderFunc(resolveEnv),
+		"vault": VaultFromEnv(),
+	}
+)
+
+// RegisterSecretProvider registers the provider of ${name:...} references,
+// replacing the provider of that name if any, e.g. a Vault provider with
+// its own address and token.
+func RegisterSecretProvider(name string, p SecretProvider) {
+	secretProvidersMu.Lock()
+	defer secretProvidersMu.Unlock()
+	secretProviders[name] = p
+}
+
+// ResolveSecrets replaces the ${provider:ref} references of value with the
+// secrets they refer to. Values without references are returned unchanged.
+func ResolveSecrets(ctx context.Context, value string) (string, error) {
+	matches := secretRef.FindAllStringSubmatchIndex(value, -1)
+	if matches == nil {
+		return value, nil
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		name, ref := value[m[2]:m[3]], value[m[4]:m[5]]
+		secretProvidersMu.RLock()
+		p, ok := secretProviders[name]
+		secretProvidersMu.RUnlock()
+		if !ok {
+			return "", fmt.Errorf("%w: %q", ErrUnknownSecretProvider, name)
+		}
+		secret, err := p.Resolve(ctx, ref)
+		if err != nil {
+			return "", fmt.Errorf("resolve ${%s:%s}: %w", name, ref, err)
+		}
+		b.WriteString(value[last:m[0]])
+		b.WriteString(secret)
+		last = m[1]
+	}
+	b.WriteString(value[last:])
+	return b.String(), nil
+}
+
+// ResolveCredentials replaces the ${provider:ref} references of the user and
+// password of c with the secrets they refer to. It is called after the
+// config is loaded and decrypted, before the collector is created.
+func (c *ConnectorConfig) ResolveCredentials(ctx context.Context) error {
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"user", &c.Credentials.User},
+		{"password", &c.Credentials.Password},
+	} {
+		v, err := ResolveSecrets(ctx, *field.value)
+		if err != nil {
+			return fmt.Errorf("credentials.%s: %w", field.name, err)
+		}
+		*field.value = v
+	}
+	return nil
+}
+
+// resolveEnv resolves ${env:VAR} references.
+func resolveEnv(_ context.Context, name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("%w: environment variable %s is not set", ErrSecretNotFound, name)
+	}
+	return v, nil
+}
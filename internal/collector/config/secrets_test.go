@@ -0,0 +1,83 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveCredentials_Env(t *testing.T) {
+	t.Setenv("TEST_MYSQL_USER", "readonly")
+	t.Setenv("TEST_MYSQL_PASSWORD", "s3cret")
+
+	cfg := &ConnectorConfig{Credentials: Credentials{User: "${env:TEST_MYSQL_USER}", Password: "pre-${env:TEST_MYSQL_PASSWORD}"}}
+	if err := cfg.ResolveCredentials(context.Background()); err != nil {
+		t.Fatalf("ResolveCredentials failed: %v", err)
+	}
+	if cfg.Credentials.User != "readonly" || cfg.Credentials.Password != "pre-s3cret" {
+		t.Errorf("credentials = %+v", cfg.Credentials)
+	}
+
+	// Literal values are unchanged
+	cfg = &ConnectorConfig{Credentials: Credentials{User: "root", Password: "$plain{}"}}
+	if err := cfg.ResolveCredentials(context.Background()); err != nil || cfg.Credentials.Password != "$plain{}" {
+		t.Errorf("ResolveCredentials = %+v, %v", cfg.Credentials, err)
+	}
+
+	cfg = &ConnectorConfig{Credentials: Credentials{Password: "${env:TEST_UNSET_PASSWORD}"}}
+	if err := cfg.ResolveCredentials(context.Background()); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("ResolveCredentials of an unset variable: %v", err)
+	}
+	cfg = &ConnectorConfig{Credentials: Credentials{Password: "${nope:x}"}}
+	if err := cfg.ResolveCredentials(context.Background()); !errors.Is(err, ErrUnknownSecretProvider) {
+		t.Errorf("ResolveCredentials of an unknown provider: %v", err)
+	}
+}
+
+func TestVaultSecretProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/mysql/prod":
+			w.Write([]byte(`{"data": {"data": {"password": "v2-secret", "port": 3306}, "metadata": {"version": 3}}}`))
+		case "/v1/kv/mysql":
+			w.Write([]byte(`{"data": {"password": "v1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewVaultSecretProvider(srv.URL+"/", "token")
+	ctx := context.Background()
+	for ref, want := range map[string]string{
+		"secret/data/mysql/prod#password": "v2-secret",
+		"secret/data/mysql/prod#port":     "3306",
+		"kv/mysql#password":               "v1-secret",
+	} {
+		got, err := p.Resolve(ctx, ref)
+		if err != nil || got != want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", ref, got, err, want)
+		}
+	}
+	for _, ref := range []string{"secret/data/mysql/prod#user", "secret/data/missing#password"} {
+		if _, err := p.Resolve(ctx, ref); !errors.Is(err, ErrSecretNotFound) {
+			t.Errorf("Resolve(%q) error = %v, want ErrSecretNotFound", ref, err)
+		}
+	}
+	if _, err := p.Resolve(ctx, "secret/data/mysql/prod"); err == nil {
+		t.Error("Resolve of a reference without a field succeeded")
+	}
+
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "token")
+	got, err := ResolveSecrets(ctx, "${vault:kv/mysql#password}")
+	if err != nil || got != "v1-secret" {
+		t.Errorf("ResolveSecrets = %q, %v", got, err)
+	}
+}
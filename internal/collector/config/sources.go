@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-metadata/internal/textfile"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrSourceNotFound 配置文件中没有该名称的数据源
+var ErrSourceNotFound = errors.New("source not found")
+
+// Sources 配置文件中按名称定义的数据源
+//
+// 数据源可以写在 sources 映射中，键即数据源名称：
+//
+//	sources:
+//	  mysql_prod:
+//	    type: mysql
+//	    endpoint: db.internal:3306
+//	    credentials: {user: readonly, password: ""}
+//	    matching:
+//	      databases: {include: ["sales*"]}
+//	    collect: {comments: true, indexes: true}
+//
+// 也可以写在服务配置的 collectors 列表中，以 id 为名称。tiers 配置节为表的分级规则
+// (见 TierConfig)。其他配置节被忽略，因此服务配置文件可以直接作为数据源配置文件使用
+type Sources struct {
+	configs []*ConnectorConfig
+	byName  map[string]*ConnectorConfig
+	tiers   *TierConfig
+}
+
+// sourcesFile 数据源配置文件中与数据源相关的配置节
+type sourcesFile struct {
+	Sources    map[string]*ConnectorConfig `yaml:"sources"`
+	Collectors []*ConnectorConfig          `yaml:"collectors"`
+	Tiers      *TierConfig                 `yaml:"tiers"`
+}
+
+// LoadSources reads the sources of a YAML config file.
+func LoadSources(path string) (*Sources, error) {
+	data, err := textfile.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSources([]byte(data))
+}
+
+// ParseSources parses the sources of a YAML config file. Every source is
+// validated; the errors of all invalid sources are returned together.
+func ParseSources(data []byte) (*Sources, error) {
+	var f sourcesFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse sources: %w", err)
+	}
+
+	configs := append([]*ConnectorConfig(nil), f.Collectors...)
+	names := make([]string, 0, len(f.Sources))
+	for name := range f.Sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cfg := f.Sources[name]
+		if cfg == nil {
+			cfg = &ConnectorConfig{}
+		}
+		if cfg.ID != "" && cfg.ID != name {
+			return nil, fmt.Errorf("source %s: id %q does not match its name", name, cfg.ID)
+		}
+		cfg.ID = name
+		configs = append(configs, cfg)
+	}
+	if err := f.Tiers.Validate(); err != nil {
+		return nil, fmt.Errorf("parse sources: %w", err)
+	}
+	s, err := NewSources(configs)
+	if err != nil {
+		return nil, err
+	}
+	s.tiers = f.Tiers
+	return s, nil
+}
+
+// NewSources indexes configs by ID, which must be set and unique.
+func NewSources(configs []*ConnectorConfig) (*Sources, error) {
+	s := &Sources{byName: make(map[string]*ConnectorConfig, len(configs))}
+	var errs []string
+	for i, cfg := range configs {
+		if cfg == nil {
+			continue
+		}
+		name := strings.TrimSpace(cfg.ID)
+		if name == "" {
+			errs = append(errs, fmt.Sprintf("source #%d: id is required", i+1))
+			continue
+		}
+		if _, ok := s.byName[name]; ok {
+			errs = append(errs, fmt.Sprintf("source %s: defined more than once", name))
+			continue
+		}
+		if err := cfg.Validate(); err != nil {
+			errs = append(errs, fmt.Sprintf("source %s: %v", name, err))
+		}
+		s.byName[name] = cfg
+		s.configs = append(s.configs, cfg)
+	}
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "; "))
+	}
+	return s, nil
+}
+
+// Get returns the config of the named source.
+func (s *Sources) Get(name string) (*ConnectorConfig, error) {
+	if cfg, ok := s.byName[name]; ok {
+		return cfg, nil
+	}
+	if len(s.configs) == 0 {
+		return nil, fmt.Errorf("%w: %s, no sources are defined", ErrSourceNotFound, name)
+	}
+	return nil, fmt.Errorf("%w: %s, defined sources are: %s", ErrSourceNotFound, name, strings.Join(s.Names(), ", "))
+}
+
+// Names returns the sorted names of the sources.
+func (s *Sources) Names() []string {
+	names := make([]string, 0, len(s.configs))
+	for _, cfg := range s.configs {
+		names = append(names, cfg.ID)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tiers returns the tier rules of the file, or nil if it has none.
+func (s *Sources) Tiers() *TierConfig {
+	return s.tiers
+}
+
+// All returns the source configs in file order, collectors first.
+func (s *Sources) All() []*ConnectorConfig {
+	return s.configs
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseSources(t *testing.T) {
+	sources, err := ParseSources([]byte(`
+server:
+  port: 8080
+sources:
+  mysql_prod:
+    type: mysql
+    endpoint: db.internal:3306
+    credentials: {user: readonly}
+    matching:
+      databases: {include: ["sales*"]}
+    collect: {comments: true}
+  hive_prod:
+    type: hive
+    endpoint: hive.internal:10000
+collectors:
+  - id: pg-prod
+    type: postgres
+    endpoint: localhost:5432
+tiers:
+  rules:
+    - match: dw.fact_*
+      tier: gold
+`))
+	if err != nil {
+		t.Fatalf("ParseSources failed: %v", err)
+	}
+	if got := sources.Names(); !slices.Equal(got, []string{"hive_prod", "mysql_prod", "pg-prod"}) {
+		t.Errorf("Names() = %v", got)
+	}
+
+	mysql, err := sources.Get("mysql_prod")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if mysql.ID != "mysql_prod" || mysql.Type != "mysql" || mysql.Credentials.User != "readonly" ||
+		mysql.Matching.Databases.Include[0] != "sales*" || !mysql.Collect.Comments {
+		t.Errorf("unexpected source %+v", mysql)
+	}
+
+	_, err = sources.Get("oracle_prod")
+	if !errors.Is(err, ErrSourceNotFound) || !strings.Contains(err.Error(), "hive_prod, mysql_prod, pg-prod") {
+		t.Errorf("expected a not found error listing the sources, got %v", err)
+	}
+
+	if tier := sources.Tiers().Tier("hive_prod", "hive", "dw", "fact_orders"); tier != TierGold {
+		t.Errorf("Tiers().Tier() = %q, want gold", tier)
+	}
+}
+
+func TestParseSourcesInvalid(t *testing.T) {
+	tests := map[string]string{
+		"missing endpoint": "sources:\n  mysql_prod:\n    type: mysql\n",
+		"missing id":       "collectors:\n  - type: mysql\n    endpoint: localhost:3306\n",
+		"duplicate":        "sources:\n  pg:\n    type: postgres\n    endpoint: localhost\ncollectors:\n  - id: pg\n    type: postgres\n    endpoint: localhost\n",
+		"mismatched id":    "sources:\n  pg:\n    id: other\n    type: postgres\n    endpoint: localhost\n",
+		"not yaml":         "sources: [",
+		"bad tier":         "tiers:\n  rules:\n    - match: dw.*\n      tier: platinum\n",
+	}
+	for name, data := range tests {
+		if _, err := ParseSources([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// The errors of all invalid sources are reported
+	_, err := ParseSources([]byte("sources:\n  a:\n    type: mysql\n  b:\n    endpoint: localhost\n"))
+	if err == nil || !strings.Contains(err.Error(), "source a:") || !strings.Contains(err.Error(), "source b:") {
+		t.Errorf("expected errors for both sources, got %v", err)
+	}
+}
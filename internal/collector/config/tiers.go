@@ -0,0 +1,203 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// 数据集级别
+const (
+	TierGold   = "gold"
+	TierSilver = "silver"
+	TierBronze = "bronze"
+)
+
+// 统计信息采集深度
+const (
+	// StatisticsColumns 采集表与列统计信息 (默认)
+	StatisticsColumns = "columns"
+	// StatisticsTable 只采集表统计信息 (行数、大小等)
+	StatisticsTable = "table"
+	// StatisticsNone 不采集统计信息
+	StatisticsNone = "none"
+)
+
+// TierConfig 数据集分级配置：按规则为表指定 gold/silver/bronze 级别，
+// 级别决定同步频率、统计信息采集深度与结构变更告警阈值
+//
+//	tiers:
+//	  rules:
+//	    - match: dw.fact_*        # schema.table；也可以是 table 或 catalog.schema.table
+//	      tier: gold
+//	    - source: mysql_*
+//	      match: "*.tmp_*"
+//	      tier: bronze
+//	  policies:                   # 覆盖默认策略
+//	    silver: {sync_interval: 12h}
+//
+// 不匹配任何规则的表没有级别，每次同步都采集完整元数据并通知所有结构变更
+type TierConfig struct {
+	// Rules 分级规则，按顺序匹配，第一条匹配的规则生效
+	Rules []TierRule `json:"rules" yaml:"rules"`
+	// Policies 各级别的策略，未配置的字段使用 DefaultTierPolicies 中的值
+	Policies map[string]TierPolicy `json:"policies" yaml:"policies"`
+}
+
+// TierRule 分级规则
+type TierRule struct {
+	// Source 数据源名称的 glob 模式，为空匹配所有数据源
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+	// Match 表名的 glob 模式 (不区分大小写)，按其中 "." 的个数匹配 table、
+	// schema.table 或 catalog.schema.table，为空匹配所有表
+	Match string `json:"match,omitempty" yaml:"match,omitempty"`
+	// Tier 匹配的表的级别：gold、silver 或 bronze
+	Tier string `json:"tier" yaml:"tier"`
+}
+
+// TierPolicy 一个级别的同步、统计与告警策略
+type TierPolicy struct {
+	// SyncInterval 重新采集一张表的最短间隔 (如 24h)，间隔内的同步保留存储中的版本；
+	// 为空或 0 表示每次同步都采集
+	SyncInterval string `json:"sync_interval,omitempty" yaml:"sync_interval,omitempty"`
+	// Statistics 统计信息采集深度：columns、table 或 none
+	Statistics string `json:"statistics,omitempty" yaml:"statistics,omitempty"`
+	// DriftThreshold 一次同步中一张表至少有多少处结构变更 (列、索引或主键) 才发送通知，
+	// 0 表示不通知；变更总会被记录
+	DriftThreshold *int `json:"drift_threshold,omitempty" yaml:"drift_threshold,omitempty"`
+}
+
+// DefaultTierPolicies returns the policies of the tiers that the config
+// does not override: gold tables are synced every time with column
+// statistics and every schema change is notified, silver tables at most
+// daily with table statistics and notified of changes of 3 or more columns
+// or indexes, and bronze tables at most weekly without statistics or
+// notifications.
+func DefaultTierPolicies() map[string]TierPolicy {
+	threshold := func(n int) *int { return &n }
+	return map[string]TierPolicy{
+		TierGold:   {SyncInterval: "0", Statistics: StatisticsColumns, DriftThreshold: threshold(1)},
+		TierSilver: {SyncInterval: "24h", Statistics: StatisticsTable, DriftThreshold: threshold(3)},
+		TierBronze: {SyncInterval: "168h", Statistics: StatisticsNone, DriftThreshold: threshold(0)},
+	}
+}
+
+// Validate checks the tiers, patterns and policies of the config.
+func (c *TierConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	for i, r := range c.Rules {
+		if !isTier(r.Tier) {
+			return fmt.Errorf("tiers.rules[%d]: tier must be gold, silver or bronze, got %q", i, r.Tier)
+		}
+		for _, pattern := range []string{r.Source, r.Match} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("tiers.rules[%d]: bad pattern %q", i, pattern)
+			}
+		}
+	}
+	for tier, p := range c.Policies {
+		if !isTier(tier) {
+			return fmt.Errorf("tiers.policies: unknown tier %q, must be gold, silver or bronze", tier)
+		}
+		if p.SyncInterval != "" {
+			if d, err := time.ParseDuration(p.SyncInterval); err != nil || d < 0 {
+				return fmt.Errorf("tiers.policies.%s: bad sync_interval %q", tier, p.SyncInterval)
+			}
+		}
+		switch p.Statistics {
+		case "", StatisticsColumns, StatisticsTable, StatisticsNone:
+		default:
+			return fmt.Errorf("tiers.policies.%s: statistics must be columns, table or none, got %q", tier, p.Statistics)
+		}
+		if p.DriftThreshold != nil && *p.DriftThreshold < 0 {
+			return fmt.Errorf("tiers.policies.%s: drift_threshold must not be negative", tier)
+		}
+	}
+	return nil
+}
+
+// Tier returns the tier of a table of a source given by the first matching
+// rule, or "" if no rule matches.
+func (c *TierConfig) Tier(source, catalog, schema, table string) string {
+	if c == nil {
+		return ""
+	}
+	for _, r := range c.Rules {
+		if r.Source != "" && !matchFold(r.Source, source) {
+			continue
+		}
+		if r.Match != "" {
+			name := table
+			switch strings.Count(r.Match, ".") {
+			case 0:
+			case 1:
+				name = schema + "." + table
+			default:
+				name = catalog + "." + schema + "." + table
+			}
+			if !matchFold(r.Match, name) {
+				continue
+			}
+		}
+		return r.Tier
+	}
+	return ""
+}
+
+// Policy returns the policy of a tier: the default policy with the fields
+// the config sets overridden. Tables without a tier get the zero policy,
+// which syncs them every time with all statistics and notifies every change.
+func (c *TierConfig) Policy(tier string) TierPolicy {
+	if tier == "" {
+		return TierPolicy{}
+	}
+	p := DefaultTierPolicies()[tier]
+	if c == nil {
+		return p
+	}
+	override, ok := c.Policies[tier]
+	if !ok {
+		return p
+	}
+	if override.SyncInterval != "" {
+		p.SyncInterval = override.SyncInterval
+	}
+	if override.Statistics != "" {
+		p.Statistics = override.Statistics
+	}
+	if override.DriftThreshold != nil {
+		p.DriftThreshold = override.DriftThreshold
+	}
+	return p
+}
+
+// Interval returns the sync interval of the policy, 0 if it syncs every
+// time.
+func (p TierPolicy) Interval() time.Duration {
+	d, err := time.ParseDuration(p.SyncInterval)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// Notifies reports whether a schema change of n changes is notified.
+func (p TierPolicy) Notifies(n int) bool {
+	if p.DriftThreshold == nil {
+		return n > 0
+	}
+	return *p.DriftThreshold > 0 && n >= *p.DriftThreshold
+}
+
+func isTier(tier string) bool {
+	return tier == TierGold || tier == TierSilver || tier == TierBronze
+}
+
+// matchFold matches name against a glob pattern case-insensitively.
+func matchFold(pattern, name string) bool {
+	ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+	return ok
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTierConfig_Tier(t *testing.T) {
+	c := &TierConfig{Rules: []TierRule{
+		{Match: "dw.fact_*", Tier: TierGold},
+		{Source: "MYSQL_*", Match: "*.tmp_*", Tier: TierBronze},
+		{Match: "hive.ods.*", Tier: TierSilver},
+		{Match: "audit_log", Tier: TierSilver},
+	}}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		source, catalog, schema, table string
+		want                           string
+	}{
+		{"hive", "hive", "dw", "fact_orders", TierGold},
+		{"hive", "hive", "DW", "FACT_orders", TierGold},
+		{"mysql_prod", "def", "shop", "tmp_import", TierBronze},
+		{"pg", "postgres", "shop", "tmp_import", ""},
+		{"hive", "hive", "ods", "orders", TierSilver},
+		{"pg", "postgres", "public", "audit_log", TierSilver},
+		{"hive", "hive", "dw", "dim_users", ""},
+	}
+	for _, tt := range tests {
+		if got := c.Tier(tt.source, tt.catalog, tt.schema, tt.table); got != tt.want {
+			t.Errorf("Tier(%s, %s.%s.%s) = %q, want %q", tt.source, tt.catalog, tt.schema, tt.table, got, tt.want)
+		}
+	}
+	if got := (*TierConfig)(nil).Tier("hive", "hive", "dw", "fact_orders"); got != "" {
+		t.Errorf("Tier() without config = %q", got)
+	}
+}
+
+func TestTierConfig_Policy(t *testing.T) {
+	two := 2
+	c := &TierConfig{Policies: map[string]TierPolicy{TierSilver: {SyncInterval: "12h", DriftThreshold: &two}}}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	silver := c.Policy(TierSilver)
+	if silver.Interval() != 12*time.Hour || silver.Statistics != StatisticsTable || silver.Notifies(1) || !silver.Notifies(2) {
+		t.Errorf("Policy(silver) = %+v", silver)
+	}
+	if gold := c.Policy(TierGold); gold.Interval() != 0 || gold.Statistics != StatisticsColumns || !gold.Notifies(1) {
+		t.Errorf("Policy(gold) = %+v", gold)
+	}
+	if bronze := c.Policy(TierBronze); bronze.Interval() != 7*24*time.Hour || bronze.Statistics != StatisticsNone || bronze.Notifies(10) {
+		t.Errorf("Policy(bronze) = %+v", bronze)
+	}
+	if none := c.Policy(""); none.Interval() != 0 || !none.Notifies(1) || none.Notifies(0) {
+		t.Errorf("Policy(\"\") = %+v", none)
+	}
+}
+
+func TestTierConfig_ValidateInvalid(t *testing.T) {
+	negative := -1
+	tests := []struct {
+		config *TierConfig
+		want   string
+	}{
+		{&TierConfig{Rules: []TierRule{{Match: "dw.*", Tier: "platinum"}}}, "tier must be"},
+		{&TierConfig{Rules: []TierRule{{Match: "dw.[", Tier: TierGold}}}, "bad pattern"},
+		{&TierConfig{Policies: map[string]TierPolicy{"platinum": {}}}, "unknown tier"},
+		{&TierConfig{Policies: map[string]TierPolicy{TierGold: {SyncInterval: "daily"}}}, "sync_interval"},
+		{&TierConfig{Policies: map[string]TierPolicy{TierGold: {Statistics: "histograms"}}}, "statistics"},
+		{&TierConfig{Policies: map[string]TierPolicy{TierGold: {DriftThreshold: &negative}}}, "drift_threshold"},
+	}
+	for _, tt := range tests {
+		if err := tt.config.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Validate(%+v) error = %v, should contain %q", tt.config, err, tt.want)
+		}
+	}
+}
@@ -104,6 +104,37 @@ func (c *ConnectorConfig) Validate() error {
 		}
 	}
 
+	// Validate session config if present
+	if c.Session != nil {
+		if err := validateSessionConfig(c.Session, c.Type); err != nil {
+			if verrs, ok := err.(*ValidationErrors); ok {
+				for _, e := range verrs.Errors {
+					errs.Add("session."+e.Field, e.Message)
+				}
+			} else {
+				errs.Add("session", err.Error())
+			}
+		}
+	}
+
+	// Validate timeouts if present
+	if c.Timeouts != nil {
+		if c.Timeouts.List < 0 {
+			errs.Add("timeouts.list", "list timeout cannot be negative")
+		}
+		if c.Timeouts.Fetch < 0 {
+			errs.Add("timeouts.fetch", "fetch timeout cannot be negative")
+		}
+		if c.Timeouts.Stats < 0 {
+			errs.Add("timeouts.stats", "stats timeout cannot be negative")
+		}
+	}
+
+	// Validate harvest settings in extra properties
+	if _, err := c.Harvest(); err != nil {
+		errs.Add("properties.extra", err.Error())
+	}
+
 	// Validate infer config if present
 	if c.Infer != nil {
 		if err := validateInferConfig(c.Infer); err != nil {
@@ -133,7 +164,7 @@ func ValidateEndpoint(endpoint, connectorType string) error {
 	switch strings.ToLower(connectorType) {
 	case "mysql", "postgres", "postgresql":
 		return validateDatabaseEndpoint(endpoint)
-	case "hive":
+	case "hive", "impala":
 		return validateHiveEndpoint(endpoint)
 	default:
 		// For unknown types, just check it's not empty (already done above)
@@ -320,6 +351,46 @@ func validateInferConfig(cfg *InferConfig) error {
 		}
 	}
 
+	// Validate sample_values mode
+	switch cfg.SampleValues {
+	case "", SampleValuesNone, SampleValuesHash, SampleValuesRaw:
+	default:
+		errs.Add("sample_values", "sample_values must be 'none', 'hash' or 'raw'")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+// applicationName 会话标识写入连接串和 SQL，只允许常见字符
+var applicationName = regexp.MustCompile(`^[A-Za-z0-9 ._/-]{1,63}$`)
+
+// validateSessionConfig validates the session configuration
+func validateSessionConfig(cfg *SessionConfig, connectorType string) error {
+	errs := &ValidationErrors{}
+
+	if cfg.ReadReplica != "" {
+		if err := ValidateEndpoint(cfg.ReadReplica, connectorType); err != nil {
+			errs.Add("read_replica", err.Error())
+		}
+	} else if cfg.ReplicaOnly {
+		errs.Add("replica_only", "replica_only requires read_replica")
+	}
+
+	if cfg.StatementTimeout < 0 {
+		errs.Add("statement_timeout", "statement_timeout cannot be negative")
+	}
+
+	if cfg.IsolationLevel != "" && cfg.IsolationLevel != IsolationReadUncommitted && cfg.IsolationLevel != IsolationReadCommitted {
+		errs.Add("isolation_level", "isolation_level must be 'read_uncommitted' or 'read_committed'")
+	}
+
+	if cfg.ApplicationName != "" && !applicationName.MatchString(cfg.ApplicationName) {
+		errs.Add("application_name", "application_name must be at most 63 letters, digits, spaces or '._/-'")
+	}
+
 	if errs.HasErrors() {
 		return errs
 	}
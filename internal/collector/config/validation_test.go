@@ -3,6 +3,9 @@ package config
 import (
 	"strings"
 	"testing"
+	"time"
+
+	"go-metadata/internal/collector"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
@@ -446,6 +449,19 @@ func TestValidateInferConfig(t *testing.T) {
 			wantError: true,
 			errorMsg:  "type_merge",
 		},
+		{
+			name: "invalid sample_values mode",
+			cfg: &ConnectorConfig{
+				Type:     "mongodb",
+				Endpoint: "localhost:27017",
+				Infer: &InferConfig{
+					Enabled:      true,
+					SampleValues: "plain",
+				},
+			},
+			wantError: true,
+			errorMsg:  "sample_values",
+		},
 	}
 
 	for _, tt := range tests {
@@ -462,3 +478,109 @@ func TestValidateInferConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateSessionConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		session   *SessionConfig
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "valid session config",
+			session: &SessionConfig{
+				ReadReplica:      "replica:3306",
+				StatementTimeout: 30,
+				IsolationLevel:   IsolationReadUncommitted,
+				ApplicationName:  "metadata-catalog",
+			},
+			wantError: false,
+		},
+		{
+			name:      "invalid read_replica",
+			session:   &SessionConfig{ReadReplica: "replica:port"},
+			wantError: true,
+			errorMsg:  "read_replica",
+		},
+		{
+			name:      "replica_only without read_replica",
+			session:   &SessionConfig{ReplicaOnly: true},
+			wantError: true,
+			errorMsg:  "replica_only",
+		},
+		{
+			name:      "negative statement_timeout",
+			session:   &SessionConfig{StatementTimeout: -1},
+			wantError: true,
+			errorMsg:  "statement_timeout",
+		},
+		{
+			name:      "invalid isolation_level",
+			session:   &SessionConfig{IsolationLevel: "serializable"},
+			wantError: true,
+			errorMsg:  "isolation_level",
+		},
+		{
+			name:      "invalid application_name",
+			session:   &SessionConfig{ApplicationName: "go-metadata'; DROP TABLE t; --"},
+			wantError: true,
+			errorMsg:  "application_name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &ConnectorConfig{Type: "mysql", Endpoint: "localhost:3306", Session: tt.session}
+			err := cfg.Validate()
+			if (err != nil) != tt.wantError {
+				t.Errorf("Validate() error = %v, wantError %v", err, tt.wantError)
+			}
+			if tt.wantError && err != nil && !strings.Contains(err.Error(), "session."+tt.errorMsg) {
+				t.Errorf("Validate() error = %v, should contain %q", err, "session."+tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestTimeoutConfig(t *testing.T) {
+	timeouts := &TimeoutConfig{List: 60, Fetch: 30}
+	cfg := &ConnectorConfig{Type: "mysql", Endpoint: "localhost:3306", Timeouts: timeouts}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := timeouts.Duration(collector.TimeoutFetch); got != 30*time.Second {
+		t.Errorf("Duration(fetch) = %v, want 30s", got)
+	}
+	if got := timeouts.Duration(collector.TimeoutStats); got != 0 {
+		t.Errorf("Duration(stats) = %v, want 0", got)
+	}
+	if got := (*TimeoutConfig)(nil).Duration(collector.TimeoutList); got != 0 {
+		t.Errorf("nil Duration(list) = %v, want 0", got)
+	}
+
+	timeouts.Stats = -1
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "timeouts.stats") {
+		t.Errorf("Validate() error = %v, should contain %q", err, "timeouts.stats")
+	}
+}
+
+func TestConnectorConfig_Endpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		session *SessionConfig
+		want    []string
+	}{
+		{name: "no session", want: []string{"primary:5432"}},
+		{name: "prefer replica", session: &SessionConfig{ReadReplica: "replica:5432"}, want: []string{"replica:5432", "primary:5432"}},
+		{name: "replica only", session: &SessionConfig{ReadReplica: "replica:5432", ReplicaOnly: true}, want: []string{"replica:5432"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &ConnectorConfig{Endpoint: "primary:5432", Session: tt.session}
+			if got := cfg.Endpoints(); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Endpoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultSecretProvider 从 HashiCorp Vault KV 引擎 (v1 或 v2) 读取凭证。
+// 引用格式为 路径#字段，如 ${vault:secret/data/mysql/prod#password}，
+// KV v2 的路径包含 data 段
+type VaultSecretProvider struct {
+	// Address Vault 地址，如 https://vault:8200
+	Address string
+	// Token Vault 令牌
+	Token string
+	// Namespace Vault Enterprise 命名空间，可为空
+	Namespace  string
+	HTTPClient *http.Client
+}
+
+// NewVaultSecretProvider creates a provider reading secrets from the Vault
+// at address with token.
+func NewVaultSecretProvider(address, token string) *VaultSecretProvider {
+	return &VaultSecretProvider{
+		Address:    strings.TrimSuffix(address, "/"),
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// VaultFromEnv returns a provider reading secrets from the Vault configured
+// by the VAULT_ADDR, VAULT_TOKEN and VAULT_NAMESPACE environment variables
+// when a secret is resolved, as the Vault CLI does.
+func VaultFromEnv() SecretProvider {
+	return SecretProviderFunc(func(ctx context.Context, ref string) (string, error) {
+		address, token := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN")
+		if address == "" || token == "" {
+			return "", errors.New("VAULT_ADDR and VAULT_TOKEN must be set")
+		}
+		p := NewVaultSecretProvider(address, token)
+		p.Namespace = os.Getenv("VAULT_NAMESPACE")
+		return p.Resolve(ctx, ref)
+	})
+}
+
+// Resolve implements SecretProvider.
+func (p *VaultSecretProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	path = strings.Trim(path, "/")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("vault reference %q must be path#field", ref)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Address+"/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+	if p.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.Namespace)
+	}
+	client := p.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: vault path %s", ErrSecretNotFound, path)
+	}
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("vault read %s: status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	var out struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("vault read %s: %w", path, err)
+	}
+
+	// KV v2 nests the secret in data.data, KV v1 returns it in data
+	data := out.Data
+	if nested, ok := out.Data["data"]; ok {
+		var v2 map[string]json.RawMessage
+		if json.Unmarshal(nested, &v2) == nil {
+			if _, hasMetadata := out.Data["metadata"]; hasMetadata {
+				data = v2
+			}
+		}
+	}
+	raw, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("%w: vault path %s has no field %s", ErrSecretNotFound, path, field)
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		// Numbers and booleans are used as written
+		return string(raw), nil
+	}
+	return s, nil
+}
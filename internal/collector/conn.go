@@ -0,0 +1,70 @@
+package collector
+
+import "sync"
+
+// Conn holds the connection of a collector, such as a *sql.DB or a client,
+// so that a collector can be used from several goroutines at once: the
+// workers of a sync, a health check and the API may call its operations
+// concurrently with each other and with Connect and Close.
+//
+// Operations take the connection once with Get and use that handle to the
+// end, so a concurrent Close makes them fail with the errors of a closed
+// connection, never with a nil one:
+//
+//	db, ok := c.db.Get()
+//	if !ok {
+//		return nil, collector.NewConnectionClosedError(SourceName, "list_tables")
+//	}
+//
+// The zero Conn is closed.
+type Conn[T any] struct {
+	// connecting serializes Open and Close, so that concurrent Connects
+	// open a single connection
+	connecting sync.Mutex
+
+	mu   sync.RWMutex
+	conn T
+	open bool
+}
+
+// Get returns the connection, and false if it is not open.
+func (c *Conn[T]) Get() (T, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.conn, c.open
+}
+
+// Open opens the connection with connect unless it is open. Concurrent
+// calls wait for the first one rather than opening connections of their
+// own; operations are not blocked while connecting.
+func (c *Conn[T]) Open(connect func() (T, error)) error {
+	c.connecting.Lock()
+	defer c.connecting.Unlock()
+	if _, ok := c.Get(); ok {
+		return nil
+	}
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.conn, c.open = conn, true
+	c.mu.Unlock()
+	return nil
+}
+
+// Close marks the connection closed and releases it with release, if it is
+// open. Operations that already got the connection keep their handle.
+func (c *Conn[T]) Close(release func(T) error) error {
+	c.connecting.Lock()
+	defer c.connecting.Unlock()
+	c.mu.Lock()
+	conn, open := c.conn, c.open
+	var zero T
+	c.conn, c.open = zero, false
+	c.mu.Unlock()
+	if !open {
+		return nil
+	}
+	return release(conn)
+}
@@ -0,0 +1,86 @@
+package collector
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConn(t *testing.T) {
+	var c Conn[*int]
+	if _, ok := c.Get(); ok {
+		t.Fatal("zero Conn is open")
+	}
+
+	var opened atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := c.Open(func() (*int, error) {
+				opened.Add(1)
+				time.Sleep(10 * time.Millisecond)
+				n := 1
+				return &n, nil
+			})
+			if err != nil {
+				t.Errorf("Open() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := opened.Load(); n != 1 {
+		t.Errorf("opened %d connections, want 1", n)
+	}
+	conn, ok := c.Get()
+	if !ok || *conn != 1 {
+		t.Fatalf("Get() = %v, %t", conn, ok)
+	}
+
+	var released *int
+	if err := c.Close(func(p *int) error { released = p; return nil }); err != nil || released != conn {
+		t.Errorf("Close() released %v, error %v", released, err)
+	}
+	if _, ok := c.Get(); ok {
+		t.Error("Conn open after Close")
+	}
+	if err := c.Close(func(*int) error { return errors.New("closed twice") }); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+
+	failed := errors.New("refused")
+	if err := c.Open(func() (*int, error) { return nil, failed }); err != failed {
+		t.Errorf("Open() error = %v, want %v", err, failed)
+	}
+	if _, ok := c.Get(); ok {
+		t.Error("Conn open after a failed Open")
+	}
+}
+
+// TestConn_Race exercises Get, Open and Close concurrently; run with -race.
+func TestConn_Race(t *testing.T) {
+	var c Conn[*int]
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Open(func() (*int, error) { n := j; return &n, nil })
+				c.Close(func(*int) error { return nil })
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if p, ok := c.Get(); ok && p == nil {
+					t.Error("Get() returned a nil open connection")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
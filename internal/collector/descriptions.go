@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// Translator 可选的翻译钩子：同步时将表和列的注释翻译为数据源配置的其他语言，
+// 译文保存在 Descriptions 中
+type Translator interface {
+	// Translate 将 text 从语言 from 翻译为语言 to，语言为 BCP 47 标签，如 zh、en
+	Translate(ctx context.Context, text, from, to string) (string, error)
+}
+
+// TranslatorFunc adapts a function to a Translator.
+type TranslatorFunc func(ctx context.Context, text, from, to string) (string, error)
+
+// Translate calls f(ctx, text, from, to).
+func (f TranslatorFunc) Translate(ctx context.Context, text, from, to string) (string, error) {
+	return f(ctx, text, from, to)
+}
+
+// DetectLanguage returns the language of a comment: "ja" if it contains kana,
+// "ko" if it contains hangul, "zh" if it contains Han characters, "en" if it
+// contains other letters, or "" for comments without letters.
+func DetectLanguage(text string) string {
+	lang := ""
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			return "ja"
+		case unicode.Is(unicode.Hangul, r):
+			return "ko"
+		case unicode.Is(unicode.Han, r):
+			lang = "zh"
+		case lang == "" && unicode.IsLetter(r):
+			lang = "en"
+		}
+	}
+	return lang
+}
+
+// Description returns the description of a table or column in language lang:
+// the one of lang, or of its base language (zh for zh-CN), falling back to
+// the comment. The comment is returned if lang is "".
+func Description(comment string, descriptions map[string]string, lang string) string {
+	if lang == "" {
+		return comment
+	}
+	if d, ok := lookupLanguage(descriptions, lang); ok {
+		return d
+	}
+	if base, _, ok := strings.Cut(lang, "-"); ok {
+		if d, ok := lookupLanguage(descriptions, base); ok {
+			return d
+		}
+	}
+	return comment
+}
+
+// lookupLanguage returns the description of lang, matching language tags
+// case-insensitively.
+func lookupLanguage(descriptions map[string]string, lang string) (string, bool) {
+	if d, ok := descriptions[lang]; ok {
+		return d, true
+	}
+	for tag, d := range descriptions {
+		if strings.EqualFold(tag, lang) {
+			return d, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,43 @@
+package collector
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"用户订单表", "zh"},
+		{"订单 ID", "zh"},
+		{"order id", "en"},
+		{"注文テーブル", "ja"},
+		{"주문 테이블", "ko"},
+		{"2024-06-01", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.text); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDescription(t *testing.T) {
+	descriptions := map[string]string{"zh": "用户订单表", "en": "Customer orders", "pt-BR": "Pedidos"}
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"", "订单"},
+		{"en", "Customer orders"},
+		{"EN", "Customer orders"},
+		{"zh-CN", "用户订单表"},
+		{"pt-br", "Pedidos"},
+		{"fr", "订单"},
+	}
+	for _, tt := range tests {
+		if got := Description("订单", descriptions, tt.lang); got != tt.want {
+			t.Errorf("Description(%q) = %q, want %q", tt.lang, got, tt.want)
+		}
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"go-metadata/internal/collector/config"
 	"go-metadata/internal/collector/infer"
 	"go-metadata/internal/collector/matcher"
+	"go-metadata/internal/tlsprofile"
 
 	"github.com/elastic/go-elasticsearch/v8"
 )
@@ -33,7 +34,7 @@ const (
 // Collector Elasticsearch 元数据采集器
 type Collector struct {
 	config   *config.ConnectorConfig
-	client   *elasticsearch.Client
+	client   collector.Conn[*elasticsearch.Client]
 	inferrer *infer.DocumentInferrer
 }
 
@@ -50,10 +51,11 @@ func NewCollector(cfg *config.ConnectorConfig) (collector.Collector, error) {
 	var inferrer *infer.DocumentInferrer
 	if cfg.Infer != nil {
 		inferConfig := &infer.InferConfig{
-			Enabled:    cfg.Infer.Enabled,
-			SampleSize: cfg.Infer.SampleSize,
-			MaxDepth:   cfg.Infer.MaxDepth,
-			TypeMerge:  infer.TypeMergeStrategy(cfg.Infer.TypeMerge),
+			Enabled:      cfg.Infer.Enabled,
+			SampleSize:   cfg.Infer.SampleSize,
+			MaxDepth:     cfg.Infer.MaxDepth,
+			TypeMerge:    infer.TypeMergeStrategy(cfg.Infer.TypeMerge),
+			SampleValues: infer.SampleValueMode(cfg.Infer.SampleValues),
 		}
 		inferrer = infer.NewDocumentInferrerWithConfig(inferConfig)
 	} else {
@@ -68,13 +70,14 @@ func NewCollector(cfg *config.ConnectorConfig) (collector.Collector, error) {
 
 // Connect 建立 Elasticsearch 连接
 func (c *Collector) Connect(ctx context.Context) error {
-	if c.client != nil {
-		return nil // Already connected
-	}
+	return c.client.Open(func() (*elasticsearch.Client, error) { return c.open(ctx) })
+}
 
+// open 建立连接并检查连通性
+func (c *Collector) open(ctx context.Context) (*elasticsearch.Client, error) {
 	addresses, err := c.buildAddresses()
 	if err != nil {
-		return collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
+		return nil, collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
 	}
 
 	// Set connection timeout
@@ -88,6 +91,7 @@ func (c *Collector) Connect(ctx context.Context) error {
 		Addresses: addresses,
 		Transport: &http.Transport{
 			ResponseHeaderTimeout: time.Duration(timeout) * time.Second,
+			TLSClientConfig:       tlsprofile.Client(),
 		},
 	}
 
@@ -109,35 +113,33 @@ func (c *Collector) Connect(ctx context.Context) error {
 
 	client, err := elasticsearch.NewClient(cfg)
 	if err != nil {
-		return c.wrapConnectionError(err)
+		return nil, c.wrapConnectionError(err)
 	}
 
 	// Test connection with cluster info
 	res, err := client.Info(client.Info.WithContext(ctx))
 	if err != nil {
-		return c.wrapConnectionError(err)
+		return nil, c.wrapConnectionError(err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return c.wrapConnectionError(fmt.Errorf("elasticsearch connection failed: %s", res.Status()))
+		return nil, c.wrapConnectionError(fmt.Errorf("elasticsearch connection failed: %s", res.Status()))
 	}
 
-	c.client = client
-	return nil
+	return client, nil
 }
 
 // Close 关闭 Elasticsearch 连接
 func (c *Collector) Close() error {
-	if c.client != nil {
-		c.client = nil
-	}
-	return nil
+	// The Elasticsearch client holds no connection of its own to release
+	return c.client.Close(func(*elasticsearch.Client) error { return nil })
 }
 
 // HealthCheck 健康检查
 func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return &collector.HealthStatus{
 			Connected: false,
 			Message:   "not connected",
@@ -147,9 +149,9 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 	start := time.Now()
 
 	// Get cluster health
-	res, err := c.client.Cluster.Health(
-		c.client.Cluster.Health.WithContext(ctx),
-		c.client.Cluster.Health.WithLevel("cluster"),
+	res, err := client.Cluster.Health(
+		client.Cluster.Health.WithContext(ctx),
+		client.Cluster.Health.WithLevel("cluster"),
 	)
 	if err != nil {
 		return &collector.HealthStatus{
@@ -169,7 +171,7 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 	}
 
 	// Get cluster info for version
-	infoRes, err := c.client.Info(c.client.Info.WithContext(ctx))
+	infoRes, err := client.Info(client.Info.WithContext(ctx))
 	if err != nil {
 		return &collector.HealthStatus{
 			Connected: true,
@@ -200,12 +202,13 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 
 // DiscoverCatalogs 发现 Catalog（Elasticsearch 中 catalog 等同于 Elasticsearch 集群）
 func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogInfo, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "discover_catalogs")
 	}
 
 	// Get cluster info
-	res, err := c.client.Info(c.client.Info.WithContext(ctx))
+	res, err := client.Info(client.Info.WithContext(ctx))
 	if err != nil {
 		return nil, collector.NewQueryError(SourceName, "discover_catalogs", err)
 	}
@@ -246,7 +249,7 @@ func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogIn
 
 // ListSchemas 列出 Schema（Elasticsearch 中没有 schema 概念，返回空列表）
 func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string, error) {
-	if c.client == nil {
+	if _, ok := c.client.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_schemas")
 	}
 
@@ -256,7 +259,8 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 
 // ListTables 列出表（Elasticsearch 中表等同于 index）
 func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts *collector.ListOptions) (*collector.TableListResult, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_tables")
 	}
 
@@ -266,10 +270,10 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 	}
 
 	// Get indices using _cat/indices API
-	res, err := c.client.Cat.Indices(
-		c.client.Cat.Indices.WithContext(ctx),
-		c.client.Cat.Indices.WithFormat("json"),
-		c.client.Cat.Indices.WithH("index"),
+	res, err := client.Cat.Indices(
+		client.Cat.Indices.WithContext(ctx),
+		client.Cat.Indices.WithFormat("json"),
+		client.Cat.Indices.WithH("index"),
 	)
 	if err != nil {
 		if ctx.Err() != nil {
@@ -383,7 +387,8 @@ func (c *Collector) filterTables(tables []string, opts *collector.ListOptions) [
 
 // FetchTableMetadata 获取表元数据（含 Schema 推断）
 func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, table string) (*collector.TableMetadata, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_metadata")
 	}
 
@@ -393,7 +398,7 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 	}
 
 	// Check if index exists
-	res, err := c.client.Indices.Exists([]string{table}, c.client.Indices.Exists.WithContext(ctx))
+	res, err := client.Indices.Exists([]string{table}, client.Indices.Exists.WithContext(ctx))
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_table_metadata")
@@ -421,9 +426,9 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 	}
 
 	// Get mapping to extract field information
-	mappingRes, err := c.client.Indices.GetMapping(
-		c.client.Indices.GetMapping.WithContext(ctx),
-		c.client.Indices.GetMapping.WithIndex(table),
+	mappingRes, err := client.Indices.GetMapping(
+		client.Indices.GetMapping.WithContext(ctx),
+		client.Indices.GetMapping.WithIndex(table),
 	)
 	if err != nil {
 		if ctx.Err() != nil {
@@ -452,7 +457,7 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 			return nil, err
 		}
 
-		inferredColumns, err := c.inferSchema(ctx, table)
+		inferredColumns, err := c.inferSchema(ctx, client, table)
 		if err != nil {
 			return nil, err
 		}
@@ -468,7 +473,7 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 			return nil, err
 		}
 
-		settings, err := c.fetchIndexSettings(ctx, table)
+		settings, err := c.fetchIndexSettings(ctx, client, table)
 		if err != nil {
 			return nil, err
 		}
@@ -482,7 +487,8 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 
 // FetchTableStatistics 获取表统计信息
 func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, table string) (*collector.TableStatistics, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_statistics")
 	}
 
@@ -492,9 +498,9 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 	}
 
 	// Get document count using _count API
-	res, err := c.client.Count(
-		c.client.Count.WithContext(ctx),
-		c.client.Count.WithIndex(table),
+	res, err := client.Count(
+		client.Count.WithContext(ctx),
+		client.Count.WithIndex(table),
 	)
 	if err != nil {
 		if ctx.Err() != nil {
@@ -695,7 +701,7 @@ func (c *Collector) convertElasticsearchType(esType string) string {
 }
 
 // inferSchema infers schema from sample documents in an Elasticsearch index
-func (c *Collector) inferSchema(ctx context.Context, indexName string) ([]collector.Column, error) {
+func (c *Collector) inferSchema(ctx context.Context, client *elasticsearch.Client, indexName string) ([]collector.Column, error) {
 	// Check context before starting
 	if err := collector.CheckContext(ctx, SourceName, "infer_schema"); err != nil {
 		return nil, err
@@ -708,7 +714,7 @@ func (c *Collector) inferSchema(ctx context.Context, indexName string) ([]collec
 	}
 
 	// Sample documents using search API
-	samples, err := c.sampleDocuments(ctx, indexName, sampleSize)
+	samples, err := c.sampleDocuments(ctx, client, indexName, sampleSize)
 	if err != nil {
 		return nil, err
 	}
@@ -734,7 +740,7 @@ func (c *Collector) inferSchema(ctx context.Context, indexName string) ([]collec
 }
 
 // sampleDocuments samples documents from an Elasticsearch index
-func (c *Collector) sampleDocuments(ctx context.Context, indexName string, sampleSize int) ([]map[string]interface{}, error) {
+func (c *Collector) sampleDocuments(ctx context.Context, client *elasticsearch.Client, indexName string, sampleSize int) ([]map[string]interface{}, error) {
 	// Check context before starting
 	if err := collector.CheckContext(ctx, SourceName, "sample_documents"); err != nil {
 		return nil, err
@@ -754,10 +760,10 @@ func (c *Collector) sampleDocuments(ctx context.Context, indexName string, sampl
 	}
 
 	// Execute search
-	res, err := c.client.Search(
-		c.client.Search.WithContext(ctx),
-		c.client.Search.WithIndex(indexName),
-		c.client.Search.WithBody(strings.NewReader(string(queryBytes))),
+	res, err := client.Search(
+		client.Search.WithContext(ctx),
+		client.Search.WithIndex(indexName),
+		client.Search.WithBody(strings.NewReader(string(queryBytes))),
 	)
 	if err != nil {
 		if ctx.Err() != nil {
@@ -798,15 +804,15 @@ func (c *Collector) sampleDocuments(ctx context.Context, indexName string, sampl
 }
 
 // fetchIndexSettings retrieves index settings
-func (c *Collector) fetchIndexSettings(ctx context.Context, indexName string) (map[string]string, error) {
+func (c *Collector) fetchIndexSettings(ctx context.Context, client *elasticsearch.Client, indexName string) (map[string]string, error) {
 	// Check context before starting
 	if err := collector.CheckContext(ctx, SourceName, "fetch_index_settings"); err != nil {
 		return nil, err
 	}
 
-	res, err := c.client.Indices.GetSettings(
-		c.client.Indices.GetSettings.WithContext(ctx),
-		c.client.Indices.GetSettings.WithIndex(indexName),
+	res, err := client.Indices.GetSettings(
+		client.Indices.GetSettings.WithContext(ctx),
+		client.Indices.GetSettings.WithIndex(indexName),
 	)
 	if err != nil {
 		if ctx.Err() != nil {
@@ -854,11 +860,12 @@ func (c *Collector) fetchIndexSettings(ctx context.Context, indexName string) (m
 // SampleDocuments provides public access to document sampling for testing
 // This method is used by the DocumentDBCollector interface
 func (c *Collector) SampleDocuments(ctx context.Context, catalog, collection string, limit int) ([]map[string]interface{}, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "sample_documents")
 	}
 
-	return c.sampleDocuments(ctx, collection, limit)
+	return c.sampleDocuments(ctx, client, collection, limit)
 }
 
 // SetInferConfig updates the schema inference configuration
@@ -869,10 +876,11 @@ func (c *Collector) SetInferConfig(config *config.InferConfig) {
 	}
 
 	inferConfig := &infer.InferConfig{
-		Enabled:    config.Enabled,
-		SampleSize: config.SampleSize,
-		MaxDepth:   config.MaxDepth,
-		TypeMerge:  infer.TypeMergeStrategy(config.TypeMerge),
+		Enabled:      config.Enabled,
+		SampleSize:   config.SampleSize,
+		MaxDepth:     config.MaxDepth,
+		TypeMerge:    infer.TypeMergeStrategy(config.TypeMerge),
+		SampleValues: infer.SampleValueMode(config.SampleValues),
 	}
 	c.inferrer.SetConfig(inferConfig)
 }
@@ -13,6 +13,7 @@ import (
 	"go-metadata/internal/collector/config"
 	"go-metadata/internal/collector/infer"
 	"go-metadata/internal/collector/matcher"
+	"go-metadata/internal/tlsprofile"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -33,7 +34,7 @@ const (
 // Collector MongoDB 元数据采集器
 type Collector struct {
 	config   *config.ConnectorConfig
-	client   *mongo.Client
+	client   collector.Conn[*mongo.Client]
 	inferrer *infer.DocumentInferrer
 }
 
@@ -50,10 +51,11 @@ func NewCollector(cfg *config.ConnectorConfig) (collector.Collector, error) {
 	var inferrer *infer.DocumentInferrer
 	if cfg.Infer != nil {
 		inferConfig := &infer.InferConfig{
-			Enabled:    cfg.Infer.Enabled,
-			SampleSize: cfg.Infer.SampleSize,
-			MaxDepth:   cfg.Infer.MaxDepth,
-			TypeMerge:  infer.TypeMergeStrategy(cfg.Infer.TypeMerge),
+			Enabled:      cfg.Infer.Enabled,
+			SampleSize:   cfg.Infer.SampleSize,
+			MaxDepth:     cfg.Infer.MaxDepth,
+			TypeMerge:    infer.TypeMergeStrategy(cfg.Infer.TypeMerge),
+			SampleValues: infer.SampleValueMode(cfg.Infer.SampleValues),
 		}
 		inferrer = infer.NewDocumentInferrerWithConfig(inferConfig)
 	} else {
@@ -68,13 +70,14 @@ func NewCollector(cfg *config.ConnectorConfig) (collector.Collector, error) {
 
 // Connect 建立 MongoDB 连接
 func (c *Collector) Connect(ctx context.Context) error {
-	if c.client != nil {
-		return nil // Already connected
-	}
+	return c.client.Open(func() (*mongo.Client, error) { return c.open(ctx) })
+}
 
+// open 建立连接并检查连通性
+func (c *Collector) open(ctx context.Context) (*mongo.Client, error) {
 	uri, err := c.buildURI()
 	if err != nil {
-		return collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
+		return nil, collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
 	}
 
 	// Set connection timeout
@@ -88,6 +91,11 @@ func (c *Collector) Connect(ctx context.Context) error {
 		SetConnectTimeout(time.Duration(timeout) * time.Second).
 		SetServerSelectionTimeout(time.Duration(timeout) * time.Second)
 
+	// Apply the outbound TLS profile when the URI enables tls
+	if clientOptions.TLSConfig != nil {
+		clientOptions.SetTLSConfig(tlsprofile.Restrict(clientOptions.TLSConfig))
+	}
+
 	// Configure connection pool
 	if c.config.Properties.MaxOpenConns > 0 {
 		clientOptions.SetMaxPoolSize(uint64(c.config.Properties.MaxOpenConns))
@@ -101,34 +109,31 @@ func (c *Collector) Connect(ctx context.Context) error {
 
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		return c.wrapConnectionError(err)
+		return nil, c.wrapConnectionError(err)
 	}
 
 	// Test connection with ping
 	if err := client.Ping(ctx, nil); err != nil {
 		client.Disconnect(ctx)
-		return c.wrapConnectionError(err)
+		return nil, c.wrapConnectionError(err)
 	}
 
-	c.client = client
-	return nil
+	return client, nil
 }
 
 // Close 关闭 MongoDB 连接
 func (c *Collector) Close() error {
-	if c.client != nil {
+	return c.client.Close(func(client *mongo.Client) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
-		err := c.client.Disconnect(ctx)
-		c.client = nil
-		return err
-	}
-	return nil
+		return client.Disconnect(ctx)
+	})
 }
 
 // HealthCheck 健康检查
 func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return &collector.HealthStatus{
 			Connected: false,
 			Message:   "not connected",
@@ -138,7 +143,7 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 	start := time.Now()
 
 	// Ping to check connection
-	if err := c.client.Ping(ctx, nil); err != nil {
+	if err := client.Ping(ctx, nil); err != nil {
 		return &collector.HealthStatus{
 			Connected: false,
 			Latency:   time.Since(start),
@@ -148,7 +153,7 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 
 	// Get MongoDB version
 	var result bson.M
-	err := c.client.Database("admin").RunCommand(ctx, bson.D{{"buildInfo", 1}}).Decode(&result)
+	err := client.Database("admin").RunCommand(ctx, bson.D{{"buildInfo", 1}}).Decode(&result)
 	if err != nil {
 		return &collector.HealthStatus{
 			Connected: true,
@@ -171,13 +176,14 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 
 // DiscoverCatalogs 发现 Catalog（MongoDB 中 catalog 等同于 MongoDB 实例）
 func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogInfo, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "discover_catalogs")
 	}
 
 	// Get MongoDB version and other info for catalog
 	var result bson.M
-	err := c.client.Database("admin").RunCommand(ctx, bson.D{{"buildInfo", 1}}).Decode(&result)
+	err := client.Database("admin").RunCommand(ctx, bson.D{{"buildInfo", 1}}).Decode(&result)
 	if err != nil {
 		return nil, collector.NewQueryError(SourceName, "discover_catalogs", err)
 	}
@@ -202,7 +208,8 @@ func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogIn
 
 // ListSchemas 列出 Schema（MongoDB 中 schema 等同于 database）
 func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_schemas")
 	}
 
@@ -211,7 +218,7 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 		return nil, err
 	}
 
-	databases, err := c.client.ListDatabaseNames(ctx, bson.D{})
+	databases, err := client.ListDatabaseNames(ctx, bson.D{})
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "list_schemas")
@@ -243,7 +250,8 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 
 // ListTables 列出表（MongoDB 中表等同于 collection）
 func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts *collector.ListOptions) (*collector.TableListResult, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_tables")
 	}
 
@@ -252,7 +260,7 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 		return nil, err
 	}
 
-	db := c.client.Database(schema)
+	db := client.Database(schema)
 	collections, err := db.ListCollectionNames(ctx, bson.D{})
 	if err != nil {
 		if ctx.Err() != nil {
@@ -346,7 +354,8 @@ func (c *Collector) filterTables(tables []string, opts *collector.ListOptions) [
 
 // FetchTableMetadata 获取表元数据（含 Schema 推断）
 func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, table string) (*collector.TableMetadata, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_metadata")
 	}
 
@@ -355,7 +364,7 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 		return nil, err
 	}
 
-	db := c.client.Database(schema)
+	db := client.Database(schema)
 	collection := db.Collection(table)
 
 	// Check if collection exists
@@ -414,7 +423,8 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 
 // FetchTableStatistics 获取表统计信息
 func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, table string) (*collector.TableStatistics, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_statistics")
 	}
 
@@ -423,7 +433,7 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 		return nil, err
 	}
 
-	db := c.client.Database(schema)
+	db := client.Database(schema)
 	collection := db.Collection(table)
 
 	// Get document count
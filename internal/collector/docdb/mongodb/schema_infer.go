@@ -141,11 +141,12 @@ func (c *Collector) getQueryTimeout() *int64 {
 // SampleDocuments provides public access to document sampling for testing
 // This method is used by the DocumentDBCollector interface
 func (c *Collector) SampleDocuments(ctx context.Context, catalog, collection string, limit int) ([]map[string]interface{}, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "sample_documents")
 	}
 
-	db := c.client.Database(catalog)
+	db := client.Database(catalog)
 	coll := db.Collection(collection)
 
 	return c.sampleDocuments(ctx, coll, limit)
@@ -159,10 +160,11 @@ func (c *Collector) SetInferConfig(config *config.InferConfig) {
 	}
 
 	inferConfig := &infer.InferConfig{
-		Enabled:    config.Enabled,
-		SampleSize: config.SampleSize,
-		MaxDepth:   config.MaxDepth,
-		TypeMerge:  infer.TypeMergeStrategy(config.TypeMerge),
+		Enabled:      config.Enabled,
+		SampleSize:   config.SampleSize,
+		MaxDepth:     config.MaxDepth,
+		TypeMerge:    infer.TypeMergeStrategy(config.TypeMerge),
+		SampleValues: infer.SampleValueMode(config.SampleValues),
 	}
 	c.inferrer.SetConfig(inferConfig)
 }
\ No newline at end of file
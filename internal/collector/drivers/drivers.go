@@ -29,6 +29,8 @@ import (
 	_ "go-metadata/internal/collector/warehouse/clickhouse"
 	_ "go-metadata/internal/collector/warehouse/doris"
 	_ "go-metadata/internal/collector/warehouse/hive"
+	_ "go-metadata/internal/collector/warehouse/impala"
+	_ "go-metadata/internal/collector/warehouse/trino"
 	
 	// DocumentDB collectors
 	_ "go-metadata/internal/collector/docdb/elasticsearch"
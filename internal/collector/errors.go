@@ -3,6 +3,9 @@ package collector
 import (
 	"errors"
 	"fmt"
+	"time"
+
+	"go-metadata/internal/redact"
 )
 
 // ErrorCode 错误码
@@ -35,6 +38,8 @@ const (
 	ErrCodeDeadlineExceeded ErrorCode = "DEADLINE_EXCEEDED"
 	// ErrCodeInferenceError Schema 推断错误
 	ErrCodeInferenceError ErrorCode = "INFERENCE_ERROR"
+	// ErrCodeInternal 采集器内部错误 (如驱动 panic)
+	ErrCodeInternal ErrorCode = "INTERNAL"
 )
 
 // CollectorError 采集器错误
@@ -46,14 +51,28 @@ type CollectorError struct {
 	Operation string             `json:"operation"` // connect, list_tables, etc.
 	Cause     error              `json:"-"`
 	Retryable bool               `json:"retryable"`
+	Timeout   TimeoutKind        `json:"timeout,omitempty"` // 超出的同步操作超时类型
 }
 
+// TimeoutKind 同步操作超时类型
+type TimeoutKind string
+
+const (
+	// TimeoutList 列出 catalog、schema 与表
+	TimeoutList TimeoutKind = "list"
+	// TimeoutFetch 获取表元数据
+	TimeoutFetch TimeoutKind = "fetch"
+	// TimeoutStats 获取表统计信息
+	TimeoutStats TimeoutKind = "stats"
+)
+
 // Error 实现 error 接口
+// 驱动错误可能包含连接串，消息中的密码等凭证会被屏蔽
 func (e *CollectorError) Error() string {
 	if e.Cause != nil {
-		return fmt.Sprintf("[%s] %s: %s (category=%s, source=%s, operation=%s)", e.Code, e.Message, e.Cause.Error(), e.Category, e.Source, e.Operation)
+		return redact.String(fmt.Sprintf("[%s] %s: %s (category=%s, source=%s, operation=%s)", e.Code, e.Message, e.Cause.Error(), e.Category, e.Source, e.Operation))
 	}
-	return fmt.Sprintf("[%s] %s (category=%s, source=%s, operation=%s)", e.Code, e.Message, e.Category, e.Source, e.Operation)
+	return redact.String(fmt.Sprintf("[%s] %s (category=%s, source=%s, operation=%s)", e.Code, e.Message, e.Category, e.Source, e.Operation))
 }
 
 // Unwrap 返回原始错误，支持 errors.Unwrap
@@ -141,6 +160,14 @@ func NewTimeoutErrorWithCategory(category DataSourceCategory, source, operation
 	}
 }
 
+// NewOperationTimeoutError 创建同步操作超出 kind 类型超时 limit 的错误
+func NewOperationTimeoutError(source, operation string, kind TimeoutKind, limit time.Duration, cause error) *CollectorError {
+	err := NewTimeoutError(source, operation, cause)
+	err.Message = fmt.Sprintf("%s timeout of %s exceeded", kind, limit)
+	err.Timeout = kind
+	return err
+}
+
 // NewNotFoundError 创建资源未找到错误
 func NewNotFoundError(source, operation, resource string, cause error) *CollectorError {
 	return NewNotFoundErrorWithCategory(GetCategoryByType(source), source, operation, resource, cause)
@@ -267,6 +294,29 @@ func NewPermissionDeniedErrorWithCategory(category DataSourceCategory, source, o
 	}
 }
 
+// PanicError 采集器调用中恢复的 panic, 保留 panic 的值和堆栈
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// NewPanicError 创建由 panic 转换的内部错误
+// resource 为出错的对象 (如 source:catalog.schema.table), value 和 stack 为恢复的 panic 及其堆栈
+func NewPanicError(source, operation, resource string, value interface{}, stack []byte) *CollectorError {
+	return &CollectorError{
+		Code:      ErrCodeInternal,
+		Message:   fmt.Sprintf("collector panicked on %s", resource),
+		Source:    source,
+		Operation: operation,
+		Cause:     &PanicError{Value: value, Stack: stack},
+		Retryable: false,
+	}
+}
+
 // IsRetryable 检查错误是否可重试
 func IsRetryable(err error) bool {
 	var collErr *CollectorError
@@ -285,6 +335,15 @@ func GetErrorCode(err error) ErrorCode {
 	return ""
 }
 
+// GetTimeoutKind 获取超出的同步操作超时类型，其他错误返回空
+func GetTimeoutKind(err error) TimeoutKind {
+	var collErr *CollectorError
+	if errors.As(err, &collErr) {
+		return collErr.Timeout
+	}
+	return ""
+}
+
 // GetErrorCategory 获取错误类别
 func GetErrorCategory(err error) DataSourceCategory {
 	var collErr *CollectorError
@@ -1,8 +1,11 @@
 package collector
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
@@ -318,6 +321,55 @@ func TestErrorTypingConsistency(t *testing.T) {
 	properties.TestingRun(t)
 }
 
+func TestOperationTimeoutError(t *testing.T) {
+	cause := NewDeadlineExceededError("mysql", "fetch_table_metadata", context.DeadlineExceeded)
+	err := fmt.Errorf("shop.orders: %w", NewOperationTimeoutError("mysql", "fetch_table_metadata", TimeoutFetch, 30*time.Second, cause))
+
+	if GetErrorCode(err) != ErrCodeTimeout || GetTimeoutKind(err) != TimeoutFetch {
+		t.Errorf("Unexpected code %s and kind %s", GetErrorCode(err), GetTimeoutKind(err))
+	}
+	if !IsRetryable(err) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a retryable error wrapping the deadline, got %v", err)
+	}
+	if !containsString(err.Error(), "fetch timeout of 30s exceeded") {
+		t.Errorf("Expected the timeout in the message, got %v", err)
+	}
+	if GetTimeoutKind(NewTimeoutError("mysql", "connect", nil)) != "" {
+		t.Error("Expected no timeout kind for a plain timeout error")
+	}
+}
+
+func TestPanicError(t *testing.T) {
+	err := NewPanicError("mysql", "fetch_table_metadata", "prod:def.shop.orders", "index out of range", []byte("goroutine 1"))
+
+	if GetErrorCode(err) != ErrCodeInternal || IsRetryable(err) {
+		t.Errorf("Expected a non-retryable INTERNAL error, got %v", err)
+	}
+	var perr *PanicError
+	if !errors.As(err, &perr) || perr.Value != "index out of range" || string(perr.Stack) != "goroutine 1" {
+		t.Fatalf("Expected the recovered panic as cause, got %#v", err.Cause)
+	}
+	if !containsString(err.Error(), "collector panicked on prod:def.shop.orders: panic: index out of range") {
+		t.Errorf("Expected the resource and panic in the message, got %v", err)
+	}
+}
+
+func TestCollectorError_RedactsCredentials(t *testing.T) {
+	errs := []error{
+		NewNetworkError("mysql", "connect", errors.New("invalid DSN: root:hunter2@tcp(db:3306)/shop?timeout=10s")),
+		NewAuthError("postgres", "connect", errors.New("host=pg port=5432 user=app password=hunter2 dbname=shop")),
+		NewInvalidConfigError("clickhouse", "endpoint", `parse "clickhouse://default:hunter2@ch:9000": invalid port`),
+	}
+	for _, err := range errs {
+		if containsString(err.Error(), "hunter2") {
+			t.Errorf("Expected the password to be masked, got %v", err)
+		}
+		if !containsString(err.Error(), "***") {
+			t.Errorf("Expected a mask in %v", err)
+		}
+	}
+}
+
 // containsString checks if s contains substr.
 func containsString(s, substr string) bool {
 	return len(substr) == 0 || (len(s) >= len(substr) && findSubstring(s, substr))
@@ -0,0 +1,128 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ExampleCollector 可选接口：读取表的少量行，采集列的示例值
+type ExampleCollector interface {
+	// FetchExamples 返回各列至多 limit 个不同的非空示例值，键为列名。
+	// 只读取 ExampleSampleRows 行，不扫描全表
+	FetchExamples(ctx context.Context, catalog, schema, table string, columns []string, limit int) (map[string][]string, error)
+}
+
+// ExampleSampleRows is the number of rows read to pick the examples of the
+// columns of a table.
+const ExampleSampleRows = 100
+
+// piiWords are the words of column names classified as personal data, whose
+// values are never captured as examples.
+var piiWords = map[string]bool{
+	"email": true, "mail": true, "phone": true, "mobile": true, "tel": true, "telephone": true,
+	"ssn": true, "passport": true, "idcard": true, "password": true, "passwd": true, "pwd": true,
+	"secret": true, "token": true, "address": true, "addr": true, "birthday": true, "dob": true,
+	"firstname": true, "lastname": true, "username": true, "fullname": true, "realname": true, "surname": true,
+	"iban": true, "card": true, "cvv": true, "salary": true, "ip": true,
+}
+
+// piiPairs are pairs of consecutive words of column names classified as
+// personal data, e.g. first_name or id_card.
+var piiPairs = map[string]bool{
+	"first name": true, "last name": true, "full name": true, "real name": true, "user name": true,
+	"id card": true, "id number": true, "birth date": true, "date birth": true, "credit card": true,
+	"bank account": true, "ip address": true,
+}
+
+// IsPII reports whether a column is classified as personal data by its name,
+// e.g. email, user_phone or firstName, or by the patterns of exclude
+// (case-insensitive globs such as *_token).
+func IsPII(column string, exclude []string) bool {
+	lower := strings.ToLower(column)
+	for _, pattern := range exclude {
+		if ok, _ := path.Match(strings.ToLower(strings.TrimSpace(pattern)), lower); ok {
+			return true
+		}
+	}
+	words := nameWords(column)
+	for i, w := range words {
+		if piiWords[w] || i > 0 && piiPairs[words[i-1]+" "+w] {
+			return true
+		}
+	}
+	return false
+}
+
+// nameWords splits a column name into lower-case words on underscores,
+// digits and case changes.
+func nameWords(name string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	return words
+}
+
+// TruncateExample shortens an example value to at most maxLength characters,
+// marking the cut with "…". Values are kept whole if maxLength is not
+// positive.
+func TruncateExample(value string, maxLength int) string {
+	if maxLength <= 0 || utf8.RuneCountInString(value) <= maxLength {
+		return value
+	}
+	return string([]rune(value)[:maxLength]) + "…"
+}
+
+// ScanExamples reads the examples of columns from rows selecting them in
+// order: up to limit distinct non-null values per column, in the order they
+// are read. Binary values are skipped.
+func ScanExamples(rows *sql.Rows, columns []string, limit int) (map[string][]string, error) {
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	examples := make(map[string][]string, len(columns))
+	seen := make([]map[string]bool, len(columns))
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan examples: %w", err)
+		}
+		for i, v := range values {
+			if v == nil || len(examples[columns[i]]) >= limit || !utf8.Valid(v) {
+				continue
+			}
+			s := string(v)
+			if seen[i] == nil {
+				seen[i] = make(map[string]bool)
+			}
+			if !seen[i][s] {
+				seen[i][s] = true
+				examples[columns[i]] = append(examples[columns[i]], s)
+			}
+		}
+	}
+	return examples, rows.Err()
+}
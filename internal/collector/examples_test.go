@@ -0,0 +1,48 @@
+package collector
+
+import "testing"
+
+func TestIsPII(t *testing.T) {
+	tests := []struct {
+		column  string
+		exclude []string
+		want    bool
+	}{
+		{"email", nil, true},
+		{"user_phone", nil, true},
+		{"firstName", nil, true},
+		{"FIRST_NAME", nil, true},
+		{"id_card_no", nil, true},
+		{"billing_address2", nil, true},
+		{"zip", nil, false},
+		{"name", nil, false},
+		{"product_name", nil, false},
+		{"amount", nil, false},
+		{"refresh_tkn", []string{"*_TKN"}, true},
+		{"notes", []string{"*_tkn", " notes "}, true},
+		{"status", []string{"*_tkn"}, false},
+	}
+	for _, tt := range tests {
+		if got := IsPII(tt.column, tt.exclude); got != tt.want {
+			t.Errorf("IsPII(%q, %v) = %v, want %v", tt.column, tt.exclude, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateExample(t *testing.T) {
+	tests := []struct {
+		value     string
+		maxLength int
+		want      string
+	}{
+		{"shanghai", 10, "shanghai"},
+		{"shanghai", 5, "shang…"},
+		{"上海市浦东新区", 3, "上海市…"},
+		{"shanghai", 0, "shanghai"},
+	}
+	for _, tt := range tests {
+		if got := TruncateExample(tt.value, tt.maxLength); got != tt.want {
+			t.Errorf("TruncateExample(%q, %d) = %q, want %q", tt.value, tt.maxLength, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoFreshness is returned when a table has neither a date-style latest
+// partition nor a date or timestamp column with a max statistic.
+var ErrNoFreshness = errors.New("no date partition or timestamp statistic to check freshness")
+
+// Freshness bases
+const (
+	FreshnessBasisPartition = "partition"
+	FreshnessBasisStatistic = "statistic"
+)
+
+// FreshnessOptions configures a freshness check.
+type FreshnessOptions struct {
+	// Cadence is the expected interval between loads of the table, e.g. 24h
+	// for a table loaded daily.
+	Cadence time.Duration
+	// Grace is the delay tolerated on top of Cadence.
+	Grace time.Duration
+	// Column is the date or timestamp column whose max statistic is checked
+	// when the table has no date-style partitions. By default the column
+	// with the latest max is used.
+	Column string
+	// Location is the time zone of partition values and timestamps without
+	// one; UTC if nil.
+	Location *time.Location
+}
+
+// Freshness is the result of a freshness check.
+type Freshness struct {
+	// Basis is what the latest data time was derived from: the latest
+	// partition or the max statistic of a column.
+	Basis     string `json:"basis"`
+	Partition string `json:"partition,omitempty"`
+	Column    string `json:"column,omitempty"`
+	// Latest is the time up to which the table holds data: the end of the
+	// period of the latest partition, e.g. the end of its day, or the max
+	// timestamp.
+	Latest time.Time     `json:"latest"`
+	Age    time.Duration `json:"age"`
+	// Deadline is the time the table is stale at without a newer load.
+	Deadline time.Time `json:"deadline"`
+	Stale    bool      `json:"stale"`
+}
+
+// CheckFreshness checks whether a table was loaded within its expected
+// cadence. The latest date-style partition of table.Partitions is checked
+// first; tables without one are checked with the max statistic of a date or
+// timestamp column of table.Stats. A table is stale when more than cadence
+// plus grace has passed since its latest data.
+func CheckFreshness(table *TableMetadata, now time.Time, opts FreshnessOptions) (*Freshness, error) {
+	if opts.Cadence <= 0 {
+		return nil, fmt.Errorf("cadence must be positive")
+	}
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var f *Freshness
+	if opts.Column == "" {
+		f = partitionFreshness(table.Partitions, loc)
+	}
+	if f == nil {
+		var err error
+		if f, err = statisticFreshness(table, opts.Column, loc); err != nil {
+			return nil, err
+		}
+	}
+
+	f.Age = now.Sub(f.Latest)
+	f.Deadline = f.Latest.Add(opts.Cadence + opts.Grace)
+	f.Stale = now.After(f.Deadline)
+	return f, nil
+}
+
+// partitionFreshness returns the freshness of the latest date-style
+// partition, or nil if there is none.
+func partitionFreshness(partitions []PartitionInfo, loc *time.Location) *Freshness {
+	var f *Freshness
+	for _, p := range partitions {
+		if p.Latest == "" {
+			continue
+		}
+		end, ok := ParsePartitionTime(p.Latest, loc)
+		if !ok {
+			continue
+		}
+		if f == nil || end.After(f.Latest) {
+			f = &Freshness{Basis: FreshnessBasisPartition, Partition: p.Latest, Latest: end}
+		}
+	}
+	return f
+}
+
+// statisticFreshness returns the freshness of the max statistic of column,
+// or of the date or timestamp column with the latest max if column is empty.
+func statisticFreshness(table *TableMetadata, column string, loc *time.Location) (*Freshness, error) {
+	if table.Stats == nil {
+		return nil, ErrNoFreshness
+	}
+	types := make(map[string]string, len(table.Columns))
+	for _, c := range table.Columns {
+		types[strings.ToLower(c.Name)] = c.Type
+	}
+
+	var f *Freshness
+	for _, s := range table.Stats.ColumnStats {
+		if column != "" && !strings.EqualFold(s.Name, column) {
+			continue
+		}
+		if column == "" && !isTimeType(types[strings.ToLower(s.Name)]) {
+			continue
+		}
+		latest, ok := statisticTime(s.Max, loc)
+		if !ok {
+			if column != "" {
+				return nil, fmt.Errorf("column %s has no date or timestamp max statistic", column)
+			}
+			continue
+		}
+		if f == nil || latest.After(f.Latest) {
+			f = &Freshness{Basis: FreshnessBasisStatistic, Column: s.Name, Latest: latest}
+		}
+	}
+	if f == nil {
+		if column != "" {
+			return nil, fmt.Errorf("column %s has no statistics", column)
+		}
+		return nil, ErrNoFreshness
+	}
+	return f, nil
+}
+
+// isTimeType reports whether a column type holds dates or timestamps.
+func isTimeType(typ string) bool {
+	typ = strings.ToUpper(typ)
+	return strings.HasPrefix(typ, "DATE") || strings.HasPrefix(typ, "TIMESTAMP")
+}
+
+// statisticTime returns the time of a max statistic: a time or a date or
+// timestamp string. A date covers its whole day.
+func statisticTime(v any, loc *time.Location) (time.Time, bool) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, !v.IsZero()
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return *v, !v.IsZero()
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t, true
+		}
+		return parseTimeValue(v, loc)
+	case []byte:
+		return statisticTime(string(v), loc)
+	}
+	return time.Time{}, false
+}
+
+// dateLayout is the layout of date-style values with the end of the period
+// a value covers, nil for timestamps.
+type dateLayout struct {
+	layout string
+	period func(time.Time) time.Time
+	daily  bool
+}
+
+// dateLayouts are the layouts of date-style partition values, finest first.
+var dateLayouts = []dateLayout{
+	{"2006-01-02 15:04:05.999999999", nil, false},
+	{"2006-01-02T15:04:05.999999999", nil, false},
+	{"2006-01-02 15:04", addMinute, false},
+	{"2006-01-02-15", addHour, false},
+	{"2006010215", addHour, false},
+	{"2006-01-02", addDay, true},
+	{"2006/01/02", addDay, true},
+	{"20060102", addDay, true},
+	{"2006-01", addMonth, false},
+}
+
+func addMinute(t time.Time) time.Time { return t.Add(time.Minute) }
+func addHour(t time.Time) time.Time   { return t.Add(time.Hour) }
+func addDay(t time.Time) time.Time    { return t.AddDate(0, 0, 1) }
+func addMonth(t time.Time) time.Time  { return t.AddDate(0, 1, 0) }
+
+// parseTimeValue parses a date-style value, returning the end of the period
+// it covers: the next day for 2024-01-15, the value itself for a timestamp.
+func parseTimeValue(v string, loc *time.Location) (time.Time, bool) {
+	start, l, ok := parseDateValue(v, loc)
+	if !ok {
+		return time.Time{}, false
+	}
+	if l.period != nil {
+		return l.period(start), true
+	}
+	return start, true
+}
+
+// parseDateValue parses a date-style value, returning its time and layout.
+func parseDateValue(v string, loc *time.Location) (time.Time, dateLayout, bool) {
+	for _, l := range dateLayouts {
+		if t, err := time.ParseInLocation(l.layout, v, loc); err == nil {
+			return t, l, true
+		}
+	}
+	return time.Time{}, dateLayout{}, false
+}
+
+// ParsePartitionTime returns the end of the period covered by a date-style
+// Hive partition spec such as dt=2024-01-15/hour=08 or
+// year=2024/month=01/day=15: the first value parsing as a date, refined by
+// an hour column, or else the year, month, day and hour columns. Values are
+// interpreted in loc.
+func ParsePartitionTime(spec string, loc *time.Location) (time.Time, bool) {
+	var date time.Time
+	var layout dateLayout
+	found := false
+	fields := make(map[string]int)
+	for _, part := range strings.Split(spec, "/") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		if v, err := url.PathUnescape(value); err == nil {
+			value = v
+		}
+		if !found {
+			if date, layout, found = parseDateValue(value, loc); found {
+				continue
+			}
+		}
+		if n, err := strconv.Atoi(value); err == nil {
+			fields[strings.ToLower(key)] = n
+		}
+	}
+
+	hour, hasHour := fields["hour"]
+	if !hasHour {
+		hour, hasHour = fields["hr"]
+	}
+	hasHour = hasHour && hour >= 0 && hour <= 23
+
+	if found {
+		switch {
+		case layout.period == nil:
+			return date, true
+		case layout.daily && hasHour:
+			return date.Add(time.Duration(hour+1) * time.Hour), true
+		}
+		return layout.period(date), true
+	}
+
+	year, hasYear := fields["year"]
+	if !hasYear || year <= 0 {
+		return time.Time{}, false
+	}
+	month, hasMonth := fields["month"]
+	day, hasDay := fields["day"]
+	switch {
+	case !hasMonth:
+		return time.Date(year+1, 1, 1, 0, 0, 0, 0, loc), true
+	case month < 1 || month > 12:
+		return time.Time{}, false
+	case !hasDay:
+		return time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, loc), true
+	case day < 1 || day > 31:
+		return time.Time{}, false
+	case hasHour:
+		return time.Date(year, time.Month(month), day, hour+1, 0, 0, 0, loc), true
+	}
+	return time.Date(year, time.Month(month), day+1, 0, 0, 0, 0, loc), true
+}
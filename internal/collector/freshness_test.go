@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParsePartitionTime(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"dt=2024-01-15", "2024-01-16T00:00:00Z"},
+		{"dt=20240115", "2024-01-16T00:00:00Z"},
+		{"dt=2024-01-15/hour=08", "2024-01-15T09:00:00Z"},
+		{"ds=2024-01-15/region=eu", "2024-01-16T00:00:00Z"},
+		{"region=eu/dt=2024-01-15", "2024-01-16T00:00:00Z"},
+		{"dt=2024011508", "2024-01-15T09:00:00Z"},
+		{"ts=2024-01-15 10%3A30%3A00", "2024-01-15T10:30:00Z"},
+		{"month=2024-01", "2024-02-01T00:00:00Z"},
+		{"year=2024/month=01/day=15", "2024-01-16T00:00:00Z"},
+		{"year=2024/month=12/day=31/hour=23", "2025-01-01T00:00:00Z"},
+		{"year=2024/month=12", "2025-01-01T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		got, ok := ParsePartitionTime(tt.spec, time.UTC)
+		if !ok {
+			t.Errorf("ParsePartitionTime(%q) failed", tt.spec)
+			continue
+		}
+		if s := got.Format(time.RFC3339); s != tt.want {
+			t.Errorf("ParsePartitionTime(%q) = %s, want %s", tt.spec, s, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"region=eu", "bucket=7", "year=2024/month=13", ""} {
+		if got, ok := ParsePartitionTime(spec, time.UTC); ok {
+			t.Errorf("ParsePartitionTime(%q) = %s, want no time", spec, got)
+		}
+	}
+}
+
+func TestCheckFreshness_Partition(t *testing.T) {
+	table := &TableMetadata{Partitions: []PartitionInfo{{Name: "partitions", Columns: []string{"dt"}, Latest: "dt=2024-01-15"}}}
+	opts := FreshnessOptions{Cadence: 24 * time.Hour, Grace: 2 * time.Hour}
+
+	// The partition of the 16th is due on the 17th, 02:00 with the grace
+	f, err := CheckFreshness(table, time.Date(2024, 1, 17, 1, 0, 0, 0, time.UTC), opts)
+	if err != nil {
+		t.Fatalf("CheckFreshness() error = %v", err)
+	}
+	if f.Stale || f.Basis != FreshnessBasisPartition || f.Partition != "dt=2024-01-15" {
+		t.Errorf("CheckFreshness() = %+v, want a fresh partition", f)
+	}
+	if want := time.Date(2024, 1, 17, 2, 0, 0, 0, time.UTC); !f.Deadline.Equal(want) {
+		t.Errorf("Deadline = %s, want %s", f.Deadline, want)
+	}
+	if f.Age != 25*time.Hour {
+		t.Errorf("Age = %s, want 25h", f.Age)
+	}
+
+	f, err = CheckFreshness(table, time.Date(2024, 1, 17, 3, 0, 0, 0, time.UTC), opts)
+	if err != nil {
+		t.Fatalf("CheckFreshness() error = %v", err)
+	}
+	if !f.Stale {
+		t.Errorf("CheckFreshness() = %+v, want stale", f)
+	}
+}
+
+func TestCheckFreshness_Statistic(t *testing.T) {
+	table := &TableMetadata{
+		Columns: []Column{
+			{Name: "id", Type: "BIGINT"},
+			{Name: "created_at", Type: "TIMESTAMP"},
+			{Name: "updated_at", Type: "DATETIME"},
+		},
+		// Partitions that are not dates are ignored
+		Partitions: []PartitionInfo{{Name: "partitions", Columns: []string{"region"}, Latest: "region=us"}},
+		Stats: &TableStatistics{ColumnStats: []ColumnStats{
+			{Name: "id", Max: int64(42)},
+			{Name: "created_at", Max: "2024-01-15 06:00:00"},
+			{Name: "updated_at", Max: time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)},
+		}},
+	}
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	f, err := CheckFreshness(table, now, FreshnessOptions{Cadence: time.Hour})
+	if err != nil {
+		t.Fatalf("CheckFreshness() error = %v", err)
+	}
+	if f.Basis != FreshnessBasisStatistic || f.Column != "updated_at" || !f.Stale || f.Age != 3*time.Hour {
+		t.Errorf("CheckFreshness() = %+v, want stale updated_at, 3h old", f)
+	}
+
+	f, err = CheckFreshness(table, now, FreshnessOptions{Cadence: 8 * time.Hour, Column: "CREATED_AT"})
+	if err != nil {
+		t.Fatalf("CheckFreshness() error = %v", err)
+	}
+	if f.Column != "created_at" || f.Stale {
+		t.Errorf("CheckFreshness() = %+v, want fresh created_at", f)
+	}
+
+	if _, err := CheckFreshness(table, now, FreshnessOptions{Cadence: time.Hour, Column: "id"}); err == nil {
+		t.Error("CheckFreshness() of a numeric column succeeded")
+	}
+}
+
+func TestCheckFreshness_Unknown(t *testing.T) {
+	table := &TableMetadata{Columns: []Column{{Name: "id", Type: "BIGINT"}}}
+	if _, err := CheckFreshness(table, time.Now(), FreshnessOptions{Cadence: time.Hour}); !errors.Is(err, ErrNoFreshness) {
+		t.Errorf("CheckFreshness() error = %v, want ErrNoFreshness", err)
+	}
+	if _, err := CheckFreshness(table, time.Now(), FreshnessOptions{}); err == nil {
+		t.Error("CheckFreshness() without a cadence succeeded")
+	}
+}
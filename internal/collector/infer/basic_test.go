@@ -234,4 +234,56 @@ func TestContextCancellation(t *testing.T) {
 	if err != context.Canceled {
 		t.Errorf("Expected context.Canceled, got %v", err)
 	}
-}
\ No newline at end of file
+}
+
+// TestDocumentInferrerSampleValues tests that sample values are kept only when configured.
+func TestDocumentInferrerSampleValues(t *testing.T) {
+	ctx := context.Background()
+	docs := []interface{}{
+		map[string]interface{}{"email": "john@example.com", "tags": []interface{}{"a"}},
+		map[string]interface{}{"email": "jane@example.com", "tags": []interface{}{"b"}},
+		map[string]interface{}{"email": "john@example.com"},
+	}
+
+	samples := func(mode SampleValueMode) map[string]interface{} {
+		config := DefaultInferConfig()
+		config.SampleValues = mode
+		result, err := NewDocumentInferrerWithConfig(config).Infer(ctx, docs)
+		if err != nil {
+			t.Fatalf("Document inference failed: %v", err)
+		}
+		values := make(map[string]interface{})
+		for _, col := range result {
+			if v, ok := col.Raw["sample_values"]; ok {
+				values[col.Name] = v
+			}
+		}
+		return values
+	}
+
+	for _, mode := range []SampleValueMode{"", SampleValuesNone} {
+		if values := samples(mode); len(values) != 0 {
+			t.Errorf("Expected no sample values in mode %q, got %v", mode, values)
+		}
+	}
+
+	raw := samples(SampleValuesRaw)
+	emails, _ := raw["email"].([]string)
+	if len(emails) != 2 || emails[0] != "john@example.com" || emails[1] != "jane@example.com" {
+		t.Errorf("Expected distinct raw emails, got %v", raw["email"])
+	}
+	if _, ok := raw["tags"]; ok {
+		t.Errorf("Expected no sample values for arrays, got %v", raw["tags"])
+	}
+
+	hashed := samples(SampleValuesHash)
+	digests, _ := hashed["email"].([]string)
+	if len(digests) != 2 {
+		t.Fatalf("Expected 2 hashed emails, got %v", hashed["email"])
+	}
+	for _, digest := range digests {
+		if !strings.HasPrefix(digest, "sha256:") || strings.Contains(digest, "example.com") {
+			t.Errorf("Expected a SHA-256 digest, got %s", digest)
+		}
+	}
+}
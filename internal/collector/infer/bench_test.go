@@ -0,0 +1,62 @@
+package infer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// BenchmarkDocumentInfer infers the schema of 1000 nested documents with
+// about 100 fields each, 20 per level over five levels, whose types vary
+// between samples.
+func BenchmarkDocumentInfer(b *testing.B) {
+	samples := nestedSamples(1000, 5, 18)
+	inferrer := NewDocumentInferrerWithConfig(&InferConfig{
+		Enabled:    true,
+		SampleSize: len(samples),
+		MaxDepth:   10,
+		TypeMerge:  TypeMergeUnion,
+	})
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := inferrer.Infer(ctx, samples); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// nestedSamples builds n documents of the given depth, each level holding
+// width scalar fields, an array and a nested document.
+func nestedSamples(n, depth, width int) []interface{} {
+	samples := make([]interface{}, n)
+	for i := range samples {
+		samples[i] = nestedDoc(i, depth, width)
+	}
+	return samples
+}
+
+func nestedDoc(i, depth, width int) map[string]interface{} {
+	doc := make(map[string]interface{}, width+2)
+	for f := range width {
+		key := fmt.Sprintf("f%d", f)
+		switch (i + f) % 4 {
+		case 0:
+			doc[key] = fmt.Sprintf("value-%d", i)
+		case 1:
+			doc[key] = float64(i)
+		case 2:
+			doc[key] = i%2 == 0
+		default:
+			if i%7 != 0 {
+				doc[key] = nil
+			}
+		}
+	}
+	doc["tags"] = []interface{}{"a", "b", float64(i)}
+	if depth > 1 {
+		doc["child"] = nestedDoc(i, depth-1, width)
+	}
+	return doc
+}
@@ -3,6 +3,8 @@ package infer
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"reflect"
 	"sort"
@@ -107,6 +109,9 @@ func (d *DocumentInferrer) collectFields(prefix string, doc map[string]interface
 
 		typeName := d.getTypeName(value)
 		fieldTypes[fieldName].AddType(typeName)
+		if sample, ok := d.sampleValue(value); ok {
+			fieldTypes[fieldName].AddSample(sample)
+		}
 
 		// Recursively process nested documents
 		if nested, ok := value.(map[string]interface{}); ok {
@@ -122,6 +127,27 @@ func (d *DocumentInferrer) collectFields(prefix string, doc map[string]interface
 	}
 }
 
+// sampleValue returns the example of a scalar value to keep, as configured by
+// SampleValues. By default no values are kept so that the metadata store
+// never holds data of the source.
+func (d *DocumentInferrer) sampleValue(value interface{}) (string, bool) {
+	switch d.config.SampleValues {
+	case SampleValuesHash, SampleValuesRaw:
+	default:
+		return "", false
+	}
+	switch value.(type) {
+	case nil, []interface{}, map[string]interface{}:
+		return "", false
+	}
+	s := fmt.Sprint(value)
+	if d.config.SampleValues == SampleValuesHash {
+		sum := sha256.Sum256([]byte(s))
+		return "sha256:" + hex.EncodeToString(sum[:]), true
+	}
+	return s, true
+}
+
 // getTypeName returns the type name for a value.
 func (d *DocumentInferrer) getTypeName(value interface{}) string {
 	if value == nil {
@@ -201,6 +227,9 @@ func (d *DocumentInferrer) fieldsToColumns(fieldTypes map[string]*FieldTypeInfo,
 				fieldInfo.TotalCount(), 
 				float64(fieldInfo.TotalCount())/float64(totalSamples)*100),
 		}
+		if len(fieldInfo.Samples) > 0 {
+			column.Raw = map[string]any{"sample_values": fieldInfo.Samples}
+		}
 
 		columns = append(columns, column)
 		ordinalPosition++
@@ -18,6 +18,22 @@ const (
 	TypeMergeMostCommon TypeMergeStrategy = "most_common"
 )
 
+// SampleValueMode defines whether values of the sampled documents are kept
+// in the inferred metadata.
+type SampleValueMode string
+
+const (
+	// SampleValuesNone keeps only the types and structure of the samples
+	SampleValuesNone SampleValueMode = "none"
+	// SampleValuesHash keeps SHA-256 digests of example values
+	SampleValuesHash SampleValueMode = "hash"
+	// SampleValuesRaw keeps example values as sampled
+	SampleValuesRaw SampleValueMode = "raw"
+)
+
+// MaxSampleValues is the number of distinct example values kept per field.
+const MaxSampleValues = 3
+
 // InferConfig holds configuration for schema inference.
 type InferConfig struct {
 	// Enabled indicates whether schema inference is enabled
@@ -28,6 +44,9 @@ type InferConfig struct {
 	MaxDepth int `json:"max_depth" yaml:"max_depth"`
 	// TypeMerge specifies the strategy for merging multiple types
 	TypeMerge TypeMergeStrategy `json:"type_merge" yaml:"type_merge"`
+	// SampleValues specifies whether example values are kept, in the Raw
+	// "sample_values" of the inferred columns (empty = none)
+	SampleValues SampleValueMode `json:"sample_values,omitempty" yaml:"sample_values"`
 }
 
 // DefaultInferConfig returns the default inference configuration.
@@ -78,6 +97,9 @@ type FieldTypeInfo struct {
 	Nullable bool
 	// Depth is the nesting depth of this field
 	Depth int
+	// Samples are distinct example values of the field, hashed or raw
+	// depending on the SampleValues mode
+	Samples []string
 }
 
 // NewFieldTypeInfo creates a new FieldTypeInfo instance.
@@ -95,6 +117,20 @@ func (f *FieldTypeInfo) AddType(typeName string) {
 	f.Types[typeName]++
 }
 
+// AddSample records an example value for this field, up to MaxSampleValues
+// distinct values.
+func (f *FieldTypeInfo) AddSample(value string) {
+	if len(f.Samples) >= MaxSampleValues {
+		return
+	}
+	for _, s := range f.Samples {
+		if s == value {
+			return
+		}
+	}
+	f.Samples = append(f.Samples, value)
+}
+
 // MostCommonType returns the most frequently observed type.
 func (f *FieldTypeInfo) MostCommonType() string {
 	var maxCount int
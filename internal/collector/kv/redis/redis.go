@@ -32,7 +32,7 @@ const (
 // Collector Redis 元数据采集器
 type Collector struct {
 	config *config.ConnectorConfig
-	client *redis.Client
+	client collector.Conn[*redis.Client]
 }
 
 // NewCollector 创建 Redis 采集器实例
@@ -51,14 +51,15 @@ func NewCollector(cfg *config.ConnectorConfig) (collector.Collector, error) {
 
 // Connect 建立 Redis 连接
 func (c *Collector) Connect(ctx context.Context) error {
-	if c.client != nil {
-		return nil // Already connected
-	}
+	return c.client.Open(func() (*redis.Client, error) { return c.open(ctx) })
+}
 
+// open 建立连接并检查连通性
+func (c *Collector) open(ctx context.Context) (*redis.Client, error) {
 	// Parse endpoint
 	host, port, err := c.parseEndpoint()
 	if err != nil {
-		return collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
+		return nil, collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
 	}
 
 	// Set connection timeout
@@ -108,26 +109,23 @@ func (c *Collector) Connect(ctx context.Context) error {
 	// Test connection with ping
 	if err := client.Ping(ctx).Err(); err != nil {
 		client.Close()
-		return c.wrapConnectionError(err)
+		return nil, c.wrapConnectionError(err)
 	}
 
-	c.client = client
-	return nil
+	return client, nil
 }
 
 // Close 关闭 Redis 连接
 func (c *Collector) Close() error {
-	if c.client != nil {
-		err := c.client.Close()
-		c.client = nil
-		return err
-	}
-	return nil
+	return c.client.Close(func(client *redis.Client) error {
+		return client.Close()
+	})
 }
 
 // HealthCheck 健康检查
 func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return &collector.HealthStatus{
 			Connected: false,
 			Message:   "not connected",
@@ -137,7 +135,7 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 	start := time.Now()
 
 	// Ping to check connection
-	if err := c.client.Ping(ctx).Err(); err != nil {
+	if err := client.Ping(ctx).Err(); err != nil {
 		return &collector.HealthStatus{
 			Connected: false,
 			Latency:   time.Since(start),
@@ -146,7 +144,7 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 	}
 
 	// Get Redis version
-	info, err := c.client.Info(ctx, "server").Result()
+	info, err := client.Info(ctx, "server").Result()
 	if err != nil {
 		return &collector.HealthStatus{
 			Connected: true,
@@ -166,12 +164,13 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 
 // DiscoverCatalogs 发现 Catalog（Redis 中 catalog 等同于 Redis 实例）
 func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogInfo, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "discover_catalogs")
 	}
 
 	// Get Redis server info
-	info, err := c.client.Info(ctx, "server").Result()
+	info, err := client.Info(ctx, "server").Result()
 	if err != nil {
 		return nil, collector.NewQueryError(SourceName, "discover_catalogs", err)
 	}
@@ -193,7 +192,8 @@ func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogIn
 
 // ListSchemas 列出 Schema（Redis 中 schema 等同于 database）
 func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_schemas")
 	}
 
@@ -203,7 +203,7 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 	}
 
 	// Get keyspace info to find databases with keys
-	info, err := c.client.Info(ctx, "keyspace").Result()
+	info, err := client.Info(ctx, "keyspace").Result()
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "list_schemas")
@@ -237,7 +237,7 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 
 // ListTables 列出表（Redis 中表等同于 key pattern）
 func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts *collector.ListOptions) (*collector.TableListResult, error) {
-	if c.client == nil {
+	if _, ok := c.client.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_tables")
 	}
 
@@ -252,18 +252,7 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 		return nil, collector.NewInvalidConfigError(SourceName, "schema", fmt.Sprintf("invalid database number: %s", schema))
 	}
 
-	// Switch to the specified database
-	originalDB := c.client.Options().DB
-	if database != originalDB {
-		// Create a new client for the specific database
-		opts := *c.client.Options()
-		opts.DB = database
-		dbClient := redis.NewClient(&opts)
-		defer dbClient.Close()
-		c.client = dbClient
-	}
-
-	// Scan for key patterns
+	// Scan for key patterns; the scan switches to the database itself
 	patterns, err := c.scanKeyPatterns(ctx, database, "*", 1000)
 	if err != nil {
 		return nil, err
@@ -309,7 +298,7 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 
 // FetchTableMetadata 获取表元数据（Redis key pattern 元数据）
 func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, table string) (*collector.TableMetadata, error) {
-	if c.client == nil {
+	if _, ok := c.client.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_metadata")
 	}
 
@@ -361,7 +350,7 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 
 // FetchTableStatistics 获取表统计信息
 func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, table string) (*collector.TableStatistics, error) {
-	if c.client == nil {
+	if _, ok := c.client.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_statistics")
 	}
 
@@ -16,7 +16,8 @@ import (
 
 // scanKeyPatterns scans Redis keys and infers patterns
 func (c *Collector) scanKeyPatterns(ctx context.Context, database int, pattern string, limit int) ([]kv.KeyPattern, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "scan_key_patterns")
 	}
 
@@ -26,9 +27,8 @@ func (c *Collector) scanKeyPatterns(ctx context.Context, database int, pattern s
 	}
 
 	// Switch to the specified database if needed
-	client := c.client
-	if database != c.client.Options().DB {
-		opts := *c.client.Options()
+	if database != client.Options().DB {
+		opts := *client.Options()
 		opts.DB = database
 		client = redis.NewClient(&opts)
 		defer client.Close()
@@ -239,7 +239,8 @@ func (c *Collector) getKeyType(ctx context.Context, client *redis.Client, key st
 
 // countKeysForPattern counts keys matching a pattern
 func (c *Collector) countKeysForPattern(ctx context.Context, database int, pattern string) (int64, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return 0, collector.NewConnectionClosedError(SourceName, "count_keys_for_pattern")
 	}
 
@@ -249,9 +250,8 @@ func (c *Collector) countKeysForPattern(ctx context.Context, database int, patte
 	}
 
 	// Switch to the specified database if needed
-	client := c.client
-	if database != c.client.Options().DB {
-		opts := *c.client.Options()
+	if database != client.Options().DB {
+		opts := *client.Options()
 		opts.DB = database
 		client = redis.NewClient(&opts)
 		defer client.Close()
@@ -296,7 +296,8 @@ func (c *Collector) ScanKeyPatterns(ctx context.Context, database int, pattern s
 
 // GetKeyTypeDistribution implements the KeyValueCollector interface
 func (c *Collector) GetKeyTypeDistribution(ctx context.Context, database int) (map[string]int64, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "get_key_type_distribution")
 	}
 
@@ -306,9 +307,8 @@ func (c *Collector) GetKeyTypeDistribution(ctx context.Context, database int) (m
 	}
 
 	// Switch to the specified database if needed
-	client := c.client
-	if database != c.client.Options().DB {
-		opts := *c.client.Options()
+	if database != client.Options().DB {
+		opts := *client.Options()
 		opts.DB = database
 		client = redis.NewClient(&opts)
 		defer client.Close()
@@ -337,7 +337,8 @@ func (c *Collector) GetKeyTypeDistribution(ctx context.Context, database int) (m
 
 // GetMemoryUsage implements the KeyValueCollector interface
 func (c *Collector) GetMemoryUsage(ctx context.Context) (*kv.MemoryStats, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "get_memory_usage")
 	}
 
@@ -347,7 +348,7 @@ func (c *Collector) GetMemoryUsage(ctx context.Context) (*kv.MemoryStats, error)
 	}
 
 	// Get memory info from Redis
-	info, err := c.client.Info(ctx, "memory").Result()
+	info, err := client.Info(ctx, "memory").Result()
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "get_memory_usage")
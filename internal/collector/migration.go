@@ -0,0 +1,353 @@
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SQL dialects of migration scripts, named as the source types of their
+// collectors.
+const (
+	DialectMySQL    = "mysql"
+	DialectPostgres = "postgres"
+)
+
+// MigrationOptions configures GenerateMigration.
+type MigrationOptions struct {
+	// Dialect is the SQL dialect of the target, DialectMySQL or
+	// DialectPostgres.
+	Dialect string
+	// Schema, if set, is made the current schema at the start of the
+	// script; table names are not qualified.
+	Schema string
+	// Drop drops the tables, columns and indexes only in the target. By
+	// default their drops are written commented out, as they may be kept
+	// deliberately.
+	Drop bool
+	// Title describes the migration at the top of the script, e.g. the
+	// compared schemas.
+	Title string
+}
+
+// GenerateMigration writes a SQL script that would alter a target schema to
+// match a source schema, e.g. prod to match staging, from the differences
+// CompareSchemas finds. Statements are ordered so that each applies after
+// those it depends on: indexes and primary keys that change are dropped
+// first, then tables and columns are created and altered, then indexes and
+// primary keys are created, and columns and tables only in the target are
+// dropped last.
+//
+// The script is derived from metadata only and must be reviewed before it
+// is applied; its header says so.
+func GenerateMigration(source, target []*TableMetadata, opts MigrationOptions) (string, error) {
+	d, err := newMigrationDialect(opts.Dialect)
+	if err != nil {
+		return "", err
+	}
+	diff := CompareSchemas(source, target)
+	sources := tablesByName(source)
+	targets := tablesByName(target)
+
+	var dropKeys, createTables, addColumns, alterColumns, createKeys, dropColumns, dropTables []string
+	optional := func(stmt string) string {
+		if opts.Drop {
+			return stmt
+		}
+		return commentOut(stmt)
+	}
+
+	for _, name := range diff.MissingTables {
+		t := sources[strings.ToLower(name)]
+		createTables = append(createTables, d.createTable(t))
+		for i := range t.Indexes {
+			if !isPrimaryIndex(&t.Indexes[i], t.PrimaryKey) {
+				createTables = append(createTables, d.createIndex(t.Name, &t.Indexes[i]))
+			}
+		}
+	}
+
+	for _, td := range diff.Tables {
+		s, t := sources[strings.ToLower(td.Table)], targets[strings.ToLower(td.Table)]
+		table := t.Name
+
+		for _, i := range td.Indexes {
+			switch i.Kind {
+			case DiffMissing:
+				if !isPrimaryIndex(i.Source, s.PrimaryKey) {
+					createKeys = append(createKeys, d.createIndex(table, i.Source))
+				}
+			case DiffExtra:
+				if !isPrimaryIndex(i.Target, t.PrimaryKey) {
+					dropKeys = append(dropKeys, optional(d.dropIndex(table, i.Target)))
+				}
+			default:
+				// Primary key indexes change with the primary key
+				if isPrimaryIndex(i.Target, t.PrimaryKey) || isPrimaryIndex(i.Source, s.PrimaryKey) {
+					continue
+				}
+				dropKeys = append(dropKeys, d.dropIndex(table, i.Target))
+				createKeys = append(createKeys, d.createIndex(table, i.Source))
+			}
+		}
+		if pk := td.PrimaryKey; pk != nil {
+			if len(pk.Target) > 0 {
+				dropKeys = append(dropKeys, d.dropPrimaryKey(t))
+			}
+			if len(pk.Source) > 0 {
+				createKeys = append(createKeys, fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s);", d.quote(table), d.quoteAll(pk.Source)))
+			}
+		}
+
+		for _, c := range td.Columns {
+			switch c.Kind {
+			case DiffMissing:
+				addColumns = append(addColumns, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", d.quote(table), d.columnDefinition(c.Source)))
+			case DiffExtra:
+				dropColumns = append(dropColumns, optional(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", d.quote(table), d.quote(c.Column))))
+			default:
+				alterColumns = append(alterColumns, d.alterColumn(table, c.Source, c.Target)...)
+			}
+		}
+	}
+
+	for _, name := range diff.ExtraTables {
+		dropTables = append(dropTables, optional(fmt.Sprintf("DROP TABLE %s;", d.quote(targets[strings.ToLower(name)].Name))))
+	}
+
+	var b strings.Builder
+	writeMigrationHeader(&b, opts)
+	if diff.Empty() {
+		b.WriteString("\n-- No differences: nothing to migrate.\n")
+		return b.String(), nil
+	}
+	if opts.Schema != "" {
+		b.WriteString("\n" + d.useSchema(opts.Schema) + "\n")
+	}
+	sections := []struct {
+		title      string
+		statements []string
+	}{
+		{"Drop the indexes and primary keys that change or are only in the target", dropKeys},
+		{"Create the tables missing from the target", createTables},
+		{"Add the columns missing from the target", addColumns},
+		{"Alter the columns that differ", alterColumns},
+		{"Create the indexes and primary keys missing from the target or changed", createKeys},
+		{"Drop the columns only in the target", dropColumns},
+		{"Drop the tables only in the target", dropTables},
+	}
+	for _, s := range sections {
+		if len(s.statements) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n-- %s\n", s.title)
+		for _, stmt := range s.statements {
+			b.WriteString(stmt + "\n")
+		}
+	}
+	return b.String(), nil
+}
+
+func writeMigrationHeader(b *strings.Builder, opts MigrationOptions) {
+	if opts.Title != "" {
+		fmt.Fprintf(b, "-- %s\n", opts.Title)
+	}
+	fmt.Fprintf(b, "-- Dialect: %s\n", opts.Dialect)
+	b.WriteString(`--
+-- REVIEW BEFORE APPLYING. This script is derived from metadata only:
+--   * column types, defaults and expressions are copied from the source as
+--     they are and may need translating to the dialect of the target;
+--   * a renamed table, column or index shows as a drop and an add, which
+--     loses its data;
+--   * changing column types, adding NOT NULL columns or primary keys may
+--     fail on, or rewrite, tables with rows;
+--   * data, foreign keys, views, triggers, grants and comments are not
+--     migrated.
+`)
+	if !opts.Drop {
+		b.WriteString("-- Drops of the objects only in the target are commented out.\n")
+	}
+}
+
+// migrationDialect writes the statements of a dialect.
+type migrationDialect struct {
+	name string
+}
+
+func newMigrationDialect(name string) (*migrationDialect, error) {
+	switch name {
+	case DialectMySQL, DialectPostgres:
+		return &migrationDialect{name: name}, nil
+	}
+	return nil, fmt.Errorf("unsupported migration dialect %q: must be %s or %s", name, DialectMySQL, DialectPostgres)
+}
+
+func (d *migrationDialect) quote(name string) string {
+	if d.name == DialectMySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (d *migrationDialect) quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = d.quote(n)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (d *migrationDialect) useSchema(schema string) string {
+	if d.name == DialectMySQL {
+		return fmt.Sprintf("USE %s;", d.quote(schema))
+	}
+	return fmt.Sprintf("SET search_path TO %s;", d.quote(schema))
+}
+
+// columnDefinition is the definition of a column in CREATE TABLE and ADD
+// COLUMN.
+func (d *migrationDialect) columnDefinition(c *Column) string {
+	parts := []string{d.quote(c.Name), ColumnType(c)}
+	if c.Generated != nil {
+		storage := "VIRTUAL"
+		if c.Generated.Stored || d.name == DialectPostgres {
+			storage = "STORED"
+		}
+		parts = append(parts, fmt.Sprintf("GENERATED ALWAYS AS (%s) %s", c.Generated.Expression, storage))
+	}
+	if !c.Nullable {
+		parts = append(parts, "NOT NULL")
+	}
+	switch {
+	case c.IsAutoIncrement && d.name == DialectMySQL:
+		parts = append(parts, "AUTO_INCREMENT")
+	case c.IsAutoIncrement:
+		parts = append(parts, "GENERATED BY DEFAULT AS IDENTITY")
+	case c.Default != nil && c.Generated == nil:
+		parts = append(parts, "DEFAULT "+defaultSQL(*c.Default))
+	}
+	return strings.Join(parts, " ")
+}
+
+// alterColumn returns the statements changing column t of the target to
+// match s of the source.
+func (d *migrationDialect) alterColumn(table string, s, t *Column) []string {
+	if d.name == DialectMySQL {
+		return []string{fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s;", d.quote(table), d.columnDefinition(s))}
+	}
+
+	alter := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", d.quote(table), d.quote(t.Name))
+	if generation(s) != generation(t) {
+		// PostgreSQL cannot alter the expression of a generated column
+		return []string{
+			fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", d.quote(table), d.quote(t.Name)),
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", d.quote(table), d.columnDefinition(s)),
+		}
+	}
+	var stmts []string
+	if st, tt := ColumnType(s), ColumnType(t); !strings.EqualFold(st, tt) {
+		stmts = append(stmts, fmt.Sprintf("%s TYPE %s; -- may need USING %s::%s", alter, st, d.quote(t.Name), st))
+	}
+	if s.IsAutoIncrement != t.IsAutoIncrement {
+		if s.IsAutoIncrement {
+			stmts = append(stmts, alter+" ADD GENERATED BY DEFAULT AS IDENTITY;")
+		} else {
+			stmts = append(stmts, alter+" DROP IDENTITY IF EXISTS;")
+		}
+	}
+	if sd, td := defaultValue(s), defaultValue(t); sd != td && !s.IsAutoIncrement {
+		if s.Default == nil {
+			stmts = append(stmts, alter+" DROP DEFAULT;")
+		} else {
+			stmts = append(stmts, alter+" SET DEFAULT "+defaultSQL(*s.Default)+";")
+		}
+	}
+	if s.Nullable != t.Nullable {
+		if s.Nullable {
+			stmts = append(stmts, alter+" DROP NOT NULL;")
+		} else {
+			stmts = append(stmts, alter+" SET NOT NULL;")
+		}
+	}
+	return stmts
+}
+
+func (d *migrationDialect) createTable(t *TableMetadata) string {
+	lines := make([]string, 0, len(t.Columns)+1)
+	for i := range t.Columns {
+		lines = append(lines, "  "+d.columnDefinition(&t.Columns[i]))
+	}
+	if len(t.PrimaryKey) > 0 {
+		lines = append(lines, fmt.Sprintf("  PRIMARY KEY (%s)", d.quoteAll(t.PrimaryKey)))
+	}
+	return fmt.Sprintf("CREATE TABLE %s (\n%s\n);", d.quote(t.Name), strings.Join(lines, ",\n"))
+}
+
+func (d *migrationDialect) createIndex(table string, i *Index) string {
+	unique := ""
+	if i.Unique {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s);", unique, d.quote(i.Name), d.quote(table), d.quoteAll(i.Columns))
+}
+
+func (d *migrationDialect) dropIndex(table string, i *Index) string {
+	if d.name == DialectMySQL {
+		return fmt.Sprintf("DROP INDEX %s ON %s;", d.quote(i.Name), d.quote(table))
+	}
+	return fmt.Sprintf("DROP INDEX %s;", d.quote(i.Name))
+}
+
+// dropPrimaryKey drops the primary key of t. PostgreSQL drops it by the name
+// of its constraint, the name of its index.
+func (d *migrationDialect) dropPrimaryKey(t *TableMetadata) string {
+	if d.name == DialectMySQL {
+		return fmt.Sprintf("ALTER TABLE %s DROP PRIMARY KEY;", d.quote(t.Name))
+	}
+	constraint := t.Name + "_pkey"
+	for i := range t.Indexes {
+		if isPrimaryIndex(&t.Indexes[i], t.PrimaryKey) {
+			constraint = t.Indexes[i].Name
+			break
+		}
+	}
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", d.quote(t.Name), d.quote(constraint))
+}
+
+// isPrimaryIndex reports whether an index is the index of a primary key,
+// which collectors list with the other indexes: PRIMARY in MySQL, a unique
+// index of the primary key columns in PostgreSQL.
+func isPrimaryIndex(i *Index, pk []string) bool {
+	if strings.EqualFold(i.Name, "PRIMARY") {
+		return true
+	}
+	return i.Unique && len(pk) > 0 && sameNames(i.Columns, pk)
+}
+
+// defaultSQL returns a default value as a SQL expression. Collectors report
+// numbers, keywords and expressions as they are, and MySQL reports string
+// defaults unquoted.
+func defaultSQL(v string) string {
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return v
+	}
+	switch upper := strings.ToUpper(v); {
+	case upper == "NULL", upper == "TRUE", upper == "FALSE", strings.HasPrefix(upper, "CURRENT_"):
+		return v
+	case strings.ContainsAny(v, "('"):
+		return v
+	}
+	return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+}
+
+func commentOut(stmt string) string {
+	return "-- " + strings.ReplaceAll(stmt, "\n", "\n-- ")
+}
+
+func tablesByName(tables []*TableMetadata) map[string]*TableMetadata {
+	m := make(map[string]*TableMetadata, len(tables))
+	for _, t := range tables {
+		m[strings.ToLower(t.Name)] = t
+	}
+	return m
+}
@@ -0,0 +1,129 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateMigration(t *testing.T) {
+	length := func(n int) *int { return &n }
+	status := "new"
+	staging := []*TableMetadata{
+		{
+			Name: "orders",
+			Columns: []Column{
+				{Name: "id", SourceType: "bigint", IsAutoIncrement: true},
+				{Name: "note", Type: "varchar", Length: length(255), Nullable: true},
+				{Name: "status", SourceType: "varchar(16)", Default: &status},
+				{Name: "user_id", SourceType: "bigint"},
+			},
+			Indexes: []Index{
+				{Name: "PRIMARY", Columns: []string{"id"}, Unique: true},
+				{Name: "idx_user", Columns: []string{"user_id", "status"}},
+			},
+			PrimaryKey: []string{"id"},
+		},
+		{
+			Name:       "coupons",
+			Columns:    []Column{{Name: "id", SourceType: "bigint"}, {Name: "code", SourceType: "varchar(32)"}},
+			Indexes:    []Index{{Name: "coupons_pkey", Columns: []string{"id"}, Unique: true}, {Name: "uk_code", Columns: []string{"code"}, Unique: true}},
+			PrimaryKey: []string{"id"},
+		},
+	}
+	prod := []*TableMetadata{
+		{
+			Name: "orders",
+			Columns: []Column{
+				{Name: "id", SourceType: "bigint", IsAutoIncrement: true},
+				{Name: "note", Type: "varchar", Length: length(64)},
+				{Name: "user_id", SourceType: "bigint"},
+				{Name: "legacy_flag", SourceType: "tinyint(1)"},
+			},
+			Indexes: []Index{
+				{Name: "PRIMARY", Columns: []string{"id"}, Unique: true},
+				{Name: "idx_user", Columns: []string{"user_id"}},
+				{Name: "idx_legacy", Columns: []string{"legacy_flag"}},
+			},
+			PrimaryKey: []string{"id"},
+		},
+		{Name: "legacy_orders", Columns: []Column{{Name: "id", SourceType: "bigint"}}},
+	}
+
+	script, err := GenerateMigration(staging, prod, MigrationOptions{Dialect: DialectMySQL, Schema: "shop"})
+	if err != nil {
+		t.Fatalf("GenerateMigration() failed: %v", err)
+	}
+	// In the order they must apply
+	want := []string{
+		"REVIEW BEFORE APPLYING",
+		"USE `shop`;",
+		"DROP INDEX `idx_user` ON `orders`;",
+		"-- DROP INDEX `idx_legacy` ON `orders`;",
+		"CREATE TABLE `coupons` (\n  `id` bigint NOT NULL,\n  `code` varchar(32) NOT NULL,\n  PRIMARY KEY (`id`)\n);",
+		"CREATE UNIQUE INDEX `uk_code` ON `coupons` (`code`);",
+		"ALTER TABLE `orders` ADD COLUMN `status` varchar(16) NOT NULL DEFAULT 'new';",
+		"ALTER TABLE `orders` MODIFY COLUMN `note` varchar(255);",
+		"CREATE INDEX `idx_user` ON `orders` (`user_id`, `status`);",
+		"-- ALTER TABLE `orders` DROP COLUMN `legacy_flag`;",
+		"-- DROP TABLE `legacy_orders`;",
+	}
+	at := 0
+	for _, stmt := range want {
+		i := strings.Index(script[at:], stmt)
+		if i < 0 {
+			t.Fatalf("Script does not contain %q after offset %d:\n%s", stmt, at, script)
+		}
+		at += i + len(stmt)
+	}
+	if strings.Contains(script, "`PRIMARY`") || strings.Contains(script, "coupons_pkey") {
+		t.Errorf("Script creates or drops primary key indexes:\n%s", script)
+	}
+
+	script, err = GenerateMigration(staging, prod, MigrationOptions{Dialect: DialectPostgres, Drop: true})
+	if err != nil {
+		t.Fatalf("GenerateMigration() failed: %v", err)
+	}
+	for _, stmt := range []string{
+		`DROP INDEX "idx_legacy";`,
+		`ALTER TABLE "orders" ALTER COLUMN "note" TYPE varchar(255);`,
+		`ALTER TABLE "orders" ALTER COLUMN "note" DROP NOT NULL;`,
+		`ALTER TABLE "orders" DROP COLUMN "legacy_flag";`,
+		`DROP TABLE "legacy_orders";`,
+	} {
+		if !strings.Contains(script, stmt) {
+			t.Errorf("Script does not contain %q:\n%s", stmt, script)
+		}
+	}
+
+	if _, err := GenerateMigration(staging, prod, MigrationOptions{Dialect: "oracle"}); err == nil {
+		t.Error("GenerateMigration() of an unsupported dialect did not fail")
+	}
+}
+
+func TestGenerateMigration_PrimaryKey(t *testing.T) {
+	source := []*TableMetadata{{
+		Name:       "items",
+		Columns:    []Column{{Name: "order_id", SourceType: "int"}, {Name: "line", SourceType: "int"}},
+		Indexes:    []Index{{Name: "items_pkey", Columns: []string{"order_id", "line"}, Unique: true}},
+		PrimaryKey: []string{"order_id", "line"},
+	}}
+	target := []*TableMetadata{{
+		Name:       "items",
+		Columns:    []Column{{Name: "order_id", SourceType: "int"}, {Name: "line", SourceType: "int"}},
+		Indexes:    []Index{{Name: "items_pk", Columns: []string{"order_id"}, Unique: true}},
+		PrimaryKey: []string{"order_id"},
+	}}
+
+	script, err := GenerateMigration(source, target, MigrationOptions{Dialect: DialectPostgres})
+	if err != nil {
+		t.Fatalf("GenerateMigration() failed: %v", err)
+	}
+	drop := strings.Index(script, `ALTER TABLE "items" DROP CONSTRAINT "items_pk";`)
+	add := strings.Index(script, `ALTER TABLE "items" ADD PRIMARY KEY ("order_id", "line");`)
+	if drop < 0 || add < drop {
+		t.Errorf("Script does not replace the primary key:\n%s", script)
+	}
+	if strings.Contains(script, "INDEX") {
+		t.Errorf("Script changes primary key indexes:\n%s", script)
+	}
+}
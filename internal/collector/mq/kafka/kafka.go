@@ -12,6 +12,7 @@ import (
 	"go-metadata/internal/collector"
 	"go-metadata/internal/collector/config"
 	"go-metadata/internal/collector/matcher"
+	"go-metadata/internal/tlsprofile"
 
 	"github.com/IBM/sarama"
 )
@@ -25,11 +26,21 @@ const (
 	DefaultTimeout = 30
 	// DefaultMaxMessageBytes is the default max message size
 	DefaultMaxMessageBytes = 1000000
+	// DefaultCatalog is the catalog of an endpoint without named clusters
+	DefaultCatalog = "kafka"
 )
 
-// Collector Kafka 元数据采集器
+// Collector Kafka 元数据采集器。
+// 一个采集器可以采集多个命名集群 (如 prod 与 staging)，每个集群对应一个 catalog
 type Collector struct {
-	config       *config.ConnectorConfig
+	config   *config.ConnectorConfig
+	clusters collector.Conn[[]*cluster]
+}
+
+// cluster 一个 Kafka 集群的连接
+type cluster struct {
+	name         string
+	brokers      []string
 	client       sarama.Client
 	admin        sarama.ClusterAdmin
 	schemaClient *SchemaRegistryClient
@@ -49,16 +60,17 @@ func NewCollector(cfg *config.ConnectorConfig) (collector.Collector, error) {
 	}, nil
 }
 
-// Connect 建立 Kafka 连接
+// Connect 建立 Kafka 连接，连接所有配置的集群
 func (c *Collector) Connect(ctx context.Context) error {
-	if c.client != nil {
-		return nil // Already connected
-	}
+	return c.clusters.Open(c.open)
+}
 
-	// Parse brokers from endpoint
-	brokers, err := c.parseBrokers()
+// open 解析并连接所有集群，任一集群连接失败时关闭已连接的集群
+func (c *Collector) open() ([]*cluster, error) {
+	// Parse clusters from endpoint
+	clusters, err := c.parseClusters()
 	if err != nil {
-		return collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
+		return nil, collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
 	}
 
 	// Create Sarama configuration
@@ -86,6 +98,7 @@ func (c *Collector) Connect(ctx context.Context) error {
 	if c.config.Properties.Extra != nil {
 		if tlsEnabled := c.config.Properties.Extra["tls_enabled"]; tlsEnabled == "true" {
 			saramaConfig.Net.TLS.Enable = true
+			saramaConfig.Net.TLS.Config = tlsprofile.Client()
 		}
 	}
 
@@ -106,70 +119,121 @@ func (c *Collector) Connect(ctx context.Context) error {
 	saramaConfig.Producer.MaxMessageBytes = maxMessageBytes
 	saramaConfig.Consumer.Fetch.Max = int32(maxMessageBytes)
 
+	for _, cl := range clusters {
+		if err := c.connectCluster(cl, saramaConfig); err != nil {
+			closeClusters(clusters)
+			if len(clusters) > 1 {
+				err = fmt.Errorf("cluster %s: %w", cl.name, err)
+			}
+			return nil, c.wrapConnectionError(err)
+		}
+	}
+	return clusters, nil
+}
+
+// connectCluster creates the client, cluster admin and Schema Registry
+// client of a cluster.
+func (c *Collector) connectCluster(cl *cluster, saramaConfig *sarama.Config) error {
 	// Create Kafka client
-	client, err := sarama.NewClient(brokers, saramaConfig)
+	client, err := sarama.NewClient(cl.brokers, saramaConfig)
 	if err != nil {
-		return c.wrapConnectionError(err)
+		return err
 	}
 
 	// Create cluster admin
 	admin, err := sarama.NewClusterAdminFromClient(client)
 	if err != nil {
 		client.Close()
-		return c.wrapConnectionError(err)
+		return err
 	}
 
-	c.client = client
-	c.admin = admin
+	cl.client = client
+	cl.admin = admin
 
 	// Initialize Schema Registry client if configured
-	if c.config.Properties.Extra != nil {
-		if schemaRegistryURL := c.config.Properties.Extra["schema_registry_url"]; schemaRegistryURL != "" {
-			schemaClient, err := NewSchemaRegistryClient(schemaRegistryURL, c.config.Credentials.User, c.config.Credentials.Password)
-			if err != nil {
-				// Schema Registry is optional, log but don't fail
-				// In a real implementation, you might want to log this
-			} else {
-				c.schemaClient = schemaClient
-			}
+	if schemaRegistryURL := c.schemaRegistryURL(cl.name); schemaRegistryURL != "" {
+		schemaClient, err := NewSchemaRegistryClient(schemaRegistryURL, c.config.Credentials.User, c.config.Credentials.Password)
+		if err != nil {
+			// Schema Registry is optional, log but don't fail
+			// In a real implementation, you might want to log this
+		} else {
+			cl.schemaClient = schemaClient
 		}
 	}
 
 	return nil
 }
 
+// schemaRegistryURL returns the Schema Registry of a cluster:
+// schema_registry_url.<cluster> if set, otherwise schema_registry_url.
+func (c *Collector) schemaRegistryURL(name string) string {
+	extra := c.config.Properties.Extra
+	if url := extra["schema_registry_url."+name]; url != "" {
+		return url
+	}
+	return extra["schema_registry_url"]
+}
+
 // Close 关闭 Kafka 连接
 func (c *Collector) Close() error {
+	return c.clusters.Close(func(clusters []*cluster) error {
+		if errs := closeClusters(clusters); len(errs) > 0 {
+			return fmt.Errorf("errors closing Kafka connections: %v", errs)
+		}
+		return nil
+	})
+}
+
+// closeClusters closes the connections of clusters and returns the errors.
+func closeClusters(clusters []*cluster) []error {
 	var errs []error
 
-	if c.admin != nil {
-		if err := c.admin.Close(); err != nil {
-			errs = append(errs, err)
+	for _, cl := range clusters {
+		if cl.admin != nil {
+			if err := cl.admin.Close(); err != nil {
+				errs = append(errs, err)
+			}
+			cl.admin = nil
 		}
-		c.admin = nil
-	}
 
-	if c.client != nil {
-		if err := c.client.Close(); err != nil {
-			errs = append(errs, err)
+		if cl.client != nil {
+			// Closing the admin closes its client
+			if !cl.client.Closed() {
+				if err := cl.client.Close(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			cl.client = nil
 		}
-		c.client = nil
-	}
 
-	if c.schemaClient != nil {
-		c.schemaClient = nil
+		cl.schemaClient = nil
 	}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("errors closing Kafka connections: %v", errs)
-	}
+	return errs
+}
 
-	return nil
+// cluster returns the connected cluster of a catalog, the first cluster for
+// an empty catalog.
+func (c *Collector) cluster(catalog, operation string) (*cluster, error) {
+	clusters, ok := c.clusters.Get()
+	if !ok {
+		return nil, collector.NewConnectionClosedError(SourceName, operation)
+	}
+	if catalog == "" {
+		return clusters[0], nil
+	}
+	for _, cl := range clusters {
+		if cl.name == catalog {
+			return cl, nil
+		}
+	}
+	return nil, collector.NewNotFoundError(SourceName, operation, "cluster "+catalog, nil)
 }
 
-// HealthCheck 健康检查
+// HealthCheck 健康检查，所有集群均可用时为已连接
 func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, error) {
-	if c.client == nil {
+	clusters, ok := c.clusters.Get()
+	if !ok {
 		return &collector.HealthStatus{
 			Connected: false,
 			Message:   "not connected",
@@ -178,90 +242,116 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 
 	start := time.Now()
 
+	version := "unknown"
+	brokerCount := 0
+	for _, cl := range clusters {
+		status := checkCluster(cl)
+		if status != nil {
+			if len(clusters) > 1 {
+				status.Message = fmt.Sprintf("cluster %s: %s", cl.name, status.Message)
+			}
+			status.Latency = time.Since(start)
+			return status, nil
+		}
+		brokerCount += len(cl.client.Brokers())
+		if version == "unknown" {
+			version = clusterVersion(cl)
+		}
+	}
+
+	message := fmt.Sprintf("connected to %d brokers", brokerCount)
+	if len(clusters) > 1 {
+		message = fmt.Sprintf("connected to %d brokers in %d clusters", brokerCount, len(clusters))
+	}
+	return &collector.HealthStatus{
+		Connected: true,
+		Latency:   time.Since(start),
+		Version:   version,
+		Message:   message,
+	}, nil
+}
+
+// checkCluster returns the status of a cluster that is not available, or nil.
+func checkCluster(cl *cluster) *collector.HealthStatus {
 	// Check if client is closed
-	if c.client.Closed() {
+	if cl.client.Closed() {
 		return &collector.HealthStatus{
 			Connected: false,
-			Latency:   time.Since(start),
 			Message:   "client is closed",
-		}, nil
+		}
 	}
 
 	// Try to get broker list to verify connectivity
-	brokers := c.client.Brokers()
-	if len(brokers) == 0 {
+	if len(cl.client.Brokers()) == 0 {
 		return &collector.HealthStatus{
 			Connected: false,
-			Latency:   time.Since(start),
 			Message:   "no brokers available",
-		}, nil
+		}
 	}
 
 	// Try to refresh metadata to test connectivity
-	if err := c.client.RefreshMetadata(); err != nil {
+	if err := cl.client.RefreshMetadata(); err != nil {
 		return &collector.HealthStatus{
 			Connected: false,
-			Latency:   time.Since(start),
 			Message:   fmt.Sprintf("failed to refresh metadata: %v", err),
-		}, nil
+		}
 	}
 
-	// Get Kafka version from broker
-	version := "unknown"
-	if len(brokers) > 0 {
-		// Get version from first available broker
-		for _, broker := range brokers {
-			connected, _ := broker.Connected()
-			if connected {
-				// Sarama doesn't directly expose broker version, so we'll use the client version
-				version = c.client.Config().Version.String()
-				break
-			}
+	return nil
+}
+
+// clusterVersion returns the Kafka version of a cluster with a connected
+// broker, or "unknown".
+func clusterVersion(cl *cluster) string {
+	for _, broker := range cl.client.Brokers() {
+		connected, _ := broker.Connected()
+		if connected {
+			// Sarama doesn't directly expose broker version, so we'll use the client version
+			return cl.client.Config().Version.String()
 		}
 	}
-
-	return &collector.HealthStatus{
-		Connected: true,
-		Latency:   time.Since(start),
-		Version:   version,
-		Message:   fmt.Sprintf("connected to %d brokers", len(brokers)),
-	}, nil
+	return "unknown"
 }
 
-// DiscoverCatalogs 发现 Catalog（Kafka 中 catalog 等同于 Kafka 集群）
+// DiscoverCatalogs 发现 Catalog（Kafka 中 catalog 等同于 Kafka 集群，
+// 未命名集群时为 kafka）
 func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogInfo, error) {
 	// Check context before starting operation
 	if err := collector.CheckContext(ctx, SourceName, "discover_catalogs"); err != nil {
 		return nil, err
 	}
 
-	if c.client == nil {
+	clusters, ok := c.clusters.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "discover_catalogs")
 	}
 
-	brokers := c.client.Brokers()
-	if len(brokers) == 0 {
-		return nil, collector.NewNetworkError(SourceName, "discover_catalogs", fmt.Errorf("no brokers available"))
-	}
+	catalogs := make([]collector.CatalogInfo, 0, len(clusters))
+	for _, cl := range clusters {
+		brokers := cl.client.Brokers()
+		if len(brokers) == 0 {
+			return nil, collector.NewNetworkError(SourceName, "discover_catalogs", fmt.Errorf("no brokers available in cluster %s", cl.name))
+		}
 
-	// Kafka typically has one catalog per cluster
-	return []collector.CatalogInfo{
-		{
-			Catalog:     "kafka",
+		catalogs = append(catalogs, collector.CatalogInfo{
+			Catalog:     cl.name,
 			Type:        SourceName,
 			Description: "Kafka Cluster",
 			Properties: map[string]string{
-				"brokers": fmt.Sprintf("%d", len(brokers)),
-				"version": c.client.Config().Version.String(),
+				"brokers":           fmt.Sprintf("%d", len(brokers)),
+				"bootstrap_servers": strings.Join(cl.brokers, ","),
+				"version":           cl.client.Config().Version.String(),
 			},
-		},
-	}, nil
+		})
+	}
+
+	return catalogs, nil
 }
 
 // ListSchemas 列出 Schema（Kafka 中 schema 等同于 namespace，这里使用默认值）
 func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string, error) {
-	if c.client == nil {
-		return nil, collector.NewConnectionClosedError(SourceName, "list_schemas")
+	if _, err := c.cluster(catalog, "list_schemas"); err != nil {
+		return nil, err
 	}
 
 	// Check context before starting operation
@@ -275,8 +365,9 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 
 // ListTables 列出表（Kafka 中表等同于 Topic）
 func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts *collector.ListOptions) (*collector.TableListResult, error) {
-	if c.client == nil {
-		return nil, collector.NewConnectionClosedError(SourceName, "list_tables")
+	cl, err := c.cluster(catalog, "list_tables")
+	if err != nil {
+		return nil, err
 	}
 
 	// Check context before starting operation
@@ -285,7 +376,7 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 	}
 
 	// Get topic metadata
-	topics, err := c.admin.ListTopics()
+	topics, err := cl.admin.ListTopics()
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "list_tables")
@@ -333,8 +424,9 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 
 // FetchTableMetadata 获取表元数据（Kafka Topic 元数据）
 func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, table string) (*collector.TableMetadata, error) {
-	if c.client == nil {
-		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_metadata")
+	cl, err := c.cluster(catalog, "fetch_table_metadata")
+	if err != nil {
+		return nil, err
 	}
 
 	// Check context before starting operation
@@ -343,7 +435,7 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 	}
 
 	// Get topic metadata
-	topicMetadata, err := c.admin.DescribeTopics([]string{table})
+	topicMetadata, err := cl.admin.DescribeTopics([]string{table})
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_table_metadata")
@@ -402,7 +494,7 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 		Name: table,
 	}
 	
-	configs, err := c.admin.DescribeConfig(configResource)
+	configs, err := cl.admin.DescribeConfig(configResource)
 	if err == nil && len(configs) > 0 {
 		for _, config := range configs {
 			if config.Value != "" {
@@ -412,8 +504,8 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 	}
 
 	// Try to get schema from Schema Registry if available
-	if c.schemaClient != nil {
-		if schema, err := c.schemaClient.GetLatestSchema(table + "-value"); err == nil {
+	if cl.schemaClient != nil {
+		if schema, err := cl.schemaClient.GetLatestSchema(table + "-value"); err == nil {
 			metadata.InferredSchema = false
 			columns, err := c.parseSchemaToColumns(schema)
 			if err == nil {
@@ -518,8 +610,9 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 
 // FetchTableStatistics 获取表统计信息
 func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, table string) (*collector.TableStatistics, error) {
-	if c.client == nil {
-		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_statistics")
+	cl, err := c.cluster(catalog, "fetch_table_statistics")
+	if err != nil {
+		return nil, err
 	}
 
 	// Check context before starting operation
@@ -528,7 +621,7 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 	}
 
 	// Get topic metadata for partition information
-	topicMetadata, err := c.admin.DescribeTopics([]string{table})
+	topicMetadata, err := cl.admin.DescribeTopics([]string{table})
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_table_statistics")
@@ -557,7 +650,7 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 	var totalSize int64
 
 	// Create a consumer to get partition offsets
-	consumer, err := sarama.NewConsumerFromClient(c.client)
+	consumer, err := sarama.NewConsumerFromClient(cl.client)
 	if err != nil {
 		return nil, collector.NewQueryError(SourceName, "fetch_table_statistics", err)
 	}
@@ -565,13 +658,13 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 
 	for _, partition := range topicDetail.Partitions {
 		// Get the latest offset for this partition
-		latestOffset, err := c.client.GetOffset(table, partition.ID, sarama.OffsetNewest)
+		latestOffset, err := cl.client.GetOffset(table, partition.ID, sarama.OffsetNewest)
 		if err != nil {
 			continue // Skip this partition if we can't get offset
 		}
 
 		// Get the earliest offset for this partition
-		earliestOffset, err := c.client.GetOffset(table, partition.ID, sarama.OffsetOldest)
+		earliestOffset, err := cl.client.GetOffset(table, partition.ID, sarama.OffsetOldest)
 		if err != nil {
 			continue // Skip this partition if we can't get offset
 		}
@@ -592,13 +685,30 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 		CollectedAt:    time.Now(),
 	}
 
+	// Report the lag of the furthest-behind consumer group, if any
+	if groups, err := c.ListConsumerGroups(ctx, catalog, table); err == nil {
+		for _, group := range groups {
+			if len(group.Lag) == 0 {
+				continue
+			}
+			var lag int64
+			for _, l := range group.Lag {
+				lag += l
+			}
+			if stats.ConsumerLag == nil || lag > *stats.ConsumerLag {
+				stats.ConsumerLag = &lag
+			}
+		}
+	}
+
 	return stats, nil
 }
 
 // FetchPartitions 获取分区信息
 func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table string) ([]collector.PartitionInfo, error) {
-	if c.client == nil {
-		return nil, collector.NewConnectionClosedError(SourceName, "fetch_partitions")
+	cl, err := c.cluster(catalog, "fetch_partitions")
+	if err != nil {
+		return nil, err
 	}
 
 	// Check context before starting operation
@@ -607,7 +717,7 @@ func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table
 	}
 
 	// Get topic metadata
-	topicMetadata, err := c.admin.DescribeTopics([]string{table})
+	topicMetadata, err := cl.admin.DescribeTopics([]string{table})
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_partitions")
@@ -656,12 +766,58 @@ func (c *Collector) Type() string {
 	return SourceName
 }
 
-// parseBrokers parses the endpoint configuration to extract broker addresses
-func (c *Collector) parseBrokers() ([]string, error) {
-	endpoint := c.config.Endpoint
+// parseClusters parses the endpoint configuration into the clusters to
+// connect. A broker list (host:port,...) is a single cluster named
+// DefaultCatalog; named broker lists separated by semicolons
+// (prod=host:port,...;staging=host:port,...) are one cluster each.
+func (c *Collector) parseClusters() ([]*cluster, error) {
+	endpoint := strings.TrimSpace(c.config.Endpoint)
 	if endpoint == "" {
 		return nil, fmt.Errorf("endpoint is required")
 	}
+	if !strings.Contains(endpoint, "=") {
+		brokers, err := parseBrokers(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		return []*cluster{{name: DefaultCatalog, brokers: brokers}}, nil
+	}
+
+	var clusters []*cluster
+	seen := make(map[string]bool)
+	for _, spec := range strings.Split(endpoint, ";") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		name, list, ok := strings.Cut(spec, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid cluster %q, expected name=host:port,...", spec)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate cluster: %s", name)
+		}
+		seen[name] = true
+		brokers, err := parseBrokers(list)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", name, err)
+		}
+		clusters = append(clusters, &cluster{name: name, brokers: brokers})
+	}
+
+	if len(clusters) == 0 {
+		return nil, fmt.Errorf("no clusters found in endpoint: %s", endpoint)
+	}
+
+	return clusters, nil
+}
+
+// parseBrokers parses a comma separated list of broker addresses
+func parseBrokers(endpoint string) ([]string, error) {
+	if strings.TrimSpace(endpoint) == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
 
 	// Split by comma to support multiple brokers
 	brokerStrs := strings.Split(endpoint, ",")
@@ -856,9 +1012,10 @@ func (c *Collector) parseSchemaToColumns(schema *Schema) ([]collector.Column, er
 }
 
 // ListConsumerGroups 获取消费者组列表
-func (c *Collector) ListConsumerGroups(ctx context.Context, topic string) ([]ConsumerGroup, error) {
-	if c.client == nil {
-		return nil, collector.NewConnectionClosedError(SourceName, "list_consumer_groups")
+func (c *Collector) ListConsumerGroups(ctx context.Context, catalog, topic string) ([]ConsumerGroup, error) {
+	cl, err := c.cluster(catalog, "list_consumer_groups")
+	if err != nil {
+		return nil, err
 	}
 
 	// Check context before starting operation
@@ -867,7 +1024,7 @@ func (c *Collector) ListConsumerGroups(ctx context.Context, topic string) ([]Con
 	}
 
 	// Get all consumer groups
-	groups, err := c.admin.ListConsumerGroups()
+	groups, err := cl.admin.ListConsumerGroups()
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "list_consumer_groups")
@@ -878,7 +1035,7 @@ func (c *Collector) ListConsumerGroups(ctx context.Context, topic string) ([]Con
 	var consumerGroups []ConsumerGroup
 	for groupID := range groups {
 		// Get group details
-		groupDetails, err := c.admin.DescribeConsumerGroups([]string{groupID})
+		groupDetails, err := cl.admin.DescribeConsumerGroups([]string{groupID})
 		if err != nil {
 			continue // Skip groups we can't describe
 		}
@@ -909,7 +1066,7 @@ func (c *Collector) ListConsumerGroups(ctx context.Context, topic string) ([]Con
 		// If topic is specified, filter by topic and get lag information
 		if topic != "" {
 			// Get consumer group offsets for the specific topic
-			coordinator, err := c.client.Coordinator(groupID)
+			coordinator, err := cl.client.Coordinator(groupID)
 			if err != nil {
 				continue
 			}
@@ -920,7 +1077,7 @@ func (c *Collector) ListConsumerGroups(ctx context.Context, topic string) ([]Con
 			}
 
 			// Get topic partitions
-			partitions, err := c.client.Partitions(topic)
+			partitions, err := cl.client.Partitions(topic)
 			if err != nil {
 				continue
 			}
@@ -938,7 +1095,7 @@ func (c *Collector) ListConsumerGroups(ctx context.Context, topic string) ([]Con
 			for partition, block := range response.Blocks[topic] {
 				if block.Err == sarama.ErrNoError {
 					// Get latest offset
-					latestOffset, err := c.client.GetOffset(topic, partition, sarama.OffsetNewest)
+					latestOffset, err := cl.client.GetOffset(topic, partition, sarama.OffsetNewest)
 					if err != nil {
 						continue
 					}
@@ -965,8 +1122,12 @@ func (c *Collector) ListConsumerGroups(ctx context.Context, topic string) ([]Con
 }
 
 // FetchSchema 获取 Topic 的 Schema（从 Schema Registry）
-func (c *Collector) FetchSchema(ctx context.Context, topic string) (*MessageSchema, error) {
-	if c.schemaClient == nil {
+func (c *Collector) FetchSchema(ctx context.Context, catalog, topic string) (*MessageSchema, error) {
+	cl, err := c.cluster(catalog, "fetch_schema")
+	if err != nil {
+		return nil, err
+	}
+	if cl.schemaClient == nil {
 		return nil, collector.NewUnsupportedFeatureError(SourceName, "fetch_schema", "Schema Registry not configured")
 	}
 
@@ -976,7 +1137,7 @@ func (c *Collector) FetchSchema(ctx context.Context, topic string) (*MessageSche
 	}
 
 	// Get schemas for the topic
-	keySchema, valueSchema, err := c.schemaClient.GetTopicSchemas(topic)
+	keySchema, valueSchema, err := cl.schemaClient.GetTopicSchemas(topic)
 	if err != nil {
 		return nil, collector.NewQueryError(SourceName, "fetch_schema", err)
 	}
@@ -1009,9 +1170,10 @@ func (c *Collector) FetchSchema(ctx context.Context, topic string) (*MessageSche
 }
 
 // FetchTopicConfig 获取 Topic 配置
-func (c *Collector) FetchTopicConfig(ctx context.Context, topic string) (map[string]string, error) {
-	if c.client == nil {
-		return nil, collector.NewConnectionClosedError(SourceName, "fetch_topic_config")
+func (c *Collector) FetchTopicConfig(ctx context.Context, catalog, topic string) (map[string]string, error) {
+	cl, err := c.cluster(catalog, "fetch_topic_config")
+	if err != nil {
+		return nil, err
 	}
 
 	// Check context before starting operation
@@ -1025,7 +1187,7 @@ func (c *Collector) FetchTopicConfig(ctx context.Context, topic string) (map[str
 		Name: topic,
 	}
 	
-	configs, err := c.admin.DescribeConfig(configResource)
+	configs, err := cl.admin.DescribeConfig(configResource)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_topic_config")
@@ -1045,9 +1207,10 @@ func (c *Collector) FetchTopicConfig(ctx context.Context, topic string) (map[str
 }
 
 // GetTopicPartitionInfo 获取 Topic 分区详细信息
-func (c *Collector) GetTopicPartitionInfo(ctx context.Context, topic string) ([]TopicPartitionInfo, error) {
-	if c.client == nil {
-		return nil, collector.NewConnectionClosedError(SourceName, "get_topic_partition_info")
+func (c *Collector) GetTopicPartitionInfo(ctx context.Context, catalog, topic string) ([]TopicPartitionInfo, error) {
+	cl, err := c.cluster(catalog, "get_topic_partition_info")
+	if err != nil {
+		return nil, err
 	}
 
 	// Check context before starting operation
@@ -1056,7 +1219,7 @@ func (c *Collector) GetTopicPartitionInfo(ctx context.Context, topic string) ([]
 	}
 
 	// Get topic metadata
-	topicMetadata, err := c.admin.DescribeTopics([]string{topic})
+	topicMetadata, err := cl.admin.DescribeTopics([]string{topic})
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "get_topic_partition_info")
@@ -1083,12 +1246,12 @@ func (c *Collector) GetTopicPartitionInfo(ctx context.Context, topic string) ([]
 	var partitionInfos []TopicPartitionInfo
 	for _, partition := range topicDetail.Partitions {
 		// Get partition offsets
-		earliestOffset, err := c.client.GetOffset(topic, partition.ID, sarama.OffsetOldest)
+		earliestOffset, err := cl.client.GetOffset(topic, partition.ID, sarama.OffsetOldest)
 		if err != nil {
 			earliestOffset = -1
 		}
 
-		latestOffset, err := c.client.GetOffset(topic, partition.ID, sarama.OffsetNewest)
+		latestOffset, err := cl.client.GetOffset(topic, partition.ID, sarama.OffsetNewest)
 		if err != nil {
 			latestOffset = -1
 		}
@@ -1115,9 +1278,10 @@ func (c *Collector) GetTopicPartitionInfo(ctx context.Context, topic string) ([]
 }
 
 // GetBrokerInfo 获取 Broker 信息
-func (c *Collector) GetBrokerInfo(ctx context.Context) ([]BrokerInfo, error) {
-	if c.client == nil {
-		return nil, collector.NewConnectionClosedError(SourceName, "get_broker_info")
+func (c *Collector) GetBrokerInfo(ctx context.Context, catalog string) ([]BrokerInfo, error) {
+	cl, err := c.cluster(catalog, "get_broker_info")
+	if err != nil {
+		return nil, err
 	}
 
 	// Check context before starting operation
@@ -1125,7 +1289,7 @@ func (c *Collector) GetBrokerInfo(ctx context.Context) ([]BrokerInfo, error) {
 		return nil, err
 	}
 
-	brokers := c.client.Brokers()
+	brokers := cl.client.Brokers()
 	var brokerInfos []BrokerInfo
 
 	for _, broker := range brokers {
@@ -1144,7 +1308,7 @@ func (c *Collector) GetBrokerInfo(ctx context.Context) ([]BrokerInfo, error) {
 				Name: strconv.Itoa(int(broker.ID())),
 			}
 			
-			configs, err := c.admin.DescribeConfig(configResource)
+			configs, err := cl.admin.DescribeConfig(configResource)
 			if err == nil && len(configs) > 0 {
 				brokerInfo.Config = make(map[string]string)
 				for _, config := range configs {
@@ -2,6 +2,7 @@ package kafka
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -123,13 +124,7 @@ func TestCollector_parseBrokers(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			cfg := &config.ConnectorConfig{
-				Type:     SourceName,
-				Endpoint: tt.endpoint,
-			}
-			
-			c := &Collector{config: cfg}
-			got, err := c.parseBrokers()
+			got, err := parseBrokers(tt.endpoint)
 			
 			if (err != nil) != tt.wantErr {
 				t.Errorf("parseBrokers() error = %v, wantErr %v", err, tt.wantErr)
@@ -152,6 +147,98 @@ func TestCollector_parseBrokers(t *testing.T) {
 	}
 }
 
+func TestCollector_parseClusters(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		want     map[string][]string
+		order    []string
+		wantErr  bool
+	}{
+		{
+			name:     "broker list",
+			endpoint: "broker1:9092,broker2",
+			want:     map[string][]string{DefaultCatalog: {"broker1:9092", "broker2:9092"}},
+			order:    []string{DefaultCatalog},
+		},
+		{
+			name:     "named clusters",
+			endpoint: "prod=prod1:9092,prod2:9092; staging=staging1;",
+			want: map[string][]string{
+				"prod":    {"prod1:9092", "prod2:9092"},
+				"staging": {"staging1:9092"},
+			},
+			order: []string{"prod", "staging"},
+		},
+		{
+			name:     "unnamed cluster",
+			endpoint: "prod=prod1:9092;staging1:9092",
+			wantErr:  true,
+		},
+		{
+			name:     "duplicate cluster",
+			endpoint: "prod=prod1:9092;prod=prod2:9092",
+			wantErr:  true,
+		},
+		{
+			name:     "empty broker list",
+			endpoint: "prod=;staging=staging1",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Collector{config: &config.ConnectorConfig{Type: SourceName, Endpoint: tt.endpoint}}
+			got, err := c.parseClusters()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseClusters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.order) {
+				t.Fatalf("parseClusters() got %d clusters, want %d", len(got), len(tt.order))
+			}
+			for i, cl := range got {
+				if cl.name != tt.order[i] {
+					t.Errorf("cluster[%d] = %s, want %s", i, cl.name, tt.order[i])
+				}
+				if strings.Join(cl.brokers, ",") != strings.Join(tt.want[cl.name], ",") {
+					t.Errorf("cluster %s brokers = %v, want %v", cl.name, cl.brokers, tt.want[cl.name])
+				}
+			}
+		})
+	}
+}
+
+func TestCollector_cluster(t *testing.T) {
+	c := &Collector{config: &config.ConnectorConfig{Type: SourceName, Endpoint: "prod=prod1;staging=staging1"}}
+	c.clusters.Open(func() ([]*cluster, error) {
+		return []*cluster{{name: "prod"}, {name: "staging"}}, nil
+	})
+	for catalog, want := range map[string]string{"": "prod", "prod": "prod", "staging": "staging"} {
+		cl, err := c.cluster(catalog, "list_tables")
+		if err != nil || cl.name != want {
+			t.Errorf("cluster(%q) = %v, %v; want %s", catalog, cl, err, want)
+		}
+	}
+	if _, err := c.cluster("dev", "list_tables"); collector.GetErrorCode(err) != collector.ErrCodeNotFound {
+		t.Errorf("cluster(dev) error = %v, want NOT_FOUND", err)
+	}
+
+	c.config.Properties.Extra = map[string]string{
+		"schema_registry_url":         "http://registry:8081",
+		"schema_registry_url.staging": "http://staging-registry:8081",
+	}
+	if got := c.schemaRegistryURL("prod"); got != "http://registry:8081" {
+		t.Errorf("schemaRegistryURL(prod) = %s", got)
+	}
+	if got := c.schemaRegistryURL("staging"); got != "http://staging-registry:8081" {
+		t.Errorf("schemaRegistryURL(staging) = %s", got)
+	}
+}
+
 func TestCollector_HealthCheck_NotConnected(t *testing.T) {
 	cfg := &config.ConnectorConfig{
 		Type:     SourceName,
@@ -27,11 +27,16 @@ const (
 
 // Collector RabbitMQ 元数据采集器
 type Collector struct {
-	config     *config.ConnectorConfig
-	httpClient *http.Client
-	baseURL    string
-	username   string
-	password   string
+	config *config.ConnectorConfig
+	api    collector.Conn[*managementAPI]
+}
+
+// managementAPI RabbitMQ Management API 的连接
+type managementAPI struct {
+	client   *http.Client
+	baseURL  string
+	username string
+	password string
 }
 
 // NewCollector 创建 RabbitMQ 采集器实例
@@ -50,14 +55,15 @@ func NewCollector(cfg *config.ConnectorConfig) (collector.Collector, error) {
 
 // Connect 建立 RabbitMQ Management API 连接
 func (c *Collector) Connect(ctx context.Context) error {
-	if c.httpClient != nil {
-		return nil // Already connected
-	}
+	return c.api.Open(c.open)
+}
 
+// open 创建 Management API 客户端
+func (c *Collector) open() (*managementAPI, error) {
 	// Parse endpoint
 	baseURL, err := c.parseEndpoint()
 	if err != nil {
-		return collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
+		return nil, collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
 	}
 
 	// Set connection timeout
@@ -66,36 +72,24 @@ func (c *Collector) Connect(ctx context.Context) error {
 		timeout = c.config.Properties.ConnectionTimeout
 	}
 
-	// Create HTTP client
-	c.httpClient = &http.Client{
-		Timeout: time.Duration(timeout) * time.Second,
-	}
-
-	c.baseURL = baseURL
-	c.username = c.config.Credentials.User
-	c.password = c.config.Credentials.Password
-
-	// Test connection with health check
-	_, err = c.HealthCheck(ctx)
-	if err != nil {
-		c.httpClient = nil
-		return err
-	}
-
-	return nil
+	return &managementAPI{
+		client: &http.Client{
+			Timeout: time.Duration(timeout) * time.Second,
+		},
+		baseURL:  baseURL,
+		username: c.config.Credentials.User,
+		password: c.config.Credentials.Password,
+	}, nil
 }
 
 // Close 关闭 RabbitMQ 连接
 func (c *Collector) Close() error {
-	if c.httpClient != nil {
-		c.httpClient = nil
-	}
-	return nil
+	return c.api.Close(func(*managementAPI) error { return nil })
 }
 
 // HealthCheck 健康检查
 func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, error) {
-	if c.httpClient == nil {
+	if _, ok := c.api.Get(); !ok {
 		return &collector.HealthStatus{
 			Connected: false,
 			Message:   "not connected",
@@ -129,7 +123,7 @@ func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogIn
 		return nil, err
 	}
 
-	if c.httpClient == nil {
+	if _, ok := c.api.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "discover_catalogs")
 	}
 
@@ -155,7 +149,7 @@ func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogIn
 
 // ListSchemas 列出 Schema（RabbitMQ 中 schema 等同于 vhost）
 func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string, error) {
-	if c.httpClient == nil {
+	if _, ok := c.api.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_schemas")
 	}
 
@@ -179,7 +173,7 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 
 // ListTables 列出表（RabbitMQ 中表等同于 Queue）
 func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts *collector.ListOptions) (*collector.TableListResult, error) {
-	if c.httpClient == nil {
+	if _, ok := c.api.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_tables")
 	}
 
@@ -237,7 +231,7 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 
 // FetchTableMetadata 获取表元数据（RabbitMQ Queue 元数据）
 func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, table string) (*collector.TableMetadata, error) {
-	if c.httpClient == nil {
+	if _, ok := c.api.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_metadata")
 	}
 
@@ -333,7 +327,7 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 
 // FetchTableStatistics 获取表统计信息
 func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, table string) (*collector.TableStatistics, error) {
-	if c.httpClient == nil {
+	if _, ok := c.api.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_statistics")
 	}
 
@@ -427,7 +421,11 @@ func (c *Collector) parseEndpoint() (string, error) {
 
 // doRequest performs an HTTP request with authentication
 func (c *Collector) doRequest(ctx context.Context, method, path string) (*http.Response, error) {
-	url := c.baseURL + path
+	api, ok := c.api.Get()
+	if !ok {
+		return nil, collector.NewConnectionClosedError(SourceName, "request")
+	}
+	url := api.baseURL + path
 
 	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
@@ -435,14 +433,14 @@ func (c *Collector) doRequest(ctx context.Context, method, path string) (*http.R
 	}
 
 	// Add authentication
-	if c.username != "" && c.password != "" {
-		req.SetBasicAuth(c.username, c.password)
+	if api.username != "" && api.password != "" {
+		req.SetBasicAuth(api.username, api.password)
 	}
 
 	// Set headers
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := api.client.Do(req)
 	if err != nil {
 		return nil, c.wrapConnectionError(err)
 	}
@@ -770,7 +768,7 @@ func (c *Collector) getConsumers(ctx context.Context, vhost string) ([]Consumer,
 
 // ListExchanges lists all exchanges in a vhost
 func (c *Collector) ListExchanges(ctx context.Context, vhost string) ([]Exchange, error) {
-	if c.httpClient == nil {
+	if _, ok := c.api.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_exchanges")
 	}
 
@@ -784,7 +782,7 @@ func (c *Collector) ListExchanges(ctx context.Context, vhost string) ([]Exchange
 
 // ListBindings lists all bindings in a vhost
 func (c *Collector) ListBindings(ctx context.Context, vhost string) ([]Binding, error) {
-	if c.httpClient == nil {
+	if _, ok := c.api.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_bindings")
 	}
 
@@ -798,7 +796,7 @@ func (c *Collector) ListBindings(ctx context.Context, vhost string) ([]Binding,
 
 // ListConsumers lists all consumers in a vhost
 func (c *Collector) ListConsumers(ctx context.Context, vhost string) ([]Consumer, error) {
-	if c.httpClient == nil {
+	if _, ok := c.api.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_consumers")
 	}
 
@@ -812,7 +810,7 @@ func (c *Collector) ListConsumers(ctx context.Context, vhost string) ([]Consumer
 
 // GetQueueBindings gets bindings for a specific queue
 func (c *Collector) GetQueueBindings(ctx context.Context, vhost, queueName string) ([]Binding, error) {
-	if c.httpClient == nil {
+	if _, ok := c.api.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "get_queue_bindings")
 	}
 
@@ -846,7 +844,7 @@ func (c *Collector) GetQueueBindings(ctx context.Context, vhost, queueName strin
 
 // GetExchangeBindings gets bindings for a specific exchange
 func (c *Collector) GetExchangeBindings(ctx context.Context, vhost, exchangeName string) ([]Binding, error) {
-	if c.httpClient == nil {
+	if _, ok := c.api.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "get_exchange_bindings")
 	}
 
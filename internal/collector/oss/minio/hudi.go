@@ -0,0 +1,441 @@
+package minio
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"go-metadata/internal/collector"
+
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	// TableFormatHudi is the table_format property of Apache Hudi tables
+	TableFormatHudi = "hudi"
+
+	// hudiMetaDir is the metadata directory at the root of a Hudi table
+	hudiMetaDir = ".hoodie"
+	// hudiPartitionMetadata marks the partition directories of a Hudi table
+	hudiPartitionMetadata = ".hoodie_partition_metadata"
+)
+
+// Hudi table types, as hoodie.table.type names them
+const (
+	HudiCopyOnWrite = "COPY_ON_WRITE"
+	HudiMergeOnRead = "MERGE_ON_READ"
+)
+
+// hudiInstant matches a completed commit of the Hudi timeline: <instant>.commit
+// before Hudi 1.0 and <instant>_<completion time>.commit, in the timeline
+// directory, since. Requested and inflight instants have another suffix.
+var hudiInstant = regexp.MustCompile(`^(\d+)(?:_\d+)?\.(commit|deltacommit|replacecommit)$`)
+
+// hudiTable 是 Hudi 表的元数据，取自 .hoodie/hoodie.properties 和最近一次提交
+type hudiTable struct {
+	Properties map[string]string
+	// LatestCommit 最近一次完成的提交 (instant time)，没有提交时为空
+	LatestCommit string
+	// Schema 最近一次提交的 Avro Schema，没有时为建表 Schema
+	Schema string
+	// PartitionPaths 最近一次提交写入的分区路径
+	PartitionPaths []string
+}
+
+// TableType 返回 COPY_ON_WRITE 或 MERGE_ON_READ
+func (t *hudiTable) TableType() string {
+	if typ := strings.ToUpper(t.Properties["hoodie.table.type"]); typ != "" {
+		return typ
+	}
+	return HudiCopyOnWrite
+}
+
+// PartitionFields 返回分区字段，去掉 Hudi 1.0 的 key generator 类型后缀 (如 dt:SIMPLE)
+func (t *hudiTable) PartitionFields() []string {
+	return splitFields(t.Properties["hoodie.table.partition.fields"], true)
+}
+
+// RecordKeyFields 返回记录主键字段
+func (t *hudiTable) RecordKeyFields() []string {
+	return splitFields(t.Properties["hoodie.table.recordkey.fields"], false)
+}
+
+// HiveStylePartitioning 判断分区路径是否为 Hive 风格 (dt=2024-06-01)
+func (t *hudiTable) HiveStylePartitioning() bool {
+	if v, ok := t.Properties["hoodie.datasource.write.hive_style_partitioning"]; ok {
+		return strings.EqualFold(v, "true")
+	}
+	for _, p := range t.PartitionPaths {
+		if strings.Contains(p, "=") {
+			return true
+		}
+	}
+	return false
+}
+
+// PartitionLayout 返回分区路径模板，如 dt={dt}/region={region}
+func (t *hudiTable) PartitionLayout() string {
+	fields := t.PartitionFields()
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = "{" + field + "}"
+		if t.HiveStylePartitioning() {
+			parts[i] = field + "=" + parts[i]
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func splitFields(s string, stripType bool) []string {
+	var fields []string
+	for _, field := range strings.Split(s, ",") {
+		if stripType {
+			field, _, _ = strings.Cut(field, ":")
+		}
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// fetchHudiTable 读取 prefix 下的 Hudi 表元数据，prefix 不是 Hudi 表时返回 nil
+func (c *Collector) fetchHudiTable(ctx context.Context, client *minio.Client, bucket, prefix string) (*hudiTable, error) {
+	metaDir := prefix + hudiMetaDir + "/"
+	props, err := c.readObject(ctx, client, bucket, metaDir+"hoodie.properties")
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	table := &hudiTable{Properties: parseHoodieProperties(strings.NewReader(string(props)))}
+
+	// Hudi 1.0 moved the timeline to .hoodie/timeline
+	var names []string
+	for _, dir := range []string{metaDir, metaDir + "timeline/"} {
+		for object := range client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: dir}) {
+			if object.Err != nil {
+				return nil, object.Err
+			}
+			names = append(names, object.Key)
+		}
+	}
+
+	if key := latestHudiCommit(names); key != "" {
+		data, err := c.readObject(ctx, client, bucket, key)
+		if err != nil {
+			return nil, err
+		}
+		table.LatestCommit = hudiInstant.FindStringSubmatch(path.Base(key))[1]
+		table.Schema, table.PartitionPaths = parseHudiCommit(data)
+	}
+	if table.Schema == "" {
+		table.Schema = table.Properties["hoodie.table.create.schema"]
+	}
+	return table, nil
+}
+
+// fetchHudiPartitions 统计 Hudi 表的分区路径 (含 .hoodie_partition_metadata 的目录)
+func (c *Collector) fetchHudiPartitions(ctx context.Context, client *minio.Client, bucket, prefix string, table *hudiTable) ([]collector.PartitionInfo, error) {
+	fields := table.PartitionFields()
+	if len(fields) == 0 {
+		return []collector.PartitionInfo{}, nil
+	}
+
+	count := 0
+	for object := range client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			if ctx.Err() != nil {
+				return nil, collector.WrapContextError(ctx, SourceName, "fetch_partitions")
+			}
+			return nil, collector.NewQueryError(SourceName, "fetch_partitions", object.Err)
+		}
+		if path.Base(object.Key) == hudiPartitionMetadata {
+			count++
+		}
+	}
+
+	return []collector.PartitionInfo{{
+		Name:        "partitions",
+		Type:        "LIST",
+		Columns:     fields,
+		Expression:  table.PartitionLayout(),
+		ValuesCount: count,
+	}}, nil
+}
+
+func (c *Collector) readObject(ctx context.Context, client *minio.Client, bucket, key string) ([]byte, error) {
+	object, err := client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	return io.ReadAll(object)
+}
+
+// applyHudiTable 用 Hudi 表元数据替换对象前缀的元数据
+func applyHudiTable(metadata *collector.TableMetadata, table *hudiTable, location string) error {
+	metadata.Type = collector.TableTypeTable
+	metadata.Storage = &collector.StorageInfo{
+		Format:   TableFormatHudi,
+		Location: location,
+	}
+	metadata.Properties["table_format"] = TableFormatHudi
+	metadata.Properties["hudi.table_type"] = table.TableType()
+	for _, key := range []string{"hoodie.table.name", "hoodie.table.version", "hoodie.table.precombine.field", "hoodie.table.base.file.format"} {
+		if v := table.Properties[key]; v != "" {
+			metadata.Properties[key] = v
+		}
+	}
+	if table.LatestCommit != "" {
+		metadata.Properties["hudi.latest_commit"] = table.LatestCommit
+	}
+
+	if table.Schema != "" {
+		columns, err := avroColumns(table.Schema)
+		if err != nil {
+			return fmt.Errorf("parse hudi schema: %w", err)
+		}
+		metadata.Columns = columns
+		metadata.InferredSchema = false
+	}
+
+	metadata.PrimaryKey = table.RecordKeyFields()
+	partitionFields := table.PartitionFields()
+	for i := range metadata.Columns {
+		col := &metadata.Columns[i]
+		col.IsPrimaryKey = slices.Contains(metadata.PrimaryKey, col.Name)
+		col.IsPartitionColumn = slices.Contains(partitionFields, col.Name)
+	}
+	if len(partitionFields) > 0 {
+		metadata.Partitions = []collector.PartitionInfo{{
+			Name:       "partitions",
+			Type:       "LIST",
+			Columns:    partitionFields,
+			Expression: table.PartitionLayout(),
+		}}
+	}
+	return nil
+}
+
+// parseHoodieProperties 解析 Java properties 格式的 hoodie.properties
+func parseHoodieProperties(r io.Reader) map[string]string {
+	props := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // the create schema is one long line
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		key, value, ok := cutProperty(line)
+		if ok {
+			props[unescapeProperty(key)] = unescapeProperty(value)
+		}
+	}
+	return props
+}
+
+// cutProperty splits a property line at its first unescaped = or :.
+func cutProperty(line string) (string, string, bool) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':':
+			return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+func unescapeProperty(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// latestHudiCommit 返回对象键中最近一次完成的提交
+func latestHudiCommit(keys []string) string {
+	latest, latestInstant := "", ""
+	for _, key := range keys {
+		m := hudiInstant.FindStringSubmatch(path.Base(key))
+		if m != nil && m[1] > latestInstant {
+			latest, latestInstant = key, m[1]
+		}
+	}
+	return latest
+}
+
+// parseHudiCommit 从提交元数据 (HoodieCommitMetadata JSON) 中取出写入 Schema 和分区路径
+func parseHudiCommit(data []byte) (string, []string) {
+	var commit struct {
+		PartitionToWriteStats map[string]json.RawMessage `json:"partitionToWriteStats"`
+		ExtraMetadata         map[string]string          `json:"extraMetadata"`
+	}
+	if err := json.Unmarshal(data, &commit); err != nil {
+		return "", nil
+	}
+	var partitions []string
+	for p := range commit.PartitionToWriteStats {
+		if p != "" {
+			partitions = append(partitions, p)
+		}
+	}
+	sort.Strings(partitions)
+	return commit.ExtraMetadata["schema"], partitions
+}
+
+// avroColumns 将 Avro record Schema 的字段转换为列
+func avroColumns(schema string) ([]collector.Column, error) {
+	var record struct {
+		Type   string `json:"type"`
+		Fields []struct {
+			Name    string          `json:"name"`
+			Type    json.RawMessage `json:"type"`
+			Doc     string          `json:"doc"`
+			Default json.RawMessage `json:"default"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(schema), &record); err != nil {
+		return nil, err
+	}
+	if record.Type != "record" {
+		return nil, fmt.Errorf("expected a record schema, got %q", record.Type)
+	}
+
+	columns := make([]collector.Column, 0, len(record.Fields))
+	for i, field := range record.Fields {
+		col := collector.Column{
+			OrdinalPosition: i + 1,
+			Name:            field.Name,
+			Comment:         field.Doc,
+		}
+		typ, nullable := avroNonNull(field.Type)
+		col.Nullable = nullable
+		col.SourceType = string(typ)
+		col.Type = avroType(typ, &col)
+		if len(field.Default) > 0 && string(field.Default) != "null" {
+			def := string(field.Default)
+			col.Default = &def
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// avroNonNull 返回 ["null", T] 联合类型中的 T 以及是否可空
+func avroNonNull(typ json.RawMessage) (json.RawMessage, bool) {
+	var union []json.RawMessage
+	if err := json.Unmarshal(typ, &union); err != nil {
+		return typ, false
+	}
+	var nonNull []json.RawMessage
+	nullable := false
+	for _, t := range union {
+		if string(t) == `"null"` {
+			nullable = true
+			continue
+		}
+		nonNull = append(nonNull, t)
+	}
+	if len(nonNull) == 1 {
+		return nonNull[0], nullable
+	}
+	return typ, nullable
+}
+
+// avroType 将 Avro 类型映射为 SQL 类型，decimal 的精度写入 col
+func avroType(typ json.RawMessage, col *collector.Column) string {
+	var name string
+	if err := json.Unmarshal(typ, &name); err == nil {
+		return avroPrimitiveType(name)
+	}
+
+	var complex struct {
+		Type        json.RawMessage `json:"type"`
+		LogicalType string          `json:"logicalType"`
+		Precision   int             `json:"precision"`
+		Scale       int             `json:"scale"`
+	}
+	if err := json.Unmarshal(typ, &complex); err != nil {
+		return "TEXT"
+	}
+	switch complex.LogicalType {
+	case "decimal":
+		precision, scale := complex.Precision, complex.Scale
+		col.Precision, col.Scale = &precision, &scale
+		return fmt.Sprintf("DECIMAL(%d,%d)", precision, scale)
+	case "date":
+		return "DATE"
+	case "time-millis", "time-micros":
+		return "TIME"
+	case "timestamp-millis", "timestamp-micros", "local-timestamp-millis", "local-timestamp-micros":
+		return "TIMESTAMP"
+	case "uuid":
+		return "TEXT"
+	}
+
+	var inner string
+	json.Unmarshal(complex.Type, &inner)
+	switch inner {
+	case "record":
+		return "STRUCT"
+	case "array":
+		return "ARRAY"
+	case "map":
+		return "MAP"
+	case "enum":
+		return "TEXT"
+	case "fixed":
+		return "BINARY"
+	}
+	if inner != "" {
+		return avroPrimitiveType(inner)
+	}
+	return "TEXT"
+}
+
+func avroPrimitiveType(name string) string {
+	switch name {
+	case "boolean":
+		return "BOOLEAN"
+	case "int":
+		return "INT"
+	case "long":
+		return "BIGINT"
+	case "float":
+		return "FLOAT"
+	case "double":
+		return "DOUBLE"
+	case "bytes", "fixed":
+		return "BINARY"
+	default:
+		return "TEXT"
+	}
+}
@@ -0,0 +1,169 @@
+package minio
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"go-metadata/internal/collector"
+)
+
+const hoodieProperties = `#Updated at 2024-06-01T08:00:00.000Z
+#Sat Jun 01 08:00:00 UTC 2024
+hoodie.table.type=MERGE_ON_READ
+hoodie.table.name=orders
+hoodie.table.version=6
+hoodie.table.recordkey.fields=order_id
+hoodie.table.precombine.field=updated_at
+hoodie.table.partition.fields=dt,region
+hoodie.datasource.write.hive_style_partitioning=true
+hoodie.table.create.schema={"type"\:"record","name"\:"orders_record","fields"\:[{"name"\:"order_id","type"\:"long"}]}
+`
+
+const hudiCommit = `{
+  "partitionToWriteStats": {
+    "dt=2024-06-01/region=eu": [{"fileId": "f1", "numWrites": 10}],
+    "dt=2024-06-01/region=us": [{"fileId": "f2", "numWrites": 5}]
+  },
+  "compacted": false,
+  "extraMetadata": {
+    "schema": "{\"type\":\"record\",\"name\":\"orders_record\",\"fields\":[{\"name\":\"order_id\",\"type\":\"long\"},{\"name\":\"amount\",\"type\":[\"null\",{\"type\":\"bytes\",\"logicalType\":\"decimal\",\"precision\":10,\"scale\":2}],\"default\":null},{\"name\":\"note\",\"type\":[\"null\",\"string\"],\"doc\":\"free text\"},{\"name\":\"updated_at\",\"type\":{\"type\":\"long\",\"logicalType\":\"timestamp-micros\"}},{\"name\":\"tags\",\"type\":{\"type\":\"array\",\"items\":\"string\"}},{\"name\":\"dt\",\"type\":\"string\"},{\"name\":\"region\",\"type\":\"string\"}]}"
+  },
+  "operationType": "UPSERT"
+}`
+
+func TestParseHoodieProperties(t *testing.T) {
+	props := parseHoodieProperties(strings.NewReader(hoodieProperties))
+
+	if props["hoodie.table.type"] != HudiMergeOnRead || props["hoodie.table.name"] != "orders" {
+		t.Errorf("Unexpected properties: %v", props)
+	}
+	want := `{"type":"record","name":"orders_record","fields":[{"name":"order_id","type":"long"}]}`
+	if got := props["hoodie.table.create.schema"]; got != want {
+		t.Errorf("Expected escaped colons to be unescaped, got %s", got)
+	}
+	if _, ok := props["#Updated at 2024-06-01T08"]; ok {
+		t.Error("Expected comment lines to be skipped")
+	}
+}
+
+func TestLatestHudiCommit(t *testing.T) {
+	keys := []string{
+		"orders/.hoodie/hoodie.properties",
+		"orders/.hoodie/20240101000000000.commit",
+		"orders/.hoodie/20240102000000000.deltacommit",
+		"orders/.hoodie/20240103000000000.deltacommit.requested",
+		"orders/.hoodie/20240103000000000.deltacommit.inflight",
+		"orders/.hoodie/20240101120000000.clean",
+	}
+	if got := latestHudiCommit(keys); got != "orders/.hoodie/20240102000000000.deltacommit" {
+		t.Errorf("latestHudiCommit() = %q", got)
+	}
+
+	// Hudi 1.0 timeline with completion times
+	keys = append(keys, "orders/.hoodie/timeline/20240104000000000_20240104000010000.commit")
+	if got := latestHudiCommit(keys); got != "orders/.hoodie/timeline/20240104000000000_20240104000010000.commit" {
+		t.Errorf("latestHudiCommit() = %q", got)
+	}
+
+	if got := latestHudiCommit([]string{"orders/.hoodie/hoodie.properties"}); got != "" {
+		t.Errorf("Expected no commit for a table without commits, got %q", got)
+	}
+}
+
+func TestApplyHudiTable(t *testing.T) {
+	table := &hudiTable{
+		Properties:   parseHoodieProperties(strings.NewReader(hoodieProperties)),
+		LatestCommit: "20240102000000000",
+	}
+	table.Schema, table.PartitionPaths = parseHudiCommit([]byte(hudiCommit))
+
+	metadata := &collector.TableMetadata{
+		Type:           collector.TableTypeBucket,
+		InferredSchema: true,
+		Properties:     map[string]string{},
+	}
+	if err := applyHudiTable(metadata, table, "s3://lake/orders"); err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata.Type != collector.TableTypeTable || metadata.InferredSchema {
+		t.Errorf("Expected a table with a declared schema, got %s (inferred %v)", metadata.Type, metadata.InferredSchema)
+	}
+	if metadata.Storage == nil || metadata.Storage.Format != TableFormatHudi || metadata.Storage.Location != "s3://lake/orders" {
+		t.Errorf("Unexpected storage: %+v", metadata.Storage)
+	}
+	if metadata.Properties["hudi.table_type"] != HudiMergeOnRead || metadata.Properties["hudi.latest_commit"] != "20240102000000000" {
+		t.Errorf("Unexpected properties: %v", metadata.Properties)
+	}
+	if !slices.Equal(metadata.PrimaryKey, []string{"order_id"}) {
+		t.Errorf("PrimaryKey = %v", metadata.PrimaryKey)
+	}
+
+	wantTypes := []string{"BIGINT", "DECIMAL(10,2)", "TEXT", "TIMESTAMP", "ARRAY", "TEXT", "TEXT"}
+	if len(metadata.Columns) != len(wantTypes) {
+		t.Fatalf("Expected the columns of the latest commit schema, got %+v", metadata.Columns)
+	}
+	for i, col := range metadata.Columns {
+		if col.Type != wantTypes[i] {
+			t.Errorf("Column %s type = %s, want %s", col.Name, col.Type, wantTypes[i])
+		}
+	}
+	amount, note := metadata.Columns[1], metadata.Columns[2]
+	if !amount.Nullable || amount.Default != nil || *amount.Precision != 10 || *amount.Scale != 2 {
+		t.Errorf("Unexpected amount column: %+v", amount)
+	}
+	if note.Comment != "free text" || metadata.Columns[0].Nullable || !metadata.Columns[0].IsPrimaryKey {
+		t.Errorf("Unexpected columns: %+v", metadata.Columns)
+	}
+	if !metadata.Columns[5].IsPartitionColumn || !metadata.Columns[6].IsPartitionColumn {
+		t.Error("Expected dt and region to be partition columns")
+	}
+
+	if len(metadata.Partitions) != 1 {
+		t.Fatalf("Expected the partition layout, got %+v", metadata.Partitions)
+	}
+	if p := metadata.Partitions[0]; !slices.Equal(p.Columns, []string{"dt", "region"}) || p.Expression != "dt={dt}/region={region}" {
+		t.Errorf("Unexpected partition layout: %+v", p)
+	}
+}
+
+func TestHudiTable_PartitionLayout(t *testing.T) {
+	tests := []struct {
+		name  string
+		table hudiTable
+		want  string
+	}{
+		{
+			name:  "plain paths",
+			table: hudiTable{Properties: map[string]string{"hoodie.table.partition.fields": "dt"}, PartitionPaths: []string{"2024-06-01"}},
+			want:  "{dt}",
+		},
+		{
+			name:  "hive style paths",
+			table: hudiTable{Properties: map[string]string{"hoodie.table.partition.fields": "dt"}, PartitionPaths: []string{"dt=2024-06-01"}},
+			want:  "dt={dt}",
+		},
+		{
+			name:  "key generator types",
+			table: hudiTable{Properties: map[string]string{"hoodie.table.partition.fields": "dt:TIMESTAMP,region:SIMPLE"}},
+			want:  "{dt}/{region}",
+		},
+		{
+			name:  "non-partitioned",
+			table: hudiTable{Properties: map[string]string{}},
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.table.PartitionLayout(); got != tt.want {
+				t.Errorf("PartitionLayout() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := (&hudiTable{Properties: map[string]string{}}).TableType(); got != HudiCopyOnWrite {
+		t.Errorf("Expected tables without a type to be copy-on-write, got %s", got)
+	}
+}
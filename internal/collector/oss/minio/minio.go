@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"go-metadata/internal/collector/config"
 	"go-metadata/internal/collector/infer"
 	"go-metadata/internal/collector/matcher"
+	"go-metadata/internal/tlsprofile"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -30,7 +32,7 @@ const (
 // Collector MinIO/S3 元数据采集器
 type Collector struct {
 	config       *config.ConnectorConfig
-	client       *minio.Client
+	client       collector.Conn[*minio.Client]
 	fileInferrer *infer.FileSchemaInferrer
 }
 
@@ -47,10 +49,11 @@ func NewCollector(cfg *config.ConnectorConfig) (collector.Collector, error) {
 	var inferConfig *infer.InferConfig
 	if cfg.Infer != nil {
 		inferConfig = &infer.InferConfig{
-			Enabled:    cfg.Infer.Enabled,
-			SampleSize: cfg.Infer.SampleSize,
-			MaxDepth:   cfg.Infer.MaxDepth,
-			TypeMerge:  infer.TypeMergeStrategy(cfg.Infer.TypeMerge),
+			Enabled:      cfg.Infer.Enabled,
+			SampleSize:   cfg.Infer.SampleSize,
+			MaxDepth:     cfg.Infer.MaxDepth,
+			TypeMerge:    infer.TypeMergeStrategy(cfg.Infer.TypeMerge),
+			SampleValues: infer.SampleValueMode(cfg.Infer.SampleValues),
 		}
 	} else {
 		inferConfig = infer.DefaultInferConfig()
@@ -66,14 +69,15 @@ func NewCollector(cfg *config.ConnectorConfig) (collector.Collector, error) {
 
 // Connect 建立 MinIO 连接
 func (c *Collector) Connect(ctx context.Context) error {
-	if c.client != nil {
-		return nil // Already connected
-	}
+	return c.client.Open(func() (*minio.Client, error) { return c.open(ctx) })
+}
 
+// open 建立连接并检查连通性
+func (c *Collector) open(ctx context.Context) (*minio.Client, error) {
 	// Parse endpoint
 	endpoint, secure, err := c.parseEndpoint()
 	if err != nil {
-		return collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
+		return nil, collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
 	}
 
 	// Get region from extra properties
@@ -95,12 +99,13 @@ func (c *Collector) Connect(ctx context.Context) error {
 
 	// Create MinIO client
 	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  creds,
-		Secure: secure,
-		Region: region,
+		Creds:     creds,
+		Secure:    secure,
+		Region:    region,
+		Transport: tlsprofile.Transport(),
 	})
 	if err != nil {
-		return c.wrapConnectionError(err)
+		return nil, c.wrapConnectionError(err)
 	}
 
 	// Set timeout if configured
@@ -115,23 +120,22 @@ func (c *Collector) Connect(ctx context.Context) error {
 
 	_, err = client.ListBuckets(ctx)
 	if err != nil {
-		return c.wrapConnectionError(err)
+		return nil, c.wrapConnectionError(err)
 	}
 
-	c.client = client
-	return nil
+	return client, nil
 }
 
 // Close 关闭 MinIO 连接
 func (c *Collector) Close() error {
 	// MinIO client doesn't require explicit closing
-	c.client = nil
-	return nil
+	return c.client.Close(func(*minio.Client) error { return nil })
 }
 
 // HealthCheck 健康检查
 func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return &collector.HealthStatus{
 			Connected: false,
 			Message:   "not connected",
@@ -141,7 +145,7 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 	start := time.Now()
 
 	// Try to list buckets to verify connectivity
-	_, err := c.client.ListBuckets(ctx)
+	_, err := client.ListBuckets(ctx)
 	if err != nil {
 		return &collector.HealthStatus{
 			Connected: false,
@@ -165,7 +169,7 @@ func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogIn
 		return nil, err
 	}
 
-	if c.client == nil {
+	if _, ok := c.client.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "discover_catalogs")
 	}
 
@@ -184,7 +188,8 @@ func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogIn
 
 // ListSchemas 列出 Schema（MinIO 中 schema 等同于 bucket）
 func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_schemas")
 	}
 
@@ -194,7 +199,7 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 	}
 
 	// List all buckets
-	buckets, err := c.client.ListBuckets(ctx)
+	buckets, err := client.ListBuckets(ctx)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "list_schemas")
@@ -215,7 +220,8 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 
 // ListTables 列出表（MinIO 中表等同于对象前缀）
 func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts *collector.ListOptions) (*collector.TableListResult, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_tables")
 	}
 
@@ -225,7 +231,7 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 	}
 
 	// List object prefixes as "tables"
-	prefixes, err := c.listPrefixes(ctx, schema, "", "/")
+	prefixes, err := c.listPrefixes(ctx, client, schema, "", "/")
 	if err != nil {
 		return nil, err
 	}
@@ -264,7 +270,8 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 
 // FetchTableMetadata 获取表元数据（对象前缀元数据）
 func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, table string) (*collector.TableMetadata, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_metadata")
 	}
 
@@ -291,7 +298,7 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 		prefix += "/"
 	}
 
-	objectCh := c.client.ListObjects(ctx, schema, minio.ListObjectsOptions{
+	objectCh := client.ListObjects(ctx, schema, minio.ListObjectsOptions{
 		Prefix:    prefix,
 		Recursive: false,
 		MaxKeys:   100, // Limit for metadata analysis
@@ -315,9 +322,22 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 	metadata.Properties["object_count"] = fmt.Sprintf("%d", objectCount)
 	metadata.Properties["total_size"] = fmt.Sprintf("%d", totalSize)
 
+	// Hudi tables are described by their .hoodie metadata rather than the files
+	hudi, err := c.fetchHudiTable(ctx, client, schema, prefix)
+	if err != nil {
+		return nil, collector.NewQueryError(SourceName, "fetch_table_metadata", err)
+	}
+	if hudi != nil {
+		location := fmt.Sprintf("s3://%s/%s", schema, strings.TrimSuffix(prefix, "/"))
+		if err := applyHudiTable(metadata, hudi, location); err != nil {
+			return nil, collector.NewParseError(SourceName, "fetch_table_metadata", err)
+		}
+		return metadata, nil
+	}
+
 	// Try to infer schema from file objects
 	if c.fileInferrer.GetConfig().Enabled && len(objects) > 0 {
-		columns, err := c.inferSchemaFromObjects(ctx, schema, objects)
+		columns, err := c.inferSchemaFromObjects(ctx, client, schema, objects)
 		if err == nil && len(columns) > 0 {
 			metadata.Columns = columns
 			metadata.InferredSchema = true
@@ -375,7 +395,8 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 
 // FetchTableStatistics 获取表统计信息
 func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, table string) (*collector.TableStatistics, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_statistics")
 	}
 
@@ -390,27 +411,37 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 		prefix += "/"
 	}
 
-	objectCh := c.client.ListObjects(ctx, schema, minio.ListObjectsOptions{
+	objectCh := client.ListObjects(ctx, schema, minio.ListObjectsOptions{
 		Prefix:    prefix,
 		Recursive: true, // Recursive for complete statistics
 	})
 
 	var totalSize int64
 	var objectCount int64
+	partitionCount := 0
 
 	for object := range objectCh {
 		if object.Err != nil {
 			return nil, collector.NewQueryError(SourceName, "fetch_table_statistics", object.Err)
 		}
 
+		// Hudi metadata is not table data
+		if strings.HasPrefix(object.Key, prefix+hudiMetaDir+"/") {
+			continue
+		}
+		if path.Base(object.Key) == hudiPartitionMetadata {
+			partitionCount++
+			continue
+		}
 		totalSize += object.Size
 		objectCount++
 	}
 
 	stats := &collector.TableStatistics{
-		RowCount:      objectCount,
-		DataSizeBytes: totalSize,
-		CollectedAt:   time.Now(),
+		RowCount:       objectCount,
+		DataSizeBytes:  totalSize,
+		PartitionCount: partitionCount,
+		CollectedAt:    time.Now(),
 	}
 
 	return stats, nil
@@ -418,7 +449,8 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 
 // FetchPartitions 获取分区信息（对象存储中分区等同于子前缀）
 func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table string) ([]collector.PartitionInfo, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_partitions")
 	}
 
@@ -433,7 +465,15 @@ func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table
 		prefix += "/"
 	}
 
-	subPrefixes, err := c.listPrefixes(ctx, schema, prefix, "/")
+	hudi, err := c.fetchHudiTable(ctx, client, schema, prefix)
+	if err != nil {
+		return nil, collector.NewQueryError(SourceName, "fetch_partitions", err)
+	}
+	if hudi != nil {
+		return c.fetchHudiPartitions(ctx, client, schema, prefix, hudi)
+	}
+
+	subPrefixes, err := c.listPrefixes(ctx, client, schema, prefix, "/")
 	if err != nil {
 		return nil, err
 	}
@@ -584,8 +624,8 @@ func (c *Collector) filterTables(tables []string, opts *collector.ListOptions) [
 // Ensure Collector implements collector.Collector interface
 var _ collector.Collector = (*Collector)(nil)
 // listPrefixes lists object prefixes in a bucket (used as "tables")
-func (c *Collector) listPrefixes(ctx context.Context, bucket, prefix, delimiter string) ([]string, error) {
-	objectCh := c.client.ListObjects(ctx, bucket, minio.ListObjectsOptions{
+func (c *Collector) listPrefixes(ctx context.Context, client *minio.Client, bucket, prefix, delimiter string) ([]string, error) {
+	objectCh := client.ListObjects(ctx, bucket, minio.ListObjectsOptions{
 		Prefix:    prefix,
 		Recursive: false,
 		MaxKeys:   1000, // Reasonable limit for prefix discovery
@@ -628,11 +668,11 @@ func (c *Collector) listPrefixes(ctx context.Context, bucket, prefix, delimiter
 }
 
 // inferSchemaFromObjects attempts to infer schema from object files
-func (c *Collector) inferSchemaFromObjects(ctx context.Context, bucket string, objects []minio.ObjectInfo) ([]collector.Column, error) {
+func (c *Collector) inferSchemaFromObjects(ctx context.Context, client *minio.Client, bucket string, objects []minio.ObjectInfo) ([]collector.Column, error) {
 	// Look for structured files (CSV, JSON, Parquet)
 	for _, obj := range objects {
 		if c.isStructuredFile(obj.Key) {
-			columns, err := c.inferSchemaFromFile(ctx, bucket, obj.Key)
+			columns, err := c.inferSchemaFromFile(ctx, client, bucket, obj.Key)
 			if err == nil && len(columns) > 0 {
 				return columns, nil
 			}
@@ -651,9 +691,9 @@ func (c *Collector) isStructuredFile(key string) bool {
 }
 
 // inferSchemaFromFile infers schema from a specific file
-func (c *Collector) inferSchemaFromFile(ctx context.Context, bucket, key string) ([]collector.Column, error) {
+func (c *Collector) inferSchemaFromFile(ctx context.Context, client *minio.Client, bucket, key string) ([]collector.Column, error) {
 	// Get object
-	object, err := c.client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	object, err := client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
 	}
@@ -696,25 +736,28 @@ func (c *Collector) inferSchemaFromFile(ctx context.Context, bucket, key string)
 
 // ListPrefixes 列出对象前缀（作为 Schema）
 func (c *Collector) ListPrefixes(ctx context.Context, bucket, prefix string, delimiter string) ([]string, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_prefixes")
 	}
 
-	return c.listPrefixes(ctx, bucket, prefix, delimiter)
+	return c.listPrefixes(ctx, client, bucket, prefix, delimiter)
 }
 
 // InferFileSchema 推断文件 Schema
 func (c *Collector) InferFileSchema(ctx context.Context, bucket, key string) ([]collector.Column, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "infer_file_schema")
 	}
 
-	return c.inferSchemaFromFile(ctx, bucket, key)
+	return c.inferSchemaFromFile(ctx, client, bucket, key)
 }
 
 // GetBucketPolicy 获取 Bucket 策略
 func (c *Collector) GetBucketPolicy(ctx context.Context, bucket string) (*BucketPolicy, error) {
-	if c.client == nil {
+	client, ok := c.client.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "get_bucket_policy")
 	}
 
@@ -728,7 +771,7 @@ func (c *Collector) GetBucketPolicy(ctx context.Context, bucket string) (*Bucket
 	}
 
 	// Get bucket policy
-	policyStr, err := c.client.GetBucketPolicy(ctx, bucket)
+	policyStr, err := client.GetBucketPolicy(ctx, bucket)
 	if err != nil {
 		// Policy might not exist, which is not an error
 		policy.Policy = ""
@@ -737,13 +780,13 @@ func (c *Collector) GetBucketPolicy(ctx context.Context, bucket string) (*Bucket
 	}
 
 	// Get bucket versioning
-	versioningConfig, err := c.client.GetBucketVersioning(ctx, bucket)
+	versioningConfig, err := client.GetBucketVersioning(ctx, bucket)
 	if err == nil {
 		policy.Versioning = versioningConfig.Status == "Enabled"
 	}
 
 	// Get bucket encryption
-	encryptionConfig, err := c.client.GetBucketEncryption(ctx, bucket)
+	encryptionConfig, err := client.GetBucketEncryption(ctx, bucket)
 	if err == nil && encryptionConfig != nil {
 		// MinIO encryption configuration is complex, simplify for metadata
 		policy.Encryption = "enabled"
@@ -0,0 +1,29 @@
+package collector
+
+import (
+	"context"
+	"time"
+)
+
+// QueryLogCollector 可选接口：读取数据库的查询日志 (如 pg_stat_statements、MySQL general log 表)，
+// 用于从实际执行的语句中采集血缘
+type QueryLogCollector interface {
+	// FetchQueryLog 返回 since 之后执行的语句，相同语句合并为一条并给出执行次数。
+	// 日志不记录执行时间时返回全部语句，Cumulative 为 true
+	FetchQueryLog(ctx context.Context, since time.Time) ([]QueryLogEntry, error)
+}
+
+// QueryLogEntry 查询日志中的一条语句
+type QueryLogEntry struct {
+	// ID 标识语句，如 pg_stat_statements 的 queryid；日志不提供时为空
+	ID string `json:"id,omitempty"`
+	// Database 执行语句的数据库，日志不记录时为空
+	Database string `json:"database,omitempty"`
+	SQL      string `json:"sql"`
+	// Calls 执行次数。Cumulative 为 true 时是统计重置以来的累计次数，
+	// 两次读取之间的执行次数为其差值
+	Calls      int64 `json:"calls"`
+	Cumulative bool  `json:"cumulative,omitempty"`
+	// LastExecuted 最后一次执行的时间，日志不记录时为零值
+	LastExecuted time.Time `json:"last_executed,omitempty"`
+}
@@ -0,0 +1,170 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/config"
+)
+
+// benchTables is the size of the schema the collection benchmarks sync.
+const benchTables = 10000
+
+// BenchmarkCollectSchema syncs a schema of 10k tables, listing them and
+// fetching the metadata of each, against a driver answering with canned rows.
+// It measures the collector's own overhead, not the server's.
+func BenchmarkCollectSchema(b *testing.B) {
+	c := newBenchCollector(benchTables, 20)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		list, err := c.ListTables(ctx, "def", "shop", nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, table := range list.Tables {
+			if _, err := c.FetchTableMetadata(ctx, "def", "shop", table); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkListTables lists a schema of 10k tables a page at a time through a
+// glob filter.
+func BenchmarkListTables(b *testing.B) {
+	c := newBenchCollector(benchTables, 0)
+	ctx := context.Background()
+	opts := &collector.ListOptions{
+		PageSize: 500,
+		Filter:   &collector.MatchingRule{Include: []string{"t_*"}, Exclude: []string{"*_tmp"}},
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		opts.PageToken = ""
+		for {
+			page, err := c.ListTables(ctx, "def", "shop", opts)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if page.NextPageToken == "" {
+				break
+			}
+			opts.PageToken = page.NextPageToken
+		}
+	}
+}
+
+func newBenchCollector(tables, columns int) *Collector {
+	results := map[string]*cannedResult{
+		queryGetTableInfo:      {rows: [][]driver.Value{{"BASE TABLE", "benchmark table", "utf8mb4_0900_ai_ci", "utf8mb4"}}},
+		queryGetServerSettings: {rows: [][]driver.Value{{"SYSTEM", "UTC", "utf8mb4", "utf8mb4_0900_ai_ci"}}},
+		queryGetPrimaryKeys:    {rows: [][]driver.Value{{"c000"}}},
+		queryGetIndexes: {rows: [][]driver.Value{
+			{"PRIMARY", "c000", int64(0), "BTREE", ""},
+			{"idx_c001", "c001", int64(1), "BTREE", ""},
+			{"idx_c001", "c002", int64(1), "BTREE", ""},
+		}},
+	}
+
+	list := &cannedResult{}
+	for i := range tables {
+		name := fmt.Sprintf("t_%05d", i)
+		if i%10 == 0 {
+			name += "_tmp"
+		}
+		list.rows = append(list.rows, []driver.Value{name})
+	}
+	results[queryListTables] = list
+
+	cols := &cannedResult{}
+	for i := range columns {
+		cols.rows = append(cols.rows, []driver.Value{
+			int64(i + 1), fmt.Sprintf("c%03d", i), "varchar", "varchar(255)",
+			int64(255), nil, nil,
+			"YES", nil, "", "", "column comment",
+			nil, "utf8mb4", "utf8mb4_0900_ai_ci",
+		})
+	}
+	results[queryGetColumns] = cols
+
+	c := &Collector{config: &config.ConnectorConfig{Type: SourceName}}
+	c.db.Open(func() (*sql.DB, error) { return sql.OpenDB(cannedConnector{results}), nil })
+	return c
+}
+
+// cannedResult is the result a query is answered with, whatever its arguments.
+type cannedResult struct {
+	rows [][]driver.Value
+}
+
+// cannedConnector is a database/sql driver answering queries from a map of
+// canned results. Unknown queries return no rows.
+type cannedConnector struct {
+	results map[string]*cannedResult
+}
+
+func (c cannedConnector) Connect(context.Context) (driver.Conn, error) {
+	return cannedConn(c), nil
+}
+
+func (c cannedConnector) Driver() driver.Driver {
+	return nil
+}
+
+type cannedConn struct {
+	results map[string]*cannedResult
+}
+
+func (c cannedConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	rows := &cannedRows{}
+	if r := c.results[query]; r != nil {
+		rows.rows = r.rows
+	}
+	return rows, nil
+}
+
+func (c cannedConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c cannedConn) Close() error {
+	return nil
+}
+
+func (c cannedConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type cannedRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *cannedRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *cannedRows) Close() error {
+	return nil
+}
+
+func (r *cannedRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
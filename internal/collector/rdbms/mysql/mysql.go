@@ -3,17 +3,24 @@ package mysql
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"go-metadata/internal/collector"
 	"go-metadata/internal/collector/config"
 	"go-metadata/internal/collector/matcher"
+	"go-metadata/internal/tlsprofile"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
 const (
@@ -28,7 +35,11 @@ const (
 // Collector MySQL 元数据采集器
 type Collector struct {
 	config *config.ConnectorConfig
-	db     *sql.DB
+	db     collector.Conn[*sql.DB]
+
+	// settings caches the server settings for the lifetime of the connection
+	settingsMu sync.Mutex
+	settings   map[string]string
 }
 
 // NewCollector 创建 MySQL 采集器实例
@@ -44,19 +55,37 @@ func NewCollector(cfg *config.ConnectorConfig) (collector.Collector, error) {
 
 // Connect 建立 MySQL 连接
 func (c *Collector) Connect(ctx context.Context) error {
-	if c.db != nil {
-		return nil // Already connected
-	}
+	return c.db.Open(func() (db *sql.DB, err error) {
+		// Prefer the read replica, falling back to the primary endpoint
+		for _, endpoint := range c.config.Endpoints() {
+			if db, err = c.open(ctx, endpoint); err == nil {
+				return db, nil
+			}
+		}
+		return nil, err
+	})
+}
 
-	dsn, err := c.buildDSN()
+// open 连接指定地址并检查连通性
+func (c *Collector) open(ctx context.Context, endpoint string) (*sql.DB, error) {
+	dsn, err := c.buildDSN(endpoint)
 	if err != nil {
-		return collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
+		return nil, collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
 	}
 
-	db, err := sql.Open("mysql", dsn)
+	// Parse the DSN ourselves to apply the outbound TLS profile when tls is set
+	mysqlConfig, err := mysql.ParseDSN(dsn)
 	if err != nil {
-		return collector.NewNetworkError(SourceName, "connect", err)
+		return nil, collector.NewNetworkError(SourceName, "connect", err)
 	}
+	if mysqlConfig.TLS != nil {
+		mysqlConfig.TLS = tlsprofile.Restrict(mysqlConfig.TLS)
+	}
+	connector, err := mysql.NewConnector(mysqlConfig)
+	if err != nil {
+		return nil, collector.NewNetworkError(SourceName, "connect", err)
+	}
+	db := sql.OpenDB(connector)
 
 	// Configure connection pool
 	if c.config.Properties.MaxOpenConns > 0 {
@@ -72,26 +101,26 @@ func (c *Collector) Connect(ctx context.Context) error {
 	// Test connection with context
 	if err := db.PingContext(ctx); err != nil {
 		db.Close()
-		return c.wrapConnectionError(err)
+		return nil, c.wrapConnectionError(err)
 	}
 
-	c.db = db
-	return nil
+	return db, nil
 }
 
 // Close 关闭 MySQL 连接
 func (c *Collector) Close() error {
-	if c.db != nil {
-		err := c.db.Close()
-		c.db = nil
-		return err
-	}
-	return nil
+	return c.db.Close(func(db *sql.DB) error {
+		c.settingsMu.Lock()
+		c.settings = nil
+		c.settingsMu.Unlock()
+		return db.Close()
+	})
 }
 
 // HealthCheck 健康检查
 func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return &collector.HealthStatus{
 			Connected: false,
 			Message:   "not connected",
@@ -101,7 +130,7 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 	start := time.Now()
 
 	// Ping to check connection
-	if err := c.db.PingContext(ctx); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return &collector.HealthStatus{
 			Connected: false,
 			Latency:   time.Since(start),
@@ -111,7 +140,7 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 
 	// Get MySQL version
 	var version string
-	err := c.db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version)
+	err := db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version)
 	if err != nil {
 		return &collector.HealthStatus{
 			Connected: true,
@@ -130,32 +159,43 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 
 // DiscoverCatalogs 发现 Catalog（MySQL 中 catalog 等同于数据库实例）
 func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogInfo, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "discover_catalogs")
 	}
 
 	// Get MySQL version for catalog info
 	var version string
-	if err := c.db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+	if err := db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
 		return nil, collector.NewQueryError(SourceName, "discover_catalogs", err)
 	}
 
+	settings, err := c.serverSettings(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	properties := map[string]string{
+		"version": version,
+	}
+	for k, v := range settings {
+		properties[k] = v
+	}
+
 	// MySQL typically has one catalog per connection
 	return []collector.CatalogInfo{
 		{
 			Catalog:     "def",
 			Type:        SourceName,
 			Description: "MySQL Server",
-			Properties: map[string]string{
-				"version": version,
-			},
+			Properties:  properties,
 		},
 	}, nil
 }
 
 // ListSchemas 列出 Schema（MySQL 中 schema 等同于 database）
 func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_schemas")
 	}
 
@@ -164,7 +204,7 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 		return nil, err
 	}
 
-	rows, err := c.db.QueryContext(ctx, queryListDatabases)
+	rows, err := db.QueryContext(ctx, queryListDatabases)
 	if err != nil {
 		// Check if error is due to context cancellation
 		if ctx.Err() != nil {
@@ -219,7 +259,8 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 
 // ListTables 列出表
 func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts *collector.ListOptions) (*collector.TableListResult, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_tables")
 	}
 
@@ -228,7 +269,7 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 		return nil, err
 	}
 
-	rows, err := c.db.QueryContext(ctx, queryListTables, schema)
+	rows, err := db.QueryContext(ctx, queryListTables, schema)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "list_tables")
@@ -344,7 +385,8 @@ func (c *Collector) filterTables(tables []string, opts *collector.ListOptions) [
 
 // FetchTableMetadata 获取表元数据
 func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, table string) (*collector.TableMetadata, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_metadata")
 	}
 
@@ -354,8 +396,8 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 	}
 
 	// Get table basic info
-	var tableType, comment sql.NullString
-	err := c.db.QueryRowContext(ctx, queryGetTableInfo, schema, table).Scan(&tableType, &comment)
+	var tableType, comment, tableCollation, tableCharset sql.NullString
+	err := db.QueryRowContext(ctx, queryGetTableInfo, schema, table).Scan(&tableType, &comment, &tableCollation, &tableCharset)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_table_metadata")
@@ -375,13 +417,29 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 		LastRefreshedAt: time.Now(),
 	}
 
+	// Record the table's default charset and the server time zone, which
+	// determine how string and TIMESTAMP values must be read elsewhere
+	settings, err := c.serverSettings(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Properties = map[string]string{
+		"time_zone": settings["time_zone"],
+	}
+	if tableCollation.Valid {
+		metadata.Properties["collation"] = tableCollation.String
+	}
+	if tableCharset.Valid {
+		metadata.Properties["charset"] = tableCharset.String
+	}
+
 	// Check context before fetching columns
 	if err := collector.CheckContext(ctx, SourceName, "fetch_table_metadata"); err != nil {
 		return nil, err
 	}
 
 	// Get columns
-	columns, err := c.fetchColumns(ctx, schema, table)
+	columns, err := c.fetchColumns(ctx, db, schema, table)
 	if err != nil {
 		return nil, err
 	}
@@ -393,7 +451,7 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 	}
 
 	// Get primary keys
-	primaryKeys, err := c.fetchPrimaryKeys(ctx, schema, table)
+	primaryKeys, err := c.fetchPrimaryKeys(ctx, db, schema, table)
 	if err != nil {
 		return nil, err
 	}
@@ -410,6 +468,17 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 		}
 	}
 
+	// Check context before fetching check constraints
+	if err := collector.CheckContext(ctx, SourceName, "fetch_table_metadata"); err != nil {
+		return nil, err
+	}
+
+	checks, err := c.fetchCheckConstraints(ctx, db, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	metadata.CheckConstraints = checks
+
 	// Get indexes if configured
 	if c.config.Collect == nil || c.config.Collect.Indexes {
 		// Check context before fetching indexes
@@ -417,7 +486,7 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 			return nil, err
 		}
 
-		indexes, err := c.fetchIndexes(ctx, schema, table)
+		indexes, err := c.fetchIndexes(ctx, db, schema, table)
 		if err != nil {
 			return nil, err
 		}
@@ -428,13 +497,13 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 }
 
 // fetchColumns retrieves column information for a table
-func (c *Collector) fetchColumns(ctx context.Context, schema, table string) ([]collector.Column, error) {
+func (c *Collector) fetchColumns(ctx context.Context, db *sql.DB, schema, table string) ([]collector.Column, error) {
 	// Check context before starting
 	if err := collector.CheckContext(ctx, SourceName, "fetch_columns"); err != nil {
 		return nil, err
 	}
 
-	rows, err := c.db.QueryContext(ctx, queryGetColumns, schema, table)
+	rows, err := db.QueryContext(ctx, queryGetColumns, schema, table)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_columns")
@@ -451,17 +520,19 @@ func (c *Collector) fetchColumns(ctx context.Context, schema, table string) ([]c
 		}
 
 		var (
-			ordinalPos                                                int
-			name, dataType, columnType                                string
-			charMaxLen, numPrecision, numScale                        sql.NullInt64
-			isNullable, columnKey, extra                              string
-			columnDefault, columnComment                              sql.NullString
+			ordinalPos                                   int
+			name, dataType, columnType                   string
+			charMaxLen, numPrecision, numScale           sql.NullInt64
+			isNullable, columnKey, extra                 string
+			columnDefault, columnComment, generationExpr sql.NullString
+			charset, collation                           sql.NullString
 		)
 
 		err := rows.Scan(
 			&ordinalPos, &name, &dataType, &columnType,
 			&charMaxLen, &numPrecision, &numScale,
 			&isNullable, &columnDefault, &columnKey, &extra, &columnComment,
+			&generationExpr, &charset, &collation,
 		)
 		if err != nil {
 			return nil, collector.NewParseError(SourceName, "fetch_columns", err)
@@ -475,6 +546,9 @@ func (c *Collector) fetchColumns(ctx context.Context, schema, table string) ([]c
 			Nullable:        isNullable == "YES",
 			Comment:         columnComment.String,
 			IsAutoIncrement: strings.Contains(extra, "auto_increment"),
+			Generated:       generatedInfo(extra, generationExpr.String),
+			Charset:         charset.String,
+			Collation:       collation.String,
 		}
 
 		if columnDefault.Valid {
@@ -507,13 +581,13 @@ func (c *Collector) fetchColumns(ctx context.Context, schema, table string) ([]c
 }
 
 // fetchPrimaryKeys retrieves primary key columns for a table
-func (c *Collector) fetchPrimaryKeys(ctx context.Context, schema, table string) ([]string, error) {
+func (c *Collector) fetchPrimaryKeys(ctx context.Context, db *sql.DB, schema, table string) ([]string, error) {
 	// Check context before starting
 	if err := collector.CheckContext(ctx, SourceName, "fetch_primary_keys"); err != nil {
 		return nil, err
 	}
 
-	rows, err := c.db.QueryContext(ctx, queryGetPrimaryKeys, schema, table)
+	rows, err := db.QueryContext(ctx, queryGetPrimaryKeys, schema, table)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_primary_keys")
@@ -541,14 +615,51 @@ func (c *Collector) fetchPrimaryKeys(ctx context.Context, schema, table string)
 	return primaryKeys, nil
 }
 
+// fetchCheckConstraints retrieves the CHECK constraints of a table. Servers
+// without information_schema.CHECK_CONSTRAINTS have none.
+func (c *Collector) fetchCheckConstraints(ctx context.Context, db *sql.DB, schema, table string) ([]collector.CheckConstraint, error) {
+	rows, err := db.QueryContext(ctx, queryGetCheckConstraints, schema, table)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_check_constraints")
+		}
+		if isUnknownTable(err) {
+			return nil, nil
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_check_constraints", err)
+	}
+	defer rows.Close()
+
+	var checks []collector.CheckConstraint
+	for rows.Next() {
+		var name, clause string
+		if err := rows.Scan(&name, &clause); err != nil {
+			return nil, collector.NewParseError(SourceName, "fetch_check_constraints", err)
+		}
+		checks = append(checks, collector.CheckConstraint{
+			Name:       name,
+			Expression: unescapeExpression(clause),
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_check_constraints")
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_check_constraints", err)
+	}
+
+	return checks, nil
+}
+
 // fetchIndexes retrieves index information for a table
-func (c *Collector) fetchIndexes(ctx context.Context, schema, table string) ([]collector.Index, error) {
+func (c *Collector) fetchIndexes(ctx context.Context, db *sql.DB, schema, table string) ([]collector.Index, error) {
 	// Check context before starting
 	if err := collector.CheckContext(ctx, SourceName, "fetch_indexes"); err != nil {
 		return nil, err
 	}
 
-	rows, err := c.db.QueryContext(ctx, queryGetIndexes, schema, table)
+	rows, err := db.QueryContext(ctx, queryGetIndexes, schema, table)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_indexes")
@@ -609,7 +720,8 @@ func (c *Collector) fetchIndexes(ctx context.Context, schema, table string) ([]c
 
 // FetchTableStatistics 获取表统计信息
 func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, table string) (*collector.TableStatistics, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_statistics")
 	}
 
@@ -619,7 +731,7 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 	}
 
 	var rowCount, dataLength, indexLength sql.NullInt64
-	err := c.db.QueryRowContext(ctx, queryGetTableStats, schema, table).Scan(&rowCount, &dataLength, &indexLength)
+	err := db.QueryRowContext(ctx, queryGetTableStats, schema, table).Scan(&rowCount, &dataLength, &indexLength)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_table_statistics")
@@ -639,9 +751,56 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 	return stats, nil
 }
 
+// FetchExamples 读取表的前 ExampleSampleRows 行，采集列的示例值
+func (c *Collector) FetchExamples(ctx context.Context, catalog, schema, table string, columns []string, limit int) (map[string][]string, error) {
+	db, ok := c.db.Get()
+	if !ok {
+		return nil, collector.NewConnectionClosedError(SourceName, "fetch_examples")
+	}
+
+	// Check context before starting operation
+	if err := collector.CheckContext(ctx, SourceName, "fetch_examples"); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, exampleQuery(schema, table, columns))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_examples")
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_examples", err)
+	}
+	defer rows.Close()
+
+	examples, err := collector.ScanExamples(rows, columns, limit)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_examples")
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_examples", err)
+	}
+	return examples, nil
+}
+
+// exampleQuery selects the columns of the first rows of a table, as text.
+func exampleQuery(schema, table string, columns []string) string {
+	selected := make([]string, len(columns))
+	for i, col := range columns {
+		selected[i] = "CAST(" + quoteIdentifier(col) + " AS CHAR)"
+	}
+	return fmt.Sprintf("SELECT %s FROM %s.%s LIMIT %d",
+		strings.Join(selected, ", "), quoteIdentifier(schema), quoteIdentifier(table), collector.ExampleSampleRows)
+}
+
+// quoteIdentifier quotes a MySQL identifier with backticks.
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
 // FetchPartitions 获取分区信息
 func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table string) ([]collector.PartitionInfo, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_partitions")
 	}
 
@@ -650,7 +809,7 @@ func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table
 		return nil, err
 	}
 
-	rows, err := c.db.QueryContext(ctx, queryGetPartitions, schema, table)
+	rows, err := db.QueryContext(ctx, queryGetPartitions, schema, table)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_partitions")
@@ -710,9 +869,210 @@ func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table
 	return partitions, nil
 }
 
-// buildDSN constructs the MySQL DSN from configuration
-func (c *Collector) buildDSN() (string, error) {
-	endpoint := c.config.Endpoint
+// FetchViewDependencies 从系统目录采集 schema 下视图对表/视图的依赖
+// (MySQL 8.0.13+，更早的版本返回 UNSUPPORTED_FEATURE)
+func (c *Collector) FetchViewDependencies(ctx context.Context, catalog, schema string) ([]collector.ViewDependency, error) {
+	db, ok := c.db.Get()
+	if !ok {
+		return nil, collector.NewConnectionClosedError(SourceName, "fetch_view_dependencies")
+	}
+
+	// Check context before starting operation
+	if err := collector.CheckContext(ctx, SourceName, "fetch_view_dependencies"); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, queryGetViewDependencies, schema)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_view_dependencies")
+		}
+		if isUnknownTable(err) {
+			return nil, collector.NewUnsupportedFeatureError(SourceName, "fetch_view_dependencies", "information_schema.VIEW_TABLE_USAGE")
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_view_dependencies", err)
+	}
+	defer rows.Close()
+
+	var deps []collector.ViewDependency
+	for rows.Next() {
+		var dep collector.ViewDependency
+		if err := rows.Scan(&dep.Schema, &dep.View, &dep.RefSchema, &dep.RefTable); err != nil {
+			return nil, collector.NewParseError(SourceName, "fetch_view_dependencies", err)
+		}
+		deps = append(deps, dep)
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_view_dependencies")
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_view_dependencies", err)
+	}
+
+	return deps, nil
+}
+
+// FetchQueryLog 从 general log 表 (mysql.general_log，需 log_output=TABLE) 读取 since 之后执行的语句，
+// 相同语句合并并统计执行次数。general log 不记录语句的数据库，Database 为空
+func (c *Collector) FetchQueryLog(ctx context.Context, since time.Time) ([]collector.QueryLogEntry, error) {
+	db, ok := c.db.Get()
+	if !ok {
+		return nil, collector.NewConnectionClosedError(SourceName, "fetch_query_log")
+	}
+
+	// Check context before starting operation
+	if err := collector.CheckContext(ctx, SourceName, "fetch_query_log"); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, queryGetQueryLog, since)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_query_log")
+		}
+		if isNoSuchTable(err) {
+			return nil, collector.NewUnsupportedFeatureError(SourceName, "fetch_query_log", "mysql.general_log")
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_query_log", err)
+	}
+	defer rows.Close()
+
+	var entries []collector.QueryLogEntry
+	for rows.Next() {
+		var entry collector.QueryLogEntry
+		if err := rows.Scan(&entry.SQL, &entry.Calls, &entry.LastExecuted); err != nil {
+			return nil, collector.NewParseError(SourceName, "fetch_query_log", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_query_log")
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_query_log", err)
+	}
+
+	return entries, nil
+}
+
+// TableFingerprints 批量获取 schema 下各表的变更指纹：列、索引与表选项的
+// DDL 哈希，UPDATE_TIME 与 TABLE_ROWS
+func (c *Collector) TableFingerprints(ctx context.Context, catalog, schema string) (map[string]collector.TableFingerprint, error) {
+	db, ok := c.db.Get()
+	if !ok {
+		return nil, collector.NewConnectionClosedError(SourceName, "table_fingerprints")
+	}
+
+	// Check context before starting operation
+	if err := collector.CheckContext(ctx, SourceName, "table_fingerprints"); err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]hash.Hash)
+	fingerprints := make(map[string]collector.TableFingerprint)
+	err := c.scanFingerprintRows(ctx, db, queryGetTableFingerprints, schema, func(rows *sql.Rows) error {
+		var name, tableType string
+		var comment, collation sql.NullString
+		var created, updated sql.NullTime
+		var rowCount sql.NullInt64
+		if err := rows.Scan(&name, &tableType, &comment, &collation, &created, &updated, &rowCount); err != nil {
+			return err
+		}
+		h := sha256.New()
+		// CREATE_TIME changes when an ALTER TABLE rebuilds the table
+		writeFingerprintFields(h, "table", tableType, comment.String, collation.String, created.Time.UTC().String())
+		hashes[name] = h
+		fp := collector.TableFingerprint{}
+		if updated.Valid {
+			fp.UpdatedAt = updated.Time
+		}
+		if rowCount.Valid {
+			fp.RowCount = &rowCount.Int64
+		}
+		fingerprints[name] = fp
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.scanFingerprintRows(ctx, db, queryGetColumnFingerprints, schema, func(rows *sql.Rows) error {
+		var table, name, columnType, nullable, key, extra string
+		var def, comment sql.NullString
+		if err := rows.Scan(&table, &name, &columnType, &nullable, &def, &key, &extra, &comment); err != nil {
+			return err
+		}
+		if h, ok := hashes[table]; ok {
+			writeFingerprintFields(h, "column", name, columnType, nullable, strconv.FormatBool(def.Valid), def.String, key, extra, comment.String)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.scanFingerprintRows(ctx, db, queryGetIndexFingerprints, schema, func(rows *sql.Rows) error {
+		var table, name, seq, nonUnique string
+		var column, indexType sql.NullString
+		if err := rows.Scan(&table, &name, &seq, &column, &nonUnique, &indexType); err != nil {
+			return err
+		}
+		if h, ok := hashes[table]; ok {
+			writeFingerprintFields(h, "index", name, seq, column.String, nonUnique, indexType.String)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for name, h := range hashes {
+		fp := fingerprints[name]
+		fp.DDLHash = hex.EncodeToString(h.Sum(nil))
+		fingerprints[name] = fp
+	}
+	return fingerprints, nil
+}
+
+// scanFingerprintRows runs a fingerprint query for a schema and calls scan
+// for each row.
+func (c *Collector) scanFingerprintRows(ctx context.Context, db *sql.DB, query, schema string, scan func(*sql.Rows) error) error {
+	rows, err := db.QueryContext(ctx, query, schema)
+	if err != nil {
+		if ctx.Err() != nil {
+			return collector.WrapContextError(ctx, SourceName, "table_fingerprints")
+		}
+		return collector.NewQueryError(SourceName, "table_fingerprints", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return collector.NewParseError(SourceName, "table_fingerprints", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		if ctx.Err() != nil {
+			return collector.WrapContextError(ctx, SourceName, "table_fingerprints")
+		}
+		return collector.NewQueryError(SourceName, "table_fingerprints", err)
+	}
+	return nil
+}
+
+// writeFingerprintFields writes a length-prefixed record to a DDL hash, so
+// that no two different records hash the same.
+func writeFingerprintFields(h hash.Hash, fields ...string) {
+	for _, f := range fields {
+		fmt.Fprintf(h, "%d:%s;", len(f), f)
+	}
+	h.Write([]byte{'\n'})
+}
+
+// buildDSN constructs the MySQL DSN for an endpoint from configuration
+func (c *Collector) buildDSN(endpoint string) (string, error) {
 	if endpoint == "" {
 		return "", fmt.Errorf("endpoint is required")
 	}
@@ -748,6 +1108,20 @@ func (c *Collector) buildDSN() (string, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?timeout=%ds&parseTime=true",
 		user, password, host, port, database, timeout)
 
+	// Query guards are set as session variables on connect; the application
+	// name is visible in performance_schema.session_connect_attrs
+	session := c.config.SessionSettings()
+	dsn += "&connectionAttributes=" + url.QueryEscape("program_name:"+session.ApplicationName)
+	if session.StatementTimeout > 0 {
+		dsn += fmt.Sprintf("&max_execution_time=%d", session.StatementTimeout*1000)
+	}
+	switch session.IsolationLevel {
+	case config.IsolationReadUncommitted:
+		dsn += "&transaction_isolation=" + url.QueryEscape("'READ-UNCOMMITTED'")
+	case config.IsolationReadCommitted:
+		dsn += "&transaction_isolation=" + url.QueryEscape("'READ-COMMITTED'")
+	}
+
 	// Add extra parameters
 	if c.config.Properties.Extra != nil {
 		for k, v := range c.config.Properties.Extra {
@@ -775,6 +1149,78 @@ func (c *Collector) wrapConnectionError(err error) error {
 	return collector.NewNetworkError(SourceName, "connect", err)
 }
 
+// isUnknownTable reports whether err is ER_UNKNOWN_TABLE, returned for
+// information_schema tables missing from older servers.
+func isUnknownTable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1109
+}
+
+// isNoSuchTable reports whether err is MySQL's ER_NO_SUCH_TABLE error.
+func isNoSuchTable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1146
+}
+
+// generatedInfo returns the generated column info from the EXTRA and
+// GENERATION_EXPRESSION columns, or nil for a regular column. EXTRA is
+// "VIRTUAL GENERATED" or "STORED GENERATED" for generated columns, while
+// "DEFAULT_GENERATED" only marks an expression default.
+func generatedInfo(extra, expression string) *collector.GeneratedInfo {
+	extra = strings.ToUpper(extra)
+	stored := strings.Contains(extra, "STORED GENERATED")
+	if expression == "" || !stored && !strings.Contains(extra, "VIRTUAL GENERATED") {
+		return nil
+	}
+	return &collector.GeneratedInfo{
+		Expression: unescapeExpression(expression),
+		Stored:     stored,
+	}
+}
+
+// unescapeExpression undoes the quote escaping of expressions in
+// information_schema, e.g. _utf8mb4\' \' becomes _utf8mb4' '.
+func unescapeExpression(expr string) string {
+	return strings.ReplaceAll(expr, `\'`, "'")
+}
+
+// serverSettings returns the server time zone and character set settings,
+// querying them once per connection. time_zone is the effective zone of
+// TIMESTAMP values, with SYSTEM resolved to the zone of the host.
+func (c *Collector) serverSettings(ctx context.Context, db *sql.DB) (map[string]string, error) {
+	c.settingsMu.Lock()
+	defer c.settingsMu.Unlock()
+	if c.settings != nil {
+		return c.settings, nil
+	}
+
+	var timeZone, systemTimeZone, charset, collation sql.NullString
+	err := db.QueryRowContext(ctx, queryGetServerSettings).Scan(&timeZone, &systemTimeZone, &charset, &collation)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "server_settings")
+		}
+		return nil, collector.NewQueryError(SourceName, "server_settings", err)
+	}
+
+	c.settings = map[string]string{
+		"time_zone":            effectiveTimeZone(timeZone.String, systemTimeZone.String),
+		"system_time_zone":     systemTimeZone.String,
+		"character_set_server": charset.String,
+		"collation_server":     collation.String,
+	}
+	return c.settings, nil
+}
+
+// effectiveTimeZone resolves the SYSTEM value of @@time_zone, which means
+// the server follows the time zone of its host.
+func effectiveTimeZone(timeZone, systemTimeZone string) string {
+	if strings.EqualFold(timeZone, "SYSTEM") && systemTimeZone != "" {
+		return systemTimeZone
+	}
+	return timeZone
+}
+
 // mapTableType maps MySQL table type to standard TableType
 func (c *Collector) mapTableType(mysqlType string) collector.TableType {
 	switch strings.ToUpper(mysqlType) {
@@ -825,7 +1271,11 @@ func (c *Collector) Type() string {
 	return SourceName
 }
 
-// Ensure Collector implements collector.Collector interface
+// Ensure Collector implements the collector.Collector and collector.ViewDependencyCollector interfaces
 var _ collector.Collector = (*Collector)(nil)
+var _ collector.ViewDependencyCollector = (*Collector)(nil)
+var _ collector.ExampleCollector = (*Collector)(nil)
+var _ collector.ChangeDetector = (*Collector)(nil)
+var _ collector.QueryLogCollector = (*Collector)(nil)
 
 
@@ -5,9 +5,12 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"go-metadata/internal/collector"
 	"go-metadata/internal/collector/config"
+
+	"github.com/go-sql-driver/mysql"
 )
 
 // TestNewCollector tests the NewCollector function
@@ -173,7 +176,7 @@ func TestBuildDSN(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &Collector{config: tt.cfg}
-			dsn, err := c.buildDSN()
+			dsn, err := c.buildDSN(tt.cfg.Endpoint)
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error, got nil")
@@ -191,6 +194,47 @@ func TestBuildDSN(t *testing.T) {
 	}
 }
 
+// TestBuildDSN_Session tests that query guards reach the driver as session variables
+func TestBuildDSN_Session(t *testing.T) {
+	c := &Collector{config: &config.ConnectorConfig{
+		Endpoint: "localhost:3306",
+		Session: &config.SessionConfig{
+			StatementTimeout: 30,
+			IsolationLevel:   config.IsolationReadUncommitted,
+		},
+	}}
+	dsn, err := c.buildDSN("replica:3306")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("driver rejected DSN %q: %v", dsn, err)
+	}
+	if cfg.Addr != "replica:3306" {
+		t.Errorf("Addr = %q, want replica:3306", cfg.Addr)
+	}
+	if cfg.Params["max_execution_time"] != "30000" {
+		t.Errorf("max_execution_time = %q, want 30000", cfg.Params["max_execution_time"])
+	}
+	if cfg.Params["transaction_isolation"] != "'READ-UNCOMMITTED'" {
+		t.Errorf("transaction_isolation = %q, want 'READ-UNCOMMITTED'", cfg.Params["transaction_isolation"])
+	}
+	if cfg.ConnectionAttributes != "program_name:"+config.DefaultApplicationName {
+		t.Errorf("ConnectionAttributes = %q", cfg.ConnectionAttributes)
+	}
+}
+
+// TestExampleQuery tests the query reading the example values of columns
+func TestExampleQuery(t *testing.T) {
+	got := exampleQuery("shop", "order`s", []string{"id", "note"})
+	want := "SELECT CAST(`id` AS CHAR), CAST(`note` AS CHAR) FROM `shop`.`order``s` LIMIT 100"
+	if got != want {
+		t.Errorf("exampleQuery() = %s, want %s", got, want)
+	}
+}
+
 // TestMapTableType tests the table type mapping
 func TestMapTableType(t *testing.T) {
 	c := &Collector{}
@@ -217,6 +261,51 @@ func TestMapTableType(t *testing.T) {
 	}
 }
 
+// TestGeneratedInfo tests generated column detection from EXTRA
+func TestGeneratedInfo(t *testing.T) {
+	tests := []struct {
+		name       string
+		extra      string
+		expression string
+		want       *collector.GeneratedInfo
+	}{
+		{"virtual", "VIRTUAL GENERATED", "(`price` * `qty`)", &collector.GeneratedInfo{Expression: "(`price` * `qty`)"}},
+		{"stored", "STORED GENERATED", "(`price` * `qty`)", &collector.GeneratedInfo{Expression: "(`price` * `qty`)", Stored: true}},
+		{"escaped quotes", "VIRTUAL GENERATED", `concat(_utf8mb4\' \',` + "`name`)", &collector.GeneratedInfo{Expression: "concat(_utf8mb4' ',`name`)"}},
+		{"expression default", "DEFAULT_GENERATED", "", nil},
+		{"expression default on update", "DEFAULT_GENERATED on update CURRENT_TIMESTAMP", "", nil},
+		{"regular", "auto_increment", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := generatedInfo(tt.extra, tt.expression)
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("generatedInfo(%q, %q) = %+v, want %+v", tt.extra, tt.expression, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEffectiveTimeZone tests resolution of the SYSTEM time zone
+func TestEffectiveTimeZone(t *testing.T) {
+	tests := []struct {
+		timeZone, systemTimeZone, want string
+	}{
+		{"SYSTEM", "CST", "CST"},
+		{"system", "UTC", "UTC"},
+		{"+08:00", "UTC", "+08:00"},
+		{"Asia/Shanghai", "UTC", "Asia/Shanghai"},
+		{"SYSTEM", "", "SYSTEM"},
+	}
+
+	for _, tt := range tests {
+		if got := effectiveTimeZone(tt.timeZone, tt.systemTimeZone); got != tt.want {
+			t.Errorf("effectiveTimeZone(%q, %q) = %q, want %q", tt.timeZone, tt.systemTimeZone, got, tt.want)
+		}
+	}
+}
+
 // TestNormalizeType tests the type normalization
 func TestNormalizeType(t *testing.T) {
 	c := &Collector{}
@@ -361,6 +450,15 @@ func TestCollectorNotConnected(t *testing.T) {
 
 	_, err = c.FetchPartitions(ctx, "def", "test", "users")
 	assertConnectionClosedError(t, err, "FetchPartitions")
+
+	_, err = c.(collector.ExampleCollector).FetchExamples(ctx, "def", "test", "users", []string{"id"}, 3)
+	assertConnectionClosedError(t, err, "FetchExamples")
+
+	_, err = c.(collector.QueryLogCollector).FetchQueryLog(ctx, time.Time{})
+	assertConnectionClosedError(t, err, "FetchQueryLog")
+
+	_, err = c.(collector.ChangeDetector).TableFingerprints(ctx, "def", "test")
+	assertConnectionClosedError(t, err, "TableFingerprints")
 }
 
 // TestCloseNotConnected tests Close when not connected
@@ -19,11 +19,20 @@ WHERE TABLE_SCHEMA = ?
 ORDER BY TABLE_NAME
 `
 
-// queryGetTableInfo retrieves basic table information
+// queryGetTableInfo retrieves basic table information, including the
+// table's default collation and its character set
 const queryGetTableInfo = `
-SELECT TABLE_TYPE, TABLE_COMMENT
-FROM information_schema.TABLES
-WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+SELECT t.TABLE_TYPE, t.TABLE_COMMENT, t.TABLE_COLLATION, ccsa.CHARACTER_SET_NAME
+FROM information_schema.TABLES t
+LEFT JOIN information_schema.COLLATION_CHARACTER_SET_APPLICABILITY ccsa
+    ON ccsa.COLLATION_NAME = t.TABLE_COLLATION
+WHERE t.TABLE_SCHEMA = ? AND t.TABLE_NAME = ?
+`
+
+// queryGetServerSettings retrieves the server time zone and character set
+// settings that affect how values are stored and compared
+const queryGetServerSettings = `
+SELECT @@global.time_zone, @@system_time_zone, @@character_set_server, @@collation_server
 `
 
 // queryGetColumns retrieves column information for a specific table
@@ -40,12 +49,26 @@ SELECT
     COLUMN_DEFAULT,
     COLUMN_KEY,
     EXTRA,
-    COLUMN_COMMENT
+    COLUMN_COMMENT,
+    GENERATION_EXPRESSION,
+    CHARACTER_SET_NAME,
+    COLLATION_NAME
 FROM information_schema.COLUMNS
 WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
 ORDER BY ORDINAL_POSITION
 `
 
+// queryGetCheckConstraints retrieves the CHECK constraints of a table
+// (information_schema.CHECK_CONSTRAINTS exists since MySQL 8.0.16)
+const queryGetCheckConstraints = `
+SELECT cc.CONSTRAINT_NAME, cc.CHECK_CLAUSE
+FROM information_schema.TABLE_CONSTRAINTS tc
+JOIN information_schema.CHECK_CONSTRAINTS cc
+    ON cc.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA AND cc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+WHERE tc.TABLE_SCHEMA = ? AND tc.TABLE_NAME = ? AND tc.CONSTRAINT_TYPE = 'CHECK'
+ORDER BY cc.CONSTRAINT_NAME
+`
+
 // queryGetIndexes retrieves index information from information_schema.STATISTICS
 const queryGetIndexes = `
 SELECT 
@@ -79,6 +102,15 @@ FROM information_schema.TABLES
 WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
 `
 
+// queryGetViewDependencies retrieves the tables and views referenced by the
+// views of a schema (information_schema.VIEW_TABLE_USAGE exists since MySQL 8.0.13)
+const queryGetViewDependencies = `
+SELECT VIEW_SCHEMA, VIEW_NAME, TABLE_SCHEMA, TABLE_NAME
+FROM information_schema.VIEW_TABLE_USAGE
+WHERE VIEW_SCHEMA = ?
+ORDER BY VIEW_NAME, TABLE_SCHEMA, TABLE_NAME
+`
+
 // queryGetPartitions retrieves partition information
 const queryGetPartitions = `
 SELECT 
@@ -90,3 +122,40 @@ FROM information_schema.PARTITIONS
 WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
 ORDER BY PARTITION_ORDINAL_POSITION
 `
+
+// queryGetTableFingerprints retrieves the table-level inputs of the change
+// fingerprints of the tables of a database
+const queryGetTableFingerprints = `
+SELECT TABLE_NAME, TABLE_TYPE, TABLE_COMMENT, TABLE_COLLATION, CREATE_TIME, UPDATE_TIME, TABLE_ROWS
+FROM information_schema.TABLES
+WHERE TABLE_SCHEMA = ?
+`
+
+// queryGetColumnFingerprints retrieves the column definitions of the tables
+// of a database, in table and ordinal order, to hash their DDL
+const queryGetColumnFingerprints = `
+SELECT TABLE_NAME, COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, COLUMN_KEY, EXTRA, COLUMN_COMMENT
+FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = ?
+ORDER BY TABLE_NAME, ORDINAL_POSITION
+`
+
+// queryGetIndexFingerprints retrieves the index definitions of the tables of
+// a database, in a stable order, to hash their DDL
+const queryGetIndexFingerprints = `
+SELECT TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX, COLUMN_NAME, NON_UNIQUE, INDEX_TYPE
+FROM information_schema.STATISTICS
+WHERE TABLE_SCHEMA = ?
+ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX
+`
+
+// queryGetQueryLog retrieves the statements recorded in the general query
+// log table since a time, merging identical statements. The server logs to
+// the table with log_output=TABLE and general_log=ON.
+const queryGetQueryLog = `
+SELECT CONVERT(argument USING utf8mb4) AS statement, COUNT(*) AS calls, MAX(event_time) AS last_executed
+FROM mysql.general_log
+WHERE command_type IN ('Query', 'Execute') AND event_time > ?
+GROUP BY statement
+ORDER BY last_executed
+`
@@ -12,7 +12,7 @@ import (
 	"go-metadata/internal/collector"
 	"go-metadata/internal/collector/config"
 
-	_ "github.com/godror/godror"
+	"github.com/godror/godror"
 )
 
 const (
@@ -23,7 +23,7 @@ const (
 // Collector implements the collector.Collector interface for Oracle Database.
 type Collector struct {
 	config *config.ConnectorConfig
-	db     *sql.DB
+	db     collector.Conn[*sql.DB]
 }
 
 // NewCollector creates a new Oracle collector instance.
@@ -39,24 +39,49 @@ func NewCollector(cfg *config.ConnectorConfig) (*Collector, error) {
 
 // Connect establishes a connection to the Oracle database.
 func (c *Collector) Connect(ctx context.Context) error {
-	if c.db != nil {
-		return nil // Already connected
-	}
+	return c.db.Open(func() (db *sql.DB, err error) {
+		// Prefer the read replica (e.g. an Active Data Guard standby), falling
+		// back to the primary endpoint
+		for _, endpoint := range c.config.Endpoints() {
+			if db, err = c.open(ctx, endpoint); err == nil {
+				return db, nil
+			}
+		}
+		return nil, err
+	})
+}
 
-	dsn := c.buildDSN()
-	db, err := sql.Open("godror", dsn)
+// open connects to the endpoint and checks the connection.
+func (c *Collector) open(ctx context.Context, endpoint string) (*sql.DB, error) {
+	params, err := godror.ParseDSN(c.buildDSN(endpoint))
 	if err != nil {
-		return collector.NewNetworkErrorWithCategory(collector.CategoryRDBMS, SourceName, "connect", err)
+		return nil, collector.NewNetworkErrorWithCategory(collector.CategoryRDBMS, SourceName, "connect", err)
 	}
+	params.OnInitStmts = append(params.OnInitStmts, c.sessionInitStmts()...)
+	db := sql.OpenDB(godror.NewConnector(params))
 
 	// Test the connection
 	if err := db.PingContext(ctx); err != nil {
 		db.Close()
-		return collector.NewNetworkErrorWithCategory(collector.CategoryRDBMS, SourceName, "connect", err)
+		return nil, collector.NewNetworkErrorWithCategory(collector.CategoryRDBMS, SourceName, "connect", err)
 	}
 
-	c.db = db
-	return nil
+	return db, nil
+}
+
+// sessionInitStmts returns the query guards run on every new session. The
+// application name is shown as the module in v$session. Oracle never reads
+// uncommitted data, so both isolation levels map to READ COMMITTED, and it has
+// no session-level statement timeout.
+func (c *Collector) sessionInitStmts() []string {
+	session := c.config.SessionSettings()
+	stmts := []string{
+		fmt.Sprintf("BEGIN DBMS_APPLICATION_INFO.SET_MODULE('%s', NULL); END;", strings.ReplaceAll(session.ApplicationName, "'", "''")),
+	}
+	if session.IsolationLevel != "" {
+		stmts = append(stmts, "ALTER SESSION SET ISOLATION_LEVEL = READ COMMITTED")
+	}
+	return stmts
 }
 
 // Disconnect closes the database connection.
@@ -66,19 +91,18 @@ func (c *Collector) Disconnect() error {
 
 // Close closes the database connection.
 func (c *Collector) Close() error {
-	if c.db != nil {
-		err := c.db.Close()
-		c.db = nil
-		if err != nil {
+	return c.db.Close(func(db *sql.DB) error {
+		if err := db.Close(); err != nil {
 			return collector.NewNetworkErrorWithCategory(collector.CategoryRDBMS, SourceName, "close", err)
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 // HealthCheck verifies the database connection is healthy.
 func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return &collector.HealthStatus{
 			Connected: false,
 			Message:   "not connected to Oracle database",
@@ -89,7 +113,7 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	if err := c.db.PingContext(ctx); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return &collector.HealthStatus{
 			Connected: false,
 			Latency:   time.Since(start),
@@ -116,14 +140,15 @@ func (c *Collector) Type() string {
 
 // DiscoverCatalogs discovers available catalogs (Oracle instances).
 func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogInfo, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedErrorWithCategory(collector.CategoryRDBMS, SourceName, "discover_catalogs")
 	}
 
 	// In Oracle, there's typically one catalog per instance
 	// We can get the database name from V$DATABASE
 	var dbName string
-	err := c.db.QueryRowContext(ctx, "SELECT NAME FROM V$DATABASE").Scan(&dbName)
+	err := db.QueryRowContext(ctx, "SELECT NAME FROM V$DATABASE").Scan(&dbName)
 	if err != nil {
 		return nil, collector.NewQueryErrorWithCategory(collector.CategoryRDBMS, SourceName, "discover_catalogs", err)
 	}
@@ -157,14 +182,15 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 
 // FetchTableStatistics retrieves table statistics.
 func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, table string) (*collector.TableStatistics, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedErrorWithCategory(collector.CategoryRDBMS, SourceName, "fetch_table_statistics")
 	}
 
 	var numRows, blocks, avgRowLen sql.NullInt64
 	var lastAnalyzed sql.NullTime
 
-	err := c.db.QueryRowContext(ctx, GetTableStatsQuery(), strings.ToUpper(schema), strings.ToUpper(table)).
+	err := db.QueryRowContext(ctx, GetTableStatsQuery(), strings.ToUpper(schema), strings.ToUpper(table)).
 		Scan(&numRows, &blocks, &avgRowLen, &lastAnalyzed)
 	if err != nil {
 		return nil, collector.NewQueryErrorWithCategory(collector.CategoryRDBMS, SourceName, "fetch_table_statistics", err)
@@ -188,16 +214,21 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 
 // FetchPartitions retrieves partition information for a table.
 func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table string) ([]collector.PartitionInfo, error) {
-	return c.fetchPartitions(ctx, schema, table)
+	db, ok := c.db.Get()
+	if !ok {
+		return nil, collector.NewConnectionClosedErrorWithCategory(collector.CategoryRDBMS, SourceName, "fetch_partitions")
+	}
+	return c.fetchPartitions(ctx, db, schema, table)
 }
 
 // FetchDatabases retrieves all accessible databases/schemas.
 func (c *Collector) FetchDatabases(ctx context.Context) ([]string, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedErrorWithCategory(collector.CategoryRDBMS, SourceName, "fetch_databases")
 	}
 
-	rows, err := c.db.QueryContext(ctx, GetAllUsersQuery())
+	rows, err := db.QueryContext(ctx, GetAllUsersQuery())
 	if err != nil {
 		return nil, collector.NewQueryErrorWithCategory(collector.CategoryRDBMS, SourceName, "fetch_databases", err)
 	}
@@ -221,11 +252,12 @@ func (c *Collector) FetchDatabases(ctx context.Context) ([]string, error) {
 
 // FetchTables retrieves all tables in the specified database/schema.
 func (c *Collector) FetchTables(ctx context.Context, database string) ([]string, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedErrorWithCategory(collector.CategoryRDBMS, SourceName, "fetch_tables")
 	}
 
-	rows, err := c.db.QueryContext(ctx, GetAllTablesQuery(), strings.ToUpper(database))
+	rows, err := db.QueryContext(ctx, GetAllTablesQuery(), strings.ToUpper(database))
 	if err != nil {
 		return nil, collector.NewQueryErrorWithCategory(collector.CategoryRDBMS, SourceName, "fetch_tables", err)
 	}
@@ -249,7 +281,8 @@ func (c *Collector) FetchTables(ctx context.Context, database string) ([]string,
 
 // FetchTableMetadata retrieves detailed metadata for a specific table.
 func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, table string) (*collector.TableMetadata, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedErrorWithCategory(collector.CategoryRDBMS, SourceName, "fetch_table_metadata")
 	}
 
@@ -263,21 +296,28 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 	}
 
 	// Fetch columns
-	columns, err := c.fetchColumns(ctx, schema, table)
+	columns, err := c.fetchColumns(ctx, db, schema, table)
 	if err != nil {
 		return nil, err
 	}
 	metadata.Columns = columns
 
 	// Fetch indexes
-	indexes, err := c.fetchIndexes(ctx, schema, table)
+	indexes, err := c.fetchIndexes(ctx, db, schema, table)
 	if err != nil {
 		return nil, err
 	}
 	metadata.Indexes = indexes
 
+	// Fetch check constraints
+	checks, err := c.fetchCheckConstraints(ctx, db, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	metadata.CheckConstraints = checks
+
 	// Fetch partitions if any
-	partitions, err := c.fetchPartitions(ctx, schema, table)
+	partitions, err := c.fetchPartitions(ctx, db, schema, table)
 	if err != nil {
 		return nil, err
 	}
@@ -286,10 +326,9 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 	return metadata, nil
 }
 
-// buildDSN constructs the Oracle connection string.
-func (c *Collector) buildDSN() string {
+// buildDSN constructs the Oracle connection string for an endpoint.
+func (c *Collector) buildDSN(endpoint string) string {
 	// Parse endpoint (expected format: host:port or host)
-	endpoint := c.config.Endpoint
 	if endpoint == "" {
 		endpoint = "localhost:1521"
 	}
@@ -323,8 +362,8 @@ func (c *Collector) buildDSN() string {
 }
 
 // fetchColumns retrieves column information for a table.
-func (c *Collector) fetchColumns(ctx context.Context, schema, table string) ([]collector.Column, error) {
-	rows, err := c.db.QueryContext(ctx, GetAllTabColumnsQuery(), strings.ToUpper(schema), strings.ToUpper(table))
+func (c *Collector) fetchColumns(ctx context.Context, db *sql.DB, schema, table string) ([]collector.Column, error) {
+	rows, err := db.QueryContext(ctx, GetAllTabColumnsQuery(), strings.ToUpper(schema), strings.ToUpper(table))
 	if err != nil {
 		return nil, collector.NewQueryErrorWithCategory(collector.CategoryRDBMS, SourceName, "fetch_columns", err)
 	}
@@ -336,6 +375,7 @@ func (c *Collector) fetchColumns(ctx context.Context, schema, table string) ([]c
 		var nullable string
 		var dataDefault sql.NullString
 		var comments string
+		var virtualColumn string
 
 		err := rows.Scan(
 			&col.Name,
@@ -347,6 +387,7 @@ func (c *Collector) fetchColumns(ctx context.Context, schema, table string) ([]c
 			&nullable,
 			&dataDefault,
 			&comments,
+			&virtualColumn,
 		)
 		if err != nil {
 			return nil, collector.NewQueryErrorWithCategory(collector.CategoryRDBMS, SourceName, "fetch_columns", err)
@@ -354,7 +395,13 @@ func (c *Collector) fetchColumns(ctx context.Context, schema, table string) ([]c
 
 		col.Nullable = (nullable == "Y")
 		if dataDefault.Valid && dataDefault.String != "" {
-			col.Default = &dataDefault.String
+			// Virtual columns hold their expression in DATA_DEFAULT
+			expression := strings.TrimSpace(dataDefault.String)
+			if virtualColumn == "YES" {
+				col.Generated = &collector.GeneratedInfo{Expression: expression}
+			} else {
+				col.Default = &dataDefault.String
+			}
 		}
 		col.Comment = comments
 
@@ -368,9 +415,36 @@ func (c *Collector) fetchColumns(ctx context.Context, schema, table string) ([]c
 	return columns, nil
 }
 
+// fetchCheckConstraints retrieves the enabled check constraints of a table.
+func (c *Collector) fetchCheckConstraints(ctx context.Context, db *sql.DB, schema, table string) ([]collector.CheckConstraint, error) {
+	rows, err := db.QueryContext(ctx, GetCheckConstraintsQuery(), strings.ToUpper(schema), strings.ToUpper(table))
+	if err != nil {
+		return nil, collector.NewQueryErrorWithCategory(collector.CategoryRDBMS, SourceName, "fetch_check_constraints", err)
+	}
+	defer rows.Close()
+
+	var checks []collector.CheckConstraint
+	for rows.Next() {
+		var name, condition, status string
+		if err := rows.Scan(&name, &condition, &status); err != nil {
+			return nil, collector.NewQueryErrorWithCategory(collector.CategoryRDBMS, SourceName, "fetch_check_constraints", err)
+		}
+		if status != "ENABLED" {
+			continue
+		}
+		checks = append(checks, collector.CheckConstraint{Name: name, Expression: condition})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, collector.NewQueryErrorWithCategory(collector.CategoryRDBMS, SourceName, "fetch_check_constraints", err)
+	}
+
+	return checks, nil
+}
+
 // fetchIndexes retrieves index information for a table.
-func (c *Collector) fetchIndexes(ctx context.Context, schema, table string) ([]collector.Index, error) {
-	rows, err := c.db.QueryContext(ctx, GetAllIndexesQuery(), strings.ToUpper(schema), strings.ToUpper(table))
+func (c *Collector) fetchIndexes(ctx context.Context, db *sql.DB, schema, table string) ([]collector.Index, error) {
+	rows, err := db.QueryContext(ctx, GetAllIndexesQuery(), strings.ToUpper(schema), strings.ToUpper(table))
 	if err != nil {
 		return nil, collector.NewQueryErrorWithCategory(collector.CategoryRDBMS, SourceName, "fetch_indexes", err)
 	}
@@ -410,8 +484,8 @@ func (c *Collector) fetchIndexes(ctx context.Context, schema, table string) ([]c
 }
 
 // fetchPartitions retrieves partition information for a table.
-func (c *Collector) fetchPartitions(ctx context.Context, schema, table string) ([]collector.PartitionInfo, error) {
-	rows, err := c.db.QueryContext(ctx, GetAllTabPartitionsQuery(), strings.ToUpper(schema), strings.ToUpper(table))
+func (c *Collector) fetchPartitions(ctx context.Context, db *sql.DB, schema, table string) ([]collector.PartitionInfo, error) {
+	rows, err := db.QueryContext(ctx, GetAllTabPartitionsQuery(), strings.ToUpper(schema), strings.ToUpper(table))
 	if err != nil {
 		return nil, collector.NewQueryErrorWithCategory(collector.CategoryRDBMS, SourceName, "fetch_partitions", err)
 	}
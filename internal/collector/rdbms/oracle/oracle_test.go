@@ -1,6 +1,7 @@
 package oracle
 
 import (
+	"strings"
 	"testing"
 
 	"go-metadata/internal/collector"
@@ -166,7 +167,7 @@ func TestBuildDSN(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &Collector{config: tt.config}
-			got := c.buildDSN()
+			got := c.buildDSN(tt.config.Endpoint)
 
 			if got != tt.want {
 				t.Errorf("buildDSN() = %v, want %v", got, tt.want)
@@ -175,6 +176,23 @@ func TestBuildDSN(t *testing.T) {
 	}
 }
 
+func TestCollector_sessionInitStmts(t *testing.T) {
+	c := &Collector{config: &config.ConnectorConfig{
+		Session: &config.SessionConfig{
+			IsolationLevel:  config.IsolationReadUncommitted,
+			ApplicationName: "metadata catalog",
+		},
+	}}
+	want := []string{
+		"BEGIN DBMS_APPLICATION_INFO.SET_MODULE('metadata catalog', NULL); END;",
+		"ALTER SESSION SET ISOLATION_LEVEL = READ COMMITTED",
+	}
+	got := c.sessionInitStmts()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("sessionInitStmts() = %q, want %q", got, want)
+	}
+}
+
 func TestConstants(t *testing.T) {
 	if SourceName != "oracle" {
 		t.Errorf("SourceName = %v, want oracle", SourceName)
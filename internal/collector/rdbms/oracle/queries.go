@@ -30,24 +30,26 @@ func GetAllTablesQuery() string {
 }
 
 // GetAllTabColumnsQuery returns the query to get all columns for a table.
+// ALL_TAB_COLS is used for VIRTUAL_COLUMN, whose expression is in DATA_DEFAULT.
 func GetAllTabColumnsQuery() string {
 	return `
 		SELECT 
-			COLUMN_NAME,
-			DATA_TYPE,
-			COLUMN_ID,
-			DATA_LENGTH,
-			DATA_PRECISION,
-			DATA_SCALE,
-			NULLABLE,
-			DATA_DEFAULT,
-			NVL(cc.COMMENTS, '') as COMMENTS
-		FROM ALL_TAB_COLUMNS c
+			c.COLUMN_NAME,
+			c.DATA_TYPE,
+			c.COLUMN_ID,
+			c.DATA_LENGTH,
+			c.DATA_PRECISION,
+			c.DATA_SCALE,
+			c.NULLABLE,
+			c.DATA_DEFAULT,
+			NVL(cc.COMMENTS, '') as COMMENTS,
+			c.VIRTUAL_COLUMN
+		FROM ALL_TAB_COLS c
 		LEFT JOIN ALL_COL_COMMENTS cc ON c.OWNER = cc.OWNER 
 			AND c.TABLE_NAME = cc.TABLE_NAME 
 			AND c.COLUMN_NAME = cc.COLUMN_NAME
-		WHERE c.OWNER = :1 AND c.TABLE_NAME = :2
-		ORDER BY COLUMN_ID`
+		WHERE c.OWNER = :1 AND c.TABLE_NAME = :2 AND c.HIDDEN_COLUMN = 'NO'
+		ORDER BY c.COLUMN_ID`
 }
 
 // GetAllIndexesQuery returns the query to get all indexes for a table.
@@ -4,16 +4,18 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"go-metadata/internal/collector"
 	"go-metadata/internal/collector/config"
 	"go-metadata/internal/collector/matcher"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 const (
@@ -23,12 +25,29 @@ const (
 	DefaultPort = 5432
 	// DefaultTimeout is the default connection timeout in seconds
 	DefaultTimeout = 30
+	// AllDatabases as the database property syncs every database of the
+	// cluster, enumerated through the postgres maintenance database
+	AllDatabases = "*"
 )
 
 // Collector PostgreSQL 元数据采集器
+//
+// PostgreSQL 连接绑定单个数据库；请求其他 catalog 时按需在同一地址打开该数据库的连接池并复用，
+// 配置 database: "*" 时 DiscoverCatalogs 列出集群中的所有数据库
 type Collector struct {
 	config *config.ConnectorConfig
-	db     *sql.DB
+	db     collector.Conn[*sql.DB]
+
+	// databases pools the connections to the other databases of the
+	// cluster, opened on the endpoint of the connection
+	databasesMu sync.Mutex
+	endpoint    string
+	databases   map[string]*sql.DB
+
+	// settings caches the server settings of each database for the lifetime
+	// of the connection
+	settingsMu sync.Mutex
+	settings   map[string]map[string]string
 }
 
 // NewCollector 创建 PostgreSQL 采集器实例
@@ -44,18 +63,30 @@ func NewCollector(cfg *config.ConnectorConfig) (collector.Collector, error) {
 
 // Connect 建立 PostgreSQL 连接
 func (c *Collector) Connect(ctx context.Context) error {
-	if c.db != nil {
-		return nil // Already connected
-	}
+	return c.db.Open(func() (db *sql.DB, err error) {
+		// Prefer the read replica, falling back to the primary endpoint
+		for _, endpoint := range c.config.Endpoints() {
+			if db, err = c.open(ctx, endpoint, c.database()); err == nil {
+				c.databasesMu.Lock()
+				c.endpoint = endpoint
+				c.databasesMu.Unlock()
+				return db, nil
+			}
+		}
+		return nil, err
+	})
+}
 
-	dsn, err := c.buildDSN()
+// open 连接指定地址上的数据库并检查连通性
+func (c *Collector) open(ctx context.Context, endpoint, database string) (*sql.DB, error) {
+	dsn, err := c.buildDSN(endpoint, database)
 	if err != nil {
-		return collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
+		return nil, collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
 	}
 
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
-		return collector.NewNetworkError(SourceName, "connect", err)
+		return nil, collector.NewNetworkError(SourceName, "connect", err)
 	}
 
 	// Configure connection pool
@@ -72,26 +103,76 @@ func (c *Collector) Connect(ctx context.Context) error {
 	// Test connection with context
 	if err := db.PingContext(ctx); err != nil {
 		db.Close()
-		return c.wrapConnectionError(err)
+		return nil, c.wrapConnectionError(err)
 	}
 
-	c.db = db
-	return nil
+	return db, nil
 }
 
 // Close 关闭 PostgreSQL 连接
 func (c *Collector) Close() error {
-	if c.db != nil {
-		err := c.db.Close()
-		c.db = nil
-		return err
+	return c.db.Close(func(db *sql.DB) error {
+		c.settingsMu.Lock()
+		c.settings = nil
+		c.settingsMu.Unlock()
+
+		c.databasesMu.Lock()
+		databases := c.databases
+		c.databases = nil
+		c.databasesMu.Unlock()
+		for _, other := range databases {
+			other.Close()
+		}
+		return db.Close()
+	})
+}
+
+// conn returns the connection to the database of a catalog: the connection
+// of the collector for its own database or an empty catalog, otherwise the
+// pooled connection to that database, opened by the first request for it.
+func (c *Collector) conn(ctx context.Context, catalog, op string) (*sql.DB, error) {
+	db, ok := c.db.Get()
+	if !ok {
+		return nil, collector.NewConnectionClosedError(SourceName, op)
+	}
+	if catalog == "" || catalog == c.database() {
+		return db, nil
+	}
+
+	c.databasesMu.Lock()
+	defer c.databasesMu.Unlock()
+	if other, ok := c.databases[catalog]; ok {
+		return other, nil
+	}
+	// Close releases the pooled connections under databasesMu, so a
+	// connection opened after it would leak
+	if _, ok := c.db.Get(); !ok {
+		return nil, collector.NewConnectionClosedError(SourceName, op)
 	}
-	return nil
+	other, err := c.open(ctx, c.endpoint, catalog)
+	if err != nil {
+		return nil, err
+	}
+	if c.databases == nil {
+		c.databases = make(map[string]*sql.DB)
+	}
+	c.databases[catalog] = other
+	return other, nil
+}
+
+// database returns the database the connection of the collector is bound
+// to: the configured database, or postgres by default and for AllDatabases.
+func (c *Collector) database() string {
+	if db := c.config.Properties.Extra["database"]; db != "" && db != AllDatabases {
+		return db
+	}
+	return "postgres"
 }
 
 // HealthCheck 健康检查
 func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return &collector.HealthStatus{
 			Connected: false,
 			Message:   "not connected",
@@ -101,7 +182,7 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 	start := time.Now()
 
 	// Ping to check connection
-	if err := c.db.PingContext(ctx); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return &collector.HealthStatus{
 			Connected: false,
 			Latency:   time.Since(start),
@@ -111,7 +192,7 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 
 	// Get PostgreSQL version
 	var version string
-	err := c.db.QueryRowContext(ctx, "SELECT version()").Scan(&version)
+	err := db.QueryRowContext(ctx, "SELECT version()").Scan(&version)
 	if err != nil {
 		return &collector.HealthStatus{
 			Connected: true,
@@ -130,39 +211,106 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 
 // DiscoverCatalogs 发现 Catalog（PostgreSQL 中 catalog 等同于数据库）
 func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogInfo, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "discover_catalogs")
 	}
 
 	// Get PostgreSQL version for catalog info
 	var version string
-	if err := c.db.QueryRowContext(ctx, "SELECT version()").Scan(&version); err != nil {
+	if err := db.QueryRowContext(ctx, "SELECT version()").Scan(&version); err != nil {
 		return nil, collector.NewQueryError(SourceName, "discover_catalogs", err)
 	}
 
 	// Get current database name
 	var currentDB string
-	if err := c.db.QueryRowContext(ctx, "SELECT current_database()").Scan(&currentDB); err != nil {
+	if err := db.QueryRowContext(ctx, "SELECT current_database()").Scan(&currentDB); err != nil {
 		return nil, collector.NewQueryError(SourceName, "discover_catalogs", err)
 	}
 
-	// PostgreSQL connection is bound to a single database
-	return []collector.CatalogInfo{
-		{
-			Catalog:     currentDB,
+	databases := []string{currentDB}
+	if c.config.Properties.Extra["database"] == AllDatabases {
+		var err error
+		if databases, err = c.listDatabases(ctx, db); err != nil {
+			return nil, err
+		}
+	}
+
+	catalogs := make([]collector.CatalogInfo, 0, len(databases))
+	for _, database := range databases {
+		dbConn, err := c.conn(ctx, database, "discover_catalogs")
+		if err != nil {
+			return nil, err
+		}
+		settings, err := c.serverSettings(ctx, dbConn, database)
+		if err != nil {
+			return nil, err
+		}
+		properties := map[string]string{
+			"version": version,
+		}
+		for k, v := range settings {
+			properties[k] = v
+		}
+		catalogs = append(catalogs, collector.CatalogInfo{
+			Catalog:     database,
 			Type:        SourceName,
 			Description: "PostgreSQL Database",
-			Properties: map[string]string{
-				"version": version,
-			},
-		},
-	}, nil
+			Properties:  properties,
+		})
+	}
+	return catalogs, nil
+}
+
+// listDatabases lists the databases of the cluster the collector can
+// connect to, filtered by the database matching rules.
+func (c *Collector) listDatabases(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, queryListDatabases)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "discover_catalogs")
+		}
+		return nil, collector.NewQueryError(SourceName, "discover_catalogs", err)
+	}
+	defer rows.Close()
+
+	var databases []string
+	for rows.Next() {
+		var database string
+		if err := rows.Scan(&database); err != nil {
+			return nil, collector.NewParseError(SourceName, "discover_catalogs", err)
+		}
+		databases = append(databases, database)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, collector.NewQueryError(SourceName, "discover_catalogs", err)
+	}
+
+	if c.config.Matching != nil && c.config.Matching.Databases != nil {
+		ruleMatcher, err := matcher.NewRuleMatcher(
+			c.config.Matching.Databases,
+			c.config.Matching.PatternType,
+			c.config.Matching.CaseSensitive,
+		)
+		if err != nil {
+			return nil, collector.NewInvalidConfigError(SourceName, "matching.databases", err.Error())
+		}
+		var filtered []string
+		for _, d := range databases {
+			if ruleMatcher.Match(d) {
+				filtered = append(filtered, d)
+			}
+		}
+		databases = filtered
+	}
+	return databases, nil
 }
 
 // ListSchemas 列出 Schema（PostgreSQL 有真正的 schema 概念）
 func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string, error) {
-	if c.db == nil {
-		return nil, collector.NewConnectionClosedError(SourceName, "list_schemas")
+	db, err := c.conn(ctx, catalog, "list_schemas")
+	if err != nil {
+		return nil, err
 	}
 
 	// Check context before starting operation
@@ -170,7 +318,7 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 		return nil, err
 	}
 
-	rows, err := c.db.QueryContext(ctx, queryListSchemas)
+	rows, err := db.QueryContext(ctx, queryListSchemas)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "list_schemas")
@@ -224,8 +372,9 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 
 // ListTables 列出表
 func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts *collector.ListOptions) (*collector.TableListResult, error) {
-	if c.db == nil {
-		return nil, collector.NewConnectionClosedError(SourceName, "list_tables")
+	db, err := c.conn(ctx, catalog, "list_tables")
+	if err != nil {
+		return nil, err
 	}
 
 	// Check context before starting operation
@@ -233,7 +382,7 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 		return nil, err
 	}
 
-	rows, err := c.db.QueryContext(ctx, queryListTables, schema)
+	rows, err := db.QueryContext(ctx, queryListTables, schema)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "list_tables")
@@ -349,8 +498,9 @@ func (c *Collector) filterTables(tables []string, opts *collector.ListOptions) [
 
 // FetchTableMetadata 获取表元数据
 func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, table string) (*collector.TableMetadata, error) {
-	if c.db == nil {
-		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_metadata")
+	db, err := c.conn(ctx, catalog, "fetch_table_metadata")
+	if err != nil {
+		return nil, err
 	}
 
 	// Check context before starting operation
@@ -361,7 +511,7 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 	// Get table basic info
 	var tableType string
 	var comment sql.NullString
-	err := c.db.QueryRowContext(ctx, queryGetTableInfo, schema, table).Scan(&tableType, &comment)
+	err = db.QueryRowContext(ctx, queryGetTableInfo, schema, table).Scan(&tableType, &comment)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_table_metadata")
@@ -381,13 +531,25 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 		LastRefreshedAt: time.Now(),
 	}
 
+	// Encoding and collation are per database in PostgreSQL; record them
+	// with the time zone timestamptz values are rendered in
+	settings, err := c.serverSettings(ctx, db, catalog)
+	if err != nil {
+		return nil, err
+	}
+	metadata.Properties = map[string]string{
+		"time_zone": settings["time_zone"],
+		"charset":   settings["encoding"],
+		"collation": settings["collation"],
+	}
+
 	// Check context before fetching columns
 	if err := collector.CheckContext(ctx, SourceName, "fetch_table_metadata"); err != nil {
 		return nil, err
 	}
 
 	// Get columns
-	columns, err := c.fetchColumns(ctx, schema, table)
+	columns, err := c.fetchColumns(ctx, db, schema, table)
 	if err != nil {
 		return nil, err
 	}
@@ -399,7 +561,7 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 	}
 
 	// Get primary keys
-	primaryKeys, err := c.fetchPrimaryKeys(ctx, schema, table)
+	primaryKeys, err := c.fetchPrimaryKeys(ctx, db, schema, table)
 	if err != nil {
 		return nil, err
 	}
@@ -416,6 +578,17 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 		}
 	}
 
+	// Check context before fetching check constraints
+	if err := collector.CheckContext(ctx, SourceName, "fetch_table_metadata"); err != nil {
+		return nil, err
+	}
+
+	checks, err := c.fetchCheckConstraints(ctx, db, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	metadata.CheckConstraints = checks
+
 	// Get indexes if configured
 	if c.config.Collect == nil || c.config.Collect.Indexes {
 		// Check context before fetching indexes
@@ -423,7 +596,7 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 			return nil, err
 		}
 
-		indexes, err := c.fetchIndexes(ctx, schema, table)
+		indexes, err := c.fetchIndexes(ctx, db, schema, table)
 		if err != nil {
 			return nil, err
 		}
@@ -434,13 +607,13 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 }
 
 // fetchColumns retrieves column information for a table
-func (c *Collector) fetchColumns(ctx context.Context, schema, table string) ([]collector.Column, error) {
+func (c *Collector) fetchColumns(ctx context.Context, db *sql.DB, schema, table string) ([]collector.Column, error) {
 	// Check context before starting
 	if err := collector.CheckContext(ctx, SourceName, "fetch_columns"); err != nil {
 		return nil, err
 	}
 
-	rows, err := c.db.QueryContext(ctx, queryGetColumns, schema, table)
+	rows, err := db.QueryContext(ctx, queryGetColumns, schema, table)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_columns")
@@ -463,12 +636,16 @@ func (c *Collector) fetchColumns(ctx context.Context, schema, table string) ([]c
 			isNullable                         string
 			columnDefault, columnComment       sql.NullString
 			isIdentity                         string
+			isGenerated                        sql.NullString
+			generationExpr                     sql.NullString
+			charset, collation                 sql.NullString
 		)
 
 		err := rows.Scan(
 			&ordinalPos, &name, &dataType, &udtName,
 			&charMaxLen, &numPrecision, &numScale,
 			&isNullable, &columnDefault, &columnComment, &isIdentity,
+			&isGenerated, &generationExpr, &charset, &collation,
 		)
 		if err != nil {
 			return nil, collector.NewParseError(SourceName, "fetch_columns", err)
@@ -482,6 +659,13 @@ func (c *Collector) fetchColumns(ctx context.Context, schema, table string) ([]c
 			Nullable:        isNullable == "YES",
 			Comment:         columnComment.String,
 			IsAutoIncrement: isIdentity == "YES" || strings.HasPrefix(columnDefault.String, "nextval("),
+			Charset:         charset.String,
+			Collation:       collation.String,
+		}
+
+		// PostgreSQL generated columns are always stored
+		if isGenerated.String == "ALWAYS" && generationExpr.Valid {
+			col.Generated = &collector.GeneratedInfo{Expression: generationExpr.String, Stored: true}
 		}
 
 		if columnDefault.Valid {
@@ -514,13 +698,13 @@ func (c *Collector) fetchColumns(ctx context.Context, schema, table string) ([]c
 }
 
 // fetchPrimaryKeys retrieves primary key columns for a table
-func (c *Collector) fetchPrimaryKeys(ctx context.Context, schema, table string) ([]string, error) {
+func (c *Collector) fetchPrimaryKeys(ctx context.Context, db *sql.DB, schema, table string) ([]string, error) {
 	// Check context before starting
 	if err := collector.CheckContext(ctx, SourceName, "fetch_primary_keys"); err != nil {
 		return nil, err
 	}
 
-	rows, err := c.db.QueryContext(ctx, queryGetPrimaryKeys, schema, table)
+	rows, err := db.QueryContext(ctx, queryGetPrimaryKeys, schema, table)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_primary_keys")
@@ -548,14 +732,44 @@ func (c *Collector) fetchPrimaryKeys(ctx context.Context, schema, table string)
 	return primaryKeys, nil
 }
 
+// fetchCheckConstraints retrieves the CHECK constraints of a table
+func (c *Collector) fetchCheckConstraints(ctx context.Context, db *sql.DB, schema, table string) ([]collector.CheckConstraint, error) {
+	rows, err := db.QueryContext(ctx, queryGetCheckConstraints, schema, table)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_check_constraints")
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_check_constraints", err)
+	}
+	defer rows.Close()
+
+	var checks []collector.CheckConstraint
+	for rows.Next() {
+		var check collector.CheckConstraint
+		if err := rows.Scan(&check.Name, &check.Expression); err != nil {
+			return nil, collector.NewParseError(SourceName, "fetch_check_constraints", err)
+		}
+		checks = append(checks, check)
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_check_constraints")
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_check_constraints", err)
+	}
+
+	return checks, nil
+}
+
 // fetchIndexes retrieves index information for a table
-func (c *Collector) fetchIndexes(ctx context.Context, schema, table string) ([]collector.Index, error) {
+func (c *Collector) fetchIndexes(ctx context.Context, db *sql.DB, schema, table string) ([]collector.Index, error) {
 	// Check context before starting
 	if err := collector.CheckContext(ctx, SourceName, "fetch_indexes"); err != nil {
 		return nil, err
 	}
 
-	rows, err := c.db.QueryContext(ctx, queryGetIndexes, schema, table)
+	rows, err := db.QueryContext(ctx, queryGetIndexes, schema, table)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_indexes")
@@ -615,8 +829,9 @@ func (c *Collector) fetchIndexes(ctx context.Context, schema, table string) ([]c
 
 // FetchTableStatistics 获取表统计信息
 func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, table string) (*collector.TableStatistics, error) {
-	if c.db == nil {
-		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_statistics")
+	db, err := c.conn(ctx, catalog, "fetch_table_statistics")
+	if err != nil {
+		return nil, err
 	}
 
 	// Check context before starting operation
@@ -625,7 +840,7 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 	}
 
 	var reltuples, relpages sql.NullFloat64
-	err := c.db.QueryRowContext(ctx, queryGetTableStats, schema, table).Scan(&reltuples, &relpages)
+	err = db.QueryRowContext(ctx, queryGetTableStats, schema, table).Scan(&reltuples, &relpages)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_table_statistics")
@@ -657,10 +872,57 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 	return stats, nil
 }
 
+// FetchExamples 读取表的前 ExampleSampleRows 行，采集列的示例值
+func (c *Collector) FetchExamples(ctx context.Context, catalog, schema, table string, columns []string, limit int) (map[string][]string, error) {
+	db, err := c.conn(ctx, catalog, "fetch_examples")
+	if err != nil {
+		return nil, err
+	}
+
+	// Check context before starting operation
+	if err := collector.CheckContext(ctx, SourceName, "fetch_examples"); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, exampleQuery(schema, table, columns))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_examples")
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_examples", err)
+	}
+	defer rows.Close()
+
+	examples, err := collector.ScanExamples(rows, columns, limit)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_examples")
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_examples", err)
+	}
+	return examples, nil
+}
+
+// exampleQuery selects the columns of the first rows of a table, as text.
+func exampleQuery(schema, table string, columns []string) string {
+	selected := make([]string, len(columns))
+	for i, col := range columns {
+		selected[i] = quoteIdentifier(col) + "::text"
+	}
+	return fmt.Sprintf("SELECT %s FROM %s.%s LIMIT %d",
+		strings.Join(selected, ", "), quoteIdentifier(schema), quoteIdentifier(table), collector.ExampleSampleRows)
+}
+
+// quoteIdentifier quotes a PostgreSQL identifier with double quotes.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
 // FetchPartitions 获取分区信息
 func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table string) ([]collector.PartitionInfo, error) {
-	if c.db == nil {
-		return nil, collector.NewConnectionClosedError(SourceName, "fetch_partitions")
+	db, err := c.conn(ctx, catalog, "fetch_partitions")
+	if err != nil {
+		return nil, err
 	}
 
 	// Check context before starting operation
@@ -668,7 +930,7 @@ func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table
 		return nil, err
 	}
 
-	rows, err := c.db.QueryContext(ctx, queryGetPartitions, schema, table)
+	rows, err := db.QueryContext(ctx, queryGetPartitions, schema, table)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_partitions")
@@ -712,9 +974,100 @@ func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table
 	return partitions, nil
 }
 
-// buildDSN constructs the PostgreSQL connection string from configuration
-func (c *Collector) buildDSN() (string, error) {
-	endpoint := c.config.Endpoint
+// FetchViewDependencies 从系统目录采集 schema 下视图对表/视图的依赖
+// (pg_depend 中视图的 _RETURN 规则，含物化视图)
+func (c *Collector) FetchViewDependencies(ctx context.Context, catalog, schema string) ([]collector.ViewDependency, error) {
+	db, err := c.conn(ctx, catalog, "fetch_view_dependencies")
+	if err != nil {
+		return nil, err
+	}
+
+	// Check context before starting operation
+	if err := collector.CheckContext(ctx, SourceName, "fetch_view_dependencies"); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, queryGetViewDependencies, schema)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_view_dependencies")
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_view_dependencies", err)
+	}
+	defer rows.Close()
+
+	var deps []collector.ViewDependency
+	for rows.Next() {
+		var dep collector.ViewDependency
+		if err := rows.Scan(&dep.Schema, &dep.View, &dep.RefSchema, &dep.RefTable); err != nil {
+			return nil, collector.NewParseError(SourceName, "fetch_view_dependencies", err)
+		}
+		deps = append(deps, dep)
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_view_dependencies")
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_view_dependencies", err)
+	}
+
+	return deps, nil
+}
+
+// FetchQueryLog 从 pg_stat_statements 扩展读取集群中各数据库执行过的语句及累计执行次数。
+// pg_stat_statements 不记录执行时间，since 被忽略；扩展未安装时返回 UNSUPPORTED_FEATURE
+func (c *Collector) FetchQueryLog(ctx context.Context, since time.Time) ([]collector.QueryLogEntry, error) {
+	db, err := c.conn(ctx, "", "fetch_query_log")
+	if err != nil {
+		return nil, err
+	}
+
+	// Check context before starting operation
+	if err := collector.CheckContext(ctx, SourceName, "fetch_query_log"); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, queryGetQueryLog)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_query_log")
+		}
+		if isUndefinedTable(err) {
+			return nil, collector.NewUnsupportedFeatureError(SourceName, "fetch_query_log", "pg_stat_statements")
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_query_log", err)
+	}
+	defer rows.Close()
+
+	var entries []collector.QueryLogEntry
+	for rows.Next() {
+		entry := collector.QueryLogEntry{Cumulative: true}
+		if err := rows.Scan(&entry.ID, &entry.Database, &entry.SQL, &entry.Calls); err != nil {
+			return nil, collector.NewParseError(SourceName, "fetch_query_log", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "fetch_query_log")
+		}
+		return nil, collector.NewQueryError(SourceName, "fetch_query_log", err)
+	}
+
+	return entries, nil
+}
+
+// isUndefinedTable reports whether err is PostgreSQL's undefined_table
+// error, returned for the views of extensions that are not installed.
+func isUndefinedTable(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "42P01"
+}
+
+// buildDSN constructs the PostgreSQL connection string for a database on an endpoint from configuration
+func (c *Collector) buildDSN(endpoint, database string) (string, error) {
 	if endpoint == "" {
 		return "", fmt.Errorf("endpoint is required")
 	}
@@ -741,14 +1094,6 @@ func (c *Collector) buildDSN() (string, error) {
 		timeout = c.config.Properties.ConnectionTimeout
 	}
 
-	// Get database from extra properties, default to "postgres"
-	database := "postgres"
-	if c.config.Properties.Extra != nil {
-		if db, ok := c.config.Properties.Extra["database"]; ok && db != "" {
-			database = db
-		}
-	}
-
 	// Get SSL mode from extra properties, default to "disable"
 	sslmode := "disable"
 	if c.config.Properties.Extra != nil {
@@ -760,6 +1105,20 @@ func (c *Collector) buildDSN() (string, error) {
 	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s connect_timeout=%d",
 		host, port, user, password, database, sslmode, timeout)
 
+	// Query guards are sent as startup parameters and so apply to every pooled
+	// connection; DBAs see the application name in pg_stat_activity
+	session := c.config.SessionSettings()
+	dsn += " application_name=" + quoteParam(session.ApplicationName)
+	if session.StatementTimeout > 0 {
+		dsn += fmt.Sprintf(" statement_timeout=%d", session.StatementTimeout*1000)
+	}
+	switch session.IsolationLevel {
+	case config.IsolationReadUncommitted:
+		dsn += " default_transaction_isolation='read uncommitted'"
+	case config.IsolationReadCommitted:
+		dsn += " default_transaction_isolation='read committed'"
+	}
+
 	// Add extra parameters
 	if c.config.Properties.Extra != nil {
 		for k, v := range c.config.Properties.Extra {
@@ -772,6 +1131,11 @@ func (c *Collector) buildDSN() (string, error) {
 	return dsn, nil
 }
 
+// quoteParam quotes a connection string value that may contain spaces
+func quoteParam(v string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(v) + "'"
+}
+
 // wrapConnectionError wraps a connection error with appropriate error type
 func (c *Collector) wrapConnectionError(err error) error {
 	errStr := err.Error()
@@ -787,6 +1151,40 @@ func (c *Collector) wrapConnectionError(err error) error {
 	return collector.NewNetworkError(SourceName, "connect", err)
 }
 
+// serverSettings returns the session time zone and the encoding and
+// collation of a database, querying them once per connection.
+func (c *Collector) serverSettings(ctx context.Context, db *sql.DB, database string) (map[string]string, error) {
+	if database == "" {
+		database = c.database()
+	}
+	c.settingsMu.Lock()
+	defer c.settingsMu.Unlock()
+	if settings, ok := c.settings[database]; ok {
+		return settings, nil
+	}
+
+	var timeZone, encoding, collation, ctype string
+	err := db.QueryRowContext(ctx, queryGetServerSettings).Scan(&timeZone, &encoding, &collation, &ctype)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "server_settings")
+		}
+		return nil, collector.NewQueryError(SourceName, "server_settings", err)
+	}
+
+	settings := map[string]string{
+		"time_zone": timeZone,
+		"encoding":  encoding,
+		"collation": collation,
+		"ctype":     ctype,
+	}
+	if c.settings == nil {
+		c.settings = make(map[string]map[string]string)
+	}
+	c.settings[database] = settings
+	return settings, nil
+}
+
 // mapTableType maps PostgreSQL table type to standard TableType
 func (c *Collector) mapTableType(pgType string) collector.TableType {
 	switch strings.ToUpper(pgType) {
@@ -845,5 +1243,8 @@ func (c *Collector) Type() string {
 	return SourceName
 }
 
-// Ensure Collector implements collector.Collector interface
+// Ensure Collector implements the collector.Collector and collector.ViewDependencyCollector interfaces
 var _ collector.Collector = (*Collector)(nil)
+var _ collector.ViewDependencyCollector = (*Collector)(nil)
+var _ collector.ExampleCollector = (*Collector)(nil)
+var _ collector.QueryLogCollector = (*Collector)(nil)
@@ -3,8 +3,10 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"go-metadata/internal/collector"
 	"go-metadata/internal/collector/config"
@@ -163,6 +165,27 @@ func TestBuildDSN(t *testing.T) {
 			wantErr:     false,
 			wantContain: "sslmode=require",
 		},
+		{
+			name: "default application name",
+			cfg: &config.ConnectorConfig{
+				Endpoint: "localhost:5432",
+			},
+			wantErr:     false,
+			wantContain: "application_name='go-metadata'",
+		},
+		{
+			name: "with query guards",
+			cfg: &config.ConnectorConfig{
+				Endpoint: "localhost:5432",
+				Session: &config.SessionConfig{
+					StatementTimeout: 30,
+					IsolationLevel:   config.IsolationReadUncommitted,
+					ApplicationName:  "metadata catalog",
+				},
+			},
+			wantErr:     false,
+			wantContain: "application_name='metadata catalog' statement_timeout=30000 default_transaction_isolation='read uncommitted'",
+		},
 		{
 			name: "empty endpoint",
 			cfg: &config.ConnectorConfig{
@@ -190,7 +213,7 @@ func TestBuildDSN(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &Collector{config: tt.cfg}
-			dsn, err := c.buildDSN()
+			dsn, err := c.buildDSN(tt.cfg.Endpoint, c.database())
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error, got nil")
@@ -208,6 +231,82 @@ func TestBuildDSN(t *testing.T) {
 	}
 }
 
+// TestDatabase tests the database the connection is bound to
+func TestDatabase(t *testing.T) {
+	tests := []struct {
+		name     string
+		database string
+		want     string
+	}{
+		{name: "default", want: "postgres"},
+		{name: "configured", database: "shop", want: "shop"},
+		{name: "all databases", database: AllDatabases, want: "postgres"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.ConnectorConfig{Endpoint: "localhost:5432"}
+			if tt.database != "" {
+				cfg.Properties.Extra = map[string]string{"database": tt.database}
+			}
+			c := &Collector{config: cfg}
+			if got := c.database(); got != tt.want {
+				t.Errorf("database() = %q, want %q", got, tt.want)
+			}
+			dsn, err := c.buildDSN(cfg.Endpoint, c.database())
+			if err != nil {
+				t.Fatalf("buildDSN: %v", err)
+			}
+			if !contains(dsn, "dbname="+tt.want+" ") {
+				t.Errorf("DSN %q should connect to %s", dsn, tt.want)
+			}
+		})
+	}
+}
+
+// TestConnPerCatalog tests that catalogs other than the connected database
+// get their own pooled connections, released on Close
+func TestConnPerCatalog(t *testing.T) {
+	c := &Collector{config: &config.ConnectorConfig{
+		Endpoint:   "localhost:5432",
+		Properties: config.ConnectionProps{Extra: map[string]string{"database": "shop"}},
+	}}
+	// lib/pq connects lazily, so the handles are never dialed
+	main, _ := sql.Open("postgres", "host=localhost dbname=shop")
+	other, _ := sql.Open("postgres", "host=localhost dbname=billing")
+	if err := c.db.Open(func() (*sql.DB, error) { return main, nil }); err != nil {
+		t.Fatal(err)
+	}
+	c.databases = map[string]*sql.DB{"billing": other}
+
+	ctx := context.Background()
+	for _, catalog := range []string{"", "shop"} {
+		if db, err := c.conn(ctx, catalog, "test"); err != nil || db != main {
+			t.Errorf("conn(%q) = %p, %v; want the main connection", catalog, db, err)
+		}
+	}
+	if db, err := c.conn(ctx, "billing", "test"); err != nil || db != other {
+		t.Errorf("conn(billing) = %p, %v; want the pooled connection", db, err)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if c.databases != nil {
+		t.Error("Close should release the pooled connections")
+	}
+	_, err := c.conn(ctx, "billing", "test")
+	assertConnectionClosedError(t, err, "conn")
+}
+
+// TestExampleQuery tests the query reading the example values of columns
+func TestExampleQuery(t *testing.T) {
+	got := exampleQuery("public", `my"table`, []string{"id", "Note"})
+	want := `SELECT "id"::text, "Note"::text FROM "public"."my""table" LIMIT 100`
+	if got != want {
+		t.Errorf("exampleQuery() = %s, want %s", got, want)
+	}
+}
+
 // TestMapTableType tests the table type mapping
 func TestMapTableType(t *testing.T) {
 	c := &Collector{}
@@ -386,6 +485,12 @@ func TestCollectorNotConnected(t *testing.T) {
 
 	_, err = c.FetchPartitions(ctx, "testdb", "public", "users")
 	assertConnectionClosedError(t, err, "FetchPartitions")
+
+	_, err = c.(collector.ExampleCollector).FetchExamples(ctx, "testdb", "public", "users", []string{"id"}, 3)
+	assertConnectionClosedError(t, err, "FetchExamples")
+
+	_, err = c.(collector.QueryLogCollector).FetchQueryLog(ctx, time.Time{})
+	assertConnectionClosedError(t, err, "FetchQueryLog")
 }
 
 // TestCloseNotConnected tests Close when not connected
@@ -3,11 +3,13 @@ package postgres
 
 // SQL queries for PostgreSQL metadata collection
 
-// queryListDatabases retrieves all database names from pg_database
+// queryListDatabases retrieves the names of the databases the user can connect to from pg_database
 const queryListDatabases = `
 SELECT datname 
 FROM pg_database 
 WHERE datistemplate = false 
+  AND datallowconn
+  AND has_database_privilege(datname, 'CONNECT')
   AND datname NOT IN ('postgres')
 ORDER BY datname
 `
@@ -40,7 +42,9 @@ FROM information_schema.tables t
 WHERE t.table_schema = $1 AND t.table_name = $2
 `
 
-// queryGetColumns retrieves column information for a specific table
+// queryGetColumns retrieves column information for a specific table.
+// Collatable columns report the database encoding as their charset and
+// resolve the "default" collation to the database collation.
 const queryGetColumns = `
 SELECT 
     c.ordinal_position,
@@ -53,12 +57,39 @@ SELECT
     c.is_nullable,
     c.column_default,
     col_description((quote_ident(c.table_schema) || '.' || quote_ident(c.table_name))::regclass, c.ordinal_position) as column_comment,
-    c.is_identity
+    c.is_identity,
+    c.is_generated,
+    c.generation_expression,
+    CASE WHEN a.attcollation <> 0 THEN pg_encoding_to_char(d.encoding) END as charset,
+    CASE WHEN a.attcollation <> 0 THEN COALESCE(NULLIF(co.collname, 'default'), d.datcollate) END as collation_name
 FROM information_schema.columns c
+JOIN pg_database d ON d.datname = current_database()
+LEFT JOIN pg_attribute a
+    ON a.attrelid = (quote_ident(c.table_schema) || '.' || quote_ident(c.table_name))::regclass
+    AND a.attname = c.column_name
+LEFT JOIN pg_collation co ON co.oid = a.attcollation
 WHERE c.table_schema = $1 AND c.table_name = $2
 ORDER BY c.ordinal_position
 `
 
+// queryGetServerSettings retrieves the session time zone and the encoding
+// and default collation of the current database
+const queryGetServerSettings = `
+SELECT current_setting('TimeZone'), pg_encoding_to_char(d.encoding), d.datcollate, d.datctype
+FROM pg_database d
+WHERE d.datname = current_database()
+`
+
+// queryGetCheckConstraints retrieves the CHECK constraints of a table
+const queryGetCheckConstraints = `
+SELECT con.conname, pg_get_expr(con.conbin, con.conrelid)
+FROM pg_constraint con
+JOIN pg_class rel ON rel.oid = con.conrelid
+JOIN pg_namespace nsp ON nsp.oid = rel.relnamespace
+WHERE con.contype = 'c' AND nsp.nspname = $1 AND rel.relname = $2
+ORDER BY con.conname
+`
+
 // queryGetIndexes retrieves index information from pg_indexes
 const queryGetIndexes = `
 SELECT 
@@ -111,3 +142,45 @@ JOIN pg_namespace n ON n.oid = parent.relnamespace
 WHERE n.nspname = $1 AND parent.relname = $2
 ORDER BY child.relname
 `
+
+// queryGetViewDependencies retrieves the tables and views referenced by the
+// views and materialized views of a schema. A view depends on its
+// referenced relations through its _RETURN rewrite rule in pg_depend.
+const queryGetViewDependencies = `
+SELECT DISTINCT
+    vn.nspname as view_schema,
+    v.relname as view_name,
+    rn.nspname as ref_schema,
+    r.relname as ref_name
+FROM pg_depend d
+JOIN pg_rewrite rw ON rw.oid = d.objid
+JOIN pg_class v ON v.oid = rw.ev_class
+JOIN pg_namespace vn ON vn.oid = v.relnamespace
+JOIN pg_class r ON r.oid = d.refobjid
+JOIN pg_namespace rn ON rn.oid = r.relnamespace
+WHERE d.classid = 'pg_rewrite'::regclass
+    AND d.refclassid = 'pg_class'::regclass
+    AND d.deptype = 'n'
+    AND v.relkind IN ('v', 'm')
+    AND r.oid <> v.oid
+    AND rn.nspname NOT IN ('pg_catalog', 'information_schema')
+    AND vn.nspname = $1
+ORDER BY view_name, ref_schema, ref_name
+`
+
+// queryGetQueryLog retrieves the statements tracked by the
+// pg_stat_statements extension in every database of the cluster, with their
+// cumulative number of calls. Statements of other users are only visible to
+// superusers and members of pg_read_all_stats.
+const queryGetQueryLog = `
+SELECT
+    s.queryid::text as query_id,
+    d.datname as database_name,
+    s.query,
+    s.calls
+FROM pg_stat_statements s
+JOIN pg_database d ON d.oid = s.dbid
+WHERE s.calls > 0
+    AND s.query <> '<insufficient privilege>'
+ORDER BY s.calls DESC
+`
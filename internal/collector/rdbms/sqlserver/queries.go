@@ -1,4 +1,8 @@
 // Package sqlserver provides SQL Server metadata queries.
+//
+// Queries address the database of the catalog as [?], which catalogQuery
+// replaces with its quoted name since identifiers cannot be parameters; the
+// schema and table are passed as the named parameters @schema and @table.
 package sqlserver
 
 // GetDatabasesQuery returns the query to get all databases in SQL Server.
@@ -29,12 +33,12 @@ func GetTablesQuery() string {
 		SELECT t.name
 		FROM [?].sys.tables t
 		INNER JOIN [?].sys.schemas s ON t.schema_id = s.schema_id
-		WHERE s.name = ?
+		WHERE s.name = @schema
 		UNION ALL
 		SELECT v.name
 		FROM [?].sys.views v
 		INNER JOIN [?].sys.schemas s ON v.schema_id = s.schema_id
-		WHERE s.name = ?
+		WHERE s.name = @schema
 		ORDER BY name`
 }
 
@@ -49,11 +53,11 @@ func GetTableInfoQuery() string {
 			END as table_type,
 			ISNULL(ep.value, '') as description
 		FROM [?].sys.schemas s
-		LEFT JOIN [?].sys.tables t ON s.schema_id = t.schema_id AND t.name = ?
-		LEFT JOIN [?].sys.views v ON s.schema_id = v.schema_id AND v.name = ?
+		LEFT JOIN [?].sys.tables t ON s.schema_id = t.schema_id AND t.name = @table
+		LEFT JOIN [?].sys.views v ON s.schema_id = v.schema_id AND v.name = @table
 		LEFT JOIN [?].sys.extended_properties ep ON ep.major_id = COALESCE(t.object_id, v.object_id)
 			AND ep.minor_id = 0 AND ep.name = 'MS_Description'
-		WHERE s.name = ?`
+		WHERE s.name = @schema`
 }
 
 // GetColumnsQuery returns the query to get all columns for a table.
@@ -68,15 +72,19 @@ func GetColumnsQuery() string {
 			c.scale,
 			CASE WHEN c.is_nullable = 1 THEN 'YES' ELSE 'NO' END as is_nullable,
 			ISNULL(dc.definition, '') as column_default,
-			ISNULL(ep.value, '') as description
+			ISNULL(ep.value, '') as description,
+			ISNULL(cc.definition, '') as computed_definition,
+			ISNULL(cc.is_persisted, 0) as is_persisted,
+			c.is_identity
 		FROM [?].sys.columns c
 		INNER JOIN [?].sys.objects o ON c.object_id = o.object_id
 		INNER JOIN [?].sys.schemas s ON o.schema_id = s.schema_id
 		INNER JOIN [?].sys.types t ON c.user_type_id = t.user_type_id
 		LEFT JOIN [?].sys.default_constraints dc ON c.default_object_id = dc.object_id
+		LEFT JOIN [?].sys.computed_columns cc ON cc.object_id = c.object_id AND cc.column_id = c.column_id
 		LEFT JOIN [?].sys.extended_properties ep ON ep.major_id = c.object_id 
 			AND ep.minor_id = c.column_id AND ep.name = 'MS_Description'
-		WHERE s.name = ? AND o.name = ?
+		WHERE s.name = @schema AND o.name = @table
 		ORDER BY c.column_id`
 }
 
@@ -87,14 +95,16 @@ func GetIndexesQuery() string {
 			i.name,
 			c.name as column_name,
 			ic.key_ordinal,
-			i.is_unique
+			i.is_unique,
+			i.type_desc
 		FROM [?].sys.indexes i
 		INNER JOIN [?].sys.index_columns ic ON i.object_id = ic.object_id AND i.index_id = ic.index_id
 		INNER JOIN [?].sys.columns c ON ic.object_id = c.object_id AND ic.column_id = c.column_id
 		INNER JOIN [?].sys.objects o ON i.object_id = o.object_id
 		INNER JOIN [?].sys.schemas s ON o.schema_id = s.schema_id
-		WHERE s.name = ? AND o.name = ? AND i.name IS NOT NULL
-		ORDER BY i.name, ic.key_ordinal`
+		WHERE s.name = @schema AND o.name = @table AND i.name IS NOT NULL
+			AND ic.is_included_column = 0
+		ORDER BY i.index_id, ic.key_ordinal`
 }
 
 // GetPrimaryKeyQuery returns the query to get primary key columns for a table.
@@ -107,22 +117,23 @@ func GetPrimaryKeyQuery() string {
 		INNER JOIN [?].sys.columns c ON ic.object_id = c.object_id AND ic.column_id = c.column_id
 		INNER JOIN [?].sys.objects o ON kc.parent_object_id = o.object_id
 		INNER JOIN [?].sys.schemas s ON o.schema_id = s.schema_id
-		WHERE s.name = ? AND o.name = ? AND kc.type = 'PK'
+		WHERE s.name = @schema AND o.name = @table AND kc.type = 'PK'
 		ORDER BY ic.key_ordinal`
 }
 
-// GetTableStatsQuery returns the query to get table statistics.
+// GetTableStatsQuery returns the query to get the row count, size and
+// partition count of a table from sys.dm_db_partition_stats. Only the heap or
+// clustered index (index_id 0 or 1) holds the rows and data of a table.
 func GetTableStatsQuery() string {
 	return `
 		SELECT 
-			SUM(p.rows) as row_count,
-			SUM(a.used_pages) * 8 as data_size_kb
-		FROM [?].sys.tables t
-		INNER JOIN [?].sys.schemas s ON t.schema_id = s.schema_id
-		INNER JOIN [?].sys.partitions p ON t.object_id = p.object_id
-		INNER JOIN [?].sys.allocation_units a ON p.partition_id = a.container_id
-		WHERE s.name = ? AND t.name = ? AND p.index_id IN (0, 1)
-		GROUP BY t.object_id`
+			SUM(ps.row_count) as row_count,
+			SUM(ps.used_page_count) * 8 as data_size_kb,
+			COUNT(*) as partition_count
+		FROM [?].sys.dm_db_partition_stats ps
+		INNER JOIN [?].sys.objects o ON ps.object_id = o.object_id
+		INNER JOIN [?].sys.schemas s ON o.schema_id = s.schema_id
+		WHERE s.name = @schema AND o.name = @table AND ps.index_id IN (0, 1)`
 }
 
 // GetPartitionsQuery returns the query to get partition information for a table.
@@ -140,7 +151,7 @@ func GetPartitionsQuery() string {
 		LEFT JOIN [?].sys.partition_functions pf ON ps.function_id = pf.function_id
 		LEFT JOIN [?].sys.partition_range_values prv ON pf.function_id = prv.function_id 
 			AND p.partition_number = prv.boundary_id
-		WHERE s.name = ? AND o.name = ? AND p.partition_number > 1
+		WHERE s.name = @schema AND o.name = @table AND p.partition_number > 1
 		ORDER BY p.partition_number`
 }
 
@@ -163,7 +174,7 @@ func GetForeignKeysQuery() string {
 		INNER JOIN [?].sys.objects o2 ON fk.referenced_object_id = o2.object_id
 		INNER JOIN [?].sys.schemas s1 ON o1.schema_id = s1.schema_id
 		INNER JOIN [?].sys.schemas s2 ON o2.schema_id = s2.schema_id
-		WHERE s1.name = ? AND o1.name = ?
+		WHERE s1.name = @schema AND o1.name = @table
 		ORDER BY fk.name, fkc.constraint_column_id`
 }
 
@@ -177,7 +188,7 @@ func GetCheckConstraintsQuery() string {
 		FROM [?].sys.check_constraints cc
 		INNER JOIN [?].sys.objects o ON cc.parent_object_id = o.object_id
 		INNER JOIN [?].sys.schemas s ON o.schema_id = s.schema_id
-		WHERE s.name = ? AND o.name = ?
+		WHERE s.name = @schema AND o.name = @table
 		ORDER BY cc.name`
 }
 
@@ -195,7 +206,7 @@ func GetTriggersQuery() string {
 		FROM [?].sys.triggers tr
 		INNER JOIN [?].sys.objects o ON tr.parent_id = o.object_id
 		INNER JOIN [?].sys.schemas s ON o.schema_id = s.schema_id
-		WHERE s.name = ? AND o.name = ?
+		WHERE s.name = @schema AND o.name = @table
 		ORDER BY tr.name`
 }
 
@@ -206,7 +217,7 @@ func GetViewDefinitionQuery() string {
 		FROM [?].sys.views v
 		INNER JOIN [?].sys.schemas s ON v.schema_id = s.schema_id
 		INNER JOIN [?].sys.sql_modules m ON v.object_id = m.object_id
-		WHERE s.name = ? AND v.name = ?`
+		WHERE s.name = @schema AND v.name = @table`
 }
 
 // GetStoredProceduresQuery returns the query to get stored procedures in a schema.
@@ -221,7 +232,7 @@ func GetStoredProceduresQuery() string {
 		INNER JOIN [?].sys.schemas s ON p.schema_id = s.schema_id
 		LEFT JOIN [?].sys.extended_properties ep ON ep.major_id = p.object_id 
 			AND ep.minor_id = 0 AND ep.name = 'MS_Description'
-		WHERE s.name = ?
+		WHERE s.name = @schema
 		ORDER BY p.name`
 }
 
@@ -238,7 +249,7 @@ func GetFunctionsQuery() string {
 		INNER JOIN [?].sys.schemas s ON o.schema_id = s.schema_id
 		LEFT JOIN [?].sys.extended_properties ep ON ep.major_id = o.object_id 
 			AND ep.minor_id = 0 AND ep.name = 'MS_Description'
-		WHERE s.name = ? AND o.type IN ('FN', 'IF', 'TF')
+		WHERE s.name = @schema AND o.type IN ('FN', 'IF', 'TF')
 		ORDER BY o.name`
 }
 
@@ -255,7 +266,7 @@ func GetUserDefinedTypesQuery() string {
 		FROM [?].sys.types t
 		INNER JOIN [?].sys.schemas s ON t.schema_id = s.schema_id
 		LEFT JOIN [?].sys.types st ON t.system_type_id = st.system_type_id AND st.user_type_id = st.system_type_id
-		WHERE s.name = ? AND t.is_user_defined = 1
+		WHERE s.name = @schema AND t.is_user_defined = 1
 		ORDER BY t.name`
 }
 
@@ -2,7 +2,11 @@
 // from various data sources such as MySQL, PostgreSQL, and Hive.
 package collector
 
-import "time"
+import (
+	"strconv"
+	"strings"
+	"time"
+)
 
 // TableType 表类型
 type TableType string
@@ -32,6 +36,8 @@ type TableMetadata struct {
 	Name       string    `json:"name"`
 	Type       TableType `json:"type"`
 	Comment    string    `json:"comment,omitempty"`
+	// Descriptions 多语言描述，键为语言标签 (如 en、zh-CN)，见 Description
+	Descriptions map[string]string `json:"descriptions,omitempty"`
 
 	// 结构信息
 	Columns    []Column        `json:"columns"`
@@ -39,6 +45,8 @@ type TableMetadata struct {
 	Indexes    []Index         `json:"indexes,omitempty"`
 	PrimaryKey []string        `json:"primary_key,omitempty"`
 
+	CheckConstraints []CheckConstraint `json:"check_constraints,omitempty"`
+
 	// 存储信息
 	Storage *StorageInfo `json:"storage,omitempty"`
 
@@ -55,23 +63,75 @@ type TableMetadata struct {
 
 // Column 列定义
 type Column struct {
-	OrdinalPosition   int            `json:"ordinal_position"`
-	Name              string         `json:"name"`
-	Type              string         `json:"type"`
-	SourceType        string         `json:"source_type"`
-	Length            *int           `json:"length,omitempty"`
-	Precision         *int           `json:"precision,omitempty"`
-	Scale             *int           `json:"scale,omitempty"`
-	Nullable          bool           `json:"nullable"`
-	Default           *string        `json:"default,omitempty"`
-	Comment           string         `json:"comment,omitempty"`
-	IsPrimaryKey      bool           `json:"is_primary_key"`
-	IsPartitionColumn bool           `json:"is_partition_column"`
-	IsAutoIncrement   bool           `json:"is_auto_increment"`
-	Raw               map[string]any `json:"raw,omitempty"`
+	OrdinalPosition   int               `json:"ordinal_position"`
+	Name              string            `json:"name"`
+	Type              string            `json:"type"`
+	SourceType        string            `json:"source_type"`
+	Length            *int              `json:"length,omitempty"`
+	Precision         *int              `json:"precision,omitempty"`
+	Scale             *int              `json:"scale,omitempty"`
+	Nullable          bool              `json:"nullable"`
+	Default           *string           `json:"default,omitempty"`
+	Comment           string            `json:"comment,omitempty"`
+	Descriptions      map[string]string `json:"descriptions,omitempty"` // 多语言描述，键为语言标签
+	IsPrimaryKey      bool              `json:"is_primary_key"`
+	IsPartitionColumn bool              `json:"is_partition_column"`
+	IsAutoIncrement   bool              `json:"is_auto_increment"`
+	Generated         *GeneratedInfo    `json:"generated,omitempty"`
+	Charset           string            `json:"charset,omitempty"`   // 字符集，仅字符类型列
+	Collation         string            `json:"collation,omitempty"` // 排序规则，仅字符类型列
+	Examples          []string          `json:"examples,omitempty"` // 示例值，仅在同步开启 examples 时采集
+	Raw               map[string]any    `json:"raw,omitempty"`
+}
+
+// GeneratedInfo 生成列/计算列信息
+type GeneratedInfo struct {
+	Expression string `json:"expression"`
+	Stored     bool   `json:"stored"` // 是否持久化存储（否则为虚拟列）
+}
+
+// CheckConstraint CHECK 约束
+type CheckConstraint struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+// ViewDependency 视图依赖：视图 (或物化视图) 直接引用的表或视图，取自系统目录
+type ViewDependency struct {
+	Schema    string `json:"schema"`
+	View      string `json:"view"`
+	RefSchema string `json:"ref_schema"`
+	RefTable  string `json:"ref_table"`
 }
 
 
+// TableFingerprint 表变更指纹：DDL 哈希、最后修改时间与行数，任一变化即视为表已变更。
+// 数据源不提供的部分留空
+type TableFingerprint struct {
+	DDLHash   string    `json:"ddl_hash,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	RowCount  *int64    `json:"row_count,omitempty"`
+}
+
+// String returns the canonical form of the fingerprint that syncs store
+// and compare, or "" if nothing is known about the table.
+func (f TableFingerprint) String() string {
+	if f.DDLHash == "" && f.UpdatedAt.IsZero() && f.RowCount == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(f.DDLHash)
+	b.WriteByte('|')
+	if !f.UpdatedAt.IsZero() {
+		b.WriteString(f.UpdatedAt.UTC().Format(time.RFC3339Nano))
+	}
+	b.WriteByte('|')
+	if f.RowCount != nil {
+		b.WriteString(strconv.FormatInt(*f.RowCount, 10))
+	}
+	return b.String()
+}
+
 // Index 索引定义
 type Index struct {
 	Name    string   `json:"name"`
@@ -88,6 +148,8 @@ type PartitionInfo struct {
 	Columns     []string `json:"columns"`
 	Expression  string   `json:"expression,omitempty"`
 	ValuesCount int      `json:"values_count,omitempty"`
+	// Latest 最新分区的分区值，如 dt=2024-01-15/hour=08，用于新鲜度检查
+	Latest string `json:"latest,omitempty"`
 }
 
 // StorageInfo 存储信息（主要用于 Hive/数据湖）
@@ -105,6 +167,7 @@ type TableStatistics struct {
 	RowCount       int64         `json:"row_count"`
 	DataSizeBytes  int64         `json:"data_size_bytes"`
 	PartitionCount int           `json:"partition_count,omitempty"`
+	ConsumerLag    *int64        `json:"consumer_lag,omitempty"` // 消息队列：最落后消费者组的积压消息数
 	ColumnStats    []ColumnStats `json:"column_stats,omitempty"`
 	CollectedAt    time.Time     `json:"collected_at"`
 }
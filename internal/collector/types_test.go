@@ -404,3 +404,25 @@ func stringPtrEqual(a, b *string) bool {
 	}
 	return *a == *b
 }
+
+func TestTableFingerprintString(t *testing.T) {
+	if s := (TableFingerprint{}).String(); s != "" {
+		t.Errorf("String() of an empty fingerprint = %q, want \"\"", s)
+	}
+
+	rows := int64(42)
+	fp := TableFingerprint{
+		DDLHash:   "abc",
+		UpdatedAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.FixedZone("CST", 8*3600)),
+		RowCount:  &rows,
+	}
+	if s, want := fp.String(), "abc|2024-01-15T02:30:00Z|42"; s != want {
+		t.Errorf("String() = %q, want %q", s, want)
+	}
+
+	// Zero rows differ from unknown rows
+	zero := int64(0)
+	if a, b := (TableFingerprint{DDLHash: "abc", RowCount: &zero}).String(), (TableFingerprint{DDLHash: "abc"}).String(); a == b {
+		t.Errorf("String() of 0 rows = String() of unknown rows = %q", a)
+	}
+}
@@ -11,8 +11,9 @@ import (
 
 	"go-metadata/internal/collector"
 	"go-metadata/internal/collector/config"
+	"go-metadata/internal/tlsprofile"
 
-	_ "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2"
 )
 
 const (
@@ -23,7 +24,7 @@ const (
 // Collector implements the collector.Collector interface for ClickHouse.
 type Collector struct {
 	config *config.ConnectorConfig
-	db     *sql.DB
+	db     collector.Conn[*sql.DB]
 }
 
 // NewCollector creates a new ClickHouse collector instance.
@@ -39,41 +40,45 @@ func NewCollector(cfg *config.ConnectorConfig) (*Collector, error) {
 
 // Connect establishes a connection to the ClickHouse database.
 func (c *Collector) Connect(ctx context.Context) error {
-	if c.db != nil {
-		return nil // Already connected
-	}
+	return c.db.Open(func() (*sql.DB, error) { return c.open(ctx) })
+}
 
+// open opens the connection and checks that it works.
+func (c *Collector) open(ctx context.Context) (*sql.DB, error) {
 	dsn := c.buildDSN()
-	db, err := sql.Open("clickhouse", dsn)
+	// Parse the DSN ourselves to apply the outbound TLS profile when secure is set
+	options, err := clickhouse.ParseDSN(dsn)
 	if err != nil {
-		return collector.NewNetworkErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "connect", err)
+		return nil, collector.NewNetworkErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "connect", err)
 	}
+	if options.TLS != nil {
+		options.TLS = tlsprofile.Restrict(options.TLS)
+	}
+	db := clickhouse.OpenDB(options)
 
 	// Test the connection
 	if err := db.PingContext(ctx); err != nil {
 		db.Close()
-		return collector.NewNetworkErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "connect", err)
+		return nil, collector.NewNetworkErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "connect", err)
 	}
 
-	c.db = db
-	return nil
+	return db, nil
 }
 
 // Close closes the database connection.
 func (c *Collector) Close() error {
-	if c.db != nil {
-		err := c.db.Close()
-		c.db = nil
-		if err != nil {
+	return c.db.Close(func(db *sql.DB) error {
+		if err := db.Close(); err != nil {
 			return collector.NewNetworkErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "close", err)
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 // HealthCheck verifies the database connection is healthy.
 func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return &collector.HealthStatus{
 			Connected: false,
 			Message:   "not connected to ClickHouse database",
@@ -84,7 +89,7 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	if err := c.db.PingContext(ctx); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return &collector.HealthStatus{
 			Connected: false,
 			Latency:   time.Since(start),
@@ -94,7 +99,7 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 
 	// Get version information
 	var version string
-	err := c.db.QueryRowContext(ctx, "SELECT version()").Scan(&version)
+	err := db.QueryRowContext(ctx, "SELECT version()").Scan(&version)
 	if err != nil {
 		version = "unknown"
 	}
@@ -119,7 +124,7 @@ func (c *Collector) Type() string {
 
 // DiscoverCatalogs discovers available catalogs (ClickHouse databases).
 func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogInfo, error) {
-	if c.db == nil {
+	if _, ok := c.db.Get(); !ok {
 		return nil, collector.NewConnectionClosedErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "discover_catalogs")
 	}
 
@@ -142,11 +147,12 @@ func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogIn
 
 // ListSchemas lists all databases in ClickHouse.
 func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "list_schemas")
 	}
 
-	rows, err := c.db.QueryContext(ctx, GetDatabasesQuery())
+	rows, err := db.QueryContext(ctx, GetDatabasesQuery())
 	if err != nil {
 		return nil, collector.NewQueryErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "list_schemas", err)
 	}
@@ -170,7 +176,8 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 
 // ListTables lists all tables in the specified database.
 func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts *collector.ListOptions) (*collector.TableListResult, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "list_tables")
 	}
 
@@ -179,7 +186,7 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 		database = catalog
 	}
 
-	rows, err := c.db.QueryContext(ctx, GetTablesQuery(), database)
+	rows, err := db.QueryContext(ctx, GetTablesQuery(), database)
 	if err != nil {
 		return nil, collector.NewQueryErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "list_tables", err)
 	}
@@ -206,7 +213,8 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 
 // FetchTableMetadata retrieves detailed metadata for a specific table.
 func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, table string) (*collector.TableMetadata, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "fetch_table_metadata")
 	}
 
@@ -225,14 +233,14 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 	}
 
 	// Fetch columns
-	columns, err := c.fetchColumns(ctx, database, table)
+	columns, err := c.fetchColumns(ctx, db, database, table)
 	if err != nil {
 		return nil, err
 	}
 	metadata.Columns = columns
 
 	// Fetch partitions if any
-	partitions, err := c.fetchPartitions(ctx, database, table)
+	partitions, err := c.fetchPartitions(ctx, db, database, table)
 	if err != nil {
 		return nil, err
 	}
@@ -243,7 +251,8 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 
 // FetchTableStatistics retrieves table statistics.
 func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, table string) (*collector.TableStatistics, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "fetch_table_statistics")
 	}
 
@@ -254,7 +263,7 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 
 	var totalRows, totalBytes sql.NullInt64
 
-	err := c.db.QueryRowContext(ctx, GetTableStatsQuery(), database, table).
+	err := db.QueryRowContext(ctx, GetTableStatsQuery(), database, table).
 		Scan(&totalRows, &totalBytes)
 	if err != nil {
 		return nil, collector.NewQueryErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "fetch_table_statistics", err)
@@ -277,11 +286,16 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 
 // FetchPartitions retrieves partition information for a table.
 func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table string) ([]collector.PartitionInfo, error) {
+	db, ok := c.db.Get()
+	if !ok {
+		return nil, collector.NewConnectionClosedErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "fetch_partitions")
+	}
+
 	database := schema
 	if database == "" {
 		database = catalog
 	}
-	return c.fetchPartitions(ctx, database, table)
+	return c.fetchPartitions(ctx, db, database, table)
 }
 
 // buildDSN constructs the ClickHouse connection string.
@@ -344,8 +358,8 @@ func (c *Collector) buildDSN() string {
 }
 
 // fetchColumns retrieves column information for a table.
-func (c *Collector) fetchColumns(ctx context.Context, database, table string) ([]collector.Column, error) {
-	rows, err := c.db.QueryContext(ctx, GetColumnsQuery(), database, table)
+func (c *Collector) fetchColumns(ctx context.Context, db *sql.DB, database, table string) ([]collector.Column, error) {
+	rows, err := db.QueryContext(ctx, GetColumnsQuery(), database, table)
 	if err != nil {
 		return nil, collector.NewQueryErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "fetch_columns", err)
 	}
@@ -390,8 +404,8 @@ func (c *Collector) fetchColumns(ctx context.Context, database, table string) ([
 }
 
 // fetchPartitions retrieves partition information for a table.
-func (c *Collector) fetchPartitions(ctx context.Context, database, table string) ([]collector.PartitionInfo, error) {
-	rows, err := c.db.QueryContext(ctx, GetPartitionsQuery(), database, table)
+func (c *Collector) fetchPartitions(ctx context.Context, db *sql.DB, database, table string) ([]collector.PartitionInfo, error) {
+	rows, err := db.QueryContext(ctx, GetPartitionsQuery(), database, table)
 	if err != nil {
 		return nil, collector.NewQueryErrorWithCategory(collector.CategoryDataWarehouse, SourceName, "fetch_partitions", err)
 	}
@@ -26,7 +26,7 @@ const (
 // Collector Hive 元数据采集器
 type Collector struct {
 	config *config.ConnectorConfig
-	db     *sql.DB
+	db     collector.Conn[*sql.DB]
 }
 
 // NewCollector 创建 Hive 采集器实例
@@ -37,7 +37,35 @@ func NewCollector(cfg *config.ConnectorConfig) (collector.Collector, error) {
 	if cfg.Type != "" && cfg.Type != SourceName {
 		return nil, collector.NewInvalidConfigError(SourceName, "type", fmt.Sprintf("expected '%s', got '%s'", SourceName, cfg.Type))
 	}
-	return &Collector{config: cfg}, nil
+	c := &Collector{config: cfg}
+	switch c.mode() {
+	case ModeHive, ModeSpark, ModeKyuubi:
+	default:
+		return nil, collector.NewInvalidConfigError(SourceName, "properties.extra.mode",
+			fmt.Sprintf("unsupported mode '%s', expected %s, %s or %s", c.mode(), ModeHive, ModeSpark, ModeKyuubi))
+	}
+	return c, nil
+}
+
+// mode 返回服务端模式 (hive, spark 或 kyuubi)，默认 hive
+func (c *Collector) mode() string {
+	if mode := strings.ToLower(strings.TrimSpace(c.config.Properties.Extra["mode"])); mode != "" {
+		return mode
+	}
+	return ModeHive
+}
+
+// sparkSQL 报告服务端是否为 Spark SQL 引擎 (Spark Thrift Server 或 Kyuubi)
+func (c *Collector) sparkSQL() bool {
+	return c.mode() == ModeSpark || c.mode() == ModeKyuubi
+}
+
+// describeQuery 返回获取表详情的语句，Spark SQL 使用 DESCRIBE TABLE EXTENDED
+func (c *Collector) describeQuery(schema, table string) string {
+	if c.sparkSQL() {
+		return fmt.Sprintf("DESCRIBE TABLE EXTENDED %s.%s", schema, table)
+	}
+	return fmt.Sprintf("DESCRIBE FORMATTED %s.%s", schema, table)
 }
 
 // Connect 建立 Hive 连接 (通过 HiveServer2 Thrift 协议)
@@ -45,13 +73,14 @@ func NewCollector(cfg *config.ConnectorConfig) (collector.Collector, error) {
 // - github.com/beltran/gohive (import _ "github.com/beltran/gohive")
 // - github.com/apache/thrift based drivers
 func (c *Collector) Connect(ctx context.Context) error {
-	if c.db != nil {
-		return nil // Already connected
-	}
+	return c.db.Open(func() (*sql.DB, error) { return c.open(ctx) })
+}
 
+// open 建立连接并检查连通性
+func (c *Collector) open(ctx context.Context) (*sql.DB, error) {
 	dsn, err := c.buildDSN()
 	if err != nil {
-		return collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
+		return nil, collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
 	}
 
 	// Get driver name from config, default to "hive"
@@ -64,7 +93,7 @@ func (c *Collector) Connect(ctx context.Context) error {
 
 	db, err := sql.Open(driverName, dsn)
 	if err != nil {
-		return collector.NewNetworkError(SourceName, "connect", err)
+		return nil, collector.NewNetworkError(SourceName, "connect", err)
 	}
 
 	// Configure connection pool
@@ -81,26 +110,23 @@ func (c *Collector) Connect(ctx context.Context) error {
 	// Test connection with context
 	if err := db.PingContext(ctx); err != nil {
 		db.Close()
-		return c.wrapConnectionError(err)
+		return nil, c.wrapConnectionError(err)
 	}
 
-	c.db = db
-	return nil
+	return db, nil
 }
 
 // Close 关闭 Hive 连接
 func (c *Collector) Close() error {
-	if c.db != nil {
-		err := c.db.Close()
-		c.db = nil
-		return err
-	}
-	return nil
+	return c.db.Close(func(db *sql.DB) error {
+		return db.Close()
+	})
 }
 
 // HealthCheck 健康检查
 func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return &collector.HealthStatus{
 			Connected: false,
 			Message:   "not connected",
@@ -110,7 +136,7 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 	start := time.Now()
 
 	// Ping to check connection
-	if err := c.db.PingContext(ctx); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return &collector.HealthStatus{
 			Connected: false,
 			Latency:   time.Since(start),
@@ -120,7 +146,7 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 
 	// Get Hive version using SET command
 	var version string
-	rows, err := c.db.QueryContext(ctx, "SET hive.server2.thrift.http.path")
+	rows, err := db.QueryContext(ctx, "SET hive.server2.thrift.http.path")
 	if err == nil {
 		defer rows.Close()
 		// Try to get version from system properties
@@ -142,10 +168,25 @@ func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, e
 
 // DiscoverCatalogs 发现 Catalog（Hive 中 catalog 通常是单一的）
 func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogInfo, error) {
-	if c.db == nil {
+	if _, ok := c.db.Get(); !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "discover_catalogs")
 	}
 
+	if c.sparkSQL() {
+		description := "Spark Thrift Server"
+		if c.mode() == ModeKyuubi {
+			description = "Apache Kyuubi"
+		}
+		return []collector.CatalogInfo{
+			{
+				Catalog:     "spark_catalog",
+				Type:        SourceName,
+				Description: description,
+				Properties:  map[string]string{"mode": c.mode()},
+			},
+		}, nil
+	}
+
 	// Hive typically has one catalog per connection
 	return []collector.CatalogInfo{
 		{
@@ -159,7 +200,8 @@ func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogIn
 
 // ListSchemas 列出 Schema（Hive 中 schema 等同于 database）
 func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_schemas")
 	}
 
@@ -168,7 +210,7 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 		return nil, err
 	}
 
-	rows, err := c.db.QueryContext(ctx, "SHOW DATABASES")
+	rows, err := db.QueryContext(ctx, "SHOW DATABASES")
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "list_schemas")
@@ -222,7 +264,8 @@ func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string,
 
 // ListTables 列出表
 func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts *collector.ListOptions) (*collector.TableListResult, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "list_tables")
 	}
 
@@ -231,36 +274,44 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 		return nil, err
 	}
 
-	// Use the schema (database) in the query
-	query := fmt.Sprintf("SHOW TABLES IN %s", schema)
-	rows, err := c.db.QueryContext(ctx, query)
-	if err != nil {
-		if ctx.Err() != nil {
-			return nil, collector.WrapContextError(ctx, SourceName, "list_tables")
-		}
-		return nil, collector.NewQueryError(SourceName, "list_tables", err)
-	}
-	defer rows.Close()
-
 	var allTables []string
-	for rows.Next() {
-		// Check context during iteration
-		if err := collector.CheckContext(ctx, SourceName, "list_tables"); err != nil {
+	if c.sparkSQL() {
+		tables, err := c.listSparkTables(ctx, db, schema)
+		if err != nil {
 			return nil, err
 		}
+		allTables = tables
+	} else {
+		// Use the schema (database) in the query
+		query := fmt.Sprintf("SHOW TABLES IN %s", schema)
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, collector.WrapContextError(ctx, SourceName, "list_tables")
+			}
+			return nil, collector.NewQueryError(SourceName, "list_tables", err)
+		}
+		defer rows.Close()
 
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
-			return nil, collector.NewParseError(SourceName, "list_tables", err)
+		for rows.Next() {
+			// Check context during iteration
+			if err := collector.CheckContext(ctx, SourceName, "list_tables"); err != nil {
+				return nil, err
+			}
+
+			var tableName string
+			if err := rows.Scan(&tableName); err != nil {
+				return nil, collector.NewParseError(SourceName, "list_tables", err)
+			}
+			allTables = append(allTables, tableName)
 		}
-		allTables = append(allTables, tableName)
-	}
 
-	if err := rows.Err(); err != nil {
-		if ctx.Err() != nil {
-			return nil, collector.WrapContextError(ctx, SourceName, "list_tables")
+		if err := rows.Err(); err != nil {
+			if ctx.Err() != nil {
+				return nil, collector.WrapContextError(ctx, SourceName, "list_tables")
+			}
+			return nil, collector.NewQueryError(SourceName, "list_tables", err)
 		}
-		return nil, collector.NewQueryError(SourceName, "list_tables", err)
 	}
 
 	// Apply table matching filter
@@ -295,6 +346,67 @@ func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts
 	return result, nil
 }
 
+// listSparkTables 使用 SHOW TABLE EXTENDED 列出 Spark SQL 的表，跳过临时视图。
+// Spark 的 SHOW TABLES 返回 namespace, tableName, isTemporary 三列，与 Hive 不同。
+func (c *Collector) listSparkTables(ctx context.Context, db *sql.DB, schema string) ([]string, error) {
+	query := fmt.Sprintf("SHOW TABLE EXTENDED IN %s LIKE '*'", schema)
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "list_tables")
+		}
+		return nil, collector.NewQueryError(SourceName, "list_tables", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, collector.NewParseError(SourceName, "list_tables", err)
+	}
+	numCols := len(cols)
+
+	var output [][]string
+	for rows.Next() {
+		// Check context during iteration
+		if err := collector.CheckContext(ctx, SourceName, "list_tables"); err != nil {
+			return nil, err
+		}
+
+		values := make([]sql.NullString, numCols)
+		valuePtrs := make([]interface{}, numCols)
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, collector.NewParseError(SourceName, "list_tables", err)
+		}
+
+		row := make([]string, numCols)
+		for i, v := range values {
+			if v.Valid {
+				row[i] = v.String
+			}
+		}
+		output = append(output, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctx.Err() != nil {
+			return nil, collector.WrapContextError(ctx, SourceName, "list_tables")
+		}
+		return nil, collector.NewQueryError(SourceName, "list_tables", err)
+	}
+
+	var tables []string
+	for _, t := range parseShowTableExtended(output) {
+		if !t.Temporary {
+			tables = append(tables, t.Name)
+		}
+	}
+	return tables, nil
+}
+
 // filterTables applies matching rules to filter tables
 func (c *Collector) filterTables(tables []string, opts *collector.ListOptions) []string {
 	// First apply config-level table matching
@@ -347,9 +459,10 @@ func (c *Collector) filterTables(tables []string, opts *collector.ListOptions) [
 }
 
 
-// FetchTableMetadata 获取表元数据 (使用 DESCRIBE FORMATTED)
+// FetchTableMetadata 获取表元数据 (使用 DESCRIBE FORMATTED，Spark SQL 使用 DESCRIBE TABLE EXTENDED)
 func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, table string) (*collector.TableMetadata, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_metadata")
 	}
 
@@ -359,8 +472,7 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 	}
 
 	// Execute DESCRIBE FORMATTED to get full table metadata
-	query := fmt.Sprintf("DESCRIBE FORMATTED %s.%s", schema, table)
-	rows, err := c.db.QueryContext(ctx, query)
+	rows, err := db.QueryContext(ctx, c.describeQuery(schema, table))
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_table_metadata")
@@ -425,7 +537,8 @@ func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, tab
 
 // FetchTableStatistics 获取表统计信息
 func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, table string) (*collector.TableStatistics, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_statistics")
 	}
 
@@ -435,8 +548,7 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 	}
 
 	// Try to get statistics from DESCRIBE FORMATTED
-	query := fmt.Sprintf("DESCRIBE FORMATTED %s.%s", schema, table)
-	rows, err := c.db.QueryContext(ctx, query)
+	rows, err := db.QueryContext(ctx, c.describeQuery(schema, table))
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_table_statistics")
@@ -479,6 +591,9 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 			}
 
 			switch {
+			case key == "Statistics":
+				// Spark SQL: "10485760 bytes, 1000 rows"
+				parseSparkStatistics(value, stats)
 			case strings.Contains(strings.ToLower(key), "numrows"):
 				if n, err := strconv.ParseInt(value, 10, 64); err == nil {
 					stats.RowCount = n
@@ -506,7 +621,8 @@ func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, t
 
 // FetchPartitions 获取分区信息 (使用 SHOW PARTITIONS)
 func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table string) ([]collector.PartitionInfo, error) {
-	if c.db == nil {
+	db, ok := c.db.Get()
+	if !ok {
 		return nil, collector.NewConnectionClosedError(SourceName, "fetch_partitions")
 	}
 
@@ -516,8 +632,7 @@ func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table
 	}
 
 	// First check if table is partitioned by getting partition columns
-	descQuery := fmt.Sprintf("DESCRIBE FORMATTED %s.%s", schema, table)
-	descRows, err := c.db.QueryContext(ctx, descQuery)
+	descRows, err := db.QueryContext(ctx, c.describeQuery(schema, table))
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_partitions")
@@ -584,7 +699,7 @@ func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table
 
 	// Get partition values using SHOW PARTITIONS
 	query := fmt.Sprintf("SHOW PARTITIONS %s.%s", schema, table)
-	rows, err := c.db.QueryContext(ctx, query)
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, collector.WrapContextError(ctx, SourceName, "fetch_partitions")
@@ -598,12 +713,17 @@ func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table
 	defer rows.Close()
 
 	partitionCount := 0
+	latest := ""
 	for rows.Next() {
 		// Check context during iteration
 		if err := collector.CheckContext(ctx, SourceName, "fetch_partitions"); err != nil {
 			return nil, err
 		}
 		partitionCount++
+		var spec string
+		if err := rows.Scan(&spec); err == nil && spec > latest {
+			latest = spec
+		}
 	}
 
 	if err := rows.Err(); err != nil {
@@ -620,6 +740,7 @@ func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table
 			Type:        "LIST", // Hive uses list-style partitioning
 			Columns:     partitionColumns,
 			ValuesCount: partitionCount,
+			Latest:      latest,
 		},
 	}
 
@@ -637,6 +758,9 @@ func (c *Collector) buildDSN() (string, error) {
 	// Parse endpoint (expected format: host:port or host)
 	host := endpoint
 	port := DefaultPort
+	if c.mode() == ModeKyuubi {
+		port = DefaultKyuubiPort
+	}
 
 	if idx := strings.LastIndex(endpoint, ":"); idx != -1 {
 		host = endpoint[:idx]
@@ -679,10 +803,10 @@ func (c *Collector) buildDSN() (string, error) {
 		dsn = fmt.Sprintf("%s@%s:%d/%s?auth=%s", user, host, port, database, authMode)
 	}
 
-	// Add extra parameters
+	// Add extra parameters, except the collector's own settings
 	if c.config.Properties.Extra != nil {
 		for k, v := range c.config.Properties.Extra {
-			if k != "database" && k != "auth" {
+			if k != "database" && k != "auth" && k != "driver" && k != "mode" {
 				dsn += fmt.Sprintf("&%s=%s", k, v)
 			}
 		}
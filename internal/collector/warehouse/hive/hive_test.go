@@ -53,6 +53,29 @@ func TestNewCollector(t *testing.T) {
 			wantErr: true,
 			errCode: collector.ErrCodeInvalidConfig,
 		},
+		{
+			name: "kyuubi mode",
+			cfg: &config.ConnectorConfig{
+				Type:     "hive",
+				Endpoint: "localhost:10009",
+				Properties: config.ConnectionProps{
+					Extra: map[string]string{"mode": "Kyuubi"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unsupported mode",
+			cfg: &config.ConnectorConfig{
+				Type:     "hive",
+				Endpoint: "localhost:10000",
+				Properties: config.ConnectionProps{
+					Extra: map[string]string{"mode": "presto"},
+				},
+			},
+			wantErr: true,
+			errCode: collector.ErrCodeInvalidConfig,
+		},
 	}
 
 	for _, tt := range tests {
@@ -189,6 +212,17 @@ func TestBuildDSN(t *testing.T) {
 			wantErr:     false,
 			wantContain: "hive@localhost:10000",
 		},
+		{
+			name: "kyuubi default port",
+			cfg: &config.ConnectorConfig{
+				Endpoint: "kyuubi.local",
+				Properties: config.ConnectionProps{
+					Extra: map[string]string{"mode": "kyuubi"},
+				},
+			},
+			wantErr:     false,
+			wantContain: "kyuubi.local:10009",
+		},
 	}
 
 	for _, tt := range tests {
@@ -212,6 +246,54 @@ func TestBuildDSN(t *testing.T) {
 	}
 }
 
+// TestBuildDSNCollectorSettings tests that the driver and mode settings are
+// not passed to the driver
+func TestBuildDSNCollectorSettings(t *testing.T) {
+	c := &Collector{config: &config.ConnectorConfig{
+		Endpoint: "localhost:10000",
+		Properties: config.ConnectionProps{
+			Extra: map[string]string{
+				"driver":        "hive",
+				"mode":          "spark",
+				"transportMode": "http",
+			},
+		},
+	}}
+	dsn, err := c.buildDSN()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if contains(dsn, "driver=") || contains(dsn, "mode=spark") {
+		t.Errorf("DSN %q should not contain collector settings", dsn)
+	}
+	if !contains(dsn, "transportMode=http") {
+		t.Errorf("DSN %q should contain transportMode=http", dsn)
+	}
+}
+
+// TestDescribeQuery tests the DESCRIBE statement of each mode
+func TestDescribeQuery(t *testing.T) {
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{"", "DESCRIBE FORMATTED db.t"},
+		{"hive", "DESCRIBE FORMATTED db.t"},
+		{"spark", "DESCRIBE TABLE EXTENDED db.t"},
+		{"kyuubi", "DESCRIBE TABLE EXTENDED db.t"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			c := &Collector{config: &config.ConnectorConfig{
+				Properties: config.ConnectionProps{Extra: map[string]string{"mode": tt.mode}},
+			}}
+			if got := c.describeQuery("db", "t"); got != tt.want {
+				t.Errorf("describeQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
 
 // TestMapTableType tests the table type mapping
 func TestMapTableType(t *testing.T) {
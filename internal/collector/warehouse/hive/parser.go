@@ -19,6 +19,14 @@ import (
 // 3. Detailed Table Information (table properties)
 // 4. Storage Information (input/output format, serde, location)
 // 5. Table Parameters (TBLPROPERTIES)
+//
+// The rows are tokenized first so that the layouts of Hive 1-3 (CDP, EMR)
+// and Spark SQL parse alike: keys with or without a trailing colon, parameters
+// in the second and third cell after an empty first cell, Spark's
+// "Table Properties [k=v, ...]" lists, tab separated single-cell rows and
+// NULL cells. Struct, map and array columns are expanded into nested child
+// columns, see nestedColumns. Delta, Iceberg and Hudi tables are detected
+// from their provider and properties, see detectTableFormat.
 func ParseDescribeFormatted(rows [][]string, catalog, schema, table string) (*collector.TableMetadata, error) {
 	if len(rows) == 0 {
 		return nil, fmt.Errorf("empty DESCRIBE FORMATTED output")
@@ -33,379 +41,288 @@ func ParseDescribeFormatted(rows [][]string, catalog, schema, table string) (*co
 	}
 
 	parser := &describeParser{
-		rows:     rows,
+		rows:     tokenizeDescribe(rows),
 		metadata: metadata,
 	}
 
 	if err := parser.parse(); err != nil {
 		return nil, err
 	}
+	detectTableFormat(metadata)
 
 	return metadata, nil
 }
 
-// describeParser handles parsing of DESCRIBE FORMATTED output
-type describeParser struct {
-	rows     [][]string
-	metadata *collector.TableMetadata
-	idx      int
-}
+// rowKind is the kind of a tokenized DESCRIBE FORMATTED row
+type rowKind int
 
-// Section markers in DESCRIBE FORMATTED output
 const (
-	sectionPartitionInfo    = "# Partition Information"
-	sectionDetailedInfo     = "# Detailed Table Information"
-	sectionStorageInfo      = "# Storage Information"
-	sectionStorageDesc      = "# Storage Desc Params"
-	sectionTableParameters  = "Table Parameters:"
-	sectionColumnHeader     = "# col_name"
+	rowBlank   rowKind = iota
+	rowSection         // # Partition Information, # Detailed Table Information, ...
+	rowHeader          // # col_name  data_type  comment
+	rowField           // key/column name, value/data type, comment
 )
 
-func (p *describeParser) parse() error {
-	// Parse columns first (before any section marker)
-	p.parseColumns()
-
-	// Continue parsing other sections
-	for p.idx < len(p.rows) {
-		row := p.rows[p.idx]
-		if len(row) == 0 {
-			p.idx++
-			continue
-		}
+// describeRow is a tokenized DESCRIBE FORMATTED row
+type describeRow struct {
+	kind  rowKind
+	key   string // column name or property key without trailing colon; section name for rowSection
+	value string
+	extra string
+}
 
-		col0 := strings.TrimSpace(row[0])
+// tokenizeDescribe trims and classifies the raw rows
+func tokenizeDescribe(rows [][]string) []describeRow {
+	tokens := make([]describeRow, 0, len(rows))
+	for _, row := range rows {
+		// Some drivers return each row as a single tab separated cell
+		if len(row) == 1 && strings.Contains(row[0], "\t") {
+			row = strings.Split(row[0], "\t")
+		}
+		cells := make([]string, 3)
+		for i := 0; i < len(row) && i < len(cells); i++ {
+			cells[i] = strings.TrimSpace(row[i])
+		}
+		// Hive prints absent values and comments as NULL
+		for i := 1; i < len(cells); i++ {
+			if strings.EqualFold(cells[i], "null") {
+				cells[i] = ""
+			}
+		}
 
 		switch {
-		case strings.Contains(col0, sectionPartitionInfo):
-			p.idx++
-			p.parsePartitionInfo()
-		case strings.Contains(col0, sectionDetailedInfo):
-			p.idx++
-			p.parseDetailedInfo()
-		case strings.Contains(col0, sectionStorageInfo):
-			p.idx++
-			p.parseStorageInfo()
-		case strings.Contains(col0, sectionStorageDesc):
-			p.idx++
-			p.parseStorageDescParams()
+		case cells[0] == "" && cells[1] == "" && cells[2] == "":
+			tokens = append(tokens, describeRow{kind: rowBlank})
+		case strings.HasPrefix(cells[0], "#"):
+			name := strings.ToLower(strings.Join(strings.Fields(strings.TrimPrefix(cells[0], "#")), " "))
+			if strings.HasPrefix(name, "col_name") {
+				tokens = append(tokens, describeRow{kind: rowHeader})
+			} else {
+				tokens = append(tokens, describeRow{kind: rowSection, key: strings.TrimSuffix(name, ":")})
+			}
 		default:
-			p.idx++
+			tokens = append(tokens, describeRow{
+				kind:  rowField,
+				key:   strings.TrimSpace(strings.TrimSuffix(cells[0], ":")),
+				value: cells[1],
+				extra: cells[2],
+			})
 		}
 	}
-
-	return nil
+	return tokens
 }
 
+// describeSection is the section of the output being parsed
+type describeSection int
 
-// parseColumns parses the column information section
-func (p *describeParser) parseColumns() {
-	ordinalPos := 1
-
-	for p.idx < len(p.rows) {
-		row := p.rows[p.idx]
-		if len(row) == 0 {
-			p.idx++
-			continue
-		}
-
-		col0 := strings.TrimSpace(row[0])
+const (
+	sectionColumns describeSection = iota
+	sectionPartitionInfo
+	sectionPartitioning // Spark 3 "# Partitioning": Part 0 | dt
+	sectionDetailedInfo
+	sectionStorageDescParams
+	sectionOther // constraints, metadata columns, ...
+)
 
-		// Check for section markers
-		if strings.HasPrefix(col0, "#") {
-			// Check if this is the column header row
-			if strings.Contains(col0, "col_name") {
-				p.idx++
-				continue
-			}
-			// Other section marker, stop parsing columns
-			return
-		}
+// describeParser handles parsing of DESCRIBE FORMATTED output
+type describeParser struct {
+	rows     []describeRow
+	metadata *collector.TableMetadata
 
-		// Skip empty or separator rows
-		if col0 == "" || col0 == "---" {
-			p.idx++
-			continue
-		}
+	// inParams is set on the parameter rows following a "Table Parameters:"
+	// or "Storage Desc Params:" row, stored with the key prefix paramPrefix
+	inParams    bool
+	paramPrefix string
+	// partitionColumns in declaration order
+	partitionColumns []string
+}
 
-		// Parse column: col_name, data_type, comment
-		colName := col0
-		dataType := ""
-		comment := ""
+// storageParamPrefix prefixes storage descriptor parameters in Properties
+const storageParamPrefix = "storage."
+
+// sections maps section names to sections
+var sections = map[string]describeSection{
+	"partition information":      sectionPartitionInfo,
+	"partitioning":               sectionPartitioning,
+	"detailed table information": sectionDetailedInfo,
+	"storage information":        sectionDetailedInfo,
+	"view information":           sectionDetailedInfo,
+	"storage desc params":        sectionStorageDescParams,
+}
 
-		if len(row) > 1 {
-			dataType = strings.TrimSpace(row[1])
-		}
-		if len(row) > 2 {
-			comment = strings.TrimSpace(row[2])
-		}
+func (p *describeParser) parse() error {
+	section := sectionColumns
+	var columns []collector.Column
 
-		// Skip if this looks like a property row (contains ':')
-		if strings.Contains(colName, ":") && !strings.Contains(dataType, "<") {
-			p.idx++
+	for _, row := range p.rows {
+		switch row.kind {
+		case rowBlank, rowHeader:
 			continue
-		}
-
-		// Skip if dataType is empty (likely a section marker or property)
-		if dataType == "" {
-			p.idx++
+		case rowSection:
+			var ok bool
+			if section, ok = sections[row.key]; !ok {
+				section = sectionOther
+			}
+			p.inParams, p.paramPrefix = section == sectionStorageDescParams, storageParamPrefix
 			continue
 		}
 
-		col := collector.Column{
-			OrdinalPosition: ordinalPos,
-			Name:            colName,
-			Type:            normalizeHiveType(dataType),
-			SourceType:      dataType,
-			Nullable:        true, // Hive columns are nullable by default
-			Comment:         comment,
+		switch section {
+		case sectionColumns:
+			// Skip rows without a data type and property rows preceding the sections
+			if row.key == "" || row.value == "" || strings.HasSuffix(row.key, ":") {
+				continue
+			}
+			columns = append(columns, newColumn(row.key, row.value, row.extra))
+		case sectionPartitionInfo:
+			if row.key != "" && row.value != "" {
+				p.addPartitionColumn(row.key, columns)
+			}
+		case sectionPartitioning:
+			if row.value != "" {
+				p.addPartitionColumn(row.value, columns)
+			}
+		case sectionDetailedInfo, sectionStorageDescParams:
+			p.parseDetail(row)
 		}
-
-		// Parse type parameters (length, precision, scale)
-		parseTypeParams(dataType, &col)
-
-		p.metadata.Columns = append(p.metadata.Columns, col)
-		ordinalPos++
-		p.idx++
 	}
-}
 
-// parsePartitionInfo parses the partition information section
-func (p *describeParser) parsePartitionInfo() {
-	var partitionColumns []string
-	seenHeader := false
-
-	for p.idx < len(p.rows) {
-		row := p.rows[p.idx]
-		if len(row) == 0 {
-			p.idx++
-			continue
-		}
-
-		col0 := strings.TrimSpace(row[0])
-
-		// Check for next section
-		if strings.Contains(col0, "# Detailed") || strings.Contains(col0, "# Storage") {
-			break
-		}
-
-		// Skip header rows (# col_name, etc.)
-		if strings.HasPrefix(col0, "#") {
-			seenHeader = true
-			p.idx++
-			continue
-		}
-
-		// Skip empty rows
-		if col0 == "" {
-			p.idx++
-			continue
-		}
+	if len(columns) == 0 && len(p.metadata.Properties) == 0 && p.metadata.Storage == nil {
+		return fmt.Errorf("no columns or table information in DESCRIBE FORMATTED output")
+	}
 
-		// After seeing header, this should be a partition column
-		if seenHeader {
-			// Check if this looks like a column definition (has data type in second column)
-			if len(row) > 1 && strings.TrimSpace(row[1]) != "" {
-				partitionColumns = append(partitionColumns, col0)
-
-				// Mark the column as partition column in metadata
-				for i := range p.metadata.Columns {
-					if p.metadata.Columns[i].Name == col0 {
-						p.metadata.Columns[i].IsPartitionColumn = true
-						break
-					}
-				}
-			}
+	// Expand complex columns, numbering the columns in output order
+	for _, col := range columns {
+		p.metadata.Columns = append(p.metadata.Columns, col)
+		if t, err := parseHiveType(col.SourceType); err == nil {
+			p.metadata.Columns = append(p.metadata.Columns, nestedColumns(col.Name, t, 0)...)
 		}
-
-		p.idx++
+	}
+	for i := range p.metadata.Columns {
+		p.metadata.Columns[i].OrdinalPosition = i + 1
 	}
 
 	// Add partition info if we found partition columns
-	if len(partitionColumns) > 0 {
+	if len(p.partitionColumns) > 0 {
 		p.metadata.Partitions = append(p.metadata.Partitions, collector.PartitionInfo{
 			Name:    "partition",
 			Type:    "LIST",
-			Columns: partitionColumns,
+			Columns: p.partitionColumns,
 		})
 	}
-}
-
-// parseDetailedInfo parses the detailed table information section
-func (p *describeParser) parseDetailedInfo() {
-	for p.idx < len(p.rows) {
-		row := p.rows[p.idx]
-		if len(row) == 0 {
-			p.idx++
-			continue
-		}
 
-		col0 := strings.TrimSpace(row[0])
+	return nil
+}
 
-		// Check for next section
-		if strings.HasPrefix(col0, "# Storage") {
+// addPartitionColumn records a partition column and marks it in columns
+func (p *describeParser) addPartitionColumn(name string, columns []collector.Column) {
+	for _, existing := range p.partitionColumns {
+		if strings.EqualFold(existing, name) {
 			return
 		}
+	}
+	p.partitionColumns = append(p.partitionColumns, name)
 
-		// Parse key-value pairs
-		key := col0
-		value := ""
-		if len(row) > 1 {
-			value = strings.TrimSpace(row[1])
-		}
-
-		// Handle multi-column values
-		if len(row) > 2 && row[2] != "" {
-			value = value + " " + strings.TrimSpace(row[2])
-		}
-
-		// Remove trailing colon from key
-		key = strings.TrimSuffix(key, ":")
-
-		switch strings.ToLower(key) {
-		case "table type":
-			p.metadata.Type = mapHiveTableType(value)
-		case "comment":
-			p.metadata.Comment = value
-		case "table parameters":
-			p.idx++
-			p.parseTableParameters()
-			continue
-		default:
-			// Store other properties
-			if key != "" && value != "" && !strings.HasPrefix(key, "#") {
-				p.metadata.Properties[key] = value
-			}
+	for i := range columns {
+		if strings.EqualFold(columns[i].Name, name) {
+			columns[i].IsPartitionColumn = true
+			break
 		}
-
-		p.idx++
 	}
 }
 
-
-// parseTableParameters parses the TBLPROPERTIES section
-func (p *describeParser) parseTableParameters() {
-	for p.idx < len(p.rows) {
-		row := p.rows[p.idx]
-		if len(row) == 0 {
-			p.idx++
-			continue
+// parseDetail parses a row of the detailed table, storage and view sections.
+// Spark lists the storage keys in the detailed section and Hive lists the
+// location there, so all keys are handled wherever they appear.
+func (p *describeParser) parseDetail(row describeRow) {
+	// Parameter rows: "" | key | value
+	if row.key == "" {
+		if p.inParams && row.value != "" {
+			p.metadata.Properties[p.paramPrefix+row.value] = row.extra
 		}
+		return
+	}
+	p.inParams = false
 
-		col0 := strings.TrimSpace(row[0])
+	key, value := row.key, row.value
+	// Handle multi-column values
+	if row.extra != "" {
+		value = strings.TrimSpace(value + " " + row.extra)
+	}
 
-		// Check for next section
-		if strings.HasPrefix(col0, "# Storage") || strings.HasPrefix(col0, "# Detailed") {
+	switch strings.ToLower(strings.Join(strings.Fields(key), " ")) {
+	case "table type", "type":
+		p.metadata.Type = mapHiveTableType(value)
+	case "comment":
+		p.metadata.Comment = value
+	case "table parameters", "table properties":
+		if value == "" {
+			p.inParams, p.paramPrefix = true, ""
 			return
 		}
-
-		// Skip empty or header rows
-		if col0 == "" || strings.HasPrefix(col0, "#") {
-			p.idx++
-			continue
+		parsePropertyList(value, "", p.metadata.Properties)
+	case "storage desc params", "storage properties":
+		if value == "" {
+			p.inParams, p.paramPrefix = true, storageParamPrefix
+			return
 		}
-
-		// Parse property: key, value
-		key := col0
-		value := ""
-		if len(row) > 1 {
-			value = strings.TrimSpace(row[1])
+		parsePropertyList(value, storageParamPrefix, p.metadata.Properties)
+	case "inputformat":
+		p.storage().InputFormat = value
+		p.storage().Format = extractStorageFormat(value)
+	case "outputformat":
+		p.storage().OutputFormat = value
+	case "serde library", "serdelibrary":
+		p.storage().SerDe = value
+	case "location":
+		p.storage().Location = value
+	case "compressed":
+		p.storage().Compressed = strings.EqualFold(value, "yes") || strings.EqualFold(value, "true")
+	case "provider":
+		// Spark data source tables: parquet, orc, delta, iceberg, ...
+		p.metadata.Properties[key] = value
+		if s := p.storage(); s.Format == "" {
+			s.Format = strings.ToLower(value)
 		}
-
-		if key != "" && value != "" {
+	default:
+		// Store other properties
+		if value != "" {
 			p.metadata.Properties[key] = value
 		}
-
-		p.idx++
 	}
 }
 
-// parseStorageInfo parses the storage information section
-func (p *describeParser) parseStorageInfo() {
+// storage returns the storage info, creating it on first use
+func (p *describeParser) storage() *collector.StorageInfo {
 	if p.metadata.Storage == nil {
 		p.metadata.Storage = &collector.StorageInfo{}
 	}
+	return p.metadata.Storage
+}
 
-	for p.idx < len(p.rows) {
-		row := p.rows[p.idx]
-		if len(row) == 0 {
-			p.idx++
-			continue
-		}
-
-		col0 := strings.TrimSpace(row[0])
-
-		// Check for next section
-		if strings.HasPrefix(col0, "# Storage Desc") {
-			return
-		}
-
-		// Skip header rows
-		if strings.HasPrefix(col0, "#") {
-			p.idx++
-			continue
-		}
-
-		// Parse key-value pairs
-		key := strings.TrimSuffix(col0, ":")
-		value := ""
-		if len(row) > 1 {
-			value = strings.TrimSpace(row[1])
-		}
-
-		switch strings.ToLower(key) {
-		case "inputformat":
-			p.metadata.Storage.InputFormat = value
-			p.metadata.Storage.Format = extractStorageFormat(value)
-		case "outputformat":
-			p.metadata.Storage.OutputFormat = value
-		case "serde library", "serdelibrary":
-			p.metadata.Storage.SerDe = value
-		case "location":
-			p.metadata.Storage.Location = value
-		case "compressed":
-			p.metadata.Storage.Compressed = strings.ToLower(value) == "yes" || strings.ToLower(value) == "true"
+// parsePropertyList parses Spark's "[k1=v1, k2=v2]" property lists
+func parsePropertyList(list, prefix string, properties map[string]string) {
+	list = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(list), "["), "]")
+	for _, kv := range strings.Split(list, ", ") {
+		k, v, ok := strings.Cut(kv, "=")
+		if k = strings.TrimSpace(k); ok && k != "" {
+			properties[prefix+k] = strings.TrimSpace(v)
 		}
-
-		p.idx++
 	}
 }
 
-// parseStorageDescParams parses storage descriptor parameters
-func (p *describeParser) parseStorageDescParams() {
-	for p.idx < len(p.rows) {
-		row := p.rows[p.idx]
-		if len(row) == 0 {
-			p.idx++
-			continue
-		}
-
-		col0 := strings.TrimSpace(row[0])
-
-		// Check for next section
-		if strings.HasPrefix(col0, "#") && !strings.Contains(col0, "Storage Desc") {
-			return
-		}
-
-		// Skip header rows
-		if strings.HasPrefix(col0, "#") || col0 == "" {
-			p.idx++
-			continue
-		}
-
-		// Parse property
-		key := col0
-		value := ""
-		if len(row) > 1 {
-			value = strings.TrimSpace(row[1])
-		}
-
-		// Store as property
-		if key != "" && value != "" {
-			p.metadata.Properties["storage."+key] = value
-		}
-
-		p.idx++
+// newColumn creates a column of a Hive data type
+func newColumn(name, dataType, comment string) collector.Column {
+	col := collector.Column{
+		Name:       name,
+		Type:       normalizeHiveType(dataType),
+		SourceType: dataType,
+		Nullable:   true, // Hive columns are nullable by default
+		Comment:    comment,
 	}
+	// Parse type parameters (length, precision, scale)
+	parseTypeParams(dataType, &col)
+	return col
 }
 
 // normalizeHiveType normalizes Hive data type to standard type
@@ -482,11 +399,11 @@ func mapHiveTableType(hiveType string) collector.TableType {
 	switch strings.ToUpper(hiveType) {
 	case "VIRTUAL_VIEW", "VIEW":
 		return collector.TableTypeView
-	case "EXTERNAL_TABLE":
+	case "EXTERNAL_TABLE", "EXTERNAL":
 		return collector.TableTypeExternalTable
 	case "MATERIALIZED_VIEW":
 		return collector.TableTypeMaterializedView
-	case "MANAGED_TABLE", "TABLE":
+	case "MANAGED_TABLE", "MANAGED", "TABLE":
 		return collector.TableTypeTable
 	default:
 		return collector.TableTypeTable
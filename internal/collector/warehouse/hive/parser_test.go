@@ -321,6 +321,8 @@ func TestMapHiveTableType(t *testing.T) {
 		{"MATERIALIZED_VIEW", collector.TableTypeMaterializedView},
 		{"managed_table", collector.TableTypeTable},
 		{"external_table", collector.TableTypeExternalTable},
+		{"EXTERNAL", collector.TableTypeExternalTable},
+		{"MANAGED", collector.TableTypeTable},
 		{"UNKNOWN", collector.TableTypeTable},
 		{"", collector.TableTypeTable},
 	}
@@ -422,6 +424,251 @@ func TestParseDescribeFormattedWithComment(t *testing.T) {
 	}
 }
 
+// TestParseDescribeFormattedCDP tests the Hive 3 (CDP) layout with parameter
+// rows in the second and third cell and NULL comments
+func TestParseDescribeFormattedCDP(t *testing.T) {
+	rows := [][]string{
+		{"# col_name            ", "data_type           ", "comment             "},
+		{"id", "bigint", "NULL"},
+		{"amount", "decimal(12,2)", "Order amount"},
+		{"dt", "string", ""},
+		{"", "NULL", "NULL"},
+		{"# Partition Information", "NULL", "NULL"},
+		{"# col_name            ", "data_type           ", "comment             "},
+		{"dt", "string", ""},
+		{"", "NULL", "NULL"},
+		{"# Detailed Table Information", "NULL", "NULL"},
+		{"Database:           ", "sales", "NULL"},
+		{"OwnerType:          ", "USER", "NULL"},
+		{"Location:           ", "hdfs://ns1/warehouse/tablespace/external/hive/sales.db/orders", "NULL"},
+		{"Table Type:         ", "EXTERNAL_TABLE", "NULL"},
+		{"Table Parameters:", "NULL", "NULL"},
+		{"", "EXTERNAL", "TRUE"},
+		{"", "comment", "Orders"},
+		{"", "numRows", "1024"},
+		{"", "NULL", "NULL"},
+		{"# Storage Information", "NULL", "NULL"},
+		{"SerDe Library:      ", "org.apache.hadoop.hive.ql.io.orc.OrcSerde", "NULL"},
+		{"InputFormat:        ", "org.apache.hadoop.hive.ql.io.orc.OrcInputFormat", "NULL"},
+		{"Compressed:         ", "No", "NULL"},
+		{"Storage Desc Params:", "NULL", "NULL"},
+		{"", "serialization.format", "1"},
+		{"", "NULL", "NULL"},
+		{"# Constraints", "NULL", "NULL"},
+		{"# Primary Key", "NULL", "NULL"},
+		{"Table:", "sales.orders", "NULL"},
+	}
+
+	metadata, err := ParseDescribeFormatted(rows, "hive", "sales", "orders")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(metadata.Columns) != 3 {
+		t.Fatalf("expected 3 columns, got %d", len(metadata.Columns))
+	}
+	if metadata.Columns[0].Comment != "" {
+		t.Errorf("expected NULL comment to be empty, got %q", metadata.Columns[0].Comment)
+	}
+	if !intPtrEqual(metadata.Columns[1].Precision, intPtr(12)) || !intPtrEqual(metadata.Columns[1].Scale, intPtr(2)) {
+		t.Errorf("unexpected decimal params: %+v", metadata.Columns[1])
+	}
+	if !metadata.Columns[2].IsPartitionColumn {
+		t.Error("expected dt to be a partition column")
+	}
+	if metadata.Type != collector.TableTypeExternalTable {
+		t.Errorf("expected external table, got %s", metadata.Type)
+	}
+	if metadata.Storage == nil || metadata.Storage.Format != "orc" || metadata.Storage.Compressed ||
+		metadata.Storage.Location != "hdfs://ns1/warehouse/tablespace/external/hive/sales.db/orders" {
+		t.Errorf("unexpected storage: %+v", metadata.Storage)
+	}
+
+	wantProps := map[string]string{
+		"Database":                     "sales",
+		"OwnerType":                    "USER",
+		"EXTERNAL":                     "TRUE",
+		"comment":                      "Orders",
+		"numRows":                      "1024",
+		"storage.serialization.format": "1",
+	}
+	for k, v := range wantProps {
+		if metadata.Properties[k] != v {
+			t.Errorf("expected property %s=%q, got %q", k, v, metadata.Properties[k])
+		}
+	}
+	if _, ok := metadata.Properties["Table"]; ok {
+		t.Error("expected constraint rows to be ignored")
+	}
+}
+
+// TestParseDescribeFormattedSpark tests the Spark SQL layout without colons,
+// column header or storage section
+func TestParseDescribeFormattedSpark(t *testing.T) {
+	rows := [][]string{
+		{"id\tbigint\t"},
+		{"event\tstring\tEvent name"},
+		{"dt\tdate\t"},
+		{"# Partition Information\t\t"},
+		{"# col_name\tdata_type\tcomment"},
+		{"dt\tdate\t"},
+		{"\t\t"},
+		{"# Detailed Table Information\t\t"},
+		{"Catalog\tspark_catalog\t"},
+		{"Database\tdefault\t"},
+		{"Type\tEXTERNAL\t"},
+		{"Provider\tdelta\t"},
+		{"Comment\tClick events\t"},
+		{"Table Properties\t[delta.minReaderVersion=1, delta.minWriterVersion=2]\t"},
+		{"Location\ts3://lake/events\t"},
+		{"Storage Properties\t[serialization.format=1]\t"},
+	}
+
+	metadata, err := ParseDescribeFormatted(rows, "spark", "default", "events")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(metadata.Columns) != 3 || metadata.Columns[1].Comment != "Event name" {
+		t.Fatalf("unexpected columns: %+v", metadata.Columns)
+	}
+	if len(metadata.Partitions) != 1 || metadata.Partitions[0].Columns[0] != "dt" || !metadata.Columns[2].IsPartitionColumn {
+		t.Errorf("unexpected partitions: %+v", metadata.Partitions)
+	}
+	if metadata.Type != collector.TableTypeExternalTable {
+		t.Errorf("expected external table, got %s", metadata.Type)
+	}
+	if metadata.Comment != "Click events" {
+		t.Errorf("unexpected comment %q", metadata.Comment)
+	}
+	if metadata.Storage == nil || metadata.Storage.Format != "delta" || metadata.Storage.Location != "s3://lake/events" {
+		t.Errorf("unexpected storage: %+v", metadata.Storage)
+	}
+	if metadata.Properties["table_format"] != TableFormatDelta {
+		t.Errorf("expected delta table format, got %q", metadata.Properties["table_format"])
+	}
+	if metadata.Properties["delta.minWriterVersion"] != "2" || metadata.Properties["storage.serialization.format"] != "1" {
+		t.Errorf("unexpected properties: %v", metadata.Properties)
+	}
+}
+
+// TestParseDescribeFormattedSparkPartitioning tests Spark 3 "# Partitioning"
+func TestParseDescribeFormattedSparkPartitioning(t *testing.T) {
+	rows := [][]string{
+		{"ts", "timestamp", ""},
+		{"region", "string", ""},
+		{"", "", ""},
+		{"# Partitioning", "", ""},
+		{"Part 0", "region", ""},
+		{"Part 1", "days(ts)", ""},
+	}
+
+	metadata, err := ParseDescribeFormatted(rows, "spark", "default", "t")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(metadata.Partitions) != 1 || len(metadata.Partitions[0].Columns) != 2 {
+		t.Fatalf("unexpected partitions: %+v", metadata.Partitions)
+	}
+	if !metadata.Columns[1].IsPartitionColumn || metadata.Columns[0].IsPartitionColumn {
+		t.Errorf("unexpected partition columns: %+v", metadata.Columns)
+	}
+}
+
+// TestParseDescribeFormattedNested tests expansion of complex column types
+func TestParseDescribeFormattedNested(t *testing.T) {
+	rows := [][]string{
+		{"# col_name", "data_type", "comment"},
+		{"id", "int", ""},
+		{"address", "struct<street:string,geo:struct<lat:double,lon:double>>", ""},
+		{"items", "array<struct<sku:string COMMENT 'Stock unit',price:decimal(10,2)>>", ""},
+		{"attrs", "map<string,array<int>>", ""},
+		{"tags", "array<string>", ""},
+	}
+
+	metadata, err := ParseDescribeFormatted(rows, "hive", "default", "orders")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []struct {
+		name   string
+		typ    string
+		source string
+		parent string
+	}{
+		{"id", "INTEGER", "int", ""},
+		{"address", "STRUCT", "struct<street:string,geo:struct<lat:double,lon:double>>", ""},
+		{"address.street", "STRING", "string", "address"},
+		{"address.geo", "STRUCT", "struct<lat:double,lon:double>", "address"},
+		{"address.geo.lat", "FLOAT", "double", "address.geo"},
+		{"address.geo.lon", "FLOAT", "double", "address.geo"},
+		{"items", "ARRAY", "array<struct<sku:string COMMENT 'Stock unit',price:decimal(10,2)>>", ""},
+		{"items.sku", "STRING", "string", "items"},
+		{"items.price", "DECIMAL", "decimal(10,2)", "items"},
+		{"attrs", "MAP", "map<string,array<int>>", ""},
+		{"attrs.key", "STRING", "string", "attrs"},
+		{"attrs.value", "ARRAY", "array<int>", "attrs"},
+		{"tags", "ARRAY", "array<string>", ""},
+	}
+	if len(metadata.Columns) != len(want) {
+		t.Fatalf("expected %d columns, got %d: %+v", len(want), len(metadata.Columns), metadata.Columns)
+	}
+	for i, w := range want {
+		col := metadata.Columns[i]
+		if col.Name != w.name || col.Type != w.typ || col.SourceType != w.source || col.OrdinalPosition != i+1 {
+			t.Errorf("column %d: got %s %s %s #%d, want %s %s %s", i, col.Name, col.Type, col.SourceType, col.OrdinalPosition, w.name, w.typ, w.source)
+		}
+		parent, _ := col.Raw["parent"].(string)
+		if parent != w.parent {
+			t.Errorf("column %s: expected parent %q, got %q", col.Name, w.parent, parent)
+		}
+	}
+	if metadata.Columns[7].Comment != "Stock unit" {
+		t.Errorf("expected field comment, got %q", metadata.Columns[7].Comment)
+	}
+	if !intPtrEqual(metadata.Columns[8].Precision, intPtr(10)) {
+		t.Errorf("expected nested decimal precision, got %s", intPtrValue(metadata.Columns[8].Precision))
+	}
+}
+
+// TestParseHiveType tests parsing of complex type strings
+func TestParseHiveType(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"int", false},
+		{"timestamp with local time zone", false},
+		{"struct<`a b`:int,`c.d`:string>", false},
+		{"STRUCT<a: INT, b: ARRAY<STRING>>", false},
+		{"uniontype<int,string>", false},
+		{"struct<>", false},
+		{"struct<a:int", true},
+		{"map<string>", true},
+		{"struct<a>", true},
+		{"array<int>>", true},
+		{"struct<a:int comment 'x>", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := parseHiveType(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseHiveType(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+
+	typ, err := parseHiveType("struct<`a b`:int,`c.d`:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ.fields[0].name != "a b" || typ.fields[1].name != "c.d" {
+		t.Errorf("unexpected fields: %+v", typ.fields)
+	}
+}
+
 // Helper functions
 
 func intPtr(i int) *int {
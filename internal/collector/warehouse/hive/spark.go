@@ -0,0 +1,134 @@
+package hive
+
+import (
+	"strconv"
+	"strings"
+
+	"go-metadata/internal/collector"
+)
+
+// Modes of the HiveServer2 compatible endpoint, set with the "mode" extra
+// property.
+const (
+	// ModeHive targets HiveServer2.
+	ModeHive = "hive"
+	// ModeSpark targets the Spark Thrift Server, whose SHOW TABLES and
+	// DESCRIBE output differ from Hive.
+	ModeSpark = "spark"
+	// ModeKyuubi targets Apache Kyuubi with the Spark SQL engine.
+	ModeKyuubi = "kyuubi"
+
+	// DefaultKyuubiPort is the default Kyuubi frontend port
+	DefaultKyuubiPort = 10009
+)
+
+// Table formats detected from the provider and table properties
+const (
+	TableFormatDelta   = "delta"
+	TableFormatIceberg = "iceberg"
+	TableFormatHudi    = "hudi"
+)
+
+// sparkTable is a row of SHOW TABLE EXTENDED.
+type sparkTable struct {
+	Database  string
+	Name      string
+	Temporary bool
+	// Information are the "Key: Value" lines of the information column,
+	// e.g. Type, Provider, Location.
+	Information map[string]string
+}
+
+// parseShowTableExtended parses the rows of Spark's
+// SHOW TABLE EXTENDED IN db LIKE '*': namespace (database), tableName,
+// isTemporary and information.
+func parseShowTableExtended(rows [][]string) []sparkTable {
+	tables := make([]sparkTable, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 2 || strings.TrimSpace(row[1]) == "" {
+			continue
+		}
+		t := sparkTable{
+			Database:    strings.TrimSpace(row[0]),
+			Name:        strings.TrimSpace(row[1]),
+			Information: make(map[string]string),
+		}
+		if len(row) > 2 {
+			t.Temporary = strings.EqualFold(strings.TrimSpace(row[2]), "true")
+		}
+		if len(row) > 3 {
+			for _, line := range strings.Split(row[3], "\n") {
+				// The schema tree closes the information
+				if strings.HasPrefix(line, "Schema:") {
+					break
+				}
+				if key, value, ok := strings.Cut(line, ":"); ok {
+					t.Information[strings.TrimSpace(key)] = strings.TrimSpace(value)
+				}
+			}
+		}
+		tables = append(tables, t)
+	}
+	return tables
+}
+
+// detectTableFormat sets the open table format of Delta, Iceberg and Hudi
+// tables as the storage format and the table_format property. Spark reports
+// it as the provider; tables registered in the Hive Metastore carry it in
+// their parameters, input format or SerDe.
+func detectTableFormat(metadata *collector.TableMetadata) {
+	candidates := []string{
+		metadata.Properties["Provider"],
+		metadata.Properties["spark.sql.sources.provider"],
+		metadata.Properties["table_type"],
+	}
+	if metadata.Storage != nil {
+		candidates = append(candidates, metadata.Storage.InputFormat, metadata.Storage.SerDe)
+	}
+
+	format := ""
+	for _, s := range candidates {
+		s = strings.ToLower(s)
+		switch {
+		case strings.Contains(s, TableFormatDelta):
+			format = TableFormatDelta
+		case strings.Contains(s, TableFormatIceberg):
+			format = TableFormatIceberg
+		case strings.Contains(s, TableFormatHudi):
+			format = TableFormatHudi
+		default:
+			continue
+		}
+		break
+	}
+	if format == "" {
+		return
+	}
+
+	metadata.Properties["table_format"] = format
+	if metadata.Storage == nil {
+		metadata.Storage = &collector.StorageInfo{}
+	}
+	metadata.Storage.Format = format
+}
+
+// parseSparkStatistics parses Spark's "Statistics" detail, e.g.
+// "10485760 bytes, 1000 rows".
+func parseSparkStatistics(value string, stats *collector.TableStatistics) {
+	for _, part := range strings.Split(value, ",") {
+		fields := strings.Fields(part)
+		if len(fields) != 2 {
+			continue
+		}
+		n, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[1] {
+		case "bytes":
+			stats.DataSizeBytes = n
+		case "rows":
+			stats.RowCount = n
+		}
+	}
+}
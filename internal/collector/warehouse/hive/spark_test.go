@@ -0,0 +1,109 @@
+package hive
+
+import (
+	"testing"
+
+	"go-metadata/internal/collector"
+)
+
+// TestParseShowTableExtended tests parsing SHOW TABLE EXTENDED rows
+func TestParseShowTableExtended(t *testing.T) {
+	rows := [][]string{
+		{"sales", "orders", "false", "Database: sales\nTable: orders\nType: MANAGED\nProvider: delta\nLocation: s3://lake/sales/orders\nSchema: root\n |-- id: long (nullable = true)\n"},
+		{"sales", "events", "false", "Database: sales\nTable: events\nType: EXTERNAL\nProvider: iceberg\n"},
+		{"", "tmp_view", "true", "Table: tmp_view\nType: VIEW\n"},
+		{"sales", "", "false", ""},
+	}
+
+	tables := parseShowTableExtended(rows)
+	if len(tables) != 3 {
+		t.Fatalf("expected 3 tables, got %d: %+v", len(tables), tables)
+	}
+
+	orders := tables[0]
+	if orders.Database != "sales" || orders.Name != "orders" || orders.Temporary {
+		t.Errorf("unexpected table: %+v", orders)
+	}
+	if orders.Information["Provider"] != "delta" || orders.Information["Location"] != "s3://lake/sales/orders" {
+		t.Errorf("unexpected information: %v", orders.Information)
+	}
+	if _, ok := orders.Information["|-- id"]; ok {
+		t.Error("expected the schema tree to be skipped")
+	}
+	if !tables[2].Temporary {
+		t.Errorf("expected %s to be temporary", tables[2].Name)
+	}
+}
+
+// TestDetectTableFormat tests open table format detection
+func TestDetectTableFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties map[string]string
+		storage    *collector.StorageInfo
+		want       string
+	}{
+		{
+			name:       "spark delta provider",
+			properties: map[string]string{"Provider": "delta"},
+			want:       TableFormatDelta,
+		},
+		{
+			name:       "spark iceberg provider",
+			properties: map[string]string{"Provider": "iceberg"},
+			want:       TableFormatIceberg,
+		},
+		{
+			name:       "hive iceberg table",
+			properties: map[string]string{"table_type": "ICEBERG"},
+			storage:    &collector.StorageInfo{Format: "UNKNOWN", InputFormat: "org.apache.iceberg.mr.hive.HiveIcebergInputFormat"},
+			want:       TableFormatIceberg,
+		},
+		{
+			name:       "delta registered in the metastore",
+			properties: map[string]string{"spark.sql.sources.provider": "DELTA"},
+			storage:    &collector.StorageInfo{Format: "SEQUENCEFILE"},
+			want:       TableFormatDelta,
+		},
+		{
+			name:       "hudi input format",
+			properties: map[string]string{},
+			storage:    &collector.StorageInfo{InputFormat: "org.apache.hudi.hadoop.HoodieParquetInputFormat"},
+			want:       TableFormatHudi,
+		},
+		{
+			name:       "plain parquet",
+			properties: map[string]string{"Provider": "parquet"},
+			storage:    &collector.StorageInfo{Format: "parquet"},
+			want:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := &collector.TableMetadata{Properties: tt.properties, Storage: tt.storage}
+			detectTableFormat(metadata)
+			if got := metadata.Properties["table_format"]; got != tt.want {
+				t.Errorf("table_format = %q, want %q", got, tt.want)
+			}
+			if tt.want != "" && metadata.Storage.Format != tt.want {
+				t.Errorf("storage format = %q, want %q", metadata.Storage.Format, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseSparkStatistics tests parsing Spark's Statistics detail
+func TestParseSparkStatistics(t *testing.T) {
+	stats := &collector.TableStatistics{}
+	parseSparkStatistics("10485760 bytes, 1000 rows", stats)
+	if stats.DataSizeBytes != 10485760 || stats.RowCount != 1000 {
+		t.Errorf("unexpected statistics: %+v", stats)
+	}
+
+	stats = &collector.TableStatistics{}
+	parseSparkStatistics("2048 bytes", stats)
+	if stats.DataSizeBytes != 2048 || stats.RowCount != 0 {
+		t.Errorf("unexpected statistics: %+v", stats)
+	}
+}
@@ -0,0 +1,256 @@
+package hive
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go-metadata/internal/collector"
+)
+
+// maxNestedDepth limits the expansion of nested complex types.
+const maxNestedDepth = 16
+
+// hiveType is a parsed Hive/Spark type string such as
+// struct<a:int,b:array<map<string,decimal(10,2)>>>.
+type hiveType struct {
+	name   string // lower-case base name, e.g. struct, array, decimal
+	text   string // source text of the type
+	fields []hiveField
+	elem   *hiveType // array element
+	key    *hiveType // map key
+	value  *hiveType // map value
+}
+
+// hiveField is a struct field.
+type hiveField struct {
+	name    string
+	typ     *hiveType
+	comment string
+}
+
+// commentKeyword matches the COMMENT keyword trailing a struct field type.
+var commentKeyword = regexp.MustCompile(`(?i)\s+comment\s*$`)
+
+// parseHiveType parses a Hive/Spark type string.
+func parseHiveType(s string) (*hiveType, error) {
+	p := &typeParser{s: s}
+	t, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.s) {
+		return nil, fmt.Errorf("unexpected %q at offset %d in type %q", p.s[p.pos:], p.pos, s)
+	}
+	return t, nil
+}
+
+type typeParser struct {
+	s   string
+	pos int
+}
+
+func (p *typeParser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+func (p *typeParser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+func (p *typeParser) expect(c byte) error {
+	p.skipSpace()
+	if p.peek() != c {
+		return fmt.Errorf("expected %q at offset %d in type %q", c, p.pos, p.s)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *typeParser) parseType() (*hiveType, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune("<>(),:'`", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	word := p.s[start:p.pos]
+	// Leave a trailing COMMENT keyword to the struct field
+	if loc := commentKeyword.FindStringIndex(word); loc != nil {
+		p.pos = start + loc[0]
+		word = word[:loc[0]]
+	}
+	t := &hiveType{name: strings.ToLower(strings.Join(strings.Fields(word), " "))}
+	if t.name == "" {
+		return nil, fmt.Errorf("missing type at offset %d in type %q", start, p.s)
+	}
+
+	switch p.peek() {
+	case '(':
+		end := strings.IndexByte(p.s[p.pos:], ')')
+		if end == -1 {
+			return nil, fmt.Errorf("unterminated parameters in type %q", p.s)
+		}
+		p.pos += end + 1
+	case '<':
+		p.pos++
+		if err := p.parseTypeArgs(t); err != nil {
+			return nil, err
+		}
+		if err := p.expect('>'); err != nil {
+			return nil, err
+		}
+	}
+	t.text = strings.TrimSpace(p.s[start:p.pos])
+	return t, nil
+}
+
+// parseTypeArgs parses the arguments between < and > of a complex type.
+func (p *typeParser) parseTypeArgs(t *hiveType) error {
+	switch t.name {
+	case "struct":
+		for {
+			p.skipSpace()
+			if p.peek() == '>' {
+				return nil
+			}
+			f, err := p.parseField()
+			if err != nil {
+				return err
+			}
+			t.fields = append(t.fields, f)
+			p.skipSpace()
+			if p.peek() != ',' {
+				return nil
+			}
+			p.pos++
+		}
+	case "array":
+		elem, err := p.parseType()
+		t.elem = elem
+		return err
+	case "map":
+		key, err := p.parseType()
+		if err != nil {
+			return err
+		}
+		if err := p.expect(','); err != nil {
+			return err
+		}
+		value, err := p.parseType()
+		t.key, t.value = key, value
+		return err
+	default:
+		// uniontype<...> and vendor types are kept as a whole
+		for {
+			if _, err := p.parseType(); err != nil {
+				return err
+			}
+			p.skipSpace()
+			if p.peek() != ',' {
+				return nil
+			}
+			p.pos++
+		}
+	}
+}
+
+// parseField parses a struct field: name:type [COMMENT 'text'].
+func (p *typeParser) parseField() (hiveField, error) {
+	var f hiveField
+	p.skipSpace()
+	if p.peek() == '`' {
+		end := strings.IndexByte(p.s[p.pos+1:], '`')
+		if end == -1 {
+			return f, fmt.Errorf("unterminated field name in type %q", p.s)
+		}
+		f.name = p.s[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+	} else {
+		start := p.pos
+		for p.pos < len(p.s) && !strings.ContainsRune(":<>,", rune(p.s[p.pos])) {
+			p.pos++
+		}
+		f.name = strings.TrimSpace(p.s[start:p.pos])
+	}
+	if f.name == "" {
+		return f, fmt.Errorf("missing field name at offset %d in type %q", p.pos, p.s)
+	}
+	if err := p.expect(':'); err != nil {
+		return f, err
+	}
+	typ, err := p.parseType()
+	if err != nil {
+		return f, err
+	}
+	f.typ = typ
+
+	p.skipSpace()
+	if len(p.s)-p.pos >= len("comment") && strings.EqualFold(p.s[p.pos:p.pos+len("comment")], "comment") {
+		p.pos += len("comment")
+		p.skipSpace()
+		if f.comment, err = p.parseQuoted(); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+// parseQuoted parses a single quoted string, unescaping \' and \\.
+func (p *typeParser) parseQuoted() (string, error) {
+	if p.peek() != '\'' {
+		return "", fmt.Errorf("expected quoted comment at offset %d in type %q", p.pos, p.s)
+	}
+	var sb strings.Builder
+	for p.pos++; p.pos < len(p.s); p.pos++ {
+		switch c := p.s[p.pos]; c {
+		case '\\':
+			if p.pos+1 < len(p.s) {
+				p.pos++
+				sb.WriteByte(p.s[p.pos])
+			}
+		case '\'':
+			p.pos++
+			return sb.String(), nil
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated comment in type %q", p.s)
+}
+
+// nestedColumns expands a complex type into child columns named by their
+// dot separated path, like the fields of document stores. Arrays are
+// transparent, i.e. the fields of array<struct<a:int>> column c are c.a, and
+// maps have the children key and value.
+func nestedColumns(path string, t *hiveType, depth int) []collector.Column {
+	if t == nil || depth >= maxNestedDepth {
+		return nil
+	}
+
+	var columns []collector.Column
+	child := func(name string, typ *hiveType, comment string) {
+		col := newColumn(path+"."+name, typ.text, comment)
+		col.Raw = map[string]any{"parent": path}
+		columns = append(columns, col)
+		columns = append(columns, nestedColumns(col.Name, typ, depth+1)...)
+	}
+
+	switch t.name {
+	case "struct":
+		for _, f := range t.fields {
+			child(f.name, f.typ, f.comment)
+		}
+	case "array":
+		columns = nestedColumns(path, t.elem, depth+1)
+	case "map":
+		child("key", t.key, "")
+		child("value", t.value, "")
+	}
+	return columns
+}
@@ -0,0 +1,152 @@
+package impala
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/config"
+)
+
+// TestNewCollector tests the NewCollector function
+func TestNewCollector(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *config.ConnectorConfig
+		wantErr bool
+	}{
+		{name: "nil config", cfg: nil, wantErr: true},
+		{name: "valid config", cfg: &config.ConnectorConfig{Type: "impala", Endpoint: "localhost:21050"}},
+		{name: "empty type (allowed)", cfg: &config.ConnectorConfig{Endpoint: "localhost:21050"}},
+		{name: "wrong type", cfg: &config.ConnectorConfig{Type: "hive", Endpoint: "localhost:10000"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCollector(tt.cfg)
+			if tt.wantErr {
+				if collector.GetErrorCode(err) != collector.ErrCodeInvalidConfig {
+					t.Errorf("expected invalid config error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.Type() != SourceName || c.Category() != collector.CategoryDataWarehouse {
+				t.Errorf("unexpected collector %s/%s", c.Category(), c.Type())
+			}
+		})
+	}
+}
+
+// TestBuildDSN tests the DSN building logic
+func TestBuildDSN(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       *config.ConnectorConfig
+		wantErr   bool
+		wantHost  string
+		wantUser  string
+		wantPath  string
+		wantQuery map[string]string
+	}{
+		{
+			name:      "default port and database",
+			cfg:       &config.ConnectorConfig{Endpoint: "impalad"},
+			wantHost:  "impalad:21050",
+			wantPath:  "/default",
+			wantQuery: map[string]string{"auth": "noauth"},
+		},
+		{
+			name: "ldap when a password is set",
+			cfg: &config.ConnectorConfig{
+				Endpoint:    "impalad:21051",
+				Credentials: config.Credentials{User: "etl", Password: "p@ss"},
+				Properties: config.ConnectionProps{
+					Extra: map[string]string{"database": "sales", "driver": "impala", "tls": "true"},
+				},
+			},
+			wantHost:  "impalad:21051",
+			wantUser:  "etl",
+			wantPath:  "/sales",
+			wantQuery: map[string]string{"auth": "ldap", "tls": "true", "driver": ""},
+		},
+		{
+			name:      "thrift prefix",
+			cfg:       &config.ConnectorConfig{Endpoint: "thrift://impalad:21050"},
+			wantHost:  "impalad:21050",
+			wantPath:  "/default",
+			wantQuery: map[string]string{"auth": "noauth"},
+		},
+		{name: "empty endpoint", cfg: &config.ConnectorConfig{}, wantErr: true},
+		{name: "invalid port", cfg: &config.ConnectorConfig{Endpoint: "impalad:abc"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Collector{config: tt.cfg}
+			dsn, err := c.buildDSN()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got DSN %q", dsn)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			u, err := url.Parse(dsn)
+			if err != nil {
+				t.Fatalf("invalid DSN %q: %v", dsn, err)
+			}
+			if u.Scheme != "impala" || u.Host != tt.wantHost || u.Path != tt.wantPath || u.User.Username() != tt.wantUser {
+				t.Errorf("unexpected DSN %q", dsn)
+			}
+			for k, v := range tt.wantQuery {
+				if got := u.Query().Get(k); got != v {
+					t.Errorf("DSN %q: %s = %q, want %q", dsn, k, got, v)
+				}
+			}
+		})
+	}
+}
+
+// TestCollectorNotConnected tests that operations fail before Connect
+func TestCollectorNotConnected(t *testing.T) {
+	c := &Collector{config: &config.ConnectorConfig{Endpoint: "localhost:21050"}}
+	ctx := context.Background()
+
+	if _, err := c.ListSchemas(ctx, ""); collector.GetErrorCode(err) != collector.ErrCodeConnectionClosed {
+		t.Errorf("ListSchemas: expected connection closed error, got %v", err)
+	}
+	if _, err := c.FetchTableStatistics(ctx, "", "db", "t"); collector.GetErrorCode(err) != collector.ErrCodeConnectionClosed {
+		t.Errorf("FetchTableStatistics: expected connection closed error, got %v", err)
+	}
+	if _, err := c.FetchPartitions(ctx, "", "db", "t"); collector.GetErrorCode(err) != collector.ErrCodeConnectionClosed {
+		t.Errorf("FetchPartitions: expected connection closed error, got %v", err)
+	}
+	status, err := c.HealthCheck(ctx)
+	if err != nil || status.Connected {
+		t.Errorf("HealthCheck: unexpected status %+v, err %v", status, err)
+	}
+}
+
+// TestFilterColumnStats tests the statistics.column_stats.columns filter
+func TestFilterColumnStats(t *testing.T) {
+	stats := []collector.ColumnStats{{Name: "id"}, {Name: "Name"}, {Name: "dt"}}
+
+	c := &Collector{config: &config.ConnectorConfig{}}
+	if got := c.filterColumnStats(stats); len(got) != 3 {
+		t.Errorf("expected all columns without a filter, got %+v", got)
+	}
+
+	c.config.Statistics = &config.StatisticsConfig{
+		ColumnStats: &config.ColumnStatsOpts{Columns: []string{"name", "dt"}},
+	}
+	got := c.filterColumnStats(stats)
+	if len(got) != 2 || got[0].Name != "Name" || got[1].Name != "dt" {
+		t.Errorf("unexpected filtered columns: %+v", got)
+	}
+}
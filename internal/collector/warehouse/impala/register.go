@@ -0,0 +1,12 @@
+// Package impala provides an Impala metadata collector implementation.
+package impala
+
+import (
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/factory"
+)
+
+func init() {
+	// Register Impala collector with the default factory
+	_ = factory.Register(collector.CategoryDataWarehouse, SourceName, NewCollector)
+}
@@ -0,0 +1,181 @@
+package impala
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-metadata/internal/collector"
+)
+
+// tableStats is the parsed output of SHOW TABLE STATS.
+type tableStats struct {
+	// PartitionColumns are the partition key columns preceding #Rows
+	PartitionColumns []string
+	// Partitions is the number of partitions, 0 for unpartitioned tables
+	Partitions int
+	// RowCount is -1 when COMPUTE STATS has not been run
+	RowCount  int64
+	Files     int64
+	SizeBytes int64
+	Format    string
+}
+
+// parseTableStats parses SHOW TABLE STATS. HDFS tables return
+//
+//	[partition keys...] #Rows #Files Size Bytes Cached Cache Replication Format Incremental stats Location
+//
+// with one row per partition and a trailing "Total" row for partitioned
+// tables. Kudu tables return # Rows, Start Key, Stop Key, ... per tablet.
+func parseTableStats(header []string, rows [][]string) (*tableStats, error) {
+	rowsIdx := -1
+	for i, h := range header {
+		if normalizeHeader(h) == "#rows" {
+			rowsIdx = i
+			break
+		}
+	}
+	if rowsIdx == -1 {
+		return nil, fmt.Errorf("no #Rows column in SHOW TABLE STATS output: %v", header)
+	}
+	filesIdx, sizeIdx, formatIdx := headerIndex(header, "#files"), headerIndex(header, "size"), headerIndex(header, "format")
+
+	stats := &tableStats{
+		PartitionColumns: append([]string(nil), header[:rowsIdx]...),
+		RowCount:         -1,
+	}
+
+	var total []string
+	var known bool
+	var sumRows, sumFiles, sumSize int64
+	for _, row := range rows {
+		if len(row) <= rowsIdx {
+			continue
+		}
+		if rowsIdx > 0 && strings.EqualFold(row[0], "Total") {
+			total = row
+			continue
+		}
+		stats.Partitions++
+		if n, err := strconv.ParseInt(row[rowsIdx], 10, 64); err == nil && n >= 0 {
+			sumRows += n
+			known = true
+		}
+		sumFiles += cellInt(row, filesIdx)
+		sumSize += cellSize(row, sizeIdx)
+		if stats.Format == "" && formatIdx >= 0 && formatIdx < len(row) {
+			stats.Format = row[formatIdx]
+		}
+	}
+
+	if total != nil {
+		// The Total row carries the table level row count, -1 if unknown
+		if n, err := strconv.ParseInt(total[rowsIdx], 10, 64); err == nil {
+			stats.RowCount = n
+		}
+		stats.Files = cellInt(total, filesIdx)
+		stats.SizeBytes = cellSize(total, sizeIdx)
+	} else {
+		if known {
+			stats.RowCount = sumRows
+		}
+		stats.Files, stats.SizeBytes = sumFiles, sumSize
+	}
+	if rowsIdx == 0 {
+		// Unpartitioned, or Kudu tablets
+		stats.Partitions = 0
+	}
+	return stats, nil
+}
+
+// parseColumnStats parses SHOW COLUMN STATS:
+//
+//	Column Type #Distinct Values #Nulls Max Size Avg Size [#Trues #Falses]
+//
+// Unknown counts are -1 and are left nil.
+func parseColumnStats(header []string, rows [][]string) ([]collector.ColumnStats, error) {
+	nameIdx := headerIndex(header, "column")
+	if nameIdx == -1 {
+		return nil, fmt.Errorf("no Column column in SHOW COLUMN STATS output: %v", header)
+	}
+	distinctIdx, nullsIdx := headerIndex(header, "#distinctvalues"), headerIndex(header, "#nulls")
+
+	stats := make([]collector.ColumnStats, 0, len(rows))
+	for _, row := range rows {
+		if nameIdx >= len(row) || row[nameIdx] == "" {
+			continue
+		}
+		stats = append(stats, collector.ColumnStats{
+			Name:          row[nameIdx],
+			DistinctCount: cellCount(row, distinctIdx),
+			NullCount:     cellCount(row, nullsIdx),
+		})
+	}
+	return stats, nil
+}
+
+// parseSize parses Impala's pretty printed sizes such as 0B, 12B, 1.50KB,
+// 24.31MB and 2.00GB.
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	units := []struct {
+		suffix string
+		factor float64
+	}{
+		{"PB", 1 << 50}, {"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1},
+	}
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			f, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid size %q", s)
+			}
+			return int64(f * u.factor), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid size %q", s)
+}
+
+// normalizeHeader lower-cases a header and removes spaces, so that "# Rows"
+// and "#Rows" match.
+func normalizeHeader(h string) string {
+	return strings.ToLower(strings.Join(strings.Fields(h), ""))
+}
+
+// headerIndex returns the index of the normalized header name, or -1.
+func headerIndex(header []string, name string) int {
+	for i, h := range header {
+		if normalizeHeader(h) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func cellInt(row []string, idx int) int64 {
+	if idx < 0 || idx >= len(row) {
+		return 0
+	}
+	n, _ := strconv.ParseInt(row[idx], 10, 64)
+	return max(n, 0)
+}
+
+func cellSize(row []string, idx int) int64 {
+	if idx < 0 || idx >= len(row) {
+		return 0
+	}
+	n, _ := parseSize(row[idx])
+	return n
+}
+
+// cellCount returns a count, nil when it is unknown (-1).
+func cellCount(row []string, idx int) *int64 {
+	if idx < 0 || idx >= len(row) {
+		return nil
+	}
+	n, err := strconv.ParseInt(row[idx], 10, 64)
+	if err != nil || n < 0 {
+		return nil
+	}
+	return &n
+}
@@ -0,0 +1,137 @@
+package impala
+
+import (
+	"testing"
+)
+
+// TestParseTableStatsUnpartitioned tests SHOW TABLE STATS of an unpartitioned table
+func TestParseTableStatsUnpartitioned(t *testing.T) {
+	header := []string{"#Rows", "#Files", "Size", "Bytes Cached", "Cache Replication", "Format", "Incremental stats", "Location"}
+	rows := [][]string{
+		{"1000", "2", "1.50MB", "NOT CACHED", "NOT CACHED", "PARQUET", "false", "hdfs://nn/warehouse/t"},
+	}
+
+	stats, err := parseTableStats(header, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.RowCount != 1000 || stats.Files != 2 || stats.SizeBytes != 1572864 || stats.Format != "PARQUET" {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats.Partitions != 0 || len(stats.PartitionColumns) != 0 {
+		t.Errorf("expected no partitions, got %+v", stats)
+	}
+}
+
+// TestParseTableStatsPartitioned tests SHOW TABLE STATS of a partitioned table
+func TestParseTableStatsPartitioned(t *testing.T) {
+	header := []string{"year", "month", "#Rows", "#Files", "Size", "Bytes Cached", "Cache Replication", "Format", "Incremental stats", "Location"}
+	rows := [][]string{
+		{"2024", "1", "600", "1", "10.00KB", "NOT CACHED", "NOT CACHED", "TEXT", "false", "hdfs://nn/t/year=2024/month=1"},
+		{"2024", "2", "-1", "3", "2.00KB", "NOT CACHED", "NOT CACHED", "TEXT", "false", "hdfs://nn/t/year=2024/month=2"},
+		{"Total", "", "600", "4", "12.00KB", "0B", "", "", "", ""},
+	}
+
+	stats, err := parseTableStats(header, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats.PartitionColumns) != 2 || stats.PartitionColumns[0] != "year" || stats.PartitionColumns[1] != "month" {
+		t.Errorf("unexpected partition columns: %v", stats.PartitionColumns)
+	}
+	if stats.Partitions != 2 || stats.RowCount != 600 || stats.Files != 4 || stats.SizeBytes != 12288 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+// TestParseTableStatsUnknown tests a table without computed statistics
+func TestParseTableStatsUnknown(t *testing.T) {
+	header := []string{"#Rows", "#Files", "Size", "Format"}
+	stats, err := parseTableStats(header, [][]string{{"-1", "1", "512B", "TEXT"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.RowCount != -1 || stats.SizeBytes != 512 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+// TestParseTableStatsKudu tests SHOW TABLE STATS of a Kudu table, one row per tablet
+func TestParseTableStatsKudu(t *testing.T) {
+	header := []string{"# Rows", "Start Key", "Stop Key", "Leader Replica", "# Replicas"}
+	rows := [][]string{
+		{"-1", "", "00000001", "kudu-1:7050", "3"},
+		{"-1", "00000001", "", "kudu-2:7050", "3"},
+	}
+	stats, err := parseTableStats(header, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.RowCount != -1 || stats.Partitions != 0 || len(stats.PartitionColumns) != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+// TestParseTableStatsInvalid tests output without a #Rows column
+func TestParseTableStatsInvalid(t *testing.T) {
+	if _, err := parseTableStats([]string{"name", "comment"}, nil); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+// TestParseColumnStats tests SHOW COLUMN STATS
+func TestParseColumnStats(t *testing.T) {
+	header := []string{"Column", "Type", "#Distinct Values", "#Nulls", "Max Size", "Avg Size", "#Trues", "#Falses"}
+	rows := [][]string{
+		{"id", "BIGINT", "1000", "0", "8", "8", "-1", "-1"},
+		{"name", "STRING", "-1", "-1", "-1", "-1", "-1", "-1"},
+	}
+
+	stats, err := parseColumnStats(header, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 columns, got %d", len(stats))
+	}
+	if stats[0].Name != "id" || stats[0].DistinctCount == nil || *stats[0].DistinctCount != 1000 || stats[0].NullCount == nil || *stats[0].NullCount != 0 {
+		t.Errorf("unexpected stats: %+v", stats[0])
+	}
+	if stats[1].DistinctCount != nil || stats[1].NullCount != nil {
+		t.Errorf("expected unknown counts to be nil: %+v", stats[1])
+	}
+
+	if _, err := parseColumnStats([]string{"name"}, nil); err == nil {
+		t.Error("expected error without a Column column, got nil")
+	}
+}
+
+// TestParseSize tests parsing pretty printed sizes
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"0B", 0, false},
+		{"12B", 12, false},
+		{"1.50KB", 1536, false},
+		{"24.00MB", 24 << 20, false},
+		{"2.00GB", 2 << 30, false},
+		{"1TB", 1 << 40, false},
+		{"NOT CACHED", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSize(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSize(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSize(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
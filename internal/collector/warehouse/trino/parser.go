@@ -0,0 +1,268 @@
+package trino
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go-metadata/internal/collector"
+)
+
+// partitionKey is the SHOW COLUMNS Extra value of Hive partition columns
+const partitionKey = "partition key"
+
+// typeParams matches the parameters of a type, e.g. varchar(100) or decimal(10,2)
+var typeParams = regexp.MustCompile(`^\w+\((\d+)(?:,\s*(\d+))?\)`)
+
+// parseCatalogs parses the rows of system.metadata.catalogs. The connector
+// name column is connector_name since Trino 420 and connector_id before.
+func parseCatalogs(header []string, rows [][]string) []collector.CatalogInfo {
+	nameIdx, connectorIdx := -1, -1
+	for i, h := range header {
+		switch strings.ToLower(h) {
+		case "catalog_name":
+			nameIdx = i
+		case "connector_name":
+			connectorIdx = i
+		case "connector_id":
+			if connectorIdx == -1 {
+				connectorIdx = i
+			}
+		}
+	}
+	if nameIdx == -1 {
+		nameIdx = 0
+	}
+
+	var catalogs []collector.CatalogInfo
+	for _, row := range rows {
+		if nameIdx >= len(row) || row[nameIdx] == "" {
+			continue
+		}
+		info := collector.CatalogInfo{
+			Catalog:     row[nameIdx],
+			Type:        SourceName,
+			Description: "Trino catalog",
+			Properties:  map[string]string{},
+		}
+		// connector_id is the catalog name itself on recent versions
+		if connectorIdx >= 0 && connectorIdx < len(row) && row[connectorIdx] != "" && row[connectorIdx] != row[nameIdx] {
+			info.Properties["connector"] = row[connectorIdx]
+			info.Description = fmt.Sprintf("Trino catalog (%s)", row[connectorIdx])
+		}
+		catalogs = append(catalogs, info)
+	}
+	return catalogs
+}
+
+// parseColumns parses the output of SHOW COLUMNS: Column, Type, Extra and
+// Comment. Trino does not report nullability, so columns are nullable.
+func parseColumns(header []string, rows [][]string) ([]collector.Column, error) {
+	idx := map[string]int{}
+	for i, h := range header {
+		idx[strings.ToLower(h)] = i
+	}
+	nameIdx, ok := idx["column"]
+	if !ok {
+		return nil, fmt.Errorf("unexpected SHOW COLUMNS header %v", header)
+	}
+	typeIdx, ok := idx["type"]
+	if !ok {
+		return nil, fmt.Errorf("unexpected SHOW COLUMNS header %v", header)
+	}
+	cell := func(row []string, name string) string {
+		if i, ok := idx[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	columns := make([]collector.Column, 0, len(rows))
+	for _, row := range rows {
+		if nameIdx >= len(row) || typeIdx >= len(row) || row[nameIdx] == "" {
+			continue
+		}
+		dataType := row[typeIdx]
+		col := collector.Column{
+			OrdinalPosition:   len(columns) + 1,
+			Name:              row[nameIdx],
+			Type:              normalizeTrinoType(dataType),
+			SourceType:        dataType,
+			Nullable:          true,
+			Comment:           cell(row, "comment"),
+			IsPartitionColumn: strings.Contains(strings.ToLower(cell(row, "extra")), partitionKey),
+		}
+		parseTypeParams(dataType, &col)
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// partitionColumnsOf returns the names of the partition columns.
+func partitionColumnsOf(columns []collector.Column) []string {
+	var names []string
+	for _, col := range columns {
+		if col.IsPartitionColumn {
+			names = append(names, col.Name)
+		}
+	}
+	return names
+}
+
+// parseStats parses the output of SHOW STATS: one row per column with its
+// data size, distinct values count, nulls fraction and low/high values, and a
+// summary row without a column name holding the row count. Unknown values
+// are NULL.
+func parseStats(header []string, rows [][]string) (*collector.TableStatistics, error) {
+	idx := map[string]int{}
+	for i, h := range header {
+		idx[strings.ToLower(h)] = i
+	}
+	nameIdx, ok := idx["column_name"]
+	if !ok {
+		return nil, fmt.Errorf("unexpected SHOW STATS header %v", header)
+	}
+	number := func(row []string, name string) (float64, bool) {
+		i, ok := idx[name]
+		if !ok || i >= len(row) || row[i] == "" {
+			return 0, false
+		}
+		v, err := strconv.ParseFloat(row[i], 64)
+		return v, err == nil && !math.IsNaN(v)
+	}
+	cell := func(row []string, name string) string {
+		if i, ok := idx[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	stats := &collector.TableStatistics{}
+	var rowCount float64
+	var known bool
+	for _, row := range rows {
+		if nameIdx < len(row) && row[nameIdx] == "" {
+			rowCount, known = number(row, "row_count")
+			if known {
+				stats.RowCount = int64(rowCount)
+			}
+		}
+	}
+
+	for _, row := range rows {
+		if nameIdx >= len(row) || row[nameIdx] == "" {
+			continue
+		}
+		colStats := collector.ColumnStats{Name: row[nameIdx]}
+		if distinct, ok := number(row, "distinct_values_count"); ok {
+			count := int64(math.Round(distinct))
+			colStats.DistinctCount = &count
+		}
+		if fraction, ok := number(row, "nulls_fraction"); ok && known {
+			count := int64(math.Round(fraction * rowCount))
+			colStats.NullCount = &count
+		}
+		if size, ok := number(row, "data_size"); ok {
+			stats.DataSizeBytes += int64(size)
+		}
+		if low := cell(row, "low_value"); low != "" {
+			colStats.Min = low
+		}
+		if high := cell(row, "high_value"); high != "" {
+			colStats.Max = high
+		}
+		stats.ColumnStats = append(stats.ColumnStats, colStats)
+	}
+	return stats, nil
+}
+
+// normalizeTrinoType normalizes a Trino data type to a standard type
+func normalizeTrinoType(dataType string) string {
+	// Remove any parameters from type (e.g., varchar(100) -> varchar,
+	// timestamp(3) with time zone -> timestamp)
+	baseType := strings.ToLower(strings.TrimSpace(dataType))
+	if idx := strings.IndexAny(baseType, "( "); idx != -1 {
+		baseType = baseType[:idx]
+	}
+
+	switch baseType {
+	case "tinyint", "smallint", "integer", "int", "bigint":
+		return "INTEGER"
+	case "real", "double":
+		return "FLOAT"
+	case "decimal":
+		return "DECIMAL"
+	case "varchar", "char":
+		return "STRING"
+	case "date":
+		return "DATE"
+	case "timestamp":
+		return "TIMESTAMP"
+	case "time":
+		return "TIME"
+	case "varbinary":
+		return "BINARY"
+	case "boolean":
+		return "BOOLEAN"
+	case "array":
+		return "ARRAY"
+	case "map":
+		return "MAP"
+	case "row":
+		return "STRUCT"
+	default:
+		return strings.ToUpper(baseType)
+	}
+}
+
+// parseTypeParams extracts length, precision, and scale from type string
+func parseTypeParams(dataType string, col *collector.Column) {
+	matches := typeParams.FindStringSubmatch(strings.ToLower(dataType))
+	if matches == nil {
+		return
+	}
+	switch normalizeTrinoType(dataType) {
+	case "STRING":
+		if length, err := strconv.Atoi(matches[1]); err == nil {
+			col.Length = &length
+		}
+	case "DECIMAL":
+		if precision, err := strconv.Atoi(matches[1]); err == nil {
+			col.Precision = &precision
+		}
+		if matches[2] != "" {
+			if scale, err := strconv.Atoi(matches[2]); err == nil {
+				col.Scale = &scale
+			}
+		}
+	}
+}
+
+// mapTableType maps an information_schema.tables table type to TableType
+func mapTableType(tableType string) collector.TableType {
+	switch strings.ToUpper(tableType) {
+	case "VIEW":
+		return collector.TableTypeView
+	case "MATERIALIZED VIEW":
+		return collector.TableTypeMaterializedView
+	default:
+		return collector.TableTypeTable
+	}
+}
+
+// quoteIdentifier quotes a Trino identifier
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteLiteral quotes a Trino string literal
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// qualifiedName returns the catalog.schema.table name of a table
+func qualifiedName(catalog, schema, table string) string {
+	return catalog + "." + schema + "." + table
+}
@@ -0,0 +1,133 @@
+package trino
+
+import (
+	"slices"
+	"testing"
+
+	"go-metadata/internal/collector"
+)
+
+func TestParseCatalogs(t *testing.T) {
+	// Trino 420+
+	catalogs := parseCatalogs(
+		[]string{"catalog_name", "connector_id", "connector_name"},
+		[][]string{
+			{"hive", "hive", "hive"},
+			{"pg_sales", "pg_sales", "postgresql"},
+			{"system", "system", "system"},
+		})
+	if len(catalogs) != 3 {
+		t.Fatalf("expected 3 catalogs, got %+v", catalogs)
+	}
+	if catalogs[1].Catalog != "pg_sales" || catalogs[1].Properties["connector"] != "postgresql" || catalogs[1].Type != SourceName {
+		t.Errorf("unexpected catalog: %+v", catalogs[1])
+	}
+
+	// Older versions only report the catalog name as connector_id
+	catalogs = parseCatalogs([]string{"catalog_name", "connector_id"}, [][]string{{"pg_sales", "pg_sales"}})
+	if len(catalogs) != 1 || catalogs[0].Properties["connector"] != "" {
+		t.Errorf("expected no connector name, got %+v", catalogs)
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	columns, err := parseColumns(
+		[]string{"Column", "Type", "Extra", "Comment"},
+		[][]string{
+			{"id", "bigint", "", "order id"},
+			{"amount", "decimal(10,2)", "", ""},
+			{"note", "varchar(200)", "", ""},
+			{"created_at", "timestamp(3) with time zone", "", ""},
+			{"items", "array(row(sku varchar, qty integer))", "", ""},
+			{"dt", "varchar", "partition key", ""},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantTypes := []string{"INTEGER", "DECIMAL", "STRING", "TIMESTAMP", "ARRAY", "STRING"}
+	if len(columns) != len(wantTypes) {
+		t.Fatalf("expected %d columns, got %+v", len(wantTypes), columns)
+	}
+	for i, col := range columns {
+		if col.Type != wantTypes[i] || col.OrdinalPosition != i+1 || !col.Nullable {
+			t.Errorf("unexpected column %+v", col)
+		}
+	}
+	if columns[0].Comment != "order id" || columns[0].SourceType != "bigint" {
+		t.Errorf("unexpected id column: %+v", columns[0])
+	}
+	if amount := columns[1]; amount.Precision == nil || *amount.Precision != 10 || amount.Scale == nil || *amount.Scale != 2 {
+		t.Errorf("unexpected amount column: %+v", amount)
+	}
+	if note := columns[2]; note.Length == nil || *note.Length != 200 {
+		t.Errorf("unexpected note column: %+v", note)
+	}
+	if got := partitionColumnsOf(columns); !slices.Equal(got, []string{"dt"}) {
+		t.Errorf("partitionColumnsOf() = %v", got)
+	}
+
+	if _, err := parseColumns([]string{"name"}, nil); err == nil {
+		t.Error("expected an error for an unexpected header")
+	}
+}
+
+func TestParseStats(t *testing.T) {
+	header := []string{"column_name", "data_size", "distinct_values_count", "nulls_fraction", "row_count", "low_value", "high_value"}
+	stats, err := parseStats(header, [][]string{
+		{"id", "", "1000", "0", "", "1", "1000"},
+		{"note", "5120", "250", "0.25", "", "", ""},
+		{"", "", "", "", "1000", "", ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.RowCount != 1000 || stats.DataSizeBytes != 5120 {
+		t.Errorf("unexpected table stats: %+v", stats)
+	}
+	if len(stats.ColumnStats) != 2 {
+		t.Fatalf("expected 2 column stats, got %+v", stats.ColumnStats)
+	}
+	id, note := stats.ColumnStats[0], stats.ColumnStats[1]
+	if *id.DistinctCount != 1000 || *id.NullCount != 0 || id.Min != "1" || id.Max != "1000" {
+		t.Errorf("unexpected id stats: %+v", id)
+	}
+	if *note.NullCount != 250 || note.Min != nil {
+		t.Errorf("unexpected note stats: %+v", note)
+	}
+
+	// Tables without statistics have NULL values
+	stats, err = parseStats(header, [][]string{
+		{"id", "", "", "", "", "", ""},
+		{"", "", "", "", "", "", ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.RowCount != 0 || stats.ColumnStats[0].DistinctCount != nil || stats.ColumnStats[0].NullCount != nil {
+		t.Errorf("expected unknown stats, got %+v", stats)
+	}
+}
+
+func TestMapTableType(t *testing.T) {
+	tests := map[string]collector.TableType{
+		"BASE TABLE":        collector.TableTypeTable,
+		"VIEW":              collector.TableTypeView,
+		"MATERIALIZED VIEW": collector.TableTypeMaterializedView,
+	}
+	for in, want := range tests {
+		if got := mapTableType(in); got != want {
+			t.Errorf("mapTableType(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	if got := quoteIdentifier(`we"ird`); got != `"we""ird"` {
+		t.Errorf("quoteIdentifier() = %s", got)
+	}
+	if got := quoteLiteral("o'neil"); got != "'o''neil'" {
+		t.Errorf("quoteLiteral() = %s", got)
+	}
+}
@@ -0,0 +1,13 @@
+// Package trino provides a Trino (and Presto) metadata collector implementation.
+package trino
+
+import (
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/factory"
+)
+
+func init() {
+	// Register Trino collector with the default factory, also for Presto
+	_ = factory.Register(collector.CategoryDataWarehouse, SourceName, NewCollector)
+	_ = factory.Register(collector.CategoryDataWarehouse, PrestoSourceName, NewCollector)
+}
@@ -0,0 +1,704 @@
+// Package trino provides a Trino (and Presto) metadata collector implementation.
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/config"
+	"go-metadata/internal/collector/matcher"
+)
+
+const (
+	// SourceName identifies this collector type
+	SourceName = "trino"
+	// PrestoSourceName identifies the collector type of PrestoDB clusters,
+	// which expose the same metadata
+	PrestoSourceName = "presto"
+	// DefaultPort is the default Trino coordinator HTTP port
+	DefaultPort = 8080
+	// DefaultDriver is the default database/sql driver name
+	DefaultDriver = "trino"
+	// DefaultPrestoDriver is the default database/sql driver name for Presto
+	DefaultPrestoDriver = "presto"
+	// DefaultClientSource is the client source reported to Trino
+	DefaultClientSource = "go-metadata"
+)
+
+// systemCatalog holds Trino's own metadata and has no user tables
+const systemCatalog = "system"
+
+// informationSchema is the schema every catalog exposes its metadata in
+const informationSchema = "information_schema"
+
+// Collector Trino 元数据采集器
+// Trino 联邦查询引擎挂载的每个 catalog 对应一个底层数据源 (Hive、PostgreSQL、Iceberg 等)，
+// 一个 Trino 连接配置即可通过 information_schema 采集所有挂载数据源的元数据。
+type Collector struct {
+	config *config.ConnectorConfig
+	db     collector.Conn[*sql.DB]
+}
+
+// NewCollector 创建 Trino 采集器实例
+func NewCollector(cfg *config.ConnectorConfig) (collector.Collector, error) {
+	if cfg == nil {
+		return nil, collector.NewInvalidConfigError(SourceName, "config", "configuration cannot be nil")
+	}
+	if cfg.Type != "" && cfg.Type != SourceName && cfg.Type != PrestoSourceName {
+		return nil, collector.NewInvalidConfigError(SourceName, "type", fmt.Sprintf("expected '%s', got '%s'", SourceName, cfg.Type))
+	}
+	return &Collector{config: cfg}, nil
+}
+
+// Connect 建立 Trino 连接 (coordinator 的 HTTP 接口)
+// Note: Requires a Trino driver to be registered, e.g.
+// github.com/trinodb/trino-go-client (import _ "github.com/trinodb/trino-go-client/trino"),
+// or for Presto github.com/prestodb/presto-go-client (import _ "github.com/prestodb/presto-go-client/presto").
+func (c *Collector) Connect(ctx context.Context) error {
+	return c.db.Open(func() (*sql.DB, error) { return c.open(ctx) })
+}
+
+// open 建立连接并检查连通性
+func (c *Collector) open(ctx context.Context) (*sql.DB, error) {
+	dsn, err := c.buildDSN()
+	if err != nil {
+		return nil, collector.NewInvalidConfigError(SourceName, "endpoint", err.Error())
+	}
+
+	// Get driver name from config, default to "trino" or "presto"
+	driverName := DefaultDriver
+	if c.config.Type == PrestoSourceName {
+		driverName = DefaultPrestoDriver
+	}
+	if driver := c.config.Properties.Extra["driver"]; driver != "" {
+		driverName = driver
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, collector.NewNetworkError(SourceName, "connect", err)
+	}
+
+	// Configure connection pool
+	if c.config.Properties.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(c.config.Properties.MaxOpenConns)
+	}
+	if c.config.Properties.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(c.config.Properties.MaxIdleConns)
+	}
+	if c.config.Properties.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(time.Duration(c.config.Properties.ConnMaxLifetime) * time.Second)
+	}
+
+	// Test connection with context
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, c.wrapConnectionError(err)
+	}
+
+	return db, nil
+}
+
+// Close 关闭 Trino 连接
+func (c *Collector) Close() error {
+	return c.db.Close(func(db *sql.DB) error {
+		return db.Close()
+	})
+}
+
+// HealthCheck 健康检查
+func (c *Collector) HealthCheck(ctx context.Context) (*collector.HealthStatus, error) {
+	db, ok := c.db.Get()
+	if !ok {
+		return &collector.HealthStatus{
+			Connected: false,
+			Message:   "not connected",
+		}, nil
+	}
+
+	start := time.Now()
+
+	if err := db.PingContext(ctx); err != nil {
+		return &collector.HealthStatus{
+			Connected: false,
+			Latency:   time.Since(start),
+			Message:   err.Error(),
+		}, nil
+	}
+
+	var version string
+	if err := db.QueryRowContext(ctx, "SELECT version()").Scan(&version); err != nil {
+		version = "unknown"
+	}
+
+	return &collector.HealthStatus{
+		Connected: true,
+		Latency:   time.Since(start),
+		Version:   version,
+	}, nil
+}
+
+// DiscoverCatalogs 发现 Catalog（system.metadata.catalogs 中挂载的所有 catalog，system 除外）
+// catalog 按 matching.databases 过滤，其连接器名称 (如 hive、postgresql) 记录在 connector 属性中。
+func (c *Collector) DiscoverCatalogs(ctx context.Context) ([]collector.CatalogInfo, error) {
+	db, ok := c.db.Get()
+	if !ok {
+		return nil, collector.NewConnectionClosedError(SourceName, "discover_catalogs")
+	}
+
+	// connector_name is only available since Trino 420, so select every column
+	header, rows, err := c.query(ctx, db, "discover_catalogs", "SELECT * FROM system.metadata.catalogs")
+	if err != nil {
+		return nil, err
+	}
+	catalogs := parseCatalogs(header, rows)
+
+	var rule *config.MatchingRule
+	if c.config.Matching != nil {
+		rule = c.config.Matching.Databases
+	}
+	ruleMatcher, err := c.ruleMatcher("matching.databases", rule)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]collector.CatalogInfo, 0, len(catalogs))
+	for _, catalog := range catalogs {
+		if catalog.Catalog == systemCatalog || (ruleMatcher != nil && !ruleMatcher.Match(catalog.Catalog)) {
+			continue
+		}
+		result = append(result, catalog)
+	}
+	return result, nil
+}
+
+// ListSchemas 列出 catalog 下的 Schema（information_schema 除外），按 matching.schemas 过滤
+func (c *Collector) ListSchemas(ctx context.Context, catalog string) ([]string, error) {
+	db, ok := c.db.Get()
+	if !ok {
+		return nil, collector.NewConnectionClosedError(SourceName, "list_schemas")
+	}
+	catalog, err := c.resolveCatalog(catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	_, rows, err := c.query(ctx, db, "list_schemas", fmt.Sprintf(
+		"SELECT schema_name FROM %s.information_schema.schemata ORDER BY schema_name", quoteIdentifier(catalog)))
+	if err != nil {
+		return nil, err
+	}
+
+	var schemas []string
+	for _, row := range rows {
+		if len(row) == 0 || row[0] == "" || row[0] == informationSchema {
+			continue
+		}
+		schemas = append(schemas, row[0])
+	}
+
+	// Apply schema matching filter if configured
+	var rule *config.MatchingRule
+	if c.config.Matching != nil {
+		rule = c.config.Matching.Schemas
+	}
+	ruleMatcher, err := c.ruleMatcher("matching.schemas", rule)
+	if err != nil {
+		return nil, err
+	}
+	if ruleMatcher != nil {
+		var filtered []string
+		for _, s := range schemas {
+			if ruleMatcher.Match(s) {
+				filtered = append(filtered, s)
+			}
+		}
+		schemas = filtered
+	}
+
+	return schemas, nil
+}
+
+// ListTables 列出表和视图
+func (c *Collector) ListTables(ctx context.Context, catalog, schema string, opts *collector.ListOptions) (*collector.TableListResult, error) {
+	db, ok := c.db.Get()
+	if !ok {
+		return nil, collector.NewConnectionClosedError(SourceName, "list_tables")
+	}
+	catalog, err := c.resolveCatalog(catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	_, rows, err := c.query(ctx, db, "list_tables", fmt.Sprintf(
+		"SELECT table_name FROM %s.information_schema.tables WHERE table_schema = %s ORDER BY table_name",
+		quoteIdentifier(catalog), quoteLiteral(schema)))
+	if err != nil {
+		return nil, err
+	}
+
+	var allTables []string
+	for _, row := range rows {
+		if len(row) > 0 && row[0] != "" {
+			allTables = append(allTables, row[0])
+		}
+	}
+
+	// Apply table matching filter
+	tables := c.filterTables(allTables, opts)
+
+	// Apply pagination
+	result := &collector.TableListResult{
+		TotalCount: len(tables),
+	}
+
+	if opts != nil && opts.PageSize > 0 {
+		startIdx := 0
+		if opts.PageToken != "" {
+			startIdx, _ = strconv.Atoi(opts.PageToken)
+		}
+
+		endIdx := startIdx + opts.PageSize
+		if endIdx > len(tables) {
+			endIdx = len(tables)
+		}
+
+		if startIdx < len(tables) {
+			result.Tables = tables[startIdx:endIdx]
+			if endIdx < len(tables) {
+				result.NextPageToken = strconv.Itoa(endIdx)
+			}
+		}
+	} else {
+		result.Tables = tables
+	}
+
+	return result, nil
+}
+
+// filterTables applies matching rules to filter tables
+func (c *Collector) filterTables(tables []string, opts *collector.ListOptions) []string {
+	// First apply config-level table matching
+	if c.config.Matching != nil && c.config.Matching.Tables != nil {
+		ruleMatcher, err := matcher.NewRuleMatcher(
+			c.config.Matching.Tables,
+			c.config.Matching.PatternType,
+			c.config.Matching.CaseSensitive,
+		)
+		if err == nil {
+			var filtered []string
+			for _, t := range tables {
+				if ruleMatcher.Match(t) {
+					filtered = append(filtered, t)
+				}
+			}
+			tables = filtered
+		}
+	}
+
+	// Then apply request-level filter
+	if opts != nil && opts.Filter != nil {
+		patternType := "glob"
+		caseSensitive := false
+		if c.config.Matching != nil {
+			patternType = c.config.Matching.PatternType
+			caseSensitive = c.config.Matching.CaseSensitive
+		}
+
+		ruleMatcher, err := matcher.NewRuleMatcher(
+			&config.MatchingRule{
+				Include: opts.Filter.Include,
+				Exclude: opts.Filter.Exclude,
+			},
+			patternType,
+			caseSensitive,
+		)
+		if err == nil {
+			var filtered []string
+			for _, t := range tables {
+				if ruleMatcher.Match(t) {
+					filtered = append(filtered, t)
+				}
+			}
+			tables = filtered
+		}
+	}
+
+	return tables
+}
+
+// FetchTableMetadata 获取表元数据
+// 表类型取自 information_schema.tables，列取自 SHOW COLUMNS (Extra 为 partition key 的列是 Hive 分区列)，
+// 表注释取自 system.metadata.table_comments，视图定义取自 information_schema.views。
+func (c *Collector) FetchTableMetadata(ctx context.Context, catalog, schema, table string) (*collector.TableMetadata, error) {
+	db, ok := c.db.Get()
+	if !ok {
+		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_metadata")
+	}
+	catalog, err := c.resolveCatalog(catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	_, rows, err := c.query(ctx, db, "fetch_table_metadata", fmt.Sprintf(
+		"SELECT table_type FROM %s.information_schema.tables WHERE table_schema = %s AND table_name = %s",
+		quoteIdentifier(catalog), quoteLiteral(schema), quoteLiteral(table)))
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, collector.NewNotFoundError(SourceName, "fetch_table_metadata", qualifiedName(catalog, schema, table), nil)
+	}
+
+	header, columnRows, err := c.tableQuery(ctx, db, "fetch_table_metadata", "SHOW COLUMNS FROM %s", catalog, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := parseColumns(header, columnRows)
+	if err != nil {
+		return nil, collector.NewParseError(SourceName, "fetch_table_metadata", err)
+	}
+
+	metadata := &collector.TableMetadata{
+		SourceCategory: collector.CategoryDataWarehouse,
+		SourceType:     SourceName,
+		Catalog:        catalog,
+		Schema:         schema,
+		Name:           table,
+		Type:           mapTableType(rows[0][0]),
+		Columns:        columns,
+		Properties:     map[string]string{},
+	}
+	if connector := c.connectorOf(ctx, db, catalog); connector != "" {
+		metadata.Properties["trino.connector"] = connector
+	}
+	if partitionColumns := partitionColumnsOf(columns); len(partitionColumns) > 0 {
+		metadata.Partitions = []collector.PartitionInfo{
+			{Name: "partitions", Type: "LIST", Columns: partitionColumns},
+		}
+	}
+
+	// Comments and view definitions are best effort, as not every connector
+	// supports them
+	_, commentRows, err := c.query(ctx, db, "fetch_table_metadata", fmt.Sprintf(
+		"SELECT comment FROM system.metadata.table_comments WHERE catalog_name = %s AND schema_name = %s AND table_name = %s",
+		quoteLiteral(catalog), quoteLiteral(schema), quoteLiteral(table)))
+	if err == nil && len(commentRows) > 0 {
+		metadata.Comment = commentRows[0][0]
+	}
+	if metadata.Type == collector.TableTypeView {
+		_, viewRows, err := c.query(ctx, db, "fetch_table_metadata", fmt.Sprintf(
+			"SELECT view_definition FROM %s.information_schema.views WHERE table_schema = %s AND table_name = %s",
+			quoteIdentifier(catalog), quoteLiteral(schema), quoteLiteral(table)))
+		if err == nil && len(viewRows) > 0 && viewRows[0][0] != "" {
+			metadata.Properties["view_definition"] = viewRows[0][0]
+		}
+	}
+
+	metadata.LastRefreshedAt = time.Now()
+	return metadata, nil
+}
+
+// FetchTableStatistics 获取表统计信息
+// 使用 SHOW STATS 读取连接器提供的统计 (如 Hive Metastore 中 ANALYZE 的结果)，无需扫描数据。
+// statistics.level 为 table 时不采集列统计。
+func (c *Collector) FetchTableStatistics(ctx context.Context, catalog, schema, table string) (*collector.TableStatistics, error) {
+	db, ok := c.db.Get()
+	if !ok {
+		return nil, collector.NewConnectionClosedError(SourceName, "fetch_table_statistics")
+	}
+	catalog, err := c.resolveCatalog(catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	header, rows, err := c.tableQuery(ctx, db, "fetch_table_statistics", "SHOW STATS FOR %s", catalog, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := parseStats(header, rows)
+	if err != nil {
+		return nil, collector.NewParseError(SourceName, "fetch_table_statistics", err)
+	}
+	stats.CollectedAt = time.Now()
+
+	if c.config.Statistics != nil && c.config.Statistics.Level == "table" {
+		stats.ColumnStats = nil
+		return stats, nil
+	}
+	stats.ColumnStats = c.filterColumnStats(stats.ColumnStats)
+
+	return stats, nil
+}
+
+// filterColumnStats keeps the columns configured in statistics.column_stats.columns
+func (c *Collector) filterColumnStats(stats []collector.ColumnStats) []collector.ColumnStats {
+	if c.config.Statistics == nil || c.config.Statistics.ColumnStats == nil || len(c.config.Statistics.ColumnStats.Columns) == 0 {
+		return stats
+	}
+	wanted := make(map[string]bool, len(c.config.Statistics.ColumnStats.Columns))
+	for _, name := range c.config.Statistics.ColumnStats.Columns {
+		wanted[strings.ToLower(name)] = true
+	}
+	var filtered []collector.ColumnStats
+	for _, s := range stats {
+		if wanted[strings.ToLower(s.Name)] {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+// FetchPartitions 获取分区信息
+// 分区列为 SHOW COLUMNS 中的 partition key 列，分区数取自 Hive 连接器的 "table$partitions" 隐藏表。
+func (c *Collector) FetchPartitions(ctx context.Context, catalog, schema, table string) ([]collector.PartitionInfo, error) {
+	db, ok := c.db.Get()
+	if !ok {
+		return nil, collector.NewConnectionClosedError(SourceName, "fetch_partitions")
+	}
+	catalog, err := c.resolveCatalog(catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	header, rows, err := c.tableQuery(ctx, db, "fetch_partitions", "SHOW COLUMNS FROM %s", catalog, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := parseColumns(header, rows)
+	if err != nil {
+		return nil, collector.NewParseError(SourceName, "fetch_partitions", err)
+	}
+
+	partitionColumns := partitionColumnsOf(columns)
+	if len(partitionColumns) == 0 {
+		return []collector.PartitionInfo{}, nil
+	}
+
+	partition := collector.PartitionInfo{Name: "partitions", Type: "LIST", Columns: partitionColumns}
+	_, countRows, err := c.query(ctx, db, "fetch_partitions", fmt.Sprintf("SELECT count(*) FROM %s.%s.%s",
+		quoteIdentifier(catalog), quoteIdentifier(schema), quoteIdentifier(table+"$partitions")))
+	if err == nil && len(countRows) > 0 {
+		partition.ValuesCount, _ = strconv.Atoi(countRows[0][0])
+	}
+	return []collector.PartitionInfo{partition}, nil
+}
+
+// connectorOf returns the connector name of a catalog, or "" if the Trino
+// version does not report it.
+func (c *Collector) connectorOf(ctx context.Context, db *sql.DB, catalog string) string {
+	header, rows, err := c.query(ctx, db, "fetch_table_metadata", fmt.Sprintf(
+		"SELECT * FROM system.metadata.catalogs WHERE catalog_name = %s", quoteLiteral(catalog)))
+	if err != nil {
+		return ""
+	}
+	for _, info := range parseCatalogs(header, rows) {
+		return info.Properties["connector"]
+	}
+	return ""
+}
+
+// resolveCatalog returns catalog, or the catalog configured in
+// properties.extra.catalog if it is empty.
+func (c *Collector) resolveCatalog(catalog string) (string, error) {
+	if catalog != "" {
+		return catalog, nil
+	}
+	if catalog = c.config.Properties.Extra["catalog"]; catalog != "" {
+		return catalog, nil
+	}
+	return "", collector.NewInvalidConfigError(SourceName, "catalog", "a catalog is required, set properties.extra.catalog or discover catalogs first")
+}
+
+// ruleMatcher returns the matcher of a configured rule, or nil if the rule is
+// not configured.
+func (c *Collector) ruleMatcher(field string, rule *config.MatchingRule) (*matcher.RuleMatcher, error) {
+	if rule == nil {
+		return nil, nil
+	}
+	ruleMatcher, err := matcher.NewRuleMatcher(rule, c.config.Matching.PatternType, c.config.Matching.CaseSensitive)
+	if err != nil {
+		return nil, collector.NewInvalidConfigError(SourceName, field, err.Error())
+	}
+	return ruleMatcher, nil
+}
+
+// tableQuery runs a statement on catalog.schema.table, mapping a missing table
+// to a not found error.
+func (c *Collector) tableQuery(ctx context.Context, db *sql.DB, operation, format, catalog, schema, table string) ([]string, [][]string, error) {
+	name := fmt.Sprintf("%s.%s.%s", quoteIdentifier(catalog), quoteIdentifier(schema), quoteIdentifier(table))
+	header, rows, err := c.query(ctx, db, operation, fmt.Sprintf(format, name))
+	if err != nil && collector.GetErrorCode(err) == collector.ErrCodeQueryError {
+		msg := err.Error()
+		if strings.Contains(msg, "does not exist") || strings.Contains(msg, "not found") {
+			return nil, nil, collector.NewNotFoundError(SourceName, operation, qualifiedName(catalog, schema, table), nil)
+		}
+	}
+	return header, rows, err
+}
+
+// query runs a statement and returns its column names and trimmed rows;
+// NULL cells are empty strings.
+func (c *Collector) query(ctx context.Context, db *sql.DB, operation, query string) ([]string, [][]string, error) {
+	// Check context before starting operation
+	if err := collector.CheckContext(ctx, SourceName, operation); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, collector.WrapContextError(ctx, SourceName, operation)
+		}
+		return nil, nil, collector.NewQueryError(SourceName, operation, err)
+	}
+	defer rows.Close()
+
+	header, err := rows.Columns()
+	if err != nil {
+		return nil, nil, collector.NewParseError(SourceName, operation, err)
+	}
+
+	var output [][]string
+	for rows.Next() {
+		// Check context during iteration
+		if err := collector.CheckContext(ctx, SourceName, operation); err != nil {
+			return nil, nil, err
+		}
+
+		values := make([]sql.NullString, len(header))
+		valuePtrs := make([]interface{}, len(header))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, nil, collector.NewParseError(SourceName, operation, err)
+		}
+
+		row := make([]string, len(header))
+		for i, v := range values {
+			if v.Valid {
+				row[i] = strings.TrimSpace(v.String)
+			}
+		}
+		output = append(output, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, collector.WrapContextError(ctx, SourceName, operation)
+		}
+		return nil, nil, collector.NewQueryError(SourceName, operation, err)
+	}
+
+	return header, output, nil
+}
+
+// buildDSN constructs the Trino connection string from configuration
+// Format: http[s]://user[:password]@host:port?catalog=...&source=...
+// HTTPS is used when a password is set, as Trino only accepts passwords over
+// TLS, or when the endpoint has an https:// prefix.
+func (c *Collector) buildDSN() (string, error) {
+	endpoint := c.config.Endpoint
+	scheme := "http"
+	if c.config.Credentials.Password != "" {
+		scheme = "https"
+	}
+	switch lower := strings.ToLower(endpoint); {
+	case strings.HasPrefix(lower, "https://"):
+		scheme, endpoint = "https", endpoint[len("https://"):]
+	case strings.HasPrefix(lower, "http://"):
+		endpoint = endpoint[len("http://"):]
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if endpoint == "" {
+		return "", fmt.Errorf("endpoint is required")
+	}
+
+	// Parse endpoint (expected format: host:port or host)
+	host := endpoint
+	port := DefaultPort
+	if scheme == "https" {
+		port = 443
+	}
+
+	if idx := strings.LastIndex(endpoint, ":"); idx != -1 {
+		host = endpoint[:idx]
+		var err error
+		port, err = strconv.Atoi(endpoint[idx+1:])
+		if err != nil {
+			return "", fmt.Errorf("invalid port in endpoint: %s", endpoint)
+		}
+	}
+
+	u := &url.URL{
+		Scheme: scheme,
+		Host:   fmt.Sprintf("%s:%d", host, port),
+	}
+	// Trino requires a user, even without authentication
+	user := c.config.Credentials.User
+	if user == "" {
+		user = DefaultClientSource
+	}
+	if password := c.config.Credentials.Password; password != "" {
+		u.User = url.UserPassword(user, password)
+	} else {
+		u.User = url.User(user)
+	}
+
+	query := url.Values{}
+	query.Set("source", DefaultClientSource)
+
+	// Add extra parameters (catalog, schema, session_properties, ...),
+	// except the collector's own settings
+	for k, v := range c.config.Properties.Extra {
+		if k != "driver" {
+			query.Set(k, v)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// wrapConnectionError wraps a connection error with appropriate error type
+func (c *Collector) wrapConnectionError(err error) error {
+	errStr := err.Error()
+	if strings.Contains(errStr, "401") || strings.Contains(errStr, "Unauthorized") ||
+		strings.Contains(errStr, "authentication") || strings.Contains(errStr, "Authentication") {
+		return collector.NewAuthError(SourceName, "connect", err)
+	}
+	if strings.Contains(errStr, "403") || strings.Contains(errStr, "Access Denied") {
+		return collector.NewPermissionDeniedError(SourceName, "connect", err)
+	}
+	if strings.Contains(errStr, "connection refused") || strings.Contains(errStr, "no such host") ||
+		strings.Contains(errStr, "no route to host") {
+		return collector.NewNetworkError(SourceName, "connect", err)
+	}
+	if strings.Contains(errStr, "timeout") || strings.Contains(errStr, "deadline exceeded") {
+		return collector.NewTimeoutError(SourceName, "connect", err)
+	}
+	return collector.NewNetworkError(SourceName, "connect", err)
+}
+
+// Category 返回数据源类别
+func (c *Collector) Category() collector.DataSourceCategory {
+	return collector.CategoryDataWarehouse
+}
+
+// Type 返回数据源类型
+func (c *Collector) Type() string {
+	if c.config.Type == PrestoSourceName {
+		return PrestoSourceName
+	}
+	return SourceName
+}
+
+// Ensure Collector implements collector.Collector interface
+var _ collector.Collector = (*Collector)(nil)
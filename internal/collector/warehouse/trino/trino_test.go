@@ -0,0 +1,162 @@
+package trino
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/config"
+)
+
+// TestNewCollector tests the NewCollector function
+func TestNewCollector(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *config.ConnectorConfig
+		wantErr  bool
+		wantType string
+	}{
+		{name: "nil config", cfg: nil, wantErr: true},
+		{name: "valid config", cfg: &config.ConnectorConfig{Type: "trino", Endpoint: "localhost:8080"}, wantType: SourceName},
+		{name: "presto", cfg: &config.ConnectorConfig{Type: "presto", Endpoint: "localhost:8080"}, wantType: PrestoSourceName},
+		{name: "empty type (allowed)", cfg: &config.ConnectorConfig{Endpoint: "localhost:8080"}, wantType: SourceName},
+		{name: "wrong type", cfg: &config.ConnectorConfig{Type: "hive", Endpoint: "localhost:10000"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCollector(tt.cfg)
+			if tt.wantErr {
+				if collector.GetErrorCode(err) != collector.ErrCodeInvalidConfig {
+					t.Errorf("expected invalid config error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.Type() != tt.wantType || c.Category() != collector.CategoryDataWarehouse {
+				t.Errorf("unexpected collector %s/%s", c.Category(), c.Type())
+			}
+		})
+	}
+}
+
+// TestBuildDSN tests the DSN building logic
+func TestBuildDSN(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        *config.ConnectorConfig
+		wantErr    bool
+		wantScheme string
+		wantHost   string
+		wantUser   string
+		wantQuery  map[string]string
+	}{
+		{
+			name:       "default port and user",
+			cfg:        &config.ConnectorConfig{Endpoint: "coordinator"},
+			wantScheme: "http",
+			wantHost:   "coordinator:8080",
+			wantUser:   DefaultClientSource,
+			wantQuery:  map[string]string{"source": DefaultClientSource},
+		},
+		{
+			name: "https when a password is set",
+			cfg: &config.ConnectorConfig{
+				Endpoint:    "coordinator",
+				Credentials: config.Credentials{User: "etl", Password: "p@ss"},
+				Properties: config.ConnectionProps{
+					Extra: map[string]string{"catalog": "hive", "driver": "trino", "session_properties": "query_max_run_time:1h"},
+				},
+			},
+			wantScheme: "https",
+			wantHost:   "coordinator:443",
+			wantUser:   "etl",
+			wantQuery:  map[string]string{"catalog": "hive", "session_properties": "query_max_run_time:1h", "driver": ""},
+		},
+		{
+			name:       "scheme prefix",
+			cfg:        &config.ConnectorConfig{Endpoint: "https://trino.example.com:8443/", Credentials: config.Credentials{User: "etl"}},
+			wantScheme: "https",
+			wantHost:   "trino.example.com:8443",
+			wantUser:   "etl",
+		},
+		{
+			name:    "invalid port",
+			cfg:     &config.ConnectorConfig{Endpoint: "coordinator:abc"},
+			wantErr: true,
+		},
+		{
+			name:    "empty endpoint",
+			cfg:     &config.ConnectorConfig{Endpoint: "http://"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Collector{config: tt.cfg}
+			dsn, err := c.buildDSN()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got DSN %s", dsn)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			u, err := url.Parse(dsn)
+			if err != nil {
+				t.Fatalf("invalid DSN %s: %v", dsn, err)
+			}
+			if u.Scheme != tt.wantScheme || u.Host != tt.wantHost || u.User.Username() != tt.wantUser {
+				t.Errorf("unexpected DSN %s", dsn)
+			}
+			for k, v := range tt.wantQuery {
+				if got := u.Query().Get(k); got != v {
+					t.Errorf("query %s = %q, want %q", k, got, v)
+				}
+			}
+		})
+	}
+}
+
+// TestCollectorNotConnected tests that operations fail before Connect
+func TestCollectorNotConnected(t *testing.T) {
+	c := &Collector{config: &config.ConnectorConfig{Endpoint: "localhost:8080"}}
+	ctx := context.Background()
+
+	if _, err := c.DiscoverCatalogs(ctx); collector.GetErrorCode(err) != collector.ErrCodeConnectionClosed {
+		t.Errorf("DiscoverCatalogs: expected connection closed error, got %v", err)
+	}
+	if _, err := c.ListSchemas(ctx, "hive"); collector.GetErrorCode(err) != collector.ErrCodeConnectionClosed {
+		t.Errorf("ListSchemas: expected connection closed error, got %v", err)
+	}
+	if _, err := c.FetchTableMetadata(ctx, "hive", "db", "t"); collector.GetErrorCode(err) != collector.ErrCodeConnectionClosed {
+		t.Errorf("FetchTableMetadata: expected connection closed error, got %v", err)
+	}
+	status, err := c.HealthCheck(ctx)
+	if err != nil || status.Connected {
+		t.Errorf("HealthCheck: unexpected status %+v, err %v", status, err)
+	}
+}
+
+// TestResolveCatalog tests the default catalog fallback
+func TestResolveCatalog(t *testing.T) {
+	c := &Collector{config: &config.ConnectorConfig{}}
+	if _, err := c.resolveCatalog(""); collector.GetErrorCode(err) != collector.ErrCodeInvalidConfig {
+		t.Errorf("expected invalid config error without a catalog, got %v", err)
+	}
+	if got, _ := c.resolveCatalog("iceberg"); got != "iceberg" {
+		t.Errorf("resolveCatalog(iceberg) = %q", got)
+	}
+
+	c.config.Properties.Extra = map[string]string{"catalog": "hive"}
+	if got, _ := c.resolveCatalog(""); got != "hive" {
+		t.Errorf("expected the configured catalog, got %q", got)
+	}
+}
@@ -1,36 +1,131 @@
 package data
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
 	"go-metadata/internal/biz"
 	"go-metadata/internal/conf"
+	"go-metadata/internal/data/migrate"
+	"go-metadata/internal/store"
+	"go-metadata/migrations"
 
 	"github.com/go-kratos/kratos/v2/log"
+	_ "github.com/go-sql-driver/mysql"
 	"github.com/google/wire"
 )
 
+// baselineTable 用于识别迁移机制引入前手工建表的数据库
+const baselineTable = "connectors"
+
 // ProviderSet is data providers.
 var ProviderSet = wire.NewSet(
 	NewData,
 	NewDataSourceRepo,
 	NewTaskRepo,
 	NewTemplateRepo,
+	NewTableRepo,
+	NewPolicyRepo,
 )
 
 // Data is the data layer struct.
 type Data struct {
-	log *log.Helper
+	db *sql.DB
+	// store is the metadata store the catalog of synced tables is kept in,
+	// nil if none is configured.
+	store store.Repository
+	log   *log.Helper
 }
 
 // NewData creates a new Data.
-func NewData(c *conf.Data, logger log.Logger) (*Data, func(), error) {
+// 配置了数据库时打开连接并在启动时执行表结构迁移；st 为同步写入的元数据存储，未配置时为 nil
+func NewData(c *conf.Data, st store.Repository, logger log.Logger) (*Data, func(), error) {
+	helper := log.NewHelper(logger)
+
+	db, err := openDatabase(c.GetDatabase(), helper)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	cleanup := func() {
-		log.NewHelper(logger).Info("closing the data resources")
+		helper.Info("closing the data resources")
+		if db != nil {
+			db.Close()
+		}
 	}
 	return &Data{
-		log: log.NewHelper(logger),
+		db:    db,
+		store: st,
+		log:   helper,
 	}, cleanup, nil
 }
 
+// openDatabase 打开仓库数据库并迁移到最新版本，未配置时返回 nil
+func openDatabase(c *conf.Database, helper *log.Helper) (*sql.DB, error) {
+	if c == nil || c.Source == "" {
+		return nil, nil
+	}
+
+	db, err := sql.Open(c.Driver, c.Source)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	if c.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(int(c.MaxOpenConns))
+	}
+	if c.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(int(c.MaxIdleConns))
+	}
+	if c.ConnMaxLifetime != nil {
+		db.SetConnMaxLifetime(c.ConnMaxLifetime.AsDuration())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect database: %w", err)
+	}
+	if err := runMigrations(ctx, db, c.Driver, helper); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// runMigrations 执行未应用的迁移；数据库版本高于当前程序已知版本或处于 dirty 状态时拒绝启动
+func runMigrations(ctx context.Context, db *sql.DB, driver string, helper *log.Helper) error {
+	migrator, err := migrate.New(db, driver, migrations.FS)
+	if err != nil {
+		return err
+	}
+	// 已手工执行过初始建表脚本的数据库记为版本 1，避免重复执行初始迁移
+	baselined, err := migrator.Baseline(ctx, 1, baselineTable)
+	if err != nil {
+		return err
+	}
+	if baselined {
+		helper.Infof("existing database schema recorded as version 1")
+	}
+	from, _, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate database: %w", err)
+	}
+	if applied > 0 {
+		helper.Infof("database schema migrated from version %d to %d", from, migrator.Latest())
+	} else {
+		helper.Infof("database schema is up to date at version %d", from)
+	}
+	return nil
+}
+
 // dataSourceRepo implements biz.DataSourceRepo.
 type dataSourceRepo struct {
 	data *Data
@@ -72,3 +167,31 @@ func NewTemplateRepo(data *Data, logger log.Logger) biz.TemplateRepo {
 		log:  log.NewHelper(logger),
 	}
 }
+
+// tableRepo implements biz.TableRepo.
+type tableRepo struct {
+	data *Data
+	log  *log.Helper
+}
+
+// NewTableRepo creates a new TableRepo.
+func NewTableRepo(data *Data, logger log.Logger) biz.TableRepo {
+	return &tableRepo{
+		data: data,
+		log:  log.NewHelper(logger),
+	}
+}
+
+// policyRepo implements biz.PolicyRepo.
+type policyRepo struct {
+	data *Data
+	log  *log.Helper
+}
+
+// NewPolicyRepo creates a new PolicyRepo.
+func NewPolicyRepo(data *Data, logger log.Logger) biz.PolicyRepo {
+	return &policyRepo{
+		data: data,
+		log:  log.NewHelper(logger),
+	}
+}
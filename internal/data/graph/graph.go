@@ -11,6 +11,8 @@ type Config struct {
 	User     string
 	Password string
 	Space    string // NebulaGraph space 或 Neo4j database
+	// BatchSize 批量写入时每批的最大条数，0 表示使用默认值
+	BatchSize int
 }
 
 // GraphDB defines the interface for graph database operations.
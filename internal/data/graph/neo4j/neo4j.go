@@ -1,17 +1,55 @@
 // Package neo4j provides a Neo4j implementation of the GraphDB interface.
+//
+// Every node carries the Entity label plus a label for its type (Database,
+// Table, Column, Job) and is identified by its id property. Edges point in the
+// direction of data flow, so upstream traversals follow incoming lineage
+// relationships and downstream traversals follow outgoing ones.
 package neo4j
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
 
 	"go-metadata/internal/data/graph"
+	"go-metadata/internal/tlsprofile"
+)
+
+const (
+	defaultPort      = 7687
+	defaultBatchSize = 500
+
+	entityLabel = "Entity"
 )
 
+var nodeLabels = map[graph.NodeType]string{
+	graph.NodeTypeDatabase: "Database",
+	graph.NodeTypeTable:    "Table",
+	graph.NodeTypeColumn:   "Column",
+	graph.NodeTypeJob:      "Job",
+}
+
+var relTypes = map[graph.EdgeType]string{
+	graph.EdgeTypeContains:   "CONTAINS",
+	graph.EdgeTypeDependsOn:  "DEPENDS_ON",
+	graph.EdgeTypeProducedBy: "PRODUCED_BY",
+}
+
+// lineageRels are the relationship types followed by lineage traversals.
+const lineageRels = "DEPENDS_ON|PRODUCED_BY"
+
 // Client implements the graph.GraphDB interface for Neo4j.
 type Client struct {
 	config *graph.Config
-	// TODO: Add Neo4j driver connection fields
+
+	mu     sync.RWMutex
+	driver neo4j.DriverWithContext
 }
 
 // NewClient creates a new Neo4j client with the given configuration.
@@ -21,88 +59,528 @@ func NewClient(config *graph.Config) *Client {
 	}
 }
 
-// Connect establishes a connection to Neo4j.
+// Connect establishes a connection to Neo4j and creates the id constraint
+// used to look up nodes.
 func (c *Client) Connect(ctx context.Context) error {
-	// TODO: Implement Neo4j connection
-	return fmt.Errorf("not implemented")
+	driver, err := neo4j.NewDriverWithContext(c.uri(), neo4j.BasicAuth(c.config.User, c.config.Password, ""),
+		func(cfg *neo4j.Config) { cfg.TlsConfig = tlsprofile.Client() })
+	if err != nil {
+		return fmt.Errorf("%w: %v", graph.ErrConnectionFailed, err)
+	}
+	if err := driver.VerifyConnectivity(ctx); err != nil {
+		driver.Close(ctx)
+		return fmt.Errorf("%w: %v", graph.ErrConnectionFailed, err)
+	}
+
+	c.mu.Lock()
+	c.driver = driver
+	c.mu.Unlock()
+
+	_, err = c.run(ctx, "CREATE CONSTRAINT entity_id IF NOT EXISTS FOR (n:"+entityLabel+") REQUIRE n.id IS UNIQUE", nil)
+	return err
 }
 
 // Close closes the connection to Neo4j.
 func (c *Client) Close() error {
-	// TODO: Implement connection close
-	return nil
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.driver == nil {
+		return nil
+	}
+	err := c.driver.Close(context.Background())
+	c.driver = nil
+	return err
 }
 
 // CreateNode creates a new node in Neo4j.
 func (c *Client) CreateNode(ctx context.Context, node *graph.Node) error {
-	// TODO: Implement node creation
-	return fmt.Errorf("not implemented")
+	label, err := nodeLabel(node.Type)
+	if err != nil {
+		return err
+	}
+	props, err := nodeProps(node)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("CREATE (n:%s:%s) SET n = $props", entityLabel, label)
+	if _, err := c.run(ctx, query, map[string]any{"props": props}); err != nil {
+		if isConstraintViolation(err) {
+			return fmt.Errorf("%w: %s", graph.ErrDuplicateNode, node.ID)
+		}
+		return err
+	}
+	return nil
 }
 
 // GetNode retrieves a node by its ID from Neo4j.
 func (c *Client) GetNode(ctx context.Context, id string) (*graph.Node, error) {
-	// TODO: Implement node retrieval
-	return nil, fmt.Errorf("not implemented")
+	result, err := c.run(ctx, "MATCH (n:"+entityLabel+" {id: $id}) RETURN n", map[string]any{"id": id})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Records) == 0 {
+		return nil, fmt.Errorf("%w: %s", graph.ErrNodeNotFound, id)
+	}
+	n, _, err := neo4j.GetRecordValue[dbtype.Node](result.Records[0], "n")
+	if err != nil {
+		return nil, err
+	}
+	return toNode(n)
 }
 
 // UpdateNode updates an existing node in Neo4j.
 func (c *Client) UpdateNode(ctx context.Context, node *graph.Node) error {
-	// TODO: Implement node update
-	return fmt.Errorf("not implemented")
+	props, err := nodeProps(node)
+	if err != nil {
+		return err
+	}
+
+	result, err := c.run(ctx, "MATCH (n:"+entityLabel+" {id: $id}) SET n += $props RETURN count(n) AS updated",
+		map[string]any{"id": node.ID, "props": props})
+	if err != nil {
+		return err
+	}
+	if count(result, "updated") == 0 {
+		return fmt.Errorf("%w: %s", graph.ErrNodeNotFound, node.ID)
+	}
+	return nil
 }
 
-// DeleteNode deletes a node by its ID from Neo4j.
+// DeleteNode deletes a node and its relationships by its ID from Neo4j.
 func (c *Client) DeleteNode(ctx context.Context, id string) error {
-	// TODO: Implement node deletion
-	return fmt.Errorf("not implemented")
+	result, err := c.run(ctx, "MATCH (n:"+entityLabel+" {id: $id}) WITH n, n.id AS id DETACH DELETE n RETURN count(id) AS deleted",
+		map[string]any{"id": id})
+	if err != nil {
+		return err
+	}
+	if count(result, "deleted") == 0 {
+		return fmt.Errorf("%w: %s", graph.ErrNodeNotFound, id)
+	}
+	return nil
 }
 
-// CreateEdge creates a new edge in Neo4j.
+// CreateEdge creates a new edge in Neo4j. Both endpoints must exist.
 func (c *Client) CreateEdge(ctx context.Context, edge *graph.Edge) error {
-	// TODO: Implement edge creation
-	return fmt.Errorf("not implemented")
+	relType, err := relType(edge.Type)
+	if err != nil {
+		return err
+	}
+	props, err := edgeProps(edge)
+	if err != nil {
+		return err
+	}
+
+	existing, err := c.run(ctx, "MATCH ()-[r {id: $id}]->() RETURN count(r) AS existing", map[string]any{"id": props["id"]})
+	if err != nil {
+		return err
+	}
+	if count(existing, "existing") > 0 {
+		return fmt.Errorf("%w: %s", graph.ErrDuplicateEdge, props["id"])
+	}
+
+	query := fmt.Sprintf(`MATCH (s:%[1]s {id: $source}), (t:%[1]s {id: $target})
+CREATE (s)-[r:%[2]s]->(t) SET r = $props
+RETURN count(r) AS created`, entityLabel, relType)
+	result, err := c.run(ctx, query, map[string]any{"source": edge.SourceID, "target": edge.TargetID, "props": props})
+	if err != nil {
+		return err
+	}
+	if count(result, "created") == 0 {
+		return fmt.Errorf("%w: %s or %s", graph.ErrNodeNotFound, edge.SourceID, edge.TargetID)
+	}
+	return nil
 }
 
 // GetEdge retrieves an edge by its ID from Neo4j.
 func (c *Client) GetEdge(ctx context.Context, id string) (*graph.Edge, error) {
-	// TODO: Implement edge retrieval
-	return nil, fmt.Errorf("not implemented")
+	result, err := c.run(ctx, "MATCH ()-[r {id: $id}]->() RETURN r", map[string]any{"id": id})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Records) == 0 {
+		return nil, fmt.Errorf("%w: %s", graph.ErrEdgeNotFound, id)
+	}
+	r, _, err := neo4j.GetRecordValue[dbtype.Relationship](result.Records[0], "r")
+	if err != nil {
+		return nil, err
+	}
+	return toEdge(r)
 }
 
 // DeleteEdge deletes an edge by its ID from Neo4j.
 func (c *Client) DeleteEdge(ctx context.Context, id string) error {
-	// TODO: Implement edge deletion
-	return fmt.Errorf("not implemented")
+	result, err := c.run(ctx, "MATCH ()-[r {id: $id}]->() WITH r, r.id AS id DELETE r RETURN count(id) AS deleted",
+		map[string]any{"id": id})
+	if err != nil {
+		return err
+	}
+	if count(result, "deleted") == 0 {
+		return fmt.Errorf("%w: %s", graph.ErrEdgeNotFound, id)
+	}
+	return nil
 }
 
-// GetUpstream retrieves upstream nodes and edges for a given node.
+// GetUpstream retrieves upstream nodes and edges for a given node, following
+// lineage relationships up to depth hops (depth <= 0 means unlimited).
 func (c *Client) GetUpstream(ctx context.Context, nodeID string, depth int) ([]*graph.Node, []*graph.Edge, error) {
-	// TODO: Implement upstream query
-	return nil, nil, fmt.Errorf("not implemented")
+	return c.traverse(ctx, nodeID, upstreamQuery(depth))
 }
 
-// GetDownstream retrieves downstream nodes and edges for a given node.
+// GetDownstream retrieves downstream nodes and edges for a given node,
+// following lineage relationships up to depth hops (depth <= 0 means unlimited).
 func (c *Client) GetDownstream(ctx context.Context, nodeID string, depth int) ([]*graph.Node, []*graph.Edge, error) {
-	// TODO: Implement downstream query
-	return nil, nil, fmt.Errorf("not implemented")
+	return c.traverse(ctx, nodeID, downstreamQuery(depth))
 }
 
-// GetLineage retrieves the complete lineage graph for a given node.
+// GetLineage retrieves the complete lineage graph for a given node: the node
+// itself plus its upstream and downstream nodes and edges.
 func (c *Client) GetLineage(ctx context.Context, nodeID string, depth int) (*graph.LineageGraph, error) {
-	// TODO: Implement lineage query
-	return nil, fmt.Errorf("not implemented")
+	root, err := c.GetNode(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	upNodes, upEdges, err := c.GetUpstream(ctx, nodeID, depth)
+	if err != nil {
+		return nil, err
+	}
+	downNodes, downEdges, err := c.GetDownstream(ctx, nodeID, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	lineage := &graph.LineageGraph{Nodes: []*graph.Node{root}}
+	seenNodes := map[string]bool{root.ID: true}
+	for _, n := range append(upNodes, downNodes...) {
+		if !seenNodes[n.ID] {
+			seenNodes[n.ID] = true
+			lineage.Nodes = append(lineage.Nodes, n)
+		}
+	}
+	seenEdges := make(map[string]bool)
+	for _, e := range append(upEdges, downEdges...) {
+		if !seenEdges[e.ID] {
+			seenEdges[e.ID] = true
+			lineage.Edges = append(lineage.Edges, e)
+		}
+	}
+	return lineage, nil
 }
 
-// BatchCreateNodes creates multiple nodes in a single operation.
+// BatchCreateNodes creates multiple nodes, writing them in batches of
+// Config.BatchSize with one UNWIND query per node type. Existing nodes with
+// the same ID are updated, so batches can be replayed safely.
 func (c *Client) BatchCreateNodes(ctx context.Context, nodes []*graph.Node) error {
-	// TODO: Implement batch node creation
-	return fmt.Errorf("not implemented")
+	stmts, err := nodeStatements(nodes)
+	if err != nil {
+		return err
+	}
+	return c.runStatements(ctx, stmts)
 }
 
-// BatchCreateEdges creates multiple edges in a single operation.
+// BatchCreateEdges creates multiple edges, writing them in batches of
+// Config.BatchSize with one UNWIND query per edge type. Edges whose endpoints
+// do not exist are skipped; existing edges with the same ID are updated.
 func (c *Client) BatchCreateEdges(ctx context.Context, edges []*graph.Edge) error {
-	// TODO: Implement batch edge creation
-	return fmt.Errorf("not implemented")
+	stmts, err := edgeStatements(edges)
+	if err != nil {
+		return err
+	}
+	return c.runStatements(ctx, stmts)
+}
+
+// statement is an UNWIND query with the rows it writes.
+type statement struct {
+	query string
+	rows  []map[string]any
+}
+
+// nodeStatements returns the statements merging nodes, one per node type in
+// the order of their first node.
+func nodeStatements(nodes []*graph.Node) ([]statement, error) {
+	var stmts []statement
+	index := make(map[string]int)
+	for _, node := range nodes {
+		label, err := nodeLabel(node.Type)
+		if err != nil {
+			return nil, err
+		}
+		props, err := nodeProps(node)
+		if err != nil {
+			return nil, err
+		}
+		i, ok := index[label]
+		if !ok {
+			i = len(stmts)
+			index[label] = i
+			stmts = append(stmts, statement{
+				query: fmt.Sprintf("UNWIND $rows AS row MERGE (n:%s {id: row.id}) SET n:%s, n += row", entityLabel, label),
+			})
+		}
+		stmts[i].rows = append(stmts[i].rows, props)
+	}
+	return stmts, nil
+}
+
+// edgeStatements returns the statements merging edges between existing
+// nodes, one per edge type in the order of their first edge.
+func edgeStatements(edges []*graph.Edge) ([]statement, error) {
+	var stmts []statement
+	index := make(map[string]int)
+	for _, edge := range edges {
+		relType, err := relType(edge.Type)
+		if err != nil {
+			return nil, err
+		}
+		props, err := edgeProps(edge)
+		if err != nil {
+			return nil, err
+		}
+		i, ok := index[relType]
+		if !ok {
+			i = len(stmts)
+			index[relType] = i
+			stmts = append(stmts, statement{
+				query: fmt.Sprintf(`UNWIND $rows AS row
+MATCH (s:%[1]s {id: row.source_id}), (t:%[1]s {id: row.target_id})
+MERGE (s)-[r:%[2]s {id: row.id}]->(t) SET r += row`, entityLabel, relType),
+			})
+		}
+		stmts[i].rows = append(stmts[i].rows, props)
+	}
+	return stmts, nil
+}
+
+// upstreamQuery returns the traversal of the lineage relationships flowing
+// into the node $id, up to depth hops.
+func upstreamQuery(depth int) string {
+	return traversalQuery(fmt.Sprintf("(m:%s)-[:%s%s]->(n:%s {id: $id})", entityLabel, lineageRels, hops(depth), entityLabel))
+}
+
+// downstreamQuery returns the traversal of the lineage relationships flowing
+// out of the node $id, up to depth hops.
+func downstreamQuery(depth int) string {
+	return traversalQuery(fmt.Sprintf("(n:%s {id: $id})-[:%s%s]->(m:%s)", entityLabel, lineageRels, hops(depth), entityLabel))
+}
+
+// traversalQuery returns the query of the distinct relationships on the
+// paths matching pattern, with their start and end nodes.
+func traversalQuery(pattern string) string {
+	return fmt.Sprintf(`MATCH p = %s
+UNWIND relationships(p) AS r
+WITH DISTINCT r
+RETURN startNode(r) AS s, r, endNode(r) AS t`, pattern)
+}
+
+func (c *Client) traverse(ctx context.Context, nodeID, query string) ([]*graph.Node, []*graph.Edge, error) {
+	result, err := c.run(ctx, query, map[string]any{"id": nodeID})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes := make([]*graph.Node, 0)
+	edges := make([]*graph.Edge, 0, len(result.Records))
+	seen := map[string]bool{nodeID: true}
+	for _, record := range result.Records {
+		for _, key := range []string{"s", "t"} {
+			n, _, err := neo4j.GetRecordValue[dbtype.Node](record, key)
+			if err != nil {
+				return nil, nil, err
+			}
+			node, err := toNode(n)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !seen[node.ID] {
+				seen[node.ID] = true
+				nodes = append(nodes, node)
+			}
+		}
+		r, _, err := neo4j.GetRecordValue[dbtype.Relationship](record, "r")
+		if err != nil {
+			return nil, nil, err
+		}
+		edge, err := toEdge(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		edges = append(edges, edge)
+	}
+	return nodes, edges, nil
+}
+
+// runStatements runs each statement once per batch of Config.BatchSize rows.
+func (c *Client) runStatements(ctx context.Context, stmts []statement) error {
+	for _, stmt := range stmts {
+		for _, batch := range batches(stmt.rows, c.config.BatchSize) {
+			if _, err := c.run(ctx, stmt.query, map[string]any{"rows": batch}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// batches splits rows into batches of size rows, defaultBatchSize if size is
+// not positive.
+func batches(rows []map[string]any, size int) [][]any {
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+	var out [][]any
+	for start := 0; start < len(rows); start += size {
+		end := min(start+size, len(rows))
+		batch := make([]any, 0, end-start)
+		for _, row := range rows[start:end] {
+			batch = append(batch, row)
+		}
+		out = append(out, batch)
+	}
+	return out
+}
+
+func (c *Client) run(ctx context.Context, query string, params map[string]any) (*neo4j.EagerResult, error) {
+	c.mu.RLock()
+	driver := c.driver
+	c.mu.RUnlock()
+
+	if driver == nil {
+		return nil, graph.ErrConnectionClosed
+	}
+
+	var opts []neo4j.ExecuteQueryConfigurationOption
+	if c.config.Space != "" {
+		opts = append(opts, neo4j.ExecuteQueryWithDatabase(c.config.Space))
+	}
+	return neo4j.ExecuteQuery(ctx, driver, query, params, neo4j.EagerResultTransformer, opts...)
+}
+
+func (c *Client) uri() string {
+	if strings.Contains(c.config.Host, "://") {
+		return c.config.Host
+	}
+	port := c.config.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	return fmt.Sprintf("neo4j://%s:%d", c.config.Host, port)
+}
+
+// hops returns the variable-length suffix of a relationship pattern.
+func hops(depth int) string {
+	if depth <= 0 {
+		return "*1.."
+	}
+	return fmt.Sprintf("*1..%d", depth)
+}
+
+func nodeLabel(t graph.NodeType) (string, error) {
+	label, ok := nodeLabels[t]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", graph.ErrInvalidNodeType, t)
+	}
+	return label, nil
+}
+
+func relType(t graph.EdgeType) (string, error) {
+	rel, ok := relTypes[t]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", graph.ErrInvalidEdgeType, t)
+	}
+	return rel, nil
+}
+
+// nodeProps flattens a node into Neo4j properties. Custom properties are
+// stored as a JSON string since Neo4j does not support nested maps.
+func nodeProps(node *graph.Node) (map[string]any, error) {
+	props := map[string]any{
+		"id":       node.ID,
+		"type":     string(node.Type),
+		"name":     node.Name,
+		"database": node.Database,
+		"table":    node.Table,
+		"column":   node.Column,
+	}
+	if len(node.Properties) > 0 {
+		data, err := json.Marshal(node.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("marshal properties of node %s: %w", node.ID, err)
+		}
+		props["properties"] = string(data)
+	}
+	return props, nil
+}
+
+func edgeProps(edge *graph.Edge) (map[string]any, error) {
+	id := edge.ID
+	if id == "" {
+		id = edge.SourceID + "-" + string(edge.Type) + "->" + edge.TargetID
+	}
+	props := map[string]any{
+		"id":        id,
+		"type":      string(edge.Type),
+		"source_id": edge.SourceID,
+		"target_id": edge.TargetID,
+	}
+	if len(edge.Properties) > 0 {
+		data, err := json.Marshal(edge.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("marshal properties of edge %s: %w", id, err)
+		}
+		props["properties"] = string(data)
+	}
+	return props, nil
+}
+
+func toNode(n dbtype.Node) (*graph.Node, error) {
+	node := &graph.Node{
+		ID:       stringProp(n.Props, "id"),
+		Type:     graph.NodeType(stringProp(n.Props, "type")),
+		Name:     stringProp(n.Props, "name"),
+		Database: stringProp(n.Props, "database"),
+		Table:    stringProp(n.Props, "table"),
+		Column:   stringProp(n.Props, "column"),
+	}
+	if raw := stringProp(n.Props, "properties"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &node.Properties); err != nil {
+			return nil, fmt.Errorf("unmarshal properties of node %s: %w", node.ID, err)
+		}
+	}
+	return node, nil
+}
+
+func toEdge(r dbtype.Relationship) (*graph.Edge, error) {
+	edge := &graph.Edge{
+		ID:       stringProp(r.Props, "id"),
+		Type:     graph.EdgeType(stringProp(r.Props, "type")),
+		SourceID: stringProp(r.Props, "source_id"),
+		TargetID: stringProp(r.Props, "target_id"),
+	}
+	if raw := stringProp(r.Props, "properties"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &edge.Properties); err != nil {
+			return nil, fmt.Errorf("unmarshal properties of edge %s: %w", edge.ID, err)
+		}
+	}
+	return edge, nil
+}
+
+func stringProp(props map[string]any, key string) string {
+	s, _ := props[key].(string)
+	return s
+}
+
+func count(result *neo4j.EagerResult, key string) int64 {
+	if len(result.Records) == 0 {
+		return 0
+	}
+	n, _, _ := neo4j.GetRecordValue[int64](result.Records[0], key)
+	return n
+}
+
+func isConstraintViolation(err error) bool {
+	var neoErr *neo4j.Neo4jError
+	return errors.As(err, &neoErr) && neoErr.Code == "Neo.ClientError.Schema.ConstraintValidationFailed"
 }
 
 // Ensure Client implements graph.GraphDB interface.
@@ -0,0 +1,176 @@
+package neo4j
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+
+	"go-metadata/internal/data/graph"
+)
+
+func TestNodeStatements(t *testing.T) {
+	nodes := []*graph.Node{
+		{ID: "t1", Type: graph.NodeTypeTable, Name: "orders", Database: "shop", Table: "orders"},
+		{ID: "c1", Type: graph.NodeTypeColumn, Name: "id", Database: "shop", Table: "orders", Column: "id"},
+		{ID: "t2", Type: graph.NodeTypeTable, Name: "items", Database: "shop", Table: "items",
+			Properties: map[string]interface{}{"owner": "team-x"}},
+	}
+	stmts, err := nodeStatements(nodes)
+	if err != nil {
+		t.Fatalf("nodeStatements failed: %v", err)
+	}
+	if len(stmts) != 2 {
+		t.Fatalf("Expected a statement per node type, got %d", len(stmts))
+	}
+
+	tables, columns := stmts[0], stmts[1]
+	if want := "UNWIND $rows AS row MERGE (n:Entity {id: row.id}) SET n:Table, n += row"; tables.query != want {
+		t.Errorf("Expected query %q, got %q", want, tables.query)
+	}
+	if !strings.Contains(columns.query, "SET n:Column") {
+		t.Errorf("Expected the column label, got %q", columns.query)
+	}
+	if len(tables.rows) != 2 || len(columns.rows) != 1 {
+		t.Fatalf("Expected 2 table rows and 1 column row, got %d and %d", len(tables.rows), len(columns.rows))
+	}
+
+	row := tables.rows[0]
+	for key, want := range map[string]string{"id": "t1", "type": "table", "name": "orders", "database": "shop", "table": "orders", "column": ""} {
+		if row[key] != want {
+			t.Errorf("%s = %v, want %q", key, row[key], want)
+		}
+	}
+	if _, ok := row["properties"]; ok {
+		t.Errorf("Expected no properties for a node without any, got %v", row["properties"])
+	}
+	// Neo4j properties cannot be maps
+	if got := tables.rows[1]["properties"]; got != `{"owner":"team-x"}` {
+		t.Errorf("Expected the properties as JSON, got %v", got)
+	}
+
+	if _, err := nodeStatements([]*graph.Node{{ID: "x", Type: "schema"}}); !errors.Is(err, graph.ErrInvalidNodeType) {
+		t.Errorf("Expected ErrInvalidNodeType, got %v", err)
+	}
+}
+
+func TestEdgeStatements(t *testing.T) {
+	edges := []*graph.Edge{
+		{SourceID: "t1", TargetID: "t2", Type: graph.EdgeTypeDependsOn},
+		{ID: "e2", SourceID: "t2", TargetID: "j1", Type: graph.EdgeTypeProducedBy,
+			Properties: map[string]interface{}{"sql": "INSERT INTO t2 SELECT * FROM t1"}},
+	}
+	stmts, err := edgeStatements(edges)
+	if err != nil {
+		t.Fatalf("edgeStatements failed: %v", err)
+	}
+	if len(stmts) != 2 {
+		t.Fatalf("Expected a statement per edge type, got %d", len(stmts))
+	}
+	want := "UNWIND $rows AS row\n" +
+		"MATCH (s:Entity {id: row.source_id}), (t:Entity {id: row.target_id})\n" +
+		"MERGE (s)-[r:DEPENDS_ON {id: row.id}]->(t) SET r += row"
+	if stmts[0].query != want {
+		t.Errorf("Expected query %q, got %q", want, stmts[0].query)
+	}
+	if !strings.Contains(stmts[1].query, "[r:PRODUCED_BY {id: row.id}]") {
+		t.Errorf("Expected the PRODUCED_BY type, got %q", stmts[1].query)
+	}
+
+	// Edges without an ID get one derived from their endpoints
+	row := stmts[0].rows[0]
+	if row["id"] != "t1-depends_on->t2" || row["source_id"] != "t1" || row["target_id"] != "t2" || row["type"] != "depends_on" {
+		t.Errorf("Unexpected row %v", row)
+	}
+	if got := stmts[1].rows[0]["properties"]; got != `{"sql":"INSERT INTO t2 SELECT * FROM t1"}` {
+		t.Errorf("Expected the properties as JSON, got %v", got)
+	}
+
+	if _, err := edgeStatements([]*graph.Edge{{SourceID: "a", TargetID: "b", Type: "copies"}}); !errors.Is(err, graph.ErrInvalidEdgeType) {
+		t.Errorf("Expected ErrInvalidEdgeType, got %v", err)
+	}
+}
+
+func TestBatches(t *testing.T) {
+	rows := make([]map[string]any, 5)
+	for i := range rows {
+		rows[i] = map[string]any{"id": i}
+	}
+	got := batches(rows, 2)
+	if len(got) != 3 || len(got[0]) != 2 || len(got[2]) != 1 {
+		t.Fatalf("Expected batches of 2, 2 and 1 rows, got %v", got)
+	}
+	if got[2][0].(map[string]any)["id"] != 4 {
+		t.Errorf("Expected the last row in the last batch, got %v", got[2])
+	}
+	if got := batches(rows, 0); len(got) != 1 || len(got[0]) != 5 {
+		t.Errorf("Expected the default batch size without one, got %v", got)
+	}
+	if got := batches(nil, 2); len(got) != 0 {
+		t.Errorf("Expected no batch without rows, got %v", got)
+	}
+}
+
+func TestTraversalQueries(t *testing.T) {
+	up := upstreamQuery(3)
+	if !strings.HasPrefix(up, "MATCH p = (m:Entity)-[:DEPENDS_ON|PRODUCED_BY*1..3]->(n:Entity {id: $id})\n") {
+		t.Errorf("Expected upstream to follow incoming lineage relationships, got %q", up)
+	}
+	down := downstreamQuery(0)
+	if !strings.HasPrefix(down, "MATCH p = (n:Entity {id: $id})-[:DEPENDS_ON|PRODUCED_BY*1..]->(m:Entity)\n") {
+		t.Errorf("Expected unlimited downstream to follow outgoing lineage relationships, got %q", down)
+	}
+	if !strings.HasSuffix(down, "RETURN startNode(r) AS s, r, endNode(r) AS t") {
+		t.Errorf("Expected the relationships with their endpoints, got %q", down)
+	}
+}
+
+func TestToNodeAndEdge(t *testing.T) {
+	node := &graph.Node{ID: "t1", Type: graph.NodeTypeTable, Name: "orders", Database: "shop", Table: "orders",
+		Properties: map[string]interface{}{"owner": "team-x"}}
+	props, err := nodeProps(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := toNode(dbtype.Node{Props: props})
+	if err != nil {
+		t.Fatalf("toNode failed: %v", err)
+	}
+	if got.ID != node.ID || got.Type != node.Type || got.Table != node.Table || got.Properties["owner"] != "team-x" {
+		t.Errorf("Expected %+v, got %+v", node, got)
+	}
+
+	edge := &graph.Edge{ID: "e1", SourceID: "t1", TargetID: "t2", Type: graph.EdgeTypeDependsOn}
+	eprops, err := edgeProps(edge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotEdge, err := toEdge(dbtype.Relationship{Props: eprops})
+	if err != nil {
+		t.Fatalf("toEdge failed: %v", err)
+	}
+	if !reflect.DeepEqual(gotEdge, edge) {
+		t.Errorf("Expected %+v, got %+v", edge, gotEdge)
+	}
+
+	if _, err := toNode(dbtype.Node{Props: map[string]any{"id": "x", "properties": "{"}}); err == nil {
+		t.Error("Expected an error for malformed properties")
+	}
+}
+
+func TestURI(t *testing.T) {
+	for host, want := range map[string]string{
+		"neo4j.local":              "neo4j://neo4j.local:7687",
+		"neo4j+s://db.example.com": "neo4j+s://db.example.com",
+		"bolt://127.0.0.1:7688":    "bolt://127.0.0.1:7688",
+	} {
+		if got := NewClient(&graph.Config{Host: host}).uri(); got != want {
+			t.Errorf("uri(%q) = %q, want %q", host, got, want)
+		}
+	}
+	if got := NewClient(&graph.Config{Host: "neo4j.local", Port: 7688}).uri(); got != "neo4j://neo4j.local:7688" {
+		t.Errorf("Expected the configured port, got %q", got)
+	}
+}
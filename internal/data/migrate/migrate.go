@@ -0,0 +1,347 @@
+// Package migrate applies versioned SQL migrations to the repository database.
+//
+// Migrations follow the golang-migrate file layout: each version has an up
+// and a down file named <version>_<name>.up.sql and <version>_<name>.down.sql.
+// The applied version is tracked in a schema_migrations table holding a single
+// row (version, dirty); a migration that fails halfway leaves the version
+// dirty, and the database must be repaired and forced to a clean version
+// before migrating again.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TableName is the table recording the applied schema version.
+const TableName = "schema_migrations"
+
+var (
+	// ErrDirty is returned when a previous migration failed and left the
+	// database in an unknown state.
+	ErrDirty = errors.New("database schema is dirty")
+
+	// ErrDatabaseNewer is returned when the database was migrated by a newer
+	// release than the running one, whose migrations are unknown.
+	ErrDatabaseNewer = errors.New("database schema is newer than this release")
+
+	// ErrUnsupportedDriver is returned for database drivers without a dialect.
+	ErrUnsupportedDriver = errors.New("unsupported migration driver")
+)
+
+// Migration is a versioned schema change.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load reads the migrations in the root of fsys, sorted by version. Every
+// migration must have an up file; down files are optional.
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		m := fileName.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(m[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version: %s", entry.Name())
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[uint(version)]
+		if !ok {
+			mig = &Migration{Version: uint(version), Name: m[2]}
+			byVersion[uint(version)] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("conflicting migrations for version %d: %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(data)
+		} else {
+			mig.Down = string(data)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Migrator applies migrations to a database.
+type Migrator struct {
+	db         *sql.DB
+	dialect    dialect
+	migrations []*Migration
+}
+
+// New creates a migrator for db using the migrations in the directory of
+// fsys named after the driver, e.g. mysql/.
+func New(db *sql.DB, driver string, fsys fs.FS) (*Migrator, error) {
+	d, ok := dialects[driver]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, driver)
+	}
+	sub, err := fs.Sub(fsys, d.dir)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := Load(sub)
+	if err != nil {
+		return nil, fmt.Errorf("load %s migrations: %w", driver, err)
+	}
+	return &Migrator{db: db, dialect: d, migrations: migrations}, nil
+}
+
+// Latest returns the version of the last known migration, or 0 if there are none.
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the current schema version of the database and whether it
+// is dirty. A database that was never migrated is at version 0.
+func (m *Migrator) Version(ctx context.Context) (uint, bool, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return 0, false, err
+	}
+	var version int64
+	var dirty bool
+	err := m.db.QueryRowContext(ctx, "SELECT version, dirty FROM "+TableName).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("read schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
+
+// Up applies all pending migrations and returns the number applied. It
+// refuses to run on a dirty database or one migrated by a newer release.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	current, err := m.check(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, mig := range m.migrations {
+		if mig.Version <= current {
+			continue
+		}
+		if err := m.apply(ctx, mig.Version, mig.Up); err != nil {
+			return applied, fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// Down reverts the last steps applied migrations and returns the number reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	current, err := m.check(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	reverted := 0
+	for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+		mig := m.migrations[i]
+		if mig.Version > current {
+			continue
+		}
+		if mig.Down == "" {
+			return reverted, fmt.Errorf("migration %d_%s has no down file", mig.Version, mig.Name)
+		}
+		var previous uint
+		if i > 0 {
+			previous = m.migrations[i-1].Version
+		}
+		if err := m.apply(ctx, previous, mig.Down); err != nil {
+			return reverted, fmt.Errorf("revert migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		reverted++
+	}
+	return reverted, nil
+}
+
+// Baseline records version as applied, without running any migration, if the
+// database has no recorded version but already contains table: the schema was
+// created by hand before migrations were tracked, and re-running the initial
+// migrations would clobber it. It reports whether the baseline was recorded.
+func (m *Migrator) Baseline(ctx context.Context, version uint, table string) (bool, error) {
+	current, dirty, err := m.Version(ctx)
+	if err != nil || current != 0 || dirty {
+		return false, err
+	}
+
+	var count int
+	query := fmt.Sprintf("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = %s AND table_name = %s",
+		m.dialect.currentSchema, m.dialect.placeholder(1))
+	if err := m.db.QueryRowContext(ctx, query, table).Scan(&count); err != nil {
+		return false, fmt.Errorf("check existing schema: %w", err)
+	}
+	if count == 0 {
+		return false, nil
+	}
+	return true, m.setVersion(ctx, m.db, version, false)
+}
+
+// Force sets the schema version and clears the dirty flag without running
+// any migration, after a failed migration has been repaired by hand.
+func (m *Migrator) Force(ctx context.Context, version uint) error {
+	if err := m.ensureTable(ctx); err != nil {
+		return err
+	}
+	return m.setVersion(ctx, m.db, version, false)
+}
+
+// check returns the current version, failing if the database is dirty or newer
+// than the latest known migration.
+func (m *Migrator) check(ctx context.Context) (uint, error) {
+	current, dirty, err := m.Version(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w at version %d", ErrDirty, current)
+	}
+	if current > m.Latest() {
+		return 0, fmt.Errorf("%w: database at version %d, latest known migration is %d", ErrDatabaseNewer, current, m.Latest())
+	}
+	return current, nil
+}
+
+// apply runs the statements of a migration and records version. The version
+// is marked dirty until all statements succeed; on dialects with
+// transactional DDL the statements and the version update commit together.
+func (m *Migrator) apply(ctx context.Context, version uint, script string) error {
+	if err := m.setVersion(ctx, m.db, version, true); err != nil {
+		return err
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range splitStatements(script) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := m.setVersion(ctx, tx, version, false); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (m *Migrator) setVersion(ctx context.Context, db execer, version uint, dirty bool) error {
+	if _, err := db.ExecContext(ctx, "DELETE FROM "+TableName); err != nil {
+		return fmt.Errorf("set schema version: %w", err)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (version, dirty) VALUES (%s, %s)", TableName, m.dialect.placeholder(1), m.dialect.placeholder(2))
+	if _, err := db.ExecContext(ctx, query, int64(version), dirty); err != nil {
+		return fmt.Errorf("set schema version: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+TableName+" (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("create %s: %w", TableName, err)
+	}
+	return nil
+}
+
+// splitStatements splits a migration script into statements terminated by a
+// semicolon at the end of a line, dropping comment-only lines, so scripts run
+// without enabling multi-statement support in the driver.
+func splitStatements(script string) []string {
+	var stmts []string
+	var b strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSuffix(strings.TrimSpace(b.String()), ";"))
+			b.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(b.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
+
+// dialect holds the per-database differences of the migrator. MySQL holds
+// the repository schema; PostgreSQL or SQLite hold the harvested metadata
+// store (see internal/store). SQLite has no information_schema, so Baseline
+// does not apply to it.
+type dialect struct {
+	dir           string
+	currentSchema string
+	placeholder   func(n int) string
+}
+
+var dialects = map[string]dialect{
+	"mysql": {
+		dir:           "mysql",
+		currentSchema: "DATABASE()",
+		placeholder:   func(int) string { return "?" },
+	},
+	"postgres": {
+		dir:           "postgres",
+		currentSchema: "current_schema()",
+		placeholder:   func(n int) string { return fmt.Sprintf("$%d", n) },
+	},
+	"sqlite": {
+		dir:           "sqlite",
+		currentSchema: "'main'",
+		placeholder:   func(int) string { return "?" },
+	},
+}
@@ -0,0 +1,253 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var testMigrations = fstest.MapFS{
+	"mysql/0001_init.up.sql":   {Data: []byte("-- initial schema\nCREATE TABLE a (id INT);\n")},
+	"mysql/0001_init.down.sql": {Data: []byte("DROP TABLE a;\n")},
+	"mysql/0002_b.up.sql":      {Data: []byte("CREATE TABLE b (id INT);\nCREATE INDEX idx_b ON b (id);\n")},
+	"mysql/0002_b.down.sql":    {Data: []byte("DROP TABLE b;\n")},
+	"mysql/0003_c.up.sql":      {Data: []byte("CREATE TABLE c (id INT);\n")},
+	"mysql/0003_c.down.sql":    {Data: []byte("DROP TABLE c;\n")},
+	"mysql/README.md":          {Data: []byte("not a migration")},
+	"postgres/0001_a.up.sql":   {Data: []byte("CREATE TABLE a (id INT);\n")},
+	"postgres/0002_b.down.sql": {Data: []byte("DROP TABLE b;\n")},
+}
+
+func newTestMigrator(t *testing.T) (*Migrator, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	m, err := New(db, "mysql", testMigrations)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return m, mock
+}
+
+func expectVersion(mock sqlmock.Sqlmock, version int64, dirty bool) {
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS " + TableName)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, dirty FROM " + TableName)).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(version, dirty))
+}
+
+func expectSetVersion(mock sqlmock.Sqlmock, version int64, dirty bool) {
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM " + TableName)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO "+TableName+" (version, dirty) VALUES (?, ?)")).
+		WithArgs(version, dirty).WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// expectApply expects a migration to mark version dirty, run stmts in a
+// transaction and record version as clean.
+func expectApply(mock sqlmock.Sqlmock, version int64, stmts ...string) {
+	expectSetVersion(mock, version, true)
+	mock.ExpectBegin()
+	for _, stmt := range stmts {
+		mock.ExpectExec("^" + regexp.QuoteMeta(stmt) + "$").WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	expectSetVersion(mock, version, false)
+	mock.ExpectCommit()
+}
+
+func TestLoad(t *testing.T) {
+	m, _ := newTestMigrator(t)
+	if len(m.migrations) != 3 {
+		t.Fatalf("Expected 3 migrations, got %d", len(m.migrations))
+	}
+	for i, mig := range m.migrations {
+		if mig.Version != uint(i+1) {
+			t.Errorf("Expected migration %d to have version %d, got %d", i, i+1, mig.Version)
+		}
+	}
+	if m.Latest() != 3 {
+		t.Errorf("Expected latest version 3, got %d", m.Latest())
+	}
+
+	// The postgres directory has a version without an up file.
+	if _, err := New(nil, "postgres", testMigrations); err == nil {
+		t.Error("Expected an error for a migration without an up file")
+	}
+	if _, err := New(nil, "oracle", testMigrations); !errors.Is(err, ErrUnsupportedDriver) {
+		t.Errorf("Expected ErrUnsupportedDriver, got %v", err)
+	}
+}
+
+func TestUpAppliesPendingInOrder(t *testing.T) {
+	m, mock := newTestMigrator(t)
+	expectVersion(mock, 1, false)
+	expectApply(mock, 2, "CREATE TABLE b (id INT)", "CREATE INDEX idx_b ON b (id)")
+	expectApply(mock, 3, "CREATE TABLE c (id INT)")
+
+	applied, err := m.Up(context.Background())
+	if err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if applied != 2 {
+		t.Errorf("Expected 2 migrations applied, got %d", applied)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpFromEmptyDatabase(t *testing.T) {
+	m, mock := newTestMigrator(t)
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS " + TableName)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, dirty FROM " + TableName)).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}))
+	expectApply(mock, 1, "CREATE TABLE a (id INT)")
+	expectApply(mock, 2, "CREATE TABLE b (id INT)", "CREATE INDEX idx_b ON b (id)")
+	expectApply(mock, 3, "CREATE TABLE c (id INT)")
+
+	applied, err := m.Up(context.Background())
+	if err != nil || applied != 3 {
+		t.Fatalf("Expected 3 migrations applied, got %d, %v", applied, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpUpToDate(t *testing.T) {
+	m, mock := newTestMigrator(t)
+	expectVersion(mock, 3, false)
+
+	applied, err := m.Up(context.Background())
+	if err != nil || applied != 0 {
+		t.Fatalf("Expected no migrations applied, got %d, %v", applied, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDownRevertsInReverseOrder(t *testing.T) {
+	m, mock := newTestMigrator(t)
+	expectVersion(mock, 3, false)
+	expectApply(mock, 2, "DROP TABLE c")
+	expectApply(mock, 1, "DROP TABLE b")
+
+	reverted, err := m.Down(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+	if reverted != 2 {
+		t.Errorf("Expected 2 migrations reverted, got %d", reverted)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDownToEmpty(t *testing.T) {
+	m, mock := newTestMigrator(t)
+	expectVersion(mock, 1, false)
+	expectApply(mock, 0, "DROP TABLE a")
+
+	reverted, err := m.Down(context.Background(), 5)
+	if err != nil || reverted != 1 {
+		t.Fatalf("Expected 1 migration reverted, got %d, %v", reverted, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRefusesNewerDatabase(t *testing.T) {
+	for name, run := range map[string]func(*Migrator) (int, error){
+		"up":   func(m *Migrator) (int, error) { return m.Up(context.Background()) },
+		"down": func(m *Migrator) (int, error) { return m.Down(context.Background(), 1) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			m, mock := newTestMigrator(t)
+			expectVersion(mock, 4, false)
+
+			if _, err := run(m); !errors.Is(err, ErrDatabaseNewer) {
+				t.Fatalf("Expected ErrDatabaseNewer, got %v", err)
+			}
+			// Nothing may run against a schema this release does not know.
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestRefusesDirtyDatabase(t *testing.T) {
+	for name, run := range map[string]func(*Migrator) (int, error){
+		"up":   func(m *Migrator) (int, error) { return m.Up(context.Background()) },
+		"down": func(m *Migrator) (int, error) { return m.Down(context.Background(), 1) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			m, mock := newTestMigrator(t)
+			expectVersion(mock, 2, true)
+
+			if _, err := run(m); !errors.Is(err, ErrDirty) {
+				t.Fatalf("Expected ErrDirty, got %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestFailedMigrationLeavesVersionDirty(t *testing.T) {
+	m, mock := newTestMigrator(t)
+	expectVersion(mock, 1, false)
+	expectSetVersion(mock, 2, true)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE b (id INT)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE INDEX idx_b ON b (id)")).WillReturnError(errors.New("duplicate index"))
+	mock.ExpectRollback()
+
+	applied, err := m.Up(context.Background())
+	if err == nil {
+		t.Fatal("Expected the failing migration to return an error")
+	}
+	if applied != 0 {
+		t.Errorf("Expected no migrations applied, got %d", applied)
+	}
+	// The clean version is never recorded, so the next run sees version 2 dirty.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestForceClearsDirty(t *testing.T) {
+	m, mock := newTestMigrator(t)
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS " + TableName)).WillReturnResult(sqlmock.NewResult(0, 0))
+	expectSetVersion(mock, 1, false)
+
+	if err := m.Force(context.Background(), 1); err != nil {
+		t.Fatalf("Force failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := "-- comment\nCREATE TABLE a (\n  id INT\n);\n\nINSERT INTO a VALUES (1);\nSELECT 1"
+	want := []string{"CREATE TABLE a (\n  id INT\n)", "INSERT INTO a VALUES (1)", "SELECT 1"}
+	got := splitStatements(script)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d statements, got %d: %q", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Statement %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
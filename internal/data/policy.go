@@ -0,0 +1,126 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"go-metadata/internal/biz"
+	"go-metadata/internal/store"
+)
+
+func (r *policyRepo) GetPolicies(ctx context.Context) (*biz.PolicySet, error) {
+	st := r.data.store
+	if st == nil {
+		return nil, errNoStore
+	}
+	document, err := st.Policies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	set := &biz.PolicySet{}
+	if document == nil {
+		return set, nil
+	}
+	if err := json.Unmarshal(document, set); err != nil {
+		return nil, fmt.Errorf("decode policies: %w", err)
+	}
+	return set, nil
+}
+
+func (r *policyRepo) SavePolicies(ctx context.Context, set *biz.PolicySet) error {
+	st := r.data.store
+	if st == nil {
+		return errNoStore
+	}
+	document, err := json.Marshal(set)
+	if err != nil {
+		return err
+	}
+	return st.SavePolicies(ctx, document)
+}
+
+func (r *policyRepo) ListViolations(ctx context.Context, tableID string) ([]*biz.PolicyViolation, error) {
+	st := r.data.store
+	if st == nil {
+		return nil, errNoStore
+	}
+	var id int64
+	if tableID != "" {
+		var err error
+		if id, err = strconv.ParseInt(tableID, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: %s", biz.ErrTableNotFound, tableID)
+		}
+	}
+	records, err := st.PolicyViolations(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	violations := make([]*biz.PolicyViolation, 0, len(records))
+	for i := range records {
+		violations = append(violations, bizViolation(&records[i]))
+	}
+	return violations, nil
+}
+
+func (r *policyRepo) SaveViolations(ctx context.Context, tableID string, violations []*biz.PolicyViolation, events []*biz.PolicyEvent) error {
+	st := r.data.store
+	if st == nil {
+		return errNoStore
+	}
+	id, err := strconv.ParseInt(tableID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %s", biz.ErrTableNotFound, tableID)
+	}
+	records := make([]store.PolicyViolation, 0, len(violations))
+	for _, v := range violations {
+		records = append(records, storeViolation(id, v))
+	}
+	eventRecords := make([]store.PolicyEvent, 0, len(events))
+	for _, e := range events {
+		eventRecords = append(eventRecords, store.PolicyEvent{Type: e.Type, PolicyViolation: storeViolation(id, e.Violation), At: e.At})
+	}
+	return st.SavePolicyViolations(ctx, id, records, eventRecords)
+}
+
+func (r *policyRepo) ListPolicyEvents(ctx context.Context, limit int) ([]*biz.PolicyEvent, error) {
+	st := r.data.store
+	if st == nil {
+		return nil, errNoStore
+	}
+	records, err := st.PolicyEvents(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*biz.PolicyEvent, 0, len(records))
+	for i := range records {
+		e := &records[i]
+		events = append(events, &biz.PolicyEvent{Type: e.Type, Violation: bizViolation(&e.PolicyViolation), At: e.At})
+	}
+	return events, nil
+}
+
+func storeViolation(tableID int64, v *biz.PolicyViolation) store.PolicyViolation {
+	return store.PolicyViolation{
+		TableID:    tableID,
+		Table:      v.Table,
+		Policy:     v.Policy,
+		Severity:   v.Severity,
+		Column:     v.Column,
+		Message:    v.Message,
+		DetectedAt: v.DetectedAt,
+	}
+}
+
+func bizViolation(v *store.PolicyViolation) *biz.PolicyViolation {
+	return &biz.PolicyViolation{
+		Policy:     v.Policy,
+		Severity:   v.Severity,
+		TableID:    strconv.FormatInt(v.TableID, 10),
+		Table:      v.Table,
+		Column:     v.Column,
+		Message:    v.Message,
+		DetectedAt: v.DetectedAt,
+	}
+}
@@ -0,0 +1,276 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-metadata/internal/biz"
+	"go-metadata/internal/store"
+)
+
+// errNoStore is returned by the repos kept in the metadata store when none
+// is configured.
+var errNoStore = errors.New("metadata store not configured")
+
+// Get returns the catalog entry of a table. A table found in several sources
+// is read from the first one, in source order.
+func (r *tableRepo) Get(ctx context.Context, database, schema, name string) (*biz.TableMetadata, error) {
+	st := r.data.store
+	if st == nil {
+		return nil, errNoStore
+	}
+	keys, err := st.FindTables(ctx, database, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if schema != "" && (!strings.EqualFold(key.Catalog, database) || !strings.EqualFold(key.Schema, schema)) {
+			continue
+		}
+		entry, err := st.CatalogTable(ctx, key)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return decodeTable(entry)
+	}
+	return nil, fmt.Errorf("%w: %s", biz.ErrTableNotFound, tableName(database, schema, name))
+}
+
+func (r *tableRepo) GetFromSource(ctx context.Context, source, database, schema, name string) (*biz.TableMetadata, error) {
+	st := r.data.store
+	if st == nil {
+		return nil, errNoStore
+	}
+	key := store.TableKey{Source: source, Catalog: database, Schema: schema, Table: name}
+	entry, err := st.CatalogTable(ctx, key)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", biz.ErrTableNotFound, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeTable(entry)
+}
+
+func (r *tableRepo) Save(ctx context.Context, t *biz.TableMetadata) (*biz.TableMetadata, error) {
+	st := r.data.store
+	if st == nil {
+		return nil, errNoStore
+	}
+	document, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	entry := &store.CatalogTable{TableKey: tableKey(t), Version: t.Version, Document: document, Annotations: tableAnnotations(t)}
+	err = st.SaveCatalogTable(ctx, entry)
+	switch {
+	case errors.Is(err, store.ErrVersionConflict):
+		return nil, fmt.Errorf("%w: %s", biz.ErrVersionConflict, entry.TableKey)
+	case errors.Is(err, store.ErrNotFound):
+		return nil, fmt.Errorf("%w: %s", biz.ErrTableNotFound, entry.TableKey)
+	case err != nil:
+		return nil, err
+	}
+	t.ID = strconv.FormatInt(entry.ID, 10)
+	t.Version = entry.Version
+	return t, nil
+}
+
+func (r *tableRepo) List(ctx context.Context) ([]*biz.TableMetadata, error) {
+	st := r.data.store
+	if st == nil {
+		return nil, errNoStore
+	}
+	entries, err := st.CatalogTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]*biz.TableMetadata, 0, len(entries))
+	for i := range entries {
+		t, err := decodeTable(&entries[i])
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
+
+func (r *tableRepo) SaveConflicts(ctx context.Context, conflicts []*biz.MetadataConflict) error {
+	st := r.data.store
+	if st == nil {
+		return errNoStore
+	}
+	records := make([]store.MetadataConflict, 0, len(conflicts))
+	for _, c := range conflicts {
+		id, err := strconv.ParseInt(c.TableID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %s", biz.ErrTableNotFound, c.TableID)
+		}
+		records = append(records, store.MetadataConflict{
+			TableID:             id,
+			Field:               c.Field,
+			UserValue:           c.UserValue,
+			EditedBy:            c.EditedBy,
+			PreviousSourceValue: c.PreviousSourceValue,
+			SourceValue:         c.SourceValue,
+			Resolution:          c.Resolution,
+			DetectedAt:          c.DetectedAt,
+		})
+	}
+	return st.SaveConflicts(ctx, records)
+}
+
+func (r *tableRepo) ListConflicts(ctx context.Context, tableID string) ([]*biz.MetadataConflict, error) {
+	st := r.data.store
+	if st == nil {
+		return nil, errNoStore
+	}
+	id, err := strconv.ParseInt(tableID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", biz.ErrTableNotFound, tableID)
+	}
+	records, err := st.Conflicts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	conflicts := make([]*biz.MetadataConflict, 0, len(records))
+	for _, c := range records {
+		conflicts = append(conflicts, &biz.MetadataConflict{
+			TableID:             tableID,
+			Field:               c.Field,
+			UserValue:           c.UserValue,
+			EditedBy:            c.EditedBy,
+			PreviousSourceValue: c.PreviousSourceValue,
+			SourceValue:         c.SourceValue,
+			Resolution:          c.Resolution,
+			DetectedAt:          c.DetectedAt,
+		})
+	}
+	return conflicts, nil
+}
+
+func (r *tableRepo) SaveSizeSnapshot(ctx context.Context, s *biz.SizeSnapshot) error {
+	st := r.data.store
+	if st == nil {
+		return errNoStore
+	}
+	id, err := strconv.ParseInt(s.TableID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %s", biz.ErrTableNotFound, s.TableID)
+	}
+	return st.SaveSizeSnapshot(ctx, &store.SizeSnapshot{
+		TableKey:    store.TableKey{Source: s.Source, Catalog: s.Database, Schema: s.Schema, Table: s.Table},
+		TableID:     id,
+		RowCount:    s.RowCount,
+		DataSize:    s.DataSize,
+		CollectedAt: s.CollectedAt,
+	})
+}
+
+func (r *tableRepo) ListSizeSnapshots(ctx context.Context, until time.Time) ([]*biz.SizeSnapshot, error) {
+	st := r.data.store
+	if st == nil {
+		return nil, errNoStore
+	}
+	records, err := st.SizeSnapshots(ctx, until)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]*biz.SizeSnapshot, 0, len(records))
+	for _, z := range records {
+		snapshots = append(snapshots, &biz.SizeSnapshot{
+			TableID:     strconv.FormatInt(z.TableID, 10),
+			Source:      z.Source,
+			Database:    z.Catalog,
+			Schema:      z.Schema,
+			Table:       z.Table,
+			RowCount:    z.RowCount,
+			DataSize:    z.DataSize,
+			CollectedAt: z.CollectedAt,
+		})
+	}
+	return snapshots, nil
+}
+
+func (r *tableRepo) SearchAnnotations(ctx context.Context, q biz.AnnotationQuery) ([]*biz.AnnotationMatch, error) {
+	st := r.data.store
+	if st == nil {
+		return nil, errNoStore
+	}
+	key, prefix := strings.CutSuffix(q.Key, "*")
+	found, err := st.SearchAnnotations(ctx, key, prefix, q.Value)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]*biz.AnnotationMatch, 0, len(found))
+	for _, m := range found {
+		matches = append(matches, &biz.AnnotationMatch{
+			TableID:  strconv.FormatInt(m.TableID, 10),
+			Database: m.Catalog,
+			Schema:   m.Schema,
+			Table:    m.Table,
+			Column:   m.Column,
+			Key:      m.Key,
+			Value:    m.Value,
+		})
+	}
+	return matches, nil
+}
+
+// tableKey returns the store key of a catalog table: its database is the
+// catalog of the source.
+func tableKey(t *biz.TableMetadata) store.TableKey {
+	return store.TableKey{Source: t.Source, Catalog: t.Database, Schema: t.Schema, Table: t.Name}
+}
+
+// tableAnnotations returns the table and column annotations of t, sorted by
+// column and key.
+func tableAnnotations(t *biz.TableMetadata) []store.Annotation {
+	var annotations []store.Annotation
+	add := func(column string, set map[string]string) {
+		for key, value := range set {
+			annotations = append(annotations, store.Annotation{Column: column, Key: key, Value: value})
+		}
+	}
+	add("", t.Annotations)
+	for _, c := range t.Columns {
+		add(c.Name, c.Annotations)
+	}
+	sort.Slice(annotations, func(i, j int) bool {
+		if annotations[i].Column != annotations[j].Column {
+			return annotations[i].Column < annotations[j].Column
+		}
+		return annotations[i].Key < annotations[j].Key
+	})
+	return annotations
+}
+
+// decodeTable decodes a catalog entry, taking its id, version and name from
+// the stored table.
+func decodeTable(entry *store.CatalogTable) (*biz.TableMetadata, error) {
+	var t biz.TableMetadata
+	if err := json.Unmarshal(entry.Document, &t); err != nil {
+		return nil, fmt.Errorf("decode catalog table %s: %w", entry.TableKey, err)
+	}
+	t.ID = strconv.FormatInt(entry.ID, 10)
+	t.Version = entry.Version
+	t.Source, t.Database, t.Schema, t.Name = entry.Source, entry.Catalog, entry.Schema, entry.Table
+	return &t, nil
+}
+
+func tableName(database, schema, name string) string {
+	if schema == "" {
+		return database + "." + name
+	}
+	return database + "." + schema + "." + name
+}
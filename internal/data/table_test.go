@@ -0,0 +1,650 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"go-metadata/internal/biz"
+	"go-metadata/internal/store"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// memStore keeps the catalog of a metadata store in memory, with the
+// semantics of the SQL store. The other methods of store.Repository are not
+// implemented.
+type memStore struct {
+	store.Repository
+	tables     []store.TableKey
+	catalog    map[store.TableKey]*store.CatalogTable
+	conflicts  []store.MetadataConflict
+	policies   json.RawMessage
+	violations map[int64][]store.PolicyViolation
+	events     []store.PolicyEvent
+	snapshots  []store.SizeSnapshot
+}
+
+func newMemStore(tables ...store.TableKey) *memStore {
+	return &memStore{
+		tables:     tables,
+		catalog:    make(map[store.TableKey]*store.CatalogTable),
+		violations: make(map[int64][]store.PolicyViolation),
+	}
+}
+
+func (s *memStore) tableID(key store.TableKey) (int64, error) {
+	for i, k := range s.tables {
+		if k == key {
+			return int64(i + 1), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", store.ErrNotFound, key)
+}
+
+func (s *memStore) FindTables(ctx context.Context, database, table string) ([]store.TableKey, error) {
+	var keys []store.TableKey
+	for _, k := range s.tables {
+		if strings.EqualFold(k.Table, table) &&
+			(database == "" || strings.EqualFold(k.Schema, database) || strings.EqualFold(k.Catalog, database)) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	return keys, nil
+}
+
+func (s *memStore) CatalogTable(ctx context.Context, key store.TableKey) (*store.CatalogTable, error) {
+	t, ok := s.catalog[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", store.ErrNotFound, key)
+	}
+	copied := *t
+	return &copied, nil
+}
+
+func (s *memStore) CatalogTables(ctx context.Context) ([]store.CatalogTable, error) {
+	var tables []store.CatalogTable
+	for _, t := range s.catalog {
+		tables = append(tables, *t)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].TableKey.String() < tables[j].TableKey.String() })
+	return tables, nil
+}
+
+func (s *memStore) SaveCatalogTable(ctx context.Context, t *store.CatalogTable) error {
+	id, err := s.tableID(t.TableKey)
+	if err != nil {
+		return err
+	}
+	stored, ok := s.catalog[t.TableKey]
+	if ok != (t.Version != 0) || ok && stored.Version != t.Version {
+		return fmt.Errorf("%w: %s", store.ErrVersionConflict, t.TableKey)
+	}
+	t.ID, t.Version, t.UpdatedAt = id, t.Version+1, time.Now()
+	copied := *t
+	s.catalog[t.TableKey] = &copied
+	return nil
+}
+
+func (s *memStore) SearchAnnotations(ctx context.Context, key string, prefix bool, value string) ([]store.AnnotationMatch, error) {
+	var matches []store.AnnotationMatch
+	for _, t := range s.catalog {
+		for _, a := range t.Annotations {
+			if (a.Key == key || prefix && strings.HasPrefix(a.Key, key)) && (value == "" || a.Value == value) {
+				matches = append(matches, store.AnnotationMatch{TableKey: t.TableKey, TableID: t.ID, Annotation: a})
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.TableKey != b.TableKey {
+			return a.TableKey.String() < b.TableKey.String()
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return a.Key < b.Key
+	})
+	return matches, nil
+}
+
+func (s *memStore) SaveConflicts(ctx context.Context, conflicts []store.MetadataConflict) error {
+	s.conflicts = append(s.conflicts, conflicts...)
+	return nil
+}
+
+func (s *memStore) Conflicts(ctx context.Context, tableID int64) ([]store.MetadataConflict, error) {
+	var conflicts []store.MetadataConflict
+	for i := len(s.conflicts) - 1; i >= 0; i-- {
+		if s.conflicts[i].TableID == tableID {
+			conflicts = append(conflicts, s.conflicts[i])
+		}
+	}
+	return conflicts, nil
+}
+
+func (s *memStore) SaveSizeSnapshot(ctx context.Context, snapshot *store.SizeSnapshot) error {
+	s.snapshots = append(s.snapshots, *snapshot)
+	return nil
+}
+
+func (s *memStore) SizeSnapshots(ctx context.Context, until time.Time) ([]store.SizeSnapshot, error) {
+	var snapshots []store.SizeSnapshot
+	for _, z := range s.snapshots {
+		if !z.CollectedAt.After(until) {
+			snapshots = append(snapshots, z)
+		}
+	}
+	return snapshots, nil
+}
+
+func (s *memStore) Policies(ctx context.Context) (json.RawMessage, error) {
+	return s.policies, nil
+}
+
+func (s *memStore) SavePolicies(ctx context.Context, document json.RawMessage) error {
+	s.policies = document
+	return nil
+}
+
+func (s *memStore) PolicyViolations(ctx context.Context, tableID int64) ([]store.PolicyViolation, error) {
+	var violations []store.PolicyViolation
+	for id, v := range s.violations {
+		if tableID == 0 || id == tableID {
+			violations = append(violations, v...)
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		if a.Policy != b.Policy {
+			return a.Policy < b.Policy
+		}
+		return a.Column < b.Column
+	})
+	return violations, nil
+}
+
+func (s *memStore) SavePolicyViolations(ctx context.Context, tableID int64, violations []store.PolicyViolation, events []store.PolicyEvent) error {
+	s.violations[tableID] = violations
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *memStore) PolicyEvents(ctx context.Context, limit int) ([]store.PolicyEvent, error) {
+	var events []store.PolicyEvent
+	for i := len(s.events) - 1; i >= 0 && len(events) < limit; i-- {
+		events = append(events, s.events[i])
+	}
+	return events, nil
+}
+
+var (
+	ordersKey  = store.TableKey{Source: "mysql_prod", Catalog: "def", Schema: "shop", Table: "orders"}
+	usersKey   = store.TableKey{Source: "mysql_prod", Catalog: "def", Schema: "shop", Table: "users"}
+	replicaKey = store.TableKey{Source: "mysql_replica", Catalog: "def", Schema: "shop", Table: "orders"}
+)
+
+func syncedTable(key store.TableKey, comment string) *biz.TableMetadata {
+	return &biz.TableMetadata{
+		Source:   key.Source,
+		Database: key.Catalog,
+		Schema:   key.Schema,
+		Name:     key.Table,
+		Comment:  comment,
+		Columns: []*biz.ColumnMetadata{
+			{Name: "id", Type: "bigint", IsPrimaryKey: true, Position: 1},
+			{Name: "email", Type: "varchar(255)", Position: 2},
+		},
+		CollectedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func newTestTables(st store.Repository) *biz.TableUsecase {
+	logger := log.NewStdLogger(io.Discard)
+	d := &Data{store: st, log: log.NewHelper(logger)}
+	return biz.NewTableUsecase(NewTableRepo(d, logger), NewPolicyRepo(d, logger), logger)
+}
+
+func TestTableRepo(t *testing.T) {
+	ctx := context.Background()
+	st := newMemStore(ordersKey, usersKey)
+	repo := NewTableRepo(&Data{store: st}, log.NewStdLogger(io.Discard))
+
+	if _, err := repo.Get(ctx, "shop", "", "orders"); !errors.Is(err, biz.ErrTableNotFound) {
+		t.Fatalf("Expected ErrTableNotFound before the first sync, got %v", err)
+	}
+	saved, err := repo.Save(ctx, syncedTable(ordersKey, "orders"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.ID != "1" || saved.Version != 1 {
+		t.Errorf("id = %s, version = %d", saved.ID, saved.Version)
+	}
+
+	// Tables are found by database.table, the database being the schema or
+	// the catalog, and by catalog.schema.table
+	for _, name := range [][3]string{{"shop", "", "orders"}, {"def", "", "ORDERS"}, {"def", "shop", "orders"}} {
+		got, err := repo.Get(ctx, name[0], name[1], name[2])
+		if err != nil {
+			t.Fatalf("Get(%q) failed: %v", name, err)
+		}
+		if got.ID != "1" || got.Version != 1 || got.Database != "def" || got.Schema != "shop" || got.Comment != "orders" || len(got.Columns) != 2 {
+			t.Errorf("Get(%q) = %+v", name, got)
+		}
+	}
+	if _, err := repo.Get(ctx, "other", "shop", "orders"); !errors.Is(err, biz.ErrTableNotFound) {
+		t.Errorf("Expected ErrTableNotFound for another catalog, got %v", err)
+	}
+
+	stale := syncedTable(ordersKey, "stale")
+	stale.Version = 0
+	if _, err := repo.Save(ctx, stale); !errors.Is(err, biz.ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict creating an existing table, got %v", err)
+	}
+	if _, err := repo.Save(ctx, syncedTable(store.TableKey{Source: "pg", Catalog: "db", Table: "t"}, "")); !errors.Is(err, biz.ErrTableNotFound) {
+		t.Errorf("Expected ErrTableNotFound for a table not in the store, got %v", err)
+	}
+
+	if _, err := NewTableRepo(&Data{}, log.NewStdLogger(io.Discard)).List(ctx); !errors.Is(err, errNoStore) {
+		t.Errorf("Expected errNoStore, got %v", err)
+	}
+}
+
+func TestTableRepoSyncConflicts(t *testing.T) {
+	ctx := context.Background()
+	st := newMemStore(ordersKey, usersKey, replicaKey)
+	uc := newTestTables(st)
+
+	if _, err := uc.UpsertSynced(ctx, syncedTable(usersKey, "users")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.UpsertSynced(ctx, syncedTable(ordersKey, "orders")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.EditField(ctx, "shop", "", "orders", biz.ColumnCommentField("email"), "Billing email", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	synced := syncedTable(ordersKey, "orders")
+	synced.Columns[1].Comment = "email"
+	result, err := uc.UpsertSynced(ctx, synced)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Conflicts) != 1 || result.Table.Version != 3 {
+		t.Fatalf("conflicts = %v, version = %d", result.Conflicts, result.Table.Version)
+	}
+
+	conflicts, err := uc.ListConflicts(ctx, result.Table.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := biz.MetadataConflict{
+		TableID: "1", Field: biz.ColumnCommentField("email"), UserValue: "Billing email", EditedBy: "alice",
+		SourceValue: "email", Resolution: biz.ConflictKeptUserValue, DetectedAt: result.Conflicts[0].DetectedAt,
+	}
+	if len(conflicts) != 1 || *conflicts[0] != want {
+		t.Fatalf("conflicts = %v, want %+v", conflicts, want)
+	}
+
+	// The same table of another source is another entry
+	replica, err := uc.UpsertSynced(ctx, syncedTable(replicaKey, "orders"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !replica.Created || replica.Table.ID != "3" || len(replica.Conflicts) != 0 {
+		t.Errorf("Unexpected replica result %+v", replica)
+	}
+
+	tables, err := uc.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 3 || tables[0].Name != "orders" || tables[0].Columns[1].Comment != "Billing email" ||
+		tables[1].Name != "users" || tables[2].Source != "mysql_replica" || tables[2].Columns[1].Comment != "" {
+		t.Errorf("Unexpected tables %+v", tables)
+	}
+}
+
+func TestTableRepoDescriptions(t *testing.T) {
+	ctx := context.Background()
+	st := newMemStore(ordersKey)
+	if _, err := newTestTables(st).UpsertSynced(ctx, syncedTable(ordersKey, "orders")); err != nil {
+		t.Fatal(err)
+	}
+	uc := newTestTables(st)
+	if _, err := uc.SetDescription(ctx, "shop", "", "orders", "", "# Orders\n\nOne row per order.", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.SetDescription(ctx, "def", "shop", "orders", "email", "Billing *email*", "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A sync reporting comments keeps the descriptions
+	synced := syncedTable(ordersKey, "orders v2")
+	synced.Columns[1].Comment = "email"
+	if _, err := newTestTables(st).UpsertSynced(ctx, synced); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := newTestTables(st).Get(ctx, "shop", "", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Description != "# Orders\n\nOne row per order." || got.Columns[1].Description != "Billing *email*" {
+		t.Errorf("descriptions = %q, %q", got.Description, got.Columns[1].Description)
+	}
+	if got.Comment != "orders v2" || got.Columns[1].Comment != "email" || got.Version != 4 {
+		t.Errorf("comment = %q, column comment = %q, version = %d", got.Comment, got.Columns[1].Comment, got.Version)
+	}
+	if _, err := uc.SetDescription(ctx, "shop", "", "orders", "missing", "x", "alice"); !errors.Is(err, biz.ErrColumnNotFound) {
+		t.Errorf("Expected ErrColumnNotFound, got %v", err)
+	}
+}
+
+func TestTableRepoAnnotations(t *testing.T) {
+	ctx := context.Background()
+	st := newMemStore(ordersKey, usersKey)
+	for _, key := range []store.TableKey{ordersKey, usersKey} {
+		if _, err := newTestTables(st).UpsertSynced(ctx, syncedTable(key, key.Table)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	uc := newTestTables(st)
+	if _, err := uc.SetAnnotations(ctx, "shop", "", "orders", "", map[string]string{"dq.check": "enabled", "finance.domain": "sales"}, nil, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.SetAnnotations(ctx, "shop", "", "orders", "email", map[string]string{"dq.check": "disabled", "privacy.pii": "email"}, nil, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.SetAnnotations(ctx, "shop", "", "users", "", map[string]string{"dq.check": "enabled"}, nil, "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A sync keeps the annotations, in the document and in the index
+	if _, err := newTestTables(st).UpsertSynced(ctx, syncedTable(ordersKey, "orders v2")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := newTestTables(st).Get(ctx, "shop", "", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Annotations["finance.domain"] != "sales" || got.Columns[1].Annotations["privacy.pii"] != "email" {
+		t.Errorf("annotations = %v, column annotations = %v", got.Annotations, got.Columns[1].Annotations)
+	}
+
+	tests := []struct {
+		query biz.AnnotationQuery
+		want  []string
+	}{
+		{biz.AnnotationQuery{Key: "dq.check"}, []string{"orders..dq.check=enabled", "orders.email.dq.check=disabled", "users..dq.check=enabled"}},
+		{biz.AnnotationQuery{Key: "dq.check", Value: "enabled"}, []string{"orders..dq.check=enabled", "users..dq.check=enabled"}},
+		{biz.AnnotationQuery{Key: "finance.*"}, []string{"orders..finance.domain=sales"}},
+		{biz.AnnotationQuery{Key: "privacy.pii"}, []string{"orders.email.privacy.pii=email"}},
+		{biz.AnnotationQuery{Key: "dq"}, nil},
+	}
+	for _, tt := range tests {
+		matches, err := uc.SearchAnnotations(ctx, tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found []string
+		for _, m := range matches {
+			if m.Database != "def" || m.Schema != "shop" || m.TableID == "" {
+				t.Errorf("Unexpected match %+v", m)
+			}
+			found = append(found, m.Table+"."+m.Column+"."+m.Key+"="+m.Value)
+		}
+		if strings.Join(found, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("SearchAnnotations(%+v) = %v, want %v", tt.query, found, tt.want)
+		}
+	}
+
+	// Removed annotations are no longer found
+	if _, err := uc.SetAnnotations(ctx, "shop", "", "orders", "email", nil, []string{"privacy.pii"}, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if matches, err := uc.SearchAnnotations(ctx, biz.AnnotationQuery{Key: "privacy.pii"}); err != nil || len(matches) != 0 {
+		t.Errorf("Expected no match after removal, got %v, %v", matches, err)
+	}
+}
+
+func TestTableRepoApply(t *testing.T) {
+	ctx := context.Background()
+	st := newMemStore(ordersKey, usersKey)
+	for _, key := range []store.TableKey{ordersKey, usersKey} {
+		if _, err := newTestTables(st).UpsertSynced(ctx, syncedTable(key, key.Table)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	changes, err := biz.ParseChanges(strings.NewReader(`
+changes:
+  - table: shop.orders
+    description: One row per order.
+    owners: [alice, data-platform]
+    tags: [finance]
+    columns:
+      email:
+        tags: [pii]
+        deprecated: true
+        deprecation_note: Use users.email
+  - table: def.shop.users
+    owners: [bob]
+`), biz.FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Preview, then apply what was previewed
+	uc := newTestTables(st)
+	preview, err := uc.Apply(ctx, changes, biz.ApplyOptions{DryRun: true, User: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Applied || preview.Count(biz.PlanUpdate) != 2 {
+		t.Fatalf("Unexpected preview %+v", preview)
+	}
+	versions := make(map[string]int64)
+	for _, e := range preview.Entities {
+		versions[e.Table] = e.Version
+	}
+	plan, err := uc.Apply(ctx, changes, biz.ApplyOptions{Versions: versions, User: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Applied || plan.Entities[0].Version != 2 || plan.Entities[1].Version != 2 {
+		t.Fatalf("Unexpected plan %+v", plan)
+	}
+
+	// A sync keeps the applied fields
+	if _, err := newTestTables(st).UpsertSynced(ctx, syncedTable(ordersKey, "orders v2")); err != nil {
+		t.Fatal(err)
+	}
+	orders, err := newTestTables(st).Get(ctx, "shop", "", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	email := orders.Columns[1]
+	if orders.Description != "One row per order." || strings.Join(orders.Owners, ",") != "alice,data-platform" ||
+		strings.Join(orders.Tags, ",") != "finance" || orders.Comment != "orders v2" {
+		t.Errorf("Unexpected orders %+v", orders)
+	}
+	if strings.Join(email.Tags, ",") != "pii" || email.Deprecation == nil || email.Deprecation.Note != "Use users.email" || email.Deprecation.By != "alice" {
+		t.Errorf("Unexpected email column %+v", email)
+	}
+	users, err := newTestTables(st).Get(ctx, "def", "shop", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(users.Owners, ",") != "bob" {
+		t.Errorf("owners = %v", users.Owners)
+	}
+
+	// The plan is stale once applied, and applying it again is unchanged
+	if _, err := uc.Apply(ctx, changes, biz.ApplyOptions{Versions: versions, User: "alice"}); !errors.Is(err, biz.ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict applying a stale plan, got %v", err)
+	}
+	again, err := uc.Apply(ctx, changes, biz.ApplyOptions{User: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Count(biz.PlanUnchanged) != 2 {
+		t.Errorf("Expected the tables unchanged, got %+v", again.Entities)
+	}
+}
+
+func TestPolicyRepoSync(t *testing.T) {
+	ctx := context.Background()
+	st := newMemStore(ordersKey, usersKey)
+	uc := newTestTables(st)
+	set, err := biz.ParsePolicies(strings.NewReader(`
+policies:
+  - name: shop-owner
+    match:
+      schema: shop
+    require:
+      owner: true
+  - name: email-pii
+    severity: warning
+    match:
+      columns: [email]
+    require:
+      tags: [pii]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.SetPolicies(ctx, set, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := newTestTables(st).GetPolicies(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Policies) != 2 || stored.Policies[1].Require.Tags[0] != "pii" {
+		t.Fatalf("Unexpected stored policies %+v", stored.Policies)
+	}
+
+	// Syncs check the policies and record their violations
+	for _, key := range []store.TableKey{ordersKey, usersKey} {
+		result, err := newTestTables(st).UpsertSynced(ctx, syncedTable(key, key.Table))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Violations) != 2 {
+			t.Errorf("%s: violations = %v", key, result.Violations)
+		}
+	}
+	violations, err := newTestTables(st).ListViolations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.TableID+" "+v.Table+" "+v.Policy+" "+v.Column)
+	}
+	want := "1 def.shop.orders email-pii email,1 def.shop.orders shop-owner ,2 def.shop.users email-pii email,2 def.shop.users shop-owner "
+	if strings.Join(got, ",") != want {
+		t.Errorf("violations = %q, want %q", strings.Join(got, ","), want)
+	}
+	detected := violations[0].DetectedAt
+
+	// A user fixes a violation; the next sync resolves it and keeps the other open
+	if _, err := uc.Apply(ctx, []*biz.Change{{Table: "shop.orders", Owners: &[]string{"alice"}}}, biz.ApplyOptions{User: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newTestTables(st).UpsertSynced(ctx, syncedTable(ordersKey, "orders v2")); err != nil {
+		t.Fatal(err)
+	}
+	events, err := newTestTables(st).ListPolicyEvents(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 5 || events[0].Type != biz.PolicyResolved || events[0].Violation.Policy != "shop-owner" || events[0].Violation.TableID != "1" {
+		t.Fatalf("Unexpected events %+v", events)
+	}
+	violations, err = newTestTables(st).ListViolations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) != 3 || violations[0].Policy != "email-pii" || !violations[0].DetectedAt.Equal(detected) {
+		t.Errorf("Unexpected violations %+v", violations)
+	}
+}
+
+func TestTableRepoSizeSnapshots(t *testing.T) {
+	ctx := context.Background()
+	st := newMemStore(ordersKey, usersKey)
+	sync := func(key store.TableKey, rows int64, at time.Time) {
+		synced := syncedTable(key, key.Table)
+		synced.RowCount, synced.DataSize, synced.CollectedAt = rows, rows*64, at
+		if _, err := newTestTables(st).UpsertSynced(ctx, synced); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+	sync(ordersKey, 1000, first)
+	sync(usersKey, 10, first)
+	sync(ordersKey, 1000, first.AddDate(0, 0, 1))
+	sync(ordersKey, 1500, first.AddDate(0, 0, 2))
+
+	snapshots, err := NewTableRepo(&Data{store: st}, log.NewStdLogger(io.Discard)).ListSizeSnapshots(ctx, first.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 || snapshots[0].TableID != "1" || snapshots[0].Database != "def" || snapshots[0].Schema != "shop" ||
+		snapshots[0].Table != "orders" || snapshots[0].DataSize != 64000 || snapshots[1].Table != "users" {
+		t.Fatalf("Unexpected snapshots %+v", snapshots)
+	}
+
+	rollups, err := newTestTables(st).StorageReport(ctx, biz.StorageOptions{
+		Interval: biz.IntervalDay,
+		To:       first.AddDate(0, 0, 2),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range rollups {
+		got = append(got, fmt.Sprintf("%s %s.%s %d %d %+d", r.Period.Format("01-02"), r.Database, r.Schema, r.Tables, r.DataSize, r.DataSizeGrowth))
+	}
+	want := "03-02 def.shop 2 64640 +64640,03-03 def.shop 2 64640 +0,03-04 def.shop 2 96640 +32000"
+	if strings.Join(got, ",") != want {
+		t.Errorf("rollups = %q, want %q", strings.Join(got, ","), want)
+	}
+}
+
+func TestTableRepoFindDuplicates(t *testing.T) {
+	ctx := context.Background()
+	st := newMemStore(ordersKey, usersKey, replicaKey)
+	for _, key := range []store.TableKey{ordersKey, usersKey, replicaKey} {
+		synced := syncedTable(key, key.Table)
+		if key.Table == "orders" {
+			synced.Columns = append(synced.Columns, &biz.ColumnMetadata{Name: "amount", Type: "decimal(10,2)", Position: 3})
+		}
+		if _, err := newTestTables(st).UpsertSynced(ctx, synced); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	duplicates, err := newTestTables(st).FindDuplicates(ctx, biz.SimilarityOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(duplicates) != 1 {
+		t.Fatalf("Expected 1 duplicate, got %v", duplicates)
+	}
+	d := duplicates[0]
+	if d.Left != "def.shop.orders" || d.Right != "def.shop.orders" || d.LeftSource == d.RightSource || d.Similarity != 1 {
+		t.Errorf("Unexpected duplicate %+v", d)
+	}
+}
@@ -0,0 +1,264 @@
+// Package airflow imports Airflow DAGs into the lineage graph as pipelines:
+// each task is linked to the tables its SQL reads and writes and to the
+// datasets it declares as outlets, and each DAG to the tables of all its
+// tasks and the datasets that trigger it.
+package airflow
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-metadata/internal/lineage"
+)
+
+// sqlFields are the template fields of operators holding the SQL they run:
+// sql for the SQL, Postgres, MySQL and Snowflake operators, hql for Hive and
+// bql for the older BigQuery operators.
+var sqlFields = []string{"sql", "hql", "bql", "query"}
+
+// Pipeline is a DAG with its tasks.
+type Pipeline struct {
+	ID          string   `json:"dag_id"`
+	Description string   `json:"description,omitempty"`
+	Owners      []string `json:"owners,omitempty"`
+	Paused      bool     `json:"paused,omitempty"`
+	Tasks       []*Task  `json:"tasks"`
+	// Inlets are the tables of the datasets whose updates trigger the DAG.
+	Inlets []string `json:"inlets,omitempty"`
+}
+
+// Task is a task of a pipeline.
+type Task struct {
+	ID         string   `json:"task_id"`
+	Operator   string   `json:"operator,omitempty"`
+	Downstream []string `json:"downstream,omitempty"`
+	// SQL is the SQL the task ran in the latest run of the DAG, rendered.
+	SQL string `json:"sql,omitempty"`
+	// Outlets are the tables of the datasets the task declares it updates.
+	Outlets []string `json:"outlets,omitempty"`
+}
+
+// Summary counts the pipelines and tasks recorded by Apply.
+type Summary struct {
+	Pipelines int `json:"pipelines"`
+	Tasks     int `json:"tasks"`
+	// Linked is the number of tasks linked to the tables they read or write.
+	Linked int `json:"linked"`
+	// Errors are the errors of the task SQL the analyzer rejected.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Import reads the DAGs of dagIDs, or all DAGs if it is empty, with their
+// tasks, the SQL of their latest run and the datasets they update or are
+// triggered by.
+func Import(ctx context.Context, c *Client, dagIDs []string) ([]*Pipeline, error) {
+	dags, err := c.DAGs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(dagIDs) > 0 {
+		wanted := make(map[string]bool, len(dagIDs))
+		for _, id := range dagIDs {
+			wanted[id] = true
+		}
+		selected := dags[:0]
+		for _, dag := range dags {
+			if wanted[dag.ID] {
+				selected = append(selected, dag)
+				delete(wanted, dag.ID)
+			}
+		}
+		if len(wanted) > 0 {
+			missing := make([]string, 0, len(wanted))
+			for id := range wanted {
+				missing = append(missing, id)
+			}
+			sort.Strings(missing)
+			return nil, fmt.Errorf("dags not found: %s", strings.Join(missing, ", "))
+		}
+		dags = selected
+	}
+
+	datasets, err := c.Datasets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	outlets := make(map[string][]string)
+	inlets := make(map[string][]string)
+	for _, ds := range datasets {
+		table := DatasetTable(ds.URI)
+		for _, t := range ds.ProducingTasks {
+			key := t.DAGID + "." + t.TaskID
+			outlets[key] = append(outlets[key], table)
+		}
+		for _, d := range ds.ConsumingDAGs {
+			inlets[d.DAGID] = append(inlets[d.DAGID], table)
+		}
+	}
+
+	pipelines := make([]*Pipeline, 0, len(dags))
+	for _, dag := range dags {
+		tasks, err := c.Tasks(ctx, dag.ID)
+		if err != nil {
+			return nil, err
+		}
+		fields, err := c.RenderedFields(ctx, dag.ID)
+		if err != nil {
+			return nil, err
+		}
+		p := &Pipeline{
+			ID:          dag.ID,
+			Description: dag.Description,
+			Owners:      dag.Owners,
+			Paused:      dag.Paused,
+			Tasks:       make([]*Task, 0, len(tasks)),
+			Inlets:      inlets[dag.ID],
+		}
+		for _, t := range tasks {
+			operator := t.Operator
+			if operator == "" {
+				operator = t.ClassRef.ClassName
+			}
+			p.Tasks = append(p.Tasks, &Task{
+				ID:         t.ID,
+				Operator:   operator,
+				Downstream: t.Downstream,
+				SQL:        renderedSQL(fields[t.ID]),
+				Outlets:    outlets[dag.ID+"."+t.ID],
+			})
+		}
+		pipelines = append(pipelines, p)
+	}
+	return pipelines, nil
+}
+
+// Apply registers the pipelines in g. Each task is registered as an
+// airflow_task job named dag_id.task_id, with the lineage of its SQL analyzed
+// with analyzer and its outlets as outputs; its downstream tasks are kept in
+// the downstream property. Each DAG is registered as an airflow_dag job
+// whose inputs and outputs are those of its tasks and its inlets. analyzer
+// may be nil, in which case only outlets and inlets are linked. SQL the
+// analyzer rejects is counted in the summary rather than failing.
+func Apply(g *lineage.Graph, pipelines []*Pipeline, analyzer *lineage.Analyzer, at time.Time) (*Summary, error) {
+	summary := &Summary{}
+	for _, p := range pipelines {
+		dag := &lineage.Job{
+			Name:        p.ID,
+			Type:        lineage.JobTypeAirflowDAG,
+			Description: p.Description,
+			Properties:  map[string]string{"tasks": strconv.Itoa(len(p.Tasks))},
+			Inputs:      appendUnique(nil, p.Inlets...),
+		}
+		if len(p.Owners) > 0 {
+			dag.Properties["owners"] = strings.Join(p.Owners, ",")
+		}
+		if p.Paused {
+			dag.Properties["paused"] = "true"
+		}
+
+		for _, t := range p.Tasks {
+			job := &lineage.Job{
+				Name:       p.ID + "." + t.ID,
+				Type:       lineage.JobTypeAirflowTask,
+				Properties: map[string]string{"dag_id": p.ID, "task_id": t.ID},
+				Outputs:    t.Outlets,
+			}
+			if t.Operator != "" {
+				job.Properties["operator"] = t.Operator
+			}
+			if len(t.Downstream) > 0 {
+				job.Properties["downstream"] = strings.Join(t.Downstream, ",")
+			}
+			if err := g.RegisterJob(job); err != nil {
+				return nil, fmt.Errorf("register task %s: %w", job.Name, err)
+			}
+
+			if analyzer != nil && strings.TrimSpace(t.SQL) != "" {
+				result, err := analyzer.AnalyzeScript(t.SQL)
+				if err != nil {
+					summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", job.Name, err))
+				} else if err := g.AttachStatement(job.Name, result, lineage.Fingerprint(t.SQL), at); err != nil {
+					return nil, err
+				}
+			}
+
+			registered, err := g.Job(job.Name)
+			if err != nil {
+				return nil, err
+			}
+			if len(registered.Inputs) > 0 || len(registered.Outputs) > 0 {
+				summary.Linked++
+			}
+			dag.Inputs = appendUnique(dag.Inputs, registered.Inputs...)
+			dag.Outputs = appendUnique(dag.Outputs, registered.Outputs...)
+			summary.Tasks++
+		}
+
+		if err := g.RegisterJob(dag); err != nil {
+			return nil, fmt.Errorf("register dag %s: %w", p.ID, err)
+		}
+		summary.Pipelines++
+	}
+	return summary, nil
+}
+
+// DatasetTable returns the qualified table name of an Airflow dataset URI.
+// URIs with a scheme, such as postgres://host/db/public/orders, name the
+// table with their last two path segments, public.orders, or with their host
+// if they have no path; URIs without one, such as dw.orders, are the table
+// name itself.
+func DatasetTable(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" {
+		return uri
+	}
+	var segments []string
+	for _, s := range strings.Split(u.Path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	if len(segments) == 0 {
+		return u.Host
+	}
+	return strings.Join(segments[max(len(segments)-2, 0):], ".")
+}
+
+// renderedSQL returns the SQL among the rendered template fields of a task,
+// joining the statements of fields holding a list of them.
+func renderedSQL(fields map[string]interface{}) string {
+	for _, name := range sqlFields {
+		switch v := fields[name].(type) {
+		case string:
+			if strings.TrimSpace(v) != "" {
+				return v
+			}
+		case []interface{}:
+			var statements []string
+			for _, s := range v {
+				if s, ok := s.(string); ok && strings.TrimSpace(s) != "" {
+					statements = append(statements, strings.TrimSuffix(strings.TrimSpace(s), ";"))
+				}
+			}
+			if len(statements) > 0 {
+				return strings.Join(statements, ";\n")
+			}
+		}
+	}
+	return ""
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,150 @@
+package airflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-metadata/internal/lineage"
+)
+
+// airflowAPI serves the responses of a webserver with a daily_sales DAG:
+// transform runs SQL, publish declares an outlet dataset, and the DAG is
+// triggered by the orders dataset. The other DAG never ran.
+var airflowAPI = map[string]string{
+	"/api/v1/dags": `{"dags": [
+		{"dag_id": "daily_sales", "description": "Daily sales", "is_paused": false, "owners": ["data"]},
+		{"dag_id": "other", "is_paused": true}
+	], "total_entries": 2}`,
+	"/api/v1/dags/daily_sales/tasks": `{"tasks": [
+		{"task_id": "transform", "operator_name": "PostgresOperator", "downstream_task_ids": ["publish"]},
+		{"task_id": "publish", "class_ref": {"class_name": "PythonOperator"}, "downstream_task_ids": []}
+	]}`,
+	"/api/v1/dags/daily_sales/dagRuns": `{"dag_runs": [{"dag_run_id": "scheduled__2026-01-01"}]}`,
+	"/api/v1/dags/daily_sales/dagRuns/scheduled__2026-01-01/taskInstances": `{"task_instances": [
+		{"task_id": "transform", "map_index": -1, "rendered_fields": {
+			"sql": ["DELETE FROM daily_sales", "INSERT INTO daily_sales SELECT order_date, SUM(amount) AS total FROM orders GROUP BY order_date"]
+		}},
+		{"task_id": "publish", "map_index": -1, "rendered_fields": {"python_callable": "publish"}}
+	], "total_entries": 2}`,
+	"/api/v1/dags/other/tasks":   `{"tasks": []}`,
+	"/api/v1/dags/other/dagRuns": `{"dag_runs": []}`,
+	"/api/v1/datasets": `{"datasets": [
+		{"uri": "postgres://db:5432/shop/public/sales_report", "producing_tasks": [{"dag_id": "daily_sales", "task_id": "publish"}], "consuming_dags": []},
+		{"uri": "orders", "producing_tasks": [], "consuming_dags": [{"dag_id": "daily_sales"}]}
+	], "total_entries": 2}`,
+}
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, ok := airflowAPI[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(&Config{URL: srv.URL, Username: "admin", Password: "secret"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return c
+}
+
+func TestImport(t *testing.T) {
+	pipelines, err := Import(context.Background(), newTestClient(t), []string{"daily_sales"})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(pipelines) != 1 {
+		t.Fatalf("Expected 1 pipeline, got %d", len(pipelines))
+	}
+	p := pipelines[0]
+	if len(p.Inlets) != 1 || p.Inlets[0] != "orders" {
+		t.Errorf("Expected inlet orders, got %v", p.Inlets)
+	}
+	if len(p.Tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(p.Tasks))
+	}
+	transform, publish := p.Tasks[0], p.Tasks[1]
+	if transform.SQL == "" || transform.Operator != "PostgresOperator" {
+		t.Errorf("Unexpected transform task %+v", transform)
+	}
+	if publish.Operator != "PythonOperator" || publish.SQL != "" {
+		t.Errorf("Unexpected publish task %+v", publish)
+	}
+	if len(publish.Outlets) != 1 || publish.Outlets[0] != "public.sales_report" {
+		t.Errorf("Expected outlet public.sales_report, got %v", publish.Outlets)
+	}
+
+	if _, err := Import(context.Background(), newTestClient(t), []string{"missing"}); err == nil {
+		t.Error("Expected an error for a missing DAG")
+	}
+}
+
+func TestApply(t *testing.T) {
+	pipelines, err := Import(context.Background(), newTestClient(t), nil)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	g := lineage.NewGraph()
+	summary, err := Apply(g, pipelines, lineage.NewAnalyzer(nil), time.Now())
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if summary.Pipelines != 2 || summary.Tasks != 2 || summary.Linked != 2 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+
+	transform, err := g.Job("daily_sales.transform")
+	if err != nil {
+		t.Fatalf("Job failed: %v", err)
+	}
+	if transform.Type != lineage.JobTypeAirflowTask || transform.Properties["downstream"] != "publish" {
+		t.Errorf("Unexpected transform job %+v", transform)
+	}
+	if len(transform.Inputs) != 1 || transform.Inputs[0] != "orders" || len(transform.Outputs) != 1 || transform.Outputs[0] != "daily_sales" {
+		t.Errorf("Unexpected transform inputs %v and outputs %v", transform.Inputs, transform.Outputs)
+	}
+
+	dag, err := g.Job("daily_sales")
+	if err != nil {
+		t.Fatalf("Job failed: %v", err)
+	}
+	if dag.Type != lineage.JobTypeAirflowDAG {
+		t.Errorf("Expected airflow_dag, got %s", dag.Type)
+	}
+	if len(dag.Inputs) != 1 || dag.Inputs[0] != "orders" {
+		t.Errorf("Expected DAG input orders, got %v", dag.Inputs)
+	}
+	if len(dag.Outputs) != 2 {
+		t.Errorf("Expected DAG outputs daily_sales and public.sales_report, got %v", dag.Outputs)
+	}
+	if jobs := g.JobsWriting("public.sales_report"); len(jobs) != 2 {
+		t.Errorf("Expected the publish task and its DAG to write the report, got %d jobs", len(jobs))
+	}
+}
+
+func TestDatasetTable(t *testing.T) {
+	tests := map[string]string{
+		"dw.orders":                            "dw.orders",
+		"postgres://db:5432/shop/public/users": "public.users",
+		"mysql://db/orders":                    "orders",
+		"bigquery://project":                   "project",
+	}
+	for uri, want := range tests {
+		if got := DatasetTable(uri); got != want {
+			t.Errorf("DatasetTable(%q) = %q, want %q", uri, got, want)
+		}
+	}
+}
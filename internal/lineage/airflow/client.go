@@ -0,0 +1,231 @@
+package airflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pageSize is the number of entries requested per page of list endpoints.
+const pageSize = 100
+
+// errNotFound is returned for endpoints the Airflow version does not serve,
+// such as the datasets of Airflow < 2.4.
+var errNotFound = errors.New("not found")
+
+// Config configures a Client.
+type Config struct {
+	// URL is the base URL of the Airflow webserver, e.g. http://airflow:8080.
+	URL string `yaml:"url"`
+	// Username and Password authenticate with the basic auth backend.
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// Token is sent as a bearer token if set, instead of basic auth.
+	Token string `yaml:"token"`
+	// Timeout bounds each request; defaults to 30s.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Client reads DAGs, tasks and datasets from the Airflow stable REST API
+// (/api/v1).
+type Client struct {
+	baseURL    string
+	username   string
+	password   string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the Airflow webserver described by cfg.
+func NewClient(cfg *Config) (*Client, error) {
+	if cfg == nil || cfg.URL == "" {
+		return nil, fmt.Errorf("airflow url is required")
+	}
+	if _, err := url.Parse(cfg.URL); err != nil {
+		return nil, fmt.Errorf("invalid airflow url: %w", err)
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(cfg.URL, "/") + "/api/v1",
+		username:   cfg.Username,
+		password:   cfg.Password,
+		token:      cfg.Token,
+		httpClient: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// DAG is a DAG as listed by GET /dags.
+type DAG struct {
+	ID          string   `json:"dag_id"`
+	Description string   `json:"description,omitempty"`
+	Paused      bool     `json:"is_paused"`
+	Owners      []string `json:"owners,omitempty"`
+}
+
+// TaskInfo is a task of a DAG as listed by GET /dags/{dag_id}/tasks.
+type TaskInfo struct {
+	ID         string   `json:"task_id"`
+	Operator   string   `json:"operator_name,omitempty"`
+	Downstream []string `json:"downstream_task_ids"`
+	ClassRef   struct {
+		ClassName string `json:"class_name"`
+	} `json:"class_ref"`
+}
+
+// Dataset is a dataset as listed by GET /datasets, with the tasks that
+// declare it as an outlet and the DAGs it triggers.
+type Dataset struct {
+	URI            string `json:"uri"`
+	ProducingTasks []struct {
+		DAGID  string `json:"dag_id"`
+		TaskID string `json:"task_id"`
+	} `json:"producing_tasks"`
+	ConsumingDAGs []struct {
+		DAGID string `json:"dag_id"`
+	} `json:"consuming_dags"`
+}
+
+// taskInstance is a task instance of a DAG run, with the template fields of
+// its task rendered for the run.
+type taskInstance struct {
+	TaskID         string                 `json:"task_id"`
+	MapIndex       int                    `json:"map_index"`
+	RenderedFields map[string]interface{} `json:"rendered_fields"`
+}
+
+// DAGs returns all DAGs, paused or not.
+func (c *Client) DAGs(ctx context.Context) ([]DAG, error) {
+	var dags []DAG
+	for offset := 0; ; offset += pageSize {
+		var page struct {
+			DAGs  []DAG `json:"dags"`
+			Total int   `json:"total_entries"`
+		}
+		query := url.Values{"limit": {strconv.Itoa(pageSize)}, "offset": {strconv.Itoa(offset)}}
+		if err := c.get(ctx, "/dags", query, &page); err != nil {
+			return nil, err
+		}
+		dags = append(dags, page.DAGs...)
+		if len(page.DAGs) == 0 || len(dags) >= page.Total {
+			return dags, nil
+		}
+	}
+}
+
+// Tasks returns the tasks of a DAG.
+func (c *Client) Tasks(ctx context.Context, dagID string) ([]TaskInfo, error) {
+	var resp struct {
+		Tasks []TaskInfo `json:"tasks"`
+	}
+	if err := c.get(ctx, "/dags/"+url.PathEscape(dagID)+"/tasks", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tasks, nil
+}
+
+// RenderedFields returns the rendered template fields of the tasks of the
+// latest run of a DAG, by task ID, or nil if the DAG never ran. Mapped tasks
+// get the fields of their first instance.
+func (c *Client) RenderedFields(ctx context.Context, dagID string) (map[string]map[string]interface{}, error) {
+	var runs struct {
+		DAGRuns []struct {
+			ID string `json:"dag_run_id"`
+		} `json:"dag_runs"`
+	}
+	query := url.Values{"limit": {"1"}, "order_by": {"-execution_date"}}
+	if err := c.get(ctx, "/dags/"+url.PathEscape(dagID)+"/dagRuns", query, &runs); err != nil {
+		return nil, err
+	}
+	if len(runs.DAGRuns) == 0 {
+		return nil, nil
+	}
+
+	path := "/dags/" + url.PathEscape(dagID) + "/dagRuns/" + url.PathEscape(runs.DAGRuns[0].ID) + "/taskInstances"
+	fields := make(map[string]map[string]interface{})
+	for offset := 0; ; offset += pageSize {
+		var page struct {
+			TaskInstances []taskInstance `json:"task_instances"`
+			Total         int            `json:"total_entries"`
+		}
+		query := url.Values{"limit": {strconv.Itoa(pageSize)}, "offset": {strconv.Itoa(offset)}}
+		if err := c.get(ctx, path, query, &page); err != nil {
+			return nil, err
+		}
+		for _, ti := range page.TaskInstances {
+			if _, ok := fields[ti.TaskID]; !ok || ti.MapIndex <= 0 {
+				fields[ti.TaskID] = ti.RenderedFields
+			}
+		}
+		if len(page.TaskInstances) == 0 || offset+len(page.TaskInstances) >= page.Total {
+			return fields, nil
+		}
+	}
+}
+
+// Datasets returns all datasets, or nil if the Airflow version has none.
+func (c *Client) Datasets(ctx context.Context) ([]Dataset, error) {
+	var datasets []Dataset
+	for offset := 0; ; offset += pageSize {
+		var page struct {
+			Datasets []Dataset `json:"datasets"`
+			Total    int       `json:"total_entries"`
+		}
+		query := url.Values{"limit": {strconv.Itoa(pageSize)}, "offset": {strconv.Itoa(offset)}}
+		if err := c.get(ctx, "/datasets", query, &page); err != nil {
+			if errors.Is(err, errNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		datasets = append(datasets, page.Datasets...)
+		if len(page.Datasets) == 0 || len(datasets) >= page.Total {
+			return datasets, nil
+		}
+	}
+}
+
+// get decodes the JSON response of a GET request to path.
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("airflow GET %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("airflow GET %s: %w", path, errNotFound)
+	}
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("airflow GET %s: status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("airflow GET %s: decode response: %w", path, err)
+	}
+	return nil
+}
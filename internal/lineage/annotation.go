@@ -0,0 +1,140 @@
+package lineage
+
+import (
+	"regexp"
+	"strings"
+)
+
+// annotation matches a "key: value", "key=value" or "@key value" comment line.
+var annotation = regexp.MustCompile(`^(?:@([A-Za-z][\w.-]*)\s*[:=]?|([A-Za-z][\w.-]*)\s*[:=])\s*(\S.*)$`)
+
+// datasetAnnotation matches an "@key value" comment line, the value being
+// optional.
+var datasetAnnotation = regexp.MustCompile(`^@([A-Za-z][\w.-]*)(?:\s*[:=]?\s+|\s*[:=]\s*|$)(.*)$`)
+
+// ParseAnnotations returns the key/value annotations of comments, one per
+// comment line, e.g. "owner: team-x", "@sla=daily" or "@owner team-x". Keys
+// are lower-cased; the first value of a key wins. It returns nil if there are
+// none.
+func ParseAnnotations(comments []Comment) map[string]string {
+	var annotations map[string]string
+	for _, line := range commentLines(comments) {
+		m := annotation.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		key := strings.ToLower(m[1] + m[2])
+		if _, ok := annotations[key]; ok {
+			continue
+		}
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[key] = strings.TrimSpace(m[3])
+	}
+	return annotations
+}
+
+// DatasetAnnotations are the metadata annotations of the SQL comments of a
+// statement, which apply to the table it creates or writes:
+//
+//	-- @owner team-data
+//	-- @tag finance, daily
+//	-- @description Daily order facts.
+//	-- @deprecated Use mart.orders_v2 instead.
+//	-- @pii email, phone
+//	-- @column amount finance
+//	-- @sla.tier gold
+//
+// @pii tags the columns it lists as pii and @column tags a column with the
+// tags that follow it. Other keys are kept as annotations.
+type DatasetAnnotations struct {
+	Owners          []string            `json:"owners,omitempty"`
+	Tags            []string            `json:"tags,omitempty"`
+	Description     string              `json:"description,omitempty"`
+	Deprecated      bool                `json:"deprecated,omitempty"`
+	DeprecationNote string              `json:"deprecation_note,omitempty"`
+	ColumnTags      map[string][]string `json:"column_tags,omitempty"`
+	Annotations     map[string]string   `json:"annotations,omitempty"`
+}
+
+// Dataset annotation keys with a meaning of their own.
+const (
+	AnnotationOwner       = "owner"
+	AnnotationTag         = "tag"
+	AnnotationDescription = "description"
+	AnnotationDeprecated  = "deprecated"
+	AnnotationPII         = "pii"
+	AnnotationColumn      = "column"
+)
+
+// ParseDatasetAnnotations returns the "@key value" annotations of comments,
+// or nil if there are none. Owners and tags are separated by commas or
+// spaces and may be given on several lines; a repeated description or
+// annotation overrides the earlier one.
+func ParseDatasetAnnotations(comments []Comment) *DatasetAnnotations {
+	var a *DatasetAnnotations
+	for _, line := range commentLines(comments) {
+		m := datasetAnnotation.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if a == nil {
+			a = &DatasetAnnotations{}
+		}
+		key, value := strings.ToLower(m[1]), strings.TrimSpace(m[2])
+		switch key {
+		case AnnotationOwner, "owners":
+			a.Owners = appendUnique(a.Owners, splitItems(value)...)
+		case AnnotationTag, "tags":
+			a.Tags = appendUnique(a.Tags, splitItems(value)...)
+		case AnnotationDescription:
+			a.Description = value
+		case AnnotationDeprecated:
+			a.Deprecated, a.DeprecationNote = true, value
+		case AnnotationPII:
+			for _, column := range splitItems(value) {
+				a.tagColumn(column, AnnotationPII)
+			}
+		case AnnotationColumn:
+			items := splitItems(value)
+			if len(items) > 1 {
+				a.tagColumn(items[0], items[1:]...)
+			}
+		default:
+			if a.Annotations == nil {
+				a.Annotations = make(map[string]string)
+			}
+			a.Annotations[key] = value
+		}
+	}
+	return a
+}
+
+func (a *DatasetAnnotations) tagColumn(column string, tags ...string) {
+	if a.ColumnTags == nil {
+		a.ColumnTags = make(map[string][]string)
+	}
+	a.ColumnTags[column] = appendUnique(a.ColumnTags[column], tags...)
+}
+
+// commentLines returns the lines of comments without their leading
+// decoration, such as the asterisks of a block comment.
+func commentLines(comments []Comment) []string {
+	var lines []string
+	for _, c := range comments {
+		for _, line := range strings.Split(c.Text, "\n") {
+			if line = strings.TrimLeft(strings.TrimSpace(line), "*-# "); line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+// splitItems splits a list separated by commas or spaces.
+func splitItems(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
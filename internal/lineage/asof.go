@@ -0,0 +1,188 @@
+package lineage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Interval is a validity period of a lineage edge. A zero To means the edge
+// is still valid.
+type Interval struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to,omitempty"`
+}
+
+// Open reports whether the interval has not been closed.
+func (i Interval) Open() bool {
+	return i.To.IsZero()
+}
+
+// Contains reports whether t falls within the interval [From, To).
+func (i Interval) Contains(t time.Time) bool {
+	if t.Before(i.From) {
+		return false
+	}
+	return i.Open() || t.Before(i.To)
+}
+
+// ValidAt reports whether the edge was valid at t.
+func (e *Edge) ValidAt(t time.Time) bool {
+	for _, iv := range e.Validity {
+		if iv.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Current reports whether the edge is valid now, i.e. has not been retired.
+func (e *Edge) Current() bool {
+	return len(e.Validity) > 0 && e.Validity[len(e.Validity)-1].Open()
+}
+
+// validFrom records that the edge was observed at t, opening a new validity
+// interval if the edge had been retired before t.
+func (e *Edge) validFrom(t time.Time) {
+	if len(e.Validity) == 0 {
+		e.Validity = []Interval{{From: t}}
+		return
+	}
+	first := &e.Validity[0]
+	if t.Before(first.From) {
+		first.From = t
+		return
+	}
+	last := &e.Validity[len(e.Validity)-1]
+	if !last.Open() && !t.Before(last.To) {
+		e.Validity = append(e.Validity, Interval{From: t})
+	}
+}
+
+// retire closes the open validity interval of the edge at t.
+func (e *Edge) retire(t time.Time) {
+	if len(e.Validity) == 0 {
+		return
+	}
+	last := &e.Validity[len(e.Validity)-1]
+	if last.Open() {
+		if t.Before(last.From) {
+			t = last.From
+		}
+		last.To = t
+	}
+}
+
+// mergeIntervals sorts intervals and merges overlapping ones.
+func mergeIntervals(intervals []Interval) []Interval {
+	if len(intervals) < 2 {
+		return intervals
+	}
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].From.Before(intervals[j].From)
+	})
+
+	merged := []Interval{intervals[0]}
+	for _, iv := range intervals[1:] {
+		last := &merged[len(merged)-1]
+		if last.Open() || !iv.From.After(last.To) {
+			if last.Open() || iv.Open() {
+				last.To = time.Time{}
+			} else if iv.To.After(last.To) {
+				last.To = iv.To
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// Retire marks the edge source -> target as no longer valid from t on.
+func (g *Graph) Retire(source, target ColumnRef, at time.Time) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	source.Confidence = ""
+	edge, ok := g.edges[edgeKey(source, target)]
+	if !ok {
+		return fmt.Errorf("edge %s -> %s not found", source.QualifiedName(), target.QualifiedName())
+	}
+	edge.retire(at)
+	return nil
+}
+
+// ReplaceJobLineage replaces the lineage produced by a job as of t, e.g. after
+// a pipeline refactor. Edges the job no longer produces, and that no other job
+// produces, are retired at t; the edges of result are attached to the job.
+// The replacement is atomic: readers see either the old or the new lineage.
+// A nil result leaves the job unchanged.
+func (g *Graph) ReplaceJobLineage(jobName string, result *LineageResult, fingerprint string, at time.Time) error {
+	if result == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	job, ok := g.jobs[jobName]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, jobName)
+	}
+
+	produced := make(map[string]bool)
+	for _, col := range result.Columns {
+		for _, src := range col.Sources {
+			src.Confidence = ""
+			produced[edgeKey(src, col.Target)] = true
+		}
+	}
+
+	for key, edge := range g.edges {
+		if produced[key] || !contains(edge.Provenance.Jobs, jobName) {
+			continue
+		}
+		edge.Provenance.Jobs = removeValue(edge.Provenance.Jobs, jobName)
+		if len(edge.Provenance.Jobs) == 0 {
+			edge.retire(at)
+		}
+	}
+	job.Inputs = job.Inputs[:0]
+	job.Outputs = job.Outputs[:0]
+
+	g.attachLocked(job, result, fingerprint, OriginAnalysis, at)
+	return nil
+}
+
+// AsOf returns the edges that were valid at t.
+func (g *Graph) AsOf(t time.Time) []*Edge {
+	result := make([]*Edge, 0)
+	for _, edge := range g.Edges() {
+		if edge.ValidAt(t) {
+			result = append(result, edge)
+		}
+	}
+	return result
+}
+
+// TableLineageAsOf returns the edges valid at t that read from or write to the
+// given table (qualified as database.table or table).
+func (g *Graph) TableLineageAsOf(table string, t time.Time) []*Edge {
+	result := make([]*Edge, 0)
+	for _, edge := range g.AsOf(t) {
+		if edge.Source.TableName() == table || edge.Target.TableName() == table {
+			result = append(result, edge)
+		}
+	}
+	return result
+}
+
+func removeValue(list []string, value string) []string {
+	result := list[:0]
+	for _, v := range list {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
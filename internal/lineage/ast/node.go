@@ -120,9 +120,24 @@ func (s *SubqueryExpr) Accept(visitor Visitor) interface{} {
 }
 func (s *SubqueryExpr) expressionNode() {}
 
+// PredicateExpr represents a condition such as a comparison, IN, BETWEEN or
+// an AND/OR combination. Predicate operators are not modeled; only the
+// operands the condition refers to are kept.
+type PredicateExpr struct {
+	Operands []Expression
+	RawText  string // Original expression text
+}
+
+func (p *PredicateExpr) Accept(visitor Visitor) interface{} {
+	return visitor.VisitPredicate(p)
+}
+func (p *PredicateExpr) expressionNode() {}
+
 // StarExpr represents a * or table.* expression.
 type StarExpr struct {
 	Table string // empty for *, non-empty for table.*
+	// Except are the columns left out by BigQuery SELECT * EXCEPT (...).
+	Except []string
 }
 
 func (s *StarExpr) Accept(visitor Visitor) interface{} {
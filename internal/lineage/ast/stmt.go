@@ -27,8 +27,9 @@ type WithClause struct {
 
 // CTE represents a Common Table Expression.
 type CTE struct {
-	Name  string
-	Query *SelectStmt
+	Name    string
+	Columns []string // optional column list: name (a, b) AS (...)
+	Query   *SelectStmt
 }
 
 // FromClause represents a FROM clause.
@@ -64,6 +65,41 @@ func (i *InsertStmt) Accept(visitor Visitor) interface{} {
 }
 func (i *InsertStmt) statementNode() {}
 
+// CreateTableAsStmt represents a CREATE TABLE ... AS SELECT statement.
+type CreateTableAsStmt struct {
+	Table   *TableRef
+	Columns []string
+	Select  *SelectStmt
+}
+
+func (c *CreateTableAsStmt) Accept(visitor Visitor) interface{} {
+	return visitor.VisitCreateTableAsStmt(c)
+}
+func (c *CreateTableAsStmt) statementNode() {}
+
+// MergeStmt represents a MERGE INTO statement.
+type MergeStmt struct {
+	Target  *TableRef
+	Source  *TableSource
+	On      Expression
+	Clauses []*MergeClause
+}
+
+func (m *MergeStmt) Accept(visitor Visitor) interface{} {
+	return visitor.VisitMergeStmt(m)
+}
+func (m *MergeStmt) statementNode() {}
+
+// MergeClause represents a WHEN [NOT] MATCHED clause of a MERGE statement.
+type MergeClause struct {
+	Matched     bool
+	Delete      bool
+	Condition   Expression
+	Assignments []*Assignment // WHEN MATCHED THEN UPDATE
+	Columns     []string      // WHEN NOT MATCHED THEN INSERT
+	Values      []Expression
+}
+
 // UpdateStmt represents an UPDATE statement.
 type UpdateStmt struct {
 	Table       *TableRef
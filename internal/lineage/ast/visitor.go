@@ -7,6 +7,8 @@ type Visitor interface {
 	VisitInsertStmt(stmt *InsertStmt) interface{}
 	VisitUpdateStmt(stmt *UpdateStmt) interface{}
 	VisitDeleteStmt(stmt *DeleteStmt) interface{}
+	VisitCreateTableAsStmt(stmt *CreateTableAsStmt) interface{}
+	VisitMergeStmt(stmt *MergeStmt) interface{}
 
 	// Expressions
 	VisitColumnRef(expr *ColumnRefExpr) interface{}
@@ -15,6 +17,7 @@ type Visitor interface {
 	VisitCaseExpr(expr *CaseExpr) interface{}
 	VisitLiteral(expr *LiteralExpr) interface{}
 	VisitSubquery(expr *SubqueryExpr) interface{}
+	VisitPredicate(expr *PredicateExpr) interface{}
 	VisitStar(expr *StarExpr) interface{}
 	VisitAliasedExpr(expr *AliasedExpr) interface{}
 	VisitTableRef(ref *TableRef) interface{}
@@ -39,4 +42,11 @@ func (v *BaseVisitor) VisitStar(expr *StarExpr) interface{}         { return nil
 func (v *BaseVisitor) VisitAliasedExpr(expr *AliasedExpr) interface{} {
 	return nil
 }
+func (v *BaseVisitor) VisitPredicate(expr *PredicateExpr) interface{} {
+	return nil
+}
 func (v *BaseVisitor) VisitTableRef(ref *TableRef) interface{} { return nil }
+func (v *BaseVisitor) VisitCreateTableAsStmt(stmt *CreateTableAsStmt) interface{} {
+	return nil
+}
+func (v *BaseVisitor) VisitMergeStmt(stmt *MergeStmt) interface{} { return nil }
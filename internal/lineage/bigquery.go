@@ -0,0 +1,197 @@
+package lineage
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"go-metadata/internal/lineage/ast"
+)
+
+// BigQuery constructs the grammar does not cover are rewritten before
+// parsing, so that scheduled queries are analyzed as written:
+//
+//   - Table paths project.dataset.table, quoted as a whole or by part, e.g.
+//     `proj.ds.orders` or `proj`.ds.orders, name the table in the database
+//     proj.ds, as BigQuery tables are named elsewhere in the lineage graph.
+//     Paths of two parts quoted as a whole, `ds.orders`, are split too.
+//   - SELECT * EXCEPT (a, b) and t.* EXCEPT (a) select the columns of the
+//     star but the excepted ones.
+//   - Wildcard tables such as `proj.analytics.events_*` are a table of their
+//     own, and their _TABLE_SUFFIX pseudo column is not a source column.
+//
+// SQL without these constructs is parsed as written.
+
+// tableSuffix is the pseudo column of BigQuery wildcard tables holding the
+// part of the table name the wildcard matched.
+const tableSuffix = "_TABLE_SUFFIX"
+
+// bigQueryTableKeywords are the keywords a table path follows.
+var bigQueryTableKeywords = map[string]bool{
+	"FROM": true, "JOIN": true, "INTO": true, "TABLE": true,
+	"UPDATE": true, "MERGE": true, "USING": true, "DELETE": true,
+}
+
+// bigQueryFromEnd are the keywords ending the table list of a FROM clause.
+var bigQueryFromEnd = map[string]bool{
+	"WHERE": true, "GROUP": true, "HAVING": true, "QUALIFY": true, "WINDOW": true,
+	"ORDER": true, "LIMIT": true, "UNION": true, "INTERSECT": true, "EXCEPT": true,
+	"SELECT": true, "SET": true, "VALUES": true, "ON": true,
+}
+
+// bigQueryEdit replaces sql[start:end] with text; the edits of a star keep
+// the columns it excepts.
+type bigQueryEdit struct {
+	sparkEdit
+	except []string
+}
+
+// parseStatement parses a statement with its BigQuery constructs rewritten.
+func parseStatement(sql string) (ast.Statement, error) {
+	rewritten, excepts, _ := rewriteBigQuery(sql)
+	return parseSQL(rewritten, excepts)
+}
+
+// rewriteBigQuery rewrites the BigQuery table paths and star excepts of sql,
+// and reports whether it found any. The columns excepted by each star are
+// returned by the offset of the star in the rewritten SQL, in runes as the
+// parser counts them.
+func rewriteBigQuery(sql string) (string, map[int][]string, bool) {
+	tokens := tokenizeSpark(sql)
+	var edits []bigQueryEdit
+	// inFrom tells, per parenthesis depth, whether the tokens are in the
+	// table list of a FROM clause, where commas precede tables
+	inFrom := []bool{false}
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.text == "(":
+			inFrom = append(inFrom, false)
+			continue
+		case t.text == ")":
+			if len(inFrom) > 1 {
+				inFrom = inFrom[:len(inFrom)-1]
+			}
+			continue
+		case t.text == "*" && i+2 < len(tokens) && tokens[i+1].word() == "EXCEPT" && tokens[i+2].text == "(":
+			end := closingParen(tokens, i+2)
+			var columns []string
+			for _, c := range strings.Split(sql[tokens[i+2].end:tokens[end].start], ",") {
+				if c = unquoteIdentifier(strings.TrimSpace(c)); c != "" {
+					columns = append(columns, c)
+				}
+			}
+			edits = append(edits, bigQueryEdit{sparkEdit{t.start, tokens[end].end, "*"}, columns})
+			i = end
+			continue
+		}
+
+		word := t.word()
+		switch {
+		case word == "FROM":
+			inFrom[len(inFrom)-1] = true
+		case bigQueryFromEnd[word]:
+			inFrom[len(inFrom)-1] = false
+		}
+		if !bigQueryTableKeywords[word] && (t.text != "," || !inFrom[len(inFrom)-1]) {
+			continue
+		}
+		name, next := qualifiedName(sql, tokens, i+1)
+		if path, ok := bigQueryPath(name); ok {
+			edits = append(edits, bigQueryEdit{sparkEdit: sparkEdit{tokens[i+1].start, tokens[next-1].end, path}})
+			i = next - 1
+		}
+	}
+	if len(edits) == 0 {
+		return sql, nil, false
+	}
+
+	var b strings.Builder
+	var excepts map[int][]string
+	last, runes := 0, 0
+	for _, e := range edits {
+		b.WriteString(sql[last:e.start])
+		runes += utf8.RuneCountInString(sql[last:e.start])
+		if e.except != nil {
+			if excepts == nil {
+				excepts = make(map[int][]string)
+			}
+			excepts[runes] = e.except
+		}
+		b.WriteString(e.text)
+		runes += utf8.RuneCountInString(e.text)
+		last = e.end
+	}
+	b.WriteString(sql[last:])
+	return b.String(), excepts, true
+}
+
+// bigQueryPath returns a table path of three or more parts, or with parts
+// quoted together, as the two-part name `project.dataset`.`table`.
+func bigQueryPath(name string) (string, bool) {
+	var parts []string
+	quotedDots := false
+	for len(name) > 0 {
+		var part string
+		if name[0] == '`' {
+			end := strings.IndexByte(name[1:], '`')
+			if end < 0 {
+				return "", false
+			}
+			part, name = name[1:end+1], name[end+2:]
+			if strings.Contains(part, ".") {
+				quotedDots = true
+			}
+			parts = append(parts, strings.Split(part, ".")...)
+		} else {
+			end := strings.IndexByte(name, '.')
+			if end < 0 {
+				end = len(name)
+			}
+			part, name = strings.TrimSpace(name[:end]), name[end:]
+			parts = append(parts, unquoteIdentifier(part))
+		}
+		name = strings.TrimPrefix(strings.TrimSpace(name), ".")
+		name = strings.TrimSpace(name)
+	}
+	if len(parts) < 3 && !quotedDots || len(parts) < 2 {
+		return "", false
+	}
+	n := len(parts)
+	return "`" + strings.Join(parts[:n-1], ".") + "`.`" + parts[n-1] + "`", true
+}
+
+// dropTableSuffix removes the _TABLE_SUFFIX pseudo column of wildcard
+// tables from the sources, unresolved references and usages of result.
+func dropTableSuffix(result *LineageResult) {
+	if !slices.ContainsFunc(result.Scans, func(s TableScan) bool { return strings.HasSuffix(s.Table, "*") }) {
+		return
+	}
+	isSuffix := func(table, column string) bool {
+		return strings.HasSuffix(table, "*") && strings.EqualFold(column, tableSuffix)
+	}
+	for i := range result.Columns {
+		col := &result.Columns[i]
+		sources := col.Sources[:0]
+		for _, s := range col.Sources {
+			if !isSuffix(s.Table, s.Column) {
+				sources = append(sources, s)
+			}
+		}
+		col.Sources = sources
+	}
+	unresolved := result.Unresolved[:0]
+	for _, ref := range result.Unresolved {
+		if !strings.EqualFold(ref.Column, tableSuffix) {
+			unresolved = append(unresolved, ref)
+		}
+	}
+	result.Unresolved = unresolved
+	usages := result.Usages[:0]
+	for _, u := range result.Usages {
+		if !isSuffix(u.Column.Table, u.Column.Column) {
+			usages = append(usages, u)
+		}
+	}
+	result.Usages = usages
+}
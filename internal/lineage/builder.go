@@ -1,6 +1,8 @@
 package lineage
 
 import (
+	"strings"
+
 	"go-metadata/internal/lineage/ast"
 	"go-metadata/internal/lineage/parser"
 
@@ -12,12 +14,31 @@ type scopeMarker struct {
 	queryType string // "select", "subquery", "exists", etc.
 }
 
+// clauseMarker marks the beginning of a clause on the stack; the expressions
+// pushed above it belong to the clause.
+type clauseMarker struct{}
+
+// clause holds the expressions of a WHERE, GROUP BY, HAVING or ORDER BY
+// clause until the enclosing query term is built.
+type clause struct {
+	kind  string // "where", "group_by", "having", "order_by"
+	exprs []ast.Expression
+	desc  []bool // ORDER BY only
+	raw   string
+}
+
 // ASTBuilder builds custom AST from ANTLR parse tree.
 type ASTBuilder struct {
 	*parser.BaseSQLParserListener
 	stack      []interface{}
 	sourceSQL  string // Original SQL string for extracting text with spaces
 	queryDepth int    // Track nested query depth
+	// starExcept holds the columns excepted by the stars of BigQuery
+	// SELECT * EXCEPT, by the offset of the star.
+	starExcept map[int][]string
+	// comparisons holds the stack size at the start of each comparison
+	// being built.
+	comparisons []int
 }
 
 // NewASTBuilder creates a new AST builder.
@@ -138,11 +159,24 @@ loop:
 			} else {
 				fromClause.Tables = append([]*ast.TableSource{v}, fromClause.Tables...)
 			}
+		case *clause:
+			switch v.kind {
+			case "where":
+				stmt.Where = predicate(v.exprs, v.raw)
+			case "having":
+				stmt.Having = predicate(v.exprs, v.raw)
+			case "group_by":
+				stmt.GroupBy = v.exprs
+			case "order_by":
+				for i, expr := range v.exprs {
+					stmt.OrderBy = append(stmt.OrderBy, &ast.OrderByElement{Expr: expr, Desc: i < len(v.desc) && v.desc[i]})
+				}
+			}
 		case *ast.SelectStmt:
 			// This is a subquery result, skip it (already processed)
 			continue
 		case *ast.ColumnRefExpr, *ast.BinaryExpr, *ast.LiteralExpr, *ast.FunctionCallExpr:
-			// Skip expressions from ON/WHERE/HAVING conditions
+			// Skip expressions of unsupported clauses
 			continue
 		default:
 			// Skip unknown items
@@ -161,7 +195,7 @@ loop:
 // ExitSelectAll is called when exiting selectAll (*).
 func (b *ASTBuilder) ExitSelectAll(ctx *parser.SelectAllContext) {
 	b.push(&ast.AliasedExpr{
-		Expr: &ast.StarExpr{Table: ""},
+		Expr: &ast.StarExpr{Table: "", Except: b.starExcept[ctx.GetStop().GetStop()]},
 	})
 }
 
@@ -172,7 +206,7 @@ func (b *ASTBuilder) ExitSelectTableAll(ctx *parser.SelectTableAllContext) {
 		tableName = getText(ctx.TableName())
 	}
 	b.push(&ast.AliasedExpr{
-		Expr: &ast.StarExpr{Table: tableName},
+		Expr: &ast.StarExpr{Table: tableName, Except: b.starExcept[ctx.GetStop().GetStop()]},
 	})
 }
 
@@ -226,9 +260,14 @@ func (b *ASTBuilder) ExitFuncExpr(ctx *parser.FuncExprContext) {
 		exprList := funcCall.ExpressionList().(*parser.ExpressionListContext)
 		argCount := len(exprList.AllExpression())
 		for i := 0; i < argCount; i++ {
-			if expr, ok := b.pop().(ast.Expression); ok {
-				args = append([]ast.Expression{expr}, args...)
+			// Arguments of unsupported expression types push nothing; never
+			// pop past the expressions into enclosing clauses and scopes.
+			expr, ok := b.peek().(ast.Expression)
+			if !ok {
+				break
 			}
+			b.pop()
+			args = append([]ast.Expression{expr}, args...)
 		}
 	}
 
@@ -273,6 +312,35 @@ func (b *ASTBuilder) ExitMulDivExpr(ctx *parser.MulDivExprContext) {
 	})
 }
 
+// EnterComparisonExpr is called when entering comparisonExpr.
+func (b *ASTBuilder) EnterComparisonExpr(ctx *parser.ComparisonExprContext) {
+	b.comparisons = append(b.comparisons, len(b.stack))
+}
+
+// ExitComparisonExpr is called when exiting comparisonExpr. The operands are
+// combined only if both pushed exactly one expression; otherwise they are
+// left on the stack as they are.
+func (b *ASTBuilder) ExitComparisonExpr(ctx *parser.ComparisonExprContext) {
+	start := b.comparisons[len(b.comparisons)-1]
+	b.comparisons = b.comparisons[:len(b.comparisons)-1]
+	if len(b.stack)-start != 2 {
+		return
+	}
+	left, lok := b.stack[start].(ast.Expression)
+	right, rok := b.stack[start+1].(ast.Expression)
+	if !lok || !rok {
+		return
+	}
+
+	b.stack = b.stack[:start]
+	b.push(&ast.BinaryExpr{
+		Left:     left,
+		Operator: ctx.GetOp().GetText(),
+		Right:    right,
+		RawText:  b.getSourceText(ctx),
+	})
+}
+
 // ExitLiteralExpr is called when exiting literalExpr.
 func (b *ASTBuilder) ExitLiteralExpr(ctx *parser.LiteralExprContext) {
 	literal := ctx.Literal().(*parser.LiteralContext)
@@ -322,8 +390,11 @@ func (b *ASTBuilder) ExitCaseExpr(ctx *parser.CaseExprContext) {
 	}
 
 	// Pop operand if exists (CASE expr WHEN ...)
-	allExprs := caseExprCtx.AllExpression()
-	if len(allExprs) > whenCount*2 {
+	branchCount := whenCount * 2
+	if caseExprCtx.ELSE() != nil {
+		branchCount++
+	}
+	if len(caseExprCtx.AllExpression()) > branchCount {
 		if operand, ok := b.pop().(ast.Expression); ok {
 			caseExpr.Operand = operand
 		}
@@ -350,6 +421,144 @@ func (b *ASTBuilder) ExitScalarSubqueryExpr(ctx *parser.ScalarSubqueryExprContex
 	})
 }
 
+// EnterJoinPart is called when entering joinPart.
+func (b *ASTBuilder) EnterJoinPart(ctx *parser.JoinPartContext) {
+	b.push(&clauseMarker{})
+}
+
+// ExitJoinPart is called when exiting joinPart. The joined table and the ON
+// condition are attached to the table source the join belongs to.
+func (b *ASTBuilder) ExitJoinPart(ctx *parser.JoinPartContext) {
+	join := &ast.JoinClause{Type: "INNER"}
+	if ctx.JoinType() != nil {
+		join.Type = strings.ToUpper(getText(ctx.JoinType()))
+	}
+
+	var operands []ast.Expression
+	for _, item := range b.popClause() {
+		switch v := item.(type) {
+		case *ast.TableSource:
+			join.Table = v
+		case ast.Expression:
+			operands = append(operands, v)
+		}
+	}
+	if ctx.ON() != nil {
+		join.Condition = predicate(operands, b.getSourceText(ctx.Expression()))
+	}
+
+	if left, ok := b.peek().(*ast.TableSource); ok {
+		left.Joins = append(left.Joins, join)
+	} else if join.Table != nil {
+		b.push(join.Table)
+	}
+}
+
+// EnterWhereClause is called when entering whereClause.
+func (b *ASTBuilder) EnterWhereClause(ctx *parser.WhereClauseContext) {
+	if inQueryTerm(ctx) {
+		b.push(&clauseMarker{})
+	}
+}
+
+// ExitWhereClause is called when exiting whereClause.
+func (b *ASTBuilder) ExitWhereClause(ctx *parser.WhereClauseContext) {
+	if inQueryTerm(ctx) {
+		b.push(&clause{kind: "where", exprs: b.popClauseExprs(), raw: b.getSourceText(ctx.Expression())})
+	}
+}
+
+// EnterGroupByClause is called when entering groupByClause.
+func (b *ASTBuilder) EnterGroupByClause(ctx *parser.GroupByClauseContext) {
+	b.push(&clauseMarker{})
+}
+
+// ExitGroupByClause is called when exiting groupByClause.
+func (b *ASTBuilder) ExitGroupByClause(ctx *parser.GroupByClauseContext) {
+	b.push(&clause{kind: "group_by", exprs: b.popClauseExprs()})
+}
+
+// EnterHavingClause is called when entering havingClause.
+func (b *ASTBuilder) EnterHavingClause(ctx *parser.HavingClauseContext) {
+	b.push(&clauseMarker{})
+}
+
+// ExitHavingClause is called when exiting havingClause.
+func (b *ASTBuilder) ExitHavingClause(ctx *parser.HavingClauseContext) {
+	b.push(&clause{kind: "having", exprs: b.popClauseExprs(), raw: b.getSourceText(ctx.Expression())})
+}
+
+// EnterOrderByClause is called when entering orderByClause. ORDER BY inside
+// window specifications and WITHIN GROUP is not collected.
+func (b *ASTBuilder) EnterOrderByClause(ctx *parser.OrderByClauseContext) {
+	if inQueryTerm(ctx) {
+		b.push(&clauseMarker{})
+	}
+}
+
+// ExitOrderByClause is called when exiting orderByClause.
+func (b *ASTBuilder) ExitOrderByClause(ctx *parser.OrderByClauseContext) {
+	if !inQueryTerm(ctx) {
+		return
+	}
+	c := &clause{kind: "order_by", exprs: b.popClauseExprs()}
+	if elements := ctx.AllOrderByElement(); len(elements) == len(c.exprs) {
+		for _, el := range elements {
+			c.desc = append(c.desc, el.(*parser.OrderByElementContext).DESC() != nil)
+		}
+	}
+	b.push(c)
+}
+
+// popClause pops the items pushed since the last clause marker, in push
+// order. It never pops past a query scope marker.
+func (b *ASTBuilder) popClause() []interface{} {
+	var items []interface{}
+	for len(b.stack) > 0 {
+		if _, ok := b.peek().(*scopeMarker); ok {
+			break
+		}
+		item := b.pop()
+		if _, ok := item.(*clauseMarker); ok {
+			break
+		}
+		items = append([]interface{}{item}, items...)
+	}
+	return items
+}
+
+// popClauseExprs pops the expressions of a clause; nested queries, such as
+// IN and EXISTS subqueries, become subquery expressions.
+func (b *ASTBuilder) popClauseExprs() []ast.Expression {
+	var exprs []ast.Expression
+	for _, item := range b.popClause() {
+		switch v := item.(type) {
+		case ast.Expression:
+			exprs = append(exprs, v)
+		case *ast.SelectStmt:
+			exprs = append(exprs, &ast.SubqueryExpr{Query: v})
+		}
+	}
+	return exprs
+}
+
+// inQueryTerm reports whether a clause belongs directly to a SELECT.
+func inQueryTerm(ctx antlr.ParserRuleContext) bool {
+	_, ok := ctx.GetParent().(*parser.QueryTermContext)
+	return ok
+}
+
+// predicate combines the expressions of a condition.
+func predicate(exprs []ast.Expression, raw string) ast.Expression {
+	switch len(exprs) {
+	case 0:
+		return nil
+	case 1:
+		return exprs[0]
+	}
+	return &ast.PredicateExpr{Operands: exprs, RawText: raw}
+}
+
 // ExitFromClause is called when exiting fromClause.
 func (b *ASTBuilder) ExitFromClause(ctx *parser.FromClauseContext) {
 	from := &ast.FromClause{
@@ -402,6 +611,36 @@ func (b *ASTBuilder) ExitTableNameFactor(ctx *parser.TableNameFactorContext) {
 	})
 }
 
+// ExitSubqueryFactor is called when exiting subqueryFactor, a derived table
+// in FROM: (SELECT ...) alias.
+func (b *ASTBuilder) ExitSubqueryFactor(ctx *parser.SubqueryFactorContext) {
+	b.pushDerivedTable(ctx.Alias())
+}
+
+// ExitLateralSubqueryFactor is called when exiting lateralSubqueryFactor.
+func (b *ASTBuilder) ExitLateralSubqueryFactor(ctx *parser.LateralSubqueryFactorContext) {
+	b.pushDerivedTable(ctx.Alias())
+}
+
+// pushDerivedTable wraps the query on top of the stack in a table source.
+func (b *ASTBuilder) pushDerivedTable(aliasCtx parser.IAliasContext) {
+	query, ok := b.peek().(*ast.SelectStmt)
+	if !ok {
+		return
+	}
+	b.pop()
+
+	alias := ""
+	if aliasCtx != nil {
+		alias = getIdentifierText(getText(aliasCtx.(*parser.AliasContext).Identifier()))
+	}
+	b.push(&ast.TableSource{
+		Subquery: query,
+		Alias:    alias,
+		Joins:    make([]*ast.JoinClause, 0),
+	})
+}
+
 // ExitInsertStatement is called when exiting insertStatement.
 func (b *ASTBuilder) ExitInsertStatement(ctx *parser.InsertStatementContext) {
 	stmt := &ast.InsertStmt{}
@@ -441,6 +680,195 @@ func (b *ASTBuilder) ExitInsertStatement(ctx *parser.InsertStatementContext) {
 	b.push(stmt)
 }
 
+// EnterCreateTableStatement is called when entering createTableStatement.
+func (b *ASTBuilder) EnterCreateTableStatement(ctx *parser.CreateTableStatementContext) {
+	b.push(&clauseMarker{})
+}
+
+// ExitCreateTableStatement is called when exiting createTableStatement. Only
+// CREATE TABLE ... AS SELECT produces a statement; the column definitions of
+// other CREATE TABLE statements carry no lineage.
+func (b *ASTBuilder) ExitCreateTableStatement(ctx *parser.CreateTableStatementContext) {
+	var query *ast.SelectStmt
+	for _, item := range b.popClause() {
+		if stmt, ok := item.(*ast.SelectStmt); ok {
+			query = stmt
+		}
+	}
+	if ctx.SelectStatement() == nil || query == nil {
+		return
+	}
+
+	stmt := &ast.CreateTableAsStmt{
+		Table:  tableRef(ctx.TableName()),
+		Select: query,
+	}
+	if ctx.TableElementList() != nil {
+		for _, el := range ctx.TableElementList().(*parser.TableElementListContext).AllTableElement() {
+			if def := el.(*parser.TableElementContext).ColumnDefinition(); def != nil {
+				stmt.Columns = append(stmt.Columns, getIdentifierText(getText(def.(*parser.ColumnDefinitionContext).Identifier())))
+			}
+		}
+	}
+	b.push(stmt)
+}
+
+// mergeOperand holds one expression of a MERGE statement: the ON condition,
+// a clause condition, an assigned value or an inserted value. Expr is nil for
+// expressions the builder does not support.
+type mergeOperand struct {
+	expr ast.Expression
+}
+
+// EnterEveryRule isolates the operands of a MERGE statement, so that each
+// leaves exactly one item on the stack however many it pushes.
+func (b *ASTBuilder) EnterEveryRule(ctx antlr.ParserRuleContext) {
+	if isMergeOperand(ctx) {
+		b.push(&clauseMarker{})
+	}
+}
+
+// ExitEveryRule completes a MERGE operand started in EnterEveryRule.
+func (b *ASTBuilder) ExitEveryRule(ctx antlr.ParserRuleContext) {
+	if isMergeOperand(ctx) {
+		b.push(&mergeOperand{expr: predicate(b.popClauseExprs(), b.getSourceText(ctx))})
+	}
+}
+
+// isMergeOperand reports whether ctx is an expression directly below a MERGE
+// statement, one of its clauses, assignments or inserted value lists.
+func isMergeOperand(ctx antlr.ParserRuleContext) bool {
+	if _, ok := ctx.(parser.IExpressionContext); !ok {
+		return false
+	}
+	switch parent := ctx.GetParent().(type) {
+	case *parser.MergeStatementContext, *parser.MergeClauseContext:
+		return true
+	case *parser.UpdateElementContext:
+		_, ok := parent.GetParent().(*parser.MergeUpdateClauseContext)
+		return ok
+	case *parser.ExpressionListContext:
+		_, ok := parent.GetParent().(*parser.MergeInsertClauseContext)
+		return ok
+	}
+	return false
+}
+
+// popMergeOperand pops the operand on top of the stack.
+func (b *ASTBuilder) popMergeOperand() ast.Expression {
+	if op, ok := b.peek().(*mergeOperand); ok {
+		b.pop()
+		return op.expr
+	}
+	return nil
+}
+
+// ExitUpdateElement is called when exiting updateElement. Only the
+// assignments of MERGE statements are built.
+func (b *ASTBuilder) ExitUpdateElement(ctx *parser.UpdateElementContext) {
+	if _, ok := ctx.GetParent().(*parser.MergeUpdateClauseContext); !ok {
+		return
+	}
+	column := ""
+	if colRef := ctx.ColumnRef().(*parser.ColumnRefContext); colRef.ColumnName() != nil {
+		column = getIdentifierText(getText(colRef.ColumnName()))
+	}
+	b.push(&ast.Assignment{
+		Column: column,
+		Value:  b.popMergeOperand(),
+	})
+}
+
+// ExitMergeUpdateClause is called when exiting mergeUpdateClause.
+func (b *ASTBuilder) ExitMergeUpdateClause(ctx *parser.MergeUpdateClauseContext) {
+	mc := &ast.MergeClause{}
+	for range ctx.AllUpdateElement() {
+		if a, ok := b.peek().(*ast.Assignment); ok {
+			b.pop()
+			mc.Assignments = append([]*ast.Assignment{a}, mc.Assignments...)
+		}
+	}
+	b.push(mc)
+}
+
+// ExitMergeInsertClause is called when exiting mergeInsertClause.
+func (b *ASTBuilder) ExitMergeInsertClause(ctx *parser.MergeInsertClauseContext) {
+	mc := &ast.MergeClause{}
+	if ctx.ColumnList() != nil {
+		for _, id := range ctx.ColumnList().(*parser.ColumnListContext).AllIdentifier() {
+			mc.Columns = append(mc.Columns, getIdentifierText(getText(id)))
+		}
+	}
+	values := ctx.ExpressionList().(*parser.ExpressionListContext).AllExpression()
+	mc.Values = make([]ast.Expression, len(values))
+	for i := len(values) - 1; i >= 0; i-- {
+		mc.Values[i] = b.popMergeOperand()
+	}
+	b.push(mc)
+}
+
+// ExitMergeClause is called when exiting mergeClause.
+func (b *ASTBuilder) ExitMergeClause(ctx *parser.MergeClauseContext) {
+	mc, ok := b.peek().(*ast.MergeClause)
+	if ok && ctx.DELETE() == nil {
+		b.pop()
+	} else {
+		mc = &ast.MergeClause{Delete: true}
+	}
+	mc.Matched = ctx.NOT() == nil
+	if ctx.Expression() != nil {
+		mc.Condition = b.popMergeOperand()
+	}
+	b.push(mc)
+}
+
+// ExitMergeStatement is called when exiting mergeStatement.
+func (b *ASTBuilder) ExitMergeStatement(ctx *parser.MergeStatementContext) {
+	stmt := &ast.MergeStmt{Target: tableRef(ctx.TableName())}
+
+	for range ctx.AllMergeClause() {
+		if mc, ok := b.peek().(*ast.MergeClause); ok {
+			b.pop()
+			stmt.Clauses = append([]*ast.MergeClause{mc}, stmt.Clauses...)
+		}
+	}
+	stmt.On = b.popMergeOperand()
+	if ts, ok := b.peek().(*ast.TableSource); ok {
+		b.pop()
+		stmt.Source = ts
+	}
+
+	// The target alias precedes USING; an alias after the source table
+	// reference belongs to the source.
+	using := ctx.USING().GetSymbol().GetTokenIndex()
+	for _, a := range ctx.AllAlias() {
+		alias := getIdentifierText(getText(a.(*parser.AliasContext).Identifier()))
+		if a.GetStart().GetTokenIndex() < using {
+			stmt.Target.Alias = alias
+		} else if stmt.Source != nil {
+			stmt.Source.Alias = alias
+			if stmt.Source.Table != nil {
+				stmt.Source.Table.Alias = alias
+			}
+		}
+	}
+
+	b.push(stmt)
+}
+
+// tableRef builds a table reference from a tableName context.
+func tableRef(ctx parser.ITableNameContext) *ast.TableRef {
+	ref := &ast.TableRef{}
+	tableNameCtx := ctx.(*parser.TableNameContext)
+	if tableNameCtx.DatabaseName() != nil {
+		ref.Database = getIdentifierText(getText(tableNameCtx.DatabaseName()))
+	}
+	if tableNameCtx.Identifier() != nil {
+		ref.Table = getIdentifierText(getText(tableNameCtx.Identifier()))
+	}
+	return ref
+}
+
 // ExitWithClause is called when exiting withClause.
 func (b *ASTBuilder) ExitWithClause(ctx *parser.WithClauseContext) {
 	wc := &ast.WithClause{
@@ -462,9 +890,14 @@ func (b *ASTBuilder) ExitWithClause(ctx *parser.WithClauseContext) {
 // ExitCteDefinition is called when exiting cteDefinition.
 func (b *ASTBuilder) ExitCteDefinition(ctx *parser.CteDefinitionContext) {
 	name := ""
-	// CTE name is the first identifier
-	if len(ctx.AllIdentifier()) > 0 {
-		name = getIdentifierText(getText(ctx.Identifier(0)))
+	var columns []string
+	// CTE name is the first identifier, followed by the optional column list
+	for i, id := range ctx.AllIdentifier() {
+		if i == 0 {
+			name = getIdentifierText(getText(id))
+		} else {
+			columns = append(columns, getIdentifierText(getText(id)))
+		}
 	}
 
 	var query *ast.SelectStmt
@@ -473,8 +906,9 @@ func (b *ASTBuilder) ExitCteDefinition(ctx *parser.CteDefinitionContext) {
 	}
 
 	b.push(&ast.CTE{
-		Name:  name,
-		Query: query,
+		Name:    name,
+		Columns: columns,
+		Query:   query,
 	})
 }
 
@@ -502,6 +936,12 @@ func getIdentifierText(text string) string {
 
 // ParseSQL parses SQL string and returns AST.
 func ParseSQL(sql string) (ast.Statement, error) {
+	return parseSQL(sql, nil)
+}
+
+// parseSQL parses sql, whose stars at the offsets of starExcept leave out
+// the columns it maps them to.
+func parseSQL(sql string, starExcept map[int][]string) (ast.Statement, error) {
 	input := antlr.NewInputStream(sql)
 	lexer := parser.NewSQLLexer(input)
 	stream := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
@@ -521,6 +961,7 @@ func ParseSQL(sql string) (ast.Statement, error) {
 
 	// Build AST with source SQL to preserve spaces
 	builder := NewASTBuilderWithSource(sql)
+	builder.starExcept = starExcept
 	antlr.ParseTreeWalkerDefault.Walk(builder, tree)
 
 	return builder.Result(), nil
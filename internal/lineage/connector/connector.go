@@ -0,0 +1,167 @@
+// Package connector derives lineage between Kafka topics and warehouse tables
+// from connector configurations: Kafka Connect sink configs and Flink table
+// definitions (CREATE TABLE ... WITH ('connector' = ...)).
+package connector
+
+import (
+	"fmt"
+	"time"
+
+	"go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/metadata"
+)
+
+// DefaultTopicNamespace is the database name under which Kafka topics appear
+// in the lineage graph, e.g. kafka.orders.
+const DefaultTopicNamespace = "kafka"
+
+// Resolver turns connector configurations into lineage.
+type Resolver struct {
+	// TopicNamespace is the database name used for topic datasets.
+	TopicNamespace string
+	// KnownTopics are the topics known to exist, used to expand topics.regex.
+	KnownTopics []string
+
+	catalog  lineage.Catalog
+	bindings map[string]*Binding
+}
+
+// NewResolver creates a resolver. catalog is optional; when it knows the
+// schema of a sink's destination table, column-level edges are emitted by
+// matching topic fields to columns of the same name.
+func NewResolver(catalog lineage.Catalog) *Resolver {
+	return &Resolver{
+		TopicNamespace: DefaultTopicNamespace,
+		catalog:        catalog,
+		bindings:       make(map[string]*Binding),
+	}
+}
+
+// TopicRef returns the dataset reference of a topic.
+func (r *Resolver) TopicRef(topic string) lineage.ColumnRef {
+	return lineage.ColumnRef{Database: r.TopicNamespace, Table: topic}
+}
+
+// AddFlinkTables registers the connector-backed tables among schemas, as
+// collected from Flink DDL. Tables without a supported connector are ignored.
+func (r *Resolver) AddFlinkTables(schemas []*metadata.TableSchema) {
+	for _, schema := range schemas {
+		if b := r.flinkBinding(schema); b != nil {
+			r.bindings[b.Table] = b
+		}
+	}
+}
+
+// Bindings returns the registered Flink table bindings.
+func (r *Resolver) Bindings() map[string]*Binding {
+	return r.bindings
+}
+
+// Rewrite replaces references to connector-backed Flink tables in a lineage
+// result with the datasets behind them, so that e.g. INSERT INTO jdbc_sink
+// SELECT ... FROM kafka_source yields edges from the topic to the warehouse
+// table. References to other tables are kept as is.
+func (r *Resolver) Rewrite(result *lineage.LineageResult) *lineage.LineageResult {
+	if result == nil || len(r.bindings) == 0 {
+		return result
+	}
+
+	rewritten := &lineage.LineageResult{Unresolved: result.Unresolved}
+	for _, col := range result.Columns {
+		var sources []lineage.ColumnRef
+		for _, src := range col.Sources {
+			sources = append(sources, r.resolveRef(src)...)
+		}
+		for _, target := range r.resolveRef(col.Target) {
+			target.Confidence = ""
+			rewritten.Columns = append(rewritten.Columns, lineage.ColumnLineage{
+				Target:    target,
+				Sources:   sources,
+				Operators: col.Operators,
+			})
+		}
+	}
+	return rewritten
+}
+
+// resolveRef maps a column of a bound Flink table to the same column of each
+// dataset behind it.
+func (r *Resolver) resolveRef(ref lineage.ColumnRef) []lineage.ColumnRef {
+	b, ok := r.bindings[ref.TableName()]
+	if !ok {
+		return []lineage.ColumnRef{ref}
+	}
+	refs := make([]lineage.ColumnRef, 0, len(b.Datasets))
+	for _, ds := range b.Datasets {
+		ds.Column = ref.Column
+		ds.Confidence = ref.Confidence
+		refs = append(refs, ds)
+	}
+	return refs
+}
+
+// SinkLineage returns the job node of a Kafka Connect sink connector, whose
+// inputs are the consumed topics and outputs the destination tables, along
+// with column-level edges for destinations whose schema is in the catalog.
+func (r *Resolver) SinkLineage(sink *SinkConnector) (*lineage.Job, *lineage.LineageResult, error) {
+	routes, err := sink.Routes(r.KnownTopics)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	job := &lineage.Job{
+		Name: sink.Name,
+		Type: lineage.JobTypeKafkaSink,
+		Properties: map[string]string{
+			"connector.class": sink.Config["connector.class"],
+		},
+	}
+	result := &lineage.LineageResult{}
+	for _, route := range routes {
+		topic := r.TopicRef(route.Topic)
+		job.Inputs = append(job.Inputs, topic.TableName())
+		job.Outputs = append(job.Outputs, route.Table.TableName())
+
+		for _, column := range r.columns(route.Table) {
+			source := topic
+			source.Column = column
+			source.Confidence = lineage.ConfidenceCatalog
+			target := route.Table
+			target.Column = column
+			result.Columns = append(result.Columns, lineage.ColumnLineage{
+				Target:  target,
+				Sources: []lineage.ColumnRef{source},
+			})
+		}
+	}
+	return job, result, nil
+}
+
+// Apply records the lineage of the given sink connectors in g as of at.
+func (r *Resolver) Apply(g *lineage.Graph, sinks []*SinkConnector, at time.Time) error {
+	for _, sink := range sinks {
+		job, result, err := r.SinkLineage(sink)
+		if err != nil {
+			return err
+		}
+		if err := g.RegisterJob(job); err != nil {
+			return fmt.Errorf("register connector %s: %w", sink.Name, err)
+		}
+		if err := g.AttachStatement(job.Name, result, "", at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columns returns the columns of a table from the catalog, or nil if unknown.
+func (r *Resolver) columns(table lineage.ColumnRef) []string {
+	if r.catalog == nil {
+		return nil
+	}
+	schema, err := r.catalog.GetTableSchema(table.Database, table.Table)
+	if err != nil || schema == nil {
+		return nil
+	}
+	return schema.Columns
+}
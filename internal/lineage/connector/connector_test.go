@@ -0,0 +1,212 @@
+package connector
+
+import (
+	"testing"
+	"time"
+
+	"go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/metadata"
+)
+
+const flinkDDL = `
+CREATE TABLE orders (
+    order_id BIGINT,
+    amount DECIMAL(10, 2)
+) WITH (
+    'connector' = 'kafka',
+    'topic' = 'orders',
+    'format' = 'json'
+);
+CREATE TABLE order_sink (
+    order_id BIGINT,
+    amount DECIMAL(10, 2)
+) WITH (
+    'connector' = 'jdbc',
+    'url' = 'jdbc:mysql://localhost:3306/dw',
+    'table-name' = 'fact_orders'
+);
+CREATE TABLE print_sink (
+    order_id BIGINT
+) WITH (
+    'connector' = 'print'
+);
+`
+
+func TestParseFlinkConnectorOptions(t *testing.T) {
+	schemas, err := metadata.NewDDLParser().ParseMultipleDDL(flinkDDL)
+	if err != nil {
+		t.Fatalf("ParseMultipleDDL failed: %v", err)
+	}
+	if len(schemas) != 3 {
+		t.Fatalf("Expected 3 tables, got %d", len(schemas))
+	}
+	if got := schemas[0].Properties["topic"]; got != "orders" {
+		t.Errorf("Expected topic option 'orders', got %q", got)
+	}
+}
+
+func TestResolver_RewriteFlinkLineage(t *testing.T) {
+	schemas, err := metadata.NewDDLParser().ParseMultipleDDL(flinkDDL)
+	if err != nil {
+		t.Fatalf("ParseMultipleDDL failed: %v", err)
+	}
+	r := NewResolver(nil)
+	r.AddFlinkTables(schemas)
+	if len(r.Bindings()) != 2 {
+		t.Fatalf("Expected 2 bindings, got %d", len(r.Bindings()))
+	}
+
+	result, err := lineage.NewAnalyzer(nil).Analyze("INSERT INTO order_sink SELECT order_id, amount FROM orders")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	rewritten := r.Rewrite(result)
+	if len(rewritten.Columns) != 2 {
+		t.Fatalf("Expected 2 column lineages, got %d", len(rewritten.Columns))
+	}
+	col := rewritten.Columns[1]
+	if col.Target.QualifiedName() != "dw.fact_orders.amount" {
+		t.Errorf("Unexpected target %s", col.Target.QualifiedName())
+	}
+	if len(col.Sources) != 1 || col.Sources[0].QualifiedName() != "kafka.orders.amount" {
+		t.Errorf("Unexpected sources %v", col.Sources)
+	}
+}
+
+func TestParseSinkConnectors(t *testing.T) {
+	data := []byte(`{
+		"jdbc-sink": {"info": {"name": "jdbc-sink", "type": "sink", "config": {
+			"connector.class": "io.confluent.connect.jdbc.JdbcSinkConnector",
+			"topics": "orders",
+			"connection.url": "jdbc:postgresql://pg:5432/dw"}}},
+		"mysql-source": {"info": {"name": "mysql-source", "type": "source", "config": {
+			"connector.class": "io.debezium.connector.mysql.MySqlConnector"}}}
+	}`)
+	sinks, err := ParseSinkConnectors(data)
+	if err != nil {
+		t.Fatalf("ParseSinkConnectors failed: %v", err)
+	}
+	if len(sinks) != 1 || sinks[0].Name != "jdbc-sink" {
+		t.Fatalf("Expected only jdbc-sink, got %v", sinks)
+	}
+
+	single, err := ParseSinkConnectors([]byte(`{"name": "s", "config": {"topics": "a"}}`))
+	if err != nil || len(single) != 1 {
+		t.Fatalf("Expected single connector, got %v (%v)", single, err)
+	}
+}
+
+func TestSinkConnector_Routes(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]string
+		known  []string
+		want   map[string]string
+	}{
+		{
+			name: "jdbc default format",
+			config: map[string]string{
+				"connector.class": classJDBCSink,
+				"topics":          "orders, users",
+				"connection.url":  "jdbc:mysql://db:3306/dw?useSSL=false",
+			},
+			want: map[string]string{"orders": "dw.orders", "users": "dw.users"},
+		},
+		{
+			name: "jdbc table format with regex router",
+			config: map[string]string{
+				"connector.class":              classJDBCSink,
+				"topics.regex":                 "prod\\..*",
+				"table.name.format":            "staging.kafka_${topic}",
+				"transforms":                   "route",
+				"transforms.route.type":        classRegexRouter,
+				"transforms.route.regex":       "prod\\.(.*)",
+				"transforms.route.replacement": "$1",
+			},
+			known: []string{"prod.orders", "dev.orders"},
+			want:  map[string]string{"prod.orders": "staging.kafka_orders"},
+		},
+		{
+			name: "snowflake topic map",
+			config: map[string]string{
+				"connector.class":           classSnowflakeSink,
+				"topics":                    "orders,page-views",
+				"snowflake.database.name":   "RAW",
+				"snowflake.schema.name":     "KAFKA",
+				"snowflake.topic2table.map": "orders:ORDERS_RAW",
+			},
+			want: map[string]string{"orders": "RAW.KAFKA.ORDERS_RAW", "page-views": "RAW.KAFKA.page_views"},
+		},
+		{
+			name: "bigquery",
+			config: map[string]string{
+				"connector.class": classBigQuerySink,
+				"topics":          "orders",
+				"project":         "acme",
+				"defaultDataset":  "events",
+			},
+			want: map[string]string{"orders": "acme.events.orders"},
+		},
+		{
+			name: "clickhouse",
+			config: map[string]string{
+				"connector.class": classClickHouseSink,
+				"topics":          "orders",
+				"database":        "ods",
+				"topic2TableMap":  "orders=orders_local",
+			},
+			want: map[string]string{"orders": "ods.orders_local"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &SinkConnector{Name: tt.name, Config: tt.config}
+			routes, err := sink.Routes(tt.known)
+			if err != nil {
+				t.Fatalf("Routes failed: %v", err)
+			}
+			if len(routes) != len(tt.want) {
+				t.Fatalf("Expected %d routes, got %v", len(tt.want), routes)
+			}
+			for _, route := range routes {
+				if got := route.Table.TableName(); got != tt.want[route.Topic] {
+					t.Errorf("Topic %s: expected %s, got %s", route.Topic, tt.want[route.Topic], got)
+				}
+			}
+		})
+	}
+}
+
+func TestResolver_Apply(t *testing.T) {
+	catalog := metadata.NewMetadataBuilder().AddTable("dw", "orders", []string{"id", "amount"}).BuildCatalog()
+	r := NewResolver(catalog)
+
+	sinks := []*SinkConnector{{
+		Name: "orders-sink",
+		Config: map[string]string{
+			"connector.class": classJDBCSink,
+			"topics":          "orders",
+			"connection.url":  "jdbc:mysql://db:3306/dw",
+		},
+	}}
+
+	g := lineage.NewGraph()
+	if err := r.Apply(g, sinks, time.Now()); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	job, err := g.Job("orders-sink")
+	if err != nil {
+		t.Fatalf("Job not registered: %v", err)
+	}
+	if len(job.Inputs) != 1 || job.Inputs[0] != "kafka.orders" || job.Outputs[0] != "dw.orders" {
+		t.Errorf("Unexpected job inputs/outputs: %v -> %v", job.Inputs, job.Outputs)
+	}
+	if g.Len() != 2 {
+		t.Fatalf("Expected 2 column edges, got %d", g.Len())
+	}
+	if edge := g.Edges()[0]; edge.Source.QualifiedName() != "kafka.orders.amount" || edge.Target.QualifiedName() != "dw.orders.amount" {
+		t.Errorf("Unexpected edge %s", edge.Key())
+	}
+}
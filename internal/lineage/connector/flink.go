@@ -0,0 +1,55 @@
+package connector
+
+import (
+	"strings"
+
+	"go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/metadata"
+)
+
+// Binding maps a logical Flink table to the physical datasets behind it.
+type Binding struct {
+	// Table is the qualified name of the Flink table.
+	Table string
+	// Connector is the value of the table's 'connector' option.
+	Connector string
+	// Datasets are the topics or tables the Flink table reads and writes.
+	Datasets []lineage.ColumnRef
+}
+
+// flinkBinding returns the binding of a Flink table, or nil if its connector
+// is not backed by a Kafka topic or JDBC table.
+func (r *Resolver) flinkBinding(schema *metadata.TableSchema) *Binding {
+	opts := schema.Properties
+	name := schema.Table
+	if schema.Database != "" {
+		name = schema.Database + "." + name
+	}
+	b := &Binding{Table: name, Connector: opts["connector"]}
+
+	switch b.Connector {
+	case "kafka", "upsert-kafka":
+		// 'topic' may list several topics separated by semicolons.
+		for _, topic := range strings.Split(opts["topic"], ";") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				b.Datasets = append(b.Datasets, r.TopicRef(topic))
+			}
+		}
+
+	case "jdbc":
+		table := opts["table-name"]
+		if table == "" {
+			return nil
+		}
+		ref := lineage.ColumnRef{Database: jdbcDatabase(opts["url"]), Table: table}
+		if i := strings.LastIndex(table, "."); i >= 0 {
+			ref = lineage.ColumnRef{Database: table[:i], Table: table[i+1:]}
+		}
+		b.Datasets = append(b.Datasets, ref)
+	}
+
+	if len(b.Datasets) == 0 {
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,260 @@
+package connector
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go-metadata/internal/lineage"
+)
+
+// Kafka Connect sink connector classes with dedicated table naming rules.
+const (
+	classJDBCSink       = "io.confluent.connect.jdbc.JdbcSinkConnector"
+	classSnowflakeSink  = "com.snowflake.kafka.connector.SnowflakeSinkConnector"
+	classBigQuerySink   = "com.wepay.kafka.connect.bigquery.BigQuerySinkConnector"
+	classClickHouseSink = "com.clickhouse.kafka.connect.ClickHouseSinkConnector"
+	classRegexRouter    = "org.apache.kafka.connect.transforms.RegexRouter"
+)
+
+// SinkConnector is a Kafka Connect sink connector configuration as returned by
+// the Connect REST API (GET /connectors/{name}).
+type SinkConnector struct {
+	Name   string            `json:"name"`
+	Config map[string]string `json:"config"`
+}
+
+// Route is a topic landing in a warehouse table through a sink connector.
+type Route struct {
+	Topic string
+	Table lineage.ColumnRef
+}
+
+// ParseSinkConnectors parses Kafka Connect connector configurations. It
+// accepts a single connector ({"name": ..., "config": {...}}), an array of
+// connectors, or the output of GET /connectors?expand=info. Source connectors
+// are skipped.
+func ParseSinkConnectors(data []byte) ([]*SinkConnector, error) {
+	var single SinkConnector
+	if err := json.Unmarshal(data, &single); err == nil && single.Config != nil {
+		return filterSinks([]*SinkConnector{&single}), nil
+	}
+
+	var list []*SinkConnector
+	if err := json.Unmarshal(data, &list); err == nil {
+		return filterSinks(list), nil
+	}
+
+	var expanded map[string]struct {
+		Info *struct {
+			SinkConnector
+			Type string `json:"type"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal(data, &expanded); err != nil {
+		return nil, fmt.Errorf("parse connector configs: %w", err)
+	}
+	names := make([]string, 0, len(expanded))
+	for name := range expanded {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]*SinkConnector, 0, len(names))
+	for _, name := range names {
+		info := expanded[name].Info
+		if info == nil || (info.Type != "" && info.Type != "sink") {
+			continue
+		}
+		sink := info.SinkConnector
+		if sink.Name == "" {
+			sink.Name = name
+		}
+		result = append(result, &sink)
+	}
+	return filterSinks(result), nil
+}
+
+// filterSinks drops connectors without a topics or topics.regex setting, which
+// source connectors never have.
+func filterSinks(connectors []*SinkConnector) []*SinkConnector {
+	result := make([]*SinkConnector, 0, len(connectors))
+	for _, c := range connectors {
+		if c != nil && (c.Config["topics"] != "" || c.Config["topics.regex"] != "") {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// Topics returns the topics consumed by the connector. Topics matching
+// topics.regex are taken from known, the topics known to exist.
+func (c *SinkConnector) Topics(known []string) ([]string, error) {
+	var topics []string
+	for _, t := range strings.Split(c.Config["topics"], ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			topics = append(topics, t)
+		}
+	}
+
+	if pattern := c.Config["topics.regex"]; pattern != "" {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("connector %s: invalid topics.regex: %w", c.Name, err)
+		}
+		for _, t := range known {
+			if re.MatchString(t) {
+				topics = append(topics, t)
+			}
+		}
+	}
+	return topics, nil
+}
+
+// Routes returns the destination table of each topic consumed by the
+// connector, applying RegexRouter transforms and the connector's table naming
+// rules.
+func (c *SinkConnector) Routes(known []string) ([]Route, error) {
+	topics, err := c.Topics(known)
+	if err != nil {
+		return nil, err
+	}
+	routers, err := c.regexRouters()
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make([]Route, 0, len(topics))
+	for _, topic := range topics {
+		routed := topic
+		for _, r := range routers {
+			routed = r.apply(routed)
+		}
+		routes = append(routes, Route{Topic: topic, Table: c.destination(routed)})
+	}
+	return routes, nil
+}
+
+// destination returns the table a (possibly renamed) topic lands in.
+func (c *SinkConnector) destination(topic string) lineage.ColumnRef {
+	cfg := c.Config
+	switch cfg["connector.class"] {
+	case classSnowflakeSink:
+		table := mappedTable(cfg["snowflake.topic2table.map"], ":", topic)
+		if table == "" {
+			table = sanitizeName(topic)
+		}
+		return lineage.ColumnRef{Database: joinNonEmpty(cfg["snowflake.database.name"], cfg["snowflake.schema.name"]), Table: table}
+
+	case classBigQuerySink:
+		table := mappedTable(cfg["topic2TableMap"], ":", topic)
+		if table == "" {
+			table = topic
+			if cfg["sanitizeTopics"] == "true" {
+				table = sanitizeName(topic)
+			}
+		}
+		dataset := cfg["defaultDataset"]
+		if project := cfg["project"]; project != "" && dataset != "" {
+			dataset = project + "." + dataset
+		}
+		return lineage.ColumnRef{Database: dataset, Table: table}
+
+	case classClickHouseSink:
+		table := mappedTable(cfg["topic2TableMap"], "=", topic)
+		if table == "" {
+			table = topic
+		}
+		return lineage.ColumnRef{Database: cfg["database"], Table: table}
+	}
+
+	// JDBC sink and connectors following its table.name.format convention.
+	format := cfg["table.name.format"]
+	if format == "" {
+		format = "${topic}"
+	}
+	name := strings.ReplaceAll(format, "${topic}", topic)
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return lineage.ColumnRef{Database: name[:i], Table: name[i+1:]}
+	}
+	return lineage.ColumnRef{Database: jdbcDatabase(cfg["connection.url"]), Table: name}
+}
+
+type regexRouter struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+func (r regexRouter) apply(topic string) string {
+	if !r.re.MatchString(topic) {
+		return topic
+	}
+	return r.re.ReplaceAllString(topic, r.replacement)
+}
+
+// regexRouters returns the RegexRouter transforms of the connector in order.
+func (c *SinkConnector) regexRouters() ([]regexRouter, error) {
+	var routers []regexRouter
+	for _, name := range strings.Split(c.Config["transforms"], ",") {
+		name = strings.TrimSpace(name)
+		prefix := "transforms." + name + "."
+		if name == "" || c.Config[prefix+"type"] != classRegexRouter {
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + c.Config[prefix+"regex"] + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("connector %s: invalid regex of transform %s: %w", c.Name, name, err)
+		}
+		routers = append(routers, regexRouter{re: re, replacement: javaReplacement(c.Config[prefix+"replacement"])})
+	}
+	return routers, nil
+}
+
+var javaGroupRef = regexp.MustCompile(`\$(\d+)`)
+
+// javaReplacement converts Java-style group references ($1) to Go's ${1}.
+func javaReplacement(s string) string {
+	return javaGroupRef.ReplaceAllString(s, "$${$1}")
+}
+
+// mappedTable looks up topic in a "topic<sep>table,..." mapping.
+func mappedTable(mapping, sep, topic string) string {
+	for _, pair := range strings.Split(mapping, ",") {
+		t, table, ok := strings.Cut(pair, sep)
+		if ok && strings.TrimSpace(t) == topic {
+			return strings.TrimSpace(table)
+		}
+	}
+	return ""
+}
+
+// jdbcDatabase extracts the database name from a JDBC connection URL such as
+// jdbc:mysql://host:3306/dw?useSSL=false.
+func jdbcDatabase(connURL string) string {
+	u, err := url.Parse(strings.TrimPrefix(connURL, "jdbc:"))
+	if err != nil {
+		return ""
+	}
+	return strings.Trim(u.Path, "/")
+}
+
+var invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// sanitizeName replaces characters that are not valid in unquoted table
+// names, as sink connectors do when deriving table names from topics.
+func sanitizeName(topic string) string {
+	return invalidNameChars.ReplaceAllString(topic, "_")
+}
+
+func joinNonEmpty(parts ...string) string {
+	var result []string
+	for _, p := range parts {
+		if p != "" {
+			result = append(result, p)
+		}
+	}
+	return strings.Join(result, ".")
+}
@@ -0,0 +1,216 @@
+package lineage
+
+import (
+	"errors"
+	"strings"
+)
+
+// PostgreSQL bulk loads and exports use COPY, which the grammar does not
+// parse, or the \copy meta-command of psql, which runs the same statement
+// with a file of the client:
+//
+//   - COPY table [(columns)] FROM 'file' loads the columns of the table from
+//     the file, a dataset named by its path, e.g. /data/orders.csv, and
+//     reports the load and its format in the Loads of the result. Loads FROM
+//     STDIN or PROGRAM have no source dataset.
+//   - COPY table [(columns)] TO 'file' and COPY (query) TO 'file' write the
+//     columns of the table, or of the query, to the file.
+//
+// Columns not listed are looked up in the catalog; without one, the table
+// gets a single * column.
+
+// copyStdin is the location of loads from the client, and copyProgram
+// prefixes that of loads from the output of a command.
+const (
+	copyStdin   = "stdin"
+	copyProgram = "program:"
+)
+
+// copyBody returns sql without its leading comments, terminating semicolon
+// and the backslash of \copy, and reports whether it is a PostgreSQL COPY.
+// Snowflake COPY INTO is not.
+func copyBody(sql string) (string, bool) {
+	body := strings.TrimSpace(leadingComments.ReplaceAllString(sql, ""))
+	body = strings.TrimSpace(strings.TrimSuffix(body, ";"))
+	body = strings.TrimPrefix(body, `\`)
+	return body, MatchKeywords("COPY")(body) && !MatchKeywords("COPY", "INTO")(body)
+}
+
+// analyzeCopy extracts the lineage of a PostgreSQL COPY statement.
+func (a *Analyzer) analyzeCopy(sql string, catalog Catalog) (*LineageResult, error) {
+	tokens := tokenizeSpark(sql)
+	i := 1 // COPY
+	if i >= len(tokens) {
+		return nil, errors.New("copy: missing table")
+	}
+
+	// COPY (query) TO exports the lineage of the query
+	var query *LineageResult
+	var table ColumnRef
+	var columns []string
+	if tokens[i].text == "(" {
+		end := closingParen(tokens, i)
+		result, _, err := a.analyze(sql[tokens[i].end:tokens[end].start], catalog)
+		if err != nil {
+			return nil, err
+		}
+		query, i = result, end+1
+	} else {
+		name, next := qualifiedName(sql, tokens, i)
+		if name == "" {
+			return nil, errors.New("copy: missing table")
+		}
+		table, i = ParseTableRef(name), next
+		if i < len(tokens) && tokens[i].text == "(" {
+			end := closingParen(tokens, i)
+			for _, c := range strings.Split(sql[tokens[i].end:tokens[end].start], ",") {
+				columns = append(columns, unquoteIdentifier(strings.TrimSpace(c)))
+			}
+			i = end + 1
+		}
+	}
+
+	if i >= len(tokens) || tokens[i].word() != "FROM" && tokens[i].word() != "TO" {
+		return nil, errors.New("copy: missing FROM or TO")
+	}
+	load := tokens[i].word() == "FROM"
+	if load && query != nil {
+		return nil, errors.New("copy: cannot load into a query")
+	}
+	i++
+	var location string
+	var file *ColumnRef
+	switch {
+	case i >= len(tokens):
+		return nil, errors.New("copy: missing file")
+	case tokens[i].word() == "STDIN" || tokens[i].word() == "STDOUT":
+		location = copyStdin
+		i++
+	case tokens[i].word() == "PROGRAM" && i+1 < len(tokens):
+		location = copyProgram + unquoteString(tokens[i+1].text)
+		i += 2
+	default:
+		location = unquoteString(tokens[i].text)
+		ref := LocationRef(location)
+		file = &ref
+		i++
+	}
+
+	if query != nil {
+		// The file gets the columns of the query
+		columns := make([]ColumnLineage, 0, len(query.Columns))
+		for _, col := range query.Columns {
+			if file == nil {
+				break
+			}
+			col.Target.Database, col.Target.Table = file.Database, file.Table
+			columns = append(columns, col)
+		}
+		query.Columns = columns
+		return query, nil
+	}
+
+	result := &LineageResult{Columns: make([]ColumnLineage, 0)}
+	if file != nil {
+		if load {
+			result.Scans = []TableScan{{Database: file.Database, Table: file.Table}}
+		} else {
+			result.Scans = []TableScan{{Database: table.Database, Table: table.Table}}
+		}
+	}
+	if load {
+		result.Loads = []FileLoad{{Target: table, Location: location, Format: parseCopyOptions(sql, tokens[i:])}}
+	} else if file == nil {
+		// Nothing is written to a dataset
+		return result, nil
+	}
+
+	if len(columns) == 0 && catalog != nil {
+		schema, err := catalog.GetTableSchema(table.Database, table.Table)
+		if err == nil && len(schema.Columns) > 0 {
+			columns = schema.Columns
+		} else {
+			reason := "table not found in catalog"
+			if err != nil {
+				reason = err.Error()
+			}
+			result.Unresolved = append(result.Unresolved, UnresolvedRef{Database: table.Database, Table: table.Table, Reason: reason})
+		}
+	}
+	if len(columns) == 0 {
+		columns = []string{"*"}
+	}
+	for _, column := range columns {
+		t, s := table, ColumnRef{}
+		if file != nil {
+			s = *file
+		}
+		if !load {
+			t, s = s, table
+		}
+		t.Column, s.Column = column, column
+		col := ColumnLineage{Target: t, Sources: []ColumnRef{}, Operators: []string{}}
+		if file != nil {
+			s.Confidence = ConfidenceSyntactic
+			col.Sources = append(col.Sources, s)
+		}
+		result.Columns = append(result.Columns, col)
+	}
+	return result, nil
+}
+
+// parseCopyOptions parses the options of a COPY FROM into the format of the
+// file, given either as WITH (FORMAT csv, HEADER, DELIMITER '|') or with
+// the older syntax, WITH CSV HEADER DELIMITER AS '|'. The format is TEXT
+// unless set.
+func parseCopyOptions(sql string, tokens []sparkToken) FileFormat {
+	format := FileFormat{Type: "TEXT"}
+	set := func(key, value string) {
+		if format.Options == nil {
+			format.Options = make(map[string]string)
+		}
+		format.Options[key] = value
+	}
+
+	i := 0
+	if i < len(tokens) && tokens[i].word() == "WITH" {
+		i++
+	}
+	if i < len(tokens) && tokens[i].text == "(" {
+		end := closingParen(tokens, i)
+		for _, option := range splitTopLevel(sql[tokens[i].end:tokens[end].start]) {
+			key, value, _ := strings.Cut(strings.TrimSpace(option), " ")
+			key, value = strings.ToUpper(key), unquoteString(strings.TrimSpace(value))
+			switch {
+			case key == "":
+			case key == "FORMAT":
+				format.Type = strings.ToUpper(value)
+			case value == "":
+				set(key, "true")
+			default:
+				set(key, value)
+			}
+		}
+		return format
+	}
+
+	for ; i < len(tokens); i++ {
+		switch word := tokens[i].word(); word {
+		case "BINARY", "CSV":
+			format.Type = word
+		case "HEADER":
+			set(word, "true")
+		case "DELIMITER", "NULL", "QUOTE", "ESCAPE", "ENCODING":
+			if i+1 < len(tokens) && tokens[i+1].word() == "AS" {
+				i++
+			}
+			if i+1 < len(tokens) {
+				set(word, unquoteString(tokens[i+1].text))
+				i++
+			}
+		case "WHERE":
+			return format
+		}
+	}
+	return format
+}
@@ -0,0 +1,225 @@
+package lineage
+
+import (
+	"math"
+	"strings"
+)
+
+// ScanStats are the catalog statistics of a table that scan estimates are
+// based on.
+type ScanStats struct {
+	RowCount      int64
+	DataSizeBytes int64
+	// PartitionColumns are the partition columns of a partitioned table and
+	// PartitionCount its number of partitions.
+	PartitionColumns []string
+	PartitionCount   int
+	// Partitions are the partitions of the table, if known, with their values
+	// in the order of PartitionColumns. Partitions without statistics are
+	// assumed to be of equal size.
+	Partitions []PartitionStats
+	// DistinctCounts are the distinct value counts of columns by lower case
+	// name, used to estimate the share of the partitions a filter selects
+	// when the partitions are not known.
+	DistinctCounts map[string]int64
+}
+
+// PartitionStats are the statistics of a partition.
+type PartitionStats struct {
+	Values        []string
+	RowCount      int64
+	DataSizeBytes int64
+}
+
+// StatsSource provides the statistics of tables.
+type StatsSource interface {
+	// ScanStats returns the statistics of a table, or nil if none were
+	// collected. database is empty for tables the query does not qualify.
+	ScanStats(database, table string) *ScanStats
+}
+
+// StatsMap is a StatsSource of the statistics of tables by name, db.table or
+// table, case-insensitively. An unqualified table matches a db.table entry
+// if it is the only table of that name.
+type StatsMap map[string]*ScanStats
+
+// ScanStats implements StatsSource.
+func (m StatsMap) ScanStats(database, table string) *ScanStats {
+	name := table
+	if database != "" {
+		name = database + "." + table
+	}
+	var match *ScanStats
+	matches := 0
+	for k, stats := range m {
+		if strings.EqualFold(k, name) {
+			return stats
+		}
+		if database == "" && len(k) > len(table) && strings.EqualFold(k[len(k)-len(table):], table) && k[len(k)-len(table)-1] == '.' {
+			match = stats
+			matches++
+		}
+	}
+	if matches == 1 {
+		return match
+	}
+	return nil
+}
+
+// ScanEstimate is the estimated cost of a table scan.
+type ScanEstimate struct {
+	Database string `json:"database,omitempty"`
+	Table    string `json:"table"`
+	Rows     int64  `json:"rows"`
+	Bytes    int64  `json:"bytes"`
+	// Partitions is the estimated number of partitions read out of
+	// TotalPartitions, for partitioned tables.
+	Partitions      int `json:"partitions,omitempty"`
+	TotalPartitions int `json:"total_partitions,omitempty"`
+	// PrunedBy are the partition columns whose filters pruned partitions.
+	PrunedBy []string `json:"pruned_by,omitempty"`
+	// Unknown is set for tables without statistics, whose rows and bytes
+	// are 0.
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// CostEstimate is the estimated cost of a statement: the rows and bytes it
+// scans per table reference, and in total.
+type CostEstimate struct {
+	Scans []ScanEstimate `json:"scans"`
+	Rows  int64          `json:"rows"`
+	Bytes int64          `json:"bytes"`
+}
+
+// Unknown returns the tables without statistics, which the totals do not
+// include.
+func (c *CostEstimate) Unknown() []string {
+	var tables []string
+	for _, s := range c.Scans {
+		if s.Unknown {
+			tables = appendUnique(tables, TableScan{Database: s.Database, Table: s.Table}.TableName())
+		}
+	}
+	return tables
+}
+
+// EstimateCost estimates the rows and bytes the scans of an analyzed
+// statement read. Filters on partition columns prune partitions: the known
+// partitions whose values match, or else the share of the partitions the
+// filtered values are estimated to hold. Other filters do not reduce the
+// rows scanned.
+func EstimateCost(result *LineageResult, stats StatsSource) *CostEstimate {
+	estimate := &CostEstimate{Scans: make([]ScanEstimate, 0, len(result.Scans))}
+	for _, scan := range result.Scans {
+		e := estimateScan(scan, stats.ScanStats(scan.Database, scan.Table))
+		estimate.Rows += e.Rows
+		estimate.Bytes += e.Bytes
+		estimate.Scans = append(estimate.Scans, e)
+	}
+	return estimate
+}
+
+func estimateScan(scan TableScan, stats *ScanStats) ScanEstimate {
+	e := ScanEstimate{Database: scan.Database, Table: scan.Table}
+	if stats == nil {
+		e.Unknown = true
+		return e
+	}
+
+	rows, bytes := stats.RowCount, stats.DataSizeBytes
+	if rows == 0 && bytes == 0 {
+		// Only the partitions have statistics
+		for _, p := range stats.Partitions {
+			rows += p.RowCount
+			bytes += p.DataSizeBytes
+		}
+	}
+	e.Rows, e.Bytes = rows, bytes
+	e.TotalPartitions = stats.PartitionCount
+	if len(stats.Partitions) > 0 {
+		e.TotalPartitions = len(stats.Partitions)
+	}
+	e.Partitions = e.TotalPartitions
+
+	// The filters on partition columns, by position
+	filters := make(map[int]string)
+	for i, col := range stats.PartitionColumns {
+		if v, ok := scan.Filters[strings.ToLower(col)]; ok {
+			filters[i] = v
+			e.PrunedBy = append(e.PrunedBy, col)
+		}
+	}
+	if len(filters) == 0 {
+		return e
+	}
+
+	if len(stats.Partitions) > 0 {
+		var matched []PartitionStats
+		for _, p := range stats.Partitions {
+			if partitionMatches(p, filters) {
+				matched = append(matched, p)
+			}
+		}
+		e.Partitions = len(matched)
+		if !hasPartitionStats(stats.Partitions) {
+			// Partitions without statistics: a share of the table
+			share := float64(len(matched)) / float64(len(stats.Partitions))
+			e.Rows, e.Bytes = scale(rows, share), scale(bytes, share)
+			return e
+		}
+		e.Rows, e.Bytes = 0, 0
+		for _, p := range matched {
+			e.Rows += p.RowCount
+			e.Bytes += p.DataSizeBytes
+		}
+		return e
+	}
+
+	share := 1.0
+	switch {
+	case len(filters) == len(stats.PartitionColumns) && stats.PartitionCount > 0:
+		share = 1 / float64(stats.PartitionCount)
+	default:
+		for i := range filters {
+			if n := stats.DistinctCounts[strings.ToLower(stats.PartitionColumns[i])]; n > 0 {
+				share /= float64(n)
+			}
+		}
+	}
+	if share == 1 {
+		// Nothing is known about the values of the filtered columns
+		e.PrunedBy = nil
+		return e
+	}
+	e.Rows, e.Bytes = scale(rows, share), scale(bytes, share)
+	if e.TotalPartitions > 0 {
+		e.Partitions = int(math.Ceil(float64(e.TotalPartitions) * share))
+	}
+	return e
+}
+
+// hasPartitionStats reports whether any of the partitions has statistics.
+func hasPartitionStats(partitions []PartitionStats) bool {
+	for _, p := range partitions {
+		if p.RowCount > 0 || p.DataSizeBytes > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// partitionMatches reports whether a partition has the filtered values.
+func partitionMatches(p PartitionStats, filters map[int]string) bool {
+	for i, v := range filters {
+		if i >= len(p.Values) || p.Values[i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// scale returns n scaled by share, rounded up so that a non-empty share of
+// a table is never estimated as empty.
+func scale(n int64, share float64) int64 {
+	return int64(math.Ceil(float64(n) * share))
+}
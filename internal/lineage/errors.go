@@ -11,4 +11,13 @@ var (
 
 	// ErrColumnNotFound is returned when a column is not found in the table.
 	ErrColumnNotFound = errors.New("column not found in table")
+
+	// ErrAmbiguousColumn is returned when a column cannot be attributed to a table.
+	ErrAmbiguousColumn = errors.New("column cannot be attributed to a table")
+
+	// ErrJobNotFound is returned when a job is not registered in the lineage graph.
+	ErrJobNotFound = errors.New("job not found in lineage graph")
+
+	// ErrInvalidTemplate is returned when templated SQL cannot be rendered.
+	ErrInvalidTemplate = errors.New("invalid SQL template")
 )
@@ -0,0 +1,180 @@
+package lineage
+
+import (
+	"go-metadata/internal/lineage/parser"
+
+	"github.com/antlr4-go/antlr/v4"
+)
+
+// ParseTree is the parse tree of SQL, to debug how the grammar handles a
+// dialect.
+type ParseTree struct {
+	SQL string `json:"sql"`
+	// Rewritten is the SQL actually parsed when the grammar rejected SQL and
+	// it was retried without the Spark SQL constructs the grammar lacks.
+	Rewritten string     `json:"rewritten,omitempty"`
+	Root      *ParseNode `json:"root"`
+	// Errors are the syntax errors of the parsed SQL. The tree of SQL with
+	// errors is the one the parser recovered, with the tokens it skipped or
+	// inserted as error nodes.
+	Errors []ParseError `json:"errors,omitempty"`
+}
+
+// ParseNode is a node of a parse tree: a grammar rule and its children, or a
+// token.
+type ParseNode struct {
+	Rule string `json:"rule,omitempty"`
+	// Token is the symbolic name of the type of a token, e.g. IDENTIFIER, and
+	// Text its text.
+	Token string `json:"token,omitempty"`
+	Text  string `json:"text,omitempty"`
+	// Error marks tokens the parser skipped or inserted to recover.
+	Error bool `json:"error,omitempty"`
+	Span
+	Children []*ParseNode `json:"children,omitempty"`
+}
+
+// Span locates a node or error in the SQL: Start and Stop are the offsets of
+// its first and past its last character (not byte), and Line (1-based) and
+// Column (0-based) the position of its first character.
+type Span struct {
+	Start  int `json:"start"`
+	Stop   int `json:"stop"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// ParseError is a syntax error. Errors of the lexer, such as an unterminated
+// string, have no offending token and are only located by line and column.
+type ParseError struct {
+	Message string `json:"message"`
+	// Token is the text of the offending token, if any.
+	Token string `json:"token,omitempty"`
+	Span
+}
+
+// Explain parses SQL and returns its parse tree, rendering templated SQL
+// first as Analyze does. SQL the grammar rejects is retried without the
+// Spark SQL or BigQuery constructs it lacks, as Analyze does, and explained
+// as rewritten if the rewrite parses; otherwise the tree of the original SQL
+// is returned with its syntax errors.
+func (a *Analyzer) Explain(sql string) (*ParseTree, error) {
+	tree := &ParseTree{SQL: sql}
+	if HasTemplate(sql) {
+		rendered, err := PreprocessTemplate(sql, a.templates)
+		if err != nil {
+			return nil, err
+		}
+		tree.Rewritten, sql = rendered, rendered
+	}
+
+	tree.Root, tree.Errors = explain(sql)
+	if len(tree.Errors) > 0 {
+		for _, rewrite := range []func(string) (string, bool){rewriteSpark, rewriteBigQuerySQL} {
+			rewritten, ok := rewrite(sql)
+			if !ok {
+				continue
+			}
+			if root, errs := explain(rewritten); len(errs) == 0 {
+				tree.Rewritten, tree.Root, tree.Errors = rewritten, root, nil
+				break
+			}
+		}
+	}
+	return tree, nil
+}
+
+// explain parses sql and converts its parse tree.
+func explain(sql string) (*ParseNode, []ParseError) {
+	lexer := parser.NewSQLLexer(antlr.NewInputStream(sql))
+	p := parser.NewSQLParser(antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel))
+	lexer.RemoveErrorListeners()
+	p.RemoveErrorListeners()
+	listener := &errorSpans{}
+	lexer.AddErrorListener(listener)
+	p.AddErrorListener(listener)
+
+	root := p.SqlStatements()
+	return parseNode(p, root), listener.errors
+}
+
+// parseNode converts a node of an ANTLR parse tree.
+func parseNode(p *parser.SQLParser, t antlr.Tree) *ParseNode {
+	switch t := t.(type) {
+	case antlr.ErrorNode:
+		n := tokenNode(p, t.GetSymbol())
+		n.Error = true
+		return n
+	case antlr.TerminalNode:
+		return tokenNode(p, t.GetSymbol())
+	case antlr.ParserRuleContext:
+		n := &ParseNode{Rule: ruleName(p, t.GetRuleIndex())}
+		if start := t.GetStart(); start != nil {
+			n.Span = tokenSpan(start)
+		}
+		if stop := t.GetStop(); stop != nil && stop.GetStop() >= n.Start {
+			n.Stop = stop.GetStop() + 1
+		} else {
+			// A rule that matched no tokens
+			n.Stop = n.Start
+		}
+		for _, child := range t.GetChildren() {
+			n.Children = append(n.Children, parseNode(p, child))
+		}
+		return n
+	}
+	return &ParseNode{}
+}
+
+func tokenNode(p *parser.SQLParser, token antlr.Token) *ParseNode {
+	n := &ParseNode{Text: token.GetText(), Span: tokenSpan(token)}
+	switch names := p.GetSymbolicNames(); {
+	case token.GetTokenType() == antlr.TokenEOF:
+		n.Token, n.Text = "EOF", ""
+	case token.GetTokenType() >= 0 && token.GetTokenType() < len(names) && names[token.GetTokenType()] != "":
+		n.Token = names[token.GetTokenType()]
+	default:
+		// Literal tokens of the grammar, e.g. '('
+		n.Token = n.Text
+	}
+	return n
+}
+
+func ruleName(p *parser.SQLParser, index int) string {
+	if names := p.GetRuleNames(); index >= 0 && index < len(names) {
+		return names[index]
+	}
+	return ""
+}
+
+func tokenSpan(token antlr.Token) Span {
+	s := Span{Start: token.GetStart(), Stop: token.GetStop() + 1, Line: token.GetLine(), Column: token.GetColumn()}
+	if s.Stop < s.Start {
+		// Tokens inserted by error recovery and EOF have no text
+		s.Stop = s.Start
+	}
+	return s
+}
+
+// errorSpans collects the syntax errors of the lexer and parser with their
+// positions.
+type errorSpans struct {
+	*antlr.DefaultErrorListener
+	errors []ParseError
+}
+
+func (e *errorSpans) SyntaxError(_ antlr.Recognizer, offendingSymbol interface{}, line, column int, msg string, _ antlr.RecognitionException) {
+	pe := ParseError{Message: msg, Span: Span{Line: line, Column: column}}
+	if token, ok := offendingSymbol.(antlr.Token); ok {
+		pe.Token = token.GetText()
+		pe.Span = tokenSpan(token)
+	}
+	e.errors = append(e.errors, pe)
+}
+
+// rewriteBigQuerySQL is rewriteBigQuery without the star excepts, which the
+// parse tree does not show.
+func rewriteBigQuerySQL(sql string) (string, bool) {
+	rewritten, _, ok := rewriteBigQuery(sql)
+	return rewritten, ok
+}
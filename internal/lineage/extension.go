@@ -0,0 +1,125 @@
+package lineage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StatementClassifier reports whether a statement is one a StatementParser
+// handles. It is called before the statement is parsed, with its templates
+// rendered and its leading comments removed.
+type StatementClassifier func(sql string) bool
+
+// StatementParser extracts the lineage of a statement the grammar does not
+// support. catalog resolves the columns of tables; it is nil if the analyzer
+// has none, and follows the tables created by a script.
+type StatementParser func(sql string, catalog Catalog) (*LineageResult, error)
+
+// statementHandler is a parser registered for the statements a classifier
+// matches.
+type statementHandler struct {
+	name     string
+	classify StatementClassifier
+	parse    StatementParser
+}
+
+// RegisterStatement registers a parser for the statements classify matches,
+// such as the COPY INTO or EXPORT TO statements of a warehouse, so that their
+// lineage is extracted without extending the grammar. Handlers are tried in
+// the order they were registered, before the grammar, in single statements
+// and scripts alike; errors of parse are reported with name. A parser may
+// return ErrUnsupportedSQL for statements it cannot handle after all, which
+// scripts skip.
+func (a *Analyzer) RegisterStatement(name string, classify StatementClassifier, parse StatementParser) {
+	a.handlers = append(a.handlers, statementHandler{name: name, classify: classify, parse: parse})
+}
+
+// handle extracts the lineage of sql with the first registered handler that
+// matches it, and reports whether one did.
+func (a *Analyzer) handle(sql string, catalog Catalog) (*LineageResult, bool, error) {
+	if len(a.handlers) == 0 {
+		return nil, false, nil
+	}
+	body := strings.TrimSpace(leadingComments.ReplaceAllString(sql, ""))
+	body = strings.TrimSpace(strings.TrimSuffix(body, ";"))
+	for _, h := range a.handlers {
+		if !h.classify(body) {
+			continue
+		}
+		result, err := h.parse(body, catalog)
+		if err != nil {
+			return nil, true, fmt.Errorf("%s: %w", h.name, err)
+		}
+		if result == nil {
+			result = &LineageResult{}
+		}
+		if result.Columns == nil {
+			result.Columns = make([]ColumnLineage, 0)
+		}
+		return result, true, nil
+	}
+	return nil, false, nil
+}
+
+// MatchKeywords returns a classifier of the statements that start with
+// keywords, compared case-insensitively and separated by any blanks, e.g.
+// MatchKeywords("COPY", "INTO").
+func MatchKeywords(keywords ...string) StatementClassifier {
+	return func(sql string) bool {
+		words := strings.Fields(sql)
+		if len(words) < len(keywords) {
+			return false
+		}
+		for i, keyword := range keywords {
+			if !strings.EqualFold(words[i], keyword) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// ParseTableRef parses a possibly qualified and quoted table name, such as
+// dw.orders or "dw"."orders", into the table of a column reference. Only the
+// last two parts are kept, as database and table.
+func ParseTableRef(name string) ColumnRef {
+	parts := strings.Split(strings.TrimSpace(name), ".")
+	ref := ColumnRef{Table: unquoteIdentifier(parts[len(parts)-1])}
+	if len(parts) > 1 {
+		ref.Database = unquoteIdentifier(parts[len(parts)-2])
+	}
+	return ref
+}
+
+// CopyTable returns the lineage of a statement that copies the columns of
+// source into the same-named columns of target, such as COPY INTO target
+// FROM source. The columns of target are looked up in catalog; without a
+// catalog, or if target is not found in it, target gets a single * column
+// read from all of source.
+func CopyTable(catalog Catalog, target, source ColumnRef) *LineageResult {
+	result := &LineageResult{Columns: make([]ColumnLineage, 0)}
+	target.Column, target.Confidence = "", ""
+	source.Column = ""
+
+	columns := []string{"*"}
+	if catalog != nil {
+		schema, err := catalog.GetTableSchema(target.Database, target.Table)
+		if err == nil && len(schema.Columns) > 0 {
+			columns = schema.Columns
+		} else {
+			reason := "table not found in catalog"
+			if err != nil {
+				reason = err.Error()
+			}
+			result.Unresolved = append(result.Unresolved, UnresolvedRef{Database: target.Database, Table: target.Table, Reason: reason})
+		}
+	}
+	for _, column := range columns {
+		t, s := target, source
+		t.Column, s.Column = column, column
+		s.Confidence = ConfidenceSyntactic
+		result.Columns = append(result.Columns, ColumnLineage{Target: t, Sources: []ColumnRef{s}, Operators: []string{}})
+	}
+	result.Scans = []TableScan{{Database: source.Database, Table: source.Table}}
+	return result
+}
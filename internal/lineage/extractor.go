@@ -3,13 +3,23 @@ package lineage
 import (
 	"fmt"
 	"go-metadata/internal/lineage/ast"
+	"regexp"
+	"slices"
+	"strings"
 )
 
 // Extractor extracts column lineage from AST nodes.
 type Extractor struct {
-	catalog  Catalog
-	scope    *Scope
-	lineages []ColumnLineage
+	catalog    Catalog
+	scope      *Scope
+	lineages   []ColumnLineage
+	unresolved []UnresolvedRef
+	seen       map[UnresolvedRef]bool
+	usages     []ColumnUsage
+	used       map[ColumnUsage]bool
+	joinKeys   []JoinKey
+	scans      []TableScan
+	scanOf     map[*ast.TableRef]int // table reference -> index in scans
 }
 
 // Scope maintains the current resolution context.
@@ -18,6 +28,9 @@ type Scope struct {
 	tableAlias map[string]*ast.TableRef // alias -> table
 	cteMap     map[string]*ast.SelectStmt
 	columns    map[string][]string // table -> columns (from catalog)
+	// derived holds the column lineage of the CTEs and derived tables of the
+	// scope, so that their columns resolve to the underlying table columns.
+	derived map[string][]ColumnLineage
 }
 
 // NewExtractor creates a new lineage extractor.
@@ -26,6 +39,9 @@ func NewExtractor(catalog Catalog) *Extractor {
 		catalog:  catalog,
 		scope:    newScope(nil),
 		lineages: make([]ColumnLineage, 0),
+		seen:     make(map[UnresolvedRef]bool),
+		used:     make(map[ColumnUsage]bool),
+		scanOf:   make(map[*ast.TableRef]int),
 	}
 }
 
@@ -35,6 +51,7 @@ func newScope(parent *Scope) *Scope {
 		tableAlias: make(map[string]*ast.TableRef),
 		cteMap:     make(map[string]*ast.SelectStmt),
 		columns:    make(map[string][]string),
+		derived:    make(map[string][]ColumnLineage),
 	}
 }
 
@@ -45,17 +62,42 @@ func (e *Extractor) Extract(stmt ast.Statement) (*LineageResult, error) {
 		return e.extractSelect(s, "")
 	case *ast.InsertStmt:
 		return e.extractInsert(s)
+	case *ast.CreateTableAsStmt:
+		return e.extractInto(s.Table, s.Columns, s.Select)
+	case *ast.MergeStmt:
+		return e.extractMerge(s)
 	default:
-		return &LineageResult{Columns: e.lineages}, nil
+		return e.result(), nil
 	}
 }
 
+// result builds the lineage result collected so far.
+func (e *Extractor) result() *LineageResult {
+	return &LineageResult{
+		Columns:    e.lineages,
+		Unresolved: e.unresolved,
+		Usages:     e.usages,
+		JoinKeys:   e.joinKeys,
+		Scans:      e.scans,
+	}
+}
+
+// addUnresolved records an unresolved reference once.
+func (e *Extractor) addUnresolved(ref UnresolvedRef) {
+	if e.seen[ref] {
+		return
+	}
+	e.seen[ref] = true
+	e.unresolved = append(e.unresolved, ref)
+}
+
 // extractSelect extracts lineage from SELECT statement.
 func (e *Extractor) extractSelect(stmt *ast.SelectStmt, targetTable string) (*LineageResult, error) {
 	// Process WITH clause (CTEs)
 	if stmt.WithClause != nil {
 		for _, cte := range stmt.WithClause.CTEs {
 			e.scope.cteMap[cte.Name] = cte.Query
+			e.scope.derived[cte.Name] = e.extractDerived(cte.Query, cte.Columns)
 		}
 	}
 
@@ -66,6 +108,8 @@ func (e *Extractor) extractSelect(stmt *ast.SelectStmt, targetTable string) (*Li
 		}
 	}
 
+	e.collectSelectUsages(stmt)
+
 	// Process SELECT list
 	for i, selectExpr := range stmt.SelectList {
 		// Handle * and table.* specially - expand to multiple columns
@@ -97,34 +141,182 @@ func (e *Extractor) extractSelect(stmt *ast.SelectStmt, targetTable string) (*Li
 		})
 	}
 
-	return &LineageResult{Columns: e.lineages}, nil
+	return e.result(), nil
 }
 
 // extractInsert extracts lineage from INSERT statement.
 func (e *Extractor) extractInsert(stmt *ast.InsertStmt) (*LineageResult, error) {
-	if stmt.Select == nil {
-		return &LineageResult{Columns: e.lineages}, nil
+	return e.extractInto(stmt.Table, stmt.Columns, stmt.Select)
+}
+
+// extractInto extracts lineage from a query writing into a table, as in
+// INSERT ... SELECT and CREATE TABLE ... AS SELECT. An explicit column list
+// renames the query columns by position.
+func (e *Extractor) extractInto(table *ast.TableRef, columns []string, query *ast.SelectStmt) (*LineageResult, error) {
+	if query == nil {
+		return e.result(), nil
 	}
 
-	targetTable := stmt.Table.Table
+	targetTable := table.Table
 
 	// Process the SELECT part
-	selectResult, err := e.extractSelect(stmt.Select, targetTable)
+	selectResult, err := e.extractSelect(query, targetTable)
 	if err != nil {
 		return nil, err
 	}
 
-	// Map columns if INSERT has explicit column list
-	if len(stmt.Columns) > 0 && len(selectResult.Columns) > 0 {
+	// Map columns if the statement has an explicit column list
+	if len(columns) > 0 && len(selectResult.Columns) > 0 {
 		for i := range selectResult.Columns {
-			if i < len(stmt.Columns) {
+			if i < len(columns) {
 				e.lineages[i].Target.Table = targetTable
-				e.lineages[i].Target.Column = stmt.Columns[i]
+				e.lineages[i].Target.Column = columns[i]
+			}
+		}
+	}
+
+	return e.result(), nil
+}
+
+// extractMerge extracts lineage from MERGE statement. The columns assigned by
+// WHEN MATCHED THEN UPDATE and inserted by WHEN NOT MATCHED THEN INSERT are
+// the targets; a column written by several clauses gets the sources of all.
+func (e *Extractor) extractMerge(stmt *ast.MergeStmt) (*LineageResult, error) {
+	e.registerTableSource(&ast.TableSource{Table: stmt.Target, Alias: stmt.Target.Alias})
+	if stmt.Source != nil {
+		e.registerTableSource(stmt.Source)
+		e.collectJoinUsages(stmt.Source)
+	}
+	e.collectUsages(stmt.On, UsageJoin)
+	e.collectJoinKeys(stmt.On)
+
+	targetAlias := stmt.Target.Alias
+	if targetAlias == "" {
+		targetAlias = stmt.Target.Table
+	}
+
+	for _, clause := range stmt.Clauses {
+		e.collectUsages(clause.Condition, UsageFilter)
+		for _, a := range clause.Assignments {
+			e.collectUsages(a.Value, UsageSelect)
+			e.mergeLineage(stmt.Target.Table, a.Column, a.Value)
+		}
+
+		columns := clause.Columns
+		if len(columns) == 0 {
+			// INSERT VALUES (...) without a column list fills the target
+			// columns in order.
+			columns = e.scope.columns[targetAlias]
+		}
+		for i, value := range clause.Values {
+			e.collectUsages(value, UsageSelect)
+			column := fmt.Sprintf("_col%d", i)
+			if i < len(columns) {
+				column = columns[i]
+			}
+			e.mergeLineage(stmt.Target.Table, column, value)
+		}
+	}
+
+	return e.result(), nil
+}
+
+// mergeLineage records the lineage of a target column written by value,
+// merging it into an earlier lineage of the same column.
+func (e *Extractor) mergeLineage(table, column string, value ast.Expression) {
+	if value == nil {
+		return
+	}
+	sources, operators := e.extractExprSources(value)
+	for i := range e.lineages {
+		l := &e.lineages[i]
+		if l.Target.Table != table || !strings.EqualFold(l.Target.Column, column) {
+			continue
+		}
+		for _, src := range sources {
+			if !containsRef(l.Sources, src) {
+				l.Sources = append(l.Sources, src)
+			}
+		}
+		for _, op := range operators {
+			if !containsColumn(l.Operators, op) {
+				l.Operators = append(l.Operators, op)
 			}
 		}
+		return
 	}
+	e.lineages = append(e.lineages, ColumnLineage{
+		Target:    ColumnRef{Table: table, Column: column},
+		Sources:   sources,
+		Operators: operators,
+	})
+}
 
-	return &LineageResult{Columns: e.lineages}, nil
+// containsRef reports whether refs contains ref.
+func containsRef(refs []ColumnRef, ref ColumnRef) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// extractDerived extracts the column lineage of a CTE or derived table query
+// in a nested scope. An explicit column list renames the query columns by
+// position.
+func (e *Extractor) extractDerived(query *ast.SelectStmt, columns []string) []ColumnLineage {
+	if query == nil {
+		return nil
+	}
+	sub := NewExtractor(e.catalog)
+	sub.scope = newScope(e.scope)
+	sub.extractSelect(query, "")
+	for _, ref := range sub.unresolved {
+		e.addUnresolved(ref)
+	}
+	for i := range sub.lineages {
+		if i < len(columns) {
+			sub.lineages[i].Target.Column = columns[i]
+		}
+	}
+	return sub.lineages
+}
+
+// derivedLineage returns the column lineage of the CTE or derived table
+// named name, if any is visible in the current scope.
+func (e *Extractor) derivedLineage(name string) ([]ColumnLineage, bool) {
+	for s := e.scope; s != nil; s = s.parent {
+		if lineages, ok := s.derived[name]; ok {
+			return lineages, true
+		}
+	}
+	return nil, false
+}
+
+// derivedSources returns the sources of a column of a CTE or derived table,
+// which are the columns it was computed from. ok is false for columns of
+// base tables.
+func (e *Extractor) derivedSources(table, column string) (sources []ColumnRef, ok bool) {
+	lineages, ok := e.derivedLineage(table)
+	if !ok {
+		return nil, false
+	}
+	for _, l := range lineages {
+		if strings.EqualFold(l.Target.Column, column) {
+			return append([]ColumnRef(nil), l.Sources...), true
+		}
+	}
+	return nil, false
+}
+
+// columnNames returns the target column names of lineages.
+func columnNames(lineages []ColumnLineage) []string {
+	names := make([]string, len(lineages))
+	for i, l := range lineages {
+		names[i] = l.Target.Column
+	}
+	return names
 }
 
 // expandStarExpr expands a * or table.* expression to individual column lineages.
@@ -135,15 +327,15 @@ func (e *Extractor) expandStarExpr(starExpr *ast.StarExpr, targetTable string) {
 		if cols, ok := e.scope.columns[alias]; ok {
 			tableName := e.resolveTableAlias(alias)
 			for _, col := range cols {
+				if starExcepts(starExpr, col) {
+					continue
+				}
 				e.lineages = append(e.lineages, ColumnLineage{
 					Target: ColumnRef{
 						Table:  targetTable,
 						Column: col,
 					},
-					Sources: []ColumnRef{{
-						Table:  tableName,
-						Column: col,
-					}},
+					Sources:   e.starSources(tableName, col),
 					Operators: []string{col},
 				})
 			}
@@ -153,15 +345,15 @@ func (e *Extractor) expandStarExpr(starExpr *ast.StarExpr, targetTable string) {
 		for alias, cols := range e.scope.columns {
 			tableName := e.resolveTableAlias(alias)
 			for _, col := range cols {
+				if starExcepts(starExpr, col) {
+					continue
+				}
 				e.lineages = append(e.lineages, ColumnLineage{
 					Target: ColumnRef{
 						Table:  targetTable,
 						Column: col,
 					},
-					Sources: []ColumnRef{{
-						Table:  tableName,
-						Column: col,
-					}},
+					Sources:   e.starSources(tableName, col),
 					Operators: []string{col},
 				})
 			}
@@ -169,8 +361,35 @@ func (e *Extractor) expandStarExpr(starExpr *ast.StarExpr, targetTable string) {
 	}
 }
 
+// starExcepts reports whether a * or table.* leaves out column, with
+// BigQuery SELECT * EXCEPT.
+func starExcepts(starExpr *ast.StarExpr, column string) bool {
+	return slices.ContainsFunc(starExpr.Except, func(c string) bool { return strings.EqualFold(c, column) })
+}
+
+// starSources returns the sources of a column selected by * or table.*.
+func (e *Extractor) starSources(table, column string) []ColumnRef {
+	if sources, ok := e.derivedSources(table, column); ok {
+		return sources
+	}
+	return []ColumnRef{{
+		Table:      table,
+		Column:     column,
+		Confidence: ConfidenceCatalog,
+	}}
+}
+
 // registerTableSource registers a table source in the current scope.
 func (e *Extractor) registerTableSource(ts *ast.TableSource) {
+	if ts.Subquery != nil && ts.Alias != "" {
+		// Derived table: its columns are those of the query.
+		e.scope.derived[ts.Alias] = e.extractDerived(ts.Subquery, nil)
+		e.scope.tableAlias[ts.Alias] = &ast.TableRef{Table: ts.Alias}
+		if cols := columnNames(e.scope.derived[ts.Alias]); len(cols) > 0 {
+			e.scope.columns[ts.Alias] = cols
+		}
+	}
+
 	if ts.Table != nil {
 		alias := ts.Alias
 		if alias == "" {
@@ -178,11 +397,27 @@ func (e *Extractor) registerTableSource(ts *ast.TableSource) {
 		}
 		e.scope.tableAlias[alias] = ts.Table
 
-		// Load columns from catalog
-		if e.catalog != nil {
+		if _, ok := e.scanOf[ts.Table]; !ok && !e.isDerived(ts.Table.Table) {
+			e.scanOf[ts.Table] = len(e.scans)
+			e.scans = append(e.scans, TableScan{Database: ts.Table.Database, Table: ts.Table.Table})
+		}
+
+		if lineages, ok := e.derivedLineage(ts.Table.Table); ok {
+			// CTE: its columns are those of the query, when known.
+			if cols := columnNames(lineages); len(cols) > 0 {
+				e.scope.columns[alias] = cols
+			}
+		} else if e.catalog != nil {
+			// Load columns from catalog
 			schema, err := e.catalog.GetTableSchema(ts.Table.Database, ts.Table.Table)
 			if err == nil {
 				e.scope.columns[alias] = schema.Columns
+			} else if !e.isCTE(ts.Table.Table) {
+				e.addUnresolved(UnresolvedRef{
+					Database: ts.Table.Database,
+					Table:    ts.Table.Table,
+					Reason:   ErrTableNotFound.Error(),
+				})
 			}
 		}
 	}
@@ -202,11 +437,17 @@ func (e *Extractor) extractExprSources(expr ast.Expression) ([]ColumnRef, []stri
 
 	switch ex := expr.(type) {
 	case *ast.ColumnRefExpr:
-		tableName := e.resolveColumnTable(ex.Table, ex.Column)
-		sources = append(sources, ColumnRef{
-			Table:  tableName,
-			Column: ex.Column,
-		})
+		tableName, confidence := e.resolveColumn(ex.Table, ex.Column)
+		if derived, ok := e.derivedSources(tableName, ex.Column); ok {
+			// Column of a CTE or derived table: trace it to its sources.
+			sources = append(sources, derived...)
+		} else {
+			sources = append(sources, ColumnRef{
+				Table:      tableName,
+				Column:     ex.Column,
+				Confidence: confidence,
+			})
+		}
 		// Use raw expression text as operator
 		if ex.RawText != "" {
 			operators = append(operators, ex.RawText)
@@ -270,8 +511,9 @@ func (e *Extractor) extractExprSources(expr ast.Expression) ([]ColumnRef, []stri
 			if cols, ok := e.scope.columns[ex.Table]; ok {
 				for _, col := range cols {
 					sources = append(sources, ColumnRef{
-						Table:  tableName,
-						Column: col,
+						Table:      tableName,
+						Column:     col,
+						Confidence: ConfidenceCatalog,
 					})
 				}
 			}
@@ -281,8 +523,9 @@ func (e *Extractor) extractExprSources(expr ast.Expression) ([]ColumnRef, []stri
 				tableName := e.resolveTableAlias(alias)
 				for _, col := range cols {
 					sources = append(sources, ColumnRef{
-						Table:  tableName,
-						Column: col,
+						Table:      tableName,
+						Column:     col,
+						Confidence: ConfidenceCatalog,
 					})
 				}
 			}
@@ -297,6 +540,9 @@ func (e *Extractor) extractExprSources(expr ast.Expression) ([]ColumnRef, []stri
 		for _, col := range subResult.Columns {
 			sources = append(sources, col.Sources...)
 		}
+		for _, ref := range subResult.Unresolved {
+			e.addUnresolved(ref)
+		}
 
 	case *ast.AliasedExpr:
 		return e.extractExprSources(ex.Expr)
@@ -330,6 +576,10 @@ func (e *Extractor) resolveColumnTable(tableHint, column string) string {
 		return e.resolveTableAlias(tableHint)
 	}
 
+	if alias := e.enclosingTable(); alias != "" {
+		return e.resolveTableAlias(alias)
+	}
+
 	// If only one table in scope, use it
 	if len(e.scope.tableAlias) == 1 {
 		for _, tableRef := range e.scope.tableAlias {
@@ -348,3 +598,397 @@ func (e *Extractor) resolveColumnTable(tableHint, column string) string {
 
 	return ""
 }
+
+// enclosingTable returns the alias of the single table of the enclosing
+// query of a query without FROM, such as a lateral subquery generating rows
+// from the columns of that table, or "".
+func (e *Extractor) enclosingTable() string {
+	if len(e.scope.tableAlias) > 0 || e.scope.parent == nil || len(e.scope.parent.tableAlias) != 1 {
+		return ""
+	}
+	for alias := range e.scope.parent.tableAlias {
+		return alias
+	}
+	return ""
+}
+
+// resolveColumn resolves the table of a column together with the confidence of
+// the resolution, recording unresolved references along the way.
+func (e *Extractor) resolveColumn(tableHint, column string) (string, Confidence) {
+	tableName := e.resolveColumnTable(tableHint, column)
+
+	alias := tableHint
+	if alias == "" && len(e.scope.tableAlias) == 1 {
+		for a := range e.scope.tableAlias {
+			alias = a
+		}
+	}
+
+	cols, known := e.scope.columns[alias]
+	if alias == "" {
+		if alias = e.enclosingTable(); alias != "" {
+			cols, known = e.scope.parent.columns[alias]
+		}
+	}
+
+	if alias != "" {
+		if !known {
+			// No schema for this table (not in catalog, CTE or outer scope).
+			return tableName, ConfidenceSyntactic
+		}
+		if containsColumn(cols, column) {
+			return tableName, ConfidenceCatalog
+		}
+		e.addUnresolved(UnresolvedRef{
+			Table:  tableName,
+			Column: column,
+			Reason: ErrColumnNotFound.Error(),
+		})
+		return tableName, ConfidenceGuessed
+	}
+
+	if tableName != "" {
+		// Found by searching the schemas of all tables in scope.
+		return tableName, ConfidenceCatalog
+	}
+
+	if len(e.scope.tableAlias) > 0 {
+		e.addUnresolved(UnresolvedRef{
+			Column: column,
+			Reason: ErrAmbiguousColumn.Error(),
+		})
+	}
+	return tableName, ConfidenceGuessed
+}
+
+// isDerived reports whether name refers to a CTE or derived table visible in
+// the current scope rather than to a table.
+func (e *Extractor) isDerived(name string) bool {
+	_, ok := e.derivedLineage(name)
+	return ok || e.isCTE(name)
+}
+
+// isCTE reports whether name refers to a CTE visible in the current scope.
+func (e *Extractor) isCTE(name string) bool {
+	for s := e.scope; s != nil; s = s.parent {
+		if _, ok := s.cteMap[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// containsColumn reports whether cols contains column, ignoring case.
+func containsColumn(cols []string, column string) bool {
+	for _, col := range cols {
+		if strings.EqualFold(col, column) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectSelectUsages records the table columns read by each clause of a
+// SELECT whose FROM clause is already registered in the current scope.
+func (e *Extractor) collectSelectUsages(stmt *ast.SelectStmt) {
+	if stmt.WithClause != nil {
+		for _, cte := range stmt.WithClause.CTEs {
+			e.collectSubqueryUsages(cte.Query)
+		}
+	}
+
+	aliases := make(map[string]bool)
+	for _, selectExpr := range stmt.SelectList {
+		if starExpr, ok := selectExpr.Expr.(*ast.StarExpr); ok {
+			e.collectStarUsages(starExpr)
+			continue
+		}
+		if selectExpr.Alias != "" {
+			aliases[strings.ToLower(selectExpr.Alias)] = true
+		}
+		e.collectUsages(selectExpr.Expr, UsageSelect)
+	}
+
+	if stmt.From != nil {
+		for _, ts := range stmt.From.Tables {
+			e.collectJoinUsages(ts)
+		}
+	}
+	e.collectUsages(stmt.Where, UsageFilter)
+	e.collectUsages(stmt.Having, UsageFilter)
+	// Implicit joins compare the columns of two tables in WHERE.
+	e.collectJoinKeys(stmt.Where)
+	e.collectScanFilters(stmt.Where)
+
+	// GROUP BY and ORDER BY may name select list aliases instead of columns.
+	isAlias := func(expr ast.Expression) bool {
+		col, ok := expr.(*ast.ColumnRefExpr)
+		return ok && col.Table == "" && aliases[strings.ToLower(col.Column)]
+	}
+	for _, expr := range stmt.GroupBy {
+		if !isAlias(expr) {
+			e.collectUsages(expr, UsageGroupBy)
+		}
+	}
+	for _, el := range stmt.OrderBy {
+		if !isAlias(el.Expr) {
+			e.collectUsages(el.Expr, UsageOrderBy)
+		}
+	}
+}
+
+// collectJoinUsages records the columns compared by the joins of ts and the
+// usages of ts itself when it is a derived table.
+func (e *Extractor) collectJoinUsages(ts *ast.TableSource) {
+	if ts.Subquery != nil {
+		e.collectSubqueryUsages(ts.Subquery)
+	}
+	for _, join := range ts.Joins {
+		e.collectUsages(join.Condition, UsageJoin)
+		e.collectJoinKeys(join.Condition)
+		if join.Table != nil {
+			e.collectJoinUsages(join.Table)
+		}
+	}
+}
+
+// collectStarUsages records the columns selected by * or table.*, as known
+// from the catalog.
+func (e *Extractor) collectStarUsages(starExpr *ast.StarExpr) {
+	for alias, cols := range e.scope.columns {
+		if starExpr.Table != "" && alias != starExpr.Table {
+			continue
+		}
+		table := e.scope.tableAlias[alias]
+		if table == nil || e.isDerived(table.Table) {
+			continue
+		}
+		for _, col := range cols {
+			if starExcepts(starExpr, col) {
+				continue
+			}
+			e.addUsage(ColumnRef{Database: table.Database, Table: table.Table, Column: col}, UsageSelect)
+		}
+	}
+}
+
+// collectUsages records the table columns referenced by expr as used in
+// clause. Unlike extractExprSources it records no unresolved references.
+func (e *Extractor) collectUsages(expr ast.Expression, clause UsageClause) {
+	switch ex := expr.(type) {
+	case *ast.ColumnRefExpr:
+		if ref, ok := e.usageRef(ex.Table, ex.Column); ok {
+			e.addUsage(ref, clause)
+		}
+	case *ast.FunctionCallExpr:
+		for _, arg := range ex.Args {
+			e.collectUsages(arg, clause)
+		}
+	case *ast.BinaryExpr:
+		e.collectUsages(ex.Left, clause)
+		e.collectUsages(ex.Right, clause)
+	case *ast.CaseExpr:
+		e.collectUsages(ex.Operand, clause)
+		for _, when := range ex.WhenList {
+			e.collectUsages(when.Condition, clause)
+			e.collectUsages(when.Result, clause)
+		}
+		e.collectUsages(ex.Else, clause)
+	case *ast.PredicateExpr:
+		for _, operand := range ex.Operands {
+			e.collectUsages(operand, clause)
+		}
+	case *ast.AliasedExpr:
+		e.collectUsages(ex.Expr, clause)
+	case *ast.SubqueryExpr:
+		e.collectSubqueryUsages(ex.Query)
+	}
+}
+
+// collectSubqueryUsages records the usages of a nested query, which may
+// refer to the tables of the current scope.
+func (e *Extractor) collectSubqueryUsages(query *ast.SelectStmt) {
+	if query == nil {
+		return
+	}
+	sub := NewExtractor(e.catalog)
+	sub.scope = newScope(e.scope)
+	sub.extractSelect(query, "")
+	for _, u := range sub.usages {
+		e.addUsage(u.Column, u.Clause)
+	}
+	for _, key := range sub.joinKeys {
+		e.addJoinKey(key)
+	}
+	e.scans = append(e.scans, sub.scans...)
+}
+
+// usageRef resolves a column reference to a table column. ok is false for
+// columns of CTEs and derived tables, columns the catalog does not know and
+// columns that cannot be attributed to a single table.
+func (e *Extractor) usageRef(tableHint, column string) (ref ColumnRef, ok bool) {
+	table, ok := e.columnTable(tableHint, column)
+	if !ok {
+		return ColumnRef{}, false
+	}
+	return ColumnRef{Database: table.Database, Table: table.Table, Column: column}, true
+}
+
+// columnTable returns the table reference a column reference resolves to;
+// see usageRef.
+func (e *Extractor) columnTable(tableHint, column string) (*ast.TableRef, bool) {
+	var table *ast.TableRef
+	var cols []string
+	known := false
+
+	switch {
+	case tableHint != "":
+		for s := e.scope; s != nil && table == nil; s = s.parent {
+			table = s.tableAlias[tableHint]
+			cols, known = s.columns[tableHint]
+		}
+	case len(e.scope.tableAlias) == 1:
+		for alias, t := range e.scope.tableAlias {
+			table = t
+			cols, known = e.scope.columns[alias]
+		}
+	default:
+		for alias, c := range e.scope.columns {
+			if !containsColumn(c, column) {
+				continue
+			}
+			if table != nil {
+				return nil, false
+			}
+			table, cols, known = e.scope.tableAlias[alias], c, true
+		}
+	}
+
+	if table == nil || e.isDerived(table.Table) || known && !containsColumn(cols, column) {
+		return nil, false
+	}
+	return table, true
+}
+
+// addUsage records a column usage once per clause.
+func (e *Extractor) addUsage(ref ColumnRef, clause UsageClause) {
+	u := ColumnUsage{Column: ref, Clause: clause}
+	if e.used[u] {
+		return
+	}
+	e.used[u] = true
+	e.usages = append(e.usages, u)
+}
+
+// collectJoinKeys records the equality comparisons of condition between
+// columns of two different tables.
+func (e *Extractor) collectJoinKeys(condition ast.Expression) {
+	switch ex := condition.(type) {
+	case *ast.PredicateExpr:
+		for _, operand := range ex.Operands {
+			e.collectJoinKeys(operand)
+		}
+	case *ast.BinaryExpr:
+		if ex.Operator != "=" {
+			return
+		}
+		left, lok := ex.Left.(*ast.ColumnRefExpr)
+		right, rok := ex.Right.(*ast.ColumnRefExpr)
+		if !lok || !rok {
+			return
+		}
+		leftRef, lok := e.usageRef(left.Table, left.Column)
+		rightRef, rok := e.usageRef(right.Table, right.Column)
+		if lok && rok && !strings.EqualFold(leftRef.TableName(), rightRef.TableName()) {
+			e.addJoinKey(JoinKey{Left: leftRef, Right: rightRef})
+		}
+	}
+}
+
+// orCondition matches the OR and NOT keywords of a condition. The AST does
+// not model the boolean operators of conditions, so their raw text is
+// checked instead.
+var orCondition = regexp.MustCompile(`(?i)\b(OR|NOT)\b`)
+
+// collectScanFilters records the column = literal comparisons of a WHERE
+// condition as filters of the scans of the current scope. Conditions with
+// OR or NOT are skipped, since their comparisons do not all have to hold.
+func (e *Extractor) collectScanFilters(condition ast.Expression) {
+	operands := []ast.Expression{condition}
+	if p, ok := condition.(*ast.PredicateExpr); ok {
+		if orCondition.MatchString(stripStringLiterals(p.RawText)) {
+			return
+		}
+		operands = p.Operands
+	}
+	for _, operand := range operands {
+		ex, ok := operand.(*ast.BinaryExpr)
+		if !ok || ex.Operator != "=" {
+			continue
+		}
+		col, cok := ex.Left.(*ast.ColumnRefExpr)
+		lit, lok := ex.Right.(*ast.LiteralExpr)
+		if !cok || !lok {
+			col, cok = ex.Right.(*ast.ColumnRefExpr)
+			lit, lok = ex.Left.(*ast.LiteralExpr)
+		}
+		if !cok || !lok || lit.Type == "null" {
+			continue
+		}
+		table, ok := e.columnTable(col.Table, col.Column)
+		if !ok {
+			continue
+		}
+		i, ok := e.scanOf[table]
+		if !ok {
+			// A column of an outer query, which the condition does not filter
+			continue
+		}
+		scan := &e.scans[i]
+		if scan.Filters == nil {
+			scan.Filters = make(map[string]string)
+		}
+		scan.Filters[strings.ToLower(col.Column)] = literalValue(lit)
+	}
+}
+
+// stripStringLiterals removes the quoted strings of SQL text.
+func stripStringLiterals(sql string) string {
+	var b strings.Builder
+	var quote rune
+	for _, r := range sql {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// literalValue returns the value of a literal, without the quotes of a
+// string.
+func literalValue(lit *ast.LiteralExpr) string {
+	v := lit.Value
+	if lit.Type == "string" && len(v) >= 2 && (v[0] == '\'' || v[0] == '"') && v[len(v)-1] == v[0] {
+		v = strings.ReplaceAll(v[1:len(v)-1], v[:1]+v[:1], v[:1])
+	}
+	return v
+}
+
+// addJoinKey records a join key once, regardless of the side each column
+// was written on.
+func (e *Extractor) addJoinKey(key JoinKey) {
+	key = key.normalize()
+	for _, k := range e.joinKeys {
+		if k == key {
+			return
+		}
+	}
+	e.joinKeys = append(e.joinKeys, key)
+}
@@ -0,0 +1,153 @@
+package lineage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Output formats of lineage results.
+const (
+	FormatText    = "text"
+	FormatJSON    = "json"
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+)
+
+// queryTable labels the columns of results that are not written to a table,
+// such as those of a plain SELECT.
+const queryTable = "(query)"
+
+// diagram is the column lineage of results as tables of columns and the edges
+// between them, in the order they first appear.
+type diagram struct {
+	tables  []string
+	columns map[string][]string // table -> column names
+	ids     map[string]int      // table.column -> node id
+	edges   []diagramEdge
+	seen    map[diagramEdge]bool
+}
+
+type diagramEdge struct {
+	source, target int
+	// inferred marks sources that were not confirmed against a catalog.
+	inferred bool
+}
+
+func newDiagram(results []*LineageResult) *diagram {
+	d := &diagram{
+		columns: make(map[string][]string),
+		ids:     make(map[string]int),
+		seen:    make(map[diagramEdge]bool),
+	}
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		for _, col := range result.Columns {
+			target := d.node(col.Target)
+			for _, src := range col.Sources {
+				e := diagramEdge{
+					source:   d.node(src),
+					target:   target,
+					inferred: src.Confidence != "" && src.Confidence != ConfidenceCatalog,
+				}
+				if !d.seen[e] {
+					d.seen[e] = true
+					d.edges = append(d.edges, e)
+				}
+			}
+		}
+	}
+	return d
+}
+
+// node returns the id of the node of a column, adding it if needed.
+func (d *diagram) node(c ColumnRef) int {
+	table, column := c.TableName(), c.Column
+	if table == "" {
+		table = queryTable
+	}
+	if column == "" {
+		column = "*"
+	}
+	key := table + "\x00" + column
+	if id, ok := d.ids[key]; ok {
+		return id
+	}
+	if _, ok := d.columns[table]; !ok {
+		d.tables = append(d.tables, table)
+	}
+	d.columns[table] = append(d.columns[table], column)
+	id := len(d.ids)
+	d.ids[key] = id
+	return id
+}
+
+// id returns the node id of a column of a table.
+func (d *diagram) id(table, column string) int {
+	return d.ids[table+"\x00"+column]
+}
+
+// WriteDOT writes the column lineage of results as a Graphviz digraph: each
+// table is a cluster of its columns, and sources not confirmed against a
+// catalog are drawn with dashed edges.
+func WriteDOT(w io.Writer, results ...*LineageResult) error {
+	d := newDiagram(results)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph lineage {")
+	fmt.Fprintln(bw, "    rankdir=LR;")
+	fmt.Fprintln(bw, "    node [shape=box];")
+	for i, table := range d.tables {
+		fmt.Fprintf(bw, "    subgraph cluster_%d {\n", i)
+		fmt.Fprintf(bw, "        label=%s;\n", dotQuote(table))
+		for _, column := range d.columns[table] {
+			fmt.Fprintf(bw, "        n%d [label=%s];\n", d.id(table, column), dotQuote(column))
+		}
+		fmt.Fprintln(bw, "    }")
+	}
+	for _, e := range d.edges {
+		style := ""
+		if e.inferred {
+			style = " [style=dashed]"
+		}
+		fmt.Fprintf(bw, "    n%d -> n%d%s;\n", e.source, e.target, style)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// WriteMermaid writes the column lineage of results as a Mermaid flowchart:
+// each table is a subgraph of its columns, and sources not confirmed against
+// a catalog are drawn with dotted edges.
+func WriteMermaid(w io.Writer, results ...*LineageResult) error {
+	d := newDiagram(results)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "flowchart LR")
+	for i, table := range d.tables {
+		fmt.Fprintf(bw, "    subgraph t%d[%s]\n", i, mermaidQuote(table))
+		for _, column := range d.columns[table] {
+			fmt.Fprintf(bw, "        n%d[%s]\n", d.id(table, column), mermaidQuote(column))
+		}
+		fmt.Fprintln(bw, "    end")
+	}
+	for _, e := range d.edges {
+		arrow := "-->"
+		if e.inferred {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(bw, "    n%d %s n%d\n", e.source, arrow, e.target)
+	}
+	return bw.Flush()
+}
+
+// dotQuote quotes a DOT identifier.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// mermaidQuote quotes a Mermaid label, escaping quotes as entity codes.
+func mermaidQuote(s string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s) + `"`
+}
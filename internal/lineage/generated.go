@@ -0,0 +1,61 @@
+package lineage
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// GeneratedColumn is a generated (computed) column of a table and the
+// expression it is computed from.
+type GeneratedColumn struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+// charsetIntroducer matches MySQL charset introducers such as _utf8mb4'...',
+// which the grammar does not support.
+var charsetIntroducer = regexp.MustCompile(`\b_[A-Za-z0-9]+'`)
+
+// AnalyzeGenerated derives the lineage of generated columns from the base
+// columns of the same table. Expressions that cannot be parsed are reported
+// as unresolved references instead of failing the whole table.
+func (a *Analyzer) AnalyzeGenerated(database, table string, columns []GeneratedColumn) *LineageResult {
+	result := &LineageResult{}
+	from := table
+	if database != "" {
+		from = database + "." + table
+	}
+	for _, col := range columns {
+		expr := charsetIntroducer.ReplaceAllString(col.Expression, "'")
+		stmt, err := ParseSQL(fmt.Sprintf("SELECT %s FROM %s", expr, from))
+		if err != nil {
+			result.Unresolved = append(result.Unresolved, UnresolvedRef{
+				Database: database,
+				Table:    table,
+				Column:   col.Name,
+				Reason:   fmt.Sprintf("unparsable generation expression: %v", err),
+			})
+			continue
+		}
+		r, err := NewExtractor(a.catalog).Extract(stmt)
+		if err != nil || len(r.Columns) != 1 {
+			result.Unresolved = append(result.Unresolved, UnresolvedRef{
+				Database: database,
+				Table:    table,
+				Column:   col.Name,
+				Reason:   "unsupported generation expression",
+			})
+			continue
+		}
+
+		lineage := r.Columns[0]
+		lineage.Target = ColumnRef{Database: database, Table: table, Column: col.Name}
+		for i := range lineage.Sources {
+			if lineage.Sources[i].Table == table {
+				lineage.Sources[i].Database = database
+			}
+		}
+		result.Columns = append(result.Columns, lineage)
+	}
+	return result
+}
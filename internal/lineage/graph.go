@@ -0,0 +1,351 @@
+package lineage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QualifiedName returns the dotted database.table.column name of the reference,
+// omitting empty parts.
+func (c ColumnRef) QualifiedName() string {
+	parts := make([]string, 0, 3)
+	for _, p := range []string{c.Database, c.Table, c.Column} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// TableName returns the dotted database.table name of the reference.
+func (c ColumnRef) TableName() string {
+	if c.Database == "" {
+		return c.Table
+	}
+	return c.Database + "." + c.Table
+}
+
+// Origin identifies how a lineage edge was obtained.
+type Origin string
+
+const (
+	// OriginAnalysis is lineage from SQL submitted for analysis.
+	OriginAnalysis Origin = "analysis"
+	// OriginQueryLog is lineage harvested from database query logs.
+	OriginQueryLog Origin = "query_log"
+	// OriginCatalog is lineage read from system catalogs (e.g. view dependencies).
+	OriginCatalog Origin = "catalog"
+	// OriginHook is lineage reported by execution engines, such as Hive
+	// post-execution hooks and Spark listeners.
+	OriginHook Origin = "hook"
+)
+
+// Provenance records where and when a lineage edge was observed.
+type Provenance struct {
+	Origins      []Origin  `json:"origins,omitempty"`
+	Fingerprints []string  `json:"fingerprints"`
+	Jobs         []string  `json:"jobs,omitempty"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+	Occurrences  int       `json:"occurrences"`
+}
+
+// Edge is a deduplicated column-level lineage edge (source -> target).
+type Edge struct {
+	Source     ColumnRef  `json:"source"`
+	Target     ColumnRef  `json:"target"`
+	Operators  []string   `json:"operators,omitempty"`
+	Provenance Provenance `json:"provenance"`
+	Validity   []Interval `json:"validity"`
+}
+
+// Key returns the identity of the edge used for deduplication.
+func (e *Edge) Key() string {
+	return edgeKey(e.Source, e.Target)
+}
+
+func edgeKey(source, target ColumnRef) string {
+	return source.QualifiedName() + "->" + target.QualifiedName()
+}
+
+// Graph accumulates lineage results from many statements into a deduplicated
+// set of edges. Repeated observations of the same edge update its provenance
+// instead of adding a new edge. Graph is safe for concurrent use.
+type Graph struct {
+	mu    sync.RWMutex
+	edges map[string]*Edge
+	jobs  map[string]*Job
+}
+
+// NewGraph creates an empty lineage graph.
+func NewGraph() *Graph {
+	return &Graph{
+		edges: make(map[string]*Edge),
+		jobs:  make(map[string]*Job),
+	}
+}
+
+// Add merges the edges of a lineage result observed at the given time.
+// fingerprint identifies the statement that produced the result (see Fingerprint).
+// Each edge is counted once per call, even if the statement repeats it.
+func (g *Graph) Add(result *LineageResult, fingerprint string, at time.Time) {
+	g.AddFrom(result, fingerprint, OriginAnalysis, at)
+}
+
+// AddFrom is like Add but records the origin of the edges, which retention
+// policies use to decide how long unobserved edges are kept.
+func (g *Graph) AddFrom(result *LineageResult, fingerprint string, origin Origin, at time.Time) {
+	if result == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.addLocked(result, fingerprint, "", origin, at, 1)
+}
+
+// AddExecutions is like AddFrom for a statement executed a number of times,
+// as counted by a database query log: the occurrences of its edges grow by
+// executions rather than one.
+func (g *Graph) AddExecutions(result *LineageResult, fingerprint string, origin Origin, at time.Time, executions int) {
+	if result == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.addLocked(result, fingerprint, "", origin, at, executions)
+}
+
+func (g *Graph) addLocked(result *LineageResult, fingerprint, job string, origin Origin, at time.Time, count int) {
+	counted := make(map[string]bool)
+	for _, col := range result.Columns {
+		for _, src := range col.Sources {
+			source := src
+			source.Confidence = ""
+			key := edgeKey(source, col.Target)
+
+			edge, ok := g.edges[key]
+			if !ok {
+				edge = &Edge{
+					Source:     source,
+					Target:     col.Target,
+					Provenance: Provenance{FirstSeen: at, LastSeen: at},
+				}
+				g.edges[key] = edge
+			}
+			edge.validFrom(at)
+			edge.Operators = appendUnique(edge.Operators, col.Operators...)
+			if job != "" {
+				edge.Provenance.Jobs = appendUnique(edge.Provenance.Jobs, job)
+			}
+			if origin != "" && !containsOrigin(edge.Provenance.Origins, origin) {
+				edge.Provenance.Origins = append(edge.Provenance.Origins, origin)
+			}
+			if counted[key] {
+				continue
+			}
+			counted[key] = true
+			edge.observe(fingerprint, at, count)
+		}
+	}
+}
+
+// Merge merges all edges of other into g, combining their provenance.
+func (g *Graph) Merge(other *Graph) {
+	if other == nil || other == g {
+		return
+	}
+	g.Import(other.Edges(), other.Jobs())
+}
+
+// Import merges edges and jobs obtained outside of g, such as from a snapshot
+// of another graph, combining their provenance as Merge does.
+func (g *Graph) Import(edges []*Edge, jobs []*Job) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, e := range edges {
+		edge, ok := g.edges[e.Key()]
+		if !ok {
+			g.edges[e.Key()] = e.clone()
+			continue
+		}
+		edge.Operators = appendUnique(edge.Operators, e.Operators...)
+		edge.Provenance.Fingerprints = appendUnique(edge.Provenance.Fingerprints, e.Provenance.Fingerprints...)
+		edge.Provenance.Jobs = appendUnique(edge.Provenance.Jobs, e.Provenance.Jobs...)
+		for _, origin := range e.Provenance.Origins {
+			if !containsOrigin(edge.Provenance.Origins, origin) {
+				edge.Provenance.Origins = append(edge.Provenance.Origins, origin)
+			}
+		}
+		edge.observe("", e.Provenance.FirstSeen, 0)
+		edge.observe("", e.Provenance.LastSeen, e.Provenance.Occurrences)
+		edge.Validity = mergeIntervals(append(edge.Validity, e.Validity...))
+	}
+
+	for _, job := range jobs {
+		existing, ok := g.jobs[job.Name]
+		if !ok {
+			g.jobs[job.Name] = job.clone()
+			continue
+		}
+		existing.merge(job)
+	}
+}
+
+// observe updates the provenance of the edge with an observation.
+func (e *Edge) observe(fingerprint string, at time.Time, count int) {
+	p := &e.Provenance
+	if fingerprint != "" {
+		p.Fingerprints = appendUnique(p.Fingerprints, fingerprint)
+	}
+	if !at.IsZero() {
+		if p.FirstSeen.IsZero() || at.Before(p.FirstSeen) {
+			p.FirstSeen = at
+		}
+		if at.After(p.LastSeen) {
+			p.LastSeen = at
+		}
+	}
+	p.Occurrences += count
+}
+
+// Edges returns copies of all edges sorted by source and target.
+func (g *Graph) Edges() []*Edge {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	edges := make([]*Edge, 0, len(g.edges))
+	for _, e := range g.edges {
+		edges = append(edges, e.clone())
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i].Key() < edges[j].Key()
+	})
+	return edges
+}
+
+// Len returns the number of distinct edges in the graph.
+func (g *Graph) Len() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.edges)
+}
+
+func (e *Edge) clone() *Edge {
+	c := *e
+	c.Operators = append([]string(nil), e.Operators...)
+	c.Provenance.Fingerprints = append([]string(nil), e.Provenance.Fingerprints...)
+	c.Provenance.Jobs = append([]string(nil), e.Provenance.Jobs...)
+	c.Provenance.Origins = append([]Origin(nil), e.Provenance.Origins...)
+	c.Validity = append([]Interval(nil), e.Validity...)
+	return &c
+}
+
+func containsOrigin(origins []Origin, origin Origin) bool {
+	for _, o := range origins {
+		if o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// appendUnique appends the values not already present in list.
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// Fingerprint returns a stable identifier for a SQL statement. Comments,
+// whitespace, letter case and literal values are normalized away so variants
+// of the same statement share a fingerprint.
+func Fingerprint(sql string) string {
+	sum := sha256.Sum256([]byte(normalizeSQL(sql)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// normalizeSQL strips comments, replaces literals with '?', lowercases
+// identifiers and keywords, and collapses whitespace.
+func normalizeSQL(sql string) string {
+	var b strings.Builder
+	space := false
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			space = true
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 3
+			}
+			space = true
+		case c == '\'':
+			for i++; i < len(sql); i++ {
+				if sql[i] == '\'' {
+					if i+1 < len(sql) && sql[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			writeNormalized(&b, "?", &space)
+		case c >= '0' && c <= '9' && (space || !endsWithIdentifier(b.String())):
+			for i+1 < len(sql) && (sql[i+1] >= '0' && sql[i+1] <= '9' || sql[i+1] == '.') {
+				i++
+			}
+			writeNormalized(&b, "?", &space)
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+		case c == ';':
+			space = true
+		default:
+			writeNormalized(&b, strings.ToLower(string(c)), &space)
+		}
+	}
+	return b.String()
+}
+
+func writeNormalized(b *strings.Builder, s string, space *bool) {
+	if *space && b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	*space = false
+	b.WriteString(s)
+}
+
+// endsWithIdentifier reports whether s ends with an identifier character,
+// so digits inside names like "t1" are kept.
+func endsWithIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	c := s[len(s)-1]
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
+}
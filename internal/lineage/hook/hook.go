@@ -0,0 +1,211 @@
+// Package hook merges lineage events reported by execution engines, such as
+// Hive post-execution hooks and Spark listeners, into the lineage graph. It
+// covers the jobs whose SQL is never submitted for analysis: the engine
+// reports the tables a query read and wrote, and optionally the column
+// lineage it computed or the SQL it ran.
+package hook
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-metadata/internal/lineage"
+)
+
+// Engines reporting lineage events.
+const (
+	EngineHive  = "hive"
+	EngineSpark = "spark"
+)
+
+// StatusFailed is the status of an event of a query that failed, whose
+// lineage is not recorded. Events without a status are of successful queries.
+const StatusFailed = "failed"
+
+// ErrInvalidEvent is returned for events missing their engine, job or
+// datasets.
+var ErrInvalidEvent = errors.New("invalid lineage event")
+
+// Event is the lineage of a query or job run, as reported by a Hive hook or
+// a Spark listener. Tables are qualified names, database.table; columns are
+// database.table.column.
+type Event struct {
+	// Engine is hive or spark.
+	Engine string `json:"engine"`
+	// Job names the job; the query ID is used if it is empty.
+	Job     string `json:"job,omitempty"`
+	QueryID string `json:"query_id,omitempty"`
+	User    string `json:"user,omitempty"`
+	// SQL is the query text, analyzed for column lineage when Columns is
+	// empty.
+	SQL     string       `json:"sql,omitempty"`
+	Inputs  []string     `json:"inputs,omitempty"`
+	Outputs []string     `json:"outputs,omitempty"`
+	Columns []ColumnEdge `json:"columns,omitempty"`
+
+	Status    string    `json:"status,omitempty"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	EndedAt   time.Time `json:"ended_at,omitempty"`
+}
+
+// ColumnEdge is the lineage of a column written by the job, such as the
+// dependencies of Hive's LineageLogger.
+type ColumnEdge struct {
+	Target  string   `json:"target"`
+	Sources []string `json:"sources"`
+	// Operators are the functions applied to the sources, e.g. SUM.
+	Operators []string `json:"operators,omitempty"`
+}
+
+// JobName returns the name of the job of the event.
+func (e *Event) JobName() string {
+	if e.Job != "" {
+		return e.Job
+	}
+	return e.QueryID
+}
+
+// Failed reports whether the event is of a failed query.
+func (e *Event) Failed() bool {
+	return strings.EqualFold(e.Status, StatusFailed)
+}
+
+// Validate checks that the event names a known engine, a job and the
+// datasets it wrote.
+func (e *Event) Validate() error {
+	switch strings.ToLower(e.Engine) {
+	case EngineHive, EngineSpark:
+	default:
+		return fmt.Errorf("%w: unknown engine %q", ErrInvalidEvent, e.Engine)
+	}
+	if e.JobName() == "" {
+		return fmt.Errorf("%w: job or query_id is required", ErrInvalidEvent)
+	}
+	if len(e.Outputs) == 0 && len(e.Columns) == 0 && strings.TrimSpace(e.SQL) == "" {
+		return fmt.Errorf("%w: outputs, columns or sql is required", ErrInvalidEvent)
+	}
+	for _, c := range e.Columns {
+		if len(strings.Split(c.Target, ".")) < 2 {
+			return fmt.Errorf("%w: column %q is not qualified with its table", ErrInvalidEvent, c.Target)
+		}
+	}
+	return nil
+}
+
+// JobNode returns the job node of the event, whose inputs and outputs are the
+// tables it reported.
+func (e *Event) JobNode() *lineage.Job {
+	job := &lineage.Job{
+		Name:       e.JobName(),
+		Type:       lineage.JobTypeSparkJob,
+		Properties: map[string]string{"engine": strings.ToLower(e.Engine)},
+		Inputs:     e.Inputs,
+		Outputs:    e.Outputs,
+	}
+	if strings.EqualFold(e.Engine, EngineHive) {
+		job.Type = lineage.JobTypeHiveQuery
+	}
+	if e.QueryID != "" {
+		job.Properties["query_id"] = e.QueryID
+	}
+	if e.User != "" {
+		job.Properties["user"] = e.User
+	}
+	return job
+}
+
+// Lineage returns the column lineage of the event: the columns it reported,
+// or else the lineage of its SQL analyzed with analyzer, or else table-level
+// edges from every input to every output, as columns named *. analyzer may
+// be nil; SQL it rejects falls back to table-level edges.
+func (e *Event) Lineage(analyzer *lineage.Analyzer) *lineage.LineageResult {
+	if len(e.Columns) > 0 {
+		result := &lineage.LineageResult{Columns: make([]lineage.ColumnLineage, 0, len(e.Columns))}
+		for _, c := range e.Columns {
+			col := lineage.ColumnLineage{
+				Target:    parseColumn(c.Target),
+				Sources:   make([]lineage.ColumnRef, 0, len(c.Sources)),
+				Operators: c.Operators,
+			}
+			if col.Operators == nil {
+				col.Operators = []string{}
+			}
+			for _, s := range c.Sources {
+				col.Sources = append(col.Sources, parseColumn(s))
+			}
+			result.Columns = append(result.Columns, col)
+		}
+		return result
+	}
+
+	if analyzer != nil && strings.TrimSpace(e.SQL) != "" {
+		if result, err := analyzer.Analyze(e.SQL); err == nil && len(result.Columns) > 0 {
+			return result
+		}
+	}
+
+	result := &lineage.LineageResult{Columns: make([]lineage.ColumnLineage, 0, len(e.Outputs))}
+	if len(e.Inputs) == 0 {
+		return result
+	}
+	sources := make([]lineage.ColumnRef, 0, len(e.Inputs))
+	for _, input := range e.Inputs {
+		source := lineage.ParseTableRef(input)
+		source.Column = "*"
+		sources = append(sources, source)
+		result.Scans = append(result.Scans, lineage.TableScan{Database: source.Database, Table: source.Table})
+	}
+	for _, output := range e.Outputs {
+		target := lineage.ParseTableRef(output)
+		target.Column = "*"
+		result.Columns = append(result.Columns, lineage.ColumnLineage{Target: target, Sources: sources, Operators: []string{}})
+	}
+	return result
+}
+
+// Apply registers the job of an event in g and merges its lineage, with the
+// hook origin. Events of failed queries are ignored, and nil is returned for
+// them; otherwise the job is returned. The lineage is recorded as observed
+// when the query ended, or at if the event does not tell.
+func Apply(g *lineage.Graph, event *Event, analyzer *lineage.Analyzer, at time.Time) (*lineage.Job, error) {
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
+	if event.Failed() {
+		return nil, nil
+	}
+
+	job := event.JobNode()
+	if err := g.RegisterJob(job); err != nil {
+		return nil, fmt.Errorf("register job %s: %w", job.Name, err)
+	}
+	if !event.EndedAt.IsZero() {
+		at = event.EndedAt
+	} else if !event.StartedAt.IsZero() {
+		at = event.StartedAt
+	}
+	fingerprint := ""
+	if strings.TrimSpace(event.SQL) != "" {
+		fingerprint = lineage.Fingerprint(event.SQL)
+	}
+	if err := g.AttachStatementFrom(job.Name, event.Lineage(analyzer), fingerprint, lineage.OriginHook, at); err != nil {
+		return nil, err
+	}
+	return g.Job(job.Name)
+}
+
+// parseColumn parses a qualified column name, database.table.column. Names
+// qualified with a catalog, as Spark reports them, keep their last three
+// parts.
+func parseColumn(name string) lineage.ColumnRef {
+	name = strings.TrimSpace(name)
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return lineage.ColumnRef{Column: name}
+	}
+	ref := lineage.ParseTableRef(name[:i])
+	ref.Column = name[i+1:]
+	return ref
+}
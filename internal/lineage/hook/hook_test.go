@@ -0,0 +1,132 @@
+package hook
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"go-metadata/internal/lineage"
+)
+
+const hiveEvent = `{
+  "engine": "hive",
+  "query_id": "hive_20260101_0001",
+  "user": "etl",
+  "inputs": ["ods.orders"],
+  "outputs": ["dw.daily_sales"],
+  "columns": [
+    {"target": "dw.daily_sales.total", "sources": ["ods.orders.amount"], "operators": ["SUM"]},
+    {"target": "dw.daily_sales.day", "sources": ["ods.orders.created_at"]}
+  ],
+  "ended_at": "2026-01-01T02:00:00Z"
+}`
+
+func TestApply_HiveColumns(t *testing.T) {
+	var event Event
+	if err := json.Unmarshal([]byte(hiveEvent), &event); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	g := lineage.NewGraph()
+	job, err := Apply(g, &event, nil, time.Now())
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if job.Name != "hive_20260101_0001" || job.Type != lineage.JobTypeHiveQuery {
+		t.Errorf("Unexpected job %s of type %s", job.Name, job.Type)
+	}
+	if job.Properties["user"] != "etl" {
+		t.Errorf("Expected user property, got %v", job.Properties)
+	}
+	if want := time.Date(2026, 1, 1, 2, 0, 0, 0, time.UTC); !job.LastRunAt.Equal(want) {
+		t.Errorf("Expected last run at %v, got %v", want, job.LastRunAt)
+	}
+
+	edges := g.Edges()
+	if len(edges) != 2 {
+		t.Fatalf("Expected 2 edges, got %d", len(edges))
+	}
+	for _, edge := range edges {
+		if edge.Target.Table != "daily_sales" || edge.Source.Table != "orders" {
+			t.Errorf("Unexpected edge %s", edge.Key())
+		}
+		if len(edge.Provenance.Origins) != 1 || edge.Provenance.Origins[0] != lineage.OriginHook {
+			t.Errorf("Expected hook origin, got %v", edge.Provenance.Origins)
+		}
+		if len(edge.Provenance.Jobs) != 1 || edge.Provenance.Jobs[0] != job.Name {
+			t.Errorf("Expected job %s in provenance, got %v", job.Name, edge.Provenance.Jobs)
+		}
+	}
+}
+
+func TestApply_SparkSQL(t *testing.T) {
+	event := &Event{
+		Engine: EngineSpark,
+		Job:    "nightly_orders",
+		SQL:    "INSERT INTO orders_dw SELECT id, amount FROM orders_ods",
+	}
+	g := lineage.NewGraph()
+	job, err := Apply(g, event, lineage.NewAnalyzer(nil), time.Now())
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if job.Type != lineage.JobTypeSparkJob {
+		t.Errorf("Expected spark job, got %s", job.Type)
+	}
+	if len(job.Inputs) != 1 || job.Inputs[0] != "orders_ods" || len(job.Outputs) != 1 || job.Outputs[0] != "orders_dw" {
+		t.Errorf("Unexpected inputs %v and outputs %v", job.Inputs, job.Outputs)
+	}
+	if len(job.Statements) != 1 {
+		t.Errorf("Expected the statement fingerprint, got %v", job.Statements)
+	}
+	if got := len(g.Edges()); got != 2 {
+		t.Errorf("Expected 2 edges, got %d", got)
+	}
+}
+
+func TestApply_TableLevel(t *testing.T) {
+	event := &Event{
+		Engine:  EngineSpark,
+		Job:     "copy_job",
+		SQL:     "CALL system.rewrite_data_files('dw.orders')",
+		Inputs:  []string{"ods.orders", "ods.customers"},
+		Outputs: []string{"dw.orders"},
+	}
+	g := lineage.NewGraph()
+	if _, err := Apply(g, event, lineage.NewAnalyzer(nil), time.Now()); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	edges := g.Edges()
+	if len(edges) != 2 {
+		t.Fatalf("Expected 2 table-level edges, got %d", len(edges))
+	}
+	for _, edge := range edges {
+		if edge.Target.Column != "*" || edge.Source.Column != "*" {
+			t.Errorf("Expected * columns, got %s", edge.Key())
+		}
+	}
+}
+
+func TestApply_FailedAndInvalid(t *testing.T) {
+	g := lineage.NewGraph()
+	failed := &Event{Engine: EngineHive, Job: "j", Outputs: []string{"dw.t"}, Status: "FAILED"}
+	job, err := Apply(g, failed, nil, time.Now())
+	if err != nil || job != nil {
+		t.Errorf("Expected failed event to be ignored, got %v, %v", job, err)
+	}
+	if len(g.Jobs()) != 0 {
+		t.Errorf("Expected no jobs, got %d", len(g.Jobs()))
+	}
+
+	invalid := []*Event{
+		{Engine: "presto", Job: "j", Outputs: []string{"dw.t"}},
+		{Engine: EngineHive, Outputs: []string{"dw.t"}},
+		{Engine: EngineHive, Job: "j"},
+		{Engine: EngineSpark, Job: "j", Columns: []ColumnEdge{{Target: "amount"}}},
+	}
+	for _, e := range invalid {
+		if _, err := Apply(g, e, nil, time.Now()); !errors.Is(err, ErrInvalidEvent) {
+			t.Errorf("Expected ErrInvalidEvent for %+v, got %v", e, err)
+		}
+	}
+}
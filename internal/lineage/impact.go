@@ -0,0 +1,168 @@
+package lineage
+
+import "sort"
+
+// Impact lists what is downstream of a column or table, i.e. what breaks if
+// it is dropped or changed.
+type Impact struct {
+	Node string `json:"node"`
+	// Columns are the downstream columns, nearest first.
+	Columns []ImpactedColumn `json:"columns"`
+	// Tables are the qualified names of the tables of the downstream
+	// columns, sorted.
+	Tables []string `json:"tables"`
+	// Jobs are the sorted names of the jobs that produce the downstream
+	// columns or read their tables.
+	Jobs []string `json:"jobs"`
+}
+
+// ImpactedColumn is a column downstream of the analyzed node.
+type ImpactedColumn struct {
+	Column string `json:"column"`
+	// Hops is the number of lineage edges from the node to the column.
+	Hops int `json:"hops"`
+	// Operators are the transformations of the edges the column is derived
+	// through.
+	Operators []string `json:"operators,omitempty"`
+}
+
+// Impact returns what is downstream of node within depth hops (depth <= 0
+// means unlimited). node is a qualified column name (database.table.column)
+// or a qualified table name, as for Traverse.
+func (g *Graph) Impact(node string, depth int) *Impact {
+	edges := g.Traverse(node, Downstream, depth)
+	tableLevel := !hasColumnNode(edges, node)
+	name := func(c ColumnRef) string {
+		if tableLevel {
+			return c.TableName()
+		}
+		return c.QualifiedName()
+	}
+
+	impact := &Impact{Node: node, Columns: make([]ImpactedColumn, 0), Tables: make([]string, 0), Jobs: make([]string, 0)}
+	// Traverse returns the edges hop by hop, so the sources of an edge are
+	// reached before its target
+	hops := map[string]int{node: 0}
+	columns := make(map[string]int)
+	tables := make(map[string]bool)
+	jobs := make(map[string]bool)
+	for _, edge := range edges {
+		to := name(edge.Target)
+		if _, ok := hops[to]; !ok {
+			hops[to] = hops[name(edge.Source)] + 1
+		}
+		column := edge.Target.QualifiedName()
+		if column == node {
+			continue
+		}
+		if i, ok := columns[column]; ok {
+			impact.Columns[i].Operators = appendUnique(impact.Columns[i].Operators, edge.Operators...)
+		} else {
+			columns[column] = len(impact.Columns)
+			impact.Columns = append(impact.Columns, ImpactedColumn{
+				Column:    column,
+				Hops:      hops[to],
+				Operators: appendUnique(nil, edge.Operators...),
+			})
+		}
+		if table := edge.Target.TableName(); table != node {
+			tables[table] = true
+		}
+		for _, job := range edge.Provenance.Jobs {
+			jobs[job] = true
+		}
+	}
+	for table := range tables {
+		for _, job := range g.JobsReading(table) {
+			jobs[job.Name] = true
+		}
+	}
+
+	sort.SliceStable(impact.Columns, func(i, j int) bool {
+		return impact.Columns[i].Hops < impact.Columns[j].Hops
+	})
+	for table := range tables {
+		impact.Tables = append(impact.Tables, table)
+	}
+	sort.Strings(impact.Tables)
+	for job := range jobs {
+		impact.Jobs = append(impact.Jobs, job)
+	}
+	sort.Strings(impact.Jobs)
+	return impact
+}
+
+// Cycles returns the cycles of the table-level dependencies of the current
+// edges: the groups of tables that depend on each other, directly or
+// transitively. Each group is sorted and the groups are sorted by their
+// first table. A table that is read and written by the same statements,
+// such as an incremental merge, is not a cycle by itself.
+func (g *Graph) Cycles() [][]string {
+	successors := make(map[string][]string)
+	for _, edge := range g.Edges() {
+		if !edge.Current() {
+			continue
+		}
+		from, to := edge.Source.TableName(), edge.Target.TableName()
+		if from == to {
+			continue
+		}
+		successors[from] = appendUnique(successors[from], to)
+		if _, ok := successors[to]; !ok {
+			successors[to] = nil
+		}
+	}
+	tables := make([]string, 0, len(successors))
+	for table := range successors {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	// Tarjan's strongly connected components
+	var (
+		index   = make(map[string]int)
+		lowlink = make(map[string]int)
+		onStack = make(map[string]bool)
+		stack   []string
+		cycles  = make([][]string, 0)
+	)
+	var connect func(table string)
+	connect = func(table string) {
+		index[table] = len(index)
+		lowlink[table] = index[table]
+		stack = append(stack, table)
+		onStack[table] = true
+		for _, next := range successors[table] {
+			if _, visited := index[next]; !visited {
+				connect(next)
+				lowlink[table] = min(lowlink[table], lowlink[next])
+			} else if onStack[next] {
+				lowlink[table] = min(lowlink[table], index[next])
+			}
+		}
+		if lowlink[table] != index[table] {
+			return
+		}
+		var component []string
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == table {
+				break
+			}
+		}
+		if len(component) > 1 {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+	for _, table := range tables {
+		if _, visited := index[table]; !visited {
+			connect(table)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
@@ -0,0 +1,196 @@
+package lineage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// JobType identifies the kind of process that moves data between datasets.
+type JobType string
+
+const (
+	JobTypeSQLScript   JobType = "sql_script"
+	JobTypeDbtModel    JobType = "dbt_model"
+	JobTypeAirflowTask JobType = "airflow_task"
+	JobTypeAirflowDAG  JobType = "airflow_dag"
+	JobTypeFlinkJob    JobType = "flink_job"
+	JobTypeSparkJob    JobType = "spark_job"
+	JobTypeHiveQuery   JobType = "hive_query"
+	JobTypeKafkaSink   JobType = "kafka_connect_sink"
+	JobTypeStorage     JobType = "storage_location"
+	JobTypeView        JobType = "view"
+)
+
+// Job is a lineage node representing a process (SQL script, dbt model,
+// Airflow task, Flink job, ...) that reads its inputs and writes its outputs.
+type Job struct {
+	Name        string            `json:"name"`
+	Type        JobType           `json:"type"`
+	Description string            `json:"description,omitempty"`
+	Properties  map[string]string `json:"properties,omitempty"`
+
+	// Inputs and Outputs are the qualified table names the job reads and writes.
+	Inputs  []string `json:"inputs"`
+	Outputs []string `json:"outputs"`
+
+	// Statements are the fingerprints of the statements attached to the job.
+	Statements []string  `json:"statements,omitempty"`
+	LastRunAt  time.Time `json:"last_run_at,omitempty"`
+}
+
+// RegisterJob registers a job node, replacing the descriptive fields of an
+// existing job with the same name while keeping its inputs, outputs and statements.
+func (g *Graph) RegisterJob(job *Job) error {
+	if job == nil || job.Name == "" {
+		return fmt.Errorf("job name is required")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if existing, ok := g.jobs[job.Name]; ok {
+		existing.Type = job.Type
+		existing.Description = job.Description
+		existing.Properties = job.Properties
+		existing.Inputs = appendUnique(existing.Inputs, job.Inputs...)
+		existing.Outputs = appendUnique(existing.Outputs, job.Outputs...)
+		return nil
+	}
+
+	j := job.clone()
+	if j.Inputs == nil {
+		j.Inputs = make([]string, 0)
+	}
+	if j.Outputs == nil {
+		j.Outputs = make([]string, 0)
+	}
+	g.jobs[j.Name] = j
+	return nil
+}
+
+// AttachStatement merges the lineage of a statement executed by the named job
+// into the graph. The job's inputs and outputs are extended with the tables the
+// statement reads and writes, and the produced edges record the job in their provenance.
+func (g *Graph) AttachStatement(jobName string, result *LineageResult, fingerprint string, at time.Time) error {
+	return g.AttachStatementFrom(jobName, result, fingerprint, OriginAnalysis, at)
+}
+
+// AttachStatementFrom is AttachStatement for lineage obtained from origin
+// rather than by analyzing SQL, e.g. reported by the engine that ran the job.
+func (g *Graph) AttachStatementFrom(jobName string, result *LineageResult, fingerprint string, origin Origin, at time.Time) error {
+	if result == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	job, ok := g.jobs[jobName]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, jobName)
+	}
+
+	g.attachLocked(job, result, fingerprint, origin, at)
+	return nil
+}
+
+func (g *Graph) attachLocked(job *Job, result *LineageResult, fingerprint string, origin Origin, at time.Time) {
+	g.addLocked(result, fingerprint, job.Name, origin, at, 1)
+
+	for _, col := range result.Columns {
+		if target := col.Target.TableName(); target != "" {
+			job.Outputs = appendUnique(job.Outputs, target)
+		}
+		for _, src := range col.Sources {
+			if source := src.TableName(); source != "" {
+				job.Inputs = appendUnique(job.Inputs, source)
+			}
+		}
+	}
+	if fingerprint != "" {
+		job.Statements = appendUnique(job.Statements, fingerprint)
+	}
+	if at.After(job.LastRunAt) {
+		job.LastRunAt = at
+	}
+}
+
+// Job returns a copy of the named job.
+func (g *Graph) Job(name string) (*Job, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	job, ok := g.jobs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	return job.clone(), nil
+}
+
+// Jobs returns copies of all jobs sorted by name.
+func (g *Graph) Jobs() []*Job {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	jobs := make([]*Job, 0, len(g.jobs))
+	for _, job := range g.jobs {
+		jobs = append(jobs, job.clone())
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Name < jobs[j].Name
+	})
+	return jobs
+}
+
+// JobsReading returns the jobs that read the given table.
+func (g *Graph) JobsReading(table string) []*Job {
+	return g.filterJobs(func(j *Job) bool { return contains(j.Inputs, table) })
+}
+
+// JobsWriting returns the jobs that write the given table.
+func (g *Graph) JobsWriting(table string) []*Job {
+	return g.filterJobs(func(j *Job) bool { return contains(j.Outputs, table) })
+}
+
+func (g *Graph) filterJobs(match func(*Job) bool) []*Job {
+	result := make([]*Job, 0)
+	for _, job := range g.Jobs() {
+		if match(job) {
+			result = append(result, job)
+		}
+	}
+	return result
+}
+
+func (j *Job) merge(other *Job) {
+	j.Inputs = appendUnique(j.Inputs, other.Inputs...)
+	j.Outputs = appendUnique(j.Outputs, other.Outputs...)
+	j.Statements = appendUnique(j.Statements, other.Statements...)
+	if other.LastRunAt.After(j.LastRunAt) {
+		j.LastRunAt = other.LastRunAt
+	}
+}
+
+func (j *Job) clone() *Job {
+	c := *j
+	c.Inputs = append([]string(nil), j.Inputs...)
+	c.Outputs = append([]string(nil), j.Outputs...)
+	c.Statements = append([]string(nil), j.Statements...)
+	if j.Properties != nil {
+		c.Properties = make(map[string]string, len(j.Properties))
+		for k, v := range j.Properties {
+			c.Properties[k] = v
+		}
+	}
+	return &c
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
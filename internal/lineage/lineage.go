@@ -2,9 +2,18 @@
 // It uses ANTLR4 for SQL parsing and extracts column-level lineage.
 package lineage
 
+import (
+	"errors"
+
+	"go-metadata/internal/lineage/ast"
+)
+
 // Analyzer is the main entry point for lineage analysis.
 type Analyzer struct {
-	catalog Catalog
+	catalog   Catalog
+	templates TemplateResolver
+	comments  bool
+	handlers  []statementHandler
 }
 
 // NewAnalyzer creates a new lineage analyzer.
@@ -14,14 +23,79 @@ func NewAnalyzer(catalog Catalog) *Analyzer {
 	}
 }
 
-// Analyze parses the SQL and extracts column-level lineage.
+// SetCatalog sets the catalog tables and their columns are resolved against.
+func (a *Analyzer) SetCatalog(catalog Catalog) {
+	a.catalog = catalog
+}
+
+// SetTemplateResolver sets the resolver used to render templated (dbt/Airflow)
+// SQL before parsing. Templated SQL is rendered even without a resolver.
+func (a *Analyzer) SetTemplateResolver(resolver TemplateResolver) {
+	a.templates = resolver
+}
+
+// SetPreserveComments sets whether results carry the comments of the SQL and
+// the annotations found in them.
+func (a *Analyzer) SetPreserveComments(preserve bool) {
+	a.comments = preserve
+}
+
+// Analyze parses the SQL and extracts column-level lineage. SQL of several
+// statements, or a stored procedure or function, is analyzed as a script by
+// AnalyzeScript.
 func (a *Analyzer) Analyze(sql string) (*LineageResult, error) {
-	// Parse SQL using ANTLR-generated parser
-	stmt, err := ParseSQL(sql)
-	if err != nil {
-		return nil, err
+	if stmts := SplitStatements(sql); len(stmts) > 1 || len(stmts) == 1 && isRoutine(stmts[0]) {
+		return a.analyzeScript(stmts)
+	}
+	result, _, err := a.analyze(sql, a.catalog)
+	return result, err
+}
+
+// analyze extracts the lineage of a statement resolved against catalog and
+// returns it with the parsed statement.
+func (a *Analyzer) analyze(sql string, catalog Catalog) (*LineageResult, ast.Statement, error) {
+	// Comments are taken before rendering, which drops template comments
+	comments := ExtractComments(sql)
+	if HasTemplate(sql) {
+		rendered, err := PreprocessTemplate(sql, a.templates)
+		if err != nil {
+			return nil, nil, err
+		}
+		sql = rendered
 	}
 
-	extractor := NewExtractor(a.catalog)
-	return extractor.Extract(stmt)
+	// Statements of registered handlers are not parsed by the grammar
+	result, handled, err := a.handle(sql, catalog)
+	if body, ok := copyBody(sql); !handled && ok {
+		result, err = a.analyzeCopy(body, catalog)
+		handled = true
+	}
+	var stmt ast.Statement
+	if !handled {
+		// Parse SQL using ANTLR-generated parser, with its BigQuery
+		// constructs rewritten, retrying statements it rejects without the
+		// Spark SQL constructs the grammar lacks
+		stmt, err = parseStatement(sql)
+		if errors.Is(err, ErrUnsupportedSQL) {
+			if spark, ok := rewriteSpark(sql); ok {
+				stmt, err = parseStatement(spark)
+			}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		result, err = NewExtractor(catalog).Extract(stmt)
+		if err == nil {
+			dropTableSuffix(result)
+		}
+	}
+	if err != nil || len(comments) == 0 {
+		return result, stmt, err
+	}
+	result.Dataset = ParseDatasetAnnotations(comments)
+	if a.comments {
+		result.Comments = comments
+		result.Annotations = ParseAnnotations(comments)
+	}
+	return result, stmt, nil
 }
@@ -1,6 +1,9 @@
 package metadata
 
 import (
+	"sort"
+	"strings"
+
 	"go-metadata/internal/lineage"
 )
 
@@ -14,9 +17,19 @@ func NewCatalogAdapter(provider Provider) *CatalogAdapter {
 	return &CatalogAdapter{provider: provider}
 }
 
-// GetTableSchema implements lineage.Catalog interface.
+// GetTableSchema implements lineage.Catalog interface. A BigQuery wildcard
+// table such as events_* not described itself has the schema of the last
+// table of the database matching it, by name, which for date-suffixed
+// tables is the latest one, as in BigQuery.
 func (a *CatalogAdapter) GetTableSchema(db, table string) (*lineage.TableSchema, error) {
 	schema, err := a.provider.GetTableSchema(db, table)
+	if err != nil && strings.HasSuffix(table, "*") {
+		if match := a.wildcardMatch(db, strings.TrimSuffix(table, "*")); match != "" {
+			if schema, err = a.provider.GetTableSchema(db, match); err == nil {
+				return &lineage.TableSchema{Database: schema.Database, Table: table, Columns: schema.GetColumnNames()}, nil
+			}
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -29,7 +42,46 @@ func (a *CatalogAdapter) GetTableSchema(db, table string) (*lineage.TableSchema,
 	}, nil
 }
 
+// wildcardMatch returns the last table of db whose name starts with prefix,
+// or "" if none does.
+func (a *CatalogAdapter) wildcardMatch(db, prefix string) string {
+	tables, err := a.provider.ListTables(db)
+	if err != nil {
+		return ""
+	}
+	sort.Strings(tables)
+	for i := len(tables) - 1; i >= 0; i-- {
+		if strings.HasPrefix(strings.ToLower(tables[i]), strings.ToLower(prefix)) {
+			return tables[i]
+		}
+	}
+	return ""
+}
+
 // Provider returns the underlying metadata provider.
 func (a *CatalogAdapter) Provider() Provider {
 	return a.provider
 }
+
+// ForeignKeyJoins returns the column pairs of the foreign keys of a table.
+// Foreign keys that do not name the referenced columns are skipped, since
+// the referenced primary key may not be known.
+func ForeignKeyJoins(schema *TableSchema) []lineage.JoinKey {
+	var keys []lineage.JoinKey
+	for _, fk := range schema.ForeignKeys {
+		if len(fk.Columns) != len(fk.ReferencedColumns) {
+			continue
+		}
+		database := fk.ReferencedDatabase
+		if database == "" {
+			database = schema.Database
+		}
+		for i, col := range fk.Columns {
+			keys = append(keys, lineage.JoinKey{
+				Left:  lineage.ColumnRef{Database: schema.Database, Table: schema.Table, Column: col},
+				Right: lineage.ColumnRef{Database: database, Table: fk.ReferencedTable, Column: fk.ReferencedColumns[i]},
+			})
+		}
+	}
+	return keys
+}
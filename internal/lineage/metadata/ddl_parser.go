@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"go-metadata/internal/lineage"
 	"go-metadata/internal/lineage/parser"
 	"strings"
 
@@ -50,30 +51,13 @@ func (p *DDLParser) ParseDDL(sql string) (*TableSchema, error) {
 	return extractor.schema, nil
 }
 
-// ParseMultipleDDL parses multiple DDL statements and returns all extracted schemas.
+// ParseMultipleDDL parses multiple DDL statements and returns all extracted
+// schemas. The "@key value" annotations of the comments of a statement, such
+// as "-- @owner team-data", are applied to its table.
 func (p *DDLParser) ParseMultipleDDL(sql string) ([]*TableSchema, error) {
 	var schemas []*TableSchema
 
-	// Remove line comments first
-	lines := strings.Split(sql, "\n")
-	var cleanLines []string
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, "--") {
-			continue
-		}
-		cleanLines = append(cleanLines, line)
-	}
-	cleanSQL := strings.Join(cleanLines, "\n")
-
-	// Split by semicolon
-	statements := strings.Split(cleanSQL, ";")
-	for _, stmt := range statements {
-		stmt = strings.TrimSpace(stmt)
-		if stmt == "" {
-			continue
-		}
-
+	for _, stmt := range lineage.SplitStatements(sql) {
 		// Skip if statement is only whitespace or comments
 		if !containsDDLKeyword(stmt) {
 			continue
@@ -85,6 +69,7 @@ func (p *DDLParser) ParseMultipleDDL(sql string) ([]*TableSchema, error) {
 			continue
 		}
 		if schema != nil {
+			schema.ApplyAnnotations(lineage.ParseDatasetAnnotations(lineage.ExtractComments(stmt)))
 			schemas = append(schemas, schema)
 		}
 	}
@@ -203,6 +188,14 @@ func (e *ddlSchemaExtractor) EnterColumnDefinition(ctx *parser.ColumnDefinitionC
 				col.DefaultExpr = constraintCtx.Expression().GetText()
 			}
 		}
+		if constraintCtx.REFERENCES() != nil && constraintCtx.TableName() != nil {
+			fk := referencedTable(constraintCtx.TableName().(*parser.TableNameContext))
+			fk.Columns = []string{col.Name}
+			if constraintCtx.Identifier() != nil {
+				fk.ReferencedColumns = []string{getIdentifierText(constraintCtx.Identifier().GetText())}
+			}
+			e.schema.ForeignKeys = append(e.schema.ForeignKeys, fk)
+		}
 	}
 
 	// Extract column comment (defined at ColumnDefinition level)
@@ -213,6 +206,66 @@ func (e *ddlSchemaExtractor) EnterColumnDefinition(ctx *parser.ColumnDefinitionC
 	e.schema.Columns = append(e.schema.Columns, col)
 }
 
+// EnterTableConstraint is called when entering a table constraint; only
+// FOREIGN KEY constraints are extracted.
+func (e *ddlSchemaExtractor) EnterTableConstraint(ctx *parser.TableConstraintContext) {
+	if e.schema == nil || ctx.FOREIGN() == nil || ctx.TableName() == nil {
+		return
+	}
+
+	fk := referencedTable(ctx.TableName().(*parser.TableNameContext))
+	// Identifiers before REFERENCES are the referencing columns, the ones
+	// after it the referenced columns.
+	references := ctx.REFERENCES().GetSymbol().GetTokenIndex()
+	for _, id := range ctx.AllIdentifier() {
+		name := getIdentifierText(id.GetText())
+		if id.GetStart().GetTokenIndex() < references {
+			fk.Columns = append(fk.Columns, name)
+		} else {
+			fk.ReferencedColumns = append(fk.ReferencedColumns, name)
+		}
+	}
+	e.schema.ForeignKeys = append(e.schema.ForeignKeys, fk)
+}
+
+// referencedTable returns a foreign key referencing the given table.
+func referencedTable(ctx *parser.TableNameContext) ForeignKey {
+	var fk ForeignKey
+	if ctx.DatabaseName() != nil {
+		fk.ReferencedDatabase = getIdentifierText(ctx.DatabaseName().GetText())
+	}
+	if ctx.Identifier() != nil {
+		fk.ReferencedTable = getIdentifierText(ctx.Identifier().GetText())
+	}
+	return fk
+}
+
+// EnterProperty is called when entering a table property, e.g. a Flink
+// connector option in a WITH clause or a Hive TBLPROPERTIES entry.
+func (e *ddlSchemaExtractor) EnterProperty(ctx *parser.PropertyContext) {
+	if e.schema == nil {
+		return
+	}
+
+	literals := ctx.AllSTRING_LITERAL()
+	var key, value string
+	switch {
+	case ctx.Identifier() != nil && len(literals) == 1:
+		key = getIdentifierText(ctx.Identifier().GetText())
+		value = unquoteString(literals[0].GetText())
+	case len(literals) == 2:
+		key = unquoteString(literals[0].GetText())
+		value = unquoteString(literals[1].GetText())
+	default:
+		return
+	}
+
+	if e.schema.Properties == nil {
+		e.schema.Properties = make(map[string]string)
+	}
+	e.schema.Properties[key] = value
+}
+
 // EnterCreateViewStatement is called when entering a CREATE VIEW statement.
 func (e *ddlSchemaExtractor) EnterCreateViewStatement(ctx *parser.CreateViewStatementContext) {
 	e.schema = &TableSchema{
@@ -1,8 +1,15 @@
 package metadata
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/lineage"
+	"go-metadata/internal/store"
 )
 
 func TestMemoryProvider_AddTable(t *testing.T) {
@@ -184,6 +191,53 @@ func TestDDLParser_MultipleTables(t *testing.T) {
 	}
 }
 
+func TestDDLParser_ParseMultipleDDL_Annotations(t *testing.T) {
+	ddl := `
+		-- @owner team-data
+		-- @tag finance, daily
+		-- @pii email
+		-- @sla.tier gold
+		CREATE TABLE customers (
+			id INT PRIMARY KEY,
+			email VARCHAR(100)
+		) COMMENT 'Customers';
+
+		/* @description Orders loaded T+1
+		 * @deprecated Use orders_v2 */
+		CREATE TABLE orders (id INT, amount DECIMAL(10,2));
+
+		CREATE TABLE plain (id INT);
+	`
+
+	schemas, err := NewDDLParser().ParseMultipleDDL(ddl)
+	if err != nil {
+		t.Fatalf("ParseMultipleDDL failed: %v", err)
+	}
+	if len(schemas) != 3 {
+		t.Fatalf("Expected 3 schemas, got %d", len(schemas))
+	}
+
+	customers := schemas[0]
+	if strings.Join(customers.Owners, ",") != "team-data" || strings.Join(customers.Tags, ",") != "finance,daily" {
+		t.Errorf("Unexpected owners %v and tags %v", customers.Owners, customers.Tags)
+	}
+	if customers.Comment != "Customers" || customers.Annotations["sla.tier"] != "gold" {
+		t.Errorf("Expected the DDL comment to win and other keys to be annotations, got %q and %v", customers.Comment, customers.Annotations)
+	}
+	if email := customers.GetColumn("email"); email == nil || strings.Join(email.Tags, ",") != "pii" {
+		t.Errorf("Expected email to be tagged pii, got %+v", email)
+	}
+
+	orders := schemas[1]
+	if orders.Comment != "Orders loaded T+1" || orders.Annotations["deprecated"] != "Use orders_v2" {
+		t.Errorf("Unexpected comment %q and annotations %v", orders.Comment, orders.Annotations)
+	}
+
+	if plain := schemas[2]; plain.Owners != nil || plain.Annotations != nil {
+		t.Errorf("Expected no annotations on a table without comments, got %+v", plain)
+	}
+}
+
 func TestMetadataBuilder_Fluent(t *testing.T) {
 	ddl := `
 		CREATE TABLE products (
@@ -261,6 +315,85 @@ func TestCatalogAdapter(t *testing.T) {
 	}
 }
 
+func TestCatalogAdapter_WildcardTable(t *testing.T) {
+	provider := NewMemoryProvider()
+	_ = provider.AddTable("analytics", "events_20240101", []string{"user_id"})
+	_ = provider.AddTable("analytics", "events_20240102", []string{"user_id", "event_name"})
+	_ = provider.AddTable("analytics", "sessions", []string{"id"})
+
+	schema, err := NewCatalogAdapter(provider).GetTableSchema("analytics", "events_*")
+	if err != nil {
+		t.Fatalf("GetTableSchema failed: %v", err)
+	}
+	if schema.Table != "events_*" || len(schema.Columns) != 2 {
+		t.Errorf("schema = %+v, want the columns of events_20240102", schema)
+	}
+
+	if _, err := NewCatalogAdapter(provider).GetTableSchema("analytics", "logs_*"); err == nil {
+		t.Error("expected an error for a wildcard matching no table")
+	}
+}
+
+// harvested is an in-memory HarvestedTables.
+type harvested map[store.TableKey][]string
+
+func (h harvested) FindTables(_ context.Context, database, table string) ([]store.TableKey, error) {
+	var keys []store.TableKey
+	for key := range h {
+		if strings.EqualFold(key.Table, table) && (database == "" ||
+			strings.EqualFold(key.Schema, database) || strings.EqualFold(key.Catalog, database)) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (h harvested) ColumnNames(_ context.Context, key store.TableKey) ([]string, error) {
+	return h[key], nil
+}
+
+func TestStoreCatalog(t *testing.T) {
+	tables := harvested{
+		{Source: "mysql_prod", Catalog: "shop", Table: "orders"}:             {"id", "user_id", "amount"},
+		{Source: "pg_prod", Catalog: "dw", Schema: "public", Table: "users"}: {"id", "name"},
+		{Source: "pg_prod", Catalog: "dw", Schema: "stage", Table: "users"}:  {"id", "name", "email"},
+	}
+	catalog := NewStoreCatalog(tables)
+
+	schema, err := catalog.GetTableSchema("", "ORDERS")
+	if err != nil {
+		t.Fatalf("GetTableSchema failed: %v", err)
+	}
+	if schema.Database != "shop" || strings.Join(schema.Columns, ",") != "id,user_id,amount" {
+		t.Errorf("orders = %+v", schema)
+	}
+	if schema, err := catalog.GetTableSchema("stage", "users"); err != nil || len(schema.Columns) != 3 {
+		t.Errorf("stage.users = %+v, %v", schema, err)
+	}
+	if _, err := catalog.GetTableSchema("", "users"); !errors.Is(err, ErrTableNotFound) {
+		t.Errorf("users in two schemas with different columns: err = %v, want ErrTableNotFound", err)
+	}
+	if _, err := catalog.GetTableSchema("shop", "users"); !errors.Is(err, ErrTableNotFound) {
+		t.Errorf("shop.users: err = %v, want ErrTableNotFound", err)
+	}
+
+	// SELECT * of a synced table gets the lineage of each of its columns
+	result, err := lineage.NewAnalyzer(catalog).Analyze("INSERT INTO shop.orders_copy SELECT * FROM shop.orders")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	var targets []string
+	for _, col := range result.Columns {
+		targets = append(targets, col.Target.Column)
+		if len(col.Sources) != 1 || col.Sources[0].Column != col.Target.Column {
+			t.Errorf("%s <- %+v", col.Target.Column, col.Sources)
+		}
+	}
+	if got := strings.Join(targets, ","); got != "id,user_id,amount" {
+		t.Errorf("SELECT * columns = %s, want id,user_id,amount", got)
+	}
+}
+
 func TestDDLParser_CreateView(t *testing.T) {
 	ddl := `
 		CREATE VIEW user_orders AS
@@ -288,6 +421,42 @@ func TestDDLParser_CreateView(t *testing.T) {
 	}
 }
 
+func TestDDLParser_ForeignKeys(t *testing.T) {
+	ddl := `
+		CREATE TABLE order_items (
+			order_id INT REFERENCES orders(id),
+			sku VARCHAR(20),
+			warehouse VARCHAR(10),
+			CONSTRAINT fk_stock FOREIGN KEY (sku, warehouse) REFERENCES inv.stock (sku, warehouse_code)
+		)
+	`
+
+	schema, err := NewDDLParser().ParseDDL(ddl)
+	if err != nil {
+		t.Fatalf("ParseDDL failed: %v", err)
+	}
+	if len(schema.ForeignKeys) != 2 {
+		t.Fatalf("Expected 2 foreign keys, got %+v", schema.ForeignKeys)
+	}
+
+	fk := schema.ForeignKeys[1]
+	if fk.ReferencedDatabase != "inv" || fk.ReferencedTable != "stock" ||
+		strings.Join(fk.Columns, ",") != "sku,warehouse" || strings.Join(fk.ReferencedColumns, ",") != "sku,warehouse_code" {
+		t.Errorf("Unexpected table foreign key: %+v", fk)
+	}
+
+	keys := ForeignKeyJoins(schema)
+	if len(keys) != 3 {
+		t.Fatalf("Expected 3 join keys, got %+v", keys)
+	}
+	if keys[0].Left.QualifiedName() != "order_items.order_id" || keys[0].Right.QualifiedName() != "orders.id" {
+		t.Errorf("Unexpected column foreign key join: %+v", keys[0])
+	}
+	if keys[2].Right.QualifiedName() != "inv.stock.warehouse_code" {
+		t.Errorf("Unexpected table foreign key join: %+v", keys[2])
+	}
+}
+
 func TestDDLParser_ExternalTable(t *testing.T) {
 	ddl := `
 		CREATE EXTERNAL TABLE logs (
@@ -417,3 +586,23 @@ func TestDDLParser_SparkHiveTable(t *testing.T) {
 		t.Errorf("Expected table type 'EXTERNAL', got '%s'", schema.TableType)
 	}
 }
+
+func TestScanStats(t *testing.T) {
+	if ScanStats(&collector.TableMetadata{Name: "orders"}) != nil {
+		t.Error("Expected no statistics for a table without stats")
+	}
+
+	distinct := int64(30)
+	stats := ScanStats(&collector.TableMetadata{
+		Name:       "orders",
+		Partitions: []collector.PartitionInfo{{Columns: []string{"dt"}, ValuesCount: 90}},
+		Stats: &collector.TableStatistics{
+			RowCount:      9000,
+			DataSizeBytes: 90000,
+			ColumnStats:   []collector.ColumnStats{{Name: "DT", DistinctCount: &distinct}, {Name: "amount"}},
+		},
+	})
+	if stats.RowCount != 9000 || stats.PartitionCount != 90 || len(stats.PartitionColumns) != 1 || stats.DistinctCounts["dt"] != 30 || len(stats.DistinctCounts) != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
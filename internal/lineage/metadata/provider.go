@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 )
 
@@ -168,3 +169,20 @@ func (p *MemoryProvider) ExportToJSON() ([]byte, error) {
 func (p *MemoryProvider) Clear() {
 	p.databases = make(map[string]*DatabaseSchema)
 }
+
+// AllTables returns all table schemas sorted by database and table name.
+func (p *MemoryProvider) AllTables() []*TableSchema {
+	var schemas []*TableSchema
+	for _, db := range p.databases {
+		for _, table := range db.Tables {
+			schemas = append(schemas, table)
+		}
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		if schemas[i].Database != schemas[j].Database {
+			return schemas[i].Database < schemas[j].Database
+		}
+		return schemas[i].Table < schemas[j].Table
+	})
+	return schemas
+}
@@ -1,6 +1,14 @@
 // Package metadata provides table schema metadata for SQL lineage analysis.
 package metadata
 
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"go-metadata/internal/lineage"
+)
+
 // ColumnSchema represents the schema of a database column.
 type ColumnSchema struct {
 	Name        string `json:"name"`
@@ -9,6 +17,11 @@ type ColumnSchema struct {
 	PrimaryKey  bool   `json:"primary_key"`
 	Comment     string `json:"comment,omitempty"`
 	DefaultExpr string `json:"default_expr,omitempty"`
+	// Annotations holds user-defined namespaced key/values such as
+	// dq.check=enabled.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Tags are set with "-- @pii" and "-- @column" comment annotations.
+	Tags []string `json:"tags,omitempty"`
 }
 
 // TableSchema represents the schema of a database table.
@@ -20,6 +33,71 @@ type TableSchema struct {
 	PrimaryKey []string       `json:"primary_key,omitempty"`
 	Comment    string         `json:"comment,omitempty"`
 	TableType  string         `json:"table_type,omitempty"` // "TABLE", "VIEW", "EXTERNAL"
+	// Properties holds table options such as Flink connector options
+	// ('connector' = 'kafka', 'topic' = ...) or Hive TBLPROPERTIES.
+	Properties map[string]string `json:"properties,omitempty"`
+	// Annotations holds user-defined namespaced key/values such as
+	// finance.coa=4010. Unlike Properties they are not part of the DDL.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	ForeignKeys []ForeignKey      `json:"foreign_keys,omitempty"`
+	// Owners and Tags are set with "-- @owner" and "-- @tag" comment
+	// annotations of the DDL.
+	Owners []string `json:"owners,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// ForeignKey represents a foreign key constraint. Columns and
+// ReferencedColumns are paired by position.
+type ForeignKey struct {
+	Columns            []string `json:"columns"`
+	ReferencedDatabase string   `json:"referenced_database,omitempty"`
+	ReferencedTable    string   `json:"referenced_table"`
+	ReferencedColumns  []string `json:"referenced_columns,omitempty"`
+}
+
+// ApplyAnnotations applies the comment annotations of the DDL of the table.
+// A description is the table comment if the DDL has none; a deprecation and
+// other keys are set as annotations. Tags of unknown columns are ignored.
+func (t *TableSchema) ApplyAnnotations(a *lineage.DatasetAnnotations) {
+	if a == nil {
+		return
+	}
+	t.Owners = appendNew(t.Owners, a.Owners)
+	t.Tags = appendNew(t.Tags, a.Tags)
+	if t.Comment == "" {
+		t.Comment = a.Description
+	}
+	annotations := a.Annotations
+	if a.Deprecated {
+		annotations = maps.Clone(annotations)
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[lineage.AnnotationDeprecated] = a.DeprecationNote
+	}
+	for key, value := range annotations {
+		if t.Annotations == nil {
+			t.Annotations = make(map[string]string)
+		}
+		t.Annotations[key] = value
+	}
+	for name, tags := range a.ColumnTags {
+		for i := range t.Columns {
+			if strings.EqualFold(t.Columns[i].Name, name) {
+				t.Columns[i].Tags = appendNew(t.Columns[i].Tags, tags)
+			}
+		}
+	}
+}
+
+// appendNew appends the values not already in list.
+func appendNew(list, values []string) []string {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
 }
 
 // GetColumnNames returns the list of column names.
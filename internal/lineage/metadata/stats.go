@@ -0,0 +1,39 @@
+package metadata
+
+import (
+	"strings"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/lineage"
+)
+
+// ScanStats returns the statistics of a collected table that scan cost
+// estimates are based on, or nil if none were collected. The partition
+// columns are those of the first partitioning of the table; the values of
+// individual partitions are not collected.
+func ScanStats(table *collector.TableMetadata) *lineage.ScanStats {
+	if table == nil || table.Stats == nil {
+		return nil
+	}
+	stats := &lineage.ScanStats{
+		RowCount:       table.Stats.RowCount,
+		DataSizeBytes:  table.Stats.DataSizeBytes,
+		PartitionCount: table.Stats.PartitionCount,
+	}
+	if len(table.Partitions) > 0 {
+		stats.PartitionColumns = table.Partitions[0].Columns
+		if stats.PartitionCount == 0 {
+			stats.PartitionCount = table.Partitions[0].ValuesCount
+		}
+	}
+	for _, col := range table.Stats.ColumnStats {
+		if col.DistinctCount == nil {
+			continue
+		}
+		if stats.DistinctCounts == nil {
+			stats.DistinctCounts = make(map[string]int64)
+		}
+		stats.DistinctCounts[strings.ToLower(col.Name)] = *col.DistinctCount
+	}
+	return stats
+}
@@ -0,0 +1,93 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go-metadata/internal/lineage"
+	"go-metadata/internal/store"
+)
+
+// HarvestedTables looks up the tables harvested by the collectors, as the
+// metadata store does.
+type HarvestedTables interface {
+	FindTables(ctx context.Context, database, table string) ([]store.TableKey, error)
+	ColumnNames(ctx context.Context, key store.TableKey) ([]string, error)
+}
+
+// storeLookupTimeout bounds a lookup of the schema of a table.
+const storeLookupTimeout = 10 * time.Second
+
+// StoreCatalog resolves tables against the metadata harvested into the store,
+// so that SELECT * and unqualified columns of queries on synced tables are
+// resolved to their columns.
+type StoreCatalog struct {
+	tables HarvestedTables
+}
+
+// NewStoreCatalog creates a catalog of the tables harvested into tables.
+func NewStoreCatalog(tables HarvestedTables) *StoreCatalog {
+	return &StoreCatalog{tables: tables}
+}
+
+// GetTableSchema implements lineage.Catalog interface. The database of a
+// query names the schema of a table, or its catalog for sources without
+// schemas such as MySQL. A table found in several schemas or sources is only
+// resolved if they agree on its columns.
+func (c *StoreCatalog) GetTableSchema(db, table string) (*lineage.TableSchema, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeLookupTimeout)
+	defer cancel()
+
+	// catalog.schema names the schema
+	if i := strings.LastIndex(db, "."); i >= 0 {
+		db = db[i+1:]
+	}
+	keys, err := c.tables.FindTables(ctx, db, table)
+	if err != nil {
+		return nil, err
+	}
+	if db != "" {
+		// A schema of the name takes precedence over a catalog of the name
+		var inSchema []store.TableKey
+		for _, key := range keys {
+			if strings.EqualFold(key.Schema, db) {
+				inSchema = append(inSchema, key)
+			}
+		}
+		if len(inSchema) > 0 {
+			keys = inSchema
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: %s.%s", ErrTableNotFound, db, table)
+	}
+
+	var columns []string
+	for i, key := range keys {
+		cols, err := c.tables.ColumnNames(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			columns = cols
+		} else if !slices.EqualFunc(columns, cols, strings.EqualFold) {
+			return nil, fmt.Errorf("%w: %s is ambiguous, found in %s and %s", ErrTableNotFound, table, keys[0], key)
+		}
+	}
+
+	database := db
+	if database == "" {
+		database = keys[0].Schema
+		if database == "" {
+			database = keys[0].Catalog
+		}
+	}
+	return &lineage.TableSchema{
+		Database: database,
+		Table:    keys[0].Table,
+		Columns:  columns,
+	}, nil
+}
@@ -0,0 +1,121 @@
+package openlineage
+
+import (
+	"regexp"
+	"strings"
+
+	"go-metadata/internal/lineage"
+)
+
+// Transformation types and subtypes of the columnLineage facet.
+const (
+	TransformationDirect   = "DIRECT"
+	TransformationIndirect = "INDIRECT"
+
+	SubtypeIdentity       = "IDENTITY"
+	SubtypeTransformation = "TRANSFORMATION"
+	SubtypeAggregation    = "AGGREGATION"
+)
+
+// ColumnLineageFacet describes, for each field of an output dataset, the
+// input fields it is derived from.
+type ColumnLineageFacet struct {
+	BaseFacet
+	Fields map[string]ColumnLineageField `json:"fields"`
+}
+
+// ColumnLineageField lists the input fields of an output field.
+type ColumnLineageField struct {
+	InputFields []InputField `json:"inputFields"`
+	// TransformationDescription and TransformationType are the deprecated
+	// field-level descriptions, still read by older Marquez versions.
+	TransformationDescription string `json:"transformationDescription,omitempty"`
+	TransformationType        string `json:"transformationType,omitempty"`
+}
+
+// InputField is an input field of a column lineage entry.
+type InputField struct {
+	Namespace       string           `json:"namespace"`
+	Name            string           `json:"name"`
+	Field           string           `json:"field"`
+	Transformations []Transformation `json:"transformations,omitempty"`
+}
+
+// Transformation describes how an input field is turned into the output field.
+type Transformation struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype,omitempty"`
+	Description string `json:"description,omitempty"`
+	Masking     bool   `json:"masking"`
+}
+
+// columnLineageFacet builds the columnLineage facet of the output dataset
+// identified by key (namespace/name), or nil if none of its fields has sources.
+func columnLineageFacet(result *lineage.LineageResult, key string, namer DatasetNamer) *ColumnLineageFacet {
+	facet := &ColumnLineageFacet{
+		BaseFacet: BaseFacet{Producer: Producer, SchemaURL: columnLineageFacetURL},
+		Fields:    make(map[string]ColumnLineageField),
+	}
+
+	for _, col := range result.Columns {
+		namespace, name := namer(col.Target)
+		if namespace+"/"+name != key || col.Target.Column == "" || len(col.Sources) == 0 {
+			continue
+		}
+
+		t := classify(col)
+		field := facet.Fields[col.Target.Column]
+		for _, src := range col.Sources {
+			if src.Table == "" || src.Column == "" {
+				continue
+			}
+			srcNamespace, srcName := namer(src)
+			field.InputFields = append(field.InputFields, InputField{
+				Namespace:       srcNamespace,
+				Name:            srcName,
+				Field:           src.Column,
+				Transformations: []Transformation{t},
+			})
+		}
+		if len(field.InputFields) == 0 {
+			continue
+		}
+		field.TransformationDescription = t.Description
+		field.TransformationType = "IDENTITY"
+		if t.Masking {
+			field.TransformationType = "MASKED"
+		}
+		facet.Fields[col.Target.Column] = field
+	}
+
+	if len(facet.Fields) == 0 {
+		return nil
+	}
+	return facet
+}
+
+var (
+	plainColumn = regexp.MustCompile("^[`\"\\w]+(\\.[`\"\\w]+)*$")
+	aggregate   = regexp.MustCompile(`(?i)\b(SUM|COUNT|AVG|MIN|MAX|COLLECT_LIST|COLLECT_SET|ARRAY_AGG|STRING_AGG|GROUP_CONCAT|LISTAGG|APPROX_COUNT_DISTINCT|STDDEV|VARIANCE|PERCENTILE\w*)\s*\(`)
+	masking     = regexp.MustCompile(`(?i)\b(MD5|SHA1?|SHA2|SHA256|HASH|MASK\w*|ENCRYPT|AES_ENCRYPT)\s*\(`)
+)
+
+// classify derives the transformation of a column from the operators
+// recorded by the analyzer: a bare column reference is an identity, an
+// aggregate function call an aggregation, anything else a transformation.
+func classify(col lineage.ColumnLineage) Transformation {
+	t := Transformation{
+		Type:        TransformationDirect,
+		Subtype:     SubtypeTransformation,
+		Description: strings.Join(col.Operators, "; "),
+	}
+
+	switch {
+	case len(col.Operators) == 0 || (len(col.Operators) == 1 && plainColumn.MatchString(col.Operators[0])):
+		t.Subtype = SubtypeIdentity
+	case aggregate.MatchString(t.Description):
+		t.Subtype = SubtypeAggregation
+	}
+	t.Masking = masking.MatchString(t.Description)
+	return t
+}
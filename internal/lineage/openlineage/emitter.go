@@ -0,0 +1,90 @@
+package openlineage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint is the lineage endpoint of Marquez and most OpenLineage
+// backends.
+const DefaultEndpoint = "api/v1/lineage"
+
+// Config configures an Emitter.
+type Config struct {
+	// URL is the base URL of the OpenLineage backend, e.g. http://marquez:5000.
+	URL string `yaml:"url"`
+	// Endpoint is the path events are posted to; defaults to DefaultEndpoint.
+	Endpoint string `yaml:"endpoint"`
+	// APIKey is sent as a bearer token if set.
+	APIKey string `yaml:"api_key"`
+	// Timeout bounds each request; defaults to 10s.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Emitter posts run events to an OpenLineage backend over HTTP.
+type Emitter struct {
+	endpoint   string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewEmitter creates an emitter for the backend described by cfg.
+func NewEmitter(cfg *Config) (*Emitter, error) {
+	if cfg == nil || cfg.URL == "" {
+		return nil, fmt.Errorf("openlineage url is required")
+	}
+	if _, err := url.Parse(cfg.URL); err != nil {
+		return nil, fmt.Errorf("invalid openlineage url: %w", err)
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &Emitter{
+		endpoint:   strings.TrimSuffix(cfg.URL, "/") + "/" + strings.TrimPrefix(endpoint, "/"),
+		apiKey:     cfg.APIKey,
+		httpClient: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Emit posts a run event.
+func (e *Emitter) Emit(ctx context.Context, event *RunEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal run event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("emit run event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("emit run event: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+// Package openlineage converts lineage analysis results into OpenLineage run
+// events and emits them to an OpenLineage-compatible backend such as Marquez
+// or Atlan.
+package openlineage
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"go-metadata/internal/lineage"
+	"go-metadata/internal/urn"
+)
+
+const (
+	// Producer identifies this project as the producer of emitted events.
+	Producer = "https://github.com/kannon007/go-metadata"
+
+	schemaURL = "https://openlineage.io/spec/2-0-2/OpenLineage.json#/definitions/RunEvent"
+
+	columnLineageFacetURL = "https://openlineage.io/spec/facets/1-2-0/ColumnLineageDatasetFacet.json#/$defs/ColumnLineageDatasetFacet"
+	schemaFacetURL        = "https://openlineage.io/spec/facets/1-1-1/SchemaDatasetFacet.json#/$defs/SchemaDatasetFacet"
+	sqlFacetURL           = "https://openlineage.io/spec/facets/1-1-0/SQLJobFacet.json#/$defs/SQLJobFacet"
+)
+
+// EventType is the type of a run event.
+type EventType string
+
+const (
+	EventTypeStart    EventType = "START"
+	EventTypeRunning  EventType = "RUNNING"
+	EventTypeComplete EventType = "COMPLETE"
+	EventTypeAbort    EventType = "ABORT"
+	EventTypeFail     EventType = "FAIL"
+	EventTypeOther    EventType = "OTHER"
+)
+
+// RunEvent is an OpenLineage run event.
+type RunEvent struct {
+	EventType EventType `json:"eventType"`
+	EventTime time.Time `json:"eventTime"`
+	Run       Run       `json:"run"`
+	Job       Job       `json:"job"`
+	Inputs    []Dataset `json:"inputs"`
+	Outputs   []Dataset `json:"outputs"`
+	Producer  string    `json:"producer"`
+	SchemaURL string    `json:"schemaURL"`
+}
+
+// Run identifies a run of a job.
+type Run struct {
+	RunID  string         `json:"runId"`
+	Facets map[string]any `json:"facets,omitempty"`
+}
+
+// Job identifies the job that ran.
+type Job struct {
+	Namespace string         `json:"namespace"`
+	Name      string         `json:"name"`
+	Facets    map[string]any `json:"facets,omitempty"`
+}
+
+// Dataset is an input or output dataset of a run.
+type Dataset struct {
+	Namespace string         `json:"namespace"`
+	Name      string         `json:"name"`
+	Facets    map[string]any `json:"facets,omitempty"`
+}
+
+// BaseFacet holds the fields common to all facets.
+type BaseFacet struct {
+	Producer  string `json:"_producer"`
+	SchemaURL string `json:"_schemaURL"`
+}
+
+// SQLJobFacet records the SQL statement executed by a job.
+type SQLJobFacet struct {
+	BaseFacet
+	Query string `json:"query"`
+}
+
+// SchemaDatasetFacet lists the fields of a dataset.
+type SchemaDatasetFacet struct {
+	BaseFacet
+	Fields []SchemaField `json:"fields"`
+}
+
+// SchemaField is a field of a dataset schema.
+type SchemaField struct {
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+// DatasetNamer returns the OpenLineage namespace and name of the dataset a
+// table reference belongs to.
+type DatasetNamer func(ref lineage.ColumnRef) (namespace, name string)
+
+// StaticNamespace returns a DatasetNamer placing every dataset in the given
+// namespace (e.g. "mysql://db.internal:3306") and naming it db.table.
+func StaticNamespace(namespace string) DatasetNamer {
+	return func(ref lineage.ColumnRef) (string, string) {
+		return namespace, ref.TableName()
+	}
+}
+
+// URNNamespace returns a DatasetNamer naming the datasets of source with the
+// OpenLineage namespace and name of the URN scheme of n, so that events line
+// up with the identifiers of other exports, e.g. DataHub or Marquez datasets.
+func URNNamespace(n *urn.Namer, source string) DatasetNamer {
+	return func(ref lineage.ColumnRef) (string, string) {
+		return n.OpenLineage(n.Resolve(source, ref.TableName()))
+	}
+}
+
+// EventOptions configures how a lineage result is converted into a run event.
+type EventOptions struct {
+	// JobNamespace and JobName identify the job; JobName defaults to the
+	// statement fingerprint.
+	JobNamespace string
+	JobName      string
+	// RunID identifies the run; a random UUID is used if empty.
+	RunID string
+	// SQL is the analyzed statement, attached as the job's sql facet.
+	SQL string
+	// Datasets names the datasets; defaults to StaticNamespace(JobNamespace).
+	Datasets DatasetNamer
+	// EventTime defaults to now.
+	EventTime time.Time
+}
+
+// NewRunEvent builds a run event from a lineage result. Source tables become
+// inputs and target tables outputs; each output carries a schema facet and a
+// columnLineage facet describing where each of its fields comes from.
+func NewRunEvent(eventType EventType, result *lineage.LineageResult, opts EventOptions) *RunEvent {
+	if opts.Datasets == nil {
+		opts.Datasets = StaticNamespace(opts.JobNamespace)
+	}
+	if opts.RunID == "" {
+		opts.RunID = uuid.NewString()
+	}
+	if opts.EventTime.IsZero() {
+		opts.EventTime = time.Now().UTC()
+	}
+	if opts.JobName == "" && opts.SQL != "" {
+		opts.JobName = lineage.Fingerprint(opts.SQL)
+	}
+
+	event := &RunEvent{
+		EventType: eventType,
+		EventTime: opts.EventTime,
+		Run:       Run{RunID: opts.RunID},
+		Job:       Job{Namespace: opts.JobNamespace, Name: opts.JobName},
+		Inputs:    make([]Dataset, 0),
+		Outputs:   make([]Dataset, 0),
+		Producer:  Producer,
+		SchemaURL: schemaURL,
+	}
+	if opts.SQL != "" {
+		event.Job.Facets = map[string]any{
+			"sql": SQLJobFacet{BaseFacet: facet(sqlFacetURL), Query: opts.SQL},
+		}
+	}
+	if result == nil {
+		return event
+	}
+
+	inputs := newDatasetSet(opts.Datasets)
+	outputs := newDatasetSet(opts.Datasets)
+	for _, col := range result.Columns {
+		for _, src := range col.Sources {
+			if src.Table != "" {
+				inputs.addField(src)
+			}
+		}
+		if col.Target.Table != "" {
+			outputs.addField(col.Target)
+		}
+	}
+	event.Inputs = inputs.datasets(nil)
+	event.Outputs = outputs.datasets(func(ds *Dataset, key string) {
+		if facet := columnLineageFacet(result, key, opts.Datasets); facet != nil {
+			ds.Facets["columnLineage"] = facet
+		}
+	})
+	return event
+}
+
+func facet(schemaURL string) BaseFacet {
+	return BaseFacet{Producer: Producer, SchemaURL: schemaURL}
+}
+
+// datasetSet collects datasets and their fields in first-seen order.
+type datasetSet struct {
+	namer  DatasetNamer
+	order  []string
+	items  map[string]*Dataset
+	fields map[string][]string
+}
+
+func newDatasetSet(namer DatasetNamer) *datasetSet {
+	return &datasetSet{namer: namer, items: make(map[string]*Dataset), fields: make(map[string][]string)}
+}
+
+func (s *datasetSet) addField(ref lineage.ColumnRef) {
+	namespace, name := s.namer(ref)
+	key := namespace + "/" + name
+	if _, ok := s.items[key]; !ok {
+		s.items[key] = &Dataset{Namespace: namespace, Name: name, Facets: make(map[string]any)}
+		s.order = append(s.order, key)
+	}
+	if ref.Column != "" && ref.Column != "*" && !containsString(s.fields[key], ref.Column) {
+		s.fields[key] = append(s.fields[key], ref.Column)
+	}
+}
+
+func (s *datasetSet) datasets(decorate func(ds *Dataset, key string)) []Dataset {
+	result := make([]Dataset, 0, len(s.order))
+	for _, key := range s.order {
+		ds := s.items[key]
+		if fields := s.fields[key]; len(fields) > 0 {
+			schema := SchemaDatasetFacet{BaseFacet: facet(schemaFacetURL)}
+			for _, f := range fields {
+				schema.Fields = append(schema.Fields, SchemaField{Name: f})
+			}
+			ds.Facets["schema"] = schema
+		}
+		if decorate != nil {
+			decorate(ds, key)
+		}
+		if len(ds.Facets) == 0 {
+			ds.Facets = nil
+		}
+		result = append(result, *ds)
+	}
+	return result
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
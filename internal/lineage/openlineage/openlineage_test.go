@@ -0,0 +1,141 @@
+package openlineage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/metadata"
+	"go-metadata/internal/urn"
+)
+
+func analyze(t *testing.T, sql string) *lineage.LineageResult {
+	t.Helper()
+	catalog := metadata.NewMetadataBuilder().
+		AddTable("", "orders", []string{"id", "user_id", "amount", "email"}).
+		BuildCatalog()
+	result, err := lineage.NewAnalyzer(catalog).Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	return result
+}
+
+func TestNewRunEvent_ColumnLineageFacet(t *testing.T) {
+	sql := `INSERT INTO user_totals(user_id, total, email_hash)
+		SELECT user_id, SUM(amount), MD5(email) FROM orders GROUP BY user_id, email`
+	event := NewRunEvent(EventTypeComplete, analyze(t, sql), EventOptions{
+		JobNamespace: "etl",
+		SQL:          sql,
+		Datasets:     StaticNamespace("mysql://db:3306"),
+	})
+
+	if len(event.Inputs) != 1 || event.Inputs[0].Name != "orders" {
+		t.Fatalf("Unexpected inputs %+v", event.Inputs)
+	}
+	if len(event.Outputs) != 1 || event.Outputs[0].Name != "user_totals" {
+		t.Fatalf("Unexpected outputs %+v", event.Outputs)
+	}
+	if event.Job.Name != lineage.Fingerprint(sql) {
+		t.Errorf("Expected job name to default to the fingerprint, got %s", event.Job.Name)
+	}
+
+	facet, ok := event.Outputs[0].Facets["columnLineage"].(*ColumnLineageFacet)
+	if !ok {
+		t.Fatalf("Expected columnLineage facet, got %v", event.Outputs[0].Facets)
+	}
+
+	tests := []struct {
+		field   string
+		source  string
+		subtype string
+		masking bool
+	}{
+		{"user_id", "user_id", SubtypeIdentity, false},
+		{"total", "amount", SubtypeAggregation, false},
+		{"email_hash", "email", SubtypeTransformation, true},
+	}
+	for _, tt := range tests {
+		field, ok := facet.Fields[tt.field]
+		if !ok || len(field.InputFields) != 1 {
+			t.Errorf("%s: unexpected field %+v", tt.field, field)
+			continue
+		}
+		input := field.InputFields[0]
+		if input.Namespace != "mysql://db:3306" || input.Name != "orders" || input.Field != tt.source {
+			t.Errorf("%s: unexpected input field %+v", tt.field, input)
+		}
+		tr := input.Transformations[0]
+		if tr.Type != TransformationDirect || tr.Subtype != tt.subtype || tr.Masking != tt.masking {
+			t.Errorf("%s: unexpected transformation %+v", tt.field, tr)
+		}
+		if tr.Description == "" {
+			t.Errorf("%s: expected a transformation description", tt.field)
+		}
+	}
+}
+
+func TestNewRunEvent_URNNamespace(t *testing.T) {
+	namer, err := urn.New(&urn.Config{Scheme: urn.SchemeOpenLineage, Platform: "mysql"})
+	if err != nil {
+		t.Fatalf("urn.New failed: %v", err)
+	}
+	event := NewRunEvent(EventTypeComplete, analyze(t, "INSERT INTO totals(total) SELECT SUM(amount) FROM orders"),
+		EventOptions{JobNamespace: "etl", JobName: "totals", Datasets: URNNamespace(namer, "mysql-prod")})
+
+	if len(event.Inputs) != 1 || event.Inputs[0].Namespace != "mysql://mysql-prod" || event.Inputs[0].Name != "orders" {
+		t.Fatalf("Unexpected inputs %+v", event.Inputs)
+	}
+	facet := event.Outputs[0].Facets["columnLineage"].(*ColumnLineageFacet)
+	if input := facet.Fields["total"].InputFields[0]; input.Namespace != "mysql://mysql-prod" {
+		t.Errorf("Unexpected input field %+v", input)
+	}
+}
+
+func TestEmitter_Emit(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/lineage" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	emitter, err := NewEmitter(&Config{URL: server.URL, APIKey: "secret"})
+	if err != nil {
+		t.Fatalf("NewEmitter failed: %v", err)
+	}
+
+	event := NewRunEvent(EventTypeComplete, analyze(t, "INSERT INTO totals(total) SELECT SUM(amount) FROM orders"),
+		EventOptions{JobNamespace: "etl", JobName: "totals"})
+	if err := emitter.Emit(context.Background(), event); err != nil {
+		t.Fatalf("Emit failed: %v", err)
+	}
+
+	outputs, _ := received["outputs"].([]any)
+	if len(outputs) != 1 {
+		t.Fatalf("Expected 1 output, got %v", received["outputs"])
+	}
+	facets := outputs[0].(map[string]any)["facets"].(map[string]any)
+	if _, ok := facets["columnLineage"]; !ok {
+		t.Errorf("Expected columnLineage facet in emitted event, got %v", facets)
+	}
+}
+
+func TestEmitter_EmitError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad event", http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	emitter, _ := NewEmitter(&Config{URL: server.URL})
+	if err := emitter.Emit(context.Background(), NewRunEvent(EventTypeStart, nil, EventOptions{JobName: "j"})); err == nil {
+		t.Error("Expected error for non-2xx response")
+	}
+}
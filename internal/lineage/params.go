@@ -0,0 +1,92 @@
+package lineage
+
+import "strings"
+
+// ReplacePositionalParams replaces the PostgreSQL positional parameters of
+// sql, $1, $2 and so on, with ? markers, which the grammar parses. Query logs
+// such as pg_stat_statements record statements with their constants replaced
+// by such parameters. String literals, quoted identifiers, dollar-quoted
+// strings and comments are left as they are.
+func ReplacePositionalParams(sql string) string {
+	if !strings.Contains(sql, "$") {
+		return sql
+	}
+	var b strings.Builder
+	b.Grow(len(sql))
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(sql) {
+				if sql[end] == c {
+					if end+1 < len(sql) && sql[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			end = min(end+1, len(sql))
+			b.WriteString(sql[i:end])
+			i = end
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			b.WriteString(sql[i : i+end])
+			i += end
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql) - i
+			} else {
+				end += 4
+			}
+			b.WriteString(sql[i : i+end])
+			i += end
+		case c == '$' && i+1 < len(sql) && isDigit(sql[i+1]) && !precededByIdentifier(sql, i):
+			i++
+			for i < len(sql) && isDigit(sql[i]) {
+				i++
+			}
+			b.WriteByte('?')
+		case c == '$':
+			// A dollar-quoted string, $$...$$ or $tag$...$tag$
+			tag := dollarTag(sql[i:])
+			if tag == "" {
+				b.WriteByte(c)
+				i++
+				continue
+			}
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				end = len(sql) - i
+			} else {
+				end += 2 * len(tag)
+			}
+			b.WriteString(sql[i : i+end])
+			i += end
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// precededByIdentifier reports whether the character before s[i] belongs to
+// an identifier, in which $ is part of the name, e.g. sys$1.
+func precededByIdentifier(s string, i int) bool {
+	if i == 0 {
+		return false
+	}
+	c := s[i-1]
+	return c == '_' || c == '$' || isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
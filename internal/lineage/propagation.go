@@ -0,0 +1,410 @@
+package lineage
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PropagateNone disables the propagation of a tag.
+const PropagateNone Direction = "none"
+
+// TagRule configures how a tag propagates along lineage edges.
+type TagRule struct {
+	// Tag is the tag the rule applies to.
+	Tag string `json:"tag" yaml:"tag"`
+	// Direction is Downstream, Upstream or PropagateNone.
+	Direction Direction `json:"direction" yaml:"direction"`
+	// MaxHops stops the propagation that many edges away from the tagged
+	// node; 0 means unlimited.
+	MaxHops int `json:"max_hops,omitempty" yaml:"max_hops"`
+	// Exclude are glob patterns (see path.Match) of database.table names,
+	// compared case-insensitively, whose tags are not propagated and which
+	// the tag does not propagate to or through.
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude"`
+}
+
+// TagRules are the tag propagation rules, usually kept in a YAML file:
+//
+//	default:
+//	  direction: none
+//	rules:
+//	  - tag: pii
+//	    direction: downstream
+//	  - tag: finance
+//	    direction: downstream
+//	    max_hops: 2
+//	    exclude: ["sandbox.*"]
+//	  - tag: source-of-truth
+//	    direction: upstream
+//	    max_hops: 1
+//
+// Tags without a rule follow Default; without a default they do not
+// propagate.
+type TagRules struct {
+	Default *TagRule   `json:"default,omitempty" yaml:"default"`
+	Rules   []*TagRule `json:"rules" yaml:"rules"`
+}
+
+// ParseTagRules reads tag propagation rules in YAML, or JSON.
+func ParseTagRules(r io.Reader) (*TagRules, error) {
+	var rules TagRules
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&rules); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid tag rules: %w", err)
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// Validate checks that the rules are well-formed and configure each tag at
+// most once.
+func (r *TagRules) Validate() error {
+	if r.Default != nil {
+		if err := r.Default.validate(); err != nil {
+			return fmt.Errorf("invalid default tag rule: %w", err)
+		}
+	}
+	seen := make(map[string]bool, len(r.Rules))
+	for i, rule := range r.Rules {
+		if rule == nil || rule.Tag == "" {
+			return fmt.Errorf("invalid tag rule %d: tag is required", i+1)
+		}
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("invalid tag rule %s: %w", rule.Tag, err)
+		}
+		if seen[rule.Tag] {
+			return fmt.Errorf("invalid tag rule %s: tag is configured more than once", rule.Tag)
+		}
+		seen[rule.Tag] = true
+	}
+	return nil
+}
+
+func (r *TagRule) validate() error {
+	switch r.Direction {
+	case Downstream, Upstream, PropagateNone:
+	default:
+		return fmt.Errorf("direction must be %s, %s or %s, got %q", Downstream, Upstream, PropagateNone, r.Direction)
+	}
+	if r.MaxHops < 0 {
+		return fmt.Errorf("max_hops must not be negative")
+	}
+	for _, pattern := range r.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("exclude pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Rule returns the rule of tag, or nil if the tag does not propagate.
+func (r *TagRules) Rule(tag string) *TagRule {
+	if r == nil {
+		return nil
+	}
+	rule := r.Default
+	for _, candidate := range r.Rules {
+		if candidate.Tag == tag {
+			rule = candidate
+			break
+		}
+	}
+	if rule == nil || rule.Direction == PropagateNone {
+		return nil
+	}
+	return rule
+}
+
+// excludes reports whether the rule excludes the table of node.
+func (r *TagRule) excludes(table string) bool {
+	table = strings.ToLower(table)
+	for _, pattern := range r.Exclude {
+		if ok, _ := path.Match(strings.ToLower(pattern), table); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// PropagatedTag is a tag a node inherits through lineage.
+type PropagatedTag struct {
+	Tag string `json:"tag"`
+	// From is the nearest node carrying the tag itself.
+	From string `json:"from"`
+	Hops int    `json:"hops"`
+}
+
+// TagChange is a tag a node gained, or lost if Removed, through propagation.
+type TagChange struct {
+	Node    string `json:"node"`
+	Tag     string `json:"tag"`
+	From    string `json:"from,omitempty"`
+	Removed bool   `json:"removed,omitempty"`
+}
+
+// TagPropagator propagates the tags of tables and columns along the current
+// edges of a lineage graph as its rules configure. Tags of columns
+// (database.table.column) follow column-level edges and tags of tables
+// (database.table) table-level dependencies.
+//
+// Evaluation is incremental: changing the tags of a node re-evaluates only
+// the tags that changed, and a lineage change only the tags that propagate.
+// TagPropagator is safe for concurrent use.
+type TagPropagator struct {
+	mu    sync.Mutex
+	graph *Graph
+	rules *TagRules
+	// tags are the tags set on each node, by node
+	tags map[string][]string
+	// propagated are the tags inherited by each node, by tag then node
+	propagated map[string]map[string]PropagatedTag
+	dirty      map[string]bool
+}
+
+// NewTagPropagator creates a propagator of tags along the edges of g.
+func NewTagPropagator(g *Graph, rules *TagRules) *TagPropagator {
+	return &TagPropagator{
+		graph:      g,
+		rules:      rules,
+		tags:       make(map[string][]string),
+		propagated: make(map[string]map[string]PropagatedTag),
+		dirty:      make(map[string]bool),
+	}
+}
+
+// SetRules replaces the rules, re-evaluating every tag at the next
+// evaluation.
+func (p *TagPropagator) SetRules(rules *TagRules) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rules = rules
+	for _, tags := range p.tags {
+		for _, tag := range tags {
+			p.dirty[tag] = true
+		}
+	}
+	for tag := range p.propagated {
+		p.dirty[tag] = true
+	}
+}
+
+// SetTags sets the tags of a table or column node, replacing its previous
+// tags.
+func (p *TagPropagator) SetTags(node string, tags []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	old := p.tags[node]
+	for _, tag := range old {
+		if !contains(tags, tag) {
+			p.dirty[tag] = true
+		}
+	}
+	for _, tag := range tags {
+		if !contains(old, tag) {
+			p.dirty[tag] = true
+		}
+	}
+	if len(tags) == 0 {
+		delete(p.tags, node)
+		return
+	}
+	p.tags[node] = append([]string(nil), tags...)
+}
+
+// LineageChanged records that edges of the graph were added, removed or
+// retired, so that the tags that propagate are re-evaluated.
+func (p *TagPropagator) LineageChanged() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, tags := range p.tags {
+		for _, tag := range tags {
+			if p.rules.Rule(tag) != nil {
+				p.dirty[tag] = true
+			}
+		}
+	}
+	for tag := range p.propagated {
+		p.dirty[tag] = true
+	}
+}
+
+// Evaluate re-evaluates the tags changed since the last evaluation and
+// returns the tags nodes gained or lost, sorted by node and tag.
+func (p *TagPropagator) Evaluate() []TagChange {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.evaluateLocked()
+}
+
+// Tags returns the tags node inherits through lineage, sorted by tag.
+func (p *TagPropagator) Tags(node string) []PropagatedTag {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.evaluateLocked()
+	var tags []PropagatedTag
+	for _, nodes := range p.propagated {
+		if t, ok := nodes[node]; ok {
+			tags = append(tags, t)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Tag < tags[j].Tag })
+	return tags
+}
+
+// Propagated returns the tags inherited through lineage, by node.
+func (p *TagPropagator) Propagated() map[string][]PropagatedTag {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.evaluateLocked()
+	result := make(map[string][]PropagatedTag)
+	for _, nodes := range p.propagated {
+		for node, t := range nodes {
+			result[node] = append(result[node], t)
+		}
+	}
+	for _, tags := range result {
+		sort.Slice(tags, func(i, j int) bool { return tags[i].Tag < tags[j].Tag })
+	}
+	return result
+}
+
+func (p *TagPropagator) evaluateLocked() []TagChange {
+	if len(p.dirty) == 0 {
+		return nil
+	}
+
+	var changes []TagChange
+	var index *lineageIndex
+	for tag := range p.dirty {
+		var next map[string]PropagatedTag
+		if rule := p.rules.Rule(tag); rule != nil {
+			if index == nil {
+				index = p.index()
+			}
+			next = p.propagate(tag, rule, index)
+		}
+
+		old := p.propagated[tag]
+		for node, t := range next {
+			if _, ok := old[node]; !ok {
+				changes = append(changes, TagChange{Node: node, Tag: tag, From: t.From})
+			}
+		}
+		for node := range old {
+			if _, ok := next[node]; !ok {
+				changes = append(changes, TagChange{Node: node, Tag: tag, Removed: true})
+			}
+		}
+		if len(next) == 0 {
+			delete(p.propagated, tag)
+		} else {
+			p.propagated[tag] = next
+		}
+	}
+	p.dirty = make(map[string]bool)
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Node != changes[j].Node {
+			return changes[i].Node < changes[j].Node
+		}
+		return changes[i].Tag < changes[j].Tag
+	})
+	return changes
+}
+
+// propagate returns the nodes inheriting tag, walking the lineage in the
+// rule's direction breadth-first from every node carrying the tag.
+func (p *TagPropagator) propagate(tag string, rule *TagRule, index *lineageIndex) map[string]PropagatedTag {
+	type step struct{ node, from string }
+
+	visited := make(map[string]bool)
+	var current []step
+	for node, tags := range p.tags {
+		if contains(tags, tag) {
+			visited[node] = true
+			if !rule.excludes(index.tableOf(node)) {
+				current = append(current, step{node, node})
+			}
+		}
+	}
+	// Visit the tagged nodes in a stable order so that From is deterministic
+	sort.Slice(current, func(i, j int) bool { return current[i].node < current[j].node })
+
+	neighbours := index.neighbours[rule.Direction]
+	result := make(map[string]PropagatedTag)
+	for hop := 1; len(current) > 0 && (rule.MaxHops <= 0 || hop <= rule.MaxHops); hop++ {
+		var next []step
+		for _, s := range current {
+			for _, to := range neighbours[s.node] {
+				if visited[to] || rule.excludes(index.tableOf(to)) {
+					continue
+				}
+				visited[to] = true
+				result[to] = PropagatedTag{Tag: tag, From: s.from, Hops: hop}
+				next = append(next, step{to, s.from})
+			}
+		}
+		current = next
+	}
+	return result
+}
+
+// lineageIndex is the adjacency of the column and table nodes of the current
+// edges of a graph.
+type lineageIndex struct {
+	neighbours map[Direction]map[string][]string
+	// tables are the database.table names of the column nodes
+	tables map[string]string
+}
+
+func (p *TagPropagator) index() *lineageIndex {
+	index := &lineageIndex{
+		neighbours: map[Direction]map[string][]string{
+			Downstream: make(map[string][]string),
+			Upstream:   make(map[string][]string),
+		},
+		tables: make(map[string]string),
+	}
+	link := func(from, to string) {
+		if from != to {
+			index.neighbours[Downstream][from] = appendUnique(index.neighbours[Downstream][from], to)
+			index.neighbours[Upstream][to] = appendUnique(index.neighbours[Upstream][to], from)
+		}
+	}
+	for _, edge := range p.graph.Edges() {
+		if !edge.Current() {
+			continue
+		}
+		for _, c := range []ColumnRef{edge.Source, edge.Target} {
+			index.tables[c.QualifiedName()] = c.TableName()
+		}
+		link(edge.Source.QualifiedName(), edge.Target.QualifiedName())
+		link(edge.Source.TableName(), edge.Target.TableName())
+	}
+	return index
+}
+
+// tableOf returns the database.table name of a table or column node.
+func (x *lineageIndex) tableOf(node string) string {
+	if table, ok := x.tables[node]; ok {
+		return table
+	}
+	if strings.Count(node, ".") >= 2 {
+		return node[:strings.LastIndex(node, ".")]
+	}
+	return node
+}
@@ -0,0 +1,194 @@
+package lineage
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JoinKey is a pair of columns of two tables compared for equality, either
+// in a join condition or by a foreign key.
+type JoinKey struct {
+	Left  ColumnRef `json:"left"`
+	Right ColumnRef `json:"right"`
+}
+
+// normalize orders the columns by table and column name and drops the
+// resolution confidence, so that a = b and b = a are the same key.
+func (k JoinKey) normalize() JoinKey {
+	k.Left.Confidence, k.Right.Confidence = "", ""
+	left, right := strings.ToLower(k.Left.TableName()), strings.ToLower(k.Right.TableName())
+	if right < left || right == left && strings.ToLower(k.Right.Column) < strings.ToLower(k.Left.Column) {
+		k.Left, k.Right = k.Right, k.Left
+	}
+	return k
+}
+
+// RelationshipKey is a column pair two tables are joined on.
+type RelationshipKey struct {
+	LeftColumn  string `json:"left_column"`
+	RightColumn string `json:"right_column"`
+	// Occurrences counts the statements joining on the key.
+	Occurrences int64 `json:"occurrences"`
+	// ForeignKey is set if the key is declared as a foreign key.
+	ForeignKey bool `json:"foreign_key"`
+}
+
+// Relationship is a pair of tables joined in queries or related by foreign
+// keys. Relationships that are not foreign keys are suggestions inferred
+// from how the tables are queried.
+type Relationship struct {
+	Left  string `json:"left"`
+	Right string `json:"right"`
+	// Occurrences counts the statements joining the tables.
+	Occurrences int64              `json:"occurrences"`
+	ForeignKey  bool               `json:"foreign_key"`
+	Keys        []*RelationshipKey `json:"keys"`
+	FirstSeen   time.Time          `json:"first_seen,omitempty"`
+	LastSeen    time.Time          `json:"last_seen,omitempty"`
+}
+
+// Suggested reports whether the relationship was only inferred from queries.
+func (r *Relationship) Suggested() bool {
+	return !r.ForeignKey
+}
+
+func (r *Relationship) key(left, right string) *RelationshipKey {
+	for _, k := range r.Keys {
+		if strings.EqualFold(k.LeftColumn, left) && strings.EqualFold(k.RightColumn, right) {
+			return k
+		}
+	}
+	k := &RelationshipKey{LeftColumn: left, RightColumn: right}
+	r.Keys = append(r.Keys, k)
+	return k
+}
+
+func (r *Relationship) copy() *Relationship {
+	copied := *r
+	copied.Keys = make([]*RelationshipKey, len(r.Keys))
+	for i, k := range r.Keys {
+		key := *k
+		copied.Keys[i] = &key
+	}
+	sort.Slice(copied.Keys, func(i, j int) bool {
+		if copied.Keys[i].Occurrences != copied.Keys[j].Occurrences {
+			return copied.Keys[i].Occurrences > copied.Keys[j].Occurrences
+		}
+		return copied.Keys[i].LeftColumn < copied.Keys[j].LeftColumn
+	})
+	return &copied
+}
+
+// RelationshipStats infers table relationships from the join keys of many
+// statements, typically harvested from query logs, and from foreign keys.
+// Table and column names are compared case-insensitively. RelationshipStats
+// is safe for concurrent use.
+type RelationshipStats struct {
+	mu            sync.RWMutex
+	relationships map[string]*Relationship
+}
+
+// NewRelationshipStats creates empty relationship statistics.
+func NewRelationshipStats() *RelationshipStats {
+	return &RelationshipStats{relationships: make(map[string]*Relationship)}
+}
+
+// Add counts the join keys of a statement executed at the given time.
+func (s *RelationshipStats) Add(result *LineageResult, at time.Time) {
+	if result == nil || len(result.JoinKeys) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counted := make(map[*Relationship]bool)
+	for _, key := range result.JoinKeys {
+		key = key.normalize()
+		r := s.relationship(key)
+		r.key(key.Left.Column, key.Right.Column).Occurrences++
+		if counted[r] {
+			continue
+		}
+		counted[r] = true
+		r.Occurrences++
+		if r.FirstSeen.IsZero() || at.Before(r.FirstSeen) {
+			r.FirstSeen = at
+		}
+		if at.After(r.LastSeen) {
+			r.LastSeen = at
+		}
+	}
+}
+
+// AddForeignKey records a column pair declared as a foreign key.
+func (s *RelationshipStats) AddForeignKey(key JoinKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key = key.normalize()
+	r := s.relationship(key)
+	r.ForeignKey = true
+	r.key(key.Left.Column, key.Right.Column).ForeignKey = true
+}
+
+// Relationships returns copies of the relationships of a table, given as
+// table or database.table, or of every table if table is empty. Suggested
+// relationships seen in fewer than minOccurrences statements are left out.
+// The most frequently joined relationships come first.
+func (s *RelationshipStats) Relationships(table string, minOccurrences int64) []*Relationship {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matches := func(name string) bool {
+		if strings.EqualFold(name, table) {
+			return true
+		}
+		_, short, found := strings.Cut(name, ".")
+		return found && strings.EqualFold(short, table)
+	}
+
+	relationships := make([]*Relationship, 0)
+	for _, r := range s.relationships {
+		if table != "" && !matches(r.Left) && !matches(r.Right) {
+			continue
+		}
+		if !r.ForeignKey && r.Occurrences < minOccurrences {
+			continue
+		}
+		relationships = append(relationships, r.copy())
+	}
+	sort.Slice(relationships, func(i, j int) bool {
+		a, b := relationships[i], relationships[j]
+		if a.Occurrences != b.Occurrences {
+			return a.Occurrences > b.Occurrences
+		}
+		if a.Left != b.Left {
+			return a.Left < b.Left
+		}
+		return a.Right < b.Right
+	})
+	return relationships
+}
+
+// Len returns the number of related table pairs.
+func (s *RelationshipStats) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.relationships)
+}
+
+// relationship returns the relationship of the tables of a normalized key,
+// creating it if needed.
+func (s *RelationshipStats) relationship(key JoinKey) *Relationship {
+	left, right := key.Left.TableName(), key.Right.TableName()
+	id := strings.ToLower(left + "|" + right)
+	r, ok := s.relationships[id]
+	if !ok {
+		r = &Relationship{Left: left, Right: right}
+		s.relationships[id] = r
+	}
+	return r
+}
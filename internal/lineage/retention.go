@@ -0,0 +1,106 @@
+package lineage
+
+import "time"
+
+// RetentionPolicy controls how long lineage edges of a given origin are kept
+// after they were last observed.
+type RetentionPolicy struct {
+	// Origin is the edge origin the policy applies to; empty matches every origin.
+	Origin Origin `json:"origin,omitempty" yaml:"origin"`
+	// MaxAge is how long an edge may go unobserved before it is pruned.
+	MaxAge time.Duration `json:"max_age" yaml:"max_age"`
+	// Retire closes the edge's validity interval instead of deleting it, so the
+	// edge remains visible to as-of queries.
+	Retire bool `json:"retire,omitempty" yaml:"retire"`
+}
+
+// PruneStats summarizes a pruning run.
+type PruneStats struct {
+	Examined int `json:"examined"`
+	Deleted  int `json:"deleted"`
+	Retired  int `json:"retired"`
+}
+
+// GraphStats describes the size of a lineage graph.
+type GraphStats struct {
+	Edges        int `json:"edges"`
+	CurrentEdges int `json:"current_edges"`
+	Jobs         int `json:"jobs"`
+	Fingerprints int `json:"fingerprints"`
+}
+
+// Prune applies retention policies to the graph as of now. An edge is pruned
+// only when every one of its origins is covered by a policy and the edge has
+// not been observed within the longest MaxAge of those policies; it is retired
+// rather than deleted if any applicable policy asks to retire. Overlapping
+// validity intervals are compacted along the way.
+func (g *Graph) Prune(policies []RetentionPolicy, now time.Time) PruneStats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stats := PruneStats{}
+	for key, edge := range g.edges {
+		stats.Examined++
+		edge.Validity = mergeIntervals(edge.Validity)
+
+		maxAge, retire, ok := applicableRetention(policies, edge.Provenance.Origins)
+		if !ok || !edge.Provenance.LastSeen.Before(now.Add(-maxAge)) {
+			continue
+		}
+
+		if retire {
+			if edge.Current() {
+				edge.retire(now)
+				stats.Retired++
+			}
+			continue
+		}
+		delete(g.edges, key)
+		stats.Deleted++
+	}
+	return stats
+}
+
+// applicableRetention combines the policies covering origins. ok is false when
+// some origin has no policy, in which case the edge is kept.
+func applicableRetention(policies []RetentionPolicy, origins []Origin) (maxAge time.Duration, retire bool, ok bool) {
+	if len(origins) == 0 {
+		origins = []Origin{""}
+	}
+	for _, origin := range origins {
+		found := false
+		for _, p := range policies {
+			if p.Origin != "" && p.Origin != origin {
+				continue
+			}
+			found = true
+			if p.MaxAge > maxAge {
+				maxAge = p.MaxAge
+			}
+			retire = retire || p.Retire
+		}
+		if !found {
+			return 0, false, false
+		}
+	}
+	return maxAge, retire, true
+}
+
+// Stats returns the current size of the graph.
+func (g *Graph) Stats() GraphStats {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	fingerprints := make(map[string]bool)
+	stats := GraphStats{Edges: len(g.edges), Jobs: len(g.jobs)}
+	for _, edge := range g.edges {
+		if edge.Current() {
+			stats.CurrentEdges++
+		}
+		for _, fp := range edge.Provenance.Fingerprints {
+			fingerprints[fp] = true
+		}
+	}
+	stats.Fingerprints = len(fingerprints)
+	return stats
+}
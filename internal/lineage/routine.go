@@ -0,0 +1,384 @@
+package lineage
+
+import (
+	"regexp"
+	"strings"
+)
+
+// routineVariables is the table the variables of a routine are analyzed as
+// columns of: references to them are qualified with it, so that they are
+// traced like the columns of a temporary table.
+const routineVariables = "__variables"
+
+var (
+	// routineBegin matches the BEGIN of the body of a routine.
+	routineBegin = regexp.MustCompile(`(?i)\bbegin\b`)
+	// routineDollarBody matches a PostgreSQL dollar-quoted function body.
+	routineDollarBody = regexp.MustCompile(`(?s)\$(\w*)\$(.*)\$(\w*)\$`)
+	// routineLabel matches the label of a block or loop, e.g. read_loop:.
+	routineLabel = regexp.MustCompile(`(?is)^\w+\s*:(?:[^=]|$)\s*`)
+	// routineControl matches the control flow that starts a statement of a
+	// routine body: BEGIN, LOOP, REPEAT and ELSE, and the conditions of IF,
+	// ELSEIF, WHILE and FOR.
+	routineControl = regexp.MustCompile(`(?is)^(?:begin(?:\s+not\s+atomic)?|loop|repeat|else|(?:if|elseif|elsif|while)\s.*?\s(?:then|do|loop)|for\s.*?\s(?:do|loop))\b\s*`)
+	// routineForQuery matches a cursor FOR loop over a query:
+	// FOR r IN (SELECT ...) LOOP.
+	routineForQuery = regexp.MustCompile(`(?is)^for\s+(\w+)\s+in\s*\((.*?)\)\s*loop\b\s*`)
+	// routineForCursor matches a cursor FOR loop over a declared cursor:
+	// FOR r IN cur LOOP.
+	routineForCursor = regexp.MustCompile(`(?is)^for\s+(\w+)\s+in\s+(\w+)\s+loop\b\s*`)
+	// routineSkip matches the statements of a routine body without lineage.
+	routineSkip = regexp.MustCompile(`(?is)^(?:$|end\b|until\b|open\b|close\b|leave\b|iterate\b|exit\b|continue\b|return\b|call\b|signal\b|resignal\b|commit\b|rollback\b|start\s+transaction\b|null\b|print\b|declare\s+\w+\s+handler\b)`)
+	// routineCursor matches DECLARE cur CURSOR FOR query, or CURSOR IS.
+	routineCursor = regexp.MustCompile(`(?is)^declare\s+(\w+)\s+cursor\s+(?:for|is)\s+(.*)$`)
+	// routineDeclare matches DECLARE of one or more variables.
+	routineDeclare = regexp.MustCompile(`(?is)^declare\s+(@?\w+(?:\s*,\s*@?\w+)*)`)
+	// routineFetch matches FETCH [NEXT FROM] cur INTO variables.
+	routineFetch = regexp.MustCompile(`(?is)^fetch\s+(?:(?:next\s+)?from\s+)?(\w+)\s+into\s+(.*)$`)
+	// routineSet matches the assignment SET v = expr, or v := expr.
+	routineSet = regexp.MustCompile(`(?is)^(?:set\s+(@?\w+)\s*:?=|(\w+)\s*:=)\s*(.*)$`)
+	// routineSelectInto matches SELECT ... INTO variables; the second group
+	// is the variables.
+	routineSelectInto = regexp.MustCompile(`(?is)^select\s.*?(\binto\s+(@?\w+(?:\s*,\s*@?\w+)*))`)
+	// routineValues matches INSERT ... VALUES (...) of one row.
+	routineValues = regexp.MustCompile(`(?is)^(insert\s.*?)\bvalues\s*\((.*)\)\s*$`)
+	// routineUpdate matches an UPDATE of a single table.
+	routineUpdate = regexp.MustCompile(`(?is)^update\s+([\w.` + "`" + `"]+)((?:\s+(?:as\s+)?\w+)?)\s+set\s+(.*?)(\s+where\s.*)?$`)
+	// routineInsertSource matches the target of an INSERT; the group is the
+	// keyword its query starts with.
+	routineInsertSource = regexp.MustCompile(`(?is)^insert\s.*?\b(select|values|with)\b`)
+)
+
+// isRoutine reports whether stmt creates a stored procedure or function.
+func isRoutine(stmt string) bool {
+	return routineHeader.MatchString(leadingComments.ReplaceAllString(stmt, ""))
+}
+
+// routine analyzes the statements of the body of a stored procedure or
+// function. Their variables, including the parameters, are analyzed as
+// columns: a variable assigned by SET, SELECT ... INTO or FETCH carries the
+// lineage of the expression or cursor column it was assigned, and the
+// statements that read it are traced through to those sources, as for
+// temporary tables. The records of cursor FOR loops are traced the same way.
+func (s *scriptSession) routine(a *Analyzer, stmt string, catalog Catalog) (bool, error) {
+	header, body, ok := routineBody(stmt)
+	if !ok {
+		return false, ErrUnsupportedSQL
+	}
+	r := &routineScope{cursors: make(map[string][]ColumnLineage)}
+	s.temps[routineVariables] = make(map[string]ColumnLineage)
+	defer func() {
+		delete(s.temps, routineVariables)
+		for _, record := range r.records {
+			delete(s.temps, record)
+		}
+	}()
+	for _, param := range routineParams(header) {
+		s.declare(param)
+	}
+
+	analyzed := false
+	for _, stmt := range SplitStatements(body) {
+		if s.routineStatement(a, r, leadingComments.ReplaceAllString(stmt, ""), catalog) {
+			analyzed = true
+		}
+	}
+	if !analyzed {
+		return false, ErrUnsupportedSQL
+	}
+	return true, nil
+}
+
+// routineScope is the state of the routine being analyzed.
+type routineScope struct {
+	// cursors holds the lineage of the columns of the cursors, in order
+	cursors map[string][]ColumnLineage
+	// records are the temporary tables of the records of cursor FOR loops
+	records []string
+}
+
+// routineStatement analyzes a statement of a routine body and reports
+// whether it had lineage. Statements that cannot be analyzed are ignored, as
+// much of a body is control flow the parser does not support.
+func (s *scriptSession) routineStatement(a *Analyzer, r *routineScope, stmt string, catalog Catalog) bool {
+	// The statement split at a semicolon may start with the control flow
+	// around it, e.g. WHILE done = 0 DO FETCH ...
+	for {
+		stmt = strings.TrimSpace(stmt)
+		if m := routineLabel.FindString(stmt); m != "" && !strings.Contains(m, "::") {
+			stmt = strings.TrimSpace(stmt[strings.IndexByte(m, ':')+1:])
+		}
+		if m := routineForQuery.FindStringSubmatch(stmt); m != nil {
+			s.record(r, m[1], s.query(a, m[2], catalog))
+			stmt = stmt[len(m[0]):]
+			continue
+		}
+		if m := routineForCursor.FindStringSubmatch(stmt); m != nil {
+			s.record(r, m[1], r.cursors[strings.ToLower(m[2])])
+			stmt = stmt[len(m[0]):]
+			continue
+		}
+		m := routineControl.FindString(stmt)
+		if m == "" {
+			break
+		}
+		stmt = stmt[len(m):]
+	}
+
+	switch {
+	case routineSkip.MatchString(stmt):
+		return false
+	case routineCursor.MatchString(stmt):
+		m := routineCursor.FindStringSubmatch(stmt)
+		r.cursors[strings.ToLower(m[1])] = s.query(a, m[2], catalog)
+		return false
+	case routineDeclare.MatchString(stmt):
+		for _, name := range strings.Split(routineDeclare.FindStringSubmatch(stmt)[1], ",") {
+			s.declare(name)
+		}
+		return false
+	case routineFetch.MatchString(stmt):
+		m := routineFetch.FindStringSubmatch(stmt)
+		s.assign(strings.Split(m[2], ","), r.cursors[strings.ToLower(m[1])])
+		return false
+	case routineSet.MatchString(stmt):
+		m := routineSet.FindStringSubmatch(stmt)
+		name := m[1] + m[2]
+		if _, ok := s.temps[routineVariables][variableKey(name)]; ok || strings.HasPrefix(name, "@") || m[2] != "" {
+			s.assign([]string{name}, s.query(a, "SELECT "+m[3], catalog))
+			return false
+		}
+	case routineSelectInto.MatchString(stmt):
+		m := routineSelectInto.FindStringSubmatchIndex(stmt)
+		query := stmt[:m[2]] + stmt[m[3]:]
+		s.assign(strings.Split(stmt[m[4]:m[5]], ","), s.query(a, query, catalog))
+		return false
+	}
+
+	stmt = rewriteRoutineDML(stmt)
+	if m := routineInsertSource.FindStringSubmatchIndex(stmt); m != nil {
+		// The target columns are not variables, even if named like them
+		stmt = stmt[:m[2]] + s.qualifyVariables(stmt[m[2]:])
+	} else {
+		stmt = s.qualifyVariables(stmt)
+	}
+	ok, err := s.statement(a, stmt, catalog)
+	return ok && err == nil
+}
+
+// query returns the lineage of the columns of a query of a routine, in
+// order, traced through variables and temporary tables. The tables the
+// query reads are recorded in the result.
+func (s *scriptSession) query(a *Analyzer, query string, catalog Catalog) []ColumnLineage {
+	result, _, err := a.analyze(s.qualifyVariables(query), catalog)
+	if err != nil {
+		return nil
+	}
+	columns := make([]ColumnLineage, len(result.Columns))
+	for i, col := range result.Columns {
+		columns[i] = s.trace(col)
+	}
+	s.add(&LineageResult{Unresolved: result.Unresolved, Usages: result.Usages, JoinKeys: result.JoinKeys, Scans: result.Scans}, nil, "")
+	return columns
+}
+
+// declare adds a variable without lineage to the routine, so that its
+// references are not taken for columns of the tables of a query.
+func (s *scriptSession) declare(name string) {
+	key := variableKey(name)
+	if key == "" {
+		return
+	}
+	s.temps[routineVariables][key] = ColumnLineage{Target: ColumnRef{Table: routineVariables, Column: key}}
+}
+
+// assign sets the lineage of variables to that of the columns assigned to
+// them by position. Variables assigned from a query that cannot be analyzed
+// lose their lineage.
+func (s *scriptSession) assign(names []string, columns []ColumnLineage) {
+	for i, name := range names {
+		s.declare(name)
+		if i < len(columns) {
+			key := variableKey(name)
+			l := columns[i]
+			l.Target = ColumnRef{Table: routineVariables, Column: key}
+			s.temps[routineVariables][key] = l
+		}
+	}
+}
+
+// record adds the record of a cursor FOR loop as a temporary table with the
+// columns of its query.
+func (s *scriptSession) record(r *routineScope, name string, columns []ColumnLineage) {
+	key := tableKey(name)
+	temp := make(map[string]ColumnLineage, len(columns))
+	for _, col := range columns {
+		temp[strings.ToLower(col.Target.Column)] = col
+	}
+	s.temps[key] = temp
+	r.records = append(r.records, key)
+}
+
+// qualifyVariables qualifies the references to the variables of the routine
+// in stmt with routineVariables. Like MySQL, a variable takes precedence
+// over a column of the same name; qualified names, function names and
+// aliases are left alone.
+func (s *scriptSession) qualifyVariables(stmt string) string {
+	variables := s.temps[routineVariables]
+	if len(variables) == 0 {
+		return stmt
+	}
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(stmt); i++ {
+		c := stmt[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			b.WriteByte(c)
+			continue
+		}
+		if c == '\'' || c == '"' || c == '`' {
+			quote = c
+			b.WriteByte(c)
+			continue
+		}
+		start := i
+		if c == '@' && i+1 < len(stmt) && isWordStart(stmt, i+1) {
+			i++
+		} else if !isWordStart(stmt, i) {
+			b.WriteByte(c)
+			continue
+		}
+		end := i + 1
+		for end < len(stmt) && isWordChar(stmt[end]) {
+			end++
+		}
+		word := stmt[start:end]
+		rest := strings.TrimLeft(stmt[end:], " \t\r\n")
+		before := strings.TrimRight(stmt[:start], " \t\r\n")
+		_, ok := variables[variableKey(word)]
+		qualified := strings.HasSuffix(before, ".") || strings.HasPrefix(rest, ".")
+		alias := len(before) >= 3 && strings.EqualFold(before[len(before)-3:], " as")
+		if ok && !qualified && !alias && !strings.HasPrefix(rest, "(") {
+			b.WriteString(routineVariables + "." + variableKey(word))
+		} else {
+			b.WriteString(word)
+		}
+		i = end - 1
+	}
+	return b.String()
+}
+
+// rewriteRoutineDML rewrites the DML of a routine whose lineage the parser
+// does not extract as INSERT ... SELECT: INSERT ... VALUES of a row and the
+// UPDATE of a single table.
+func rewriteRoutineDML(stmt string) string {
+	if m := routineValues.FindStringSubmatch(stmt); m != nil {
+		return m[1] + "SELECT " + m[2]
+	}
+	m := routineUpdate.FindStringSubmatch(stmt)
+	if m == nil {
+		return stmt
+	}
+	var columns, values []string
+	for _, assignment := range splitTopLevel(m[3]) {
+		column, value, ok := strings.Cut(assignment, "=")
+		if !ok {
+			return stmt
+		}
+		column = strings.TrimSpace(column)
+		if i := strings.LastIndexByte(column, '.'); i >= 0 {
+			column = column[i+1:]
+		}
+		columns = append(columns, column)
+		values = append(values, strings.TrimSpace(value))
+	}
+	return "INSERT INTO " + m[1] + " (" + strings.Join(columns, ", ") + ") SELECT " +
+		strings.Join(values, ", ") + " FROM " + m[1] + m[2] + m[4]
+}
+
+// routineBody splits a CREATE PROCEDURE or FUNCTION statement into its
+// header, with the parameters, and its body: from its BEGIN, or the contents
+// of a PostgreSQL dollar-quoted body.
+func routineBody(stmt string) (header, body string, ok bool) {
+	if m := routineDollarBody.FindStringSubmatchIndex(stmt); m != nil && stmt[m[2]:m[3]] == stmt[m[6]:m[7]] {
+		return stmt[:m[0]], stmt[m[4]:m[5]], true
+	}
+	loc := routineBegin.FindStringIndex(stmt)
+	if loc == nil {
+		return "", "", false
+	}
+	return stmt[:loc[0]], stmt[loc[0]:], true
+}
+
+// routineParams returns the names of the parameters of a routine from its
+// header, e.g. p_date of (IN p_date DATE, OUT p_total INT). Parameters
+// declared by their type alone, as PostgreSQL allows, have no name.
+func routineParams(header string) []string {
+	open := strings.IndexByte(header, '(')
+	if open < 0 {
+		return nil
+	}
+	params := header[open+1:]
+	depth := 0
+	for i := 0; i < len(params); i++ {
+		if params[i] == '(' {
+			depth++
+		} else if params[i] == ')' {
+			if depth == 0 {
+				params = params[:i]
+				break
+			}
+			depth--
+		}
+	}
+
+	var names []string
+	for _, param := range splitTopLevel(params) {
+		fields := strings.Fields(param)
+		if len(fields) > 2 {
+			switch strings.ToLower(fields[0]) {
+			case "in", "out", "inout":
+				fields = fields[1:]
+			}
+		}
+		if len(fields) > 1 {
+			names = append(names, fields[0])
+		}
+	}
+	return names
+}
+
+// splitTopLevel splits s at the commas outside of parentheses and quotes.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// variableKey returns the lower-case name of a variable, without the @ of
+// MySQL user and T-SQL variables.
+func variableKey(name string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "@"))
+}
@@ -0,0 +1,336 @@
+package lineage
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// batchSeparator matches a T-SQL GO batch separator line, e.g. in scripts
+// exported by SQL Server Management Studio.
+var batchSeparator = regexp.MustCompile(`(?i)^\s*go(\s+\d+)?\s*$`)
+
+// delimiterCommand matches a MySQL client DELIMITER line, which changes the
+// statement terminator, e.g. to // around stored procedures.
+var delimiterCommand = regexp.MustCompile(`(?i)^\s*delimiter\s+(\S+)\s*$`)
+
+// psqlCommand matches a psql meta-command line, e.g. \set or \copy, which
+// ends at the end of the line.
+var psqlCommand = regexp.MustCompile(`^\s*\\[a-zA-Z]`)
+
+// copyFromStdin matches a COPY FROM STDIN statement, followed in the script
+// by the rows it loads up to a \. line, as in pg_dump output.
+var copyFromStdin = regexp.MustCompile(`(?is)^\\?copy\s.*\bfrom\s+stdin\b`)
+
+// routineHeader matches the start of a stored procedure, function or
+// trigger, whose BEGIN ... END body is part of the statement.
+var routineHeader = regexp.MustCompile(`(?is)^create\s+(?:or\s+replace\s+)?(?:definer\s*=\s*\S+\s+)?(?:procedure|function|trigger)\b`)
+
+// scriptState is where a script scanner is within a statement.
+type scriptState int
+
+const (
+	stateCode scriptState = iota
+	stateSingleQuote
+	stateDoubleQuote
+	stateBacktick
+	stateBlockComment
+	stateDollarQuote
+	stateTemplate
+)
+
+// ScanStatements reads a SQL script from r and calls fn with each statement,
+// without its terminating semicolon, as soon as it is complete, so that
+// scripts of any size are read in constant memory. Statements end at a
+// semicolon outside of quotes, comments, dollar-quoted bodies, dbt/Airflow
+// template tags and the BEGIN ... END bodies of stored procedures, at the
+// terminator set by a MySQL DELIMITER line, or at a T-SQL GO batch separator
+// line. A psql \copy meta-command is a statement of its line; other psql
+// meta-commands and the rows following COPY FROM STDIN are skipped. A line
+// comment after the semicolon of a statement belongs to that statement;
+// other comments belong to the statement that follows them. Statements made
+// only of comments are skipped. It stops at the first error of fn.
+func ScanStatements(r io.Reader, fn func(stmt string) error) error {
+	s := &scriptScanner{fn: fn}
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if ferr := s.scanLine(line); ferr != nil {
+				return ferr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return s.flush()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// SplitStatements returns the statements of a SQL script, as ScanStatements
+// reads them.
+func SplitStatements(script string) []string {
+	var stmts []string
+	ScanStatements(strings.NewReader(script), func(stmt string) error {
+		stmts = append(stmts, stmt)
+		return nil
+	})
+	return stmts
+}
+
+// ExtractComments returns the comments of sql, outside of quotes, in order.
+// Optimizer hints (/*+ ... */) are not comments.
+func ExtractComments(sql string) []Comment {
+	s := &scriptScanner{fn: func(string) error { return nil }, keepComments: true}
+	for _, line := range strings.SplitAfter(sql, "\n") {
+		s.scanLine(line)
+	}
+	return s.comments
+}
+
+type scriptScanner struct {
+	fn      func(stmt string) error
+	stmt    strings.Builder
+	hasCode bool
+	state   scriptState
+	closing string // closing delimiter of a dollar quote or template tag
+
+	delimiter string // statement terminator set by DELIMITER, "" for ;
+	depth     int    // nesting of BEGIN ... END blocks in a routine body
+	copyData  bool   // within the rows of a COPY FROM STDIN
+
+	// Comments are recorded if keepComments is set
+	keepComments bool
+	comments     []Comment
+	line         int
+	comment      strings.Builder // text of the current block comment
+	commentLine  int
+}
+
+func (s *scriptScanner) scanLine(line string) error {
+	s.line++
+	if s.copyData {
+		if strings.TrimSpace(line) == `\.` {
+			s.copyData = false
+		}
+		return nil
+	}
+	if s.state == stateCode && batchSeparator.MatchString(line) {
+		return s.flush()
+	}
+	if s.state == stateCode && s.delimiter == "" && !s.hasCode && psqlCommand.MatchString(line) {
+		if !MatchKeywords(`\copy`)(line) {
+			return nil
+		}
+		s.stmt.WriteString(line)
+		s.hasCode = true
+		return s.flush()
+	}
+	if m := delimiterCommand.FindStringSubmatch(line); m != nil && s.state == stateCode {
+		if err := s.flush(); err != nil {
+			return err
+		}
+		s.delimiter = m[1]
+		if s.delimiter == ";" {
+			s.delimiter = ""
+		}
+		return nil
+	}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch s.state {
+		case stateCode:
+			switch {
+			case s.delimiter != "" && strings.HasPrefix(line[i:], s.delimiter):
+				if err := s.flush(); err != nil {
+					return err
+				}
+				i += len(s.delimiter) - 1
+				continue
+			case c == ';' && s.delimiter == "" && s.depth == 0:
+				if rest := strings.TrimSpace(line[i+1:]); strings.HasPrefix(rest, "--") {
+					// A trailing comment annotates the statement it follows
+					s.lineComment(rest)
+					s.stmt.WriteString(" " + rest)
+					return s.flush()
+				}
+				if err := s.flush(); err != nil {
+					return err
+				}
+				continue
+			case strings.HasPrefix(line[i:], "--"):
+				// The rest of the line is a comment
+				s.lineComment(line[i:])
+				s.stmt.WriteString(line[i:])
+				return nil
+			case strings.HasPrefix(line[i:], "/*") && !strings.HasPrefix(line[i:], "/*+"):
+				s.state = stateBlockComment
+				s.comment.Reset()
+				s.commentLine = s.line
+				s.stmt.WriteString("/*")
+				i++
+				continue
+			case c == '\'':
+				s.state = stateSingleQuote
+			case c == '"':
+				s.state = stateDoubleQuote
+			case c == '`':
+				s.state = stateBacktick
+			case c == '$':
+				if tag := dollarTag(line[i:]); tag != "" {
+					s.state, s.closing = stateDollarQuote, tag
+					s.stmt.WriteString(tag)
+					s.hasCode = true
+					i += len(tag) - 1
+					continue
+				}
+			case c == '{' && i+1 < len(line) && strings.IndexByte("{%#", line[i+1]) >= 0:
+				s.state = stateTemplate
+				s.closing = string(templateClose[line[i+1]]) + "}"
+				s.stmt.WriteString(line[i : i+2])
+				s.hasCode = true
+				i++
+				continue
+			case isWordStart(line, i):
+				end := i + 1
+				for end < len(line) && isWordChar(line[end]) {
+					end++
+				}
+				s.block(line[i:end], line[end:])
+				s.stmt.WriteString(line[i:end])
+				s.hasCode = true
+				i = end - 1
+				continue
+			}
+			if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+				s.hasCode = true
+			}
+		case stateSingleQuote, stateDoubleQuote, stateBacktick:
+			if c == '\\' && s.state != stateBacktick && i+1 < len(line) {
+				s.stmt.WriteString(line[i : i+2])
+				i++
+				continue
+			}
+			if c == quoteOf[s.state] {
+				s.state = stateCode
+			}
+		case stateBlockComment:
+			if strings.HasPrefix(line[i:], "*/") {
+				s.state = stateCode
+				s.addComment(s.comment.String(), s.commentLine, true)
+				s.stmt.WriteString("*/")
+				i++
+				continue
+			}
+			if s.keepComments {
+				s.comment.WriteByte(c)
+			}
+		case stateDollarQuote, stateTemplate:
+			if strings.HasPrefix(line[i:], s.closing) {
+				s.state = stateCode
+				s.stmt.WriteString(s.closing)
+				i += len(s.closing) - 1
+				continue
+			}
+		}
+		s.stmt.WriteByte(c)
+	}
+	return nil
+}
+
+// block tracks the BEGIN ... END blocks of a routine body from its words, so
+// that the semicolons of its statements do not end it. CASE is closed by END
+// too; END IF, END LOOP, END WHILE, END REPEAT and END FOR close blocks that
+// are not counted.
+func (s *scriptScanner) block(word, rest string) {
+	switch {
+	case strings.EqualFold(word, "begin"):
+		if s.depth > 0 || routineHeader.MatchString(leadingComments.ReplaceAllString(s.stmt.String(), "")) {
+			s.depth++
+		}
+	case strings.EqualFold(word, "case"):
+		if s.depth > 0 {
+			s.depth++
+		}
+	case strings.EqualFold(word, "end"):
+		if s.depth > 0 && !loopEnd.MatchString(rest) {
+			s.depth--
+		}
+	}
+}
+
+// loopEnd matches the keyword after END that closes a control flow statement
+// rather than a block.
+var loopEnd = regexp.MustCompile(`(?i)^\s+(?:if|loop|while|repeat|for)\b`)
+
+// isWordStart reports whether an identifier or keyword starts at line[i].
+func isWordStart(line string, i int) bool {
+	c := line[i]
+	isLetter := c == '_' || (c|0x20 >= 'a' && c|0x20 <= 'z')
+	return isLetter && (i == 0 || !isWordChar(line[i-1]) && line[i-1] != '$')
+}
+
+// isWordChar reports whether c can be part of an identifier or keyword.
+func isWordChar(c byte) bool {
+	return c == '_' || c == '$' || (c|0x20 >= 'a' && c|0x20 <= 'z') || (c >= '0' && c <= '9')
+}
+
+// lineComment records a -- comment running to the end of the line.
+func (s *scriptScanner) lineComment(text string) {
+	s.addComment(strings.TrimPrefix(text, "--"), s.line, false)
+}
+
+func (s *scriptScanner) addComment(text string, line int, block bool) {
+	if !s.keepComments {
+		return
+	}
+	if text = strings.TrimSpace(text); text != "" {
+		s.comments = append(s.comments, Comment{Text: text, Line: line, Block: block})
+	}
+}
+
+// flush passes the current statement to fn, unless it has no code.
+func (s *scriptScanner) flush() error {
+	stmt := strings.TrimSpace(s.stmt.String())
+	hasCode := s.hasCode
+	s.stmt.Reset()
+	s.hasCode = false
+	s.state = stateCode
+	s.depth = 0
+	if !hasCode || stmt == "" {
+		return nil
+	}
+	s.copyData = copyFromStdin.MatchString(leadingComments.ReplaceAllString(stmt, ""))
+	return s.fn(stmt)
+}
+
+var quoteOf = map[scriptState]byte{
+	stateSingleQuote: '\'',
+	stateDoubleQuote: '"',
+	stateBacktick:    '`',
+}
+
+// templateClose maps the second character of a template tag opening to that
+// of its closing: {{ }}, {% %} and {# #}.
+var templateClose = map[byte]byte{'{': '}', '%': '%', '#': '#'}
+
+// dollarTag returns the PostgreSQL dollar-quote tag ($$ or $tag$) s starts
+// with, or "" if it starts with none, e.g. a $1 parameter.
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '$' {
+			return s[:i+1]
+		}
+		isLetter := c == '_' || (c|0x20 >= 'a' && c|0x20 <= 'z')
+		if !isLetter && (i == 1 || c < '0' || c > '9') {
+			return ""
+		}
+	}
+	return ""
+}
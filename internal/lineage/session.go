@@ -0,0 +1,354 @@
+package lineage
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go-metadata/internal/lineage/ast"
+)
+
+var (
+	// leadingComments matches the comments and blanks a statement starts with.
+	leadingComments = regexp.MustCompile(`^(?:\s+|--[^\n]*(?:\n|$)|(?s:/\*.*?\*/))*`)
+	// createTemp matches the creation of a session-scoped table or view:
+	// CREATE TEMPORARY TABLE (PostgreSQL, MySQL, Hive), CREATE TEMP VIEW
+	// (Spark) or CREATE VOLATILE TABLE (Teradata). The second group is the
+	// name.
+	createTemp = regexp.MustCompile(`(?is)^create\s+(?:or\s+replace\s+)?(?:global\s+|local\s+)?(?:temp|temporary|volatile)\s+(table|view)\s+(?:if\s+not\s+exists\s+)?([^\s(]+)`)
+	// dropTable matches DROP TABLE and DROP VIEW.
+	dropTable = regexp.MustCompile(`(?is)^drop\s+(?:temporary\s+)?(?:table|view)\s+(?:if\s+exists\s+)?([^\s;]+)`)
+	// useDatabase matches USE database.
+	useDatabase = regexp.MustCompile(`(?is)^use\s+([^\s;]+)\s*$`)
+	// searchPath matches the PostgreSQL SET search_path, whose first schema
+	// is where unqualified tables are looked up.
+	searchPath = regexp.MustCompile(`(?is)^set\s+(?:session\s+)?search_path\s*(?:=|to)\s*([^\s,;]+)`)
+	// setVariable matches the Hive and Spark SET [hivevar:|hiveconf:]name=value.
+	setVariable = regexp.MustCompile(`(?is)^set\s+(?:(?:hivevar|hiveconf):)?([\w.]+)\s*=(.*)$`)
+	// variableRef matches a ${name}, ${hivevar:name} or ${hiveconf:name}
+	// reference to a variable.
+	variableRef = regexp.MustCompile(`\$\{(?:(?:hivevar|hiveconf):)?([\w.]+)\}`)
+)
+
+// AnalyzeScript analyzes a SQL script, such as an Airflow task file, and
+// returns the merged lineage of its statements. State the statements share
+// through the session is followed from one statement to the next:
+//
+//   - the lineage of temporary tables and views is traced through to the
+//     columns they were filled from, and the temporary tables themselves are
+//     left out of the result;
+//   - tables created by the script resolve against the columns it created
+//     them with;
+//   - USE database and SET search_path set where unqualified tables are
+//     looked up in the catalog;
+//   - ${name} references to Hive variables are replaced with the values SET
+//     before them.
+//
+// The statements in the bodies of stored procedures and functions are
+// analyzed as part of the script, following their variables and cursors.
+//
+// Statements the parser does not support are skipped and
+// listed in the result; the script fails with ErrUnsupportedSQL only if none
+// of its statements could be analyzed.
+func (a *Analyzer) AnalyzeScript(script string) (*LineageResult, error) {
+	return a.analyzeScript(SplitStatements(script))
+}
+
+func (a *Analyzer) analyzeScript(stmts []string) (*LineageResult, error) {
+	s := &scriptSession{
+		base:      a.catalog,
+		variables: make(map[string]string),
+		temps:     make(map[string]map[string]ColumnLineage),
+		created:   make(map[string]*TableSchema),
+		merged:    &LineageResult{Columns: make([]ColumnLineage, 0)},
+		targets:   make(map[string]int),
+	}
+	// Without a catalog tables are not looked up, as for single statements
+	var catalog Catalog
+	if a.catalog != nil {
+		catalog = s
+	}
+
+	analyzed := 0
+	for i, stmt := range stmts {
+		ok, err := s.statement(a, stmt, catalog)
+		if errors.Is(err, ErrUnsupportedSQL) {
+			s.merged.Skipped = append(s.merged.Skipped, SkippedStatement{Statement: i + 1, Reason: err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("statement %d: %w", i+1, err)
+		}
+		if ok {
+			analyzed++
+		}
+	}
+	if analyzed == 0 && len(s.merged.Skipped) > 0 {
+		return nil, ErrUnsupportedSQL
+	}
+	return s.merged, nil
+}
+
+// statement applies a statement of the script to the session and reports
+// whether it had lineage to analyze: USE and SET statements and temporary
+// tables created without a query have none.
+func (s *scriptSession) statement(a *Analyzer, stmt string, catalog Catalog) (bool, error) {
+	stmt = s.substitute(stmt)
+	body := leadingComments.ReplaceAllString(stmt, "")
+	if s.command(body) {
+		return false, nil
+	}
+	if routineHeader.MatchString(body) {
+		return s.routine(a, body, catalog)
+	}
+	if m := dropTable.FindStringSubmatch(body); m != nil {
+		s.drop(m[1])
+	}
+	temp := ""
+	if m := createTemp.FindStringSubmatchIndex(body); m != nil {
+		// Analyzed as CREATE TABLE name ..., which the parser supports
+		temp = tableKey(body[m[4]:m[5]])
+		s.temps[temp] = make(map[string]ColumnLineage)
+		stmt = stmt[:len(stmt)-len(body)] + "CREATE TABLE " + body[m[4]:]
+	}
+
+	result, parsed, err := a.analyze(stmt, catalog)
+	if errors.Is(err, ErrUnsupportedSQL) && temp != "" {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	s.add(result, parsed, temp)
+	return true, nil
+}
+
+// scriptSession is the state a script builds up across its statements.
+type scriptSession struct {
+	base      Catalog
+	database  string // set by USE or SET search_path
+	variables map[string]string
+	// temps holds the lineage of the columns of the temporary tables, by
+	// lower-case table and column name, traced to non-temporary tables
+	temps map[string]map[string]ColumnLineage
+	// created holds the tables created by the script, by lower-case name
+	created map[string]*TableSchema
+
+	merged  *LineageResult
+	targets map[string]int // target column -> index in merged.Columns
+}
+
+// GetTableSchema resolves tables against those created by the script, then
+// against the catalog in the database of the session.
+func (s *scriptSession) GetTableSchema(db, table string) (*TableSchema, error) {
+	if schema, ok := s.created[tableKey(table)]; ok && (db == "" || strings.EqualFold(db, schema.Database)) {
+		return schema, nil
+	}
+	if db == "" && s.database != "" {
+		if schema, err := s.base.GetTableSchema(s.database, table); err == nil {
+			return schema, nil
+		}
+	}
+	return s.base.GetTableSchema(db, table)
+}
+
+// substitute replaces the references to the variables set so far.
+func (s *scriptSession) substitute(stmt string) string {
+	if len(s.variables) == 0 {
+		return stmt
+	}
+	return variableRef.ReplaceAllStringFunc(stmt, func(ref string) string {
+		if value, ok := s.variables[variableRef.FindStringSubmatch(ref)[1]]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// command applies a USE or SET statement to the session and reports whether
+// the statement was one.
+func (s *scriptSession) command(stmt string) bool {
+	if m := useDatabase.FindStringSubmatch(stmt); m != nil {
+		s.database = unquoteIdentifier(m[1])
+		return true
+	}
+	if m := searchPath.FindStringSubmatch(stmt); m != nil {
+		s.database = unquoteIdentifier(strings.Trim(m[1], "'"))
+		return true
+	}
+	if m := setVariable.FindStringSubmatch(stmt); m != nil {
+		s.variables[m[1]] = strings.TrimSpace(m[2])
+		return true
+	}
+	// Other SET statements, e.g. SET @x = 1 or SET NOCOUNT ON, have no lineage
+	return len(stmt) > 4 && strings.EqualFold(stmt[:4], "set ")
+}
+
+// drop forgets a table dropped by the script.
+func (s *scriptSession) drop(name string) {
+	key := tableKey(name)
+	delete(s.temps, key)
+	delete(s.created, key)
+}
+
+// add merges the lineage of a statement into the script result. temp is the
+// table the statement creates if it is temporary.
+func (s *scriptSession) add(result *LineageResult, stmt ast.Statement, temp string) {
+	var created *TableSchema
+	if ctas, ok := stmt.(*ast.CreateTableAsStmt); ok && ctas.Table != nil {
+		created = &TableSchema{Database: ctas.Table.Database, Table: ctas.Table.Table}
+		if created.Database == "" && temp == "" {
+			created.Database = s.database
+		}
+	}
+
+	for _, col := range result.Columns {
+		col = s.trace(col)
+		if created != nil && strings.EqualFold(col.Target.Table, created.Table) {
+			created.Columns = append(created.Columns, col.Target.Column)
+		}
+		if columns, ok := s.temps[tableKey(col.Target.Table)]; ok {
+			// Written into a temporary table, e.g. by INSERT INTO tmp
+			key := strings.ToLower(col.Target.Column)
+			columns[key] = mergeColumnLineage(columns[key], col)
+			continue
+		}
+		s.addColumn(col)
+	}
+	if created != nil {
+		s.created[tableKey(created.Table)] = created
+	}
+
+	for _, ref := range result.Unresolved {
+		if !s.isTemp(ref.Table) {
+			if ref.Database == "" && ref.Table != "" && ref.Column == "" {
+				ref.Database = s.database
+			}
+			s.merged.Unresolved = appendUnresolved(s.merged.Unresolved, ref)
+		}
+	}
+	for _, u := range result.Usages {
+		if !s.isTemp(u.Column.Table) {
+			s.merged.Usages = append(s.merged.Usages, u)
+		}
+	}
+	for _, k := range result.JoinKeys {
+		if !s.isTemp(k.Left.Table) && !s.isTemp(k.Right.Table) {
+			s.merged.JoinKeys = append(s.merged.JoinKeys, k)
+		}
+	}
+	for _, scan := range result.Scans {
+		if !s.isTemp(scan.Table) {
+			if scan.Database == "" {
+				scan.Database = s.database
+			}
+			s.merged.Scans = append(s.merged.Scans, scan)
+		}
+	}
+
+	s.merged.Loads = append(s.merged.Loads, result.Loads...)
+	s.merged.Comments = append(s.merged.Comments, result.Comments...)
+	for k, v := range result.Annotations {
+		if s.merged.Annotations == nil {
+			s.merged.Annotations = make(map[string]string)
+		}
+		if _, ok := s.merged.Annotations[k]; !ok {
+			s.merged.Annotations[k] = v
+		}
+	}
+	if s.merged.Dataset == nil {
+		s.merged.Dataset = result.Dataset
+	}
+}
+
+// trace replaces the sources of a column read from temporary tables with the
+// sources of their columns.
+func (s *scriptSession) trace(col ColumnLineage) ColumnLineage {
+	traced := ColumnLineage{Target: col.Target, Sources: make([]ColumnRef, 0, len(col.Sources)), Operators: col.Operators}
+	if _, ok := s.temps[routineVariables]; ok {
+		// Variables read as written in the routine
+		traced.Operators = make([]string, len(col.Operators))
+		for i, op := range col.Operators {
+			traced.Operators[i] = strings.ReplaceAll(op, routineVariables+".", "")
+		}
+	}
+	for _, src := range col.Sources {
+		if temp, ok := s.temps[tableKey(src.Table)]; ok && src.Database == "" {
+			if l, ok := temp[strings.ToLower(src.Column)]; ok {
+				for _, ref := range l.Sources {
+					if !containsRef(traced.Sources, ref) {
+						traced.Sources = append(traced.Sources, ref)
+					}
+				}
+				traced.Operators = appendUnique(traced.Operators, l.Operators...)
+				continue
+			}
+		}
+		if !containsRef(traced.Sources, src) {
+			traced.Sources = append(traced.Sources, src)
+		}
+	}
+	return traced
+}
+
+// addColumn adds the lineage of a column to the result, merged with that of
+// the same column written by earlier statements.
+func (s *scriptSession) addColumn(col ColumnLineage) {
+	if col.Target.Table == "" {
+		// Query results are not merged
+		s.merged.Columns = append(s.merged.Columns, col)
+		return
+	}
+	key := strings.ToLower(col.Target.Database + "." + col.Target.Table + "." + col.Target.Column)
+	if i, ok := s.targets[key]; ok {
+		s.merged.Columns[i] = mergeColumnLineage(s.merged.Columns[i], col)
+		return
+	}
+	s.targets[key] = len(s.merged.Columns)
+	s.merged.Columns = append(s.merged.Columns, col)
+}
+
+// isTemp reports whether table is a temporary table of the script.
+func (s *scriptSession) isTemp(table string) bool {
+	_, ok := s.temps[tableKey(table)]
+	return ok && table != ""
+}
+
+// mergeColumnLineage adds the sources and operators of col to those of l.
+func mergeColumnLineage(l, col ColumnLineage) ColumnLineage {
+	if l.Target.Column == "" {
+		return col
+	}
+	for _, ref := range col.Sources {
+		if !containsRef(l.Sources, ref) {
+			l.Sources = append(l.Sources, ref)
+		}
+	}
+	l.Operators = appendUnique(l.Operators, col.Operators...)
+	return l
+}
+
+// appendUnresolved appends ref to refs unless it is in them.
+func appendUnresolved(refs []UnresolvedRef, ref UnresolvedRef) []UnresolvedRef {
+	for _, r := range refs {
+		if r == ref {
+			return refs
+		}
+	}
+	return append(refs, ref)
+}
+
+// tableKey returns the lower-case unqualified name of a table.
+func tableKey(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.ToLower(unquoteIdentifier(name))
+}
+
+// unquoteIdentifier removes the quotes, backticks or brackets around an
+// identifier.
+func unquoteIdentifier(name string) string {
+	return strings.Trim(name, "`\"[]")
+}
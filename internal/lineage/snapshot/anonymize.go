@@ -0,0 +1,170 @@
+package snapshot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/metadata"
+)
+
+// Name kinds of pseudonyms, used as their prefixes so that anonymized
+// snapshots stay readable.
+const (
+	nameDatabase = "db"
+	nameSchema   = "s"
+	nameTable    = "t"
+	nameColumn   = "c"
+	nameJob      = "job"
+)
+
+// Anonymizer pseudonymizes the names of an exported snapshot so that it can
+// be shared for debugging or benchmarking without exposing the schema. Each
+// database, schema, table, column and job name is replaced by a keyed hash
+// of the name, e.g. t_5c1f0e9a24b7, so the same name maps to the same
+// pseudonym everywhere and the structure of the tables, their keys and the
+// lineage topology are preserved. Comments, descriptions, default
+// expressions, properties, annotations, owners, tags and edge operators,
+// which may quote names or business terms, are stripped.
+type Anonymizer struct {
+	key []byte
+}
+
+// NewAnonymizer creates an anonymizer keyed by salt. Snapshots anonymized
+// with the same salt use the same pseudonyms; the salt must be kept secret,
+// since names can otherwise be recovered by hashing guesses.
+func NewAnonymizer(salt string) *Anonymizer {
+	return &Anonymizer{key: []byte(salt)}
+}
+
+// name returns the pseudonym of a name of the given kind. Names are SQL
+// identifiers, so the pseudonym does not depend on their case.
+func (a *Anonymizer) name(kind, name string) string {
+	if name == "" {
+		return ""
+	}
+	return kind + "_" + a.hash(kind + ":" + strings.ToLower(name))[:12]
+}
+
+func (a *Anonymizer) hash(s string) string {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// tableName returns the pseudonym of a qualified db.table or
+// db.schema.table name.
+func (a *Anonymizer) tableName(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		switch {
+		case i == len(parts)-1:
+			parts[i] = a.name(nameTable, p)
+		case i == 0:
+			parts[i] = a.name(nameDatabase, p)
+		default:
+			parts[i] = a.name(nameSchema, p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func (a *Anonymizer) names(kind string, names []string) []string {
+	if names == nil {
+		return nil
+	}
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = a.name(kind, n)
+	}
+	return out
+}
+
+func (a *Anonymizer) tableNames(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = a.tableName(n)
+	}
+	return out
+}
+
+// fingerprints rehashes statement fingerprints, which are hashes of the SQL
+// and could be matched against known statements.
+func (a *Anonymizer) fingerprints(fps []string) []string {
+	if fps == nil {
+		return nil
+	}
+	out := make([]string, len(fps))
+	for i, fp := range fps {
+		out[i] = a.hash("fp:" + fp)[:16]
+	}
+	return out
+}
+
+// Table returns an anonymized copy of t.
+func (a *Anonymizer) Table(t *metadata.TableSchema) *metadata.TableSchema {
+	c := &metadata.TableSchema{
+		Database:   a.name(nameDatabase, t.Database),
+		Schema:     a.name(nameSchema, t.Schema),
+		Table:      a.name(nameTable, t.Table),
+		Columns:    make([]metadata.ColumnSchema, len(t.Columns)),
+		PrimaryKey: a.names(nameColumn, t.PrimaryKey),
+		TableType:  t.TableType,
+	}
+	for i, col := range t.Columns {
+		c.Columns[i] = metadata.ColumnSchema{
+			Name:       a.name(nameColumn, col.Name),
+			DataType:   col.DataType,
+			Nullable:   col.Nullable,
+			PrimaryKey: col.PrimaryKey,
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		c.ForeignKeys = append(c.ForeignKeys, metadata.ForeignKey{
+			Columns:            a.names(nameColumn, fk.Columns),
+			ReferencedDatabase: a.name(nameDatabase, fk.ReferencedDatabase),
+			ReferencedTable:    a.name(nameTable, fk.ReferencedTable),
+			ReferencedColumns:  a.names(nameColumn, fk.ReferencedColumns),
+		})
+	}
+	return c
+}
+
+// Edge returns an anonymized copy of e.
+func (a *Anonymizer) Edge(e *lineage.Edge) *lineage.Edge {
+	c := &lineage.Edge{
+		Source:     a.columnRef(e.Source),
+		Target:     a.columnRef(e.Target),
+		Provenance: e.Provenance,
+		Validity:   e.Validity,
+	}
+	c.Provenance.Fingerprints = a.fingerprints(e.Provenance.Fingerprints)
+	c.Provenance.Jobs = a.names(nameJob, e.Provenance.Jobs)
+	return c
+}
+
+func (a *Anonymizer) columnRef(r lineage.ColumnRef) lineage.ColumnRef {
+	return lineage.ColumnRef{
+		Database:   a.name(nameDatabase, r.Database),
+		Table:      a.name(nameTable, r.Table),
+		Column:     a.name(nameColumn, r.Column),
+		Confidence: r.Confidence,
+	}
+}
+
+// Job returns an anonymized copy of j.
+func (a *Anonymizer) Job(j *lineage.Job) *lineage.Job {
+	return &lineage.Job{
+		Name:       a.name(nameJob, j.Name),
+		Type:       j.Type,
+		Inputs:     a.tableNames(j.Inputs),
+		Outputs:    a.tableNames(j.Outputs),
+		Statements: a.fingerprints(j.Statements),
+		LastRunAt:  j.LastRunAt,
+	}
+}
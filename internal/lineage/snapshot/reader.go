@@ -0,0 +1,131 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/metadata"
+)
+
+// Snapshot is the verified content of a snapshot archive.
+type Snapshot struct {
+	Manifest *Manifest
+	Tables   []*metadata.TableSchema
+	Edges    []*lineage.Edge
+	Jobs     []*lineage.Job
+}
+
+// Read reads a snapshot archive, verifying its format version and the
+// checksum and entity count of every file listed in the manifest.
+func Read(r io.Reader) (*Snapshot, error) {
+	tr := tar.NewReader(r)
+
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if hdr.Name != manifestFile {
+		return nil, fmt.Errorf("%w: expected %s, found %s", ErrInvalidSnapshot, manifestFile, hdr.Name)
+	}
+	manifest := &Manifest{}
+	if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("%w: decode manifest: %v", ErrInvalidSnapshot, err)
+	}
+	if manifest.Format != Format {
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidSnapshot, manifest.Format)
+	}
+	if manifest.Version < 1 || manifest.Version > Version {
+		return nil, fmt.Errorf("%w: %d (supported up to %d)", ErrUnsupportedVersion, manifest.Version, Version)
+	}
+
+	files := make(map[string][]byte)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: read %s: %v", ErrInvalidSnapshot, hdr.Name, err)
+		}
+		files[hdr.Name] = data
+	}
+
+	snap := &Snapshot{Manifest: manifest}
+	for _, entry := range manifest.Entries {
+		data, ok := files[entry.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w: missing %s", ErrInvalidSnapshot, entry.Name)
+		}
+		if checksum(data) != entry.SHA256 {
+			return nil, fmt.Errorf("%w: %s", ErrChecksumMismatch, entry.Name)
+		}
+
+		var count int
+		switch entry.Kind {
+		case KindTable:
+			snap.Tables, err = decodeLines[metadata.TableSchema](data)
+			count = len(snap.Tables)
+		case KindEdge:
+			snap.Edges, err = decodeLines[lineage.Edge](data)
+			count = len(snap.Edges)
+		case KindJob:
+			snap.Jobs, err = decodeLines[lineage.Job](data)
+			count = len(snap.Jobs)
+		default:
+			// Entities of kinds added by later minor revisions are skipped.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: decode %s: %v", ErrInvalidSnapshot, entry.Name, err)
+		}
+		if count != entry.Count {
+			return nil, fmt.Errorf("%w: %s has %d entities, manifest lists %d", ErrChecksumMismatch, entry.Name, count, entry.Count)
+		}
+	}
+	return snap, nil
+}
+
+func decodeLines[T any](data []byte) ([]*T, error) {
+	var items []*T
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		item := new(T)
+		if err := json.Unmarshal(line, item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+// Apply imports the snapshot: its tables are added to provider, replacing
+// tables with the same name, and its edges and jobs are merged into g. Either
+// may be nil to import only the catalog or only the lineage.
+func (s *Snapshot) Apply(provider *metadata.MemoryProvider, g *lineage.Graph) error {
+	if provider != nil {
+		for _, t := range s.Tables {
+			if err := provider.AddTableSchema(t); err != nil {
+				return fmt.Errorf("import table %s.%s: %w", t.Database, t.Table, err)
+			}
+		}
+	}
+	if g != nil {
+		g.Import(s.Edges, s.Jobs)
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+// Package snapshot implements a versioned bundle format for moving a catalog
+// and lineage graph between deployments.
+//
+// A snapshot is a tar archive holding a manifest.json followed by one JSON
+// Lines file per entity kind (tables.jsonl, edges.jsonl, jobs.jsonl). The
+// manifest records the format version, the databases the snapshot was limited
+// to, and the entity count and SHA-256 checksum of every file, which are
+// verified on import.
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/metadata"
+)
+
+const (
+	// Format identifies snapshot archives.
+	Format = "go-metadata-snapshot"
+	// Version is the format version written by Export. Read accepts
+	// snapshots up to this version.
+	Version = 1
+
+	manifestFile = "manifest.json"
+)
+
+// Entity kinds and the files holding them.
+const (
+	KindTable = "table"
+	KindEdge  = "edge"
+	KindJob   = "job"
+)
+
+var entityFiles = map[string]string{
+	KindTable: "tables.jsonl",
+	KindEdge:  "edges.jsonl",
+	KindJob:   "jobs.jsonl",
+}
+
+var (
+	// ErrInvalidSnapshot is returned when an archive is not a readable snapshot.
+	ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+	// ErrUnsupportedVersion is returned when a snapshot was written by a newer
+	// format version.
+	ErrUnsupportedVersion = errors.New("unsupported snapshot version")
+
+	// ErrChecksumMismatch is returned when an entity file does not match the
+	// checksum or count recorded in the manifest.
+	ErrChecksumMismatch = errors.New("snapshot checksum mismatch")
+)
+
+// Manifest describes the contents of a snapshot.
+type Manifest struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// Origin names the deployment the snapshot was exported from.
+	Origin string `json:"origin,omitempty"`
+	// Databases lists the databases a partial snapshot is limited to; empty
+	// for a full snapshot.
+	Databases []string `json:"databases,omitempty"`
+	// Anonymized is set for snapshots whose names were pseudonymized.
+	Anonymized bool    `json:"anonymized,omitempty"`
+	Entries    []Entry `json:"entries"`
+}
+
+// Entry describes an entity file of a snapshot.
+type Entry struct {
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Count  int    `json:"count"`
+	SHA256 string `json:"sha256"`
+}
+
+// Partial reports whether the snapshot is limited to some databases.
+func (m *Manifest) Partial() bool {
+	return len(m.Databases) > 0
+}
+
+// Entry returns the entry of the given kind, or nil if there is none.
+func (m *Manifest) Entry(kind string) *Entry {
+	for i := range m.Entries {
+		if m.Entries[i].Kind == kind {
+			return &m.Entries[i]
+		}
+	}
+	return nil
+}
+
+// Options configures an export.
+type Options struct {
+	// Origin names the exporting deployment.
+	Origin string
+	// Databases limits the export to tables of these databases, lineage edges
+	// touching them and jobs reading or writing them. Empty exports everything.
+	Databases []string
+	// CreatedAt defaults to now.
+	CreatedAt time.Time
+	// Anonymize pseudonymizes the names of the exported entities and strips
+	// their comments, if set.
+	Anonymize *Anonymizer
+}
+
+// Export writes the tables and the edges and jobs of g as a snapshot to w and
+// returns its manifest.
+func Export(w io.Writer, tables []*metadata.TableSchema, g *lineage.Graph, opts Options) (*Manifest, error) {
+	filter := newDatabaseFilter(opts.Databases)
+
+	anon := opts.Anonymize
+
+	var selectedTables []any
+	for _, t := range tables {
+		if filter.matchDatabase(t.Database) {
+			if anon != nil {
+				t = anon.Table(t)
+			}
+			selectedTables = append(selectedTables, t)
+		}
+	}
+	var edges, jobs []any
+	if g != nil {
+		for _, e := range g.Edges() {
+			if filter.matchTable(e.Source.TableName()) || filter.matchTable(e.Target.TableName()) {
+				if anon != nil {
+					e = anon.Edge(e)
+				}
+				edges = append(edges, e)
+			}
+		}
+		for _, j := range g.Jobs() {
+			if filter.matchAny(j.Inputs) || filter.matchAny(j.Outputs) {
+				if anon != nil {
+					j = anon.Job(j)
+				}
+				jobs = append(jobs, j)
+			}
+		}
+	}
+
+	manifest := &Manifest{
+		Format:    Format,
+		Version:   Version,
+		CreatedAt: opts.CreatedAt,
+		Origin:    opts.Origin,
+		Databases: opts.Databases,
+	}
+	if anon != nil {
+		manifest.Anonymized = true
+		manifest.Databases = anon.names(nameDatabase, opts.Databases)
+		// Sorted by pseudonym, the order does not reveal the original names
+		sortEntities(selectedTables, func(v any) string {
+			t := v.(*metadata.TableSchema)
+			return t.Database + "." + t.Schema + "." + t.Table
+		})
+		sortEntities(edges, func(v any) string { return v.(*lineage.Edge).Key() })
+		sortEntities(jobs, func(v any) string { return v.(*lineage.Job).Name })
+	}
+	if manifest.CreatedAt.IsZero() {
+		manifest.CreatedAt = time.Now().UTC()
+	}
+
+	entities := []struct {
+		kind  string
+		items []any
+	}{
+		{KindTable, selectedTables},
+		{KindEdge, edges},
+		{KindJob, jobs},
+	}
+	files := make(map[string][]byte)
+	for _, entity := range entities {
+		data, err := encodeLines(entity.items)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s entities: %w", entity.kind, err)
+		}
+		name := entityFiles[entity.kind]
+		files[name] = data
+		manifest.Entries = append(manifest.Entries, Entry{
+			Name:   name,
+			Kind:   entity.kind,
+			Count:  len(entity.items),
+			SHA256: checksum(data),
+		})
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode manifest: %w", err)
+	}
+
+	// The manifest goes first so readers can reject unsupported versions
+	// before reading the entities.
+	tw := tar.NewWriter(w)
+	if err := writeFile(tw, manifestFile, manifestData, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, entry := range manifest.Entries {
+		if err := writeFile(tw, entry.Name, files[entry.Name], manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("write snapshot: %w", err)
+	}
+	return manifest, nil
+}
+
+func sortEntities(items []any, key func(any) string) {
+	sort.SliceStable(items, func(i, j int) bool { return key(items[i]) < key(items[j]) })
+}
+
+func encodeLines(items []any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func writeFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+		Format:  tar.FormatPAX,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// databaseFilter matches tables by database; an empty filter matches everything.
+type databaseFilter map[string]bool
+
+func newDatabaseFilter(databases []string) databaseFilter {
+	if len(databases) == 0 {
+		return nil
+	}
+	f := make(databaseFilter, len(databases))
+	for _, db := range databases {
+		f[strings.ToLower(db)] = true
+	}
+	return f
+}
+
+func (f databaseFilter) matchDatabase(database string) bool {
+	return f == nil || f[strings.ToLower(database)]
+}
+
+// matchTable matches a qualified table name (db.table).
+func (f databaseFilter) matchTable(name string) bool {
+	if f == nil {
+		return true
+	}
+	database, _, ok := strings.Cut(name, ".")
+	return ok && f[strings.ToLower(database)]
+}
+
+func (f databaseFilter) matchAny(names []string) bool {
+	for _, name := range names {
+		if f.matchTable(name) {
+			return true
+		}
+	}
+	return f == nil
+}
@@ -0,0 +1,249 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/metadata"
+)
+
+func testCatalog() ([]*metadata.TableSchema, *lineage.Graph) {
+	tables := []*metadata.TableSchema{
+		{Database: "ods", Table: "orders", Columns: []metadata.ColumnSchema{{Name: "id", DataType: "BIGINT"}, {Name: "amount", DataType: "DECIMAL(10,2)", Annotations: map[string]string{"dq.check": "enabled"}}},
+			Annotations: map[string]string{"finance.coa": "4010"}},
+		{Database: "dw", Table: "fact_orders", Columns: []metadata.ColumnSchema{{Name: "id"}, {Name: "amount"}}},
+		{Database: "crm", Table: "users", Columns: []metadata.ColumnSchema{{Name: "id"}}},
+	}
+
+	at := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	g := lineage.NewGraph()
+	g.RegisterJob(&lineage.Job{Name: "load_orders", Type: lineage.JobTypeSQLScript})
+	g.AttachStatement("load_orders", &lineage.LineageResult{Columns: []lineage.ColumnLineage{
+		{
+			Target:    lineage.ColumnRef{Database: "dw", Table: "fact_orders", Column: "amount"},
+			Sources:   []lineage.ColumnRef{{Database: "ods", Table: "orders", Column: "amount"}},
+			Operators: []string{"o.amount"},
+		},
+	}}, "fp1", at)
+	g.Add(&lineage.LineageResult{Columns: []lineage.ColumnLineage{
+		{
+			Target:  lineage.ColumnRef{Database: "crm", Table: "user_stats", Column: "id"},
+			Sources: []lineage.ColumnRef{{Database: "crm", Table: "users", Column: "id"}},
+		},
+	}}, "fp2", at)
+	return tables, g
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	tables, g := testCatalog()
+
+	var buf bytes.Buffer
+	manifest, err := Export(&buf, tables, g, Options{Origin: "prod"})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if manifest.Partial() {
+		t.Error("Expected full snapshot")
+	}
+	if e := manifest.Entry(KindEdge); e == nil || e.Count != 2 {
+		t.Errorf("Expected 2 edges in manifest, got %+v", e)
+	}
+
+	snap, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if snap.Manifest.Origin != "prod" || snap.Manifest.Version != Version {
+		t.Errorf("Unexpected manifest: %+v", snap.Manifest)
+	}
+
+	provider := metadata.NewMemoryProvider()
+	imported := lineage.NewGraph()
+	if err := snap.Apply(provider, imported); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	if len(provider.AllTables()) != 3 {
+		t.Errorf("Expected 3 tables, got %d", len(provider.AllTables()))
+	}
+	orders, err := provider.GetTableSchema("ods", "orders")
+	if err != nil || orders.GetColumn("amount").DataType != "DECIMAL(10,2)" {
+		t.Errorf("Expected ods.orders with column types, got %+v (%v)", orders, err)
+	}
+	if orders.Annotations["finance.coa"] != "4010" || orders.GetColumn("amount").Annotations["dq.check"] != "enabled" {
+		t.Errorf("Expected annotations to be exported, got %+v", orders)
+	}
+
+	edges := imported.Edges()
+	if len(edges) != 2 {
+		t.Fatalf("Expected 2 edges, got %d", len(edges))
+	}
+	original := g.Edges()
+	for i := range edges {
+		if edges[i].Key() != original[i].Key() || edges[i].Provenance.Occurrences != original[i].Provenance.Occurrences {
+			t.Errorf("Edge %d differs: %+v vs %+v", i, edges[i], original[i])
+		}
+		if len(edges[i].Validity) != len(original[i].Validity) {
+			t.Errorf("Edge %d lost validity intervals", i)
+		}
+	}
+	job, err := imported.Job("load_orders")
+	if err != nil {
+		t.Fatalf("Expected job to be imported: %v", err)
+	}
+	if len(job.Inputs) != 1 || job.Inputs[0] != "ods.orders" {
+		t.Errorf("Expected job input ods.orders, got %v", job.Inputs)
+	}
+}
+
+func TestExportPartial(t *testing.T) {
+	tables, g := testCatalog()
+
+	var buf bytes.Buffer
+	manifest, err := Export(&buf, tables, g, Options{Databases: []string{"DW"}})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !manifest.Partial() {
+		t.Error("Expected partial snapshot")
+	}
+
+	snap, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(snap.Tables) != 1 || snap.Tables[0].Table != "fact_orders" {
+		t.Errorf("Expected only dw.fact_orders, got %v", snap.Tables)
+	}
+	// The edge into dw is kept with its upstream endpoint.
+	if len(snap.Edges) != 1 || snap.Edges[0].Source.TableName() != "ods.orders" {
+		t.Errorf("Expected the ods.orders -> dw.fact_orders edge, got %v", snap.Edges)
+	}
+	if len(snap.Jobs) != 1 {
+		t.Errorf("Expected the job writing dw, got %v", snap.Jobs)
+	}
+}
+
+func TestExportAnonymized(t *testing.T) {
+	tables, g := testCatalog()
+	tables[0].Comment = "Orders of the webshop"
+	tables[0].Columns[1].Comment = "Gross amount in EUR"
+	tables[1].ForeignKeys = []metadata.ForeignKey{{Columns: []string{"id"}, ReferencedDatabase: "ods", ReferencedTable: "orders", ReferencedColumns: []string{"id"}}}
+
+	var buf bytes.Buffer
+	anon := NewAnonymizer("salt")
+	manifest, err := Export(&buf, tables, g, Options{Anonymize: anon})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !manifest.Anonymized {
+		t.Error("Expected an anonymized manifest")
+	}
+	data := buf.String()
+	for _, name := range []string{"orders", "amount", "load_orders", "webshop", "EUR", "finance.coa", "dq.check", "o.amount", "fp1"} {
+		if strings.Contains(data, name) {
+			t.Errorf("Anonymized snapshot contains %q", name)
+		}
+	}
+
+	snap, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	provider := metadata.NewMemoryProvider()
+	imported := lineage.NewGraph()
+	if err := snap.Apply(provider, imported); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	// The structure and the lineage topology are preserved
+	db, table := anon.name(nameDatabase, "ods"), anon.name(nameTable, "orders")
+	orders, err := provider.GetTableSchema(db, table)
+	if err != nil {
+		t.Fatalf("Expected %s.%s: %v", db, table, err)
+	}
+	amount := orders.GetColumn(anon.name(nameColumn, "amount"))
+	if amount == nil || amount.DataType != "DECIMAL(10,2)" || amount.Comment != "" {
+		t.Errorf("Expected the amount column with its type and no comment, got %+v", orders.Columns)
+	}
+	fact, _ := provider.GetTableSchema(anon.name(nameDatabase, "dw"), anon.name(nameTable, "fact_orders"))
+	if fact == nil || len(fact.ForeignKeys) != 1 || fact.ForeignKeys[0].ReferencedTable != table {
+		t.Errorf("Expected the foreign key to reference %s, got %+v", table, fact)
+	}
+
+	edges := imported.Edges()
+	if len(edges) != 2 {
+		t.Fatalf("Expected 2 edges, got %v", edges)
+	}
+	for _, e := range edges {
+		if e.Target.Table == anon.name(nameTable, "fact_orders") && e.Source.TableName() != db+"."+table {
+			t.Errorf("Expected the edge from %s.%s, got %+v", db, table, e)
+		}
+	}
+	jobs := imported.Jobs()
+	if len(jobs) != 1 || jobs[0].Name != anon.name(nameJob, "load_orders") || jobs[0].Inputs[0] != db+"."+table {
+		t.Errorf("Expected the anonymized job reading %s.%s, got %+v", db, table, jobs)
+	}
+
+	// Names map to the same pseudonym regardless of case, and differently
+	// with another salt
+	if anon.name(nameTable, "ORDERS") != table || NewAnonymizer("other").name(nameTable, "orders") == table {
+		t.Error("Expected pseudonyms keyed by the salt and independent of case")
+	}
+}
+
+func TestReadRejectsTampering(t *testing.T) {
+	tables, g := testCatalog()
+	var buf bytes.Buffer
+	if _, err := Export(&buf, tables, g, Options{}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	// Rewrite the archive with a modified tables file.
+	var tampered bytes.Buffer
+	tr := tar.NewReader(&buf)
+	tw := tar.NewWriter(&tampered)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		if hdr.Name == "tables.jsonl" {
+			data = bytes.Replace(data, []byte("BIGINT"), []byte("STRING"), 1)
+			hdr.Size = int64(len(data))
+		}
+		tw.WriteHeader(hdr)
+		tw.Write(data)
+	}
+	tw.Close()
+
+	if _, err := Read(&tampered); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected ErrChecksumMismatch, got %v", err)
+	}
+}
+
+func TestReadRejectsNewerVersion(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	data := []byte(`{"format":"go-metadata-snapshot","version":99,"entries":[]}`)
+	tw.WriteHeader(&tar.Header{Name: "manifest.json", Mode: 0644, Size: int64(len(data))})
+	tw.Write(data)
+	tw.Close()
+
+	if _, err := Read(&buf); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Expected ErrUnsupportedVersion, got %v", err)
+	}
+	if _, err := Read(bytes.NewReader([]byte("not a tar"))); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Errorf("Expected ErrInvalidSnapshot, got %v", err)
+	}
+}
@@ -0,0 +1,451 @@
+package lineage
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Snowflake loads data files into tables with COPY INTO table FROM @stage or
+// FROM an external location, which the grammar does not parse. The handlers
+// registered by RegisterSnowflake give such loads lineage from the location
+// of the files, e.g. s3.lake/orders for s3://lake/orders/, to the columns of
+// the table, and report the file format of each load in the Loads of the
+// result. Stages are resolved to their URL from the CREATE STAGE statements
+// analyzed before, or added with Stages.Add.
+
+// Stage is a Snowflake stage: a named location data files are loaded from.
+type Stage struct {
+	// Name is the name of the stage, possibly qualified with its database
+	// and schema.
+	Name string `json:"name"`
+	// URL is the location of an external stage, e.g. s3://lake/orders/. It
+	// is empty for internal stages.
+	URL        string     `json:"url,omitempty"`
+	FileFormat FileFormat `json:"file_format,omitempty"`
+}
+
+// FileFormat is the format of data files, given by its TYPE and format
+// options or by the name of a named file format.
+type FileFormat struct {
+	Type    string            `json:"type,omitempty"`
+	Name    string            `json:"name,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// FileLoad is a load of data files into a table, such as Snowflake COPY
+// INTO or PostgreSQL COPY FROM.
+type FileLoad struct {
+	Target ColumnRef `json:"target"`
+	// Stage is the stage the files are loaded from, if any, and Location
+	// where the files are: the URL of the stage followed by the path of the
+	// statement, or the external location or file path it names. PostgreSQL
+	// loads from the client are at stdin, and loads from the output of a
+	// command at program:command.
+	Stage    string `json:"stage,omitempty"`
+	Location string `json:"location"`
+	// Pattern is the regular expression the loaded file names match.
+	Pattern string     `json:"pattern,omitempty"`
+	Format  FileFormat `json:"format"`
+}
+
+// Stages are the Snowflake stages known to an analyzer. It is safe for
+// concurrent use.
+type Stages struct {
+	mu     sync.RWMutex
+	stages map[string]*Stage
+}
+
+// NewStages creates an empty set of stages.
+func NewStages() *Stages {
+	return &Stages{stages: make(map[string]*Stage)}
+}
+
+// Add adds or replaces a stage.
+func (s *Stages) Add(stage *Stage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stages[stageKey(stage.Name)] = stage
+}
+
+// Get returns the stage of a name, matched case-insensitively. Qualified
+// names not found as such are looked up by their last part.
+func (s *Stages) Get(name string) (*Stage, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if stage, ok := s.stages[stageKey(name)]; ok {
+		return stage, true
+	}
+	parts := strings.Split(name, ".")
+	stage, ok := s.stages[stageKey(parts[len(parts)-1])]
+	return stage, ok
+}
+
+func stageKey(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = strings.ToLower(unquoteIdentifier(strings.TrimSpace(p)))
+	}
+	return strings.Join(parts, ".")
+}
+
+// RegisterSnowflake registers the handlers of Snowflake CREATE STAGE and
+// COPY INTO table statements, and returns the stages they use. CREATE STAGE
+// adds its stage to stages, or to new stages if it is nil, and has no
+// lineage. COPY INTO a table reads the columns of the table from the files of
+// the stage or location it loads, by name, or by position for loads that
+// transform the files with a query; unloads, COPY INTO a location, are not
+// supported.
+func (a *Analyzer) RegisterSnowflake(stages *Stages) *Stages {
+	if stages == nil {
+		stages = NewStages()
+	}
+	a.RegisterStatement("create stage", isCreateStage, func(sql string, _ Catalog) (*LineageResult, error) {
+		stage, err := parseCreateStage(sql)
+		if err != nil {
+			return nil, err
+		}
+		stages.Add(stage)
+		return &LineageResult{}, nil
+	})
+	a.RegisterStatement("copy into", MatchKeywords("COPY", "INTO"), func(sql string, catalog Catalog) (*LineageResult, error) {
+		return parseCopyInto(sql, catalog, stages)
+	})
+	return stages
+}
+
+// LocationRef returns the dataset reference of a location of data files:
+// s3://lake/orders/ is s3.lake/orders, as object-store datasets appear in
+// the lineage graph, and other URLs are named by their scheme, e.g.
+// gcs.lake/orders. Locations without a scheme are a table of their own.
+func LocationRef(location string) ColumnRef {
+	u, err := url.Parse(strings.TrimSpace(location))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ColumnRef{Table: location}
+	}
+	scheme := strings.ToLower(u.Scheme)
+	switch scheme {
+	case "s3a", "s3n":
+		scheme = "s3"
+	}
+	name := u.Host
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		name += "/" + path
+	}
+	return ColumnRef{Database: scheme, Table: name}
+}
+
+// isCreateStage reports whether sql is CREATE [OR REPLACE] [TEMPORARY] STAGE.
+func isCreateStage(sql string) bool {
+	words := strings.Fields(strings.ToUpper(sql))
+	if len(words) < 2 || words[0] != "CREATE" {
+		return false
+	}
+	for _, w := range words[1:] {
+		switch w {
+		case "OR", "REPLACE", "TEMP", "TEMPORARY":
+		case "STAGE":
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// parseCreateStage parses the name, URL and file format of a CREATE STAGE
+// statement.
+func parseCreateStage(sql string) (*Stage, error) {
+	tokens := tokenizeSpark(sql)
+	i := 0
+	for i < len(tokens) && tokens[i].word() != "STAGE" {
+		i++
+	}
+	i++
+	if i+2 < len(tokens) && tokens[i].word() == "IF" && tokens[i+1].word() == "NOT" && tokens[i+2].word() == "EXISTS" {
+		i += 3
+	}
+	name, next := qualifiedName(sql, tokens, i)
+	if name == "" {
+		return nil, errors.New("missing stage name")
+	}
+	options := stageOptions(sql[tokenEnd(tokens, next-1):])
+	return &Stage{Name: name, URL: options["URL"], FileFormat: parseFileFormat(options["FILE_FORMAT"])}, nil
+}
+
+// stagePath matches the path of a stage reference, @stage/path/.
+var stagePath = regexp.MustCompile(`^@([^/\s]+)(/\S*)?$`)
+
+// positionalRef matches the references of COPY transformations to the fields
+// of the files, $1 or $1:path.
+var positionalRef = regexp.MustCompile(`\$\d+(:[\w."\[\]]+)?`)
+
+// parseCopyInto extracts the lineage of COPY INTO table FROM @stage, FROM
+// 'location' or FROM (SELECT ... FROM @stage).
+func parseCopyInto(sql string, catalog Catalog, stages *Stages) (*LineageResult, error) {
+	tokens := tokenizeSpark(sql)
+	i := 2 // COPY INTO
+	if i >= len(tokens) || tokens[i].text == "@" || strings.HasPrefix(tokens[i].text, "'") {
+		return nil, ErrUnsupportedSQL
+	}
+	name, i := qualifiedName(sql, tokens, i)
+	if name == "" {
+		return nil, errors.New("missing target table")
+	}
+	target := ParseTableRef(name)
+
+	var columns []string
+	if i < len(tokens) && tokens[i].text == "(" {
+		end := closingParen(tokens, i)
+		for _, c := range strings.Split(sql[tokens[i].end:tokens[end].start], ",") {
+			columns = append(columns, unquoteIdentifier(strings.TrimSpace(c)))
+		}
+		i = end + 1
+	}
+	if i >= len(tokens) || tokens[i].word() != "FROM" {
+		return nil, errors.New("missing FROM")
+	}
+	i++
+	if i >= len(tokens) {
+		return nil, errors.New("missing source")
+	}
+
+	// A transformation query reads the files by position
+	var items []string
+	rest := sql[tokens[i].start:]
+	if tokens[i].text == "(" {
+		end := closingParen(tokens, i)
+		query := sql[tokens[i].end:tokens[end].start]
+		selectList, from, ok := cutKeyword(query, "FROM")
+		if !ok {
+			return nil, errors.New("missing FROM in transformation query")
+		}
+		selectList = strings.TrimSpace(selectList)
+		if len(selectList) < len("SELECT") || !strings.EqualFold(selectList[:len("SELECT")], "SELECT") {
+			return nil, errors.New("transformation is not a SELECT")
+		}
+		items = splitTopLevel(selectList[len("SELECT"):])
+		rest = strings.TrimSpace(from) + " " + sql[tokens[end].end:]
+	}
+	source, options := cutLocation(rest)
+	if source == "" {
+		return nil, errors.New("missing source location")
+	}
+	opts := stageOptions(options)
+
+	result := &LineageResult{Columns: make([]ColumnLineage, 0)}
+	load := FileLoad{Target: target, Pattern: opts["PATTERN"], Format: parseFileFormat(opts["FILE_FORMAT"])}
+	if m := stagePath.FindStringSubmatch(source); m != nil {
+		load.Stage = m[1]
+		stage, ok := stages.Get(m[1])
+		switch {
+		case !ok:
+			load.Location = source
+			result.Unresolved = append(result.Unresolved, UnresolvedRef{Table: source, Reason: "stage not defined"})
+		case stage.URL == "":
+			load.Location = source
+		default:
+			load.Location = strings.TrimSuffix(stage.URL, "/") + "/" + strings.TrimPrefix(m[2], "/")
+			load.Location = strings.TrimSuffix(load.Location, "/") + "/"
+		}
+		if ok && load.Format.Type == "" && load.Format.Name == "" {
+			load.Format = stage.FileFormat
+		}
+	} else {
+		load.Location = strings.Trim(source, "'")
+	}
+	result.Loads = []FileLoad{load}
+
+	from := LocationRef(load.Location)
+	if load.Location == source && strings.HasPrefix(source, "@") {
+		from = ColumnRef{Table: source}
+	}
+	result.Scans = []TableScan{{Database: from.Database, Table: from.Table}}
+
+	if len(columns) == 0 && catalog != nil {
+		schema, err := catalog.GetTableSchema(target.Database, target.Table)
+		if err == nil && len(schema.Columns) > 0 {
+			columns = schema.Columns
+		} else {
+			reason := "table not found in catalog"
+			if err != nil {
+				reason = err.Error()
+			}
+			result.Unresolved = append(result.Unresolved, UnresolvedRef{Database: target.Database, Table: target.Table, Reason: reason})
+		}
+	}
+	if items != nil && len(columns) != len(items) {
+		if len(columns) > 0 {
+			result.Unresolved = append(result.Unresolved, UnresolvedRef{Database: target.Database, Table: target.Table, Reason: "transformation does not select a field per column"})
+		}
+		columns, items = nil, nil
+	}
+	if len(columns) == 0 {
+		columns = []string{"*"}
+	}
+
+	for i, column := range columns {
+		t := target
+		t.Column = column
+		col := ColumnLineage{Target: t, Sources: make([]ColumnRef, 0, 1), Operators: []string{}}
+		if items == nil {
+			s := from
+			s.Column, s.Confidence = column, ConfidenceSyntactic
+			col.Sources = append(col.Sources, s)
+		} else {
+			item := strings.TrimSpace(items[i])
+			for _, ref := range positionalRef.FindAllString(item, -1) {
+				s := from
+				s.Column = ref
+				col.Sources = append(col.Sources, s)
+			}
+			if len(col.Sources) != 1 || col.Sources[0].Column != item {
+				col.Operators = append(col.Operators, item)
+			}
+		}
+		result.Columns = append(result.Columns, col)
+	}
+	return result, nil
+}
+
+// qualifiedName returns the possibly qualified and quoted name starting at
+// tokens[i] and the index of the token after it.
+func qualifiedName(sql string, tokens []sparkToken, i int) (string, int) {
+	start := i
+	for i < len(tokens) {
+		t := tokens[i].text
+		if t == "" || !isWordChar(t[0]) && t[0] != '"' && t[0] != '`' {
+			break
+		}
+		i++
+		if i < len(tokens) && tokens[i].text == "." {
+			i++
+			continue
+		}
+		break
+	}
+	if i == start {
+		return "", i
+	}
+	return sql[tokens[start].start:tokens[i-1].end], i
+}
+
+// tokenEnd returns the end offset of tokens[i], or 0 if i is out of range.
+func tokenEnd(tokens []sparkToken, i int) int {
+	if i < 0 || i >= len(tokens) {
+		return 0
+	}
+	return tokens[i].end
+}
+
+// closingParen returns the index of the parenthesis closing tokens[open], or
+// of the last token if it is not closed.
+func closingParen(tokens []sparkToken, open int) int {
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		switch tokens[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(tokens) - 1
+}
+
+// cutKeyword cuts s around the first keyword outside parentheses and quotes.
+func cutKeyword(s, keyword string) (before, after string, found bool) {
+	depth := 0
+	for _, t := range tokenizeSpark(s) {
+		switch {
+		case t.text == "(":
+			depth++
+		case t.text == ")":
+			depth--
+		case depth == 0 && t.word() == keyword:
+			return s[:t.start], s[t.end:], true
+		}
+	}
+	return s, "", false
+}
+
+// cutLocation cuts the location a COPY loads from, a stage reference or a
+// quoted URL, from the options following it. The alias of the location in a
+// transformation query is dropped.
+func cutLocation(s string) (location, options string) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "'") {
+		end := strings.Index(s[1:], "'")
+		if end < 0 {
+			return s, ""
+		}
+		return s[:end+2], s[end+2:]
+	}
+	end := strings.IndexAny(s, " \t\r\n)")
+	if end < 0 {
+		return s, ""
+	}
+	location, options = s[:end], strings.TrimSpace(s[end:])
+	// An alias precedes the options, which are all KEY = value
+	if fields := strings.Fields(options); len(fields) > 1 && fields[1] != "=" && !strings.HasPrefix(fields[1], "=") && !strings.Contains(fields[0], "=") {
+		options = strings.TrimSpace(options[len(fields[0]):])
+	}
+	return location, options
+}
+
+// stageOptions parses the KEY = value options of a stage or COPY statement,
+// e.g. URL = 's3://lake/' FILE_FORMAT = (TYPE = CSV). Keys are upper-cased,
+// quoted values unquoted and parenthesized values returned without their
+// parentheses.
+func stageOptions(s string) map[string]string {
+	tokens := tokenizeSpark(s)
+	options := make(map[string]string)
+	for i := 0; i+2 < len(tokens); i++ {
+		key := tokens[i].word()
+		if key == "" || tokens[i+1].text != "=" {
+			continue
+		}
+		value := tokens[i+2]
+		if value.text == "(" {
+			end := closingParen(tokens, i+2)
+			options[key] = strings.TrimSpace(s[value.end:tokens[end].start])
+			i = end
+			continue
+		}
+		options[key] = unquoteString(value.text)
+		i += 2
+	}
+	return options
+}
+
+// parseFileFormat parses the options of a FILE_FORMAT clause.
+func parseFileFormat(s string) FileFormat {
+	var format FileFormat
+	for key, value := range stageOptions(s) {
+		switch key {
+		case "TYPE":
+			format.Type = strings.ToUpper(value)
+		case "FORMAT_NAME":
+			format.Name = value
+		default:
+			if format.Options == nil {
+				format.Options = make(map[string]string)
+			}
+			format.Options[key] = value
+		}
+	}
+	return format
+}
+
+// unquoteString removes the single quotes of a string literal.
+func unquoteString(s string) string {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
@@ -0,0 +1,419 @@
+package lineage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Spark SQL and Databricks constructs the grammar does not cover are
+// rewritten into equivalent SQL it parses, keeping the column lineage:
+//
+//   - CREATE TABLE ... USING delta OPTIONS (...): the data source and its
+//     options are dropped, and the clauses Spark allows in any order
+//     (PARTITIONED BY, LOCATION, COMMENT, TBLPROPERTIES) are put in the order
+//     of the grammar. ROW FORMAT, STORED AS, CLUSTERED BY and CLUSTER BY,
+//     which do not change lineage, are dropped too.
+//   - LATERAL VIEW [OUTER] explode(arr) t AS item becomes
+//     CROSS JOIN LATERAL (SELECT explode(arr) AS item) t.
+//   - DISTRIBUTE BY, SORT BY and CLUSTER BY of queries are dropped.
+//   - posexplode, a keyword of the grammar, is quoted where it is called.
+//   - INSERT OVERWRITE [LOCAL] DIRECTORY '/path' ... SELECT writes to a
+//     target table named by the path, e.g. `/path`.
+//
+// The rewrite is only tried on statements the grammar rejects, so SQL of
+// other dialects is parsed as written.
+
+// sparkToken is a token of a statement: a word, a quoted string or
+// identifier, or a punctuation character. Comments are skipped.
+type sparkToken struct {
+	text       string
+	start, end int
+}
+
+// word returns the token upper-cased if it is a word, or "".
+func (t sparkToken) word() string {
+	if t.text == "" || !isWordChar(t.text[0]) {
+		return ""
+	}
+	return strings.ToUpper(t.text)
+}
+
+// tokenizeSpark splits sql into tokens.
+func tokenizeSpark(sql string) []sparkToken {
+	var tokens []sparkToken
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			i += end
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 4
+			}
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for j < len(sql) {
+				if sql[j] == '\\' && c != '`' {
+					j += 2
+					continue
+				}
+				if sql[j] == c {
+					if j+1 < len(sql) && sql[j+1] == c {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			j = min(j+1, len(sql))
+			tokens = append(tokens, sparkToken{sql[i:j], i, j})
+			i = j
+		case isWordChar(c):
+			j := i
+			for j < len(sql) && isWordChar(sql[j]) {
+				j++
+			}
+			tokens = append(tokens, sparkToken{sql[i:j], i, j})
+			i = j
+		default:
+			tokens = append(tokens, sparkToken{sql[i : i+1], i, i + 1})
+			i++
+		}
+	}
+	return tokens
+}
+
+// sparkEdit replaces sql[start:end] with text.
+type sparkEdit struct {
+	start, end int
+	text       string
+}
+
+// rewriteSpark rewrites the Spark constructs of a statement, and reports
+// whether it found any.
+func rewriteSpark(sql string) (string, bool) {
+	tokens := tokenizeSpark(sql)
+	var edits []sparkEdit
+	lateral := 0
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i].word() {
+		case "CREATE":
+			if edit, next, ok := sparkCreateTable(sql, tokens, i); ok {
+				edits = append(edits, edit)
+				i = next - 1
+			}
+		case "INSERT":
+			if edit, next, ok := sparkInsertDirectory(sql, tokens, i); ok {
+				edits = append(edits, edit)
+				i = next - 1
+			}
+		case "LATERAL":
+			lateral++
+			if edit, next, ok := sparkLateralView(sql, tokens, i, lateral); ok {
+				edits = append(edits, edit)
+				i = next - 1
+			}
+		case "POSEXPLODE":
+			if i+1 < len(tokens) && tokens[i+1].text == "(" {
+				edits = append(edits, sparkEdit{tokens[i].start, tokens[i].end, "`" + tokens[i].text + "`"})
+			}
+		case "DISTRIBUTE", "SORT", "CLUSTER":
+			if i+1 < len(tokens) && tokens[i+1].word() == "BY" {
+				next := sparkClauseEnd(tokens, i+2)
+				edits = append(edits, sparkEdit{tokens[i].start, sparkEditEnd(sql, tokens, next), ""})
+				i = next - 1
+			}
+		}
+	}
+	if len(edits) == 0 {
+		return sql, false
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, e := range edits {
+		b.WriteString(sql[last:e.start])
+		b.WriteString(e.text)
+		last = e.end
+	}
+	b.WriteString(sql[last:])
+	return b.String(), true
+}
+
+// sparkEditEnd returns the offset an edit removing the tokens before next
+// ends at.
+func sparkEditEnd(sql string, tokens []sparkToken, next int) int {
+	if next < len(tokens) {
+		return tokens[next].start
+	}
+	return len(sql)
+}
+
+// matchingParen returns the index of the token closing the parenthesis
+// opened at tokens[i], or len(tokens).
+func matchingParen(tokens []sparkToken, i int) int {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		switch tokens[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(tokens)
+}
+
+// sparkClauseEnd returns the index of the token ending a DISTRIBUTE BY,
+// SORT BY or CLUSTER BY clause of a query starting at tokens[i]: the next
+// clause or set operator, the parenthesis closing the query, or the end.
+func sparkClauseEnd(tokens []sparkToken, i int) int {
+	for ; i < len(tokens); i++ {
+		switch tokens[i].text {
+		case "(":
+			i = matchingParen(tokens, i)
+			continue
+		case ")", ";":
+			return i
+		}
+		switch tokens[i].word() {
+		case "LIMIT", "UNION", "INTERSECT", "EXCEPT", "MINUS", "INSERT", "WINDOW":
+			return i
+		case "DISTRIBUTE", "SORT", "CLUSTER":
+			if i+1 < len(tokens) && tokens[i+1].word() == "BY" {
+				return i
+			}
+		}
+	}
+	return len(tokens)
+}
+
+// sparkTableClauses are the clauses of a Spark CREATE TABLE statement kept
+// in the rewrite, in the order of the grammar. The others are dropped.
+var sparkTableClauses = []string{"PARTITIONED", "LOCATION", "TBLPROPERTIES", "COMMENT"}
+
+// sparkCreateTable rewrites the clauses of a CREATE TABLE statement starting
+// at tokens[i], returning the edit and the index of the token after the
+// clauses: AS of a CREATE TABLE AS SELECT, or the end.
+func sparkCreateTable(sql string, tokens []sparkToken, i int) (sparkEdit, int, bool) {
+	j := i + 1
+	for j < len(tokens) && tokens[j].word() != "TABLE" {
+		switch tokens[j].word() {
+		case "OR", "REPLACE", "GLOBAL", "LOCAL", "TEMPORARY", "TEMP", "EXTERNAL":
+			j++
+		default:
+			return sparkEdit{}, 0, false
+		}
+	}
+	if j++; j+2 < len(tokens) && tokens[j].word() == "IF" && tokens[j+1].word() == "NOT" {
+		j += 3
+	}
+	// Table name
+	for j++; j+1 < len(tokens) && tokens[j].text == "."; j += 2 {
+	}
+	if j < len(tokens) && tokens[j].text == "(" {
+		j = matchingParen(tokens, j) + 1
+	}
+	if j >= len(tokens) {
+		return sparkEdit{}, 0, false
+	}
+
+	// Split the clauses on their leading keywords
+	start := j
+	clauses := make(map[string]string)
+	var keyword string
+	from := j
+	flush := func(to int) {
+		if keyword != "" && to > from {
+			clauses[keyword] = sql[tokens[from].start:tokens[to-1].end]
+		}
+	}
+	for ; j < len(tokens); j++ {
+		if tokens[j].text == "(" {
+			j = matchingParen(tokens, j)
+			continue
+		}
+		w := tokens[j].word()
+		if w == "AS" || tokens[j].text == ";" {
+			break
+		}
+		next := ""
+		if j+1 < len(tokens) {
+			next = tokens[j+1].word()
+		}
+		switch {
+		case w == "USING", w == "OPTIONS", w == "LOCATION", w == "COMMENT", w == "TBLPROPERTIES",
+			w == "PARTITIONED" && next == "BY", w == "CLUSTERED" && next == "BY",
+			w == "CLUSTER" && next == "BY", w == "ROW" && next == "FORMAT", w == "STORED" && next == "AS":
+			flush(j)
+			keyword, from = w, j
+			if w == "STORED" {
+				// STORED AS is a single clause
+				j++
+			}
+		case keyword == "":
+			return sparkEdit{}, 0, false
+		}
+	}
+	j = min(j, len(tokens))
+	flush(j)
+	if len(clauses) == 0 {
+		return sparkEdit{}, 0, false
+	}
+
+	kept := make([]string, 0, len(sparkTableClauses))
+	for _, k := range sparkTableClauses {
+		if c, ok := clauses[k]; ok {
+			kept = append(kept, c)
+		}
+	}
+	text := strings.Join(kept, " ")
+	if text != "" {
+		text += " "
+	}
+	return sparkEdit{tokens[start].start, sparkEditEnd(sql, tokens, j), text}, j, true
+}
+
+// sparkInsertDirectory rewrites INSERT OVERWRITE [LOCAL] DIRECTORY starting
+// at tokens[i] into an INSERT into a table named by the path of the
+// directory, returning the edit and the index of the first token of the
+// query.
+func sparkInsertDirectory(sql string, tokens []sparkToken, i int) (sparkEdit, int, bool) {
+	j := i + 1
+	if j >= len(tokens) || tokens[j].word() != "OVERWRITE" {
+		return sparkEdit{}, 0, false
+	}
+	if j++; j < len(tokens) && tokens[j].word() == "LOCAL" {
+		j++
+	}
+	if j >= len(tokens) || tokens[j].word() != "DIRECTORY" {
+		return sparkEdit{}, 0, false
+	}
+	path := ""
+	if j++; j < len(tokens) && tokens[j].text[0] == '\'' {
+		path = unquoteSparkString(tokens[j].text)
+		j++
+	}
+	for ; j < len(tokens); j++ {
+		switch tokens[j].word() {
+		case "SELECT", "WITH", "FROM":
+		case "OPTIONS":
+			// The path of USING ... OPTIONS (path '/x')
+			end := matchingParen(tokens, j+1)
+			for k := j + 2; k+1 < end; k++ {
+				if path == "" && tokens[k].word() == "PATH" {
+					v := k + 1
+					if tokens[v].text == "=" {
+						v++
+					}
+					path = unquoteSparkString(tokens[v].text)
+				}
+			}
+			j = end
+			continue
+		default:
+			continue
+		}
+		break
+	}
+	if j >= len(tokens) {
+		return sparkEdit{}, 0, false
+	}
+	if path == "" {
+		path = "directory"
+	}
+	target := fmt.Sprintf("INSERT OVERWRITE TABLE `%s` ", strings.ReplaceAll(path, "`", "``"))
+	return sparkEdit{tokens[i].start, tokens[j].start, target}, j, true
+}
+
+// unquoteSparkString returns the value of a quoted string token.
+func unquoteSparkString(s string) string {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		s = s[1 : len(s)-1]
+	}
+	return strings.NewReplacer(`\\`, `\`, `\'`, `'`, `\"`, `"`).Replace(s)
+}
+
+// sparkLateralViewEnd are the keywords that may follow the table alias of a
+// LATERAL VIEW without column aliases.
+var sparkLateralViewEnd = map[string]bool{
+	"WHERE": true, "GROUP": true, "HAVING": true, "ORDER": true, "LIMIT": true, "LATERAL": true,
+	"JOIN": true, "LEFT": true, "RIGHT": true, "INNER": true, "FULL": true, "CROSS": true,
+	"SORT": true, "DISTRIBUTE": true, "CLUSTER": true, "UNION": true, "WINDOW": true, "INSERT": true,
+}
+
+// sparkLateralView rewrites LATERAL VIEW [OUTER] fn(args) t [AS] c1, c2
+// starting at tokens[i] into a lateral subquery selecting the generator once
+// per column alias, returning the edit and the index of the token after it.
+// A view without a table alias is named __lateral_n.
+func sparkLateralView(sql string, tokens []sparkToken, i, n int) (sparkEdit, int, bool) {
+	j := i + 1
+	if j >= len(tokens) || tokens[j].word() != "VIEW" {
+		return sparkEdit{}, 0, false
+	}
+	if j++; j < len(tokens) && tokens[j].word() == "OUTER" {
+		j++
+	}
+	fnStart := j
+	for j < len(tokens) && tokens[j].text != "(" {
+		j++
+	}
+	if j == fnStart || j >= len(tokens) {
+		return sparkEdit{}, 0, false
+	}
+	name := tokens[j-1]
+	fn := strings.ToLower(name.text)
+	j = matchingParen(tokens, j)
+	if j >= len(tokens) {
+		return sparkEdit{}, 0, false
+	}
+	generator := sql[tokens[fnStart].start:tokens[j].end]
+	if fn == "posexplode" {
+		generator = sql[tokens[fnStart].start:name.start] + "`" + name.text + "`" + sql[name.end:tokens[j].end]
+	}
+
+	alias := fmt.Sprintf("__lateral_%d", n)
+	j++
+	if j < len(tokens) && tokens[j].word() != "" && tokens[j].word() != "AS" && !sparkLateralViewEnd[tokens[j].word()] {
+		alias = tokens[j].text
+		j++
+	}
+	var columns []string
+	if j < len(tokens) && tokens[j].word() == "AS" {
+		j++
+	}
+	for j < len(tokens) && tokens[j].word() != "" && !sparkLateralViewEnd[tokens[j].word()] {
+		columns = append(columns, tokens[j].text)
+		if j++; j >= len(tokens) || tokens[j].text != "," {
+			break
+		}
+		j++
+	}
+	if len(columns) == 0 {
+		// Spark names the columns of generators without aliases
+		columns = []string{"col"}
+		if fn == "posexplode" || fn == "posexplode_outer" {
+			columns = []string{"pos", "col"}
+		}
+	}
+
+	selected := make([]string, len(columns))
+	for k, col := range columns {
+		selected[k] = generator + " AS " + col
+	}
+	text := fmt.Sprintf("CROSS JOIN LATERAL (SELECT %s) %s ", strings.Join(selected, ", "), alias)
+	return sparkEdit{tokens[i].start, sparkEditEnd(sql, tokens, j), text}, j, true
+}
@@ -0,0 +1,235 @@
+// Package storage derives lineage between warehouse tables and the object-store
+// datasets at their storage locations, e.g. a Hive external table at
+// s3a://lake/orders and the orders prefix of the lake bucket collected from
+// MinIO.
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-metadata/internal/lineage"
+)
+
+// DefaultNamespace is the database name under which object-store datasets
+// appear in the lineage graph, e.g. s3.lake/orders.
+const DefaultNamespace = "s3"
+
+// schemes are the URI schemes of S3-compatible locations.
+var schemes = map[string]bool{"s3": true, "s3a": true, "s3n": true}
+
+// Location is a parsed object-store location.
+type Location struct {
+	Bucket string
+	// Path is the object key prefix without leading or trailing slashes.
+	Path string
+}
+
+// ParseLocation parses an s3://, s3a:// or s3n:// location. It returns false
+// for other locations, such as hdfs:// or file:// paths.
+func ParseLocation(uri string) (Location, bool) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil || !schemes[strings.ToLower(u.Scheme)] || u.Host == "" {
+		return Location{}, false
+	}
+	return Location{Bucket: u.Host, Path: strings.Trim(u.Path, "/")}, true
+}
+
+// Dataset is an object-store dataset: a prefix of a bucket.
+type Dataset struct {
+	Bucket string
+	Prefix string
+	// Columns are the fields inferred from the files under the prefix, if any.
+	Columns []string
+}
+
+// Table is a warehouse table with its storage location.
+type Table struct {
+	Database string
+	Table    string
+	Location string
+	Format   string
+	Columns  []string
+}
+
+// LinkKind describes how a table location relates to a dataset.
+type LinkKind string
+
+const (
+	// LinkIdentity means the table location is the dataset prefix.
+	LinkIdentity LinkKind = "identity"
+	// LinkContains means the table location lies under the dataset prefix.
+	LinkContains LinkKind = "contains"
+)
+
+// Link connects a warehouse table to the dataset at its location.
+type Link struct {
+	Table    lineage.ColumnRef `json:"table"`
+	Dataset  lineage.ColumnRef `json:"dataset"`
+	Kind     LinkKind          `json:"kind"`
+	Location string            `json:"location"`
+	Format   string            `json:"format,omitempty"`
+}
+
+// Resolver matches table locations against the datasets collected from
+// object-store sources. It is safe for concurrent use.
+type Resolver struct {
+	// Namespace is the database name used for dataset references.
+	Namespace string
+
+	mu       sync.RWMutex
+	datasets map[string][]Dataset
+}
+
+// NewResolver creates a resolver without datasets.
+func NewResolver() *Resolver {
+	return &Resolver{
+		Namespace: DefaultNamespace,
+		datasets:  make(map[string][]Dataset),
+	}
+}
+
+// SetDatasets replaces the datasets collected from an object-store source.
+func (r *Resolver) SetDatasets(source string, datasets []Dataset) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.datasets[source] = datasets
+}
+
+// DatasetRef returns the dataset reference of a bucket prefix.
+func (r *Resolver) DatasetRef(ds Dataset) lineage.ColumnRef {
+	name := ds.Bucket
+	if prefix := strings.Trim(ds.Prefix, "/"); prefix != "" {
+		name += "/" + prefix
+	}
+	return lineage.ColumnRef{Database: r.Namespace, Table: name}
+}
+
+// Resolve returns the link of a table to the dataset at its location. A
+// dataset whose prefix equals the location wins; otherwise the dataset with
+// the longest prefix containing the location is used.
+func (r *Resolver) Resolve(t Table) (*Link, *Dataset, bool) {
+	loc, ok := ParseLocation(t.Location)
+	if !ok {
+		return nil, nil, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sources := make([]string, 0, len(r.datasets))
+	for source := range r.datasets {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	var best *Dataset
+	var kind LinkKind
+	bestLen := -1
+	for _, source := range sources {
+		for i := range r.datasets[source] {
+			ds := &r.datasets[source][i]
+			if ds.Bucket != loc.Bucket {
+				continue
+			}
+			prefix := strings.Trim(ds.Prefix, "/")
+			switch {
+			case prefix == loc.Path:
+				if kind != LinkIdentity {
+					best, kind, bestLen = ds, LinkIdentity, len(prefix)
+				}
+			case kind != LinkIdentity && len(prefix) > bestLen &&
+				(prefix == "" || strings.HasPrefix(loc.Path, prefix+"/")):
+				best, kind, bestLen = ds, LinkContains, len(prefix)
+			}
+		}
+	}
+	if best == nil {
+		return nil, nil, false
+	}
+
+	ds := *best
+	return &Link{
+		Table:    lineage.ColumnRef{Database: t.Database, Table: t.Table},
+		Dataset:  r.DatasetRef(ds),
+		Kind:     kind,
+		Location: t.Location,
+		Format:   t.Format,
+	}, &ds, true
+}
+
+// LinkLineage returns the job node of a table's storage link, whose input is
+// the dataset and output the table. Identity links also get column-level
+// edges for the table columns found among the inferred dataset fields.
+func (r *Resolver) LinkLineage(t Table) (*Link, *lineage.Job, *lineage.LineageResult, bool) {
+	link, ds, ok := r.Resolve(t)
+	if !ok {
+		return nil, nil, nil, false
+	}
+
+	job := &lineage.Job{
+		Name: "storage:" + link.Table.TableName(),
+		Type: lineage.JobTypeStorage,
+		Properties: map[string]string{
+			"link":     string(link.Kind),
+			"location": link.Location,
+		},
+		Inputs:  []string{link.Dataset.TableName()},
+		Outputs: []string{link.Table.TableName()},
+	}
+	if link.Format != "" {
+		job.Properties["format"] = link.Format
+	}
+
+	result := &lineage.LineageResult{}
+	if link.Kind == LinkIdentity {
+		for _, column := range t.Columns {
+			if !containsFold(ds.Columns, column) {
+				continue
+			}
+			source := link.Dataset
+			source.Column = column
+			source.Confidence = lineage.ConfidenceCatalog
+			target := link.Table
+			target.Column = column
+			result.Columns = append(result.Columns, lineage.ColumnLineage{
+				Target:  target,
+				Sources: []lineage.ColumnRef{source},
+			})
+		}
+	}
+	return link, job, result, true
+}
+
+// Apply records the storage links of the given tables in g as of at and
+// returns them. Tables without a matching dataset are skipped.
+func (r *Resolver) Apply(g *lineage.Graph, tables []Table, at time.Time) ([]*Link, error) {
+	var links []*Link
+	for _, t := range tables {
+		link, job, result, ok := r.LinkLineage(t)
+		if !ok {
+			continue
+		}
+		if err := g.RegisterJob(job); err != nil {
+			return links, fmt.Errorf("register storage link of %s: %w", link.Table.TableName(), err)
+		}
+		if err := g.AttachStatement(job.Name, result, "", at); err != nil {
+			return links, err
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
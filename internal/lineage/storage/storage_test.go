@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"go-metadata/internal/lineage"
+)
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		uri    string
+		want   Location
+		wantOK bool
+	}{
+		{"s3a://lake/warehouse/orders/", Location{Bucket: "lake", Path: "warehouse/orders"}, true},
+		{"s3://lake", Location{Bucket: "lake"}, true},
+		{"S3N://lake/orders", Location{Bucket: "lake", Path: "orders"}, true},
+		{"hdfs://nn:8020/user/hive/warehouse/orders", Location{}, false},
+		{"/user/hive/warehouse/orders", Location{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseLocation(tt.uri)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("ParseLocation(%q) = %+v, %v; want %+v, %v", tt.uri, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func newTestResolver() *Resolver {
+	r := NewResolver()
+	r.SetDatasets("minio", []Dataset{
+		{Bucket: "lake", Prefix: "orders", Columns: []string{"id", "amount"}},
+		{Bucket: "lake", Prefix: "warehouse"},
+		{Bucket: "raw", Prefix: "events"},
+	})
+	return r
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	r := newTestResolver()
+
+	tests := []struct {
+		location string
+		dataset  string
+		kind     LinkKind
+	}{
+		{"s3a://lake/orders/", "s3.lake/orders", LinkIdentity},
+		{"s3a://lake/warehouse/dw.db/customers", "s3.lake/warehouse", LinkContains},
+		{"s3://lake/ordersx", "", ""},
+		{"s3://archive/orders", "", ""},
+		{"hdfs://nn/warehouse/orders", "", ""},
+	}
+	for _, tt := range tests {
+		link, _, ok := r.Resolve(Table{Database: "dw", Table: "t", Location: tt.location})
+		if tt.dataset == "" {
+			if ok {
+				t.Errorf("Resolve(%q): unexpected link to %s", tt.location, link.Dataset.TableName())
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("Resolve(%q): no link", tt.location)
+			continue
+		}
+		if link.Dataset.TableName() != tt.dataset || link.Kind != tt.kind {
+			t.Errorf("Resolve(%q) = %s (%s), want %s (%s)", tt.location, link.Dataset.TableName(), link.Kind, tt.dataset, tt.kind)
+		}
+	}
+}
+
+func TestResolver_SetDatasetsReplacesSource(t *testing.T) {
+	r := newTestResolver()
+	r.SetDatasets("minio", nil)
+	if _, _, ok := r.Resolve(Table{Table: "t", Location: "s3a://lake/orders"}); ok {
+		t.Error("Expected no link after the source datasets were cleared")
+	}
+}
+
+func TestResolver_Apply(t *testing.T) {
+	r := newTestResolver()
+	tables := []Table{
+		{Database: "dw", Table: "orders", Location: "s3a://lake/orders", Format: "PARQUET", Columns: []string{"id", "amount", "note"}},
+		{Database: "dw", Table: "customers", Location: "s3a://lake/warehouse/dw.db/customers", Columns: []string{"id"}},
+		{Database: "dw", Table: "managed", Location: "hdfs://nn/warehouse/managed"},
+	}
+
+	g := lineage.NewGraph()
+	links, err := r.Apply(g, tables, time.Now())
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 links, got %d", len(links))
+	}
+
+	job, err := g.Job("storage:dw.orders")
+	if err != nil {
+		t.Fatalf("Job not registered: %v", err)
+	}
+	if job.Type != lineage.JobTypeStorage || job.Inputs[0] != "s3.lake/orders" || job.Outputs[0] != "dw.orders" {
+		t.Errorf("Unexpected job %s: %v -> %v", job.Type, job.Inputs, job.Outputs)
+	}
+	if job.Properties["link"] != string(LinkIdentity) || job.Properties["format"] != "PARQUET" {
+		t.Errorf("Unexpected job properties: %v", job.Properties)
+	}
+
+	// Only the identity link yields column edges, for the inferred fields.
+	if g.Len() != 2 {
+		t.Fatalf("Expected 2 column edges, got %d", g.Len())
+	}
+	for _, edge := range g.Edges() {
+		if edge.Source.Table != "lake/orders" || edge.Target.Table != "orders" || edge.Source.Column != edge.Target.Column {
+			t.Errorf("Unexpected edge %s", edge.Key())
+		}
+	}
+}
@@ -0,0 +1,509 @@
+package lineage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TemplateResolver resolves Jinja constructs found in dbt/Airflow SQL files.
+// Each method reports false when the name is unknown, in which case the
+// preprocessor falls back to a syntactic rendering of the construct.
+type TemplateResolver interface {
+	// ResolveRef returns the relation name for {{ ref('model') }}.
+	ResolveRef(model string) (string, bool)
+	// ResolveSource returns the relation name for {{ source('source', 'table') }}.
+	ResolveSource(source, table string) (string, bool)
+	// ResolveVar returns the value of a variable such as {{ var('x') }} or {{ params.x }}.
+	ResolveVar(name string) (string, bool)
+}
+
+// TemplateVars is a map-backed TemplateResolver.
+type TemplateVars struct {
+	Refs    map[string]string // model -> relation
+	Sources map[string]string // "source.table" -> relation
+	Vars    map[string]string // variable name -> value
+}
+
+// ResolveRef returns the relation registered for model.
+func (v *TemplateVars) ResolveRef(model string) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	rel, ok := v.Refs[model]
+	return rel, ok
+}
+
+// ResolveSource returns the relation registered for source.table.
+func (v *TemplateVars) ResolveSource(source, table string) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	rel, ok := v.Sources[source+"."+table]
+	return rel, ok
+}
+
+// ResolveVar returns the value registered for name.
+func (v *TemplateVars) ResolveVar(name string) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	val, ok := v.Vars[name]
+	return val, ok
+}
+
+// HasTemplate reports whether sql contains Jinja expressions, statements or comments.
+func HasTemplate(sql string) bool {
+	return strings.Contains(sql, "{{") || strings.Contains(sql, "{%") || strings.Contains(sql, "{#")
+}
+
+// PreprocessTemplate renders the Jinja constructs in sql into plain SQL so it
+// can be fed to the parser:
+//   - {{ ref('m') }} / {{ source('s', 't') }} become relation names
+//   - {{ var('x') }}, {{ params.x }} and other expressions become their resolved
+//     value, or an identifier derived from the expression when unresolved
+//   - {% if %} blocks are evaluated when the condition can be resolved, otherwise
+//     the first branch is kept; {% for %} bodies are emitted once
+//   - {% macro %}, {% set %} blocks, {{ config(...) }} and {# comments #} are dropped
+//
+// resolver may be nil.
+func PreprocessTemplate(sql string, resolver TemplateResolver) (string, error) {
+	tokens, err := tokenizeTemplate(sql)
+	if err != nil {
+		return "", err
+	}
+
+	r := &templateRenderer{resolver: resolver}
+	var out strings.Builder
+	trimNext := false
+
+	for _, tok := range tokens {
+		if tok.trimLeft {
+			trimmed := strings.TrimRight(out.String(), " \t\r\n")
+			out.Reset()
+			out.WriteString(trimmed)
+		}
+
+		switch tok.kind {
+		case templateText:
+			text := tok.value
+			if trimNext {
+				text = strings.TrimLeft(text, " \t\r\n")
+			}
+			if r.active() {
+				out.WriteString(text)
+			}
+		case templateExpr:
+			if r.active() {
+				out.WriteString(r.renderExpr(tok.value))
+			}
+		case templateStmt:
+			if err := r.handleStmt(tok.value); err != nil {
+				return "", err
+			}
+		}
+		trimNext = tok.trimRight
+	}
+
+	if len(r.frames) > 0 {
+		return "", fmt.Errorf("%w: unclosed {%% %s %%} block", ErrInvalidTemplate, r.frames[len(r.frames)-1].kind)
+	}
+	return out.String(), nil
+}
+
+type templateTokenKind int
+
+const (
+	templateText templateTokenKind = iota
+	templateExpr
+	templateStmt
+	templateComment
+)
+
+// templateToken is a raw text run or the trimmed body of a {{ }}, {% %} or {# #} tag.
+type templateToken struct {
+	kind      templateTokenKind
+	value     string
+	trimLeft  bool // tag opened with "{{-" / "{%-"
+	trimRight bool // tag closed with "-}}" / "-%}"
+}
+
+// tokenizeTemplate splits sql into text runs and template tags.
+func tokenizeTemplate(sql string) ([]templateToken, error) {
+	tokens := make([]templateToken, 0)
+	rest := sql
+
+	for len(rest) > 0 {
+		start, kind, closer := nextTemplateTag(rest)
+		if start < 0 {
+			tokens = append(tokens, templateToken{kind: templateText, value: rest})
+			break
+		}
+		if start > 0 {
+			tokens = append(tokens, templateToken{kind: templateText, value: rest[:start]})
+		}
+
+		body := rest[start+2:]
+		end := strings.Index(body, closer)
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unterminated tag at offset %d", ErrInvalidTemplate, len(sql)-len(rest)+start)
+		}
+
+		tok := templateToken{kind: kind, value: body[:end]}
+		if strings.HasPrefix(tok.value, "-") {
+			tok.trimLeft = true
+			tok.value = tok.value[1:]
+		}
+		if strings.HasSuffix(tok.value, "-") {
+			tok.trimRight = true
+			tok.value = tok.value[:len(tok.value)-1]
+		}
+		tok.value = strings.TrimSpace(tok.value)
+		tokens = append(tokens, tok)
+
+		rest = body[end+len(closer):]
+	}
+
+	return tokens, nil
+}
+
+// nextTemplateTag finds the earliest template tag opener in s.
+func nextTemplateTag(s string) (int, templateTokenKind, string) {
+	best := -1
+	var kind templateTokenKind
+	closer := ""
+	for _, c := range []struct {
+		open, close string
+		kind        templateTokenKind
+	}{
+		{"{{", "}}", templateExpr},
+		{"{%", "%}", templateStmt},
+		{"{#", "#}", templateComment},
+	} {
+		if idx := strings.Index(s, c.open); idx >= 0 && (best < 0 || idx < best) {
+			best, kind, closer = idx, c.kind, c.close
+		}
+	}
+	return best, kind, closer
+}
+
+// templateFrame tracks an open block statement.
+type templateFrame struct {
+	kind   string
+	active bool // whether text inside the current branch is emitted
+	taken  bool // whether a branch of an if block has already been selected
+	parent bool // whether the enclosing block is active
+}
+
+// templateBlocks lists the statements that open a block closed by "end<name>".
+var templateBlocks = map[string]bool{
+	"if": true, "for": true, "macro": true, "call": true, "filter": true,
+	"block": true, "snapshot": true, "test": true, "materialization": true,
+}
+
+// templateRenderer evaluates template statements and expressions.
+type templateRenderer struct {
+	resolver TemplateResolver
+	frames   []*templateFrame
+}
+
+func (r *templateRenderer) active() bool {
+	if len(r.frames) == 0 {
+		return true
+	}
+	return r.frames[len(r.frames)-1].active
+}
+
+func (r *templateRenderer) handleStmt(stmt string) error {
+	keyword, args := splitTemplateKeyword(stmt)
+	parent := r.active()
+
+	switch {
+	case keyword == "if":
+		value, known := r.evalCondition(args)
+		if !known {
+			value = true
+		}
+		r.frames = append(r.frames, &templateFrame{kind: "if", active: parent && value, taken: value, parent: parent})
+
+	case keyword == "elif":
+		top, err := r.top("if", keyword)
+		if err != nil {
+			return err
+		}
+		if top.taken {
+			top.active = false
+			return nil
+		}
+		value, known := r.evalCondition(args)
+		if !known {
+			value = true
+		}
+		top.active = top.parent && value
+		top.taken = value
+
+	case keyword == "else":
+		if len(r.frames) == 0 {
+			return fmt.Errorf("%w: {%% else %%} outside of a block", ErrInvalidTemplate)
+		}
+		top := r.frames[len(r.frames)-1]
+		// for-else bodies only run on empty loops; the loop body was already emitted.
+		top.active = top.parent && !top.taken && top.kind == "if"
+		top.taken = true
+
+	case keyword == "set":
+		// Inline assignments are dropped; block assignments capture their body.
+		if !strings.Contains(args, "=") {
+			r.frames = append(r.frames, &templateFrame{kind: "set", active: false, parent: parent})
+		}
+
+	case keyword == "macro":
+		r.frames = append(r.frames, &templateFrame{kind: "macro", active: false, parent: parent})
+
+	case templateBlocks[keyword]:
+		r.frames = append(r.frames, &templateFrame{kind: keyword, active: parent, taken: true, parent: parent})
+
+	case strings.HasPrefix(keyword, "end"):
+		if _, err := r.top(strings.TrimPrefix(keyword, "end"), keyword); err != nil {
+			return err
+		}
+		r.frames = r.frames[:len(r.frames)-1]
+	}
+
+	// Other statements (do, import, include, ...) produce no SQL.
+	return nil
+}
+
+// top returns the innermost frame, checking that it is of the expected kind.
+func (r *templateRenderer) top(kind, keyword string) (*templateFrame, error) {
+	if len(r.frames) == 0 || r.frames[len(r.frames)-1].kind != kind {
+		return nil, fmt.Errorf("%w: unexpected {%% %s %%}", ErrInvalidTemplate, keyword)
+	}
+	return r.frames[len(r.frames)-1], nil
+}
+
+// evalCondition evaluates a simple condition. It supports "not", "and", "or",
+// == / != comparisons against literals and truthiness of resolvable names.
+// known is false when any operand cannot be resolved.
+func (r *templateRenderer) evalCondition(cond string) (value bool, known bool) {
+	cond = strings.TrimSpace(cond)
+
+	if parts := splitTemplateOperator(cond, " or "); len(parts) > 1 {
+		known = true
+		for _, p := range parts {
+			v, k := r.evalCondition(p)
+			if k && v {
+				return true, true
+			}
+			known = known && k
+		}
+		return false, known
+	}
+	if parts := splitTemplateOperator(cond, " and "); len(parts) > 1 {
+		known = true
+		for _, p := range parts {
+			v, k := r.evalCondition(p)
+			if k && !v {
+				return false, true
+			}
+			known = known && k
+		}
+		return true, known
+	}
+	if strings.HasPrefix(cond, "not ") {
+		v, k := r.evalCondition(cond[4:])
+		return !v, k
+	}
+
+	for _, op := range []string{"==", "!="} {
+		if idx := strings.Index(cond, op); idx > 0 {
+			left, lok := r.evalOperand(cond[:idx])
+			right, rok := r.evalOperand(cond[idx+len(op):])
+			if !lok || !rok {
+				return false, false
+			}
+			return (left == right) == (op == "=="), true
+		}
+	}
+
+	v, ok := r.evalOperand(cond)
+	if !ok {
+		return false, false
+	}
+	switch strings.ToLower(v) {
+	case "", "0", "false", "none", "null":
+		return false, true
+	}
+	return true, true
+}
+
+// evalOperand resolves a literal, var('x') call or variable name to a string.
+func (r *templateRenderer) evalOperand(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if lit, ok := unquoteTemplateString(s); ok {
+		return lit, true
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "none":
+		return strings.ToLower(s), true
+	}
+	name, args, isCall := parseTemplateCall(s)
+	if isCall {
+		if name == "var" && len(args) > 0 {
+			return r.resolveVar(args[0])
+		}
+		return r.resolveVar(name)
+	}
+	return r.resolveVar(s)
+}
+
+func (r *templateRenderer) resolveVar(name string) (string, bool) {
+	if r.resolver == nil {
+		return "", false
+	}
+	return r.resolver.ResolveVar(name)
+}
+
+// renderExpr renders the body of a {{ }} expression.
+func (r *templateRenderer) renderExpr(expr string) string {
+	// Filters do not change the relation being referenced.
+	if idx := strings.Index(expr, "|"); idx > 0 {
+		expr = strings.TrimSpace(expr[:idx])
+	}
+
+	if lit, ok := unquoteTemplateString(expr); ok {
+		return lit
+	}
+
+	name, args, isCall := parseTemplateCall(expr)
+	if isCall {
+		switch name {
+		case "ref":
+			if len(args) == 0 {
+				break
+			}
+			model := args[len(args)-1]
+			if r.resolver != nil {
+				if rel, ok := r.resolver.ResolveRef(model); ok {
+					return rel
+				}
+			}
+			return model
+		case "source":
+			if len(args) < 2 {
+				break
+			}
+			if r.resolver != nil {
+				if rel, ok := r.resolver.ResolveSource(args[0], args[1]); ok {
+					return rel
+				}
+			}
+			return args[0] + "." + args[1]
+		case "var":
+			if len(args) == 0 {
+				break
+			}
+			if val, ok := r.resolveVar(args[0]); ok {
+				return val
+			}
+			if len(args) > 1 {
+				return args[1]
+			}
+			return templateIdentifier(args[0])
+		case "config", "log", "print", "return":
+			return ""
+		}
+	}
+
+	if val, ok := r.resolveVar(expr); ok {
+		return val
+	}
+	return templateIdentifier(expr)
+}
+
+// splitTemplateKeyword splits a statement body into its keyword and arguments.
+func splitTemplateKeyword(stmt string) (string, string) {
+	stmt = strings.TrimSpace(stmt)
+	if idx := strings.IndexAny(stmt, " \t\r\n"); idx > 0 {
+		return stmt[:idx], strings.TrimSpace(stmt[idx+1:])
+	}
+	return stmt, ""
+}
+
+// splitTemplateOperator splits s on op outside of quotes and parentheses.
+func splitTemplateOperator(s, op string) []string {
+	parts := make([]string, 0)
+	depth := 0
+	var quote byte
+	last := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && strings.HasPrefix(s[i:], op):
+			parts = append(parts, s[last:i])
+			last = i + len(op)
+			i += len(op) - 1
+		}
+	}
+	return append(parts, s[last:])
+}
+
+// parseTemplateCall parses name('a', "b", key=value) returning the positional
+// string arguments. isCall is false when expr is not a function call.
+func parseTemplateCall(expr string) (name string, args []string, isCall bool) {
+	open := strings.Index(expr, "(")
+	if open <= 0 || !strings.HasSuffix(expr, ")") {
+		return "", nil, false
+	}
+	name = strings.TrimSpace(expr[:open])
+	for _, arg := range splitTemplateOperator(expr[open+1:len(expr)-1], ",") {
+		arg = strings.TrimSpace(arg)
+		if lit, ok := unquoteTemplateString(arg); ok {
+			args = append(args, lit)
+			continue
+		}
+		if arg == "" || strings.Contains(arg, "=") {
+			continue
+		}
+		args = append(args, arg)
+	}
+	return name, args, true
+}
+
+// unquoteTemplateString returns the contents of a single- or double-quoted literal.
+func unquoteTemplateString(s string) (string, bool) {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], true
+	}
+	return "", false
+}
+
+// templateIdentifier derives a SQL identifier from an unresolved expression,
+// e.g. "params.table" -> "params_table".
+func templateIdentifier(expr string) string {
+	var b strings.Builder
+	for _, c := range expr {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			b.WriteRune(c)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	id := strings.Trim(b.String(), "_")
+	for strings.Contains(id, "__") {
+		id = strings.ReplaceAll(id, "__", "_")
+	}
+	if id == "" {
+		return "NULL"
+	}
+	return id
+}
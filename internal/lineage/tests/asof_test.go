@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"go-metadata/internal/lineage"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAsOf_JobRefactor(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+	g := lineage.NewGraph()
+	if err := g.RegisterJob(&lineage.Job{Name: "build_report", Type: lineage.JobTypeSQLScript}); err != nil {
+		t.Fatalf("RegisterJob failed: %v", err)
+	}
+
+	v1 := "INSERT INTO report(total) SELECT SUM(amount) FROM orders"
+	v2 := "INSERT INTO report(total) SELECT SUM(amount) FROM payments"
+
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jun := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := g.AttachStatement("build_report", analyzeForGraph(t, analyzer, v1), lineage.Fingerprint(v1), jan); err != nil {
+		t.Fatalf("AttachStatement failed: %v", err)
+	}
+	if err := g.ReplaceJobLineage("build_report", analyzeForGraph(t, analyzer, v2), lineage.Fingerprint(v2), jun); err != nil {
+		t.Fatalf("ReplaceJobLineage failed: %v", err)
+	}
+
+	tests := []struct {
+		at     time.Time
+		source string
+	}{
+		{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), "orders.amount"},
+		{time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), "payments.amount"},
+	}
+	for _, tt := range tests {
+		edges := g.TableLineageAsOf("report", tt.at)
+		if len(edges) != 1 || edges[0].Source.QualifiedName() != tt.source {
+			t.Errorf("As of %s: expected single edge from %s, got %v", tt.at.Format("2006-01-02"), tt.source, edges)
+		}
+	}
+
+	if edges := g.AsOf(jan.Add(-time.Hour)); len(edges) != 0 {
+		t.Errorf("Expected no edges before first observation, got %d", len(edges))
+	}
+
+	job, _ := g.Job("build_report")
+	if len(job.Inputs) != 1 || job.Inputs[0] != "payments" {
+		t.Errorf("Expected job inputs [payments], got %v", job.Inputs)
+	}
+}
+
+func TestAsOf_ReplaceJobLineageConcurrentReads(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+	g := lineage.NewGraph()
+	if err := g.RegisterJob(&lineage.Job{Name: "build_report", Type: lineage.JobTypeSQLScript}); err != nil {
+		t.Fatalf("RegisterJob failed: %v", err)
+	}
+
+	versions := []string{
+		"INSERT INTO report(total) SELECT SUM(amount) FROM orders",
+		"INSERT INTO report(total) SELECT SUM(amount) FROM payments",
+	}
+	results := make([]*lineage.LineageResult, len(versions))
+	for i, sql := range versions {
+		results[i] = analyzeForGraph(t, analyzer, sql)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := g.AttachStatement("build_report", results[0], lineage.Fingerprint(versions[0]), start); err != nil {
+		t.Fatalf("AttachStatement failed: %v", err)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				job, err := g.Job("build_report")
+				if err != nil {
+					t.Errorf("Job failed: %v", err)
+					return
+				}
+				if len(job.Inputs) != 1 || len(job.Outputs) != 1 {
+					t.Errorf("Expected one input and output during replace, got %v -> %v", job.Inputs, job.Outputs)
+					return
+				}
+				current := 0
+				for _, edge := range g.Edges() {
+					if edge.Current() {
+						current++
+					}
+				}
+				if current != 1 {
+					t.Errorf("Expected one current edge during replace, got %d", current)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 1; i <= 200; i++ {
+		v := i % len(versions)
+		at := start.Add(time.Duration(i) * time.Hour)
+		if err := g.ReplaceJobLineage("build_report", results[v], lineage.Fingerprint(versions[v]), at); err != nil {
+			t.Fatalf("ReplaceJobLineage failed: %v", err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	if err := g.ReplaceJobLineage("build_report", nil, "", start.Add(300*time.Hour)); err != nil {
+		t.Fatalf("ReplaceJobLineage with nil result failed: %v", err)
+	}
+	job, _ := g.Job("build_report")
+	if len(job.Inputs) != 1 || job.Inputs[0] != "orders" {
+		t.Errorf("Expected nil result to leave job inputs [orders], got %v", job.Inputs)
+	}
+}
+
+func TestAsOf_RetireAndReopen(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+	sql := "SELECT id FROM users"
+	result := analyzeForGraph(t, analyzer, sql)
+
+	t1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	t3 := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	g := lineage.NewGraph()
+	g.Add(result, lineage.Fingerprint(sql), t1)
+
+	edge := g.Edges()[0]
+	if err := g.Retire(edge.Source, edge.Target, t2); err != nil {
+		t.Fatalf("Retire failed: %v", err)
+	}
+	g.Add(result, lineage.Fingerprint(sql), t3)
+
+	edge = g.Edges()[0]
+	if len(edge.Validity) != 2 || !edge.Current() {
+		t.Fatalf("Expected two validity intervals ending open, got %+v", edge.Validity)
+	}
+	if edge.ValidAt(t2.Add(24 * time.Hour)) {
+		t.Error("Edge should not be valid while retired")
+	}
+	if !edge.ValidAt(t1) || !edge.ValidAt(t3) {
+		t.Error("Edge should be valid in both intervals")
+	}
+
+	if err := g.Retire(lineage.ColumnRef{Table: "x", Column: "y"}, edge.Target, t3); err == nil {
+		t.Error("Expected error retiring unknown edge")
+	}
+}
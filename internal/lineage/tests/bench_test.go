@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"go-metadata/internal/lineage"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// benchScriptSize is the size of the script the parse benchmark parses.
+const benchScriptSize = 1 << 20
+
+// BenchmarkParseScript parses a 1MB script made of the mysql and pgsql corpus
+// statements repeated.
+func BenchmarkParseScript(b *testing.B) {
+	script := corpusScript(b, benchScriptSize)
+
+	b.SetBytes(int64(len(script)))
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := lineage.ParseSQL(script); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkAnalyze analyzes a join with aggregates against a catalog, the
+// per-statement cost of query log ingestion.
+func BenchmarkAnalyze(b *testing.B) {
+	catalog := NewMockCatalog()
+	catalog.AddTable("", "users", []string{"id", "name", "city"})
+	catalog.AddTable("", "orders", []string{"id", "user_id", "amount", "created_at"})
+	analyzer := lineage.NewAnalyzer(catalog)
+	sql := `INSERT INTO user_totals (user_id, name, city, total, orders)
+		SELECT u.id, u.name, u.city, SUM(o.amount), COUNT(*)
+		FROM users u JOIN orders o ON o.user_id = u.id
+		WHERE o.created_at > '2024-01-01'
+		GROUP BY u.id, u.name, u.city`
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := analyzer.Analyze(sql); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// corpusScript repeats the statements of the mysql and pgsql corpus files
+// until the script reaches size bytes.
+func corpusScript(b *testing.B, size int) string {
+	b.Helper()
+	var statements []string
+	for _, dialect := range []string{"mysql", "pgsql"} {
+		files, err := filepath.Glob(filepath.Join("..", "testdata", dialect, "*.sql"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				b.Fatal(err)
+			}
+			statements = append(statements, splitStatements(string(content))...)
+		}
+	}
+
+	var sb strings.Builder
+	for i := 0; sb.Len() < size; i++ {
+		sb.WriteString(statements[i%len(statements)])
+		sb.WriteString(";\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"testing"
+
+	"go-metadata/internal/lineage"
+)
+
+func TestBigQuery_TablePaths(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+
+	for _, sql := range []string{
+		"INSERT INTO `proj.mart.daily` SELECT o.id, c.name FROM `proj.sales.orders` o JOIN `proj`.sales.customers c ON o.cid = c.id",
+		"INSERT INTO `proj.mart.daily` SELECT o.id, c.name FROM `proj.sales.orders` o, proj.sales.customers c WHERE o.cid = c.id",
+	} {
+		result, err := analyzer.Analyze(sql)
+		if err != nil {
+			t.Fatalf("Analyze(%q) failed: %v", sql, err)
+		}
+		if len(result.Scans) != 2 {
+			t.Fatalf("scans = %+v, want orders and customers", result.Scans)
+		}
+		for _, scan := range result.Scans {
+			if scan.Database != "proj.sales" {
+				t.Errorf("scan = %+v, want database proj.sales", scan)
+			}
+		}
+		if len(result.Columns) != 2 || result.Columns[1].Sources[0].Table != "customers" {
+			t.Errorf("columns = %+v", result.Columns)
+		}
+	}
+}
+
+func TestBigQuery_SelectExcept(t *testing.T) {
+	catalog := NewMockCatalog()
+	catalog.AddTable("", "orders", []string{"id", "amount", "internal_note", "updated_at"})
+	catalog.AddTable("", "clean_orders", []string{"id", "amount"})
+	analyzer := lineage.NewAnalyzer(catalog)
+
+	result, err := analyzer.Analyze("SELECT * EXCEPT (internal_note, updated_at) FROM orders")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Columns) != 2 || result.Columns[0].Target.Column != "id" || result.Columns[1].Target.Column != "amount" {
+		t.Errorf("columns = %+v, want id and amount", result.Columns)
+	}
+
+	// Inserted columns map to the remaining columns by position
+	result, err = analyzer.Analyze("INSERT INTO clean_orders SELECT o.* EXCEPT (internal_note, updated_at) FROM orders o")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Columns) != 2 || result.Columns[1].Target.QualifiedName() != "clean_orders.amount" || result.Columns[1].Sources[0].QualifiedName() != "orders.amount" {
+		t.Errorf("columns = %+v", result.Columns)
+	}
+}
+
+func TestBigQuery_WildcardTable(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+
+	result, err := analyzer.Analyze("INSERT INTO `proj.mart.daily_events` SELECT _TABLE_SUFFIX AS day, user_id FROM `proj.analytics.events_*` WHERE _TABLE_SUFFIX BETWEEN '20240101' AND '20240131'")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Scans) != 1 || result.Scans[0].Table != "events_*" {
+		t.Errorf("scans = %+v, want events_*", result.Scans)
+	}
+	if len(result.Columns) != 2 {
+		t.Fatalf("columns = %+v, want day and user_id", result.Columns)
+	}
+	if day := result.Columns[0]; len(day.Sources) != 0 {
+		t.Errorf("day = %+v, want no source for _TABLE_SUFFIX", day)
+	}
+	if user := result.Columns[1]; len(user.Sources) != 1 || user.Sources[0].Table != "events_*" {
+		t.Errorf("user_id = %+v", user)
+	}
+	for _, u := range result.Usages {
+		if u.Column.Column == "_TABLE_SUFFIX" {
+			t.Errorf("usage of _TABLE_SUFFIX: %+v", u)
+		}
+	}
+}
+
+func TestBigQuery_Explain(t *testing.T) {
+	tree, err := lineage.NewAnalyzer(nil).Explain("SELECT * EXCEPT (note) FROM `proj.sales.orders`")
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if len(tree.Errors) != 0 || tree.Rewritten != "SELECT * FROM `proj.sales`.`orders`" {
+		t.Errorf("rewritten = %q, errors = %+v", tree.Rewritten, tree.Errors)
+	}
+}
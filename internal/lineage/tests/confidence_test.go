@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"go-metadata/internal/lineage"
+	"testing"
+)
+
+// findSource returns the first source of the given target column.
+func findSource(t *testing.T, result *lineage.LineageResult, targetCol string) lineage.ColumnRef {
+	t.Helper()
+	for _, col := range result.Columns {
+		if col.Target.Column == targetCol && len(col.Sources) > 0 {
+			return col.Sources[0]
+		}
+	}
+	t.Fatalf("Column '%s' has no sources", targetCol)
+	return lineage.ColumnRef{}
+}
+
+func TestConfidence_ResolvedViaCatalog(t *testing.T) {
+	catalog := NewMockCatalog()
+	catalog.AddTable("", "users", []string{"id", "name"})
+	catalog.AddTable("", "orders", []string{"id", "user_id", "amount"})
+
+	analyzer := lineage.NewAnalyzer(catalog)
+	sql := `SELECT u.name, amount FROM users u JOIN orders o ON u.id = o.user_id`
+
+	result, err := analyzer.Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	printLineageResult(t, sql, result)
+
+	if src := findSource(t, result, "name"); src.Confidence != lineage.ConfidenceCatalog {
+		t.Errorf("Expected catalog confidence for name, got %q", src.Confidence)
+	}
+	if src := findSource(t, result, "amount"); src.Confidence != lineage.ConfidenceCatalog || src.Table != "orders" {
+		t.Errorf("Expected orders.amount with catalog confidence, got %s.%s (%q)", src.Table, src.Column, src.Confidence)
+	}
+	if result.HasUnresolved() {
+		t.Errorf("Expected no unresolved references, got %v", result.Unresolved)
+	}
+}
+
+func TestConfidence_SyntacticWithoutCatalog(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+	sql := `SELECT u.name FROM users u`
+
+	result, err := analyzer.Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	src := findSource(t, result, "name")
+	if src.Table != "users" || src.Confidence != lineage.ConfidenceSyntactic {
+		t.Errorf("Expected users.name with syntactic confidence, got %s.%s (%q)", src.Table, src.Column, src.Confidence)
+	}
+}
+
+func TestConfidence_UnresolvedReport(t *testing.T) {
+	catalog := NewMockCatalog()
+	catalog.AddTable("", "users", []string{"id", "name"})
+
+	analyzer := lineage.NewAnalyzer(catalog)
+	sql := `SELECT u.nickname, score FROM users u JOIN scores s ON u.id = s.user_id`
+
+	result, err := analyzer.Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	printLineageResult(t, sql, result)
+
+	if src := findSource(t, result, "nickname"); src.Confidence != lineage.ConfidenceGuessed {
+		t.Errorf("Expected guessed confidence for nickname, got %q", src.Confidence)
+	}
+	if src := findSource(t, result, "score"); src.Confidence != lineage.ConfidenceGuessed {
+		t.Errorf("Expected guessed confidence for score, got %q", src.Confidence)
+	}
+
+	expected := map[lineage.UnresolvedRef]bool{
+		{Table: "scores", Reason: lineage.ErrTableNotFound.Error()}:                     true,
+		{Table: "users", Column: "nickname", Reason: lineage.ErrColumnNotFound.Error()}: true,
+		{Column: "score", Reason: lineage.ErrAmbiguousColumn.Error()}:                   true,
+	}
+	if len(result.Unresolved) != len(expected) {
+		t.Fatalf("Expected %d unresolved references, got %v", len(expected), result.Unresolved)
+	}
+	for _, ref := range result.Unresolved {
+		if !expected[ref] {
+			t.Errorf("Unexpected unresolved reference: %+v", ref)
+		}
+	}
+}
+
+func TestConfidence_CTENotReportedAsMissingTable(t *testing.T) {
+	catalog := NewMockCatalog()
+	catalog.AddTable("", "orders", []string{"id", "amount"})
+
+	analyzer := lineage.NewAnalyzer(catalog)
+	sql := `WITH big AS (SELECT id, amount FROM orders WHERE amount > 100) SELECT id FROM big`
+
+	result, err := analyzer.Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	for _, ref := range result.Unresolved {
+		if ref.Table == "big" {
+			t.Errorf("CTE reported as unresolved: %+v", ref)
+		}
+	}
+}
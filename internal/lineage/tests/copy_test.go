@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"testing"
+
+	"go-metadata/internal/lineage"
+)
+
+func TestCopy_FromFile(t *testing.T) {
+	catalog := NewMockCatalog()
+	catalog.AddTable("public", "orders", []string{"id", "amount"})
+	analyzer := lineage.NewAnalyzer(catalog)
+
+	result, err := analyzer.Analyze(`COPY public.orders FROM '/data/orders.csv' WITH (FORMAT csv, HEADER, DELIMITER '|')`)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Columns) != 2 {
+		t.Fatalf("columns = %+v, want id and amount", result.Columns)
+	}
+	col := result.Columns[1]
+	if col.Target.QualifiedName() != "public.orders.amount" || len(col.Sources) != 1 || col.Sources[0].QualifiedName() != "/data/orders.csv.amount" {
+		t.Errorf("column = %+v", col)
+	}
+	if len(result.Loads) != 1 {
+		t.Fatalf("loads = %+v, want 1", result.Loads)
+	}
+	load := result.Loads[0]
+	if load.Location != "/data/orders.csv" || load.Format.Type != "CSV" || load.Format.Options["HEADER"] != "true" || load.Format.Options["DELIMITER"] != "|" {
+		t.Errorf("load = %+v", load)
+	}
+}
+
+func TestCopy_LegacyOptionsAndColumns(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+
+	result, err := analyzer.Analyze(`\copy orders (id, amount) from 'orders.csv' with csv header delimiter as ';'`)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Columns) != 2 || result.Columns[0].Sources[0].QualifiedName() != "orders.csv.id" {
+		t.Errorf("columns = %+v", result.Columns)
+	}
+	if format := result.Loads[0].Format; format.Type != "CSV" || format.Options["DELIMITER"] != ";" || format.Options["HEADER"] != "true" {
+		t.Errorf("format = %+v", format)
+	}
+
+	// Loads from the client have no source dataset
+	result, err = analyzer.Analyze("COPY orders (id) FROM STDIN")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Columns) != 1 || len(result.Columns[0].Sources) != 0 || result.Loads[0].Location != "stdin" || result.Loads[0].Format.Type != "TEXT" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestCopy_ToFile(t *testing.T) {
+	catalog := NewMockCatalog()
+	catalog.AddTable("", "orders", []string{"id", "amount"})
+	analyzer := lineage.NewAnalyzer(catalog)
+
+	result, err := analyzer.Analyze("COPY orders TO 's3://lake/exports/orders.csv' CSV HEADER")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Columns) != 2 || result.Columns[0].Target.QualifiedName() != "s3.lake/exports/orders.csv.id" || result.Columns[0].Sources[0].QualifiedName() != "orders.id" {
+		t.Errorf("columns = %+v", result.Columns)
+	}
+	if len(result.Loads) != 0 {
+		t.Errorf("loads = %+v, want none for an export", result.Loads)
+	}
+
+	result, err = analyzer.Analyze("COPY (SELECT id, amount * 100 AS cents FROM orders) TO '/tmp/cents.csv'")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Columns) != 2 {
+		t.Fatalf("columns = %+v, want id and cents", result.Columns)
+	}
+	if cents := result.Columns[1]; cents.Target.QualifiedName() != "/tmp/cents.csv.cents" || cents.Sources[0].QualifiedName() != "orders.amount" {
+		t.Errorf("cents = %+v", cents)
+	}
+
+	result, err = analyzer.Analyze("COPY orders TO STDOUT")
+	if err != nil || len(result.Columns) != 0 {
+		t.Errorf("COPY TO STDOUT = %+v, %v, want no lineage", result, err)
+	}
+}
+
+func TestCopy_Script(t *testing.T) {
+	script := `\set ON_ERROR_STOP on
+CREATE TABLE staging (id INT, amount INT);
+COPY staging (id, amount) FROM stdin;
+1	10
+2	20
+\.
+\copy staging from 'more.csv' csv
+INSERT INTO orders SELECT id, amount FROM staging;
+`
+	stmts := lineage.SplitStatements(script)
+	if len(stmts) != 4 || stmts[2] != `\copy staging from 'more.csv' csv` {
+		t.Fatalf("statements = %q", stmts)
+	}
+
+	result, err := lineage.NewAnalyzer(nil).AnalyzeScript(script)
+	if err != nil {
+		t.Fatalf("AnalyzeScript failed: %v", err)
+	}
+	if len(result.Loads) != 2 || result.Loads[1].Location != "more.csv" {
+		t.Errorf("loads = %+v", result.Loads)
+	}
+	if len(result.Skipped) != 0 {
+		t.Errorf("skipped = %+v", result.Skipped)
+	}
+}
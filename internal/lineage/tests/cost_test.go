@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"go-metadata/internal/lineage"
+	"testing"
+)
+
+func TestScanFilters(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want map[string]string
+	}{
+		{"SELECT id FROM dw.orders o WHERE o.dt = '2024-01-01' AND 'eu' = region AND amount > 10", map[string]string{"dt": "2024-01-01", "region": "eu"}},
+		{"SELECT id FROM dw.orders WHERE dt = '2024-01-01' OR region = 'eu'", nil},
+		{"SELECT id FROM dw.orders WHERE NOT dt = '2024-01-01' AND region = 'eu'", nil},
+		{"SELECT id FROM dw.orders WHERE note = 'this or that' AND dt = 'o''neil'", map[string]string{"note": "this or that", "dt": "o'neil"}},
+		{"SELECT id FROM dw.orders WHERE dt IN ('2024-01-01', '2024-01-02')", nil},
+	}
+	for _, tt := range tests {
+		result, err := lineage.NewAnalyzer(nil).Analyze(tt.sql)
+		if err != nil {
+			t.Fatalf("Analyze(%q) failed: %v", tt.sql, err)
+		}
+		if len(result.Scans) != 1 || result.Scans[0].TableName() != "dw.orders" {
+			t.Fatalf("%s: expected a scan of dw.orders, got %+v", tt.sql, result.Scans)
+		}
+		got := result.Scans[0].Filters
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: filters = %v, want %v", tt.sql, got, tt.want)
+			continue
+		}
+		for k, v := range tt.want {
+			if got[k] != v {
+				t.Errorf("%s: filters = %v, want %v", tt.sql, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestScanFilters_Subqueries(t *testing.T) {
+	sql := `WITH recent AS (SELECT user_id FROM dw.orders WHERE dt = '2024-01-01')
+		SELECT u.name FROM dim.users u JOIN recent r ON u.id = r.user_id WHERE u.country = 'DE'`
+	result, err := lineage.NewAnalyzer(nil).Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	filters := make(map[string]map[string]string)
+	for _, scan := range result.Scans {
+		filters[scan.TableName()] = scan.Filters
+	}
+	if len(result.Scans) != 2 || filters["dw.orders"]["dt"] != "2024-01-01" || filters["dim.users"]["country"] != "DE" {
+		t.Errorf("Unexpected scans %+v", result.Scans)
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	stats := lineage.StatsMap{
+		// Known partitions
+		"dw.orders": {
+			RowCount:         3000,
+			DataSizeBytes:    30000,
+			PartitionColumns: []string{"dt", "region"},
+			Partitions: []lineage.PartitionStats{
+				{Values: []string{"2024-01-01", "eu"}, RowCount: 100, DataSizeBytes: 1000},
+				{Values: []string{"2024-01-01", "us"}, RowCount: 400, DataSizeBytes: 4000},
+				{Values: []string{"2024-01-02", "eu"}, RowCount: 2500, DataSizeBytes: 25000},
+			},
+		},
+		// Only the partition count and distinct counts
+		"dw.events": {
+			RowCount:         1000000,
+			DataSizeBytes:    1 << 30,
+			PartitionColumns: []string{"dt", "hour"},
+			PartitionCount:   240,
+			DistinctCounts:   map[string]int64{"dt": 10},
+		},
+		"dim.users": {RowCount: 50, DataSizeBytes: 500},
+	}
+
+	tests := []struct {
+		sql        string
+		rows       int64
+		partitions int
+	}{
+		{"SELECT id FROM dw.orders WHERE dt = '2024-01-01'", 500, 2},
+		{"SELECT id FROM dw.orders WHERE dt = '2024-01-01' AND region = 'eu'", 100, 1},
+		{"SELECT id FROM dw.orders WHERE amount > 0", 3000, 3},
+		{"SELECT id FROM dw.orders WHERE dt = '2023-12-31'", 0, 0},
+		{"SELECT id FROM dw.events WHERE dt = '2024-01-01'", 100000, 24},
+		{"SELECT id FROM dw.events WHERE dt = '2024-01-01' AND hour = 3", 4167, 1},
+		{"SELECT id FROM dw.events WHERE hour = 3", 1000000, 240},
+		{"SELECT name FROM users", 50, 0},
+	}
+	for _, tt := range tests {
+		result, err := lineage.NewAnalyzer(nil).Analyze(tt.sql)
+		if err != nil {
+			t.Fatalf("Analyze(%q) failed: %v", tt.sql, err)
+		}
+		estimate := lineage.EstimateCost(result, stats)
+		if estimate.Rows != tt.rows || len(estimate.Scans) != 1 || estimate.Scans[0].Partitions != tt.partitions {
+			t.Errorf("%s: unexpected estimate %+v", tt.sql, estimate)
+		}
+	}
+}
+
+func TestEstimateCost_Unknown(t *testing.T) {
+	sql := "SELECT o.id FROM dw.orders o JOIN dw.payments p ON o.id = p.order_id"
+	result, err := lineage.NewAnalyzer(nil).Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	estimate := lineage.EstimateCost(result, lineage.StatsMap{"dw.orders": {RowCount: 10, DataSizeBytes: 100}})
+	if estimate.Rows != 10 || estimate.Bytes != 100 {
+		t.Errorf("Unexpected totals %+v", estimate)
+	}
+	if unknown := estimate.Unknown(); len(unknown) != 1 || unknown[0] != "dw.payments" {
+		t.Errorf("Expected dw.payments to be unknown, got %v", unknown)
+	}
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"go-metadata/internal/lineage"
+	"testing"
+)
+
+// findNode returns the first node of the tree, depth first, matching match.
+func findNode(n *lineage.ParseNode, match func(*lineage.ParseNode) bool) *lineage.ParseNode {
+	if n == nil || match(n) {
+		return n
+	}
+	for _, child := range n.Children {
+		if found := findNode(child, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func TestExplain(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+
+	sql := "SELECT id FROM orders"
+	tree, err := analyzer.Explain(sql)
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if len(tree.Errors) != 0 || tree.Rewritten != "" {
+		t.Errorf("Explain(%q) errors = %v, rewritten = %q", sql, tree.Errors, tree.Rewritten)
+	}
+	if tree.Root == nil || tree.Root.Rule != "sqlStatements" {
+		t.Fatalf("root = %+v, want sqlStatements", tree.Root)
+	}
+	if tree.Root.Start != 0 || tree.Root.Stop != len(sql) {
+		t.Errorf("root span = %d-%d, want 0-%d", tree.Root.Start, tree.Root.Stop, len(sql))
+	}
+	if findNode(tree.Root, func(n *lineage.ParseNode) bool { return n.Rule == "selectStatement" }) == nil {
+		t.Error("no selectStatement node")
+	}
+	orders := findNode(tree.Root, func(n *lineage.ParseNode) bool { return n.Text == "orders" })
+	if orders == nil {
+		t.Fatal("no token orders")
+	}
+	if orders.Token != "IDENTIFIER" || sql[orders.Start:orders.Stop] != "orders" || orders.Line != 1 || orders.Column != 15 {
+		t.Errorf("orders = %+v", orders)
+	}
+}
+
+func TestExplain_Errors(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+
+	tree, err := analyzer.Explain("SELECT a FROM t WHERE (")
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if len(tree.Errors) == 0 {
+		t.Fatal("no syntax errors")
+	}
+	if e := tree.Errors[0]; e.Message == "" || e.Line != 1 || e.Start != 23 {
+		t.Errorf("error = %+v", e)
+	}
+	if tree.Root == nil {
+		t.Error("no recovered tree")
+	}
+}
+
+func TestExplain_Spark(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+
+	tree, err := analyzer.Explain("SELECT id FROM orders DISTRIBUTE BY id")
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if len(tree.Errors) != 0 || tree.Rewritten == "" {
+		t.Errorf("errors = %v, rewritten = %q, want the rewrite explained", tree.Errors, tree.Rewritten)
+	}
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"errors"
+	"go-metadata/internal/lineage"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var copyInto = regexp.MustCompile(`(?is)^copy\s+into\s+(\S+)\s+from\s+(\S+)`)
+
+// registerCopyInto registers a handler of Snowflake COPY INTO table FROM
+// table or @stage statements.
+func registerCopyInto(analyzer *lineage.Analyzer) {
+	analyzer.RegisterStatement("copy into", lineage.MatchKeywords("COPY", "INTO"), func(sql string, catalog lineage.Catalog) (*lineage.LineageResult, error) {
+		m := copyInto.FindStringSubmatch(sql)
+		if m == nil {
+			return nil, lineage.ErrUnsupportedSQL
+		}
+		return lineage.CopyTable(catalog, lineage.ParseTableRef(m[1]), lineage.ParseTableRef(m[2])), nil
+	})
+}
+
+func TestRegisterStatement(t *testing.T) {
+	catalog := NewMockCatalog()
+	catalog.AddTable("dw", "orders", []string{"id", "amount"})
+	analyzer := lineage.NewAnalyzer(catalog)
+	registerCopyInto(analyzer)
+
+	result, err := analyzer.Analyze("-- nightly load\ncopy  INTO dw.orders FROM @raw.orders_stage;")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Columns) != 2 {
+		t.Fatalf("columns = %+v, want id and amount", result.Columns)
+	}
+	col := result.Columns[1]
+	if col.Target.QualifiedName() != "dw.orders.amount" || len(col.Sources) != 1 || col.Sources[0].QualifiedName() != "@raw.orders_stage.amount" {
+		t.Errorf("column = %+v", col)
+	}
+
+	// Without the table in the catalog, a * column
+	result, err = analyzer.Analyze(`COPY INTO "dw"."events" FROM raw.events`)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Columns) != 1 || result.Columns[0].Target.QualifiedName() != "dw.events.*" || len(result.Unresolved) != 1 {
+		t.Errorf("result = %+v", result)
+	}
+
+	// Other statements are parsed by the grammar
+	result, err = analyzer.Analyze("INSERT INTO dw.orders SELECT id, amount FROM raw.orders")
+	if err != nil || len(result.Columns) != 2 {
+		t.Errorf("Analyze = %+v, %v", result, err)
+	}
+}
+
+func TestRegisterStatement_Script(t *testing.T) {
+	catalog := NewMockCatalog()
+	catalog.AddTable("raw", "orders", []string{"id", "amount"})
+	analyzer := lineage.NewAnalyzer(catalog)
+	registerCopyInto(analyzer)
+
+	// EXPORT TO writes the result of its query to a location
+	analyzer.RegisterStatement("export to", lineage.MatchKeywords("EXPORT", "TO"), func(sql string, catalog lineage.Catalog) (*lineage.LineageResult, error) {
+		location, query, ok := strings.Cut(sql[len("EXPORT TO"):], " AS ")
+		if !ok {
+			return nil, errors.New("missing AS query")
+		}
+		result, err := lineage.NewAnalyzer(catalog).Analyze(query)
+		if err != nil {
+			return nil, err
+		}
+		for i := range result.Columns {
+			result.Columns[i].Target.Table = strings.Trim(strings.TrimSpace(location), "'")
+		}
+		return result, nil
+	})
+
+	script := `CREATE TABLE staging (id INT, amount DECIMAL);
+COPY INTO staging FROM raw.orders;
+EXPORT TO 's3://exports/orders' AS SELECT id, amount FROM staging;`
+	result, err := analyzer.Analyze(script)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	var exported []string
+	for _, col := range result.Columns {
+		if col.Target.Table == "s3://exports/orders" {
+			exported = append(exported, col.Target.Column+"<-"+col.Sources[0].QualifiedName())
+		}
+	}
+	if strings.Join(exported, ",") != "id<-staging.id,amount<-staging.amount" {
+		t.Errorf("exported = %v, columns = %+v", exported, result.Columns)
+	}
+
+	// Errors of handlers name them
+	_, err = analyzer.Analyze("EXPORT TO 's3://exports/orders'")
+	if err == nil || !strings.Contains(err.Error(), "export to: missing AS query") {
+		t.Errorf("Analyze error = %v", err)
+	}
+}
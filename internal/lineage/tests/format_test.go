@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"go-metadata/internal/lineage"
+	"strings"
+	"testing"
+)
+
+func formatResults(t *testing.T) []*lineage.LineageResult {
+	t.Helper()
+	catalog := NewMockCatalog()
+	catalog.AddTable("", "orders", []string{"id", "amount"})
+	analyzer := lineage.NewAnalyzer(catalog)
+
+	var results []*lineage.LineageResult
+	for _, sql := range []string{
+		"INSERT INTO daily SELECT id, amount * 2 AS total FROM orders",
+		// Repeated edges are drawn once
+		"INSERT INTO daily SELECT id, amount * 2 AS total FROM orders",
+		`INSERT INTO report SELECT d.total, x.note FROM daily d JOIN notes x ON d.id = x.id`,
+	} {
+		result, err := analyzer.Analyze(sql)
+		if err != nil {
+			t.Fatalf("Analyze(%q) failed: %v", sql, err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func TestWriteDOT(t *testing.T) {
+	var b strings.Builder
+	if err := lineage.WriteDOT(&b, formatResults(t)...); err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph lineage {
+    rankdir=LR;
+    node [shape=box];
+    subgraph cluster_0 {
+        label="daily";
+        n0 [label="id"];
+        n2 [label="total"];
+    }
+    subgraph cluster_1 {
+        label="orders";
+        n1 [label="id"];
+        n3 [label="amount"];
+    }
+    subgraph cluster_2 {
+        label="report";
+        n4 [label="total"];
+        n5 [label="note"];
+    }
+    subgraph cluster_3 {
+        label="notes";
+        n6 [label="note"];
+    }
+    n1 -> n0;
+    n3 -> n2;
+    n2 -> n4 [style=dashed];
+    n6 -> n5 [style=dashed];
+}
+`
+	if b.String() != want {
+		t.Errorf("WriteDOT =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var b strings.Builder
+	if err := lineage.WriteMermaid(&b, formatResults(t)...); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"flowchart LR\n",
+		"    subgraph t0[\"daily\"]\n        n0[\"id\"]\n        n2[\"total\"]\n    end\n",
+		"    n1 --> n0\n",
+		"    n3 --> n2\n",
+		"    n6 -.-> n5\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteMermaid missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "n1 --> n0") != 1 {
+		t.Errorf("repeated edge written more than once:\n%s", out)
+	}
+
+	// Query columns and quotes in names
+	b.Reset()
+	result := &lineage.LineageResult{Columns: []lineage.ColumnLineage{{
+		Target:  lineage.ColumnRef{Column: `say "hi"`},
+		Sources: []lineage.ColumnRef{{Table: "t", Column: "c", Confidence: lineage.ConfidenceCatalog}},
+	}}}
+	if err := lineage.WriteMermaid(&b, result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `subgraph t0["(query)"]`) || !strings.Contains(b.String(), `n0["say #quot;hi#quot;"]`) {
+		t.Errorf("WriteMermaid =\n%s", b.String())
+	}
+}
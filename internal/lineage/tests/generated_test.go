@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"go-metadata/internal/lineage"
+	"testing"
+)
+
+func TestGenerated_ColumnsFromBaseColumns(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+	result := analyzer.AnalyzeGenerated("shop", "orders", []lineage.GeneratedColumn{
+		{Name: "total", Expression: "(`price` * `qty`)"},
+		{Name: "full_name", Expression: "concat(`first_name`,_utf8mb4' ',`last_name`)"},
+		{Name: "price_num", Expression: "(price)::numeric"},
+		{Name: "label", Expression: "upper([name])"},
+	})
+
+	assertColumnCount(t, result, 4)
+	assertColumnLineage(t, result, "total", []string{"orders.price", "orders.qty"}, nil)
+	assertColumnLineage(t, result, "full_name", []string{"orders.first_name", "orders.last_name"}, nil)
+	assertColumnLineage(t, result, "price_num", []string{"orders.price"}, nil)
+	assertColumnLineage(t, result, "label", []string{"orders.name"}, nil)
+	for _, col := range result.Columns {
+		if col.Target.Database != "shop" || col.Target.Table != "orders" {
+			t.Errorf("Unexpected target %+v", col.Target)
+		}
+		for _, src := range col.Sources {
+			if src.Database != "shop" {
+				t.Errorf("Expected source %s in database shop, got %q", src.Column, src.Database)
+			}
+		}
+	}
+}
+
+func TestGenerated_UnparsableExpression(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+	result := analyzer.AnalyzeGenerated("", "t", []lineage.GeneratedColumn{
+		{Name: "ok", Expression: "a + b"},
+		{Name: "bad", Expression: "a +* )"},
+	})
+
+	assertColumnCount(t, result, 1)
+	if len(result.Unresolved) != 1 || result.Unresolved[0].Column != "bad" {
+		t.Errorf("Expected the bad expression as unresolved, got %+v", result.Unresolved)
+	}
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/metadata"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the lineage golden files")
+
+// goldenStatement is the recorded analysis of one statement of a corpus file.
+type goldenStatement struct {
+	SQL     string                 `json:"sql"`
+	Lineage *lineage.LineageResult `json:"lineage,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+}
+
+// TestGolden analyzes every statement of the SQL corpus in testdata/<dialect>
+// and compares the lineage with the <name>.golden.json file next to it. Tables
+// created by the DDL of a file make up the catalog of its statements.
+//
+// Run with -update to rewrite the golden files after intended changes:
+//
+//	go test ./internal/lineage/tests -run TestGolden -update
+func TestGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "testdata", "*", "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("No SQL files in the corpus")
+	}
+
+	for _, file := range files {
+		dialect := filepath.Base(filepath.Dir(file))
+		name := strings.TrimSuffix(filepath.Base(file), ".sql")
+		t.Run(dialect+"/"+name, func(t *testing.T) {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := analyzeCorpus(t, string(content))
+
+			golden := strings.TrimSuffix(file, ".sql") + ".golden.json"
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Missing golden file, run with -update to create it: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Lineage differs from %s, run with -update if the change is intended:\n%s",
+					golden, diffLines(string(want), string(got)))
+			}
+		})
+	}
+}
+
+func analyzeCorpus(t *testing.T, content string) []byte {
+	t.Helper()
+	catalog := metadata.NewMetadataBuilder().LoadFromDDL(content).BuildCatalog()
+	analyzer := lineage.NewAnalyzer(catalog)
+
+	statements := []goldenStatement{}
+	for _, sql := range splitStatements(content) {
+		stmt := goldenStatement{SQL: sql}
+		result, err := analyzer.Analyze(sql)
+		if err != nil {
+			stmt.Error = err.Error()
+		} else {
+			stmt.Lineage = result
+		}
+		statements = append(statements, stmt)
+	}
+
+	data, err := json.MarshalIndent(statements, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(data, '\n')
+}
+
+// diffLines returns the first differing line of want and got with its line
+// number, which is enough to locate the change in the golden file.
+func diffLines(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return "line " + strconv.Itoa(i+1) + ":\n- " + w + "\n+ " + g
+		}
+	}
+	return ""
+}
@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"go-metadata/internal/lineage"
+	"testing"
+	"time"
+)
+
+func analyzeForGraph(t *testing.T, analyzer *lineage.Analyzer, sql string) *lineage.LineageResult {
+	t.Helper()
+	result, err := analyzer.Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	return result
+}
+
+func TestGraph_DeduplicatesRepeatedStatements(t *testing.T) {
+	catalog := NewMockCatalog()
+	catalog.AddTable("", "orders", []string{"id", "amount", "user_id"})
+	analyzer := lineage.NewAnalyzer(catalog)
+
+	sqlA := "INSERT INTO report(total) SELECT SUM(amount) FROM orders WHERE id > 10"
+	sqlB := "insert into report(total)\n  select sum(amount) from orders where id > 20"
+
+	t1 := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(24 * time.Hour)
+
+	g := lineage.NewGraph()
+	g.Add(analyzeForGraph(t, analyzer, sqlA), lineage.Fingerprint(sqlA), t1)
+	g.Add(analyzeForGraph(t, analyzer, sqlB), lineage.Fingerprint(sqlB), t2)
+	g.Add(analyzeForGraph(t, analyzer, sqlA), lineage.Fingerprint(sqlA), t2)
+
+	if g.Len() != 1 {
+		t.Fatalf("Expected 1 edge, got %d", g.Len())
+	}
+
+	edge := g.Edges()[0]
+	if edge.Source.QualifiedName() != "orders.amount" || edge.Target.QualifiedName() != "report.total" {
+		t.Errorf("Unexpected edge %s", edge.Key())
+	}
+	if edge.Provenance.Occurrences != 3 {
+		t.Errorf("Expected 3 occurrences, got %d", edge.Provenance.Occurrences)
+	}
+	if !edge.Provenance.FirstSeen.Equal(t1) || !edge.Provenance.LastSeen.Equal(t2) {
+		t.Errorf("Unexpected first/last seen: %v / %v", edge.Provenance.FirstSeen, edge.Provenance.LastSeen)
+	}
+	if len(edge.Provenance.Fingerprints) != 1 {
+		t.Errorf("Expected literal variants to share a fingerprint, got %v", edge.Provenance.Fingerprints)
+	}
+}
+
+func TestGraph_CountsEdgeOncePerStatement(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+	sql := "SELECT amount + amount AS doubled FROM orders"
+
+	g := lineage.NewGraph()
+	g.Add(analyzeForGraph(t, analyzer, sql), lineage.Fingerprint(sql), time.Now())
+
+	if g.Len() != 1 {
+		t.Fatalf("Expected 1 edge, got %d", g.Len())
+	}
+	if g.Edges()[0].Provenance.Occurrences != 1 {
+		t.Errorf("Expected 1 occurrence, got %d", g.Edges()[0].Provenance.Occurrences)
+	}
+}
+
+func TestGraph_AddExecutions(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+	sql := "INSERT INTO report(total) SELECT amount FROM orders"
+	at := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	g := lineage.NewGraph()
+	g.AddExecutions(analyzeForGraph(t, analyzer, sql), lineage.Fingerprint(sql), lineage.OriginQueryLog, at, 42)
+	g.AddExecutions(analyzeForGraph(t, analyzer, sql), lineage.Fingerprint(sql), lineage.OriginQueryLog, at.Add(time.Hour), 8)
+
+	if g.Len() != 1 {
+		t.Fatalf("Expected 1 edge, got %d", g.Len())
+	}
+	p := g.Edges()[0].Provenance
+	if p.Occurrences != 50 || !p.LastSeen.Equal(at.Add(time.Hour)) {
+		t.Errorf("Expected 50 occurrences last seen at %s, got %d at %s", at.Add(time.Hour), p.Occurrences, p.LastSeen)
+	}
+	if len(p.Origins) != 1 || p.Origins[0] != lineage.OriginQueryLog {
+		t.Errorf("Expected the query log origin, got %v", p.Origins)
+	}
+}
+
+func TestReplacePositionalParams(t *testing.T) {
+	tests := []struct {
+		sql, want string
+	}{
+		{"SELECT id FROM orders WHERE id = $1 LIMIT $12", "SELECT id FROM orders WHERE id = ? LIMIT ?"},
+		{"SELECT '$1', \"a$2\" FROM t -- $3", "SELECT '$1', \"a$2\" FROM t -- $3"},
+		{"SELECT $$ $1 $$, $q$ $2 $q$ /* $3 */ FROM t", "SELECT $$ $1 $$, $q$ $2 $q$ /* $3 */ FROM t"},
+		{"SELECT sys$1 FROM t", "SELECT sys$1 FROM t"},
+	}
+	for _, tt := range tests {
+		if got := lineage.ReplacePositionalParams(tt.sql); got != tt.want {
+			t.Errorf("ReplacePositionalParams(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+
+	// Statements of pg_stat_statements are analyzed with ? markers
+	sql := lineage.ReplacePositionalParams("INSERT INTO report(total) SELECT amount FROM orders WHERE id > $1")
+	if result := analyzeForGraph(t, lineage.NewAnalyzer(nil), sql); len(result.Columns) != 1 {
+		t.Errorf("Expected 1 column, got %+v", result.Columns)
+	}
+}
+
+func TestGraph_Merge(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+	t1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	sqlA := "SELECT id FROM users"
+	sqlB := "SELECT id, name FROM users"
+
+	a := lineage.NewGraph()
+	a.Add(analyzeForGraph(t, analyzer, sqlA), lineage.Fingerprint(sqlA), t2)
+
+	b := lineage.NewGraph()
+	b.Add(analyzeForGraph(t, analyzer, sqlB), lineage.Fingerprint(sqlB), t1)
+
+	a.Merge(b)
+
+	if a.Len() != 2 {
+		t.Fatalf("Expected 2 edges, got %d", a.Len())
+	}
+	for _, edge := range a.Edges() {
+		if edge.Source.Column != "id" {
+			continue
+		}
+		if edge.Provenance.Occurrences != 2 {
+			t.Errorf("Expected 2 occurrences, got %d", edge.Provenance.Occurrences)
+		}
+		if !edge.Provenance.FirstSeen.Equal(t1) || !edge.Provenance.LastSeen.Equal(t2) {
+			t.Errorf("Unexpected first/last seen: %v / %v", edge.Provenance.FirstSeen, edge.Provenance.LastSeen)
+		}
+		if len(edge.Provenance.Fingerprints) != 2 {
+			t.Errorf("Expected 2 fingerprints, got %v", edge.Provenance.Fingerprints)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	same := []string{
+		"SELECT id FROM users WHERE name = 'alice' AND age > 30",
+		"select id\n from users -- comment\n where name = 'bob' and age > 41;",
+	}
+	if lineage.Fingerprint(same[0]) != lineage.Fingerprint(same[1]) {
+		t.Errorf("Expected equal fingerprints for %q and %q", same[0], same[1])
+	}
+	if lineage.Fingerprint("SELECT id FROM t1") == lineage.Fingerprint("SELECT id FROM t2") {
+		t.Error("Expected different fingerprints for different tables")
+	}
+}
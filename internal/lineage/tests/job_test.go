@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"errors"
+	"go-metadata/internal/lineage"
+	"testing"
+	"time"
+)
+
+func TestJob_AttachStatement(t *testing.T) {
+	catalog := NewMockCatalog()
+	catalog.AddTable("", "orders", []string{"id", "amount", "user_id"})
+	analyzer := lineage.NewAnalyzer(catalog)
+
+	g := lineage.NewGraph()
+	err := g.RegisterJob(&lineage.Job{
+		Name: "daily_report",
+		Type: lineage.JobTypeAirflowTask,
+	})
+	if err != nil {
+		t.Fatalf("RegisterJob failed: %v", err)
+	}
+
+	sql := "INSERT INTO report(user_id, total) SELECT user_id, SUM(amount) FROM orders GROUP BY user_id"
+	result := analyzeForGraph(t, analyzer, sql)
+	at := time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC)
+	if err := g.AttachStatement("daily_report", result, lineage.Fingerprint(sql), at); err != nil {
+		t.Fatalf("AttachStatement failed: %v", err)
+	}
+
+	job, err := g.Job("daily_report")
+	if err != nil {
+		t.Fatalf("Job failed: %v", err)
+	}
+	if len(job.Inputs) != 1 || job.Inputs[0] != "orders" {
+		t.Errorf("Expected inputs [orders], got %v", job.Inputs)
+	}
+	if len(job.Outputs) != 1 || job.Outputs[0] != "report" {
+		t.Errorf("Expected outputs [report], got %v", job.Outputs)
+	}
+	if len(job.Statements) != 1 || !job.LastRunAt.Equal(at) {
+		t.Errorf("Unexpected statements %v / last run %v", job.Statements, job.LastRunAt)
+	}
+
+	for _, edge := range g.Edges() {
+		if len(edge.Provenance.Jobs) != 1 || edge.Provenance.Jobs[0] != "daily_report" {
+			t.Errorf("Edge %s: expected job provenance, got %v", edge.Key(), edge.Provenance.Jobs)
+		}
+	}
+
+	if readers := g.JobsReading("orders"); len(readers) != 1 {
+		t.Errorf("Expected 1 job reading orders, got %d", len(readers))
+	}
+	if writers := g.JobsWriting("orders"); len(writers) != 0 {
+		t.Errorf("Expected no job writing orders, got %d", len(writers))
+	}
+}
+
+func TestJob_NotFound(t *testing.T) {
+	g := lineage.NewGraph()
+	err := g.AttachStatement("missing", &lineage.LineageResult{}, "", time.Now())
+	if !errors.Is(err, lineage.ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+	if err := g.RegisterJob(&lineage.Job{}); err == nil {
+		t.Error("Expected error for job without name")
+	}
+}
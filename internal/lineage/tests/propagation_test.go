@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"go-metadata/internal/lineage"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTagRules(t *testing.T) {
+	rules, err := lineage.ParseTagRules(strings.NewReader(`
+default:
+  direction: none
+rules:
+  - tag: pii
+    direction: downstream
+  - tag: finance
+    direction: downstream
+    max_hops: 2
+    exclude: ["sandbox.*"]
+`))
+	if err != nil {
+		t.Fatalf("ParseTagRules failed: %v", err)
+	}
+	if rule := rules.Rule("finance"); rule == nil || rule.MaxHops != 2 || len(rule.Exclude) != 1 {
+		t.Errorf("Unexpected finance rule: %+v", rule)
+	}
+	if rule := rules.Rule("internal"); rule != nil {
+		t.Errorf("Expected tags without a rule to follow the default, got %+v", rule)
+	}
+
+	for _, invalid := range []string{
+		"rules:\n  - tag: pii\n    direction: sideways\n",
+		"rules:\n  - direction: downstream\n",
+		"rules:\n  - tag: pii\n    direction: downstream\n  - tag: pii\n    direction: upstream\n",
+		"rules:\n  - tag: pii\n    direction: downstream\n    max_hops: -1\n",
+		"rules:\n  - tag: pii\n    direction: downstream\n    exclude: [\"[\"]\n",
+		"rule:\n  - tag: pii\n",
+	} {
+		if _, err := lineage.ParseTagRules(strings.NewReader(invalid)); err == nil {
+			t.Errorf("Expected an error for rules %q", invalid)
+		}
+	}
+}
+
+func TestTagPropagator_Downstream(t *testing.T) {
+	g := buildChainGraph(t)
+	p := lineage.NewTagPropagator(g, &lineage.TagRules{Rules: []*lineage.TagRule{
+		{Tag: "pii", Direction: lineage.Downstream},
+		{Tag: "finance", Direction: lineage.Downstream, MaxHops: 1},
+	}})
+	p.SetTags("raw_orders.amount", []string{"pii"})
+	p.SetTags("raw_orders", []string{"finance"})
+
+	changes := p.Evaluate()
+	if len(changes) != 4 {
+		t.Fatalf("Expected 4 tag changes, got %+v", changes)
+	}
+
+	tags := p.Tags("sales_report.total")
+	if len(tags) != 1 || tags[0].Tag != "pii" || tags[0].From != "raw_orders.amount" || tags[0].Hops != 3 {
+		t.Errorf("Expected pii to reach sales_report.total in 3 hops, got %+v", tags)
+	}
+	if tags := p.Tags("stg_orders"); len(tags) != 1 || tags[0].Tag != "finance" {
+		t.Errorf("Expected finance on stg_orders, got %+v", tags)
+	}
+	if tags := p.Tags("daily_sales"); len(tags) != 0 {
+		t.Errorf("Expected finance to stop after 1 hop, got %+v", tags)
+	}
+	if tags := p.Tags("raw_orders.amount"); len(tags) != 0 {
+		t.Errorf("Expected tags set on a node not to be propagated to itself, got %+v", tags)
+	}
+}
+
+func TestTagPropagator_UpstreamAndExclude(t *testing.T) {
+	g := buildChainGraph(t)
+	p := lineage.NewTagPropagator(g, &lineage.TagRules{
+		Default: &lineage.TagRule{Direction: lineage.PropagateNone},
+		Rules: []*lineage.TagRule{
+			{Tag: "certified", Direction: lineage.Upstream, Exclude: []string{"STG_*"}},
+		},
+	})
+	p.SetTags("sales_report", []string{"certified", "internal"})
+
+	if tags := p.Tags("daily_sales"); len(tags) != 1 || tags[0].Tag != "certified" {
+		t.Errorf("Expected certified upstream on daily_sales, got %+v", tags)
+	}
+	if tags := p.Tags("stg_orders"); len(tags) != 0 {
+		t.Errorf("Expected excluded stg_orders not to be tagged, got %+v", tags)
+	}
+	if tags := p.Tags("raw_orders"); len(tags) != 0 {
+		t.Errorf("Expected propagation to stop at excluded stg_orders, got %+v", tags)
+	}
+}
+
+func TestTagPropagator_Incremental(t *testing.T) {
+	g := buildChainGraph(t)
+	p := lineage.NewTagPropagator(g, &lineage.TagRules{Rules: []*lineage.TagRule{
+		{Tag: "pii", Direction: lineage.Downstream},
+	}})
+	p.SetTags("raw_orders.amount", []string{"pii"})
+	if changes := p.Evaluate(); len(changes) != 3 {
+		t.Fatalf("Expected 3 added tags, got %+v", changes)
+	}
+	if changes := p.Evaluate(); len(changes) != 0 {
+		t.Errorf("Expected nothing to re-evaluate, got %+v", changes)
+	}
+
+	// Retiring an edge removes the tag downstream of it
+	edge := g.Traverse("daily_sales.total", lineage.Upstream, 1)[0]
+	if err := g.Retire(edge.Source, edge.Target, time.Now()); err != nil {
+		t.Fatalf("Retire failed: %v", err)
+	}
+	p.LineageChanged()
+	changes := p.Evaluate()
+	if len(changes) != 2 || !changes[0].Removed || changes[0].Node != "daily_sales.total" {
+		t.Errorf("Expected pii to be removed from daily_sales and sales_report, got %+v", changes)
+	}
+
+	// Removing the tag removes what it propagated
+	p.SetTags("raw_orders.amount", nil)
+	changes = p.Evaluate()
+	if len(changes) != 1 || !changes[0].Removed || changes[0].Node != "stg_orders.amount" {
+		t.Errorf("Expected pii to be removed from stg_orders, got %+v", changes)
+	}
+
+	// Changing the rules re-evaluates the tags
+	p.SetTags("stg_orders.amount", []string{"pii"})
+	p.Evaluate()
+	p.SetRules(&lineage.TagRules{Rules: []*lineage.TagRule{
+		{Tag: "pii", Direction: lineage.Upstream},
+	}})
+	changes = p.Evaluate()
+	if len(changes) != 1 || changes[0].Node != "raw_orders.amount" || changes[0].Removed {
+		t.Errorf("Expected pii to propagate upstream to raw_orders, got %+v", changes)
+	}
+}
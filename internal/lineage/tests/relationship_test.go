@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"go-metadata/internal/lineage"
+	"testing"
+	"time"
+)
+
+func relationshipCatalog() *MockCatalog {
+	catalog := NewMockCatalog()
+	catalog.AddTable("", "orders", []string{"id", "user_id", "region", "amount"})
+	catalog.AddTable("", "users", []string{"id", "region", "name"})
+	catalog.AddTable("", "payments", []string{"order_id", "paid"})
+	return catalog
+}
+
+func TestJoinKeys_Extraction(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(relationshipCatalog())
+	result, err := analyzer.Analyze(`
+		SELECT o.id, u.name
+		FROM orders o
+		JOIN users u ON u.id = o.user_id AND o.region = u.region AND o.amount > 10
+		LEFT JOIN payments p ON p.order_id = o.id`)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	var got []string
+	for _, key := range result.JoinKeys {
+		got = append(got, key.Left.QualifiedName()+"="+key.Right.QualifiedName())
+	}
+	// Keys are normalized to table order; the filter on amount is no join key.
+	assertUsages(t, got, "orders.user_id=users.id", "orders.region=users.region", "orders.id=payments.order_id")
+}
+
+func TestJoinKeys_ImplicitJoinAndSameTable(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(relationshipCatalog())
+	result, err := analyzer.Analyze("SELECT o.id FROM orders o, users u WHERE o.user_id = u.id AND o.id = o.user_id")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.JoinKeys) != 1 || result.JoinKeys[0].Left.Column != "user_id" || result.JoinKeys[0].Right.Table != "users" {
+		t.Errorf("Unexpected join keys: %+v", result.JoinKeys)
+	}
+}
+
+func TestRelationshipStats(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(relationshipCatalog())
+	stats := lineage.NewRelationshipStats()
+	at := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, sql := range []string{
+		"SELECT o.id FROM orders o JOIN users u ON o.user_id = u.id",
+		"SELECT u.name FROM users u JOIN orders o ON u.id = o.user_id AND u.region = o.region",
+		"SELECT p.paid FROM payments p JOIN orders o ON p.order_id = o.id",
+	} {
+		result, err := analyzer.Analyze(sql)
+		if err != nil {
+			t.Fatalf("Analyze %q failed: %v", sql, err)
+		}
+		stats.Add(result, at.Add(time.Duration(i)*time.Hour))
+	}
+	stats.AddForeignKey(lineage.JoinKey{
+		Left:  lineage.ColumnRef{Table: "payments", Column: "order_id"},
+		Right: lineage.ColumnRef{Table: "orders", Column: "id"},
+	})
+
+	all := stats.Relationships("", 1)
+	if len(all) != 2 {
+		t.Fatalf("Expected 2 relationships, got %+v", all)
+	}
+	r := all[0]
+	if r.Left != "orders" || r.Right != "users" || r.Occurrences != 2 || !r.Suggested() || !r.LastSeen.Equal(at.Add(time.Hour)) {
+		t.Errorf("Unexpected orders/users relationship: %+v", r)
+	}
+	if len(r.Keys) != 2 || r.Keys[0].LeftColumn != "user_id" || r.Keys[0].RightColumn != "id" || r.Keys[0].Occurrences != 2 {
+		t.Errorf("Unexpected orders/users keys: %+v %+v", r.Keys[0], r.Keys[1])
+	}
+
+	// Foreign keys are kept below the occurrence threshold.
+	frequent := stats.Relationships("payments", 2)
+	if len(frequent) != 1 || !frequent[0].ForeignKey || frequent[0].Occurrences != 1 || !frequent[0].Keys[0].ForeignKey {
+		t.Errorf("Unexpected payments relationships: %+v", frequent)
+	}
+	if got := stats.Relationships("users", 3); len(got) != 0 {
+		t.Errorf("Expected no frequent users relationships, got %+v", got)
+	}
+}
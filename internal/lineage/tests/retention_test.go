@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"go-metadata/internal/lineage"
+	"testing"
+	"time"
+)
+
+func TestRetention_PrunesStaleQueryLogEdges(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	stale := "INSERT INTO a(x) SELECT x FROM old_source"
+	fresh := "INSERT INTO a(y) SELECT y FROM new_source"
+	manual := "INSERT INTO a(z) SELECT z FROM manual_source"
+
+	g := lineage.NewGraph()
+	g.AddFrom(analyzeForGraph(t, analyzer, stale), lineage.Fingerprint(stale), lineage.OriginQueryLog, now.Add(-120*24*time.Hour))
+	g.AddFrom(analyzeForGraph(t, analyzer, fresh), lineage.Fingerprint(fresh), lineage.OriginQueryLog, now.Add(-10*24*time.Hour))
+	g.Add(analyzeForGraph(t, analyzer, manual), lineage.Fingerprint(manual), now.Add(-365*24*time.Hour))
+
+	policies := []lineage.RetentionPolicy{{Origin: lineage.OriginQueryLog, MaxAge: 90 * 24 * time.Hour}}
+	stats := g.Prune(policies, now)
+
+	if stats.Examined != 3 || stats.Deleted != 1 || stats.Retired != 0 {
+		t.Errorf("Unexpected prune stats: %+v", stats)
+	}
+	for _, edge := range g.Edges() {
+		if edge.Source.Table == "old_source" {
+			t.Error("Stale query-log edge was not pruned")
+		}
+	}
+	if g.Stats().Edges != 2 {
+		t.Errorf("Expected 2 remaining edges, got %d", g.Stats().Edges)
+	}
+}
+
+func TestRetention_RetireKeepsHistory(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	seen := now.Add(-100 * 24 * time.Hour)
+
+	sql := "INSERT INTO a(x) SELECT x FROM b"
+	g := lineage.NewGraph()
+	g.AddFrom(analyzeForGraph(t, analyzer, sql), lineage.Fingerprint(sql), lineage.OriginQueryLog, seen)
+
+	stats := g.Prune([]lineage.RetentionPolicy{{MaxAge: 90 * 24 * time.Hour, Retire: true}}, now)
+	if stats.Retired != 1 || stats.Deleted != 0 {
+		t.Fatalf("Unexpected prune stats: %+v", stats)
+	}
+
+	size := g.Stats()
+	if size.Edges != 1 || size.CurrentEdges != 0 {
+		t.Errorf("Unexpected graph stats: %+v", size)
+	}
+	if len(g.AsOf(seen)) != 1 || len(g.AsOf(now)) != 0 {
+		t.Error("Retired edge should remain visible only before retirement")
+	}
+
+	// A second run does not retire the edge again.
+	if stats := g.Prune([]lineage.RetentionPolicy{{MaxAge: 90 * 24 * time.Hour, Retire: true}}, now); stats.Retired != 0 {
+		t.Errorf("Expected no further retirements, got %+v", stats)
+	}
+}
+
+func TestRetention_EdgeWithUncoveredOriginIsKept(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := now.Add(-200 * 24 * time.Hour)
+
+	sql := "INSERT INTO a(x) SELECT x FROM b"
+	result := analyzeForGraph(t, analyzer, sql)
+
+	g := lineage.NewGraph()
+	g.AddFrom(result, lineage.Fingerprint(sql), lineage.OriginQueryLog, old)
+	g.Add(result, lineage.Fingerprint(sql), old)
+
+	stats := g.Prune([]lineage.RetentionPolicy{{Origin: lineage.OriginQueryLog, MaxAge: time.Hour}}, now)
+	if stats.Deleted != 0 || g.Stats().Edges != 1 {
+		t.Errorf("Edge also produced by analysis should be kept, stats %+v", stats)
+	}
+}
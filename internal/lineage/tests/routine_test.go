@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"errors"
+	"go-metadata/internal/lineage"
+	"testing"
+)
+
+func TestAnalyzeRoutine_MySQLCursorLoop(t *testing.T) {
+	script := `
+DELIMITER //
+CREATE DEFINER=etl@localhost PROCEDURE load_totals(IN p_date DATE)
+BEGIN
+  DECLARE done INT DEFAULT 0;
+  DECLARE v_user, v_amount INT;
+  DECLARE cur CURSOR FOR
+    SELECT user_id, SUM(amount) FROM orders WHERE dt = p_date GROUP BY user_id;
+  DECLARE CONTINUE HANDLER FOR NOT FOUND SET done = 1;
+  OPEN cur;
+  read_loop: LOOP
+    FETCH cur INTO v_user, v_amount;
+    IF done THEN
+      LEAVE read_loop;
+    END IF;
+    INSERT INTO user_totals (user_id, total, dt) VALUES (v_user, v_amount * 100, p_date);
+  END LOOP;
+  CLOSE cur;
+END //
+DELIMITER ;
+`
+	result, err := lineage.NewAnalyzer(nil).Analyze(script)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	printLineageResult(t, script, result)
+
+	assertColumnCount(t, result, 3)
+	assertTargetTable(t, result, "user_totals")
+	assertColumnLineage(t, result, "user_id", []string{"orders.user_id"}, []string{"v_user", "user_id"})
+	assertColumnLineage(t, result, "total", []string{"orders.amount"}, []string{"v_amount * 100", "SUM(amount)"})
+	// Parameters have no lineage
+	assertColumnLineage(t, result, "dt", []string{}, nil)
+	if len(result.Scans) != 1 || result.Scans[0].Table != "orders" {
+		t.Errorf("Expected a scan of orders, got %+v", result.Scans)
+	}
+}
+
+func TestAnalyzeRoutine_Variables(t *testing.T) {
+	script := `
+CREATE PROCEDURE refresh_stats()
+BEGIN
+  DECLARE v_max DECIMAL(10, 2);
+  SELECT MAX(total) INTO v_max FROM user_totals;
+  SET @v_avg = (SELECT AVG(total) FROM user_totals);
+  UPDATE stats s SET s.max_total = v_max, avg_total = @v_avg WHERE id = 1;
+END
+`
+	result, err := lineage.NewAnalyzer(nil).Analyze(script)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	printLineageResult(t, script, result)
+
+	assertColumnCount(t, result, 2)
+	assertTargetTable(t, result, "stats")
+	assertColumnLineage(t, result, "max_total", []string{"user_totals.total"}, nil)
+	assertColumnLineage(t, result, "avg_total", []string{"user_totals.total"}, nil)
+}
+
+func TestAnalyzeRoutine_HiveForLoop(t *testing.T) {
+	script := `
+CREATE PROCEDURE load_dim_user(IN ds STRING)
+BEGIN
+  FOR r IN (SELECT id, name FROM ods.users WHERE dt = ds) LOOP
+    INSERT INTO dim_user SELECT r.id, UPPER(r.name) AS name;
+  END LOOP;
+  INSERT OVERWRITE TABLE user_count SELECT COUNT(id) AS users FROM dim_user;
+END;
+`
+	result, err := lineage.NewAnalyzer(nil).Analyze(script)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	printLineageResult(t, script, result)
+
+	assertColumnCount(t, result, 3)
+	assertColumnLineage(t, result, "id", []string{"users.id"}, nil)
+	assertColumnLineage(t, result, "name", []string{"users.name"}, nil)
+	assertColumnLineage(t, result, "users", []string{"dim_user.id"}, nil)
+}
+
+func TestAnalyzeRoutine_TemporaryTable(t *testing.T) {
+	script := `
+CREATE PROCEDURE rebuild()
+BEGIN
+  DROP TEMPORARY TABLE IF EXISTS tmp_sales;
+  CREATE TEMPORARY TABLE tmp_sales AS SELECT region, amount FROM sales;
+  WHILE (SELECT COUNT(*) FROM queue) > 0 DO
+    INSERT INTO region_sales SELECT region, SUM(amount) FROM tmp_sales GROUP BY region;
+  END WHILE;
+END
+`
+	result, err := lineage.NewAnalyzer(nil).Analyze(script)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	printLineageResult(t, script, result)
+
+	assertColumnCount(t, result, 2)
+	assertColumnLineage(t, result, "region", []string{"sales.region"}, nil)
+	assertColumnLineage(t, result, "_col1", []string{"sales.amount"}, nil)
+}
+
+func TestAnalyzeRoutine_WithoutBody(t *testing.T) {
+	_, err := lineage.NewAnalyzer(nil).Analyze("CREATE FUNCTION to_upper AS 'com.example.udf.ToUpper'")
+	if !errors.Is(err, lineage.ErrUnsupportedSQL) {
+		t.Errorf("Expected ErrUnsupportedSQL, got %v", err)
+	}
+}
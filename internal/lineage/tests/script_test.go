@@ -0,0 +1,213 @@
+package tests
+
+import (
+	"errors"
+	"go-metadata/internal/lineage"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "semicolons",
+			script: "INSERT INTO a SELECT x FROM b;\nINSERT INTO c SELECT y FROM d;\n",
+			want:   []string{"INSERT INTO a SELECT x FROM b", "INSERT INTO c SELECT y FROM d"},
+		},
+		{
+			name:   "last statement without semicolon",
+			script: "SELECT 1;\nSELECT 2",
+			want:   []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name:   "quotes and comments",
+			script: "SELECT 'a;b', \"c;d\", `e;f` -- g;h\nFROM t /* i;\nj */;\n-- only a comment;\n",
+			want:   []string{"SELECT 'a;b', \"c;d\", `e;f` -- g;h\nFROM t /* i;\nj */"},
+		},
+		{
+			name:   "escaped quotes",
+			script: "SELECT 'it''s;', 'it\\'s;' FROM t; SELECT 2",
+			want:   []string{"SELECT 'it''s;', 'it\\'s;' FROM t", "SELECT 2"},
+		},
+		{
+			name:   "dollar quotes",
+			script: "CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql;\nSELECT $1, $$;$$;",
+			want:   []string{"CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql", "SELECT $1, $$;$$"},
+		},
+		{
+			name:   "template tags",
+			script: "{% set cols = 'a;b' %}\nSELECT {{ cols }} FROM {{ ref('orders;') }};\n",
+			want:   []string{"{% set cols = 'a;b' %}\nSELECT {{ cols }} FROM {{ ref('orders;') }}"},
+		},
+		{
+			name:   "procedure body",
+			script: "CREATE PROCEDURE p()\nBEGIN\n  IF x THEN SELECT 1; END IF;\n  SELECT CASE WHEN y THEN 1 END;\n  BEGIN SELECT 2; END;\nEND;\nSELECT 3;\nBEGIN;\nSELECT 4;",
+			want:   []string{"CREATE PROCEDURE p()\nBEGIN\n  IF x THEN SELECT 1; END IF;\n  SELECT CASE WHEN y THEN 1 END;\n  BEGIN SELECT 2; END;\nEND", "SELECT 3", "BEGIN", "SELECT 4"},
+		},
+		{
+			name:   "delimiter",
+			script: "DELIMITER //\nCREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW SET NEW.x = 1; //\nDELIMITER ;\nSELECT 1;",
+			want:   []string{"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW SET NEW.x = 1;", "SELECT 1"},
+		},
+		{
+			name:   "go batch separators",
+			script: "SET NOCOUNT ON\r\nGO\r\nINSERT INTO a SELECT x FROM b\r\ngo 2\r\nSELECT 'GO'\r\n",
+			want:   []string{"SET NOCOUNT ON", "INSERT INTO a SELECT x FROM b", "SELECT 'GO'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lineage.SplitStatements(tt.script); !slices.Equal(got, tt.want) {
+				t.Errorf("SplitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanStatements_StopsOnError(t *testing.T) {
+	stop := errors.New("stop")
+	var seen []string
+	err := lineage.ScanStatements(strings.NewReader("SELECT 1; SELECT 2; SELECT 3;"), func(stmt string) error {
+		seen = append(seen, stmt)
+		if len(seen) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || len(seen) != 2 {
+		t.Errorf("Expected to stop after 2 statements, got %v and %q", err, seen)
+	}
+}
+
+func TestScanStatements_Analyze(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+	script := "INSERT INTO a SELECT x FROM b;\nINSERT INTO c SELECT y FROM d;\n"
+
+	var targets []string
+	err := lineage.ScanStatements(strings.NewReader(script), func(stmt string) error {
+		result, err := analyzer.Analyze(stmt)
+		if err != nil {
+			return err
+		}
+		for _, col := range result.Columns {
+			targets = append(targets, col.Target.QualifiedName())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(targets, []string{"a.x", "c.y"}) {
+		t.Errorf("Expected the lineage of both statements, got %v", targets)
+	}
+}
+
+func TestExtractComments(t *testing.T) {
+	sql := "-- owner: team-x\nSELECT /*+ BROADCAST(b) */ a.id, '-- not a comment' /* inline */\nFROM a /* sla=daily\n   tier: gold */ JOIN b ON a.id = b.id -- trailing\n"
+	want := []lineage.Comment{
+		{Text: "owner: team-x", Line: 1},
+		{Text: "inline", Line: 2, Block: true},
+		{Text: "sla=daily\n   tier: gold", Line: 3, Block: true},
+		{Text: "trailing", Line: 4},
+	}
+	if got := lineage.ExtractComments(sql); !slices.Equal(got, want) {
+		t.Errorf("ExtractComments() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSplitStatements_TrailingComment(t *testing.T) {
+	script := "-- owner: team-a\nINSERT INTO a SELECT x FROM b; -- sla: daily\nINSERT INTO c SELECT y FROM d;\n"
+	want := []string{
+		"-- owner: team-a\nINSERT INTO a SELECT x FROM b -- sla: daily",
+		"INSERT INTO c SELECT y FROM d",
+	}
+	if got := lineage.SplitStatements(script); !slices.Equal(got, want) {
+		t.Errorf("SplitStatements() = %q, want %q", got, want)
+	}
+}
+
+func TestAnalyze_PreserveComments(t *testing.T) {
+	sql := "-- owner: team-x\n-- Owner: team-y\n/* @sla = daily\n * loads the orders mart */\nINSERT INTO mart.orders SELECT id FROM ods.orders"
+
+	analyzer := lineage.NewAnalyzer(nil)
+	result, err := analyzer.Analyze(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Comments != nil || result.Annotations != nil {
+		t.Errorf("Expected no comments by default, got %v", result.Comments)
+	}
+
+	analyzer.SetPreserveComments(true)
+	result, err = analyzer.Analyze(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Comments) != 3 {
+		t.Errorf("Expected 3 comments, got %+v", result.Comments)
+	}
+	want := map[string]string{"owner": "team-x", "sla": "daily"}
+	if !maps.Equal(result.Annotations, want) {
+		t.Errorf("Annotations = %v, want %v", result.Annotations, want)
+	}
+	if len(result.Columns) != 1 {
+		t.Errorf("Expected the lineage to be extracted too, got %v", result.Columns)
+	}
+}
+
+func TestParseDatasetAnnotations(t *testing.T) {
+	comments := lineage.ExtractComments(`-- @owner team-data
+-- @owners alice, bob
+-- @tag finance daily
+/*
+ * @description Daily order facts.
+ * @deprecated
+ * @pii email, phone
+ * @column amount finance, gdpr
+ * @sla.tier: gold
+ */
+-- owner: not an @ annotation
+SELECT 1`)
+
+	got := lineage.ParseDatasetAnnotations(comments)
+	want := &lineage.DatasetAnnotations{
+		Owners:      []string{"team-data", "alice", "bob"},
+		Tags:        []string{"finance", "daily"},
+		Description: "Daily order facts.",
+		Deprecated:  true,
+		ColumnTags: map[string][]string{
+			"email":  {"pii"},
+			"phone":  {"pii"},
+			"amount": {"finance", "gdpr"},
+		},
+		Annotations: map[string]string{"sla.tier": "gold"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDatasetAnnotations() = %+v, want %+v", got, want)
+	}
+
+	if got := lineage.ParseDatasetAnnotations(lineage.ExtractComments("-- loads orders\nSELECT 1")); got != nil {
+		t.Errorf("Expected no annotations, got %+v", got)
+	}
+}
+
+func TestAnalyze_DatasetAnnotations(t *testing.T) {
+	result, err := lineage.NewAnalyzer(nil).Analyze("-- @owner team-data\n-- @pii email\nINSERT INTO mart.customers SELECT id, email FROM ods.customers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Dataset == nil || !slices.Equal(result.Dataset.Owners, []string{"team-data"}) {
+		t.Fatalf("Expected the dataset annotations without preserving comments, got %+v", result.Dataset)
+	}
+	if result.Comments != nil {
+		t.Errorf("Expected comments to be left out, got %+v", result.Comments)
+	}
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"errors"
+	"go-metadata/internal/lineage"
+	"testing"
+)
+
+func TestAnalyzeScript_TemporaryTable(t *testing.T) {
+	script := `
+-- daily region sales
+DROP TABLE IF EXISTS tmp_orders;
+CREATE TEMPORARY TABLE tmp_orders AS
+SELECT o.id, o.amount * 100 AS cents, c.region
+FROM ods.orders o JOIN ods.customers c ON o.customer_id = c.id;
+INSERT INTO dw.region_sales (region, total)
+SELECT region, SUM(cents) FROM tmp_orders GROUP BY region;
+`
+	result, err := lineage.NewAnalyzer(nil).Analyze(script)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	printLineageResult(t, script, result)
+
+	assertColumnCount(t, result, 2)
+	assertTargetTable(t, result, "region_sales")
+	assertColumnLineage(t, result, "region", []string{"customers.region"}, nil)
+	assertColumnLineage(t, result, "total", []string{"orders.amount"}, nil)
+	for _, scan := range result.Scans {
+		if scan.Table == "tmp_orders" {
+			t.Errorf("Temporary table should not be scanned: %+v", scan)
+		}
+	}
+}
+
+func TestAnalyzeScript_InsertIntoTemporaryTable(t *testing.T) {
+	script := `
+CREATE TEMPORARY TABLE staging (id INT, name VARCHAR(50));
+INSERT INTO staging SELECT id, name FROM users;
+INSERT INTO staging SELECT id, name FROM admins;
+INSERT INTO people (person_id, person_name) SELECT id, UPPER(name) FROM staging;
+`
+	result, err := lineage.NewAnalyzer(nil).AnalyzeScript(script)
+	if err != nil {
+		t.Fatalf("AnalyzeScript failed: %v", err)
+	}
+	printLineageResult(t, script, result)
+
+	assertColumnCount(t, result, 2)
+	assertColumnLineage(t, result, "person_id", []string{"users.id", "admins.id"}, nil)
+	assertColumnLineage(t, result, "person_name", []string{"users.name", "admins.name"}, []string{"UPPER(name)", "name"})
+	if len(result.Skipped) != 0 {
+		t.Errorf("Temporary table DDL should not be skipped, got %+v", result.Skipped)
+	}
+}
+
+func TestAnalyzeScript_SparkTempView(t *testing.T) {
+	script := `
+CREATE OR REPLACE TEMP VIEW active_users AS
+SELECT user_id, country FROM events WHERE active = 1;
+INSERT OVERWRITE TABLE users_by_country
+SELECT country, COUNT(user_id) AS users FROM active_users GROUP BY country
+`
+	result, err := lineage.NewAnalyzer(nil).Analyze(script)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	printLineageResult(t, script, result)
+
+	assertTargetTable(t, result, "users_by_country")
+	assertColumnLineage(t, result, "country", []string{"events.country"}, nil)
+	assertColumnLineage(t, result, "users", []string{"events.user_id"}, nil)
+}
+
+func TestAnalyzeScript_UseDatabase(t *testing.T) {
+	catalog := NewMockCatalog()
+	catalog.AddTable("sales", "orders", []string{"id", "amount"})
+	script := `
+USE sales;
+INSERT INTO summary SELECT * FROM orders;
+`
+	result, err := lineage.NewAnalyzer(catalog).Analyze(script)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	printLineageResult(t, script, result)
+
+	// SELECT * is expanded against sales.orders
+	assertColumnCount(t, result, 2)
+	assertColumnLineage(t, result, "id", []string{"orders.id"}, nil)
+	assertColumnLineage(t, result, "amount", []string{"orders.amount"}, nil)
+	if len(result.Scans) != 1 || result.Scans[0].Database != "sales" {
+		t.Errorf("Expected a scan of sales.orders, got %+v", result.Scans)
+	}
+}
+
+func TestAnalyzeScript_CreatedTable(t *testing.T) {
+	catalog := NewMockCatalog()
+	catalog.AddTable("", "orders", []string{"id", "amount"})
+	script := `
+CREATE TABLE orders_copy AS SELECT id, amount FROM orders;
+INSERT INTO archive SELECT * FROM orders_copy;
+`
+	result, err := lineage.NewAnalyzer(catalog).Analyze(script)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	printLineageResult(t, script, result)
+
+	// orders_copy is not in the catalog but its columns are known from the script
+	assertColumnLineage(t, result, "amount", []string{"orders.amount"}, nil)
+	found := false
+	for _, col := range result.Columns {
+		if col.Target.Table == "archive" && col.Target.Column == "amount" {
+			found = len(col.Sources) == 1 && col.Sources[0].Table == "orders_copy"
+		}
+	}
+	if !found {
+		t.Errorf("Expected archive.amount to be read from orders_copy")
+	}
+}
+
+func TestAnalyzeScript_Variables(t *testing.T) {
+	script := `
+SET hivevar:ds=2024-06-01;
+INSERT OVERWRITE TABLE daily SELECT id FROM events WHERE dt = '${hivevar:ds}';
+`
+	result, err := lineage.NewAnalyzer(nil).Analyze(script)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Scans) != 1 || result.Scans[0].Filters["dt"] != "2024-06-01" {
+		t.Errorf("Expected a scan of events filtered on dt = 2024-06-01, got %+v", result.Scans)
+	}
+}
+
+func TestAnalyzeScript_MergesTargets(t *testing.T) {
+	script := `
+INSERT INTO totals (id, amount) SELECT id, amount FROM online_orders;
+INSERT INTO totals (id, amount) SELECT id, price FROM store_orders;
+`
+	result, err := lineage.NewAnalyzer(nil).Analyze(script)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	assertColumnCount(t, result, 2)
+	assertColumnLineage(t, result, "amount", []string{"online_orders.amount", "store_orders.price"}, nil)
+}
+
+func TestAnalyzeScript_Unsupported(t *testing.T) {
+	script := `
+USE dw;
+CREATE OR REPLACE TABLE a AS SELECT 1;
+INSERT INTO b SELECT x FROM c;
+`
+	result, err := lineage.NewAnalyzer(nil).Analyze(script)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	assertColumnLineage(t, result, "x", []string{"c.x"}, nil)
+	if len(result.Skipped) != 1 || result.Skipped[0].Statement != 2 {
+		t.Errorf("Expected statement 2 to be skipped, got %+v", result.Skipped)
+	}
+
+	_, err = lineage.NewAnalyzer(nil).Analyze("USE dw; CREATE OR REPLACE TABLE a AS SELECT 1;")
+	if !errors.Is(err, lineage.ErrUnsupportedSQL) {
+		t.Errorf("Expected ErrUnsupportedSQL, got %v", err)
+	}
+}
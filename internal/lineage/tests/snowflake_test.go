@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"go-metadata/internal/lineage"
+)
+
+func TestSnowflake_CopyIntoStage(t *testing.T) {
+	catalog := NewMockCatalog()
+	catalog.AddTable("raw", "orders", []string{"id", "amount"})
+	analyzer := lineage.NewAnalyzer(catalog)
+	analyzer.RegisterSnowflake(nil)
+
+	result, err := analyzer.AnalyzeScript(`
+CREATE OR REPLACE STAGE raw.orders_stage
+  URL = 's3://lake/orders/'
+  STORAGE_INTEGRATION = lake_int
+  FILE_FORMAT = (TYPE = CSV FIELD_DELIMITER = '|' SKIP_HEADER = 1);
+COPY INTO raw.orders FROM @raw.orders_stage/2026/01/ PATTERN = '.*[.]csv';
+`)
+	if err != nil {
+		t.Fatalf("AnalyzeScript failed: %v", err)
+	}
+	if len(result.Columns) != 2 {
+		t.Fatalf("columns = %+v, want id and amount", result.Columns)
+	}
+	col := result.Columns[1]
+	if col.Target.QualifiedName() != "raw.orders.amount" || len(col.Sources) != 1 || col.Sources[0].QualifiedName() != "s3.lake/orders/2026/01.amount" {
+		t.Errorf("column = %+v", col)
+	}
+
+	if len(result.Loads) != 1 {
+		t.Fatalf("loads = %+v, want 1", result.Loads)
+	}
+	load := result.Loads[0]
+	if load.Stage != "raw.orders_stage" || load.Location != "s3://lake/orders/2026/01/" || load.Pattern != ".*[.]csv" {
+		t.Errorf("load = %+v", load)
+	}
+	// The format of the stage applies to loads without their own
+	if load.Format.Type != "CSV" || load.Format.Options["FIELD_DELIMITER"] != "|" || load.Format.Options["SKIP_HEADER"] != "1" {
+		t.Errorf("format = %+v", load.Format)
+	}
+}
+
+func TestSnowflake_CopyIntoLocation(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+	analyzer.RegisterSnowflake(nil)
+
+	result, err := analyzer.Analyze(`COPY INTO dw.events (id, payload)
+FROM 's3a://lake/events/'
+CREDENTIALS = (AWS_KEY_ID = 'x' AWS_SECRET_KEY = 'y')
+FILE_FORMAT = (TYPE = PARQUET)`)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Columns) != 2 || result.Columns[0].Sources[0].QualifiedName() != "s3.lake/events.id" {
+		t.Errorf("columns = %+v", result.Columns)
+	}
+	if len(result.Loads) != 1 || result.Loads[0].Format.Type != "PARQUET" || result.Loads[0].Stage != "" {
+		t.Errorf("loads = %+v", result.Loads)
+	}
+}
+
+func TestSnowflake_CopyIntoTransform(t *testing.T) {
+	stages := lineage.NewStages()
+	stages.Add(&lineage.Stage{Name: "ORDERS_STAGE", URL: "s3://lake/orders/", FileFormat: lineage.FileFormat{Type: "JSON"}})
+	analyzer := lineage.NewAnalyzer(nil)
+	analyzer.RegisterSnowflake(stages)
+
+	result, err := analyzer.Analyze(`COPY INTO dw.orders (id, amount, loaded_at)
+FROM (SELECT t.$1:id, t.$1:amount::NUMBER(10, 2), CURRENT_TIMESTAMP() FROM @dw.orders_stage t)
+FILE_FORMAT = (FORMAT_NAME = 'json_format')`)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Columns) != 3 {
+		t.Fatalf("columns = %+v, want 3", result.Columns)
+	}
+	if id := result.Columns[0]; len(id.Sources) != 1 || id.Sources[0].QualifiedName() != "s3.lake/orders.$1:id" {
+		t.Errorf("id = %+v", id)
+	}
+	if amount := result.Columns[1]; len(amount.Sources) != 1 || amount.Sources[0].Column != "$1:amount" || len(amount.Operators) != 1 {
+		t.Errorf("amount = %+v", amount)
+	}
+	if loaded := result.Columns[2]; len(loaded.Sources) != 0 {
+		t.Errorf("loaded_at = %+v", loaded)
+	}
+	if format := result.Loads[0].Format; format.Name != "json_format" || format.Type != "" {
+		t.Errorf("format = %+v, want the named format of the statement", format)
+	}
+}
+
+func TestSnowflake_UnknownStageAndUnload(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(nil)
+	analyzer.RegisterSnowflake(nil)
+
+	result, err := analyzer.Analyze("COPY INTO dw.orders FROM @missing_stage")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.Columns) != 1 || result.Columns[0].Sources[0].QualifiedName() != "@missing_stage.*" {
+		t.Errorf("columns = %+v", result.Columns)
+	}
+	if len(result.Unresolved) != 1 || result.Unresolved[0].Reason != "stage not defined" {
+		t.Errorf("unresolved = %+v", result.Unresolved)
+	}
+
+	if _, err := analyzer.Analyze("COPY INTO @unload_stage FROM dw.orders"); !errors.Is(err, lineage.ErrUnsupportedSQL) {
+		t.Errorf("unload err = %v, want ErrUnsupportedSQL", err)
+	}
+}
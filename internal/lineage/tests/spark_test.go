@@ -93,3 +93,71 @@ func TestSpark_JsonExtract(t *testing.T) {
 		t.Fatal("Parse returned nil result")
 	}
 }
+
+func TestSpark_CreateTableUsingDelta(t *testing.T) {
+	sql := `CREATE TABLE dw.user_orders
+			USING delta
+			PARTITIONED BY (user_id)
+			TBLPROPERTIES ('delta.autoOptimize.optimizeWrite' = 'true')
+			AS SELECT user_id, amount * 100 AS cents FROM default.orders`
+	result, err := lineage.NewAnalyzer(setupSparkCatalog()).Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	printLineageResult(t, sql, result)
+
+	assertTargetTable(t, result, "user_orders")
+	assertColumnCount(t, result, 2)
+	assertColumnLineage(t, result, "user_id", []string{"orders.user_id"}, nil)
+	assertColumnLineage(t, result, "cents", []string{"orders.amount"}, nil)
+}
+
+func TestSpark_LateralViewExplode(t *testing.T) {
+	sql := `INSERT OVERWRITE TABLE user_tags
+			SELECT id, tag
+			FROM default.users
+			LATERAL VIEW explode(tags) t AS tag
+			DISTRIBUTE BY id SORT BY tag`
+	result, err := lineage.NewAnalyzer(setupSparkCatalog()).Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	printLineageResult(t, sql, result)
+
+	assertTargetTable(t, result, "user_tags")
+	assertColumnCount(t, result, 2)
+	assertColumnLineage(t, result, "id", []string{"users.id"}, nil)
+	assertColumnLineage(t, result, "tag", []string{"users.tags"}, nil)
+}
+
+func TestSpark_LateralViewPosexplode(t *testing.T) {
+	sql := `INSERT INTO user_tags
+			SELECT u.id, t.pos, t.tag
+			FROM default.users u
+			LATERAL VIEW OUTER posexplode(u.tags) t AS pos, tag`
+	result, err := lineage.NewAnalyzer(setupSparkCatalog()).Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	printLineageResult(t, sql, result)
+
+	assertColumnCount(t, result, 3)
+	assertColumnLineage(t, result, "id", []string{"users.id"}, nil)
+	assertColumnLineage(t, result, "pos", []string{"users.tags"}, nil)
+	assertColumnLineage(t, result, "tag", []string{"users.tags"}, nil)
+}
+
+func TestSpark_InsertOverwriteDirectory(t *testing.T) {
+	sql := `INSERT OVERWRITE DIRECTORY 's3://exports/events'
+			USING parquet
+			SELECT user_id, event_type FROM default.events`
+	result, err := lineage.NewAnalyzer(setupSparkCatalog()).Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	printLineageResult(t, sql, result)
+
+	assertTargetTable(t, result, "s3://exports/events")
+	assertColumnCount(t, result, 2)
+	assertColumnLineage(t, result, "event_type", []string{"events.event_type"}, nil)
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"go-metadata/internal/lineage"
+	"testing"
+)
+
+func newStatementCatalog() *MockCatalog {
+	catalog := NewMockCatalog()
+	catalog.AddTable("", "orders", []string{"id", "user_id", "amount", "status"})
+	catalog.AddTable("", "users", []string{"id", "name", "region"})
+	catalog.AddTable("", "customers", []string{"id", "name", "total"})
+	return catalog
+}
+
+func TestStatement_CTEResolvedToSourceTables(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(newStatementCatalog())
+	sql := `INSERT INTO user_totals
+		WITH uo AS (SELECT user_id, SUM(amount) AS total FROM orders GROUP BY user_id)
+		SELECT u.name, uo.total FROM users u JOIN uo ON u.id = uo.user_id`
+
+	result, err := analyzer.Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	printLineageResult(t, sql, result)
+	assertColumnCount(t, result, 2)
+	assertTargetTable(t, result, "user_totals")
+	assertColumnLineage(t, result, "name", []string{"users.name"}, []string{"u.name"})
+	assertColumnLineage(t, result, "total", []string{"orders.amount"}, []string{"uo.total"})
+}
+
+func TestStatement_CTEColumnList(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(newStatementCatalog())
+	sql := `WITH c (a, b) AS (SELECT id, name FROM users) SELECT * FROM c`
+
+	result, err := analyzer.Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	assertColumnCount(t, result, 2)
+	assertColumnLineage(t, result, "a", []string{"users.id"}, nil)
+	assertColumnLineage(t, result, "b", []string{"users.name"}, nil)
+}
+
+func TestStatement_DerivedTableResolvedToSourceTables(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(newStatementCatalog())
+	sql := `INSERT INTO stats
+		SELECT s.user_id, s.total_amount * 2 AS doubled
+		FROM (SELECT user_id, SUM(amount) AS total_amount FROM orders GROUP BY user_id) s`
+
+	result, err := analyzer.Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	printLineageResult(t, sql, result)
+	assertColumnCount(t, result, 2)
+	assertColumnLineage(t, result, "user_id", []string{"orders.user_id"}, []string{"s.user_id"})
+	assertColumnLineage(t, result, "doubled", []string{"orders.amount"}, []string{"s.total_amount * 2"})
+	if src := findSource(t, result, "doubled"); src.Confidence != lineage.ConfidenceCatalog {
+		t.Errorf("Expected catalog confidence through the derived table, got %q", src.Confidence)
+	}
+	for _, u := range result.Usages {
+		if u.Column.Table == "s" {
+			t.Errorf("Derived table recorded as a usage: %+v", u)
+		}
+	}
+}
+
+func TestStatement_CreateTableAsSelect(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(newStatementCatalog())
+	sql := `CREATE TABLE dw.order_amounts AS SELECT id, amount + 1 AS amt FROM orders`
+
+	result, err := analyzer.Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	printLineageResult(t, sql, result)
+	assertColumnCount(t, result, 2)
+	assertTargetTable(t, result, "order_amounts")
+	assertColumnLineage(t, result, "id", []string{"orders.id"}, []string{"id"})
+	assertColumnLineage(t, result, "amt", []string{"orders.amount"}, []string{"amount + 1"})
+}
+
+func TestStatement_CreateTableWithoutSelect(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(newStatementCatalog())
+
+	result, err := analyzer.Analyze(`CREATE TABLE t (id INT, name VARCHAR(10))`)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	assertColumnCount(t, result, 0)
+}
+
+func TestStatement_MergeUpdateAndInsert(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(newStatementCatalog())
+	sql := `MERGE INTO customers t
+		USING (SELECT user_id, SUM(amount) AS total FROM orders GROUP BY user_id) s
+		ON t.id = s.user_id
+		WHEN MATCHED AND s.total > 0 THEN UPDATE SET total = t.total + s.total
+		WHEN NOT MATCHED THEN INSERT (id, total) VALUES (s.user_id, s.total)`
+
+	result, err := analyzer.Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	printLineageResult(t, sql, result)
+	assertColumnCount(t, result, 2)
+	assertTargetTable(t, result, "customers")
+	// total is written by both clauses.
+	assertColumnLineage(t, result, "total", []string{"customers.total", "orders.amount"}, []string{"t.total + s.total", "s.total"})
+	assertColumnLineage(t, result, "id", []string{"orders.user_id"}, []string{"s.user_id"})
+}
+
+func TestStatement_MergeInsertWithoutColumnList(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(newStatementCatalog())
+	sql := `MERGE INTO customers AS c USING users AS u ON c.id = u.id
+		WHEN MATCHED THEN DELETE
+		WHEN NOT MATCHED THEN INSERT VALUES (u.id, u.name, 0)`
+
+	result, err := analyzer.Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	printLineageResult(t, sql, result)
+	assertColumnCount(t, result, 3)
+	assertColumnLineage(t, result, "id", []string{"users.id"}, nil)
+	assertColumnLineage(t, result, "name", []string{"users.name"}, nil)
+	assertColumnLineage(t, result, "total", []string{}, nil)
+	if len(result.JoinKeys) != 1 {
+		t.Errorf("Expected the ON condition as a join key, got %v", result.JoinKeys)
+	}
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"errors"
+	"go-metadata/internal/lineage"
+	"strings"
+	"testing"
+)
+
+func TestTemplate_RefAndSource(t *testing.T) {
+	sql := `SELECT o.id, c.name FROM {{ ref('stg_orders') }} o JOIN {{ source('crm', 'customers') }} c ON o.customer_id = c.id`
+
+	rendered, err := lineage.PreprocessTemplate(sql, nil)
+	if err != nil {
+		t.Fatalf("PreprocessTemplate failed: %v", err)
+	}
+	expected := `SELECT o.id, c.name FROM stg_orders o JOIN crm.customers c ON o.customer_id = c.id`
+	if rendered != expected {
+		t.Errorf("Expected %q, got %q", expected, rendered)
+	}
+}
+
+func TestTemplate_Resolver(t *testing.T) {
+	vars := &lineage.TemplateVars{
+		Refs:    map[string]string{"stg_orders": "analytics.stg_orders"},
+		Sources: map[string]string{"crm.customers": "raw.crm_customers"},
+		Vars:    map[string]string{"ds": "2024-06-01", "params.schema": "dw"},
+	}
+	sql := `SELECT * FROM {{ ref('stg_orders') }}, {{ source('crm', 'customers') }}, {{ params.schema }}.t WHERE dt = '{{ ds }}'`
+
+	rendered, err := lineage.PreprocessTemplate(sql, vars)
+	if err != nil {
+		t.Fatalf("PreprocessTemplate failed: %v", err)
+	}
+	expected := `SELECT * FROM analytics.stg_orders, raw.crm_customers, dw.t WHERE dt = '2024-06-01'`
+	if rendered != expected {
+		t.Errorf("Expected %q, got %q", expected, rendered)
+	}
+}
+
+func TestTemplate_IfBlocks(t *testing.T) {
+	sql := `SELECT id FROM events{% if is_incremental() %} WHERE ts > 0{% else %} WHERE 1 = 1{% endif %}`
+
+	tests := []struct {
+		name     string
+		vars     *lineage.TemplateVars
+		expected string
+	}{
+		{"unresolved keeps first branch", nil, `SELECT id FROM events WHERE ts > 0`},
+		{"resolved true", &lineage.TemplateVars{Vars: map[string]string{"is_incremental": "true"}}, `SELECT id FROM events WHERE ts > 0`},
+		{"resolved false", &lineage.TemplateVars{Vars: map[string]string{"is_incremental": "false"}}, `SELECT id FROM events WHERE 1 = 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resolver lineage.TemplateResolver
+			if tt.vars != nil {
+				resolver = tt.vars
+			}
+			rendered, err := lineage.PreprocessTemplate(sql, resolver)
+			if err != nil {
+				t.Fatalf("PreprocessTemplate failed: %v", err)
+			}
+			if rendered != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, rendered)
+			}
+		})
+	}
+}
+
+func TestTemplate_DropsConfigMacrosAndComments(t *testing.T) {
+	sql := `{{ config(materialized='table') }}
+{# build the daily summary #}
+{% macro cents(col) %}{{ col }} / 100{% endmacro %}
+{% set days = 7 %}
+SELECT user_id FROM users`
+
+	rendered, err := lineage.PreprocessTemplate(sql, nil)
+	if err != nil {
+		t.Fatalf("PreprocessTemplate failed: %v", err)
+	}
+	if strings.TrimSpace(rendered) != "SELECT user_id FROM users" {
+		t.Errorf("Unexpected rendering: %q", rendered)
+	}
+}
+
+func TestTemplate_WhitespaceControl(t *testing.T) {
+	sql := "SELECT id\n{%- if true %}\nFROM users\n{%- endif %}"
+
+	rendered, err := lineage.PreprocessTemplate(sql, nil)
+	if err != nil {
+		t.Fatalf("PreprocessTemplate failed: %v", err)
+	}
+	if rendered != "SELECT id\nFROM users" {
+		t.Errorf("Unexpected rendering: %q", rendered)
+	}
+}
+
+func TestTemplate_Invalid(t *testing.T) {
+	for _, sql := range []string{
+		"SELECT {{ id FROM users",
+		"SELECT id FROM users {% if x %}",
+		"SELECT id FROM users {% endfor %}",
+	} {
+		_, err := lineage.PreprocessTemplate(sql, nil)
+		if !errors.Is(err, lineage.ErrInvalidTemplate) {
+			t.Errorf("Expected ErrInvalidTemplate for %q, got %v", sql, err)
+		}
+	}
+}
+
+func TestTemplate_AnalyzeDbtModel(t *testing.T) {
+	catalog := NewMockCatalog()
+	catalog.AddTable("", "stg_orders", []string{"id", "amount", "user_id"})
+
+	analyzer := lineage.NewAnalyzer(catalog)
+	sql := `{{ config(materialized='incremental') }}
+SELECT user_id, SUM(amount) AS total
+FROM {{ ref('stg_orders') }}
+{% if is_incremental() %}
+WHERE id > 100
+{% endif %}
+GROUP BY user_id`
+
+	result, err := analyzer.Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	printLineageResult(t, sql, result)
+
+	assertColumnCount(t, result, 2)
+	assertColumnLineage(t, result, "user_id", []string{"stg_orders.user_id"}, nil)
+	assertColumnLineage(t, result, "total", []string{"stg_orders.amount"}, []string{"SUM(amount)"})
+}
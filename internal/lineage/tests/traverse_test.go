@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"fmt"
+	"go-metadata/internal/lineage"
+	"strings"
+	"testing"
+	"time"
+)
+
+func buildChainGraph(t *testing.T) *lineage.Graph {
+	t.Helper()
+	analyzer := lineage.NewAnalyzer(nil)
+	now := time.Now()
+
+	g := lineage.NewGraph()
+	for _, sql := range []string{
+		"INSERT INTO stg_orders(amount) SELECT amount FROM raw_orders",
+		"INSERT INTO daily_sales(total) SELECT SUM(amount) FROM stg_orders",
+		"INSERT INTO sales_report(total) SELECT total FROM daily_sales",
+	} {
+		g.Add(analyzeForGraph(t, analyzer, sql), lineage.Fingerprint(sql), now)
+	}
+	return g
+}
+
+func TestGraph_TraverseColumnUpstream(t *testing.T) {
+	g := buildChainGraph(t)
+
+	if edges := g.Traverse("sales_report.total", lineage.Upstream, 1); len(edges) != 1 {
+		t.Fatalf("Expected 1 edge at depth 1, got %d", len(edges))
+	}
+
+	edges := g.Traverse("sales_report.total", lineage.Upstream, 0)
+	if len(edges) != 3 {
+		t.Fatalf("Expected 3 edges with unlimited depth, got %d", len(edges))
+	}
+	last := edges[len(edges)-1]
+	if last.Source.QualifiedName() != "raw_orders.amount" {
+		t.Errorf("Expected traversal to reach raw_orders.amount, got %s", last.Source.QualifiedName())
+	}
+}
+
+func TestGraph_TraverseTableDownstream(t *testing.T) {
+	g := buildChainGraph(t)
+
+	edges := g.Traverse("raw_orders", lineage.Downstream, 2)
+	if len(edges) != 2 {
+		t.Fatalf("Expected 2 edges, got %d", len(edges))
+	}
+	if edges[1].Target.TableName() != "daily_sales" {
+		t.Errorf("Expected second hop to reach daily_sales, got %s", edges[1].Target.TableName())
+	}
+}
+
+func TestGraph_TraverseSkipsRetiredEdges(t *testing.T) {
+	g := buildChainGraph(t)
+	edge := g.Traverse("daily_sales.total", lineage.Upstream, 1)[0]
+	if err := g.Retire(edge.Source, edge.Target, time.Now()); err != nil {
+		t.Fatalf("Retire failed: %v", err)
+	}
+
+	if edges := g.Traverse("sales_report.total", lineage.Upstream, 0); len(edges) != 1 {
+		t.Errorf("Expected traversal to stop at the retired edge, got %d edges", len(edges))
+	}
+}
+
+func TestGraph_Dependents(t *testing.T) {
+	g := buildChainGraph(t)
+
+	deps := g.Dependents("raw_orders")
+	if len(deps) != 3 || deps[0] != "daily_sales" || deps[1] != "sales_report" || deps[2] != "stg_orders" {
+		t.Errorf("Expected the 3 downstream tables, sorted, got %v", deps)
+	}
+	if deps := g.Dependents("sales_report"); len(deps) != 0 {
+		t.Errorf("Expected no dependents of a leaf table, got %v", deps)
+	}
+	if deps := g.Dependents("unknown"); len(deps) != 0 {
+		t.Errorf("Expected no dependents of an unknown table, got %v", deps)
+	}
+}
+
+func TestGraph_Ancestors(t *testing.T) {
+	g := buildChainGraph(t)
+
+	var got []string
+	for _, a := range g.Ancestors("sales_report", 0) {
+		got = append(got, fmt.Sprintf("%s:%d", a.Table, a.Hops))
+	}
+	if want := "daily_sales:1 stg_orders:2 raw_orders:3"; strings.Join(got, " ") != want {
+		t.Errorf("Expected ancestors %s, got %v", want, got)
+	}
+	if ancestors := g.Ancestors("sales_report", 2); len(ancestors) != 2 {
+		t.Errorf("Expected 2 ancestors within 2 hops, got %v", ancestors)
+	}
+	if ancestors := g.Ancestors("raw_orders", 0); len(ancestors) != 0 {
+		t.Errorf("Expected no ancestors of a root table, got %v", ancestors)
+	}
+}
+
+func TestGraph_Impact(t *testing.T) {
+	g := buildChainGraph(t)
+	if err := g.RegisterJob(&lineage.Job{Name: "export_report", Inputs: []string{"sales_report"}}); err != nil {
+		t.Fatalf("RegisterJob failed: %v", err)
+	}
+
+	impact := g.Impact("raw_orders.amount", 0)
+	want := []lineage.ImpactedColumn{
+		{Column: "stg_orders.amount", Hops: 1},
+		{Column: "daily_sales.total", Hops: 2},
+		{Column: "sales_report.total", Hops: 3},
+	}
+	if len(impact.Columns) != len(want) {
+		t.Fatalf("Expected %d impacted columns, got %+v", len(want), impact.Columns)
+	}
+	for i, w := range want {
+		if got := impact.Columns[i]; got.Column != w.Column || got.Hops != w.Hops {
+			t.Errorf("Column %d = %s at %d hops, want %s at %d", i, got.Column, got.Hops, w.Column, w.Hops)
+		}
+	}
+	if len(impact.Tables) != 3 || impact.Tables[0] != "daily_sales" {
+		t.Errorf("Unexpected impacted tables %v", impact.Tables)
+	}
+	if len(impact.Jobs) != 1 || impact.Jobs[0] != "export_report" {
+		t.Errorf("Expected the job reading sales_report, got %v", impact.Jobs)
+	}
+
+	if impact := g.Impact("raw_orders", 1); len(impact.Tables) != 1 || impact.Tables[0] != "stg_orders" {
+		t.Errorf("Expected only stg_orders within 1 hop of raw_orders, got %v", impact.Tables)
+	}
+	if impact := g.Impact("sales_report.total", 0); len(impact.Columns) != 0 {
+		t.Errorf("Expected no impact of a leaf column, got %+v", impact.Columns)
+	}
+}
+
+func TestGraph_Cycles(t *testing.T) {
+	g := buildChainGraph(t)
+	if cycles := g.Cycles(); len(cycles) != 0 {
+		t.Fatalf("Expected no cycles in a chain, got %v", cycles)
+	}
+
+	analyzer := lineage.NewAnalyzer(nil)
+	for _, sql := range []string{
+		"INSERT INTO raw_orders(amount) SELECT total FROM daily_sales",
+		"INSERT INTO audit(total) SELECT total + 1 FROM audit",
+	} {
+		g.Add(analyzeForGraph(t, analyzer, sql), lineage.Fingerprint(sql), time.Now())
+	}
+	cycles := g.Cycles()
+	if len(cycles) != 1 {
+		t.Fatalf("Expected 1 cycle, got %v", cycles)
+	}
+	if want := []string{"daily_sales", "raw_orders", "stg_orders"}; strings.Join(cycles[0], ",") != strings.Join(want, ",") {
+		t.Errorf("Cycle = %v, want %v", cycles[0], want)
+	}
+}
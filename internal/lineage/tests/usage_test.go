@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"go-metadata/internal/lineage"
+	"sort"
+	"testing"
+	"time"
+)
+
+func usageCatalog() *MockCatalog {
+	catalog := NewMockCatalog()
+	catalog.AddTable("", "orders", []string{"id", "user_id", "amount", "status", "created_at", "legacy_code"})
+	catalog.AddTable("", "users", []string{"id", "name", "email", "country", "fax"})
+	catalog.AddTable("", "banned", []string{"user_id"})
+	return catalog
+}
+
+// usages returns the usages of a result as sorted "table.column:clause" strings.
+func usages(result *lineage.LineageResult) []string {
+	out := make([]string, 0, len(result.Usages))
+	for _, u := range result.Usages {
+		out = append(out, u.Column.QualifiedName()+":"+string(u.Clause))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func assertUsages(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	sort.Strings(got)
+	sort.Strings(want)
+	if len(got) != len(want) {
+		t.Fatalf("Expected usages %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected usages %v, got %v", want, got)
+		}
+	}
+}
+
+func TestUsage_Clauses(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(usageCatalog())
+	result, err := analyzer.Analyze(`
+		SELECT u.name, SUM(o.amount) AS total
+		FROM orders o
+		JOIN users u ON o.user_id = u.id
+		WHERE o.status = 'paid' AND u.country IN ('DE', 'FR')
+		GROUP BY u.name
+		HAVING COUNT(o.id) > 1
+		ORDER BY total DESC, u.name`)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	assertUsages(t, usages(result),
+		"users.name:select", "orders.amount:select",
+		"orders.user_id:join", "users.id:join",
+		"orders.status:filter", "users.country:filter", "orders.id:filter",
+		"users.name:group_by", "users.name:order_by",
+	)
+	if len(result.Columns) != 2 || result.HasUnresolved() {
+		t.Errorf("Usage tracking changed the lineage: %+v", result)
+	}
+}
+
+func TestUsage_SubqueriesAndCTEs(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(usageCatalog())
+	result, err := analyzer.Analyze(`
+		WITH recent AS (SELECT id, user_id FROM orders WHERE created_at > '2024-01-01')
+		SELECT r.id FROM recent r
+		WHERE r.user_id NOT IN (SELECT user_id FROM banned)`)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	// Columns of the CTE itself are not table columns.
+	assertUsages(t, usages(result),
+		"orders.id:select", "orders.user_id:select", "orders.created_at:filter",
+		"banned.user_id:select",
+	)
+}
+
+func TestUsage_StarAndUnknownColumns(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(usageCatalog())
+	result, err := analyzer.Analyze("SELECT * FROM banned WHERE no_such_column = 1")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	assertUsages(t, usages(result), "banned.user_id:select")
+}
+
+func TestUsageStats_CountsAndUnused(t *testing.T) {
+	analyzer := lineage.NewAnalyzer(usageCatalog())
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	stats := lineage.NewUsageStats()
+
+	queries := []struct {
+		sql string
+		at  time.Time
+	}{
+		{"SELECT id, amount FROM orders WHERE status = 'paid' AND amount > 0", day},
+		{"SELECT id FROM orders WHERE status = 'open'", day.Add(24 * time.Hour)},
+		{"SELECT u.email FROM users u JOIN orders o ON o.user_id = u.id", day.Add(48 * time.Hour)},
+		{"SELECT fax FROM users", day.Add(-90 * 24 * time.Hour)},
+	}
+	for _, q := range queries {
+		result, err := analyzer.Analyze(q.sql)
+		if err != nil {
+			t.Fatalf("Analyze %q failed: %v", q.sql, err)
+		}
+		stats.Add(result, q.at)
+	}
+
+	amount := stats.Column("", "orders", "amount")
+	if amount == nil || amount.Queries != 1 || amount.Selected != 1 || amount.Filtered != 1 {
+		t.Errorf("Unexpected amount stats: %+v", amount)
+	}
+	status := stats.Column("", "orders", "status")
+	if status == nil || status.Queries != 2 || status.Filtered != 2 ||
+		!status.FirstUsed.Equal(day) || !status.LastUsed.Equal(day.Add(24*time.Hour)) {
+		t.Errorf("Unexpected status stats: %+v", status)
+	}
+	if id := stats.Column("", "orders", "id"); id == nil || id.Queries != 2 || id.Selected != 2 {
+		t.Errorf("Unexpected orders.id stats: %+v", id)
+	}
+	if joined := stats.Column("", "users", "id"); joined == nil || joined.Joined != 1 {
+		t.Errorf("Unexpected users.id stats: %+v", joined)
+	}
+	if len(stats.Columns("orders")) != 4 {
+		t.Errorf("Expected 4 used columns of orders, got %+v", stats.Columns("orders"))
+	}
+
+	tables := []*lineage.TableSchema{
+		{Table: "orders", Columns: []string{"id", "user_id", "amount", "status", "created_at", "legacy_code"}},
+		{Table: "users", Columns: []string{"id", "name", "email", "country", "fax"}},
+	}
+	var unused []string
+	for _, ref := range stats.Unused(tables, time.Time{}) {
+		unused = append(unused, ref.QualifiedName())
+	}
+	assertUsages(t, unused, "orders.created_at", "orders.legacy_code", "users.name", "users.country")
+
+	// A column last used before the cut-off counts as unused.
+	unused = unused[:0]
+	for _, ref := range stats.Unused(tables, day.Add(-30*24*time.Hour)) {
+		unused = append(unused, ref.QualifiedName())
+	}
+	assertUsages(t, unused, "orders.created_at", "orders.legacy_code", "users.name", "users.country", "users.fax")
+}
+
+func TestUsageStats_UnqualifiedQueriesCountForEveryDatabase(t *testing.T) {
+	stats := lineage.NewUsageStats()
+	at := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	stats.Add(&lineage.LineageResult{Usages: []lineage.ColumnUsage{
+		{Column: lineage.ColumnRef{Database: "dw", Table: "orders", Column: "id"}, Clause: lineage.UsageSelect},
+	}}, at)
+	stats.Add(&lineage.LineageResult{Usages: []lineage.ColumnUsage{
+		{Column: lineage.ColumnRef{Table: "ORDERS", Column: "ID"}, Clause: lineage.UsageFilter},
+	}}, at.Add(time.Hour))
+
+	c := stats.Column("dw", "orders", "id")
+	if c == nil || c.Queries != 2 || c.Selected != 1 || c.Filtered != 1 || c.Database != "dw" || !c.LastUsed.Equal(at.Add(time.Hour)) {
+		t.Errorf("Unexpected combined stats: %+v", c)
+	}
+	if c := stats.Column("", "orders", "id"); c == nil || c.Queries != 1 {
+		t.Errorf("Unexpected unqualified stats: %+v", c)
+	}
+}
@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"go-metadata/internal/lineage"
+	"testing"
+)
+
+func TestViewJobs_GroupsDependenciesPerView(t *testing.T) {
+	ref := func(database, table string) lineage.ColumnRef {
+		return lineage.ColumnRef{Database: database, Table: table}
+	}
+	jobs := lineage.ViewJobs([]lineage.ViewDependency{
+		{View: ref("shop", "order_totals"), Ref: ref("shop", "orders")},
+		{View: ref("shop", "order_totals"), Ref: ref("shop", "order_items")},
+		{View: ref("shop", "order_totals"), Ref: ref("shop", "orders")},
+		{View: ref("report", "big_orders"), Ref: ref("shop", "order_totals")},
+	})
+
+	if len(jobs) != 2 {
+		t.Fatalf("Expected 2 jobs, got %d", len(jobs))
+	}
+	totals := jobs[0]
+	if totals.Name != "view:shop.order_totals" || totals.Type != lineage.JobTypeView {
+		t.Errorf("Unexpected job %s (%s)", totals.Name, totals.Type)
+	}
+	if len(totals.Inputs) != 2 || totals.Inputs[0] != "shop.orders" || totals.Inputs[1] != "shop.order_items" {
+		t.Errorf("Expected inputs [shop.orders shop.order_items], got %v", totals.Inputs)
+	}
+	if len(totals.Outputs) != 1 || totals.Outputs[0] != "shop.order_totals" {
+		t.Errorf("Expected outputs [shop.order_totals], got %v", totals.Outputs)
+	}
+
+	g := lineage.NewGraph()
+	for _, job := range jobs {
+		if err := g.RegisterJob(job); err != nil {
+			t.Fatalf("RegisterJob failed: %v", err)
+		}
+	}
+	if readers := g.JobsReading("shop.order_totals"); len(readers) != 1 || readers[0].Name != "view:report.big_orders" {
+		t.Errorf("Expected view:report.big_orders reading shop.order_totals, got %v", readers)
+	}
+}
@@ -0,0 +1,125 @@
+package lineage
+
+import "sort"
+
+// Direction is the direction of a lineage traversal.
+type Direction string
+
+const (
+	// Upstream walks from targets to their sources.
+	Upstream Direction = "upstream"
+	// Downstream walks from sources to their targets.
+	Downstream Direction = "downstream"
+)
+
+// Traverse returns the current edges reachable from node in the given
+// direction within depth hops (depth <= 0 means unlimited). node is either a
+// qualified column name (database.table.column) or a qualified table name; in
+// the latter case the traversal follows table-level dependencies.
+func (g *Graph) Traverse(node string, direction Direction, depth int) []*Edge {
+	edges := make([]*Edge, 0)
+	for _, edge := range g.Edges() {
+		if edge.Current() {
+			edges = append(edges, edge)
+		}
+	}
+
+	tableLevel := !hasColumnNode(edges, node)
+	name := func(c ColumnRef) string {
+		if tableLevel {
+			return c.TableName()
+		}
+		return c.QualifiedName()
+	}
+
+	result := make([]*Edge, 0)
+	included := make(map[string]bool)
+	visited := map[string]bool{node: true}
+	frontier := []string{node}
+	for hop := 0; len(frontier) > 0 && (depth <= 0 || hop < depth); hop++ {
+		current := make(map[string]bool, len(frontier))
+		for _, n := range frontier {
+			current[n] = true
+		}
+
+		var next []string
+		for _, edge := range edges {
+			from, to := name(edge.Target), name(edge.Source)
+			if direction == Downstream {
+				from, to = to, from
+			}
+			if !current[from] {
+				continue
+			}
+			if !included[edge.Key()] {
+				included[edge.Key()] = true
+				result = append(result, edge)
+			}
+			if !visited[to] {
+				visited[to] = true
+				next = append(next, to)
+			}
+		}
+		frontier = next
+	}
+	return result
+}
+
+func hasColumnNode(edges []*Edge, node string) bool {
+	for _, edge := range edges {
+		if edge.Source.QualifiedName() == node || edge.Target.QualifiedName() == node {
+			return true
+		}
+	}
+	return false
+}
+
+// Dependents returns the sorted qualified names of the tables downstream of
+// a table (database.table) in the current edges, directly or transitively.
+// A table with dependents is a shared upstream definition that should not be
+// removed while they still read from it.
+func (g *Graph) Dependents(table string) []string {
+	seen := map[string]bool{table: true}
+	dependents := make([]string, 0)
+	for _, edge := range g.Traverse(table, Downstream, 0) {
+		if name := edge.Target.TableName(); !seen[name] {
+			seen[name] = true
+			dependents = append(dependents, name)
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
+// Ancestor is a table upstream of another.
+type Ancestor struct {
+	Table string `json:"table"`
+	// Hops is the number of table-level dependencies from the table it is
+	// an ancestor of, 1 for the tables it reads directly.
+	Hops int `json:"hops"`
+}
+
+// Ancestors returns the tables upstream of a table (database.table) in the
+// current edges within depth hops (depth <= 0 means unlimited), nearest
+// first and then by name.
+func (g *Graph) Ancestors(table string, depth int) []Ancestor {
+	// Traverse returns the edges hop by hop, so the targets of an edge are
+	// reached before its sources
+	hops := map[string]int{table: 0}
+	ancestors := make([]Ancestor, 0)
+	for _, edge := range g.Traverse(table, Upstream, depth) {
+		name := edge.Source.TableName()
+		if _, ok := hops[name]; ok {
+			continue
+		}
+		hops[name] = hops[edge.Target.TableName()] + 1
+		ancestors = append(ancestors, Ancestor{Table: name, Hops: hops[name]})
+	}
+	sort.SliceStable(ancestors, func(i, j int) bool {
+		if ancestors[i].Hops != ancestors[j].Hops {
+			return ancestors[i].Hops < ancestors[j].Hops
+		}
+		return ancestors[i].Table < ancestors[j].Table
+	})
+	return ancestors
+}
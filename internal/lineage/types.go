@@ -1,11 +1,25 @@
 // Package lineage provides SQL data lineage parsing capabilities.
 package lineage
 
+// Confidence describes how a lineage source column was resolved.
+type Confidence string
+
+const (
+	// ConfidenceCatalog means the column was confirmed against the catalog schema.
+	ConfidenceCatalog Confidence = "catalog"
+	// ConfidenceSyntactic means the table was derived from SQL qualifiers or the
+	// query scope, without catalog confirmation.
+	ConfidenceSyntactic Confidence = "syntactic"
+	// ConfidenceGuessed means the column could not be attributed reliably.
+	ConfidenceGuessed Confidence = "guessed"
+)
+
 // ColumnRef represents a reference to a column in a table.
 type ColumnRef struct {
-	Database string `json:"database,omitempty"`
-	Table    string `json:"table"`
-	Column   string `json:"column"`
+	Database   string     `json:"database,omitempty"`
+	Table      string     `json:"table"`
+	Column     string     `json:"column"`
+	Confidence Confidence `json:"confidence,omitempty"` // set on lineage sources only
 }
 
 // ColumnLineage represents the lineage of a single target column.
@@ -15,7 +29,74 @@ type ColumnLineage struct {
 	Operators []string    `json:"operators"`
 }
 
+// UnresolvedRef is a table or column reference that could not be resolved
+// against the catalog.
+type UnresolvedRef struct {
+	Database string `json:"database,omitempty"`
+	Table    string `json:"table,omitempty"`
+	Column   string `json:"column,omitempty"`
+	Reason   string `json:"reason"`
+}
+
 // LineageResult represents the complete lineage result for a SQL statement.
 type LineageResult struct {
-	Columns []ColumnLineage `json:"columns"`
+	Columns    []ColumnLineage `json:"columns"`
+	Unresolved []UnresolvedRef `json:"unresolved,omitempty"`
+	// Usages are the table columns read by the statement, once per clause.
+	Usages []ColumnUsage `json:"usages,omitempty"`
+	// JoinKeys are the column pairs the statement joins tables on.
+	JoinKeys []JoinKey `json:"join_keys,omitempty"`
+	// Scans are the tables the statement reads, once per reference.
+	Scans []TableScan `json:"scans,omitempty"`
+	// Comments are the comments of the statement, if the analyzer preserves
+	// them, and Annotations the key/value pairs found in them, such as
+	// "-- owner: team-x".
+	Comments    []Comment         `json:"comments,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Dataset holds the "@key value" annotations of the comments, such as
+	// "-- @owner team-data", for the table the statement writes.
+	Dataset *DatasetAnnotations `json:"dataset,omitempty"`
+	// Skipped are the statements of a script that could not be analyzed.
+	Skipped []SkippedStatement `json:"skipped,omitempty"`
+	// Loads are the data files the statement loads into tables, such as
+	// Snowflake COPY INTO table FROM @stage or PostgreSQL COPY table FROM
+	// 'file'.
+	Loads []FileLoad `json:"loads,omitempty"`
+}
+
+// SkippedStatement is a statement of a script left out of its lineage, such
+// as DDL the parser does not support.
+type SkippedStatement struct {
+	Statement int    `json:"statement"` // 1-based position in the script
+	Reason    string `json:"reason"`
+}
+
+// TableScan is a table read by a statement and the literal values the WHERE
+// clause reading it restricts its columns to, e.g. dt = '2024-01-01'.
+// Filters are only set when the conditions are all ANDed equalities, as
+// only then do they limit the rows read.
+type TableScan struct {
+	Database string            `json:"database,omitempty"`
+	Table    string            `json:"table"`
+	Filters  map[string]string `json:"filters,omitempty"`
+}
+
+// TableName returns the table name, qualified with the database if known.
+func (s TableScan) TableName() string {
+	if s.Database == "" {
+		return s.Table
+	}
+	return s.Database + "." + s.Table
+}
+
+// Comment is a SQL comment, without its delimiters.
+type Comment struct {
+	Text  string `json:"text"`
+	Line  int    `json:"line"` // 1-based line of the statement it starts on
+	Block bool   `json:"block,omitempty"`
+}
+
+// HasUnresolved reports whether any references could not be resolved.
+func (r *LineageResult) HasUnresolved() bool {
+	return len(r.Unresolved) > 0
 }
@@ -0,0 +1,219 @@
+package lineage
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UsageClause is the part of a query a column is used in.
+type UsageClause string
+
+const (
+	// UsageSelect is a column read into the result of a query.
+	UsageSelect UsageClause = "select"
+	// UsageFilter is a column tested in a WHERE or HAVING condition.
+	UsageFilter UsageClause = "filter"
+	// UsageJoin is a column compared in a JOIN condition.
+	UsageJoin UsageClause = "join"
+	// UsageGroupBy is a column grouped by.
+	UsageGroupBy UsageClause = "group_by"
+	// UsageOrderBy is a column sorted by.
+	UsageOrderBy UsageClause = "order_by"
+)
+
+// ColumnUsage is a column of a table read by a statement and the clause
+// reading it. Columns of CTEs and derived tables are not reported.
+type ColumnUsage struct {
+	Column ColumnRef   `json:"column"`
+	Clause UsageClause `json:"clause"`
+}
+
+// ColumnUsageStats counts the statements using a column, per clause. A
+// statement using a column in several clauses counts once in Queries.
+type ColumnUsageStats struct {
+	Database  string    `json:"database,omitempty"`
+	Table     string    `json:"table"`
+	Column    string    `json:"column"`
+	Queries   int64     `json:"queries"`
+	Selected  int64     `json:"selected"`
+	Filtered  int64     `json:"filtered"`
+	Joined    int64     `json:"joined"`
+	Grouped   int64     `json:"grouped"`
+	Ordered   int64     `json:"ordered"`
+	FirstUsed time.Time `json:"first_used"`
+	LastUsed  time.Time `json:"last_used"`
+}
+
+// Ref returns the column the statistics are about.
+func (c *ColumnUsageStats) Ref() ColumnRef {
+	return ColumnRef{Database: c.Database, Table: c.Table, Column: c.Column}
+}
+
+func (c *ColumnUsageStats) count(clause UsageClause) {
+	switch clause {
+	case UsageSelect:
+		c.Selected++
+	case UsageFilter:
+		c.Filtered++
+	case UsageJoin:
+		c.Joined++
+	case UsageGroupBy:
+		c.Grouped++
+	case UsageOrderBy:
+		c.Ordered++
+	}
+}
+
+func (c *ColumnUsageStats) merge(other *ColumnUsageStats) {
+	c.Queries += other.Queries
+	c.Selected += other.Selected
+	c.Filtered += other.Filtered
+	c.Joined += other.Joined
+	c.Grouped += other.Grouped
+	c.Ordered += other.Ordered
+	if other.FirstUsed.Before(c.FirstUsed) {
+		c.FirstUsed = other.FirstUsed
+	}
+	if other.LastUsed.After(c.LastUsed) {
+		c.LastUsed = other.LastUsed
+	}
+}
+
+// UsageStats aggregates the column usages of many statements, typically
+// harvested from query logs, into per-column counters. Column names are
+// compared case-insensitively. UsageStats is safe for concurrent use.
+type UsageStats struct {
+	mu      sync.RWMutex
+	columns map[string]*ColumnUsageStats
+}
+
+// NewUsageStats creates empty usage statistics.
+func NewUsageStats() *UsageStats {
+	return &UsageStats{columns: make(map[string]*ColumnUsageStats)}
+}
+
+// Add counts the column usages of a statement executed at the given time.
+func (s *UsageStats) Add(result *LineageResult, at time.Time) {
+	if result == nil || len(result.Usages) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counted := make(map[string]bool)
+	for _, u := range result.Usages {
+		key := usageKey(u.Column)
+		stats, ok := s.columns[key]
+		if !ok {
+			stats = &ColumnUsageStats{
+				Database:  u.Column.Database,
+				Table:     u.Column.Table,
+				Column:    u.Column.Column,
+				FirstUsed: at,
+			}
+			s.columns[key] = stats
+		}
+		stats.count(u.Clause)
+		if counted[key] {
+			continue
+		}
+		counted[key] = true
+		stats.Queries++
+		if at.Before(stats.FirstUsed) {
+			stats.FirstUsed = at
+		}
+		if at.After(stats.LastUsed) {
+			stats.LastUsed = at
+		}
+	}
+}
+
+// Columns returns copies of the statistics of the columns of a table, given
+// as table or database.table, or of every column if table is empty, sorted
+// by table and column.
+func (s *UsageStats) Columns(table string) []*ColumnUsageStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	columns := make([]*ColumnUsageStats, 0)
+	for _, c := range s.columns {
+		ref := c.Ref()
+		if table != "" && !strings.EqualFold(ref.TableName(), table) && !strings.EqualFold(ref.Table, table) {
+			continue
+		}
+		copied := *c
+		columns = append(columns, &copied)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return usageKey(columns[i].Ref()) < usageKey(columns[j].Ref())
+	})
+	return columns
+}
+
+// Column returns the statistics of a column, or nil if it was never used.
+// Statements that did not qualify the table with a database count for the
+// column of every database.
+func (s *UsageStats) Column(database, table, column string) *ColumnUsageStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lookup(database, table, column)
+}
+
+// Unused returns the columns of the given tables not used since the given
+// time, or never used if since is zero, in the order of the tables.
+func (s *UsageStats) Unused(tables []*TableSchema, since time.Time) []ColumnRef {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	unused := make([]ColumnRef, 0)
+	for _, t := range tables {
+		for _, col := range t.Columns {
+			c := s.lookup(t.Database, t.Table, col)
+			if c != nil && !c.LastUsed.Before(since) {
+				continue
+			}
+			unused = append(unused, ColumnRef{Database: t.Database, Table: t.Table, Column: col})
+		}
+	}
+	return unused
+}
+
+// Len returns the number of distinct columns used.
+func (s *UsageStats) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.columns)
+}
+
+// lookup returns the combined statistics of a column and of its uses
+// without a database, or nil.
+func (s *UsageStats) lookup(database, table, column string) *ColumnUsageStats {
+	refs := []ColumnRef{{Database: database, Table: table, Column: column}}
+	if database != "" {
+		refs = append(refs, ColumnRef{Table: table, Column: column})
+	}
+
+	var combined *ColumnUsageStats
+	for _, ref := range refs {
+		c, ok := s.columns[usageKey(ref)]
+		if !ok {
+			continue
+		}
+		if combined == nil {
+			copied := *c
+			copied.Database = database
+			combined = &copied
+			continue
+		}
+		combined.merge(c)
+	}
+	return combined
+}
+
+func usageKey(ref ColumnRef) string {
+	ref.Confidence = ""
+	return strings.ToLower(ref.QualifiedName())
+}
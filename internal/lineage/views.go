@@ -0,0 +1,33 @@
+package lineage
+
+// ViewDependency is a dependency of a view on a table or view it reads, as
+// recorded in the system catalog of its database. Only the Database and
+// Table of the references are used.
+type ViewDependency struct {
+	View ColumnRef `json:"view"`
+	Ref  ColumnRef `json:"ref"`
+}
+
+// ViewJobs groups view dependencies into one job per view, named
+// view:<database>.<view>, that reads the referenced tables and views and
+// writes the view. Jobs are returned in the order their views first appear.
+func ViewJobs(deps []ViewDependency) []*Job {
+	byView := make(map[string]*Job)
+	var jobs []*Job
+	for _, d := range deps {
+		view := d.View.TableName()
+		job, ok := byView[view]
+		if !ok {
+			job = &Job{
+				Name:       "view:" + view,
+				Type:       JobTypeView,
+				Properties: map[string]string{"origin": string(OriginCatalog)},
+				Outputs:    []string{view},
+			}
+			byView[view] = job
+			jobs = append(jobs, job)
+		}
+		job.Inputs = appendUnique(job.Inputs, d.Ref.TableName())
+	}
+	return jobs
+}
@@ -0,0 +1,193 @@
+package metrics
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// DatasetStats holds the statistics of a single dataset (table or topic)
+type DatasetStats struct {
+	Source   string
+	Dataset  string // qualified name, e.g. db.schema.table
+	RowCount int64
+	DataSize int64
+	// UpdatedAt is when the dataset was last seen changing; zero if unknown.
+	UpdatedAt time.Time
+	// ConsumerLag is set for datasets consumed by consumer groups, such as
+	// Kafka topics.
+	ConsumerLag *int64
+}
+
+// DatasetStatsProvider provides the statistics of all known datasets
+type DatasetStatsProvider interface {
+	GetDatasetStats(ctx context.Context) ([]*DatasetStats, error)
+}
+
+// DatasetMetricsConfig configures the dataset metrics exporter. Each exported
+// dataset adds one series per gauge, so only the allowlisted datasets and the
+// TopN largest ones are exported.
+type DatasetMetricsConfig struct {
+	// Interval is how often dataset statistics are collected.
+	Interval time.Duration `yaml:"interval"`
+	// TopN is the number of largest datasets (by data size) exported in
+	// addition to the allowlist; 0 exports the allowlist only.
+	TopN int `yaml:"top_n"`
+	// Allowlist holds glob patterns (see path.Match) of datasets that are
+	// always exported, matched case-insensitively against "source:dataset"
+	// and "dataset", e.g. "orders.*" or "kafka:events.*".
+	Allowlist []string `yaml:"allowlist"`
+}
+
+// DefaultDatasetMetricsConfig returns the default configuration, which
+// exports the 50 largest datasets every 5 minutes.
+func DefaultDatasetMetricsConfig() *DatasetMetricsConfig {
+	return &DatasetMetricsConfig{
+		Interval: 5 * time.Minute,
+		TopN:     50,
+	}
+}
+
+// SelectDatasets returns the datasets to export: those matching the
+// allowlist followed by the TopN largest of the others.
+func SelectDatasets(stats []*DatasetStats, config *DatasetMetricsConfig) []*DatasetStats {
+	var selected, rest []*DatasetStats
+	for _, s := range stats {
+		if datasetAllowed(config.Allowlist, s) {
+			selected = append(selected, s)
+		} else {
+			rest = append(rest, s)
+		}
+	}
+	if config.TopN <= 0 {
+		return selected
+	}
+	sort.SliceStable(rest, func(i, j int) bool {
+		return rest[i].DataSize > rest[j].DataSize
+	})
+	if len(rest) > config.TopN {
+		rest = rest[:config.TopN]
+	}
+	return append(selected, rest...)
+}
+
+func datasetAllowed(allowlist []string, s *DatasetStats) bool {
+	dataset := strings.ToLower(s.Dataset)
+	qualified := strings.ToLower(s.Source) + ":" + dataset
+	for _, pattern := range allowlist {
+		pattern = strings.ToLower(pattern)
+		if ok, _ := path.Match(pattern, qualified); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, dataset); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// DatasetExporter periodically exports the statistics of selected datasets
+// as Prometheus gauges
+type DatasetExporter struct {
+	metrics  *Metrics
+	provider DatasetStatsProvider
+	config   *DatasetMetricsConfig
+	log      *log.Helper
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	running bool
+}
+
+// NewDatasetExporter creates a new dataset metrics exporter
+func NewDatasetExporter(provider DatasetStatsProvider, config *DatasetMetricsConfig, logger log.Logger) *DatasetExporter {
+	if config == nil {
+		config = DefaultDatasetMetricsConfig()
+	}
+	return &DatasetExporter{
+		metrics:  GetMetrics(),
+		provider: provider,
+		config:   config,
+		log:      log.NewHelper(logger),
+	}
+}
+
+// Start starts the exporter
+func (e *DatasetExporter) Start(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.running {
+		return nil
+	}
+
+	e.ctx, e.cancel = context.WithCancel(ctx)
+	e.running = true
+
+	e.wg.Add(1)
+	go e.run()
+
+	e.log.Info("Dataset metrics exporter started")
+	return nil
+}
+
+// Stop stops the exporter
+func (e *DatasetExporter) Stop() error {
+	e.mu.Lock()
+	if !e.running {
+		e.mu.Unlock()
+		return nil
+	}
+	e.cancel()
+	e.running = false
+	e.mu.Unlock()
+
+	e.wg.Wait()
+	e.log.Info("Dataset metrics exporter stopped")
+	return nil
+}
+
+// run is the main collection loop
+func (e *DatasetExporter) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+
+	e.collect(e.ctx)
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+			e.collect(e.ctx)
+		}
+	}
+}
+
+// collect replaces the exported dataset gauges with fresh statistics
+func (e *DatasetExporter) collect(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	stats, err := e.provider.GetDatasetStats(ctx)
+	if err != nil {
+		e.log.Warnf("Failed to collect dataset stats: %v", err)
+		return
+	}
+
+	e.metrics.SetDatasetStats(SelectDatasets(stats, e.config), time.Now())
+}
+
+// CollectNow triggers an immediate collection
+func (e *DatasetExporter) CollectNow(ctx context.Context) {
+	e.collect(ctx)
+}
@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// staticStats provides the same statistics on every collection.
+type staticStats []*DatasetStats
+
+func (s staticStats) GetDatasetStats(ctx context.Context) ([]*DatasetStats, error) {
+	return s, nil
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestDatasetMetricsRegister(t *testing.T) {
+	m := GetMetrics()
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(m.DatasetRows); err != nil {
+		t.Fatalf("Expected the dataset gauges to register with a fresh registry: %v", err)
+	}
+	reg.MustRegister(m.DatasetSize, m.DatasetFreshness, m.DatasetConsumerLag)
+
+	now := time.Now()
+	m.SetDatasetStats([]*DatasetStats{{Source: "kafka", Dataset: "events", RowCount: 1, UpdatedAt: now, ConsumerLag: int64Ptr(0)}}, now)
+	defer m.SetDatasetStats(nil, now)
+	if n, err := testutil.GatherAndCount(reg); err != nil || n != 4 {
+		t.Errorf("Expected 4 series in the fresh registry, got %d, %v", n, err)
+	}
+
+	// The registry of the metrics handler exports them too
+	families, err := m.registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[string]bool)
+	for _, f := range families {
+		names[f.GetName()] = true
+	}
+	for _, name := range []string{"metadata_dataset_rows", "metadata_dataset_size_bytes", "metadata_dataset_freshness_age_seconds", "metadata_dataset_consumer_lag"} {
+		if !names[name] {
+			t.Errorf("Expected %s to be exported, got %v", name, names)
+		}
+	}
+}
+
+func TestSetDatasetStats(t *testing.T) {
+	m := GetMetrics()
+	defer m.SetDatasetStats(nil, time.Now())
+	now := time.Now()
+
+	m.SetDatasetStats([]*DatasetStats{
+		{Source: "mysql", Dataset: "shop.orders", RowCount: 100, DataSize: 4096, UpdatedAt: now.Add(-time.Minute)},
+		{Source: "kafka", Dataset: "events", RowCount: 7, ConsumerLag: int64Ptr(3)},
+	}, now)
+	if got := testutil.ToFloat64(m.DatasetRows.WithLabelValues("mysql", "shop.orders")); got != 100 {
+		t.Errorf("Expected 100 rows, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.DatasetSize.WithLabelValues("mysql", "shop.orders")); got != 4096 {
+		t.Errorf("Expected 4096 bytes, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.DatasetFreshness.WithLabelValues("mysql", "shop.orders")); got != 60 {
+		t.Errorf("Expected a freshness of 60s, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.DatasetConsumerLag.WithLabelValues("kafka", "events")); got != 3 {
+		t.Errorf("Expected a consumer lag of 3, got %v", got)
+	}
+	// Unknown freshness and lag are not exported
+	if n := testutil.CollectAndCount(m.DatasetFreshness); n != 1 {
+		t.Errorf("Expected 1 freshness series, got %d", n)
+	}
+	if n := testutil.CollectAndCount(m.DatasetConsumerLag); n != 1 {
+		t.Errorf("Expected 1 consumer lag series, got %d", n)
+	}
+
+	// Datasets no longer selected stop being exported
+	m.SetDatasetStats([]*DatasetStats{{Source: "kafka", Dataset: "events", RowCount: 9}}, now)
+	if n := testutil.CollectAndCount(m.DatasetRows); n != 1 {
+		t.Errorf("Expected 1 rows series, got %d", n)
+	}
+	if got := testutil.ToFloat64(m.DatasetRows.WithLabelValues("kafka", "events")); got != 9 {
+		t.Errorf("Expected 9 rows, got %v", got)
+	}
+}
+
+func TestSelectDatasets(t *testing.T) {
+	stats := []*DatasetStats{
+		{Source: "mysql", Dataset: "shop.orders", DataSize: 10},
+		{Source: "mysql", Dataset: "shop.customers", DataSize: 30},
+		{Source: "mysql", Dataset: "shop.items", DataSize: 20},
+		{Source: "kafka", Dataset: "events.clicks", DataSize: 1},
+	}
+	selected := SelectDatasets(stats, &DatasetMetricsConfig{TopN: 2, Allowlist: []string{"KAFKA:events.*"}})
+	want := []string{"events.clicks", "shop.customers", "shop.items"}
+	if len(selected) != len(want) {
+		t.Fatalf("Expected %d datasets, got %d", len(want), len(selected))
+	}
+	for i, s := range selected {
+		if s.Dataset != want[i] {
+			t.Errorf("Dataset %d: expected %s, got %s", i, want[i], s.Dataset)
+		}
+	}
+
+	if selected := SelectDatasets(stats, &DatasetMetricsConfig{Allowlist: []string{"shop.orders"}}); len(selected) != 1 || selected[0].Dataset != "shop.orders" {
+		t.Errorf("Expected only the allowlist without TopN, got %v", selected)
+	}
+}
+
+func TestDatasetExporterCollect(t *testing.T) {
+	provider := staticStats{
+		{Source: "mysql", Dataset: "shop.orders", RowCount: 5, DataSize: 10},
+		{Source: "mysql", Dataset: "shop.items", RowCount: 6, DataSize: 20},
+	}
+	e := NewDatasetExporter(provider, &DatasetMetricsConfig{Interval: time.Hour, TopN: 1}, log.NewStdLogger(io.Discard))
+	defer e.metrics.SetDatasetStats(nil, time.Now())
+
+	e.CollectNow(context.Background())
+	if n := testutil.CollectAndCount(e.metrics.DatasetRows); n != 1 {
+		t.Errorf("Expected the largest dataset only, got %d series", n)
+	}
+	if got := testutil.ToFloat64(e.metrics.DatasetRows.WithLabelValues("mysql", "shop.items")); got != 6 {
+		t.Errorf("Expected 6 rows, got %v", got)
+	}
+}
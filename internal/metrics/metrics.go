@@ -38,6 +38,20 @@ type Metrics struct {
 	HTTPRequestDuration  *prometheus.HistogramVec
 	HTTPRequestsInFlight prometheus.Gauge
 
+	// Lineage graph metrics
+	LineageEdges       *prometheus.GaugeVec
+	LineageJobs        prometheus.Gauge
+	LineageEdgesPruned *prometheus.CounterVec
+
+	// Schema change metrics
+	SchemaChanges *prometheus.CounterVec
+
+	// Dataset metrics
+	DatasetRows        *prometheus.GaugeVec
+	DatasetSize        *prometheus.GaugeVec
+	DatasetFreshness   *prometheus.GaugeVec
+	DatasetConsumerLag *prometheus.GaugeVec
+
 	// System metrics
 	SystemUptime    prometheus.Gauge
 	SystemStartTime prometheus.Gauge
@@ -253,6 +267,88 @@ func newMetrics() *Metrics {
 		},
 	)
 
+	// Lineage graph metrics
+	m.LineageEdges = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "metadata",
+			Subsystem: "lineage",
+			Name:      "edges",
+			Help:      "Number of lineage edges by state (current, retired)",
+		},
+		[]string{"state"},
+	)
+
+	m.LineageJobs = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "metadata",
+			Subsystem: "lineage",
+			Name:      "jobs",
+			Help:      "Number of job nodes in the lineage graph",
+		},
+	)
+
+	m.LineageEdgesPruned = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "metadata",
+			Subsystem: "lineage",
+			Name:      "edges_pruned_total",
+			Help:      "Total number of lineage edges pruned by retention policies",
+		},
+		[]string{"action"},
+	)
+
+	// Schema change metrics
+	m.SchemaChanges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "metadata",
+			Subsystem: "schema",
+			Name:      "changes_total",
+			Help:      "Total number of schema changes found by syncs, and of the columns they added, dropped or modified",
+		},
+		[]string{"source", "change"},
+	)
+
+	// Dataset metrics
+	m.DatasetRows = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "metadata",
+			Subsystem: "dataset",
+			Name:      "rows",
+			Help:      "Number of rows (messages for topics) of a dataset",
+		},
+		[]string{"source", "dataset"},
+	)
+
+	m.DatasetSize = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "metadata",
+			Subsystem: "dataset",
+			Name:      "size_bytes",
+			Help:      "Data size of a dataset in bytes",
+		},
+		[]string{"source", "dataset"},
+	)
+
+	m.DatasetFreshness = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "metadata",
+			Subsystem: "dataset",
+			Name:      "freshness_age_seconds",
+			Help:      "Seconds since a dataset was last seen changing",
+		},
+		[]string{"source", "dataset"},
+	)
+
+	m.DatasetConsumerLag = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "metadata",
+			Subsystem: "dataset",
+			Name:      "consumer_lag",
+			Help:      "Messages not yet consumed by the furthest-behind consumer group of a dataset",
+		},
+		[]string{"source", "dataset"},
+	)
+
 	// System metrics
 	m.SystemUptime = prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -292,6 +388,14 @@ func newMetrics() *Metrics {
 		m.HTTPRequestsTotal,
 		m.HTTPRequestDuration,
 		m.HTTPRequestsInFlight,
+		m.LineageEdges,
+		m.LineageJobs,
+		m.LineageEdgesPruned,
+		m.SchemaChanges,
+		m.DatasetRows,
+		m.DatasetSize,
+		m.DatasetFreshness,
+		m.DatasetConsumerLag,
 		m.SystemUptime,
 		m.SystemStartTime,
 	)
@@ -316,6 +420,14 @@ func newMetrics() *Metrics {
 		m.HTTPRequestsTotal,
 		m.HTTPRequestDuration,
 		m.HTTPRequestsInFlight,
+		m.LineageEdges,
+		m.LineageJobs,
+		m.LineageEdgesPruned,
+		m.SchemaChanges,
+		m.DatasetRows,
+		m.DatasetSize,
+		m.DatasetFreshness,
+		m.DatasetConsumerLag,
 		m.SystemUptime,
 		m.SystemStartTime,
 	)
@@ -427,6 +539,56 @@ func (m *Metrics) DecHTTPRequestsInFlight() {
 	m.HTTPRequestsInFlight.Dec()
 }
 
+// Lineage metric helpers
+
+// SetLineageGraphSize sets the lineage graph size gauges
+func (m *Metrics) SetLineageGraphSize(current, retired, jobs int) {
+	m.LineageEdges.WithLabelValues("current").Set(float64(current))
+	m.LineageEdges.WithLabelValues("retired").Set(float64(retired))
+	m.LineageJobs.Set(float64(jobs))
+}
+
+// RecordLineagePruned records lineage edges removed or retired by retention
+func (m *Metrics) RecordLineagePruned(deleted, retired int) {
+	m.LineageEdgesPruned.WithLabelValues("deleted").Add(float64(deleted))
+	m.LineageEdgesPruned.WithLabelValues("retired").Add(float64(retired))
+}
+
+// Schema change metric helpers
+
+// RecordSchemaChange records a schema change of a table of a source and the
+// columns it added, dropped and modified
+func (m *Metrics) RecordSchemaChange(source string, added, dropped, modified int) {
+	m.SchemaChanges.WithLabelValues(source, "table").Inc()
+	m.SchemaChanges.WithLabelValues(source, "column_added").Add(float64(added))
+	m.SchemaChanges.WithLabelValues(source, "column_dropped").Add(float64(dropped))
+	m.SchemaChanges.WithLabelValues(source, "column_modified").Add(float64(modified))
+}
+
+// Dataset metric helpers
+
+// SetDatasetStats replaces the dataset gauges with stats as of now, so
+// datasets that are no longer selected stop being exported
+func (m *Metrics) SetDatasetStats(stats []*DatasetStats, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.DatasetRows.Reset()
+	m.DatasetSize.Reset()
+	m.DatasetFreshness.Reset()
+	m.DatasetConsumerLag.Reset()
+	for _, s := range stats {
+		m.DatasetRows.WithLabelValues(s.Source, s.Dataset).Set(float64(s.RowCount))
+		m.DatasetSize.WithLabelValues(s.Source, s.Dataset).Set(float64(s.DataSize))
+		if !s.UpdatedAt.IsZero() {
+			m.DatasetFreshness.WithLabelValues(s.Source, s.Dataset).Set(now.Sub(s.UpdatedAt).Seconds())
+		}
+		if s.ConsumerLag != nil {
+			m.DatasetConsumerLag.WithLabelValues(s.Source, s.Dataset).Set(float64(*s.ConsumerLag))
+		}
+	}
+}
+
 // System metric helpers
 
 // UpdateUptime updates the system uptime
@@ -454,4 +616,12 @@ func (m *Metrics) Reset() {
 	m.HTTPRequestsTotal.Reset()
 	m.HTTPRequestDuration.Reset()
 	m.HTTPRequestsInFlight.Set(0)
+	m.LineageEdges.Reset()
+	m.LineageJobs.Set(0)
+	m.LineageEdgesPruned.Reset()
+	m.SchemaChanges.Reset()
+	m.DatasetRows.Reset()
+	m.DatasetSize.Reset()
+	m.DatasetFreshness.Reset()
+	m.DatasetConsumerLag.Reset()
 }
@@ -0,0 +1,22 @@
+// Package model defines the metadata entities shared by the service layer.
+package model
+
+import "time"
+
+// TableMetadata represents the metadata of a table managed by the metadata service.
+type TableMetadata struct {
+	Database  string            `json:"database"`
+	Table     string            `json:"table"`
+	TableType string            `json:"table_type"`
+	Comment   string            `json:"comment,omitempty"`
+	Columns   []*ColumnMetadata `json:"columns"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// ColumnMetadata represents the metadata of a table column.
+type ColumnMetadata struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+	Comment  string `json:"comment,omitempty"`
+}
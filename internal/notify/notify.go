@@ -0,0 +1,440 @@
+// Package notify sends schema drift, policy and sync failure notifications to
+// Slack, Microsoft Teams and DingTalk channels through incoming webhooks, or
+// as JSON events to any webhook. Besides the configured team channels, the
+// users subscribed to a table receive its notifications in their own
+// channels.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"go-metadata/internal/biz"
+	"go-metadata/internal/store"
+	"go-metadata/internal/urn"
+)
+
+// Channel types.
+const (
+	TypeSlack    = "slack"
+	TypeTeams    = "teams"
+	TypeDingTalk = "dingtalk"
+	// TypeWebhook posts the notification itself as JSON, with its rendered
+	// message as text.
+	TypeWebhook = "webhook"
+)
+
+// Headers of signed webhook requests.
+const (
+	HeaderTimestamp = "X-Metadata-Timestamp"
+	HeaderSignature = "X-Metadata-Signature"
+)
+
+// defaultTemplates are the message templates of the event types without a
+// channel template.
+var defaultTemplates = map[string]string{
+	biz.EventSchemaDrift: `Schema drift on {{.Table}} ({{.Source}}): {{.Summary}}
+{{range .Changes}}- {{.}}
+{{end}}{{with .Mentions}}Owners: {{.}}{{end}}`,
+	biz.EventSLABreach: `SLA breach on {{.Table}} ({{.Source}}): {{.Summary}}
+{{with .Mentions}}Owners: {{.}}{{end}}`,
+	biz.EventPolicyViolation: `Policy violation on {{.Table}} ({{.Source}}): {{.Summary}}
+{{with .Mentions}}Owners: {{.}}{{end}}`,
+	biz.EventSyncFailed: `Sync of {{.Source}} failed: {{.Summary}}
+{{range .Changes}}- {{.}}
+{{end}}{{with .Mentions}}Owners: {{.}}{{end}}`,
+}
+
+// Config configures the notification channels.
+type Config struct {
+	Channels []*ChannelConfig `yaml:"channels"`
+}
+
+// ChannelConfig configures a Slack, Teams or DingTalk channel, or a webhook.
+type ChannelConfig struct {
+	Name string `yaml:"name"`
+	// Type is TypeSlack, TypeTeams, TypeDingTalk or TypeWebhook.
+	Type string `yaml:"type"`
+	// WebhookURL is the incoming webhook of the channel.
+	WebhookURL string `yaml:"webhook_url"`
+	// Events are the event types sent to the channel; empty sends all.
+	Events []string `yaml:"events"`
+	// Sources are glob patterns (see path.Match) of the sources whose
+	// events are sent to the channel; empty sends all.
+	Sources []string `yaml:"sources"`
+	// Templates override the message template (text/template) of event
+	// types. Templates see the fields of biz.Notification, Mentions and URN.
+	Templates map[string]string `yaml:"templates"`
+	// Mentions maps owners to Slack member IDs (U123ABC), Teams user
+	// principal names or DingTalk user IDs, so that owners are mentioned;
+	// other owners are written as @owner.
+	Mentions map[string]string `yaml:"mentions"`
+	// Secret signs the requests: a webhook receives the HMAC-SHA256 of
+	// "<timestamp>.<body>" in the X-Metadata-Signature header as
+	// sha256=<hex>, and a DingTalk robot with signing enabled the timestamp
+	// and sign parameters it requires.
+	Secret string `yaml:"secret"`
+	// Retries is how many times a request failing with a network error, 429
+	// or 5xx is retried; none by default.
+	Retries int `yaml:"retries"`
+	// RetryBackoff is the wait before the first retry, doubled for each
+	// next one; defaults to 1s.
+	RetryBackoff time.Duration `yaml:"retry_backoff"`
+	// Timeout bounds each request; defaults to 10s.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// message is the data of message templates.
+type message struct {
+	*biz.Notification
+	// Mentions are the owners mentioned as the channel type requires.
+	Mentions string
+	// URN is the identifier of the table in the URN scheme of the
+	// dispatcher, its qualified name by default.
+	URN string
+}
+
+// mention is an owner mentioned in a message.
+type mention struct {
+	Owner string
+	ID    string
+}
+
+// channel sends messages to a webhook.
+type channel struct {
+	name       string
+	kind       string
+	url        string
+	events     map[string]bool
+	sources    []string
+	templates  map[string]*template.Template
+	mentions   map[string]string
+	secret     string
+	retries    int
+	backoff    time.Duration
+	httpClient *http.Client
+}
+
+// Watchers finds the channels of the users subscribed to the events of a
+// table, e.g. *store.Store.
+type Watchers interface {
+	Watchers(ctx context.Context, source, table, event string) ([]store.UserChannel, error)
+}
+
+// Dispatcher sends notifications to the channels configured for their event
+// type and source, and to the channels of the users watching their table.
+// It implements biz.Notifier.
+type Dispatcher struct {
+	channels []*channel
+	urns     *urn.Namer
+	watchers Watchers
+}
+
+var _ biz.Notifier = (*Dispatcher)(nil)
+
+// NewDispatcher creates a dispatcher for the channels of cfg.
+func NewDispatcher(cfg *Config) (*Dispatcher, error) {
+	d := &Dispatcher{}
+	if cfg == nil {
+		return d, nil
+	}
+	for i, cc := range cfg.Channels {
+		ch, err := newChannel(cc)
+		if err != nil {
+			name := fmt.Sprintf("%d", i+1)
+			if cc != nil && cc.Name != "" {
+				name = cc.Name
+			}
+			return nil, fmt.Errorf("notification channel %s: %w", name, err)
+		}
+		d.channels = append(d.channels, ch)
+	}
+	return d, nil
+}
+
+func newChannel(cfg *ChannelConfig) (*channel, error) {
+	if cfg == nil {
+		return nil, errors.New("empty channel")
+	}
+	switch cfg.Type {
+	case TypeSlack, TypeTeams, TypeDingTalk, TypeWebhook:
+	default:
+		return nil, fmt.Errorf("type must be %s, %s, %s or %s", TypeSlack, TypeTeams, TypeDingTalk, TypeWebhook)
+	}
+	if cfg.WebhookURL == "" {
+		return nil, errors.New("webhook_url is required")
+	}
+	if _, err := url.Parse(cfg.WebhookURL); err != nil {
+		return nil, fmt.Errorf("bad webhook_url: %w", err)
+	}
+	if cfg.Retries < 0 {
+		return nil, errors.New("retries must not be negative")
+	}
+
+	ch := &channel{
+		name:       cfg.Name,
+		kind:       cfg.Type,
+		url:        cfg.WebhookURL,
+		events:     make(map[string]bool, len(cfg.Events)),
+		sources:    cfg.Sources,
+		templates:  make(map[string]*template.Template, len(defaultTemplates)),
+		mentions:   cfg.Mentions,
+		secret:     cfg.Secret,
+		retries:    cfg.Retries,
+		backoff:    cfg.RetryBackoff,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+	if ch.httpClient.Timeout <= 0 {
+		ch.httpClient.Timeout = 10 * time.Second
+	}
+	if ch.backoff <= 0 {
+		ch.backoff = time.Second
+	}
+	for _, e := range cfg.Events {
+		if _, ok := defaultTemplates[e]; !ok {
+			return nil, fmt.Errorf("unknown event type %q", e)
+		}
+		ch.events[e] = true
+	}
+	for _, pattern := range cfg.Sources {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("bad source pattern %q", pattern)
+		}
+	}
+	for event, text := range defaultTemplates {
+		if custom, ok := cfg.Templates[event]; ok {
+			text = custom
+		}
+		tmpl, err := template.New(event).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", event, err)
+		}
+		ch.templates[event] = tmpl
+	}
+	for event := range cfg.Templates {
+		if _, ok := defaultTemplates[event]; !ok {
+			return nil, fmt.Errorf("template of unknown event type %q", event)
+		}
+	}
+	return ch, nil
+}
+
+// SetURNs sets the URN scheme of the tables of messages, the .URN of
+// templates, so that messages can link to the table in e.g. DataHub.
+func (d *Dispatcher) SetURNs(n *urn.Namer) {
+	d.urns = n
+}
+
+// SetWatchers sets the subscriptions of users: notifications are sent to the
+// channels of the users watching their table too.
+func (d *Dispatcher) SetWatchers(w Watchers) {
+	d.watchers = w
+}
+
+// CheckChannel returns an error if a user channel of channelType could not
+// be sent to.
+func CheckChannel(channelType, webhookURL string) error {
+	_, err := newChannel(&ChannelConfig{Type: channelType, WebhookURL: webhookURL})
+	return err
+}
+
+// Notify sends n to every channel subscribed to its event type and source,
+// and to the channels of the users watching its table. A failing channel
+// does not keep the others from being notified.
+func (d *Dispatcher) Notify(ctx context.Context, n *biz.Notification) error {
+	id := n.Table
+	if d.urns != nil && n.Table != "" {
+		id = d.urns.DatasetURN(n.Source, n.Table)
+	}
+	var errs []error
+	for _, ch := range d.channels {
+		if !ch.accepts(n) {
+			continue
+		}
+		if err := ch.send(ctx, n, id); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ch.name, err))
+		}
+	}
+	if d.watchers == nil {
+		return errors.Join(errs...)
+	}
+	watchers, err := d.watchers.Watchers(ctx, n.Source, n.Table, n.Event)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("find watchers: %w", err))
+	}
+	for _, w := range watchers {
+		ch, err := newChannel(&ChannelConfig{Name: w.User, Type: w.Type, WebhookURL: w.WebhookURL})
+		if err == nil {
+			err = ch.send(ctx, n, id)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s channel of %s: %w", w.Type, w.User, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (ch *channel) accepts(n *biz.Notification) bool {
+	if len(ch.events) > 0 && !ch.events[n.Event] {
+		return false
+	}
+	if len(ch.sources) == 0 {
+		return true
+	}
+	for _, pattern := range ch.sources {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(n.Source)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// render returns the text of the message of n and the owners it mentions.
+func (ch *channel) render(n *biz.Notification, id string) (string, []mention, error) {
+	tmpl, ok := ch.templates[n.Event]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown event type %q", n.Event)
+	}
+
+	var mentions []mention
+	names := make([]string, 0, len(n.Owners))
+	for _, owner := range n.Owners {
+		id, ok := ch.mentions[owner]
+		switch {
+		case !ok || ch.kind == TypeWebhook:
+			names = append(names, "@"+owner)
+		case ch.kind == TypeSlack:
+			names = append(names, "<@"+id+">")
+		case ch.kind == TypeDingTalk:
+			// DingTalk only notifies the users of at that the text mentions
+			names = append(names, "@"+id)
+			mentions = append(mentions, mention{Owner: owner, ID: id})
+		default:
+			names = append(names, "<at>"+owner+"</at>")
+			mentions = append(mentions, mention{Owner: owner, ID: id})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, message{Notification: n, Mentions: strings.Join(names, " "), URN: id}); err != nil {
+		return "", nil, fmt.Errorf("render %s: %w", n.Event, err)
+	}
+	return strings.TrimSpace(buf.String()), mentions, nil
+}
+
+// send posts the message of n, retrying requests that may succeed later.
+func (ch *channel) send(ctx context.Context, n *biz.Notification, id string) error {
+	text, mentions, err := ch.render(n, id)
+	if err != nil {
+		return err
+	}
+	var payload any
+	switch ch.kind {
+	case TypeSlack:
+		payload = slackPayload(text)
+	case TypeTeams:
+		payload = teamsPayload(text, mentions)
+	case TypeDingTalk:
+		payload = dingTalkPayload(text, mentions)
+	default:
+		payload = webhookPayload(n, id, text)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+
+	backoff := ch.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := ch.post(ctx, body)
+		if err == nil || !retry || attempt == ch.retries {
+			return err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// post posts a message once. It reports whether a failed request is worth
+// retrying: network errors, 429 and 5xx are.
+func (ch *channel) post(ctx context.Context, body []byte) (bool, error) {
+	target := ch.url
+	if ch.secret != "" && ch.kind == TypeDingTalk {
+		target = ch.signDingTalk(time.Now())
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ch.secret != "" && ch.kind == TypeWebhook {
+		timestamp, signature := Sign(ch.secret, time.Now(), body)
+		req.Header.Set(HeaderTimestamp, timestamp)
+		req.Header.Set(HeaderSignature, signature)
+	}
+
+	resp, err := ch.httpClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("post message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5
+		return retry, fmt.Errorf("post message: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return false, nil
+}
+
+// Sign returns the timestamp and signature headers of a webhook request
+// body sent at t: the Unix time in seconds and sha256= followed by the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed by secret. Receivers should
+// reject old timestamps to prevent replays.
+func Sign(secret string, t time.Time, body []byte) (timestamp, signature string) {
+	timestamp = strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return timestamp, "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// signDingTalk returns the webhook URL with the timestamp (in milliseconds)
+// and sign parameters of robots with signing enabled: the base64
+// HMAC-SHA256 of "<timestamp>\n<secret>" keyed by the secret.
+func (ch *channel) signDingTalk(t time.Time) string {
+	timestamp := strconv.FormatInt(t.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(ch.secret))
+	mac.Write([]byte(timestamp + "\n" + ch.secret))
+
+	u, err := url.Parse(ch.url)
+	if err != nil {
+		return ch.url
+	}
+	q := u.Query()
+	q.Set("timestamp", timestamp)
+	q.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
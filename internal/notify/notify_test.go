@@ -0,0 +1,366 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-metadata/internal/biz"
+	"go-metadata/internal/store"
+	"go-metadata/internal/urn"
+)
+
+func webhook(t *testing.T, received *[]map[string]any) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		*received = append(*received, payload)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func drift() *biz.Notification {
+	return &biz.Notification{
+		Event:   biz.EventSchemaDrift,
+		Source:  "mysql",
+		Table:   "shop.orders",
+		Summary: "schema changed: 2 column changes",
+		Changes: []string{"added column note TEXT", "dropped column legacy"},
+		Owners:  []string{"alice", "bob"},
+	}
+}
+
+func TestDispatcher_Slack(t *testing.T) {
+	var received []map[string]any
+	server := webhook(t, &received)
+
+	d, err := NewDispatcher(&Config{Channels: []*ChannelConfig{{
+		Name:       "data-team",
+		Type:       TypeSlack,
+		WebhookURL: server.URL,
+		Mentions:   map[string]string{"alice": "U123"},
+	}}})
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+	if err := d.Notify(context.Background(), drift()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(received))
+	}
+	text, _ := received[0]["text"].(string)
+	for _, want := range []string{"Schema drift on shop.orders (mysql)", "- added column note TEXT", "- dropped column legacy", "Owners: <@U123> @bob"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Message %q does not contain %q", text, want)
+		}
+	}
+}
+
+func TestDispatcher_URN(t *testing.T) {
+	var received []map[string]any
+	server := webhook(t, &received)
+
+	d, err := NewDispatcher(&Config{Channels: []*ChannelConfig{{
+		Type:       TypeSlack,
+		WebhookURL: server.URL,
+		Templates:  map[string]string{biz.EventSchemaDrift: "{{.Summary}}: {{.URN}}"},
+	}}})
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+	namer, err := urn.New(&urn.Config{Scheme: urn.SchemeDataHub, Platform: "mysql"})
+	if err != nil {
+		t.Fatalf("urn.New failed: %v", err)
+	}
+	d.SetURNs(namer)
+	if err := d.Notify(context.Background(), drift()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	want := "schema changed: 2 column changes: urn:li:dataset:(urn:li:dataPlatform:mysql,shop.orders,PROD)"
+	if len(received) != 1 || received[0]["text"] != want {
+		t.Errorf("Expected message %q, got %v", want, received)
+	}
+}
+
+func TestDispatcher_TeamsMentions(t *testing.T) {
+	var received []map[string]any
+	server := webhook(t, &received)
+
+	d, err := NewDispatcher(&Config{Channels: []*ChannelConfig{{
+		Type:       TypeTeams,
+		WebhookURL: server.URL,
+		Templates:  map[string]string{biz.EventSchemaDrift: "{{.Table}} changed, cc {{.Mentions}}"},
+		Mentions:   map[string]string{"alice": "alice@example.com"},
+	}}})
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+	if err := d.Notify(context.Background(), drift()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(received))
+	}
+	attachments, _ := received[0]["attachments"].([]any)
+	if len(attachments) != 1 {
+		t.Fatalf("Unexpected attachments %+v", received[0])
+	}
+	card, _ := attachments[0].(map[string]any)["content"].(map[string]any)
+	block, _ := card["body"].([]any)[0].(map[string]any)
+	if block["text"] != "shop.orders changed, cc <at>alice</at> @bob" {
+		t.Errorf("Unexpected text %q", block["text"])
+	}
+	entities, _ := card["msteams"].(map[string]any)["entities"].([]any)
+	if len(entities) != 1 {
+		t.Fatalf("Expected 1 mention entity, got %+v", entities)
+	}
+	mentioned, _ := entities[0].(map[string]any)["mentioned"].(map[string]any)
+	if mentioned["id"] != "alice@example.com" {
+		t.Errorf("Unexpected mention %+v", mentioned)
+	}
+}
+
+func TestDispatcher_Routing(t *testing.T) {
+	var slas, warehouse []map[string]any
+	slaServer := webhook(t, &slas)
+	warehouseServer := webhook(t, &warehouse)
+
+	d, err := NewDispatcher(&Config{Channels: []*ChannelConfig{
+		{Name: "sla", Type: TypeSlack, WebhookURL: slaServer.URL, Events: []string{biz.EventSLABreach}},
+		{Name: "warehouse", Type: TypeTeams, WebhookURL: warehouseServer.URL, Sources: []string{"hive*"}},
+	}})
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+
+	ctx := context.Background()
+	d.Notify(ctx, drift())
+	d.Notify(ctx, &biz.Notification{Event: biz.EventSLABreach, Source: "hive_prod", Table: "dw.sales"})
+	d.Notify(ctx, &biz.Notification{Event: biz.EventPolicyViolation, Source: "hive_prod", Table: "dw.sales"})
+
+	if len(slas) != 1 {
+		t.Errorf("Expected 1 SLA message, got %d", len(slas))
+	}
+	if len(warehouse) != 2 {
+		t.Errorf("Expected 2 warehouse messages, got %d", len(warehouse))
+	}
+}
+
+// subscriptions is a Watchers of fixed subscriptions and user channels.
+type subscriptions struct {
+	subs     []store.Subscription
+	channels []store.UserChannel
+}
+
+func (s *subscriptions) Watchers(ctx context.Context, source, table, event string) ([]store.UserChannel, error) {
+	var watchers []store.UserChannel
+	for _, sub := range s.subs {
+		if !sub.Matches(source, table, event) {
+			continue
+		}
+		for _, c := range s.channels {
+			if c.User == sub.User {
+				watchers = append(watchers, c)
+			}
+		}
+	}
+	return watchers, nil
+}
+
+func TestDispatcher_Watchers(t *testing.T) {
+	var alice, bob []map[string]any
+	aliceServer := webhook(t, &alice)
+	bobServer := webhook(t, &bob)
+
+	d, err := NewDispatcher(nil)
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+	d.SetWatchers(&subscriptions{
+		subs: []store.Subscription{
+			{User: "alice", Source: "mysql", Table: "shop.orders"},
+			{User: "bob", Source: "mysql", Table: "shop.*", Events: []string{biz.EventSLABreach}},
+		},
+		channels: []store.UserChannel{
+			{User: "alice", Type: TypeSlack, WebhookURL: aliceServer.URL},
+			{User: "bob", Type: TypeWebhook, WebhookURL: bobServer.URL},
+		},
+	})
+
+	ctx := context.Background()
+	if err := d.Notify(ctx, drift()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	d.Notify(ctx, &biz.Notification{Event: biz.EventSLABreach, Source: "mysql", Table: "shop.users"})
+	d.Notify(ctx, &biz.Notification{Event: biz.EventSchemaDrift, Source: "mysql", Table: "shop.users"})
+
+	if len(alice) != 1 || !strings.HasPrefix(alice[0]["text"].(string), "Schema drift on shop.orders") {
+		t.Errorf("Expected the drift of shop.orders for alice, got %v", alice)
+	}
+	if len(bob) != 1 || bob[0]["event"] != biz.EventSLABreach || bob[0]["table"] != "shop.users" {
+		t.Errorf("Expected the SLA breach of shop.users for bob, got %v", bob)
+	}
+}
+
+func TestDispatcher_SignedWebhook(t *testing.T) {
+	var attempts atomic.Int32
+	var body []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+	}))
+	t.Cleanup(server.Close)
+
+	d, err := NewDispatcher(&Config{Channels: []*ChannelConfig{{
+		Type:         TypeWebhook,
+		WebhookURL:   server.URL,
+		Secret:       "s3cret",
+		Retries:      2,
+		RetryBackoff: time.Millisecond,
+	}}})
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+	if err := d.Notify(context.Background(), drift()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts.Load())
+	}
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(header.Get(HeaderTimestamp) + "."))
+	mac.Write(body)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); header.Get(HeaderSignature) != want {
+		t.Errorf("Signature = %q, want %q", header.Get(HeaderSignature), want)
+	}
+	var event map[string]any
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if event["event"] != biz.EventSchemaDrift || event["table"] != "shop.orders" || event["source"] != "mysql" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	if changes, _ := event["changes"].([]any); len(changes) != 2 {
+		t.Errorf("Unexpected changes %+v", event["changes"])
+	}
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+
+	for path, want := range map[string]int32{"/busy": 3, "/gone": 1} {
+		attempts.Store(0)
+		d, err := NewDispatcher(&Config{Channels: []*ChannelConfig{{
+			Type:         TypeWebhook,
+			WebhookURL:   server.URL + path,
+			Retries:      2,
+			RetryBackoff: time.Millisecond,
+		}}})
+		if err != nil {
+			t.Fatalf("NewDispatcher failed: %v", err)
+		}
+		if err := d.Notify(context.Background(), drift()); err == nil {
+			t.Errorf("%s: expected error", path)
+		}
+		if attempts.Load() != want {
+			t.Errorf("%s: expected %d attempts, got %d", path, want, attempts.Load())
+		}
+	}
+}
+
+func TestDispatcher_DingTalk(t *testing.T) {
+	var query map[string][]string
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	t.Cleanup(server.Close)
+
+	d, err := NewDispatcher(&Config{Channels: []*ChannelConfig{{
+		Type:       TypeDingTalk,
+		WebhookURL: server.URL + "/robot/send?access_token=abc",
+		Secret:     "SECabc",
+		Mentions:   map[string]string{"alice": "manager123"},
+	}}})
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+	if err := d.Notify(context.Background(), &biz.Notification{
+		Event:   biz.EventSyncFailed,
+		Source:  "mysql",
+		Summary: "0 tables fetched, 1 failed",
+		Changes: []string{"shop.orders: connection reset"},
+		Owners:  []string{"alice"},
+	}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if query["access_token"][0] != "abc" {
+		t.Errorf("Lost access_token: %v", query)
+	}
+	timestamp := query["timestamp"][0]
+	mac := hmac.New(sha256.New, []byte("SECabc"))
+	mac.Write([]byte(timestamp + "\nSECabc"))
+	if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); query["sign"][0] != want {
+		t.Errorf("sign = %q, want %q", query["sign"][0], want)
+	}
+	text, _ := received["text"].(map[string]any)["content"].(string)
+	if want := "Sync of mysql failed: 0 tables fetched, 1 failed\n- shop.orders: connection reset\nOwners: @manager123"; text != want {
+		t.Errorf("Unexpected text %q", text)
+	}
+	ids, _ := received["at"].(map[string]any)["atUserIds"].([]any)
+	if !reflect.DeepEqual(ids, []any{"manager123"}) {
+		t.Errorf("Unexpected atUserIds %v", ids)
+	}
+}
+
+func TestNewDispatcher_Invalid(t *testing.T) {
+	tests := []*ChannelConfig{
+		{Type: "email", WebhookURL: "http://example.com"},
+		{Type: TypeSlack},
+		{Type: TypeWebhook, WebhookURL: "http://example.com", Retries: -1},
+		{Type: TypeSlack, WebhookURL: "http://example.com", Events: []string{"deleted"}},
+		{Type: TypeSlack, WebhookURL: "http://example.com", Templates: map[string]string{biz.EventSLABreach: "{{.Table"}},
+	}
+	for _, cfg := range tests {
+		if _, err := NewDispatcher(&Config{Channels: []*ChannelConfig{cfg}}); err == nil {
+			t.Errorf("Expected error for %+v", cfg)
+		}
+	}
+}
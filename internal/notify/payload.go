@@ -0,0 +1,78 @@
+package notify
+
+import "go-metadata/internal/biz"
+
+// slackPayload is an incoming webhook message; Slack renders mrkdwn and
+// <@member> mentions in text.
+func slackPayload(text string) map[string]any {
+	return map[string]any{"text": text}
+}
+
+// teamsPayload is an incoming webhook message carrying an Adaptive Card.
+// Teams only renders <at>owner</at> as a mention if the card lists it as an
+// entity.
+func teamsPayload(text string, mentions []mention) map[string]any {
+	entities := make([]map[string]any, 0, len(mentions))
+	for _, m := range mentions {
+		entities = append(entities, map[string]any{
+			"type": "mention",
+			"text": "<at>" + m.Owner + "</at>",
+			"mentioned": map[string]any{
+				"id":   m.ID,
+				"name": m.Owner,
+			},
+		})
+	}
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body": []map[string]any{
+			{"type": "TextBlock", "text": text, "wrap": true},
+		},
+		"msteams": map[string]any{"entities": entities},
+	}
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{
+			{"contentType": "application/vnd.microsoft.card.adaptive", "content": card},
+		},
+	}
+}
+
+// dingTalkPayload is a text message of a DingTalk robot. DingTalk only
+// notifies the users of at that the text mentions as @user.
+func dingTalkPayload(text string, mentions []mention) map[string]any {
+	ids := make([]string, 0, len(mentions))
+	for _, m := range mentions {
+		ids = append(ids, m.ID)
+	}
+	return map[string]any{
+		"msgtype": "text",
+		"text":    map[string]any{"content": text},
+		"at":      map[string]any{"atUserIds": ids},
+	}
+}
+
+// webhookPayload is the event sent to generic webhooks: the notification,
+// the URN of its table and its rendered message.
+func webhookPayload(n *biz.Notification, id, text string) map[string]any {
+	payload := map[string]any{
+		"event":   n.Event,
+		"source":  n.Source,
+		"summary": n.Summary,
+		"text":    text,
+		"at":      n.At,
+	}
+	if n.Table != "" {
+		payload["table"] = n.Table
+		payload["urn"] = id
+	}
+	if len(n.Changes) > 0 {
+		payload["changes"] = n.Changes
+	}
+	if len(n.Owners) > 0 {
+		payload["owners"] = n.Owners
+	}
+	return payload
+}
@@ -0,0 +1,99 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "mysql dsn",
+			in:   "invalid DSN root:s3cr:et@tcp(db:3306)/shop?timeout=10s",
+			want: "invalid DSN root:***@tcp(db:3306)/shop?timeout=10s",
+		},
+		{
+			name: "mysql socket dsn",
+			in:   "open app:p@ss@unix(/var/run/mysqld.sock)/shop",
+			want: "open app:***@unix(/var/run/mysqld.sock)/shop",
+		},
+		{
+			name: "hive dsn",
+			in:   `dial "hive:hunter2@hs2:10000/default?auth=NOSASL"`,
+			want: `dial "hive:***@hs2:10000/default?auth=NOSASL"`,
+		},
+		{
+			name: "url",
+			in:   `parse "clickhouse://default:hunter2@ch:9000/db": invalid port`,
+			want: `parse "clickhouse://default:***@ch:9000/db": invalid port`,
+		},
+		{
+			name: "postgres key/value",
+			in:   "host=pg port=5432 user=app password=hunter2 dbname=shop sslmode=disable",
+			want: "host=pg port=5432 user=app password=*** dbname=shop sslmode=disable",
+		},
+		{
+			name: "quoted value",
+			in:   `password='it\'s secret' dbname=shop`,
+			want: `password=*** dbname=shop`,
+		},
+		{
+			name: "json",
+			in:   `{"user":"app","password":"hunter2","api_key": "abc"}`,
+			want: `{"user":"app","password":***,"api_key": ***}`,
+		},
+		{
+			name: "query parameters",
+			in:   "https://es:9200/_search?token=abc&secret_key=def&size=10",
+			want: "https://es:9200/_search?token=***&secret_key=***&size=10",
+		},
+		{
+			name: "no credentials",
+			in:   `pq: password authentication failed for user "app"; dial tcp 10.0.0.1:5432: connect: connection refused`,
+			want: `pq: password authentication failed for user "app"; dial tcp 10.0.0.1:5432: connect: connection refused`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := String(tt.in); got != tt.want {
+				t.Errorf("String(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := Error(fmt.Errorf("open root:hunter2@tcp(db:3306)/shop: %w", cause))
+
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("Expected the password to be masked, got %v", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected the redacted error to unwrap to its cause")
+	}
+	if Error(cause) != cause || Error(nil) != nil {
+		t.Error("Expected errors without credentials to be returned as is")
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.NewHelper(Logger(log.NewStdLogger(&buf)))
+
+	logger.Errorf("sync %s: %v", "prod", errors.New("dial clickhouse://default:hunter2@ch:9000"))
+	logger.Log(log.LevelInfo, "dsn", "user=app password=hunter2", "err", errors.New("password=hunter2"))
+
+	if out := buf.String(); strings.Contains(out, "hunter2") || !strings.Contains(out, "sync prod") {
+		t.Errorf("Expected masked credentials in the log, got %s", out)
+	}
+}
@@ -0,0 +1,245 @@
+// Package render writes the results of CLI commands in a structured output
+// format: JSON, YAML or an aligned table.
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the output format of a command.
+type Format string
+
+const (
+	// Text is the free-form output of each command.
+	Text  Format = "text"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	Table Format = "table"
+)
+
+// Structured reports whether f is a structured format rather than text.
+func (f Format) Structured() bool {
+	return f == JSON || f == YAML || f == Table
+}
+
+// Flags removes the global format flags --json, --yaml and --table from
+// args, wherever they are, and returns the remaining arguments with the
+// format the last of them sets, Text if none. Only the double-dash form is
+// global, since some commands have a -table option of their own; arguments
+// after -- are kept as they are.
+func Flags(args []string) ([]string, Format) {
+	format := Text
+	rest := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		switch arg {
+		case "--json":
+			format = JSON
+		case "--yaml":
+			format = YAML
+		case "--table":
+			format = Table
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return rest, format
+}
+
+// Write writes v to w in format f. YAML has the keys of the JSON encoding of
+// v. A table has a row per element of a slice v, or a single row for other
+// values, and a column per field of the rows; columns empty in every row
+// are left out.
+func Write(w io.Writer, f Format, v any) error {
+	switch f {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case YAML:
+		return writeYAML(w, v)
+	case Table:
+		return writeTable(w, v)
+	default:
+		return fmt.Errorf("render: unknown format %q", f)
+	}
+}
+
+// writeYAML writes the JSON encoding of v as block YAML, keeping the order
+// of its keys.
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle clears the flow and quoting styles of the JSON syntax, so that
+// the encoder picks the plain YAML ones.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// writeTable writes v as a table, its cells taken from the JSON encoding of
+// v so that columns are named and valued as in JSON.
+func writeTable(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var decoded any
+	if err := dec.Decode(&decoded); err != nil {
+		return err
+	}
+
+	columns := fieldNames(reflect.TypeOf(v))
+	var rows []map[string]any
+	switch d := decoded.(type) {
+	case nil:
+	case []any:
+		for _, elem := range d {
+			row, ok := elem.(map[string]any)
+			if !ok {
+				row = map[string]any{"value": elem}
+			}
+			rows = append(rows, row)
+		}
+	case map[string]any:
+		rows = []map[string]any{d}
+	default:
+		rows = []map[string]any{{"value": d}}
+	}
+
+	// Keys of maps and of values with their own JSON encoding follow the
+	// fields, sorted
+	known := make(map[string]bool)
+	for _, c := range columns {
+		known[c] = true
+	}
+	var extra []string
+	for _, row := range rows {
+		for key := range row {
+			if !known[key] {
+				known[key] = true
+				extra = append(extra, key)
+			}
+		}
+	}
+	sort.Strings(extra)
+	columns = append(columns, extra...)
+	if len(rows) > 0 {
+		columns = slices.DeleteFunc(columns, func(c string) bool {
+			for _, row := range rows {
+				if cell(row[c]) != "" {
+					return false
+				}
+			}
+			return true
+		})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			cells[i] = cell(row[c])
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// fieldNames returns the JSON names of the fields of the struct t, or of the
+// elements of the slice t, in order, including the promoted fields of
+// embedded structs.
+func fieldNames(t reflect.Type) []string {
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		name, _, _ := strings.Cut(tag, ",")
+		switch {
+		case name == "-":
+		case field.Anonymous && name == "":
+			names = append(names, fieldNames(field.Type)...)
+		case !field.IsExported():
+		case name == "":
+			names = append(names, field.Name)
+		default:
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// cell formats a JSON value for a table: lists of scalars are joined with
+// commas and objects are written as compact JSON.
+func cell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.ReplaceAll(v, "\n", " ")
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case []any:
+		parts := make([]string, len(v))
+		for i, elem := range v {
+			switch elem.(type) {
+			case map[string]any, []any:
+				data, _ := json.Marshal(v)
+				return string(data)
+			}
+			parts[i] = cell(elem)
+		}
+		return strings.Join(parts, ", ")
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
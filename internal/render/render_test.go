@@ -0,0 +1,74 @@
+package render
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+type base struct {
+	Database string `json:"database,omitempty"`
+}
+
+type table struct {
+	base
+	Table   string   `json:"table"`
+	Rows    int64    `json:"rows"`
+	Tags    []string `json:"tags,omitempty"`
+	Comment string   `json:"comment,omitempty"`
+	secret  string
+}
+
+func TestFlags(t *testing.T) {
+	args, format := Flags([]string{"freshness", "--json", "-table", "dw.orders", "--table", "--", "--yaml"})
+	if format != Table {
+		t.Errorf("format = %q, want table", format)
+	}
+	if want := []string{"freshness", "-table", "dw.orders", "--", "--yaml"}; !slices.Equal(args, want) {
+		t.Errorf("args = %q, want %q", args, want)
+	}
+	if _, format := Flags([]string{"list"}); format != Text || format.Structured() {
+		t.Errorf("format = %q, want text", format)
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	tables := []table{
+		{base: base{Database: "dw"}, Table: "orders", Rows: 1200, Tags: []string{"pii", "gold"}},
+		{Table: "order_items", Rows: 35, secret: "x"},
+	}
+	var b strings.Builder
+	if err := Write(&b, Table, tables); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := "DATABASE  TABLE        ROWS  TAGS\n" +
+		"dw        orders       1200  pii, gold\n" +
+		"          order_items  35    \n"
+	if b.String() != want {
+		t.Errorf("table =\n%s\nwant\n%s", b.String(), want)
+	}
+
+	// A single value is a row, and an empty list only has the header
+	b.Reset()
+	Write(&b, Table, &table{Table: "orders"})
+	if b.String() != "TABLE   ROWS\norders  0\n" {
+		t.Errorf("table = %q", b.String())
+	}
+	b.Reset()
+	Write(&b, Table, []table{})
+	if b.String() != "DATABASE  TABLE  ROWS  TAGS  COMMENT\n" {
+		t.Errorf("table = %q", b.String())
+	}
+}
+
+func TestWriteYAML(t *testing.T) {
+	var b strings.Builder
+	err := Write(&b, YAML, map[string]any{"tables": []table{{Table: "orders", Rows: 3, Comment: "2024"}}})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := "tables:\n  - table: orders\n    rows: 3\n    comment: \"2024\"\n"
+	if b.String() != want {
+		t.Errorf("yaml =\n%s\nwant\n%s", b.String(), want)
+	}
+}
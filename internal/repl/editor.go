@@ -0,0 +1,325 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// ErrInterrupt is returned by ReadLine when the user presses Ctrl-C.
+var ErrInterrupt = errors.New("interrupted")
+
+// maxListed is the number of completion candidates listed at most.
+const maxListed = 100
+
+// Completer returns the candidates completing the word ending line, the text
+// before the cursor, with the word they replace.
+type Completer func(line string) (word string, candidates []string)
+
+// Editor reads lines typed in a terminal with emacs-style editing keys,
+// history and tab completion. When its input is not a terminal, such as a
+// pipe, it reads plain lines.
+type Editor struct {
+	// Complete completes the word before the cursor on Tab; nil disables
+	// completion.
+	Complete Completer
+
+	in      *os.File
+	out     io.Writer
+	r       *bufio.Reader
+	history []string
+}
+
+// NewEditor creates an editor reading from in and echoing to out.
+func NewEditor(in *os.File, out io.Writer) *Editor {
+	return &Editor{in: in, out: out, r: bufio.NewReader(in)}
+}
+
+// ReadLine prints prompt and returns the line typed, without its newline.
+// It returns io.EOF on Ctrl-D on an empty line or at the end of the input,
+// and ErrInterrupt on Ctrl-C.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	restore, err := makeRaw(int(e.in.Fd()))
+	if err != nil {
+		return e.readPlain()
+	}
+	defer restore()
+	return e.readRaw(prompt)
+}
+
+// readPlain reads a line of input that is not a terminal, printing no
+// prompt so that piped output holds the lineage only.
+func (e *Editor) readPlain() (string, error) {
+	line, err := e.r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// lineState is the line being edited by readRaw.
+type lineState struct {
+	prompt string
+	buf    []rune
+	pos    int
+}
+
+func (e *Editor) readRaw(prompt string) (string, error) {
+	s := &lineState{prompt: prompt}
+	fmt.Fprint(e.out, prompt)
+	// hist is the index of the history entry shown, len(history) for the
+	// line being typed, which is kept in typed while browsing.
+	hist := len(e.history)
+	var typed []rune
+
+	for {
+		r, _, err := e.r.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			line := string(s.buf)
+			if strings.TrimSpace(line) != "" && (len(e.history) == 0 || e.history[len(e.history)-1] != line) {
+				e.history = append(e.history, line)
+			}
+			return line, nil
+		case 3: // Ctrl-C
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", ErrInterrupt
+		case 4: // Ctrl-D
+			if len(s.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteAt(s, s.pos)
+		case 127, 8: // Backspace, Ctrl-H
+			if s.pos > 0 {
+				s.pos--
+				e.deleteAt(s, s.pos)
+			}
+		case '\t':
+			e.complete(s)
+		case 1: // Ctrl-A
+			s.pos = 0
+			e.refresh(s)
+		case 5: // Ctrl-E
+			s.pos = len(s.buf)
+			e.refresh(s)
+		case 2: // Ctrl-B
+			e.move(s, -1)
+		case 6: // Ctrl-F
+			e.move(s, 1)
+		case 11: // Ctrl-K
+			s.buf = s.buf[:s.pos]
+			e.refresh(s)
+		case 21: // Ctrl-U
+			s.buf = append([]rune(nil), s.buf[s.pos:]...)
+			s.pos = 0
+			e.refresh(s)
+		case 23: // Ctrl-W
+			start := s.pos
+			for start > 0 && unicode.IsSpace(s.buf[start-1]) {
+				start--
+			}
+			for start > 0 && !unicode.IsSpace(s.buf[start-1]) {
+				start--
+			}
+			s.buf = append(s.buf[:start], s.buf[s.pos:]...)
+			s.pos = start
+			e.refresh(s)
+		case 12: // Ctrl-L
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+			e.refresh(s)
+		case 16, 14: // Ctrl-P, Ctrl-N
+			up := r == 16
+			hist, typed = e.browse(s, up, hist, typed)
+		case 27: // Escape sequences of the arrow and editing keys
+			seq := e.readEscape()
+			switch seq {
+			case "[A", "OA":
+				hist, typed = e.browse(s, true, hist, typed)
+			case "[B", "OB":
+				hist, typed = e.browse(s, false, hist, typed)
+			case "[C", "OC":
+				e.move(s, 1)
+			case "[D", "OD":
+				e.move(s, -1)
+			case "[H", "OH", "[1~", "[7~":
+				s.pos = 0
+				e.refresh(s)
+			case "[F", "OF", "[4~", "[8~":
+				s.pos = len(s.buf)
+				e.refresh(s)
+			case "[3~":
+				e.deleteAt(s, s.pos)
+			}
+		default:
+			if r < ' ' {
+				continue
+			}
+			s.buf = append(s.buf, 0)
+			copy(s.buf[s.pos+1:], s.buf[s.pos:])
+			s.buf[s.pos] = r
+			s.pos++
+			if s.pos == len(s.buf) {
+				// Typed or pasted at the end: echo the rune only, letting
+				// the terminal wrap long lines.
+				fmt.Fprint(e.out, string(r))
+			} else {
+				e.refresh(s)
+			}
+		}
+	}
+}
+
+// readEscape reads the rest of an escape sequence after ESC: an ESC [ or
+// ESC O followed by parameters and a final byte.
+func (e *Editor) readEscape() string {
+	first, _, err := e.r.ReadRune()
+	if err != nil || (first != '[' && first != 'O') {
+		return ""
+	}
+	seq := []rune{first}
+	for {
+		r, _, err := e.r.ReadRune()
+		if err != nil {
+			return ""
+		}
+		seq = append(seq, r)
+		if r >= 0x40 && r <= 0x7e {
+			return string(seq)
+		}
+	}
+}
+
+// browse shows the previous (up) or next history entry, returning the new
+// history index and the line typed before browsing.
+func (e *Editor) browse(s *lineState, up bool, hist int, typed []rune) (int, []rune) {
+	switch {
+	case up && hist > 0:
+		if hist == len(e.history) {
+			typed = append([]rune(nil), s.buf...)
+		}
+		hist--
+		s.buf = []rune(e.history[hist])
+	case !up && hist < len(e.history):
+		hist++
+		if hist == len(e.history) {
+			s.buf = typed
+		} else {
+			s.buf = []rune(e.history[hist])
+		}
+	default:
+		return hist, typed
+	}
+	s.pos = len(s.buf)
+	e.refresh(s)
+	return hist, typed
+}
+
+func (e *Editor) move(s *lineState, delta int) {
+	if pos := s.pos + delta; pos >= 0 && pos <= len(s.buf) {
+		s.pos = pos
+		e.refresh(s)
+	}
+}
+
+func (e *Editor) deleteAt(s *lineState, pos int) {
+	if pos < len(s.buf) {
+		s.buf = append(s.buf[:pos], s.buf[pos+1:]...)
+		e.refresh(s)
+	}
+}
+
+// complete replaces the word before the cursor with its only candidate, or
+// with the prefix common to its candidates, listing them if it has no
+// longer one.
+func (e *Editor) complete(s *lineState) {
+	if e.Complete == nil {
+		return
+	}
+	word, candidates := e.Complete(string(s.buf[:s.pos]))
+	if len(candidates) == 0 {
+		fmt.Fprint(e.out, "\a")
+		return
+	}
+	replacement := candidates[0]
+	if len(candidates) == 1 {
+		if !strings.HasSuffix(replacement, ".") {
+			replacement += " "
+		}
+	} else {
+		replacement = commonPrefix(candidates)
+		if len([]rune(replacement)) <= len([]rune(word)) {
+			fmt.Fprint(e.out, "\r\n"+strings.ReplaceAll(listCandidates(candidates), "\n", "\r\n"))
+			e.refresh(s)
+			return
+		}
+	}
+	start := s.pos - len([]rune(word))
+	rest := append([]rune(replacement), s.buf[s.pos:]...)
+	s.buf = append(s.buf[:start], rest...)
+	s.pos = start + len([]rune(replacement))
+	e.refresh(s)
+}
+
+// refresh redraws the prompt and the line, with the cursor at its position.
+func (e *Editor) refresh(s *lineState) {
+	var b strings.Builder
+	b.WriteString("\r")
+	b.WriteString(s.prompt)
+	b.WriteString(string(s.buf))
+	b.WriteString("\x1b[K")
+	if back := len(s.buf) - s.pos; back > 0 {
+		fmt.Fprintf(&b, "\x1b[%dD", back)
+	}
+	fmt.Fprint(e.out, b.String())
+}
+
+// commonPrefix returns the longest prefix of the candidates, compared
+// case-insensitively and spelled as the first one.
+func commonPrefix(candidates []string) string {
+	prefix := []rune(candidates[0])
+	for _, c := range candidates[1:] {
+		runes := []rune(c)
+		n := 0
+		for n < len(prefix) && n < len(runes) && unicode.ToLower(prefix[n]) == unicode.ToLower(runes[n]) {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return string(prefix)
+}
+
+// listCandidates lays the candidates out in columns 80 characters wide.
+func listCandidates(candidates []string) string {
+	shown := candidates
+	if len(shown) > maxListed {
+		shown = shown[:maxListed]
+	}
+	width := 0
+	for _, c := range shown {
+		width = max(width, len([]rune(c))+2)
+	}
+	perLine := max(80/width, 1)
+
+	var b strings.Builder
+	for i, c := range shown {
+		b.WriteString(c)
+		if (i+1)%perLine == 0 || i == len(shown)-1 {
+			b.WriteString("\n")
+		} else {
+			b.WriteString(strings.Repeat(" ", width-len([]rune(c))))
+		}
+	}
+	if more := len(candidates) - len(shown); more > 0 {
+		fmt.Fprintf(&b, "... %d more\n", more)
+	}
+	return b.String()
+}
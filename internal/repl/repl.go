@@ -0,0 +1,337 @@
+// Package repl implements the interactive prompt of metadata-cli repl:
+// SQL typed or pasted at the prompt is analyzed as soon as its statement is
+// terminated with a semicolon, and its lineage printed. Lines starting with
+// a dot are commands setting the dialect or output format of the session.
+package repl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"go-metadata/internal/lineage"
+)
+
+// Dialects are the SQL dialects of a session. Every dialect is analyzed by
+// the same parser; the dialect sets how table names are quoted in
+// completions and, for postgres, replaces positional parameters such as $1
+// with literals so that prepared statements parse.
+var Dialects = []string{"generic", "mysql", "postgres", "snowflake", "spark", "hive", "flink"}
+
+// Outputs are the output formats of a session.
+var Outputs = []string{lineage.FormatText, lineage.FormatJSON, lineage.FormatDOT, lineage.FormatMermaid}
+
+var commands = []string{".dialect", ".output", ".tables", ".clear", ".help", ".quit", ".exit"}
+
+const help = `Type SQL terminated with ; to print its lineage. Statements pasted together
+are analyzed as a script, so temporary tables resolve across them.
+
+  .dialect [name]   Show or set the dialect: %s
+  .output [format]  Show or set the output format: %s
+  .tables [prefix]  List the known tables starting with prefix
+  .clear            Discard the statement being typed
+  .help             Show this help
+  .quit             Exit (or Ctrl-D)
+
+Tab completes table names and commands.
+`
+
+// PrintFunc prints the lineage of the SQL analyzed in the output format.
+type PrintFunc func(result *lineage.LineageResult, output string) error
+
+// Session holds the state of a prompt: its dialect, output format, known
+// tables and the statement being typed.
+type Session struct {
+	analyzer *lineage.Analyzer
+	print    PrintFunc
+	out      io.Writer
+	// tables are the qualified names of the known tables, sorted.
+	tables  []string
+	dialect string
+	output  string
+	pending strings.Builder
+}
+
+// NewSession creates a session analyzing SQL with analyzer, printing its
+// lineage with print and errors and command output to out. tables are the qualified names of the tables
+// completed on Tab, such as dw.orders.
+func NewSession(analyzer *lineage.Analyzer, tables []string, out io.Writer, print PrintFunc) *Session {
+	tables = slices.Clone(tables)
+	sort.Strings(tables)
+	return &Session{
+		analyzer: analyzer,
+		print:    print,
+		out:      out,
+		tables:   slices.Compact(tables),
+		dialect:  Dialects[0],
+		output:   lineage.FormatText,
+	}
+}
+
+// SetDialect sets the dialect of the session, one of Dialects.
+func (s *Session) SetDialect(name string) error {
+	name = strings.ToLower(name)
+	if !slices.Contains(Dialects, name) {
+		return fmt.Errorf("unknown dialect %q, use %s", name, strings.Join(Dialects, ", "))
+	}
+	s.dialect = name
+	return nil
+}
+
+// SetOutput sets the output format of the session, one of Outputs.
+func (s *Session) SetOutput(format string) error {
+	format = strings.ToLower(format)
+	if !slices.Contains(Outputs, format) {
+		return fmt.Errorf("unknown output %q, use %s", format, strings.Join(Outputs, ", "))
+	}
+	s.output = format
+	return nil
+}
+
+// Prompt returns the prompt of the next line: the dialect of the session,
+// or a continuation prompt while a statement is being typed.
+func (s *Session) Prompt() string {
+	prompt := "lineage(" + s.dialect + ")> "
+	if s.pending.Len() > 0 {
+		return strings.Repeat(" ", len(prompt)-3) + "-> "
+	}
+	return prompt
+}
+
+// Eval evaluates a line read at the prompt: a command, or a line of SQL
+// that is analyzed once it terminates its statement. It returns true if the
+// line quits the session.
+func (s *Session) Eval(line string) bool {
+	if s.pending.Len() == 0 {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			return false
+		}
+		if strings.HasPrefix(trimmed, ".") {
+			return s.command(strings.Fields(trimmed))
+		}
+	}
+	s.pending.WriteString(line)
+	s.pending.WriteString("\n")
+	if !terminated(s.pending.String()) {
+		return false
+	}
+	sql := s.pending.String()
+	s.pending.Reset()
+	s.analyze(sql)
+	return false
+}
+
+// Interrupt discards the statement being typed.
+func (s *Session) Interrupt() {
+	s.pending.Reset()
+}
+
+func (s *Session) analyze(sql string) {
+	if s.dialect == "postgres" {
+		sql = lineage.ReplacePositionalParams(sql)
+	}
+	result, err := s.analyzer.AnalyzeScript(sql)
+	if err != nil {
+		fmt.Fprintf(s.out, "Error: %v\n", err)
+		return
+	}
+	if err := s.print(result, s.output); err != nil {
+		fmt.Fprintf(s.out, "Error writing lineage: %v\n", err)
+	}
+}
+
+func (s *Session) command(args []string) bool {
+	switch args[0] {
+	case ".quit", ".exit":
+		return true
+	case ".help":
+		fmt.Fprintf(s.out, help, strings.Join(Dialects, ", "), strings.Join(Outputs, ", "))
+	case ".clear":
+		s.pending.Reset()
+	case ".dialect":
+		if len(args) == 1 {
+			fmt.Fprintln(s.out, s.dialect)
+		} else if err := s.SetDialect(args[1]); err != nil {
+			fmt.Fprintf(s.out, "Error: %v\n", err)
+		}
+	case ".output":
+		if len(args) == 1 {
+			fmt.Fprintln(s.out, s.output)
+		} else if err := s.SetOutput(args[1]); err != nil {
+			fmt.Fprintf(s.out, "Error: %v\n", err)
+		}
+	case ".tables":
+		prefix := ""
+		if len(args) > 1 {
+			prefix = args[1]
+		}
+		tables := s.matchTables(prefix)
+		if len(tables) == 0 {
+			fmt.Fprintln(s.out, "No tables")
+		}
+		for _, t := range tables {
+			fmt.Fprintln(s.out, t)
+		}
+	default:
+		fmt.Fprintf(s.out, "Unknown command %s, type .help for the commands\n", args[0])
+	}
+	return false
+}
+
+// Complete completes the word before the cursor of line: a command or its
+// argument at the start of a line, or a table name in SQL. Table names
+// complete by their qualified name or by their name alone, and databases
+// by their name followed by a dot; names that are not plain identifiers
+// are quoted as in the dialect.
+func (s *Session) Complete(line string) (string, []string) {
+	start := strings.LastIndexFunc(line, func(r rune) bool { return !isWordRune(r) }) + 1
+	word := line[start:]
+
+	if s.pending.Len() == 0 && strings.HasPrefix(strings.TrimSpace(line), ".") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 1 && word != "":
+			return word, matchPrefix(commands, word)
+		case fields[0] == ".dialect":
+			return word, matchPrefix(Dialects, word)
+		case fields[0] == ".output":
+			return word, matchPrefix(Outputs, word)
+		case fields[0] != ".tables":
+			return word, nil
+		}
+	}
+
+	prefix := strings.ToLower(strings.NewReplacer("`", "", `"`, "").Replace(word))
+	var candidates []string
+	if strings.Contains(prefix, ".") {
+		for _, t := range s.matchTables(prefix) {
+			candidates = append(candidates, s.quote(t))
+		}
+	} else {
+		for _, t := range s.tables {
+			db, name, ok := strings.Cut(t, ".")
+			if !ok {
+				db, name = "", t
+			}
+			if strings.HasPrefix(strings.ToLower(name), prefix) {
+				candidates = append(candidates, s.quote(name))
+			}
+			if db != "" && strings.HasPrefix(strings.ToLower(db), prefix) {
+				candidates = append(candidates, s.quote(db)+".")
+			}
+		}
+	}
+	sort.Strings(candidates)
+	return word, slices.Compact(candidates)
+}
+
+// matchTables returns the known tables whose qualified name starts with
+// prefix, ignoring case.
+func (s *Session) matchTables(prefix string) []string {
+	return matchPrefix(s.tables, prefix)
+}
+
+// quote quotes the parts of a qualified name that are not plain
+// identifiers, with backticks in the MySQL family of dialects and double
+// quotes otherwise.
+func (s *Session) quote(name string) string {
+	q := `"`
+	switch s.dialect {
+	case "mysql", "spark", "hive", "flink":
+		q = "`"
+	}
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		if !isIdentifier(p) {
+			parts[i] = q + p + q
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// Run reads lines with e and evaluates them in s until the user quits or
+// the input ends. Ctrl-C discards the statement being typed.
+func Run(s *Session, e *Editor) error {
+	e.Complete = s.Complete
+	for {
+		line, err := e.ReadLine(s.Prompt())
+		if errors.Is(err, ErrInterrupt) {
+			s.Interrupt()
+			continue
+		}
+		if err == io.EOF {
+			// Analyze a last statement left without its semicolon.
+			if strings.TrimSpace(s.pending.String()) != "" {
+				s.analyze(s.pending.String())
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if s.Eval(line) {
+			return nil
+		}
+	}
+}
+
+// terminated reports whether the last token of sql, ignoring whitespace
+// and comments, is a semicolon outside quotes.
+func terminated(sql string) bool {
+	last := rune(0)
+	var quote rune
+	runes := []rune(sql)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			last = r
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			for i += 2; i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/'); i++ {
+			}
+			i++
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n':
+		default:
+			last = r
+		}
+	}
+	return quote == 0 && last == ';'
+}
+
+func matchPrefix(values []string, prefix string) []string {
+	prefix = strings.ToLower(prefix)
+	var matches []string
+	for _, v := range values {
+		if strings.HasPrefix(strings.ToLower(v), prefix) {
+			matches = append(matches, v)
+		}
+	}
+	return matches
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '.' || r == '$' || r == '`' || r == '"' ||
+		r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r > 127
+}
+
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && (r >= '0' && r <= '9' || r == '$')) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,122 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-metadata/internal/lineage"
+)
+
+func newTestSession(out io.Writer) *Session {
+	print := func(result *lineage.LineageResult, output string) error {
+		for _, col := range result.Columns {
+			fmt.Fprintf(out, "%s %s <-", output, col.Target.QualifiedName())
+			for _, src := range col.Sources {
+				fmt.Fprintf(out, " %s", src.QualifiedName())
+			}
+			fmt.Fprintln(out)
+		}
+		return nil
+	}
+	tables := []string{"dw.orders", "dw.order_items", "ods.orders", "ods.user events", "dw.orders"}
+	return NewSession(lineage.NewAnalyzer(nil), tables, out, print)
+}
+
+func TestSessionEval(t *testing.T) {
+	var out strings.Builder
+	s := newTestSession(&out)
+
+	s.Eval("INSERT INTO daily SELECT id,")
+	if out.Len() != 0 || !strings.HasSuffix(s.Prompt(), "-> ") {
+		t.Fatalf("statement analyzed before its semicolon: %q, prompt %q", out.String(), s.Prompt())
+	}
+	s.Eval("  'a;b' AS note -- ends here;")
+	s.Eval("FROM orders;")
+	if got := out.String(); !strings.Contains(got, "text daily.id <- orders.id") {
+		t.Errorf("output = %q", got)
+	}
+	if s.Prompt() != "lineage(generic)> " {
+		t.Errorf("prompt = %q", s.Prompt())
+	}
+
+	out.Reset()
+	s.Eval(".output json")
+	s.Eval(".dialect postgres")
+	s.Eval("INSERT INTO daily SELECT id FROM orders WHERE id = $1;")
+	if got := out.String(); got != "json daily.id <- orders.id\n" {
+		t.Errorf("output = %q", got)
+	}
+
+	out.Reset()
+	s.Eval(".dialect oracle")
+	s.Eval("SELEC 1;")
+	if got := out.String(); !strings.Contains(got, `unknown dialect "oracle"`) || !strings.Contains(got, "Error:") {
+		t.Errorf("output = %q", got)
+	}
+
+	s.Eval("INSERT INTO t SELECT")
+	s.Interrupt()
+	if s.Prompt() != "lineage(postgres)> " {
+		t.Errorf("prompt after interrupt = %q", s.Prompt())
+	}
+	if !s.Eval(".quit") {
+		t.Error(".quit did not quit")
+	}
+}
+
+func TestSessionComplete(t *testing.T) {
+	s := newTestSession(io.Discard)
+	tests := []struct {
+		line, word string
+		want       []string
+	}{
+		{"SELECT * FROM dw.ord", "dw.ord", []string{"dw.order_items", "dw.orders"}},
+		{"SELECT * FROM ORDER_", "ORDER_", []string{"order_items"}},
+		{"SELECT * FROM od", "od", []string{"ods."}},
+		{"SELECT * FROM ods.u", "ods.u", []string{`ods."user events"`}},
+		{".di", ".di", []string{".dialect"}},
+		{".dialect s", "s", []string{"snowflake", "spark"}},
+		{".tables dw.order_", "dw.order_", []string{"dw.order_items"}},
+	}
+	for _, tt := range tests {
+		word, got := s.Complete(tt.line)
+		if word != tt.word || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%q) = %q, %v, want %q, %v", tt.line, word, got, tt.word, tt.want)
+		}
+	}
+
+	s.SetDialect("mysql")
+	if _, got := s.Complete("SELECT * FROM ods.user"); !reflect.DeepEqual(got, []string{"ods.`user events`"}) {
+		t.Errorf("mysql completion = %v", got)
+	}
+}
+
+func TestTerminated(t *testing.T) {
+	tests := map[string]bool{
+		"SELECT 1;":                 true,
+		"SELECT 1;  -- done\n":      true,
+		"SELECT 1; /* done */":      true,
+		"SELECT ';":                 false,
+		"SELECT 1 -- not yet;\n":    false,
+		"SELECT 1 /* ; */":          false,
+		"SELECT \"a;\"\n, 2\n":      false,
+		"CREATE TABLE t (`a;` INT)": false,
+	}
+	for sql, want := range tests {
+		if got := terminated(sql); got != want {
+			t.Errorf("terminated(%q) = %v, want %v", sql, got, want)
+		}
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	if got := commonPrefix([]string{"dw.orders", "DW.order_items"}); got != "dw.order" {
+		t.Errorf("commonPrefix = %q", got)
+	}
+	if got := listCandidates([]string{"a", "b"}); got != "a  b\n" {
+		t.Errorf("listCandidates = %q", got)
+	}
+}
@@ -0,0 +1,70 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+)
+
+const (
+	boxWidth  = 200
+	boxHeight = 28
+	boxGap    = 12
+	colGap    = 80
+	padding   = 10
+)
+
+// diagram renders the direct upstream and downstream tables of a page as an
+// inline SVG: upstream tables on the left, the table itself in the middle and
+// downstream tables on the right, each box linking to its table page.
+func diagram(p *tablePage) template.HTML {
+	if len(p.Upstream) == 0 && len(p.Downstream) == 0 {
+		return ""
+	}
+
+	rows := max(len(p.Upstream), len(p.Downstream), 1)
+	height := rows*(boxHeight+boxGap) - boxGap + 2*padding
+	width := 3*boxWidth + 2*colGap + 2*padding
+
+	columnY := func(n, i int) int {
+		offset := (height - 2*padding - (n*(boxHeight+boxGap) - boxGap)) / 2
+		return padding + offset + i*(boxHeight+boxGap)
+	}
+	centerX, centerY := padding+boxWidth+colGap, columnY(1, 0)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="diagram" width="%d" height="%d" viewBox="0 0 %d %d" xmlns="http://www.w3.org/2000/svg">`,
+		width, height, width, height)
+	for i, name := range p.Upstream {
+		x, y := padding, columnY(len(p.Upstream), i)
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d"/>`,
+			x+boxWidth, y+boxHeight/2, centerX, centerY+boxHeight/2)
+		writeBox(&b, x, y, name, pageFile(name), "")
+	}
+	for i, name := range p.Downstream {
+		x, y := centerX+boxWidth+colGap, columnY(len(p.Downstream), i)
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d"/>`,
+			centerX+boxWidth, centerY+boxHeight/2, x, y+boxHeight/2)
+		writeBox(&b, x, y, name, pageFile(name), "")
+	}
+	writeBox(&b, centerX, centerY, p.Name, "", "current")
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+func writeBox(b *strings.Builder, x, y int, name, href, class string) {
+	label := name
+	if len(label) > 28 {
+		label = label[:25] + "..."
+	}
+	if href != "" {
+		fmt.Fprintf(b, `<a href="%s">`, html.EscapeString(href))
+	}
+	fmt.Fprintf(b, `<g class="node %s"><title>%s</title><rect x="%d" y="%d" width="%d" height="%d" rx="4"/>`,
+		class, html.EscapeString(name), x, y, boxWidth, boxHeight)
+	fmt.Fprintf(b, `<text x="%d" y="%d">%s</text></g>`, x+boxWidth/2, y+boxHeight/2+4, html.EscapeString(label))
+	if href != "" {
+		b.WriteString(`</a>`)
+	}
+}
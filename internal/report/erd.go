@@ -0,0 +1,97 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	lineageCore "go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/metadata"
+)
+
+// WriteERD writes the tables and their relationships as a Mermaid entity
+// relationship diagram. Foreign keys are drawn as solid lines and suggested
+// relationships as dotted lines labeled with the number of joins seen.
+// Tables that only appear in relationships are drawn without columns.
+func WriteERD(w io.Writer, tables []*metadata.TableSchema, relationships []*lineageCore.Relationship) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "erDiagram")
+
+	for _, t := range tables {
+		name := t.Table
+		if t.Database != "" {
+			name = t.Database + "." + name
+		}
+		foreignKeys := make(map[string]bool)
+		for _, fk := range t.ForeignKeys {
+			for _, col := range fk.Columns {
+				foreignKeys[strings.ToLower(col)] = true
+			}
+		}
+
+		fmt.Fprintf(bw, "    %s {\n", erdName(name))
+		for _, col := range t.Columns {
+			var keys []string
+			if col.PrimaryKey || containsFold(t.PrimaryKey, col.Name) {
+				keys = append(keys, "PK")
+			}
+			if foreignKeys[strings.ToLower(col.Name)] {
+				keys = append(keys, "FK")
+			}
+			attr := strings.TrimSpace(erdType(col.DataType) + " " + erdName(col.Name) + " " + strings.Join(keys, ","))
+			fmt.Fprintf(bw, "        %s\n", attr)
+		}
+		fmt.Fprintln(bw, "    }")
+	}
+
+	for _, r := range relationships {
+		keys := make([]string, 0, len(r.Keys))
+		for _, k := range r.Keys {
+			keys = append(keys, k.LeftColumn+" = "+k.RightColumn)
+		}
+		label := strings.Join(keys, ", ")
+		line := "--"
+		if r.Suggested() {
+			line = ".."
+			label = fmt.Sprintf("%s (%d joins)", label, r.Occurrences)
+		}
+		fmt.Fprintf(bw, "    %s }o%so{ %s : %q\n", erdName(r.Left), line, erdName(r.Right), label)
+	}
+	return bw.Flush()
+}
+
+// erdName replaces the characters Mermaid does not accept in entity and
+// attribute names.
+func erdName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// erdType returns the base name of a data type, e.g. DECIMAL for
+// DECIMAL(10,2).
+func erdType(dataType string) string {
+	base, _, _ := strings.Cut(dataType, "(")
+	base, _, _ = strings.Cut(base, "<")
+	if base = erdName(strings.TrimSpace(base)); base == "" {
+		return "unknown"
+	}
+	return base
+}
+
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package report
+
+import (
+	"bytes"
+	"html"
+	"html/template"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// markdown renders READMEs as GitHub flavored markdown. Raw HTML and
+// dangerous link URLs are omitted by the default renderer, so the output is
+// safe to embed in pages.
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// renderMarkdown renders a README to HTML. The source is returned escaped if
+// it cannot be rendered.
+func renderMarkdown(source string) template.HTML {
+	if source == "" {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(source), &buf); err != nil {
+		return template.HTML("<pre>" + html.EscapeString(source) + "</pre>")
+	}
+	return template.HTML(buf.String())
+}
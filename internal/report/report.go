@@ -0,0 +1,280 @@
+// Package report renders the metadata catalog and lineage graph as a
+// self-contained static HTML site.
+package report
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	lineageCore "go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/metadata"
+	"go-metadata/internal/store"
+	"go-metadata/internal/urn"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"join":        strings.Join,
+	"annotations": annotationList,
+}).ParseFS(templateFS, "templates/*.html"))
+
+// Options configures report generation.
+type Options struct {
+	// Title is shown in the page header.
+	Title string
+	// Tables are the catalog tables to document.
+	Tables []*metadata.TableSchema
+	// Graph is the lineage graph used for the lineage sections and diagrams.
+	// Tables that only appear in the graph get a page as well.
+	Graph *lineageCore.Graph
+	// Relationships are the foreign keys and suggested relationships shown on
+	// the table pages and in the ER diagram erd.mmd.
+	Relationships []*lineageCore.Relationship
+	// Docs are the markdown READMEs of tables by table name, e.g. dw.orders,
+	// rendered in a Documentation section of their pages.
+	Docs map[string]string
+	// URNs, if set, gives each table page the identifier of the table in
+	// its URN scheme, e.g. its DataHub URN, which is also searchable.
+	URNs *urn.Namer
+	// ChangeRates, if set, are shown in a Schema Stability section of the
+	// index: how often the schemas of each source changed since
+	// ChangesSince.
+	ChangeRates  []*store.ChangeRate
+	ChangesSince time.Time
+}
+
+// Stats summarizes a generated site.
+type Stats struct {
+	Tables int
+	Edges  int
+}
+
+// Generate renders the site into outDir: index.html with a searchable table
+// list and one page per table under tables/.
+func Generate(outDir string, opts Options) (*Stats, error) {
+	if opts.Title == "" {
+		opts.Title = "Metadata Catalog"
+	}
+	if opts.Graph == nil {
+		opts.Graph = lineageCore.NewGraph()
+	}
+
+	pages := buildPages(opts)
+	if err := os.MkdirAll(filepath.Join(outDir, "tables"), 0o755); err != nil {
+		return nil, fmt.Errorf("create output directory: %w", err)
+	}
+
+	generatedAt := time.Now().Format("2006-01-02 15:04:05")
+	index := struct {
+		Title        string
+		GeneratedAt  string
+		Tables       []*tablePage
+		EdgeCount    int
+		HasERD       bool
+		ChangeRates  []*store.ChangeRate
+		ChangesSince string
+	}{opts.Title, generatedAt, pages, opts.Graph.Len(), len(opts.Relationships) > 0,
+		opts.ChangeRates, opts.ChangesSince.Format("2006-01-02")}
+	if err := render(filepath.Join(outDir, "index.html"), "index.html", index); err != nil {
+		return nil, err
+	}
+
+	if len(opts.Relationships) > 0 {
+		f, err := os.Create(filepath.Join(outDir, "erd.mmd"))
+		if err != nil {
+			return nil, fmt.Errorf("create erd.mmd: %w", err)
+		}
+		err = WriteERD(f, opts.Tables, opts.Relationships)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("write erd.mmd: %w", err)
+		}
+	}
+
+	for _, page := range pages {
+		data := struct {
+			Title       string
+			GeneratedAt string
+			Page        *tablePage
+		}{opts.Title, generatedAt, page}
+		if err := render(filepath.Join(outDir, "tables", page.File), "table.html", data); err != nil {
+			return nil, err
+		}
+	}
+	return &Stats{Tables: len(pages), Edges: opts.Graph.Len()}, nil
+}
+
+type tablePage struct {
+	Name        string
+	File        string
+	URN         string
+	Type        string
+	Comment     string
+	Readme      template.HTML
+	Annotations map[string]string
+	Columns     []metadata.ColumnSchema
+	Upstream    []string
+	Downstream  []string
+	Edges       []edgeRow
+	Related     []relationshipRow
+	Diagram     template.HTML
+	Search      string
+}
+
+type edgeRow struct {
+	Source    string
+	Target    string
+	Operators []string
+	Jobs      []string
+}
+
+type relationshipRow struct {
+	Table       string
+	File        string
+	Keys        []string
+	Occurrences int64
+	ForeignKey  bool
+}
+
+func buildPages(opts Options) []*tablePage {
+	pages := make(map[string]*tablePage)
+	page := func(name string) *tablePage {
+		if p, ok := pages[name]; ok {
+			return p
+		}
+		p := &tablePage{Name: name, File: pageFile(name)}
+		pages[name] = p
+		return p
+	}
+
+	for _, schema := range opts.Tables {
+		name := schema.Table
+		if schema.Database != "" {
+			name = schema.Database + "." + name
+		}
+		p := page(name)
+		p.Type = schema.TableType
+		p.Comment = schema.Comment
+		p.Annotations = schema.Annotations
+		p.Columns = schema.Columns
+	}
+
+	for name, doc := range opts.Docs {
+		page(name).Readme = renderMarkdown(doc)
+	}
+
+	edges := opts.Graph.Edges()
+	for _, edge := range edges {
+		if !edge.Current() {
+			continue
+		}
+		source, target := page(edge.Source.TableName()), page(edge.Target.TableName())
+		row := edgeRow{
+			Source:    edge.Source.QualifiedName(),
+			Target:    edge.Target.QualifiedName(),
+			Operators: edge.Operators,
+			Jobs:      edge.Provenance.Jobs,
+		}
+		target.Edges = append(target.Edges, row)
+		if source != target {
+			source.Edges = append(source.Edges, row)
+			target.Upstream = appendUnique(target.Upstream, source.Name)
+			source.Downstream = appendUnique(source.Downstream, target.Name)
+		}
+	}
+
+	for _, r := range opts.Relationships {
+		left, right := page(r.Left), page(r.Right)
+		var leftKeys, rightKeys []string
+		for _, k := range r.Keys {
+			leftKeys = append(leftKeys, k.LeftColumn+" = "+r.Right+"."+k.RightColumn)
+			rightKeys = append(rightKeys, k.RightColumn+" = "+r.Left+"."+k.LeftColumn)
+		}
+		left.Related = append(left.Related, relationshipRow{r.Right, right.File, leftKeys, r.Occurrences, r.ForeignKey})
+		right.Related = append(right.Related, relationshipRow{r.Left, left.File, rightKeys, r.Occurrences, r.ForeignKey})
+	}
+
+	result := make([]*tablePage, 0, len(pages))
+	for _, p := range pages {
+		sort.Strings(p.Upstream)
+		sort.Strings(p.Downstream)
+		search := []string{p.Name, p.Comment}
+		if opts.URNs != nil {
+			p.URN = opts.URNs.DatasetURN("", p.Name)
+			search = append(search, p.URN)
+		}
+		if doc, ok := opts.Docs[p.Name]; ok {
+			search = append(search, strings.Fields(doc)...)
+		}
+		search = append(search, annotationList(p.Annotations)...)
+		for _, col := range p.Columns {
+			search = append(search, col.Name)
+			search = append(search, annotationList(col.Annotations)...)
+		}
+		p.Search = strings.ToLower(strings.Join(search, " "))
+		p.Diagram = diagram(p)
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// annotationList returns annotations as sorted key=value strings, the form
+// they are displayed and searched in.
+func annotationList(annotations map[string]string) []string {
+	list := make([]string, 0, len(annotations))
+	for k, v := range annotations {
+		list = append(list, k+"="+v)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func render(path, name string, data interface{}) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if err := templates.ExecuteTemplate(f, name, data); err != nil {
+		return fmt.Errorf("render %s: %w", path, err)
+	}
+	return nil
+}
+
+// pageFile returns the file name of a table page, replacing characters that
+// are unsafe in file names.
+func pageFile(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String() + ".html"
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
@@ -0,0 +1,239 @@
+package report
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	lineageCore "go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/metadata"
+	"go-metadata/internal/store"
+	"go-metadata/internal/urn"
+)
+
+func TestGenerate(t *testing.T) {
+	sql := "INSERT INTO report(total) SELECT SUM(amount) FROM orders"
+	result, err := lineageCore.NewAnalyzer(nil).Analyze(sql)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	graph := lineageCore.NewGraph()
+	graph.Add(result, lineageCore.Fingerprint(sql), time.Now())
+
+	tables := []*metadata.TableSchema{{
+		Table:       "orders",
+		Comment:     "raw <orders>",
+		Annotations: map[string]string{"finance.coa": "4010"},
+		Columns:     []metadata.ColumnSchema{{Name: "amount", DataType: "DECIMAL", Annotations: map[string]string{"dq.check": "enabled"}}},
+	}}
+
+	out := t.TempDir()
+	stats, err := Generate(out, Options{Title: "Test Catalog", Tables: tables, Graph: graph})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if stats.Tables != 2 || stats.Edges != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	index := readFile(t, filepath.Join(out, "index.html"))
+	for _, want := range []string{`href="tables/orders.html"`, `href="tables/report.html"`, "raw &lt;orders&gt;", `data-search="orders raw &lt;orders&gt; finance.coa=4010 amount dq.check=enabled"`} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index.html does not contain %q", want)
+		}
+	}
+
+	page := readFile(t, filepath.Join(out, "tables", "orders.html"))
+	for _, want := range []string{"<svg", `href="report.html"`, "orders.amount", "DECIMAL", "finance.coa=4010", "dq.check=enabled"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("orders.html does not contain %q", want)
+		}
+	}
+}
+
+func TestDocs(t *testing.T) {
+	tables := []*metadata.TableSchema{{Database: "dw", Table: "orders", Comment: "Order facts"}}
+	docs := map[string]string{
+		"dw.orders": "# Orders\n\nOne row per **order line**, loaded T+1.\n\n<script>alert(1)</script>",
+	}
+
+	out := t.TempDir()
+	if _, err := Generate(out, Options{Tables: tables, Docs: docs}); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	page := readFile(t, filepath.Join(out, "tables", "dw.orders.html"))
+	for _, want := range []string{"<h2>Documentation</h2>", "<h1>Orders</h1>", "<strong>order line</strong>"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("dw.orders.html does not contain %q", want)
+		}
+	}
+	if strings.Contains(page, "<script>alert") {
+		t.Error("Expected raw HTML in the README to be omitted")
+	}
+	if index := readFile(t, filepath.Join(out, "index.html")); !strings.Contains(index, "**order line**, loaded") {
+		t.Error("Expected the README to be searchable")
+	}
+}
+
+func TestURNs(t *testing.T) {
+	namer, err := urn.New(&urn.Config{Scheme: urn.SchemeDataHub, Platform: "hive", Env: "dev"})
+	if err != nil {
+		t.Fatalf("urn.New failed: %v", err)
+	}
+	tables := []*metadata.TableSchema{{Database: "dw", Table: "orders"}}
+	out := t.TempDir()
+	if _, err := Generate(out, Options{Tables: tables, URNs: namer}); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	want := "urn:li:dataset:(urn:li:dataPlatform:hive,dw.orders,DEV)"
+	if page := readFile(t, filepath.Join(out, "tables", "dw.orders.html")); !strings.Contains(page, want) {
+		t.Errorf("dw.orders.html does not contain %q", want)
+	}
+	if index := readFile(t, filepath.Join(out, "index.html")); !strings.Contains(index, strings.ToLower(want)) {
+		t.Errorf("index.html does not make %q searchable", want)
+	}
+}
+
+func TestChangeRates(t *testing.T) {
+	since := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	rates := []*store.ChangeRate{{
+		Source: "mysql_prod", Events: 9, Tables: 4, ColumnsAdded: 6, ColumnsDropped: 2, ColumnsModified: 3,
+		EventsPerWeek: 0.75, LastChange: since.AddDate(0, 2, 3),
+	}}
+	out := t.TempDir()
+	if _, err := Generate(out, Options{Tables: []*metadata.TableSchema{{Table: "orders"}}, ChangeRates: rates, ChangesSince: since}); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	index := readFile(t, filepath.Join(out, "index.html"))
+	for _, want := range []string{"Schema Stability", "since 2026-07-01", "<td>mysql_prod</td>", "<td>0.8</td>", "<td>2026-09-04</td>"} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index.html does not contain %q", want)
+		}
+	}
+
+	if _, err := Generate(out, Options{Tables: []*metadata.TableSchema{{Table: "orders"}}}); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if index := readFile(t, filepath.Join(out, "index.html")); strings.Contains(index, "Schema Stability") {
+		t.Error("index.html has a Schema Stability section without change rates")
+	}
+}
+
+func TestRelationshipsAndERD(t *testing.T) {
+	stats := lineageCore.NewRelationshipStats()
+	stats.AddForeignKey(lineageCore.JoinKey{
+		Left:  lineageCore.ColumnRef{Table: "orders", Column: "user_id"},
+		Right: lineageCore.ColumnRef{Table: "users", Column: "id"},
+	})
+	stats.Add(&lineageCore.LineageResult{JoinKeys: []lineageCore.JoinKey{{
+		Left:  lineageCore.ColumnRef{Database: "dw", Table: "orders", Column: "region"},
+		Right: lineageCore.ColumnRef{Database: "dw", Table: "regions", Column: "code"},
+	}}}, time.Now())
+
+	tables := []*metadata.TableSchema{{
+		Table:       "orders",
+		Columns:     []metadata.ColumnSchema{{Name: "id", DataType: "INT", PrimaryKey: true}, {Name: "user_id", DataType: "INT"}, {Name: "amount", DataType: "DECIMAL(10,2)"}},
+		ForeignKeys: []metadata.ForeignKey{{Columns: []string{"user_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}}},
+	}}
+
+	out := t.TempDir()
+	if _, err := Generate(out, Options{Tables: tables, Relationships: stats.Relationships("", 1)}); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	page := readFile(t, filepath.Join(out, "tables", "orders.html"))
+	for _, want := range []string{"Relationships", `href="users.html"`, "user_id = users.id", "foreign key"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("orders.html does not contain %q", want)
+		}
+	}
+	if page := readFile(t, filepath.Join(out, "tables", "dw.regions.html")); !strings.Contains(page, "suggested") {
+		t.Errorf("dw.regions.html does not show the suggested relationship")
+	}
+
+	erd := readFile(t, filepath.Join(out, "erd.mmd"))
+	for _, want := range []string{
+		"erDiagram",
+		"INT id PK",
+		"INT user_id FK",
+		"DECIMAL amount",
+		`orders }o--o{ users : "user_id = id"`,
+		`dw_orders }o..o{ dw_regions : "region = code (1 joins)"`,
+	} {
+		if !strings.Contains(erd, want) {
+			t.Errorf("erd.mmd does not contain %q:\n%s", want, erd)
+		}
+	}
+}
+
+func TestPageFile(t *testing.T) {
+	if got := pageFile("db.my table/x"); got != "db.my_table_x.html" {
+		t.Errorf("Unexpected page file %q", got)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestViewerHandler(t *testing.T) {
+	analyzer := lineageCore.NewAnalyzer(nil)
+	graph := lineageCore.NewGraph()
+	for _, sql := range []string{
+		"INSERT INTO stg(a) SELECT a FROM raw",
+		"INSERT INTO mart(b) SELECT a FROM stg",
+	} {
+		result, err := analyzer.Analyze(sql)
+		if err != nil {
+			t.Fatalf("Analyze failed: %v", err)
+		}
+		graph.Add(result, lineageCore.Fingerprint(sql), time.Now())
+	}
+	handler := NewViewerHandler(graph)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/lineage?node=stg&depth=0", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var got viewerGraph
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	want := []viewerNode{{ID: "raw", Level: -1}, {ID: "stg", Level: 0}, {ID: "mart", Level: 1}}
+	if len(got.Nodes) != len(want) {
+		t.Fatalf("Expected nodes %v, got %v", want, got.Nodes)
+	}
+	for i := range want {
+		if got.Nodes[i] != want[i] {
+			t.Errorf("Expected node %v, got %v", want[i], got.Nodes[i])
+		}
+	}
+	if len(got.Edges) != 2 {
+		t.Errorf("Expected 2 edges, got %v", got.Edges)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/lineage?node=unknown", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown table, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/viewer.js", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "api/lineage") {
+		t.Errorf("Expected embedded viewer script, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,129 @@
+package report
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strconv"
+
+	lineageCore "go-metadata/internal/lineage"
+)
+
+//go:embed viewer
+var viewerFS embed.FS
+
+// viewerGraph is the table-level lineage around a focused table, as served to
+// the viewer.
+type viewerGraph struct {
+	Nodes []viewerNode `json:"nodes"`
+	Edges []viewerEdge `json:"edges"`
+}
+
+type viewerNode struct {
+	ID string `json:"id"`
+	// Level is the distance from the focused table: negative upstream,
+	// positive downstream.
+	Level int `json:"level"`
+}
+
+type viewerEdge struct {
+	Source  string   `json:"source"`
+	Target  string   `json:"target"`
+	Columns []string `json:"columns"`
+}
+
+// NewViewerHandler returns an HTTP handler serving the interactive lineage
+// viewer for g: the embedded page at / and the table-level lineage of a table
+// at /api/lineage?node=db.table&depth=N (depth 0 means unlimited).
+func NewViewerHandler(g *lineageCore.Graph) http.Handler {
+	assets, err := fs.Sub(viewerFS, "viewer")
+	if err != nil {
+		panic(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(assets)))
+	mux.HandleFunc("/api/lineage", func(w http.ResponseWriter, r *http.Request) {
+		node := r.URL.Query().Get("node")
+		depth, _ := strconv.Atoi(r.URL.Query().Get("depth"))
+
+		result := tableLineage(g, node, depth)
+		if result == nil {
+			http.Error(w, "no lineage recorded for "+node, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	})
+	return mux
+}
+
+// tableLineage returns the upstream and downstream tables of table within
+// depth hops, or nil if the table has no lineage.
+func tableLineage(g *lineageCore.Graph, table string, depth int) *viewerGraph {
+	edges := append(g.Traverse(table, lineageCore.Upstream, depth), g.Traverse(table, lineageCore.Downstream, depth)...)
+	if len(edges) == 0 {
+		return nil
+	}
+
+	type pair struct{ source, target string }
+	columns := make(map[pair][]string)
+	var pairs []pair
+	for _, e := range edges {
+		p := pair{e.Source.TableName(), e.Target.TableName()}
+		if p.source == p.target {
+			continue
+		}
+		if _, ok := columns[p]; !ok {
+			pairs = append(pairs, p)
+		}
+		columns[p] = appendUnique(columns[p], e.Source.QualifiedName()+" -> "+e.Target.QualifiedName())
+	}
+
+	// Assign levels breadth-first from the focused table.
+	levels := map[string]int{table: 0}
+	for _, step := range []int{-1, 1} {
+		frontier := []string{table}
+		for level := step; len(frontier) > 0; level += step {
+			var next []string
+			for _, p := range pairs {
+				from, to := p.target, p.source
+				if step > 0 {
+					from, to = p.source, p.target
+				}
+				if _, seen := levels[to]; seen || !contains(frontier, from) {
+					continue
+				}
+				levels[to] = level
+				next = append(next, to)
+			}
+			frontier = next
+		}
+	}
+
+	result := &viewerGraph{}
+	for id, level := range levels {
+		result.Nodes = append(result.Nodes, viewerNode{ID: id, Level: level})
+	}
+	sort.Slice(result.Nodes, func(i, j int) bool {
+		if result.Nodes[i].Level != result.Nodes[j].Level {
+			return result.Nodes[i].Level < result.Nodes[j].Level
+		}
+		return result.Nodes[i].ID < result.Nodes[j].ID
+	})
+	for _, p := range pairs {
+		result.Edges = append(result.Edges, viewerEdge{Source: p.source, Target: p.target, Columns: columns[p]})
+	}
+	return result
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+// Lineage viewer: fetches the lineage of a table from /api/lineage and draws
+// it as a layered graph, upstream tables to the left and downstream tables to
+// the right of the focused table. Click a table to refocus; drag to pan and
+// scroll to zoom.
+(function () {
+  "use strict";
+
+  var SVG = "http://www.w3.org/2000/svg";
+  var BOX_W = 200, BOX_H = 30, COL_GAP = 90, ROW_GAP = 16;
+
+  var svg = document.getElementById("graph");
+  var viewport = document.getElementById("viewport");
+  var status = document.getElementById("status");
+  var input = document.getElementById("node");
+  var depth = document.getElementById("depth");
+  var view = { x: 40, y: 40, k: 1 };
+
+  function el(name, attrs, parent) {
+    var e = document.createElementNS(SVG, name);
+    Object.keys(attrs).forEach(function (k) { e.setAttribute(k, attrs[k]); });
+    if (parent) parent.appendChild(e);
+    return e;
+  }
+
+  function applyView() {
+    viewport.setAttribute("transform", "translate(" + view.x + "," + view.y + ") scale(" + view.k + ")");
+  }
+
+  function load(node) {
+    input.value = node;
+    history.replaceState(null, "", "?node=" + encodeURIComponent(node));
+    status.textContent = "Loading...";
+    fetch("api/lineage?node=" + encodeURIComponent(node) + "&depth=" + depth.value)
+      .then(function (r) {
+        if (!r.ok) return r.text().then(function (t) { throw new Error(t); });
+        return r.json();
+      })
+      .then(function (data) {
+        render(data);
+        status.textContent = data.nodes.length + " tables, " + data.edges.length + " edges";
+      })
+      .catch(function (err) { status.textContent = err.message; });
+  }
+
+  function render(data) {
+    while (viewport.firstChild) viewport.removeChild(viewport.firstChild);
+
+    // Group nodes into columns by their level relative to the focused table.
+    var columns = {};
+    data.nodes.forEach(function (n) { (columns[n.level] = columns[n.level] || []).push(n); });
+    var levels = Object.keys(columns).map(Number).sort(function (a, b) { return a - b; });
+    var tallest = Math.max.apply(null, levels.map(function (l) { return columns[l].length; }));
+    var pos = {};
+    levels.forEach(function (level, i) {
+      var col = columns[level];
+      var offset = (tallest - col.length) * (BOX_H + ROW_GAP) / 2;
+      col.forEach(function (n, j) {
+        pos[n.id] = { x: i * (BOX_W + COL_GAP), y: offset + j * (BOX_H + ROW_GAP) };
+      });
+    });
+
+    data.edges.forEach(function (e) {
+      var s = pos[e.source], t = pos[e.target];
+      if (!s || !t) return;
+      var x1 = s.x + BOX_W, y1 = s.y + BOX_H / 2, x2 = t.x, y2 = t.y + BOX_H / 2;
+      var mid = (x1 + x2) / 2;
+      var path = el("path", { "class": "edge", d: "M" + x1 + "," + y1 + " C" + mid + "," + y1 + " " + mid + "," + y2 + " " + x2 + "," + y2 }, viewport);
+      el("title", {}, path).textContent = e.columns.join("\n");
+    });
+
+    data.nodes.forEach(function (n) {
+      var p = pos[n.id];
+      var cls = n.level === 0 ? "focus" : n.level < 0 ? "upstream" : "downstream";
+      var g = el("g", { "class": "node " + cls, transform: "translate(" + p.x + "," + p.y + ")" }, viewport);
+      el("rect", { width: BOX_W, height: BOX_H }, g);
+      var label = n.id.length > 28 ? n.id.slice(0, 25) + "..." : n.id;
+      el("text", { x: BOX_W / 2, y: BOX_H / 2 }, g).textContent = label;
+      el("title", {}, g).textContent = n.id;
+      g.addEventListener("click", function () { load(n.id); });
+    });
+
+    view = { x: 40, y: 40, k: 1 };
+    applyView();
+  }
+
+  var drag = null;
+  svg.addEventListener("mousedown", function (e) {
+    drag = { x: e.clientX - view.x, y: e.clientY - view.y };
+    svg.classList.add("dragging");
+  });
+  window.addEventListener("mousemove", function (e) {
+    if (!drag) return;
+    view.x = e.clientX - drag.x;
+    view.y = e.clientY - drag.y;
+    applyView();
+  });
+  window.addEventListener("mouseup", function () {
+    drag = null;
+    svg.classList.remove("dragging");
+  });
+  svg.addEventListener("wheel", function (e) {
+    e.preventDefault();
+    var k = Math.min(4, Math.max(0.2, view.k * (e.deltaY < 0 ? 1.1 : 0.9)));
+    view.x = e.offsetX - (e.offsetX - view.x) * k / view.k;
+    view.y = e.offsetY - (e.offsetY - view.y) * k / view.k;
+    view.k = k;
+    applyView();
+  }, { passive: false });
+
+  document.getElementById("search").addEventListener("submit", function (e) {
+    e.preventDefault();
+    if (input.value) load(input.value);
+  });
+  depth.addEventListener("change", function () { if (input.value) load(input.value); });
+
+  var initial = new URLSearchParams(location.search).get("node");
+  if (initial) load(initial);
+})();
@@ -23,6 +23,7 @@ type BuiltinScheduler struct {
 	log        *log.Helper
 	executor   types.TaskExecutor
 	taskRepo   biz.TaskRepo
+	dsRepo     biz.DataSourceRepo
 	running    bool
 }
 
@@ -34,6 +35,8 @@ type workflowEntry struct {
 	isRunning bool
 	lastRun   *time.Time
 	nextRun   *time.Time
+	// deferTimer 采集窗口开始时重新触发被推迟的执行
+	deferTimer *time.Timer
 }
 
 // NewBuiltinScheduler 创建内置调度器
@@ -57,6 +60,11 @@ func (s *BuiltinScheduler) SetTaskRepo(repo biz.TaskRepo) {
 	s.taskRepo = repo
 }
 
+// SetDataSourceRepo 设置数据源仓储，用于检查数据源的采集窗口
+func (s *BuiltinScheduler) SetDataSourceRepo(repo biz.DataSourceRepo) {
+	s.dsRepo = repo
+}
+
 // GetType 获取调度器类型
 func (s *BuiltinScheduler) GetType() types.SchedulerType {
 	return types.SchedulerTypeBuiltIn
@@ -99,6 +107,7 @@ func (s *BuiltinScheduler) Shutdown(ctx context.Context) error {
 		if entry.stopChan != nil {
 			close(entry.stopChan)
 		}
+		entry.stopDeferLocked()
 	}
 
 	s.workflows = make(map[string]*workflowEntry)
@@ -189,6 +198,7 @@ func (s *BuiltinScheduler) DeleteWorkflow(ctx context.Context, id string) error
 	if entry.stopChan != nil {
 		close(entry.stopChan)
 	}
+	entry.stopDeferLocked()
 
 	delete(s.workflows, id)
 	s.log.WithContext(ctx).Infof("Workflow deleted: %s", id)
@@ -245,6 +255,11 @@ func (s *BuiltinScheduler) executeWorkflow(ctx context.Context, entry *workflowE
 	execution.Status = types.ExecutionStatusRunning
 	s.mu.Unlock()
 
+	if until, windows, ok := s.deferral(ctx, entry); ok {
+		s.deferWorkflow(entry, execution, until, windows)
+		return
+	}
+
 	s.log.Infof("Executing workflow: %s, execution: %s", entry.workflow.ID, execution.ID)
 
 	var result *biz.ExecutionResult
@@ -252,7 +267,7 @@ func (s *BuiltinScheduler) executeWorkflow(ctx context.Context, entry *workflowE
 
 	// 如果有任务执行器和任务仓储，执行实际任务
 	if s.executor != nil && s.taskRepo != nil {
-		task, err := s.taskRepo.GetTask(ctx, entry.workflow.ID)
+		task, err := s.taskRepo.Get(ctx, entry.workflow.ID)
 		if err != nil {
 			execErr = err
 		} else {
@@ -331,6 +346,7 @@ func (s *BuiltinScheduler) PauseWorkflow(ctx context.Context, id string) error {
 		s.cron.Remove(entry.cronID)
 		entry.cronID = 0
 	}
+	entry.stopDeferLocked()
 
 	entry.workflow.Status = types.WorkflowStatusPaused
 	entry.workflow.UpdatedAt = time.Now()
@@ -410,6 +426,14 @@ func (s *BuiltinScheduler) GetExecutionLogs(ctx context.Context, executionID str
 		})
 	}
 
+	if execution.Status == types.ExecutionStatusDeferred {
+		logs.Logs = append(logs.Logs, types.LogEntry{
+			Timestamp: *execution.EndTime,
+			Level:     "WARN",
+			Message:   fmt.Sprintf("Workflow execution deferred until %v: outside the collection window", execution.Result["deferred_until"]),
+		})
+	}
+
 	if execution.ErrorMessage != "" {
 		logs.Logs = append(logs.Logs, types.LogEntry{
 			Timestamp: *execution.EndTime,
@@ -505,12 +529,12 @@ func (s *BuiltinScheduler) scheduleWorkflowLocked(ctx context.Context, entry *wo
 		return nil
 
 	case biz.ScheduleTypeOnce:
-		if schedule.StartTime == nil {
+		if schedule.StartTime.IsZero() {
 			return fmt.Errorf("start time is required for once schedule")
 		}
 		// 定时执行一次
 		go func() {
-			delay := time.Until(*schedule.StartTime)
+			delay := time.Until(schedule.StartTime)
 			if delay > 0 {
 				select {
 				case <-time.After(delay):
@@ -523,7 +547,8 @@ func (s *BuiltinScheduler) scheduleWorkflowLocked(ctx context.Context, entry *wo
 				s.triggerWorkflowExecution(entry)
 			}
 		}()
-		entry.nextRun = schedule.StartTime
+		startTime := schedule.StartTime
+		entry.nextRun = &startTime
 		return nil
 	}
 
@@ -548,6 +573,67 @@ func (s *BuiltinScheduler) triggerWorkflowExecution(entry *workflowEntry) {
 	s.executeWorkflow(context.Background(), entry, execution)
 }
 
+// deferral 检查工作流数据源的采集窗口，不在窗口内时返回窗口开始时间
+func (s *BuiltinScheduler) deferral(ctx context.Context, entry *workflowEntry) (time.Time, *biz.CollectionWindows, bool) {
+	if s.dsRepo == nil || entry.workflow.DataSourceID == "" {
+		return time.Time{}, nil, false
+	}
+	ds, err := s.dsRepo.Get(ctx, entry.workflow.DataSourceID)
+	if err != nil {
+		// 数据源不可用时由执行本身报告错误
+		return time.Time{}, nil, false
+	}
+	windows, err := ds.CollectionWindows()
+	if err != nil {
+		s.log.Warnf("Ignoring collection window of data source %s: %v", ds.ID, err)
+		return time.Time{}, nil, false
+	}
+	now := time.Now()
+	if windows.Allows(now) {
+		return time.Time{}, nil, false
+	}
+	return windows.Next(now), windows, true
+}
+
+// deferWorkflow 将执行标记为推迟，并在窗口开始时重新触发；已有推迟的触发时不再重复安排
+func (s *BuiltinScheduler) deferWorkflow(entry *workflowEntry, execution *types.WorkflowExecution, until time.Time, windows *biz.CollectionWindows) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	execution.Status = types.ExecutionStatusDeferred
+	execution.EndTime = &now
+	execution.Duration = now.Sub(execution.StartTime).Milliseconds()
+	execution.Result = map[string]interface{}{
+		"deferred_until":     until.Format(time.RFC3339),
+		"collection_windows": windows.String(),
+	}
+	entry.isRunning = false
+
+	if entry.deferTimer == nil {
+		id := entry.workflow.ID
+		entry.deferTimer = time.AfterFunc(time.Until(until), func() {
+			s.mu.Lock()
+			entry.deferTimer = nil
+			s.mu.Unlock()
+			if _, err := s.TriggerWorkflow(context.Background(), id, nil); err != nil {
+				s.log.Errorf("Failed to run deferred workflow %s: %v", id, err)
+			}
+		})
+	}
+	entry.nextRun = &until
+	s.log.Warnf("Workflow execution deferred: %s, data source %s is outside its collection window %s, next run at %s",
+		execution.ID, entry.workflow.DataSourceID, windows, until.Format(time.RFC3339))
+}
+
+// stopDeferLocked 取消被推迟的执行（需要持有锁）
+func (e *workflowEntry) stopDeferLocked() {
+	if e.deferTimer != nil {
+		e.deferTimer.Stop()
+		e.deferTimer = nil
+	}
+}
+
 // GetRunningWorkflows 获取所有运行中的工作流
 func (s *BuiltinScheduler) GetRunningWorkflows() []string {
 	s.mu.RLock()
@@ -556,10 +556,10 @@ func (a *DolphinSchedulerAdapter) buildScheduleJSON(schedule *biz.ScheduleConfig
 	startTime := now.Format("2006-01-02 00:00:00")
 	endTime := now.AddDate(10, 0, 0).Format("2006-01-02 00:00:00") // 10年后
 
-	if schedule.StartTime != nil {
+	if !schedule.StartTime.IsZero() {
 		startTime = schedule.StartTime.Format("2006-01-02 15:04:05")
 	}
-	if schedule.EndTime != nil {
+	if !schedule.EndTime.IsZero() {
 		endTime = schedule.EndTime.Format("2006-01-02 15:04:05")
 	}
 
@@ -22,10 +22,15 @@ type SchedulerManager struct {
 	mu             sync.RWMutex
 	log            *log.Helper
 	taskRepo       biz.TaskRepo
+	dsRepo         biz.DataSourceRepo
 }
 
 // NewSchedulerManager 创建调度器管理器
+// 未配置调度器时使用内置调度器
 func NewSchedulerManager(config *conf.Scheduler, logger log.Logger) *SchedulerManager {
+	if config == nil {
+		config = &conf.Scheduler{}
+	}
 	return &SchedulerManager{
 		adapters: make(map[types.SchedulerType]types.SchedulerAdapter),
 		config:   config,
@@ -40,12 +45,40 @@ func (m *SchedulerManager) RegisterAdapter(adapter types.SchedulerAdapter) {
 
 	adapterType := adapter.GetType()
 	m.adapters[adapterType] = adapter
+	m.bindReposLocked(adapter)
 	m.log.Infof("Scheduler adapter registered: %s", adapterType)
 }
 
-// SetTaskRepo 设置任务仓储
+// SetTaskRepo 设置任务仓储，并传递给已注册的适配器
 func (m *SchedulerManager) SetTaskRepo(repo biz.TaskRepo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.taskRepo = repo
+	for _, adapter := range m.adapters {
+		m.bindReposLocked(adapter)
+	}
+}
+
+// SetDataSourceRepo 设置数据源仓储，并传递给已注册的适配器，用于检查数据源的采集窗口
+func (m *SchedulerManager) SetDataSourceRepo(repo biz.DataSourceRepo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.dsRepo = repo
+	for _, adapter := range m.adapters {
+		m.bindReposLocked(adapter)
+	}
+}
+
+// bindReposLocked 将任务与数据源仓储传递给需要它们的适配器（需要持有锁）
+func (m *SchedulerManager) bindReposLocked(adapter types.SchedulerAdapter) {
+	if a, ok := adapter.(interface{ SetTaskRepo(biz.TaskRepo) }); ok && m.taskRepo != nil {
+		a.SetTaskRepo(m.taskRepo)
+	}
+	if a, ok := adapter.(interface{ SetDataSourceRepo(biz.DataSourceRepo) }); ok && m.dsRepo != nil {
+		a.SetDataSourceRepo(m.dsRepo)
+	}
 }
 
 // Initialize 初始化调度器管理器
@@ -176,15 +209,12 @@ func (m *SchedulerManager) migrateWorkflows(ctx context.Context, from, to types.
 		return nil
 	}
 
-	tasks, err := m.taskRepo.ListTasks(ctx, &biz.ListTasksRequest{
-		Page:     1,
-		PageSize: 1000,
-	})
+	tasks, _, err := m.taskRepo.List(ctx, 1, 1000)
 	if err != nil {
 		return fmt.Errorf("failed to list tasks: %w", err)
 	}
 
-	for _, task := range tasks.Tasks {
+	for _, task := range tasks {
 		// 在新调度器中创建工作流
 		req := &types.CreateWorkflowRequest{
 			ID:           task.ID,
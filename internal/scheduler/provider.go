@@ -4,6 +4,7 @@ import (
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/google/wire"
 
+	"go-metadata/internal/biz"
 	"go-metadata/internal/conf"
 	"go-metadata/internal/scheduler/dolphin"
 	"go-metadata/internal/scheduler/types"
@@ -11,10 +12,9 @@ import (
 
 // ProviderSet is scheduler providers.
 var ProviderSet = wire.NewSet(
-	NewSchedulerManager,
 	NewBuiltinScheduler,
 	NewDolphinSchedulerAdapterFromConfig,
-	ProvideSchedulerAdapter,
+	ProvideSchedulerManager,
 )
 
 // NewDolphinSchedulerAdapterFromConfig 从配置创建DolphinScheduler适配器
@@ -36,12 +36,19 @@ func NewDolphinSchedulerAdapterFromConfig(c *conf.Scheduler, logger log.Logger)
 	return dolphin.NewDolphinSchedulerAdapter(config, logger)
 }
 
-// ProvideSchedulerAdapter 提供调度器适配器并注册到管理器
-func ProvideSchedulerAdapter(
-	manager *SchedulerManager,
+// ProvideSchedulerManager 创建调度器管理器并注册调度器适配器；内置调度器通过数据源仓储检查采集窗口
+func ProvideSchedulerManager(
+	c *conf.Scheduler,
 	builtin *BuiltinScheduler,
 	dolphinAdapter *dolphin.DolphinSchedulerAdapter,
+	taskRepo biz.TaskRepo,
+	dsRepo biz.DataSourceRepo,
+	logger log.Logger,
 ) *SchedulerManager {
+	manager := NewSchedulerManager(c, logger)
+	manager.SetTaskRepo(taskRepo)
+	manager.SetDataSourceRepo(dsRepo)
+
 	// 注册内置调度器
 	manager.RegisterAdapter(types.SchedulerAdapter(builtin))
 
@@ -34,6 +34,8 @@ const (
 	ExecutionStatusCompleted ExecutionStatus = "completed"
 	ExecutionStatusFailed    ExecutionStatus = "failed"
 	ExecutionStatusCancelled ExecutionStatus = "cancelled"
+	// ExecutionStatusDeferred 不在数据源采集窗口内，推迟到窗口开始时执行
+	ExecutionStatusDeferred ExecutionStatus = "deferred"
 )
 
 // CreateWorkflowRequest 创建工作流请求
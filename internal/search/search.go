@@ -0,0 +1,484 @@
+// Package search indexes harvested table metadata for full-text search.
+//
+// Table names, column names, comments and their translations, tags and the
+// example values of columns, if captured, are indexed in an embedded, in-memory inverted index.
+// Matches are ranked by the field they are found in, from table names down
+// to column examples, and by how rare the matched terms are. Results carry
+// facets counting the matching tables per source type, and can be filtered
+// by source type and source.
+//
+// Names are split into words on underscores, digits and case changes, so that
+// "user" finds user_id and UserAccount; the last word of a query also matches
+// as a prefix. Chinese text is indexed as characters and character pairs.
+package search
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/store"
+)
+
+// Weights of the fields a term matches in.
+const (
+	weightTable         = 10.0
+	weightTag           = 6.0
+	weightColumn        = 4.0
+	weightTableComment  = 3.0
+	weightColumnTag     = 3.0
+	weightSchema        = 2.0
+	weightColumnComment = 1.0
+	weightExample       = 0.5
+
+	// prefixFactor scales the weight of a word matched as a prefix.
+	prefixFactor = 0.5
+	// exactBoost is added to tables named exactly as the query.
+	exactBoost = 20.0
+)
+
+// DefaultLimit is the number of hits returned by queries without a limit.
+const DefaultLimit = 20
+
+// Document is the searchable metadata of a table.
+type Document struct {
+	Source     string   `json:"source"`
+	SourceType string   `json:"source_type"`
+	Catalog    string   `json:"catalog"`
+	Schema     string   `json:"schema"`
+	Table      string   `json:"table"`
+	Comment    string   `json:"comment,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Columns    []Column `json:"-"`
+	// Descriptions are the translations of Comment, found as the comment.
+	Descriptions []string `json:"-"`
+}
+
+// Column is the searchable metadata of a column.
+type Column struct {
+	Name         string
+	Comment      string
+	Descriptions []string
+	Tags         []string
+	Examples     []string
+}
+
+// Key returns the key of the table of d in the store.
+func (d *Document) Key() store.TableKey {
+	return store.TableKey{Source: d.Source, Catalog: d.Catalog, Schema: d.Schema, Table: d.Table}
+}
+
+// NewDocument returns the document of a harvested table of source.
+func NewDocument(source string, t *collector.TableMetadata) *Document {
+	d := &Document{
+		Source:       source,
+		SourceType:   t.SourceType,
+		Catalog:      t.Catalog,
+		Schema:       t.Schema,
+		Table:        t.Name,
+		Comment:      t.Comment,
+		Columns:      make([]Column, 0, len(t.Columns)),
+		Descriptions: translations(t.Comment, t.Descriptions),
+	}
+	for _, c := range t.Columns {
+		d.Columns = append(d.Columns, Column{
+			Name:         c.Name,
+			Comment:      c.Comment,
+			Descriptions: translations(c.Comment, c.Descriptions),
+			Examples:     c.Examples,
+		})
+	}
+	return d
+}
+
+// translations returns the descriptions of a comment other than the comment
+// itself, sorted.
+func translations(comment string, descriptions map[string]string) []string {
+	var texts []string
+	for _, text := range descriptions {
+		if text != comment && !slices.Contains(texts, text) {
+			texts = append(texts, text)
+		}
+	}
+	sort.Strings(texts)
+	return texts
+}
+
+// Query is a search.
+type Query struct {
+	// Text is the words to find; tables must match all of them.
+	Text string
+	// SourceTypes and Sources, if set, restrict the hits to tables of these
+	// source types and sources.
+	SourceTypes []string
+	Sources     []string
+	// Limit is the maximum number of hits; defaults to DefaultLimit.
+	Limit int
+}
+
+// Result is the outcome of a search.
+type Result struct {
+	// Total is the number of matching tables, of which Hits are the best
+	// ranked.
+	Total int    `json:"total"`
+	Hits  []*Hit `json:"hits"`
+	// Facets count the matching tables per source type, regardless of the
+	// source type filter of the query, so that other types can be selected.
+	Facets map[string]int `json:"facets"`
+}
+
+// Hit is a matching table.
+type Hit struct {
+	*Document
+	Score float64 `json:"score"`
+	// Matches are the fields the words matched in, e.g. "column:user_id".
+	Matches []string `json:"matches"`
+}
+
+// posting is a term of a document with the weight of the best field it is
+// in and the fields it is in.
+type posting struct {
+	weight float64
+	fields []string
+}
+
+// Index is an in-memory full-text index of table documents. It is safe for
+// concurrent use.
+type Index struct {
+	mu    sync.RWMutex
+	docs  map[string]*Document
+	terms map[string]map[string]*posting // term -> document ID -> posting
+}
+
+// NewIndex creates an empty index.
+func NewIndex() *Index {
+	return &Index{
+		docs:  make(map[string]*Document),
+		terms: make(map[string]map[string]*posting),
+	}
+}
+
+// Len returns the number of indexed tables.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Add indexes documents, replacing the documents of the same tables.
+func (ix *Index) Add(docs ...*Document) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, d := range docs {
+		ix.add(d)
+	}
+}
+
+// Remove removes the document of a table.
+func (ix *Index) Remove(key store.TableKey) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(key.String())
+}
+
+// ReplaceSource replaces the documents of the tables of source with docs.
+func (ix *Index) ReplaceSource(source string, docs []*Document) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for id, d := range ix.docs {
+		if d.Source == source {
+			ix.remove(id)
+		}
+	}
+	for _, d := range docs {
+		ix.add(d)
+	}
+}
+
+func (ix *Index) add(d *Document) {
+	id := d.Key().String()
+	ix.remove(id)
+	ix.docs[id] = d
+
+	post := func(text string, weight float64, field string) {
+		for _, term := range tokenize(text, true) {
+			postings := ix.terms[term]
+			if postings == nil {
+				postings = make(map[string]*posting)
+				ix.terms[term] = postings
+			}
+			p := postings[id]
+			if p == nil {
+				p = &posting{}
+				postings[id] = p
+			}
+			p.weight = math.Max(p.weight, weight)
+			if !slices.Contains(p.fields, field) {
+				p.fields = append(p.fields, field)
+			}
+		}
+	}
+	post(d.Table, weightTable, "table")
+	post(d.Catalog+" "+d.Schema, weightSchema, "schema")
+	post(d.Comment, weightTableComment, "comment")
+	for _, text := range d.Descriptions {
+		post(text, weightTableComment, "comment")
+	}
+	for _, tag := range d.Tags {
+		post(tag, weightTag, "tag:"+tag)
+	}
+	for _, c := range d.Columns {
+		post(c.Name, weightColumn, "column:"+c.Name)
+		post(c.Comment, weightColumnComment, "column_comment:"+c.Name)
+		for _, text := range c.Descriptions {
+			post(text, weightColumnComment, "column_comment:"+c.Name)
+		}
+		for _, tag := range c.Tags {
+			post(tag, weightColumnTag, "column_tag:"+c.Name+":"+tag)
+		}
+		for _, example := range c.Examples {
+			post(example, weightExample, "example:"+c.Name)
+		}
+	}
+}
+
+func (ix *Index) remove(id string) {
+	if _, ok := ix.docs[id]; !ok {
+		return
+	}
+	delete(ix.docs, id)
+	for term, postings := range ix.terms {
+		delete(postings, id)
+		if len(postings) == 0 {
+			delete(ix.terms, term)
+		}
+	}
+}
+
+// Search returns the tables matching all words of q.Text, best first. An
+// empty text matches nothing.
+func (ix *Index) Search(q Query) *Result {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	result := &Result{Hits: []*Hit{}, Facets: map[string]int{}}
+	words := tokenize(q.Text, false)
+	if len(words) == 0 {
+		return result
+	}
+
+	// Each word must match; a document scores the best match of each word
+	var hits map[string]*Hit
+	for i, word := range words {
+		prefix := i == len(words)-1
+		scores := ix.match(word, prefix)
+		next := make(map[string]*Hit, len(scores))
+		for id, m := range scores {
+			h := hits[id]
+			if hits != nil && h == nil {
+				continue
+			}
+			if h == nil {
+				h = &Hit{Document: ix.docs[id]}
+			}
+			h.Score += m.weight
+			for _, f := range m.fields {
+				if !slices.Contains(h.Matches, f) {
+					h.Matches = append(h.Matches, f)
+				}
+			}
+			next[id] = h
+		}
+		hits = next
+		if len(hits) == 0 {
+			return result
+		}
+	}
+
+	for _, h := range hits {
+		if len(q.Sources) > 0 && !containsFold(q.Sources, h.Source) {
+			continue
+		}
+		result.Facets[h.SourceType]++
+		if len(q.SourceTypes) > 0 && !containsFold(q.SourceTypes, h.SourceType) {
+			continue
+		}
+		if strings.EqualFold(h.Table, strings.TrimSpace(q.Text)) {
+			h.Score += exactBoost
+		}
+		h.Score = math.Round(h.Score*1000) / 1000
+		sort.Strings(h.Matches)
+		result.Hits = append(result.Hits, h)
+	}
+	result.Total = len(result.Hits)
+
+	sort.Slice(result.Hits, func(i, j int) bool {
+		a, b := result.Hits[i], result.Hits[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Key().String() < b.Key().String()
+	})
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if len(result.Hits) > limit {
+		result.Hits = result.Hits[:limit]
+	}
+	return result
+}
+
+// match returns the documents containing word, or a term it prefixes if
+// prefix is set, with the weight of their best match scaled by the rarity
+// of the term.
+func (ix *Index) match(word string, prefix bool) map[string]*posting {
+	scores := make(map[string]*posting)
+	add := func(term string, factor float64) {
+		postings := ix.terms[term]
+		idf := math.Log(1 + float64(len(ix.docs))/float64(len(postings)))
+		for id, p := range postings {
+			weight := p.weight * factor * idf
+			s := scores[id]
+			if s == nil {
+				s = &posting{}
+				scores[id] = s
+			}
+			s.weight = math.Max(s.weight, weight)
+			for _, f := range p.fields {
+				if !slices.Contains(s.fields, f) {
+					s.fields = append(s.fields, f)
+				}
+			}
+		}
+	}
+	if _, ok := ix.terms[word]; ok {
+		add(word, 1)
+	}
+	if prefix {
+		for term := range ix.terms {
+			if term != word && strings.HasPrefix(term, word) {
+				add(term, prefixFactor)
+			}
+		}
+	}
+	return scores
+}
+
+// Load reads the documents of the stored tables of source, or of every
+// source if source is empty.
+func Load(ctx context.Context, st store.Repository, source string) ([]*Document, error) {
+	keys, err := st.Tables(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]*Document, 0, len(keys))
+	for _, key := range keys {
+		t, err := st.GetTable(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		docs = append(docs, NewDocument(key.Source, t))
+	}
+	return docs, nil
+}
+
+// tokenize splits text into lower-case terms: words of letters or digits,
+// split further on underscores and case changes, and for Chinese text pairs
+// of characters. Terms of indexed text also include whole words and single
+// characters, so that both user_id and user find user_id.
+func tokenize(text string, indexing bool) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	emit := func(t string) {
+		t = strings.ToLower(t)
+		if t != "" && !seen[t] {
+			seen[t] = true
+			terms = append(terms, t)
+		}
+	}
+
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	for _, field := range fields {
+		var words []string
+		var han []rune
+		flushHan := func() {
+			switch {
+			case len(han) == 1:
+				emit(string(han))
+			case len(han) > 1:
+				for i := 0; i+1 < len(han); i++ {
+					emit(string(han[i : i+2]))
+				}
+				if indexing {
+					for _, r := range han {
+						emit(string(r))
+					}
+				}
+			}
+			han = han[:0]
+		}
+		var word []rune
+		flushWord := func() {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = word[:0]
+			}
+		}
+		runes := []rune(field)
+		for i, r := range runes {
+			switch {
+			case unicode.Is(unicode.Han, r):
+				flushWord()
+				han = append(han, r)
+				continue
+			case r == '_':
+				flushWord()
+			case i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) ||
+				i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])):
+				// userId, HTTPServer
+				flushWord()
+				word = append(word, r)
+			case i > 0 && unicode.IsDigit(r) != unicode.IsDigit(runes[i-1]) && runes[i-1] != '_':
+				flushWord()
+				word = append(word, r)
+			default:
+				word = append(word, r)
+			}
+			flushHan()
+		}
+		flushWord()
+		flushHan()
+
+		for _, w := range words {
+			emit(w)
+		}
+		// The whole name, e.g. user_id, matches too
+		if indexing && len(words) > 1 {
+			emit(strings.Map(func(r rune) rune {
+				if unicode.Is(unicode.Han, r) {
+					return -1
+				}
+				return r
+			}, field))
+		}
+	}
+	return terms
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"go-metadata/internal/store"
+)
+
+func testIndex() *Index {
+	ix := NewIndex()
+	ix.Add(
+		&Document{
+			Source: "mysql_prod", SourceType: "mysql", Catalog: "def", Schema: "shop", Table: "orders",
+			Comment: "用户订单表",
+			Tags:    []string{"pii"},
+			Columns: []Column{{Name: "id"}, {Name: "user_id", Comment: "下单用户"}, {Name: "createdAt"}},
+
+			Descriptions: []string{"customer purchases"},
+		},
+		&Document{
+			Source: "mysql_prod", SourceType: "mysql", Catalog: "def", Schema: "shop", Table: "users",
+			Columns: []Column{{Name: "id"}, {Name: "email", Tags: []string{"pii"}}},
+		},
+		&Document{
+			Source: "hive", SourceType: "hive", Catalog: "hive", Schema: "dw", Table: "fact_orders_daily",
+			Columns: []Column{{Name: "order_count"}, {Name: "user_id"}, {Name: "pay_channel", Examples: []string{"alipay", "wechat"}}},
+		},
+		&Document{
+			Source: "kafka", SourceType: "kafka", Catalog: "kafka", Schema: "default", Table: "order_events",
+			Comment: "order created and shipped events",
+		},
+	)
+	return ix
+}
+
+func tables(r *Result) []string {
+	var names []string
+	for _, h := range r.Hits {
+		names = append(names, h.Table)
+	}
+	return names
+}
+
+func TestIndex_Search(t *testing.T) {
+	ix := testIndex()
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"exact table name first", Query{Text: "orders"}, []string{"orders", "fact_orders_daily"}},
+		{"prefix of the last word", Query{Text: "order"}, []string{"order_events", "fact_orders_daily", "orders"}},
+		{"all words must match", Query{Text: "user order"}, []string{"fact_orders_daily", "orders"}},
+		{"camel case column", Query{Text: "created"}, []string{"orders", "order_events"}},
+		{"tags", Query{Text: "pii"}, []string{"orders", "users"}},
+		{"chinese comment", Query{Text: "订单"}, []string{"orders"}},
+		{"translated comment", Query{Text: "purchase"}, []string{"orders"}},
+		{"example values", Query{Text: "wechat"}, []string{"fact_orders_daily"}},
+		{"source type filter", Query{Text: "user", SourceTypes: []string{"HIVE"}}, []string{"fact_orders_daily"}},
+		{"source filter", Query{Text: "id", Sources: []string{"hive"}}, []string{"fact_orders_daily"}},
+		{"limit", Query{Text: "id", Limit: 1}, []string{"fact_orders_daily"}},
+		{"no match", Query{Text: "invoice"}, nil},
+		{"empty", Query{Text: " "}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tables(ix.Search(tt.query)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%+v) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndex_Facets(t *testing.T) {
+	r := testIndex().Search(Query{Text: "user", SourceTypes: []string{"hive"}})
+	if want := map[string]int{"mysql": 2, "hive": 1}; !reflect.DeepEqual(r.Facets, want) {
+		t.Errorf("Facets = %v, want %v", r.Facets, want)
+	}
+	if r.Total != 1 {
+		t.Errorf("Total = %d, want 1", r.Total)
+	}
+	if want := []string{"column:user_id"}; !reflect.DeepEqual(r.Hits[0].Matches, want) {
+		t.Errorf("Matches = %v, want %v", r.Hits[0].Matches, want)
+	}
+}
+
+func TestIndex_ReplaceSource(t *testing.T) {
+	ix := testIndex()
+	ix.ReplaceSource("mysql_prod", []*Document{{Source: "mysql_prod", SourceType: "mysql", Schema: "shop", Table: "invoices"}})
+	if ix.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", ix.Len())
+	}
+	if got := tables(ix.Search(Query{Text: "pii"})); got != nil {
+		t.Errorf("Removed tables still found: %v", got)
+	}
+	if got := tables(ix.Search(Query{Text: "invoice"})); !reflect.DeepEqual(got, []string{"invoices"}) {
+		t.Errorf("Search(invoice) = %v", got)
+	}
+
+	ix.Remove(store.TableKey{Source: "mysql_prod", Schema: "shop", Table: "invoices"})
+	if got := tables(ix.Search(Query{Text: "invoice"})); got != nil {
+		t.Errorf("Removed table still found: %v", got)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text     string
+		indexing bool
+		want     []string
+	}{
+		{"user_id", true, []string{"user", "id", "user_id"}},
+		{"user_id", false, []string{"user", "id"}},
+		{"HTTPServerLog", true, []string{"http", "server", "log", "httpserverlog"}},
+		{"orders2024", false, []string{"orders", "2024"}},
+		{"用户订单", false, []string{"用户", "户订", "订单"}},
+		{"订单 table", true, []string{"订单", "订", "单", "table"}},
+	}
+	for _, tt := range tests {
+		if got := tokenize(tt.text, tt.indexing); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q, %t) = %v, want %v", tt.text, tt.indexing, got, tt.want)
+		}
+	}
+}
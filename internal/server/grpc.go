@@ -18,7 +18,10 @@ func NewGRPCServer(
 	logger log.Logger,
 	datasource *service.DataSourceService,
 	task *service.TaskService,
+	syncProgress *service.SyncProgressService,
 	template *service.TemplateService,
+	catalog *service.CatalogGRPCService,
+	lineage *service.LineageGRPCService,
 ) *grpc.Server {
 	var opts = []grpc.ServerOption{
 		grpc.Middleware(
@@ -41,7 +44,10 @@ func NewGRPCServer(
 	// 注册生成的 gRPC 服务
 	v1.RegisterDataSourceServiceServer(srv, datasource)
 	v1.RegisterTaskServiceServer(srv, task)
+	v1.RegisterSyncProgressServiceServer(srv, syncProgress)
 	v1.RegisterTemplateServiceServer(srv, template)
+	v1.RegisterCatalogServiceServer(srv, catalog)
+	v1.RegisterLineageServiceServer(srv, lineage)
 
 	return srv
 }
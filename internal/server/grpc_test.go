@@ -0,0 +1,232 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	v1 "go-metadata/api/metadata/v1"
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/collectortest"
+	"go-metadata/internal/conf"
+	lineageCore "go-metadata/internal/lineage"
+	"go-metadata/internal/service"
+	lineageService "go-metadata/internal/service/lineage"
+	metadataService "go-metadata/internal/service/metadata"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestConn serves NewGRPCServer on an in-memory listener for a mysql_prod
+// source with the tables def.shop.orders, def.shop.customers and
+// def.shop.items, and returns a connection to it.
+func newTestConn(t *testing.T) (*grpc.ClientConn, *collectortest.Collector) {
+	t.Helper()
+	c := collectortest.New(collector.CategoryRDBMS, "mysql")
+	for _, name := range []string{"orders", "customers", "items"} {
+		c.AddTable(&collector.TableMetadata{Catalog: "def", Schema: "shop", Name: name, Type: collector.TableTypeTable,
+			Columns: []collector.Column{{OrdinalPosition: 1, Name: "id", Type: "bigint"}}})
+	}
+	md := metadataService.NewService(nil)
+	md.RegisterCollector("mysql_prod", c)
+	lineage := lineageService.NewService(lineageCore.NewAnalyzer(nil), nil)
+	logger := log.NewStdLogger(io.Discard)
+
+	catalog := service.NewCatalogService(md, lineage, nil, logger)
+	srv := NewGRPCServer(&conf.Server{Grpc: &conf.GRPC{Addr: "127.0.0.1:0"}}, logger,
+		service.NewDataSourceService(nil, logger),
+		service.NewTaskService(nil, logger),
+		service.NewSyncProgressService(nil, logger),
+		service.NewTemplateService(nil, logger),
+		service.NewCatalogGRPCService(catalog, md, logger),
+		service.NewLineageGRPCService(lineage),
+	)
+
+	// Stream interceptors report the endpoint of the configured address;
+	// requests go through the in-memory listener.
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(func() { srv.Stop(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, c
+}
+
+// expectStatus checks that err has the gRPC code and the reason of the
+// kratos error it was converted from.
+func expectStatus(t *testing.T, err error, code codes.Code, reason string) {
+	t.Helper()
+	if got := status.Code(err); got != code {
+		t.Errorf("Expected %s, got %s (%v)", code, got, err)
+	}
+	if got := errors.Reason(err); got != reason {
+		t.Errorf("Expected reason %s, got %q", reason, got)
+	}
+}
+
+func TestGRPCCatalog(t *testing.T) {
+	conn, _ := newTestConn(t)
+	client := v1.NewCatalogServiceClient(conn)
+	ctx := context.Background()
+
+	sources, err := client.ListSources(ctx, &v1.ListSourcesRequest{})
+	if err != nil || len(sources.Sources) != 1 || sources.Sources[0] != "mysql_prod" {
+		t.Fatalf("Expected the mysql_prod source, got %v, %v", sources, err)
+	}
+
+	page, err := client.ListTables(ctx, &v1.ListTablesRequest{Source: "mysql_prod", Catalog: "def", Schema: "shop", PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Tables) != 2 || page.TotalCount != 3 || page.NextPageToken == "" {
+		t.Errorf("Expected 2 of 3 tables, got %v", page)
+	}
+	page, err = client.ListTables(ctx, &v1.ListTablesRequest{Source: "mysql_prod", Catalog: "def", Schema: "shop", PageSize: 2, PageToken: page.NextPageToken})
+	if err != nil || len(page.Tables) != 1 || page.NextPageToken != "" {
+		t.Errorf("Expected the last table, got %v, %v", page, err)
+	}
+
+	table, err := client.GetTableMetadata(ctx, &v1.GetTableMetadataRequest{Source: "mysql_prod", Catalog: "def", Schema: "shop", Table: "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Name != "orders" || len(table.Columns) != 1 || table.Columns[0].Name != "id" {
+		t.Errorf("Expected the orders table, got %v", table)
+	}
+
+	sync, err := client.SyncSource(ctx, &v1.SyncSourceRequest{Source: "mysql_prod"})
+	if err != nil || sync.Status != "accepted" {
+		t.Errorf("Expected the sync to be accepted, got %v, %v", sync, err)
+	}
+}
+
+func TestGRPCCatalogErrors(t *testing.T) {
+	conn, c := newTestConn(t)
+	client := v1.NewCatalogServiceClient(conn)
+	ctx := context.Background()
+
+	_, err := client.ListCatalogs(ctx, &v1.ListCatalogsRequest{Source: "oracle_prod"})
+	expectStatus(t, err, codes.NotFound, "SOURCE_NOT_FOUND")
+	_, err = client.GetTableMetadata(ctx, &v1.GetTableMetadataRequest{Source: "mysql_prod", Catalog: "def", Schema: "shop", Table: "missing"})
+	expectStatus(t, err, codes.NotFound, "NOT_FOUND")
+	_, err = client.SyncSource(ctx, &v1.SyncSourceRequest{Source: "oracle_prod"})
+	expectStatus(t, err, codes.NotFound, "SOURCE_NOT_FOUND")
+
+	// Validation
+	_, err = client.SyncSource(ctx, &v1.SyncSourceRequest{})
+	expectStatus(t, err, codes.InvalidArgument, "INVALID_REQUEST")
+	_, err = client.ListTables(ctx, &v1.ListTablesRequest{Source: "mysql_prod", Catalog: "def", Schema: "shop", PageToken: "abc"})
+	expectStatus(t, err, codes.InvalidArgument, "INVALID_REQUEST")
+
+	// Collector errors
+	c.SetError(collectortest.OpListSchemas, collector.NewTimeoutError("mysql", "list_schemas", nil))
+	_, err = client.ListSchemas(ctx, &v1.ListSchemasRequest{Source: "mysql_prod", Catalog: "def"})
+	expectStatus(t, err, codes.DeadlineExceeded, "SOURCE_TIMEOUT")
+	c.SetError(collectortest.OpFetchTableStatistics, collector.NewNetworkError("mysql", "fetch_table_statistics", nil))
+	_, err = client.GetTableStatistics(ctx, &v1.GetTableStatisticsRequest{Source: "mysql_prod", Catalog: "def", Schema: "shop", Table: "orders"})
+	expectStatus(t, err, codes.Unavailable, "SOURCE_UNAVAILABLE")
+
+	// Methods the server does not implement
+	err = conn.Invoke(ctx, "/api.metadata.v1.CatalogService/DropSource", &v1.SyncSourceRequest{}, &v1.SyncSourceResponse{})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented for an unknown method, got %v", err)
+	}
+}
+
+func TestGRPCStreamTables(t *testing.T) {
+	conn, c := newTestConn(t)
+	client := v1.NewCatalogServiceClient(conn)
+	ctx := context.Background()
+
+	stream, err := client.StreamTables(ctx, &v1.StreamTablesRequest{Source: "mysql_prod", Catalog: "def", Schema: "shop", IncludeMetadata: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for {
+		entry, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Metadata == nil || entry.Metadata.Name != entry.Name {
+			t.Errorf("Expected the metadata of %s, got %v", entry.Name, entry)
+		}
+		names = append(names, entry.Name)
+	}
+	if len(names) != 3 {
+		t.Errorf("Expected 3 tables, got %v", names)
+	}
+
+	// Tables whose metadata cannot be fetched are sent with the error
+	c.SetError(collectortest.OpFetchTableMetadata, collector.NewNetworkError("mysql", "fetch_table_metadata", nil))
+	stream, err = client.StreamTables(ctx, &v1.StreamTablesRequest{Source: "mysql_prod", Catalog: "def", Schema: "shop", IncludeMetadata: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := stream.Recv()
+	if err != nil || entry.Metadata != nil || entry.Error == "" {
+		t.Errorf("Expected a table with an error, got %v, %v", entry, err)
+	}
+
+	stream, err = client.StreamTables(ctx, &v1.StreamTablesRequest{Source: "oracle_prod", Catalog: "def", Schema: "shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Recv()
+	expectStatus(t, err, codes.NotFound, "SOURCE_NOT_FOUND")
+}
+
+func TestGRPCLineage(t *testing.T) {
+	conn, _ := newTestConn(t)
+	client := v1.NewLineageServiceClient(conn)
+	ctx := context.Background()
+
+	analyzed, err := client.AnalyzeSQL(ctx, &v1.AnalyzeSQLRequest{Sql: "INSERT INTO daily SELECT id FROM orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(analyzed.Columns) != 1 || analyzed.Columns[0].Target.Table != "daily" {
+		t.Errorf("Expected the lineage of daily.id, got %v", analyzed.Columns)
+	}
+	// Analyzing does not record
+	if lineage, err := client.GetTableLineage(ctx, &v1.GetTableLineageRequest{Table: "daily"}); err != nil || len(lineage.Edges) != 0 {
+		t.Errorf("Expected analyze not to record lineage, got %v, %v", lineage, err)
+	}
+
+	if _, err := client.RecordSQL(ctx, &v1.RecordSQLRequest{Sql: "INSERT INTO daily SELECT id FROM orders"}); err != nil {
+		t.Fatal(err)
+	}
+	lineage, err := client.GetTableLineage(ctx, &v1.GetTableLineageRequest{Table: "daily"})
+	if err != nil || len(lineage.Edges) != 1 || lineage.Edges[0].Source.Table != "orders" {
+		t.Errorf("Expected the recorded edge from orders, got %v, %v", lineage, err)
+	}
+
+	_, err = client.AnalyzeSQL(ctx, &v1.AnalyzeSQLRequest{Sql: " "})
+	expectStatus(t, err, codes.InvalidArgument, "INVALID_REQUEST")
+	_, err = client.RecordSQL(ctx, &v1.RecordSQLRequest{})
+	expectStatus(t, err, codes.InvalidArgument, "INVALID_REQUEST")
+	_, err = client.RecordSQL(ctx, &v1.RecordSQLRequest{Sql: "INSERT INTO"})
+	expectStatus(t, err, codes.InvalidArgument, "INVALID_SQL")
+	_, err = client.GetTableLineage(ctx, &v1.GetTableLineageRequest{})
+	expectStatus(t, err, codes.InvalidArgument, "INVALID_REQUEST")
+}
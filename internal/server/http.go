@@ -4,6 +4,7 @@ import (
 	v1 "go-metadata/api/metadata/v1"
 	"go-metadata/internal/conf"
 	"go-metadata/internal/service"
+	lineageService "go-metadata/internal/service/lineage"
 
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware/logging"
@@ -20,7 +21,10 @@ func NewHTTPServer(
 	task *service.TaskService,
 	template *service.TemplateService,
 	user *service.UserService,
-) *http.Server {
+	table *service.TableService,
+	lineage *lineageService.Service,
+	catalog *service.CatalogService,
+) (*http.Server, error) {
 	var opts = []http.ServerOption{
 		http.Middleware(
 			recovery.Recovery(),
@@ -45,5 +49,22 @@ func NewHTTPServer(
 	v1.RegisterTemplateServiceHTTPServer(srv, template)
 	v1.RegisterUserServiceHTTPServer(srv, user)
 
-	return srv
+	// 数据源凭证迁移接口
+	datasource.RegisterHTTP(srv)
+
+	// 表与字段的用户描述接口
+	table.RegisterHTTP(srv)
+
+	// 数据源目录浏览、同步触发与 SQL 血缘分析接口
+	catalog.RegisterHTTP(srv)
+	service.RegisterLineageHTTP(srv, lineage)
+
+	// 血缘 GraphQL 查询接口
+	graphqlHandler, err := lineageService.NewGraphQLHandler(lineage)
+	if err != nil {
+		return nil, err
+	}
+	srv.Handle("/graphql", graphqlHandler)
+
+	return srv, nil
 }
@@ -0,0 +1,440 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-metadata/internal/biz"
+	"go-metadata/internal/collector"
+	"go-metadata/internal/lineage/storage"
+	"go-metadata/internal/redact"
+	"go-metadata/internal/search"
+	lineageService "go-metadata/internal/service/lineage"
+	metadataService "go-metadata/internal/service/metadata"
+	"go-metadata/internal/store"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Page sizes of the list endpoints.
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// CatalogService browses the catalogs, schemas and tables of the configured
+// collector sources, triggers their syncs and searches the synced tables.
+type CatalogService struct {
+	md      *metadataService.Service
+	lineage *lineageService.Service
+	tables  *biz.TableUsecase
+	storage *storage.Resolver
+	log     *log.Helper
+
+	// search indexes the stored tables once searched, and is refreshed by
+	// syncs and deletions.
+	searchMu sync.Mutex
+	search   *search.Index
+}
+
+// NewCatalogService creates a new CatalogService. Syncs record the lineage
+// found in source metadata in the lineage service; searches find the tags
+// curated on the tables of the table usecase.
+func NewCatalogService(md *metadataService.Service, lineage *lineageService.Service, tables *biz.TableUsecase, logger log.Logger) *CatalogService {
+	// Tables the recorded lineage depends on are protected from deletion
+	md.SetDependencyChecker(lineage)
+	return &CatalogService{
+		md:      md,
+		lineage: lineage,
+		tables:  tables,
+		storage: storage.NewResolver(),
+		log:     log.NewHelper(logger),
+	}
+}
+
+// PageRequest selects a page of a list. PageToken is the next_page_token of
+// the previous page.
+type PageRequest struct {
+	PageSize  int
+	PageToken string
+}
+
+// ListSourcesResponse lists the configured sources.
+type ListSourcesResponse struct {
+	Sources []string `json:"sources"`
+}
+
+// ListCatalogsResponse lists a page of the catalogs of a source.
+type ListCatalogsResponse struct {
+	Catalogs      []collector.CatalogInfo `json:"catalogs"`
+	NextPageToken string                  `json:"next_page_token,omitempty"`
+	TotalCount    int                     `json:"total_count"`
+}
+
+// ListSchemasResponse lists a page of the schemas of a catalog.
+type ListSchemasResponse struct {
+	Schemas       []string `json:"schemas"`
+	NextPageToken string   `json:"next_page_token,omitempty"`
+	TotalCount    int      `json:"total_count"`
+}
+
+// ListTablesResponse lists a page of the tables of a schema.
+type ListTablesResponse struct {
+	Tables        []string `json:"tables"`
+	NextPageToken string   `json:"next_page_token,omitempty"`
+	TotalCount    int      `json:"total_count"`
+}
+
+// DeleteTableResponse acknowledges a table removed from the store.
+type DeleteTableResponse struct {
+	Source string `json:"source"`
+	Table  string `json:"table"`
+	Status string `json:"status"`
+}
+
+// SyncResponse acknowledges a triggered sync.
+type SyncResponse struct {
+	Source string `json:"source"`
+	Status string `json:"status"`
+}
+
+// SchemaChangesResponse lists how often the schemas of the sources changed
+// since a time.
+type SchemaChangesResponse struct {
+	Since time.Time           `json:"since"`
+	Rates []*store.ChangeRate `json:"rates"`
+}
+
+// RegisterHTTP registers the routes on srv:
+//
+//	GET    /api/v1/sources
+//	GET    /api/v1/sources/{source}/catalogs[?page_size=&page_token=]
+//	GET    /api/v1/sources/{source}/catalogs/{catalog}/schemas[?page_size=&page_token=]
+//	GET    /api/v1/sources/{source}/catalogs/{catalog}/schemas/{schema}/tables[?page_size=&page_token=]
+//	GET    /api/v1/sources/{source}/catalogs/{catalog}/schemas/{schema}/tables/{table}[?cached=true]
+//	DELETE /api/v1/sources/{source}/catalogs/{catalog}/schemas/{schema}/tables/{table}[?force=true]
+//	POST   /api/v1/sources/{source}/sync[?incremental=true&force=true]
+//	GET    /api/v1/search?q=[&source_type=&source=&limit=]
+//	GET    /api/v1/reports/schema-changes[?source=&since=]
+//	GET    /api/v1/subscriptions
+//	POST   /api/v1/subscriptions
+//	DELETE /api/v1/subscriptions?source=[&table=]
+//	PUT    /api/v1/subscriptions/channels/{type}
+//	DELETE /api/v1/subscriptions/channels/{type}
+func (s *CatalogService) RegisterHTTP(srv *http.Server) {
+	r := srv.Route("/")
+	r.GET("/api/v1/sources", s.listSources)
+	r.GET("/api/v1/sources/{source}/catalogs", s.listCatalogs)
+	r.GET("/api/v1/sources/{source}/catalogs/{catalog}/schemas", s.listSchemas)
+	r.GET("/api/v1/sources/{source}/catalogs/{catalog}/schemas/{schema}/tables", s.listTables)
+	r.GET("/api/v1/sources/{source}/catalogs/{catalog}/schemas/{schema}/tables/{table}", s.getTable)
+	r.DELETE("/api/v1/sources/{source}/catalogs/{catalog}/schemas/{schema}/tables/{table}", s.deleteTable)
+	r.POST("/api/v1/sources/{source}/sync", s.sync)
+	r.GET("/api/v1/search", s.searchTables)
+	r.GET("/api/v1/reports/schema-changes", s.schemaChanges)
+	r.GET("/api/v1/subscriptions", s.listSubscriptions)
+	r.POST("/api/v1/subscriptions", s.subscribe)
+	r.DELETE("/api/v1/subscriptions", s.unsubscribe)
+	r.PUT("/api/v1/subscriptions/channels/{type}", s.setChannel)
+	r.DELETE("/api/v1/subscriptions/channels/{type}", s.deleteChannel)
+}
+
+// ListSources lists the configured sources.
+func (s *CatalogService) ListSources(ctx context.Context) (*ListSourcesResponse, error) {
+	return &ListSourcesResponse{Sources: s.md.Sources()}, nil
+}
+
+// ListCatalogs lists a page of the catalogs of a source.
+func (s *CatalogService) ListCatalogs(ctx context.Context, source string, page PageRequest) (*ListCatalogsResponse, error) {
+	catalogs, err := s.md.DiscoverCatalogs(ctx, source)
+	if err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	items, next, err := paginate(catalogs, page)
+	if err != nil {
+		return nil, err
+	}
+	return &ListCatalogsResponse{Catalogs: items, NextPageToken: next, TotalCount: len(catalogs)}, nil
+}
+
+// ListSchemas lists a page of the schemas of a catalog.
+func (s *CatalogService) ListSchemas(ctx context.Context, source, catalog string, page PageRequest) (*ListSchemasResponse, error) {
+	schemas, err := s.md.ListSchemas(ctx, source, catalog)
+	if err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	items, next, err := paginate(schemas, page)
+	if err != nil {
+		return nil, err
+	}
+	return &ListSchemasResponse{Schemas: items, NextPageToken: next, TotalCount: len(schemas)}, nil
+}
+
+// ListTables lists a page of the tables of a schema.
+func (s *CatalogService) ListTables(ctx context.Context, source, catalog, schema string, page PageRequest) (*ListTablesResponse, error) {
+	if _, err := pageOffset(page.PageToken); err != nil {
+		return nil, err
+	}
+	result, err := s.md.ListSourceTables(ctx, source, catalog, schema, &collector.ListOptions{
+		PageSize:  pageSize(page.PageSize),
+		PageToken: page.PageToken,
+	})
+	if err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	return &ListTablesResponse{Tables: result.Tables, NextPageToken: result.NextPageToken, TotalCount: result.TotalCount}, nil
+}
+
+// GetTable fetches the metadata of a table from its source.
+func (s *CatalogService) GetTable(ctx context.Context, source, catalog, schema, table string) (*collector.TableMetadata, error) {
+	metadata, err := s.md.FetchTableMetadata(ctx, source, catalog, schema, table)
+	if err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	return metadata, nil
+}
+
+// GetStoredTable returns the metadata of a table as of its last sync from the
+// metadata store, without connecting to the source.
+func (s *CatalogService) GetStoredTable(ctx context.Context, source, catalog, schema, table string) (*collector.TableMetadata, error) {
+	metadata, err := s.md.StoredTable(ctx, source, catalog, schema, table)
+	if err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	return metadata, nil
+}
+
+// DeleteTable removes a table from the metadata store. Tables that other
+// tables depend on in the lineage graph are only removed with force.
+func (s *CatalogService) DeleteTable(ctx context.Context, source, catalog, schema, table string, force bool) (*DeleteTableResponse, error) {
+	if err := s.md.DeleteTable(ctx, source, catalog, schema, table, force); err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	key := store.TableKey{Source: source, Catalog: catalog, Schema: schema, Table: table}
+	s.unindex(key)
+	s.log.Infof("table %s deleted from the store (force: %t)", key, force)
+	return &DeleteTableResponse{Source: source, Table: key.String(), Status: "deleted"}, nil
+}
+
+// Sync starts synchronizing the metadata of a source in the background. An
+// incremental sync only refetches the tables that changed since the last
+// one; a forced sync also prunes dropped tables that others depend on.
+func (s *CatalogService) Sync(ctx context.Context, source string, opts metadataService.SyncOptions) (*SyncResponse, error) {
+	if !slices.Contains(s.md.Sources(), source) {
+		return nil, toCatalogHTTPError(metadataService.ErrSourceNotFound)
+	}
+	go func() {
+		ctx := context.Background()
+		summary, err := s.md.Sync(ctx, source, opts)
+		if err != nil {
+			s.log.Errorf("sync %s: %v", source, err)
+			return
+		}
+		if err := s.reindex(ctx, source); err != nil {
+			s.log.Errorf("sync %s: update search index: %v", source, err)
+		}
+		if err := s.recordLineage(ctx, source); err != nil {
+			s.log.Errorf("sync %s: record lineage: %v", source, err)
+			return
+		}
+		if err := s.lineage.PersistGraph(ctx); err != nil {
+			s.log.Errorf("sync %s: persist lineage graph: %v", source, err)
+		}
+		s.log.Infof("sync %s completed: %d tables fetched, %d unchanged, %d deferred", source, summary.Fetched, summary.Unchanged, summary.Deferred)
+	}()
+	return &SyncResponse{Source: source, Status: "accepted"}, nil
+}
+
+func (s *CatalogService) listSources(ctx http.Context) error {
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.ListSources(c)
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *CatalogService) listCatalogs(ctx http.Context) error {
+	vars := ctx.Vars()
+	page, err := pageRequest(ctx)
+	if err != nil {
+		return err
+	}
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.ListCatalogs(c, vars.Get("source"), page)
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *CatalogService) listSchemas(ctx http.Context) error {
+	vars := ctx.Vars()
+	page, err := pageRequest(ctx)
+	if err != nil {
+		return err
+	}
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.ListSchemas(c, vars.Get("source"), vars.Get("catalog"), page)
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *CatalogService) listTables(ctx http.Context) error {
+	vars := ctx.Vars()
+	page, err := pageRequest(ctx)
+	if err != nil {
+		return err
+	}
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.ListTables(c, vars.Get("source"), vars.Get("catalog"), vars.Get("schema"), page)
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *CatalogService) getTable(ctx http.Context) error {
+	vars := ctx.Vars()
+	cached := ctx.Query().Get("cached") == "true"
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		if cached {
+			return s.GetStoredTable(c, vars.Get("source"), vars.Get("catalog"), vars.Get("schema"), vars.Get("table"))
+		}
+		return s.GetTable(c, vars.Get("source"), vars.Get("catalog"), vars.Get("schema"), vars.Get("table"))
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *CatalogService) deleteTable(ctx http.Context) error {
+	vars := ctx.Vars()
+	force := ctx.Query().Get("force") == "true"
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.DeleteTable(c, vars.Get("source"), vars.Get("catalog"), vars.Get("schema"), vars.Get("table"), force)
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *CatalogService) sync(ctx http.Context) error {
+	vars := ctx.Vars()
+	query := ctx.Query()
+	opts := metadataService.SyncOptions{
+		Incremental: query.Get("incremental") == "true",
+		Force:       query.Get("force") == "true",
+	}
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.Sync(c, vars.Get("source"), opts)
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(202, out)
+}
+
+// pageRequest reads page_size and page_token from the query.
+func pageRequest(ctx http.Context) (PageRequest, error) {
+	query := ctx.Query()
+	page := PageRequest{PageToken: query.Get("page_token")}
+	if v := query.Get("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return page, errors.BadRequest("INVALID_REQUEST", "page_size must be a non-negative integer")
+		}
+		page.PageSize = n
+	}
+	return page, nil
+}
+
+// pageSize applies the default and maximum page size.
+func pageSize(n int) int {
+	if n <= 0 {
+		return defaultPageSize
+	}
+	return min(n, maxPageSize)
+}
+
+// pageOffset decodes a page token, the offset of the page like the
+// collectors' table listings.
+func pageOffset(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	offset, err := strconv.Atoi(token)
+	if err != nil || offset < 0 {
+		return 0, errors.BadRequest("INVALID_REQUEST", "invalid page_token")
+	}
+	return offset, nil
+}
+
+// paginate returns a page of items and the token of the next page.
+func paginate[T any](items []T, page PageRequest) ([]T, string, error) {
+	start, err := pageOffset(page.PageToken)
+	if err != nil {
+		return nil, "", err
+	}
+	start = min(start, len(items))
+	end := min(start+pageSize(page.PageSize), len(items))
+	next := ""
+	if end < len(items) {
+		next = strconv.Itoa(end)
+	}
+	return items[start:end], next, nil
+}
+
+// toCatalogHTTPError maps source and collector errors to HTTP errors.
+func toCatalogHTTPError(err error) error {
+	if stderrors.Is(err, metadataService.ErrSourceNotFound) {
+		return errors.NotFound("SOURCE_NOT_FOUND", err.Error())
+	}
+	if stderrors.Is(err, store.ErrNotFound) || stderrors.Is(err, store.ErrNoSubscription) {
+		return errors.NotFound("NOT_FOUND", err.Error())
+	}
+	if stderrors.Is(err, metadataService.ErrInvalidSubscription) {
+		return errors.BadRequest("INVALID_REQUEST", err.Error())
+	}
+	var deps *metadataService.DependentsError
+	if stderrors.As(err, &deps) {
+		return errors.Conflict("HAS_DEPENDENTS", err.Error()).
+			WithMetadata(map[string]string{"dependents": strings.Join(deps.Dependents, ",")})
+	}
+	if stderrors.Is(err, metadataService.ErrNoStore) {
+		return errors.ServiceUnavailable("STORE_NOT_CONFIGURED", err.Error())
+	}
+	switch collector.GetErrorCode(err) {
+	case collector.ErrCodeNotFound:
+		return errors.NotFound("NOT_FOUND", err.Error())
+	case collector.ErrCodeAuthError, collector.ErrCodeNetworkError, collector.ErrCodeConnectionClosed:
+		return errors.ServiceUnavailable("SOURCE_UNAVAILABLE", err.Error())
+	case collector.ErrCodeTimeout:
+		return errors.GatewayTimeout("SOURCE_TIMEOUT", err.Error())
+	case collector.ErrCodeInternal:
+		return errors.InternalServer("COLLECTOR_INTERNAL", err.Error())
+	}
+	return redact.Error(err)
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go-metadata/internal/store"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// DefaultChangeWindow is the period schema change rates are computed over
+// when no start is given: the last 12 weeks.
+const DefaultChangeWindow = 12 * 7 * 24 * time.Hour
+
+// SchemaChanges returns how often the schemas of a source, or of all sources
+// if source is empty, changed since a time: the schema changes per week and
+// the columns added, dropped and modified, most frequently changing sources
+// first.
+func (s *CatalogService) SchemaChanges(ctx context.Context, source string, since time.Time) (*SchemaChangesResponse, error) {
+	if since.IsZero() {
+		since = time.Now().Add(-DefaultChangeWindow)
+	}
+	rates, err := s.md.ChangeRates(ctx, source, since)
+	if err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	if rates == nil {
+		rates = []*store.ChangeRate{}
+	}
+	return &SchemaChangesResponse{Since: since, Rates: rates}, nil
+}
+
+func (s *CatalogService) schemaChanges(ctx http.Context) error {
+	query := ctx.Query()
+	source := query.Get("source")
+	var since time.Time
+	if v := query.Get("since"); v != "" {
+		parsed, err := parseReportTime(v)
+		if err != nil {
+			return errors.BadRequest("INVALID_REQUEST", "since must be a date (2006-01-02) or an RFC 3339 time")
+		}
+		since = parsed
+	}
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.SchemaChanges(c, source, since)
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	v1 "go-metadata/api/metadata/v1"
+	"go-metadata/internal/collector"
+	metadataService "go-metadata/internal/service/metadata"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// streamTablesBatch is the number of table names fetched per page while
+// streaming a table listing.
+const streamTablesBatch = 500
+
+// CatalogGRPCService serves the collector operations of CatalogService over
+// gRPC, for services embedding go-metadata.
+type CatalogGRPCService struct {
+	v1.UnimplementedCatalogServiceServer
+
+	catalog *CatalogService
+	md      *metadataService.Service
+	log     *log.Helper
+}
+
+// NewCatalogGRPCService creates a new CatalogGRPCService sharing the sources
+// of the REST CatalogService.
+func NewCatalogGRPCService(catalog *CatalogService, md *metadataService.Service, logger log.Logger) *CatalogGRPCService {
+	return &CatalogGRPCService{
+		catalog: catalog,
+		md:      md,
+		log:     log.NewHelper(logger),
+	}
+}
+
+// ListSources lists the configured sources.
+func (s *CatalogGRPCService) ListSources(ctx context.Context, req *v1.ListSourcesRequest) (*v1.ListSourcesResponse, error) {
+	return &v1.ListSourcesResponse{Sources: s.md.Sources()}, nil
+}
+
+// ListCatalogs lists the catalogs of a source.
+func (s *CatalogGRPCService) ListCatalogs(ctx context.Context, req *v1.ListCatalogsRequest) (*v1.ListCatalogsResponse, error) {
+	catalogs, err := s.md.DiscoverCatalogs(ctx, req.Source)
+	if err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	out := make([]*v1.CatalogInfo, len(catalogs))
+	for i, c := range catalogs {
+		out[i] = &v1.CatalogInfo{
+			Catalog:     c.Catalog,
+			Type:        c.Type,
+			Description: c.Description,
+			Properties:  c.Properties,
+		}
+	}
+	return &v1.ListCatalogsResponse{Catalogs: out}, nil
+}
+
+// ListSchemas lists the schemas of a catalog.
+func (s *CatalogGRPCService) ListSchemas(ctx context.Context, req *v1.ListSchemasRequest) (*v1.ListSchemasResponse, error) {
+	schemas, err := s.md.ListSchemas(ctx, req.Source, req.Catalog)
+	if err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	return &v1.ListSchemasResponse{Schemas: schemas}, nil
+}
+
+// ListTables lists a page of the tables of a schema.
+func (s *CatalogGRPCService) ListTables(ctx context.Context, req *v1.ListTablesRequest) (*v1.ListTablesResponse, error) {
+	page, err := s.catalog.ListTables(ctx, req.Source, req.Catalog, req.Schema, PageRequest{
+		PageSize:  int(req.PageSize),
+		PageToken: req.PageToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &v1.ListTablesResponse{
+		Tables:        page.Tables,
+		NextPageToken: page.NextPageToken,
+		TotalCount:    int32(page.TotalCount),
+	}, nil
+}
+
+// StreamTables sends every table of a schema, page by page, optionally with
+// its metadata. A table whose metadata cannot be fetched is sent with the
+// error instead of failing the stream.
+func (s *CatalogGRPCService) StreamTables(req *v1.StreamTablesRequest, stream grpc.ServerStreamingServer[v1.TableEntry]) error {
+	ctx := stream.Context()
+	token := ""
+	for {
+		page, err := s.md.ListSourceTables(ctx, req.Source, req.Catalog, req.Schema, &collector.ListOptions{
+			PageSize:  streamTablesBatch,
+			PageToken: token,
+		})
+		if err != nil {
+			return toCatalogHTTPError(err)
+		}
+		for _, table := range page.Tables {
+			entry := &v1.TableEntry{Name: table}
+			if req.IncludeMetadata {
+				metadata, err := s.md.FetchTableMetadata(ctx, req.Source, req.Catalog, req.Schema, table)
+				if err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					entry.Error = err.Error()
+				} else {
+					entry.Metadata = toProtoTableMetadata(metadata)
+				}
+			}
+			if err := stream.Send(entry); err != nil {
+				return err
+			}
+		}
+		if page.NextPageToken == "" {
+			return nil
+		}
+		token = page.NextPageToken
+	}
+}
+
+// GetTableMetadata fetches the metadata of a table from its source.
+func (s *CatalogGRPCService) GetTableMetadata(ctx context.Context, req *v1.GetTableMetadataRequest) (*v1.TableMetadata, error) {
+	metadata, err := s.catalog.GetTable(ctx, req.Source, req.Catalog, req.Schema, req.Table)
+	if err != nil {
+		return nil, err
+	}
+	return toProtoTableMetadata(metadata), nil
+}
+
+// GetTableStatistics fetches the statistics of a table from its source.
+func (s *CatalogGRPCService) GetTableStatistics(ctx context.Context, req *v1.GetTableStatisticsRequest) (*v1.TableStatistics, error) {
+	stats, err := s.md.FetchTableStatistics(ctx, req.Source, req.Catalog, req.Schema, req.Table)
+	if err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	return toProtoTableStatistics(stats), nil
+}
+
+// ListPartitions fetches the partitions of a table from its source.
+func (s *CatalogGRPCService) ListPartitions(ctx context.Context, req *v1.ListPartitionsRequest) (*v1.ListPartitionsResponse, error) {
+	partitions, err := s.md.FetchPartitions(ctx, req.Source, req.Catalog, req.Schema, req.Table)
+	if err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	return &v1.ListPartitionsResponse{Partitions: toProtoPartitions(partitions)}, nil
+}
+
+// SyncSource starts synchronizing the metadata of a source in the background.
+func (s *CatalogGRPCService) SyncSource(ctx context.Context, req *v1.SyncSourceRequest) (*v1.SyncSourceResponse, error) {
+	if req.Source == "" {
+		return nil, errors.BadRequest("INVALID_REQUEST", "source is required")
+	}
+	out, err := s.catalog.Sync(ctx, req.Source, metadataService.SyncOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &v1.SyncSourceResponse{Source: out.Source, Status: out.Status}, nil
+}
+
+// toProtoTableMetadata converts collector TableMetadata to proto.
+func toProtoTableMetadata(t *collector.TableMetadata) *v1.TableMetadata {
+	if t == nil {
+		return nil
+	}
+	out := &v1.TableMetadata{
+		SourceCategory: string(t.SourceCategory),
+		SourceType:     t.SourceType,
+		Catalog:        t.Catalog,
+		Schema:         t.Schema,
+		Name:           t.Name,
+		Type:           string(t.Type),
+		Comment:        t.Comment,
+		Partitions:     toProtoPartitions(t.Partitions),
+		PrimaryKey:     t.PrimaryKey,
+		Properties:     t.Properties,
+		InferredSchema: t.InferredSchema,
+	}
+	if !t.LastRefreshedAt.IsZero() {
+		out.LastRefreshedAt = timestamppb.New(t.LastRefreshedAt)
+	}
+	for _, c := range t.Columns {
+		out.Columns = append(out.Columns, &v1.Column{
+			OrdinalPosition:   int32(c.OrdinalPosition),
+			Name:              c.Name,
+			Type:              c.Type,
+			SourceType:        c.SourceType,
+			Length:            toProtoInt32(c.Length),
+			Precision:         toProtoInt32(c.Precision),
+			Scale:             toProtoInt32(c.Scale),
+			Nullable:          c.Nullable,
+			DefaultValue:      c.Default,
+			Comment:           c.Comment,
+			IsPrimaryKey:      c.IsPrimaryKey,
+			IsPartitionColumn: c.IsPartitionColumn,
+			IsAutoIncrement:   c.IsAutoIncrement,
+		})
+	}
+	for _, idx := range t.Indexes {
+		out.Indexes = append(out.Indexes, &v1.Index{
+			Name:    idx.Name,
+			Columns: idx.Columns,
+			Unique:  idx.Unique,
+			Type:    idx.Type,
+			Comment: idx.Comment,
+		})
+	}
+	if t.Storage != nil {
+		out.Storage = &v1.StorageInfo{
+			Format:       t.Storage.Format,
+			Location:     t.Storage.Location,
+			InputFormat:  t.Storage.InputFormat,
+			OutputFormat: t.Storage.OutputFormat,
+			Serde:        t.Storage.SerDe,
+			Compressed:   t.Storage.Compressed,
+		}
+	}
+	return out
+}
+
+// toProtoPartitions converts collector PartitionInfo to proto.
+func toProtoPartitions(partitions []collector.PartitionInfo) []*v1.PartitionInfo {
+	out := make([]*v1.PartitionInfo, len(partitions))
+	for i, p := range partitions {
+		out[i] = &v1.PartitionInfo{
+			Name:        p.Name,
+			Type:        p.Type,
+			Columns:     p.Columns,
+			Expression:  p.Expression,
+			ValuesCount: int32(p.ValuesCount),
+		}
+	}
+	return out
+}
+
+// toProtoTableStatistics converts collector TableStatistics to proto. Min and
+// max values are formatted as strings.
+func toProtoTableStatistics(s *collector.TableStatistics) *v1.TableStatistics {
+	if s == nil {
+		return nil
+	}
+	out := &v1.TableStatistics{
+		RowCount:       s.RowCount,
+		DataSizeBytes:  s.DataSizeBytes,
+		PartitionCount: int32(s.PartitionCount),
+	}
+	if !s.CollectedAt.IsZero() {
+		out.CollectedAt = timestamppb.New(s.CollectedAt)
+	}
+	for _, c := range s.ColumnStats {
+		out.ColumnStats = append(out.ColumnStats, &v1.ColumnStatistics{
+			Name:          c.Name,
+			DistinctCount: c.DistinctCount,
+			NullCount:     c.NullCount,
+			Min:           toProtoValue(c.Min),
+			Max:           toProtoValue(c.Max),
+			Avg:           c.Avg,
+		})
+	}
+	return out
+}
+
+func toProtoInt32(n *int) *int32 {
+	if n == nil {
+		return nil
+	}
+	v := int32(*n)
+	return &v
+}
+
+func toProtoValue(v any) *string {
+	if v == nil {
+		return nil
+	}
+	s := fmt.Sprint(v)
+	return &s
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go-metadata/internal/collector"
+	lineageCore "go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/storage"
+	lineageService "go-metadata/internal/service/lineage"
+)
+
+// recordLineage records the lineage found in the metadata of a source:
+// storage links between warehouse tables and the object-store datasets at
+// their locations, and generated columns and view dependencies of RDBMS
+// tables, and the statements of their query logs if opted in. Syncing an
+// object-store source refreshes its datasets; syncing a warehouse source
+// records the links of its tables, so object-store sources should be synced
+// first.
+func (s *CatalogService) recordLineage(ctx context.Context, source string) error {
+	category, err := s.md.Category(source)
+	if err != nil {
+		return err
+	}
+	switch category {
+	case collector.CategoryObjectStorage:
+		datasets, err := s.collectDatasets(ctx, source)
+		if err != nil {
+			return err
+		}
+		s.storage.SetDatasets(source, datasets)
+		s.log.Infof("sync %s: collected %d storage datasets", source, len(datasets))
+	case collector.CategoryDataWarehouse:
+		tables, err := s.collectStorageTables(ctx, source)
+		if err != nil {
+			return err
+		}
+		links, err := s.lineage.RecordStorageLinks(ctx, s.storage, tables)
+		if err != nil {
+			return err
+		}
+		s.log.Infof("sync %s: linked %d of %d tables to storage datasets", source, len(links), len(tables))
+	case collector.CategoryRDBMS:
+		if err := s.recordViewDependencies(ctx, source); err != nil {
+			return err
+		}
+		if err := s.recordGeneratedColumns(ctx, source); err != nil {
+			return err
+		}
+		if s.md.QueryLogEnabled(source) {
+			return s.recordQueryLog(ctx, source)
+		}
+	}
+	return nil
+}
+
+// recordViewDependencies records the dependencies of the views of an RDBMS
+// source read from its system catalog. Sources that do not expose them are
+// skipped.
+func (s *CatalogService) recordViewDependencies(ctx context.Context, source string) error {
+	deps, err := s.md.FetchViewDependencies(ctx, source)
+	if collector.GetErrorCode(err) == collector.ErrCodeUnsupportedFeature {
+		s.log.Debugf("sync %s: view dependencies not collected: %v", source, err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	refs := make([]lineageCore.ViewDependency, len(deps))
+	for i, d := range deps {
+		refs[i] = lineageCore.ViewDependency{
+			View: lineageCore.ColumnRef{Database: d.Schema, Table: d.View},
+			Ref:  lineageCore.ColumnRef{Database: d.RefSchema, Table: d.RefTable},
+		}
+	}
+	jobs, err := s.lineage.RecordViewDependencies(ctx, refs)
+	if err != nil {
+		return err
+	}
+	s.log.Infof("sync %s: recorded dependencies of %d views", source, len(jobs))
+	return nil
+}
+
+// recordQueryLog records the lineage of the statements of the query log of
+// an RDBMS source executed since the previous sync, weighted by their
+// executions. Sources that do not expose a query log are skipped.
+func (s *CatalogService) recordQueryLog(ctx context.Context, source string) error {
+	entries, err := s.md.ReadQueryLog(ctx, source, time.Time{})
+	if collector.GetErrorCode(err) == collector.ErrCodeUnsupportedFeature {
+		s.log.Warnf("sync %s: query log not read: %v", source, err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	summary := s.lineage.RecordQueryLog(ctx, QueryLogStatements(entries, time.Now()))
+	s.log.Infof("sync %s: recorded %d query log statements (%d with lineage, %d executions), skipped %d",
+		source, summary.Statements, summary.WithLineage, summary.Executions, summary.Skipped)
+	return nil
+}
+
+// QueryLogStatements converts the entries of a query log to the statements
+// recorded by the lineage service, executed at readAt if the log does not
+// record when.
+func QueryLogStatements(entries []collector.QueryLogEntry, readAt time.Time) []lineageService.QueryLogStatement {
+	statements := make([]lineageService.QueryLogStatement, len(entries))
+	for i, e := range entries {
+		statements[i] = lineageService.QueryLogStatement{SQL: e.SQL, Executions: e.Calls, ExecutedAt: e.LastExecuted}
+		if e.LastExecuted.IsZero() {
+			statements[i].ExecutedAt = readAt
+		}
+	}
+	return statements
+}
+
+// recordGeneratedColumns records the lineage of the generated columns of the
+// tables of an RDBMS source from their base columns.
+func (s *CatalogService) recordGeneratedColumns(ctx context.Context, source string) error {
+	return s.md.WalkTables(ctx, source, func(catalog, schema, table string) error {
+		metadata, err := s.md.FetchTableMetadata(ctx, source, catalog, schema, table)
+		if err != nil {
+			s.log.Warnf("sync %s: fetch %s.%s: %v", source, schema, table, err)
+			return nil
+		}
+		var generated []lineageCore.GeneratedColumn
+		for _, col := range metadata.Columns {
+			if col.Generated != nil {
+				generated = append(generated, lineageCore.GeneratedColumn{Name: col.Name, Expression: col.Generated.Expression})
+			}
+		}
+		if len(generated) == 0 {
+			return nil
+		}
+		result := s.lineage.RecordGeneratedColumns(ctx, schema, table, generated)
+		for _, u := range result.Unresolved {
+			s.log.Warnf("sync %s: generated column %s.%s.%s: %s", source, schema, table, u.Column, u.Reason)
+		}
+		return nil
+	})
+}
+
+// collectDatasets lists the bucket prefixes of an object-store source along
+// with the fields inferred from their files.
+func (s *CatalogService) collectDatasets(ctx context.Context, source string) ([]storage.Dataset, error) {
+	var datasets []storage.Dataset
+	err := s.md.WalkTables(ctx, source, func(catalog, schema, table string) error {
+		ds := storage.Dataset{Bucket: schema, Prefix: table}
+		metadata, err := s.md.FetchTableMetadata(ctx, source, catalog, schema, table)
+		if err != nil {
+			s.log.Warnf("sync %s: fetch %s/%s: %v", source, schema, table, err)
+		} else if metadata.InferredSchema {
+			ds.Columns = columnNames(metadata.Columns)
+		}
+		datasets = append(datasets, ds)
+		return nil
+	})
+	return datasets, err
+}
+
+// collectStorageTables lists the tables of a warehouse source that have a
+// storage location.
+func (s *CatalogService) collectStorageTables(ctx context.Context, source string) ([]storage.Table, error) {
+	var tables []storage.Table
+	err := s.md.WalkTables(ctx, source, func(catalog, schema, table string) error {
+		metadata, err := s.md.FetchTableMetadata(ctx, source, catalog, schema, table)
+		if err != nil {
+			s.log.Warnf("sync %s: fetch %s.%s: %v", source, schema, table, err)
+			return nil
+		}
+		if metadata.Storage == nil || metadata.Storage.Location == "" {
+			return nil
+		}
+		tables = append(tables, storage.Table{
+			Database: schema,
+			Table:    table,
+			Location: metadata.Storage.Location,
+			Format:   metadata.Storage.Format,
+			Columns:  columnNames(metadata.Columns),
+		})
+		return nil
+	})
+	return tables, err
+}
+
+func columnNames(columns []collector.Column) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return names
+}
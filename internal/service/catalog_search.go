@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"go-metadata/internal/search"
+	metadataService "go-metadata/internal/service/metadata"
+	"go-metadata/internal/store"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// maxSearchLimit bounds the hits of a search.
+const maxSearchLimit = 100
+
+// Search searches the names, comments and tags of the stored tables and
+// columns. The index is built from the store on the first search.
+func (s *CatalogService) Search(ctx context.Context, q search.Query) (*search.Result, error) {
+	if strings.TrimSpace(q.Text) == "" {
+		return nil, errors.BadRequest("INVALID_REQUEST", "q must not be empty")
+	}
+	ix, err := s.searchIndex(ctx)
+	if err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	return ix.Search(q), nil
+}
+
+// searchIndex returns the search index, building it on first use.
+func (s *CatalogService) searchIndex(ctx context.Context) (*search.Index, error) {
+	s.searchMu.Lock()
+	defer s.searchMu.Unlock()
+	if s.search != nil {
+		return s.search, nil
+	}
+	st := s.md.Store()
+	if st == nil {
+		return nil, metadataService.ErrNoStore
+	}
+	docs, err := search.Load(ctx, st, "")
+	if err != nil {
+		return nil, err
+	}
+	s.tagDocuments(ctx, docs)
+	ix := search.NewIndex()
+	ix.Add(docs...)
+	s.search = ix
+	s.log.Infof("search index built with %d tables", ix.Len())
+	return ix, nil
+}
+
+// reindex refreshes the tables of a synced source in the search index, if
+// it was built.
+func (s *CatalogService) reindex(ctx context.Context, source string) error {
+	s.searchMu.Lock()
+	ix := s.search
+	s.searchMu.Unlock()
+	st := s.md.Store()
+	if ix == nil || st == nil {
+		return nil
+	}
+	docs, err := search.Load(ctx, st, source)
+	if err != nil {
+		return err
+	}
+	s.tagDocuments(ctx, docs)
+	ix.ReplaceSource(source, docs)
+	return nil
+}
+
+// unindex removes a deleted table from the search index, if it was built.
+func (s *CatalogService) unindex(key store.TableKey) {
+	s.searchMu.Lock()
+	ix := s.search
+	s.searchMu.Unlock()
+	if ix != nil {
+		ix.Remove(key)
+	}
+}
+
+// tagDocuments adds the tags curated on tables and columns to the documents
+// of the same tables: of the same source and name, in a database or schema
+// named as their schema or catalog. Tags that cannot be read are skipped.
+func (s *CatalogService) tagDocuments(ctx context.Context, docs []*search.Document) {
+	if s.tables == nil {
+		return
+	}
+	tables, err := s.tables.List(ctx)
+	if err != nil {
+		s.log.Warnf("search index built without curated tags: %v", err)
+		return
+	}
+	curated := make(map[string]int, 2*len(tables))
+	for i, t := range tables {
+		for _, namespace := range []string{t.Schema, t.Database} {
+			if namespace != "" {
+				curated[tagKey(t.Source, namespace, t.Name)] = i
+			}
+		}
+	}
+	for _, d := range docs {
+		i, ok := curated[tagKey(d.Source, d.Schema, d.Table)]
+		if !ok {
+			if i, ok = curated[tagKey(d.Source, d.Catalog, d.Table)]; !ok {
+				continue
+			}
+		}
+		t := tables[i]
+		d.Tags = append(d.Tags, t.Tags...)
+		columns := make(map[string][]string, len(t.Columns))
+		for _, c := range t.Columns {
+			if len(c.Tags) > 0 {
+				columns[strings.ToLower(c.Name)] = c.Tags
+			}
+		}
+		for j := range d.Columns {
+			d.Columns[j].Tags = append(d.Columns[j].Tags, columns[strings.ToLower(d.Columns[j].Name)]...)
+		}
+	}
+}
+
+func tagKey(source, namespace, table string) string {
+	return strings.ToLower(source + "\x00" + namespace + "\x00" + table)
+}
+
+func (s *CatalogService) searchTables(ctx http.Context) error {
+	query := ctx.Query()
+	in := search.Query{
+		Text:        query.Get("q"),
+		SourceTypes: listParam(query["source_type"]),
+		Sources:     listParam(query["source"]),
+	}
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxSearchLimit {
+			return errors.BadRequest("INVALID_REQUEST", "limit must be an integer in [1, 100]")
+		}
+		in.Limit = n
+	}
+	h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+		return s.Search(c, *req.(*search.Query))
+	})
+	out, err := h(ctx, &in)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+// listParam reads a query parameter given repeatedly or comma-separated.
+func listParam(values []string) []string {
+	var list []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
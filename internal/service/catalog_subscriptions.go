@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+
+	"go-metadata/internal/store"
+
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// SubscribeRequest subscribes the current user to the events of tables of a
+// source: Table is a pattern such as shop.orders or shop.*, empty for every
+// table; Events are the event types, empty for all.
+type SubscribeRequest struct {
+	Source string   `json:"source"`
+	Table  string   `json:"table"`
+	Events []string `json:"events"`
+}
+
+// SetChannelRequest sets the webhook of a channel of the current user.
+type SetChannelRequest struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+// SubscriptionsResponse lists the subscriptions of a user and the channels
+// they are notified in.
+type SubscriptionsResponse struct {
+	User          string               `json:"user"`
+	Subscriptions []store.Subscription `json:"subscriptions"`
+	Channels      []store.UserChannel  `json:"channels"`
+}
+
+// Subscriptions returns the subscriptions and channels of the user in ctx.
+func (s *CatalogService) Subscriptions(ctx context.Context) (*SubscriptionsResponse, error) {
+	user := currentUser(ctx)
+	subs, err := s.md.Subscriptions(ctx, user)
+	if err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	channels, err := s.md.UserChannels(ctx, user)
+	if err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	if subs == nil {
+		subs = []store.Subscription{}
+	}
+	if channels == nil {
+		channels = []store.UserChannel{}
+	}
+	return &SubscriptionsResponse{User: user, Subscriptions: subs, Channels: channels}, nil
+}
+
+// Subscribe subscribes the user in ctx to the events of tables of a source.
+func (s *CatalogService) Subscribe(ctx context.Context, req *SubscribeRequest) (*SubscriptionsResponse, error) {
+	sub := &store.Subscription{User: currentUser(ctx), Source: req.Source, Table: req.Table, Events: req.Events}
+	if err := s.md.Subscribe(ctx, sub); err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	return s.Subscriptions(ctx)
+}
+
+// Unsubscribe deletes a subscription of the user in ctx.
+func (s *CatalogService) Unsubscribe(ctx context.Context, source, table string) (*SubscriptionsResponse, error) {
+	if err := s.md.Unsubscribe(ctx, currentUser(ctx), source, table); err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	return s.Subscriptions(ctx)
+}
+
+// SetChannel sets the channel of a type the user in ctx is notified in.
+func (s *CatalogService) SetChannel(ctx context.Context, channelType string, req *SetChannelRequest) (*SubscriptionsResponse, error) {
+	c := &store.UserChannel{User: currentUser(ctx), Type: channelType, WebhookURL: req.WebhookURL}
+	if err := s.md.SetUserChannel(ctx, c); err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	return s.Subscriptions(ctx)
+}
+
+// DeleteChannel deletes the channel of a type of the user in ctx.
+func (s *CatalogService) DeleteChannel(ctx context.Context, channelType string) (*SubscriptionsResponse, error) {
+	if err := s.md.DeleteUserChannel(ctx, currentUser(ctx), channelType); err != nil {
+		return nil, toCatalogHTTPError(err)
+	}
+	return s.Subscriptions(ctx)
+}
+
+func (s *CatalogService) listSubscriptions(ctx http.Context) error {
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.Subscriptions(c)
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *CatalogService) subscribe(ctx http.Context) error {
+	var in SubscribeRequest
+	if err := ctx.Bind(&in); err != nil {
+		return err
+	}
+	h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+		return s.Subscribe(c, req.(*SubscribeRequest))
+	})
+	out, err := h(ctx, &in)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *CatalogService) unsubscribe(ctx http.Context) error {
+	query := ctx.Query()
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.Unsubscribe(c, query.Get("source"), query.Get("table"))
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *CatalogService) setChannel(ctx http.Context) error {
+	var in SetChannelRequest
+	if err := ctx.Bind(&in); err != nil {
+		return err
+	}
+	channelType := ctx.Vars().Get("type")
+	h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+		return s.SetChannel(c, channelType, req.(*SetChannelRequest))
+	})
+	out, err := h(ctx, &in)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *CatalogService) deleteChannel(ctx http.Context) error {
+	channelType := ctx.Vars().Get("type")
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.DeleteChannel(c, channelType)
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/collectortest"
+	lineageCore "go-metadata/internal/lineage"
+	lineageService "go-metadata/internal/service/lineage"
+	metadataService "go-metadata/internal/service/metadata"
+	"go-metadata/internal/store"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// deletingStore records the tables deleted from it. The other methods of
+// store.Repository are not implemented.
+type deletingStore struct {
+	store.Repository
+	deleted []store.TableKey
+}
+
+func (s *deletingStore) DeleteTable(ctx context.Context, key store.TableKey) error {
+	s.deleted = append(s.deleted, key)
+	return nil
+}
+
+// testAPI serves the catalog and lineage routes of a mysql_prod source with
+// the tables def.shop.orders, def.shop.customers and def.shop.items.
+type testAPI struct {
+	srv     *http.Server
+	c       *collectortest.Collector
+	md      *metadataService.Service
+	lineage *lineageService.Service
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	c := collectortest.New(collector.CategoryRDBMS, "mysql")
+	for _, name := range []string{"orders", "customers", "items"} {
+		c.AddTable(&collector.TableMetadata{Catalog: "def", Schema: "shop", Name: name, Type: collector.TableTypeTable,
+			Columns: []collector.Column{{OrdinalPosition: 1, Name: "id", Type: "bigint"}}})
+	}
+	c.AddSchema("def", "dw")
+	md := metadataService.NewService(nil)
+	md.RegisterCollector("mysql_prod", c)
+	lineage := lineageService.NewService(lineageCore.NewAnalyzer(nil), nil)
+	logger := log.NewStdLogger(io.Discard)
+
+	srv := http.NewServer()
+	NewCatalogService(md, lineage, nil, logger).RegisterHTTP(srv)
+	RegisterLineageHTTP(srv, lineage)
+	return &testAPI{srv: srv, c: c, md: md, lineage: lineage}
+}
+
+// do serves a request and decodes the JSON response into out, if not nil.
+func (a *testAPI) do(t *testing.T, method, path, body string, out any) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	a.srv.ServeHTTP(rec, req)
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decode %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+// apiError is the body of an error response.
+type apiError struct {
+	Code     int               `json:"code"`
+	Reason   string            `json:"reason"`
+	Message  string            `json:"message"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// expectError checks that a request fails with status and reason.
+func (a *testAPI) expectError(t *testing.T, method, path, body string, status int, reason string) apiError {
+	t.Helper()
+	var e apiError
+	if code := a.do(t, method, path, body, &e); code != status || e.Reason != reason {
+		t.Errorf("%s %s: expected %d %s, got %d %+v", method, path, status, reason, code, e)
+	}
+	return e
+}
+
+func TestCatalogBrowse(t *testing.T) {
+	a := newTestAPI(t)
+
+	var sources ListSourcesResponse
+	if code := a.do(t, "GET", "/api/v1/sources", "", &sources); code != 200 || len(sources.Sources) != 1 || sources.Sources[0] != "mysql_prod" {
+		t.Errorf("Expected the mysql_prod source, got %d %+v", code, sources)
+	}
+
+	var schemas ListSchemasResponse
+	if code := a.do(t, "GET", "/api/v1/sources/mysql_prod/catalogs/def/schemas?page_size=1", "", &schemas); code != 200 {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if len(schemas.Schemas) != 1 || schemas.TotalCount != 2 || schemas.NextPageToken != "1" {
+		t.Errorf("Expected the first of 2 schemas, got %+v", schemas)
+	}
+	schemas = ListSchemasResponse{}
+	a.do(t, "GET", "/api/v1/sources/mysql_prod/catalogs/def/schemas?page_size=1&page_token=1", "", &schemas)
+	if len(schemas.Schemas) != 1 || schemas.NextPageToken != "" {
+		t.Errorf("Expected the last page, got %+v", schemas)
+	}
+
+	var tables ListTablesResponse
+	if code := a.do(t, "GET", "/api/v1/sources/mysql_prod/catalogs/def/schemas/shop/tables?page_size=2", "", &tables); code != 200 {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if len(tables.Tables) != 2 || tables.TotalCount != 3 || tables.NextPageToken == "" {
+		t.Errorf("Expected 2 of 3 tables, got %+v", tables)
+	}
+
+	var table collector.TableMetadata
+	if code := a.do(t, "GET", "/api/v1/sources/mysql_prod/catalogs/def/schemas/shop/tables/orders", "", &table); code != 200 || table.Name != "orders" || len(table.Columns) != 1 {
+		t.Errorf("Expected the orders table, got %d %+v", code, table)
+	}
+}
+
+func TestCatalogErrors(t *testing.T) {
+	a := newTestAPI(t)
+
+	a.expectError(t, "GET", "/api/v1/sources/oracle_prod/catalogs", "", 404, "SOURCE_NOT_FOUND")
+	a.expectError(t, "GET", "/api/v1/sources/mysql_prod/catalogs/def/schemas/shop/tables/missing", "", 404, "NOT_FOUND")
+	a.expectError(t, "POST", "/api/v1/sources/oracle_prod/sync", "", 404, "SOURCE_NOT_FOUND")
+
+	// Validation
+	a.expectError(t, "GET", "/api/v1/sources/mysql_prod/catalogs?page_size=-1", "", 400, "INVALID_REQUEST")
+	a.expectError(t, "GET", "/api/v1/sources/mysql_prod/catalogs?page_size=ten", "", 400, "INVALID_REQUEST")
+	a.expectError(t, "GET", "/api/v1/sources/mysql_prod/catalogs?page_token=abc", "", 400, "INVALID_REQUEST")
+	a.expectError(t, "GET", "/api/v1/sources/mysql_prod/catalogs/def/schemas/shop/tables?page_token=-5", "", 400, "INVALID_REQUEST")
+
+	// Without a store, cached reads and deletions are unavailable
+	a.expectError(t, "GET", "/api/v1/sources/mysql_prod/catalogs/def/schemas/shop/tables/orders?cached=true", "", 503, "STORE_NOT_CONFIGURED")
+	a.expectError(t, "DELETE", "/api/v1/sources/mysql_prod/catalogs/def/schemas/shop/tables/orders", "", 503, "STORE_NOT_CONFIGURED")
+
+	// Collector errors
+	a.c.SetError(collectortest.OpListSchemas, collector.NewTimeoutError("mysql", "list_schemas", nil))
+	a.expectError(t, "GET", "/api/v1/sources/mysql_prod/catalogs/def/schemas", "", 504, "SOURCE_TIMEOUT")
+	a.c.SetError(collectortest.OpDiscoverCatalogs, collector.NewNetworkError("mysql", "discover_catalogs", nil))
+	a.expectError(t, "GET", "/api/v1/sources/mysql_prod/catalogs", "", 503, "SOURCE_UNAVAILABLE")
+
+	if code := a.do(t, "GET", "/api/v1/nothing", "", nil); code != 404 {
+		t.Errorf("Expected 404 for an unknown route, got %d", code)
+	}
+}
+
+func TestCatalogDeleteTable(t *testing.T) {
+	a := newTestAPI(t)
+	st := &deletingStore{}
+	a.md.SetStore(st)
+	// dw.daily is loaded from shop.orders
+	a.lineage.MergedGraph().Add(&lineageCore.LineageResult{
+		Columns: []lineageCore.ColumnLineage{{
+			Target:  lineageCore.ColumnRef{Database: "dw", Table: "daily", Column: "id"},
+			Sources: []lineageCore.ColumnRef{{Database: "shop", Table: "orders", Column: "id"}},
+		}},
+	}, "q1", time.Now())
+
+	path := "/api/v1/sources/mysql_prod/catalogs/def/schemas/shop/tables/orders"
+	e := a.expectError(t, "DELETE", path, "", 409, "HAS_DEPENDENTS")
+	if e.Metadata["dependents"] != "dw.daily" {
+		t.Errorf("Expected dw.daily to be listed as a dependent, got %+v", e.Metadata)
+	}
+	if len(st.deleted) != 0 {
+		t.Fatalf("Expected a table with dependents to be kept, got %v", st.deleted)
+	}
+
+	var deleted DeleteTableResponse
+	if code := a.do(t, "DELETE", path+"?force=true", "", &deleted); code != 200 || deleted.Status != "deleted" || deleted.Table != "mysql_prod:def.shop.orders" {
+		t.Errorf("Expected the table to be deleted with force, got %d %+v", code, deleted)
+	}
+	if len(st.deleted) != 1 {
+		t.Errorf("Expected 1 table deleted, got %v", st.deleted)
+	}
+}
+
+func TestCatalogSyncAccepted(t *testing.T) {
+	a := newTestAPI(t)
+
+	// Without a store the sync does nothing, in the background
+	var out SyncResponse
+	if code := a.do(t, "POST", "/api/v1/sources/mysql_prod/sync?incremental=true", "", &out); code != 202 || out.Status != "accepted" {
+		t.Errorf("Expected the sync to be accepted, got %d %+v", code, out)
+	}
+}
+
+func TestLineageRoutes(t *testing.T) {
+	a := newTestAPI(t)
+
+	var result lineageCore.LineageResult
+	if code := a.do(t, "POST", "/api/v1/lineage/analyze", `{"sql": "INSERT INTO daily SELECT id FROM orders"}`, &result); code != 200 {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if len(result.Columns) != 1 || result.Columns[0].Target.Table != "daily" {
+		t.Errorf("Expected the lineage of daily.id, got %+v", result.Columns)
+	}
+	// Analyzing does not record
+	var up TraverseResponse
+	a.do(t, "GET", "/api/v1/lineage/upstream?node=daily", "", &up)
+	if len(up.Edges) != 0 {
+		t.Errorf("Expected analyze not to record lineage, got %+v", up.Edges)
+	}
+
+	if _, err := a.lineage.RecordSQL(context.Background(), "INSERT INTO daily SELECT id FROM orders"); err != nil {
+		t.Fatal(err)
+	}
+	up = TraverseResponse{}
+	if code := a.do(t, "GET", "/api/v1/lineage/upstream?node=daily&depth=1", "", &up); code != 200 || len(up.Tables) != 1 || up.Tables[0] != "orders" {
+		t.Errorf("Expected orders upstream of daily, got %d %+v", code, up)
+	}
+	var down TraverseResponse
+	if code := a.do(t, "GET", "/api/v1/lineage/downstream?node=orders", "", &down); code != 200 || down.Direction != "downstream" || len(down.Tables) != 1 {
+		t.Errorf("Expected daily downstream of orders, got %d %+v", code, down)
+	}
+
+	a.expectError(t, "POST", "/api/v1/lineage/analyze", `{"sql": "  "}`, 400, "INVALID_REQUEST")
+	a.expectError(t, "POST", "/api/v1/lineage/explain", `{}`, 400, "INVALID_REQUEST")
+	a.expectError(t, "GET", "/api/v1/lineage/upstream", "", 400, "INVALID_REQUEST")
+	a.expectError(t, "GET", "/api/v1/lineage/impact?node=dw.daily&depth=-1", "", 400, "INVALID_REQUEST")
+	if code := a.do(t, "POST", "/api/v1/lineage/analyze", `{"sql":`, nil); code != nethttp.StatusBadRequest {
+		t.Errorf("Expected 400 for a malformed body, got %d", code)
+	}
+}
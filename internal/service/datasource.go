@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
+	stderrors "errors"
 
 	v1 "go-metadata/api/metadata/v1"
 	"go-metadata/internal/biz"
 
+	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport/http"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
@@ -173,3 +176,31 @@ func (s *DataSourceService) BatchExportDataSources(ctx context.Context, req *v1.
 	// TODO: implement batch export
 	return &v1.BatchExportResponse{}, nil
 }
+
+// RegisterHTTP registers the hand-written HTTP routes of the data source
+// service, which are not part of the generated API.
+func (s *DataSourceService) RegisterHTTP(srv *http.Server) {
+	r := srv.Route("/")
+	r.POST("/api/v1/datasources/secrets/migrate", s.migrateSecrets)
+}
+
+// MigrateSecrets encrypts the stored credentials that are still in plaintext
+// or encrypted with a rotated-out key with the primary key.
+func (s *DataSourceService) MigrateSecrets(ctx context.Context) (*biz.SecretMigrationResult, error) {
+	result, err := s.uc.MigrateSecrets(ctx)
+	if stderrors.Is(err, biz.ErrEncryptionNotConfigured) {
+		return nil, errors.BadRequest("ENCRYPTION_NOT_CONFIGURED", err.Error())
+	}
+	return result, err
+}
+
+func (s *DataSourceService) migrateSecrets(ctx http.Context) error {
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.MigrateSecrets(c)
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-metadata/internal/data/graph"
+	lineageCore "go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/hook"
+	lineageMetadata "go-metadata/internal/lineage/metadata"
+	lineageService "go-metadata/internal/service/lineage"
+	metadataService "go-metadata/internal/service/metadata"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// NewLineageService creates the lineage service backed by an in-process
+// lineage graph, persisted to graphDB if it is not nil. SQL is resolved
+// against the tables harvested into the store of md, if it has one, so that
+// SELECT * and unqualified columns of synced tables get column lineage.
+// Snowflake COPY INTO loads from stages are analyzed too.
+func NewLineageService(graphDB graph.GraphDB, md *metadataService.Service) *lineageService.Service {
+	var catalog lineageCore.Catalog
+	if st := md.Store(); st != nil {
+		catalog = lineageMetadata.NewStoreCatalog(st)
+	}
+	analyzer := lineageCore.NewAnalyzer(catalog)
+	analyzer.RegisterSnowflake(nil)
+	return lineageService.NewService(analyzer, graphDB)
+}
+
+// AnalyzeRequest is the body of a SQL lineage analysis.
+type AnalyzeRequest struct {
+	SQL string `json:"sql"`
+}
+
+// TraverseResponse lists the lineage upstream or downstream of a table or
+// column.
+type TraverseResponse struct {
+	Node      string `json:"node"`
+	Direction string `json:"direction"`
+	// Tables are the sorted qualified names of the tables reached.
+	Tables []string            `json:"tables"`
+	Edges  []*lineageCore.Edge `json:"edges"`
+}
+
+// EventResponse is the outcome of a lineage event. Events of failed queries
+// are not recorded.
+type EventResponse struct {
+	Recorded bool             `json:"recorded"`
+	Job      *lineageCore.Job `json:"job,omitempty"`
+}
+
+// CyclesResponse lists the groups of tables that depend on each other.
+type CyclesResponse struct {
+	Cycles [][]string `json:"cycles"`
+}
+
+// RegisterLineageHTTP registers the routes of the lineage service on srv:
+//
+//	POST /api/v1/lineage/analyze
+//	POST /api/v1/lineage/explain
+//	POST /api/v1/lineage/events
+//	GET  /api/v1/lineage/upstream?node=[&depth=]
+//	GET  /api/v1/lineage/downstream?node=[&depth=]
+//	GET  /api/v1/lineage/impact?node=[&depth=]
+//	GET  /api/v1/lineage/cycles
+func RegisterLineageHTTP(srv *http.Server, svc *lineageService.Service) {
+	r := srv.Route("/")
+	for _, direction := range []lineageCore.Direction{lineageCore.Upstream, lineageCore.Downstream} {
+		r.GET("/api/v1/lineage/"+string(direction), func(ctx http.Context) error {
+			node, depth, err := lineageQuery(ctx)
+			if err != nil {
+				return err
+			}
+			h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+				return traverse(c, svc, node, direction, depth), nil
+			})
+			out, err := h(ctx, nil)
+			if err != nil {
+				return err
+			}
+			return ctx.Result(200, out)
+		})
+	}
+	r.GET("/api/v1/lineage/impact", func(ctx http.Context) error {
+		node, depth, err := lineageQuery(ctx)
+		if err != nil {
+			return err
+		}
+		h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+			return svc.Impact(c, node, depth), nil
+		})
+		out, err := h(ctx, nil)
+		if err != nil {
+			return err
+		}
+		return ctx.Result(200, out)
+	})
+	r.GET("/api/v1/lineage/cycles", func(ctx http.Context) error {
+		h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+			return &CyclesResponse{Cycles: svc.Cycles(c)}, nil
+		})
+		out, err := h(ctx, nil)
+		if err != nil {
+			return err
+		}
+		return ctx.Result(200, out)
+	})
+	r.POST("/api/v1/lineage/analyze", func(ctx http.Context) error {
+		var in AnalyzeRequest
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+			return analyzeSQL(c, svc, req.(*AnalyzeRequest))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		return ctx.Result(200, out)
+	})
+	r.POST("/api/v1/lineage/explain", func(ctx http.Context) error {
+		var in AnalyzeRequest
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+			return explainSQL(c, svc, req.(*AnalyzeRequest))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		return ctx.Result(200, out)
+	})
+	r.POST("/api/v1/lineage/events", func(ctx http.Context) error {
+		var in hook.Event
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+			return recordEvent(c, svc, req.(*hook.Event))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		return ctx.Result(200, out)
+	})
+}
+
+// analyzeSQL extracts the column lineage of a SQL statement without
+// recording it.
+func analyzeSQL(ctx context.Context, svc *lineageService.Service, req *AnalyzeRequest) (*lineageCore.LineageResult, error) {
+	if strings.TrimSpace(req.SQL) == "" {
+		return nil, errors.BadRequest("INVALID_REQUEST", "sql is required")
+	}
+	result, err := svc.AnalyzeSQL(ctx, req.SQL)
+	if err != nil {
+		return nil, errors.BadRequest("INVALID_SQL", err.Error())
+	}
+	if result == nil {
+		result = &lineageCore.LineageResult{}
+	}
+	return result, nil
+}
+
+// explainSQL returns the parse tree of a SQL statement. Syntax errors are
+// part of the tree rather than failing the request.
+func explainSQL(ctx context.Context, svc *lineageService.Service, req *AnalyzeRequest) (*lineageCore.ParseTree, error) {
+	if strings.TrimSpace(req.SQL) == "" {
+		return nil, errors.BadRequest("INVALID_REQUEST", "sql is required")
+	}
+	tree, err := svc.ExplainSQL(ctx, req.SQL)
+	if err != nil {
+		return nil, errors.BadRequest("INVALID_SQL", err.Error())
+	}
+	if tree == nil {
+		tree = &lineageCore.ParseTree{SQL: req.SQL}
+	}
+	return tree, nil
+}
+
+// recordEvent merges the lineage event of a Hive hook or Spark listener into
+// the lineage graph.
+func recordEvent(ctx context.Context, svc *lineageService.Service, event *hook.Event) (*EventResponse, error) {
+	job, err := svc.RecordHookEvent(ctx, event)
+	if errors.Is(err, hook.ErrInvalidEvent) {
+		return nil, errors.BadRequest("INVALID_EVENT", err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &EventResponse{Recorded: job != nil, Job: job}, nil
+}
+
+// lineageQuery reads the node and depth of a traversal from the query.
+// depth defaults to 0, i.e. unlimited.
+func lineageQuery(ctx http.Context) (string, int, error) {
+	query := ctx.Query()
+	node := strings.TrimSpace(query.Get("node"))
+	if node == "" {
+		return "", 0, errors.BadRequest("INVALID_REQUEST", "node is required, e.g. dw.orders or dw.orders.amount")
+	}
+	depth := 0
+	if v := query.Get("depth"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return "", 0, errors.BadRequest("INVALID_REQUEST", "depth must be a non-negative integer")
+		}
+		depth = n
+	}
+	return node, depth, nil
+}
+
+// traverse returns the lineage of node in direction and the tables it
+// reaches.
+func traverse(ctx context.Context, svc *lineageService.Service, node string, direction lineageCore.Direction, depth int) *TraverseResponse {
+	edges := svc.Traverse(ctx, node, direction, depth)
+	seen := make(map[string]bool)
+	tables := make([]string, 0)
+	for _, edge := range edges {
+		ref := edge.Target
+		if direction == lineageCore.Upstream {
+			ref = edge.Source
+		}
+		if name := ref.TableName(); name != node && !seen[name] {
+			seen[name] = true
+			tables = append(tables, name)
+		}
+	}
+	sort.Strings(tables)
+	return &TraverseResponse{Node: node, Direction: string(direction), Tables: tables, Edges: edges}
+}
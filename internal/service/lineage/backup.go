@@ -0,0 +1,234 @@
+package lineage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"go-metadata/internal/lineage/metadata"
+	"go-metadata/internal/lineage/snapshot"
+)
+
+const (
+	backupPrefix     = "backup-"
+	backupSuffix     = ".tar"
+	backupTimeLayout = "20060102T150405Z"
+)
+
+// BackupConfig configures scheduled backups.
+type BackupConfig struct {
+	// Dir is the directory backups are written to.
+	Dir string `yaml:"dir"`
+	// Interval is how often a backup is taken.
+	Interval time.Duration `yaml:"interval"`
+	// Keep is the number of most recent backups retained; 0 keeps all.
+	Keep int `yaml:"keep"`
+	// Origin names this deployment in the backup manifests.
+	Origin string `yaml:"origin"`
+}
+
+// DefaultBackupConfig returns the default backup configuration: a daily
+// backup to ./backups, keeping the last 7.
+func DefaultBackupConfig() *BackupConfig {
+	return &BackupConfig{
+		Dir:      "./backups",
+		Interval: 24 * time.Hour,
+		Keep:     7,
+	}
+}
+
+// Backuper periodically writes the catalog and the merged lineage graph to
+// snapshot files and removes backups beyond the retention count.
+type Backuper struct {
+	service *Service
+	catalog *metadata.MemoryProvider
+	config  *BackupConfig
+	log     *log.Helper
+
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running bool
+}
+
+// NewBackuper creates a backuper for the service's merged lineage graph and
+// the tables of catalog, which may be nil to back up lineage only.
+func (s *Service) NewBackuper(config *BackupConfig, catalog *metadata.MemoryProvider, logger log.Logger) *Backuper {
+	if config == nil {
+		config = DefaultBackupConfig()
+	}
+	return &Backuper{
+		service: s,
+		catalog: catalog,
+		config:  config,
+		log:     log.NewHelper(logger),
+	}
+}
+
+// Start starts the backup loop.
+func (b *Backuper) Start(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.running {
+		return nil
+	}
+
+	ctx, b.cancel = context.WithCancel(ctx)
+	b.running = true
+
+	b.wg.Add(1)
+	go b.run(ctx)
+
+	b.log.Infof("Lineage backups started: dir=%s interval=%s keep=%d", b.config.Dir, b.config.Interval, b.config.Keep)
+	return nil
+}
+
+// Stop stops the backup loop and waits for an in-flight backup to finish.
+func (b *Backuper) Stop() error {
+	b.mu.Lock()
+	if !b.running {
+		b.mu.Unlock()
+		return nil
+	}
+	b.cancel()
+	b.running = false
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	b.log.Info("Lineage backups stopped")
+	return nil
+}
+
+func (b *Backuper) run(ctx context.Context) {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := b.RunOnce(ctx, time.Now()); err != nil {
+				b.log.Errorf("Lineage backup failed: %v", err)
+			}
+		}
+	}
+}
+
+// RunOnce writes a backup taken at now and applies the retention count. It
+// returns the path of the new backup.
+func (b *Backuper) RunOnce(ctx context.Context, now time.Time) (string, error) {
+	now = now.UTC()
+	path, err := b.write(ctx, now)
+	if err != nil {
+		return "", err
+	}
+	b.log.Infof("Lineage backup written to %s", path)
+
+	if b.config.Keep > 0 {
+		backups, err := ListBackups(b.config.Dir)
+		if err != nil {
+			return path, err
+		}
+		for _, old := range backups[:max(0, len(backups)-b.config.Keep)] {
+			if err := os.Remove(old); err != nil {
+				return path, fmt.Errorf("remove old backup: %w", err)
+			}
+		}
+	}
+	return path, nil
+}
+
+// write writes the backup to a temporary file renamed into place once
+// complete, so a partially written backup is never picked up by a restore.
+func (b *Backuper) write(ctx context.Context, now time.Time) (string, error) {
+	if err := os.MkdirAll(b.config.Dir, 0755); err != nil {
+		return "", fmt.Errorf("create backup dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(b.config.Dir, ".backup-*")
+	if err != nil {
+		return "", fmt.Errorf("create backup: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	var tables []*metadata.TableSchema
+	if b.catalog != nil {
+		tables = b.catalog.AllTables()
+	}
+	_, err = b.service.ExportSnapshot(ctx, tmp, tables, snapshot.Options{Origin: b.config.Origin, CreatedAt: now})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("write backup: %w", err)
+	}
+
+	path := filepath.Join(b.config.Dir, backupPrefix+now.Format(backupTimeLayout)+backupSuffix)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("write backup: %w", err)
+	}
+	return path, nil
+}
+
+// ListBackups returns the backup files in dir, oldest first.
+func ListBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, name))
+	}
+	// Timestamps sort lexically.
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// RestoreBackup imports a backup into the merged lineage graph and catalog.
+// path may be a backup file or a backup directory, in which case the most
+// recent backup is restored. It returns the path and manifest of the restored
+// backup.
+func (s *Service) RestoreBackup(ctx context.Context, path string, catalog *metadata.MemoryProvider) (string, *snapshot.Manifest, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, err
+	}
+	if info.IsDir() {
+		backups, err := ListBackups(path)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(backups) == 0 {
+			return "", nil, fmt.Errorf("no backups found in %s", path)
+		}
+		path = backups[len(backups)-1]
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+
+	manifest, err := s.ImportSnapshot(ctx, file, catalog)
+	if err != nil {
+		return "", nil, fmt.Errorf("restore %s: %w", path, err)
+	}
+	return path, manifest, nil
+}
@@ -0,0 +1,117 @@
+package lineage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	lineageCore "go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/metadata"
+)
+
+func sortedEdges(g *lineageCore.Graph) []*lineageCore.Edge {
+	edges := g.Edges()
+	sort.Slice(edges, func(i, j int) bool { return edges[i].Key() < edges[j].Key() })
+	return edges
+}
+
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	s := NewService(nil, nil)
+	s.merged.AddFrom(copyResult("shop.orders", "dw.orders"), "q1", lineageCore.OriginQueryLog, now.Add(-time.Hour))
+	s.merged.AddFrom(copyResult("dw.orders", "dw.daily"), "q2", lineageCore.OriginHook, now)
+	catalog := metadata.NewMemoryProvider()
+	if err := catalog.AddTableSchema(&metadata.TableSchema{
+		Database: "shop",
+		Table:    "orders",
+		Columns:  []metadata.ColumnSchema{{Name: "id", DataType: "bigint", PrimaryKey: true}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	b := s.NewBackuper(&BackupConfig{Dir: dir, Keep: 3, Origin: "prod"}, catalog, log.DefaultLogger)
+	path, err := b.RunOnce(ctx, now)
+	if err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	if want := filepath.Join(dir, "backup-20240601T120000Z.tar"); path != want {
+		t.Errorf("Expected backup %s, got %s", want, path)
+	}
+
+	restored := NewService(nil, nil)
+	restoredCatalog := metadata.NewMemoryProvider()
+	got, manifest, err := restored.RestoreBackup(ctx, dir, restoredCatalog)
+	if err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	if got != path {
+		t.Errorf("Expected %s to be restored, got %s", path, got)
+	}
+	if manifest.Origin != "prod" {
+		t.Errorf("Expected origin prod, got %q", manifest.Origin)
+	}
+	if want, got := sortedEdges(s.merged), sortedEdges(restored.merged); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected the restored graph to equal the backed up one:\nwant %+v\ngot  %+v", want, got)
+	}
+	if want, got := catalog.AllTables(), restoredCatalog.AllTables(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected the restored catalog to equal the backed up one:\nwant %+v\ngot  %+v", want, got)
+	}
+}
+
+func TestBackupRetention(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	s := NewService(nil, nil)
+	s.merged.AddFrom(copyResult("a", "b"), "q1", lineageCore.OriginQueryLog, now)
+
+	dir := t.TempDir()
+	// Other files in the directory are left alone
+	other := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(other, []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := s.NewBackuper(&BackupConfig{Dir: dir, Keep: 2}, nil, log.DefaultLogger)
+	var paths []string
+	for i := 0; i < 4; i++ {
+		path, err := b.RunOnce(ctx, now.Add(time.Duration(i)*time.Hour))
+		if err != nil {
+			t.Fatalf("RunOnce %d failed: %v", i, err)
+		}
+		paths = append(paths, path)
+	}
+
+	backups, err := ListBackups(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(backups, paths[2:]) {
+		t.Errorf("Expected the 2 most recent backups to be kept, got %v", backups)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("Expected %s to be kept: %v", other, err)
+	}
+
+	// Keep 0 keeps every backup
+	b.config.Keep = 0
+	if _, err := b.RunOnce(ctx, now.Add(4*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if backups, _ := ListBackups(dir); len(backups) != 3 {
+		t.Errorf("Expected 3 backups without retention, got %v", backups)
+	}
+}
+
+func TestRestoreBackupEmptyDir(t *testing.T) {
+	if _, _, err := NewService(nil, nil).RestoreBackup(context.Background(), t.TempDir(), nil); err == nil {
+		t.Error("Expected an error for a directory without backups")
+	}
+}
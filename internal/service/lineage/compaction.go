@@ -0,0 +1,130 @@
+package lineage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	lineageCore "go-metadata/internal/lineage"
+	"go-metadata/internal/metrics"
+)
+
+// CompactionConfig configures the lineage compaction job.
+type CompactionConfig struct {
+	// Interval is how often retention policies are applied.
+	Interval time.Duration `yaml:"interval"`
+	// Policies are the retention policies applied on each run, e.g.
+	// {Origin: query_log, MaxAge: 90 * 24h} drops query-log edges not seen in 90 days.
+	Policies []lineageCore.RetentionPolicy `yaml:"policies"`
+}
+
+// DefaultCompactionConfig returns the default compaction configuration, which
+// drops query-log edges not observed in 90 days.
+func DefaultCompactionConfig() *CompactionConfig {
+	return &CompactionConfig{
+		Interval: time.Hour,
+		Policies: []lineageCore.RetentionPolicy{
+			{Origin: lineageCore.OriginQueryLog, MaxAge: 90 * 24 * time.Hour},
+		},
+	}
+}
+
+// Compactor periodically applies retention policies to the merged lineage
+// graph and publishes graph size metrics.
+type Compactor struct {
+	graph   *lineageCore.Graph
+	tags    *lineageCore.TagPropagator
+	config  *CompactionConfig
+	metrics *metrics.Metrics
+	log     *log.Helper
+
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running bool
+}
+
+// NewCompactor creates a compactor for the service's merged lineage graph.
+func (s *Service) NewCompactor(config *CompactionConfig, logger log.Logger) *Compactor {
+	if config == nil {
+		config = DefaultCompactionConfig()
+	}
+	return &Compactor{
+		graph:   s.merged,
+		tags:    s.tags,
+		config:  config,
+		metrics: metrics.GetMetrics(),
+		log:     log.NewHelper(logger),
+	}
+}
+
+// Start starts the compaction loop. The first run happens right away so the
+// graph size metrics are published from startup on.
+func (c *Compactor) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running {
+		return nil
+	}
+
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.running = true
+
+	c.wg.Add(1)
+	go c.run(ctx)
+
+	c.log.Info("Lineage compactor started")
+	return nil
+}
+
+// Stop stops the compaction loop and waits for an in-flight run to finish.
+func (c *Compactor) Stop() error {
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return nil
+	}
+	c.cancel()
+	c.running = false
+	c.mu.Unlock()
+
+	c.wg.Wait()
+	c.log.Info("Lineage compactor stopped")
+	return nil
+}
+
+func (c *Compactor) run(ctx context.Context) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	c.RunOnce(time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.RunOnce(time.Now())
+		}
+	}
+}
+
+// RunOnce applies the retention policies as of now and updates metrics.
+func (c *Compactor) RunOnce(now time.Time) lineageCore.PruneStats {
+	stats := c.graph.Prune(c.config.Policies, now)
+	size := c.graph.Stats()
+
+	c.metrics.RecordLineagePruned(stats.Deleted, stats.Retired)
+	c.metrics.SetLineageGraphSize(size.CurrentEdges, size.Edges-size.CurrentEdges, size.Jobs)
+
+	if stats.Deleted > 0 || stats.Retired > 0 {
+		c.tags.LineageChanged()
+		c.log.Infof("Lineage compaction: examined=%d deleted=%d retired=%d remaining=%d",
+			stats.Examined, stats.Deleted, stats.Retired, size.Edges)
+	}
+	return stats
+}
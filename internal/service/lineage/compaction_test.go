@@ -0,0 +1,93 @@
+package lineage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	lineageCore "go-metadata/internal/lineage"
+)
+
+func copyResult(source, target string) *lineageCore.LineageResult {
+	return &lineageCore.LineageResult{
+		Columns: []lineageCore.ColumnLineage{{
+			Target:  lineageCore.ColumnRef{Table: target, Column: "id"},
+			Sources: []lineageCore.ColumnRef{{Table: source, Column: "id"}},
+		}},
+	}
+}
+
+func TestCompactorRunOncePrunesAndPublishesMetrics(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	s := NewService(nil, nil)
+	s.merged.AddFrom(copyResult("stale", "a"), "q1", lineageCore.OriginQueryLog, now.Add(-120*24*time.Hour))
+	s.merged.AddFrom(copyResult("retired", "a"), "q2", lineageCore.OriginHook, now.Add(-40*24*time.Hour))
+	s.merged.AddFrom(copyResult("fresh", "a"), "q3", lineageCore.OriginQueryLog, now.Add(-24*time.Hour))
+	s.merged.Add(copyResult("manual", "a"), "q4", now.Add(-365*24*time.Hour))
+
+	c := s.NewCompactor(&CompactionConfig{
+		Interval: time.Hour,
+		Policies: []lineageCore.RetentionPolicy{
+			{Origin: lineageCore.OriginQueryLog, MaxAge: 90 * 24 * time.Hour},
+			{Origin: lineageCore.OriginHook, MaxAge: 30 * 24 * time.Hour, Retire: true},
+		},
+	}, log.DefaultLogger)
+
+	m := c.metrics
+	deletedBefore := testutil.ToFloat64(m.LineageEdgesPruned.WithLabelValues("deleted"))
+	retiredBefore := testutil.ToFloat64(m.LineageEdgesPruned.WithLabelValues("retired"))
+
+	stats := c.RunOnce(now)
+	if stats.Examined != 4 || stats.Deleted != 1 || stats.Retired != 1 {
+		t.Fatalf("Unexpected prune stats: %+v", stats)
+	}
+	for _, edge := range s.merged.Edges() {
+		switch edge.Source.Table {
+		case "stale":
+			t.Error("Stale query-log edge was not deleted")
+		case "retired":
+			if edge.Current() {
+				t.Error("Hook edge was not retired")
+			}
+		}
+	}
+
+	if got := testutil.ToFloat64(m.LineageEdgesPruned.WithLabelValues("deleted")) - deletedBefore; got != 1 {
+		t.Errorf("Expected 1 deleted edge counted, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.LineageEdgesPruned.WithLabelValues("retired")) - retiredBefore; got != 1 {
+		t.Errorf("Expected 1 retired edge counted, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.LineageEdges.WithLabelValues("current")); got != 2 {
+		t.Errorf("Expected 2 current edges, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.LineageEdges.WithLabelValues("retired")); got != 1 {
+		t.Errorf("Expected 1 retired edge, got %v", got)
+	}
+}
+
+func TestCompactorStartStop(t *testing.T) {
+	s := NewService(nil, nil)
+	s.merged.AddFrom(copyResult("stale", "a"), "q1", lineageCore.OriginQueryLog, time.Now().Add(-120*24*time.Hour))
+
+	c := s.NewCompactor(nil, log.DefaultLogger)
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for s.merged.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Compactor did not run on start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := c.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if err := c.Stop(); err != nil {
+		t.Fatalf("Second Stop failed: %v", err)
+	}
+}
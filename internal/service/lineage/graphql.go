@@ -0,0 +1,369 @@
+package lineage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+
+	lineageCore "go-metadata/internal/lineage"
+)
+
+// graphqlSchema describes the lineage GraphQL API. Node ids are qualified
+// column names (db.table.column) or qualified table names (db.table);
+// upstream/downstream fields recurse up to depth hops, 0 meaning unlimited.
+const graphqlSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	node(id: String!): Node
+	edges(table: String, asOf: String): [Edge!]!
+	jobs: [Job!]!
+	job(name: String!): Job
+	stats: Stats!
+}
+
+type Node {
+	id: String!
+	kind: String!
+	database: String
+	table: String!
+	column: String
+	upstream(depth: Int = 1): [Node!]!
+	downstream(depth: Int = 1): [Node!]!
+	upstreamEdges(depth: Int = 1): [Edge!]!
+	downstreamEdges(depth: Int = 1): [Edge!]!
+	readBy: [Job!]!
+	writtenBy: [Job!]!
+}
+
+type Edge {
+	source: Node!
+	target: Node!
+	operators: [String!]!
+	origins: [String!]!
+	jobs: [String!]!
+	occurrences: Int!
+	firstSeen: String!
+	lastSeen: String!
+	current: Boolean!
+}
+
+type Job {
+	name: String!
+	type: String!
+	description: String
+	inputs: [Node!]!
+	outputs: [Node!]!
+	lastRunAt: String
+}
+
+type Stats {
+	edges: Int!
+	currentEdges: Int!
+	jobs: Int!
+	fingerprints: Int!
+}
+`
+
+// maxGraphQLDepth bounds the nesting of GraphQL queries so recursive
+// upstream/downstream selections cannot grow without limit.
+const maxGraphQLDepth = 20
+
+// NewGraphQLHandler returns an HTTP handler serving the lineage GraphQL API
+// over the service's merged lineage graph.
+func NewGraphQLHandler(s *Service) (http.Handler, error) {
+	schema, err := graphql.ParseSchema(graphqlSchema, &queryResolver{graph: s.merged},
+		graphql.MaxDepth(maxGraphQLDepth))
+	if err != nil {
+		return nil, fmt.Errorf("parse lineage graphql schema: %w", err)
+	}
+	return &relay.Handler{Schema: schema}, nil
+}
+
+type queryResolver struct {
+	graph *lineageCore.Graph
+}
+
+func (r *queryResolver) Node(ctx context.Context, args struct{ ID string }) *nodeResolver {
+	for _, edge := range r.graph.Edges() {
+		for _, ref := range []lineageCore.ColumnRef{edge.Source, edge.Target} {
+			if ref.QualifiedName() == args.ID {
+				return &nodeResolver{graph: r.graph, ref: ref}
+			}
+			if ref.TableName() == args.ID {
+				return r.tableNode(ref)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *queryResolver) Edges(ctx context.Context, args struct {
+	Table *string
+	AsOf  *string
+}) ([]*edgeResolver, error) {
+	var edges []*lineageCore.Edge
+	if args.AsOf != nil {
+		at, err := time.Parse(time.RFC3339, *args.AsOf)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asOf %q: %w", *args.AsOf, err)
+		}
+		edges = r.graph.AsOf(at)
+	} else {
+		edges = r.graph.Edges()
+	}
+
+	result := make([]*edgeResolver, 0, len(edges))
+	for _, edge := range edges {
+		if args.Table != nil && edge.Source.TableName() != *args.Table && edge.Target.TableName() != *args.Table {
+			continue
+		}
+		result = append(result, &edgeResolver{graph: r.graph, edge: edge})
+	}
+	return result, nil
+}
+
+func (r *queryResolver) Jobs() []*jobResolver {
+	return newJobResolvers(r.graph, r.graph.Jobs())
+}
+
+func (r *queryResolver) Job(args struct{ Name string }) *jobResolver {
+	job, err := r.graph.Job(args.Name)
+	if err != nil {
+		return nil
+	}
+	return &jobResolver{graph: r.graph, job: job}
+}
+
+func (r *queryResolver) Stats() *statsResolver {
+	return &statsResolver{stats: r.graph.Stats()}
+}
+
+func (r *queryResolver) tableNode(ref lineageCore.ColumnRef) *nodeResolver {
+	ref.Column = ""
+	return &nodeResolver{graph: r.graph, ref: ref, table: true}
+}
+
+type nodeResolver struct {
+	graph *lineageCore.Graph
+	ref   lineageCore.ColumnRef
+	table bool
+}
+
+func (n *nodeResolver) ID() string {
+	if n.table {
+		return n.ref.TableName()
+	}
+	return n.ref.QualifiedName()
+}
+
+func (n *nodeResolver) Kind() string {
+	if n.table {
+		return "table"
+	}
+	return "column"
+}
+
+func (n *nodeResolver) Database() *string {
+	return optionalString(n.ref.Database)
+}
+
+func (n *nodeResolver) Table() string {
+	return n.ref.Table
+}
+
+func (n *nodeResolver) Column() *string {
+	return optionalString(n.ref.Column)
+}
+
+type depthArgs struct {
+	Depth int32
+}
+
+func (n *nodeResolver) Upstream(args depthArgs) []*nodeResolver {
+	return n.neighbours(lineageCore.Upstream, args.Depth)
+}
+
+func (n *nodeResolver) Downstream(args depthArgs) []*nodeResolver {
+	return n.neighbours(lineageCore.Downstream, args.Depth)
+}
+
+func (n *nodeResolver) UpstreamEdges(args depthArgs) []*edgeResolver {
+	return n.edges(lineageCore.Upstream, args.Depth)
+}
+
+func (n *nodeResolver) DownstreamEdges(args depthArgs) []*edgeResolver {
+	return n.edges(lineageCore.Downstream, args.Depth)
+}
+
+func (n *nodeResolver) ReadBy() []*jobResolver {
+	return newJobResolvers(n.graph, n.graph.JobsReading(n.ref.TableName()))
+}
+
+func (n *nodeResolver) WrittenBy() []*jobResolver {
+	return newJobResolvers(n.graph, n.graph.JobsWriting(n.ref.TableName()))
+}
+
+func (n *nodeResolver) edges(direction lineageCore.Direction, depth int32) []*edgeResolver {
+	edges := n.graph.Traverse(n.ID(), direction, int(depth))
+	result := make([]*edgeResolver, 0, len(edges))
+	for _, edge := range edges {
+		result = append(result, &edgeResolver{graph: n.graph, edge: edge})
+	}
+	return result
+}
+
+// neighbours returns the distinct nodes reached by a traversal, at the same
+// granularity (table or column) as n.
+func (n *nodeResolver) neighbours(direction lineageCore.Direction, depth int32) []*nodeResolver {
+	seen := map[string]bool{n.ID(): true}
+	result := make([]*nodeResolver, 0)
+	for _, edge := range n.graph.Traverse(n.ID(), direction, int(depth)) {
+		ref := edge.Source
+		if direction == lineageCore.Downstream {
+			ref = edge.Target
+		}
+		node := &nodeResolver{graph: n.graph, ref: ref, table: n.table}
+		if n.table {
+			node.ref.Column = ""
+		}
+		if !seen[node.ID()] {
+			seen[node.ID()] = true
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+type edgeResolver struct {
+	graph *lineageCore.Graph
+	edge  *lineageCore.Edge
+}
+
+func (e *edgeResolver) Source() *nodeResolver {
+	return &nodeResolver{graph: e.graph, ref: e.edge.Source}
+}
+
+func (e *edgeResolver) Target() *nodeResolver {
+	return &nodeResolver{graph: e.graph, ref: e.edge.Target}
+}
+
+func (e *edgeResolver) Operators() []string {
+	return nonNil(e.edge.Operators)
+}
+
+func (e *edgeResolver) Origins() []string {
+	origins := make([]string, 0, len(e.edge.Provenance.Origins))
+	for _, o := range e.edge.Provenance.Origins {
+		origins = append(origins, string(o))
+	}
+	return origins
+}
+
+func (e *edgeResolver) Jobs() []string {
+	return nonNil(e.edge.Provenance.Jobs)
+}
+
+func (e *edgeResolver) Occurrences() int32 {
+	return int32(e.edge.Provenance.Occurrences)
+}
+
+func (e *edgeResolver) FirstSeen() string {
+	return e.edge.Provenance.FirstSeen.Format(time.RFC3339)
+}
+
+func (e *edgeResolver) LastSeen() string {
+	return e.edge.Provenance.LastSeen.Format(time.RFC3339)
+}
+
+func (e *edgeResolver) Current() bool {
+	return e.edge.Current()
+}
+
+type jobResolver struct {
+	graph *lineageCore.Graph
+	job   *lineageCore.Job
+}
+
+func newJobResolvers(g *lineageCore.Graph, jobs []*lineageCore.Job) []*jobResolver {
+	result := make([]*jobResolver, 0, len(jobs))
+	for _, job := range jobs {
+		result = append(result, &jobResolver{graph: g, job: job})
+	}
+	return result
+}
+
+func (j *jobResolver) Name() string {
+	return j.job.Name
+}
+
+func (j *jobResolver) Type() string {
+	return string(j.job.Type)
+}
+
+func (j *jobResolver) Description() *string {
+	return optionalString(j.job.Description)
+}
+
+func (j *jobResolver) Inputs() []*nodeResolver {
+	return j.tableNodes(j.job.Inputs)
+}
+
+func (j *jobResolver) Outputs() []*nodeResolver {
+	return j.tableNodes(j.job.Outputs)
+}
+
+func (j *jobResolver) LastRunAt() *string {
+	if j.job.LastRunAt.IsZero() {
+		return nil
+	}
+	s := j.job.LastRunAt.Format(time.RFC3339)
+	return &s
+}
+
+func (j *jobResolver) tableNodes(tables []string) []*nodeResolver {
+	result := make([]*nodeResolver, 0, len(tables))
+	for _, table := range tables {
+		result = append(result, &nodeResolver{graph: j.graph, ref: parseTableName(table), table: true})
+	}
+	return result
+}
+
+type statsResolver struct {
+	stats lineageCore.GraphStats
+}
+
+func (s *statsResolver) Edges() int32        { return int32(s.stats.Edges) }
+func (s *statsResolver) CurrentEdges() int32 { return int32(s.stats.CurrentEdges) }
+func (s *statsResolver) Jobs() int32         { return int32(s.stats.Jobs) }
+func (s *statsResolver) Fingerprints() int32 { return int32(s.stats.Fingerprints) }
+
+// parseTableName splits a qualified db.table name into a table reference.
+func parseTableName(name string) lineageCore.ColumnRef {
+	for i := len(name) - 1; i >= 0; i-- {
+		if name[i] == '.' {
+			return lineageCore.ColumnRef{Database: name[:i], Table: name[i+1:]}
+		}
+	}
+	return lineageCore.ColumnRef{Table: name}
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
@@ -0,0 +1,219 @@
+package lineage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	lineageCore "go-metadata/internal/lineage"
+)
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// postGraphQL posts query to the lineage GraphQL handler of s and decodes
+// the response.
+func postGraphQL(t *testing.T, s *Service, query string) *graphqlResponse {
+	t.Helper()
+	handler, err := NewGraphQLHandler(s)
+	if err != nil {
+		t.Fatalf("NewGraphQLHandler failed: %v", err)
+	}
+	body, _ := json.Marshal(map[string]string{"query": query})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp graphqlResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid response %s: %v", rec.Body.String(), err)
+	}
+	return &resp
+}
+
+// chainService returns a service whose graph copies t0.id to t1.id and so on
+// up to t<n>.id.
+func chainService(n int) *Service {
+	s := NewService(nil, nil)
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		s.merged.Add(copyResult(fmt.Sprintf("t%d", i), fmt.Sprintf("t%d", i+1)), fmt.Sprintf("q%d", i), at)
+	}
+	return s
+}
+
+func TestGraphQLNode(t *testing.T) {
+	s := chainService(3)
+
+	resp := postGraphQL(t, s, `{
+		column: node(id: "t3.id") { id kind table column upstream { id } }
+		table: node(id: "t1") { id kind column downstream(depth: 2) { id kind } }
+		missing: node(id: "nope") { id }
+	}`)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("Unexpected errors: %+v", resp.Errors)
+	}
+
+	var data struct {
+		Column struct {
+			ID       string
+			Kind     string
+			Table    string
+			Column   *string
+			Upstream []struct{ ID string }
+		}
+		Table struct {
+			ID         string
+			Kind       string
+			Column     *string
+			Downstream []struct{ ID, Kind string }
+		}
+		Missing *struct{ ID string }
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Column.ID != "t3.id" || data.Column.Kind != "column" || data.Column.Table != "t3" ||
+		data.Column.Column == nil || *data.Column.Column != "id" {
+		t.Errorf("Unexpected column node %+v", data.Column)
+	}
+	if len(data.Column.Upstream) != 1 || data.Column.Upstream[0].ID != "t2.id" {
+		t.Errorf("Expected upstream [t2.id], got %+v", data.Column.Upstream)
+	}
+	if data.Table.ID != "t1" || data.Table.Kind != "table" || data.Table.Column != nil {
+		t.Errorf("Unexpected table node %+v", data.Table)
+	}
+	if len(data.Table.Downstream) != 2 || data.Table.Downstream[0].ID != "t2" || data.Table.Downstream[1].ID != "t3" {
+		t.Errorf("Expected downstream tables [t2 t3], got %+v", data.Table.Downstream)
+	}
+	if data.Missing != nil {
+		t.Errorf("Expected null for unknown node, got %+v", data.Missing)
+	}
+}
+
+func TestGraphQLTraversalDepth(t *testing.T) {
+	s := chainService(30)
+
+	resp := postGraphQL(t, s, `{
+		node(id: "t30") {
+			all: upstream(depth: 0) { id }
+			deep: upstream(depth: 25) { id }
+			default: upstream { id }
+			edges: upstreamEdges(depth: 0) { source { id } target { id } }
+		}
+	}`)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("Unexpected errors: %+v", resp.Errors)
+	}
+
+	var data struct {
+		Node struct {
+			All     []struct{ ID string }
+			Deep    []struct{ ID string }
+			Default []struct{ ID string }
+			Edges   []struct{ Source, Target struct{ ID string } }
+		}
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+
+	// depth 0 is unlimited; depth arguments above the query depth limit are
+	// not clamped, the limit only bounds the nesting of selections.
+	if len(data.Node.All) != 30 || len(data.Node.Edges) != 30 {
+		t.Errorf("Expected depth 0 to reach all 30 tables and edges, got %d and %d", len(data.Node.All), len(data.Node.Edges))
+	}
+	if len(data.Node.Deep) != 25 || data.Node.Deep[24].ID != "t5" {
+		t.Errorf("Expected depth 25 to reach t29..t5, got %+v", data.Node.Deep)
+	}
+	if len(data.Node.Default) != 1 || data.Node.Default[0].ID != "t29" {
+		t.Errorf("Expected default depth 1 to reach [t29], got %+v", data.Node.Default)
+	}
+}
+
+func TestGraphQLMaxDepth(t *testing.T) {
+	s := chainService(30)
+
+	// nested builds a query selecting upstream levels times below node.
+	nested := func(levels int) string {
+		return `{ node(id: "t30") ` + strings.Repeat(`{ upstream `, levels) + `{ id }` +
+			strings.Repeat(` }`, levels) + ` }`
+	}
+
+	resp := postGraphQL(t, s, nested(maxGraphQLDepth-2))
+	if len(resp.Errors) > 0 {
+		t.Fatalf("Expected query within depth limit to succeed, got %+v", resp.Errors)
+	}
+
+	resp = postGraphQL(t, s, nested(maxGraphQLDepth+1))
+	if len(resp.Errors) == 0 {
+		t.Fatal("Expected error for query nested beyond the depth limit")
+	}
+	if !strings.Contains(resp.Errors[0].Message, "exceeds max depth") {
+		t.Errorf("Unexpected error %q", resp.Errors[0].Message)
+	}
+}
+
+func TestGraphQLEdgesAsOf(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	s := NewService(nil, nil)
+	s.merged.Add(copyResult("orders", "report"), "q1", jan)
+	s.merged.Add(copyResult("payments", "report"), "q2", feb)
+	s.merged.Add(copyResult("users", "profiles"), "q3", jan)
+	if err := s.merged.Retire(lineageCore.ColumnRef{Table: "orders", Column: "id"}, lineageCore.ColumnRef{Table: "report", Column: "id"}, feb); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    string
+		sources []string
+	}{
+		{"all", ``, []string{"orders.id", "payments.id", "users.id"}},
+		{"table", `(table: "report")`, []string{"orders.id", "payments.id"}},
+		{"before retire", `(table: "report", asOf: "2024-01-15T00:00:00Z")`, []string{"orders.id"}},
+		{"after retire", `(table: "report", asOf: "2024-03-01T00:00:00Z")`, []string{"payments.id"}},
+		{"before first seen", `(asOf: "2023-12-01T00:00:00Z")`, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := postGraphQL(t, s, `{ edges`+tt.args+` { source { id } target { id } } }`)
+			if len(resp.Errors) > 0 {
+				t.Fatalf("Unexpected errors: %+v", resp.Errors)
+			}
+			var data struct {
+				Edges []struct {
+					Source struct{ ID string }
+				}
+			}
+			if err := json.Unmarshal(resp.Data, &data); err != nil {
+				t.Fatal(err)
+			}
+			sources := make([]string, 0, len(data.Edges))
+			for _, e := range data.Edges {
+				sources = append(sources, e.Source.ID)
+			}
+			if strings.Join(sources, ",") != strings.Join(tt.sources, ",") {
+				t.Errorf("Expected sources %v, got %v", tt.sources, sources)
+			}
+		})
+	}
+
+	resp := postGraphQL(t, s, `{ edges(asOf: "2024-01-15") { source { id } } }`)
+	if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, `invalid asOf "2024-01-15"`) {
+		t.Errorf("Expected invalid asOf error, got %+v", resp.Errors)
+	}
+}
@@ -0,0 +1,119 @@
+package lineage
+
+import (
+	"context"
+	"time"
+
+	"go-metadata/internal/data/graph"
+	lineageCore "go-metadata/internal/lineage"
+)
+
+// PersistGraph writes the current edges and jobs of the merged lineage graph
+// to the graph database. Tables and columns become nodes linked by contains
+// edges; column lineage becomes depends_on edges pointing in the direction of
+// data flow, between the columns and between their tables, and jobs are
+// linked to the tables they read (depends_on) and write (produced_by). Writes
+// are idempotent, so the graph can be persisted repeatedly as it grows.
+func (s *Service) PersistGraph(ctx context.Context) error {
+	if s.graphDB == nil {
+		return nil
+	}
+	nodes, edges := exportGraph(s.merged)
+	if err := s.graphDB.BatchCreateNodes(ctx, nodes); err != nil {
+		return err
+	}
+	return s.graphDB.BatchCreateEdges(ctx, edges)
+}
+
+// exportGraph converts the current state of a lineage graph into graph
+// database nodes and edges.
+func exportGraph(g *lineageCore.Graph) ([]*graph.Node, []*graph.Edge) {
+	nodes := make([]*graph.Node, 0)
+	edges := make([]*graph.Edge, 0)
+	seen := make(map[string]bool)
+
+	addTable := func(ref lineageCore.ColumnRef) string {
+		id := buildTableNodeID(ref.Database, ref.Table)
+		if !seen[id] {
+			seen[id] = true
+			nodes = append(nodes, &graph.Node{
+				ID: id, Type: graph.NodeTypeTable, Name: ref.Table, Database: ref.Database, Table: ref.Table,
+			})
+		}
+		return id
+	}
+	addColumn := func(ref lineageCore.ColumnRef) string {
+		tableID := addTable(ref)
+		id := buildColumnNodeID(ref.Database, ref.Table, ref.Column)
+		if !seen[id] {
+			seen[id] = true
+			nodes = append(nodes, &graph.Node{
+				ID: id, Type: graph.NodeTypeColumn, Name: ref.Column,
+				Database: ref.Database, Table: ref.Table, Column: ref.Column,
+			})
+			edges = append(edges, &graph.Edge{
+				ID: tableID + "->" + id, Type: graph.EdgeTypeContains, SourceID: tableID, TargetID: id,
+			})
+		}
+		return id
+	}
+
+	tableEdges := make(map[string]*graph.Edge)
+	for _, edge := range g.Edges() {
+		if !edge.Current() {
+			continue
+		}
+		if source, target := addTable(edge.Source), addTable(edge.Target); source != target {
+			id := source + "->" + target
+			if e, ok := tableEdges[id]; ok {
+				e.Properties["columns"] = e.Properties["columns"].(int) + 1
+			} else {
+				tableEdges[id] = &graph.Edge{
+					ID: id, Type: graph.EdgeTypeDependsOn, SourceID: source, TargetID: target,
+					Properties: map[string]any{"columns": 1},
+				}
+				edges = append(edges, tableEdges[id])
+			}
+		}
+		source, target := addColumn(edge.Source), addColumn(edge.Target)
+		edges = append(edges, &graph.Edge{
+			ID:       edge.Key(),
+			Type:     graph.EdgeTypeDependsOn,
+			SourceID: source,
+			TargetID: target,
+			Properties: map[string]any{
+				"operators":   edge.Operators,
+				"jobs":        edge.Provenance.Jobs,
+				"occurrences": edge.Provenance.Occurrences,
+				"first_seen":  edge.Provenance.FirstSeen.Format(time.RFC3339),
+				"last_seen":   edge.Provenance.LastSeen.Format(time.RFC3339),
+			},
+		})
+	}
+
+	for _, job := range g.Jobs() {
+		jobID := "job:" + job.Name
+		nodes = append(nodes, &graph.Node{
+			ID:   jobID,
+			Type: graph.NodeTypeJob,
+			Name: job.Name,
+			Properties: map[string]any{
+				"job_type":    string(job.Type),
+				"description": job.Description,
+			},
+		})
+		for _, input := range job.Inputs {
+			tableID := addTable(parseTableName(input))
+			edges = append(edges, &graph.Edge{
+				ID: tableID + "->" + jobID, Type: graph.EdgeTypeDependsOn, SourceID: tableID, TargetID: jobID,
+			})
+		}
+		for _, output := range job.Outputs {
+			tableID := addTable(parseTableName(output))
+			edges = append(edges, &graph.Edge{
+				ID: jobID + "->" + tableID, Type: graph.EdgeTypeProducedBy, SourceID: jobID, TargetID: tableID,
+			})
+		}
+	}
+	return nodes, edges
+}
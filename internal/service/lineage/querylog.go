@@ -0,0 +1,105 @@
+package lineage
+
+import (
+	"context"
+	"time"
+
+	lineageCore "go-metadata/internal/lineage"
+)
+
+// QueryLogStatement is a statement read from the query log of a database,
+// such as pg_stat_statements or the MySQL general log, with the number of
+// times it was executed.
+type QueryLogStatement struct {
+	SQL        string
+	Executions int64
+	// ExecutedAt is when the statement was last executed, or when the log
+	// was read if it does not record it.
+	ExecutedAt time.Time
+}
+
+// QueryLogSummary counts the statements of a query log recorded by
+// RecordQueryLog.
+type QueryLogSummary struct {
+	// Statements is the number of distinct statements analyzed, and
+	// Executions their total executions.
+	Statements int   `json:"statements"`
+	Executions int64 `json:"executions"`
+	// WithLineage is the number of statements that wrote column lineage,
+	// e.g. INSERT ... SELECT, as opposed to plain queries.
+	WithLineage int `json:"with_lineage"`
+	// Skipped is the number of statements the analyzer rejected, such as
+	// SET or SHOW, and Errors their first errors.
+	Skipped int      `json:"skipped"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// maxQueryLogErrors bounds the errors kept in a QueryLogSummary.
+const maxQueryLogErrors = 10
+
+// RecordQueryLog analyzes the statements of a database query log and merges
+// their lineage as RecordQueryLogSQL does. Variants of a statement that only
+// differ by their literals are analyzed once, and the occurrences of their
+// edges grow by their total executions. PostgreSQL positional parameters,
+// as in the statements of pg_stat_statements, are analyzed as ? markers.
+func (s *Service) RecordQueryLog(ctx context.Context, statements []QueryLogStatement) QueryLogSummary {
+	var summary QueryLogSummary
+	if s.analyzer == nil {
+		return summary
+	}
+
+	// Statements by fingerprint, in the order first read
+	type aggregate struct {
+		sql        string
+		executions int64
+		executedAt time.Time
+	}
+	var fingerprints []string
+	aggregates := make(map[string]*aggregate)
+	for _, st := range statements {
+		if st.Executions <= 0 {
+			continue
+		}
+		sql := lineageCore.ReplacePositionalParams(st.SQL)
+		fp := lineageCore.Fingerprint(sql)
+		a, ok := aggregates[fp]
+		if !ok {
+			a = &aggregate{sql: sql}
+			aggregates[fp] = a
+			fingerprints = append(fingerprints, fp)
+		}
+		a.executions += st.Executions
+		if st.ExecutedAt.After(a.executedAt) {
+			a.executedAt = st.ExecutedAt
+		}
+	}
+
+	changed := false
+	for _, fp := range fingerprints {
+		if ctx.Err() != nil {
+			break
+		}
+		a := aggregates[fp]
+		result, err := s.analyzer.Analyze(a.sql)
+		if err != nil {
+			summary.Skipped++
+			if len(summary.Errors) < maxQueryLogErrors {
+				summary.Errors = append(summary.Errors, err.Error())
+			}
+			continue
+		}
+		summary.Statements++
+		summary.Executions += a.executions
+		if len(result.Columns) > 0 {
+			summary.WithLineage++
+			changed = true
+		}
+		s.merged.AddExecutions(result, fp, lineageCore.OriginQueryLog, a.executedAt, int(a.executions))
+		s.usage.Add(result, a.executedAt)
+		s.joins.Add(result, a.executedAt)
+	}
+	if changed {
+		s.tags.LineageChanged()
+	}
+	return summary
+}
@@ -3,22 +3,37 @@ package lineage
 
 import (
 	"context"
+	"time"
 
 	"go-metadata/internal/data/graph"
 	lineageCore "go-metadata/internal/lineage"
+	"go-metadata/internal/lineage/airflow"
+	"go-metadata/internal/lineage/connector"
+	"go-metadata/internal/lineage/hook"
+	"go-metadata/internal/lineage/openlineage"
+	"go-metadata/internal/lineage/storage"
 )
 
 // Service provides lineage query operations.
 type Service struct {
 	analyzer *lineageCore.Analyzer
 	graphDB  graph.GraphDB
+	merged   *lineageCore.Graph
+	usage    *lineageCore.UsageStats
+	joins    *lineageCore.RelationshipStats
+	tags     *lineageCore.TagPropagator
 }
 
 // NewService creates a new lineage service.
 func NewService(analyzer *lineageCore.Analyzer, graphDB graph.GraphDB) *Service {
+	merged := lineageCore.NewGraph()
 	return &Service{
 		analyzer: analyzer,
 		graphDB:  graphDB,
+		merged:   merged,
+		usage:    lineageCore.NewUsageStats(),
+		joins:    lineageCore.NewRelationshipStats(),
+		tags:     lineageCore.NewTagPropagator(merged, nil),
 	}
 }
 
@@ -30,6 +45,230 @@ func (s *Service) AnalyzeSQL(ctx context.Context, sql string) (*lineageCore.Line
 	return s.analyzer.Analyze(sql)
 }
 
+// ExplainSQL returns the parse tree of a SQL statement, to debug how the
+// grammar handles its dialect.
+func (s *Service) ExplainSQL(ctx context.Context, sql string) (*lineageCore.ParseTree, error) {
+	if s.analyzer == nil {
+		return nil, nil
+	}
+	return s.analyzer.Explain(sql)
+}
+
+// RecordSQL analyzes a SQL statement and merges its lineage into the service's
+// deduplicated lineage graph, tracking the statement fingerprint and the time
+// the edges were observed.
+func (s *Service) RecordSQL(ctx context.Context, sql string) (*lineageCore.LineageResult, error) {
+	result, err := s.AnalyzeSQL(ctx, sql)
+	if err != nil || result == nil {
+		return result, err
+	}
+	s.merged.Add(result, lineageCore.Fingerprint(sql), time.Now())
+	s.tags.LineageChanged()
+	return result, nil
+}
+
+// RecordQueryLogSQL is like RecordSQL for statements harvested from query
+// logs; such edges are subject to query-log retention policies. The columns
+// the statement reads are counted in the column usage statistics and its
+// join keys in the suggested table relationships.
+func (s *Service) RecordQueryLogSQL(ctx context.Context, sql string, executedAt time.Time) (*lineageCore.LineageResult, error) {
+	result, err := s.AnalyzeSQL(ctx, sql)
+	if err != nil || result == nil {
+		return result, err
+	}
+	s.merged.AddFrom(result, lineageCore.Fingerprint(sql), lineageCore.OriginQueryLog, executedAt)
+	s.tags.LineageChanged()
+	s.usage.Add(result, executedAt)
+	s.joins.Add(result, executedAt)
+	return result, nil
+}
+
+// GetColumnUsage returns the usage statistics of the columns of a table, or
+// of every used column if table is empty.
+func (s *Service) GetColumnUsage(ctx context.Context, table string) []*lineageCore.ColumnUsageStats {
+	return s.usage.Columns(table)
+}
+
+// GetUnusedColumns returns the columns of the given tables that no query
+// harvested from query logs has used since the given time.
+func (s *Service) GetUnusedColumns(ctx context.Context, tables []*lineageCore.TableSchema, since time.Time) []lineageCore.ColumnRef {
+	return s.usage.Unused(tables, since)
+}
+
+// RecordForeignKeys records declared foreign keys as table relationships.
+func (s *Service) RecordForeignKeys(ctx context.Context, keys []lineageCore.JoinKey) {
+	for _, key := range keys {
+		s.joins.AddForeignKey(key)
+	}
+}
+
+// GetRelationships returns the foreign keys and the relationships suggested
+// by the joins of at least minOccurrences harvested queries of a table, or
+// of every table if table is empty.
+func (s *Service) GetRelationships(ctx context.Context, table string, minOccurrences int64) []*lineageCore.Relationship {
+	return s.joins.Relationships(table, minOccurrences)
+}
+
+// UsageStats returns the column usage statistics built by RecordQueryLogSQL.
+func (s *Service) UsageStats() *lineageCore.UsageStats {
+	return s.usage
+}
+
+// EmitOpenLineage analyzes a SQL statement and emits its lineage, including
+// the columnLineage facet, as a COMPLETE run event.
+func (s *Service) EmitOpenLineage(ctx context.Context, emitter *openlineage.Emitter, sql string, opts openlineage.EventOptions) error {
+	result, err := s.AnalyzeSQL(ctx, sql)
+	if err != nil {
+		return err
+	}
+	opts.SQL = sql
+	return emitter.Emit(ctx, openlineage.NewRunEvent(openlineage.EventTypeComplete, result, opts))
+}
+
+// RegisterJob registers a job node (SQL script, dbt model, Airflow task, ...)
+// in the lineage graph.
+func (s *Service) RegisterJob(ctx context.Context, job *lineageCore.Job) error {
+	if err := s.merged.RegisterJob(job); err != nil {
+		return err
+	}
+	s.tags.LineageChanged()
+	return nil
+}
+
+// RecordJobSQL analyzes a SQL statement executed by a registered job and
+// attaches its lineage to the job.
+func (s *Service) RecordJobSQL(ctx context.Context, jobName, sql string) (*lineageCore.LineageResult, error) {
+	result, err := s.AnalyzeSQL(ctx, sql)
+	if err != nil || result == nil {
+		return result, err
+	}
+	if err := s.merged.AttachStatement(jobName, result, lineageCore.Fingerprint(sql), time.Now()); err != nil {
+		return nil, err
+	}
+	s.tags.LineageChanged()
+	return result, nil
+}
+
+// RecordSinkConnectors records the topic-to-table lineage of Kafka Connect
+// sink connectors, each registered as a job in the lineage graph.
+func (s *Service) RecordSinkConnectors(ctx context.Context, resolver *connector.Resolver, sinks []*connector.SinkConnector) error {
+	defer s.tags.LineageChanged()
+	return resolver.Apply(s.merged, sinks, time.Now())
+}
+
+// RecordAirflowPipelines records the pipeline-to-dataset lineage of Airflow
+// DAGs, each DAG and task registered as a job in the lineage graph and the
+// SQL of the tasks analyzed.
+func (s *Service) RecordAirflowPipelines(ctx context.Context, pipelines []*airflow.Pipeline) (*airflow.Summary, error) {
+	defer s.tags.LineageChanged()
+	return airflow.Apply(s.merged, pipelines, s.analyzer, time.Now())
+}
+
+// RecordHookEvent records the lineage of a query reported by a Hive
+// post-execution hook or a Spark listener, with its job registered in the
+// lineage graph. SQL reported without column lineage is analyzed. Events of
+// failed queries are ignored and return a nil job.
+func (s *Service) RecordHookEvent(ctx context.Context, event *hook.Event) (*lineageCore.Job, error) {
+	job, err := hook.Apply(s.merged, event, s.analyzer, time.Now())
+	if err != nil || job == nil {
+		return nil, err
+	}
+	s.tags.LineageChanged()
+	return job, nil
+}
+
+// RecordStorageLinks records the lineage between warehouse tables and the
+// object-store datasets at their storage locations, each link registered as a
+// job in the lineage graph.
+func (s *Service) RecordStorageLinks(ctx context.Context, resolver *storage.Resolver, tables []storage.Table) ([]*storage.Link, error) {
+	defer s.tags.LineageChanged()
+	return resolver.Apply(s.merged, tables, time.Now())
+}
+
+// RecordGeneratedColumns records the lineage of the generated columns of a
+// table from its base columns, as read from the system catalog of its source.
+func (s *Service) RecordGeneratedColumns(ctx context.Context, database, table string, columns []lineageCore.GeneratedColumn) *lineageCore.LineageResult {
+	result := s.analyzer.AnalyzeGenerated(database, table, columns)
+	s.merged.AddFrom(result, "", lineageCore.OriginCatalog, time.Now())
+	s.tags.LineageChanged()
+	return result
+}
+
+// RecordViewDependencies records the dependencies of views on the tables and
+// views they read, as read from the system catalog of their source, each
+// view registered as a job in the lineage graph. It complements the column
+// lineage of view definitions analyzed as SQL and returns the jobs.
+func (s *Service) RecordViewDependencies(ctx context.Context, deps []lineageCore.ViewDependency) ([]*lineageCore.Job, error) {
+	jobs := lineageCore.ViewJobs(deps)
+	for _, job := range jobs {
+		if err := s.merged.RegisterJob(job); err != nil {
+			return nil, err
+		}
+	}
+	s.tags.LineageChanged()
+	return jobs, nil
+}
+
+// GetTableLineageAsOf returns the column-level edges touching a table as they
+// were valid at the given time.
+func (s *Service) GetTableLineageAsOf(ctx context.Context, database, table string, at time.Time) []*lineageCore.Edge {
+	return s.merged.TableLineageAsOf(buildTableNodeID(database, table), at)
+}
+
+// Dependents returns the tables downstream of a table (database.table) in
+// the lineage graph. It implements metadata.DependencyChecker, protecting
+// shared upstream tables from deletion.
+func (s *Service) Dependents(ctx context.Context, table string) []string {
+	return s.merged.Dependents(table)
+}
+
+// Traverse returns the current edges upstream or downstream of a table
+// (database.table) or column (database.table.column) within depth hops
+// (depth <= 0 means unlimited).
+func (s *Service) Traverse(ctx context.Context, node string, direction lineageCore.Direction, depth int) []*lineageCore.Edge {
+	return s.merged.Traverse(node, direction, depth)
+}
+
+// Impact returns the columns, tables and jobs downstream of a table or
+// column within depth hops: what breaks if it is dropped.
+func (s *Service) Impact(ctx context.Context, node string, depth int) *lineageCore.Impact {
+	return s.merged.Impact(node, depth)
+}
+
+// Cycles returns the groups of tables that depend on each other in the
+// lineage graph.
+func (s *Service) Cycles(ctx context.Context) [][]string {
+	return s.merged.Cycles()
+}
+
+// SetTagRules sets the rules propagating tags along the lineage graph.
+func (s *Service) SetTagRules(rules *lineageCore.TagRules) {
+	s.tags.SetRules(rules)
+}
+
+// SetTags sets the tags of a table (database.table) or column
+// (database.table.column), replacing its previous tags.
+func (s *Service) SetTags(ctx context.Context, node string, tags []string) {
+	s.tags.SetTags(node, tags)
+}
+
+// EvaluateTags re-evaluates the propagation of the tags and lineage changed
+// since the last evaluation and returns the tags nodes gained or lost.
+func (s *Service) EvaluateTags(ctx context.Context) []lineageCore.TagChange {
+	return s.tags.Evaluate()
+}
+
+// GetPropagatedTags returns the tags a table or column inherits through
+// lineage.
+func (s *Service) GetPropagatedTags(ctx context.Context, node string) []lineageCore.PropagatedTag {
+	return s.tags.Tags(node)
+}
+
+// MergedGraph returns the deduplicated lineage graph built by RecordSQL.
+func (s *Service) MergedGraph() *lineageCore.Graph {
+	return s.merged
+}
+
 // GetColumnLineage retrieves column-level lineage.
 func (s *Service) GetColumnLineage(ctx context.Context, database, table, column string, depth int) (*graph.LineageGraph, error) {
 	if s.graphDB == nil {
@@ -52,10 +291,13 @@ func (s *Service) GetTableLineage(ctx context.Context, database, table string, d
 
 // buildColumnNodeID builds a node ID for a column.
 func buildColumnNodeID(database, table, column string) string {
-	return database + "." + table + "." + column
+	return lineageCore.ColumnRef{Database: database, Table: table, Column: column}.QualifiedName()
 }
 
 // buildTableNodeID builds a node ID for a table.
 func buildTableNodeID(database, table string) string {
+	if database == "" {
+		return table
+	}
 	return database + "." + table
 }
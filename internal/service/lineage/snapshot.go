@@ -0,0 +1,30 @@
+package lineage
+
+import (
+	"context"
+	"io"
+
+	"go-metadata/internal/lineage/metadata"
+	"go-metadata/internal/lineage/snapshot"
+)
+
+// ExportSnapshot writes the given tables and the merged lineage graph as a
+// snapshot bundle, limited to opts.Databases if set.
+func (s *Service) ExportSnapshot(ctx context.Context, w io.Writer, tables []*metadata.TableSchema, opts snapshot.Options) (*snapshot.Manifest, error) {
+	return snapshot.Export(w, tables, s.merged, opts)
+}
+
+// ImportSnapshot verifies a snapshot bundle exported by another deployment and
+// merges its lineage into the merged graph. Its tables are added to provider
+// if one is given.
+func (s *Service) ImportSnapshot(ctx context.Context, r io.Reader, provider *metadata.MemoryProvider) (*snapshot.Manifest, error) {
+	snap, err := snapshot.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := snap.Apply(provider, s.merged); err != nil {
+		return nil, err
+	}
+	s.tags.LineageChanged()
+	return snap.Manifest, nil
+}
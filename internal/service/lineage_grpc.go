@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	v1 "go-metadata/api/metadata/v1"
+	lineageCore "go-metadata/internal/lineage"
+	lineageService "go-metadata/internal/service/lineage"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// LineageGRPCService serves SQL lineage analysis and the lineage graph over
+// gRPC. It shares the lineage graph of the REST lineage routes.
+type LineageGRPCService struct {
+	v1.UnimplementedLineageServiceServer
+
+	lineage *lineageService.Service
+}
+
+// NewLineageGRPCService creates a new LineageGRPCService.
+func NewLineageGRPCService(lineage *lineageService.Service) *LineageGRPCService {
+	return &LineageGRPCService{lineage: lineage}
+}
+
+// AnalyzeSQL extracts the column lineage of a SQL statement without
+// recording it.
+func (s *LineageGRPCService) AnalyzeSQL(ctx context.Context, req *v1.AnalyzeSQLRequest) (*v1.AnalyzeSQLResponse, error) {
+	result, err := analyzeSQL(ctx, s.lineage, &AnalyzeRequest{SQL: req.Sql})
+	if err != nil {
+		return nil, err
+	}
+	return toProtoLineageResult(result), nil
+}
+
+// RecordSQL extracts the column lineage of a SQL statement and records it in
+// the lineage graph.
+func (s *LineageGRPCService) RecordSQL(ctx context.Context, req *v1.RecordSQLRequest) (*v1.AnalyzeSQLResponse, error) {
+	if strings.TrimSpace(req.Sql) == "" {
+		return nil, errors.BadRequest("INVALID_REQUEST", "sql is required")
+	}
+	result, err := s.lineage.RecordSQL(ctx, req.Sql)
+	if err != nil {
+		return nil, errors.BadRequest("INVALID_SQL", err.Error())
+	}
+	return toProtoLineageResult(result), nil
+}
+
+// GetTableLineage returns the column lineage edges of a table valid at
+// as_of, or now when as_of is unset.
+func (s *LineageGRPCService) GetTableLineage(ctx context.Context, req *v1.GetTableLineageRequest) (*v1.GetTableLineageResponse, error) {
+	if req.Table == "" {
+		return nil, errors.BadRequest("INVALID_REQUEST", "table is required")
+	}
+	at := time.Now()
+	if req.AsOf != nil {
+		at = req.AsOf.AsTime()
+	}
+	edges := s.lineage.GetTableLineageAsOf(ctx, req.Database, req.Table, at)
+	out := &v1.GetTableLineageResponse{Edges: make([]*v1.LineageEdge, len(edges))}
+	for i, e := range edges {
+		out.Edges[i] = &v1.LineageEdge{
+			Source:    toProtoColumnRef(e.Source),
+			Target:    toProtoColumnRef(e.Target),
+			Operators: e.Operators,
+		}
+	}
+	return out, nil
+}
+
+// toProtoLineageResult converts a LineageResult to proto.
+func toProtoLineageResult(r *lineageCore.LineageResult) *v1.AnalyzeSQLResponse {
+	out := &v1.AnalyzeSQLResponse{}
+	if r == nil {
+		return out
+	}
+	for _, c := range r.Columns {
+		lineage := &v1.ColumnLineage{
+			Target:    toProtoColumnRef(c.Target),
+			Operators: c.Operators,
+		}
+		for _, src := range c.Sources {
+			lineage.Sources = append(lineage.Sources, toProtoColumnRef(src))
+		}
+		out.Columns = append(out.Columns, lineage)
+	}
+	for _, u := range r.Unresolved {
+		out.Unresolved = append(out.Unresolved, &v1.UnresolvedRef{
+			Database: u.Database,
+			Table:    u.Table,
+			Column:   u.Column,
+			Reason:   u.Reason,
+		})
+	}
+	return out
+}
+
+func toProtoColumnRef(ref lineageCore.ColumnRef) *v1.ColumnRef {
+	return &v1.ColumnRef{
+		Database:   ref.Database,
+		Table:      ref.Table,
+		Column:     ref.Column,
+		Confidence: string(ref.Confidence),
+	}
+}
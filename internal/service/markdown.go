@@ -0,0 +1,27 @@
+package service
+
+import (
+	"bytes"
+	"html"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// markdown renders descriptions as GitHub flavored markdown. Raw HTML and
+// dangerous link URLs (javascript: etc.) are omitted by the default
+// renderer, so the output is safe to embed in pages.
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// renderMarkdown renders a markdown description to HTML. The source is
+// returned escaped if it cannot be rendered.
+func renderMarkdown(source string) string {
+	if source == "" {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(source), &buf); err != nil {
+		return "<pre>" + html.EscapeString(source) + "</pre>"
+	}
+	return buf.String()
+}
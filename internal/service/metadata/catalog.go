@@ -0,0 +1,104 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-metadata/internal/biz"
+	"go-metadata/internal/collector"
+	"go-metadata/internal/store"
+)
+
+// SetTables sets the catalog of tables that syncs merge the tables they
+// store into, preserving the edits of users (see biz.MergeSynced). Without
+// one the tables are only stored. The policy violations and SLA breaches
+// the catalog finds are sent to the notifier of s.
+func (s *Service) SetTables(uc *biz.TableUsecase) {
+	uc.SetNotifier(s)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tables = uc
+}
+
+func (s *Service) getTables() *biz.TableUsecase {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables
+}
+
+// mergeCatalog merges a table a sync stored in st at syncedAt into the
+// catalog, if any. A table synced without statistics keeps the sizes of its
+// stored ones, so that a failed statistics query is not recorded as the
+// table shrinking to nothing. Its errors are store errors.
+func (s *Service) mergeCatalog(ctx context.Context, st store.Repository, key store.TableKey, metadata *collector.TableMetadata, syncedAt time.Time) error {
+	uc := s.getTables()
+	if uc == nil {
+		return nil
+	}
+	stats := metadata.Stats
+	if stats == nil {
+		var err error
+		if stats, err = st.GetStatistics(ctx, key); err != nil {
+			return err
+		}
+	}
+	if _, err := uc.UpsertSynced(ctx, catalogTable(key, metadata, stats, syncedAt)); err != nil {
+		return fmt.Errorf("merge %s into the catalog: %w", key, err)
+	}
+	return nil
+}
+
+// catalogTable converts a synced table and its statistics, if any, to the
+// catalog model. Its database is the catalog of the source.
+func catalogTable(key store.TableKey, metadata *collector.TableMetadata, stats *collector.TableStatistics, syncedAt time.Time) *biz.TableMetadata {
+	t := &biz.TableMetadata{
+		Database:    key.Catalog,
+		Schema:      key.Schema,
+		Name:        key.Table,
+		Type:        string(metadata.Type),
+		Source:      key.Source,
+		Comment:     metadata.Comment,
+		Columns:     make([]*biz.ColumnMetadata, 0, len(metadata.Columns)),
+		CollectedAt: syncedAt,
+	}
+	for i := range metadata.Columns {
+		c := &metadata.Columns[i]
+		col := &biz.ColumnMetadata{
+			Name:         c.Name,
+			Type:         collector.ColumnType(c),
+			DataType:     c.Type,
+			Nullable:     c.Nullable,
+			Comment:      c.Comment,
+			IsPrimaryKey: c.IsPrimaryKey,
+			Position:     c.OrdinalPosition,
+		}
+		if c.Length != nil {
+			col.Length = *c.Length
+		}
+		if c.Precision != nil {
+			col.Precision = *c.Precision
+		}
+		if c.Scale != nil {
+			col.Scale = *c.Scale
+		}
+		if c.Default != nil {
+			col.DefaultValue = *c.Default
+		}
+		t.Columns = append(t.Columns, col)
+	}
+	for _, idx := range metadata.Indexes {
+		t.Indexes = append(t.Indexes, &biz.IndexMetadata{
+			Name:     idx.Name,
+			Type:     idx.Type,
+			Columns:  idx.Columns,
+			IsUnique: idx.Unique,
+		})
+	}
+	if stats != nil {
+		t.RowCount = stats.RowCount
+		t.DataSize = stats.DataSizeBytes
+		t.ConsumerLag = stats.ConsumerLag
+	}
+	return t
+}
@@ -0,0 +1,154 @@
+package metadata
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-metadata/internal/biz"
+	"go-metadata/internal/collector"
+	"go-metadata/internal/store"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// memCatalog keeps the catalog tables of one source in memory. The other
+// methods of biz.TableRepo are not implemented.
+type memCatalog struct {
+	biz.TableRepo
+	tables map[string]*biz.TableMetadata
+}
+
+func (r *memCatalog) GetFromSource(ctx context.Context, source, database, schema, name string) (*biz.TableMetadata, error) {
+	t, ok := r.tables[database+"."+schema+"."+name]
+	if !ok {
+		return nil, biz.ErrTableNotFound
+	}
+	copied := *t
+	return &copied, nil
+}
+
+func (r *memCatalog) Save(ctx context.Context, t *biz.TableMetadata) (*biz.TableMetadata, error) {
+	key := t.Database + "." + t.Schema + "." + t.Name
+	if stored, ok := r.tables[key]; ok != (t.Version != 0) || ok && stored.Version != t.Version {
+		return nil, biz.ErrVersionConflict
+	}
+	if t.ID == "" {
+		t.ID = strconv.Itoa(len(r.tables) + 1)
+	}
+	t.Version++
+	copied := *t
+	r.tables[key] = &copied
+	return t, nil
+}
+
+func (r *memCatalog) SaveSizeSnapshot(ctx context.Context, s *biz.SizeSnapshot) error {
+	return nil
+}
+
+// memPolicies keeps policies and violations in memory.
+type memPolicies struct {
+	biz.PolicyRepo
+	set        *biz.PolicySet
+	violations map[string][]*biz.PolicyViolation
+}
+
+func (r *memPolicies) GetPolicies(ctx context.Context) (*biz.PolicySet, error) {
+	return r.set, nil
+}
+
+func (r *memPolicies) ListViolations(ctx context.Context, tableID string) ([]*biz.PolicyViolation, error) {
+	return r.violations[tableID], nil
+}
+
+func (r *memPolicies) SaveViolations(ctx context.Context, tableID string, violations []*biz.PolicyViolation, events []*biz.PolicyEvent) error {
+	r.violations[tableID] = violations
+	return nil
+}
+
+// recordingNotifier records the notifications it is sent.
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []*biz.Notification
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification *biz.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func TestMergeCatalogNotifiesPolicyEvents(t *testing.T) {
+	ctx := context.Background()
+	set, err := biz.ParsePolicies(strings.NewReader(`
+policies:
+  - name: shop-owner
+    match:
+      schema: shop
+    require:
+      owner: true
+  - name: orders-fresh
+    match:
+      table: orders
+    require:
+      freshness: 24h
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog := &memCatalog{tables: make(map[string]*biz.TableMetadata)}
+	uc := biz.NewTableUsecase(catalog, &memPolicies{set: set, violations: make(map[string][]*biz.PolicyViolation)}, log.NewStdLogger(io.Discard))
+
+	// The notifier may be set after the catalog
+	s := NewService(nil)
+	s.SetTables(uc)
+	notifier := &recordingNotifier{}
+	s.SetNotifier(notifier)
+
+	key := store.TableKey{Source: "mysql_prod", Catalog: "def", Schema: "shop", Table: "orders"}
+	metadata := &collector.TableMetadata{
+		Catalog: "def",
+		Schema:  "shop",
+		Name:    "orders",
+		Type:    collector.TableTypeTable,
+		Columns: []collector.Column{{OrdinalPosition: 1, Name: "id", Type: "bigint"}},
+		Stats:   &collector.TableStatistics{RowCount: 10},
+	}
+	syncedAt := time.Now()
+	if err := s.mergeCatalog(ctx, nil, key, metadata, syncedAt); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.sent) != 1 {
+		t.Fatalf("Expected 1 notification, got %d", len(notifier.sent))
+	}
+	if n := notifier.sent[0]; n.Event != biz.EventPolicyViolation || n.Source != "mysql_prod" || n.Table != "def.shop.orders" ||
+		!strings.Contains(n.Summary, "shop-owner") {
+		t.Errorf("Unexpected notification %+v", n)
+	}
+
+	// The table has not changed for two days: the next sync breaches its
+	// freshness
+	catalog.tables["def.shop.orders"].UpdatedAt = syncedAt.Add(-48 * time.Hour)
+	if err := s.mergeCatalog(ctx, nil, key, metadata, syncedAt.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.sent) != 2 {
+		t.Fatalf("Expected 2 notifications, got %d", len(notifier.sent))
+	}
+	if n := notifier.sent[1]; n.Event != biz.EventSLABreach || n.Table != "def.shop.orders" || !strings.Contains(n.Summary, "24h") {
+		t.Errorf("Unexpected notification %+v", n)
+	}
+
+	// Open violations are not notified again
+	if err := s.mergeCatalog(ctx, nil, key, metadata, syncedAt.Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.sent) != 2 {
+		t.Errorf("Expected no new notification, got %+v", notifier.sent[2:])
+	}
+}
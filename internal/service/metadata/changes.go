@@ -0,0 +1,99 @@
+package metadata
+
+import (
+	"context"
+	"time"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/config"
+	"go-metadata/internal/metrics"
+	"go-metadata/internal/redact"
+	"go-metadata/internal/store"
+)
+
+// previousTable returns the stored version of a table a sync refetches, to
+// find its schema changes, or nil if it is not stored yet.
+func (s *Service) previousTable(ctx context.Context, st store.Repository, key store.TableKey) *collector.TableMetadata {
+	t, err := st.GetTable(ctx, key)
+	if err != nil {
+		return nil
+	}
+	return t
+}
+
+// schemaChanged records the schema change of a table between its stored
+// version and the one refetched at syncedAt, if any, and notifies it if it
+// reaches the drift threshold of the policy of the table. Tables stored for
+// the first time are not changes.
+func (s *Service) schemaChanged(ctx context.Context, st store.Repository, key store.TableKey, previous, current *collector.TableMetadata, syncedAt time.Time, policy config.TierPolicy) error {
+	if previous == nil {
+		return nil
+	}
+	diff := collector.CompareTables(current, previous)
+	if diff == nil {
+		return nil
+	}
+	change := &store.SchemaChange{TableKey: key, IndexesChanged: len(diff.Indexes), ChangedAt: syncedAt}
+	for _, c := range diff.Columns {
+		switch c.Kind {
+		case collector.DiffMissing:
+			change.ColumnsAdded++
+		case collector.DiffExtra:
+			change.ColumnsDropped++
+		default:
+			change.ColumnsModified++
+		}
+	}
+	if diff.PrimaryKey != nil {
+		change.IndexesChanged++
+	}
+	if err := st.SaveSchemaChange(ctx, change); err != nil {
+		return err
+	}
+	metrics.GetMetrics().RecordSchemaChange(key.Source, change.ColumnsAdded, change.ColumnsDropped, change.ColumnsModified)
+	n := change.ColumnsAdded + change.ColumnsDropped + change.ColumnsModified + change.IndexesChanged
+	if policy.Notifies(n) {
+		s.notifyDrift(ctx, key, diff, syncedAt)
+	}
+	return nil
+}
+
+// syncFailed records a failed sync of a source in the store, if any, with
+// its error redacted.
+func (s *Service) syncFailed(ctx context.Context, source string, summary *SyncSummary, err error) error {
+	st := s.Store()
+	if st == nil {
+		return nil
+	}
+	// The sync may have failed because ctx is done
+	return st.SaveSyncFailure(context.WithoutCancel(ctx), &store.SyncFailure{
+		Source:       source,
+		TablesFailed: summary.Failed,
+		Error:        redact.String(err.Error()),
+		FailedAt:     time.Now(),
+	})
+}
+
+// SyncFailures returns the failed syncs of a source, or of all sources if
+// source is empty, since a time.
+func (s *Service) SyncFailures(ctx context.Context, source string, since time.Time) ([]store.SyncFailure, error) {
+	st := s.Store()
+	if st == nil {
+		return nil, ErrNoStore
+	}
+	return st.SyncFailures(ctx, source, since)
+}
+
+// ChangeRates rolls up the schema changes of a source, or of all sources if
+// source is empty, from since to now.
+func (s *Service) ChangeRates(ctx context.Context, source string, since time.Time) ([]*store.ChangeRate, error) {
+	st := s.Store()
+	if st == nil {
+		return nil, ErrNoStore
+	}
+	changes, err := st.SchemaChanges(ctx, source, since)
+	if err != nil {
+		return nil, err
+	}
+	return store.ChangeRates(changes, since, time.Now()), nil
+}
@@ -0,0 +1,106 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-metadata/internal/store"
+)
+
+// ErrHasDependents is returned when removing a table that other tables
+// depend on in the lineage graph without forcing it.
+var ErrHasDependents = errors.New("table has downstream dependents")
+
+// DependentsError reports a table that was not removed because other tables
+// depend on it.
+type DependentsError struct {
+	Table      store.TableKey
+	Dependents []string
+}
+
+func (e *DependentsError) Error() string {
+	return fmt.Sprintf("%s has downstream dependents: %s", e.Table, strings.Join(e.Dependents, ", "))
+}
+
+func (e *DependentsError) Unwrap() error {
+	return ErrHasDependents
+}
+
+// DependencyChecker finds the tables that depend on a table, e.g. in the
+// lineage graph.
+type DependencyChecker interface {
+	// Dependents returns the tables downstream of a table (database.table).
+	Dependents(ctx context.Context, table string) []string
+}
+
+// SetDependencyChecker sets the checker protecting tables with downstream
+// dependents from deletion. Without one tables are removed unchecked.
+func (s *Service) SetDependencyChecker(c DependencyChecker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deps = c
+}
+
+// dependents returns the tables depending on a stored table. Lineage names
+// tables schema.table, as view dependencies are recorded.
+func (s *Service) dependents(ctx context.Context, key store.TableKey) []string {
+	s.mu.Lock()
+	deps := s.deps
+	s.mu.Unlock()
+	if deps == nil {
+		return nil
+	}
+	name := key.Table
+	if key.Schema != "" {
+		name = key.Schema + "." + name
+	}
+	return deps.Dependents(ctx, name)
+}
+
+// DeleteTable removes a table from the store. A table that other tables
+// depend on is only removed with force; otherwise a *DependentsError listing
+// them is returned, so that a shared upstream definition is not removed by
+// accident.
+func (s *Service) DeleteTable(ctx context.Context, source, catalog, schema, table string, force bool) error {
+	st := s.Store()
+	if st == nil {
+		return ErrNoStore
+	}
+	key := store.TableKey{Source: source, Catalog: catalog, Schema: schema, Table: table}
+	if deps := s.dependents(ctx, key); len(deps) > 0 && !force {
+		return &DependentsError{Table: key, Dependents: deps}
+	}
+	return st.DeleteTable(ctx, key)
+}
+
+// prune removes the tables of a source not synced since before. Tables with
+// dependents are kept and reported unless force is set; the next sync
+// retries them.
+func (s *Service) prune(ctx context.Context, st store.Repository, source string, before time.Time, force bool) error {
+	s.mu.Lock()
+	checked := s.deps != nil
+	s.mu.Unlock()
+	if !checked || force {
+		_, err := st.PruneTables(ctx, source, before)
+		return err
+	}
+
+	stale, err := st.StaleTables(ctx, source, before)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, key := range stale {
+		if deps := s.dependents(ctx, key); len(deps) > 0 {
+			errs = append(errs, &DependentsError{Table: key, Dependents: deps})
+			continue
+		}
+		if err := st.DeleteTable(ctx, key); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,335 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/config"
+	"go-metadata/internal/store"
+)
+
+// SetHarvest sets how many tables the syncs of a source fetch in parallel
+// and how fast. Sources without one fetch their tables one at a time.
+func (s *Service) SetHarvest(source string, h config.HarvestConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.harvest[source] = h
+}
+
+// harvestTable is a table a sync dispatches to the workers, with its current
+// fingerprint and the policy of its tier.
+type harvestTable struct {
+	key         store.TableKey
+	fingerprint string
+	policy      config.TierPolicy
+}
+
+// harvester fetches the tables of a sync with a pool of workers and stores
+// them. A store error stops the sync: it cancels the context of the walk and
+// is returned by wait; the errors of single tables are collected.
+type harvester struct {
+	s        *Service
+	c        collector.Collector
+	st       store.Repository
+	syncedAt time.Time
+	cfg      config.HarvestConfig
+	tr       collector.Translator
+	limiter  *limiter
+	tables   chan harvestTable
+	wg       sync.WaitGroup
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	summary *SyncSummary
+	errs    []error
+	fatal   error
+}
+
+// startHarvest starts the workers of a sync of source.
+func (s *Service) startHarvest(ctx context.Context, c collector.Collector, st store.Repository, source string, syncedAt time.Time, summary *SyncSummary) *harvester {
+	s.mu.Lock()
+	cfg, ok := s.harvest[source]
+	tr := s.translator
+	s.mu.Unlock()
+	if !ok || cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+
+	h := &harvester{
+		s:        s,
+		c:        c,
+		st:       st,
+		syncedAt: syncedAt,
+		cfg:      cfg,
+		tr:       tr,
+		limiter:  newLimiter(cfg.RateLimit),
+		tables:   make(chan harvestTable),
+		summary:  summary,
+	}
+	h.ctx, h.cancel = context.WithCancel(ctx)
+	for i := 0; i < cfg.Concurrency; i++ {
+		h.wg.Add(1)
+		go h.work()
+	}
+	return h
+}
+
+// dispatch hands a table to the workers, waiting for a free one.
+func (h *harvester) dispatch(key store.TableKey, fingerprint string, policy config.TierPolicy) error {
+	select {
+	case h.tables <- harvestTable{key: key, fingerprint: fingerprint, policy: policy}:
+		return nil
+	case <-h.ctx.Done():
+		return h.ctx.Err()
+	}
+}
+
+// count runs fn on the summary.
+func (h *harvester) count(fn func(*SyncSummary)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fn(h.summary)
+}
+
+// wait waits for the dispatched tables and returns the errors of single
+// tables and the error that stopped the sync, if any.
+func (h *harvester) wait() ([]error, error) {
+	close(h.tables)
+	h.wg.Wait()
+	h.cancel()
+	return h.errs, h.fatal
+}
+
+func (h *harvester) work() {
+	defer h.wg.Done()
+	for t := range h.tables {
+		// Drain the tables dispatched before the sync stopped
+		if h.ctx.Err() != nil {
+			continue
+		}
+		if err := h.fetch(t); err != nil {
+			h.mu.Lock()
+			if h.fatal == nil {
+				h.fatal = err
+			}
+			h.mu.Unlock()
+			h.cancel()
+		}
+	}
+}
+
+// fetch harvests a table and stores it, with the statistics its policy
+// collects, and merges it into the catalog. Tables that fail are counted and
+// reported; only store errors are returned.
+func (h *harvester) fetch(t harvestTable) error {
+	if err := h.limiter.wait(h.ctx); err != nil {
+		return nil
+	}
+	s, c, key := h.s, h.c, t.key
+	current := t.fingerprint
+
+	opCtx, done := s.withTimeout(h.ctx, c, key.Source, "fetch_table_metadata", collector.TimeoutFetch)
+	resource := key.String()
+	metadata, err := guard(c, "fetch_table_metadata", resource, func() (*collector.TableMetadata, error) {
+		return c.FetchTableMetadata(opCtx, key.Catalog, key.Schema, key.Table)
+	})
+	if err = done(err); err != nil {
+		h.mu.Lock()
+		h.errs = append(h.errs, fmt.Errorf("%s.%s: %w", key.Schema, key.Table, err))
+		h.summary.Failed++
+		h.mu.Unlock()
+		return nil
+	}
+	switch t.policy.Statistics {
+	case config.StatisticsNone:
+		metadata.Stats = nil
+	case config.StatisticsTable:
+		if metadata.Stats != nil {
+			metadata.Stats.ColumnStats = nil
+		}
+	}
+	if metadata.Stats == nil && t.policy.Statistics != config.StatisticsNone {
+		opCtx, done := s.withTimeout(h.ctx, c, key.Source, "fetch_table_statistics", collector.TimeoutStats)
+		stats, err := guard(c, "fetch_table_statistics", resource, func() (*collector.TableStatistics, error) {
+			return c.FetchTableStatistics(opCtx, key.Catalog, key.Schema, key.Table)
+		})
+		if err = done(err); err == nil {
+			if stats != nil && t.policy.Statistics == config.StatisticsTable {
+				stats.ColumnStats = nil
+			}
+			metadata.Stats = stats
+		} else if collector.GetErrorCode(err) != collector.ErrCodeUnsupportedFeature {
+			h.mu.Lock()
+			h.errs = append(h.errs, fmt.Errorf("%s.%s statistics: %w", key.Schema, key.Table, err))
+			h.mu.Unlock()
+			// Without its statistics the table must be refetched
+			current = ""
+		}
+	}
+	if err := h.examples(key, metadata); err != nil {
+		h.mu.Lock()
+		h.errs = append(h.errs, fmt.Errorf("%s.%s examples: %w", key.Schema, key.Table, err))
+		h.mu.Unlock()
+		// Without its examples the table must be refetched
+		current = ""
+	}
+	if err := h.describe(metadata); err != nil {
+		h.mu.Lock()
+		h.errs = append(h.errs, fmt.Errorf("%s.%s descriptions: %w", key.Schema, key.Table, err))
+		h.mu.Unlock()
+		// Without its translations the table must be refetched
+		current = ""
+	}
+	previous := s.previousTable(h.ctx, h.st, key)
+	if err := h.st.SaveTable(h.ctx, key.Source, metadata, h.syncedAt); err != nil {
+		return err
+	}
+	if err := h.st.SaveFingerprint(h.ctx, key, current, h.syncedAt); err != nil {
+		return err
+	}
+	if err := s.schemaChanged(h.ctx, h.st, key, previous, metadata, h.syncedAt, t.policy); err != nil {
+		return err
+	}
+	if err := s.mergeCatalog(h.ctx, h.st, key, metadata, h.syncedAt); err != nil {
+		return err
+	}
+	h.count(func(s *SyncSummary) { s.Fetched++ })
+	return nil
+}
+
+// examples captures the example values of the columns of a table, if its
+// source opted in and its collector implements collector.ExampleCollector.
+// Views are not read, nor the columns classified as personal data.
+func (h *harvester) examples(key store.TableKey, metadata *collector.TableMetadata) error {
+	ec, ok := h.c.(collector.ExampleCollector)
+	if !ok || h.cfg.Examples < 1 || metadata.Type == collector.TableTypeView {
+		return nil
+	}
+	var columns []string
+	for _, col := range metadata.Columns {
+		if !collector.IsPII(col.Name, h.cfg.ExampleExclude) {
+			columns = append(columns, col.Name)
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	opCtx, done := h.s.withTimeout(h.ctx, h.c, key.Source, "fetch_examples", collector.TimeoutStats)
+	examples, err := guard(h.c, "fetch_examples", key.String(), func() (map[string][]string, error) {
+		return ec.FetchExamples(opCtx, key.Catalog, key.Schema, key.Table, columns, h.cfg.Examples)
+	})
+	if err = done(err); err != nil {
+		if collector.GetErrorCode(err) == collector.ErrCodeUnsupportedFeature {
+			return nil
+		}
+		return err
+	}
+	for i := range metadata.Columns {
+		col := &metadata.Columns[i]
+		col.Examples = nil
+		for _, v := range examples[col.Name] {
+			if len(col.Examples) == h.cfg.Examples {
+				break
+			}
+			col.Examples = append(col.Examples, collector.TruncateExample(v, h.cfg.ExampleLength))
+		}
+	}
+	return nil
+}
+
+// describe records the comments of a table and its columns in the languages
+// of its source: each comment in the language it is written in, translated
+// to the others if the service has a translator. Comments are translated
+// once per table however many columns share them.
+func (h *harvester) describe(metadata *collector.TableMetadata) error {
+	if len(h.cfg.Languages) == 0 {
+		return nil
+	}
+	translated := make(map[[2]string]string)
+	descriptions := func(comment string) (map[string]string, error) {
+		from := collector.DetectLanguage(comment)
+		if from == "" {
+			return nil, nil
+		}
+		d := map[string]string{from: comment}
+		for _, to := range h.cfg.Languages {
+			if collector.Description("", d, to) != "" || h.tr == nil {
+				continue
+			}
+			key := [2]string{comment, to}
+			text, ok := translated[key]
+			if !ok {
+				var err error
+				if text, err = h.tr.Translate(h.ctx, comment, from, to); err != nil {
+					return nil, fmt.Errorf("translate to %s: %w", to, err)
+				}
+				translated[key] = text
+			}
+			d[to] = text
+		}
+		return d, nil
+	}
+
+	var err error
+	if metadata.Descriptions, err = descriptions(metadata.Comment); err != nil {
+		return err
+	}
+	for i := range metadata.Columns {
+		col := &metadata.Columns[i]
+		if col.Descriptions, err = descriptions(col.Comment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// limiter spaces out operations to at most a rate per second. A nil limiter
+// does not limit.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newLimiter returns a limiter of perSecond operations, or nil if perSecond
+// is not positive.
+func newLimiter(perSecond float64) *limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &limiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until the next operation may start or ctx is done.
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,191 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/collectortest"
+	"go-metadata/internal/collector/config"
+	"go-metadata/internal/store"
+)
+
+// trackingCollector records when each table fetch starts and how many run at
+// once.
+type trackingCollector struct {
+	*collectortest.Collector
+
+	mu       sync.Mutex
+	inFlight int
+	maxIn    int
+	started  []time.Time
+}
+
+func (c *trackingCollector) FetchTableMetadata(ctx context.Context, catalog, schema, table string) (*collector.TableMetadata, error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.maxIn = max(c.maxIn, c.inFlight)
+	c.started = append(c.started, time.Now())
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.inFlight--
+		c.mu.Unlock()
+	}()
+	return c.Collector.FetchTableMetadata(ctx, catalog, schema, table)
+}
+
+// harvestStore keeps the tables a harvest saves in memory; saving failTable
+// fails. The other methods of store.Repository are not implemented.
+type harvestStore struct {
+	store.Repository
+	failTable string
+
+	mu       sync.Mutex
+	saved    map[string]bool
+	failures []*store.SyncFailure
+}
+
+func (s *harvestStore) GetTable(ctx context.Context, key store.TableKey) (*collector.TableMetadata, error) {
+	return nil, store.ErrNotFound
+}
+
+func (s *harvestStore) SaveTable(ctx context.Context, source string, t *collector.TableMetadata, syncedAt time.Time) error {
+	if t.Name == s.failTable {
+		return errors.New("disk full")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.saved == nil {
+		s.saved = make(map[string]bool)
+	}
+	s.saved[t.Name] = true
+	return nil
+}
+
+func (s *harvestStore) SaveFingerprint(ctx context.Context, key store.TableKey, fingerprint string, syncedAt time.Time) error {
+	return nil
+}
+
+func (s *harvestStore) SaveSyncFailure(ctx context.Context, f *store.SyncFailure) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, f)
+	return nil
+}
+
+// newHarvestCollector returns a connected collector with the given tables in
+// def.shop.
+func newHarvestCollector(t *testing.T, tables ...string) *trackingCollector {
+	t.Helper()
+	c := collectortest.New(collector.CategoryRDBMS, "mysql")
+	for _, name := range tables {
+		c.AddTable(&collector.TableMetadata{Catalog: "def", Schema: "shop", Name: name, Type: collector.TableTypeTable})
+	}
+	if err := c.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return &trackingCollector{Collector: c}
+}
+
+// harvestTables runs a harvest of tables with cfg and returns its summary,
+// the errors of single tables and the error that stopped it.
+func harvestTables(c collector.Collector, st store.Repository, cfg config.HarvestConfig, tables ...string) (*SyncSummary, []error, error) {
+	s := NewService(nil)
+	s.SetHarvest("mysql_prod", cfg)
+	summary := &SyncSummary{}
+	h := s.startHarvest(context.Background(), c, st, "mysql_prod", time.Now(), summary)
+	for _, name := range tables {
+		key := store.TableKey{Source: "mysql_prod", Catalog: "def", Schema: "shop", Table: name}
+		if err := h.dispatch(key, "", config.TierPolicy{Statistics: config.StatisticsNone}); err != nil {
+			break
+		}
+	}
+	errs, fatal := h.wait()
+	return summary, errs, fatal
+}
+
+func TestHarvestBoundsConcurrency(t *testing.T) {
+	tables := []string{"t1", "t2", "t3", "t4", "t5", "t6", "t7", "t8", "t9"}
+	c := newHarvestCollector(t, tables...)
+	c.SetLatency(collectortest.OpFetchTableMetadata, 50*time.Millisecond)
+	st := &harvestStore{}
+
+	summary, errs, fatal := harvestTables(c, st, config.HarvestConfig{Concurrency: 3}, tables...)
+	if fatal != nil || len(errs) > 0 {
+		t.Fatalf("Expected the harvest to succeed, got %v, %v", errs, fatal)
+	}
+	if c.maxIn != 3 {
+		t.Errorf("Expected 3 fetches at most at once, got %d", c.maxIn)
+	}
+	if summary.Fetched != len(tables) || len(st.saved) != len(tables) {
+		t.Errorf("Expected %d tables fetched and saved, got %+v and %d saved", len(tables), summary, len(st.saved))
+	}
+}
+
+func TestHarvestRateLimit(t *testing.T) {
+	tables := []string{"t1", "t2", "t3", "t4", "t5"}
+	c := newHarvestCollector(t, tables...)
+
+	// 20 tables per second: one every 50ms, however many workers are free
+	if _, _, fatal := harvestTables(c, &harvestStore{}, config.HarvestConfig{Concurrency: 4, RateLimit: 20}, tables...); fatal != nil {
+		t.Fatal(fatal)
+	}
+	if len(c.started) != len(tables) {
+		t.Fatalf("Expected %d fetches, got %d", len(tables), len(c.started))
+	}
+	sort.Slice(c.started, func(i, j int) bool { return c.started[i].Before(c.started[j]) })
+	for i := 1; i < len(c.started); i++ {
+		if gap := c.started[i].Sub(c.started[i-1]); gap < 40*time.Millisecond {
+			t.Errorf("Expected fetches 50ms apart, fetch %d started %v after the previous one", i, gap)
+		}
+	}
+}
+
+func TestHarvestStoreErrorStopsSync(t *testing.T) {
+	tables := []string{"t1", "t2", "t3", "t4", "t5"}
+	c := newHarvestCollector(t, tables...)
+	c.SetLatency(collectortest.OpFetchTableMetadata, 10*time.Millisecond)
+	st := &harvestStore{failTable: "t1"}
+
+	summary, _, fatal := harvestTables(c, st, config.HarvestConfig{Concurrency: 1}, tables...)
+	if fatal == nil || fatal.Error() != "disk full" {
+		t.Fatalf("Expected the store error to stop the harvest, got %v", fatal)
+	}
+	// The tables dispatched after the store failed are not fetched
+	if n := c.Calls(collectortest.OpFetchTableMetadata); n != 1 {
+		t.Errorf("Expected 1 fetch, got %d", n)
+	}
+	if summary.Fetched != 0 || len(st.saved) != 0 {
+		t.Errorf("Expected no table stored, got %+v and %d saved", summary, len(st.saved))
+	}
+}
+
+func TestHarvestCollectsTableErrors(t *testing.T) {
+	c := newHarvestCollector(t, "orders", "customers")
+	st := &harvestStore{}
+
+	// dropped and renamed are not in the source
+	summary, errs, fatal := harvestTables(c, st, config.HarvestConfig{Concurrency: 2}, "orders", "dropped", "customers", "renamed")
+	if fatal != nil {
+		t.Fatalf("Expected errors of single tables not to stop the harvest, got %v", fatal)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 table errors, got %v", errs)
+	}
+	for _, err := range errs {
+		if collector.GetErrorCode(err) != collector.ErrCodeNotFound {
+			t.Errorf("Expected a not found error, got %v", err)
+		}
+	}
+	if summary.Fetched != 2 || summary.Failed != 2 {
+		t.Errorf("Expected 2 tables fetched and 2 failed, got %+v", summary)
+	}
+	if !st.saved["orders"] || !st.saved["customers"] {
+		t.Errorf("Expected orders and customers to be saved, got %v", st.saved)
+	}
+}
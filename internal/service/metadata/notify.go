@@ -0,0 +1,112 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-metadata/internal/biz"
+	"go-metadata/internal/collector"
+	"go-metadata/internal/store"
+)
+
+// SetNotifier sets the notifier of the schema changes and policy events
+// found by syncs and of failed syncs. Without one no notifications are sent.
+// Notification errors are left to the notifier to report: they never fail a
+// sync.
+func (s *Service) SetNotifier(n biz.Notifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifier = n
+}
+
+func (s *Service) getNotifier() biz.Notifier {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.notifier
+}
+
+// Notify sends n with the notifier set with SetNotifier, if any. The catalog
+// set with SetTables notifies its policy events through it.
+func (s *Service) Notify(ctx context.Context, n *biz.Notification) error {
+	notifier := s.getNotifier()
+	if notifier == nil {
+		return nil
+	}
+	return notifier.Notify(ctx, n)
+}
+
+// notifyDrift notifies the schema changes of a table between its stored
+// version and the one refetched at syncedAt.
+func (s *Service) notifyDrift(ctx context.Context, key store.TableKey, diff *collector.TableDiff, syncedAt time.Time) {
+	n := s.getNotifier()
+	if n == nil {
+		return
+	}
+	changes := schemaChanges(diff)
+	n.Notify(ctx, &biz.Notification{
+		Event:   biz.EventSchemaDrift,
+		Source:  key.Source,
+		Table:   qualifiedTable(key),
+		Summary: fmt.Sprintf("schema changed: %d changes", len(changes)),
+		Changes: changes,
+		At:      syncedAt,
+	})
+}
+
+// notifySyncFailed notifies the errors of a failed sync, one per line of
+// err.
+func (s *Service) notifySyncFailed(ctx context.Context, source string, summary *SyncSummary, err error) {
+	n := s.getNotifier()
+	if n == nil {
+		return
+	}
+	// The sync may have failed because ctx is done
+	n.Notify(context.WithoutCancel(ctx), &biz.Notification{
+		Event:   biz.EventSyncFailed,
+		Source:  source,
+		Summary: fmt.Sprintf("%d tables fetched, %d unchanged, %d deferred, %d failed", summary.Fetched, summary.Unchanged, summary.Deferred, summary.Failed),
+		Changes: strings.Split(err.Error(), "\n"),
+		At:      time.Now(),
+	})
+}
+
+// schemaChanges describes a table diff from the stored version to the
+// current one.
+func schemaChanges(d *collector.TableDiff) []string {
+	var changes []string
+	for _, c := range d.Columns {
+		switch c.Kind {
+		case collector.DiffMissing:
+			changes = append(changes, fmt.Sprintf("added column %s %s", c.Column, collector.ColumnType(c.Source)))
+		case collector.DiffExtra:
+			changes = append(changes, fmt.Sprintf("dropped column %s", c.Column))
+		default:
+			changes = append(changes, fmt.Sprintf("changed column %s: %s", c.Column, strings.Join(c.Changes, ", ")))
+		}
+	}
+	for _, i := range d.Indexes {
+		switch i.Kind {
+		case collector.DiffMissing:
+			changes = append(changes, fmt.Sprintf("added index %s (%s)", i.Index, strings.Join(i.Source.Columns, ", ")))
+		case collector.DiffExtra:
+			changes = append(changes, fmt.Sprintf("dropped index %s", i.Index))
+		default:
+			changes = append(changes, fmt.Sprintf("changed index %s (%s) -> (%s)", i.Index, strings.Join(i.Target.Columns, ", "), strings.Join(i.Source.Columns, ", ")))
+		}
+	}
+	if pk := d.PrimaryKey; pk != nil {
+		changes = append(changes, fmt.Sprintf("changed primary key (%s) -> (%s)", strings.Join(pk.Target, ", "), strings.Join(pk.Source, ", ")))
+	}
+	return changes
+}
+
+// qualifiedTable is the name of a table in notifications, without its
+// source.
+func qualifiedTable(key store.TableKey) string {
+	if key.Schema == "" {
+		return key.Catalog + "." + key.Table
+	}
+	return key.Catalog + "." + key.Schema + "." + key.Table
+}
@@ -0,0 +1,84 @@
+package metadata
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-metadata/internal/collector"
+)
+
+// queryLogCursor is the position of the reads of the query log of a source:
+// the time of the latest statement read and, for logs that count the calls
+// of statements cumulatively, their calls at the previous read.
+type queryLogCursor struct {
+	mu    sync.Mutex
+	since time.Time
+	calls map[string]int64
+}
+
+// QueryLogEnabled reports whether lineage is harvested from the query log of
+// a source, as set with SetHarvest.
+func (s *Service) QueryLogEnabled(source string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.harvest[source].QueryLog
+}
+
+// ReadQueryLog reads the statements of the query log of a source executed
+// after since and after its previous read, so that repeated reads tail the
+// log. Calls are the executions since the previous read: for logs that count
+// them cumulatively, such as pg_stat_statements, the first read returns the
+// counts since they were last reset and the statements not executed since
+// are left out. Sources whose collector does not implement
+// collector.QueryLogCollector return an UNSUPPORTED_FEATURE error.
+func (s *Service) ReadQueryLog(ctx context.Context, source string, since time.Time) ([]collector.QueryLogEntry, error) {
+	c, err := s.collector(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	qc, ok := c.(collector.QueryLogCollector)
+	if !ok {
+		return nil, collector.NewUnsupportedFeatureError(c.Type(), "fetch_query_log", "query log")
+	}
+
+	s.mu.Lock()
+	cursor, ok := s.queryLogs[source]
+	if !ok {
+		cursor = &queryLogCursor{calls: make(map[string]int64)}
+		s.queryLogs[source] = cursor
+	}
+	s.mu.Unlock()
+
+	cursor.mu.Lock()
+	defer cursor.mu.Unlock()
+	if cursor.since.After(since) {
+		since = cursor.since
+	}
+	opCtx, done := s.withTimeout(ctx, c, source, "fetch_query_log", collector.TimeoutStats)
+	entries, err := guard(c, "fetch_query_log", source, func() ([]collector.QueryLogEntry, error) {
+		return qc.FetchQueryLog(opCtx, since)
+	})
+	if err = done(err); err != nil {
+		return nil, err
+	}
+
+	read := entries[:0]
+	for _, e := range entries {
+		if e.Cumulative && e.ID != "" {
+			prev, seen := cursor.calls[e.ID]
+			cursor.calls[e.ID] = e.Calls
+			if seen && e.Calls >= prev {
+				// Counts lower than at the previous read were reset
+				e.Calls -= prev
+			}
+		}
+		if e.LastExecuted.After(cursor.since) {
+			cursor.since = e.LastExecuted
+		}
+		if e.Calls > 0 {
+			read = append(read, e)
+		}
+	}
+	return read, nil
+}
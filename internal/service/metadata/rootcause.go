@@ -0,0 +1,231 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/lineage"
+	"go-metadata/internal/store"
+)
+
+// Kinds of likely causes of a data incident.
+const (
+	// CauseSchemaChange is a schema change of the table or an ancestor.
+	CauseSchemaChange = "schema_change"
+	// CauseSyncFailed is a failed sync of the source of an ancestor.
+	CauseSyncFailed = "sync_failed"
+	// CauseStale is an ancestor whose data is older than its cadence allows.
+	CauseStale = "stale"
+)
+
+// Weights of the kinds of causes. Dropped and modified columns break
+// readers, added columns and index changes rarely do.
+const (
+	weightBreakingChange = 3.0
+	weightSchemaChange   = 1.0
+	weightSyncFailed     = 2.0
+	weightStale          = 2.0
+)
+
+// RootCauseOptions configures RootCause.
+type RootCauseOptions struct {
+	// Since and Until are the window of the incident: the schema changes
+	// and failed syncs within it are correlated.
+	Since, Until time.Time
+	// Freshness is the cadence tables are expected to be loaded at; the
+	// tables stale at Until are causes. Freshness is not checked without a
+	// cadence.
+	Freshness collector.FreshnessOptions
+}
+
+// Cause is a likely cause of an incident on a table.
+type Cause struct {
+	Kind string `json:"kind"`
+	// Table is the table or ancestor, as named in the lineage, and Hops the
+	// number of dependencies from the table to it, 0 for the table itself.
+	Table  string    `json:"table"`
+	Hops   int       `json:"hops"`
+	Source string    `json:"source"`
+	At     time.Time `json:"at"`
+	Detail string    `json:"detail"`
+	// Score ranks the causes: the weight of the kind of cause, divided by
+	// the number of tables from the table to it and reduced by up to half
+	// for causes early in the window.
+	Score float64 `json:"score"`
+}
+
+// RootCause correlates the schema changes, failed syncs and stale data of a
+// broken table and its ancestors, found in its upstream lineage, within the
+// window of an incident, and returns them most likely first. Tables are
+// matched to the stored tables of any source by name; those not in the
+// store are skipped.
+func (s *Service) RootCause(ctx context.Context, table string, ancestors []lineage.Ancestor, opts RootCauseOptions) ([]Cause, error) {
+	st := s.Store()
+	if st == nil {
+		return nil, ErrNoStore
+	}
+	if opts.Until.IsZero() {
+		opts.Until = time.Now()
+	}
+
+	// The stored tables of the table and its ancestors, nearest first
+	type storedTable struct {
+		lineage.Ancestor
+		keys []store.TableKey
+	}
+	var tables []storedTable
+	tableOf := make(map[store.TableKey]int)
+	for _, a := range append([]lineage.Ancestor{{Table: table}}, ancestors...) {
+		database, name := "", a.Table
+		if i := strings.LastIndex(a.Table, "."); i >= 0 {
+			database, name = a.Table[:i], a.Table[i+1:]
+		}
+		keys, err := st.FindTables(ctx, database, name)
+		if err != nil {
+			return nil, err
+		}
+		var stored []store.TableKey
+		for _, key := range keys {
+			if _, ok := tableOf[key]; !ok {
+				tableOf[key] = len(tables)
+				stored = append(stored, key)
+			}
+		}
+		if len(stored) > 0 {
+			tables = append(tables, storedTable{Ancestor: a, keys: stored})
+		}
+	}
+
+	var causes []Cause
+	add := func(c Cause, weight float64) {
+		c.Score = weight / float64(c.Hops+1) * recency(c.At, opts.Since, opts.Until)
+		causes = append(causes, c)
+	}
+
+	changes, err := st.SchemaChanges(ctx, "", opts.Since)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range changes {
+		i, ok := tableOf[c.TableKey]
+		if !ok || c.ChangedAt.After(opts.Until) {
+			continue
+		}
+		weight := weightSchemaChange
+		if c.ColumnsDropped > 0 || c.ColumnsModified > 0 {
+			weight = weightBreakingChange
+		}
+		add(Cause{
+			Kind:   CauseSchemaChange,
+			Table:  tables[i].Table,
+			Hops:   tables[i].Hops,
+			Source: c.Source,
+			At:     c.ChangedAt,
+			Detail: fmt.Sprintf("%s: %s", qualifiedTable(c.TableKey), changeSummary(c)),
+		}, weight)
+	}
+
+	failures, err := st.SyncFailures(ctx, "", opts.Since)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range failures {
+		if f.FailedAt.After(opts.Until) {
+			continue
+		}
+		// A failed sync is a cause of the nearest table of its source
+		for _, t := range tables {
+			if !hasSource(t.keys, f.Source) {
+				continue
+			}
+			detail, _, _ := strings.Cut(f.Error, "\n")
+			if f.TablesFailed > 0 {
+				detail = fmt.Sprintf("%d tables failed: %s", f.TablesFailed, detail)
+			}
+			add(Cause{
+				Kind:   CauseSyncFailed,
+				Table:  t.Table,
+				Hops:   t.Hops,
+				Source: f.Source,
+				At:     f.FailedAt,
+				Detail: detail,
+			}, weightSyncFailed)
+			break
+		}
+	}
+
+	if opts.Freshness.Cadence > 0 {
+		for _, t := range tables {
+			for _, key := range t.keys {
+				stored, err := st.GetTable(ctx, key)
+				if err != nil {
+					return nil, err
+				}
+				f, err := collector.CheckFreshness(stored, opts.Until, opts.Freshness)
+				if err != nil || !f.Stale {
+					// Tables without a date partition or timestamp statistic
+					continue
+				}
+				add(Cause{
+					Kind:   CauseStale,
+					Table:  t.Table,
+					Hops:   t.Hops,
+					Source: key.Source,
+					At:     f.Deadline,
+					Detail: fmt.Sprintf("%s: latest data %s is %s old", qualifiedTable(key),
+						f.Latest.Format(time.RFC3339), f.Age.Round(time.Minute)),
+				}, weightStale)
+			}
+		}
+	}
+
+	sort.SliceStable(causes, func(i, j int) bool {
+		if causes[i].Score != causes[j].Score {
+			return causes[i].Score > causes[j].Score
+		}
+		return causes[i].At.After(causes[j].At)
+	})
+	return causes, nil
+}
+
+// recency is 1 for causes at the end of the window, when the incident was
+// found, down to 0.5 for causes at or before its start.
+func recency(at, since, until time.Time) float64 {
+	window := until.Sub(since)
+	if window <= 0 || !at.After(since) {
+		return 0.5
+	}
+	return 0.5 + 0.5*min(float64(at.Sub(since))/float64(window), 1)
+}
+
+// changeSummary describes the columns and indexes a schema change changed.
+func changeSummary(c store.SchemaChange) string {
+	var parts []string
+	for _, p := range []struct {
+		n    int
+		what string
+	}{
+		{c.ColumnsDropped, "columns dropped"},
+		{c.ColumnsModified, "columns modified"},
+		{c.ColumnsAdded, "columns added"},
+		{c.IndexesChanged, "indexes changed"},
+	} {
+		if p.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", p.n, p.what))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func hasSource(keys []store.TableKey, source string) bool {
+	for _, key := range keys {
+		if key.Source == source {
+			return true
+		}
+	}
+	return false
+}
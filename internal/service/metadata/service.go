@@ -3,37 +3,536 @@ package metadata
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
 
+	"go-metadata/internal/biz"
 	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/config"
 	"go-metadata/internal/data/graph"
 	"go-metadata/internal/model"
+	"go-metadata/internal/store"
 )
 
+// ErrSourceNotFound is returned for a source without a registered collector.
+var ErrSourceNotFound = errors.New("source not found")
+
+// ErrNoStore is returned when reading stored metadata without a configured store.
+var ErrNoStore = errors.New("metadata store not configured")
+
+// walkPageSize is the page size used by WalkTables.
+const walkPageSize = 500
+
 // Service provides metadata management operations.
 type Service struct {
+	mu         sync.Mutex
 	collectors map[string]collector.Collector
+	connected  map[string]bool
+	timeouts   map[string]*config.TimeoutConfig
+	harvest    map[string]config.HarvestConfig
+	queryLogs  map[string]*queryLogCursor
+	translator collector.Translator
+	tiers      *config.TierConfig
 	graphDB    graph.GraphDB
+	store      store.Repository
+	deps       DependencyChecker
+	notifier   biz.Notifier
+	tables     *biz.TableUsecase
 }
 
 // NewService creates a new metadata service.
 func NewService(graphDB graph.GraphDB) *Service {
 	return &Service{
 		collectors: make(map[string]collector.Collector),
+		connected:  make(map[string]bool),
+		timeouts:   make(map[string]*config.TimeoutConfig),
+		harvest:    make(map[string]config.HarvestConfig),
+		queryLogs:  make(map[string]*queryLogCursor),
 		graphDB:    graphDB,
 	}
 }
 
+// SetStore sets the store that syncs persist harvested metadata to.
+func (s *Service) SetStore(st store.Repository) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = st
+}
+
+// Store returns the metadata store, or nil if none is configured.
+func (s *Service) Store() store.Repository {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store
+}
+
 // RegisterCollector registers a collector for a data source.
 func (s *Service) RegisterCollector(name string, c collector.Collector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.collectors[name] = c
+	delete(s.connected, name)
 }
 
-// SyncMetadata synchronizes metadata from a data source.
-func (s *Service) SyncMetadata(ctx context.Context, source string) error {
-	// TODO: Implement metadata synchronization
+// SetTranslator sets the hook translating the comments of tables and columns
+// to the languages of their sources. A nil translator only records the
+// comments in the language they are written in.
+func (s *Service) SetTranslator(t collector.Translator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.translator = t
+}
+
+// SetTimeouts sets the per-operation timeouts of the syncs of a source. A nil
+// config removes them.
+func (s *Service) SetTimeouts(source string, t *config.TimeoutConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t == nil {
+		delete(s.timeouts, source)
+		return
+	}
+	s.timeouts[source] = t
+}
+
+// Sources returns the names of the registered data sources.
+func (s *Service) Sources() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.collectors))
+	for name := range s.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Category returns the category of the collector of a source.
+func (s *Service) Category(source string) (collector.DataSourceCategory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.collectors[source]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSourceNotFound, source)
+	}
+	return c.Category(), nil
+}
+
+// DiscoverCatalogs lists the catalogs of a data source.
+func (s *Service) DiscoverCatalogs(ctx context.Context, source string) ([]collector.CatalogInfo, error) {
+	c, err := s.collector(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return guard(c, "discover_catalogs", source, func() ([]collector.CatalogInfo, error) {
+		return c.DiscoverCatalogs(ctx)
+	})
+}
+
+// ListSchemas lists the schemas of a catalog of a data source.
+func (s *Service) ListSchemas(ctx context.Context, source, catalog string) ([]string, error) {
+	c, err := s.collector(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return guard(c, "list_schemas", source+":"+catalog, func() ([]string, error) {
+		return c.ListSchemas(ctx, catalog)
+	})
+}
+
+// ListSourceTables lists a page of the tables of a schema of a data source.
+func (s *Service) ListSourceTables(ctx context.Context, source, catalog, schema string, opts *collector.ListOptions) (*collector.TableListResult, error) {
+	c, err := s.collector(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return guard(c, "list_tables", schemaResource(source, catalog, schema), func() (*collector.TableListResult, error) {
+		return c.ListTables(ctx, catalog, schema, opts)
+	})
+}
+
+// FetchTableMetadata fetches the metadata of a table from a data source.
+func (s *Service) FetchTableMetadata(ctx context.Context, source, catalog, schema, table string) (*collector.TableMetadata, error) {
+	c, err := s.collector(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return guard(c, "fetch_table_metadata", tableResource(source, catalog, schema, table), func() (*collector.TableMetadata, error) {
+		return c.FetchTableMetadata(ctx, catalog, schema, table)
+	})
+}
+
+// FetchTableStatistics fetches the statistics of a table from a data source.
+func (s *Service) FetchTableStatistics(ctx context.Context, source, catalog, schema, table string) (*collector.TableStatistics, error) {
+	c, err := s.collector(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return guard(c, "fetch_table_statistics", tableResource(source, catalog, schema, table), func() (*collector.TableStatistics, error) {
+		return c.FetchTableStatistics(ctx, catalog, schema, table)
+	})
+}
+
+// FetchPartitions fetches the partitions of a table from a data source.
+func (s *Service) FetchPartitions(ctx context.Context, source, catalog, schema, table string) ([]collector.PartitionInfo, error) {
+	c, err := s.collector(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return guard(c, "fetch_partitions", tableResource(source, catalog, schema, table), func() ([]collector.PartitionInfo, error) {
+		return c.FetchPartitions(ctx, catalog, schema, table)
+	})
+}
+
+// FetchViewDependencies reads the dependencies of the views of every schema
+// of a source from its system catalog. Sources whose collector does not
+// implement collector.ViewDependencyCollector return an UNSUPPORTED_FEATURE
+// error.
+func (s *Service) FetchViewDependencies(ctx context.Context, source string) ([]collector.ViewDependency, error) {
+	c, err := s.collector(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	vc, ok := c.(collector.ViewDependencyCollector)
+	if !ok {
+		return nil, collector.NewUnsupportedFeatureError(c.Type(), "fetch_view_dependencies", "view dependencies")
+	}
+	catalogs, err := guard(c, "discover_catalogs", source, func() ([]collector.CatalogInfo, error) {
+		return c.DiscoverCatalogs(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var deps []collector.ViewDependency
+	for _, catalog := range catalogs {
+		schemas, err := guard(c, "list_schemas", source+":"+catalog.Catalog, func() ([]string, error) {
+			return c.ListSchemas(ctx, catalog.Catalog)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, schema := range schemas {
+			schemaDeps, err := guard(c, "fetch_view_dependencies", schemaResource(source, catalog.Catalog, schema), func() ([]collector.ViewDependency, error) {
+				return vc.FetchViewDependencies(ctx, catalog.Catalog, schema)
+			})
+			if err != nil {
+				return nil, err
+			}
+			deps = append(deps, schemaDeps...)
+		}
+	}
+	return deps, nil
+}
+
+// WalkTables calls fn for every table of every schema of every catalog of a
+// source, paging through the table lists. Each listing is bounded by the list
+// timeout of the source. It stops at the first error.
+func (s *Service) WalkTables(ctx context.Context, source string, fn func(catalog, schema, table string) error) error {
+	c, err := s.collector(ctx, source)
+	if err != nil {
+		return err
+	}
+	opCtx, done := s.withTimeout(ctx, c, source, "discover_catalogs", collector.TimeoutList)
+	catalogs, err := guard(c, "discover_catalogs", source, func() ([]collector.CatalogInfo, error) {
+		return c.DiscoverCatalogs(opCtx)
+	})
+	if err = done(err); err != nil {
+		return err
+	}
+	for _, catalog := range catalogs {
+		opCtx, done := s.withTimeout(ctx, c, source, "list_schemas", collector.TimeoutList)
+		schemas, err := guard(c, "list_schemas", source+":"+catalog.Catalog, func() ([]string, error) {
+			return c.ListSchemas(opCtx, catalog.Catalog)
+		})
+		if err = done(err); err != nil {
+			return err
+		}
+		for _, schema := range schemas {
+			opts := &collector.ListOptions{PageSize: walkPageSize}
+			for {
+				opCtx, done := s.withTimeout(ctx, c, source, "list_tables", collector.TimeoutList)
+				page, err := guard(c, "list_tables", schemaResource(source, catalog.Catalog, schema), func() (*collector.TableListResult, error) {
+					return c.ListTables(opCtx, catalog.Catalog, schema, opts)
+				})
+				if err = done(err); err != nil {
+					return err
+				}
+				for _, table := range page.Tables {
+					if err := fn(catalog.Catalog, schema, table); err != nil {
+						return err
+					}
+				}
+				if page.NextPageToken == "" {
+					break
+				}
+				opts.PageToken = page.NextPageToken
+			}
+		}
+	}
 	return nil
 }
 
+// withTimeout bounds an operation of a source by its timeout of kind, if one
+// is set. The returned function must be called with the result of the
+// operation: it releases the context and turns the error of an operation
+// whose own timeout expired, rather than ctx, into a TIMEOUT error of kind.
+func (s *Service) withTimeout(ctx context.Context, c collector.Collector, source, operation string, kind collector.TimeoutKind) (context.Context, func(error) error) {
+	s.mu.Lock()
+	limit := s.timeouts[source].Duration(kind)
+	s.mu.Unlock()
+	if limit <= 0 {
+		return ctx, func(err error) error { return err }
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, limit)
+	return opCtx, func(err error) error {
+		expired := errors.Is(opCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+		cancel()
+		if err != nil && expired {
+			return collector.NewOperationTimeoutError(c.Type(), operation, kind, limit, err)
+		}
+		return err
+	}
+}
+
+// Close closes the connections of the collectors.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for name := range s.connected {
+		c := s.collectors[name]
+		if err := guardErr(c, "close", name, c.Close); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	s.connected = make(map[string]bool)
+	return errors.Join(errs...)
+}
+
+// collector returns the collector of a source, connecting it on first use.
+func (s *Service) collector(ctx context.Context, source string) (collector.Collector, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.collectors[source]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSourceNotFound, source)
+	}
+	if !s.connected[source] {
+		err := guardErr(c, "connect", source, func() error {
+			return c.Connect(ctx)
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.connected[source] = true
+	}
+	return c, nil
+}
+
+// guard calls op, an operation of collector c on resource, and turns a panic
+// of the collector into an INTERNAL error naming the operation and resource,
+// so that one faulty driver fails its call rather than the whole process.
+// Panics of goroutines started by the collector cannot be recovered here.
+func guard[T any](c collector.Collector, operation, resource string, op func() (T, error)) (result T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			perr := collector.NewPanicError(c.Type(), operation, resource, r, debug.Stack())
+			perr.Category = c.Category()
+			err = perr
+		}
+	}()
+	return op()
+}
+
+// guardErr is guard for operations returning only an error.
+func guardErr(c collector.Collector, operation, resource string, op func() error) error {
+	_, err := guard(c, operation, resource, func() (struct{}, error) {
+		return struct{}{}, op()
+	})
+	return err
+}
+
+// schemaResource names a schema of a source in errors.
+func schemaResource(source, catalog, schema string) string {
+	return source + ":" + catalog + "." + schema
+}
+
+// tableResource names a table of a source in errors, as store.TableKey does.
+func tableResource(source, catalog, schema, table string) string {
+	return store.TableKey{Source: source, Catalog: catalog, Schema: schema, Table: table}.String()
+}
+
+// SyncOptions configures a sync.
+type SyncOptions struct {
+	// Incremental only refetches the tables whose change fingerprint differs
+	// from the one stored by the previous sync. Sources whose collector does
+	// not implement collector.ChangeDetector are synced in full.
+	Incremental bool
+	// Force prunes the tables dropped from the source even if other tables
+	// depend on them; otherwise they are kept and reported.
+	Force bool
+}
+
+// SyncSummary counts the tables of a sync.
+type SyncSummary struct {
+	// Fetched tables were harvested from the source, Unchanged tables were
+	// skipped by an incremental sync, Deferred tables were skipped because
+	// the sync interval of their tier has not elapsed and Failed tables could
+	// not be synced.
+	Fetched   int `json:"fetched"`
+	Unchanged int `json:"unchanged"`
+	Deferred  int `json:"deferred"`
+	Failed    int `json:"failed"`
+}
+
+// SyncMetadata harvests the metadata and statistics of every table of a
+// data source into the store. Tables that fail, including those whose fetch
+// or stats timeout expires or whose collector panics, are skipped and
+// reported in the returned error; tables no longer in the source are removed
+// from the store only after a sync without failures, and, with a dependency
+// checker, only if no other table depends on them. Without a store it does
+// nothing.
+func (s *Service) SyncMetadata(ctx context.Context, source string) error {
+	_, err := s.Sync(ctx, source, SyncOptions{})
+	return err
+}
+
+// Sync is SyncMetadata with options. When the collector of the source
+// implements collector.ChangeDetector, the fingerprints of the tables are
+// stored with them, and an incremental sync only refetches the tables whose
+// fingerprint changed. A fingerprint is taken before its table is fetched,
+// so a table changed in between is refetched by the next sync.
+//
+// Tables are fetched by the workers set with SetHarvest, at most at its rate
+// limit, while the walk lists the next ones; a store error or the end of ctx
+// stops the sync.
+//
+// Tables whose tier, set with SetTiers, has a sync interval are only
+// refetched once it has elapsed since their last fetch, by incremental and
+// full syncs alike.
+//
+// Refetched tables are merged into the catalog set with SetTables. The
+// schema changes of refetched tables and failed syncs are sent to the
+// notifier set with SetNotifier. Failed syncs are also recorded in the store.
+func (s *Service) Sync(ctx context.Context, source string, opts SyncOptions) (*SyncSummary, error) {
+	summary, err := s.sync(ctx, source, opts)
+	if err != nil {
+		s.notifySyncFailed(ctx, source, summary, err)
+		if recordErr := s.syncFailed(ctx, source, summary, err); recordErr != nil {
+			err = errors.Join(err, recordErr)
+		}
+	}
+	return summary, err
+}
+
+func (s *Service) sync(ctx context.Context, source string, opts SyncOptions) (*SyncSummary, error) {
+	summary := &SyncSummary{}
+	st := s.Store()
+	if st == nil {
+		return summary, nil
+	}
+
+	c, err := s.collector(ctx, source)
+	if err != nil {
+		return summary, err
+	}
+	syncedAt := time.Now()
+	h := s.startHarvest(ctx, c, st, source, syncedAt, summary)
+	fingerprints := &schemaFingerprints{}
+	fetchTimes := &schemaFetchTimes{}
+	err = s.WalkTables(h.ctx, source, func(catalog, schema, table string) error {
+		key := store.TableKey{Source: source, Catalog: catalog, Schema: schema, Table: table}
+		policy := s.tierPolicy(key)
+		if !fetchTimes.due(h.ctx, st, key, policy.Interval(), syncedAt) {
+			if err := st.TouchTable(h.ctx, key, syncedAt); err != nil {
+				return err
+			}
+			h.count(func(s *SyncSummary) { s.Deferred++ })
+			return h.ctx.Err()
+		}
+		current, stored := fingerprints.get(h.ctx, s, c, st, key, opts.Incremental)
+		if opts.Incremental && current != "" && current == stored {
+			if err := st.SaveFingerprint(h.ctx, key, current, syncedAt); err != nil {
+				return err
+			}
+			h.count(func(s *SyncSummary) { s.Unchanged++ })
+			return h.ctx.Err()
+		}
+		return h.dispatch(key, current, policy)
+	})
+	errs, fatal := h.wait()
+	if fatal != nil {
+		// The walk was cancelled by the store error of a worker
+		err = fatal
+	} else if err == nil {
+		// Tables dispatched after ctx was done were not synced
+		err = ctx.Err()
+	}
+	if err != nil {
+		return summary, errors.Join(append(errs, err)...)
+	}
+	if len(errs) > 0 {
+		return summary, errors.Join(errs...)
+	}
+	return summary, s.prune(ctx, st, source, syncedAt, opts.Force)
+}
+
+// schemaFingerprints holds the current and stored fingerprints of the tables
+// of the schema a sync is walking.
+type schemaFingerprints struct {
+	catalog, schema string
+	loaded          bool
+	current         map[string]collector.TableFingerprint
+	stored          map[string]string
+}
+
+// get returns the current fingerprint of a table, "" if its collector does
+// not detect changes, and, for incremental syncs, its stored fingerprint.
+// The fingerprints of a schema are read once, when its first table is
+// synced. A schema whose fingerprints cannot be read is synced in full.
+func (f *schemaFingerprints) get(ctx context.Context, s *Service, c collector.Collector, st store.Repository, key store.TableKey, incremental bool) (current, stored string) {
+	detector, ok := c.(collector.ChangeDetector)
+	if !ok {
+		return "", ""
+	}
+	if !f.loaded || f.catalog != key.Catalog || f.schema != key.Schema {
+		*f = schemaFingerprints{catalog: key.Catalog, schema: key.Schema, loaded: true}
+		opCtx, done := s.withTimeout(ctx, c, key.Source, "table_fingerprints", collector.TimeoutList)
+		fps, err := guard(c, "table_fingerprints", schemaResource(key.Source, key.Catalog, key.Schema), func() (map[string]collector.TableFingerprint, error) {
+			return detector.TableFingerprints(opCtx, key.Catalog, key.Schema)
+		})
+		if done(err) != nil {
+			return "", ""
+		}
+		f.current = fps
+		if incremental {
+			if f.stored, err = st.Fingerprints(ctx, key.Source, key.Catalog, key.Schema); err != nil {
+				f.stored = nil
+			}
+		}
+	}
+	fp, ok := f.current[key.Table]
+	if !ok {
+		return "", ""
+	}
+	return fp.String(), f.stored[key.Table]
+}
+
+// StoredTable returns the metadata of a table as of its last sync, without
+// connecting to the source.
+func (s *Service) StoredTable(ctx context.Context, source, catalog, schema, table string) (*collector.TableMetadata, error) {
+	st := s.Store()
+	if st == nil {
+		return nil, ErrNoStore
+	}
+	return st.GetTable(ctx, store.TableKey{Source: source, Catalog: catalog, Schema: schema, Table: table})
+}
+
 // GetTableMetadata retrieves table metadata.
 func (s *Service) GetTableMetadata(ctx context.Context, database, table string) (*model.TableMetadata, error) {
 	// TODO: Implement table metadata retrieval
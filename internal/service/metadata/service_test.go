@@ -0,0 +1,91 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/collectortest"
+)
+
+// panickingCollector panics when listing the tables of panicSchema or
+// fetching panicTable.
+type panickingCollector struct {
+	*collectortest.Collector
+	panicSchema, panicTable string
+}
+
+func (c *panickingCollector) ListTables(ctx context.Context, catalog, schema string, opts *collector.ListOptions) (*collector.TableListResult, error) {
+	if schema == c.panicSchema {
+		panic("list tables: nil map")
+	}
+	return c.Collector.ListTables(ctx, catalog, schema, opts)
+}
+
+func (c *panickingCollector) FetchTableMetadata(ctx context.Context, catalog, schema, table string) (*collector.TableMetadata, error) {
+	if table == c.panicTable {
+		panic("fetch table metadata: index out of range")
+	}
+	return c.Collector.FetchTableMetadata(ctx, catalog, schema, table)
+}
+
+func newPanickingCollector(tables ...string) *panickingCollector {
+	c := collectortest.New(collector.CategoryRDBMS, "mysql")
+	for _, name := range tables {
+		c.AddTable(&collector.TableMetadata{Catalog: "def", Schema: "shop", Name: name, Type: collector.TableTypeTable})
+	}
+	return &panickingCollector{Collector: c}
+}
+
+// expectPanicError checks that err is the INTERNAL error of a recovered panic
+// of operation.
+func expectPanicError(t *testing.T, err error, operation string) {
+	t.Helper()
+	var cerr *collector.CollectorError
+	if !errors.As(err, &cerr) || cerr.Code != collector.ErrCodeInternal || cerr.Operation != operation {
+		t.Fatalf("Expected an INTERNAL error of %s, got %v", operation, err)
+	}
+	var perr *collector.PanicError
+	if !errors.As(err, &perr) || len(perr.Stack) == 0 {
+		t.Errorf("Expected the panic and its stack to be kept, got %v", err)
+	}
+	if cerr.Category != collector.CategoryRDBMS {
+		t.Errorf("Expected the category of the collector, got %q", cerr.Category)
+	}
+}
+
+func TestWalkTablesRecoversPanic(t *testing.T) {
+	c := newPanickingCollector("orders")
+	c.panicSchema = "shop"
+	s := NewService(nil)
+	s.RegisterCollector("mysql_prod", c)
+
+	err := s.WalkTables(context.Background(), "mysql_prod", func(catalog, schema, table string) error {
+		t.Errorf("Expected no table, got %s.%s.%s", catalog, schema, table)
+		return nil
+	})
+	expectPanicError(t, err, "list_tables")
+}
+
+func TestSyncRecoversPanic(t *testing.T) {
+	c := newPanickingCollector("orders", "customers", "broken")
+	c.panicTable = "broken"
+	st := &harvestStore{}
+	s := NewService(nil)
+	s.SetStore(st)
+	s.RegisterCollector("mysql_prod", c)
+
+	summary, err := s.Sync(context.Background(), "mysql_prod", SyncOptions{})
+	expectPanicError(t, err, "fetch_table_metadata")
+	// The other tables are synced and the failure recorded
+	if summary.Fetched != 2 || summary.Failed != 1 {
+		t.Errorf("Expected 2 tables fetched and 1 failed, got %+v", summary)
+	}
+	if !st.saved["orders"] || !st.saved["customers"] {
+		t.Errorf("Expected orders and customers to be saved, got %v", st.saved)
+	}
+	if len(st.failures) != 1 || st.failures[0].TablesFailed != 1 {
+		t.Errorf("Expected the failed sync to be recorded, got %+v", st.failures)
+	}
+}
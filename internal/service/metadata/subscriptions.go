@@ -0,0 +1,118 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"go-metadata/internal/biz"
+	"go-metadata/internal/notify"
+	"go-metadata/internal/store"
+)
+
+// ErrInvalidSubscription is returned for subscriptions and user channels
+// that cannot be notified.
+var ErrInvalidSubscription = errors.New("invalid subscription")
+
+// subscriptionEvents are the event types users may subscribe to.
+var subscriptionEvents = map[string]bool{
+	biz.EventSchemaDrift:     true,
+	biz.EventSLABreach:       true,
+	biz.EventPolicyViolation: true,
+	biz.EventSyncFailed:      true,
+}
+
+// Subscribe subscribes a user to the events of the tables of a source,
+// registered or with tables in the store, replacing the events of their
+// subscription to the same tables.
+func (s *Service) Subscribe(ctx context.Context, sub *store.Subscription) error {
+	st := s.Store()
+	if st == nil {
+		return ErrNoStore
+	}
+	if sub.User == "" || sub.Source == "" {
+		return fmt.Errorf("%w: user and source are required", ErrInvalidSubscription)
+	}
+	s.mu.Lock()
+	_, ok := s.collectors[sub.Source]
+	s.mu.Unlock()
+	if !ok {
+		// Sources synced into the store by another process, e.g. the CLI
+		keys, err := st.Tables(ctx, sub.Source)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("%w: %s", ErrSourceNotFound, sub.Source)
+		}
+	}
+	if _, err := path.Match(sub.Table, ""); err != nil {
+		return fmt.Errorf("%w: bad table pattern %q", ErrInvalidSubscription, sub.Table)
+	}
+	for _, e := range sub.Events {
+		if !subscriptionEvents[e] {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidSubscription, e)
+		}
+	}
+	if sub.CreatedAt.IsZero() {
+		sub.CreatedAt = time.Now()
+	}
+	return st.Subscribe(ctx, sub)
+}
+
+// Unsubscribe deletes the subscription of a user to the tables of a source.
+func (s *Service) Unsubscribe(ctx context.Context, user, source, table string) error {
+	st := s.Store()
+	if st == nil {
+		return ErrNoStore
+	}
+	return st.Unsubscribe(ctx, user, source, table)
+}
+
+// Subscriptions returns the subscriptions of a user.
+func (s *Service) Subscriptions(ctx context.Context, user string) ([]store.Subscription, error) {
+	st := s.Store()
+	if st == nil {
+		return nil, ErrNoStore
+	}
+	return st.Subscriptions(ctx, user)
+}
+
+// SetUserChannel sets the channel of a type a user receives the
+// notifications of their subscriptions in.
+func (s *Service) SetUserChannel(ctx context.Context, c *store.UserChannel) error {
+	st := s.Store()
+	if st == nil {
+		return ErrNoStore
+	}
+	if c.User == "" {
+		return fmt.Errorf("%w: user is required", ErrInvalidSubscription)
+	}
+	if err := notify.CheckChannel(c.Type, c.WebhookURL); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = time.Now()
+	}
+	return st.SaveUserChannel(ctx, c)
+}
+
+// DeleteUserChannel deletes the channel of a type of a user.
+func (s *Service) DeleteUserChannel(ctx context.Context, user, channelType string) error {
+	st := s.Store()
+	if st == nil {
+		return ErrNoStore
+	}
+	return st.DeleteUserChannel(ctx, user, channelType)
+}
+
+// UserChannels returns the channels of a user.
+func (s *Service) UserChannels(ctx context.Context, user string) ([]store.UserChannel, error) {
+	st := s.Store()
+	if st == nil {
+		return nil, ErrNoStore
+	}
+	return st.UserChannels(ctx, user)
+}
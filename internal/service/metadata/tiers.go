@@ -0,0 +1,56 @@
+package metadata
+
+import (
+	"context"
+	"time"
+
+	"go-metadata/internal/collector/config"
+	"go-metadata/internal/store"
+)
+
+// SetTiers sets the tier rules of the synced tables. The policy of its tier
+// sets how often a table is refetched, how much of its statistics are
+// collected and how large its schema changes must be to be notified. A nil
+// config syncs every table in full every time.
+func (s *Service) SetTiers(t *config.TierConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tiers = t
+}
+
+// tierPolicy returns the policy of the tier of a table.
+func (s *Service) tierPolicy(key store.TableKey) config.TierPolicy {
+	s.mu.Lock()
+	tiers := s.tiers
+	s.mu.Unlock()
+	return tiers.Policy(tiers.Tier(key.Source, key.Catalog, key.Schema, key.Table))
+}
+
+// schemaFetchTimes holds the fetch times of the stored tables of the schema
+// a sync is walking.
+type schemaFetchTimes struct {
+	catalog, schema string
+	loaded          bool
+	fetched         map[string]time.Time
+}
+
+// due reports whether a table whose policy refetches it at most every
+// interval must be fetched by a sync at syncedAt: tables without an
+// interval, not stored yet or fetched at least interval ago. The fetch
+// times of a schema are read once, when its first table with an interval is
+// synced. A schema whose fetch times cannot be read is synced in full.
+func (f *schemaFetchTimes) due(ctx context.Context, st store.Repository, key store.TableKey, interval time.Duration, syncedAt time.Time) bool {
+	if interval <= 0 {
+		return true
+	}
+	if !f.loaded || f.catalog != key.Catalog || f.schema != key.Schema {
+		*f = schemaFetchTimes{catalog: key.Catalog, schema: key.Schema, loaded: true}
+		fetched, err := st.FetchTimes(ctx, key.Source, key.Catalog, key.Schema)
+		if err != nil {
+			return true
+		}
+		f.fetched = fetched
+	}
+	at, ok := f.fetched[key.Table]
+	return !ok || !syncedAt.Before(at.Add(interval))
+}
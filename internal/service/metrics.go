@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+
+	"go-metadata/internal/biz"
+	"go-metadata/internal/metrics"
+)
+
+// DatasetStatsProvider feeds the stored table metadata to the dataset
+// metrics exporter (see metrics.NewDatasetExporter).
+type DatasetStatsProvider struct {
+	uc *biz.TableUsecase
+}
+
+// NewDatasetStatsProvider creates a new DatasetStatsProvider.
+func NewDatasetStatsProvider(uc *biz.TableUsecase) *DatasetStatsProvider {
+	return &DatasetStatsProvider{uc: uc}
+}
+
+// GetDatasetStats returns the size, freshness and consumer lag of every
+// table as of its latest sync.
+func (p *DatasetStatsProvider) GetDatasetStats(ctx context.Context) ([]*metrics.DatasetStats, error) {
+	tables, err := p.uc.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]*metrics.DatasetStats, 0, len(tables))
+	for _, t := range tables {
+		name := t.Database + "." + t.Name
+		if t.Schema != "" {
+			name = t.Database + "." + t.Schema + "." + t.Name
+		}
+		stats = append(stats, &metrics.DatasetStats{
+			Source:      t.Source,
+			Dataset:     name,
+			RowCount:    t.RowCount,
+			DataSize:    t.DataSize,
+			UpdatedAt:   t.UpdatedAt,
+			ConsumerLag: t.ConsumerLag,
+		})
+	}
+	return stats, nil
+}
@@ -8,6 +8,12 @@ import (
 var ProviderSet = wire.NewSet(
 	NewDataSourceService,
 	NewTaskService,
+	NewSyncProgressService,
 	NewTemplateService,
 	NewUserService,
+	NewLineageService,
+	NewTableService,
+	NewCatalogService,
+	NewCatalogGRPCService,
+	NewLineageGRPCService,
 )
@@ -0,0 +1,70 @@
+package service
+
+import (
+	stderrors "errors"
+
+	v1 "go-metadata/api/metadata/v1"
+	"go-metadata/internal/biz"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SyncProgressService streams the progress of running syncs over gRPC, so
+// UIs can show live progress instead of polling execution records.
+type SyncProgressService struct {
+	v1.UnimplementedSyncProgressServiceServer
+
+	uc  *biz.TaskUsecase
+	log *log.Helper
+}
+
+// NewSyncProgressService creates a new SyncProgressService.
+func NewSyncProgressService(uc *biz.TaskUsecase, logger log.Logger) *SyncProgressService {
+	return &SyncProgressService{
+		uc:  uc,
+		log: log.NewHelper(logger),
+	}
+}
+
+// WatchSyncProgress sends the progress events of an execution until its sync
+// finishes or the client goes away. The first event is the current progress.
+func (s *SyncProgressService) WatchSyncProgress(req *v1.WatchSyncProgressRequest, stream grpc.ServerStreamingServer[v1.SyncProgressEvent]) error {
+	if req.ExecutionId == "" {
+		return errors.BadRequest("INVALID_REQUEST", "execution_id is required")
+	}
+	events, err := s.uc.WatchProgress(stream.Context(), req.ExecutionId)
+	if err != nil {
+		if stderrors.Is(err, biz.ErrExecutionNotRunning) {
+			return errors.NotFound("NOT_FOUND", err.Error())
+		}
+		return err
+	}
+	for e := range events {
+		if err := stream.Send(toProtoSyncProgress(e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var syncProgressTypes = map[string]v1.SyncProgressEventType{
+	biz.ProgressStarted:  v1.SyncProgressEventType_SYNC_PROGRESS_EVENT_TYPE_STARTED,
+	biz.ProgressTable:    v1.SyncProgressEventType_SYNC_PROGRESS_EVENT_TYPE_TABLE,
+	biz.ProgressFinished: v1.SyncProgressEventType_SYNC_PROGRESS_EVENT_TYPE_FINISHED,
+}
+
+func toProtoSyncProgress(e *biz.SyncProgress) *v1.SyncProgressEvent {
+	return &v1.SyncProgressEvent{
+		ExecutionId: e.ExecutionID,
+		Type:        syncProgressTypes[e.Type],
+		Table:       e.Table,
+		Error:       e.Error,
+		Completed:   int32(e.Completed),
+		Failed:      int32(e.Failed),
+		Total:       int32(e.Total),
+		Time:        timestamppb.New(e.At),
+	}
+}
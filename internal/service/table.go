@@ -0,0 +1,581 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"strconv"
+	"time"
+
+	"go-metadata/internal/auth"
+	"go-metadata/internal/biz"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// TableService serves the user-curated documentation of tables: descriptions,
+// annotations, declarative bulk edits and the policies checked after syncs.
+type TableService struct {
+	uc  *biz.TableUsecase
+	log *log.Helper
+}
+
+// NewTableService creates a new TableService.
+func NewTableService(uc *biz.TableUsecase, logger log.Logger) *TableService {
+	return &TableService{
+		uc:  uc,
+		log: log.NewHelper(logger),
+	}
+}
+
+// TableDescription is the documentation of a table: the comment reported by
+// the data source and the user-authored markdown description, also rendered
+// as HTML.
+type TableDescription struct {
+	Database        string               `json:"database"`
+	Schema          string               `json:"schema,omitempty"`
+	Table           string               `json:"table"`
+	Comment         string               `json:"comment"`
+	Description     string               `json:"description"`
+	DescriptionHTML string               `json:"description_html"`
+	Annotations     map[string]string    `json:"annotations,omitempty"`
+	Owners          []string             `json:"owners,omitempty"`
+	Tags            []string             `json:"tags,omitempty"`
+	Deprecation     *biz.Deprecation     `json:"deprecation,omitempty"`
+	Columns         []*ColumnDescription `json:"columns"`
+}
+
+// ColumnDescription is the documentation of a column.
+type ColumnDescription struct {
+	Name            string            `json:"name"`
+	Comment         string            `json:"comment"`
+	Description     string            `json:"description"`
+	DescriptionHTML string            `json:"description_html"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+	Tags            []string          `json:"tags,omitempty"`
+	Deprecation     *biz.Deprecation  `json:"deprecation,omitempty"`
+}
+
+// SetDescriptionRequest is the body of a description update. An empty
+// description clears it.
+type SetDescriptionRequest struct {
+	Schema      string `json:"schema"`
+	Description string `json:"description"`
+}
+
+// TableReadme is the long-form markdown documentation of a table, also
+// rendered as HTML, and the path where it is maintained.
+type TableReadme struct {
+	Database  string     `json:"database"`
+	Schema    string     `json:"schema,omitempty"`
+	Table     string     `json:"table"`
+	Markdown  string     `json:"markdown"`
+	HTML      string     `json:"html"`
+	Path      string     `json:"path,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// SetReadmeRequest is the body of a README update. An empty markdown and
+// path removes the README.
+type SetReadmeRequest struct {
+	Schema   string `json:"schema"`
+	Markdown string `json:"markdown"`
+	Path     string `json:"path"`
+}
+
+// SetAnnotationsRequest is the body of an annotation update: the keys in Set
+// are added or replaced and the keys in Remove are deleted.
+type SetAnnotationsRequest struct {
+	Schema string            `json:"schema"`
+	Set    map[string]string `json:"set"`
+	Remove []string          `json:"remove"`
+}
+
+// ApplyRequest is the body of a declarative bulk edit.
+type ApplyRequest struct {
+	Changes []*biz.Change `json:"changes"`
+	// DryRun returns the plan without writing.
+	DryRun bool `json:"dry_run"`
+	// Versions are the table versions of a previewed plan; see biz.ApplyOptions.
+	Versions map[string]int64 `json:"versions,omitempty"`
+}
+
+// ListViolationsResponse lists the open policy violations.
+type ListViolationsResponse struct {
+	Violations []*biz.PolicyViolation `json:"violations"`
+}
+
+// ListPolicyEventsResponse lists policy events, newest first.
+type ListPolicyEventsResponse struct {
+	Events []*biz.PolicyEvent `json:"events"`
+}
+
+// SearchAnnotationsResponse lists the annotations matching a search.
+type SearchAnnotationsResponse struct {
+	Matches []*biz.AnnotationMatch `json:"matches"`
+}
+
+// FindDuplicatesResponse lists the likely duplicated datasets.
+type FindDuplicatesResponse struct {
+	Duplicates []*biz.DuplicateCandidate `json:"duplicates"`
+}
+
+// StorageReportResponse lists the storage roll-ups.
+type StorageReportResponse struct {
+	Rollups []*biz.StorageRollup `json:"rollups"`
+}
+
+// RegisterHTTP registers the routes on srv:
+//
+//	GET /api/v1/tables/{database}/{table}/description[?schema=]
+//	PUT /api/v1/tables/{database}/{table}/description
+//	PUT /api/v1/tables/{database}/{table}/columns/{column}/description
+//	GET /api/v1/tables/{database}/{table}/readme[?schema=]
+//	PUT /api/v1/tables/{database}/{table}/readme
+//	PUT /api/v1/tables/{database}/{table}/annotations
+//	PUT /api/v1/tables/{database}/{table}/columns/{column}/annotations
+//	GET /api/v1/annotations?key=dq.check[&value=enabled]
+//	GET /api/v1/tables/duplicates[?threshold=0.8&min_columns=3&cross_source=true]
+//	GET /api/v1/reports/storage[?group_by=schema|source&interval=day|week|month&from=&to=&format=csv]
+//	POST /api/v1/metadata/apply
+//	GET /api/v1/policies
+//	PUT /api/v1/policies
+//	GET /api/v1/policies/violations
+//	GET /api/v1/policies/events[?limit=100]
+func (s *TableService) RegisterHTTP(srv *http.Server) {
+	r := srv.Route("/")
+	r.GET("/api/v1/tables/{database}/{table}/description", s.getDescription)
+	r.PUT("/api/v1/tables/{database}/{table}/description", s.setDescription)
+	r.PUT("/api/v1/tables/{database}/{table}/columns/{column}/description", s.setDescription)
+	r.GET("/api/v1/tables/{database}/{table}/readme", s.getReadme)
+	r.PUT("/api/v1/tables/{database}/{table}/readme", s.setReadme)
+	r.PUT("/api/v1/tables/{database}/{table}/annotations", s.setAnnotations)
+	r.PUT("/api/v1/tables/{database}/{table}/columns/{column}/annotations", s.setAnnotations)
+	r.GET("/api/v1/annotations", s.searchAnnotations)
+	r.GET("/api/v1/tables/duplicates", s.findDuplicates)
+	r.GET("/api/v1/reports/storage", s.storageReport)
+	r.POST("/api/v1/metadata/apply", s.apply)
+	r.GET("/api/v1/policies", s.getPolicies)
+	r.PUT("/api/v1/policies", s.setPolicies)
+	r.GET("/api/v1/policies/violations", s.listViolations)
+	r.GET("/api/v1/policies/events", s.listPolicyEvents)
+}
+
+// GetDescription returns the documentation of a table.
+func (s *TableService) GetDescription(ctx context.Context, database, schema, table string) (*TableDescription, error) {
+	t, err := s.uc.Get(ctx, database, schema, table)
+	if err != nil {
+		return nil, toHTTPError(err)
+	}
+	return toTableDescription(t), nil
+}
+
+// SetDescription sets the description of a table, or of one of its columns
+// if column is not empty, on behalf of the user in ctx.
+func (s *TableService) SetDescription(ctx context.Context, database, schema, table, column, description string) (*TableDescription, error) {
+	t, err := s.uc.SetDescription(ctx, database, schema, table, column, description, currentUser(ctx))
+	if err != nil {
+		return nil, toHTTPError(err)
+	}
+	return toTableDescription(t), nil
+}
+
+// GetReadme returns the README of a table. A table without one has an empty
+// README.
+func (s *TableService) GetReadme(ctx context.Context, database, schema, table string) (*TableReadme, error) {
+	t, err := s.uc.Get(ctx, database, schema, table)
+	if err != nil {
+		return nil, toHTTPError(err)
+	}
+	return toTableReadme(t), nil
+}
+
+// SetReadme sets the README of a table on behalf of the user in ctx.
+func (s *TableService) SetReadme(ctx context.Context, database, table string, req *SetReadmeRequest) (*TableReadme, error) {
+	t, err := s.uc.SetReadme(ctx, database, req.Schema, table, req.Markdown, req.Path, currentUser(ctx))
+	if err != nil {
+		return nil, toHTTPError(err)
+	}
+	return toTableReadme(t), nil
+}
+
+// SetAnnotations updates the annotations of a table, or of one of its
+// columns if column is not empty, on behalf of the user in ctx.
+func (s *TableService) SetAnnotations(ctx context.Context, database, table, column string, req *SetAnnotationsRequest) (*TableDescription, error) {
+	t, err := s.uc.SetAnnotations(ctx, database, req.Schema, table, column, req.Set, req.Remove, currentUser(ctx))
+	if err != nil {
+		return nil, toHTTPError(err)
+	}
+	return toTableDescription(t), nil
+}
+
+// SearchAnnotations finds the tables and columns annotated with a key, or
+// any key of a namespace given as ns.*, and optionally a value.
+func (s *TableService) SearchAnnotations(ctx context.Context, q biz.AnnotationQuery) (*SearchAnnotationsResponse, error) {
+	matches, err := s.uc.SearchAnnotations(ctx, q)
+	if err != nil {
+		return nil, toHTTPError(err)
+	}
+	return &SearchAnnotationsResponse{Matches: matches}, nil
+}
+
+// FindDuplicates lists the pairs of tables with similar columns.
+func (s *TableService) FindDuplicates(ctx context.Context, opts biz.SimilarityOptions) (*FindDuplicatesResponse, error) {
+	duplicates, err := s.uc.FindDuplicates(ctx, opts)
+	if err != nil {
+		return nil, toHTTPError(err)
+	}
+	return &FindDuplicatesResponse{Duplicates: duplicates}, nil
+}
+
+// StorageReport rolls up the data size, row count and table count per schema
+// or source over time.
+func (s *TableService) StorageReport(ctx context.Context, opts biz.StorageOptions) (*StorageReportResponse, error) {
+	rollups, err := s.uc.StorageReport(ctx, opts)
+	if err != nil {
+		return nil, toHTTPError(err)
+	}
+	return &StorageReportResponse{Rollups: rollups}, nil
+}
+
+func (s *TableService) getDescription(ctx http.Context) error {
+	vars := ctx.Vars()
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.GetDescription(c, vars.Get("database"), ctx.Query().Get("schema"), vars.Get("table"))
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *TableService) setDescription(ctx http.Context) error {
+	var in SetDescriptionRequest
+	if err := ctx.Bind(&in); err != nil {
+		return err
+	}
+	vars := ctx.Vars()
+	h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+		in := req.(*SetDescriptionRequest)
+		return s.SetDescription(c, vars.Get("database"), in.Schema, vars.Get("table"), vars.Get("column"), in.Description)
+	})
+	out, err := h(ctx, &in)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *TableService) getReadme(ctx http.Context) error {
+	vars := ctx.Vars()
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.GetReadme(c, vars.Get("database"), ctx.Query().Get("schema"), vars.Get("table"))
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *TableService) setReadme(ctx http.Context) error {
+	var in SetReadmeRequest
+	if err := ctx.Bind(&in); err != nil {
+		return err
+	}
+	vars := ctx.Vars()
+	h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+		return s.SetReadme(c, vars.Get("database"), vars.Get("table"), req.(*SetReadmeRequest))
+	})
+	out, err := h(ctx, &in)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+// Apply applies declarative changes to many tables, or previews them on a
+// dry run, on behalf of the user in ctx.
+func (s *TableService) Apply(ctx context.Context, req *ApplyRequest) (*biz.ApplyPlan, error) {
+	plan, err := s.uc.Apply(ctx, req.Changes, biz.ApplyOptions{
+		DryRun:   req.DryRun,
+		Versions: req.Versions,
+		User:     currentUser(ctx),
+	})
+	if err != nil {
+		return nil, toHTTPError(err)
+	}
+	return plan, nil
+}
+
+func (s *TableService) setAnnotations(ctx http.Context) error {
+	var in SetAnnotationsRequest
+	if err := ctx.Bind(&in); err != nil {
+		return err
+	}
+	vars := ctx.Vars()
+	h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+		return s.SetAnnotations(c, vars.Get("database"), vars.Get("table"), vars.Get("column"), req.(*SetAnnotationsRequest))
+	})
+	out, err := h(ctx, &in)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *TableService) searchAnnotations(ctx http.Context) error {
+	query := ctx.Query()
+	in := biz.AnnotationQuery{Key: query.Get("key"), Value: query.Get("value")}
+	h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+		return s.SearchAnnotations(c, *req.(*biz.AnnotationQuery))
+	})
+	out, err := h(ctx, &in)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *TableService) findDuplicates(ctx http.Context) error {
+	query := ctx.Query()
+	var in biz.SimilarityOptions
+	if v := query.Get("threshold"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 || f > 1 {
+			return errors.BadRequest("INVALID_REQUEST", "threshold must be a number in (0, 1]")
+		}
+		in.Threshold = f
+	}
+	if v := query.Get("min_columns"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return errors.BadRequest("INVALID_REQUEST", "min_columns must be a positive integer")
+		}
+		in.MinColumns = n
+	}
+	if v := query.Get("cross_source"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.BadRequest("INVALID_REQUEST", "cross_source must be a boolean")
+		}
+		in.CrossSource = b
+	}
+	h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+		return s.FindDuplicates(c, *req.(*biz.SimilarityOptions))
+	})
+	out, err := h(ctx, &in)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *TableService) storageReport(ctx http.Context) error {
+	query := ctx.Query()
+	in := biz.StorageOptions{GroupBy: query.Get("group_by"), Interval: query.Get("interval")}
+	for name, t := range map[string]*time.Time{"from": &in.From, "to": &in.To} {
+		if v := query.Get(name); v != "" {
+			parsed, err := parseReportTime(v)
+			if err != nil {
+				return errors.BadRequest("INVALID_REQUEST", name+" must be a date (2006-01-02) or an RFC 3339 time")
+			}
+			*t = parsed
+		}
+	}
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		return errors.BadRequest("INVALID_REQUEST", "format must be json or csv")
+	}
+	h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+		return s.StorageReport(c, *req.(*biz.StorageOptions))
+	})
+	out, err := h(ctx, &in)
+	if err != nil {
+		return err
+	}
+	if format == "csv" {
+		var buf bytes.Buffer
+		if err := biz.WriteStorageCSV(&buf, out.(*StorageReportResponse).Rollups); err != nil {
+			return err
+		}
+		return ctx.Blob(200, "text/csv; charset=utf-8", buf.Bytes())
+	}
+	return ctx.Result(200, out)
+}
+
+// parseReportTime parses a date, taken as midnight UTC, or an RFC 3339 time.
+func parseReportTime(v string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+func (s *TableService) apply(ctx http.Context) error {
+	var in ApplyRequest
+	if err := ctx.Bind(&in); err != nil {
+		return err
+	}
+	h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+		return s.Apply(c, req.(*ApplyRequest))
+	})
+	out, err := h(ctx, &in)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+// GetPolicies returns the metadata policies.
+func (s *TableService) GetPolicies(ctx context.Context) (*biz.PolicySet, error) {
+	return s.uc.GetPolicies(ctx)
+}
+
+// SetPolicies replaces the metadata policies on behalf of the user in ctx.
+func (s *TableService) SetPolicies(ctx context.Context, set *biz.PolicySet) (*biz.PolicySet, error) {
+	if err := s.uc.SetPolicies(ctx, set, currentUser(ctx)); err != nil {
+		return nil, toHTTPError(err)
+	}
+	return set, nil
+}
+
+// ListViolations lists the policy violations found by the latest syncs.
+func (s *TableService) ListViolations(ctx context.Context) (*ListViolationsResponse, error) {
+	violations, err := s.uc.ListViolations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ListViolationsResponse{Violations: violations}, nil
+}
+
+// ListPolicyEvents lists the most recent policy events.
+func (s *TableService) ListPolicyEvents(ctx context.Context, limit int) (*ListPolicyEventsResponse, error) {
+	events, err := s.uc.ListPolicyEvents(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &ListPolicyEventsResponse{Events: events}, nil
+}
+
+func (s *TableService) getPolicies(ctx http.Context) error {
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.GetPolicies(c)
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *TableService) setPolicies(ctx http.Context) error {
+	var in biz.PolicySet
+	if err := ctx.Bind(&in); err != nil {
+		return err
+	}
+	h := ctx.Middleware(func(c context.Context, req interface{}) (interface{}, error) {
+		return s.SetPolicies(c, req.(*biz.PolicySet))
+	})
+	out, err := h(ctx, &in)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *TableService) listViolations(ctx http.Context) error {
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.ListViolations(c)
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *TableService) listPolicyEvents(ctx http.Context) error {
+	limit := 100
+	if v := ctx.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return errors.BadRequest("INVALID_REQUEST", "limit must be a positive integer")
+		}
+		limit = n
+	}
+	h := ctx.Middleware(func(c context.Context, _ interface{}) (interface{}, error) {
+		return s.ListPolicyEvents(c, limit)
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+// currentUser returns the name of the authenticated user in ctx.
+func currentUser(ctx context.Context) string {
+	if u, ok := auth.UserFromContext(ctx); ok {
+		return u.Username
+	}
+	return "anonymous"
+}
+
+func toTableDescription(t *biz.TableMetadata) *TableDescription {
+	d := &TableDescription{
+		Database:        t.Database,
+		Schema:          t.Schema,
+		Table:           t.Name,
+		Comment:         t.Comment,
+		Description:     t.Description,
+		DescriptionHTML: renderMarkdown(t.Description),
+		Annotations:     t.Annotations,
+		Owners:          t.Owners,
+		Tags:            t.Tags,
+		Deprecation:     t.Deprecation,
+		Columns:         make([]*ColumnDescription, 0, len(t.Columns)),
+	}
+	for _, col := range t.Columns {
+		d.Columns = append(d.Columns, &ColumnDescription{
+			Name:            col.Name,
+			Comment:         col.Comment,
+			Description:     col.Description,
+			DescriptionHTML: renderMarkdown(col.Description),
+			Annotations:     col.Annotations,
+			Tags:            col.Tags,
+			Deprecation:     col.Deprecation,
+		})
+	}
+	return d
+}
+
+func toTableReadme(t *biz.TableMetadata) *TableReadme {
+	r := &TableReadme{Database: t.Database, Schema: t.Schema, Table: t.Name}
+	if t.Readme != nil {
+		r.Markdown = t.Readme.Markdown
+		r.HTML = renderMarkdown(t.Readme.Markdown)
+		r.Path = t.Readme.Path
+		r.UpdatedBy = t.Readme.UpdatedBy
+		r.UpdatedAt = &t.Readme.UpdatedAt
+	}
+	return r
+}
+
+// toHTTPError maps table usecase errors to HTTP status codes.
+func toHTTPError(err error) error {
+	switch {
+	case stderrors.Is(err, biz.ErrTableNotFound), stderrors.Is(err, biz.ErrColumnNotFound):
+		return errors.NotFound("NOT_FOUND", err.Error())
+	case stderrors.Is(err, biz.ErrDescriptionTooLong), stderrors.Is(err, biz.ErrReadmeTooLong), stderrors.Is(err, biz.ErrInvalidAnnotation),
+		stderrors.Is(err, biz.ErrInvalidChange), stderrors.Is(err, biz.ErrInvalidStorageOptions):
+		return errors.BadRequest("INVALID_REQUEST", err.Error())
+	case stderrors.Is(err, biz.ErrVersionConflict):
+		return errors.Conflict("CONFLICT", err.Error())
+	}
+	return err
+}
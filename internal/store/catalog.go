@@ -0,0 +1,244 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrVersionConflict is returned when saving a catalog table that was
+// modified since it was read.
+var ErrVersionConflict = errors.New("catalog table was modified concurrently")
+
+// CatalogTable is the catalog entry of a stored table: its synced metadata
+// merged with the edits of users, as a JSON document the catalog
+// (internal/biz) reads and writes whole. Entries are deleted with their
+// table.
+type CatalogTable struct {
+	TableKey
+	// ID is the id of the stored table.
+	ID int64 `json:"id"`
+	// Version is incremented on every save and compared by SaveCatalogTable
+	// for optimistic locking; 0 is an entry not saved yet.
+	Version   int64           `json:"version"`
+	Document  json.RawMessage `json:"document"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	// Annotations are the annotations of the document, indexed by
+	// SaveCatalogTable for SearchAnnotations. They are not read back.
+	Annotations []Annotation `json:"annotations,omitempty"`
+}
+
+// Annotation is a key/value annotation of a catalog table, or of one of its
+// columns if Column is set.
+type Annotation struct {
+	Column string `json:"column,omitempty"`
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+}
+
+// AnnotationMatch is an annotation found by SearchAnnotations.
+type AnnotationMatch struct {
+	TableKey
+	TableID int64 `json:"table_id"`
+	Annotation
+}
+
+// CatalogTable returns the catalog entry of a stored table, or ErrNotFound
+// if the table is not stored or has no entry yet.
+func (s *Store) CatalogTable(ctx context.Context, key TableKey) (*CatalogTable, error) {
+	t := &CatalogTable{TableKey: key}
+	var document []byte
+	err := s.db.QueryRowContext(ctx, s.rebind(`
+		SELECT t.id, c.version, c.document, c.updated_at
+		FROM harvested_tables t JOIN catalog_tables c ON c.table_id = t.id
+		WHERE t.source = $1 AND t.catalog_name = $2 AND t.schema_name = $3 AND t.table_name = $4`),
+		key.Source, key.Catalog, key.Schema, key.Table).Scan(&t.ID, &t.Version, &document, &t.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Document = document
+	return t, nil
+}
+
+// CatalogTables returns the catalog entries of every stored table that has
+// one, ordered by source, catalog, schema and table.
+func (s *Store) CatalogTables(ctx context.Context) ([]CatalogTable, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.source, t.catalog_name, t.schema_name, t.table_name, t.id, c.version, c.document, c.updated_at
+		FROM harvested_tables t JOIN catalog_tables c ON c.table_id = t.id
+		ORDER BY t.source, t.catalog_name, t.schema_name, t.table_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []CatalogTable
+	for rows.Next() {
+		var t CatalogTable
+		var document []byte
+		if err := rows.Scan(&t.Source, &t.Catalog, &t.Schema, &t.Table, &t.ID, &t.Version, &document, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		t.Document = document
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+// SaveCatalogTable creates the catalog entry of a stored table if
+// t.Version is 0, or replaces it if its version still equals t.Version, and
+// sets the id, version and update time of t to the saved ones. The
+// annotations of the entry are replaced by t.Annotations. It returns
+// ErrVersionConflict if the entry was created or modified since it was
+// read, and ErrNotFound if the table is not stored.
+func (s *Store) SaveCatalogTable(ctx context.Context, t *CatalogTable) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	id, err := s.tableID(ctx, tx, t.TableKey)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	var res sql.Result
+	if t.Version == 0 {
+		res, err = tx.ExecContext(ctx, s.rebind(`
+			INSERT INTO catalog_tables (table_id, version, document, updated_at)
+			VALUES ($1, 1, $2, $3)
+			ON CONFLICT (table_id) DO NOTHING`), id, string(t.Document), now)
+	} else {
+		res, err = tx.ExecContext(ctx, s.rebind(`
+			UPDATE catalog_tables SET version = version + 1, document = $2, updated_at = $3
+			WHERE table_id = $1 AND version = $4`), id, string(t.Document), now, t.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("save catalog table %s: %w", t.TableKey, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s", ErrVersionConflict, t.TableKey)
+	}
+
+	if _, err := tx.ExecContext(ctx, s.rebind(`DELETE FROM catalog_annotations WHERE table_id = $1`), id); err != nil {
+		return fmt.Errorf("save annotations of %s: %w", t.TableKey, err)
+	}
+	for _, a := range t.Annotations {
+		_, err := tx.ExecContext(ctx, s.rebind(`
+			INSERT INTO catalog_annotations (table_id, column_name, ann_key, ann_value)
+			VALUES ($1, $2, $3, $4)`), id, a.Column, a.Key, a.Value)
+		if err != nil {
+			return fmt.Errorf("save annotation %s of %s: %w", a.Key, t.TableKey, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	t.ID, t.Version, t.UpdatedAt = id, t.Version+1, now
+	return nil
+}
+
+// SearchAnnotations returns the annotations of catalog tables whose key is
+// key, or starts with key if prefix is set, and whose value is value unless
+// it is empty. They are ordered by catalog, schema, table, source, column
+// and key.
+func (s *Store) SearchAnnotations(ctx context.Context, key string, prefix bool, value string) ([]AnnotationMatch, error) {
+	keyCond, args := "a.ann_key = $1", []any{key, value}
+	if prefix {
+		keyCond, args = "substr(a.ann_key, 1, $3) = $1", append(args, len(key))
+	}
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT t.source, t.catalog_name, t.schema_name, t.table_name, a.table_id, a.column_name, a.ann_key, a.ann_value
+		FROM catalog_annotations a JOIN harvested_tables t ON t.id = a.table_id
+		WHERE `+keyCond+` AND ($2 = '' OR a.ann_value = $2)
+		ORDER BY t.catalog_name, t.schema_name, t.table_name, t.source, a.column_name, a.ann_key`), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []AnnotationMatch
+	for rows.Next() {
+		var m AnnotationMatch
+		if err := rows.Scan(&m.Source, &m.Catalog, &m.Schema, &m.Table, &m.TableID, &m.Column, &m.Key, &m.Value); err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// MetadataConflict records a sync that reported a new source value for a
+// field of a table edited by a user.
+type MetadataConflict struct {
+	TableID             int64  `json:"table_id"`
+	Field               string `json:"field"`
+	UserValue           string `json:"user_value"`
+	EditedBy            string `json:"edited_by"`
+	PreviousSourceValue string `json:"previous_source_value"`
+	SourceValue         string `json:"source_value"`
+	// Resolution is kept_user_value or field_removed.
+	Resolution string    `json:"resolution"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// SaveConflicts records sync conflicts. Conflicts are deleted with their
+// table.
+func (s *Store) SaveConflicts(ctx context.Context, conflicts []MetadataConflict) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, c := range conflicts {
+		_, err := tx.ExecContext(ctx, s.rebind(`
+			INSERT INTO metadata_conflicts (
+				table_id, field, user_value, edited_by, previous_source_value, source_value,
+				resolution, detected_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`),
+			c.TableID, c.Field, c.UserValue, c.EditedBy, c.PreviousSourceValue, c.SourceValue,
+			c.Resolution, c.DetectedAt.UTC(),
+		)
+		if err != nil {
+			return fmt.Errorf("save conflict of %s: %w", c.Field, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Conflicts returns the sync conflicts recorded for a stored table, newest
+// first.
+func (s *Store) Conflicts(ctx context.Context, tableID int64) ([]MetadataConflict, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT table_id, field, user_value, edited_by, previous_source_value, source_value,
+			resolution, detected_at
+		FROM metadata_conflicts WHERE table_id = $1
+		ORDER BY detected_at DESC, id DESC`), tableID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conflicts []MetadataConflict
+	for rows.Next() {
+		var c MetadataConflict
+		if err := rows.Scan(&c.TableID, &c.Field, &c.UserValue, &c.EditedBy, &c.PreviousSourceValue, &c.SourceValue,
+			&c.Resolution, &c.DetectedAt); err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, c)
+	}
+	return conflicts, rows.Err()
+}
@@ -0,0 +1,169 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SchemaChange is a change of the schema of a stored table found by a sync:
+// one DDL event, with the number of columns and indexes it changed.
+type SchemaChange struct {
+	TableKey
+	ColumnsAdded    int       `json:"columns_added"`
+	ColumnsDropped  int       `json:"columns_dropped"`
+	ColumnsModified int       `json:"columns_modified"`
+	IndexesChanged  int       `json:"indexes_changed"`
+	ChangedAt       time.Time `json:"changed_at"`
+}
+
+// SaveSchemaChange records a schema change. Changes are kept when their
+// table is deleted.
+func (s *Store) SaveSchemaChange(ctx context.Context, c *SchemaChange) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`
+		INSERT INTO schema_changes (
+			source, catalog_name, schema_name, table_name,
+			columns_added, columns_dropped, columns_modified, indexes_changed, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`),
+		c.Source, c.Catalog, c.Schema, c.Table,
+		c.ColumnsAdded, c.ColumnsDropped, c.ColumnsModified, c.IndexesChanged, c.ChangedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("save schema change of %s: %w", c.TableKey, err)
+	}
+	return nil
+}
+
+// SchemaChanges returns the schema changes recorded since a time, oldest
+// first, of a source or of all sources if source is empty.
+func (s *Store) SchemaChanges(ctx context.Context, source string, since time.Time) ([]SchemaChange, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT source, catalog_name, schema_name, table_name,
+			columns_added, columns_dropped, columns_modified, indexes_changed, changed_at
+		FROM schema_changes
+		WHERE ($1 = '' OR source = $1) AND changed_at >= $2
+		ORDER BY changed_at, id`), source, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []SchemaChange
+	for rows.Next() {
+		var c SchemaChange
+		if err := rows.Scan(&c.Source, &c.Catalog, &c.Schema, &c.Table,
+			&c.ColumnsAdded, &c.ColumnsDropped, &c.ColumnsModified, &c.IndexesChanged, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// SyncFailure is a failed sync of a source.
+type SyncFailure struct {
+	Source       string `json:"source"`
+	TablesFailed int    `json:"tables_failed"`
+	// Error is the error of the sync, one line per failed table or step.
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// SaveSyncFailure records a failed sync.
+func (s *Store) SaveSyncFailure(ctx context.Context, f *SyncFailure) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`
+		INSERT INTO sync_failures (source, tables_failed, error, failed_at)
+		VALUES ($1, $2, $3, $4)`),
+		f.Source, f.TablesFailed, f.Error, f.FailedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("save sync failure of %s: %w", f.Source, err)
+	}
+	return nil
+}
+
+// SyncFailures returns the failed syncs recorded since a time, oldest first,
+// of a source or of all sources if source is empty.
+func (s *Store) SyncFailures(ctx context.Context, source string, since time.Time) ([]SyncFailure, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT source, tables_failed, error, failed_at FROM sync_failures
+		WHERE ($1 = '' OR source = $1) AND failed_at >= $2
+		ORDER BY failed_at, id`), source, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var failures []SyncFailure
+	for rows.Next() {
+		var f SyncFailure
+		if err := rows.Scan(&f.Source, &f.TablesFailed, &f.Error, &f.FailedAt); err != nil {
+			return nil, err
+		}
+		failures = append(failures, f)
+	}
+	return failures, rows.Err()
+}
+
+// ChangeRate summarizes how often the schemas of a source changed over a
+// period.
+type ChangeRate struct {
+	Source string `json:"source"`
+	// Events is the number of schema changes, and Tables the number of
+	// distinct tables they changed.
+	Events          int `json:"events"`
+	Tables          int `json:"tables"`
+	ColumnsAdded    int `json:"columns_added"`
+	ColumnsDropped  int `json:"columns_dropped"`
+	ColumnsModified int `json:"columns_modified"`
+	// EventsPerWeek is the average number of schema changes per week of
+	// the period.
+	EventsPerWeek float64   `json:"events_per_week"`
+	LastChange    time.Time `json:"last_change"`
+}
+
+// ChangeRates rolls up schema changes per source over the period from
+// since to until, most frequently changing sources first. Changes outside
+// the period are ignored.
+func ChangeRates(changes []SchemaChange, since, until time.Time) []*ChangeRate {
+	weeks := until.Sub(since).Hours() / (7 * 24)
+	bySource := make(map[string]*ChangeRate)
+	tables := make(map[TableKey]bool)
+	for _, c := range changes {
+		if c.ChangedAt.Before(since) || c.ChangedAt.After(until) {
+			continue
+		}
+		r, ok := bySource[c.Source]
+		if !ok {
+			r = &ChangeRate{Source: c.Source}
+			bySource[c.Source] = r
+		}
+		r.Events++
+		r.ColumnsAdded += c.ColumnsAdded
+		r.ColumnsDropped += c.ColumnsDropped
+		r.ColumnsModified += c.ColumnsModified
+		if c.ChangedAt.After(r.LastChange) {
+			r.LastChange = c.ChangedAt
+		}
+		if !tables[c.TableKey] {
+			tables[c.TableKey] = true
+			r.Tables++
+		}
+	}
+
+	rates := make([]*ChangeRate, 0, len(bySource))
+	for _, r := range bySource {
+		if weeks > 0 {
+			r.EventsPerWeek = float64(r.Events) / weeks
+		}
+		rates = append(rates, r)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].Events != rates[j].Events {
+			return rates[i].Events > rates[j].Events
+		}
+		return rates[i].Source < rates[j].Source
+	})
+	return rates
+}
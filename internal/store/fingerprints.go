@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Fingerprints returns the change fingerprints of the stored tables of a
+// schema of a source by table name. Tables saved without a fingerprint are
+// left out.
+func (s *Store) Fingerprints(ctx context.Context, source, catalog, schema string) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT table_name, fingerprint FROM harvested_tables
+		WHERE source = $1 AND catalog_name = $2 AND schema_name = $3 AND fingerprint <> ''`),
+		source, catalog, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fingerprints := make(map[string]string)
+	for rows.Next() {
+		var name, fingerprint string
+		if err := rows.Scan(&name, &fingerprint); err != nil {
+			return nil, err
+		}
+		fingerprints[name] = fingerprint
+	}
+	return fingerprints, rows.Err()
+}
+
+// SaveFingerprint records the change fingerprint of a stored table and marks
+// it synced at syncedAt, so that a table an incremental sync found unchanged
+// is not pruned. It returns ErrNotFound for tables that are not in the store.
+func (s *Store) SaveFingerprint(ctx context.Context, key TableKey, fingerprint string, syncedAt time.Time) error {
+	res, err := s.db.ExecContext(ctx, s.rebind(`
+		UPDATE harvested_tables SET fingerprint = $1, synced_at = $2
+		WHERE source = $3 AND catalog_name = $4 AND schema_name = $5 AND table_name = $6`),
+		fingerprint, syncedAt.UTC(), key.Source, key.Catalog, key.Schema, key.Table)
+	if err != nil {
+		return fmt.Errorf("save fingerprint of %s: %w", key, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return nil
+}
+
+// FetchTimes returns when the stored tables of a schema of a source were
+// last fetched from the source by table name. Unlike their sync time, it is
+// not updated for tables a sync skipped.
+func (s *Store) FetchTimes(ctx context.Context, source, catalog, schema string) (map[string]time.Time, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT table_name, fetched_at FROM harvested_tables
+		WHERE source = $1 AND catalog_name = $2 AND schema_name = $3 AND fetched_at IS NOT NULL`),
+		source, catalog, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fetched := make(map[string]time.Time)
+	for rows.Next() {
+		var name string
+		var at time.Time
+		if err := rows.Scan(&name, &at); err != nil {
+			return nil, err
+		}
+		fetched[name] = at
+	}
+	return fetched, rows.Err()
+}
+
+// TouchTable marks a stored table synced at syncedAt without changing its
+// metadata or fingerprint, so that a table a sync skipped is not pruned. It
+// returns ErrNotFound for tables that are not in the store.
+func (s *Store) TouchTable(ctx context.Context, key TableKey, syncedAt time.Time) error {
+	res, err := s.db.ExecContext(ctx, s.rebind(`
+		UPDATE harvested_tables SET synced_at = $1
+		WHERE source = $2 AND catalog_name = $3 AND schema_name = $4 AND table_name = $5`),
+		syncedAt.UTC(), key.Source, key.Catalog, key.Schema, key.Table)
+	if err != nil {
+		return fmt.Errorf("touch table %s: %w", key, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PolicyViolation is an open violation of a metadata policy by a stored
+// table, or by one of its columns if Column is set.
+type PolicyViolation struct {
+	TableID int64 `json:"table_id"`
+	// Table is the qualified name of the table in the catalog.
+	Table      string    `json:"table"`
+	Policy     string    `json:"policy"`
+	Severity   string    `json:"severity"`
+	Column     string    `json:"column,omitempty"`
+	Message    string    `json:"message"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// PolicyEvent records that a sync found a violation (violated) or no longer
+// found it (resolved). Events are kept when their table is deleted.
+type PolicyEvent struct {
+	Type string `json:"type"`
+	PolicyViolation
+	At time.Time `json:"at"`
+}
+
+// Policies returns the stored policy set document, or nil if none was
+// saved.
+func (s *Store) Policies(ctx context.Context) (json.RawMessage, error) {
+	var document []byte
+	err := s.db.QueryRowContext(ctx, `SELECT document FROM metadata_policies WHERE id = 1`).Scan(&document)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// SavePolicies replaces the stored policy set document.
+func (s *Store) SavePolicies(ctx context.Context, document json.RawMessage) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`
+		INSERT INTO metadata_policies (id, document, updated_at) VALUES (1, $1, $2)
+		ON CONFLICT (id) DO UPDATE SET document = excluded.document, updated_at = excluded.updated_at`),
+		string(document), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("save policies: %w", err)
+	}
+	return nil
+}
+
+// PolicyViolations returns the open violations of a stored table, or of
+// every table if tableID is 0, ordered by table, policy and column.
+func (s *Store) PolicyViolations(ctx context.Context, tableID int64) ([]PolicyViolation, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT table_id, table_name, policy, severity, column_name, message, detected_at
+		FROM policy_violations WHERE $1 = 0 OR table_id = $1
+		ORDER BY table_name, policy, column_name, id`), tableID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var violations []PolicyViolation
+	for rows.Next() {
+		var v PolicyViolation
+		if err := rows.Scan(&v.TableID, &v.Table, &v.Policy, &v.Severity, &v.Column, &v.Message, &v.DetectedAt); err != nil {
+			return nil, err
+		}
+		violations = append(violations, v)
+	}
+	return violations, rows.Err()
+}
+
+// SavePolicyViolations replaces the open violations of a stored table and
+// records the events reporting the change, in one transaction.
+func (s *Store) SavePolicyViolations(ctx context.Context, tableID int64, violations []PolicyViolation, events []PolicyEvent) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, s.rebind(`DELETE FROM policy_violations WHERE table_id = $1`), tableID); err != nil {
+		return fmt.Errorf("save policy violations: %w", err)
+	}
+	for _, v := range violations {
+		_, err := tx.ExecContext(ctx, s.rebind(`
+			INSERT INTO policy_violations (table_id, table_name, policy, severity, column_name, message, detected_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`),
+			tableID, v.Table, v.Policy, v.Severity, v.Column, v.Message, v.DetectedAt.UTC())
+		if err != nil {
+			return fmt.Errorf("save violation of %s: %w", v.Policy, err)
+		}
+	}
+	for _, e := range events {
+		_, err := tx.ExecContext(ctx, s.rebind(`
+			INSERT INTO policy_events (
+				event_type, table_id, table_name, policy, severity, column_name, message, detected_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`),
+			e.Type, tableID, e.Table, e.Policy, e.Severity, e.Column, e.Message, e.DetectedAt.UTC(), e.At.UTC())
+		if err != nil {
+			return fmt.Errorf("save policy event of %s: %w", e.Policy, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// PolicyEvents returns the most recent policy events, newest first.
+func (s *Store) PolicyEvents(ctx context.Context, limit int) ([]PolicyEvent, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT event_type, table_id, table_name, policy, severity, column_name, message, detected_at, created_at
+		FROM policy_events ORDER BY created_at DESC, id DESC LIMIT $1`), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []PolicyEvent
+	for rows.Next() {
+		var e PolicyEvent
+		if err := rows.Scan(&e.Type, &e.TableID, &e.Table, &e.Policy, &e.Severity, &e.Column, &e.Message,
+			&e.DetectedAt, &e.At); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
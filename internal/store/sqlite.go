@@ -0,0 +1,7 @@
+//go:build sqlite
+
+package store
+
+// The pure-Go SQLite driver registers itself as "sqlite". It is only linked
+// into builds with -tags sqlite, keeping it out of PostgreSQL deployments.
+import _ "modernc.org/sqlite"
@@ -0,0 +1,127 @@
+//go:build sqlite
+
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-metadata/internal/collector"
+	"go-metadata/migrations"
+)
+
+func openSQLite(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(context.Background(), &Config{Driver: DriverSQLite, DSN: filepath.Join(t.TempDir(), "metadata.db")})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestSQLiteMigrations(t *testing.T) {
+	ctx := context.Background()
+	s := openSQLite(t)
+
+	ups, err := fs.Glob(migrations.FS, "sqlite/*.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var version int
+	if err := s.db.QueryRowContext(ctx, "SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != len(ups) {
+		t.Errorf("Expected every migration to be applied, got version %d of %d", version, len(ups))
+	}
+	if applied, err := s.Migrate(ctx); err != nil || applied != 0 {
+		t.Errorf("Expected nothing to migrate on a migrated store, got %d, %v", applied, err)
+	}
+}
+
+func TestSQLiteRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s := openSQLite(t)
+	syncedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	key := TableKey{Source: "mysql_prod", Catalog: "def", Schema: "shop", Table: "orders"}
+
+	def := "0"
+	orders := &collector.TableMetadata{
+		SourceCategory: collector.CategoryRDBMS,
+		SourceType:     "mysql",
+		Catalog:        "def",
+		Schema:         "shop",
+		Name:           "orders",
+		Type:           collector.TableTypeTable,
+		Comment:        "订单",
+		Descriptions:   map[string]string{"zh-CN": "订单", "en": "Orders"},
+		Columns: []collector.Column{
+			{OrdinalPosition: 1, Name: "id", Type: "bigint", SourceType: "bigint", IsPrimaryKey: true, IsAutoIncrement: true},
+			{OrdinalPosition: 2, Name: "status", Type: "varchar", SourceType: "varchar(16)", Length: intPtr(16), Nullable: true,
+				Default: &def, Charset: "utf8mb4", Collation: "utf8mb4_bin", Examples: []string{"paid", "shipped"}},
+			{OrdinalPosition: 3, Name: "total", Type: "decimal", SourceType: "decimal(10,2)", Precision: intPtr(10), Scale: intPtr(2),
+				Generated: &collector.GeneratedInfo{Expression: "price * quantity", Stored: true}},
+		},
+		Indexes:    []collector.Index{{Name: "idx_status", Columns: []string{"status"}, Type: "BTREE"}},
+		Partitions: []collector.PartitionInfo{{Name: "p2026", Type: "RANGE", Columns: []string{"id"}, Expression: "id", ValuesCount: 1}},
+		PrimaryKey: []string{"id"},
+		Properties: map[string]string{"engine": "InnoDB"},
+		Stats:      &collector.TableStatistics{RowCount: 42, DataSizeBytes: 4096, CollectedAt: syncedAt},
+	}
+	if err := s.SaveTable(ctx, key.Source, orders, syncedAt); err != nil {
+		t.Fatalf("SaveTable failed: %v", err)
+	}
+
+	got, err := s.GetTable(ctx, key)
+	if err != nil {
+		t.Fatalf("GetTable failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, orders) {
+		t.Errorf("Expected the stored table to equal the saved one:\nwant %+v\ngot  %+v", orders, got)
+	}
+	if tables, err := s.Tables(ctx, key.Source); err != nil || len(tables) != 1 || tables[0] != key {
+		t.Errorf("Expected the table to be listed for its source, got %v, %v", tables, err)
+	}
+
+	// The catalog document and schema changes of the table
+	catalog := &CatalogTable{TableKey: key, Document: json.RawMessage(`{"owners":["team-x"]}`),
+		Annotations: []Annotation{{Column: "status", Key: "dq.check", Value: "enabled"}}}
+	if err := s.SaveCatalogTable(ctx, catalog); err != nil {
+		t.Fatalf("SaveCatalogTable failed: %v", err)
+	}
+	stored, err := s.CatalogTable(ctx, key)
+	if err != nil || stored.Version != 1 || string(stored.Document) != `{"owners":["team-x"]}` {
+		t.Errorf("Expected the catalog document, got %+v, %v", stored, err)
+	}
+	if matches, err := s.SearchAnnotations(ctx, "dq.check", false, "enabled"); err != nil || len(matches) != 1 || matches[0].TableKey != key {
+		t.Errorf("Expected the annotation to be found, got %+v, %v", matches, err)
+	}
+	change := &SchemaChange{TableKey: key, ColumnsAdded: 1, ChangedAt: syncedAt}
+	if err := s.SaveSchemaChange(ctx, change); err != nil {
+		t.Fatalf("SaveSchemaChange failed: %v", err)
+	}
+
+	// Deleting the table cascades to its catalog entry; schema changes are kept
+	if err := s.DeleteTable(ctx, key); err != nil {
+		t.Fatalf("DeleteTable failed: %v", err)
+	}
+	if _, err := s.GetTable(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after deletion, got %v", err)
+	}
+	var entries int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM catalog_tables").Scan(&entries); err != nil || entries != 0 {
+		t.Errorf("Expected the catalog entry to be deleted with the table, got %d, %v", entries, err)
+	}
+	if changes, err := s.SchemaChanges(ctx, key.Source, syncedAt.Add(-time.Hour)); err != nil || len(changes) != 1 || changes[0] != *change {
+		t.Errorf("Expected the schema change to be kept, got %+v, %v", changes, err)
+	}
+}
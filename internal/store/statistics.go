@@ -0,0 +1,131 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-metadata/internal/collector"
+)
+
+// SaveStatistics stores the statistics of a stored table, replacing the
+// previous ones. The table must have been saved first.
+func (s *Store) SaveStatistics(ctx context.Context, key TableKey, stats *collector.TableStatistics) error {
+	if stats == nil {
+		return nil
+	}
+	id, err := s.tableID(ctx, s.db, key)
+	if err != nil {
+		return err
+	}
+	return s.saveStatistics(ctx, s.db, id, stats)
+}
+
+func (s *Store) saveStatistics(ctx context.Context, q querier, tableID int64, stats *collector.TableStatistics) error {
+	columnStats, err := jsonValue(stats.ColumnStats)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, s.rebind(`
+		INSERT INTO harvested_statistics (
+			table_id, row_count, data_size_bytes, partition_count, consumer_lag, column_stats, collected_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (table_id) DO UPDATE SET
+			row_count = EXCLUDED.row_count,
+			data_size_bytes = EXCLUDED.data_size_bytes,
+			partition_count = EXCLUDED.partition_count,
+			consumer_lag = EXCLUDED.consumer_lag,
+			column_stats = EXCLUDED.column_stats,
+			collected_at = EXCLUDED.collected_at`),
+		tableID, stats.RowCount, stats.DataSizeBytes, stats.PartitionCount, stats.ConsumerLag,
+		columnStats, stats.CollectedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("save statistics: %w", err)
+	}
+	return nil
+}
+
+// GetStatistics returns the stored statistics of a table, or nil if none were
+// saved. It returns ErrNotFound for tables that are not in the store.
+func (s *Store) GetStatistics(ctx context.Context, key TableKey) (*collector.TableStatistics, error) {
+	id, err := s.tableID(ctx, s.db, key)
+	if err != nil {
+		return nil, err
+	}
+	return s.statistics(ctx, s.db, id)
+}
+
+func (s *Store) statistics(ctx context.Context, q querier, tableID int64) (*collector.TableStatistics, error) {
+	var (
+		stats       collector.TableStatistics
+		consumerLag sql.NullInt64
+		columnStats []byte
+	)
+	err := q.QueryRowContext(ctx, s.rebind(`
+		SELECT row_count, data_size_bytes, partition_count, consumer_lag, column_stats, collected_at
+		FROM harvested_statistics WHERE table_id = $1`), tableID,
+	).Scan(&stats.RowCount, &stats.DataSizeBytes, &stats.PartitionCount, &consumerLag, &columnStats, &stats.CollectedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if consumerLag.Valid {
+		stats.ConsumerLag = &consumerLag.Int64
+	}
+	if err := unmarshalJSON(columnStats, &stats.ColumnStats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// SizeSnapshot is the size of a stored table at a point in time, recorded
+// when the catalog first sees the table and whenever its size changes.
+type SizeSnapshot struct {
+	TableKey
+	TableID     int64     `json:"table_id"`
+	RowCount    int64     `json:"row_count"`
+	DataSize    int64     `json:"data_size_bytes"`
+	CollectedAt time.Time `json:"collected_at"`
+}
+
+// SaveSizeSnapshot records the size of the stored table snapshot.TableID.
+// Snapshots are deleted with their table.
+func (s *Store) SaveSizeSnapshot(ctx context.Context, snapshot *SizeSnapshot) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`
+		INSERT INTO table_size_snapshots (table_id, row_count, data_size_bytes, collected_at)
+		VALUES ($1, $2, $3, $4)`),
+		snapshot.TableID, snapshot.RowCount, snapshot.DataSize, snapshot.CollectedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("save size snapshot of %s: %w", snapshot.TableKey, err)
+	}
+	return nil
+}
+
+// SizeSnapshots returns the size snapshots collected up to until, oldest
+// first.
+func (s *Store) SizeSnapshots(ctx context.Context, until time.Time) ([]SizeSnapshot, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT t.source, t.catalog_name, t.schema_name, t.table_name, z.table_id, z.row_count, z.data_size_bytes, z.collected_at
+		FROM table_size_snapshots z JOIN harvested_tables t ON t.id = z.table_id
+		WHERE z.collected_at <= $1
+		ORDER BY z.collected_at, z.id`), until.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []SizeSnapshot
+	for rows.Next() {
+		var z SizeSnapshot
+		if err := rows.Scan(&z.Source, &z.Catalog, &z.Schema, &z.Table, &z.TableID, &z.RowCount, &z.DataSize, &z.CollectedAt); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, z)
+	}
+	return snapshots, rows.Err()
+}
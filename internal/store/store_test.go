@@ -0,0 +1,135 @@
+package store
+
+import (
+	"io/fs"
+	"testing"
+	"time"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/data/migrate"
+	"go-metadata/migrations"
+)
+
+func TestMigrationsLoad(t *testing.T) {
+	for _, driver := range []string{DriverPostgres, DriverSQLite} {
+		sub, err := fs.Sub(migrations.FS, driver)
+		if err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := migrate.Load(sub)
+		if err != nil {
+			t.Fatalf("Load %s failed: %v", driver, err)
+		}
+		if len(loaded) == 0 {
+			t.Fatalf("No %s migrations", driver)
+		}
+		for i, m := range loaded {
+			if m.Version != uint(i+1) || m.Down == "" {
+				t.Errorf("%s migration %d: unexpected version or missing down script: %+v", driver, i, m)
+			}
+		}
+	}
+}
+
+func TestRebind(t *testing.T) {
+	query := "DELETE FROM harvested_tables WHERE source = $1 AND synced_at < $12"
+	if got := New(nil, DriverPostgres).rebind(query); got != query {
+		t.Errorf("postgres rebind changed the query: %s", got)
+	}
+	want := "DELETE FROM harvested_tables WHERE source = ?1 AND synced_at < ?12"
+	if got := New(nil, DriverSQLite).rebind(query); got != want {
+		t.Errorf("sqlite rebind = %s, want %s", got, want)
+	}
+}
+
+func TestJSONValue(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want any
+	}{
+		{"nil slice", []string(nil), nil},
+		{"empty slice", []string{}, nil},
+		{"nil pointer", (*collector.StorageInfo)(nil), nil},
+		{"empty map", map[string]string{}, nil},
+		{"slice", []string{"id"}, `["id"]`},
+		{"struct", &collector.GeneratedInfo{Expression: "a + b", Stored: true}, `{"expression":"a + b","stored":true}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonValue(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("jsonValue(%v) = %v, want %v", tt.v, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var generated *collector.GeneratedInfo
+	if err := unmarshalJSON(nil, &generated); err != nil || generated != nil {
+		t.Fatalf("Expected NULL to leave the value nil, got %+v, %v", generated, err)
+	}
+	if err := unmarshalJSON([]byte(`{"expression":"a + b","stored":true}`), &generated); err != nil {
+		t.Fatal(err)
+	}
+	if generated == nil || generated.Expression != "a + b" || !generated.Stored {
+		t.Errorf("Unexpected value %+v", generated)
+	}
+}
+
+func TestChangeRates(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(4 * 7 * 24 * time.Hour)
+	orders := TableKey{Source: "mysql", Catalog: "def", Schema: "shop", Table: "orders"}
+	users := TableKey{Source: "mysql", Catalog: "def", Schema: "shop", Table: "users"}
+	events := TableKey{Source: "kafka", Catalog: "kafka", Schema: "default", Table: "events"}
+	changes := []SchemaChange{
+		{TableKey: orders, ColumnsAdded: 2, ChangedAt: since.Add(-time.Hour)},
+		{TableKey: orders, ColumnsAdded: 1, ColumnsDropped: 1, ChangedAt: since.Add(24 * time.Hour)},
+		{TableKey: orders, ColumnsModified: 1, ChangedAt: since.Add(48 * time.Hour)},
+		{TableKey: users, ColumnsDropped: 2, ChangedAt: since.Add(72 * time.Hour)},
+		{TableKey: events, ColumnsAdded: 1, ChangedAt: since.Add(96 * time.Hour)},
+	}
+
+	rates := ChangeRates(changes, since, until)
+	if len(rates) != 2 {
+		t.Fatalf("Expected 2 sources, got %d", len(rates))
+	}
+	mysql := rates[0]
+	want := ChangeRate{
+		Source: "mysql", Events: 3, Tables: 2, ColumnsAdded: 1, ColumnsDropped: 3, ColumnsModified: 1,
+		EventsPerWeek: 0.75, LastChange: since.Add(72 * time.Hour),
+	}
+	if *mysql != want {
+		t.Errorf("ChangeRates()[0] = %+v, want %+v", *mysql, want)
+	}
+	if rates[1].Source != "kafka" || rates[1].Events != 1 {
+		t.Errorf("Unexpected rate %+v", rates[1])
+	}
+}
+
+func TestSubscriptionMatches(t *testing.T) {
+	tests := []struct {
+		sub                  Subscription
+		source, table, event string
+		want                 bool
+	}{
+		{Subscription{Source: "mysql_prod", Table: "shop.orders"}, "mysql_prod", "def.shop.orders", "schema_drift", true},
+		{Subscription{Source: "mysql_prod", Table: "SHOP.*"}, "mysql_prod", "def.shop.users", "schema_drift", true},
+		{Subscription{Source: "mysql_prod", Table: "orders"}, "mysql_prod", "def.shop.orders", "sla_breach", true},
+		{Subscription{Source: "mysql_prod", Table: "shop.orders"}, "mysql_prod", "def.shop.order_items", "schema_drift", false},
+		{Subscription{Source: "mysql_prod", Table: "shop.orders"}, "mysql_dev", "def.shop.orders", "schema_drift", false},
+		{Subscription{Source: "mysql_prod", Table: "shop.orders", Events: []string{"sla_breach"}}, "mysql_prod", "def.shop.orders", "schema_drift", false},
+		{Subscription{Source: "mysql_prod"}, "mysql_prod", "", "sync_failed", true},
+		{Subscription{Source: "mysql_prod", Table: "shop.*"}, "mysql_prod", "", "sync_failed", false},
+	}
+	for _, tt := range tests {
+		if got := tt.sub.Matches(tt.source, tt.table, tt.event); got != tt.want {
+			t.Errorf("%+v.Matches(%q, %q, %q) = %v, want %v", tt.sub, tt.source, tt.table, tt.event, got, tt.want)
+		}
+	}
+}
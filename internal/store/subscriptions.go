@@ -0,0 +1,200 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrNoSubscription is returned for subscriptions that are not in the store.
+var ErrNoSubscription = errors.New("subscription not found")
+
+// Subscription is a user watching the tables of a source: they are notified
+// of the events of these tables only.
+type Subscription struct {
+	User   string `json:"user"`
+	Source string `json:"source"`
+	// Table is a glob pattern (see path.Match) of the qualified names of the
+	// watched tables, e.g. shop.orders or shop.*, matched case-insensitively
+	// against catalog.schema.table and its schema.table and table suffixes.
+	// Empty watches every table of the source and its failed syncs.
+	Table string `json:"table,omitempty"`
+	// Events are the watched event types; empty watches all.
+	Events    []string  `json:"events,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Matches reports whether s watches the event of a table of source. Events
+// of the source itself, such as failed syncs, have an empty table.
+func (s *Subscription) Matches(source, table, event string) bool {
+	if s.Source != source {
+		return false
+	}
+	if len(s.Events) > 0 && !slices.Contains(s.Events, event) {
+		return false
+	}
+	if s.Table == "" {
+		return true
+	}
+	pattern, name := strings.ToLower(s.Table), strings.ToLower(table)
+	for name != "" {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		_, name, _ = strings.Cut(name, ".")
+	}
+	return false
+}
+
+// UserChannel is a channel a user receives the notifications of their
+// subscriptions in, e.g. their Slack webhook.
+type UserChannel struct {
+	User string `json:"user"`
+	// Type is slack, teams, dingtalk or webhook.
+	Type       string    `json:"type"`
+	WebhookURL string    `json:"webhook_url"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Subscribe records a subscription, replacing the events of the one of the
+// same user, source and table.
+func (s *Store) Subscribe(ctx context.Context, sub *Subscription) error {
+	events, err := jsonValue(sub.Events)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, s.rebind(`
+		INSERT INTO subscriptions (user_name, source, table_pattern, events, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_name, source, table_pattern) DO UPDATE SET events = EXCLUDED.events`),
+		sub.User, sub.Source, sub.Table, events, sub.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("subscribe %s to %s %s: %w", sub.User, sub.Source, sub.Table, err)
+	}
+	return nil
+}
+
+// Unsubscribe deletes a subscription, or returns ErrNoSubscription.
+func (s *Store) Unsubscribe(ctx context.Context, user, source, table string) error {
+	res, err := s.db.ExecContext(ctx, s.rebind(`
+		DELETE FROM subscriptions WHERE user_name = $1 AND source = $2 AND table_pattern = $3`),
+		user, source, table)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: %s %s %s", ErrNoSubscription, user, source, table)
+	}
+	return nil
+}
+
+// Subscriptions returns the subscriptions of a user, or of all users if user
+// is empty, ordered by user, source and table.
+func (s *Store) Subscriptions(ctx context.Context, user string) ([]Subscription, error) {
+	return s.subscriptions(ctx, `
+		SELECT user_name, source, table_pattern, events, created_at FROM subscriptions
+		WHERE $1 = '' OR user_name = $1
+		ORDER BY user_name, source, table_pattern`, user)
+}
+
+func (s *Store) subscriptions(ctx context.Context, query string, args ...any) ([]Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []Subscription
+	for rows.Next() {
+		var (
+			sub    Subscription
+			events []byte
+		)
+		if err := rows.Scan(&sub.User, &sub.Source, &sub.Table, &events, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := unmarshalJSON(events, &sub.Events); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// SaveUserChannel sets the channel of a type of a user.
+func (s *Store) SaveUserChannel(ctx context.Context, c *UserChannel) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`
+		INSERT INTO user_channels (user_name, channel_type, webhook_url, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_name, channel_type) DO UPDATE SET
+			webhook_url = EXCLUDED.webhook_url,
+			updated_at = EXCLUDED.updated_at`),
+		c.User, c.Type, c.WebhookURL, c.UpdatedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("save %s channel of %s: %w", c.Type, c.User, err)
+	}
+	return nil
+}
+
+// DeleteUserChannel deletes the channel of a type of a user, if any.
+func (s *Store) DeleteUserChannel(ctx context.Context, user, channelType string) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`
+		DELETE FROM user_channels WHERE user_name = $1 AND channel_type = $2`), user, channelType)
+	return err
+}
+
+// UserChannels returns the channels of a user, or of all users if user is
+// empty, ordered by user and type.
+func (s *Store) UserChannels(ctx context.Context, user string) ([]UserChannel, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT user_name, channel_type, webhook_url, updated_at FROM user_channels
+		WHERE $1 = '' OR user_name = $1
+		ORDER BY user_name, channel_type`), user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var channels []UserChannel
+	for rows.Next() {
+		var c UserChannel
+		if err := rows.Scan(&c.User, &c.Type, &c.WebhookURL, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		channels = append(channels, c)
+	}
+	return channels, rows.Err()
+}
+
+// Watchers returns the channels of the users whose subscriptions match the
+// event of a table of source (see Subscription.Matches), ordered by user and
+// type. Users without channels are left out.
+func (s *Store) Watchers(ctx context.Context, source, table, event string) ([]UserChannel, error) {
+	subs, err := s.subscriptions(ctx, `
+		SELECT user_name, source, table_pattern, events, created_at FROM subscriptions
+		WHERE source = $1
+		ORDER BY user_name`, source)
+	if err != nil {
+		return nil, err
+	}
+	var channels []UserChannel
+	notified := make(map[string]bool)
+	for _, sub := range subs {
+		if notified[sub.User] || !sub.Matches(source, table, event) {
+			continue
+		}
+		notified[sub.User] = true
+		user, err := s.UserChannels(ctx, sub.User)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, user...)
+	}
+	return channels, nil
+}
@@ -0,0 +1,504 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go-metadata/internal/collector"
+)
+
+// SaveTable stores the metadata of a table of source synced at syncedAt,
+// replacing its previous columns, indexes and partitions, and records it
+// fetched at syncedAt. Statistics in t.Stats are saved too.
+func (s *Store) SaveTable(ctx context.Context, source string, t *collector.TableMetadata, syncedAt time.Time) error {
+	primaryKey, err := jsonValue(t.PrimaryKey)
+	if err != nil {
+		return err
+	}
+	checks, err := jsonValue(t.CheckConstraints)
+	if err != nil {
+		return err
+	}
+	storage, err := jsonValue(t.Storage)
+	if err != nil {
+		return err
+	}
+	properties, err := jsonValue(t.Properties)
+	if err != nil {
+		return err
+	}
+	descriptions, err := jsonValue(t.Descriptions)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, s.rebind(`
+		INSERT INTO harvested_tables (
+			source, catalog_name, schema_name, table_name, source_category, source_type,
+			table_type, comment, descriptions, primary_key, check_constraints, storage, properties,
+			inferred_schema, last_refreshed_at, synced_at, fetched_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $16)
+		ON CONFLICT (source, catalog_name, schema_name, table_name) DO UPDATE SET
+			source_category = EXCLUDED.source_category,
+			source_type = EXCLUDED.source_type,
+			table_type = EXCLUDED.table_type,
+			comment = EXCLUDED.comment,
+			descriptions = EXCLUDED.descriptions,
+			primary_key = EXCLUDED.primary_key,
+			check_constraints = EXCLUDED.check_constraints,
+			storage = EXCLUDED.storage,
+			properties = EXCLUDED.properties,
+			inferred_schema = EXCLUDED.inferred_schema,
+			last_refreshed_at = EXCLUDED.last_refreshed_at,
+			synced_at = EXCLUDED.synced_at,
+			fetched_at = EXCLUDED.fetched_at
+		RETURNING id`),
+		source, t.Catalog, t.Schema, t.Name, string(t.SourceCategory), t.SourceType,
+		string(t.Type), t.Comment, descriptions, primaryKey, checks, storage, properties,
+		t.InferredSchema, nullTime(t.LastRefreshedAt), syncedAt.UTC(),
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("save table %s.%s: %w", t.Schema, t.Name, err)
+	}
+
+	for _, table := range []string{"harvested_columns", "harvested_indexes", "harvested_partitions"} {
+		if _, err := tx.ExecContext(ctx, s.rebind("DELETE FROM "+table+" WHERE table_id = $1"), id); err != nil {
+			return err
+		}
+	}
+	if err := s.saveColumns(ctx, tx, id, t.Columns); err != nil {
+		return err
+	}
+	if err := s.saveIndexes(ctx, tx, id, t.Indexes); err != nil {
+		return err
+	}
+	if err := s.savePartitions(ctx, tx, id, t.Partitions); err != nil {
+		return err
+	}
+	if t.Stats != nil {
+		if err := s.saveStatistics(ctx, tx, id, t.Stats); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) saveColumns(ctx context.Context, tx *sql.Tx, tableID int64, columns []collector.Column) error {
+	for _, c := range columns {
+		generated, err := jsonValue(c.Generated)
+		if err != nil {
+			return err
+		}
+		examples, err := jsonValue(c.Examples)
+		if err != nil {
+			return err
+		}
+		descriptions, err := jsonValue(c.Descriptions)
+		if err != nil {
+			return err
+		}
+		raw, err := jsonValue(c.Raw)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, s.rebind(`
+			INSERT INTO harvested_columns (
+				table_id, ordinal_position, column_name, data_type, source_type, length,
+				"precision", scale, nullable, default_value, comment, is_primary_key,
+				is_partition_column, is_auto_increment, generated, charset, collation_name, examples,
+				descriptions, raw)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)`),
+			tableID, c.OrdinalPosition, c.Name, c.Type, c.SourceType, c.Length,
+			c.Precision, c.Scale, c.Nullable, c.Default, c.Comment, c.IsPrimaryKey,
+			c.IsPartitionColumn, c.IsAutoIncrement, generated, c.Charset, c.Collation, examples,
+			descriptions, raw,
+		)
+		if err != nil {
+			return fmt.Errorf("save column %s: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) saveIndexes(ctx context.Context, tx *sql.Tx, tableID int64, indexes []collector.Index) error {
+	for _, idx := range indexes {
+		columns, err := jsonValue(idx.Columns)
+		if err != nil {
+			return err
+		}
+		if columns == nil {
+			columns = "[]"
+		}
+		_, err = tx.ExecContext(ctx, s.rebind(`
+			INSERT INTO harvested_indexes (table_id, index_name, columns, is_unique, index_type, comment)
+			VALUES ($1, $2, $3, $4, $5, $6)`),
+			tableID, idx.Name, columns, idx.Unique, idx.Type, idx.Comment,
+		)
+		if err != nil {
+			return fmt.Errorf("save index %s: %w", idx.Name, err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) savePartitions(ctx context.Context, tx *sql.Tx, tableID int64, partitions []collector.PartitionInfo) error {
+	for _, p := range partitions {
+		columns, err := jsonValue(p.Columns)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, s.rebind(`
+			INSERT INTO harvested_partitions (table_id, partition_name, partition_type, columns, expression, values_count)
+			VALUES ($1, $2, $3, $4, $5, $6)`),
+			tableID, p.Name, p.Type, columns, p.Expression, p.ValuesCount,
+		)
+		if err != nil {
+			return fmt.Errorf("save partition %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// GetTable returns the stored metadata of a table, including its statistics
+// if any, or ErrNotFound.
+func (s *Store) GetTable(ctx context.Context, key TableKey) (*collector.TableMetadata, error) {
+	var (
+		id                                                    int64
+		category, tableType                                   string
+		descriptions, primaryKey, checks, storage, properties []byte
+		lastRefreshedAt                                       sql.NullTime
+	)
+	t := &collector.TableMetadata{Catalog: key.Catalog, Schema: key.Schema, Name: key.Table}
+	err := s.db.QueryRowContext(ctx, s.rebind(`
+		SELECT id, source_category, source_type, table_type, comment, descriptions, primary_key,
+			check_constraints, storage, properties, inferred_schema, last_refreshed_at
+		FROM harvested_tables
+		WHERE source = $1 AND catalog_name = $2 AND schema_name = $3 AND table_name = $4`),
+		key.Source, key.Catalog, key.Schema, key.Table,
+	).Scan(&id, &category, &t.SourceType, &tableType, &t.Comment, &descriptions, &primaryKey,
+		&checks, &storage, &properties, &t.InferredSchema, &lastRefreshedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.SourceCategory = collector.DataSourceCategory(category)
+	t.Type = collector.TableType(tableType)
+	t.LastRefreshedAt = lastRefreshedAt.Time
+	if err := unmarshalJSON(descriptions, &t.Descriptions); err != nil {
+		return nil, err
+	}
+	if err := unmarshalJSON(primaryKey, &t.PrimaryKey); err != nil {
+		return nil, err
+	}
+	if err := unmarshalJSON(checks, &t.CheckConstraints); err != nil {
+		return nil, err
+	}
+	if err := unmarshalJSON(storage, &t.Storage); err != nil {
+		return nil, err
+	}
+	if err := unmarshalJSON(properties, &t.Properties); err != nil {
+		return nil, err
+	}
+
+	if t.Columns, err = s.columns(ctx, id); err != nil {
+		return nil, err
+	}
+	if t.Indexes, err = s.indexes(ctx, id); err != nil {
+		return nil, err
+	}
+	if t.Partitions, err = s.partitions(ctx, id); err != nil {
+		return nil, err
+	}
+	if t.Stats, err = s.statistics(ctx, s.db, id); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *Store) columns(ctx context.Context, tableID int64) ([]collector.Column, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT ordinal_position, column_name, data_type, source_type, length, "precision",
+			scale, nullable, default_value, comment, is_primary_key, is_partition_column,
+			is_auto_increment, generated, charset, collation_name, examples, descriptions, raw
+		FROM harvested_columns WHERE table_id = $1 ORDER BY ordinal_position`), tableID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []collector.Column
+	for rows.Next() {
+		var (
+			c                                      collector.Column
+			length, precision, scale               sql.NullInt64
+			defaultValue                           sql.NullString
+			generated, examples, descriptions, raw []byte
+		)
+		if err := rows.Scan(&c.OrdinalPosition, &c.Name, &c.Type, &c.SourceType, &length, &precision,
+			&scale, &c.Nullable, &defaultValue, &c.Comment, &c.IsPrimaryKey, &c.IsPartitionColumn,
+			&c.IsAutoIncrement, &generated, &c.Charset, &c.Collation, &examples, &descriptions, &raw); err != nil {
+			return nil, err
+		}
+		c.Length = nullInt(length)
+		c.Precision = nullInt(precision)
+		c.Scale = nullInt(scale)
+		if defaultValue.Valid {
+			c.Default = &defaultValue.String
+		}
+		if err := unmarshalJSON(generated, &c.Generated); err != nil {
+			return nil, err
+		}
+		if err := unmarshalJSON(examples, &c.Examples); err != nil {
+			return nil, err
+		}
+		if err := unmarshalJSON(descriptions, &c.Descriptions); err != nil {
+			return nil, err
+		}
+		if err := unmarshalJSON(raw, &c.Raw); err != nil {
+			return nil, err
+		}
+		columns = append(columns, c)
+	}
+	return columns, rows.Err()
+}
+
+func (s *Store) indexes(ctx context.Context, tableID int64) ([]collector.Index, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT index_name, columns, is_unique, index_type, comment
+		FROM harvested_indexes WHERE table_id = $1 ORDER BY index_name`), tableID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []collector.Index
+	for rows.Next() {
+		var idx collector.Index
+		var columns []byte
+		if err := rows.Scan(&idx.Name, &columns, &idx.Unique, &idx.Type, &idx.Comment); err != nil {
+			return nil, err
+		}
+		if err := unmarshalJSON(columns, &idx.Columns); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, idx)
+	}
+	return indexes, rows.Err()
+}
+
+func (s *Store) partitions(ctx context.Context, tableID int64) ([]collector.PartitionInfo, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT partition_name, partition_type, columns, expression, values_count
+		FROM harvested_partitions WHERE table_id = $1 ORDER BY partition_name`), tableID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partitions []collector.PartitionInfo
+	for rows.Next() {
+		var p collector.PartitionInfo
+		var columns []byte
+		if err := rows.Scan(&p.Name, &p.Type, &columns, &p.Expression, &p.ValuesCount); err != nil {
+			return nil, err
+		}
+		if err := unmarshalJSON(columns, &p.Columns); err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, p)
+	}
+	return partitions, rows.Err()
+}
+
+// ListTables returns the names of the stored tables of a schema of a source.
+func (s *Store) ListTables(ctx context.Context, source, catalog, schema string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT table_name FROM harvested_tables
+		WHERE source = $1 AND catalog_name = $2 AND schema_name = $3
+		ORDER BY table_name`), source, catalog, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// Tables returns the keys of the stored tables of a source, or of every
+// source if source is empty.
+func (s *Store) Tables(ctx context.Context, source string) ([]TableKey, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT source, catalog_name, schema_name, table_name FROM harvested_tables
+		WHERE $1 = '' OR source = $1
+		ORDER BY source, catalog_name, schema_name, table_name`), source)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []TableKey
+	for rows.Next() {
+		var key TableKey
+		if err := rows.Scan(&key.Source, &key.Catalog, &key.Schema, &key.Table); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// FindTables returns the keys of the stored tables of any source named table
+// in database, matched case-insensitively against their schema or catalog.
+// An empty database finds the tables of that name in every schema.
+func (s *Store) FindTables(ctx context.Context, database, table string) ([]TableKey, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT source, catalog_name, schema_name, table_name FROM harvested_tables
+		WHERE lower(table_name) = lower($1)
+			AND ($2 = '' OR lower(schema_name) = lower($2) OR lower(catalog_name) = lower($2))
+		ORDER BY source, catalog_name, schema_name, table_name`), table, database)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []TableKey
+	for rows.Next() {
+		var key TableKey
+		if err := rows.Scan(&key.Source, &key.Catalog, &key.Schema, &key.Table); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// ColumnNames returns the names of the columns of a stored table in ordinal
+// order, or ErrNotFound.
+func (s *Store) ColumnNames(ctx context.Context, key TableKey) ([]string, error) {
+	tableID, err := s.tableID(ctx, s.db, key)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT column_name FROM harvested_columns WHERE table_id = $1 ORDER BY ordinal_position`), tableID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// DeleteTable removes a table and everything stored for it.
+func (s *Store) DeleteTable(ctx context.Context, key TableKey) error {
+	res, err := s.db.ExecContext(ctx, s.rebind(`
+		DELETE FROM harvested_tables
+		WHERE source = $1 AND catalog_name = $2 AND schema_name = $3 AND table_name = $4`),
+		key.Source, key.Catalog, key.Schema, key.Table)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return nil
+}
+
+// PruneTables removes the tables of a source not synced since before, i.e.
+// tables dropped from the source since the last complete sync, and returns
+// the number removed.
+func (s *Store) PruneTables(ctx context.Context, source string, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.rebind(
+		"DELETE FROM harvested_tables WHERE source = $1 AND synced_at < $2"), source, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// StaleTables returns the tables of a source not synced since before, the
+// tables PruneTables would remove.
+func (s *Store) StaleTables(ctx context.Context, source string, before time.Time) ([]TableKey, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT catalog_name, schema_name, table_name FROM harvested_tables
+		WHERE source = $1 AND synced_at < $2
+		ORDER BY catalog_name, schema_name, table_name`), source, before.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []TableKey
+	for rows.Next() {
+		key := TableKey{Source: source}
+		if err := rows.Scan(&key.Catalog, &key.Schema, &key.Table); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// jsonValue encodes v as a JSON parameter, or NULL for nil and empty values.
+func jsonValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	switch string(data) {
+	case "null", "[]", "{}":
+		return nil, nil
+	}
+	return string(data), nil
+}
+
+// unmarshalJSON decodes a JSON column, leaving v unchanged for NULL.
+func unmarshalJSON(data []byte, v any) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+func nullInt(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	n := int(v.Int64)
+	return &n
+}
+
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
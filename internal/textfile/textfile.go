@@ -0,0 +1,121 @@
+// Package textfile opens the text files named on the command line: SQL
+// scripts, DDL and config files. It reads "-" as standard input, expands
+// glob patterns and directories itself, since the Windows shells do not, and
+// decodes UTF-16 files, such as the scripts SQL Server Management Studio
+// exports, to UTF-8, dropping byte order marks.
+package textfile
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Stdin is the name that reads standard input.
+const Stdin = "-"
+
+// Open opens the named file, or standard input for Stdin, for reading as
+// UTF-8.
+func Open(name string) (io.ReadCloser, error) {
+	if name == Stdin {
+		return readCloser{Reader: NewReader(os.Stdin), Closer: io.NopCloser(nil)}, nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return readCloser{Reader: NewReader(f), Closer: f}, nil
+}
+
+// ReadFile reads the named file, or standard input for Stdin, as UTF-8.
+func ReadFile(name string) (string, error) {
+	r, err := Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	return string(data), err
+}
+
+// NewReader returns a reader decoding r to UTF-8. The encoding is taken from
+// the byte order mark of r; without one, text whose first character has a
+// zero byte is UTF-16 of that byte order, anything else is passed as is.
+func NewReader(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(2)
+
+	fallback := encoding.Nop
+	if len(head) == 2 {
+		switch {
+		case head[0] != 0 && head[1] == 0:
+			fallback = unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+		case head[0] == 0 && head[1] != 0:
+			fallback = unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+		}
+	}
+	return transform.NewReader(br, unicode.BOMOverride(fallback.NewDecoder()))
+}
+
+// Expand returns the files a path names: Stdin, a file, the files matching a
+// glob pattern or, for a directory, the files with extension ext (e.g.
+// ".sql") below it. Directories matched by a pattern are expanded too. The
+// files are returned sorted and without duplicates.
+func Expand(path, ext string) ([]string, error) {
+	if path == Stdin {
+		return []string{Stdin}, nil
+	}
+
+	paths := []string{path}
+	if _, err := os.Stat(path); err != nil && hasMeta(path) {
+		if paths, err = filepath.Glob(path); err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, &os.PathError{Op: "glob", Path: path, Err: os.ErrNotExist}
+		}
+	}
+
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		err = filepath.WalkDir(p, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.EqualFold(filepath.Ext(p), ext) {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// hasMeta reports whether path contains glob metacharacters.
+func hasMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,113 @@
+package textfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"unicode/utf16"
+)
+
+const script = "SELECT 'café' FROM t;\n"
+
+func utf16Bytes(s string, order binary.ByteOrder, bom bool) []byte {
+	var buf bytes.Buffer
+	if bom {
+		binary.Write(&buf, order, uint16(0xFEFF))
+	}
+	for _, u := range utf16.Encode([]rune(s)) {
+		binary.Write(&buf, order, u)
+	}
+	return buf.Bytes()
+}
+
+func TestNewReader(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+	}{
+		{"utf-8", []byte(script)},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, script...)},
+		{"utf-16le bom", utf16Bytes(script, binary.LittleEndian, true)},
+		{"utf-16be bom", utf16Bytes(script, binary.BigEndian, true)},
+		{"utf-16le", utf16Bytes(script, binary.LittleEndian, false)},
+		{"utf-16be", utf16Bytes(script, binary.BigEndian, false)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := io.ReadAll(NewReader(bytes.NewReader(tt.in)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != script {
+				t.Errorf("Got %q, want %q", got, script)
+			}
+		})
+	}
+
+	latin1 := []byte("SELECT 'caf\xe9'")
+	if got, _ := io.ReadAll(NewReader(bytes.NewReader(latin1))); !bytes.Equal(got, latin1) {
+		t.Errorf("Expected bytes that are not UTF-8 to pass unchanged, got %q", got)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.sql")
+	if err := os.WriteFile(path, utf16Bytes(script, binary.LittleEndian, true), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadFile(path)
+	if err != nil || got != script {
+		t.Errorf("ReadFile() = %q, %v, want %q", got, err, script)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.sql", "b.SQL", "notes.txt", filepath.Join("models", "c.sql"), filepath.Join("models", "staging", "d.sql")} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	join := func(names ...string) []string {
+		paths := make([]string, len(names))
+		for i, name := range names {
+			paths[i] = filepath.Join(dir, name)
+		}
+		return paths
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{"stdin", Stdin, []string{Stdin}},
+		{"file", filepath.Join(dir, "notes.txt"), join("notes.txt")},
+		{"directory", filepath.Join(dir, "models"), join(filepath.Join("models", "c.sql"), filepath.Join("models", "staging", "d.sql"))},
+		{"glob", filepath.Join(dir, "*.sql"), join("a.sql")},
+		{"glob matching a directory", filepath.Join(dir, "mod*"), join(filepath.Join("models", "c.sql"), filepath.Join("models", "staging", "d.sql"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expand(tt.path, ".sql")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Expand(filepath.Join(dir, "*.csv"), ".sql"); !os.IsNotExist(err) {
+		t.Errorf("Expected a not exist error for a glob without matches, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+// Package tlsprofile restricts the TLS versions, cipher suites and key
+// exchanges of outbound connections (collectors, graph stores, exporters and
+// webhooks) to a configured profile, e.g. the FIPS 140 approved subset.
+//
+// The profile is process-wide: Apply installs it once at startup, before any
+// connection is made, and clients pick it up through Client, Restrict and
+// Transport. Clients using http.DefaultTransport are covered by Apply itself.
+package tlsprofile
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Profiles
+const (
+	// ProfileDefault keeps the defaults of crypto/tls and the drivers, only
+	// applying min_version and cipher_suites if set.
+	ProfileDefault = "default"
+	// ProfileFIPS allows TLS 1.2+ with the NIST SP 800-52 approved ECDHE
+	// AES-GCM suites and NIST curves only.
+	ProfileFIPS = "fips"
+)
+
+// ErrInvalidConfig is returned by Apply for an unknown profile, TLS version
+// or cipher suite.
+var ErrInvalidConfig = errors.New("invalid tls config")
+
+// Config is the tls section of the server config.
+//
+//	tls:
+//	  profile: "fips"
+//	  min_version: "1.2"
+//	  cipher_suites:
+//	    - "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"
+type Config struct {
+	Profile string `json:"profile" yaml:"profile"` // default, fips
+	// MinVersion is the lowest TLS version offered: 1.2 or 1.3.
+	MinVersion string `json:"min_version" yaml:"min_version"`
+	// CipherSuites limits the TLS 1.2 suites offered; empty means all the
+	// profile allows. TLS 1.3 suites are not configurable in crypto/tls.
+	CipherSuites []string `json:"cipher_suites" yaml:"cipher_suites"`
+}
+
+// fipsCipherSuites are the approved TLS 1.2 suites crypto/tls implements.
+var fipsCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+// fipsCurves are the approved key exchanges; X25519 is not one of them.
+var fipsCurves = []tls.CurveID{tls.CurveP256, tls.CurveP384, tls.CurveP521}
+
+var versions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+type profile struct {
+	minVersion   uint16
+	cipherSuites []uint16
+	curves       []tls.CurveID
+}
+
+// current is nil while no restriction is configured.
+var current atomic.Pointer[profile]
+
+// Apply validates cfg and installs it as the profile of all outbound TLS,
+// including http.DefaultTransport. A nil or empty config removes the profile.
+func Apply(cfg *Config) error {
+	p, err := compile(cfg)
+	if err != nil {
+		return err
+	}
+	current.Store(p)
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		t.TLSClientConfig = Client()
+	}
+	return nil
+}
+
+func compile(cfg *Config) (*profile, error) {
+	if cfg == nil || (cfg.Profile == "" && cfg.MinVersion == "" && len(cfg.CipherSuites) == 0) {
+		return nil, nil
+	}
+
+	p := &profile{minVersion: tls.VersionTLS12}
+	switch cfg.Profile {
+	case "", ProfileDefault:
+	case ProfileFIPS:
+		p.cipherSuites = fipsCipherSuites
+		p.curves = fipsCurves
+	default:
+		return nil, fmt.Errorf("%w: unknown profile %q", ErrInvalidConfig, cfg.Profile)
+	}
+
+	if cfg.MinVersion != "" {
+		v, ok := versions[cfg.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("%w: min_version must be 1.2 or 1.3, got %q", ErrInvalidConfig, cfg.MinVersion)
+		}
+		p.minVersion = v
+	}
+
+	if len(cfg.CipherSuites) > 0 {
+		suites := make([]uint16, 0, len(cfg.CipherSuites))
+		for _, name := range cfg.CipherSuites {
+			id, ok := cipherSuite(name)
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown or insecure cipher suite %q", ErrInvalidConfig, name)
+			}
+			if p.cipherSuites != nil && !slices.Contains(p.cipherSuites, id) {
+				return nil, fmt.Errorf("%w: cipher suite %s is not allowed by the %s profile", ErrInvalidConfig, name, cfg.Profile)
+			}
+			suites = append(suites, id)
+		}
+		p.cipherSuites = suites
+	}
+	return p, nil
+}
+
+// cipherSuite looks up a secure TLS 1.2 suite by its IANA name.
+func cipherSuite(name string) (uint16, bool) {
+	for _, s := range tls.CipherSuites() {
+		if strings.EqualFold(s.Name, name) && slices.Contains(s.SupportedVersions, tls.VersionTLS12) {
+			return s.ID, true
+		}
+	}
+	return 0, false
+}
+
+// Enabled reports whether a profile is installed.
+func Enabled() bool {
+	return current.Load() != nil
+}
+
+// Client returns a client config of the installed profile, or nil, meaning
+// the library defaults, if there is none.
+func Client() *tls.Config {
+	if !Enabled() {
+		return nil
+	}
+	return Restrict(&tls.Config{})
+}
+
+// Restrict applies the installed profile to a copy of c, keeping its server
+// name, certificates and verification settings. A stricter MinVersion of c is
+// kept. Without a profile c is returned as is.
+func Restrict(c *tls.Config) *tls.Config {
+	p := current.Load()
+	if p == nil {
+		return c
+	}
+	if c == nil {
+		c = &tls.Config{}
+	}
+	c = c.Clone()
+	if c.MinVersion < p.minVersion {
+		c.MinVersion = p.minVersion
+	}
+	if c.MaxVersion != 0 && c.MaxVersion < c.MinVersion {
+		c.MaxVersion = c.MinVersion
+	}
+	if p.cipherSuites != nil {
+		c.CipherSuites = slices.Clone(p.cipherSuites)
+	}
+	if p.curves != nil {
+		c.CurvePreferences = slices.Clone(p.curves)
+	}
+	return c
+}
+
+// Transport returns a copy of http.DefaultTransport using the installed
+// profile for clients that need their own transport, or nil if there is no
+// profile.
+func Transport() http.RoundTripper {
+	if !Enabled() {
+		return nil
+	}
+	t, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil
+	}
+	t = t.Clone()
+	t.TLSClientConfig = Client()
+	return t
+}
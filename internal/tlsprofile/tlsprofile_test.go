@@ -0,0 +1,112 @@
+package tlsprofile
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func apply(t *testing.T, cfg *Config) {
+	t.Helper()
+	if err := Apply(cfg); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	t.Cleanup(func() { Apply(nil) })
+}
+
+func TestApply_Default(t *testing.T) {
+	apply(t, &Config{})
+
+	if Enabled() || Client() != nil || Transport() != nil {
+		t.Error("Expected no profile for an empty config")
+	}
+	c := &tls.Config{ServerName: "db"}
+	if Restrict(c) != c {
+		t.Error("Expected Restrict to return the config unchanged")
+	}
+}
+
+func TestApply_FIPS(t *testing.T) {
+	apply(t, &Config{Profile: ProfileFIPS})
+
+	c := Restrict(&tls.Config{ServerName: "db", InsecureSkipVerify: true})
+	if c.ServerName != "db" || !c.InsecureSkipVerify {
+		t.Errorf("Restrict lost settings: %+v", c)
+	}
+	if c.MinVersion != tls.VersionTLS12 {
+		t.Errorf("Expected TLS 1.2 minimum, got %x", c.MinVersion)
+	}
+	if !slices.Equal(c.CipherSuites, fipsCipherSuites) || !slices.Equal(c.CurvePreferences, fipsCurves) {
+		t.Errorf("Unexpected suites %v / curves %v", c.CipherSuites, c.CurvePreferences)
+	}
+	if Restrict(&tls.Config{MinVersion: tls.VersionTLS13}).MinVersion != tls.VersionTLS13 {
+		t.Error("Expected Restrict to keep a stricter MinVersion")
+	}
+	if got := http.DefaultTransport.(*http.Transport).TLSClientConfig; got == nil || got.MinVersion != tls.VersionTLS12 {
+		t.Errorf("Expected profile on http.DefaultTransport, got %+v", got)
+	}
+}
+
+func TestApply_CipherSuites(t *testing.T) {
+	apply(t, &Config{MinVersion: "1.3", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"}})
+
+	c := Client()
+	if c.MinVersion != tls.VersionTLS13 {
+		t.Errorf("Expected TLS 1.3 minimum, got %x", c.MinVersion)
+	}
+	if !slices.Equal(c.CipherSuites, []uint16{tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256}) {
+		t.Errorf("Unexpected suites %v", c.CipherSuites)
+	}
+}
+
+func TestApply_Invalid(t *testing.T) {
+	apply(t, &Config{Profile: ProfileFIPS})
+
+	tests := []*Config{
+		{Profile: "strict"},
+		{MinVersion: "1.1"},
+		{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+		{CipherSuites: []string{"TLS_AES_128_GCM_SHA256"}},
+		{Profile: ProfileFIPS, CipherSuites: []string{"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"}},
+	}
+	for _, cfg := range tests {
+		if err := Apply(cfg); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Expected ErrInvalidConfig for %+v, got %v", cfg, err)
+		}
+	}
+	if !Enabled() || Client().CipherSuites == nil {
+		t.Error("Expected a failed Apply to keep the previous profile")
+	}
+}
+
+func TestRestrict_Handshake(t *testing.T) {
+	apply(t, &Config{Profile: ProfileFIPS})
+
+	tests := []struct {
+		suite uint16
+		ok    bool
+	}{
+		{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, true},
+		{tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256, false},
+	}
+	for _, tt := range tests {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12, CipherSuites: []uint16{tt.suite}}
+		server.StartTLS()
+
+		client := server.Client()
+		transport := client.Transport.(*http.Transport)
+		transport.TLSClientConfig = Restrict(transport.TLSClientConfig)
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if (err == nil) != tt.ok {
+			t.Errorf("Suite %s: expected ok=%v, got %v", tls.CipherSuiteName(tt.suite), tt.ok, err)
+		}
+		server.Close()
+	}
+}
@@ -0,0 +1,331 @@
+// Package urn builds the identifiers of datasets and their fields used in
+// exports and events, so that they line up with the identifiers of the
+// downstream ecosystem: DataHub URNs, OpenLineage namespaces and names, or a
+// custom template.
+package urn
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Built-in schemes.
+const (
+	// SchemeNative identifies datasets by their qualified name, db.table.
+	SchemeNative = "native"
+	// SchemeDataHub identifies datasets by DataHub dataset URNs,
+	// urn:li:dataset:(urn:li:dataPlatform:mysql,db.table,PROD).
+	SchemeDataHub = "datahub"
+	// SchemeOpenLineage identifies datasets by OpenLineage namespace and
+	// name, mysql://db.internal:3306/db.table.
+	SchemeOpenLineage = "openlineage"
+	// SchemeTemplate identifies datasets by Config.Template.
+	SchemeTemplate = "template"
+)
+
+// ErrUnknownScheme is returned for schemes that are not registered.
+var ErrUnknownScheme = errors.New("unknown urn scheme")
+
+// Dataset is a table to identify.
+type Dataset struct {
+	// Platform is the type of the data source, e.g. mysql or hive.
+	Platform string
+	// Instance is the name or address of the data source, e.g. mysql-prod
+	// or db.internal:3306.
+	Instance string
+	Database string
+	Schema   string
+	Table    string
+}
+
+// Name returns the qualified name of d, its non-empty database, schema and
+// table joined by dots.
+func (d Dataset) Name() string {
+	parts := make([]string, 0, 3)
+	for _, p := range []string{d.Database, d.Schema, d.Table} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// Scheme builds the identifiers of datasets and fields.
+type Scheme interface {
+	// Dataset returns the identifier of d.
+	Dataset(d Dataset) string
+	// Field returns the identifier of a field of d.
+	Field(d Dataset, field string) string
+	// OpenLineage returns the namespace and name of d in OpenLineage events.
+	OpenLineage(d Dataset) (namespace, name string)
+}
+
+// Factory creates a scheme from its config.
+type Factory func(cfg *Config) (Scheme, error)
+
+var (
+	schemesMu sync.RWMutex
+	schemes   = map[string]Factory{
+		SchemeNative:      func(*Config) (Scheme, error) { return nativeScheme{}, nil },
+		SchemeDataHub:     newDataHubScheme,
+		SchemeOpenLineage: func(*Config) (Scheme, error) { return openLineageScheme{}, nil },
+		SchemeTemplate:    newTemplateScheme,
+	}
+)
+
+// RegisterScheme registers the factory of the named scheme, replacing the
+// scheme of that name if any.
+func RegisterScheme(name string, f Factory) {
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+	schemes[name] = f
+}
+
+// Schemes returns the names of the registered schemes.
+func Schemes() []string {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Config configures the identifiers of datasets.
+type Config struct {
+	// Scheme is the name of the scheme; defaults to SchemeNative.
+	Scheme string `yaml:"scheme" json:"scheme"`
+	// Env is the DataHub environment (fabric) of datasets; defaults to PROD.
+	Env string `yaml:"env" json:"env"`
+	// Template is the text/template of dataset identifiers of
+	// SchemeTemplate. It sees the fields of Dataset and .Name, e.g.
+	// "{{.Platform}}:{{.Instance}}:{{.Name}}".
+	Template string `yaml:"template" json:"template"`
+	// FieldTemplate is the text/template of field identifiers of
+	// SchemeTemplate. It also sees .Dataset, the identifier of the dataset,
+	// and .Field; defaults to "{{.Dataset}}#{{.Field}}".
+	FieldTemplate string `yaml:"field_template" json:"field_template"`
+	// Platform and Instance are the platform and instance of datasets whose
+	// source is not listed in Sources.
+	Platform string `yaml:"platform" json:"platform"`
+	Instance string `yaml:"instance" json:"instance"`
+	// Sources are the platform and instance of datasets by source name;
+	// the instance defaults to the source name.
+	Sources map[string]SourceConfig `yaml:"sources" json:"sources"`
+}
+
+// SourceConfig is the platform and instance of the datasets of a source.
+type SourceConfig struct {
+	Platform string `yaml:"platform" json:"platform"`
+	Instance string `yaml:"instance" json:"instance"`
+}
+
+// Namer identifies the datasets of sources with a scheme.
+type Namer struct {
+	Scheme
+	cfg Config
+}
+
+// New creates a namer for cfg. A nil cfg uses SchemeNative.
+func New(cfg *Config) (*Namer, error) {
+	n := &Namer{}
+	if cfg != nil {
+		n.cfg = *cfg
+	}
+	if n.cfg.Scheme == "" {
+		n.cfg.Scheme = SchemeNative
+	}
+	schemesMu.RLock()
+	f, ok := schemes[n.cfg.Scheme]
+	schemesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q, one of: %s", ErrUnknownScheme, n.cfg.Scheme, strings.Join(Schemes(), ", "))
+	}
+	s, err := f(&n.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("urn scheme %s: %w", n.cfg.Scheme, err)
+	}
+	n.Scheme = s
+	return n, nil
+}
+
+// Resolve returns the dataset of a table of source, a qualified db.table,
+// schema.table or db.schema.table name.
+func (n *Namer) Resolve(source, table string) Dataset {
+	d := Dataset{Platform: n.cfg.Platform, Instance: n.cfg.Instance}
+	if source != "" {
+		d.Instance = source
+	}
+	if sc, ok := n.cfg.Sources[source]; ok {
+		if sc.Platform != "" {
+			d.Platform = sc.Platform
+		}
+		if sc.Instance != "" {
+			d.Instance = sc.Instance
+		}
+	}
+	parts := strings.Split(table, ".")
+	switch len(parts) {
+	case 1:
+		d.Table = parts[0]
+	case 2:
+		d.Database, d.Table = parts[0], parts[1]
+	default:
+		d.Database, d.Schema, d.Table = parts[0], strings.Join(parts[1:len(parts)-1], "."), parts[len(parts)-1]
+	}
+	return d
+}
+
+// DatasetURN returns the identifier of a table of source.
+func (n *Namer) DatasetURN(source, table string) string {
+	return n.Dataset(n.Resolve(source, table))
+}
+
+type nativeScheme struct{}
+
+func (nativeScheme) Dataset(d Dataset) string { return d.Name() }
+
+func (nativeScheme) Field(d Dataset, field string) string { return d.Name() + "." + field }
+
+func (nativeScheme) OpenLineage(d Dataset) (string, string) { return d.Instance, d.Name() }
+
+// dataHubScheme follows https://datahubproject.io/docs/what/urn. Like
+// DataHub ingestion without a platform_instance, dataset names leave the
+// instance out.
+type dataHubScheme struct {
+	env string
+}
+
+func newDataHubScheme(cfg *Config) (Scheme, error) {
+	env := strings.ToUpper(cfg.Env)
+	if env == "" {
+		env = "PROD"
+	}
+	return dataHubScheme{env: env}, nil
+}
+
+func (s dataHubScheme) Dataset(d Dataset) string {
+	return fmt.Sprintf("urn:li:dataset:(urn:li:dataPlatform:%s,%s,%s)", platform(d), d.Name(), s.env)
+}
+
+func (s dataHubScheme) Field(d Dataset, field string) string {
+	return fmt.Sprintf("urn:li:schemaField:(%s,%s)", s.Dataset(d), field)
+}
+
+// OpenLineage uses the namespaces of the DataHub OpenLineage converter,
+// whose platform is the scheme of the namespace.
+func (s dataHubScheme) OpenLineage(d Dataset) (string, string) {
+	return openLineageScheme{}.OpenLineage(d)
+}
+
+// openLineageScheme follows the OpenLineage naming conventions
+// (https://openlineage.io/docs/spec/naming): the namespace is the platform
+// and instance, platform://instance, and the name the qualified table name.
+type openLineageScheme struct{}
+
+func (s openLineageScheme) Dataset(d Dataset) string {
+	namespace, name := s.OpenLineage(d)
+	return namespace + "/" + name
+}
+
+func (s openLineageScheme) Field(d Dataset, field string) string {
+	return s.Dataset(d) + "#" + field
+}
+
+func (openLineageScheme) OpenLineage(d Dataset) (string, string) {
+	namespace := platform(d) + "://"
+	if d.Instance != "" {
+		namespace += d.Instance
+	}
+	return namespace, d.Name()
+}
+
+// templateScheme renders Config.Template; its OpenLineage namespace is the
+// instance and its name the identifier.
+type templateScheme struct {
+	dataset, field *template.Template
+}
+
+func newTemplateScheme(cfg *Config) (Scheme, error) {
+	if cfg.Template == "" {
+		return nil, errors.New("template is required")
+	}
+	dataset, err := template.New("dataset").Option("missingkey=error").Parse(cfg.Template)
+	if err != nil {
+		return nil, err
+	}
+	text := cfg.FieldTemplate
+	if text == "" {
+		text = "{{.Dataset}}#{{.Field}}"
+	}
+	field, err := template.New("field").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	s := &templateScheme{dataset: dataset, field: field}
+	// Unknown fields are only reported when the template runs
+	sample := Dataset{Platform: "mysql", Instance: "db", Database: "db", Table: "t"}
+	if err := dataset.Execute(io.Discard, s.data(sample)); err != nil {
+		return nil, err
+	}
+	fieldData := s.data(sample)
+	fieldData["Dataset"], fieldData["Field"] = "", "c"
+	if err := field.Execute(io.Discard, fieldData); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// data returns the data of the templates of d: the fields of Dataset and
+// .Name.
+func (s *templateScheme) data(d Dataset) map[string]string {
+	return map[string]string{
+		"Platform": d.Platform,
+		"Instance": d.Instance,
+		"Database": d.Database,
+		"Schema":   d.Schema,
+		"Table":    d.Table,
+		"Name":     d.Name(),
+	}
+}
+
+func (s *templateScheme) Dataset(d Dataset) string {
+	return execute(s.dataset, s.data(d))
+}
+
+func (s *templateScheme) Field(d Dataset, field string) string {
+	data := s.data(d)
+	data["Dataset"], data["Field"] = s.Dataset(d), field
+	return execute(s.field, data)
+}
+
+func (s *templateScheme) OpenLineage(d Dataset) (string, string) {
+	return d.Instance, s.Dataset(d)
+}
+
+// execute renders t. Templates are checked when the scheme is created, so
+// only a template failing on a particular value renders "".
+func execute(t *template.Template, data map[string]string) string {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// platform returns the platform of d, lowercase as both DataHub and
+// OpenLineage name platforms, or "unknown".
+func platform(d Dataset) string {
+	if d.Platform == "" {
+		return "unknown"
+	}
+	return strings.ToLower(d.Platform)
+}
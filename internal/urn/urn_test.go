@@ -0,0 +1,94 @@
+package urn
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSchemes(t *testing.T) {
+	cfg := Config{
+		Platform: "mysql",
+		Sources: map[string]SourceConfig{
+			"hive-prod": {Platform: "hive", Instance: "metastore:9083"},
+		},
+	}
+	tests := []struct {
+		cfg     Config
+		source  string
+		table   string
+		dataset string
+		field   string
+	}{
+		{Config{}, "mysql-prod", "shop.orders", "shop.orders", "shop.orders.id"},
+		{Config{Scheme: SchemeDataHub, Platform: "MySQL", Env: "dev"}, "mysql-prod", "shop.orders",
+			"urn:li:dataset:(urn:li:dataPlatform:mysql,shop.orders,DEV)",
+			"urn:li:schemaField:(urn:li:dataset:(urn:li:dataPlatform:mysql,shop.orders,DEV),id)"},
+		{Config{Scheme: SchemeDataHub, Sources: cfg.Sources}, "hive-prod", "dw.public.orders",
+			"urn:li:dataset:(urn:li:dataPlatform:hive,dw.public.orders,PROD)",
+			"urn:li:schemaField:(urn:li:dataset:(urn:li:dataPlatform:hive,dw.public.orders,PROD),id)"},
+		{Config{Scheme: SchemeOpenLineage, Platform: "mysql", Sources: cfg.Sources}, "hive-prod", "dw.orders",
+			"hive://metastore:9083/dw.orders", "hive://metastore:9083/dw.orders#id"},
+		{Config{Scheme: SchemeOpenLineage, Platform: "postgres"}, "pg", "app.public.users",
+			"postgres://pg/app.public.users", "postgres://pg/app.public.users#id"},
+		{Config{Scheme: SchemeTemplate, Template: "{{.Platform}}:{{.Instance}}:{{.Name}}", Platform: "mysql"}, "mysql-prod", "shop.orders",
+			"mysql:mysql-prod:shop.orders", "mysql:mysql-prod:shop.orders#id"},
+		{Config{Scheme: SchemeTemplate, Template: "{{.Database}}/{{.Table}}", FieldTemplate: "{{.Table}}.{{.Field}}"}, "", "shop.orders",
+			"shop/orders", "orders.id"},
+	}
+	for _, tt := range tests {
+		n, err := New(&tt.cfg)
+		if err != nil {
+			t.Fatalf("New(%+v) error = %v", tt.cfg, err)
+		}
+		d := n.Resolve(tt.source, tt.table)
+		if got := n.Dataset(d); got != tt.dataset {
+			t.Errorf("%s: Dataset(%s) = %q, want %q", n.cfg.Scheme, tt.table, got, tt.dataset)
+		}
+		if got := n.Field(d, "id"); got != tt.field {
+			t.Errorf("%s: Field(%s, id) = %q, want %q", n.cfg.Scheme, tt.table, got, tt.field)
+		}
+	}
+}
+
+func TestOpenLineageNames(t *testing.T) {
+	n, err := New(&Config{Scheme: SchemeOpenLineage, Platform: "mysql", Instance: "db.internal:3306"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	namespace, name := n.OpenLineage(n.Resolve("", "shop.orders"))
+	if namespace != "mysql://db.internal:3306" || name != "shop.orders" {
+		t.Errorf("OpenLineage() = %q, %q", namespace, name)
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	if _, err := New(&Config{Scheme: "nope"}); !errors.Is(err, ErrUnknownScheme) {
+		t.Errorf("New of an unknown scheme: %v", err)
+	}
+	for _, cfg := range []Config{
+		{Scheme: SchemeTemplate},
+		{Scheme: SchemeTemplate, Template: "{{.Nope}}"},
+		{Scheme: SchemeTemplate, Template: "{{.Name}}", FieldTemplate: "{{.Column}}"},
+		{Scheme: SchemeTemplate, Template: "{{.Name"},
+	} {
+		if _, err := New(&cfg); err == nil {
+			t.Errorf("New(%+v) succeeded", cfg)
+		}
+	}
+}
+
+func TestRegisterScheme(t *testing.T) {
+	RegisterScheme("upper", func(*Config) (Scheme, error) { return upperScheme{}, nil })
+	n, err := New(&Config{Scheme: "upper"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := n.DatasetURN("", "shop.orders"); got != "SHOP.ORDERS" {
+		t.Errorf("DatasetURN() = %q", got)
+	}
+}
+
+type upperScheme struct{ nativeScheme }
+
+func (upperScheme) Dataset(d Dataset) string { return strings.ToUpper(d.Name()) }
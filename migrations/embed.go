@@ -0,0 +1,10 @@
+// Package migrations embeds the repository schema migrations so the server
+// can apply them at startup (see internal/data/migrate).
+package migrations
+
+import "embed"
+
+// FS holds the migration files, one directory per database driver.
+//
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var FS embed.FS
@@ -0,0 +1,25 @@
+-- 回滚初始表结构 (按依赖顺序删除视图和表)
+
+-- 删除视图
+DROP VIEW IF EXISTS v_lineage_graph;
+DROP VIEW IF EXISTS v_column_info;
+DROP VIEW IF EXISTS v_table_info;
+
+-- 删除数据源管理模块表
+DROP TABLE IF EXISTS datasource_templates;
+DROP TABLE IF EXISTS task_executions;
+DROP TABLE IF EXISTS collection_tasks;
+DROP TABLE IF EXISTS datasources;
+
+-- 删除元数据核心表
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS system_configs;
+DROP TABLE IF EXISTS lineage_edges;
+DROP TABLE IF EXISTS lineage_nodes;
+DROP TABLE IF EXISTS partitions;
+DROP TABLE IF EXISTS column_statistics;
+DROP TABLE IF EXISTS columns;
+DROP TABLE IF EXISTS tables;
+DROP TABLE IF EXISTS `schemas`;
+DROP TABLE IF EXISTS catalogs;
+DROP TABLE IF EXISTS connectors;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS harvested_statistics;
+DROP TABLE IF EXISTS harvested_partitions;
+DROP TABLE IF EXISTS harvested_indexes;
+DROP TABLE IF EXISTS harvested_columns;
+DROP TABLE IF EXISTS harvested_tables;
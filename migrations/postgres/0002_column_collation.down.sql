@@ -0,0 +1,2 @@
+ALTER TABLE harvested_columns DROP COLUMN collation_name;
+ALTER TABLE harvested_columns DROP COLUMN charset;
@@ -0,0 +1,5 @@
+-- 列字符集与排序规则 / Column charset and collation
+
+-- 仅字符类型列有值；表级默认值与服务器时区记录在 harvested_tables.properties
+ALTER TABLE harvested_columns ADD COLUMN charset VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE harvested_columns ADD COLUMN collation_name VARCHAR(128) NOT NULL DEFAULT '';
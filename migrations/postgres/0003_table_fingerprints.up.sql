@@ -0,0 +1,5 @@
+-- 表变更指纹 / Table change fingerprints
+
+-- 采集器实现 collector.ChangeDetector 时记录 (DDL 哈希、最后修改时间与行数)，
+-- 增量同步只重新采集指纹变化的表
+ALTER TABLE harvested_tables ADD COLUMN fingerprint TEXT NOT NULL DEFAULT '';
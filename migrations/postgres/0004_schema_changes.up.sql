@@ -0,0 +1,18 @@
+-- 表结构变更记录 / Schema change events
+
+-- 同步重新采集的表与存储中的版本结构不一致时记录一条 (DDL 事件)，
+-- 用于统计各数据源的结构变更频率；表删除时保留其变更记录
+CREATE TABLE schema_changes (
+    id BIGSERIAL PRIMARY KEY,
+    source VARCHAR(255) NOT NULL,
+    catalog_name VARCHAR(255) NOT NULL,
+    schema_name VARCHAR(255) NOT NULL,
+    table_name VARCHAR(255) NOT NULL,
+    columns_added INT NOT NULL DEFAULT 0,
+    columns_dropped INT NOT NULL DEFAULT 0,
+    columns_modified INT NOT NULL DEFAULT 0,
+    indexes_changed INT NOT NULL DEFAULT 0,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_schema_changes_source ON schema_changes (source, changed_at);
@@ -0,0 +1 @@
+ALTER TABLE harvested_columns DROP COLUMN examples;
@@ -0,0 +1,4 @@
+-- 列示例值 / Column example values
+
+-- 同步开启 examples 时采集的少量示例值 (JSON 数组)，个人信息列不采集
+ALTER TABLE harvested_columns ADD COLUMN examples JSONB;
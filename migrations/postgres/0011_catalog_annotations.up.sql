@@ -0,0 +1,13 @@
+-- 自定义注解 / Namespaced key/value annotations
+
+-- 目录中表和列的自定义注解 (如 dq.check=enabled、finance.coa=4010) 的检索索引，保存目录文档时整体重写；
+-- column_name 为空表示表级注解
+CREATE TABLE catalog_annotations (
+    table_id BIGINT NOT NULL REFERENCES catalog_tables(table_id) ON DELETE CASCADE,
+    column_name VARCHAR(255) NOT NULL DEFAULT '',
+    ann_key VARCHAR(255) NOT NULL,
+    ann_value VARCHAR(1024) NOT NULL DEFAULT '',
+    PRIMARY KEY (table_id, column_name, ann_key)
+);
+
+CREATE INDEX idx_catalog_annotations_key ON catalog_annotations (ann_key, ann_value);
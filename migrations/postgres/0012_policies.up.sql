@@ -0,0 +1,38 @@
+-- 元数据策略检查 / Metadata policy checks
+
+-- 策略集合以单个文档保存 (id 固定为 1)，由 PUT /api/v1/policies 或 metadata-cli policy push 整体替换
+CREATE TABLE metadata_policies (
+    id INTEGER PRIMARY KEY,
+    document JSONB NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- 每次同步后重新计算的未解决违规；column_name 为空表示表级违规
+CREATE TABLE policy_violations (
+    id BIGSERIAL PRIMARY KEY,
+    table_id BIGINT NOT NULL REFERENCES harvested_tables(id) ON DELETE CASCADE,
+    table_name VARCHAR(767) NOT NULL,
+    policy VARCHAR(255) NOT NULL,
+    severity VARCHAR(16) NOT NULL,
+    column_name VARCHAR(255) NOT NULL DEFAULT '',
+    message VARCHAR(1024) NOT NULL,
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_policy_violations_table ON policy_violations (table_id, policy);
+
+-- 违规的出现 (violated) 与解决 (resolved) 事件，表删除后保留
+CREATE TABLE policy_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(16) NOT NULL,
+    table_id BIGINT NOT NULL,
+    table_name VARCHAR(767) NOT NULL,
+    policy VARCHAR(255) NOT NULL,
+    severity VARCHAR(16) NOT NULL,
+    column_name VARCHAR(255) NOT NULL DEFAULT '',
+    message VARCHAR(1024) NOT NULL,
+    detected_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_policy_events_created ON policy_events (created_at);
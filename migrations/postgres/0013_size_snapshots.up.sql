@@ -0,0 +1,13 @@
+-- 表大小快照 / Table size snapshots
+
+-- 表首次合并进目录以及行数或数据大小变化时记录，用于按模式、数据源汇总存储的历史变化
+CREATE TABLE table_size_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    table_id BIGINT NOT NULL REFERENCES harvested_tables(id) ON DELETE CASCADE,
+    row_count BIGINT NOT NULL DEFAULT 0,
+    data_size_bytes BIGINT NOT NULL DEFAULT 0,
+    collected_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_table_size_snapshots_collected ON table_size_snapshots (collected_at);
+CREATE INDEX idx_table_size_snapshots_table ON table_size_snapshots (table_id, collected_at);
@@ -0,0 +1,81 @@
+-- 采集元数据存储 (SQLite) / Harvested metadata store (SQLite)
+-- 与 postgres/ 下的表结构一致：JSON 以 TEXT 保存，时间以 UTC 文本保存
+
+-- 采集到的表，按 (数据源, catalog, schema, 表名) 唯一；同步时整体覆盖
+CREATE TABLE harvested_tables (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    source VARCHAR(255) NOT NULL,
+    catalog_name VARCHAR(255) NOT NULL,
+    schema_name VARCHAR(255) NOT NULL,
+    table_name VARCHAR(255) NOT NULL,
+    source_category VARCHAR(64) NOT NULL DEFAULT '',
+    source_type VARCHAR(64) NOT NULL DEFAULT '',
+    table_type VARCHAR(64) NOT NULL DEFAULT '',
+    comment TEXT NOT NULL DEFAULT '',
+    primary_key TEXT,
+    check_constraints TEXT,
+    storage TEXT,
+    properties TEXT,
+    inferred_schema BOOLEAN NOT NULL DEFAULT FALSE,
+    last_refreshed_at DATETIME,
+    synced_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (source, catalog_name, schema_name, table_name)
+);
+
+CREATE INDEX idx_harvested_tables_synced ON harvested_tables (source, synced_at);
+
+-- 列信息
+CREATE TABLE harvested_columns (
+    table_id INTEGER NOT NULL REFERENCES harvested_tables(id) ON DELETE CASCADE,
+    ordinal_position INT NOT NULL,
+    column_name VARCHAR(255) NOT NULL,
+    data_type VARCHAR(255) NOT NULL DEFAULT '',
+    source_type VARCHAR(255) NOT NULL DEFAULT '',
+    length INT,
+    "precision" INT,
+    scale INT,
+    nullable BOOLEAN NOT NULL DEFAULT TRUE,
+    default_value TEXT,
+    comment TEXT NOT NULL DEFAULT '',
+    is_primary_key BOOLEAN NOT NULL DEFAULT FALSE,
+    is_partition_column BOOLEAN NOT NULL DEFAULT FALSE,
+    is_auto_increment BOOLEAN NOT NULL DEFAULT FALSE,
+    generated TEXT,
+    charset VARCHAR(64) NOT NULL DEFAULT '',
+    collation_name VARCHAR(128) NOT NULL DEFAULT '',
+    raw TEXT,
+    PRIMARY KEY (table_id, column_name)
+);
+
+-- 索引信息
+CREATE TABLE harvested_indexes (
+    table_id INTEGER NOT NULL REFERENCES harvested_tables(id) ON DELETE CASCADE,
+    index_name VARCHAR(255) NOT NULL,
+    columns TEXT NOT NULL,
+    is_unique BOOLEAN NOT NULL DEFAULT FALSE,
+    index_type VARCHAR(64) NOT NULL DEFAULT '',
+    comment TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (table_id, index_name)
+);
+
+-- 分区信息
+CREATE TABLE harvested_partitions (
+    table_id INTEGER NOT NULL REFERENCES harvested_tables(id) ON DELETE CASCADE,
+    partition_name VARCHAR(255) NOT NULL,
+    partition_type VARCHAR(64) NOT NULL DEFAULT '',
+    columns TEXT,
+    expression TEXT NOT NULL DEFAULT '',
+    values_count INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (table_id, partition_name)
+);
+
+-- 表统计信息，列统计以 JSON 保存
+CREATE TABLE harvested_statistics (
+    table_id INTEGER PRIMARY KEY REFERENCES harvested_tables(id) ON DELETE CASCADE,
+    row_count BIGINT NOT NULL DEFAULT 0,
+    data_size_bytes BIGINT NOT NULL DEFAULT 0,
+    partition_count INT NOT NULL DEFAULT 0,
+    consumer_lag BIGINT,
+    column_stats TEXT,
+    collected_at DATETIME NOT NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE harvested_tables DROP COLUMN fingerprint;
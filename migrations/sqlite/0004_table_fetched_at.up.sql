@@ -0,0 +1,7 @@
+-- 表采集时间 (SQLite) / Table fetch times
+
+-- 表最近一次从数据源重新采集的时间；synced_at 在增量同步跳过的表上也会更新，
+-- fetched_at 只在重新采集时更新。分级策略 (tiers) 据此跳过同步间隔未到的表
+ALTER TABLE harvested_tables ADD COLUMN fetched_at DATETIME;
+
+UPDATE harvested_tables SET fetched_at = synced_at;
@@ -0,0 +1,2 @@
+ALTER TABLE harvested_columns DROP COLUMN descriptions;
+ALTER TABLE harvested_tables DROP COLUMN descriptions;
@@ -0,0 +1,6 @@
+-- 多语言描述 (SQLite) / Multi-language descriptions
+
+-- 表和列注释的多语言描述 (JSON 对象，键为语言标签)。数据源配置 languages 时同步写入，
+-- 注释原文记在其语言下，其余语言由翻译钩子 (collector.Translator) 翻译
+ALTER TABLE harvested_tables ADD COLUMN descriptions TEXT;
+ALTER TABLE harvested_columns ADD COLUMN descriptions TEXT;
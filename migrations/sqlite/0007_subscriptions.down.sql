@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_channels;
+DROP TABLE IF EXISTS subscriptions;
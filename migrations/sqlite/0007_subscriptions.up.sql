@@ -0,0 +1,24 @@
+-- 数据集订阅 (SQLite) / Dataset subscriptions
+
+-- 用户订阅的表：table_pattern 为 catalog.schema.table 的通配符 (如 shop.*)，空表示数据源的所有表与同步失败；
+-- events 为订阅的事件类型 (JSON 数组)，空表示全部。通知只发送给订阅了该表的用户
+CREATE TABLE subscriptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_name VARCHAR(255) NOT NULL,
+    source VARCHAR(255) NOT NULL,
+    table_pattern VARCHAR(512) NOT NULL DEFAULT '',
+    events TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_name, source, table_pattern)
+);
+
+CREATE INDEX idx_subscriptions_source ON subscriptions (source);
+
+-- 用户接收通知的频道 (slack、teams、dingtalk 或 webhook)，每种类型一个
+CREATE TABLE user_channels (
+    user_name VARCHAR(255) NOT NULL,
+    channel_type VARCHAR(32) NOT NULL,
+    webhook_url TEXT NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_name, channel_type)
+);
@@ -0,0 +1,13 @@
+-- 同步失败记录 (SQLite) / Failed syncs
+
+-- 数据源同步失败时记录一条：tables_failed 为失败的表数，error 为 (已脱敏的) 错误，每行一条；
+-- 用于事故排查时关联上游数据源的同步失败
+CREATE TABLE sync_failures (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    source VARCHAR(255) NOT NULL,
+    tables_failed INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL,
+    failed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sync_failures_source ON sync_failures (source, failed_at);
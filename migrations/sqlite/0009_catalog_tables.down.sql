@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS metadata_conflicts;
+DROP TABLE IF EXISTS catalog_tables;
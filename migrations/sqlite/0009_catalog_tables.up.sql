@@ -0,0 +1,25 @@
+-- 元数据目录 (SQLite) / Curated catalog tables
+
+-- 目录中的表：同步合并后的元数据与用户编辑 (描述、注解、负责人、标签等) 以 JSON 文档保存；
+-- version 为乐观锁版本号，同步与用户编辑并发写入时重新读取并合并而不是整行覆盖
+CREATE TABLE catalog_tables (
+    table_id INTEGER PRIMARY KEY REFERENCES harvested_tables(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    document TEXT NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 同步带来的数据源值与用户编辑冲突的记录；resolution 为 kept_user_value 或 field_removed
+CREATE TABLE metadata_conflicts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    table_id INTEGER NOT NULL REFERENCES harvested_tables(id) ON DELETE CASCADE,
+    field VARCHAR(512) NOT NULL,
+    user_value TEXT NOT NULL DEFAULT '',
+    edited_by VARCHAR(255) NOT NULL DEFAULT '',
+    previous_source_value TEXT NOT NULL DEFAULT '',
+    source_value TEXT NOT NULL DEFAULT '',
+    resolution VARCHAR(32) NOT NULL,
+    detected_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_metadata_conflicts_table ON metadata_conflicts (table_id, detected_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS policy_events;
+DROP TABLE IF EXISTS policy_violations;
+DROP TABLE IF EXISTS metadata_policies;
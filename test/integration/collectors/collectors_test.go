@@ -0,0 +1,374 @@
+//go:build integration
+
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/config"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+)
+
+const password = "Secret-123"
+
+func TestMySQL(t *testing.T) {
+	dsn := func(endpoint string) string {
+		return fmt.Sprintf("root:%s@tcp(%s)/shop?multiStatements=true", password, endpoint)
+	}
+	endpoint := start(t, container{
+		name: "mysql",
+		options: &dockertest.RunOptions{
+			Repository: "mysql",
+			Tag:        "8.0",
+			Env:        []string{"MYSQL_ROOT_PASSWORD=" + password, "MYSQL_DATABASE=shop"},
+		},
+		port:  "3306/tcp",
+		ready: func(endpoint string) error { return ping("mysql", dsn(endpoint)) },
+	})
+	seedSQL(t, "mysql", dsn(endpoint), "testdata/mysql.sql")
+
+	c := newCollector(t, &config.ConnectorConfig{
+		Type:        "mysql",
+		Category:    collector.CategoryRDBMS,
+		Endpoint:    endpoint,
+		Credentials: config.Credentials{User: "root", Password: password},
+		Properties:  config.ConnectionProps{Extra: map[string]string{"database": "shop"}},
+	})
+	runCollectorSuite(t, c, fixture{
+		catalog: "def",
+		schema:  "shop",
+		table:   "orders",
+		columns: []string{"id", "customer_id", "price", "qty", "total", "ordered_at"},
+		views:   []string{"customer_orders"},
+	})
+}
+
+func TestPostgres(t *testing.T) {
+	dsn := func(endpoint string) string {
+		return fmt.Sprintf("postgres://postgres:%s@%s/shop?sslmode=disable", password, endpoint)
+	}
+	endpoint := start(t, container{
+		name: "postgres",
+		options: &dockertest.RunOptions{
+			Repository: "postgres",
+			Tag:        "16",
+			Env:        []string{"POSTGRES_PASSWORD=" + password, "POSTGRES_DB=shop"},
+		},
+		port:  "5432/tcp",
+		ready: func(endpoint string) error { return ping("postgres", dsn(endpoint)) },
+	})
+	seedSQL(t, "postgres", dsn(endpoint), "testdata/postgres.sql")
+
+	c := newCollector(t, &config.ConnectorConfig{
+		Type:        "postgres",
+		Category:    collector.CategoryRDBMS,
+		Endpoint:    endpoint,
+		Credentials: config.Credentials{User: "postgres", Password: password},
+		Properties: config.ConnectionProps{Extra: map[string]string{
+			"database": "shop",
+			"sslmode":  "disable",
+		}},
+	})
+	runCollectorSuite(t, c, fixture{
+		catalog: "shop",
+		schema:  "public",
+		table:   "customers",
+		columns: []string{"id", "email", "first_name", "last_name", "full_name", "created_at"},
+		minRows: 3,
+		views:   []string{"customer_orders"},
+	})
+}
+
+func TestKafka(t *testing.T) {
+	// Kafka hands out the advertised address to clients, so the host port
+	// has to be known before the broker starts.
+	hostPort := freePort(t)
+	endpoint := start(t, container{
+		name: "kafka",
+		options: &dockertest.RunOptions{
+			Repository: "apache/kafka",
+			Tag:        "3.7.0",
+			Env: []string{
+				"KAFKA_NODE_ID=1",
+				"KAFKA_PROCESS_ROLES=broker,controller",
+				"KAFKA_LISTENERS=PLAINTEXT://:9092,CONTROLLER://:9093",
+				"KAFKA_ADVERTISED_LISTENERS=PLAINTEXT://localhost:" + hostPort,
+				"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP=CONTROLLER:PLAINTEXT,PLAINTEXT:PLAINTEXT",
+				"KAFKA_CONTROLLER_LISTENER_NAMES=CONTROLLER",
+				"KAFKA_CONTROLLER_QUORUM_VOTERS=1@localhost:9093",
+				"KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR=1",
+			},
+			PortBindings: map[docker.Port][]docker.PortBinding{
+				"9092/tcp": {{HostIP: "localhost", HostPort: hostPort}},
+			},
+		},
+		port: "9092/tcp",
+		ready: func(endpoint string) error {
+			client, err := sarama.NewClient([]string{endpoint}, sarama.NewConfig())
+			if err != nil {
+				return err
+			}
+			return client.Close()
+		},
+	})
+	seedKafka(t, endpoint, "orders", 3, 5)
+
+	c := newCollector(t, &config.ConnectorConfig{
+		Type:     "kafka",
+		Category: collector.CategoryMessageQueue,
+		Endpoint: endpoint,
+	})
+	runCollectorSuite(t, c, fixture{
+		catalog: "kafka",
+		schema:  "default",
+		table:   "orders",
+		minRows: 5,
+	})
+}
+
+func TestElasticsearch(t *testing.T) {
+	endpoint := start(t, container{
+		name: "elasticsearch",
+		options: &dockertest.RunOptions{
+			Repository: "docker.elastic.co/elasticsearch/elasticsearch",
+			Tag:        "8.13.4",
+			Env: []string{
+				"discovery.type=single-node",
+				"xpack.security.enabled=false",
+				"ES_JAVA_OPTS=-Xms512m -Xmx512m",
+			},
+		},
+		port: "9200/tcp",
+		ready: func(endpoint string) error {
+			return httpDo(http.MethodGet, "http://"+endpoint+"/_cluster/health?wait_for_status=yellow&timeout=5s", "", nil)
+		},
+	})
+	base := "http://" + endpoint
+	httpRequire(t, http.MethodPut, base+"/orders", "", map[string]any{
+		"mappings": map[string]any{"properties": map[string]any{
+			"id":       map[string]any{"type": "long"},
+			"customer": map[string]any{"type": "keyword"},
+			"total":    map[string]any{"type": "double"},
+		}},
+	})
+	for i, customer := range []string{"ada", "alan", "grace"} {
+		httpRequire(t, http.MethodPut, fmt.Sprintf("%s/orders/_doc/%d", base, i+1), "", map[string]any{
+			"id": i + 1, "customer": customer, "total": float64(i+1) * 9.99,
+		})
+	}
+	httpRequire(t, http.MethodPost, base+"/orders/_refresh", "", nil)
+
+	c := newCollector(t, &config.ConnectorConfig{
+		Type:     "elasticsearch",
+		Category: collector.CategoryDocumentDB,
+		Endpoint: endpoint,
+	})
+	runCollectorSuite(t, c, fixture{
+		table:   "orders",
+		columns: []string{"id", "customer", "total"},
+		minRows: 3,
+	})
+}
+
+func TestRabbitMQ(t *testing.T) {
+	endpoint := start(t, container{
+		name: "rabbitmq",
+		options: &dockertest.RunOptions{
+			Repository: "rabbitmq",
+			Tag:        "3.13-management",
+		},
+		port: "15672/tcp",
+		ready: func(endpoint string) error {
+			return httpDo(http.MethodGet, "http://"+endpoint+"/api/overview", "guest", nil)
+		},
+	})
+	base := "http://" + endpoint + "/api"
+	httpRequire(t, http.MethodPut, base+"/queues/%2F/orders", "guest", map[string]any{"durable": true})
+	for i := range 4 {
+		httpRequire(t, http.MethodPost, base+"/exchanges/%2F/amq.default/publish", "guest", map[string]any{
+			"routing_key":      "orders",
+			"payload":          fmt.Sprintf(`{"id":%d}`, i+1),
+			"payload_encoding": "string",
+			"properties":       map[string]any{},
+		})
+	}
+
+	c := newCollector(t, &config.ConnectorConfig{
+		Type:        "rabbitmq",
+		Category:    collector.CategoryMessageQueue,
+		Endpoint:    endpoint,
+		Credentials: config.Credentials{User: "guest", Password: "guest"},
+	})
+	runCollectorSuite(t, c, fixture{
+		catalog: "rabbitmq",
+		schema:  "/",
+		table:   "orders",
+	})
+}
+
+func TestMinIO(t *testing.T) {
+	const user = "minio"
+	endpoint := start(t, container{
+		name: "minio",
+		options: &dockertest.RunOptions{
+			Repository: "minio/minio",
+			Tag:        "latest",
+			Cmd:        []string{"server", "/data"},
+			Env:        []string{"MINIO_ROOT_USER=" + user, "MINIO_ROOT_PASSWORD=" + password},
+		},
+		port: "9000/tcp",
+		ready: func(endpoint string) error {
+			return httpDo(http.MethodGet, "http://"+endpoint+"/minio/health/live", "", nil)
+		},
+	})
+	seedMinIO(t, endpoint, user, "lake", map[string]string{
+		"events/2024-01-01.json": `{"id":1,"type":"click"}` + "\n" + `{"id":2,"type":"view"}` + "\n",
+		"events/2024-01-02.json": `{"id":3,"type":"click"}` + "\n",
+		"users/users.csv":        "id,name\n1,ada\n2,alan\n",
+	})
+
+	c := newCollector(t, &config.ConnectorConfig{
+		Type:        "minio",
+		Category:    collector.CategoryObjectStorage,
+		Endpoint:    endpoint,
+		Credentials: config.Credentials{User: user, Password: password},
+	})
+	runCollectorSuite(t, c, fixture{
+		catalog: "minio",
+		schema:  "lake",
+		table:   "events",
+	})
+}
+
+func ping(driver, dsn string) error {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Ping()
+}
+
+// seedSQL runs the statements of a testdata script.
+func seedSQL(t *testing.T, driver, dsn, path string) {
+	t.Helper()
+	script, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range strings.Split(string(script), ";\n") {
+		if stmt = strings.TrimSpace(stmt); stmt == "" {
+			continue
+		}
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("seed %s: %v\n%s", path, err, stmt)
+		}
+	}
+}
+
+// seedKafka creates a topic and produces messages to it.
+func seedKafka(t *testing.T, endpoint, topic string, partitions int32, messages int) {
+	t.Helper()
+	cfg := sarama.NewConfig()
+	cfg.Producer.Return.Successes = true
+	admin, err := sarama.NewClusterAdmin([]string{endpoint}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+	if err := admin.CreateTopic(topic, &sarama.TopicDetail{NumPartitions: partitions, ReplicationFactor: 1}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	producer, err := sarama.NewSyncProducer([]string{endpoint}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+	for i := range messages {
+		_, _, err := producer.SendMessage(&sarama.ProducerMessage{
+			Topic: topic,
+			Key:   sarama.StringEncoder(fmt.Sprint(i)),
+			Value: sarama.StringEncoder(fmt.Sprintf(`{"id":%d}`, i+1)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// seedMinIO creates a bucket holding the given objects.
+func seedMinIO(t *testing.T, endpoint, user, bucket string, objects map[string]string) {
+	t.Helper()
+	ctx := context.Background()
+	client, err := minio.New(endpoint, &minio.Options{Creds: credentials.NewStaticV4(user, password, "")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for key, body := range objects {
+		_, err := client.PutObject(ctx, bucket, key, strings.NewReader(body), int64(len(body)), minio.PutObjectOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// httpDo sends body as JSON, authenticating as user with the same password
+// when user is set, and fails on non-2xx responses.
+func httpDo(method, url, user string, body any) error {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, url, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if user != "" {
+		req.SetBasicAuth(user, user)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s %s: %s", method, url, resp.Status)
+	}
+	return nil
+}
+
+func httpRequire(t *testing.T, method, url, user string, body any) {
+	t.Helper()
+	if err := httpDo(method, url, user, body); err != nil {
+		t.Fatal(err)
+	}
+}
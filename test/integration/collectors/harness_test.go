@@ -0,0 +1,111 @@
+//go:build integration
+
+// Package collectors runs the full interface of every collector against live
+// services started in Docker containers with github.com/ory/dockertest.
+//
+// The suite is CI-optional: it only builds with the integration tag and skips
+// when no Docker daemon is reachable, unless INTEGRATION_REQUIRE_DOCKER is set.
+// INTEGRATION_SERVICES restricts the run to a comma-separated list of
+// services, e.g. INTEGRATION_SERVICES=mysql,postgres.
+//
+//	go test -tags integration -v ./test/integration/collectors/...
+package collectors
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// pool is nil when Docker is unavailable.
+var (
+	pool          *dockertest.Pool
+	poolErr       error
+	services      = os.Getenv("INTEGRATION_SERVICES")
+	requireDocker = os.Getenv("INTEGRATION_REQUIRE_DOCKER") != ""
+)
+
+func TestMain(m *testing.M) {
+	pool, poolErr = dockertest.NewPool("")
+	if poolErr == nil {
+		poolErr = pool.Client.Ping()
+	}
+	if poolErr != nil {
+		if requireDocker {
+			fmt.Fprintf(os.Stderr, "docker is required but unavailable: %v\n", poolErr)
+			os.Exit(1)
+		}
+		pool = nil
+	} else {
+		pool.MaxWait = 3 * time.Minute
+	}
+	os.Exit(m.Run())
+}
+
+// container describes a service container to start.
+type container struct {
+	name    string
+	options *dockertest.RunOptions
+	// port is the exposed container port the collector connects to.
+	port docker.Port
+	// ready is retried until the service accepts requests on endpoint.
+	ready func(endpoint string) error
+}
+
+// start runs the container and waits until it is ready. It returns the
+// host:port endpoint of the service port. The container is purged when the
+// test finishes, and expires on its own should the test binary be killed.
+func start(t *testing.T, c container) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	if services != "" && !slices.Contains(strings.Split(services, ","), c.name) {
+		t.Skipf("%s is not in INTEGRATION_SERVICES", c.name)
+	}
+	if pool == nil {
+		t.Skipf("Skipping %s integration test, docker unavailable: %v", c.name, poolErr)
+	}
+
+	resource, err := pool.RunWithOptions(c.options, func(hc *docker.HostConfig) {
+		hc.AutoRemove = true
+		hc.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		t.Fatalf("start %s: %v", c.name, err)
+	}
+	t.Cleanup(func() {
+		if err := pool.Purge(resource); err != nil {
+			t.Logf("purge %s: %v", c.name, err)
+		}
+	})
+	if err := resource.Expire(600); err != nil {
+		t.Fatalf("expire %s: %v", c.name, err)
+	}
+
+	endpoint := resource.GetHostPort(string(c.port))
+	if err := pool.Retry(func() error { return c.ready(endpoint) }); err != nil {
+		t.Fatalf("%s did not become ready at %s: %v", c.name, endpoint, err)
+	}
+	return endpoint
+}
+
+// freePort returns a free host port, for services such as Kafka that
+// advertise their own address and so need a known host port up front.
+func freePort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+}
@@ -0,0 +1,238 @@
+//go:build integration
+
+package collectors
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go-metadata/internal/collector"
+	"go-metadata/internal/collector/config"
+	"go-metadata/internal/collector/factory"
+
+	_ "go-metadata/internal/collector/drivers"
+)
+
+// fixture is the seeded object a collector is expected to find.
+type fixture struct {
+	// catalog is the expected catalog, or empty to use the first discovered one.
+	catalog string
+	// schema is the expected schema, or empty for sources without schemas.
+	schema  string
+	table   string
+	columns []string
+	// minRows is the minimum row (document, message, object) count.
+	minRows int64
+	// views lists the views of schema whose dependencies are expected, for
+	// collectors implementing collector.ViewDependencyCollector.
+	views []string
+}
+
+// newCollector creates a collector through the default factory, the same way
+// the server and the CLI do.
+func newCollector(t *testing.T, cfg *config.ConnectorConfig) collector.Collector {
+	t.Helper()
+	if cfg.Properties.ConnectionTimeout == 0 {
+		cfg.Properties.ConnectionTimeout = 10
+	}
+	c, err := factory.DefaultFactory.Create(cfg)
+	if err != nil {
+		t.Fatalf("create %s collector: %v", cfg.Type, err)
+	}
+	return c
+}
+
+// runCollectorSuite exercises every method of the Collector interface against
+// a live service seeded with want.
+func runCollectorSuite(t *testing.T, c collector.Collector, want fixture) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	if c.Category() == "" || c.Type() == "" {
+		t.Fatalf("Expected category and type, got %q and %q", c.Category(), c.Type())
+	}
+	if _, err := c.DiscoverCatalogs(ctx); collector.GetErrorCode(err) != collector.ErrCodeConnectionClosed {
+		t.Errorf("Expected CONNECTION_CLOSED before Connect, got %v", err)
+	}
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if err := c.Connect(ctx); err != nil {
+		t.Errorf("Connect is not idempotent: %v", err)
+	}
+	defer c.Close()
+
+	t.Run("HealthCheck", func(t *testing.T) {
+		status, err := c.HealthCheck(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !status.Connected {
+			t.Errorf("Expected a connected status, got %+v", status)
+		}
+	})
+
+	catalog := want.catalog
+	t.Run("DiscoverCatalogs", func(t *testing.T) {
+		catalogs, err := c.DiscoverCatalogs(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(catalogs) == 0 {
+			t.Fatal("Expected at least one catalog")
+		}
+		if catalog == "" {
+			catalog = catalogs[0].Catalog
+			return
+		}
+		if !slices.ContainsFunc(catalogs, func(ci collector.CatalogInfo) bool { return ci.Catalog == catalog }) {
+			t.Errorf("Catalog %s not discovered in %+v", catalog, catalogs)
+		}
+	})
+
+	t.Run("ListSchemas", func(t *testing.T) {
+		schemas, err := c.ListSchemas(ctx, catalog)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want.schema != "" && !slices.Contains(schemas, want.schema) {
+			t.Errorf("Schema %s not listed in %v", want.schema, schemas)
+		}
+	})
+
+	t.Run("ListTables", func(t *testing.T) {
+		all, err := c.ListTables(ctx, catalog, want.schema, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Contains(all.Tables, want.table) {
+			t.Fatalf("Table %s not listed in %v", want.table, all.Tables)
+		}
+
+		// Paging one table at a time must return the same tables.
+		var paged []string
+		opts := &collector.ListOptions{PageSize: 1}
+		for range len(all.Tables) + 1 {
+			page, err := c.ListTables(ctx, catalog, want.schema, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			paged = append(paged, page.Tables...)
+			if page.NextPageToken == "" {
+				break
+			}
+			opts.PageToken = page.NextPageToken
+		}
+		if !slices.Equal(sorted(paged), sorted(all.Tables)) {
+			t.Errorf("Paged tables %v differ from %v", paged, all.Tables)
+		}
+
+		filtered, err := c.ListTables(ctx, catalog, want.schema, &collector.ListOptions{
+			Filter: &collector.MatchingRule{Include: []string{want.table}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(filtered.Tables, []string{want.table}) {
+			t.Errorf("Expected the filter to match only %s, got %v", want.table, filtered.Tables)
+		}
+	})
+
+	t.Run("FetchTableMetadata", func(t *testing.T) {
+		md, err := c.FetchTableMetadata(ctx, catalog, want.schema, want.table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if md.Name != want.table || md.SourceType != c.Type() || md.SourceCategory != c.Category() {
+			t.Errorf("Unexpected table identity %s/%s/%s", md.SourceCategory, md.SourceType, md.Name)
+		}
+		for _, name := range want.columns {
+			if !slices.ContainsFunc(md.Columns, func(col collector.Column) bool { return col.Name == name }) {
+				t.Errorf("Column %s not found in %+v", name, md.Columns)
+			}
+		}
+
+		_, err = c.FetchTableMetadata(ctx, catalog, want.schema, "no_such_table")
+		if err == nil {
+			t.Error("Expected an error for a missing table")
+		}
+	})
+
+	t.Run("FetchTableStatistics", func(t *testing.T) {
+		stats, err := c.FetchTableStatistics(ctx, catalog, want.schema, want.table)
+		if skipUnsupported(t, err) {
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.RowCount < want.minRows {
+			t.Errorf("Expected at least %d rows, got %d", want.minRows, stats.RowCount)
+		}
+	})
+
+	t.Run("FetchPartitions", func(t *testing.T) {
+		_, err := c.FetchPartitions(ctx, catalog, want.schema, want.table)
+		if skipUnsupported(t, err) {
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if vc, ok := c.(collector.ViewDependencyCollector); ok && len(want.views) > 0 {
+		t.Run("FetchViewDependencies", func(t *testing.T) {
+			deps, err := vc.FetchViewDependencies(ctx, catalog, want.schema)
+			if skipUnsupported(t, err) {
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, view := range want.views {
+				if !slices.ContainsFunc(deps, func(d collector.ViewDependency) bool { return d.View == view }) {
+					t.Errorf("No dependencies of view %s in %+v", view, deps)
+				}
+			}
+		})
+	}
+
+	t.Run("Cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := c.ListTables(cancelled, catalog, want.schema, nil)
+		if err == nil {
+			t.Fatal("Expected an error for a cancelled context")
+		}
+		if !errors.Is(err, context.Canceled) && collector.GetErrorCode(err) != collector.ErrCodeCancelled {
+			t.Errorf("Expected a cancellation error, got %v", err)
+		}
+	})
+
+	if err := c.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if _, err := c.ListSchemas(ctx, catalog); collector.GetErrorCode(err) != collector.ErrCodeConnectionClosed {
+		t.Errorf("Expected CONNECTION_CLOSED after Close, got %v", err)
+	}
+}
+
+// skipUnsupported skips the test for features the source does not support.
+func skipUnsupported(t *testing.T, err error) bool {
+	t.Helper()
+	if collector.GetErrorCode(err) == collector.ErrCodeUnsupportedFeature {
+		t.Skipf("Unsupported: %v", err)
+		return true
+	}
+	return false
+}
+
+func sorted(s []string) []string {
+	s = slices.Clone(s)
+	slices.Sort(s)
+	return s
+}
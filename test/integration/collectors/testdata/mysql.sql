@@ -0,0 +1,42 @@
+CREATE TABLE customers (
+    id         BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    email      VARCHAR(255) NOT NULL COMMENT 'login email',
+    first_name VARCHAR(64)  NOT NULL,
+    last_name  VARCHAR(64)  NOT NULL,
+    full_name  VARCHAR(129) GENERATED ALWAYS AS (CONCAT(first_name, ' ', last_name)) STORED,
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_customers_email (email)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='shop customers';
+
+CREATE TABLE orders (
+    id          BIGINT        NOT NULL,
+    customer_id BIGINT        NOT NULL,
+    price       DECIMAL(10,2) NOT NULL,
+    qty         INT           NOT NULL,
+    total       DECIMAL(12,2) AS (price * qty) VIRTUAL,
+    ordered_at  DATE          NOT NULL,
+    PRIMARY KEY (id, ordered_at),
+    KEY idx_orders_customer (customer_id),
+    CONSTRAINT chk_orders_qty CHECK (qty > 0)
+) ENGINE=InnoDB COMMENT='customer orders'
+PARTITION BY RANGE (YEAR(ordered_at)) (
+    PARTITION p2023 VALUES LESS THAN (2024),
+    PARTITION p2024 VALUES LESS THAN (2025),
+    PARTITION pmax VALUES LESS THAN MAXVALUE
+);
+
+INSERT INTO customers (email, first_name, last_name) VALUES
+    ('ada@example.com', 'Ada', 'Lovelace'),
+    ('alan@example.com', 'Alan', 'Turing'),
+    ('grace@example.com', 'Grace', 'Hopper');
+
+INSERT INTO orders (id, customer_id, price, qty, ordered_at) VALUES
+    (1, 1, 9.99, 2, '2023-06-01'),
+    (2, 2, 19.50, 1, '2024-02-14'),
+    (3, 3, 4.25, 4, '2024-11-30');
+
+ANALYZE TABLE customers, orders;
+
+CREATE VIEW customer_orders AS
+    SELECT c.id AS customer_id, c.full_name, o.id AS order_id, o.total
+    FROM customers c JOIN orders o ON o.customer_id = c.id;
@@ -0,0 +1,41 @@
+CREATE TABLE customers (
+    id         BIGSERIAL    PRIMARY KEY,
+    email      VARCHAR(255) NOT NULL UNIQUE,
+    first_name VARCHAR(64)  NOT NULL,
+    last_name  VARCHAR(64)  NOT NULL,
+    full_name  TEXT GENERATED ALWAYS AS (first_name || ' ' || last_name) STORED,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+COMMENT ON TABLE customers IS 'shop customers';
+COMMENT ON COLUMN customers.email IS 'login email';
+
+CREATE TABLE orders (
+    id          BIGINT        NOT NULL,
+    customer_id BIGINT        NOT NULL REFERENCES customers (id),
+    price       NUMERIC(10,2) NOT NULL,
+    qty         INT           NOT NULL CHECK (qty > 0),
+    ordered_at  DATE          NOT NULL,
+    PRIMARY KEY (id, ordered_at)
+) PARTITION BY RANGE (ordered_at);
+COMMENT ON TABLE orders IS 'customer orders';
+CREATE INDEX idx_orders_customer ON orders (customer_id);
+
+CREATE TABLE orders_2023 PARTITION OF orders FOR VALUES FROM ('2023-01-01') TO ('2024-01-01');
+CREATE TABLE orders_2024 PARTITION OF orders FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');
+
+INSERT INTO customers (email, first_name, last_name) VALUES
+    ('ada@example.com', 'Ada', 'Lovelace'),
+    ('alan@example.com', 'Alan', 'Turing'),
+    ('grace@example.com', 'Grace', 'Hopper');
+
+INSERT INTO orders (id, customer_id, price, qty, ordered_at) VALUES
+    (1, 1, 9.99, 2, '2023-06-01'),
+    (2, 2, 19.50, 1, '2024-02-14'),
+    (3, 3, 4.25, 4, '2024-11-30');
+
+ANALYZE customers;
+ANALYZE orders;
+
+CREATE VIEW customer_orders AS
+    SELECT c.id AS customer_id, c.full_name, o.id AS order_id, o.price * o.qty AS total
+    FROM customers c JOIN orders o ON o.customer_id = c.id;